@@ -22,9 +22,12 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	snapclient "github.com/kubernetes-csi/external-snapshotter/client/v6/clientset/versioned"
 	"github.com/pkg/errors"
@@ -35,6 +38,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
 	"kubevirt.io/containerized-data-importer/pkg/apiserver"
+	"kubevirt.io/containerized-data-importer/pkg/apiserver/webhooks"
 	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned"
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	certwatcher "kubevirt.io/containerized-data-importer/pkg/util/cert/watcher"
@@ -48,6 +52,9 @@ const (
 
 	// Default address api listens on.
 	defaultHost = "0.0.0.0"
+
+	// Port the metrics of the webhooks are served on.
+	metricsPort = 8080
 )
 
 var (
@@ -150,9 +157,20 @@ func main() {
 	}
 
 	go certWatcher.Start(ctx.Done())
+	go serveMetrics()
 
 	err = cdiAPIApp.Start(ctx.Done())
 	if err != nil {
 		klog.Fatalf("TLS server failed: %v\n", errors.WithStack(err))
 	}
 }
+
+// serveMetrics serves the metrics of the webhooks to prometheus, over http like the metrics of the controller
+func serveMetrics() {
+	prometheus.MustRegister(webhooks.RejectedAdmissionsCounter)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	if err := http.ListenAndServe(fmt.Sprintf("%s:%d", defaultHost, metricsPort), mux); err != nil {
+		klog.Errorf("Metrics server failed: %v\n", err)
+	}
+}
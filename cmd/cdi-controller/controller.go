@@ -38,6 +38,7 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/controller"
 	dvc "kubevirt.io/containerized-data-importer/pkg/controller/datavolume"
 	"kubevirt.io/containerized-data-importer/pkg/controller/transfer"
+	"kubevirt.io/containerized-data-importer/pkg/keys"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/fetcher"
@@ -205,7 +206,7 @@ func start(ctx context.Context, cfg *rest.Config) {
 	}
 
 	// TODO: Current DV controller had threadiness 3, should we do the same here, defaults to one thread.
-	if _, err := dvc.NewImportController(ctx, mgr, log, installerLabels); err != nil {
+	if _, err := dvc.NewImportController(ctx, mgr, log, importerImage, pullPolicy, getTokenPublicKeys(), installerLabels); err != nil {
 		klog.Errorf("Unable to setup datavolume import controller: %v", err)
 		os.Exit(1)
 	}
@@ -214,12 +215,12 @@ func start(ctx context.Context, cfg *rest.Config) {
 		os.Exit(1)
 	}
 	if _, err := dvc.NewPvcCloneController(ctx, mgr, log,
-		clonerImage, importerImage, pullPolicy, getTokenPublicKey(), getTokenPrivateKey(), installerLabels); err != nil {
+		clonerImage, importerImage, pullPolicy, getTokenPublicKeys(), getTokenPrivateKey(), installerLabels); err != nil {
 		klog.Errorf("Unable to setup datavolume pvc clone controller: %v", err)
 		os.Exit(1)
 	}
 	if _, err := dvc.NewSnapshotCloneController(ctx, mgr, log,
-		clonerImage, importerImage, pullPolicy, getTokenPublicKey(), getTokenPrivateKey(), installerLabels); err != nil {
+		clonerImage, importerImage, pullPolicy, getTokenPublicKeys(), getTokenPrivateKey(), installerLabels); err != nil {
 		klog.Errorf("Unable to setup datavolume snapshot clone controller: %v", err)
 		os.Exit(1)
 	}
@@ -233,7 +234,7 @@ func start(ctx context.Context, cfg *rest.Config) {
 		os.Exit(1)
 	}
 
-	if _, err := controller.NewCloneController(mgr, log, clonerImage, pullPolicy, verbose, uploadClientCertGenerator, uploadServerBundleFetcher, getTokenPublicKey(), installerLabels); err != nil {
+	if _, err := controller.NewCloneController(mgr, log, clonerImage, pullPolicy, verbose, uploadClientCertGenerator, uploadServerBundleFetcher, getTokenPublicKeys(), installerLabels); err != nil {
 		klog.Errorf("Unable to setup clone controller: %v", err)
 		os.Exit(1)
 	}
@@ -319,18 +320,19 @@ func deleteReadyFile() {
 	os.Remove(readyFile)
 }
 
-func getTokenPublicKey() *rsa.PublicKey {
+func getTokenPublicKeys() []*rsa.PublicKey {
 	keyBytes, err := os.ReadFile(controller.TokenPublicKeyPath)
 	if err != nil {
 		klog.Fatalf("Error reading apiserver public key")
 	}
 
-	key, err := controller.DecodePublicKey(keyBytes)
-	if err != nil {
-		klog.Fatalf("Error decoding public key")
+	// The public key of the apiserver signing key, followed by the public keys of the previous signing keys
+	publicKeys, err := keys.DecodePublicKeys(keyBytes)
+	if err != nil || len(publicKeys) == 0 {
+		klog.Fatalf("Error decoding public keys")
 	}
 
-	return key
+	return publicKeys
 }
 
 func getTokenPrivateKey() *rsa.PrivateKey {
@@ -353,9 +355,10 @@ func getTokenPrivateKey() *rsa.PrivateKey {
 }
 
 func registerMetrics() {
-	metrics.Registry.MustRegister(controller.IncompleteProfileGauge)
+	if err := controller.RegisterMetrics(metrics.Registry); err != nil {
+		klog.Fatalf("Unable to register metrics: %v\n", errors.WithStack(err))
+	}
 	controller.IncompleteProfileGauge.Set(-1)
-	metrics.Registry.MustRegister(controller.DataImportCronOutdatedGauge)
 }
 
 // Restricts some types in the cache's ListWatch to specific fields/labels per GVK at the specified object,
@@ -11,11 +11,19 @@ package main
 //    ImporterAccessKeyID  Optional. Access key is the user ID that uniquely identifies your
 //			      account.
 //    ImporterSecretKey     Optional. Secret key is the password to your account.
+//    ImporterBearerToken   Optional. Bearer token authenticating the requests of an http source,
+//			      instead of the access and secret keys.
+//    ImporterClientCertDir Optional. Directory of the tls.crt and tls.key of the client certificate
+//			      presented to an https source.
+// Run with -source=stdin, outside of a pod, it imports the data piped to it into the disk image of -dest:
+//    cat image.qcow2.gz | cdi-importer -source=stdin -dest=/out/disk.img
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -35,9 +43,24 @@ import (
 	prometheusutil "kubevirt.io/containerized-data-importer/pkg/util/prometheus"
 )
 
+// sourceStdin is the source of the data piped to the importer, run outside of a pod
+const sourceStdin = "stdin"
+
+var qemuOperations = image.NewQEMUOperations()
+
+var (
+	sourceFlag    string
+	destFlag      string
+	imageSizeFlag string
+	checksumFlag  string
+)
+
 func init() {
+	flag.StringVar(&sourceFlag, "source", "", "type of the source, overriding "+common.ImporterSource+", "+sourceStdin+" imports the data piped to the importer")
+	flag.StringVar(&destFlag, "dest", "", "file or block device the disk image of the "+sourceStdin+" source is written to")
+	flag.StringVar(&imageSizeFlag, "image-size", "", "size of the disk image, overriding "+common.ImporterImageSize)
+	flag.StringVar(&checksumFlag, "checksum", "", "<algorithm>:<hex digest> checksum of the data of the source, overriding "+common.ImporterChecksum)
 	klog.InitFlags(nil)
-	flag.Parse()
 }
 
 func waitForReadyFile() {
@@ -92,8 +115,34 @@ func touchDoneFile() {
 }
 
 func main() {
+	flag.Parse()
 	defer klog.Flush()
 
+	source, _ := util.ParseEnvVar(common.ImporterSource, false)
+	if sourceFlag != "" {
+		source = sourceFlag
+	}
+	imageSize, _ := util.ParseEnvVar(common.ImporterImageSize, false)
+	if imageSizeFlag != "" {
+		imageSize = imageSizeFlag
+	}
+	if err := validateFlags(source, destFlag, imageSizeFlag, checksumFlag); err != nil {
+		klog.Errorf("%+v", err)
+		klog.Flush()
+		os.Exit(1)
+	}
+	if checksumFlag != "" {
+		// the checksum is read from the environment by the format readers
+		os.Setenv(common.ImporterChecksum, checksumFlag)
+	}
+	if source == sourceStdin {
+		if exitCode := handleStdinImport(destFlag, imageSize); exitCode != 0 {
+			klog.Flush()
+			os.Exit(exitCode)
+		}
+		return
+	}
+
 	certsDirectory, err := os.MkdirTemp("", "certsdir")
 	if err != nil {
 		panic(err)
@@ -102,9 +151,7 @@ func main() {
 	prometheusutil.StartPrometheusEndpoint(certsDirectory)
 	klog.V(1).Infoln("Starting importer")
 
-	source, _ := util.ParseEnvVar(common.ImporterSource, false)
 	contentType, _ := util.ParseEnvVar(common.ImporterContentType, false)
-	imageSize, _ := util.ParseEnvVar(common.ImporterImageSize, false)
 	filesystemOverhead, _ := strconv.ParseFloat(os.Getenv(common.FilesystemOverheadVar), 64)
 	preallocation, err := strconv.ParseBool(os.Getenv(common.Preallocation))
 
@@ -147,16 +194,19 @@ func main() {
 }
 
 func handleEmptyImage(contentType string, imageSize string, availableDestSpace int64, preallocation bool, volumeMode v1.PersistentVolumeMode, filesystemOverhead float64) error {
-	var preallocationApplied bool
+	preallocationApplied := image.PreallocationNone
 
 	if contentType == string(cdiv1.DataVolumeKubeVirt) {
-		createBlankImage(imageSize, availableDestSpace, preallocation, volumeMode, filesystemOverhead)
-		preallocationApplied = preallocation
+		preallocationApplied = createBlankImage(imageSize, availableDestSpace, preallocationMode(preallocation), volumeMode, filesystemOverhead)
+		if fs, _ := util.ParseEnvVar(common.ImporterBlankFilesystem, false); fs != "" {
+			label, _ := util.ParseEnvVar(common.ImporterBlankFilesystemLabel, false)
+			createBlankFilesystem(image.Filesystem(fs), label, volumeMode)
+		}
 	} else {
 		errorEmptyDiskWithContentTypeArchive()
 	}
 
-	err := importCompleteTerminationMessage(preallocationApplied)
+	err := importCompleteTerminationMessage(preallocationApplied, "", "", "", "", image.Qcow2Options{}, importer.Digests{}, importer.SourceValidators{}, 0, 0)
 	return err
 }
 
@@ -172,27 +222,67 @@ func handleImport(
 	ds := newDataSource(source, contentType, volumeMode)
 	defer ds.Close()
 
-	processor := newDataProcessor(contentType, volumeMode, ds, imageSize, filesystemOverhead, preallocation)
-	err := processor.ProcessData()
+	// stop the import on SIGTERM, the pod is being deleted along with the target of the import
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	terminationChannel := importer.GetTerminationChannel()
+	go func() {
+		select {
+		case sig := <-terminationChannel:
+			klog.Infof("Received %v, cancelling the import", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	processor := newDataProcessor(source, contentType, volumeMode, ds, imageSize, filesystemOverhead, preallocation)
+	err := processor.ProcessDataContext(ctx)
 
 	if err != nil {
 		klog.Errorf("%+v", err)
 		if err == importer.ErrRequiresScratchSpace {
 			return common.ScratchSpaceNeededExitCode
 		}
+		exitCode := importExitCode(err)
+		if errors.Is(err, importer.ErrDecompressedTooLarge) {
+			// report the cause alone, rather than the failed write it interrupted
+			err = importer.ErrDecompressedTooLarge
+		}
 		err = util.WriteTerminationMessage(fmt.Sprintf("Unable to process data: %v", err.Error()))
 		if err != nil {
 			klog.Errorf("%+v", err)
 		}
 
-		return 1
+		return exitCode
 	}
 	touchDoneFile()
 	// due to the way some data sources can add additional information to termination message
 	// after finished (ds.close() ) termination message has to be written first, before the
 	// the ds is closed
 	// TODO: think about making communication explicit, probably DS interface should be extended
-	err = importCompleteTerminationMessage(processor.PreallocationApplied())
+	var digests importer.Digests
+	if s, ok := ds.(importer.DigestDataSource); ok {
+		digests = s.Digests()
+	}
+	digests.Destination = processor.DestinationDigest()
+	var sourceFormat string
+	if s, ok := ds.(importer.SourceFormatDataSource); ok {
+		sourceFormat = s.SourceFormat()
+	}
+	var sourceURL string
+	if s, ok := ds.(importer.SourceURLDataSource); ok {
+		sourceURL = s.SourceURL()
+	}
+	var signer string
+	if s, ok := ds.(importer.SignedDataSource); ok {
+		signer = s.Signer()
+	}
+	var validators importer.SourceValidators
+	if s, ok := ds.(importer.ValidatedDataSource); ok {
+		validators = s.SourceValidators()
+	}
+	logicalBytes, physicalBytes := processor.BytesWritten()
+	err = importCompleteTerminationMessage(processor.PreallocationModeApplied(), processor.DiskFormat(), sourceFormat, sourceURL, signer, processor.Qcow2OptionsApplied(), digests, validators, logicalBytes, physicalBytes)
 	if err != nil {
 		klog.Errorf("%+v", err)
 		return 1
@@ -201,11 +291,128 @@ func handleImport(
 	return 0
 }
 
-func importCompleteTerminationMessage(preallocationApplied bool) error {
-	message := "Import Complete"
-	if preallocationApplied {
-		message += ", " + common.PreallocationApplied
+// importExitCode returns the exit code of a failed import, telling the controller why it failed.
+func importExitCode(err error) int {
+	switch {
+	case errors.Is(err, importer.ErrInvalidSignature):
+		return common.InvalidSignatureExitCode
+	case errors.Is(err, importer.ErrChecksumMismatch):
+		return common.ChecksumMismatchExitCode
+	case errors.Is(err, importer.ErrInvalidClientCertificate):
+		return common.InvalidClientCertificateExitCode
+	case errors.Is(err, importer.ErrSourceNotFound):
+		return common.SourceNotFoundExitCode
+	case errors.Is(err, image.ErrCorruptImage):
+		return common.CorruptImageExitCode
+	case errors.Is(err, image.ErrInvalidEncryptionKey):
+		return common.InvalidEncryptionKeyExitCode
+	case errors.Is(err, image.ErrUnsupportedFormat):
+		return common.UnsupportedFormatExitCode
+	}
+	return 1
+}
+
+// validateFlags validates the flags of the importer, the -dest flag is required by the stdin source
+// and only used by it.
+func validateFlags(source, dest, imageSize, checksum string) error {
+	if source == sourceStdin && dest == "" {
+		return errors.Errorf("the -dest flag is required by the %s source", sourceStdin)
+	}
+	if source != sourceStdin && dest != "" {
+		return errors.Errorf("the -dest flag is only used by the %s source", sourceStdin)
+	}
+	if imageSize != "" {
+		if _, err := resource.ParseQuantity(imageSize); err != nil {
+			return errors.Wrapf(err, "invalid image size %q", imageSize)
+		}
+	}
+	if checksum != "" {
+		if _, err := util.ParseChecksum(checksum); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// stdinVolumeMode returns the volume mode of dest, the disk image of the stdin source is written to a
+// new file or to a block device.
+func stdinVolumeMode(dest string) (v1.PersistentVolumeMode, error) {
+	info, err := os.Stat(dest)
+	if os.IsNotExist(err) {
+		return v1.PersistentVolumeFilesystem, nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "could not stat %s", dest)
+	}
+	if info.Mode()&os.ModeDevice == 0 || info.Mode()&os.ModeCharDevice != 0 {
+		return "", errors.Errorf("the disk image is written to a new file or to a block device, %s exists and is not a block device", dest)
+	}
+	return v1.PersistentVolumeBlock, nil
+}
+
+// handleStdinImport imports the data piped to the importer into dest, a file or a block device,
+// outside of a pod. As the name of the data is unknown, its format is detected from its headers
+// only, then it is decompressed, converted and verified like the data of any source. The result of
+// the import is printed rather than written to the termination message.
+func handleStdinImport(dest, imageSize string) int {
+	volumeMode, err := stdinVolumeMode(dest)
+	if err != nil {
+		klog.Errorf("%+v", err)
+		return 1
+	}
+
+	// the data processor cleans its data and scratch directories, it is given empty ones rather than
+	// the directory of dest, the data directory on the filesystem of dest to measure its free space
+	dataParent := filepath.Dir(dest)
+	if volumeMode == v1.PersistentVolumeBlock {
+		dataParent = ""
+	}
+	dataDir, err := os.MkdirTemp(dataParent, ".cdi-importer-data-")
+	if err != nil {
+		klog.Errorf("%+v", err)
+		return 1
+	}
+	defer os.RemoveAll(dataDir)
+	scratchDir, err := os.MkdirTemp("", "cdi-importer-scratch-")
+	if err != nil {
+		klog.Errorf("%+v", err)
+		return 1
+	}
+	defer os.RemoveAll(scratchDir)
+
+	ds := importer.NewUploadDataSource(os.Stdin, cdiv1.DataVolumeKubeVirt)
+	defer ds.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	terminationChannel := importer.GetTerminationChannel()
+	go func() {
+		select {
+		case sig := <-terminationChannel:
+			klog.Infof("Received %v, cancelling the import", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	processor := newDataProcessorAt(sourceStdin, string(cdiv1.DataVolumeKubeVirt), volumeMode, ds, dest, dataDir, scratchDir, imageSize, 0, false)
+	if err := processor.ProcessDataContext(ctx); err != nil {
+		klog.Errorf("%+v", err)
+		return importExitCode(err)
+	}
+	if err := syncFile(dest); err != nil {
+		klog.Errorf("%+v", err)
+		return 1
+	}
+	digests := ds.Digests()
+	digests.Destination = processor.DestinationDigest()
+	logicalBytes, physicalBytes := processor.BytesWritten()
+	fmt.Println(importCompleteMessage(processor.PreallocationModeApplied(), processor.DiskFormat(), ds.SourceFormat(), "", "", processor.Qcow2OptionsApplied(), digests, importer.SourceValidators{}, logicalBytes, physicalBytes))
+	return 0
+}
+
+func importCompleteTerminationMessage(preallocationApplied image.PreallocationMode, diskFormat, sourceFormat, sourceURL, signer string, qcow2Options image.Qcow2Options, digests importer.Digests, validators importer.SourceValidators, logicalBytes, physicalBytes int64) error {
+	message := importCompleteMessage(preallocationApplied, diskFormat, sourceFormat, sourceURL, signer, qcow2Options, digests, validators, logicalBytes, physicalBytes)
 	err := util.WriteTerminationMessage(message)
 	if err != nil {
 		return err
@@ -215,12 +422,111 @@ func importCompleteTerminationMessage(preallocationApplied bool) error {
 	return nil
 }
 
-func newDataProcessor(contentType string, volumeMode v1.PersistentVolumeMode, ds importer.DataSourceInterface, imageSize string, filesystemOverhead float64, preallocation bool) *importer.DataProcessor {
+// importCompleteMessage returns the message of a complete import, parsed by the controller.
+func importCompleteMessage(preallocationApplied image.PreallocationMode, diskFormat, sourceFormat, sourceURL, signer string, qcow2Options image.Qcow2Options, digests importer.Digests, validators importer.SourceValidators, logicalBytes, physicalBytes int64) string {
+	message := "Import Complete"
+	if preallocationApplied != image.PreallocationNone {
+		message += ", " + common.PreallocationApplied
+		message += ", " + common.PreallocationModeApplied + " " + string(preallocationApplied)
+	}
+	if diskFormat != "" && diskFormat != image.QemuFormatRaw {
+		message += ", " + common.DiskFormat + " " + diskFormat
+	}
+	if sourceFormat != "" {
+		message += ", " + common.SourceFormat + " " + sourceFormat
+	}
+	if sourceURL != "" {
+		message += ", " + common.SourceURL + " " + sourceURL
+	}
+	if signer != "" {
+		message += ", " + common.SignedBy + " " + signer
+	}
+	if options := qcow2Options.String(); options != "" {
+		message += ", " + common.Qcow2Options + " " + options
+	}
+	if digests.Source != "" {
+		message += ", " + common.SourceDigest + " " + digests.Source
+	}
+	if digests.Payload != "" {
+		message += ", " + common.PayloadDigest + " " + digests.Payload
+	}
+	if digests.Destination != "" {
+		message += ", " + common.DestinationDigest + " " + digests.Destination
+	}
+	message += sourceValidatorsMessage(validators)
+	if logicalBytes > 0 {
+		message += fmt.Sprintf(", %s %d, %s %d", common.LogicalBytes, logicalBytes, common.PhysicalBytes, physicalBytes)
+	}
+	if milestones := importer.ReachedMilestones(); len(milestones) > 0 {
+		message += ", " + common.ImportMilestones + " " + strings.Join(milestones, ",")
+	}
+	return message
+}
+
+// sourceValidatorsMessage returns the part of the termination message recording the validators of the
+// data of the source, the time it was last modified in RFC 3339 format since an http date holds a comma.
+func sourceValidatorsMessage(validators importer.SourceValidators) string {
+	var message string
+	if validators.ETag != "" {
+		message += ", " + common.SourceETag + " " + validators.ETag
+	}
+	if !validators.LastModified.IsZero() {
+		message += ", " + common.SourceLastModified + " " + validators.LastModified.Format(time.RFC3339)
+	}
+	return message
+}
+
+// completeUnchangedImport completes the import of a source which did not change since its previous
+// import, keeping the data of the volume, along with the validators of that import.
+func completeUnchangedImport() {
+	klog.Infoln("The source did not change since its previous import, keeping the data of the volume")
+	validators, _ := importer.PreviousSourceValidators()
+	message := "Import Complete, " + common.SourceUnchanged + sourceValidatorsMessage(validators)
+	if err := util.WriteTerminationMessage(message); err != nil {
+		klog.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	touchDoneFile()
+	os.Exit(0)
+}
+
+func newDataProcessor(source string, contentType string, volumeMode v1.PersistentVolumeMode, ds importer.DataSourceInterface, imageSize string, filesystemOverhead float64, preallocation bool) *importer.DataProcessor {
 	dest := getImporterDestPath(contentType, volumeMode)
-	processor := importer.NewDataProcessor(ds, dest, common.ImporterDataDir, common.ScratchDataDir, imageSize, filesystemOverhead, preallocation)
+	return newDataProcessorAt(source, contentType, volumeMode, ds, dest, common.ImporterDataDir, common.ScratchDataDir, imageSize, filesystemOverhead, preallocation)
+}
+
+// newDataProcessorAt returns the data processor writing to dest, with the data and scratch directories passed in.
+func newDataProcessorAt(source string, contentType string, volumeMode v1.PersistentVolumeMode, ds importer.DataSourceInterface, dest, dataDir, scratchDir string, imageSize string, filesystemOverhead float64, preallocation bool) *importer.DataProcessor {
+	processor := importer.NewDataProcessor(ds, dest, dataDir, scratchDir, imageSize, filesystemOverhead, preallocation)
+	keyFile, _ := util.ParseEnvVar(common.ImporterEncryptionKeyFile, false)
+	processor.SetEncryptionKeyFile(keyFile)
+	processor.SetPreallocationMode(preallocationMode(preallocation))
+	preserveImageSize, _ := strconv.ParseBool(os.Getenv(common.ImporterPreserveImageSize))
+	processor.SetPreserveImageSize(preserveImageSize)
+	skipImageCheck, _ := strconv.ParseBool(os.Getenv(common.ImporterSkipImageCheck))
+	processor.SetSkipImageCheck(skipImageCheck)
+	if diskFormat, _ := util.ParseEnvVar(common.ImporterDiskFormat, false); diskFormat == image.QemuFormatQcow2 {
+		if canWriteQcow2(source, contentType, volumeMode) {
+			compress, _ := strconv.ParseBool(os.Getenv(common.ImporterCompressQcow2))
+			clusterSize, _ := util.ParseEnvVar(common.ImporterQcow2ClusterSize, false)
+			compat, _ := util.ParseEnvVar(common.ImporterQcow2Compat, false)
+			processor.SetDiskFormat(image.QemuFormatQcow2, image.Qcow2Options{Compress: compress, ClusterSize: clusterSize, Compat: compat})
+		} else {
+			klog.Warningf("Cannot write a qcow2 disk image to a %s volume from source %s, writing a raw one\n", volumeMode, source)
+		}
+	}
 	return processor
 }
 
+// canWriteQcow2 returns true if a qcow2 disk image can be written: to a filesystem volume, from the
+// sources whose data is converted by qemu-img.
+func canWriteQcow2(source, contentType string, volumeMode v1.PersistentVolumeMode) bool {
+	if contentType != string(cdiv1.DataVolumeKubeVirt) || volumeMode != v1.PersistentVolumeFilesystem {
+		return false
+	}
+	return source == cc.SourceHTTP || source == cc.SourceS3 || source == cc.SourceGCS || source == cc.SourceSFTP || source == cc.SourceGlance || source == cc.SourceFile || source == cc.SourceNFS || source == cc.SourceSMB || source == cc.SourceRegistry || source == sourceStdin
+}
+
 func getImporterDestPath(contentType string, volumeMode v1.PersistentVolumeMode) string {
 	dest := common.ImporterWritePath
 
@@ -240,6 +546,7 @@ func newDataSource(source string, contentType string, volumeMode v1.PersistentVo
 	sec, _ := util.ParseEnvVar(common.ImporterSecretKey, false)
 	diskID, _ := util.ParseEnvVar(common.ImporterDiskID, false)
 	uuid, _ := util.ParseEnvVar(common.ImporterUUID, false)
+	moref, _ := util.ParseEnvVar(common.ImporterMoref, false)
 	backingFile, _ := util.ParseEnvVar(common.ImporterBackingFile, false)
 	certDir, _ := util.ParseEnvVar(common.ImporterCertDirVar, false)
 	insecureTLS, _ := strconv.ParseBool(os.Getenv(common.InsecureTLSVar))
@@ -251,6 +558,13 @@ func newDataSource(source string, contentType string, volumeMode v1.PersistentVo
 
 	switch source {
 	case cc.SourceHTTP:
+		if importer.IsFTPEndpoint(ep) {
+			ds, err := importer.NewFTPDataSource(ep, acc, sec, certDir)
+			if err != nil {
+				errorCannotConnectDataSource(err, "ftp")
+			}
+			return ds
+		}
 		ds, err := importer.NewHTTPDataSource(getHTTPEp(ep), acc, sec, certDir, cdiv1.DataVolumeContentType(contentType))
 		if err != nil {
 			errorCannotConnectDataSource(err, "http")
@@ -266,13 +580,60 @@ func newDataSource(source string, contentType string, volumeMode v1.PersistentVo
 		ds := importer.NewRegistryDataSource(ep, acc, sec, certDir, insecureTLS)
 		return ds
 	case cc.SourceS3:
-		ds, err := importer.NewS3DataSource(ep, acc, sec, certDir)
+		endpoint, _ := util.ParseEnvVar(common.ImporterS3Endpoint, false)
+		region, _ := util.ParseEnvVar(common.ImporterS3Region, false)
+		addressingStyle, _ := util.ParseEnvVar(common.ImporterS3AddressingStyle, false)
+		ds, err := importer.NewS3DataSource(ep, acc, sec, certDir, importer.S3Options{Endpoint: endpoint, Region: region, AddressingStyle: addressingStyle})
 		if err != nil {
 			errorCannotConnectDataSource(err, "s3")
 		}
 		return ds
+	case cc.SourceGCS:
+		serviceAccount, _ := util.ParseEnvVar(common.ImporterGCSServiceAccount, false)
+		ds, err := importer.NewGCSDataSource(ep, serviceAccount, certDir)
+		if err != nil {
+			errorCannotConnectDataSource(err, "gcs")
+		}
+		return ds
+	case cc.SourceSFTP:
+		secretDir, _ := util.ParseEnvVar(common.ImporterSFTPSecretDirVar, false)
+		ds, err := importer.NewSFTPDataSource(ep, secretDir)
+		if err != nil {
+			errorCannotConnectDataSource(err, "sftp")
+		}
+		return ds
+	case cc.SourceSMB:
+		secretDir, _ := util.ParseEnvVar(common.ImporterSMBSecretDirVar, false)
+		ds, err := importer.NewSMBDataSource(ep, secretDir)
+		if err != nil {
+			errorCannotConnectDataSource(err, "smb")
+		}
+		return ds
+	case cc.SourceGlance:
+		glanceImage, _ := util.ParseEnvVar(common.ImporterGlanceImage, false)
+		project, _ := util.ParseEnvVar(common.ImporterGlanceProject, false)
+		region, _ := util.ParseEnvVar(common.ImporterGlanceRegion, false)
+		ds, err := importer.NewGlanceDataSource(ep, acc, sec, glanceImage, project, region, certDir)
+		if err != nil {
+			errorCannotConnectDataSource(err, "glance")
+		}
+		return ds
+	case cc.SourceFile:
+		dir, _ := util.ParseEnvVar(common.ImporterFileSourceDirVar, false)
+		ds, err := importer.NewFileDataSource(dir, ep, cdiv1.DataVolumeContentType(contentType))
+		if err != nil {
+			errorCannotConnectDataSource(err, "file")
+		}
+		return ds
+	case cc.SourceNFS:
+		dir, _ := util.ParseEnvVar(common.ImporterFileSourceDirVar, false)
+		ds, err := importer.NewNFSDataSource(dir, ep, cdiv1.DataVolumeContentType(contentType))
+		if err != nil {
+			errorCannotConnectDataSource(err, "nfs")
+		}
+		return ds
 	case cc.SourceVDDK:
-		ds, err := importer.NewVDDKDataSource(ep, acc, sec, thumbprint, uuid, backingFile, currentCheckpoint, previousCheckpoint, finalCheckpoint, volumeMode)
+		ds, err := importer.NewVDDKDataSource(ep, acc, sec, thumbprint, uuid, moref, backingFile, currentCheckpoint, previousCheckpoint, finalCheckpoint, volumeMode)
 		if err != nil {
 			errorCannotConnectDataSource(err, "vddk")
 		}
@@ -289,7 +650,19 @@ func newDataSource(source string, contentType string, volumeMode v1.PersistentVo
 	return nil
 }
 
-func createBlankImage(imageSize string, availableDestSpace int64, preallocation bool, volumeMode v1.PersistentVolumeMode, filesystemOverhead float64) {
+// preallocationMode returns the preallocation mode requested, falloc unless it is set, none without preallocation.
+func preallocationMode(preallocation bool) image.PreallocationMode {
+	if !preallocation {
+		return image.PreallocationNone
+	}
+	if mode, _ := util.ParseEnvVar(common.PreallocationMode, false); mode == string(image.PreallocationFull) {
+		return image.PreallocationFull
+	}
+	return image.PreallocationFalloc
+}
+
+// createBlankImage creates the blank image, and returns the preallocation mode applied to it.
+func createBlankImage(imageSize string, availableDestSpace int64, preallocation image.PreallocationMode, volumeMode v1.PersistentVolumeMode, filesystemOverhead float64) image.PreallocationMode {
 	requestImageSizeQuantity := resource.MustParse(imageSize)
 	minSizeQuantity := util.MinQuantity(resource.NewScaledQuantity(availableDestSpace, 0), &requestImageSizeQuantity)
 
@@ -299,12 +672,15 @@ func createBlankImage(imageSize string, availableDestSpace int64, preallocation
 	}
 
 	var err error
+	applied := image.PreallocationNone
 	if volumeMode == v1.PersistentVolumeFilesystem {
 		quantityWithFSOverhead := util.GetUsableSpace(filesystemOverhead, minSizeQuantity.Value())
 		klog.Infof("Space adjusted for filesystem overhead: %d.\n", quantityWithFSOverhead)
-		err = image.CreateBlankImage(common.ImporterWritePath, *resource.NewScaledQuantity(quantityWithFSOverhead, 0), preallocation)
-	} else if volumeMode == v1.PersistentVolumeBlock && preallocation {
+		applied, err = qemuOperations.CreateBlankImage(common.ImporterWritePath, *resource.NewScaledQuantity(quantityWithFSOverhead, 0), preallocation)
+	} else if volumeMode == v1.PersistentVolumeBlock && preallocation != image.PreallocationNone {
 		klog.V(1).Info("Preallocating blank block volume")
+		// zeros are written to the whole block volume
+		applied = image.PreallocationFull
 		err = image.PreallocateBlankBlock(common.WriteBlockPath, minSizeQuantity)
 	}
 
@@ -317,15 +693,52 @@ func createBlankImage(imageSize string, availableDestSpace int64, preallocation
 		}
 		os.Exit(1)
 	}
+	return applied
+}
+
+// createBlankFilesystem creates the filesystem on the blank image, or on the whole block volume
+func createBlankFilesystem(fs image.Filesystem, label string, volumeMode v1.PersistentVolumeMode) {
+	dest := common.ImporterWritePath
+	if volumeMode == v1.PersistentVolumeBlock {
+		dest = common.WriteBlockPath
+	}
+	if err := image.CreateFilesystem(dest, fs, label); err != nil {
+		klog.Errorf("%+v", err)
+		message := fmt.Sprintf("Unable to create filesystem on blank image: %v", err)
+		err = util.WriteTerminationMessage(message)
+		if err != nil {
+			klog.Errorf("%+v", err)
+		}
+		os.Exit(1)
+	}
 }
 
 func errorCannotConnectDataSource(err error, dsName string) {
+	if errors.Is(err, importer.ErrSourceUnchanged) {
+		completeUnchangedImport()
+	}
 	klog.Errorf("%+v", err)
+	exitCode := 1
+	if errors.Is(err, image.ErrUnsupportedFormat) {
+		exitCode = common.UnsupportedFormatExitCode
+	}
+	if errors.Is(err, importer.ErrInvalidClientCertificate) {
+		exitCode = common.InvalidClientCertificateExitCode
+	}
+	if errors.Is(err, importer.ErrInvalidSignature) {
+		exitCode = common.InvalidSignatureExitCode
+	}
+	if errors.Is(err, importer.ErrNoMatchingEntry) {
+		exitCode = common.NoMatchingEntryExitCode
+	}
+	if errors.Is(err, importer.ErrSourceNotFound) {
+		exitCode = common.SourceNotFoundExitCode
+	}
 	err = util.WriteTerminationMessage(fmt.Sprintf("Unable to connect to %s data source: %v", dsName, err))
 	if err != nil {
 		klog.Errorf("%+v", err)
 	}
-	os.Exit(1)
+	os.Exit(exitCode)
 }
 
 func errorEmptyDiskWithContentTypeArchive() {
@@ -338,16 +751,22 @@ func errorEmptyDiskWithContentTypeArchive() {
 }
 
 func fsyncDataFile(contentType string, volumeMode v1.PersistentVolumeMode) {
-	dataFile := getImporterDestPath(contentType, volumeMode)
+	if err := syncFile(getImporterDestPath(contentType, volumeMode)); err != nil {
+		klog.Errorf("%+v", err)
+		os.Exit(1)
+	}
+}
+
+// syncFile commits the data written to a file or a block device to storage.
+func syncFile(dataFile string) error {
 	file, err := os.Open(dataFile)
 	if err != nil {
-		klog.Errorf("could not get file descriptor for fsync call: %+v", err)
-		os.Exit(1)
+		return errors.Wrap(err, "could not get file descriptor for fsync call")
 	}
+	defer file.Close()
 	if err := file.Sync(); err != nil {
-		klog.Errorf("could not fsync following qemu-img writing: %+v", err)
-		os.Exit(1)
+		return errors.Wrap(err, "could not fsync following qemu-img writing")
 	}
 	klog.V(3).Infof("Successfully completed fsync(%s) syscall, commited to disk\n", dataFile)
-	file.Close()
+	return nil
 }
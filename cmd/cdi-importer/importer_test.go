@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/pkg/importer"
+)
+
+const testChecksum = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var _ = Describe("Validate flags", func() {
+	table.DescribeTable("should validate the flags", func(source, dest, imageSize, checksum string, valid bool) {
+		err := validateFlags(source, dest, imageSize, checksum)
+		if valid {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		table.Entry("with no flags", "", "", "", "", true),
+		table.Entry("with the stdin source and a destination", sourceStdin, "/tmp/disk.img", "", "", true),
+		table.Entry("with the stdin source and no destination", sourceStdin, "", "", "", false),
+		table.Entry("with a destination and another source", cc.SourceHTTP, "/tmp/disk.img", "", "", false),
+		table.Entry("with a valid image size", sourceStdin, "/tmp/disk.img", "1Gi", "", true),
+		table.Entry("with an invalid image size", sourceStdin, "/tmp/disk.img", "1 gigabyte", "", false),
+		table.Entry("with a valid checksum", cc.SourceHTTP, "", "", testChecksum, true),
+		table.Entry("with an unknown checksum algorithm", cc.SourceHTTP, "", "", "sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709", false),
+		table.Entry("with a checksum without algorithm", cc.SourceHTTP, "", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", false),
+		table.Entry("with a truncated checksum digest", cc.SourceHTTP, "", "", "sha256:e3b0c442", false),
+	)
+})
+
+var _ = Describe("Import exit code", func() {
+	table.DescribeTable("should map the error of the import to its exit code", func(err error, exitCode int) {
+		Expect(importExitCode(err)).To(Equal(exitCode))
+	},
+		table.Entry("with an invalid signature", importer.ErrInvalidSignature, common.InvalidSignatureExitCode),
+		table.Entry("with a checksum mismatch", importer.ErrChecksumMismatch, common.ChecksumMismatchExitCode),
+		table.Entry("with an invalid client certificate", importer.ErrInvalidClientCertificate, common.InvalidClientCertificateExitCode),
+		table.Entry("with a missing source", importer.ErrSourceNotFound, common.SourceNotFoundExitCode),
+		table.Entry("with a corrupt image", image.ErrCorruptImage, common.CorruptImageExitCode),
+		table.Entry("with an invalid encryption key", image.ErrInvalidEncryptionKey, common.InvalidEncryptionKeyExitCode),
+		table.Entry("with an unsupported format", image.ErrUnsupportedFormat, common.UnsupportedFormatExitCode),
+		table.Entry("with a wrapped error", errors.Wrap(importer.ErrChecksumMismatch, "unable to verify the data"), common.ChecksumMismatchExitCode),
+		table.Entry("with any other error", errors.New("unable to read the data"), 1),
+	)
+})
+
+var _ = Describe("Stdin import", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "importer")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	table.DescribeTable("should return the volume mode of the destination", func(dest func(string) string, volumeMode v1.PersistentVolumeMode, valid bool) {
+		mode, err := stdinVolumeMode(dest(tmpDir))
+		if valid {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+		Expect(mode).To(Equal(volumeMode))
+	},
+		table.Entry("with a missing path", func(dir string) string { return filepath.Join(dir, "disk.img") }, v1.PersistentVolumeFilesystem, true),
+		table.Entry("with a regular file", createRegularFile, v1.PersistentVolumeMode(""), false),
+		table.Entry("with a char device", func(string) string { return os.DevNull }, v1.PersistentVolumeMode(""), false),
+		table.Entry("with a directory", func(dir string) string { return dir }, v1.PersistentVolumeMode(""), false),
+	)
+
+	table.DescribeTable("should reject the destination", func(dest func(string) string) {
+		Expect(handleStdinImport(dest(tmpDir), "")).To(Equal(1))
+	},
+		table.Entry("with a regular file", createRegularFile),
+		table.Entry("with a char device", func(string) string { return os.DevNull }),
+		table.Entry("with a directory", func(dir string) string { return dir }),
+	)
+})
+
+var _ = Describe("Data processor", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "importer")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(common.ImporterDiskFormat)
+		os.RemoveAll(tmpDir)
+	})
+
+	table.DescribeTable("should write the disk format requested", func(source, contentType string, volumeMode v1.PersistentVolumeMode, diskFormat, expectedFormat string) {
+		if diskFormat != "" {
+			os.Setenv(common.ImporterDiskFormat, diskFormat)
+		}
+		ds := importer.NewUploadDataSource(io.NopCloser(bytes.NewReader(nil)), cdiv1.DataVolumeContentType(contentType))
+		defer ds.Close()
+		processor := newDataProcessorAt(source, contentType, volumeMode, ds, filepath.Join(tmpDir, "disk.img"), tmpDir, tmpDir, "", 0, false)
+		Expect(processor.DiskFormat()).To(Equal(expectedFormat))
+	},
+		table.Entry("with no disk format", sourceStdin, string(cdiv1.DataVolumeKubeVirt), v1.PersistentVolumeFilesystem, "", image.QemuFormatRaw),
+		table.Entry("with qcow2 from stdin", sourceStdin, string(cdiv1.DataVolumeKubeVirt), v1.PersistentVolumeFilesystem, image.QemuFormatQcow2, image.QemuFormatQcow2),
+		table.Entry("with qcow2 from http", cc.SourceHTTP, string(cdiv1.DataVolumeKubeVirt), v1.PersistentVolumeFilesystem, image.QemuFormatQcow2, image.QemuFormatQcow2),
+		table.Entry("with qcow2 to a block volume", sourceStdin, string(cdiv1.DataVolumeKubeVirt), v1.PersistentVolumeBlock, image.QemuFormatQcow2, image.QemuFormatRaw),
+		table.Entry("with qcow2 of an archive", cc.SourceHTTP, string(cdiv1.DataVolumeArchive), v1.PersistentVolumeFilesystem, image.QemuFormatQcow2, image.QemuFormatRaw),
+		table.Entry("with qcow2 from vddk", cc.SourceVDDK, string(cdiv1.DataVolumeKubeVirt), v1.PersistentVolumeFilesystem, image.QemuFormatQcow2, image.QemuFormatRaw),
+	)
+})
+
+func createRegularFile(dir string) string {
+	path := filepath.Join(dir, "disk.img")
+	Expect(os.WriteFile(path, []byte("data"), 0600)).To(Succeed())
+	return path
+}
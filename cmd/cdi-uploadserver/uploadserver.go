@@ -94,6 +94,7 @@ func main() {
 	}
 	if server.PreallocationApplied() {
 		message += ", " + common.PreallocationApplied
+		message += ", " + common.PreallocationModeApplied + " " + string(server.PreallocationModeApplied())
 	}
 	err = util.WriteTerminationMessage(message)
 	if err != nil {
@@ -39,6 +39,7 @@ bazel run \
     //:bazeldnf -- fetch ${bazeldnf_repos}
 
 cdi_importer="
+e2fsprogs
 libnbd
 libstdc++
 nbdkit-server
@@ -47,6 +48,7 @@ nbdkit-curl-plugin
 nbdkit-xz-filter
 nbdkit-gzip-filter
 qemu-img
+xfsprogs
 "
 
 cdi_importer_extra_x86_64="
@@ -288,6 +288,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"k8s.io/apimachinery/pkg/apis/meta/v1.TypeMeta":                                                schema_pkg_apis_meta_v1_TypeMeta(ref),
 		"k8s.io/apimachinery/pkg/apis/meta/v1.UpdateOptions":                                           schema_pkg_apis_meta_v1_UpdateOptions(ref),
 		"k8s.io/apimachinery/pkg/apis/meta/v1.WatchEvent":                                              schema_pkg_apis_meta_v1_WatchEvent(ref),
+		"kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequest":        schema_pkg_apis_upload_v1beta1_CloneTokenRequest(ref),
+		"kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequestList":    schema_pkg_apis_upload_v1beta1_CloneTokenRequestList(ref),
+		"kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequestSpec":    schema_pkg_apis_upload_v1beta1_CloneTokenRequestSpec(ref),
+		"kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequestStatus":  schema_pkg_apis_upload_v1beta1_CloneTokenRequestStatus(ref),
 		"kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.UploadTokenRequest":       schema_pkg_apis_upload_v1beta1_UploadTokenRequest(ref),
 		"kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.UploadTokenRequestList":   schema_pkg_apis_upload_v1beta1_UploadTokenRequestList(ref),
 		"kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.UploadTokenRequestSpec":   schema_pkg_apis_upload_v1beta1_UploadTokenRequestSpec(ref),
@@ -14276,6 +14280,187 @@ func schema_pkg_apis_meta_v1_WatchEvent(ref common.ReferenceCallback) common.Ope
 	}
 }
 
+func schema_pkg_apis_upload_v1beta1_CloneTokenRequest(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "CloneTokenRequest is the CR used to request a token authorizing a DataVolume of another namespace to clone a PVC or a snapshot of the namespace of the request",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spec contains the parameters of the request",
+							Default:     map[string]interface{}{},
+							Ref:         ref("kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequestSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status contains the status of the request",
+							Default:     map[string]interface{}{},
+							Ref:         ref("kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequestStatus"),
+						},
+					},
+				},
+				Required: []string{"metadata", "spec", "status"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta", "kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequestSpec", "kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequestStatus"},
+	}
+}
+
+func schema_pkg_apis_upload_v1beta1_CloneTokenRequestList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "CloneTokenRequestList contains a list of CloneTokenRequests",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Items contains a list of CloneTokenRequests",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequest"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta", "kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1.CloneTokenRequest"},
+	}
+}
+
+func schema_pkg_apis_upload_v1beta1_CloneTokenRequestSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "CloneTokenRequestSpec defines the parameters of the clone token request",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"sourceName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SourceName is the name of the PVC or of the snapshot to clone",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sourceKind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SourceKind is the kind of the source, PersistentVolumeClaim or VolumeSnapshot, PersistentVolumeClaim by default",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"targetNamespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TargetNamespace is the namespace of the DataVolume cloning the source",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"targetName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TargetName is the name of the DataVolume cloning the source",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"expirationSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpirationSeconds is the lifetime of the token in seconds, 3600 by default and 86400 at most",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+				Required: []string{"sourceName", "targetNamespace", "targetName"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_upload_v1beta1_CloneTokenRequestStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "CloneTokenRequestStatus stores the status of a clone token request",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"token": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Token is a JWT token to be set in the cdi.kubevirt.io/storage.clone.token annotation of the DataVolume",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"expirationTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpirationTimestamp is the time the token expires",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_pkg_apis_upload_v1beta1_UploadTokenRequest(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
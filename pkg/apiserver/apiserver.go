@@ -36,17 +36,21 @@ import (
 
 	restful "github.com/emicklei/go-restful"
 	"github.com/pkg/errors"
+	authv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	aggregatorclient "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
 	k8sspec "k8s.io/kube-openapi/pkg/validation/spec"
 
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	snapclient "github.com/kubernetes-csi/external-snapshotter/client/v6/clientset/versioned"
 	cdiuploadv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1"
 	pkgcdiuploadv1 "kubevirt.io/containerized-data-importer/pkg/apis/upload/v1beta1"
 	"kubevirt.io/containerized-data-importer/pkg/apiserver/webhooks"
 	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned"
+	"kubevirt.io/containerized-data-importer/pkg/clone"
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/keys"
 	"kubevirt.io/containerized-data-importer/pkg/token"
@@ -67,11 +71,18 @@ const (
 
 	cdiValidatePath = "/cdi-validate"
 
+	cdiConfigValidatePath = "/cdiconfig-validate"
+
 	objectTransferValidatePath = "/objecttransfer-validate"
 
 	dataImportCronValidatePath = "/dataimportcron-validate"
 
 	healthzPath = "/healthz"
+
+	// defaultCloneTokenExpirationSeconds is the lifetime of a clone token whose request doesn't set it
+	defaultCloneTokenExpirationSeconds = 3600
+	// maxCloneTokenExpirationSeconds bounds the lifetime of a clone token
+	maxCloneTokenExpirationSeconds = 86400
 )
 
 var uploadTokenVersions = []string{"v1beta1"}
@@ -96,6 +107,7 @@ type cdiAPIApp struct {
 	snapClient       snapclient.Interface
 
 	privateSigningKey *rsa.PrivateKey
+	publicSigningKeys []*rsa.PublicKey
 
 	container *restful.Container
 
@@ -187,6 +199,11 @@ func NewCdiAPIServer(bindAddress string,
 		return nil, errors.Errorf("failed to create CDI validating webhook: %s", err)
 	}
 
+	err = app.createCDIConfigValidatingWebhook()
+	if err != nil {
+		return nil, errors.Errorf("failed to create CDIConfig validating webhook: %s", err)
+	}
+
 	err = app.createObjectTransferValidatingWebhook()
 	if err != nil {
 		return nil, errors.Errorf("failed to create ObjectTransfer validating webhook: %s", err)
@@ -218,6 +235,15 @@ func (app *cdiAPIApp) getKeysAndCerts() error {
 
 	app.privateSigningKey = privateKey
 
+	// The public keys of the previous signing keys are kept in the secret until the tokens they signed expire
+	publicKeys, err := keys.GetPublicKeys(app.client, namespace, APISigningKeySecretName)
+	if err != nil || len(publicKeys) == 0 {
+		klog.Warningf("Unable to get the public signing keys, only accepting the tokens of the signing key: %v", err)
+		publicKeys = []*rsa.PublicKey{&privateKey.PublicKey}
+	}
+
+	app.publicSigningKeys = publicKeys
+
 	app.tokenGenerator = newUploadTokenGenerator(privateKey)
 
 	return nil
@@ -343,6 +369,137 @@ func (app *cdiAPIApp) uploadHandler(request *restful.Request, response *restful.
 
 }
 
+func (app *cdiAPIApp) cloneTokenHandler(request *restful.Request, response *restful.Response) {
+	allowed, reason, err := app.authorizer.Authorize(request)
+
+	if err != nil {
+		klog.Error(err)
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	} else if !allowed {
+		klog.Infof("Rejected Request: %s", reason)
+		response.WriteErrorString(http.StatusUnauthorized, reason)
+		return
+	}
+
+	namespace := request.PathParameter("namespace")
+	defer request.Request.Body.Close()
+	body, err := io.ReadAll(request.Request.Body)
+	if err != nil {
+		klog.Error(err)
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	cloneToken := &cdiuploadv1.CloneTokenRequest{}
+	err = json.Unmarshal(body, cloneToken)
+	if err != nil {
+		klog.Error(err)
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validateCloneTokenRequestSpec(&cloneToken.Spec); err != nil {
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	var resource metav1.GroupVersionResource
+	var cloneAuthFunc clone.UserCloneAuthFunc
+	if cloneToken.Spec.SourceKind == "VolumeSnapshot" {
+		resource = metav1.GroupVersionResource{
+			Group:    snapshotv1.GroupName,
+			Version:  snapshotv1.SchemeGroupVersion.Version,
+			Resource: "volumesnapshots",
+		}
+		cloneAuthFunc = clone.CanUserCloneSnapshot
+		_, err = app.snapClient.SnapshotV1().VolumeSnapshots(namespace).Get(context.TODO(), cloneToken.Spec.SourceName, metav1.GetOptions{})
+	} else {
+		resource = metav1.GroupVersionResource{
+			Group:    "",
+			Version:  "v1",
+			Resource: "persistentvolumeclaims",
+		}
+		cloneAuthFunc = clone.CanUserClonePVC
+		_, err = app.client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), cloneToken.Spec.SourceName, metav1.GetOptions{})
+	}
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			response.WriteErrorString(http.StatusNotFound, fmt.Sprintf("source %s %s/%s not found", resource.Resource, namespace, cloneToken.Spec.SourceName))
+			return
+		}
+		klog.Error(err)
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+
+	// The requester proves the access to the source, the clone is then authorized by the token whoever creates the DataVolume
+	userInfo, err := app.authorizer.UserInfo(request)
+	if err != nil {
+		response.WriteErrorString(http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	allowed, reason, err = cloneAuthFunc(&sarProxy{client: app.client}, namespace, cloneToken.Spec.SourceName, cloneToken.Spec.TargetNamespace, userInfo)
+	if err != nil {
+		klog.Error(err)
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	} else if !allowed {
+		klog.Infof("Rejected Request: %s", reason)
+		response.WriteErrorString(http.StatusUnauthorized, reason)
+		return
+	}
+
+	tokenData := &token.Payload{
+		Operation: token.OperationClone,
+		Name:      cloneToken.Spec.SourceName,
+		Namespace: namespace,
+		Resource:  resource,
+		Params: map[string]string{
+			"targetNamespace": cloneToken.Spec.TargetNamespace,
+			"targetName":      cloneToken.Spec.TargetName,
+		},
+	}
+
+	lifetime := time.Duration(defaultCloneTokenExpirationSeconds) * time.Second
+	if cloneToken.Spec.ExpirationSeconds != nil {
+		lifetime = time.Duration(*cloneToken.Spec.ExpirationSeconds) * time.Second
+	}
+
+	token, err := token.NewGenerator(common.CloneTokenIssuer, app.privateSigningKey, lifetime).Generate(tokenData)
+	if err != nil {
+		klog.Error(err)
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+
+	cloneToken.Status.Token = token
+	cloneToken.Status.ExpirationTimestamp = metav1.NewTime(time.Now().Add(lifetime))
+	response.WriteAsJson(cloneToken)
+}
+
+func validateCloneTokenRequestSpec(spec *cdiuploadv1.CloneTokenRequestSpec) error {
+	if spec.SourceName == "" || spec.TargetNamespace == "" || spec.TargetName == "" {
+		return errors.New("sourceName, targetNamespace and targetName are required")
+	}
+	if spec.SourceKind != "" && spec.SourceKind != "PersistentVolumeClaim" && spec.SourceKind != "VolumeSnapshot" {
+		return errors.Errorf("invalid sourceKind %s, PersistentVolumeClaim or VolumeSnapshot is expected", spec.SourceKind)
+	}
+	if seconds := spec.ExpirationSeconds; seconds != nil && (*seconds <= 0 || *seconds > maxCloneTokenExpirationSeconds) {
+		return errors.Errorf("invalid expirationSeconds %d, between 1 and %d is expected", *seconds, maxCloneTokenExpirationSeconds)
+	}
+	return nil
+}
+
+type sarProxy struct {
+	client kubernetes.Interface
+}
+
+func (p *sarProxy) Create(sar *authv1.SubjectAccessReview) (*authv1.SubjectAccessReview, error) {
+	return p.client.AuthorizationV1().SubjectAccessReviews().Create(context.TODO(), sar, metav1.CreateOptions{})
+}
+
 func uploadTokenAPIGroup() metav1.APIGroup {
 	apiGroup := metav1.APIGroup{
 		Name: uploadTokenGroup,
@@ -376,6 +533,10 @@ func (app *cdiAPIApp) composeUploadTokenAPI() {
 	groupPath := fmt.Sprintf("/apis/%s", uploadTokenGroup)
 	createPath := fmt.Sprintf("/namespaces/{namespace:[a-z0-9][a-z0-9\\-]*}/%s", resource)
 
+	cloneTokenExample := reflect.ValueOf(&cdiuploadv1.CloneTokenRequest{}).Elem().Interface()
+	cloneTokenKind := "CloneTokenRequest"
+	cloneTokenCreatePath := "/namespaces/{namespace:[a-z0-9][a-z0-9\\-]*}/clonetokenrequests"
+
 	app.container = restful.NewContainer()
 
 	var resourcePaths []string
@@ -401,6 +562,20 @@ func (app *cdiAPIApp) composeUploadTokenAPI() {
 			Returns(http.StatusUnauthorized, "Unauthorized", "").
 			Param(uploadTokenWs.PathParameter("namespace", "Object name and auth scope, such as for teams and projects").Required(true)))
 
+		uploadTokenWs.Route(uploadTokenWs.POST(cloneTokenCreatePath).
+			Produces("application/json").
+			Consumes("application/json").
+			Operation("createNamespaced"+cloneTokenKind+"-"+v).
+			To(app.cloneTokenHandler).Reads(cloneTokenExample).Writes(cloneTokenExample).
+			Doc("Create a CloneTokenRequest object.").
+			Returns(http.StatusOK, "OK", cloneTokenExample).
+			Returns(http.StatusCreated, "Created", cloneTokenExample).
+			Returns(http.StatusAccepted, "Accepted", cloneTokenExample).
+			Returns(http.StatusBadRequest, "Bad Request", "").
+			Returns(http.StatusUnauthorized, "Unauthorized", "").
+			Returns(http.StatusNotFound, "Not Found", "").
+			Param(uploadTokenWs.PathParameter("namespace", "Object name and auth scope, such as for teams and projects").Required(true)))
+
 		uploadTokenWs.Route(uploadTokenWs.GET("/").
 			Produces("application/json").Writes(metav1.APIResourceList{}).
 			To(func(request *restful.Request, response *restful.Response) {
@@ -418,6 +593,16 @@ func (app *cdiAPIApp) composeUploadTokenAPI() {
 					Verbs:        []string{"create"},
 					ShortNames:   []string{"utr", "utrs"},
 				})
+				list.APIResources = append(list.APIResources, metav1.APIResource{
+					Name:         "clonetokenrequests",
+					SingularName: "clonetokenrequest",
+					Namespaced:   true,
+					Group:        uploadTokenGroup,
+					Version:      uploadTokenVersion,
+					Kind:         cloneTokenKind,
+					Verbs:        []string{"create"},
+					ShortNames:   []string{"ctr", "ctrs"},
+				})
 				response.WriteAsJson(list)
 			}).
 			Operation("getAPIResources-"+v).
@@ -506,7 +691,7 @@ func (app *cdiAPIApp) createDataVolumeValidatingWebhook() error {
 }
 
 func (app *cdiAPIApp) createDataVolumeMutatingWebhook() error {
-	app.container.ServeMux.Handle(dvMutatePath, webhooks.NewDataVolumeMutatingWebhook(app.client, app.cdiClient, app.privateSigningKey))
+	app.container.ServeMux.Handle(dvMutatePath, webhooks.NewDataVolumeMutatingWebhook(app.client, app.cdiClient, app.privateSigningKey, app.publicSigningKeys))
 	return nil
 }
 
@@ -515,6 +700,11 @@ func (app *cdiAPIApp) createCDIValidatingWebhook() error {
 	return nil
 }
 
+func (app *cdiAPIApp) createCDIConfigValidatingWebhook() error {
+	app.container.ServeMux.Handle(cdiConfigValidatePath, webhooks.NewCDIConfigValidatingWebhook(app.cdiClient))
+	return nil
+}
+
 func (app *cdiAPIApp) createObjectTransferValidatingWebhook() error {
 	app.container.ServeMux.Handle(objectTransferValidatePath, webhooks.NewObjectTransferValidatingWebhook(app.client, app.cdiClient))
 	return nil
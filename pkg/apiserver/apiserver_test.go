@@ -27,21 +27,29 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	restful "github.com/emicklei/go-restful"
+	authentication "k8s.io/api/authentication/v1"
+	authorization "k8s.io/api/authorization/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
 
 	cdiuploadv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/upload/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/keys"
 	"kubevirt.io/containerized-data-importer/pkg/keys/keystest"
+	"kubevirt.io/containerized-data-importer/pkg/token"
+	"kubevirt.io/containerized-data-importer/pkg/util/cert"
 )
 
 type testAuthorizer struct {
@@ -54,6 +62,10 @@ func (a *testAuthorizer) Authorize(req *restful.Request) (bool, string, error) {
 	return a.allowed, a.reason, a.err
 }
 
+func (a *testAuthorizer) UserInfo(req *restful.Request) (authentication.UserInfo, error) {
+	return authentication.UserInfo{Username: "user", Groups: []string{"userGroup"}}, nil
+}
+
 func signingKeySecretGetAction() core.Action {
 	return core.NewGetAction(
 		schema.GroupVersionResource{
@@ -181,6 +193,7 @@ var _ = Describe("API server tests", func() {
 
 		actions := []core.Action{}
 		actions = append(actions, signingKeySecretGetAction())
+		actions = append(actions, signingKeySecretGetAction())
 
 		client := k8sfake.NewSimpleClientset(kubeobjects...)
 
@@ -192,6 +205,28 @@ var _ = Describe("API server tests", func() {
 		Expect(err).ToNot(HaveOccurred())
 
 		checkActions(actions, client.Actions())
+		Expect(app.publicSigningKeys).To(Equal([]*rsa.PublicKey{&signingKey.PublicKey}))
+	})
+
+	It("Should keep the public keys of the previous signing keys", func() {
+		signingKey, err := generateTestKey()
+		Expect(err).ToNot(HaveOccurred())
+		previousKey, err := generateTestKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		signingKeySecret, err := keystest.NewPrivateKeySecret("cdi", APISigningKeySecretName, signingKey)
+		Expect(err).ToNot(HaveOccurred())
+		previousPublicKeyBytes, err := cert.EncodePublicKeyPEM(&previousKey.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+		signingKeySecret.Data[keys.KeyStorePublicKeyFile] = append(signingKeySecret.Data[keys.KeyStorePublicKeyFile], previousPublicKeyBytes...)
+
+		app := &cdiAPIApp{
+			client: k8sfake.NewSimpleClientset(signingKeySecret),
+		}
+
+		err = app.getKeysAndCerts()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(app.publicSigningKeys).To(Equal([]*rsa.PublicKey{&signingKey.PublicKey, &previousKey.PublicKey}))
 	})
 
 	It("Should generate certs and key on first run", func() {
@@ -208,6 +243,7 @@ var _ = Describe("API server tests", func() {
 		actions = append(actions, signingKeySecretGetAction())
 		actions = append(actions, cdiConfigGetAction())
 		actions = append(actions, signingKeySecretCreateAction(app.privateSigningKey))
+		actions = append(actions, signingKeySecretGetAction())
 
 		checkActions(actions, client.Actions())
 	})
@@ -236,6 +272,16 @@ var _ = Describe("API server tests", func() {
 					Verbs:        []string{"create"},
 					ShortNames:   []string{"utr", "utrs"},
 				},
+				{
+					Name:         "clonetokenrequests",
+					SingularName: "clonetokenrequest",
+					Namespaced:   true,
+					Group:        "upload.cdi.kubevirt.io",
+					Version:      version,
+					Kind:         "CloneTokenRequest",
+					Verbs:        []string{"create"},
+					ShortNames:   []string{"ctr", "ctrs"},
+				},
 			},
 		}
 
@@ -399,4 +445,82 @@ var _ = Describe("API server tests", func() {
 			http.StatusOK,
 			true),
 	)
+	table.DescribeTable("Get clone token", func(spec cdiuploadv1.CloneTokenRequestSpec, authorizer CdiAPIAuthorizer, canClone bool, expectedStatus int) {
+		source := &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "source-pvc",
+				Namespace: "source-ns",
+			},
+		}
+		client := k8sfake.NewSimpleClientset(source)
+		client.PrependReactor("create", "subjectaccessreviews", func(action core.Action) (bool, runtime.Object, error) {
+			sar := action.(core.CreateAction).GetObject().(*authorization.SubjectAccessReview)
+			Expect(sar.Spec.User).To(Equal("user"))
+			Expect(sar.Spec.ResourceAttributes.Namespace).To(Equal("source-ns"))
+			sar.Status.Allowed = canClone
+			return true, sar, nil
+		})
+
+		app := &cdiAPIApp{client: client,
+			privateSigningKey: signingKey,
+			authorizer:        authorizer}
+		app.composeUploadTokenAPI()
+
+		serializedRequest, err := json.Marshal(&cdiuploadv1.CloneTokenRequest{Spec: spec})
+		Expect(err).ToNot(HaveOccurred())
+		req, err := http.NewRequest("POST",
+			"/apis/upload.cdi.kubevirt.io/v1beta1/namespaces/source-ns/clonetokenrequests",
+			bytes.NewReader(serializedRequest))
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		app.container.ServeHTTP(rr, req)
+
+		Expect(rr.Code).To(Equal(expectedStatus))
+		if expectedStatus != http.StatusOK {
+			return
+		}
+
+		cloneTokenRequest := &cdiuploadv1.CloneTokenRequest{}
+		err = json.Unmarshal(rr.Body.Bytes(), cloneTokenRequest)
+		Expect(err).ToNot(HaveOccurred())
+		payload, err := token.NewValidator(common.CloneTokenIssuer, &signingKey.PublicKey, 0).Validate(cloneTokenRequest.Status.Token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(payload.Operation).To(Equal(token.OperationClone))
+		Expect(payload.Name).To(Equal("source-pvc"))
+		Expect(payload.Namespace).To(Equal("source-ns"))
+		Expect(payload.Resource.Resource).To(Equal("persistentvolumeclaims"))
+		Expect(payload.Params).To(Equal(map[string]string{"targetNamespace": "target-ns", "targetName": "target-dv"}))
+		lifetime := time.Duration(defaultCloneTokenExpirationSeconds) * time.Second
+		if spec.ExpirationSeconds != nil {
+			lifetime = time.Duration(*spec.ExpirationSeconds) * time.Second
+		}
+		Expect(cloneTokenRequest.Status.ExpirationTimestamp.Time).To(BeTemporally("~", time.Now().Add(lifetime), 5*time.Second))
+	},
+		table.Entry("authoriser not allowed",
+			cdiuploadv1.CloneTokenRequestSpec{SourceName: "source-pvc", TargetNamespace: "target-ns", TargetName: "target-dv"},
+			&testAuthorizer{allowed: false, reason: "bad person"}, true, http.StatusUnauthorized),
+		table.Entry("user may not clone the source",
+			cdiuploadv1.CloneTokenRequestSpec{SourceName: "source-pvc", TargetNamespace: "target-ns", TargetName: "target-dv"},
+			authorizeSuccess, false, http.StatusUnauthorized),
+		table.Entry("source does not exist",
+			cdiuploadv1.CloneTokenRequestSpec{SourceName: "other-pvc", TargetNamespace: "target-ns", TargetName: "target-dv"},
+			authorizeSuccess, true, http.StatusNotFound),
+		table.Entry("target missing",
+			cdiuploadv1.CloneTokenRequestSpec{SourceName: "source-pvc", TargetNamespace: "target-ns"},
+			authorizeSuccess, true, http.StatusBadRequest),
+		table.Entry("invalid source kind",
+			cdiuploadv1.CloneTokenRequestSpec{SourceName: "source-pvc", SourceKind: "Pod", TargetNamespace: "target-ns", TargetName: "target-dv"},
+			authorizeSuccess, true, http.StatusBadRequest),
+		table.Entry("expiration too long",
+			cdiuploadv1.CloneTokenRequestSpec{SourceName: "source-pvc", TargetNamespace: "target-ns", TargetName: "target-dv", ExpirationSeconds: pointer.Int64(maxCloneTokenExpirationSeconds + 1)},
+			authorizeSuccess, true, http.StatusBadRequest),
+		table.Entry("token with the default expiration",
+			cdiuploadv1.CloneTokenRequestSpec{SourceName: "source-pvc", TargetNamespace: "target-ns", TargetName: "target-dv"},
+			authorizeSuccess, true, http.StatusOK),
+		table.Entry("token with an expiration",
+			cdiuploadv1.CloneTokenRequestSpec{SourceName: "source-pvc", SourceKind: "PersistentVolumeClaim", TargetNamespace: "target-ns", TargetName: "target-dv", ExpirationSeconds: pointer.Int64(600)},
+			authorizeSuccess, true, http.StatusOK),
+	)
 })
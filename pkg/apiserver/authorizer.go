@@ -29,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
+	authentication "k8s.io/api/authentication/v1"
 	authorization "k8s.io/api/authorization/v1"
 	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	restclient "k8s.io/client-go/rest"
@@ -45,6 +46,7 @@ const (
 // CdiAPIAuthorizer defines methods to authorize api requests
 type CdiAPIAuthorizer interface {
 	Authorize(req *restful.Request) (bool, string, error)
+	UserInfo(req *restful.Request) (authentication.UserInfo, error)
 }
 
 type authorizor struct {
@@ -83,6 +85,12 @@ var verbMap = map[string]string{
 	"POST": "create",
 }
 
+// the resources of the upload API group
+var resources = map[string]bool{
+	"uploadtokenrequests": true,
+	"clonetokenrequests":  true,
+}
+
 func (a *authorizor) generateAccessReview(req *restful.Request) (*authorization.SubjectAccessReview, error) {
 
 	httpRequest := req.Request
@@ -116,20 +124,11 @@ func (a *authorizor) generateAccessReview(req *restful.Request) (*authorization.
 		return nil, fmt.Errorf("unknown api group %s", group)
 	}
 
-	if resource != "uploadtokenrequests" {
+	if !resources[resource] {
 		return nil, fmt.Errorf("unknown resource type %s", resource)
 	}
 
-	users, err := a.matchHeaders(headers, authConfig.UserHeaders)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(users) == 0 {
-		return nil, fmt.Errorf("no user header found")
-	}
-
-	userGroups, err := a.matchHeaders(headers, authConfig.GroupHeaders)
+	userInfo, err := a.UserInfo(req)
 	if err != nil {
 		return nil, err
 	}
@@ -142,8 +141,8 @@ func (a *authorizor) generateAccessReview(req *restful.Request) (*authorization.
 
 	r := &authorization.SubjectAccessReview{}
 	r.Spec = authorization.SubjectAccessReviewSpec{
-		User:   users[0],
-		Groups: userGroups,
+		User:   userInfo.Username,
+		Groups: userInfo.Groups,
 		Extra:  userExtras,
 	}
 
@@ -158,6 +157,42 @@ func (a *authorizor) generateAccessReview(req *restful.Request) (*authorization.
 	return r, nil
 }
 
+// UserInfo returns the user of a request, as forwarded by the aggregator in the headers of the request
+func (a *authorizor) UserInfo(req *restful.Request) (authentication.UserInfo, error) {
+	if req.Request == nil {
+		return authentication.UserInfo{}, fmt.Errorf("empty http request")
+	}
+	headers := req.Request.Header
+	authConfig := a.authConfigWatcher.GetAuthConfig()
+
+	users, err := a.matchHeaders(headers, authConfig.UserHeaders)
+	if err != nil {
+		return authentication.UserInfo{}, err
+	}
+
+	if len(users) == 0 {
+		return authentication.UserInfo{}, fmt.Errorf("no user header found")
+	}
+
+	userGroups, err := a.matchHeaders(headers, authConfig.GroupHeaders)
+	if err != nil {
+		return authentication.UserInfo{}, err
+	}
+
+	userInfo := authentication.UserInfo{
+		Username: users[0],
+		Groups:   userGroups,
+	}
+	for k, v := range a.getUserExtras(headers, authConfig.ExtraPrefixHeaders) {
+		if userInfo.Extra == nil {
+			userInfo.Extra = map[string]authentication.ExtraValue{}
+		}
+		userInfo.Extra[k] = authentication.ExtraValue(v)
+	}
+
+	return userInfo, nil
+}
+
 func isInfoEndpoint(req *restful.Request) bool {
 
 	httpRequest := req.Request
@@ -30,6 +30,7 @@ import (
 
 	"github.com/emicklei/go-restful"
 
+	authentication "k8s.io/api/authentication/v1"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -103,6 +104,26 @@ var _ = Describe("Authorizer test", func() {
 		Expect(authReview).ToNot(BeNil())
 	})
 
+	It("Generate access review of a clone token request", func() {
+		app := newAuthorizor()
+		req := fakeRequest()
+		req.Request.URL.Path = "/apis/upload.cdi.kubevirt.io/v1beta1/namespaces/source/clonetokenrequests"
+		authReview, err := app.generateAccessReview(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(authReview.Spec.ResourceAttributes.Namespace).To(Equal("source"))
+		Expect(authReview.Spec.ResourceAttributes.Resource).To(Equal("clonetokenrequests"))
+		Expect(authReview.Spec.ResourceAttributes.Verb).To(Equal("create"))
+	})
+
+	It("Get the user of a request", func() {
+		app := newAuthorizor()
+		userInfo, err := app.UserInfo(fakeRequest())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(userInfo.Username).To(Equal("user"))
+		Expect(userInfo.Groups).To(Equal([]string{"userGroup"}))
+		Expect(userInfo.Extra).To(HaveKeyWithValue("test", authentication.ExtraValue{"userExtraValue"}))
+	})
+
 	It("Generate access review path err resource", func() {
 		app := newAuthorizor()
 		req := fakeRequest()
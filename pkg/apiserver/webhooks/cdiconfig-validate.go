@@ -0,0 +1,124 @@
+/*
+ * This file is part of the CDI project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfield "k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+)
+
+// cdiConfigValidatingWebhook keeps a single CDIConfig, named config, and rejects the changes of its spec that the
+// config of the CDI resource would revert, when the CDI resource is the authority of the configuration
+type cdiConfigValidatingWebhook struct {
+	client cdiclient.Interface
+}
+
+func (wh *cdiConfigValidatingWebhook) Admit(ar admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
+	klog.V(3).Infof("Got AdmissionReview %+v", ar)
+
+	if ar.Request.Resource.Group != cdiv1.CDIGroupVersionKind.Group || ar.Request.Resource.Resource != "cdiconfigs" {
+		klog.V(3).Infof("Got unexpected resource type %s", ar.Request.Resource.Resource)
+		return toAdmissionResponseError(fmt.Errorf("unexpected resource: %s", ar.Request.Resource.Resource))
+	}
+
+	config := &cdiv1.CDIConfig{}
+	if err := json.Unmarshal(ar.Request.Object.Raw, config); err != nil {
+		return toAdmissionResponseError(err)
+	}
+
+	switch ar.Request.Operation {
+	case admissionv1.Create:
+		if config.Name != common.ConfigName {
+			causes := []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("CDI is configured by the single CDIConfig %q, %q would be ignored", common.ConfigName, config.Name),
+				Field:   k8sfield.NewPath("metadata", "name").String(),
+			}}
+			klog.Infof("rejected CDIConfig admission %s", causes)
+			return toRejectedAdmissionResponse(causes)
+		}
+	case admissionv1.Update:
+		oldConfig := &cdiv1.CDIConfig{}
+		if err := json.Unmarshal(ar.Request.OldObject.Raw, oldConfig); err != nil {
+			return toAdmissionResponseError(err)
+		}
+		if equality.Semantic.DeepEqual(oldConfig.Spec, config.Spec) {
+			return allowedAdmissionResponse()
+		}
+		cdi, err := wh.getConfigAuthority()
+		if err != nil {
+			return toAdmissionResponseError(err)
+		}
+		if cdi == nil {
+			return allowedAdmissionResponse()
+		}
+		authoritySpec := cdiv1.CDIConfigSpec{}
+		if cdi.Spec.Config != nil {
+			authoritySpec = *cdi.Spec.Config
+		}
+		// the config controller copies the config of the CDI resource to the CDIConfig
+		if !equality.Semantic.DeepEqual(authoritySpec, config.Spec) {
+			causes := []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("the spec of the CDIConfig is set from the spec.config of the CDI resource %q, update it instead", cdi.Name),
+				Field:   k8sfield.NewPath("spec").String(),
+			}}
+			klog.Infof("rejected CDIConfig admission %s", causes)
+			return toRejectedAdmissionResponse(causes)
+		}
+	}
+
+	return allowedAdmissionResponse()
+}
+
+// getConfigAuthority returns the active CDI resource when it is the authority of the configuration, nil otherwise
+func (wh *cdiConfigValidatingWebhook) getConfigAuthority() (*cdiv1.CDI, error) {
+	cdis, err := wh.client.CdiV1beta1().CDIs().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var active []cdiv1.CDI
+	for _, cdi := range cdis.Items {
+		if cdi.Status.Phase != sdkapi.PhaseError {
+			active = append(active, cdi)
+		}
+	}
+	if len(active) != 1 {
+		return nil, nil
+	}
+	if _, ok := active[0].Annotations[cc.AnnConfigAuthority]; !ok {
+		return nil, nil
+	}
+	return &active[0], nil
+}
@@ -0,0 +1,135 @@
+/*
+ * This file is part of the CDI project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package webhooks
+
+import (
+	"encoding/json"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned/fake"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+)
+
+var _ = Describe("CDIConfig Webhook", func() {
+	newConfig := func(name string, spec cdiv1.CDIConfigSpec) *cdiv1.CDIConfig {
+		return &cdiv1.CDIConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       spec,
+		}
+	}
+
+	newCDI := func(config *cdiv1.CDIConfigSpec, authority bool) *cdiv1.CDI {
+		cdi := &cdiv1.CDI{
+			ObjectMeta: metav1.ObjectMeta{Name: "cdi"},
+			Spec:       cdiv1.CDISpec{Config: config},
+			Status: cdiv1.CDIStatus{
+				Status: sdkapi.Status{
+					Phase: sdkapi.PhaseDeployed,
+				},
+			},
+		}
+		if authority {
+			cdi.Annotations = map[string]string{cc.AnnConfigAuthority: ""}
+		}
+		return cdi
+	}
+
+	It("should accept the creation of the config CDIConfig", func() {
+		resp := validateCDIConfigs(admissionv1.Create, newConfig(common.ConfigName, cdiv1.CDIConfigSpec{}), nil)
+		Expect(resp.Allowed).To(BeTrue())
+	})
+
+	It("should reject the creation of another CDIConfig", func() {
+		rejections := testutil.ToFloat64(RejectedAdmissionsCounter.WithLabelValues("cdiconfigs", string(admissionv1.Create)))
+		resp := validateCDIConfigs(admissionv1.Create, newConfig("other", cdiv1.CDIConfigSpec{}), nil)
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Message).To(ContainSubstring("single CDIConfig"))
+		Expect(testutil.ToFloat64(RejectedAdmissionsCounter.WithLabelValues("cdiconfigs", string(admissionv1.Create)))).To(Equal(rejections + 1))
+	})
+
+	It("should accept an update of the spec when the CDI resource is not the authority", func() {
+		old := newConfig(common.ConfigName, cdiv1.CDIConfigSpec{})
+		config := newConfig(common.ConfigName, cdiv1.CDIConfigSpec{FeatureGates: []string{"HonorWaitForFirstConsumer"}})
+		resp := validateCDIConfigs(admissionv1.Update, config, old, newCDI(nil, false))
+		Expect(resp.Allowed).To(BeTrue())
+	})
+
+	It("should accept an update of the status only", func() {
+		limit := resource.MustParse("1000k")
+		old := newConfig(common.ConfigName, cdiv1.CDIConfigSpec{ImportBandwidthLimit: &limit})
+		config := old.DeepCopy()
+		config.Status.ImportBandwidthLimit = &limit
+		resp := validateCDIConfigs(admissionv1.Update, config, old, newCDI(nil, true))
+		Expect(resp.Allowed).To(BeTrue())
+	})
+
+	It("should accept an update of the spec to the config of the CDI resource", func() {
+		limit := resource.MustParse("1000k")
+		old := newConfig(common.ConfigName, cdiv1.CDIConfigSpec{})
+		// the quantity of the CDIConfig is serialized in its canonical form
+		config := newConfig(common.ConfigName, cdiv1.CDIConfigSpec{ImportBandwidthLimit: resource.NewQuantity(1000000, resource.DecimalSI)})
+		resp := validateCDIConfigs(admissionv1.Update, config, old, newCDI(&cdiv1.CDIConfigSpec{ImportBandwidthLimit: &limit}, true))
+		Expect(resp.Allowed).To(BeTrue())
+
+		resp = validateCDIConfigs(admissionv1.Update, old, config, newCDI(nil, true))
+		Expect(resp.Allowed).To(BeTrue())
+	})
+
+	It("should reject an update of the spec the CDI resource would revert", func() {
+		old := newConfig(common.ConfigName, cdiv1.CDIConfigSpec{})
+		config := newConfig(common.ConfigName, cdiv1.CDIConfigSpec{FeatureGates: []string{"HonorWaitForFirstConsumer"}})
+		resp := validateCDIConfigs(admissionv1.Update, config, old, newCDI(nil, true))
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Message).To(ContainSubstring("spec.config of the CDI resource \"cdi\""))
+	})
+})
+
+func validateCDIConfigs(op admissionv1.Operation, config, oldConfig *cdiv1.CDIConfig, cdiObjects ...runtime.Object) *admissionv1.AdmissionResponse {
+	ar := &admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			Operation: op,
+			Resource: metav1.GroupVersionResource{
+				Group:    cdiv1.SchemeGroupVersion.Group,
+				Version:  cdiv1.SchemeGroupVersion.Version,
+				Resource: "cdiconfigs",
+			},
+		},
+	}
+	bytes, _ := json.Marshal(config)
+	ar.Request.Object = runtime.RawExtension{Raw: bytes}
+	if oldConfig != nil {
+		bytes, _ = json.Marshal(oldConfig)
+		ar.Request.OldObject = runtime.RawExtension{Raw: bytes}
+	}
+	client := cdiclient.NewSimpleClientset(cdiObjects...)
+	wh := NewCDIConfigValidatingWebhook(client)
+	return serve(ar, wh)
+}
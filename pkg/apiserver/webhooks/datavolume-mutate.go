@@ -22,6 +22,8 @@ package webhooks
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 
 	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	admissionv1 "k8s.io/api/admission/v1"
@@ -43,6 +45,7 @@ type dataVolumeMutatingWebhook struct {
 	k8sClient      kubernetes.Interface
 	cdiClient      cdiclient.Interface
 	tokenGenerator token.Generator
+	tokenValidator token.Validator
 	proxy          clone.SubjectAccessReviewsProxy
 }
 
@@ -56,6 +59,7 @@ const (
 	noClone cloneType = iota
 	pvcClone
 	snapshotClone
+	fileImport
 )
 
 type cloneSourceHandler struct {
@@ -124,7 +128,7 @@ func (wh *dataVolumeMutatingWebhook) Admit(ar admissionv1.AdmissionReview) *admi
 		if err != nil {
 			return toAdmissionResponseError(err)
 		}
-		if cc.GetDataVolumeTTLSeconds(config) >= 0 {
+		if cc.GetDataVolumeTTLSecondsForDataVolume(config, &dataVolume) >= 0 {
 			if modifiedDataVolume.Annotations == nil {
 				modifiedDataVolume.Annotations = make(map[string]string)
 			}
@@ -165,12 +169,29 @@ func (wh *dataVolumeMutatingWebhook) Admit(ar admissionv1.AdmissionReview) *admi
 		}
 	}
 
+	// A clone token requested by a user allowed to clone the source authorizes the clone of a DataVolume created by
+	// a user who isn't
+	tokenErr := errors.New("clone token missing")
+	if tok, ok := dataVolume.Annotations[cc.AnnCloneToken]; ok {
+		tokenErr = wh.validateCloneToken(tok, cloneSourceHandler, sourceNamespace, targetNamespace, targetName)
+		if tokenErr == nil {
+			klog.V(3).Infof("DataVolume %s/%s has a valid clone token", targetNamespace, targetName)
+			if modified {
+				return toPatchResponse(dataVolume, modifiedDataVolume)
+			}
+			return allowedAdmissionResponse()
+		}
+	}
+
 	ok, reason, err := cloneSourceHandler.cloneAuthFunc(wh.proxy, sourceNamespace, sourceName, targetNamespace, ar.Request.UserInfo)
 	if err != nil {
 		return toAdmissionResponseError(err)
 	}
 
 	if !ok {
+		if _, hasToken := dataVolume.Annotations[cc.AnnCloneToken]; hasToken {
+			reason = fmt.Sprintf("%s, and the clone token is invalid: %v", reason, tokenErr)
+		}
 		causes := []metav1.StatusCause{
 			{
 				Type:    metav1.CauseTypeFieldValueInvalid,
@@ -207,15 +228,39 @@ func (wh *dataVolumeMutatingWebhook) Admit(ar admissionv1.AdmissionReview) *admi
 	return toPatchResponse(dataVolume, modifiedDataVolume)
 }
 
+// validateCloneToken verifies that the clone token is signed by the apiserver, not expired, and authorizes the clone
+// of the source into the target
+func (wh *dataVolumeMutatingWebhook) validateCloneToken(tok string, handler *cloneSourceHandler, sourceNamespace, targetNamespace, targetName string) error {
+	tokenData, err := wh.tokenValidator.Validate(tok)
+	if err != nil {
+		return err
+	}
+
+	if tokenData.Operation != token.OperationClone ||
+		tokenData.Name != handler.sourceName ||
+		tokenData.Namespace != sourceNamespace ||
+		tokenData.Resource.Resource != handler.tokenResource.Resource ||
+		tokenData.Params["targetNamespace"] != targetNamespace ||
+		tokenData.Params["targetName"] != targetName {
+		return errors.New("the token doesn't authorize the clone of the source into the DataVolume")
+	}
+
+	return nil
+}
+
 func newCloneSourceHandler(dataVolume *cdiv1.DataVolume, cdiClient cdiclient.Interface) (*cloneSourceHandler, error) {
 	var pvcSource *cdiv1.DataVolumeSourcePVC
 	var snapshotSource *cdiv1.DataVolumeSourceSnapshot
+	var fileSource *cdiv1.DataVolumeSourceFile
 
 	if dataVolume.Spec.Source != nil {
 		if dataVolume.Spec.Source.PVC != nil {
 			pvcSource = dataVolume.Spec.Source.PVC
 		} else if dataVolume.Spec.Source.Snapshot != nil {
 			snapshotSource = dataVolume.Spec.Source.Snapshot
+		} else if dataVolume.Spec.Source.File != nil && dataVolume.Spec.Source.File.PVC != "" && dataVolume.Spec.Source.File.Namespace != "" {
+			// The file of a PVC of another namespace is imported with the permission to clone it
+			fileSource = dataVolume.Spec.Source.File
 		}
 	} else if dataVolume.Spec.SourceRef != nil && dataVolume.Spec.SourceRef.Kind == cdiv1.DataVolumeDataSource {
 		ns := dataVolume.Namespace
@@ -242,6 +287,14 @@ func newCloneSourceHandler(dataVolume *cdiv1.DataVolume, cdiClient cdiclient.Int
 			sourceName:      pvcSource.Name,
 			sourceNamespace: pvcSource.Namespace,
 		}, nil
+	case fileSource != nil:
+		return &cloneSourceHandler{
+			cloneType:       fileImport,
+			tokenResource:   tokenResourcePvc,
+			cloneAuthFunc:   clone.CanUserClonePVC,
+			sourceName:      fileSource.PVC,
+			sourceNamespace: fileSource.Namespace,
+		}, nil
 	case snapshotSource != nil:
 		return &cloneSourceHandler{
 			cloneType:       snapshotClone,
@@ -43,6 +43,7 @@ import (
 	cdiclientfake "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned/fake"
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+	"kubevirt.io/containerized-data-importer/pkg/token"
 
 	cdicorev1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 )
@@ -223,6 +224,51 @@ var _ = Describe("Mutating DataVolume Webhook", func() {
 			Expect(resp.Patch).To(BeNil())
 		})
 
+		DescribeTable("should verify the clone token of a user who may not clone", func(sourceNamespace, targetName string, lifetime time.Duration, allowed bool) {
+			dataVolume := newPVCDataVolume("testDV", "testNamespace", "test")
+			tokenData := &token.Payload{
+				Operation: token.OperationClone,
+				Name:      "test",
+				Namespace: sourceNamespace,
+				Resource:  tokenResourcePvc,
+				Params: map[string]string{
+					"targetNamespace": "default",
+					"targetName":      targetName,
+				},
+			}
+			tok, err := token.NewGenerator(common.CloneTokenIssuer, key, lifetime).Generate(tokenData)
+			Expect(err).ToNot(HaveOccurred())
+			dataVolume.Annotations = map[string]string{cc.AnnCloneToken: tok}
+			dvBytes, _ := json.Marshal(&dataVolume)
+
+			ar := &admissionv1.AdmissionReview{
+				Request: &admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Resource: metav1.GroupVersionResource{
+						Group:    cdicorev1.SchemeGroupVersion.Group,
+						Version:  cdicorev1.SchemeGroupVersion.Version,
+						Resource: "datavolumes",
+					},
+					Object: runtime.RawExtension{
+						Raw: dvBytes,
+					},
+				},
+			}
+
+			resp := mutateDVsEx(key, ar, false, -1, nil)
+			Expect(resp.Allowed).To(Equal(allowed))
+			// the token is kept
+			Expect(resp.Patch).To(BeNil())
+			if !allowed {
+				Expect(resp.Result.Message).To(ContainSubstring("the clone token is invalid"))
+			}
+		},
+			Entry("succeed with a token of the source and of the DataVolume", "testNamespace", "testDV", time.Hour, true),
+			Entry("fail with a token of another source", "default", "testDV", time.Hour, false),
+			Entry("fail with a token of another DataVolume", "testNamespace", "otherDV", time.Hour, false),
+			Entry("fail with an expired token", "testNamespace", "testDV", -time.Hour, false),
+		)
+
 		It("should reject a clone if the source PVC's namespace doesn't exist", func() {
 			dataVolume := newPVCDataVolume("testDV", "noNamespace", "test")
 			dvBytes, _ := json.Marshal(&dataVolume)
@@ -279,8 +325,70 @@ var _ = Describe("Mutating DataVolume Webhook", func() {
 			Entry("succeed with empty namespace", ""),
 		)
 
-		DescribeTable("should", func(ttl int) {
+		DescribeTable("should authorize the import of the file of a PVC of another namespace with a clone token", func(isAuthorized bool) {
+			dataVolume := newFileDataVolume("testDV", "disk.qcow2", "images", "")
+			dataVolume.Spec.Source.File.Namespace = "testNamespace"
+			dvBytes, _ := json.Marshal(&dataVolume)
+
+			ar := &admissionv1.AdmissionReview{
+				Request: &admissionv1.AdmissionRequest{
+					Resource: metav1.GroupVersionResource{
+						Group:    cdicorev1.SchemeGroupVersion.Group,
+						Version:  cdicorev1.SchemeGroupVersion.Version,
+						Resource: "datavolumes",
+					},
+					Object: runtime.RawExtension{
+						Raw: dvBytes,
+					},
+				},
+			}
+
+			resp := mutateDVs(key, ar, isAuthorized)
+			Expect(resp.Allowed).To(Equal(isAuthorized))
+			if !isAuthorized {
+				Expect(resp.Patch).To(BeNil())
+				return
+			}
+
+			var patchObjs []jsonpatch.Operation
+			err := json.Unmarshal(resp.Patch, &patchObjs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(patchObjs).Should(HaveLen(1))
+			Expect(patchObjs[0].Operation).Should(Equal("add"))
+			Expect(patchObjs[0].Path).Should(Equal("/metadata/annotations"))
+			Expect(patchObjs[0].Value).Should(HaveKey(cc.AnnCloneToken))
+		},
+			Entry("succeed when the user may clone from the namespace", true),
+			Entry("fail when the user may not clone from the namespace", false),
+		)
+
+		It("should not add a clone token to a file import of a PVC of the same namespace", func() {
+			dataVolume := newFileDataVolume("testDV", "disk.qcow2", "images", "")
+			dvBytes, _ := json.Marshal(&dataVolume)
+
+			ar := &admissionv1.AdmissionReview{
+				Request: &admissionv1.AdmissionRequest{
+					Resource: metav1.GroupVersionResource{
+						Group:    cdicorev1.SchemeGroupVersion.Group,
+						Version:  cdicorev1.SchemeGroupVersion.Version,
+						Resource: "datavolumes",
+					},
+					Object: runtime.RawExtension{
+						Raw: dvBytes,
+					},
+				},
+			}
+
+			resp := mutateDVsEx(key, ar, false, -1, nil)
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patch).To(BeNil())
+		})
+
+		DescribeTable("should", func(ttl int, dvTTL string, gc bool) {
 			dataVolume := newHTTPDataVolume("testDV", "http://www.example.com")
+			if dvTTL != "" {
+				dataVolume.Annotations = map[string]string{cc.AnnTTLSecondsAfterCompletion: dvTTL}
+			}
 			dvBytes, _ := json.Marshal(&dataVolume)
 
 			ar := &admissionv1.AdmissionReview{
@@ -300,7 +408,7 @@ var _ = Describe("Mutating DataVolume Webhook", func() {
 			resp := mutateDVsEx(key, ar, true, int32(ttl), nil)
 			Expect(resp.Allowed).To(BeTrue())
 
-			if ttl < 0 {
+			if !gc {
 				Expect(resp.Patch).To(BeNil())
 				return
 			}
@@ -312,6 +420,12 @@ var _ = Describe("Mutating DataVolume Webhook", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(patchObjs).Should(HaveLen(1))
 			Expect(patchObjs[0].Operation).Should(Equal("add"))
+
+			if dvTTL != "" {
+				Expect(patchObjs[0].Path).Should(Equal("/metadata/annotations/cdi.kubevirt.io~1storage.deleteAfterCompletion"))
+				Expect(patchObjs[0].Value).Should(Equal("true"))
+				return
+			}
 			Expect(patchObjs[0].Path).Should(Equal("/metadata/annotations"))
 
 			ann, ok := patchObjs[0].Value.(map[string]interface{})
@@ -320,8 +434,10 @@ var _ = Describe("Mutating DataVolume Webhook", func() {
 			Expect(ok).Should(BeTrue())
 			Expect(val).Should(Equal("true"))
 		},
-			Entry("set GC annotation if TTL is set", 0),
-			Entry("not set GC annotation if TTL is disabled", -1),
+			Entry("set GC annotation if TTL is set", 0, "", true),
+			Entry("not set GC annotation if TTL is disabled", -1, "", false),
+			Entry("set GC annotation if the DataVolume sets its TTL", -1, "3600", true),
+			Entry("not set GC annotation if the DataVolume disables GC", 0, "-1", false),
 		)
 	})
 })
@@ -353,6 +469,6 @@ func mutateDVsEx(key *rsa.PrivateKey, ar *admissionv1.AdmissionReview, isAuthori
 	objs := []runtime.Object{cdiConfig}
 	objs = append(objs, cdiObjects...)
 	cdiClient := cdiclientfake.NewSimpleClientset(objs...)
-	wh := NewDataVolumeMutatingWebhook(client, cdiClient, key)
+	wh := NewDataVolumeMutatingWebhook(client, cdiClient, key, []*rsa.PublicKey{&key.PublicKey})
 	return serve(ar, wh)
 }
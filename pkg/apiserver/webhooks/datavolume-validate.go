@@ -23,14 +23,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	neturl "net/url"
+	"path"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	snapclient "github.com/kubernetes-csi/external-snapshotter/client/v6/clientset/versioned"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
 	admissionv1 "k8s.io/api/admission/v1"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kvalidation "k8s.io/apimachinery/pkg/util/validation"
 	k8sfield "k8s.io/apimachinery/pkg/util/validation/field"
@@ -39,7 +48,11 @@ import (
 
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned"
+	"kubevirt.io/containerized-data-importer/pkg/common"
 	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+	featuregates "kubevirt.io/containerized-data-importer/pkg/feature-gates"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
 type dataVolumeValidatingWebhook struct {
@@ -48,7 +61,9 @@ type dataVolumeValidatingWebhook struct {
 	snapClient snapclient.Interface
 }
 
-func validateSourceURL(sourceURL string) string {
+// validateSourceURL validates an http(s) source URL, an s3://bucket/key one when allowS3 is set, or
+// an ftp(s)://host/path one when allowFTP is set.
+func validateSourceURL(sourceURL string, allowS3, allowFTP bool) string {
 	if sourceURL == "" {
 		return "source URL is empty"
 	}
@@ -56,12 +71,257 @@ func validateSourceURL(sourceURL string) string {
 	if err != nil {
 		return fmt.Sprintf("Invalid source URL: %s", sourceURL)
 	}
+	if allowS3 && url.Scheme == "s3" {
+		if url.Host == "" || strings.Trim(url.Path, "/") == "" {
+			return fmt.Sprintf("Invalid S3 source URL, s3://bucket/key expected: %s", sourceURL)
+		}
+		return ""
+	}
+	if allowFTP && (url.Scheme == "ftp" || url.Scheme == "ftps") {
+		if url.Host == "" || strings.Trim(url.Path, "/") == "" {
+			return fmt.Sprintf("Invalid FTP source URL, %s://host/path expected: %s", url.Scheme, sourceURL)
+		}
+		if url.User != nil {
+			return "Invalid FTP source URL, the user and its password are read from the secret of the source"
+		}
+		return ""
+	}
 	if url.Scheme != "http" && url.Scheme != "https" {
 		return fmt.Sprintf("Invalid source URL scheme: %s", sourceURL)
 	}
 	return ""
 }
 
+// validateMirrors validates the mirrors of an http source, http(s) URLs of the same data as the http(s) URL
+// of the source.
+func validateMirrors(source *cdiv1.DataVolumeSourceHTTP, field *k8sfield.Path) *metav1.StatusCause {
+	if url, err := neturl.Parse(source.URL); err == nil && url.Scheme != "http" && url.Scheme != "https" {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s mirrors are only supported by http(s) sources", field.Child("source").String()),
+			Field:   field.Child("source", "HTTP", "mirrors").String(),
+		}
+	}
+	for i, mirror := range source.Mirrors {
+		err := validateSourceURL(mirror, false, false)
+		if err == "" && strings.ContainsAny(mirror, " \t\n") {
+			err = fmt.Sprintf("Invalid mirror URL, spaces must be escaped: %s", mirror)
+		}
+		if err != "" {
+			return &metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s %s", field.Child("source").String(), err),
+				Field:   field.Child("source", "HTTP", "mirrors").Index(i).String(),
+			}
+		}
+	}
+	return nil
+}
+
+// validateChecksum validates the algorithm and the hex digest of the checksum of an http, S3, GCS, SFTP, SMB or
+// registry source, the digest must be as long as the digests of the algorithm.
+func validateChecksum(source *cdiv1.DataVolumeSource, field *k8sfield.Path) *metav1.StatusCause {
+	var checksum *cdiv1.DataVolumeChecksum
+	var sourceType string
+	switch {
+	case source.HTTP != nil:
+		checksum, sourceType = source.HTTP.Checksum, "HTTP"
+	case source.S3 != nil:
+		checksum, sourceType = source.S3.Checksum, "S3"
+	case source.GCS != nil:
+		checksum, sourceType = source.GCS.Checksum, "GCS"
+	case source.SFTP != nil:
+		checksum, sourceType = source.SFTP.Checksum, "SFTP"
+	case source.SMB != nil:
+		checksum, sourceType = source.SMB.Checksum, "SMB"
+	case source.Registry != nil:
+		checksum, sourceType = source.Registry.Checksum, "Registry"
+	}
+	if checksum == nil {
+		return nil
+	}
+	if _, err := util.NewChecksum(string(checksum.Algorithm), checksum.Value); err != nil {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s %s", field.Child("source").String(), err.Error()),
+			Field:   field.Child("source", sourceType, "checksum").String(),
+		}
+	}
+	return nil
+}
+
+// validateChecksumAnnotation rejects the deprecated checksum annotation that is not an <algorithm>:<hex digest>
+// checksum.
+func validateChecksumAnnotation(annotations map[string]string) []metav1.StatusCause {
+	value, ok := annotations[cc.AnnChecksum]
+	if !ok {
+		return nil
+	}
+	if _, err := util.ParseChecksum(value); err != nil {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid %s: %s", cc.AnnChecksum, err.Error()),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		}}
+	}
+	return nil
+}
+
+// validateRegistryPlatform rejects the platform annotation that is not an <os>/<architecture>[/<variant>] platform, and
+// the platform of a registry source pulled by the node, whose container runtime selects the platform of the node.
+func validateRegistryPlatform(dv *cdiv1.DataVolume) []metav1.StatusCause {
+	value, ok := dv.Annotations[cc.AnnRegistryPlatform]
+	if !ok {
+		return nil
+	}
+	if _, err := util.ParseImagePlatform(value); err != nil {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid %s: %s", cc.AnnRegistryPlatform, err.Error()),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		}}
+	}
+	if source := dv.Spec.Source; source != nil && source.Registry != nil &&
+		source.Registry.PullMethod != nil && *source.Registry.PullMethod == cdiv1.RegistryPullNode {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s is not supported by the %s pull method", cc.AnnRegistryPlatform, cdiv1.RegistryPullNode),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		}}
+	}
+	return nil
+}
+
+// validateBlankFilesystem validates the filesystem created on a blank image and its label.
+func validateBlankFilesystem(blank *cdiv1.DataVolumeBlankImage, field *k8sfield.Path) *metav1.StatusCause {
+	if blank.Filesystem == "" {
+		if blank.FilesystemLabel != "" {
+			return &metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s the label of a blank image requires its filesystem", field.Child("source").String()),
+				Field:   field.Child("source", "Blank", "filesystemLabel").String(),
+			}
+		}
+		return nil
+	}
+	if err := image.ValidateFilesystem(image.Filesystem(blank.Filesystem), blank.FilesystemLabel); err != nil {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s %s", field.Child("source").String(), err.Error()),
+			Field:   field.Child("source", "Blank").String(),
+		}
+	}
+	return nil
+}
+
+// validateSignature validates the detached signature of an http source, downloaded from an http(s) URL or
+// armored in the source, and verified with the public keys of a ConfigMap.
+func validateSignature(signature *cdiv1.DataVolumeSourceSignature, field *k8sfield.Path) *metav1.StatusCause {
+	if signature.KeyConfigMap == "" {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueRequired,
+			Message: fmt.Sprintf("%s the ConfigMap holding the public keys trusted to sign the source is required", field.Child("source").String()),
+			Field:   field.Child("source", "HTTP", "signature", "keyConfigMap").String(),
+		}
+	}
+	if (signature.URL == "") == (signature.Armored == "") {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s either the URL or the armored detached signature of the source is required", field.Child("source").String()),
+			Field:   field.Child("source", "HTTP", "signature").String(),
+		}
+	}
+	if signature.URL != "" {
+		if err := validateSourceURL(signature.URL, false, false); err != "" {
+			return &metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s %s", field.Child("source").String(), err),
+				Field:   field.Child("source", "HTTP", "signature", "url").String(),
+			}
+		}
+		return nil
+	}
+	if block, err := armor.Decode(strings.NewReader(signature.Armored)); err != nil || block.Type != openpgp.SignatureType {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s the signature of the source is not an armored OpenPGP signature", field.Child("source").String()),
+			Field:   field.Child("source", "HTTP", "signature", "armored").String(),
+		}
+	}
+	return nil
+}
+
+// validateGCSURL validates a gs://bucket/object source URL, whose optional fragment is the generation
+// of the object.
+func validateGCSURL(sourceURL string) string {
+	if sourceURL == "" {
+		return "source URL is empty"
+	}
+	url, err := neturl.Parse(sourceURL)
+	if err != nil || url.Scheme != "gs" || url.Host == "" || strings.Trim(url.Path, "/") == "" {
+		return fmt.Sprintf("Invalid GCS source URL, gs://bucket/object expected: %s", sourceURL)
+	}
+	if url.Fragment != "" {
+		if generation, err := strconv.ParseInt(url.Fragment, 10, 64); err != nil || generation <= 0 {
+			return fmt.Sprintf("Invalid generation of GCS source URL, gs://bucket/object#generation expected: %s", sourceURL)
+		}
+	}
+	return ""
+}
+
+// validateSFTPURL validates a sftp://user@host/path source URL, the password of the user is read from
+// the secret of the source.
+func validateSFTPURL(sourceURL string) string {
+	if sourceURL == "" {
+		return "source URL is empty"
+	}
+	url, err := neturl.Parse(sourceURL)
+	if err != nil || url.Scheme != "sftp" || url.User == nil || url.User.Username() == "" || url.Hostname() == "" || strings.Trim(url.Path, "/") == "" {
+		return fmt.Sprintf("Invalid SFTP source URL, sftp://user@host/path expected: %s", sourceURL)
+	}
+	if _, ok := url.User.Password(); ok {
+		return "Invalid SFTP source URL, the password of the user is read from the secret of the source"
+	}
+	return ""
+}
+
+// validateNFSURL validates a nfs://host/path/file source URL, the directory of the file is mounted
+// by the importer pod with the default NFS port.
+func validateNFSURL(sourceURL string) string {
+	if sourceURL == "" {
+		return "source URL is empty"
+	}
+	url, err := neturl.Parse(sourceURL)
+	if err != nil || url.Scheme != "nfs" || url.User != nil || url.Hostname() == "" || url.RawQuery != "" || url.Fragment != "" ||
+		!strings.HasPrefix(url.Path, "/") || strings.HasSuffix(url.Path, "/") || path.Clean(url.Path) != url.Path {
+		return fmt.Sprintf("Invalid NFS source URL, nfs://host/path/file expected: %s", sourceURL)
+	}
+	if url.Port() != "" {
+		return "Invalid NFS source URL, the port of the server cannot be set"
+	}
+	return ""
+}
+
+// validateSMBURL validates a smb://server[:port]/share/path source URL, the credentials of the user are
+// read from the secret of the source.
+func validateSMBURL(sourceURL string) string {
+	if sourceURL == "" {
+		return "source URL is empty"
+	}
+	url, err := neturl.Parse(sourceURL)
+	if err != nil || url.Scheme != "smb" || url.Hostname() == "" || url.RawQuery != "" || url.Fragment != "" {
+		return fmt.Sprintf("Invalid SMB source URL, smb://server/share/path expected: %s", sourceURL)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(url.Path, "/"), "/", 2)
+	if len(parts) < 2 || parts[0] == "" || strings.Trim(parts[1], "/") == "" {
+		return fmt.Sprintf("Invalid SMB source URL, smb://server/share/path expected: %s", sourceURL)
+	}
+	if url.User != nil {
+		return "Invalid SMB source URL, the credentials of the user are read from the secret of the source"
+	}
+	return ""
+}
+
 func validateNameLength(name string, maxLen int) []metav1.StatusCause {
 	var causes []metav1.StatusCause
 	if len(name) > maxLen {
@@ -104,6 +364,13 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *admission
 		return causes
 	}
 
+	if request.Operation == admissionv1.Create && spec.PriorityClassName != "" {
+		if cause := wh.validatePriorityClass(spec.PriorityClassName, field); cause != nil {
+			causes = append(causes, *cause)
+			return causes
+		}
+	}
+
 	if spec.PVC != nil {
 		dataSourceRef = spec.PVC.DataSourceRef
 		dataSource = spec.PVC.DataSource
@@ -195,8 +462,8 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *admission
 		})
 		return causes
 	}
-	// if source types are HTTP, Imageio, S3 or VDDK, check if URL is valid
-	if spec.Source.HTTP != nil || spec.Source.S3 != nil || spec.Source.Imageio != nil || spec.Source.VDDK != nil {
+	// if source types are HTTP, Imageio, S3, VDDK or Glance, check if URL is valid
+	if spec.Source.HTTP != nil || spec.Source.S3 != nil || spec.Source.Imageio != nil || spec.Source.VDDK != nil || spec.Source.Glance != nil {
 		if spec.Source.HTTP != nil {
 			url = spec.Source.HTTP.URL
 			sourceType = field.Child("source", "HTTP", "url").String()
@@ -209,8 +476,11 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *admission
 		} else if spec.Source.VDDK != nil {
 			url = spec.Source.VDDK.URL
 			sourceType = field.Child("source", "VDDK", "url").String()
+		} else if spec.Source.Glance != nil {
+			url = spec.Source.Glance.URL
+			sourceType = field.Child("source", "Glance", "url").String()
 		}
-		err := validateSourceURL(url)
+		err := validateSourceURL(url, spec.Source.S3 != nil, spec.Source.HTTP != nil)
 		if err != "" {
 			causes = append(causes, metav1.StatusCause{
 				Type:    metav1.CauseTypeFieldValueInvalid,
@@ -220,6 +490,102 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *admission
 			return causes
 		}
 	}
+	if spec.Source.HTTP != nil && len(spec.Source.HTTP.Mirrors) > 0 {
+		if cause := validateMirrors(spec.Source.HTTP, field); cause != nil {
+			causes = append(causes, *cause)
+			return causes
+		}
+	}
+	if spec.Source.HTTP != nil && spec.Source.HTTP.Signature != nil {
+		if cause := validateSignature(spec.Source.HTTP.Signature, field); cause != nil {
+			causes = append(causes, *cause)
+			return causes
+		}
+	}
+	if spec.Source.GCS != nil {
+		if err := validateGCSURL(spec.Source.GCS.URL); err != "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s %s", field.Child("source").String(), err),
+				Field:   field.Child("source", "GCS", "url").String(),
+			})
+			return causes
+		}
+	}
+	if spec.Source.SFTP != nil {
+		if err := validateSFTPURL(spec.Source.SFTP.URL); err != "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s %s", field.Child("source").String(), err),
+				Field:   field.Child("source", "SFTP", "url").String(),
+			})
+			return causes
+		}
+		if spec.Source.SFTP.SecretRef == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: fmt.Sprintf("%s the secret holding the credentials and the known hosts of an SFTP source is required", field.Child("source").String()),
+				Field:   field.Child("source", "SFTP", "secretRef").String(),
+			})
+			return causes
+		}
+	}
+	if spec.Source.NFS != nil {
+		if err := validateNFSURL(spec.Source.NFS.URL); err != "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s %s", field.Child("source").String(), err),
+				Field:   field.Child("source", "NFS", "url").String(),
+			})
+			return causes
+		}
+	}
+	if spec.Source.SMB != nil {
+		if err := validateSMBURL(spec.Source.SMB.URL); err != "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s %s", field.Child("source").String(), err),
+				Field:   field.Child("source", "SMB", "url").String(),
+			})
+			return causes
+		}
+		if spec.Source.SMB.SecretRef == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: fmt.Sprintf("%s the secret holding the credentials of the user of an SMB source is required", field.Child("source").String()),
+				Field:   field.Child("source", "SMB", "secretRef").String(),
+			})
+			return causes
+		}
+	}
+	if cause := validateChecksum(spec.Source, field); cause != nil {
+		causes = append(causes, *cause)
+		return causes
+	}
+	if spec.Source.Glance != nil {
+		if spec.Source.Glance.Image == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: fmt.Sprintf("%s the ID or the name of the Glance image is required", field.Child("source").String()),
+				Field:   field.Child("source", "Glance", "image").String(),
+			})
+			return causes
+		}
+		if spec.Source.Glance.SecretRef == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: fmt.Sprintf("%s the secret holding the application credential of a Glance source is required", field.Child("source").String()),
+				Field:   field.Child("source", "Glance", "secretRef").String(),
+			})
+			return causes
+		}
+	}
+	if spec.Source.File != nil {
+		if cause := wh.validateFileSource(spec.Source.File, field); cause != nil {
+			causes = append(causes, *cause)
+			return causes
+		}
+	}
 
 	// Make sure contentType is either empty (kubevirt), or kubevirt or archive
 	if spec.ContentType != "" && string(spec.ContentType) != string(cdiv1.DataVolumeKubeVirt) && string(spec.ContentType) != string(cdiv1.DataVolumeArchive) {
@@ -242,6 +608,13 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *admission
 		return causes
 	}
 
+	if spec.Source.Blank != nil {
+		if cause := validateBlankFilesystem(spec.Source.Blank, field); cause != nil {
+			causes = append(causes, *cause)
+			return causes
+		}
+	}
+
 	if spec.Source.Registry != nil {
 		if spec.ContentType != "" && string(spec.ContentType) != string(cdiv1.DataVolumeKubeVirt) {
 			sourceType = field.Child("contentType").String()
@@ -272,7 +645,7 @@ func (wh *dataVolumeValidatingWebhook) validateDataVolumeSpec(request *admission
 	}
 
 	if spec.Source.VDDK != nil {
-		if spec.Source.VDDK.SecretRef == "" || spec.Source.VDDK.UUID == "" || spec.Source.VDDK.BackingFile == "" || spec.Source.VDDK.Thumbprint == "" {
+		if spec.Source.VDDK.SecretRef == "" || (spec.Source.VDDK.UUID == "" && spec.Source.VDDK.Moref == "") || spec.Source.VDDK.BackingFile == "" || spec.Source.VDDK.Thumbprint == "" {
 			causes = append(causes, metav1.StatusCause{
 				Type:    metav1.CauseTypeFieldValueInvalid,
 				Message: fmt.Sprintf("%s source VDDK is not valid", field.Child("source", "VDDK").String()),
@@ -342,7 +715,7 @@ func validateDataVolumeSourceRegistry(sourceRegistry *cdiv1.DataVolumeSourceRegi
 			return causes
 		}
 		scheme := url.Scheme
-		if scheme != cdiv1.RegistrySchemeDocker && scheme != cdiv1.RegistrySchemeOci {
+		if scheme != cdiv1.RegistrySchemeDocker && scheme != cdiv1.RegistrySchemeOciRegistry && scheme != cdiv1.RegistrySchemeOci {
 			causes = append(causes, metav1.StatusCause{
 				Type:    metav1.CauseTypeFieldValueInvalid,
 				Message: fmt.Sprintf("Illegal registry source URL scheme %s", url),
@@ -379,9 +752,93 @@ func validateDataVolumeSourceRegistry(sourceRegistry *cdiv1.DataVolumeSourceRegi
 		return causes
 	}
 
+	if sourceRegistry.Checksum != nil && importMethod != nil && *importMethod == cdiv1.RegistryPullNode {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("Source registry checksum is not supported with node pull import method"),
+			Field:   field.Child("source", "Registry", "checksum").String(),
+		})
+		return causes
+	}
+
 	return causes
 }
 
+// validateFileSource validates a file source: the path of the file must stay within the PVC or the directory
+// of the node mounted in the importer pod, only a PVC has a namespace, and the directories of the node are only
+// allowed by the HostPathImport feature gate.
+func (wh *dataVolumeValidatingWebhook) validateFileSource(file *cdiv1.DataVolumeSourceFile, field *k8sfield.Path) *metav1.StatusCause {
+	if file.Path == "" || path.IsAbs(file.Path) || path.Clean(file.Path) != file.Path || strings.HasPrefix(file.Path, "../") || file.Path == ".." || file.Path == "." {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s the path of a file source must be a clean path relative to its PVC or directory, within it: %q", field.Child("source").String(), file.Path),
+			Field:   field.Child("source", "File", "path").String(),
+		}
+	}
+	if (file.PVC == "") == (file.HostPath == "") {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s a file source must be either on a PVC or on a directory of the node", field.Child("source").String()),
+			Field:   field.Child("source", "File").String(),
+		}
+	}
+	if file.HostPath == "" {
+		return nil
+	}
+	if file.Namespace != "" {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s the namespace of a file source is the namespace of its PVC, a directory of the node has none", field.Child("source").String()),
+			Field:   field.Child("source", "File", "namespace").String(),
+		}
+	}
+	if !path.IsAbs(file.HostPath) || path.Clean(file.HostPath) != file.HostPath || file.HostPath == "/" {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s the directory of the node of a file source must be a clean absolute path: %q", field.Child("source").String(), file.HostPath),
+			Field:   field.Child("source", "File", "hostPath").String(),
+		}
+	}
+	config, err := wh.cdiClient.CdiV1beta1().CDIConfigs().Get(context.TODO(), common.ConfigName, metav1.GetOptions{})
+	if err != nil {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("could not get the CDIConfig: %v", err),
+			Field:   field.Child("source", "File", "hostPath").String(),
+		}
+	}
+	for _, featureGate := range config.Spec.FeatureGates {
+		if featureGate == featuregates.HostPathImport {
+			return nil
+		}
+	}
+	return &metav1.StatusCause{
+		Type:    metav1.CauseTypeFieldValueNotSupported,
+		Message: fmt.Sprintf("%s importing a file of a directory of the node requires the %s feature gate", field.Child("source").String(), featuregates.HostPathImport),
+		Field:   field.Child("source", "File", "hostPath").String(),
+	}
+}
+
+// validatePriorityClass rejects the priority class of the importer, cloner and uploader pods that does not exist, the
+// pods could not be created
+func (wh *dataVolumeValidatingWebhook) validatePriorityClass(priorityClassName string, field *k8sfield.Path) *metav1.StatusCause {
+	_, err := wh.k8sClient.SchedulingV1().PriorityClasses().Get(context.TODO(), priorityClassName, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if k8serrors.IsNotFound(err) {
+		return &metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueNotFound,
+			Message: fmt.Sprintf("PriorityClass %s not found", priorityClassName),
+			Field:   field.Child("priorityClassName").String(),
+		}
+	}
+	return &metav1.StatusCause{
+		Message: err.Error(),
+		Field:   field.Child("priorityClassName").String(),
+	}
+}
+
 func (wh *dataVolumeValidatingWebhook) validateSourceRef(request *admissionv1.AdmissionRequest, spec *cdiv1.DataVolumeSpec, field *k8sfield.Path, namespace *string) *metav1.StatusCause {
 	if spec.SourceRef.Kind == "" {
 		return &metav1.StatusCause{
@@ -537,8 +994,10 @@ func validateStorageSize(spec *cdiv1.DataVolumeSpec, field *k8sfield.Path) (*met
 
 	// The storage size of a DataVolume can only be empty when two conditios are met:
 	//	1. The 'Storage' spec API is used, which allows for additional logic in CDI.
-	//	2. The 'PVC'/'Snapshot' source or SourceRef is used, so the original size can be extracted from the source.
+	//	2. The 'PVC'/'Snapshot' source or SourceRef is used, so the original size can be extracted from the source,
+	//	   or the 'HTTP' source is used, so the virtual size of the image can be found before importing it.
 	isClone := spec.SourceRef != nil || (spec.Source != nil && spec.Source.PVC != nil) || (spec.Source != nil && spec.Source.Snapshot != nil)
+	isHTTP := spec.Source != nil && spec.Source.HTTP != nil
 	if pvcSize, ok := resources.Requests["storage"]; ok {
 		if pvcSize.IsZero() || pvcSize.Value() < 0 {
 			cause := metav1.StatusCause{
@@ -548,7 +1007,7 @@ func validateStorageSize(spec *cdiv1.DataVolumeSpec, field *k8sfield.Path) (*met
 			}
 			return &cause, false
 		}
-	} else if spec.Storage == nil || !isClone {
+	} else if spec.Storage == nil || !(isClone || isHTTP) {
 		cause := metav1.StatusCause{
 			Type:    metav1.CauseTypeFieldValueInvalid,
 			Message: fmt.Sprintf("%s size is missing", name),
@@ -591,6 +1050,333 @@ func validateExternalPopulation(spec *cdiv1.DataVolumeSpec, field *k8sfield.Path
 	return causes
 }
 
+// validateQcow2Options rejects the qcow2 cluster size and compat level annotations whose values are
+// not allowed, they are passed on to qemu-img by the importer.
+func validateQcow2Options(annotations map[string]string) []metav1.StatusCause {
+	options := image.Qcow2Options{
+		ClusterSize: annotations[cc.AnnQcow2ClusterSize],
+		Compat:      annotations[cc.AnnQcow2Compat],
+	}
+	if err := options.Validate(); err != nil {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: err.Error(),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		}}
+	}
+	return nil
+}
+
+// validateS3AddressingStyle rejects the s3AddressingStyle annotation whose value is not path or virtual.
+func validateS3AddressingStyle(annotations map[string]string) []metav1.StatusCause {
+	style, ok := annotations[cc.AnnS3AddressingStyle]
+	if !ok || style == "path" || style == "virtual" {
+		return nil
+	}
+	return []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: fmt.Sprintf("invalid %s %q, path or virtual is expected", cc.AnnS3AddressingStyle, style),
+		Field:   k8sfield.NewPath("metadata", "annotations").String(),
+	}}
+}
+
+// maxS3Concurrency bounds the number of parts of an S3 object downloaded at once
+const maxS3Concurrency = 64
+
+// validateS3Transfer rejects the s3PartSize annotation that is not a positive quantity, and the
+// s3Concurrency annotation that is not a number of parts from 1 to maxS3Concurrency.
+func validateS3Transfer(annotations map[string]string) []metav1.StatusCause {
+	var message string
+	if value, ok := annotations[cc.AnnS3PartSize]; ok {
+		if partSize, err := resource.ParseQuantity(value); err != nil || partSize.Sign() <= 0 {
+			message = fmt.Sprintf("invalid %s %q, a positive quantity is expected", cc.AnnS3PartSize, value)
+		}
+	}
+	if value, ok := annotations[cc.AnnS3Concurrency]; ok {
+		if concurrency, err := strconv.Atoi(value); err != nil || concurrency < 1 || concurrency > maxS3Concurrency {
+			message = fmt.Sprintf("invalid %s %q, a number from 1 to %d is expected", cc.AnnS3Concurrency, value, maxS3Concurrency)
+		}
+	}
+	if message == "" {
+		return nil
+	}
+	return []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: message,
+		Field:   k8sfield.NewPath("metadata", "annotations").String(),
+	}}
+}
+
+// validateURLPattern rejects the urlPattern annotation whose value is not glob or regex, and the URL of an
+// http source whose last segment is not a valid pattern of that syntax.
+func validateURLPattern(dv *cdiv1.DataVolume) []metav1.StatusCause {
+	syntax, ok := dv.Annotations[cc.AnnURLPattern]
+	if !ok {
+		return nil
+	}
+	field := k8sfield.NewPath("metadata", "annotations").String()
+	if syntax != "glob" && syntax != "regex" {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid %s %q, glob or regex is expected", cc.AnnURLPattern, syntax),
+			Field:   field,
+		}}
+	}
+	if dv.Spec.Source == nil || dv.Spec.Source.HTTP == nil {
+		return nil
+	}
+	u, err := neturl.Parse(dv.Spec.Source.HTTP.URL)
+	if err != nil {
+		// the URL is validated with the source
+		return nil
+	}
+	pattern := u.Path[strings.LastIndex(u.Path, "/")+1:]
+	if syntax == "regex" {
+		_, err = regexp.Compile(pattern)
+	} else {
+		_, err = path.Match(pattern, "")
+	}
+	if pattern == "" || err != nil {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("the URL of the source must end with a valid %s pattern of the names of the files of its directory", syntax),
+			Field:   k8sfield.NewPath("spec", "source", "http", "url").String(),
+		}}
+	}
+	return nil
+}
+
+// maxHTTPConcurrency bounds the number of ranges of an http source downloaded at once
+const maxHTTPConcurrency = 64
+
+// validateHTTPConcurrency rejects the httpConcurrency annotation that is not a number of ranges from
+// 1 to maxHTTPConcurrency.
+func validateHTTPConcurrency(annotations map[string]string) []metav1.StatusCause {
+	value, ok := annotations[cc.AnnHTTPConcurrency]
+	if !ok {
+		return nil
+	}
+	if concurrency, err := strconv.Atoi(value); err == nil && concurrency >= 1 && concurrency <= maxHTTPConcurrency {
+		return nil
+	}
+	return []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: fmt.Sprintf("invalid %s %q, a number from 1 to %d is expected", cc.AnnHTTPConcurrency, value, maxHTTPConcurrency),
+		Field:   k8sfield.NewPath("metadata", "annotations").String(),
+	}}
+}
+
+// validateRedirects rejects the maxRedirects annotation that is not a number of redirects, and the
+// redirectHosts annotation listing a host that is neither a DNS name, optionally prefixed with *.,
+// nor an IP address.
+func validateRedirects(annotations map[string]string) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+	if value, ok := annotations[cc.AnnMaxRedirects]; ok {
+		if max, err := strconv.Atoi(value); err != nil || max < 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid %s %q, a number of redirects is expected", cc.AnnMaxRedirects, value),
+				Field:   k8sfield.NewPath("metadata", "annotations").String(),
+			})
+		}
+	}
+	for _, host := range strings.Fields(annotations[cc.AnnRedirectHosts]) {
+		if net.ParseIP(host) != nil || len(kvalidation.IsDNS1123Subdomain(strings.ToLower(strings.TrimPrefix(host, "*.")))) == 0 {
+			continue
+		}
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid host %q of %s, a DNS name, optionally prefixed with *., or an IP address is expected", host, cc.AnnRedirectHosts),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		})
+	}
+	return causes
+}
+
+// validateImportRetry rejects the maxRetries annotation that is not a number of retries, and the
+// initialBackoff and maxBackoff annotations that are not positive durations.
+func validateImportRetry(annotations map[string]string) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+	if value, ok := annotations[cc.AnnImportMaxRetries]; ok {
+		if max, err := strconv.Atoi(value); err != nil || max < 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid %s %q, a number of retries is expected", cc.AnnImportMaxRetries, value),
+				Field:   k8sfield.NewPath("metadata", "annotations").String(),
+			})
+		}
+	}
+	for _, annotation := range []string{cc.AnnImportInitialBackoff, cc.AnnImportMaxBackoff} {
+		value, ok := annotations[annotation]
+		if !ok {
+			continue
+		}
+		if backoff, err := time.ParseDuration(value); err == nil && backoff > 0 {
+			continue
+		}
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid %s %q, a positive duration such as 30s or 5m is expected", annotation, value),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		})
+	}
+	return causes
+}
+
+// validatePodResources rejects the resource annotations of the importer, cloner and uploader
+// pods that are not positive quantities, or that set a limit below the minimum of these pods.
+func validatePodResources(annotations map[string]string) []metav1.StatusCause {
+	if _, err := cc.ParsePodResourceAnnotations(annotations); err != nil {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: err.Error(),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		}}
+	}
+	return nil
+}
+
+// validatePodPlacement rejects the scheduling annotations of the importer, cloner and uploader pods that are not
+// valid JSON node selector, tolerations or affinity
+func validatePodPlacement(annotations map[string]string) []metav1.StatusCause {
+	if _, err := cc.ParsePodPlacementAnnotations(annotations); err != nil {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: err.Error(),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		}}
+	}
+	return nil
+}
+
+// validateScratchSpace rejects the scratch size multiplier annotation that is not a positive number, and the empty
+// scratch storage class annotation.
+func validateScratchSpace(annotations map[string]string) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+	if value, ok := annotations[cc.AnnScratchSizeMultiplier]; ok {
+		if _, err := cc.ParseScratchSpaceSizeMultiplier(value); err != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid %s %q, a positive number such as 1.5 is expected", cc.AnnScratchSizeMultiplier, value),
+				Field:   k8sfield.NewPath("metadata", "annotations").String(),
+			})
+		}
+	}
+	if value, ok := annotations[cc.AnnScratchStorageClass]; ok && value == "" {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s cannot be empty", cc.AnnScratchStorageClass),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		})
+	}
+	return causes
+}
+
+// validateTTLSecondsAfterCompletion rejects the ttlSecondsAfterCompletion annotation that is neither a number of
+// seconds nor -1.
+func validateTTLSecondsAfterCompletion(annotations map[string]string) []metav1.StatusCause {
+	value, ok := annotations[cc.AnnTTLSecondsAfterCompletion]
+	if !ok {
+		return nil
+	}
+	if _, err := cc.ParseTTLSecondsAfterCompletion(value); err != nil {
+		return []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: err.Error(),
+			Field:   k8sfield.NewPath("metadata", "annotations").String(),
+		}}
+	}
+	return nil
+}
+
+// validateReimport rejects the reimport annotation that is neither IfChanged nor Always.
+func validateReimport(annotations map[string]string) []metav1.StatusCause {
+	value, ok := annotations[cc.AnnReimport]
+	if !ok || value == cc.ReimportIfChanged || value == cc.ReimportAlways {
+		return nil
+	}
+	return []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: fmt.Sprintf("invalid %s %q, %s or %s is expected", cc.AnnReimport, value, cc.ReimportIfChanged, cc.ReimportAlways),
+		Field:   k8sfield.NewPath("metadata", "annotations").String(),
+	}}
+}
+
+// validateBandwidthLimit rejects the bandwidthLimit annotation that is not a positive quantity of
+// bytes per second.
+func validateBandwidthLimit(annotations map[string]string) []metav1.StatusCause {
+	value, ok := annotations[cc.AnnImportBandwidthLimit]
+	if !ok {
+		return nil
+	}
+	if limit, err := resource.ParseQuantity(value); err == nil && limit.Sign() > 0 {
+		return nil
+	}
+	return []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: fmt.Sprintf("invalid %s %q, a positive quantity of bytes per second is expected", cc.AnnImportBandwidthLimit, value),
+		Field:   k8sfield.NewPath("metadata", "annotations").String(),
+	}}
+}
+
+// validateCompressQcow2 rejects the compressQcow2 annotation of a DataVolume writing a raw disk
+// image, or a block volume, whose clusters cannot be compressed.
+func validateCompressQcow2(dv *cdiv1.DataVolume) []metav1.StatusCause {
+	if dv.Annotations[cc.AnnCompressQcow2] != "true" {
+		return nil
+	}
+	var volumeMode *v1.PersistentVolumeMode
+	if dv.Spec.PVC != nil {
+		volumeMode = dv.Spec.PVC.VolumeMode
+	} else if dv.Spec.Storage != nil {
+		volumeMode = dv.Spec.Storage.VolumeMode
+	}
+	var message string
+	switch {
+	case volumeMode != nil && *volumeMode == v1.PersistentVolumeBlock:
+		message = fmt.Sprintf("%s cannot be set for a block volume, only qcow2 disk images written to filesystem volumes are compressed", cc.AnnCompressQcow2)
+	case dv.Spec.DiskFormat == cdiv1.DataVolumeDiskFormatRaw:
+		message = fmt.Sprintf("%s cannot be set for a raw disk image, set the diskFormat to qcow2", cc.AnnCompressQcow2)
+	default:
+		return nil
+	}
+	return []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: message,
+		Field:   k8sfield.NewPath("metadata", "annotations").String(),
+	}}
+}
+
+// dataVolumeValidators validate the name and the annotations of a DataVolume, in order, on admission.
+var dataVolumeValidators = []func(dv *cdiv1.DataVolume) []metav1.StatusCause{
+	func(dv *cdiv1.DataVolume) []metav1.StatusCause {
+		return validateNameLength(dv.Name, kvalidation.DNS1123SubdomainMaxLength)
+	},
+	annotationsValidator(validateQcow2Options),
+	validateCompressQcow2,
+	annotationsValidator(validateS3AddressingStyle),
+	annotationsValidator(validateS3Transfer),
+	annotationsValidator(validateBandwidthLimit),
+	annotationsValidator(validateHTTPConcurrency),
+	validateURLPattern,
+	annotationsValidator(validateRedirects),
+	annotationsValidator(validateReimport),
+	annotationsValidator(validateImportRetry),
+	annotationsValidator(validateChecksumAnnotation),
+	validateRegistryPlatform,
+	annotationsValidator(validatePodResources),
+	annotationsValidator(validatePodPlacement),
+	annotationsValidator(validateScratchSpace),
+	annotationsValidator(validateTTLSecondsAfterCompletion),
+}
+
+// annotationsValidator returns the validator of a DataVolume validating its annotations with validate.
+func annotationsValidator(validate func(map[string]string) []metav1.StatusCause) func(*cdiv1.DataVolume) []metav1.StatusCause {
+	return func(dv *cdiv1.DataVolume) []metav1.StatusCause {
+		return validate(dv.Annotations)
+	}
+}
+
 func (wh *dataVolumeValidatingWebhook) Admit(ar admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
 	if err := validateDataVolumeResource(ar); err != nil {
 		return toAdmissionResponseError(err)
@@ -611,6 +1397,12 @@ func (wh *dataVolumeValidatingWebhook) Admit(ar admissionv1.AdmissionReview) *ad
 			return toAdmissionResponseError(err)
 		}
 
+		// The spec was validated when it was set, the metadata and status updates of the controllers are
+		// admitted even if the spec would no longer pass, e.g. once a feature gate it requires is disabled.
+		if apiequality.Semantic.DeepEqual(dv.Spec, oldDV.Spec) {
+			return &admissionv1.AdmissionResponse{Allowed: true}
+		}
+
 		// Always admit checkpoint updates for multi-stage migrations.
 		multiStageAdmitted := false
 		isMultiStage := dv.Spec.Source != nil && len(dv.Spec.Checkpoints) > 0 &&
@@ -627,7 +1419,7 @@ func (wh *dataVolumeValidatingWebhook) Admit(ar admissionv1.AdmissionReview) *ad
 			multiStageAdmitted = apiequality.Semantic.DeepEqual(newSpec, oldSpec)
 		}
 
-		if !multiStageAdmitted && !apiequality.Semantic.DeepEqual(dv.Spec, oldDV.Spec) {
+		if !multiStageAdmitted {
 			klog.Errorf("Cannot update spec for DataVolume %s/%s", dv.GetNamespace(), dv.GetName())
 			var causes []metav1.StatusCause
 			causes = append(causes, metav1.StatusCause{
@@ -639,10 +1431,11 @@ func (wh *dataVolumeValidatingWebhook) Admit(ar admissionv1.AdmissionReview) *ad
 		}
 	}
 
-	causes := validateNameLength(dv.Name, kvalidation.DNS1123SubdomainMaxLength)
-	if len(causes) > 0 {
-		klog.Infof("rejected DataVolume admission")
-		return toRejectedAdmissionResponse(causes)
+	for _, validate := range dataVolumeValidators {
+		if causes := validate(&dv); len(causes) > 0 {
+			klog.Infof("rejected DataVolume admission %s", causes)
+			return toRejectedAdmissionResponse(causes)
+		}
 	}
 
 	if ar.Request.Operation == admissionv1.Create {
@@ -674,7 +1467,7 @@ func (wh *dataVolumeValidatingWebhook) Admit(ar admissionv1.AdmissionReview) *ad
 		}
 	}
 
-	causes = wh.validateDataVolumeSpec(ar.Request, k8sfield.NewPath("spec"), &dv.Spec, &dv.Namespace)
+	causes := wh.validateDataVolumeSpec(ar.Request, k8sfield.NewPath("spec"), &dv.Spec, &dv.Namespace)
 	if len(causes) > 0 {
 		klog.Infof("rejected DataVolume admission %s", causes)
 		return toRejectedAdmissionResponse(causes)
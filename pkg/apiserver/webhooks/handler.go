@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/appscode/jsonpatch"
+	"github.com/prometheus/client_golang/prometheus"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,9 +39,22 @@ import (
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	cdiclient "kubevirt.io/containerized-data-importer/pkg/client/clientset/versioned"
 	"kubevirt.io/containerized-data-importer/pkg/common"
+	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+	"kubevirt.io/containerized-data-importer/pkg/monitoring"
 	"kubevirt.io/containerized-data-importer/pkg/token"
 )
 
+var (
+	// RejectedAdmissionsCounter counts the requests denied by the webhooks, by resource and operation
+	RejectedAdmissionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: monitoring.MetricOptsList[monitoring.WebhookRejections].Name,
+			Help: monitoring.MetricOptsList[monitoring.WebhookRejections].Help,
+		},
+		[]string{"resource", "operation"},
+	)
+)
+
 // Admitter is the interface implemented by admission webhooks
 type Admitter interface {
 	Admit(admissionv1.AdmissionReview) *admissionv1.AdmissionResponse
@@ -55,10 +69,12 @@ func NewDataVolumeValidatingWebhook(k8sClient kubernetes.Interface, cdiClient cd
 	return newAdmissionHandler(&dataVolumeValidatingWebhook{k8sClient: k8sClient, cdiClient: cdiClient, snapClient: snapClient})
 }
 
-// NewDataVolumeMutatingWebhook creates a new DataVolumeMutation webhook
-func NewDataVolumeMutatingWebhook(k8sClient kubernetes.Interface, cdiClient cdiclient.Interface, key *rsa.PrivateKey) http.Handler {
+// NewDataVolumeMutatingWebhook creates a new DataVolumeMutation webhook, the clone tokens set on the DataVolumes are
+// verified with the public keys
+func NewDataVolumeMutatingWebhook(k8sClient kubernetes.Interface, cdiClient cdiclient.Interface, key *rsa.PrivateKey, publicKeys []*rsa.PublicKey) http.Handler {
 	generator := newCloneTokenGenerator(key)
-	return newAdmissionHandler(&dataVolumeMutatingWebhook{k8sClient: k8sClient, cdiClient: cdiClient, tokenGenerator: generator, proxy: &sarProxy{client: k8sClient}})
+	validator := cc.NewCloneTokenValidator(common.CloneTokenIssuer, publicKeys...)
+	return newAdmissionHandler(&dataVolumeMutatingWebhook{k8sClient: k8sClient, cdiClient: cdiClient, tokenGenerator: generator, tokenValidator: validator, proxy: &sarProxy{client: k8sClient}})
 }
 
 // NewCDIValidatingWebhook creates a new CDI validating webhook
@@ -66,6 +82,11 @@ func NewCDIValidatingWebhook(client cdiclient.Interface) http.Handler {
 	return newAdmissionHandler(&cdiValidatingWebhook{client: client})
 }
 
+// NewCDIConfigValidatingWebhook creates a new CDIConfig validating webhook
+func NewCDIConfigValidatingWebhook(client cdiclient.Interface) http.Handler {
+	return newAdmissionHandler(&cdiConfigValidatingWebhook{client: client})
+}
+
 // NewObjectTransferValidatingWebhook creates a new ObjectTransfer validating webhook
 func NewObjectTransferValidatingWebhook(k8sClient kubernetes.Interface, cdiClient cdiclient.Interface) http.Handler {
 	return newAdmissionHandler(&objectTransferValidatingWebhook{k8sClient: k8sClient, cdiClient: cdiClient})
@@ -122,6 +143,10 @@ func (h *admissionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		} else {
 			// pass to Admitter
 			responseAdmissionReview.Response = h.a.Admit(requestedAdmissionReview)
+			if !responseAdmissionReview.Response.Allowed {
+				request := requestedAdmissionReview.Request
+				RejectedAdmissionsCounter.WithLabelValues(request.Resource.Resource, string(request.Operation)).Inc()
+			}
 		}
 	}
 
@@ -28,6 +28,7 @@ import (
 
 type UploadV1beta1Interface interface {
 	RESTClient() rest.Interface
+	CloneTokenRequestsGetter
 	UploadTokenRequestsGetter
 }
 
@@ -36,6 +37,10 @@ type UploadV1beta1Client struct {
 	restClient rest.Interface
 }
 
+func (c *UploadV1beta1Client) CloneTokenRequests(namespace string) CloneTokenRequestInterface {
+	return newCloneTokenRequests(c, namespace)
+}
+
 func (c *UploadV1beta1Client) UploadTokenRequests(namespace string) UploadTokenRequestInterface {
 	return newUploadTokenRequests(c, namespace)
 }
@@ -93,6 +93,42 @@ const (
 	ImporterAccessKeyID = "IMPORTER_ACCESS_KEY_ID"
 	// ImporterSecretKey provides a constant to capture our env variable "IMPORTER_SECRET_KEY"
 	ImporterSecretKey = "IMPORTER_SECRET_KEY"
+	// ImporterBearerToken provides a constant to capture our env variable "IMPORTER_BEARER_TOKEN"
+	ImporterBearerToken = "IMPORTER_BEARER_TOKEN"
+	// ImporterS3Endpoint provides a constant to capture our env variable "IMPORTER_S3_ENDPOINT"
+	ImporterS3Endpoint = "IMPORTER_S3_ENDPOINT"
+	// ImporterS3Region provides a constant to capture our env variable "IMPORTER_S3_REGION"
+	ImporterS3Region = "IMPORTER_S3_REGION"
+	// ImporterS3AddressingStyle provides a constant to capture our env variable "IMPORTER_S3_ADDRESSING_STYLE"
+	ImporterS3AddressingStyle = "IMPORTER_S3_ADDRESSING_STYLE"
+	// ImporterGCSServiceAccount provides a constant to capture our env variable "IMPORTER_GCS_SERVICE_ACCOUNT"
+	ImporterGCSServiceAccount = "IMPORTER_GCS_SERVICE_ACCOUNT"
+	// ImporterSFTPSecretDirVar provides a constant to capture our env variable "IMPORTER_SFTP_SECRET_DIR"
+	ImporterSFTPSecretDirVar = "IMPORTER_SFTP_SECRET_DIR"
+	// ImporterSMBSecretDirVar provides a constant to capture our env variable "IMPORTER_SMB_SECRET_DIR"
+	ImporterSMBSecretDirVar = "IMPORTER_SMB_SECRET_DIR"
+	// ImporterFileSourceDirVar provides a constant to capture our env variable "IMPORTER_FILE_SOURCE_DIR"
+	ImporterFileSourceDirVar = "IMPORTER_FILE_SOURCE_DIR"
+	// ImporterClientCertDirVar provides a constant to capture our env variable "IMPORTER_CLIENT_CERT_DIR"
+	ImporterClientCertDirVar = "IMPORTER_CLIENT_CERT_DIR"
+	// ImporterRegistryAuthFile provides a constant to capture our env variable "IMPORTER_REGISTRY_AUTH_FILE"
+	ImporterRegistryAuthFile = "IMPORTER_REGISTRY_AUTH_FILE"
+	// ImporterRegistryArtifactMediaType provides a constant to capture our env variable "IMPORTER_REGISTRY_ARTIFACT_MEDIA_TYPE"
+	ImporterRegistryArtifactMediaType = "IMPORTER_REGISTRY_ARTIFACT_MEDIA_TYPE"
+	// ImporterRegistryPlatform provides a constant to capture our env variable "IMPORTER_REGISTRY_PLATFORM", the platform of the image imported from a manifest list
+	ImporterRegistryPlatform = "IMPORTER_REGISTRY_PLATFORM"
+	// ImporterRegistryDefaultPlatform provides a constant to capture our env variable "IMPORTER_REGISTRY_DEFAULT_PLATFORM", the platform imported from a manifest list without an image for the platform of the node
+	ImporterRegistryDefaultPlatform = "IMPORTER_REGISTRY_DEFAULT_PLATFORM"
+	// ImporterS3PartSize provides a constant to capture our env variable "IMPORTER_S3_PART_SIZE"
+	ImporterS3PartSize = "IMPORTER_S3_PART_SIZE"
+	// ImporterS3Concurrency provides a constant to capture our env variable "IMPORTER_S3_CONCURRENCY"
+	ImporterS3Concurrency = "IMPORTER_S3_CONCURRENCY"
+	// ImporterGlanceImage provides a constant to capture our env variable "IMPORTER_GLANCE_IMAGE"
+	ImporterGlanceImage = "IMPORTER_GLANCE_IMAGE"
+	// ImporterGlanceProject provides a constant to capture our env variable "IMPORTER_GLANCE_PROJECT"
+	ImporterGlanceProject = "IMPORTER_GLANCE_PROJECT"
+	// ImporterGlanceRegion provides a constant to capture our env variable "IMPORTER_GLANCE_REGION"
+	ImporterGlanceRegion = "IMPORTER_GLANCE_REGION"
 	// ImporterImageSize provides a constant to capture our env variable "IMPORTER_IMAGE_SIZE"
 	ImporterImageSize = "IMPORTER_IMAGE_SIZE"
 	// ImporterCertDirVar provides a constant to capture our env variable "IMPORTER_CERT_DIR"
@@ -107,6 +143,8 @@ const (
 	ImporterDiskID = "IMPORTER_DISK_ID"
 	// ImporterUUID provides a constant to capture our env variable "IMPORTER_UUID"
 	ImporterUUID = "IMPORTER_UUID"
+	// ImporterMoref provides a constant to capture our env variable "IMPORTER_MOREF"
+	ImporterMoref = "IMPORTER_MOREF"
 	// ImporterReadyFile provides a constant to capture our env variable "IMPORTER_READY_FILE"
 	ImporterReadyFile = "IMPORTER_READY_FILE"
 	// ImporterDoneFile provides a constant to capture our env variable "IMPORTER_DONE_FILE"
@@ -121,8 +159,50 @@ const (
 	ImporterPreviousCheckpoint = "IMPORTER_PREVIOUS_CHECKPOINT"
 	// ImporterFinalCheckpoint provides a constant to capture our env variable "IMPORTER_FINAL_CHECKPOINT"
 	ImporterFinalCheckpoint = "IMPORTER_FINAL_CHECKPOINT"
+	// ImporterArchiveEntry provides a constant to capture our env variable "IMPORTER_ARCHIVE_ENTRY"
+	ImporterArchiveEntry = "IMPORTER_ARCHIVE_ENTRY"
+	// ImporterFlattenBackingChain provides a constant to capture our env variable "IMPORTER_FLATTEN_BACKING_CHAIN"
+	ImporterFlattenBackingChain = "IMPORTER_FLATTEN_BACKING_CHAIN"
+	// ImporterEncryptionKeyFile provides a constant to capture our env variable "IMPORTER_ENCRYPTION_KEY_FILE"
+	ImporterEncryptionKeyFile = "IMPORTER_ENCRYPTION_KEY_FILE"
+	// ImporterDiskFormat provides a constant to capture our env variable "IMPORTER_DISK_FORMAT"
+	ImporterDiskFormat = "IMPORTER_DISK_FORMAT"
+	// ImporterCompressQcow2 provides a constant to capture our env variable "IMPORTER_COMPRESS_QCOW2"
+	ImporterCompressQcow2 = "IMPORTER_COMPRESS_QCOW2"
+	// ImporterQcow2ClusterSize provides a constant to capture our env variable "IMPORTER_QCOW2_CLUSTER_SIZE"
+	ImporterQcow2ClusterSize = "IMPORTER_QCOW2_CLUSTER_SIZE"
+	// ImporterQcow2Compat provides a constant to capture our env variable "IMPORTER_QCOW2_COMPAT"
+	ImporterQcow2Compat = "IMPORTER_QCOW2_COMPAT"
+	// ImporterPreserveImageSize provides a constant to capture our env variable "IMPORTER_PRESERVE_IMAGE_SIZE"
+	ImporterPreserveImageSize = "IMPORTER_PRESERVE_IMAGE_SIZE"
+	// ImporterSkipImageCheck provides a constant to capture our env variable "IMPORTER_SKIP_IMAGE_CHECK"
+	ImporterSkipImageCheck = "IMPORTER_SKIP_IMAGE_CHECK"
+	// ImporterMaxArchiveLayers provides a constant to capture our env variable "IMPORTER_MAX_ARCHIVE_LAYERS"
+	ImporterMaxArchiveLayers = "IMPORTER_MAX_ARCHIVE_LAYERS"
+	// ImporterXzMemoryLimit provides a constant to capture our env variable "IMPORTER_XZ_MEMORY_LIMIT"
+	ImporterXzMemoryLimit = "IMPORTER_XZ_MEMORY_LIMIT"
+	// ImporterBandwidthLimit provides a constant to capture our env variable "IMPORTER_BANDWIDTH_LIMIT", the quantity of bytes per second the data of the source is read at most
+	ImporterBandwidthLimit = "IMPORTER_BANDWIDTH_LIMIT"
+	// ImporterQemuRetryAttempts provides a constant to capture our env variable "IMPORTER_QEMU_RETRY_ATTEMPTS"
+	ImporterQemuRetryAttempts = "IMPORTER_QEMU_RETRY_ATTEMPTS"
+	// ImporterQemuRetryBackoff provides a constant to capture our env variable "IMPORTER_QEMU_RETRY_BACKOFF"
+	ImporterQemuRetryBackoff = "IMPORTER_QEMU_RETRY_BACKOFF"
+	// ImporterQemuCacheMode provides a constant to capture our env variable "IMPORTER_QEMU_CACHE_MODE"
+	ImporterQemuCacheMode = "IMPORTER_QEMU_CACHE_MODE"
+	// ImporterQemuCoroutines provides a constant to capture our env variable "IMPORTER_QEMU_COROUTINES"
+	ImporterQemuCoroutines = "IMPORTER_QEMU_COROUTINES"
+	// ImporterQemuMemoryLimit provides a constant to capture our env variable "IMPORTER_QEMU_MEMORY_LIMIT"
+	ImporterQemuMemoryLimit = "IMPORTER_QEMU_MEMORY_LIMIT"
+	// ImporterQemuNiceness provides a constant to capture our env variable "IMPORTER_QEMU_NICENESS"
+	ImporterQemuNiceness = "IMPORTER_QEMU_NICENESS"
+	// ImporterBlankFilesystem provides a constant to capture our env variable "IMPORTER_BLANK_FILESYSTEM"
+	ImporterBlankFilesystem = "IMPORTER_BLANK_FILESYSTEM"
+	// ImporterBlankFilesystemLabel provides a constant to capture our env variable "IMPORTER_BLANK_FILESYSTEM_LABEL"
+	ImporterBlankFilesystemLabel = "IMPORTER_BLANK_FILESYSTEM_LABEL"
 	// Preallocation provides a constant to capture out env variable "PREALLOCATION"
 	Preallocation = "PREALLOCATION"
+	// PreallocationMode provides a constant to capture our env variable "PREALLOCATION_MODE"
+	PreallocationMode = "PREALLOCATION_MODE"
 	// ImportProxyHTTP provides a constant to capture our env variable "http_proxy"
 	ImportProxyHTTP = "http_proxy"
 	// ImportProxyHTTPS provides a constant to capture our env variable "https_proxy"
@@ -137,8 +217,46 @@ const (
 	InstallerVersionLabel = "INSTALLER_VERSION_LABEL"
 	// ImporterExtraHeader provides a constant to include extra HTTP headers, as the prefix to a format string
 	ImporterExtraHeader = "IMPORTER_EXTRA_HEADER_"
+	// ImporterMirrors provides a constant to capture our env variable "IMPORTER_MIRRORS", the URLs of the mirrors of an http source separated by spaces
+	ImporterMirrors = "IMPORTER_MIRRORS"
+	// ImporterURLPattern provides a constant to capture our env variable "IMPORTER_URL_PATTERN", the syntax of the pattern ending the URL of an http source, glob or regex
+	ImporterURLPattern = "IMPORTER_URL_PATTERN"
+	// ImporterHTTPConcurrency provides a constant to capture our env variable "IMPORTER_HTTP_CONCURRENCY", the number of ranges of an http source downloaded at once to scratch space
+	ImporterHTTPConcurrency = "IMPORTER_HTTP_CONCURRENCY"
+	// ImporterMaxRedirects provides a constant to capture our env variable "IMPORTER_MAX_REDIRECTS", the number of redirects followed by a request of an http source
+	ImporterMaxRedirects = "IMPORTER_MAX_REDIRECTS"
+	// ImporterRedirectHosts provides a constant to capture our env variable "IMPORTER_REDIRECT_HOSTS", the hosts an http source may be redirected to separated by spaces
+	ImporterRedirectHosts = "IMPORTER_REDIRECT_HOSTS"
+	// ImporterChecksum provides a constant to capture our env variable "IMPORTER_CHECKSUM", the <algorithm>:<hex digest> checksum of the data of the source
+	ImporterChecksum = "IMPORTER_CHECKSUM"
+	// ImporterSignatureKeyDirVar provides a constant to capture our env variable "IMPORTER_SIGNATURE_KEY_DIR", the directory of the public keys trusted to sign the data of the source
+	ImporterSignatureKeyDirVar = "IMPORTER_SIGNATURE_KEY_DIR"
+	// ImporterSignatureURL provides a constant to capture our env variable "IMPORTER_SIGNATURE_URL", the URL of the detached signature of the data of the source
+	ImporterSignatureURL = "IMPORTER_SIGNATURE_URL"
+	// ImporterSignature provides a constant to capture our env variable "IMPORTER_SIGNATURE", the armored detached signature of the data of the source
+	ImporterSignature = "IMPORTER_SIGNATURE"
+	// ImporterSourceETag provides a constant to capture our env variable "IMPORTER_SOURCE_ETAG", the ETag of the data of the source imported to the volume before
+	ImporterSourceETag = "IMPORTER_SOURCE_ETAG"
+	// ImporterSourceLastModified provides a constant to capture our env variable "IMPORTER_SOURCE_LAST_MODIFIED", the RFC 3339 time the data of the source imported to the volume before was last modified
+	ImporterSourceLastModified = "IMPORTER_SOURCE_LAST_MODIFIED"
 	// ImporterSecretExtraHeadersDir is where the secrets containing extra HTTP headers will be mounted
 	ImporterSecretExtraHeadersDir = "/extraheaders"
+	// ImporterEncryptionSecretDir is where the secret containing the passphrase of an encrypted image will be mounted
+	ImporterEncryptionSecretDir = "/encryption"
+	// ImporterSFTPSecretDir is where the secret holding the credentials and the known hosts of an SFTP source will be mounted
+	ImporterSFTPSecretDir = "/sftp"
+	// ImporterSMBSecretDir is where the secret holding the credentials of an SMB source will be mounted
+	ImporterSMBSecretDir = "/smb"
+	// ImporterFileSourceDir is where the PVC or the directory of the node holding the file of a file source will be mounted
+	ImporterFileSourceDir = "/filesource"
+	// ImporterClientCertDir is where the client certificate and key of the secret of an http source will be mounted
+	ImporterClientCertDir = "/clientcert"
+	// ImporterSignatureKeyDir is where the configmap containing the public keys trusted to sign the data of the source will be mounted
+	ImporterSignatureKeyDir = "/signaturekeys"
+	// ImporterRegistryAuthDir is where the docker config of the secret of a registry source will be mounted
+	ImporterRegistryAuthDir = "/registryauth"
+	// ImporterRegistryAuthFileName is the name of the docker config of the secret of a registry source, in ImporterRegistryAuthDir
+	ImporterRegistryAuthFileName = "auth.json"
 
 	// CloningLabelValue provides a constant to use as a label value for pod affinity (controller pkg only)
 	CloningLabelValue = "host-assisted-cloning"
@@ -179,6 +297,8 @@ const (
 	FilesystemOverheadVar = "FILESYSTEM_OVERHEAD"
 	// DefaultGlobalOverhead is the amount of space reserved on Filesystem volumes by default
 	DefaultGlobalOverhead = "0.055"
+	// DefaultScratchSpaceSizeMultiplier is the size of the scratch space relative to the size of the PVC by default
+	DefaultScratchSpaceSizeMultiplier = "1"
 
 	// ConfigName is the name of default CDI Config
 	ConfigName = "config"
@@ -190,6 +310,30 @@ const (
 	KeyAccess = "accessKeyId"
 	// KeySecret provides a constant to the secretKey label using in controller pkg and transport_test.go
 	KeySecret = "secretKey"
+	// KeyToken provides a constant to the optional bearer token label of the secret of an http source
+	KeyToken = "token"
+	// KeyEndpoint provides a constant to the optional endpoint label of the secret of an s3:// source
+	KeyEndpoint = "endpoint"
+	// KeyRegion provides a constant to the optional region label of the secret of an S3 source
+	KeyRegion = "region"
+	// KeyServiceAccount provides a constant to the label of the JSON key of the service account reading a GCS source
+	KeyServiceAccount = "serviceAccount"
+	// KeyUsername provides a constant to the user name label of the secret of an SMB source
+	KeyUsername = "username"
+	// KeyPassword provides a constant to the password label of the secret of an SFTP or an SMB source
+	KeyPassword = "password"
+	// KeyDomain provides a constant to the optional domain label of the secret of an SMB source
+	KeyDomain = "domain"
+	// KeyPrivateKey provides a constant to the private key label of the secret of an SFTP source
+	KeyPrivateKey = "privateKey"
+	// KeyKnownHosts provides a constant to the label of the known_hosts entries of the secret of an SFTP source
+	KeyKnownHosts = "knownHosts"
+	// KeyPassphrase provides a constant to the passphrase label of the secret decrypting an encrypted image
+	KeyPassphrase = "passphrase"
+	// KeyClientCert provides a constant to the optional client certificate label of the secret of an http source
+	KeyClientCert = "tls.crt"
+	// KeyClientKey provides a constant to the optional client key label of the secret of an http source
+	KeyClientKey = "tls.key"
 
 	// DefaultResyncPeriod sets a 10 minute resync period, used in the controller pkg and the controller cmd executable
 	DefaultResyncPeriod = 10 * time.Minute
@@ -198,9 +342,35 @@ const (
 
 	// ScratchSpaceNeededExitCode is the exit code that indicates the importer pod requires scratch space to function properly.
 	ScratchSpaceNeededExitCode = 42
+	// UnsupportedFormatExitCode is the exit code that indicates the importer pod cannot import the format of the source,
+	// the import is not retried.
+	UnsupportedFormatExitCode = 43
+	// InvalidEncryptionKeyExitCode is the exit code that indicates the importer pod cannot decrypt the source with the
+	// passphrase of its encryption secret, the import is not retried.
+	InvalidEncryptionKeyExitCode = 44
+	// CorruptImageExitCode is the exit code that indicates qemu-img check found corruptions or leaked clusters in the
+	// image, the import is not retried.
+	CorruptImageExitCode = 45
+	// InvalidClientCertificateExitCode is the exit code that indicates the client certificate of the secret of the source
+	// is expired, not yet valid or does not match its key, the import is not retried.
+	InvalidClientCertificateExitCode = 46
+	// ChecksumMismatchExitCode is the exit code that indicates the data of the source does not match its checksum, the
+	// import is not retried.
+	ChecksumMismatchExitCode = 47
+	// InvalidSignatureExitCode is the exit code that indicates the data of the source is not signed by a trusted key, or
+	// its signature is expired, the import is not retried.
+	InvalidSignatureExitCode = 48
+	// NoMatchingEntryExitCode is the exit code that indicates none of the entries of the directory index of the source
+	// matches the pattern ending its URL, the import is not retried.
+	NoMatchingEntryExitCode = 49
+	// SourceNotFoundExitCode is the exit code that indicates the data of the source does not exist, such as the file of
+	// an http URL answered with 404 Not Found or a missing S3 object, the import is not retried.
+	SourceNotFoundExitCode = 50
 
 	// ScratchNameSuffix (controller pkg only)
 	ScratchNameSuffix = "scratch"
+	// NFSSourceNameSuffix is the suffix of the volume of the NFS export holding the file of an NFS source, and of its claim
+	NFSSourceNameSuffix = "nfs-source"
 
 	// UploadTokenIssuer is the JWT issuer of upload tokens
 	UploadTokenIssuer = "cdi-apiserver"
@@ -256,6 +426,43 @@ const (
 
 	// PreallocationApplied is a string inserted into importer's/uploader's exit message
 	PreallocationApplied = "Preallocation applied"
+	// PreallocationModeApplied is a string inserted into importer's exit message, followed by the preallocation mode performed
+	PreallocationModeApplied = "Preallocation mode"
+	// SourceDigest is a string inserted into importer's exit message, followed by the digest of the data of the source
+	SourceDigest = "Source digest"
+	// PayloadDigest is a string inserted into importer's exit message, followed by the digest of the data imported
+	PayloadDigest = "Payload digest"
+	// DestinationDigest is a string inserted into importer's exit message, followed by the digest of the disk image written
+	DestinationDigest = "Destination digest"
+	// DiskFormat is a string inserted into importer's exit message, followed by the format of the disk image when it is not raw
+	DiskFormat = "Disk format"
+	// SourceFormat is a string inserted into importer's exit message, followed by the format of the disk image of the source when it is recorded
+	SourceFormat = "Source format"
+	// Qcow2Options is a string inserted into importer's exit message, followed by the cluster size, the compat level and the compression of a qcow2 disk image
+	Qcow2Options = "Qcow2 options"
+	// LogicalBytes is a string inserted into importer's exit message, followed by the size of the disk image written to a filesystem volume
+	LogicalBytes = "Logical bytes"
+	// PhysicalBytes is a string inserted into importer's exit message, followed by the space allocated to the disk image
+	PhysicalBytes = "Physical bytes"
+	// SourceURL is a string inserted into importer's exit message, followed by the URL of the http source or of its mirror which served the data
+	SourceURL = "Source URL"
+	// SignedBy is a string inserted into importer's exit message, followed by the fingerprint of the primary key of the signer of the data
+	SignedBy = "Signed by"
+	// SourceETag is a string inserted into importer's exit message, followed by the ETag of the data of the source
+	SourceETag = "Source ETag"
+	// SourceLastModified is a string inserted into importer's exit message, followed by the RFC 3339 time the data of the source was last modified
+	SourceLastModified = "Source last modified"
+	// SourceUnchanged is a string inserted into importer's exit message when the data of the source did not change since it was imported to the volume
+	SourceUnchanged = "Source unchanged"
+	// ImportMilestones is a string inserted into importer's exit message, followed by the comma separated milestones reached by the import
+	ImportMilestones = "Milestones"
+
+	// ImportMilestoneSourceConnected is the milestone of an import reached once the importer connected to the source
+	ImportMilestoneSourceConnected = "SourceConnected"
+	// ImportMilestoneConversionStarted is the milestone of an import reached once the importer started converting the disk image
+	ImportMilestoneConversionStarted = "ConversionStarted"
+	// ImportMilestoneVerificationPassed is the milestone of an import reached once the disk image written passed its check
+	ImportMilestoneVerificationPassed = "VerificationPassed"
 
 	// SecretHeader is the key in a secret containing a sensitive extra header for HTTP data sources
 	SecretHeader = "secretHeader"
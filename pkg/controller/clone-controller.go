@@ -83,14 +83,14 @@ func NewCloneController(mgr manager.Manager,
 	verbose string,
 	clientCertGenerator generator.CertGenerator,
 	serverCAFetcher fetcher.CertBundleFetcher,
-	apiServerKey *rsa.PublicKey,
+	apiServerKeys []*rsa.PublicKey,
 	installerLabels map[string]string) (controller.Controller, error) {
 	reconciler := &CloneReconciler{
 		client:              mgr.GetClient(),
 		scheme:              mgr.GetScheme(),
 		log:                 log.WithName("clone-controller"),
-		shortTokenValidator: cc.NewCloneTokenValidator(common.CloneTokenIssuer, apiServerKey),
-		longTokenValidator:  cc.NewCloneTokenValidator(common.ExtendedCloneTokenIssuer, apiServerKey),
+		shortTokenValidator: cc.NewCloneTokenValidator(common.CloneTokenIssuer, apiServerKeys...),
+		longTokenValidator:  cc.NewCloneTokenValidator(common.ExtendedCloneTokenIssuer, apiServerKeys...),
 		image:               image,
 		verbose:             verbose,
 		pullPolicy:          pullPolicy,
@@ -492,12 +492,12 @@ func (r *CloneReconciler) CreateCloneSourcePod(image, pullPolicy string, pvc *co
 		return nil, err
 	}
 
-	podResourceRequirements, err := cc.GetDefaultPodResourceRequirements(r.client)
+	podResourceRequirements, err := cc.GetPodResourceRequirements(r.client, pvc)
 	if err != nil {
 		return nil, err
 	}
 
-	workloadNodePlacement, err := cc.GetWorkloadNodePlacement(r.client)
+	workloadNodePlacement, err := cc.GetPodNodePlacement(r.client, pvc)
 	if err != nil {
 		return nil, err
 	}
@@ -514,7 +514,9 @@ func (r *CloneReconciler) CreateCloneSourcePod(image, pullPolicy string, pvc *co
 		sourceVolumeMode = corev1.PersistentVolumeFilesystem
 	}
 
-	pod := MakeCloneSourcePodSpec(sourceVolumeMode, image, pullPolicy, sourcePvcName, sourcePvcNamespace, ownerKey, serverCABundle, pvc, podResourceRequirements, workloadNodePlacement)
+	priorityClassName := cc.GetPriorityClass(r.client, pvc)
+
+	pod := MakeCloneSourcePodSpec(sourceVolumeMode, image, pullPolicy, sourcePvcName, sourcePvcNamespace, ownerKey, serverCABundle, pvc, podResourceRequirements, workloadNodePlacement, priorityClassName)
 	util.SetRecommendedLabels(pod, r.installerLabels, "cdi-controller")
 
 	if err := r.client.Create(context.TODO(), pod); err != nil {
@@ -529,7 +531,7 @@ func (r *CloneReconciler) CreateCloneSourcePod(image, pullPolicy string, pvc *co
 // MakeCloneSourcePodSpec creates and returns the clone source pod spec based on the target pvc.
 func MakeCloneSourcePodSpec(sourceVolumeMode corev1.PersistentVolumeMode, image, pullPolicy, sourcePvcName, sourcePvcNamespace, ownerRefAnno string,
 	serverCACert []byte, targetPvc *corev1.PersistentVolumeClaim, resourceRequirements *corev1.ResourceRequirements,
-	workloadNodePlacement *sdkapi.NodePlacement) *corev1.Pod {
+	workloadNodePlacement *sdkapi.NodePlacement, priorityClassName string) *corev1.Pod {
 
 	var ownerID string
 	cloneSourcePodName := targetPvc.Annotations[AnnCloneSourcePod]
@@ -632,7 +634,7 @@ func MakeCloneSourcePodSpec(sourceVolumeMode corev1.PersistentVolumeMode, image,
 			NodeSelector:      workloadNodePlacement.NodeSelector,
 			Tolerations:       workloadNodePlacement.Tolerations,
 			Affinity:          workloadNodePlacement.Affinity,
-			PriorityClassName: cc.GetPriorityClass(targetPvc),
+			PriorityClassName: priorityClassName,
 		},
 	}
 
@@ -20,7 +20,10 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -76,11 +79,39 @@ const (
 	AnnPrePopulated = AnnAPIGroup + "/storage.prePopulated"
 	// AnnPriorityClassName is PVC annotation to indicate the priority class name for importer, cloner and uploader pod
 	AnnPriorityClassName = AnnAPIGroup + "/storage.pod.priorityclassname"
+	// AnnPodCPURequest is PVC annotation overriding the CPU request of the importer, cloner and uploader pod
+	AnnPodCPURequest = AnnAPIGroup + "/storage.pod.resources.requests.cpu"
+	// AnnPodMemoryRequest is PVC annotation overriding the memory request of the importer, cloner and uploader pod
+	AnnPodMemoryRequest = AnnAPIGroup + "/storage.pod.resources.requests.memory"
+	// AnnPodCPULimit is PVC annotation overriding the CPU limit of the importer, cloner and uploader pod
+	AnnPodCPULimit = AnnAPIGroup + "/storage.pod.resources.limits.cpu"
+	// AnnPodMemoryLimit is PVC annotation overriding the memory limit of the importer, cloner and uploader pod
+	AnnPodMemoryLimit = AnnAPIGroup + "/storage.pod.resources.limits.memory"
+	// AnnPodNodeSelector is PVC annotation adding a JSON map of node labels to the node selector of the importer, cloner and
+	// uploader pod
+	AnnPodNodeSelector = AnnAPIGroup + "/storage.pod.nodeSelector"
+	// AnnPodTolerations is PVC annotation adding a JSON list of tolerations to the importer, cloner and uploader pod
+	AnnPodTolerations = AnnAPIGroup + "/storage.pod.tolerations"
+	// AnnPodAffinity is PVC annotation overriding the affinity of the importer, cloner and uploader pod with a JSON affinity
+	AnnPodAffinity = AnnAPIGroup + "/storage.pod.affinity"
 	// AnnExternalPopulation annotation marks a PVC as "externally populated", allowing the import-controller to skip it
 	AnnExternalPopulation = AnnAPIGroup + "/externalPopulation"
+	// AnnConfigAuthority is the annotation specifying a resource as the CDIConfig authority
+	AnnConfigAuthority = "cdi.kubevirt.io/configAuthority"
+	// AnnImmediateBinding provides a const to indicate whether immediate binding should be performed on the PV (overrides global config)
+	AnnImmediateBinding = AnnAPIGroup + "/storage.bind.immediate.requested"
+	// AnnProvisioningNode is PVC annotation recording the node selected for a WaitForFirstConsumer PVC whose first consumer
+	// is the importer pod, while its volume is provisioned there
+	AnnProvisioningNode = AnnAPIGroup + "/storage.provisioningNode"
+	// AnnProvisioningFailedNodes is PVC annotation listing the nodes, separated by commas, where the volume of a
+	// WaitForFirstConsumer PVC could not be provisioned, the importer pod is rescheduled on other nodes
+	AnnProvisioningFailedNodes = AnnAPIGroup + "/storage.provisioningFailedNodes"
 
 	// AnnDeleteAfterCompletion is PVC annotation for deleting DV after completion
 	AnnDeleteAfterCompletion = AnnAPIGroup + "/storage.deleteAfterCompletion"
+	// AnnTTLSecondsAfterCompletion is a DataVolume annotation overriding the CDIConfig dataVolumeTTLSeconds, the DataVolume
+	// is garbage collected this number of seconds after its completion
+	AnnTTLSecondsAfterCompletion = AnnAPIGroup + "/storage.ttlSecondsAfterCompletion"
 	// AnnPodRetainAfterCompletion is PVC annotation for retaining transfer pods after completion
 	AnnPodRetainAfterCompletion = AnnAPIGroup + "/storage.pod.retainAfterCompletion"
 
@@ -102,6 +133,47 @@ const (
 	AnnPreallocationRequested = AnnAPIGroup + "/storage.preallocation.requested"
 	// AnnPreallocationApplied provides a const for PVC preallocation annotation
 	AnnPreallocationApplied = AnnAPIGroup + "/storage.preallocation"
+	// AnnPreallocationModeRequested provides a const for the preallocation mode requested for the PV, falloc or full
+	AnnPreallocationModeRequested = AnnAPIGroup + "/storage.preallocation.mode.requested"
+	// AnnPreallocationMode provides a const for the preallocation mode performed on the PV, falloc or full
+	AnnPreallocationMode = AnnAPIGroup + "/storage.preallocation.mode"
+
+	// AnnDiskFormatRequested provides a const for the format of the disk image requested to be written to the PV
+	AnnDiskFormatRequested = AnnAPIGroup + "/storage.diskFormat.requested"
+	// AnnDiskFormat provides a const for the format of the disk image written to the PV, raw or qcow2
+	AnnDiskFormat = AnnAPIGroup + "/storage.diskFormat"
+	// AnnSourceFormat provides a const for the format of the disk image read from the source of an import, iso for an ISO9660 image
+	AnnSourceFormat = AnnAPIGroup + "/storage.import.sourceFormat"
+	// AnnQcow2Options provides a const for the cluster size, the compat level and the compression of the qcow2 disk image written to the PV
+	AnnQcow2Options = AnnAPIGroup + "/storage.qcow2Options"
+	// AnnSourceURL provides a const for the URL of the http source, or of its mirror, which served the data of an import
+	AnnSourceURL = AnnAPIGroup + "/storage.import.sourceURL"
+	// AnnSignedBy provides a const for the fingerprint of the primary key of the signer of the data of an import, verified
+	// against its detached signature
+	AnnSignedBy = AnnAPIGroup + "/storage.import.signedBy"
+	// AnnSourceETag provides a const for the ETag of the data of an http source, or of an S3 object, imported to the PV
+	AnnSourceETag = AnnAPIGroup + "/storage.import.sourceETag"
+	// AnnSourceLastModified provides a const for the RFC 3339 time the data of an http source imported to the PV was last modified
+	AnnSourceLastModified = AnnAPIGroup + "/storage.import.sourceLastModified"
+	// AnnSourceUnchanged provides a const telling the data of the PV was kept by a re-import, its source did not change
+	AnnSourceUnchanged = AnnAPIGroup + "/storage.import.sourceUnchanged"
+
+	// AnnImportMilestones holds the comma separated milestones reached by an import, reported by the importer on exit
+	AnnImportMilestones = AnnAPIGroup + "/storage.import.milestones"
+	// AnnImportMilestonesRelayed holds the comma separated milestones of an import relayed as events on the DataVolume
+	AnnImportMilestonesRelayed = AnnAPIGroup + "/storage.import.milestones.relayed"
+
+	// AnnLogicalBytes holds the size of the disk image written to a filesystem volume by an import
+	AnnLogicalBytes = AnnAPIGroup + "/storage.import.logicalBytes"
+	// AnnPhysicalBytes holds the space allocated to the disk image written to a filesystem volume, smaller when it is sparse
+	AnnPhysicalBytes = AnnAPIGroup + "/storage.import.physicalBytes"
+
+	// AnnSourceDigest holds the digest of the data of the source of an import
+	AnnSourceDigest = AnnAPIGroup + "/storage.import.sourceDigest"
+	// AnnPayloadDigest holds the digest of the data imported, once decompressed and extracted
+	AnnPayloadDigest = AnnAPIGroup + "/storage.import.payloadDigest"
+	// AnnDestinationDigest holds the digest of the disk image written to the volume by an import
+	AnnDestinationDigest = AnnAPIGroup + "/storage.import.destinationDigest"
 
 	// AnnRunningCondition provides a const for the running condition
 	AnnRunningCondition = AnnAPIGroup + "/storage.condition.running"
@@ -133,6 +205,12 @@ const (
 
 	// AnnRequiresScratch provides a const for our PVC requires scratch annotation
 	AnnRequiresScratch = AnnAPIGroup + "/storage.import.requiresScratch"
+	// AnnScratchStorageClass is PVC annotation overriding the storage class of the scratch space
+	AnnScratchStorageClass = AnnAPIGroup + "/storage.scratch.storageClass"
+	// AnnScratchSizeMultiplier is PVC annotation overriding the size of the scratch space relative to the size of the PVC
+	AnnScratchSizeMultiplier = AnnAPIGroup + "/storage.scratch.sizeMultiplier"
+	// AnnImportTerminalError holds the message of an import failure that retrying cannot fix, such as an unsupported format
+	AnnImportTerminalError = AnnAPIGroup + "/storage.import.terminalError"
 
 	// AnnContentType provides a const for the PVC content-type
 	AnnContentType = AnnAPIGroup + "/storage.contentType"
@@ -150,12 +228,20 @@ const (
 	AnnRegistryImportMethod = AnnAPIGroup + "/storage.import.registryImportMethod"
 	// AnnRegistryImageStream provides a const for registry image stream annotation
 	AnnRegistryImageStream = AnnAPIGroup + "/storage.import.registryImageStream"
+	// AnnRegistryArtifactMediaType provides a const for registry artifact media type annotation, the media type of the
+	// blob imported from an OCI artifact
+	AnnRegistryArtifactMediaType = AnnAPIGroup + "/storage.import.registryArtifactMediaType"
+	// AnnRegistryPlatform provides a const for registry platform annotation, the <os>/<architecture>[/<variant>] platform
+	// of the image imported from a manifest list
+	AnnRegistryPlatform = AnnAPIGroup + "/storage.import.registryPlatform"
 	// AnnImportPod provides a const for our PVC importPodName annotation
 	AnnImportPod = AnnAPIGroup + "/storage.import.importPodName"
 	// AnnDiskID provides a const for our PVC diskId annotation
 	AnnDiskID = AnnAPIGroup + "/storage.import.diskId"
 	// AnnUUID provides a const for our PVC uuid annotation
 	AnnUUID = AnnAPIGroup + "/storage.import.uuid"
+	// AnnMoref provides a const for our PVC VM MOref annotation, the managed object reference of a VDDK source VM
+	AnnMoref = AnnAPIGroup + "/storage.import.vddk.moref"
 	// AnnBackingFile provides a const for our PVC backing file annotation
 	AnnBackingFile = AnnAPIGroup + "/storage.import.backingFile"
 	// AnnThumbprint provides a const for our PVC backing thumbprint annotation
@@ -164,6 +250,97 @@ const (
 	AnnExtraHeaders = AnnAPIGroup + "/storage.import.extraHeaders"
 	// AnnSecretExtraHeaders provides a const for our PVC secretExtraHeaders annotation
 	AnnSecretExtraHeaders = AnnAPIGroup + "/storage.import.secretExtraHeaders"
+	// AnnMirrors provides a const for the URLs of the mirrors of an http source, separated by spaces, in the order they are tried
+	AnnMirrors = AnnAPIGroup + "/storage.import.mirrors"
+	// AnnURLPattern provides a const for our PVC urlPattern annotation, the syntax of the pattern ending the URL of an
+	// http source, glob or regex, matched against the names of the files of the index of its directory
+	AnnURLPattern = AnnAPIGroup + "/storage.import.urlPattern"
+	// AnnRequiredSize provides a const for our DataVolume requiredSize annotation, the virtual size of the image of an
+	// http source found by the size-detection pod when the storage size of the DataVolume is omitted
+	AnnRequiredSize = AnnAPIGroup + "/storage.import.requiredSize"
+	// AnnHTTPConcurrency provides a const for our PVC httpConcurrency annotation, the number of ranges of an http source
+	// downloaded at once to scratch space, 1 streams the data
+	AnnHTTPConcurrency = AnnAPIGroup + "/storage.import.httpConcurrency"
+	// AnnMaxRedirects provides a const for our PVC maxRedirects annotation, the number of redirects followed by a request of
+	// an http source, 0 follows none
+	AnnMaxRedirects = AnnAPIGroup + "/storage.import.maxRedirects"
+	// AnnRedirectHosts provides a const for our PVC redirectHosts annotation, the hosts an http source may be redirected to
+	// besides its own, separated by spaces
+	AnnRedirectHosts = AnnAPIGroup + "/storage.import.redirectHosts"
+	// AnnChecksum provides a const for our PVC checksum annotation, the <algorithm>:<hex digest> checksum of the data of the source
+	AnnChecksum = AnnAPIGroup + "/storage.import.checksum"
+	// AnnReimport provides a const for our PVC reimport annotation, importing again the data of a PVC populated by a
+	// previous DataVolume, ReimportIfChanged or ReimportAlways
+	AnnReimport = AnnAPIGroup + "/storage.import.reimport"
+	// AnnSignatureKeyConfigMap provides a const for the ConfigMap containing the armored public keys trusted to sign the
+	// data of an http source
+	AnnSignatureKeyConfigMap = AnnAPIGroup + "/storage.import.signature.keyConfigMap"
+	// AnnSignatureURL provides a const for the URL of the detached signature of the data of an http source
+	AnnSignatureURL = AnnAPIGroup + "/storage.import.signature.url"
+	// AnnSignatureArmored provides a const for the armored detached signature of the data of an http source
+	AnnSignatureArmored = AnnAPIGroup + "/storage.import.signature.armored"
+	// AnnArchiveEntry provides a const for our PVC archiveEntry annotation, naming the file to extract from an archive
+	AnnArchiveEntry = AnnAPIGroup + "/archiveEntry"
+	// AnnFlattenBackingChain provides a const for our PVC flattenBackingChain annotation, allowing a qcow2 image extracted
+	// from an archive to reference backing files extracted from the same archive
+	AnnFlattenBackingChain = AnnAPIGroup + "/flattenBackingChain"
+	// AnnEncryptionSecret provides a const for our PVC encryptionSecretName annotation, naming the secret holding the
+	// passphrase of an encrypted image
+	AnnEncryptionSecret = AnnAPIGroup + "/storage.import.encryptionSecretName"
+	// AnnCompressQcow2 provides a const for our PVC compressQcow2 annotation, compressing the clusters of a qcow2 disk image
+	AnnCompressQcow2 = AnnAPIGroup + "/compressQcow2"
+	// AnnQcow2ClusterSize provides a const for our PVC qcow2ClusterSize annotation, the size of the clusters of a qcow2 disk image
+	AnnQcow2ClusterSize = AnnAPIGroup + "/qcow2ClusterSize"
+	// AnnQcow2Compat provides a const for our PVC qcow2Compat annotation, the compat level of a qcow2 disk image
+	AnnQcow2Compat = AnnAPIGroup + "/qcow2Compat"
+	// AnnPreserveImageSize provides a const for our PVC preserveImageSize annotation, keeping the virtual size of the
+	// imported image instead of expanding it to the size of the PVC
+	AnnPreserveImageSize = AnnAPIGroup + "/preserveImageSize"
+	// AnnSkipImageCheck provides a const for our PVC skipImageCheck annotation, skipping the qemu-img check of the
+	// converted image
+	AnnSkipImageCheck = AnnAPIGroup + "/skipImageCheck"
+	// AnnS3AddressingStyle provides a const for our PVC s3AddressingStyle annotation, path or virtual, addressing the
+	// bucket of an s3:// source in the path or the host name of the requests
+	AnnS3AddressingStyle = AnnAPIGroup + "/s3AddressingStyle"
+	// AnnS3PartSize provides a const for our PVC s3PartSize annotation, the size of the parts of an S3 object
+	// downloaded in parallel
+	AnnS3PartSize = AnnAPIGroup + "/s3PartSize"
+	// AnnS3Concurrency provides a const for our PVC s3Concurrency annotation, the number of parts of an S3 object
+	// downloaded at once, 1 streams the object
+	AnnS3Concurrency = AnnAPIGroup + "/s3Concurrency"
+	// AnnImportBandwidthLimit provides a const for our PVC bandwidthLimit annotation, the quantity of bytes per second
+	// an import reads from its source at most, overriding the importBandwidthLimit of the CDIConfig
+	AnnImportBandwidthLimit = AnnAPIGroup + "/storage.import.bandwidthLimit"
+	// AnnImportMaxRetries provides a const for our PVC maxRetries annotation, the number of times a failed import is
+	// retried, overriding the importRetryPolicy of the CDIConfig
+	AnnImportMaxRetries = AnnAPIGroup + "/storage.import.retry.maxRetries"
+	// AnnImportInitialBackoff provides a const for our PVC initialBackoff annotation, the delay before the first retry
+	// of a failed import, overriding the importRetryPolicy of the CDIConfig
+	AnnImportInitialBackoff = AnnAPIGroup + "/storage.import.retry.initialBackoff"
+	// AnnImportMaxBackoff provides a const for our PVC maxBackoff annotation, the cap of the delay before the retry of
+	// a failed import, overriding the importRetryPolicy of the CDIConfig
+	AnnImportMaxBackoff = AnnAPIGroup + "/storage.import.retry.maxBackoff"
+	// AnnImportRetryAfter provides a const for the time the failed import of the PVC is retried after
+	AnnImportRetryAfter = AnnAPIGroup + "/storage.import.retryAfter"
+	// AnnImportLastFailure provides a const for the reason the last failed attempt of the import of the PVC failed with
+	AnnImportLastFailure = AnnAPIGroup + "/storage.import.lastFailure"
+	// AnnGlanceImage provides a const for our PVC Glance image annotation, the ID or the name of the image
+	AnnGlanceImage = AnnAPIGroup + "/storage.import.glance.image"
+	// AnnGlanceProject provides a const for our PVC Glance project annotation, the project owning the image
+	AnnGlanceProject = AnnAPIGroup + "/storage.import.glance.project"
+	// AnnGlanceRegion provides a const for our PVC Glance region annotation, the region of the image service
+	AnnGlanceRegion = AnnAPIGroup + "/storage.import.glance.region"
+	// AnnFilePVC provides a const for our PVC file source PVC annotation, the PVC holding the file imported
+	AnnFilePVC = AnnAPIGroup + "/storage.import.file.pvc"
+	// AnnFileHostPath provides a const for our PVC file source host path annotation, the directory of the node holding
+	// the file imported
+	AnnFileHostPath = AnnAPIGroup + "/storage.import.file.hostPath"
+	// AnnBlankFilesystem provides a const for our PVC blank filesystem annotation, the filesystem created on the blank
+	// image, ext4 or xfs
+	AnnBlankFilesystem = AnnAPIGroup + "/storage.import.blank.filesystem"
+	// AnnBlankFilesystemLabel provides a const for our PVC blank filesystem label annotation, the label of the
+	// filesystem created on the blank image
+	AnnBlankFilesystemLabel = AnnAPIGroup + "/storage.import.blank.filesystemLabel"
 
 	// AnnCloneToken is the annotation containing the clone token
 	AnnCloneToken = AnnAPIGroup + "/storage.clone.token"
@@ -179,6 +356,8 @@ const (
 
 	//AnnDefaultStorageClass is the annotation indicating that a storage class is the default one.
 	AnnDefaultStorageClass = "storageclass.kubernetes.io/is-default-class"
+	// AnnDefaultSnapshotClass is the annotation indicating that a volume snapshot class is the default one of its driver.
+	AnnDefaultSnapshotClass = "snapshot.storage.kubernetes.io/is-default-class"
 
 	// AnnOpenShiftImageLookup is the annotation for OpenShift image stream lookup
 	AnnOpenShiftImageLookup = "alpha.image.policy.openshift.io/resolve-names"
@@ -188,6 +367,9 @@ const (
 	// AnnCloneOf is used to indicate that cloning was complete
 	AnnCloneOf = "k8s.io/CloneOf"
 
+	// AnnSelectedNode is the annotation of a WaitForFirstConsumer PVC naming the node the scheduler selected for its volume
+	AnnSelectedNode = "volume.kubernetes.io/selected-node"
+
 	// AnnPodNetwork is used for specifying Pod Network
 	AnnPodNetwork = "k8s.v1.cni.cncf.io/networks"
 	// AnnPodMultusDefaultNetwork is used for specifying default Pod Network
@@ -206,28 +388,78 @@ const (
 	// CloneComplete message
 	CloneComplete = "Clone Complete"
 
+	// InvalidClientCertificate is the reason of the import that failed with an expired, not yet valid, or mismatched
+	// client certificate, the import is not retried
+	InvalidClientCertificate = "InvalidClientCertificate"
+
+	// ChecksumMismatch is the reason of the import that failed because the data of the source does not match its
+	// checksum, the import is not retried
+	ChecksumMismatch = "ChecksumMismatch"
+
+	// InvalidSignature is the reason of the import that failed because the data of the source is not signed by a
+	// trusted key, or its signature is expired, the import is not retried
+	InvalidSignature = "InvalidSignature"
+
+	// NoMatchingEntry is the reason of the import that failed because none of the entries of the directory index of
+	// the source matches the pattern ending its URL, the import is not retried
+	NoMatchingEntry = "NoMatchingEntry"
+
+	// SourceNotFound is the reason of the import that failed because the data of its source does not exist, the
+	// import is not retried
+	SourceNotFound = "SourceNotFound"
+
+	// RetryLimitExceeded is the reason of the import that failed after it was retried as many times as its retry
+	// policy allows
+	RetryLimitExceeded = "RetryLimitExceeded"
+
+	// NFSMountTimeout is the reason of the import that failed because its importer pod could not mount the NFS export
+	// holding the file of its NFS source in time
+	NFSMountTimeout = "NFSMountTimeout"
+
+	// HostPathImportNotAllowed is the reason of the import of a file of a node directory that failed because it was
+	// not requested by a DataVolume while the HostPathImport feature gate is enabled
+	HostPathImportNotAllowed = "HostPathImportNotAllowed"
+
 	cloneTokenLeeway = 10 * time.Second
 
 	// Default value for preallocation option if not defined in DV or CDIConfig
 	defaultPreallocation = false
+	// Default value for disk format option if not defined in DV or CDIConfig
+	defaultDiskFormat = cdiv1.DataVolumeDiskFormatRaw
 
 	// ErrStartingPod provides a const to indicate that a pod wasn't able to start without providing sensitive information (reason)
 	ErrStartingPod = "ErrStartingPod"
 	// MessageErrStartingPod provides a const to indicate that a pod wasn't able to start without providing sensitive information (message)
 	MessageErrStartingPod = "Error starting pod '%s': For more information, request access to cdi-deploy logs from your sysadmin"
+	// ErrPriorityClassNotFound provides a const to indicate that a pod wasn't created because its priority class does not exist (reason)
+	ErrPriorityClassNotFound = "ErrPriorityClassNotFound"
+	// MessageErrPriorityClassNotFound provides a const to indicate that a pod wasn't created because its priority class does not exist (message)
+	MessageErrPriorityClassNotFound = "Error starting pod '%s': priority class %s not found"
 	// ErrClaimNotValid provides a const to indicate a claim is not valid
 	ErrClaimNotValid = "ErrClaimNotValid"
 	// ErrExceededQuota provides a const to indicate the claim has exceeded the quota
 	ErrExceededQuota = "ErrExceededQuota"
 	// ErrIncompatiblePVC provides a const to indicate a clone is not possible due to an incompatible PVC
 	ErrIncompatiblePVC = "ErrIncompatiblePVC"
+	// ErrScratchSpaceBlockOnly provides a const to indicate the storage class of the scratch space only provides block volumes
+	ErrScratchSpaceBlockOnly = "ErrScratchSpaceBlockOnly"
 
 	// SourceHTTP is the source type HTTP, if unspecified or invalid, it defaults to SourceHTTP
 	SourceHTTP = "http"
 	// SourceS3 is the source type S3
 	SourceS3 = "s3"
+	// SourceGCS is the source type of Google Cloud Storage
+	SourceGCS = "gcs"
+	// SourceSFTP is the source type of SFTP
+	SourceSFTP = "sftp"
 	// SourceGlance is the source type of glance
 	SourceGlance = "glance"
+	// SourceFile is the source type of a file of a PVC or of a directory of the node
+	SourceFile = "file"
+	// SourceNFS is the source type of a file of an NFS export
+	SourceNFS = "nfs"
+	// SourceSMB is the source type of a file of an SMB share
+	SourceSMB = "smb"
 	// SourceNone means there is no source.
 	SourceNone = "none"
 	// SourceRegistry is the source type of Registry
@@ -237,6 +469,11 @@ const (
 	// SourceVDDK is the source type of VDDK
 	SourceVDDK = "vddk"
 
+	// ReimportIfChanged re-imports the data of a populated PVC unless its http or S3 source did not change
+	ReimportIfChanged = "IfChanged"
+	// ReimportAlways re-imports the data of a populated PVC whether its source changed or not
+	ReimportAlways = "Always"
+
 	// ClaimLost reason const
 	ClaimLost = "ClaimLost"
 	// NotFound reason const
@@ -270,6 +507,9 @@ var (
 
 	apiServerKeyOnce sync.Once
 	apiServerKey     *rsa.PrivateKey
+
+	// scratchSpaceSizeMultiplierRegex matches the scratch space size multipliers allowed by the CDIConfig CRD
+	scratchSpaceSizeMultiplierRegex = regexp.MustCompile(`^\d+(\.\d{1,3})?$`)
 )
 
 // FakeValidator is a fake token validator
@@ -299,9 +539,9 @@ func (v *FakeValidator) Validate(value string) (*token.Payload, error) {
 	}, nil
 }
 
-// NewCloneTokenValidator returns a new token validator
-func NewCloneTokenValidator(issuer string, key *rsa.PublicKey) token.Validator {
-	return token.NewValidator(issuer, key, cloneTokenLeeway)
+// NewCloneTokenValidator returns a new token validator accepting the tokens signed with any of the keys
+func NewCloneTokenValidator(issuer string, keys ...*rsa.PublicKey) token.Validator {
+	return token.NewMultiKeyValidator(issuer, keys, cloneTokenLeeway)
 }
 
 // GetRequestedImageSize returns the PVC requested size
@@ -361,6 +601,11 @@ func GetFilesystemOverheadForStorageClass(client client.Client, storageClassName
 		return "0", err
 	}
 
+	if cdiConfig.Status.FilesystemOverhead == nil {
+		klog.Errorf("CDIConfig filesystemOverhead used before config controller ran reconcile. Hopefully this only happens during unit testing.")
+		return "0", nil
+	}
+
 	targetStorageClass, err := GetStorageClassByName(client, storageClassName)
 	if err != nil {
 		klog.V(3).Info("Storage class", storageClassName, "not found, trying default storage class")
@@ -371,11 +616,6 @@ func GetFilesystemOverheadForStorageClass(client client.Client, storageClassName
 		}
 	}
 
-	if cdiConfig.Status.FilesystemOverhead == nil {
-		klog.Errorf("CDIConfig filesystemOverhead used before config controller ran reconcile. Hopefully this only happens during unit testing.")
-		return "0", nil
-	}
-
 	if targetStorageClass == nil {
 		klog.V(3).Info("Storage class", storageClassName, "not found, continuing with global overhead")
 		return cdiConfig.Status.FilesystemOverhead.Global, nil
@@ -404,6 +644,134 @@ func GetDefaultPodResourceRequirements(client client.Client) (*v1.ResourceRequir
 	return cdiconfig.Status.DefaultPodResourceRequirements, nil
 }
 
+// podLimitFloors are the lowest CPU and memory limits of the importer, cloner and uploader pods, below which qemu-img
+// and the decompression of the sources cannot run
+var podLimitFloors = []struct {
+	name  v1.ResourceName
+	floor resource.Quantity
+}{
+	{v1.ResourceCPU, resource.MustParse("100m")},
+	{v1.ResourceMemory, resource.MustParse("128Mi")},
+}
+
+// ValidatePodResourceRequirements fails if a CPU or memory limit of the resource requirements of the importer, cloner
+// and uploader pods is below the floor of these pods
+func ValidatePodResourceRequirements(requirements *v1.ResourceRequirements) error {
+	for _, entry := range podLimitFloors {
+		if limit, ok := requirements.Limits[entry.name]; ok && limit.Cmp(entry.floor) < 0 {
+			return errors.Errorf("the %s limit %s is below the minimum of %s", entry.name, limit.String(), entry.floor.String())
+		}
+	}
+	return nil
+}
+
+// podResourceAnnotations are the annotations overriding the resource requirements of the pods populating a PVC
+var podResourceAnnotations = []struct {
+	annotation string
+	name       v1.ResourceName
+	limit      bool
+}{
+	{AnnPodCPURequest, v1.ResourceCPU, false},
+	{AnnPodMemoryRequest, v1.ResourceMemory, false},
+	{AnnPodCPULimit, v1.ResourceCPU, true},
+	{AnnPodMemoryLimit, v1.ResourceMemory, true},
+}
+
+// ParsePodResourceAnnotations returns the resource requirements set by the resource annotations of a DataVolume or a
+// PVC, it fails if they are invalid
+func ParsePodResourceAnnotations(annotations map[string]string) (*v1.ResourceRequirements, error) {
+	requirements := &v1.ResourceRequirements{Limits: v1.ResourceList{}, Requests: v1.ResourceList{}}
+	for _, entry := range podResourceAnnotations {
+		value, ok := annotations[entry.annotation]
+		if !ok {
+			continue
+		}
+		quantity, err := resourceQuantity(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s annotation %q", entry.annotation, value)
+		}
+		if entry.limit {
+			requirements.Limits[entry.name] = quantity
+		} else {
+			requirements.Requests[entry.name] = quantity
+		}
+	}
+	if err := ValidatePodResourceRequirements(requirements); err != nil {
+		return nil, err
+	}
+	return requirements, nil
+}
+
+// resourceQuantity parses a positive quantity of a resource
+func resourceQuantity(value string) (resource.Quantity, error) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return quantity, err
+	}
+	if quantity.Sign() <= 0 {
+		return quantity, errors.New("a positive quantity is expected")
+	}
+	return quantity, nil
+}
+
+// GetPodResourceRequirements returns the resource requirements of a pod populating pvc, the default ones of the
+// CDIConfig overridden by the resource annotations of pvc. A request overridden above its limit raises the limit, a
+// limit overridden below its request lowers the request.
+func GetPodResourceRequirements(client client.Client, pvc *v1.PersistentVolumeClaim) (*v1.ResourceRequirements, error) {
+	requirements, err := GetDefaultPodResourceRequirements(client)
+	if err != nil || pvc == nil {
+		return requirements, err
+	}
+	overrides, err := ParsePodResourceAnnotations(pvc.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	if len(overrides.Limits) == 0 && len(overrides.Requests) == 0 {
+		return requirements, nil
+	}
+	result := &v1.ResourceRequirements{}
+	if requirements != nil {
+		result = requirements.DeepCopy()
+	}
+	if result.Limits == nil {
+		result.Limits = v1.ResourceList{}
+	}
+	if result.Requests == nil {
+		result.Requests = v1.ResourceList{}
+	}
+	for name, quantity := range overrides.Limits {
+		result.Limits[name] = quantity
+	}
+	for name, quantity := range overrides.Requests {
+		result.Requests[name] = quantity
+	}
+	for name, request := range result.Requests {
+		limit, ok := result.Limits[name]
+		if !ok || request.Cmp(limit) <= 0 {
+			continue
+		}
+		if _, overridden := overrides.Requests[name]; overridden {
+			result.Limits[name] = request
+		} else {
+			result.Requests[name] = limit
+		}
+	}
+	return result, nil
+}
+
+// ParseScratchSpaceSizeMultiplier parses the size of the scratch space relative to the size of the PVC, a positive
+// number with up to 3 decimals
+func ParseScratchSpaceSizeMultiplier(value string) (float64, error) {
+	if !scratchSpaceSizeMultiplierRegex.MatchString(value) {
+		return 0, errors.Errorf("invalid scratch space size multiplier %q, a number such as 1.5 is expected", value)
+	}
+	multiplier, err := strconv.ParseFloat(value, 64)
+	if err != nil || multiplier <= 0 {
+		return 0, errors.Errorf("invalid scratch space size multiplier %q, a positive number is expected", value)
+	}
+	return multiplier, nil
+}
+
 // AddVolumeDevices returns VolumeDevice slice with one block device for pods using PV with block volume mode
 func AddVolumeDevices() []v1.VolumeDevice {
 	volumeDevices := []v1.VolumeDevice{
@@ -477,6 +845,113 @@ func GetWorkloadNodePlacement(c client.Client) (*sdkapi.NodePlacement, error) {
 	return &cr.Spec.Workloads, nil
 }
 
+// podPlacementAnnotations are the annotations of the node placement of the pods populating a PVC, and the fields of the
+// placement they are parsed into
+func podPlacementAnnotations(placement *sdkapi.NodePlacement) map[string]interface{} {
+	return map[string]interface{}{
+		AnnPodNodeSelector: &placement.NodeSelector,
+		AnnPodTolerations:  &placement.Tolerations,
+		AnnPodAffinity:     &placement.Affinity,
+	}
+}
+
+// ParsePodPlacementAnnotations returns the node placement set by the scheduling annotations of a DataVolume or a PVC, it
+// fails if they are invalid
+func ParsePodPlacementAnnotations(annotations map[string]string) (*sdkapi.NodePlacement, error) {
+	placement := &sdkapi.NodePlacement{}
+	for annotation, field := range podPlacementAnnotations(placement) {
+		value, ok := annotations[annotation]
+		if !ok {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(field); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s annotation %q", annotation, value)
+		}
+	}
+	return placement, nil
+}
+
+// GetPodNodePlacement returns the node placement of a pod populating pvc, the workload node placement of the CDI CR with
+// the node selector and the tolerations annotated on pvc added, and its affinity replaced by the annotated one
+func GetPodNodePlacement(c client.Client, pvc *v1.PersistentVolumeClaim) (*sdkapi.NodePlacement, error) {
+	workloads, err := GetWorkloadNodePlacement(c)
+	if err != nil {
+		return nil, err
+	}
+	placement := workloads.DeepCopy()
+	overrides, err := ParsePodPlacementAnnotations(pvc.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	if len(overrides.NodeSelector) > 0 {
+		nodeSelector := map[string]string{}
+		for key, value := range placement.NodeSelector {
+			nodeSelector[key] = value
+		}
+		for key, value := range overrides.NodeSelector {
+			nodeSelector[key] = value
+		}
+		placement.NodeSelector = nodeSelector
+	}
+	placement.Tolerations = append(placement.Tolerations, overrides.Tolerations...)
+	if overrides.Affinity != nil {
+		placement.Affinity = overrides.Affinity
+	}
+	return placement, nil
+}
+
+// RequireSelectedNode requires a pod mounting pvc to run on the node the scheduler selected for pvc when it waits for
+// its first consumer, its volume will be provisioned there. A bound pvc is left to the node affinity of its volume.
+func RequireSelectedNode(placement *sdkapi.NodePlacement, pvc *v1.PersistentVolumeClaim) {
+	node, ok := pvc.Annotations[AnnSelectedNode]
+	if !ok || node == "" || pvc.Status.Phase == v1.ClaimBound {
+		return
+	}
+	addRequiredNodeFields(placement, v1.NodeSelectorRequirement{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{node}})
+}
+
+// ExcludeProvisioningFailedNodes keeps a pod mounting pvc off the nodes where the volume of pvc could not be provisioned
+// while it waits for its first consumer. A bound pvc is left to the node affinity of its volume.
+func ExcludeProvisioningFailedNodes(placement *sdkapi.NodePlacement, pvc *v1.PersistentVolumeClaim) {
+	nodes := GetProvisioningFailedNodes(pvc)
+	if len(nodes) == 0 || pvc.Status.Phase == v1.ClaimBound {
+		return
+	}
+	addRequiredNodeFields(placement, v1.NodeSelectorRequirement{Key: "metadata.name", Operator: v1.NodeSelectorOpNotIn, Values: nodes})
+}
+
+// GetProvisioningFailedNodes returns the nodes where the volume of pvc could not be provisioned
+func GetProvisioningFailedNodes(pvc *v1.PersistentVolumeClaim) []string {
+	var nodes []string
+	for _, node := range strings.Split(pvc.Annotations[AnnProvisioningFailedNodes], ",") {
+		if node = strings.TrimSpace(node); node != "" {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// addRequiredNodeFields requires the nodes of a pod to match the field requirement
+func addRequiredNodeFields(placement *sdkapi.NodePlacement, requirement v1.NodeSelectorRequirement) {
+	if placement.Affinity == nil {
+		placement.Affinity = &v1.Affinity{}
+	}
+	if placement.Affinity.NodeAffinity == nil {
+		placement.Affinity.NodeAffinity = &v1.NodeAffinity{}
+	}
+	required := placement.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		required = &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{}}}
+		placement.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = required
+	}
+	// the terms are ORed, the requirement is added to each of them
+	for i := range required.NodeSelectorTerms {
+		required.NodeSelectorTerms[i].MatchFields = append(required.NodeSelectorTerms[i].MatchFields, requirement)
+	}
+}
+
 // GetActiveCDI returns the active CDI CR
 func GetActiveCDI(c client.Client) (*cdiv1.CDI, error) {
 	crList := &cdiv1.CDIList{}
@@ -527,10 +1002,42 @@ func GetPreallocation(client client.Client, dataVolume *cdiv1.DataVolume) bool {
 	return cdiconfig.Status.Preallocation
 }
 
-// GetPriorityClass gets PVC priority class
-func GetPriorityClass(pvc *v1.PersistentVolumeClaim) string {
-	anno := pvc.GetAnnotations()
-	return anno[AnnPriorityClassName]
+// GetDiskFormat returns the format of the disk image written by an import for DV, falling back to the global setting
+func GetDiskFormat(client client.Client, dataVolume *cdiv1.DataVolume) cdiv1.DataVolumeDiskFormat {
+	// First, the DV's disk format
+	if dataVolume.Spec.DiskFormat != "" {
+		return dataVolume.Spec.DiskFormat
+	}
+
+	cdiconfig := &cdiv1.CDIConfig{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiconfig); err != nil {
+		klog.Errorf("Unable to find CDI configuration, %v\n", err)
+		return defaultDiskFormat
+	}
+	if cdiconfig.Status.DiskFormat == "" {
+		return defaultDiskFormat
+	}
+
+	return cdiconfig.Status.DiskFormat
+}
+
+// GetPriorityClass gets the priority class of the pods populating pvc, falling back to the global setting
+func GetPriorityClass(client client.Client, pvc *v1.PersistentVolumeClaim) string {
+	// First, the PVC's priority class
+	if priorityClassName := pvc.GetAnnotations()[AnnPriorityClassName]; priorityClassName != "" {
+		return priorityClassName
+	}
+
+	cdiconfig := &cdiv1.CDIConfig{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiconfig); err != nil {
+		klog.Errorf("Unable to find CDI configuration, %v\n", err)
+		return ""
+	}
+	if cdiconfig.Spec.PodPriorityClassName == nil {
+		return ""
+	}
+
+	return *cdiconfig.Spec.PodPriorityClassName
 }
 
 // ShouldDeletePod returns whether the PVC workload pod should be deleted
@@ -593,6 +1100,10 @@ func ValidateCloneTokenPVC(t string, v token.Validator, source, target *v1.Persi
 // ValidateCloneTokenDV validates clone token for DV
 func ValidateCloneTokenDV(validator token.Validator, dv *cdiv1.DataVolume) error {
 	sourceName, sourceNamespace := GetCloneSourceNameAndNamespace(dv)
+	if file := dv.Spec.Source.File; file != nil {
+		// The file of a PVC of another namespace is imported with a clone token of the PVC
+		sourceName, sourceNamespace = file.PVC, file.Namespace
+	}
 	if sourceNamespace == "" || sourceNamespace == dv.Namespace {
 		return nil
 	}
@@ -620,6 +1131,8 @@ func getTokenResourceNameDataVolume(source *cdiv1.DataVolumeSource) string {
 		return "persistentvolumeclaims"
 	} else if source.Snapshot != nil {
 		return "volumesnapshots"
+	} else if source.File != nil && source.File.PVC != "" {
+		return "persistentvolumeclaims"
 	}
 
 	return ""
@@ -754,6 +1267,9 @@ func AddLabel(obj metav1.Object, key, value string) {
 	obj.GetLabels()[key] = value
 }
 
+// priorityClassNotFound matches the rejection of a pod whose priority class does not exist
+var priorityClassNotFound = regexp.MustCompile(`no PriorityClass with name (\S+) was found`)
+
 // HandleFailedPod handles pod-creation errors and updates the pod's PVC without providing sensitive information
 func HandleFailedPod(err error, podName string, pvc *v1.PersistentVolumeClaim, recorder record.EventRecorder, c client.Client) error {
 	if err == nil {
@@ -766,6 +1282,9 @@ func HandleFailedPod(err error, podName string, pvc *v1.PersistentVolumeClaim, r
 	// Error handling to fine-tune the event with pertinent info
 	if ErrQuotaExceeded(err) {
 		reason = ErrExceededQuota
+	} else if match := priorityClassNotFound.FindStringSubmatch(err.Error()); match != nil {
+		reason = ErrPriorityClassNotFound
+		msg = fmt.Sprintf(MessageErrPriorityClassNotFound, podName, match[1])
 	}
 
 	recorder.Event(pvc, v1.EventTypeWarning, reason, msg)
@@ -798,7 +1317,12 @@ func GetSource(pvc *corev1.PersistentVolumeClaim) string {
 	case
 		SourceHTTP,
 		SourceS3,
+		SourceGCS,
+		SourceSFTP,
 		SourceGlance,
+		SourceFile,
+		SourceNFS,
+		SourceSMB,
 		SourceNone,
 		SourceRegistry,
 		SourceImageio,
@@ -1133,13 +1657,33 @@ func IsErrCacheNotStarted(err error) bool {
 
 // GetDataVolumeTTLSeconds gets the current DataVolume TTL in seconds if GC is enabled, or < 0 if GC is disabled
 func GetDataVolumeTTLSeconds(config *cdiv1.CDIConfig) int32 {
-	const defaultDataVolumeTTLSeconds = 0
+	const defaultDataVolumeTTLSeconds = -1
 	if config.Spec.DataVolumeTTLSeconds != nil {
 		return *config.Spec.DataVolumeTTLSeconds
 	}
 	return defaultDataVolumeTTLSeconds
 }
 
+// GetDataVolumeTTLSecondsForDataVolume gets the TTL in seconds of the DataVolume, set by its AnnTTLSecondsAfterCompletion
+// annotation or else by the CDIConfig, or < 0 if GC is disabled for the DataVolume. An invalid annotation is ignored.
+func GetDataVolumeTTLSecondsForDataVolume(config *cdiv1.CDIConfig, dv *cdiv1.DataVolume) int32 {
+	if value, ok := dv.Annotations[AnnTTLSecondsAfterCompletion]; ok {
+		if ttl, err := ParseTTLSecondsAfterCompletion(value); err == nil {
+			return ttl
+		}
+	}
+	return GetDataVolumeTTLSeconds(config)
+}
+
+// ParseTTLSecondsAfterCompletion parses the AnnTTLSecondsAfterCompletion annotation, a number of seconds, -1 disabling GC
+func ParseTTLSecondsAfterCompletion(value string) (int32, error) {
+	ttl, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || ttl < -1 {
+		return 0, errors.Errorf("invalid %s %q, a number of seconds or -1 is expected", AnnTTLSecondsAfterCompletion, value)
+	}
+	return int32(ttl), nil
+}
+
 // NewImportDataVolume returns new import DataVolume CR
 func NewImportDataVolume(name string) *cdiv1.DataVolume {
 	return &cdiv1.DataVolume{
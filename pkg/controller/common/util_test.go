@@ -5,8 +5,13 @@ import (
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
 )
 
 var _ = Describe("GetRequestedImageSize", func() {
@@ -75,6 +80,243 @@ var _ = Describe("GetDefaultStorageClass", func() {
 	})
 })
 
+var _ = Describe("GetFilesystemOverheadForStorageClass", func() {
+	createConfig := func(overhead *cdiv1.FilesystemOverhead) *cdiv1.CDIConfig {
+		config := MakeEmptyCDIConfigSpec(common.ConfigName)
+		config.Status.FilesystemOverhead = overhead
+		return config
+	}
+	overhead := &cdiv1.FilesystemOverhead{
+		Global:       "0.1",
+		StorageClass: map[string]cdiv1.Percent{"local": "0.2", "ceph": "0.05"},
+	}
+
+	table.DescribeTable("Should return", func(storageClassName *string, defaultStorageClass string, expected cdiv1.Percent) {
+		objs := []runtime.Object{createConfig(overhead), CreateStorageClass("local", nil)}
+		if defaultStorageClass != "" {
+			objs = append(objs, CreateStorageClass(defaultStorageClass, map[string]string{AnnDefaultStorageClass: "true"}))
+		}
+		result, err := GetFilesystemOverheadForStorageClass(CreateClient(objs...), storageClassName)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(expected))
+	},
+		table.Entry("the overhead of the storage class", pointer.String("local"), "", cdiv1.Percent("0.2")),
+		table.Entry("the overhead of the default storage class without storage class", nil, "ceph", cdiv1.Percent("0.05")),
+		table.Entry("the overhead of the default storage class for a missing storage class", pointer.String("missing"), "ceph", cdiv1.Percent("0.05")),
+		table.Entry("the global overhead for a missing storage class without default storage class", pointer.String("missing"), "", cdiv1.Percent("0.1")),
+	)
+
+	It("Should return no overhead before the CDIConfig is reconciled", func() {
+		client := CreateClient(createConfig(nil))
+		result, err := GetFilesystemOverheadForStorageClass(client, pointer.String("missing"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(cdiv1.Percent("0")))
+	})
+})
+
+var _ = Describe("ParsePodResourceAnnotations", func() {
+	It("Should return the requests and limits of the annotations", func() {
+		requirements, err := ParsePodResourceAnnotations(map[string]string{
+			AnnPodCPURequest:    "250m",
+			AnnPodMemoryRequest: "256Mi",
+			AnnPodCPULimit:      "1",
+			AnnPodMemoryLimit:   "1Gi",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requirements.Requests).To(Equal(v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("250m"),
+			v1.ResourceMemory: resource.MustParse("256Mi"),
+		}))
+		Expect(requirements.Limits).To(Equal(v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("1"),
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+		}))
+	})
+
+	table.DescribeTable("Should fail with", func(annotation, value, expectedErr string) {
+		_, err := ParsePodResourceAnnotations(map[string]string{annotation: value})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErr))
+	},
+		table.Entry("an invalid quantity", AnnPodCPURequest, "lots", "invalid "+AnnPodCPURequest+" annotation"),
+		table.Entry("a zero quantity", AnnPodMemoryRequest, "0", "a positive quantity is expected"),
+		table.Entry("a CPU limit below the minimum", AnnPodCPULimit, "50m", "the cpu limit 50m is below the minimum of 100m"),
+		table.Entry("a memory limit below the minimum", AnnPodMemoryLimit, "64Mi", "the memory limit 64Mi is below the minimum of 128Mi"),
+	)
+})
+
+var _ = Describe("GetPodResourceRequirements", func() {
+	createConfig := func() *cdiv1.CDIConfig {
+		config := MakeEmptyCDIConfigSpec(common.ConfigName)
+		config.Status.DefaultPodResourceRequirements = &v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("100m"),
+				v1.ResourceMemory: resource.MustParse("60M"),
+			},
+			Limits: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("750m"),
+				v1.ResourceMemory: resource.MustParse("600M"),
+			},
+		}
+		return config
+	}
+
+	It("Should return the default requirements of a PVC without annotations", func() {
+		config := createConfig()
+		requirements, err := GetPodResourceRequirements(CreateClient(config), CreatePvc("testPVC", "default", nil, nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requirements).To(Equal(config.Status.DefaultPodResourceRequirements))
+	})
+
+	It("Should override the default requirements with the annotations of the PVC", func() {
+		pvc := CreatePvc("testPVC", "default", map[string]string{AnnPodCPULimit: "2", AnnPodMemoryRequest: "1G"}, nil)
+		requirements, err := GetPodResourceRequirements(CreateClient(createConfig()), pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requirements.Requests).To(Equal(v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("100m"),
+			v1.ResourceMemory: resource.MustParse("1G"),
+		}))
+		// the memory limit is raised to the request
+		Expect(requirements.Limits).To(Equal(v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("2"),
+			v1.ResourceMemory: resource.MustParse("1G"),
+		}))
+	})
+
+	It("Should lower the default request to a limit overridden below it", func() {
+		pvc := CreatePvc("testPVC", "default", map[string]string{AnnPodCPULimit: "100m", AnnPodMemoryLimit: "128Mi"}, nil)
+		config := createConfig()
+		config.Status.DefaultPodResourceRequirements.Requests[v1.ResourceCPU] = resource.MustParse("500m")
+		requirements, err := GetPodResourceRequirements(CreateClient(config), pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requirements.Requests).To(Equal(v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("100m"),
+			v1.ResourceMemory: resource.MustParse("60M"),
+		}))
+	})
+
+	It("Should fail with invalid annotations", func() {
+		pvc := CreatePvc("testPVC", "default", map[string]string{AnnPodMemoryLimit: "1Mi"}, nil)
+		_, err := GetPodResourceRequirements(CreateClient(createConfig()), pvc)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("GetPriorityClass", func() {
+	createConfig := func(priorityClassName string) *cdiv1.CDIConfig {
+		config := MakeEmptyCDIConfigSpec(common.ConfigName)
+		config.Spec.PodPriorityClassName = pointer.String(priorityClassName)
+		return config
+	}
+
+	It("Should return the priority class of the PVC", func() {
+		pvc := CreatePvc("testPVC", "default", map[string]string{AnnPriorityClassName: "p0"}, nil)
+		Expect(GetPriorityClass(CreateClient(createConfig("importers")), pvc)).To(Equal("p0"))
+	})
+
+	It("Should fall back to the priority class of the CDIConfig", func() {
+		pvc := CreatePvc("testPVC", "default", nil, nil)
+		Expect(GetPriorityClass(CreateClient(createConfig("importers")), pvc)).To(Equal("importers"))
+	})
+
+	It("Should return no priority class without CDIConfig", func() {
+		pvc := CreatePvc("testPVC", "default", nil, nil)
+		Expect(GetPriorityClass(CreateClient(), pvc)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GetPodNodePlacement", func() {
+	createCDI := func() *cdiv1.CDI {
+		cdi := MakeEmptyCDICR()
+		cdi.Spec.Workloads.NodeSelector = map[string]string{"kubernetes.io/os": "linux", "disk": "any"}
+		cdi.Spec.Workloads.Tolerations = []v1.Toleration{{Key: "storage", Operator: v1.TolerationOpExists}}
+		return cdi
+	}
+
+	It("Should return the workload placement of a PVC without annotations", func() {
+		cdi := createCDI()
+		placement, err := GetPodNodePlacement(CreateClient(cdi), CreatePvc("testPVC", "default", nil, nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*placement).To(Equal(cdi.Spec.Workloads))
+	})
+
+	It("Should add the annotated node selector and tolerations, and override the affinity", func() {
+		pvc := CreatePvc("testPVC", "default", map[string]string{
+			AnnPodNodeSelector: `{"disk":"ssd"}`,
+			AnnPodTolerations:  `[{"key":"gpu","operator":"Exists","effect":"NoSchedule"}]`,
+			AnnPodAffinity:     `{"nodeAffinity":{"preferredDuringSchedulingIgnoredDuringExecution":[{"weight":1,"preference":{"matchExpressions":[{"key":"zone","operator":"In","values":["a"]}]}}]}}`,
+		}, nil)
+		placement, err := GetPodNodePlacement(CreateClient(createCDI()), pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(placement.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux", "disk": "ssd"}))
+		Expect(placement.Tolerations).To(Equal([]v1.Toleration{
+			{Key: "storage", Operator: v1.TolerationOpExists},
+			{Key: "gpu", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		}))
+		Expect(placement.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].Preference.MatchExpressions[0].Key).To(Equal("zone"))
+	})
+
+	table.DescribeTable("Should fail with", func(annotation, value string) {
+		pvc := CreatePvc("testPVC", "default", map[string]string{annotation: value}, nil)
+		_, err := GetPodNodePlacement(CreateClient(createCDI()), pvc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid " + annotation + " annotation"))
+	},
+		table.Entry("a node selector that is not a map of labels", AnnPodNodeSelector, `["disk"]`),
+		table.Entry("tolerations that are not a list", AnnPodTolerations, `{"key":"gpu"}`),
+		table.Entry("an affinity with an unknown field", AnnPodAffinity, `{"nodeAfinity":{}}`),
+	)
+})
+
+var _ = Describe("RequireSelectedNode", func() {
+	It("Should require the node selected for an unbound PVC in each node selector term", func() {
+		pvc := CreatePvcInStorageClass("testPVC", "default", nil, map[string]string{AnnSelectedNode: "node01"}, nil, v1.ClaimPending)
+		placement := &sdkapi.NodePlacement{Affinity: &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{
+				{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}}}},
+				{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"b"}}}},
+			}},
+		}}}
+		RequireSelectedNode(placement, pvc)
+		selectedNode := v1.NodeSelectorRequirement{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{"node01"}}
+		for _, term := range placement.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			Expect(term.MatchFields).To(ConsistOf(selectedNode))
+		}
+	})
+
+	It("Should require the node selected for a PVC of a pod without affinity", func() {
+		pvc := CreatePvcInStorageClass("testPVC", "default", nil, map[string]string{AnnSelectedNode: "node01"}, nil, v1.ClaimPending)
+		placement := &sdkapi.NodePlacement{}
+		RequireSelectedNode(placement, pvc)
+		Expect(placement.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(HaveLen(1))
+	})
+
+	It("Should leave a bound PVC to the node affinity of its volume", func() {
+		pvc := CreatePvc("testPVC", "default", map[string]string{AnnSelectedNode: "node01"}, nil)
+		placement := &sdkapi.NodePlacement{}
+		RequireSelectedNode(placement, pvc)
+		Expect(placement.Affinity).To(BeNil())
+	})
+})
+
+var _ = Describe("ExcludeProvisioningFailedNodes", func() {
+	It("Should exclude the nodes where the volume of an unbound PVC could not be provisioned", func() {
+		pvc := CreatePvcInStorageClass("testPVC", "default", nil, map[string]string{AnnProvisioningFailedNodes: "node01, node02"}, nil, v1.ClaimPending)
+		placement := &sdkapi.NodePlacement{}
+		ExcludeProvisioningFailedNodes(placement, pvc)
+		terms := placement.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		Expect(terms).To(HaveLen(1))
+		Expect(terms[0].MatchFields).To(ConsistOf(v1.NodeSelectorRequirement{Key: "metadata.name", Operator: v1.NodeSelectorOpNotIn, Values: []string{"node01", "node02"}}))
+	})
+
+	It("Should leave a bound PVC to the node affinity of its volume", func() {
+		pvc := CreatePvc("testPVC", "default", map[string]string{AnnProvisioningFailedNodes: "node01"}, nil)
+		placement := &sdkapi.NodePlacement{}
+		ExcludeProvisioningFailedNodes(placement, pvc)
+		Expect(placement.Affinity).To(BeNil())
+	})
+})
+
 func createPvcNoSize(name, ns string, annotations, labels map[string]string) *v1.PersistentVolumeClaim {
 	return &v1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -38,13 +39,17 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
-// AnnConfigAuthority is the annotation specifying a resource as the CDIConfig authority
 const (
-	AnnConfigAuthority = "cdi.kubevirt.io/configAuthority"
-
 	errResourceDoesntExist     = "ErrResourceDoesntExist"
 	messageResourceDoesntExist = "Resource managed by %q doesn't exist"
 
+	// InvalidPodResourceRequirements is the reason of the event of podResourceRequirements of the CDIConfig ignored
+	// for being invalid
+	InvalidPodResourceRequirements = "InvalidPodResourceRequirements"
+	// MessageInvalidPodResourceRequirements is the message of the event of podResourceRequirements of the CDIConfig
+	// ignored for being invalid
+	MessageInvalidPodResourceRequirements = "The podResourceRequirements of the CDIConfig are ignored, the default ones are used: %v"
+
 	defaultCPULimit   = "750m"
 	defaultMemLimit   = "600M"
 	defaultCPURequest = "100m"
@@ -79,6 +84,10 @@ func (r *CDIConfigReconciler) Reconcile(_ context.Context, req reconcile.Request
 	currentConfigCopy := config.DeepCopyObject()
 
 	config.Status.Preallocation = config.Spec.Preallocation != nil && *config.Spec.Preallocation
+	config.Status.DiskFormat = cdiv1.DataVolumeDiskFormatRaw
+	if config.Spec.DiskFormat != nil && *config.Spec.DiskFormat != "" {
+		config.Status.DiskFormat = *config.Spec.DiskFormat
+	}
 
 	// ignore whatever is in config spec and set to operator view
 	if err := r.setOperatorParams(config); err != nil {
@@ -93,6 +102,9 @@ func (r *CDIConfigReconciler) Reconcile(_ context.Context, req reconcile.Request
 		return reconcile.Result{}, err
 	}
 
+	r.reconcileScratchSpaceSizeMultiplier(config)
+	reconcileImportDefaults(config)
+
 	if err := r.reconcileDefaultPodResourceRequirements(config); err != nil {
 		return reconcile.Result{}, err
 	}
@@ -128,7 +140,7 @@ func (r *CDIConfigReconciler) setOperatorParams(config *cdiv1.CDIConfig) error {
 		return nil
 	}
 
-	if _, ok := cdiCR.Annotations[AnnConfigAuthority]; !ok {
+	if _, ok := cdiCR.Annotations[cc.AnnConfigAuthority]; !ok {
 		return nil
 	}
 
@@ -232,6 +244,38 @@ func (r *CDIConfigReconciler) reconcileStorageClass(config *cdiv1.CDIConfig) err
 	return nil
 }
 
+func (r *CDIConfigReconciler) reconcileScratchSpaceSizeMultiplier(config *cdiv1.CDIConfig) {
+	config.Status.ScratchSpaceSizeMultiplier = common.DefaultScratchSpaceSizeMultiplier
+	if config.Spec.ScratchSpaceSizeMultiplier == nil {
+		return
+	}
+	if _, err := cc.ParseScratchSpaceSizeMultiplier(*config.Spec.ScratchSpaceSizeMultiplier); err != nil {
+		r.log.Error(err, "Ignoring the scratchSpaceSizeMultiplier")
+		return
+	}
+	config.Status.ScratchSpaceSizeMultiplier = *config.Spec.ScratchSpaceSizeMultiplier
+}
+
+// reconcileImportDefaults reports the default bandwidth limit and retry policy the importer pods are created with,
+// resolved from the spec as the import controller does
+func reconcileImportDefaults(config *cdiv1.CDIConfig) {
+	config.Status.ImportBandwidthLimit = nil
+	if limit := config.Spec.ImportBandwidthLimit; limit != nil && limit.Sign() > 0 {
+		resolved := limit.DeepCopy()
+		config.Status.ImportBandwidthLimit = &resolved
+	}
+	config.Status.ImportRetryPolicy = nil
+	if policy, set := importRetryPolicyFor(&v1.PersistentVolumeClaim{}, config); set {
+		config.Status.ImportRetryPolicy = &cdiv1.ImportRetryPolicy{
+			InitialBackoff: &metav1.Duration{Duration: policy.initialBackoff},
+			MaxBackoff:     &metav1.Duration{Duration: policy.maxBackoff},
+		}
+		if policy.maxRetries >= 0 {
+			config.Status.ImportRetryPolicy.MaxRetries = pointer.Int32(int32(policy.maxRetries))
+		}
+	}
+}
+
 func (r *CDIConfigReconciler) reconcileDefaultPodResourceRequirements(config *cdiv1.CDIConfig) error {
 	cpuLimit, _ := resource.ParseQuantity(defaultCPULimit)
 	memLimit, _ := resource.ParseQuantity(defaultMemLimit)
@@ -249,6 +293,12 @@ func (r *CDIConfigReconciler) reconcileDefaultPodResourceRequirements(config *cd
 	}
 
 	if config.Spec.PodResourceRequirements != nil {
+		// the pods requiring too few resources would get OOM killed, the default requirements are kept
+		if err := cc.ValidatePodResourceRequirements(config.Spec.PodResourceRequirements); err != nil {
+			r.log.Error(err, "Invalid podResourceRequirements")
+			r.recorder.Event(config, v1.EventTypeWarning, InvalidPodResourceRequirements, fmt.Sprintf(MessageInvalidPodResourceRequirements, err))
+			return nil
+		}
 		if config.Spec.PodResourceRequirements.Limits != nil {
 			if cpu, exist := config.Spec.PodResourceRequirements.Limits[v1.ResourceCPU]; exist {
 				config.Status.DefaultPodResourceRequirements.Limits[v1.ResourceCPU] = cpu
@@ -292,6 +342,8 @@ func (r *CDIConfigReconciler) reconcileFilesystemOverhead(config *cdiv1.CDIConfi
 	if config.Spec.FilesystemOverhead != nil {
 		if valid, _ := validOverhead(config.Spec.FilesystemOverhead.Global); valid {
 			globalOverhead = config.Spec.FilesystemOverhead.Global
+		} else if config.Spec.FilesystemOverhead.Global != "" {
+			log.Info("Ignoring invalid global filesystem overhead", "overhead", config.Spec.FilesystemOverhead.Global)
 		}
 		if config.Spec.FilesystemOverhead.StorageClass != nil {
 			perStorageConfig = config.Spec.FilesystemOverhead.StorageClass
@@ -312,14 +364,15 @@ func (r *CDIConfigReconciler) reconcileFilesystemOverhead(config *cdiv1.CDIConfi
 		storageClassNameOverhead, found := perStorageConfig[storageClassName]
 
 		if found {
-			valid, err := validOverhead(storageClassNameOverhead)
-			if !valid {
-				return err
+			// an invalid value of a storage class falls back to the global one, rather than leaving the
+			// following storage classes out of the status
+			if valid, _ := validOverhead(storageClassNameOverhead); valid {
+				config.Status.FilesystemOverhead.StorageClass[storageClassName] = storageClassNameOverhead
+				continue
 			}
-			config.Status.FilesystemOverhead.StorageClass[storageClassName] = storageClassNameOverhead
-		} else {
-			config.Status.FilesystemOverhead.StorageClass[storageClassName] = globalOverhead
+			log.Info("Ignoring invalid filesystem overhead of storage class", "storageClass", storageClassName, "overhead", storageClassNameOverhead)
 		}
+		config.Status.FilesystemOverhead.StorageClass[storageClassName] = globalOverhead
 	}
 
 	return nil
@@ -18,6 +18,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -36,6 +37,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -323,6 +325,103 @@ var _ = Describe("Controller storage class reconcile loop", func() {
 	})
 })
 
+var _ = Describe("Controller filesystem overhead reconcile loop", func() {
+	createReconciler := func() (*CDIConfigReconciler, *cdiv1.CDIConfig) {
+		return createConfigReconciler(createStorageClassList(
+			*CreateStorageClass("local", nil),
+			*CreateStorageClass("ceph", nil),
+			*CreateStorageClass("nfs", nil),
+		))
+	}
+
+	It("Should set the global overhead to all the storage classes by default", func() {
+		reconciler, cdiConfig := createReconciler()
+		Expect(reconciler.reconcileFilesystemOverhead(cdiConfig)).To(Succeed())
+		Expect(cdiConfig.Status.FilesystemOverhead.Global).To(BeEquivalentTo(common.DefaultGlobalOverhead))
+		Expect(cdiConfig.Status.FilesystemOverhead.StorageClass).To(Equal(map[string]cdiv1.Percent{
+			"local": common.DefaultGlobalOverhead,
+			"ceph":  common.DefaultGlobalOverhead,
+			"nfs":   common.DefaultGlobalOverhead,
+		}))
+	})
+
+	It("Should set the overhead of the storage classes to the overrides, and the others to the global one", func() {
+		reconciler, cdiConfig := createReconciler()
+		cdiConfig.Spec.FilesystemOverhead = &cdiv1.FilesystemOverhead{
+			Global:       "0.1",
+			StorageClass: map[string]cdiv1.Percent{"local": "0.2", "missing": "0.3"},
+		}
+		Expect(reconciler.reconcileFilesystemOverhead(cdiConfig)).To(Succeed())
+		Expect(cdiConfig.Status.FilesystemOverhead.Global).To(BeEquivalentTo("0.1"))
+		Expect(cdiConfig.Status.FilesystemOverhead.StorageClass).To(Equal(map[string]cdiv1.Percent{
+			"local": "0.2",
+			"ceph":  "0.1",
+			"nfs":   "0.1",
+		}))
+	})
+
+	It("Should set the global overhead to the storage classes of invalid overrides", func() {
+		reconciler, cdiConfig := createReconciler()
+		cdiConfig.Spec.FilesystemOverhead = &cdiv1.FilesystemOverhead{
+			Global:       "1.5",
+			StorageClass: map[string]cdiv1.Percent{"ceph": "ten", "nfs": "0.08"},
+		}
+		Expect(reconciler.reconcileFilesystemOverhead(cdiConfig)).To(Succeed())
+		Expect(cdiConfig.Status.FilesystemOverhead.Global).To(BeEquivalentTo(common.DefaultGlobalOverhead))
+		Expect(cdiConfig.Status.FilesystemOverhead.StorageClass).To(Equal(map[string]cdiv1.Percent{
+			"local": common.DefaultGlobalOverhead,
+			"ceph":  common.DefaultGlobalOverhead,
+			"nfs":   "0.08",
+		}))
+	})
+})
+
+var _ = Describe("Controller scratch space size multiplier reconcile loop", func() {
+	DescribeTable("Should set the scratchSpaceSizeMultiplier", func(override *string, expected string) {
+		reconciler, cdiConfig := createConfigReconciler()
+		cdiConfig.Spec.ScratchSpaceSizeMultiplier = override
+		reconciler.reconcileScratchSpaceSizeMultiplier(cdiConfig)
+		Expect(cdiConfig.Status.ScratchSpaceSizeMultiplier).To(Equal(expected))
+	},
+		Entry("to the default without override", nil, common.DefaultScratchSpaceSizeMultiplier),
+		Entry("to the override", pointer.String("1.5"), "1.5"),
+		Entry("to an override below 1", pointer.String("0.25"), "0.25"),
+		Entry("to the default with an override of zero", pointer.String("0"), common.DefaultScratchSpaceSizeMultiplier),
+		Entry("to the default with an invalid override", pointer.String("twice"), common.DefaultScratchSpaceSizeMultiplier),
+	)
+})
+
+var _ = Describe("Controller import defaults reconcile loop", func() {
+	It("Should not report defaults without a bandwidth limit and retry policy", func() {
+		_, cdiConfig := createConfigReconciler()
+		zero := resource.MustParse("0")
+		cdiConfig.Spec.ImportBandwidthLimit = &zero
+		reconcileImportDefaults(cdiConfig)
+		Expect(cdiConfig.Status.ImportBandwidthLimit).To(BeNil())
+		Expect(cdiConfig.Status.ImportRetryPolicy).To(BeNil())
+	})
+
+	It("Should report the bandwidth limit and the retry policy with its defaults", func() {
+		_, cdiConfig := createConfigReconciler()
+		limit := resource.MustParse("100Mi")
+		cdiConfig.Spec.ImportBandwidthLimit = &limit
+		cdiConfig.Spec.ImportRetryPolicy = &cdiv1.ImportRetryPolicy{MaxRetries: pointer.Int32(3)}
+		reconcileImportDefaults(cdiConfig)
+		Expect(cdiConfig.Status.ImportBandwidthLimit.String()).To(Equal("100Mi"))
+		Expect(cdiConfig.Status.ImportRetryPolicy).To(Equal(&cdiv1.ImportRetryPolicy{
+			MaxRetries:     pointer.Int32(3),
+			InitialBackoff: &metav1.Duration{Duration: defaultImportInitialBackoff},
+			MaxBackoff:     &metav1.Duration{Duration: defaultImportMaxBackoff},
+		}))
+
+		By("Not limiting the retries without maxRetries")
+		cdiConfig.Spec.ImportRetryPolicy = &cdiv1.ImportRetryPolicy{MaxBackoff: &metav1.Duration{Duration: time.Hour}}
+		reconcileImportDefaults(cdiConfig)
+		Expect(cdiConfig.Status.ImportRetryPolicy.MaxRetries).To(BeNil())
+		Expect(cdiConfig.Status.ImportRetryPolicy.MaxBackoff.Duration).To(Equal(time.Hour))
+	})
+})
+
 var _ = Describe("Controller ImportProxy reconcile loop", func() {
 	It("Should set ImportProxy to nil if no proxy configuration for import proxy exists", func() {
 		reconciler, cdiConfig := createConfigReconciler()
@@ -681,12 +780,12 @@ var _ = Describe("Controller default pod resource requirements reconcile loop",
 	var (
 		testValueCPULimit   = "10"
 		testValueCPURequest = "4"
-		testValueMemLimit   = "10M"
+		testValueMemLimit   = "1G"
 		testValueMemRequest = "4M"
 	)
 
 	It("Should set the defaultPodResourceRequirements to the override value", func() {
-		defaultResourceRequirements := createDefaultPodResourceRequirements("1", "2", "3000M", "4000M")
+		defaultResourceRequirements := createDefaultPodResourceRequirements("1", "2G", "3000M", "4000M")
 
 		reconciler, cdiConfig := createConfigReconciler()
 		cdiConfig.Spec.PodResourceRequirements = defaultResourceRequirements
@@ -781,6 +880,21 @@ var _ = Describe("Controller default pod resource requirements reconcile loop",
 		Expect(err).ToNot(HaveOccurred())
 		Expect(cdiConfig.Status.DefaultPodResourceRequirements).To(Equal(createDefaultPodResourceRequirements("", "", "", testValueMemRequest)))
 	})
+
+	DescribeTable("Should keep the default defaultPodResourceRequirements and report", func(limitCPU, limitMemory string) {
+		reconciler, cdiConfig := createConfigReconciler()
+		cdiConfig.Spec.PodResourceRequirements = createDefaultPodResourceRequirements(limitCPU, limitMemory, "", "")
+
+		err := reconciler.reconcileDefaultPodResourceRequirements(cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cdiConfig.Status.DefaultPodResourceRequirements).To(Equal(createDefaultPodResourceRequirements("", "", "", "")))
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(InvalidPodResourceRequirements))
+		Expect(event).To(ContainSubstring("limit " + limitCPU + limitMemory + " is below the minimum"))
+	},
+		Entry("a CPU limit below the floor", "50m", ""),
+		Entry("a memory limit below the floor", "", "64Mi"),
+	)
 })
 
 var _ = Describe("getClusterWideProxy", func() {
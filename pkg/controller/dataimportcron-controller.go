@@ -938,7 +938,7 @@ func (r *DataImportCronReconciler) newSourceDataVolume(cron *cdiv1.DataImportCro
 	dv.Name = dataVolumeName
 	dv.Namespace = cron.Namespace
 	r.setDataImportCronResourceLabels(cron, dv)
-	passCronAnnotationToDv(cron, dv, AnnImmediateBinding)
+	passCronAnnotationToDv(cron, dv, cc.AnnImmediateBinding)
 	passCronAnnotationToDv(cron, dv, cc.AnnPodRetainAfterCompletion)
 
 	passCronLabelToDv(cron, dv, cc.LabelDefaultInstancetype)
@@ -19,9 +19,11 @@ package datavolume
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +33,7 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/common"
 
 	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+	"kubevirt.io/containerized-data-importer/pkg/monitoring"
 
 	"kubevirt.io/containerized-data-importer/pkg/token"
 	"kubevirt.io/containerized-data-importer/pkg/util"
@@ -60,6 +63,8 @@ const (
 	SnapshotForSmartCloneCreated = "SnapshotForSmartCloneCreated"
 	// SmartClonePVCInProgress provides a const to indicate snapshot creation for smart-clone is in progress
 	SmartClonePVCInProgress = "SmartClonePVCInProgress"
+	// SmartCloneSnapshotFailed provides a const to indicate the snapshot for smart-clone is in error
+	SmartCloneSnapshotFailed = "SmartCloneSnapshotFailed"
 	// SmartCloneSourceInUse provides a const to indicate a smart clone is being delayed because the source is in use
 	SmartCloneSourceInUse = "SmartCloneSourceInUse"
 	// CSICloneInProgress provides a const to indicate  csi volume clone is in progress
@@ -87,6 +92,8 @@ const (
 	MessageCloneFromSnapshotSourceInProgress = "Creating PVC from snapshot source is in progress (for snapshot %s/%s)"
 	// MessageSmartClonePVCInProgress provides a const to form snapshot for smart-clone is in progress message
 	MessageSmartClonePVCInProgress = "Creating PVC for smart-clone is in progress (for pvc %s/%s)"
+	// MessageSmartCloneSnapshotFailed provides a const to form snapshot for smart-clone is in error message
+	MessageSmartCloneSnapshotFailed = "Creating snapshot for smart-clone failed (for pvc %s/%s): %s"
 	// MessageCsiCloneInProgress provides a const to form a CSI Volume Clone in progress message
 	MessageCsiCloneInProgress = "CSI Volume clone in progress (for pvc %s/%s)"
 
@@ -118,6 +125,10 @@ const (
 	CloneWithoutSource = "CloneWithoutSource"
 	// MessageCloneWithoutSource reports that the source of a clone doesn't exists (message)
 	MessageCloneWithoutSource = "The source %s %s doesn't exist"
+	// InvalidCloneToken reports that the clone token of a DataVolume is missing, expired or doesn't authorize the clone (reason)
+	InvalidCloneToken = "InvalidCloneToken"
+	// MessageInvalidCloneToken reports that the clone token of a DataVolume is missing, expired or doesn't authorize the clone (message)
+	MessageInvalidCloneToken = "The clone token of DataVolume %s/%s is invalid: %v"
 
 	// AnnCSICloneRequest annotation associates object with CSI Clone Request
 	AnnCSICloneRequest = "cdi.kubevirt.io/CSICloneRequest"
@@ -133,17 +144,26 @@ const (
 	annReadyForTransfer = "cdi.kubevirt.io/readyForTransfer"
 
 	annCloneType = "cdi.kubevirt.io/cloneType"
+
+	// prometheusCloneTypeLabel is the label of the clone duration holding the type of the clone
+	prometheusCloneTypeLabel = "type"
 )
 
-type statusPhaseSync struct {
-	phase cdiv1.DataVolumePhase
-	pvc   *corev1.PersistentVolumeClaim
-	event Event
-}
+var (
+	// CloneDurationHistogram observes the duration of the clones of DataVolumes which succeeded, by clone type
+	CloneDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: monitoring.MetricOptsList[monitoring.CloneDuration].Name,
+			Help: monitoring.MetricOptsList[monitoring.CloneDuration].Help,
+			// from 10 seconds to more than 5 hours
+			Buckets: prometheus.ExponentialBuckets(10, 2, 12),
+		},
+		[]string{prometheusCloneTypeLabel},
+	)
+)
 
 type dataVolumeCloneSyncResult struct {
 	dataVolumeSyncResult
-	phaseSync *statusPhaseSync
 }
 
 // CloneReconcilerBase members
@@ -156,7 +176,7 @@ type CloneReconcilerBase struct {
 	tokenGenerator token.Generator
 }
 
-func (r *CloneReconcilerBase) ensureExtendedToken(pvc *corev1.PersistentVolumeClaim) error {
+func (r *CloneReconcilerBase) ensureExtendedToken(dv *cdiv1.DataVolume, pvc *corev1.PersistentVolumeClaim) error {
 	_, ok := pvc.Annotations[cc.AnnExtendedCloneToken]
 	if ok {
 		return nil
@@ -169,6 +189,7 @@ func (r *CloneReconcilerBase) ensureExtendedToken(pvc *corev1.PersistentVolumeCl
 
 	payload, err := r.tokenValidator.Validate(token)
 	if err != nil {
+		r.recorder.Eventf(dv, corev1.EventTypeWarning, InvalidCloneToken, MessageInvalidCloneToken, dv.Namespace, dv.Name, err)
 		return err
 	}
 
@@ -252,7 +273,7 @@ func (r *CloneReconcilerBase) doCrossNamespaceClone(log logr.Logger,
 	returnWhenCloneInProgress bool,
 	selectedCloneStrategy cloneStrategy) (*reconcile.Result, error) {
 
-	initialized, err := r.initTransfer(log, syncRes, pvcName, sourceNamespace)
+	initialized, err := r.initTransfer(log, syncRes.dvMutated, r.tokenValidator, pvcName, sourceNamespace)
 	if err != nil {
 		return &reconcile.Result{}, err
 	}
@@ -274,6 +295,9 @@ func (r *CloneReconcilerBase) doCrossNamespaceClone(log logr.Logger,
 	} else {
 		// AnnCloneOf != true, so cloneInProgress
 		if returnWhenCloneInProgress {
+			if selectedCloneStrategy == SmartClone {
+				return &reconcile.Result{}, r.syncCloneStatusPhase(syncRes, cdiv1.SmartClonePVCInProgress, nil)
+			}
 			return &reconcile.Result{}, nil
 		}
 	}
@@ -281,9 +305,8 @@ func (r *CloneReconcilerBase) doCrossNamespaceClone(log logr.Logger,
 	return nil, nil
 }
 
-func (r *CloneReconcilerBase) initTransfer(log logr.Logger, syncRes *dataVolumeCloneSyncResult, name, namespace string) (bool, error) {
+func (r *ReconcilerBase) initTransfer(log logr.Logger, dv *cdiv1.DataVolume, validator token.Validator, name, namespace string) (bool, error) {
 	initialized := true
-	dv := syncRes.dvMutated
 
 	log.Info("Initializing transfer")
 
@@ -298,7 +321,8 @@ func (r *CloneReconcilerBase) initTransfer(log logr.Logger, syncRes *dataVolumeC
 			return false, err
 		}
 
-		if err := cc.ValidateCloneTokenDV(r.tokenValidator, dv); err != nil {
+		if err := cc.ValidateCloneTokenDV(validator, dv); err != nil {
+			r.recorder.Eventf(dv, corev1.EventTypeWarning, InvalidCloneToken, MessageInvalidCloneToken, dv.Namespace, dv.Name, err)
 			return false, err
 		}
 
@@ -431,10 +455,11 @@ func (r *CloneReconcilerBase) createExpansionPod(pvc *corev1.PersistentVolumeCla
 		return nil, err
 	}
 
-	workloadNodePlacement, err := cc.GetWorkloadNodePlacement(r.client)
+	workloadNodePlacement, err := cc.GetPodNodePlacement(r.client, pvc)
 	if err != nil {
 		return nil, err
 	}
+	cc.RequireSelectedNode(workloadNodePlacement, pvc)
 
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -520,6 +545,10 @@ func (r *CloneReconcilerBase) syncCloneStatusPhase(syncRes *dataVolumeCloneSyncR
 		event.eventType = corev1.EventTypeNormal
 		event.reason = SnapshotForSmartCloneInProgress
 		event.message = fmt.Sprintf(MessageSmartCloneInProgress, sourceNamespace, sourceName)
+	case cdiv1.SmartClonePVCInProgress:
+		event.eventType = corev1.EventTypeNormal
+		event.reason = SmartClonePVCInProgress
+		event.message = fmt.Sprintf(MessageSmartClonePVCInProgress, sourceNamespace, sourceName)
 	case cdiv1.CloneFromSnapshotSourceInProgress:
 		event.eventType = corev1.EventTypeNormal
 		event.reason = CloneFromSnapshotSourceInProgress
@@ -608,6 +637,15 @@ func (r *CloneReconcilerBase) populateSourceIfSourceRef(dv *cdiv1.DataVolume) er
 	return nil
 }
 
+// observeCloneSucceeded reports the duration of the clone of the DataVolume which succeeded
+func observeCloneSucceeded(dv *cdiv1.DataVolume) {
+	cloneType := dv.Annotations[annCloneType]
+	if cloneType == "" && dv.Spec.Source != nil && dv.Spec.Source.Snapshot != nil {
+		cloneType = "volumesnapshot"
+	}
+	CloneDurationHistogram.WithLabelValues(cloneType).Observe(time.Since(dv.CreationTimestamp.Time).Seconds())
+}
+
 func (r *CloneReconcilerBase) syncDataVolumeStatusPhaseWithEvent(
 	syncRes *dataVolumeCloneSyncResult,
 	phase cdiv1.DataVolumePhase,
@@ -620,7 +658,7 @@ func (r *CloneReconcilerBase) syncDataVolumeStatusPhaseWithEvent(
 	return nil
 }
 
-func (r *CloneReconcilerBase) cleanupTransfer(dv *cdiv1.DataVolume) error {
+func (r *ReconcilerBase) cleanupTransfer(dv *cdiv1.DataVolume) error {
 	transferName := getTransferName(dv)
 	if !cc.HasFinalizer(dv, crossNamespaceFinalizer) {
 		return nil
@@ -122,6 +122,27 @@ func UpdateReadyCondition(conditions []cdiv1.DataVolumeCondition, status corev1.
 	return updateCondition(conditions, cdiv1.DataVolumeReady, status, message, reason)
 }
 
+// getReadyReasonAndMessage returns the reason and the message of the ready condition of a DataVolume which is not ready:
+// the reason given, the reason of the failure of a failed DataVolume, or its phase otherwise, and the last failure of the
+// import.
+func getReadyReasonAndMessage(dataVolume *cdiv1.DataVolume, status corev1.ConditionStatus, reason string, anno map[string]string) (string, string) {
+	if status == corev1.ConditionTrue {
+		return reason, ""
+	}
+	failed := dataVolume.Status.Phase == cdiv1.Failed
+	if reason == "" {
+		reason = string(dataVolume.Status.Phase)
+		if failed && anno[cc.AnnRunningConditionReason] != "" {
+			reason = anno[cc.AnnRunningConditionReason]
+		}
+	}
+	message := dataVolume.Status.LastImportFailure
+	if message == "" && failed {
+		message = anno[cc.AnnRunningConditionMessage]
+	}
+	return reason, message
+}
+
 func updateBoundCondition(conditions []cdiv1.DataVolumeCondition, pvc *corev1.PersistentVolumeClaim, reason string) []cdiv1.DataVolumeCondition {
 	if pvc != nil {
 		pvcCondition := getPVCCondition(pvc.GetAnnotations())
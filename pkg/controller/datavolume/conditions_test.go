@@ -268,3 +268,19 @@ var _ = Describe("updateBoundCondition", func() {
 		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
 	})
 })
+
+var _ = Describe("getReadyReasonAndMessage", func() {
+	table.DescribeTable("should explain why the DataVolume is not ready", func(phase cdiv1.DataVolumePhase, status corev1.ConditionStatus, reason, lastImportFailure string, anno map[string]string, expectedReason, expectedMessage string) {
+		dv := &cdiv1.DataVolume{Status: cdiv1.DataVolumeStatus{Phase: phase, LastImportFailure: lastImportFailure}}
+		readyReason, readyMessage := getReadyReasonAndMessage(dv, status, reason, anno)
+		Expect(readyReason).To(Equal(expectedReason))
+		Expect(readyMessage).To(Equal(expectedMessage))
+	},
+		table.Entry("with the phase", cdiv1.ImportInProgress, corev1.ConditionFalse, "", "", map[string]string{}, "ImportInProgress", ""),
+		table.Entry("with the reason given", cdiv1.Pending, corev1.ConditionFalse, ErrExceededQuota, "", map[string]string{}, ErrExceededQuota, ""),
+		table.Entry("with the last failure of the import being retried", cdiv1.ImportInProgress, corev1.ConditionFalse, "", "Unable to connect to http data source", map[string]string{AnnRunningConditionReason: "Error"}, "ImportInProgress", "Unable to connect to http data source"),
+		table.Entry("with the failure of a failed DataVolume", cdiv1.Failed, corev1.ConditionFalse, "", "", map[string]string{AnnRunningConditionReason: SourceNotFound, AnnRunningConditionMessage: "not found"}, SourceNotFound, "not found"),
+		table.Entry("with the phase of an unknown DataVolume", cdiv1.Unknown, corev1.ConditionUnknown, "", "", map[string]string{}, "Unknown", ""),
+		table.Entry("not when the DataVolume is ready", cdiv1.Succeeded, corev1.ConditionTrue, "", "Unable to connect to http data source", map[string]string{}, "", ""),
+	)
+})
@@ -54,6 +54,7 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
 	featuregates "kubevirt.io/containerized-data-importer/pkg/feature-gates"
+	"kubevirt.io/containerized-data-importer/pkg/monitoring"
 	"kubevirt.io/containerized-data-importer/pkg/token"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
@@ -85,12 +86,38 @@ type Event struct {
 	message   string
 }
 
+const (
+	// importTransferredMilestone is the milestone of an import reached once the share of the data was transferred
+	importTransferredMilestone = "Transferred%d"
+)
+
+var (
+	// importTransferredPercents are the shares of the data of an import relayed as milestones
+	importTransferredPercents = []int{25, 50, 75}
+	// importMilestoneOrder are the milestones of an import relayed as events, in the order they are relayed
+	importMilestoneOrder = []string{
+		common.ImportMilestoneSourceConnected,
+		common.ImportMilestoneConversionStarted,
+		fmt.Sprintf(importTransferredMilestone, 25),
+		fmt.Sprintf(importTransferredMilestone, 50),
+		fmt.Sprintf(importTransferredMilestone, 75),
+		common.ImportMilestoneVerificationPassed,
+	}
+)
+
+type statusPhaseSync struct {
+	phase cdiv1.DataVolumePhase
+	pvc   *corev1.PersistentVolumeClaim
+	event Event
+}
+
 type dataVolumeSyncResult struct {
 	dv        *cdiv1.DataVolume
 	dvMutated *cdiv1.DataVolume
 	pvc       *corev1.PersistentVolumeClaim
 	pvcSpec   *v1.PersistentVolumeClaimSpec
 	result    *reconcile.Result
+	phaseSync *statusPhaseSync
 }
 
 // ReconcilerBase members
@@ -237,7 +264,7 @@ func getDataVolumeOp(dv *cdiv1.DataVolume) dataVolumeOp {
 	if src.Upload != nil {
 		return dataVolumeUpload
 	}
-	if src.HTTP != nil || src.S3 != nil || src.Registry != nil || src.Blank != nil || src.Imageio != nil || src.VDDK != nil {
+	if src.HTTP != nil || src.S3 != nil || src.GCS != nil || src.SFTP != nil || src.Glance != nil || src.File != nil || src.NFS != nil || src.SMB != nil || src.Registry != nil || src.Blank != nil || src.Imageio != nil || src.VDDK != nil {
 		return dataVolumeImport
 	}
 
@@ -280,7 +307,7 @@ func (r ReconcilerBase) sync(log logr.Logger, req reconcile.Request, cleanup, pr
 	}
 
 	if prepare != nil {
-		if err := prepare(syncRes); err != nil {
+		if err := prepare(syncRes); err != nil || syncRes.result != nil {
 			return syncRes, err
 		}
 	}
@@ -298,7 +325,7 @@ func (r ReconcilerBase) sync(log logr.Logger, req reconcile.Request, cleanup, pr
 		r.handlePrePopulation(syncRes.dvMutated, syncRes.pvc)
 	}
 
-	syncRes.pvcSpec, err = renderPvcSpec(r.client, r.recorder, log, dv)
+	syncRes.pvcSpec, err = renderPvcSpec(r.client, r.recorder, log, syncRes.dvMutated)
 	if err != nil {
 		return syncRes, err
 	}
@@ -422,15 +449,19 @@ func (r *ReconcilerBase) reconcileProgressUpdate(datavolume *cdiv1.DataVolume, p
 	if datavolume.Status.Phase == cdiv1.Succeeded || datavolume.Status.Phase == cdiv1.Failed {
 		// Data volume completed progress, or failed, either way stop queueing the data volume.
 		r.log.Info("Datavolume finished, no longer updating progress", "Namespace", datavolume.Namespace, "Name", datavolume.Name, "Phase", datavolume.Status.Phase)
-		return nil
+		return r.relayImportMilestones(datavolume, pvc, nil)
 	}
 	pod, err := r.getPodFromPvc(podNamespace, pvc)
 	if err == nil {
 		if pod.Status.Phase != corev1.PodRunning {
 			// Avoid long timeouts and error traces from HTTP get when pod is already gone
-			return nil
+			return r.relayImportMilestones(datavolume, pvc, nil)
 		}
-		if err := updateProgressUsingPod(datavolume, pod); err != nil {
+		milestones, err := updateProgressUsingPod(datavolume, pod)
+		if err != nil {
+			return err
+		}
+		if err := r.relayImportMilestones(datavolume, pvc, milestones); err != nil {
 			return err
 		}
 	}
@@ -492,8 +523,14 @@ func (r ReconcilerBase) updateStatusCommon(syncRes dataVolumeSyncResult, updateS
 				if err != nil {
 					return reconcile.Result{}, err
 				}
+				shouldBeMarkedPendingPopulation, err := r.shouldBeMarkedPendingPopulation(pvc)
+				if err != nil {
+					return reconcile.Result{}, err
+				}
 				if shouldBeMarkedWaitForFirstConsumer {
 					dataVolumeCopy.Status.Phase = cdiv1.WaitForFirstConsumer
+				} else if shouldBeMarkedPendingPopulation {
+					dataVolumeCopy.Status.Phase = cdiv1.PendingPopulation
 				} else {
 					dataVolumeCopy.Status.Phase = cdiv1.Pending
 				}
@@ -503,6 +540,8 @@ func (r ReconcilerBase) updateStatusCommon(syncRes dataVolumeSyncResult, updateS
 					dataVolumeCopy.Status.Phase = cdiv1.PVCBound
 				case cdiv1.WaitForFirstConsumer:
 					dataVolumeCopy.Status.Phase = cdiv1.PVCBound
+				case cdiv1.PendingPopulation:
+					dataVolumeCopy.Status.Phase = cdiv1.PVCBound
 				case cdiv1.Unknown:
 					dataVolumeCopy.Status.Phase = cdiv1.PVCBound
 				}
@@ -529,6 +568,25 @@ func (r ReconcilerBase) updateStatusCommon(syncRes dataVolumeSyncResult, updateS
 		if i, err := strconv.Atoi(pvc.Annotations[cc.AnnPodRestarts]); err == nil && i >= 0 {
 			dataVolumeCopy.Status.RestartCount = int32(i)
 		}
+		if _, ok := pvc.Annotations[cc.AnnImportPod]; ok {
+			dataVolumeCopy.Status.ImportAttempts = dataVolumeCopy.Status.RestartCount + 1
+		}
+		if failure, ok := pvc.Annotations[cc.AnnImportLastFailure]; ok {
+			dataVolumeCopy.Status.LastImportFailure = failure
+		}
+		if options, ok := pvc.Annotations[cc.AnnQcow2Options]; ok {
+			dataVolumeCopy.Status.Qcow2Options = options
+		}
+		if sourceURL, ok := pvc.Annotations[cc.AnnSourceURL]; ok {
+			dataVolumeCopy.Status.SourceURL = sourceURL
+		}
+		if requested, err := strconv.ParseBool(pvc.Annotations[cc.AnnPreallocationRequested]); err == nil {
+			dataVolumeCopy.Status.Preallocation = &cdiv1.DataVolumePreallocationStatus{
+				Requested: requested,
+				Applied:   pvc.Annotations[cc.AnnPreallocationApplied] == "true",
+				Mode:      cdiv1.DataVolumePreallocationMode(pvc.Annotations[cc.AnnPreallocationMode]),
+			}
+		}
 		if err := r.reconcileProgressUpdate(dataVolumeCopy, pvc, &result); err != nil {
 			return result, err
 		}
@@ -568,8 +626,10 @@ func (r *ReconcilerBase) updateConditions(dataVolume *cdiv1.DataVolume, pvc *cor
 		readyStatus = corev1.ConditionFalse
 	}
 
+	readyReason, readyMessage := getReadyReasonAndMessage(dataVolume, readyStatus, reason, anno)
+
 	dataVolume.Status.Conditions = updateBoundCondition(dataVolume.Status.Conditions, pvc, reason)
-	dataVolume.Status.Conditions = UpdateReadyCondition(dataVolume.Status.Conditions, readyStatus, "", reason)
+	dataVolume.Status.Conditions = UpdateReadyCondition(dataVolume.Status.Conditions, readyStatus, readyMessage, readyReason)
 	dataVolume.Status.Conditions = updateRunningCondition(dataVolume.Status.Conditions, anno)
 }
 
@@ -615,6 +675,9 @@ func (r *ReconcilerBase) emitEvent(dataVolume *cdiv1.DataVolume, dataVolumeCopy
 		// Emit the event only when the status change happens, not every time
 		if event.eventType != "" && curPhase != dataVolumeCopy.Status.Phase {
 			r.recorder.Event(dataVolumeCopy, event.eventType, event.reason, event.message)
+			if event.reason == CloneSucceeded {
+				observeCloneSucceeded(dataVolumeCopy)
+			}
 		}
 		r.emitConditionEvent(dataVolumeCopy, originalCond)
 	}
@@ -671,7 +734,9 @@ func shouldIgnorePod(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim) bool {
 	return false
 }
 
-func updateProgressUsingPod(dataVolumeCopy *cdiv1.DataVolume, pod *corev1.Pod) error {
+// updateProgressUsingPod updates the progress of the DataVolume from the metrics of its pod, and returns the
+// milestones reached by the import, including the shares of the data transferred.
+func updateProgressUsingPod(dataVolumeCopy *cdiv1.DataVolume, pod *corev1.Pod) ([]string, error) {
 	httpClient := buildHTTPClient()
 	// Example value: import_progress{ownerUID="b856691e-1038-11e9-a5ab-525500d15501"} 13.45
 	var importRegExp = regexp.MustCompile("progress\\{ownerUID\\=\"" + string(dataVolumeCopy.UID) + "\"\\} (\\d{1,3}\\.?\\d*)")
@@ -682,27 +747,75 @@ func updateProgressUsingPod(dataVolumeCopy *cdiv1.DataVolume, pod *corev1.Pod) e
 		resp, err := httpClient.Get(url)
 		if err != nil {
 			if errConnectionRefused(err) {
-				return nil
+				return nil, nil
 			}
-			return err
+			return nil, err
 		}
 		defer resp.Body.Close()
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
+		milestones := importMilestones(string(body), dataVolumeCopy.UID)
 		match := importRegExp.FindStringSubmatch(string(body))
 		if match == nil {
-			// No match
-			return nil
+			// Without the size of the source, only the bytes transferred are known
+			if bytes, ok := transferredBytes(string(body), dataVolumeCopy.UID); ok {
+				dataVolumeCopy.Status.Progress = cdiv1.DataVolumeProgress(formatBytes(bytes))
+			}
+			return milestones, nil
 		}
 		if f, err := strconv.ParseFloat(match[1], 64); err == nil {
 			dataVolumeCopy.Status.Progress = cdiv1.DataVolumeProgress(fmt.Sprintf("%.2f%%", f))
+			for _, percent := range importTransferredPercents {
+				if f >= float64(percent) {
+					milestones = append(milestones, fmt.Sprintf(importTransferredMilestone, percent))
+				}
+			}
 		}
-		return nil
+		return milestones, nil
 	}
-	return err
+	return nil, err
+}
+
+// importMilestones returns the milestones reached by the importer of the owner, from its metrics.
+func importMilestones(metrics string, ownerUID types.UID) []string {
+	// Example value: kubevirt_cdi_import_milestone{milestone="SourceConnected",ownerUID="b856691e-1038-11e9-a5ab-525500d15501"} 1
+	name := monitoring.MetricOptsList[monitoring.ImportMilestone].Name
+	milestoneRegExp := regexp.MustCompile(regexp.QuoteMeta(name+`{milestone="`) + `(\w+)` + regexp.QuoteMeta(`",ownerUID="`+string(ownerUID)+`"} 1`))
+	var milestones []string
+	for _, match := range milestoneRegExp.FindAllStringSubmatch(metrics, -1) {
+		milestones = append(milestones, match[1])
+	}
+	return milestones
+}
+
+// transferredBytes returns the bytes of the source read by the importer of the owner, from its metrics.
+func transferredBytes(metrics string, ownerUID types.UID) (float64, bool) {
+	// Example value: kubevirt_cdi_import_transferred_bytes{ownerUID="b856691e-1038-11e9-a5ab-525500d15501"} 1.073741824e+09
+	name := monitoring.MetricOptsList[monitoring.ImportTransferred].Name
+	bytesRegExp := regexp.MustCompile(regexp.QuoteMeta(name+`{ownerUID="`+string(ownerUID)+`"} `) + `(\S+)`)
+	match := bytesRegExp.FindStringSubmatch(metrics)
+	if match == nil {
+		return 0, false
+	}
+	bytes, err := strconv.ParseFloat(match[1], 64)
+	return bytes, err == nil
+}
+
+// formatBytes returns the number of bytes in binary units, such as 1.50GiB.
+func formatBytes(bytes float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	unit := 0
+	for bytes >= 1024 && unit < len(units)-1 {
+		bytes /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%.0fB", bytes)
+	}
+	return fmt.Sprintf("%.2f%s", bytes, units[unit])
 }
 
 func errConnectionRefused(err error) bool {
@@ -844,6 +957,73 @@ func (r *ReconcilerBase) updatePVC(pvc *corev1.PersistentVolumeClaim) error {
 	return r.client.Update(context.TODO(), pvc)
 }
 
+// relayImportMilestones emits an event on the DataVolume for each milestone of the import into the PVC which was
+// not relayed yet, whether scraped from the metrics of the importer or reported in its termination message. The
+// milestones relayed are recorded on the PVC so each is relayed once, whatever the number of reconciles.
+func (r *ReconcilerBase) relayImportMilestones(dataVolume *cdiv1.DataVolume, pvc *corev1.PersistentVolumeClaim, milestones []string) error {
+	if _, ok := pvc.Annotations[cc.AnnImportPod]; !ok {
+		return nil
+	}
+	if reported := pvc.Annotations[cc.AnnImportMilestones]; reported != "" {
+		milestones = append(milestones, strings.Split(reported, ",")...)
+	}
+	reached := make(map[string]bool)
+	for _, milestone := range milestones {
+		reached[milestone] = true
+	}
+	var relayed []string
+	if previous := pvc.Annotations[cc.AnnImportMilestonesRelayed]; previous != "" {
+		relayed = strings.Split(previous, ",")
+	}
+	wasRelayed := make(map[string]bool)
+	for _, milestone := range relayed {
+		wasRelayed[milestone] = true
+	}
+	var events []Event
+	for _, milestone := range importMilestoneOrder {
+		if !reached[milestone] || wasRelayed[milestone] {
+			continue
+		}
+		relayed = append(relayed, milestone)
+		events = append(events, importMilestoneEvent(milestone, pvc.Name))
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	// record the milestones first, an event is better lost than repeated
+	pvcCopy := pvc.DeepCopy()
+	pvcCopy.Annotations[cc.AnnImportMilestonesRelayed] = strings.Join(relayed, ",")
+	if err := r.updatePVC(pvcCopy); err != nil {
+		return err
+	}
+	for _, event := range events {
+		r.recorder.Event(dataVolume, event.eventType, event.reason, event.message)
+	}
+	return nil
+}
+
+// importMilestoneEvent returns the event relaying the milestone of the import into the PVC
+func importMilestoneEvent(milestone, pvcName string) Event {
+	event := Event{eventType: corev1.EventTypeNormal}
+	switch milestone {
+	case common.ImportMilestoneSourceConnected:
+		event.reason = ImportSourceConnected
+		event.message = fmt.Sprintf(MessageImportSourceConnected, pvcName)
+	case common.ImportMilestoneConversionStarted:
+		event.reason = ImportConversionStarted
+		event.message = fmt.Sprintf(MessageImportConversionStarted, pvcName)
+	case common.ImportMilestoneVerificationPassed:
+		event.reason = ImportVerificationPassed
+		event.message = fmt.Sprintf(MessageImportVerificationPassed, pvcName)
+	default:
+		var percent int
+		fmt.Sscanf(milestone, importTransferredMilestone, &percent)
+		event.reason = ImportTransferred
+		event.message = fmt.Sprintf(MessageImportTransferred, percent, pvcName)
+	}
+	return event
+}
+
 func newLongTermCloneTokenGenerator(key *rsa.PrivateKey) token.Generator {
 	return token.NewGenerator(common.ExtendedCloneTokenIssuer, key, 10*365*24*time.Hour)
 }
@@ -859,14 +1039,33 @@ func (r *ReconcilerBase) shouldBeMarkedWaitForFirstConsumer(pvc *corev1.Persiste
 	if err != nil {
 		return false, err
 	}
+	// when PVC requests immediateBinding the worker pod is its first consumer
+	_, isImmediateBindingRequested := pvc.Annotations[cc.AnnImmediateBinding]
 
-	res := honorWaitForFirstConsumerEnabled &&
+	res := honorWaitForFirstConsumerEnabled && !isImmediateBindingRequested &&
 		storageClassBindingMode != nil && *storageClassBindingMode == storagev1.VolumeBindingWaitForFirstConsumer &&
 		pvc.Status.Phase == corev1.ClaimPending
 
 	return res, nil
 }
 
+// shouldBeMarkedPendingPopulation decides whether we should mark DV as PendingPopulation, its WFFC PVC is not left
+// to another consumer and waits for the worker pod populating it to be scheduled
+func (r *ReconcilerBase) shouldBeMarkedPendingPopulation(pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Status.Phase != corev1.ClaimPending {
+		return false, nil
+	}
+	storageClassBindingMode, err := r.getStorageClassBindingMode(pvc.Spec.StorageClassName)
+	if err != nil {
+		return false, err
+	}
+	if storageClassBindingMode == nil || *storageClassBindingMode != storagev1.VolumeBindingWaitForFirstConsumer {
+		return false, nil
+	}
+	shouldBeMarkedWaitForFirstConsumer, err := r.shouldBeMarkedWaitForFirstConsumer(pvc)
+	return !shouldBeMarkedWaitForFirstConsumer, err
+}
+
 // handlePvcCreation works as a wrapper for non-clone PVC creation and error handling
 func (r *ReconcilerBase) handlePvcCreation(log logr.Logger, syncRes *dataVolumeSyncResult, pvcModifier pvcModifierFunc) error {
 	if syncRes.pvc != nil {
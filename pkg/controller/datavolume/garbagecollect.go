@@ -41,7 +41,7 @@ func (r *ReconcilerBase) garbageCollect(syncRes *dataVolumeSyncResult, log logr.
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig); err != nil {
 		return err
 	}
-	dvTTL := cc.GetDataVolumeTTLSeconds(cdiConfig)
+	dvTTL := cc.GetDataVolumeTTLSecondsForDataVolume(cdiConfig, dataVolume)
 	if dvTTL < 0 {
 		log.Info("Garbage Collection is disabled")
 		return nil
@@ -61,8 +61,11 @@ func (r *ReconcilerBase) garbageCollect(syncRes *dataVolumeSyncResult, log logr.
 }
 
 func (r *ReconcilerBase) isGarbageCollectionAllowed(dv *cdiv1.DataVolume, log logr.Logger) (bool, error) {
-	dvDelete := dv.Annotations[cc.AnnDeleteAfterCompletion]
-	if dvDelete != "true" {
+	// The mutating webhook annotates the DataVolumes with GC enabled, a DataVolume setting its own TTL opts in
+	// unless annotated with "false"
+	dvDelete, annotated := dv.Annotations[cc.AnnDeleteAfterCompletion]
+	_, hasTTL := dv.Annotations[cc.AnnTTLSecondsAfterCompletion]
+	if dvDelete != "true" && (annotated || !hasTTL) {
 		log.Info("DataVolume is not annotated to be garbage collected")
 		return false, nil
 	}
@@ -103,7 +106,8 @@ func (r *ReconcilerBase) canUpdateFinalizers(ownerRef metav1.OwnerReference) (bo
 
 func (r *ReconcilerBase) detachPvcDeleteDv(syncRes *dataVolumeSyncResult) error {
 	updatePvcOwnerRefs(syncRes.pvc, syncRes.dv)
-	delete(syncRes.pvc.Annotations, cc.AnnPopulatedFor)
+	// A DataVolume of the same name created again, e.g. by a pipeline applying it again, adopts the populated PVC
+	cc.AddAnnotation(syncRes.pvc, cc.AnnPopulatedFor, syncRes.dv.Name)
 	if err := r.updatePVC(syncRes.pvc); err != nil {
 		return err
 	}
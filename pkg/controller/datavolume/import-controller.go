@@ -18,6 +18,7 @@ package datavolume
 
 import (
 	"context"
+	"crypto/rsa"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -26,13 +27,19 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 
+	"kubevirt.io/containerized-data-importer/pkg/common"
 	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
 	featuregates "kubevirt.io/containerized-data-importer/pkg/feature-gates"
+	"kubevirt.io/containerized-data-importer/pkg/token"
+	"kubevirt.io/containerized-data-importer/pkg/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -48,6 +55,18 @@ const (
 	ImportSucceeded = "ImportSucceeded"
 	// ImportPaused provides a const to indicate that a multistage import is waiting for the next stage
 	ImportPaused = "ImportPaused"
+	// ImportSourceConnected provides a const to indicate the importer connected to the source
+	ImportSourceConnected = "ImportSourceConnected"
+	// ImportTransferred provides a const to indicate a share of the data of the source was transferred
+	ImportTransferred = "ImportTransferred"
+	// ImportConversionStarted provides a const to indicate the importer started converting the disk image
+	ImportConversionStarted = "ImportConversionStarted"
+	// ImportVerificationPassed provides a const to indicate the disk image written passed its check
+	ImportVerificationPassed = "ImportVerificationPassed"
+	// WeakChecksum provides a const to indicate the checksum of the source uses a weak algorithm
+	WeakChecksum = "WeakChecksum"
+	// DeprecatedChecksumAnnotation provides a const to indicate the checksum of the source is set with the deprecated annotation
+	DeprecatedChecksumAnnotation = "DeprecatedChecksumAnnotation"
 
 	// MessageImportScheduled provides a const to form import is scheduled message
 	MessageImportScheduled = "Import into %s scheduled"
@@ -57,8 +76,22 @@ const (
 	MessageImportFailed = "Failed to import into PVC %s"
 	// MessageImportSucceeded provides a const to form import has succeeded message
 	MessageImportSucceeded = "Successfully imported into PVC %s"
+	// MessageImportSourceUnchanged provides a const to form the message of a re-import whose source did not change
+	MessageImportSourceUnchanged = "The source did not change since it was imported into PVC %s, its data is kept"
 	// MessageImportPaused provides a const for a "multistage import paused" message
 	MessageImportPaused = "Multistage import into PVC %s is paused"
+	// MessageImportSourceConnected provides a const to form the message of the connection to the source of an import
+	MessageImportSourceConnected = "Connected to the source of the import into PVC %s"
+	// MessageImportTransferred provides a const to form the message of the share of the data of an import transferred
+	MessageImportTransferred = "Transferred %d%% of the data of the import into PVC %s"
+	// MessageImportConversionStarted provides a const to form the message of the start of the conversion of an import
+	MessageImportConversionStarted = "Started converting the disk image imported into PVC %s"
+	// MessageImportVerificationPassed provides a const to form the message of the check of the disk image of an import
+	MessageImportVerificationPassed = "The disk image imported into PVC %s passed its check"
+	// MessageWeakChecksum provides a const to form the weak checksum algorithm message
+	MessageWeakChecksum = "The %s checksum of the source is weak, sha256 or sha512 is recommended"
+	// MessageDeprecatedChecksumAnnotation provides a const to form the deprecated checksum annotation message
+	MessageDeprecatedChecksumAnnotation = "The %s annotation is deprecated and will be removed in the next release, the checksum of the source is set in its checksum field"
 
 	importControllerName = "datavolume-import-controller"
 )
@@ -66,6 +99,9 @@ const (
 // ImportReconciler members
 type ImportReconciler struct {
 	ReconcilerBase
+	tokenValidator token.Validator
+	importerImage  string
+	pullPolicy     string
 }
 
 // NewImportController creates a new instance of the datavolume import controller
@@ -73,6 +109,9 @@ func NewImportController(
 	ctx context.Context,
 	mgr manager.Manager,
 	log logr.Logger,
+	importerImage string,
+	pullPolicy string,
+	tokenPublicKeys []*rsa.PublicKey,
 	installerLabels map[string]string,
 ) (controller.Controller, error) {
 	client := mgr.GetClient()
@@ -85,6 +124,9 @@ func NewImportController(
 			featureGates:    featuregates.NewFeatureGates(client),
 			installerLabels: installerLabels,
 		},
+		tokenValidator: cc.NewCloneTokenValidator(common.CloneTokenIssuer, tokenPublicKeys...),
+		importerImage:  importerImage,
+		pullPolicy:     pullPolicy,
 	}
 	reconciler.Reconciler = reconciler
 
@@ -115,6 +157,13 @@ func addDataVolumeImportControllerWatches(mgr manager.Manager, datavolumeControl
 
 func (r ImportReconciler) updateAnnotations(dataVolume *cdiv1.DataVolume, pvc *corev1.PersistentVolumeClaim) error {
 	annotations := pvc.Annotations
+	annotations[cc.AnnDiskFormatRequested] = string(cc.GetDiskFormat(r.client, dataVolume))
+	if dataVolume.Spec.PreallocationMode != "" {
+		annotations[cc.AnnPreallocationModeRequested] = string(dataVolume.Spec.PreallocationMode)
+	}
+	if dataVolume.Spec.BackoffLimit != nil {
+		annotations[cc.AnnImportMaxRetries] = strconv.Itoa(int(*dataVolume.Spec.BackoffLimit))
+	}
 
 	if checkpoint := r.getNextCheckpoint(dataVolume, pvc); checkpoint != nil {
 		annotations[cc.AnnCurrentCheckpoint] = checkpoint.Current
@@ -132,12 +181,28 @@ func (r ImportReconciler) updateAnnotations(dataVolume *cdiv1.DataVolume, pvc *c
 		if dataVolume.Spec.Source.HTTP.CertConfigMap != "" {
 			annotations[cc.AnnCertConfigMap] = dataVolume.Spec.Source.HTTP.CertConfigMap
 		}
+		if dataVolume.Spec.Source.HTTP.EncryptionSecretRef != "" {
+			annotations[cc.AnnEncryptionSecret] = dataVolume.Spec.Source.HTTP.EncryptionSecretRef
+		}
 		for index, header := range dataVolume.Spec.Source.HTTP.ExtraHeaders {
 			annotations[fmt.Sprintf("%s.%d", cc.AnnExtraHeaders, index)] = header
 		}
 		for index, header := range dataVolume.Spec.Source.HTTP.SecretExtraHeaders {
 			annotations[fmt.Sprintf("%s.%d", cc.AnnSecretExtraHeaders, index)] = header
 		}
+		if len(dataVolume.Spec.Source.HTTP.Mirrors) > 0 {
+			annotations[cc.AnnMirrors] = strings.Join(dataVolume.Spec.Source.HTTP.Mirrors, " ")
+		}
+		r.setChecksum(dataVolume, annotations, dataVolume.Spec.Source.HTTP.Checksum)
+		if signature := dataVolume.Spec.Source.HTTP.Signature; signature != nil {
+			annotations[cc.AnnSignatureKeyConfigMap] = signature.KeyConfigMap
+			if signature.URL != "" {
+				annotations[cc.AnnSignatureURL] = signature.URL
+			}
+			if signature.Armored != "" {
+				annotations[cc.AnnSignatureArmored] = signature.Armored
+			}
+		}
 		return nil
 	}
 	if dataVolume.Spec.Source.S3 != nil {
@@ -149,6 +214,64 @@ func (r ImportReconciler) updateAnnotations(dataVolume *cdiv1.DataVolume, pvc *c
 		if dataVolume.Spec.Source.S3.CertConfigMap != "" {
 			annotations[cc.AnnCertConfigMap] = dataVolume.Spec.Source.S3.CertConfigMap
 		}
+		if dataVolume.Spec.Source.S3.EncryptionSecretRef != "" {
+			annotations[cc.AnnEncryptionSecret] = dataVolume.Spec.Source.S3.EncryptionSecretRef
+		}
+		r.setChecksum(dataVolume, annotations, dataVolume.Spec.Source.S3.Checksum)
+		return nil
+	}
+	if dataVolume.Spec.Source.GCS != nil {
+		annotations[cc.AnnEndpoint] = dataVolume.Spec.Source.GCS.URL
+		annotations[cc.AnnSource] = cc.SourceGCS
+		if dataVolume.Spec.Source.GCS.SecretRef != "" {
+			annotations[cc.AnnSecret] = dataVolume.Spec.Source.GCS.SecretRef
+		}
+		r.setChecksum(dataVolume, annotations, dataVolume.Spec.Source.GCS.Checksum)
+		return nil
+	}
+	if dataVolume.Spec.Source.SFTP != nil {
+		annotations[cc.AnnEndpoint] = dataVolume.Spec.Source.SFTP.URL
+		annotations[cc.AnnSource] = cc.SourceSFTP
+		annotations[cc.AnnSecret] = dataVolume.Spec.Source.SFTP.SecretRef
+		r.setChecksum(dataVolume, annotations, dataVolume.Spec.Source.SFTP.Checksum)
+		return nil
+	}
+	if dataVolume.Spec.Source.Glance != nil {
+		annotations[cc.AnnEndpoint] = dataVolume.Spec.Source.Glance.URL
+		annotations[cc.AnnSource] = cc.SourceGlance
+		annotations[cc.AnnSecret] = dataVolume.Spec.Source.Glance.SecretRef
+		annotations[cc.AnnGlanceImage] = dataVolume.Spec.Source.Glance.Image
+		if dataVolume.Spec.Source.Glance.Project != "" {
+			annotations[cc.AnnGlanceProject] = dataVolume.Spec.Source.Glance.Project
+		}
+		if dataVolume.Spec.Source.Glance.Region != "" {
+			annotations[cc.AnnGlanceRegion] = dataVolume.Spec.Source.Glance.Region
+		}
+		if dataVolume.Spec.Source.Glance.CertConfigMap != "" {
+			annotations[cc.AnnCertConfigMap] = dataVolume.Spec.Source.Glance.CertConfigMap
+		}
+		return nil
+	}
+	if dataVolume.Spec.Source.File != nil {
+		annotations[cc.AnnEndpoint] = dataVolume.Spec.Source.File.Path
+		annotations[cc.AnnSource] = cc.SourceFile
+		if dataVolume.Spec.Source.File.PVC != "" {
+			annotations[cc.AnnFilePVC] = dataVolume.Spec.Source.File.PVC
+		} else {
+			annotations[cc.AnnFileHostPath] = dataVolume.Spec.Source.File.HostPath
+		}
+		return nil
+	}
+	if dataVolume.Spec.Source.NFS != nil {
+		annotations[cc.AnnEndpoint] = dataVolume.Spec.Source.NFS.URL
+		annotations[cc.AnnSource] = cc.SourceNFS
+		return nil
+	}
+	if dataVolume.Spec.Source.SMB != nil {
+		annotations[cc.AnnEndpoint] = dataVolume.Spec.Source.SMB.URL
+		annotations[cc.AnnSource] = cc.SourceSMB
+		annotations[cc.AnnSecret] = dataVolume.Spec.Source.SMB.SecretRef
+		r.setChecksum(dataVolume, annotations, dataVolume.Spec.Source.SMB.Checksum)
 		return nil
 	}
 	if dataVolume.Spec.Source.Registry != nil {
@@ -175,10 +298,17 @@ func (r ImportReconciler) updateAnnotations(dataVolume *cdiv1.DataVolume, pvc *c
 		if certConfigMap != nil && *certConfigMap != "" {
 			annotations[cc.AnnCertConfigMap] = *certConfigMap
 		}
+		r.setChecksum(dataVolume, annotations, dataVolume.Spec.Source.Registry.Checksum)
 		return nil
 	}
 	if dataVolume.Spec.Source.Blank != nil {
 		annotations[cc.AnnSource] = cc.SourceNone
+		if dataVolume.Spec.Source.Blank.Filesystem != "" {
+			annotations[cc.AnnBlankFilesystem] = string(dataVolume.Spec.Source.Blank.Filesystem)
+		}
+		if dataVolume.Spec.Source.Blank.FilesystemLabel != "" {
+			annotations[cc.AnnBlankFilesystemLabel] = dataVolume.Spec.Source.Blank.FilesystemLabel
+		}
 		return nil
 	}
 	if dataVolume.Spec.Source.Imageio != nil {
@@ -196,6 +326,9 @@ func (r ImportReconciler) updateAnnotations(dataVolume *cdiv1.DataVolume, pvc *c
 		annotations[cc.AnnBackingFile] = dataVolume.Spec.Source.VDDK.BackingFile
 		annotations[cc.AnnUUID] = dataVolume.Spec.Source.VDDK.UUID
 		annotations[cc.AnnThumbprint] = dataVolume.Spec.Source.VDDK.Thumbprint
+		if dataVolume.Spec.Source.VDDK.Moref != "" {
+			annotations[cc.AnnMoref] = dataVolume.Spec.Source.VDDK.Moref
+		}
 		if dataVolume.Spec.Source.VDDK.InitImageURL != "" {
 			annotations[cc.AnnVddkInitImageURL] = dataVolume.Spec.Source.VDDK.InitImageURL
 		}
@@ -204,6 +337,104 @@ func (r ImportReconciler) updateAnnotations(dataVolume *cdiv1.DataVolume, pvc *c
 	return errors.Errorf("no source set for import datavolume")
 }
 
+// setChecksum annotates the PVC with the checksum of the source, or with the deprecated checksum annotation of the
+// DataVolume, a weak checksum is accepted but reported in an event
+func (r ImportReconciler) setChecksum(dataVolume *cdiv1.DataVolume, annotations map[string]string, checksum *cdiv1.DataVolumeChecksum) {
+	value := dataVolume.Annotations[cc.AnnChecksum]
+	if checksum != nil {
+		value = string(checksum.Algorithm) + ":" + checksum.Value
+	} else if value != "" {
+		r.recorder.Event(dataVolume, corev1.EventTypeWarning, DeprecatedChecksumAnnotation, fmt.Sprintf(MessageDeprecatedChecksumAnnotation, cc.AnnChecksum))
+	}
+	if value == "" {
+		return
+	}
+	annotations[cc.AnnChecksum] = value
+	if checksum, err := util.ParseChecksum(value); err == nil && checksum.Weak() {
+		r.recorder.Event(dataVolume, corev1.EventTypeWarning, WeakChecksum, fmt.Sprintf(MessageWeakChecksum, checksum.Algorithm))
+	}
+}
+
+// reimportClearedAnnotations are the annotations of the previous import of a PVC dropped when it is
+// imported again, those set from the previous DataVolume along with the state of its importer pod.
+var reimportClearedAnnotations = []string{
+	cc.AnnPopulatedFor,
+	cc.AnnPodPhase,
+	cc.AnnImportPod,
+	cc.AnnRequiresScratch,
+	cc.AnnRunningCondition,
+	cc.AnnRunningConditionMessage,
+	cc.AnnRunningConditionReason,
+	cc.AnnImportTerminalError,
+	cc.AnnImportRetryAfter,
+	cc.AnnImportLastFailure,
+	cc.AnnImportMilestones,
+	cc.AnnImportMilestonesRelayed,
+	cc.AnnSourceUnchanged,
+	cc.AnnSecret,
+	cc.AnnCertConfigMap,
+	cc.AnnEncryptionSecret,
+	cc.AnnMirrors,
+	cc.AnnURLPattern,
+	cc.AnnChecksum,
+	cc.AnnSignatureKeyConfigMap,
+	cc.AnnSignatureURL,
+	cc.AnnSignatureArmored,
+}
+
+// prepare finds the size of the source of an import whose storage size is omitted, and prepares the
+// reimport of a populated PVC.
+func (r ImportReconciler) prepare(syncRes *dataVolumeSyncResult) error {
+	if err := r.detectImportSize(syncRes); err != nil || syncRes.result != nil {
+		return err
+	}
+	return r.prepareReimport(syncRes)
+}
+
+// prepareReimport imports again the data of a PVC populated by a previous DataVolume of the same name, when
+// the DataVolume requests it with the reimport annotation. The PVC is adopted and the annotations of
+// its previous import are replaced, except the validators of its source telling whether it changed.
+func (r ImportReconciler) prepareReimport(syncRes *dataVolumeSyncResult) error {
+	dv, pvc := syncRes.dv, syncRes.pvc
+	reimport := dv.Annotations[cc.AnnReimport]
+	if reimport == "" || !pvcIsPopulated(pvc, dv) || metav1.IsControlledBy(pvc, dv) || !cc.IsPVCComplete(pvc) {
+		return nil
+	}
+	if podName := pvc.Annotations[cc.AnnImportPod]; podName != "" {
+		// a pod of the previous import retained after completion would be taken for the new one
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: pvc.Namespace}}
+		if err := r.client.Delete(context.TODO(), pod); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	endpoint := pvc.Annotations[cc.AnnEndpoint]
+	for key := range pvc.Annotations {
+		if strings.HasPrefix(key, cc.AnnExtraHeaders) || strings.HasPrefix(key, cc.AnnSecretExtraHeaders) {
+			delete(pvc.Annotations, key)
+		}
+	}
+	for _, key := range reimportClearedAnnotations {
+		delete(pvc.Annotations, key)
+	}
+	for key, value := range dv.Annotations {
+		pvc.Annotations[key] = value
+	}
+	pvc.Annotations[cc.AnnPodRestarts] = "0"
+	if err := r.updateAnnotations(dv, pvc); err != nil {
+		return err
+	}
+	if pvc.Annotations[cc.AnnEndpoint] != endpoint {
+		// the validators identify the data of another source
+		delete(pvc.Annotations, cc.AnnSourceETag)
+		delete(pvc.Annotations, cc.AnnSourceLastModified)
+	}
+	if err := controllerutil.SetControllerReference(dv, pvc, r.scheme); err != nil {
+		return err
+	}
+	r.log.V(1).Info("Importing again the data of the populated PVC", "pvc", pvc.Name, "reimport", reimport)
+	return r.updatePVC(pvc)
+}
+
 // Reconcile loop for the import data volumes
 func (r ImportReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	log := r.log.WithValues("DataVolume", req.NamespacedName)
@@ -219,10 +450,13 @@ func (r ImportReconciler) sync(log logr.Logger, req reconcile.Request) (dataVolu
 }
 
 func (r ImportReconciler) syncImport(log logr.Logger, req reconcile.Request) (dataVolumeSyncResult, error) {
-	syncRes, syncErr := r.syncCommon(log, req, nil, nil)
+	syncRes, syncErr := r.syncCommon(log, req, r.cleanup, r.prepare)
 	if syncErr != nil || syncRes.result != nil {
 		return *syncRes, syncErr
 	}
+	if _, prePopulated := syncRes.dvMutated.Annotations[cc.AnnPrePopulated]; syncRes.pvc == nil && !prePopulated && isCrossNamespaceFileImport(syncRes.dvMutated) {
+		return *syncRes, r.syncCrossNamespaceImport(log, syncRes)
+	}
 	if err := r.handlePvcCreation(log, syncRes, r.updateAnnotations); err != nil {
 		syncErr = err
 	}
@@ -233,10 +467,90 @@ func (r ImportReconciler) syncImport(log logr.Logger, req reconcile.Request) (da
 	return *syncRes, syncErr
 }
 
+// syncCrossNamespaceImport imports the file of a PVC of another namespace into a temporary PVC of that
+// namespace, then transfers it to the namespace of the DataVolume once the import succeeded. The clone
+// token of the DataVolume is validated when the transfer is initialized.
+func (r ImportReconciler) syncCrossNamespaceImport(log logr.Logger, syncRes *dataVolumeSyncResult) error {
+	dv := syncRes.dvMutated
+	namespace, name := dv.Spec.Source.File.Namespace, getTransferName(dv)
+	if initialized, err := r.initTransfer(log, dv, r.tokenValidator, name, namespace); err != nil || !initialized {
+		return err
+	}
+
+	tmpPVC := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, tmpPVC); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		tmpPVC, err = r.newPersistentVolumeClaim(dv, syncRes.pvcSpec, namespace, name, r.updateAnnotations)
+		if err != nil {
+			return err
+		}
+		util.SetRecommendedLabels(tmpPVC, r.installerLabels, "cdi-controller")
+		if err := r.client.Create(context.TODO(), tmpPVC); err != nil {
+			return err
+		}
+	}
+	if tmpPVC.Annotations[cc.AnnPodPhase] != string(corev1.PodSucceeded) {
+		// the status of the DataVolume follows the import into the temporary PVC
+		syncRes.pvc = tmpPVC
+		return nil
+	}
+
+	if tmpPVC.Annotations[annReadyForTransfer] != "true" {
+		tmpPVC.Annotations[annReadyForTransfer] = "true"
+		tmpPVC.Annotations[cc.AnnPopulatedFor] = dv.Name
+		if err := r.updatePVC(tmpPVC); err != nil {
+			return err
+		}
+	}
+	syncRes.phaseSync = &statusPhaseSync{
+		phase: cdiv1.NamespaceTransferInProgress,
+		event: Event{
+			eventType: corev1.EventTypeNormal,
+			reason:    NamespaceTransferInProgress,
+			message:   fmt.Sprintf(MessageNamespaceTransferInProgress, dv.Namespace, dv.Name),
+		},
+	}
+	return nil
+}
+
+// cleanup deletes the temporary PVC and the transfer of a cross namespace import of a deleted DataVolume
+func (r ImportReconciler) cleanup(syncRes *dataVolumeSyncResult) error {
+	dv := syncRes.dvMutated
+	if !isCrossNamespaceFileImport(dv) {
+		return nil
+	}
+	if dv.Status.Phase != cdiv1.Succeeded {
+		tmpPVC := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: dv.Spec.Source.File.Namespace,
+				Name:      getTransferName(dv),
+			},
+		}
+		if err := r.client.Delete(context.TODO(), tmpPVC); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return r.cleanupTransfer(dv)
+}
+
+// isCrossNamespaceFileImport returns true if the DataVolume imports the file of a PVC of another namespace
+func isCrossNamespaceFileImport(dv *cdiv1.DataVolume) bool {
+	file := dv.Spec.Source.File
+	return file != nil && file.PVC != "" && file.Namespace != "" && file.Namespace != dv.Namespace
+}
+
 func (r ImportReconciler) updateStatus(syncRes dataVolumeSyncResult, syncErr error) (reconcile.Result, error) {
 	if syncErr != nil {
 		return getReconcileResult(syncRes.result), syncErr
 	}
+	if ps := syncRes.phaseSync; ps != nil {
+		if err := r.updateDataVolumeStatusPhaseWithEvent(ps.phase, syncRes.dv, syncRes.dvMutated, ps.pvc, ps.event); err != nil {
+			syncErr = err
+		}
+		return getReconcileResult(syncRes.result), syncErr
+	}
 	res, err := r.updateStatusCommon(syncRes, r.updateStatusPhase)
 	if err != nil {
 		syncErr = err
@@ -274,6 +588,14 @@ func (r ImportReconciler) updateStatusPhase(pvc *corev1.PersistentVolumeClaim, d
 		event.eventType = corev1.EventTypeWarning
 		event.reason = ImportFailed
 		event.message = fmt.Sprintf(MessageImportFailed, pvc.Name)
+		if msg, ok := pvc.Annotations[cc.AnnImportTerminalError]; ok {
+			// retrying the import cannot succeed
+			dataVolumeCopy.Status.Phase = cdiv1.Failed
+			event.message = fmt.Sprintf(MessageImportFailed, pvc.Name) + ": " + msg
+			if reason := pvc.Annotations[cc.AnnRunningConditionReason]; reason == cc.InvalidClientCertificate || reason == cc.ChecksumMismatch || reason == cc.InvalidSignature || reason == cc.NoMatchingEntry || reason == cc.SourceNotFound || reason == cc.RetryLimitExceeded || reason == cc.HostPathImportNotAllowed {
+				event.reason = reason
+			}
+		}
 	case string(corev1.PodSucceeded):
 		if _, ok := pvc.Annotations[cc.AnnCurrentCheckpoint]; ok {
 			if err := r.updatesMultistageImportSucceeded(pvc, dataVolumeCopy); err != nil {
@@ -291,6 +613,9 @@ func (r ImportReconciler) updateStatusPhase(pvc *corev1.PersistentVolumeClaim, d
 		event.eventType = corev1.EventTypeNormal
 		event.reason = ImportSucceeded
 		event.message = fmt.Sprintf(MessageImportSucceeded, pvc.Name)
+		if pvc.Annotations[cc.AnnSourceUnchanged] == "true" {
+			event.message = fmt.Sprintf(MessageImportSourceUnchanged, pvc.Name)
+		}
 	}
 	return nil
 }
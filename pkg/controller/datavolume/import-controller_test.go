@@ -41,6 +41,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -49,10 +50,12 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	. "kubevirt.io/containerized-data-importer/pkg/controller/common"
 	featuregates "kubevirt.io/containerized-data-importer/pkg/feature-gates"
+	"kubevirt.io/containerized-data-importer/pkg/token"
 )
 
 const (
-	testStorageClass = "test-sc"
+	testStorageClass  = "test-sc"
+	testImporterImage = "test/importer"
 )
 
 var (
@@ -184,7 +187,8 @@ var _ = Describe("All DataVolume Tests", func() {
 		})
 
 		It("Should fail on missing size, without storageClass", func() {
-			importDataVolume := newImportDataVolumeWithPvc("test-dv", nil)
+			importDataVolume := newS3ImportDataVolume("test-dv")
+			importDataVolume.Spec.PVC = nil
 			// spec with accessMode/VolumeMode so storageprofile is not needed
 			importDataVolume.Spec.Storage = createStorageSpec()
 			importDataVolume.Spec.Storage.Resources = corev1.ResourceRequirements{}
@@ -198,7 +202,8 @@ var _ = Describe("All DataVolume Tests", func() {
 
 		It("Should fail on missing size, with StorageClass", func() {
 			storageClassName := "defaultSc"
-			importDataVolume := newImportDataVolumeWithPvc("test-dv", nil)
+			importDataVolume := newS3ImportDataVolume("test-dv")
+			importDataVolume.Spec.PVC = nil
 			// spec with accessMode/VolumeMode so storageprofile is not needed
 			importDataVolume.Spec.Storage = createStorageSpec()
 			importDataVolume.Spec.Storage.Resources = corev1.ResourceRequirements{}
@@ -212,6 +217,96 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(err.Error()).To(ContainSubstring("missing storage size"))
 		})
 
+		It("Should create a size-detection pod and no PVC on an http import DV without size", func() {
+			importDataVolume := newImportDataVolumeWithPvc("test-dv", nil)
+			importDataVolume.Spec.Storage = createStorageSpec()
+			importDataVolume.Spec.Storage.Resources = corev1.ResourceRequirements{}
+			reconciler = createImportReconciler(importDataVolume)
+
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+			pod := &corev1.Pod{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: importSizeDetectionPodName(importDataVolume), Namespace: metav1.NamespaceDefault}, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pod.Spec.Containers[0].Image).To(Equal(testImporterImage))
+			Expect(pod.Spec.Containers[0].Args).To(Equal([]string{"-image-path", "http://example.com/data"}))
+			Expect(metav1.IsControlledBy(pod, importDataVolume)).To(BeTrue())
+
+			dv := &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Phase).To(Equal(cdiv1.Pending))
+		})
+
+		It("Should create a PVC of the size found by the size-detection pod", func() {
+			importDataVolume := newImportDataVolumeWithPvc("test-dv", nil)
+			importDataVolume.Spec.Storage = createStorageSpec()
+			importDataVolume.Spec.Storage.Resources = corev1.ResourceRequirements{}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: importSizeDetectionPodName(importDataVolume), Namespace: metav1.NamespaceDefault},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodSucceeded,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "1073741824"}}},
+					},
+				},
+			}
+			reconciler = createImportReconciler(importDataVolume, pod)
+
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.Spec.Resources.Requests.Storage().Value()).To(Equal(int64(1073741824)))
+
+			dv := &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Annotations[AnnRequiredSize]).To(Equal("1073741824"))
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, pod)
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should fail the DV when the size-detection pod cannot find the size", func() {
+			importDataVolume := newImportDataVolumeWithPvc("test-dv", nil)
+			importDataVolume.Spec.Storage = createStorageSpec()
+			importDataVolume.Spec.Storage.Resources = corev1.ResourceRequirements{}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: importSizeDetectionPodName(importDataVolume), Namespace: metav1.NamespaceDefault},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodFailed,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "unknown virtual size"}}},
+					},
+				},
+			}
+			reconciler = createImportReconciler(importDataVolume, pod)
+
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+			dv := &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Phase).To(Equal(cdiv1.Failed))
+			events := reconciler.recorder.(*record.FakeRecorder).Events
+			found := false
+			for len(events) > 0 {
+				if strings.Contains(<-events, ImportSizeDetectionFailed) {
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+
 		DescribeTable("Should set params on a PVC from storageProfile when import DV has no accessMode and no volume mode", func(contentType cdiv1.DataVolumeContentType) {
 			scName := "testStorageClass"
 			importDataVolume := newImportDataVolumeWithPvc("test-dv", nil)
@@ -508,6 +603,439 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(pvc.GetAnnotations()[AnnPriorityClassName]).To(Equal("p0-s3"))
 		})
 
+		It("Should pass the mirrors of an HTTP source to the created PVC", func() {
+			dv := NewImportDataVolume("test-dv")
+			dv.Spec.Source.HTTP.Mirrors = []string{"http://mirror1.example.com/disk.img", "https://mirror2.example.com/disk.img"}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnMirrors]).To(Equal("http://mirror1.example.com/disk.img https://mirror2.example.com/disk.img"))
+		})
+
+		DescribeTable("Should pass the checksum of the source to the created PVC", func(algorithm cdiv1.DataVolumeChecksumAlgorithm, value string, weak bool) {
+			dv := NewImportDataVolume("test-dv")
+			dv.Spec.Source.HTTP.Checksum = &cdiv1.DataVolumeChecksum{Algorithm: algorithm, Value: value}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnChecksum]).To(Equal(string(algorithm) + ":" + value))
+
+			By("Checking a weak checksum is reported")
+			events := reconciler.recorder.(*record.FakeRecorder).Events
+			found := false
+			for len(events) > 0 {
+				if strings.Contains(<-events, WeakChecksum) {
+					found = true
+				}
+			}
+			Expect(found).To(Equal(weak))
+		},
+			Entry("with a sha256 checksum", cdiv1.DataVolumeChecksumSHA256, strings.Repeat("ab", 32), false),
+			Entry("with a weak md5 checksum", cdiv1.DataVolumeChecksumMD5, strings.Repeat("ab", 16), true),
+		)
+
+		It("Should pass the deprecated checksum annotation to the created PVC, and report it", func() {
+			dv := NewImportDataVolume("test-dv")
+			dv.Annotations = map[string]string{AnnChecksum: "sha256:" + strings.Repeat("ab", 32)}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnChecksum]).To(Equal("sha256:" + strings.Repeat("ab", 32)))
+
+			By("Checking the deprecated annotation is reported")
+			events := reconciler.recorder.(*record.FakeRecorder).Events
+			found := false
+			for len(events) > 0 {
+				if strings.Contains(<-events, DeprecatedChecksumAnnotation) {
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+
+		It("Should prefer the checksum of the source to the deprecated checksum annotation", func() {
+			dv := NewImportDataVolume("test-dv")
+			dv.Annotations = map[string]string{AnnChecksum: "sha256:" + strings.Repeat("ab", 32)}
+			dv.Spec.Source.HTTP.Checksum = &cdiv1.DataVolumeChecksum{Algorithm: cdiv1.DataVolumeChecksumSHA256, Value: strings.Repeat("cd", 32)}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnChecksum]).To(Equal("sha256:" + strings.Repeat("cd", 32)))
+		})
+
+		It("Should pass the checksum of a registry source to the created PVC", func() {
+			url := "docker://registry.example.com/disk"
+			dv := newS3ImportDataVolume("test-dv")
+			dv.Spec.Source = &cdiv1.DataVolumeSource{
+				Registry: &cdiv1.DataVolumeSourceRegistry{
+					URL:      &url,
+					Checksum: &cdiv1.DataVolumeChecksum{Algorithm: cdiv1.DataVolumeChecksumSHA512, Value: strings.Repeat("0a", 64)},
+				},
+			}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSource]).To(Equal(SourceRegistry))
+			Expect(pvc.GetAnnotations()[AnnChecksum]).To(Equal("sha512:" + strings.Repeat("0a", 64)))
+		})
+
+		It("Should pass the signature of the source to the created PVC", func() {
+			dv := NewImportDataVolume("test-dv")
+			dv.Spec.Source.HTTP.Signature = &cdiv1.DataVolumeSourceSignature{
+				KeyConfigMap: "signing-keys",
+				URL:          "http://example.com/data.sig",
+			}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSignatureKeyConfigMap]).To(Equal("signing-keys"))
+			Expect(pvc.GetAnnotations()[AnnSignatureURL]).To(Equal("http://example.com/data.sig"))
+			Expect(pvc.GetAnnotations()).ToNot(HaveKey(AnnSignatureArmored))
+		})
+
+		DescribeTable("Should import again the data of a PVC populated by a previous DataVolume", func(reimport, url string, validatorsKept bool) {
+			pvc := CreatePvc("test-dv", metav1.NamespaceDefault, map[string]string{
+				AnnPopulatedFor:    "test-dv",
+				AnnPodPhase:        string(corev1.PodSucceeded),
+				AnnImportPod:       "importer-test-dv",
+				AnnEndpoint:        "http://example.com/data",
+				AnnSource:          SourceHTTP,
+				AnnChecksum:        "sha256:" + strings.Repeat("ab", 32),
+				AnnSourceETag:      `"v1"`,
+				AnnSourceUnchanged: "true",
+			}, nil)
+			pvc.Status.Phase = corev1.ClaimBound
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "importer-test-dv", Namespace: metav1.NamespaceDefault}}
+			dv := NewImportDataVolume("test-dv")
+			dv.Annotations = map[string]string{AnnReimport: reimport}
+			dv.Spec.Source.HTTP.URL = url
+			reconciler = createImportReconciler(dv, pvc, pod)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			dv = &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metav1.IsControlledBy(pvc, dv)).To(BeTrue())
+			Expect(dv.Annotations).ToNot(HaveKey(AnnPrePopulated))
+			for _, key := range []string{AnnPopulatedFor, AnnPodPhase, AnnImportPod, AnnChecksum, AnnSourceUnchanged} {
+				Expect(pvc.Annotations).ToNot(HaveKey(key))
+			}
+			Expect(pvc.Annotations[AnnEndpoint]).To(Equal(url))
+			Expect(pvc.Annotations[AnnReimport]).To(Equal(reimport))
+			if validatorsKept {
+				Expect(pvc.Annotations[AnnSourceETag]).To(Equal(`"v1"`))
+			} else {
+				Expect(pvc.Annotations).ToNot(HaveKey(AnnSourceETag))
+			}
+			By("Deleting the pod of the previous import")
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-test-dv", Namespace: metav1.NamespaceDefault}, pod)
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		},
+			Entry("if its source changed", ReimportIfChanged, "http://example.com/data", true),
+			Entry("whether its source changed or not", ReimportAlways, "http://example.com/data", true),
+			Entry("from another source", ReimportIfChanged, "http://example.com/other", false),
+		)
+
+		It("Should not import again the data of a populated PVC without the reimport annotation", func() {
+			pvc := CreatePvc("test-dv", metav1.NamespaceDefault, map[string]string{
+				AnnPopulatedFor: "test-dv",
+				AnnPodPhase:     string(corev1.PodSucceeded),
+				AnnEndpoint:     "http://example.com/data",
+			}, nil)
+			pvc.Status.Phase = corev1.ClaimBound
+			reconciler = createImportReconciler(NewImportDataVolume("test-dv"), pvc)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.Annotations[AnnPopulatedFor]).To(Equal("test-dv"))
+			Expect(pvc.Annotations[AnnPodPhase]).To(Equal(string(corev1.PodSucceeded)))
+			dv := &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Annotations[AnnPrePopulated]).To(Equal("test-dv"))
+		})
+
+		It("Should pass the URL and the secret of a GCS source to the created PVC", func() {
+			dv := newS3ImportDataVolume("test-dv")
+			dv.Spec.Source = &cdiv1.DataVolumeSource{
+				GCS: &cdiv1.DataVolumeSourceGCS{URL: "gs://bucket/disk.qcow2", SecretRef: "gcs-key"},
+			}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSource]).To(Equal(SourceGCS))
+			Expect(pvc.GetAnnotations()[AnnEndpoint]).To(Equal("gs://bucket/disk.qcow2"))
+			Expect(pvc.GetAnnotations()[AnnSecret]).To(Equal("gcs-key"))
+		})
+
+		It("Should pass the URL and the secret of an SFTP source to the created PVC", func() {
+			dv := newS3ImportDataVolume("test-dv")
+			dv.Spec.Source = &cdiv1.DataVolumeSource{
+				SFTP: &cdiv1.DataVolumeSourceSFTP{URL: "sftp://importer@host/images/disk.qcow2", SecretRef: "sftp-secret"},
+			}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSource]).To(Equal(SourceSFTP))
+			Expect(pvc.GetAnnotations()[AnnEndpoint]).To(Equal("sftp://importer@host/images/disk.qcow2"))
+			Expect(pvc.GetAnnotations()[AnnSecret]).To(Equal("sftp-secret"))
+		})
+
+		It("Should pass the URL, the secret and the checksum of an SMB source to the created PVC", func() {
+			dv := newS3ImportDataVolume("test-dv")
+			dv.Spec.Source = &cdiv1.DataVolumeSource{
+				SMB: &cdiv1.DataVolumeSourceSMB{URL: "smb://fileserver/vms/images/disk.qcow2", SecretRef: "smb-secret", Checksum: &cdiv1.DataVolumeChecksum{Algorithm: cdiv1.DataVolumeChecksumSHA256, Value: strings.Repeat("0a", 32)}},
+			}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSource]).To(Equal(SourceSMB))
+			Expect(pvc.GetAnnotations()[AnnEndpoint]).To(Equal("smb://fileserver/vms/images/disk.qcow2"))
+			Expect(pvc.GetAnnotations()[AnnSecret]).To(Equal("smb-secret"))
+			Expect(pvc.GetAnnotations()[AnnChecksum]).To(Equal("sha256:" + strings.Repeat("0a", 32)))
+		})
+
+		It("Should pass the Glance image of a Glance source to the created PVC", func() {
+			dv := newS3ImportDataVolume("test-dv")
+			dv.Spec.Source = &cdiv1.DataVolumeSource{
+				Glance: &cdiv1.DataVolumeSourceGlance{
+					URL:           "https://keystone.example.com:5000/v3",
+					Image:         "cirros",
+					Project:       "demo-project",
+					SecretRef:     "glance-secret",
+					CertConfigMap: "glance-ca",
+				},
+			}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSource]).To(Equal(SourceGlance))
+			Expect(pvc.GetAnnotations()[AnnEndpoint]).To(Equal("https://keystone.example.com:5000/v3"))
+			Expect(pvc.GetAnnotations()[AnnSecret]).To(Equal("glance-secret"))
+			Expect(pvc.GetAnnotations()[AnnCertConfigMap]).To(Equal("glance-ca"))
+			Expect(pvc.GetAnnotations()[AnnGlanceImage]).To(Equal("cirros"))
+			Expect(pvc.GetAnnotations()[AnnGlanceProject]).To(Equal("demo-project"))
+			Expect(pvc.GetAnnotations()).ToNot(HaveKey(AnnGlanceRegion))
+		})
+
+		It("Should pass the filesystem of a blank image and its label to the created PVC", func() {
+			dv := newBlankImageDataVolume("test-dv")
+			dv.Spec.Source.Blank.Filesystem = cdiv1.DataVolumeBlankFilesystemXFS
+			dv.Spec.Source.Blank.FilesystemLabel = "data"
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSource]).To(Equal(SourceNone))
+			Expect(pvc.GetAnnotations()[AnnBlankFilesystem]).To(Equal("xfs"))
+			Expect(pvc.GetAnnotations()[AnnBlankFilesystemLabel]).To(Equal("data"))
+		})
+
+		DescribeTable("Should pass the path and the volume of a file source to the created PVC", func(file *cdiv1.DataVolumeSourceFile, annotation, value string) {
+			dv := newS3ImportDataVolume("test-dv")
+			dv.Spec.Source = &cdiv1.DataVolumeSource{File: file}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSource]).To(Equal(SourceFile))
+			Expect(pvc.GetAnnotations()[AnnEndpoint]).To(Equal("images/disk.qcow2"))
+			Expect(pvc.GetAnnotations()[annotation]).To(Equal(value))
+		},
+			Entry("of a PVC", &cdiv1.DataVolumeSourceFile{Path: "images/disk.qcow2", PVC: "images"}, AnnFilePVC, "images"),
+			Entry("of a directory of the node", &cdiv1.DataVolumeSourceFile{Path: "images/disk.qcow2", HostPath: "/var/images"}, AnnFileHostPath, "/var/images"),
+		)
+
+		It("Should pass the URL of an NFS source to the created PVC", func() {
+			dv := newS3ImportDataVolume("test-dv")
+			dv.Spec.Source = &cdiv1.DataVolumeSource{NFS: &cdiv1.DataVolumeSourceNFS{URL: "nfs://nfs.example.com/exports/disk.qcow2"}}
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSource]).To(Equal(SourceNFS))
+			Expect(pvc.GetAnnotations()[AnnEndpoint]).To(Equal("nfs://nfs.example.com/exports/disk.qcow2"))
+		})
+
+		It("Should import the file of a PVC of another namespace into a temporary PVC of that namespace", func() {
+			dv := newCrossNamespaceFileImportDataVolume("test-dv")
+			reconciler = createImportReconciler(dv)
+			reconciler.tokenValidator = newCrossNamespaceFileImportValidator()
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			dv = &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Finalizers).To(ContainElement(crossNamespaceFinalizer))
+			ot := &cdiv1.ObjectTransfer{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: getTransferName(dv)}, ot)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ot.Spec.Source.Namespace).To(Equal("source-ns"))
+			Expect(ot.Spec.Source.Name).To(Equal(getTransferName(dv)))
+			Expect(*ot.Spec.Target.Namespace).To(Equal(metav1.NamespaceDefault))
+			Expect(*ot.Spec.Target.Name).To(Equal("test-dv"))
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: getTransferName(dv), Namespace: "source-ns"}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnSource]).To(Equal(SourceFile))
+			Expect(pvc.GetAnnotations()[AnnFilePVC]).To(Equal("images"))
+			Expect(pvc.GetAnnotations()[AnnOwnerUID]).To(Equal(string(dv.UID)))
+			Expect(pvc.OwnerReferences).To(BeEmpty())
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should not import the file of a PVC of another namespace without a valid clone token", func() {
+			dv := newCrossNamespaceFileImportDataVolume("test-dv")
+			dv.Annotations[AnnCloneToken] = "invalid"
+			reconciler = createImportReconciler(dv)
+			reconciler.tokenValidator = newCrossNamespaceFileImportValidator()
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error verifying token"))
+			ot := &cdiv1.ObjectTransfer{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: getTransferName(dv)}, ot)
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should transfer the temporary PVC of a cross namespace import once the import succeeded", func() {
+			dv := newCrossNamespaceFileImportDataVolume("test-dv")
+			dv.Finalizers = append(dv.Finalizers, crossNamespaceFinalizer)
+			ot := &cdiv1.ObjectTransfer{ObjectMeta: metav1.ObjectMeta{Name: getTransferName(dv)}}
+			tmpPVC := CreatePvc(getTransferName(dv), "source-ns", map[string]string{AnnPodPhase: string(corev1.PodSucceeded)}, nil)
+			reconciler = createImportReconciler(dv, ot, tmpPVC)
+			reconciler.tokenValidator = newCrossNamespaceFileImportValidator()
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: getTransferName(dv), Namespace: "source-ns"}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[annReadyForTransfer]).To(Equal("true"))
+			Expect(pvc.GetAnnotations()[AnnPopulatedFor]).To(Equal("test-dv"))
+			dv = &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Phase).To(Equal(cdiv1.NamespaceTransferInProgress))
+		})
+
+		It("Should delete the temporary PVC and the transfer of a deleted cross namespace import", func() {
+			dv := newCrossNamespaceFileImportDataVolume("test-dv")
+			dv.Finalizers = append(dv.Finalizers, crossNamespaceFinalizer)
+			ts := metav1.Now()
+			dv.DeletionTimestamp = &ts
+			ot := &cdiv1.ObjectTransfer{ObjectMeta: metav1.ObjectMeta{Name: getTransferName(dv)}}
+			tmpPVC := CreatePvc(getTransferName(dv), "source-ns", map[string]string{}, nil)
+			reconciler = createImportReconciler(dv, ot, tmpPVC)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("waiting for ObjectTransfer"))
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: getTransferName(dv), Namespace: "source-ns"}, &corev1.PersistentVolumeClaim{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: getTransferName(dv)}, &cdiv1.ObjectTransfer{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		DescribeTable("Should pass the encryption secret of the source to the created PVC", func(dv *cdiv1.DataVolume) {
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnEncryptionSecret]).To(Equal("passphrase-secret"))
+		},
+			Entry("with an HTTP source", func() *cdiv1.DataVolume {
+				dv := NewImportDataVolume("test-dv")
+				dv.Spec.Source.HTTP.EncryptionSecretRef = "passphrase-secret"
+				return dv
+			}()),
+			Entry("with an S3 source", func() *cdiv1.DataVolume {
+				dv := newS3ImportDataVolume("test-dv")
+				dv.Spec.Source.S3.EncryptionSecretRef = "passphrase-secret"
+				return dv
+			}()),
+		)
+
+		It("Should request the disk format of the DV on the created PVC", func() {
+			dv := NewImportDataVolume("test-dv")
+			dv.Spec.DiskFormat = cdiv1.DataVolumeDiskFormatQcow2
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnDiskFormatRequested]).To(Equal("qcow2"))
+		})
+
+		It("Should request the preallocation mode of the DV on the created PVC", func() {
+			dv := NewImportDataVolume("test-dv")
+			dv.Spec.PreallocationMode = cdiv1.DataVolumePreallocationFull
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnPreallocationModeRequested]).To(Equal("full"))
+		})
+
+		It("Should limit the retries of the import of the created PVC to the backoff limit of the DV", func() {
+			dv := NewImportDataVolume("test-dv")
+			dv.Annotations = map[string]string{AnnImportMaxRetries: "5"}
+			backoffLimit := int32(2)
+			dv.Spec.BackoffLimit = &backoffLimit
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnImportMaxRetries]).To(Equal("2"))
+		})
+
 		It("Should follow the phase of the created PVC", func() {
 			reconciler = createImportReconciler(NewImportDataVolume("test-dv"))
 			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
@@ -562,6 +1090,121 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(dv.Status.RestartCount).To(Equal(int32(2)))
 		})
 
+		It("Should report the attempts of the import and the reason of its last failure", func() {
+			reconciler = createImportReconciler(NewImportDataVolume("test-dv"))
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+
+			dv := &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.ImportAttempts).To(BeZero())
+			Expect(dv.Status.LastImportFailure).To(BeEmpty())
+
+			pvc.Annotations[AnnImportPod] = "importer-test-dv"
+			pvc.Annotations[AnnPodRestarts] = "6"
+			pvc.Annotations[AnnImportLastFailure] = "Unable to connect to http data source: connection reset by peer"
+			err = reconciler.client.Update(context.TODO(), pvc)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+
+			dv = &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.ImportAttempts).To(Equal(int32(7)))
+			Expect(dv.Status.LastImportFailure).To(Equal("Unable to connect to http data source: connection reset by peer"))
+		})
+
+		It("Should report the qcow2 options of the PVC", func() {
+			reconciler = createImportReconciler(NewImportDataVolume("test-dv"))
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+
+			pvc.Annotations[AnnQcow2Options] = "cluster_size=2097152,compat=0.10"
+			err = reconciler.client.Update(context.TODO(), pvc)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+
+			dv := &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Qcow2Options).To(Equal("cluster_size=2097152,compat=0.10"))
+		})
+
+		It("Should report the URL which served the data of the PVC", func() {
+			reconciler = createImportReconciler(NewImportDataVolume("test-dv"))
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+
+			pvc.Annotations[AnnSourceURL] = "https://mirror.example.com/disk.img"
+			err = reconciler.client.Update(context.TODO(), pvc)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+
+			dv := &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.SourceURL).To(Equal("https://mirror.example.com/disk.img"))
+		})
+
+		DescribeTable("Should report the preallocation of the PVC", func(preallocation string, configPreallocation, requested bool) {
+			dv := NewImportDataVolume("test-dv")
+			if preallocation != "" {
+				value := preallocation == "true"
+				dv.Spec.Preallocation = &value
+			}
+			reconciler = createImportReconciler(dv)
+			cdiConfig := &cdiv1.CDIConfig{}
+			err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+			Expect(err).ToNot(HaveOccurred())
+			cdiConfig.Status.Preallocation = configPreallocation
+			err = reconciler.client.Update(context.TODO(), cdiConfig)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+
+			dv = &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Preallocation).To(Equal(&cdiv1.DataVolumePreallocationStatus{Requested: requested}))
+
+			By("Reporting the preallocation performed by the importer pod")
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			pvc.Annotations[AnnPreallocationApplied] = "true"
+			pvc.Annotations[AnnPreallocationMode] = "full"
+			err = reconciler.client.Update(context.TODO(), pvc)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+
+			dv = &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Preallocation).To(Equal(&cdiv1.DataVolumePreallocationStatus{Requested: requested, Applied: true, Mode: cdiv1.DataVolumePreallocationFull}))
+		},
+			Entry("requested by the DataVolume", "true", false, true),
+			Entry("not requested by the DataVolume", "false", true, false),
+			Entry("requested by the CDIConfig", "", true, true),
+			Entry("not requested by the CDIConfig", "", false, false),
+		)
+
 		It("Should error if a PVC with same name already exists that is not owned by us", func() {
 			reconciler = createImportReconciler(CreatePvc("test-dv", metav1.NamespaceDefault, map[string]string{}, nil), NewImportDataVolume("test-dv"))
 			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
@@ -798,6 +1441,18 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(pvc).ToNot(BeNil())
 			Expect(pvc.GetAnnotations()[AnnVddkInitImageURL]).To(Equal("test://image"))
 		})
+
+		It("Should add the VDDK VM moref to PVC", func() {
+			dv := newVDDKDataVolume("test-dv")
+			dv.Spec.Source.VDDK.Moref = "vm-1234"
+			reconciler = createImportReconciler(dv)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.GetAnnotations()[AnnMoref]).To(Equal("vm-1234"))
+		})
 	})
 
 	var _ = Describe("Reconcile Datavolume status", func() {
@@ -963,6 +1618,48 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(found).To(BeTrue())
 		})
 
+		It("Should set DV phase to PendingPopulation if storage class is WFFC and immediate binding is requested", func() {
+			scName := "default_test_sc"
+			sc := createStorageClassWithBindingMode(scName,
+				map[string]string{
+					AnnDefaultStorageClass: "true",
+				},
+				storagev1.VolumeBindingWaitForFirstConsumer)
+			importDataVolume := NewImportDataVolume("test-dv")
+			importDataVolume.Annotations = map[string]string{AnnImmediateBinding: "true"}
+			reconciler = createImportReconciler(sc, importDataVolume)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			dv := &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.Annotations).To(HaveKey(AnnImmediateBinding))
+			pvc.Status.Phase = corev1.ClaimPending
+			err = reconciler.client.Update(context.TODO(), pvc)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = reconciler.updateStatusCommon(createSyncResult(dv, pvc), reconciler.updateStatusPhase)
+			Expect(err).ToNot(HaveOccurred())
+			dv = &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Phase).To(Equal(cdiv1.PendingPopulation))
+
+			By("Switching to PVCBound once the PVC is bound")
+			pvc.Status.Phase = corev1.ClaimBound
+			err = reconciler.client.Update(context.TODO(), pvc)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = reconciler.updateStatusCommon(createSyncResult(dv, pvc), reconciler.updateStatusPhase)
+			Expect(err).ToNot(HaveOccurred())
+			dv = &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Phase).To(Equal(cdiv1.PVCBound))
+		})
+
 		It("Should switch to succeeded if PVC phase is pending, but pod phase is succeeded", func() {
 			reconciler = createImportReconciler(NewImportDataVolume("test-dv"))
 			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
@@ -1069,8 +1766,16 @@ var _ = Describe("All DataVolume Tests", func() {
 			Entry("should switch to scheduled for import", NewImportDataVolume("test-dv"), cdiv1.Pending, cdiv1.ImportScheduled, corev1.ClaimBound, corev1.PodPending, AnnImportPod, "Import into test-dv scheduled", AnnPriorityClassName, "p0"),
 			Entry("should switch to inprogress for import", NewImportDataVolume("test-dv"), cdiv1.Pending, cdiv1.ImportInProgress, corev1.ClaimBound, corev1.PodRunning, AnnImportPod, "Import into test-dv in progress", AnnPriorityClassName, "p0"),
 			Entry("should stay the same for import after pod fails", NewImportDataVolume("test-dv"), cdiv1.Pending, cdiv1.ImportScheduled, corev1.ClaimBound, corev1.PodFailed, AnnImportPod, "Failed to import into PVC test-dv", AnnPriorityClassName, "p0"),
+			Entry("should switch to failed for import after pod fails with an unsupported format", NewImportDataVolume("test-dv"), cdiv1.ImportInProgress, cdiv1.Failed, corev1.ClaimBound, corev1.PodFailed, AnnImportPod, "Failed to import into PVC test-dv: unsupported format", AnnImportTerminalError, "unsupported format"),
+			Entry("should switch to failed for import after pod fails with an invalid client certificate", NewImportDataVolume("test-dv"), cdiv1.ImportInProgress, cdiv1.Failed, corev1.ClaimBound, corev1.PodFailed, AnnImportPod, "InvalidClientCertificate Failed to import into PVC test-dv: invalid client certificate", AnnImportTerminalError, "invalid client certificate", AnnRunningConditionReason, InvalidClientCertificate),
+			Entry("should switch to failed for import after pod fails with a checksum mismatch", NewImportDataVolume("test-dv"), cdiv1.ImportInProgress, cdiv1.Failed, corev1.ClaimBound, corev1.PodFailed, AnnImportPod, "ChecksumMismatch Failed to import into PVC test-dv: checksum mismatch", AnnImportTerminalError, "checksum mismatch", AnnRunningConditionReason, ChecksumMismatch),
+			Entry("should switch to failed for import after pod fails with an invalid signature", NewImportDataVolume("test-dv"), cdiv1.ImportInProgress, cdiv1.Failed, corev1.ClaimBound, corev1.PodFailed, AnnImportPod, "InvalidSignature Failed to import into PVC test-dv: invalid signature", AnnImportTerminalError, "invalid signature", AnnRunningConditionReason, InvalidSignature),
+			Entry("should switch to failed for import after pod fails without matching entry", NewImportDataVolume("test-dv"), cdiv1.ImportInProgress, cdiv1.Failed, corev1.ClaimBound, corev1.PodFailed, AnnImportPod, "NoMatchingEntry Failed to import into PVC test-dv: no matching entry", AnnImportTerminalError, "no matching entry", AnnRunningConditionReason, NoMatchingEntry),
+			Entry("should switch to failed for import after pod fails without finding the source", NewImportDataVolume("test-dv"), cdiv1.ImportInProgress, cdiv1.Failed, corev1.ClaimBound, corev1.PodFailed, AnnImportPod, "SourceNotFound Failed to import into PVC test-dv: source not found", AnnImportTerminalError, "source not found", AnnRunningConditionReason, SourceNotFound),
+			Entry("should switch to failed for import after pod fails as many times as its retry policy allows", NewImportDataVolume("test-dv"), cdiv1.ImportInProgress, cdiv1.Failed, corev1.ClaimBound, corev1.PodFailed, AnnImportPod, "RetryLimitExceeded Failed to import into PVC test-dv: import failed after 3 retries", AnnImportTerminalError, "import failed after 3 retries", AnnRunningConditionReason, RetryLimitExceeded),
 			Entry("should switch to failed on claim lost for impot", NewImportDataVolume("test-dv"), cdiv1.Pending, cdiv1.Failed, corev1.ClaimLost, corev1.PodFailed, AnnImportPod, "PVC test-dv lost", AnnPriorityClassName, "p0"),
 			Entry("should switch to succeeded for import", NewImportDataVolume("test-dv"), cdiv1.Pending, cdiv1.Succeeded, corev1.ClaimBound, corev1.PodSucceeded, AnnImportPod, "Successfully imported into PVC test-dv", AnnPriorityClassName, "p0"),
+			Entry("should switch to succeeded for import of an unchanged source", NewImportDataVolume("test-dv"), cdiv1.Pending, cdiv1.Succeeded, corev1.ClaimBound, corev1.PodSucceeded, AnnImportPod, "The source did not change since it was imported into PVC test-dv, its data is kept", AnnSourceUnchanged, "true"),
 			Entry("should switch to scheduled for blank", newBlankImageDataVolume("test-dv"), cdiv1.Pending, cdiv1.ImportScheduled, corev1.ClaimBound, corev1.PodPending, AnnImportPod, "Import into test-dv scheduled", AnnPriorityClassName, "p0-upload"),
 			Entry("should switch to inprogress for blank", newBlankImageDataVolume("test-dv"), cdiv1.Pending, cdiv1.ImportInProgress, corev1.ClaimBound, corev1.PodRunning, AnnImportPod, "Import into test-dv in progress"),
 			Entry("should stay the same for blank after pod fails", newBlankImageDataVolume("test-dv"), cdiv1.Pending, cdiv1.ImportScheduled, corev1.ClaimBound, corev1.PodFailed, AnnImportPod, "Failed to import into PVC test-dv"),
@@ -1178,7 +1883,7 @@ var _ = Describe("All DataVolume Tests", func() {
 
 		It("Should return error, if no metrics port in pod", func() {
 			pod.Spec.Containers[0].Ports = nil
-			err := updateProgressUsingPod(dv, pod)
+			_, err := updateProgressUsingPod(dv, pod)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("Metrics port not found in pod"))
 		})
@@ -1186,7 +1891,7 @@ var _ = Describe("All DataVolume Tests", func() {
 		It("Should not error, if no endpoint exists", func() {
 			pod.Spec.Containers[0].Ports[0].ContainerPort = 12345
 			pod.Status.PodIP = "127.0.0.1"
-			err := updateProgressUsingPod(dv, pod)
+			_, err := updateProgressUsingPod(dv, pod)
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -1203,11 +1908,38 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(err).ToNot(HaveOccurred())
 			pod.Spec.Containers[0].Ports[0].ContainerPort = int32(port)
 			pod.Status.PodIP = ep.Hostname()
-			err = updateProgressUsingPod(dv, pod)
+			_, err = updateProgressUsingPod(dv, pod)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(dv.Status.Progress).To(BeEquivalentTo("13.45%"))
 		})
 
+		It("Should report the bytes transferred if http endpoint returns no progress", func() {
+			dv.SetUID("b856691e-1038-11e9-a5ab-525500d15501")
+			ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(fmt.Sprintf("kubevirt_cdi_import_transferred_bytes{ownerUID=\"%v\"} 1.610612736e+09", dv.GetUID())))
+				w.WriteHeader(200)
+			}))
+			defer ts.Close()
+			ep, err := url.Parse(ts.URL)
+			Expect(err).ToNot(HaveOccurred())
+			port, err := strconv.Atoi(ep.Port())
+			Expect(err).ToNot(HaveOccurred())
+			pod.Spec.Containers[0].Ports[0].ContainerPort = int32(port)
+			pod.Status.PodIP = ep.Hostname()
+			_, err = updateProgressUsingPod(dv, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Progress).To(BeEquivalentTo("1.50GiB"))
+		})
+
+		DescribeTable("Should format the bytes transferred", func(bytes float64, expected string) {
+			Expect(formatBytes(bytes)).To(Equal(expected))
+		},
+			Entry("in bytes", float64(512), "512B"),
+			Entry("in KiB", float64(1536), "1.50KiB"),
+			Entry("in MiB", float64(100*1024*1024), "100.00MiB"),
+			Entry("in TiB", float64(3*1024*1024*1024*1024), "3.00TiB"),
+		)
+
 		It("Should not change update progress if http endpoint returns no matching data", func() {
 			dv.SetUID("b856691e-1038-11e9-a5ab-525500d15501")
 			dv.Status.Progress = cdiv1.DataVolumeProgress("2.3%")
@@ -1222,10 +1954,67 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(err).ToNot(HaveOccurred())
 			pod.Spec.Containers[0].Ports[0].ContainerPort = int32(port)
 			pod.Status.PodIP = ep.Hostname()
-			err = updateProgressUsingPod(dv, pod)
+			_, err = updateProgressUsingPod(dv, pod)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(dv.Status.Progress).To(BeEquivalentTo("2.3%"))
 		})
+
+		It("Should return the milestones reached by the import", func() {
+			dv.SetUID("b856691e-1038-11e9-a5ab-525500d15501")
+			ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(fmt.Sprintf("import_progress{ownerUID=\"%v\"} 52.3\n", dv.GetUID())))
+				w.Write([]byte(fmt.Sprintf("kubevirt_cdi_import_milestone{milestone=\"SourceConnected\",ownerUID=\"%v\"} 1\n", dv.GetUID())))
+				w.Write([]byte(fmt.Sprintf("kubevirt_cdi_import_milestone{milestone=\"ConversionStarted\",ownerUID=\"%v\"} 1\n", dv.GetUID())))
+				w.Write([]byte("kubevirt_cdi_import_milestone{milestone=\"VerificationPassed\",ownerUID=\"b856691e-1038-11e9-a5ab-55500d15501\"} 1\n"))
+				w.WriteHeader(200)
+			}))
+			defer ts.Close()
+			ep, err := url.Parse(ts.URL)
+			Expect(err).ToNot(HaveOccurred())
+			port, err := strconv.Atoi(ep.Port())
+			Expect(err).ToNot(HaveOccurred())
+			pod.Spec.Containers[0].Ports[0].ContainerPort = int32(port)
+			pod.Status.PodIP = ep.Hostname()
+			milestones, err := updateProgressUsingPod(dv, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Progress).To(BeEquivalentTo("52.30%"))
+			Expect(milestones).To(Equal([]string{common.ImportMilestoneSourceConnected, common.ImportMilestoneConversionStarted, "Transferred25", "Transferred50"}))
+		})
+	})
+
+	var _ = Describe("Import milestones", func() {
+		It("Should relay each milestone of the import once", func() {
+			dv := NewImportDataVolume("test-dv")
+			pvc := CreatePvc("test-dv", metav1.NamespaceDefault, map[string]string{
+				AnnImportPod:               "importer-test-dv",
+				AnnImportMilestones:        "SourceConnected,ConversionStarted,VerificationPassed",
+				AnnImportMilestonesRelayed: "SourceConnected",
+			}, nil)
+			reconciler = createImportReconciler(dv, pvc)
+
+			Expect(reconciler.relayImportMilestones(dv, pvc, []string{"Transferred25"})).To(Succeed())
+			events := reconciler.recorder.(*record.FakeRecorder).Events
+			Expect(events).To(HaveLen(3))
+			Expect(<-events).To(ContainSubstring(fmt.Sprintf(MessageImportConversionStarted, pvc.Name)))
+			Expect(<-events).To(ContainSubstring(fmt.Sprintf(MessageImportTransferred, 25, pvc.Name)))
+			Expect(<-events).To(ContainSubstring(ImportVerificationPassed))
+
+			err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.Annotations[AnnImportMilestonesRelayed]).To(Equal("SourceConnected,ConversionStarted,Transferred25,VerificationPassed"))
+
+			Expect(reconciler.relayImportMilestones(dv, pvc, []string{"Transferred25"})).To(Succeed())
+			Expect(events).To(BeEmpty())
+		})
+
+		It("Should not relay milestones for a PVC without import", func() {
+			dv := NewImportDataVolume("test-dv")
+			pvc := CreatePvc("test-dv", metav1.NamespaceDefault, nil, nil)
+			reconciler = createImportReconciler(dv, pvc)
+
+			Expect(reconciler.relayImportMilestones(dv, pvc, []string{common.ImportMilestoneSourceConnected})).To(Succeed())
+			Expect(reconciler.recorder.(*record.FakeRecorder).Events).To(BeEmpty())
+		})
 	})
 
 	const (
@@ -1284,6 +2073,46 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(pvc.OwnerReferences).To(HaveLen(4))
 			Expect(pvc.OwnerReferences).To(Equal([]metav1.OwnerReference{ref("1"), ref("2"), ref("3"), vmOwnerRef}))
 		})
+
+		DescribeTable("garbageCollect should", func(configTTL *int32, annotations map[string]string, collected bool, requeued bool) {
+			dv := NewImportDataVolume("test-dv")
+			dv.Status.Phase = cdiv1.Succeeded
+			dv.Annotations = annotations
+			vmOwnerRef := metav1.OwnerReference{Kind: "VirtualMachine", Name: "test-vm", UID: "test-vm-uid"}
+			dv.OwnerReferences = []metav1.OwnerReference{vmOwnerRef}
+			pvc := CreatePvc("test-dv", metav1.NamespaceDefault, nil, nil)
+			pvc.OwnerReferences = []metav1.OwnerReference{{Kind: "DataVolume", Name: "test-dv", UID: dv.UID}}
+			reconciler = createImportReconciler(dv, pvc)
+			cdiConfig := &cdiv1.CDIConfig{}
+			err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+			Expect(err).ToNot(HaveOccurred())
+			cdiConfig.Spec.DataVolumeTTLSeconds = configTTL
+			err = reconciler.client.Update(context.TODO(), cdiConfig)
+			Expect(err).ToNot(HaveOccurred())
+
+			syncRes := createSyncResult(dv, pvc)
+			err = reconciler.garbageCollect(&syncRes, reconciler.log)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, &cdiv1.DataVolume{})
+			Expect(k8serrors.IsNotFound(err)).To(Equal(collected))
+			Expect(syncRes.result != nil && syncRes.result.RequeueAfter > 0).To(Equal(requeued))
+			if !collected {
+				return
+			}
+			pvc = &corev1.PersistentVolumeClaim{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, pvc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pvc.OwnerReferences).To(Equal([]metav1.OwnerReference{vmOwnerRef}))
+			Expect(pvc.Annotations[AnnPopulatedFor]).To(Equal("test-dv"))
+		},
+			Entry("not collect a DataVolume by default", nil, map[string]string{AnnDeleteAfterCompletion: "true"}, false, false),
+			Entry("collect an annotated DataVolume when the TTL is set", pointer.Int32(0), map[string]string{AnnDeleteAfterCompletion: "true"}, true, false),
+			Entry("not collect a DataVolume annotated not to be collected", pointer.Int32(0), map[string]string{AnnDeleteAfterCompletion: "false"}, false, false),
+			Entry("collect a DataVolume setting its own TTL", nil, map[string]string{AnnTTLSecondsAfterCompletion: "0"}, true, false),
+			Entry("requeue a DataVolume until its TTL expires", pointer.Int32(0), map[string]string{AnnDeleteAfterCompletion: "true", AnnTTLSecondsAfterCompletion: "3600"}, false, true),
+			Entry("not collect a DataVolume disabling GC", pointer.Int32(0), map[string]string{AnnDeleteAfterCompletion: "true", AnnTTLSecondsAfterCompletion: "-1"}, false, false),
+		)
 	})
 
 })
@@ -1427,6 +2256,9 @@ func createImportReconcilerWithoutConfig(objects ...runtime.Object) *ImportRecon
 				common.AppKubernetesVersionLabel: "v0.0.0-tests",
 			},
 		},
+		tokenValidator: &FakeValidator{Match: "foobar"},
+		importerImage:  testImporterImage,
+		pullPolicy:     string(corev1.PullIfNotPresent),
 	}
 	r.Reconciler = r
 	return r
@@ -1451,6 +2283,28 @@ func newImportDataVolumeWithPvc(name string, pvc *corev1.PersistentVolumeClaimSp
 	}
 }
 
+func newCrossNamespaceFileImportDataVolume(name string) *cdiv1.DataVolume {
+	dv := newS3ImportDataVolume(name)
+	dv.Annotations = map[string]string{AnnCloneToken: "foobar"}
+	dv.Spec.Source = &cdiv1.DataVolumeSource{
+		File: &cdiv1.DataVolumeSourceFile{Path: "images/disk.qcow2", PVC: "images", Namespace: "source-ns"},
+	}
+	return dv
+}
+
+func newCrossNamespaceFileImportValidator() *FakeValidator {
+	return &FakeValidator{
+		Match:     "foobar",
+		Operation: token.OperationClone,
+		Name:      "images",
+		Namespace: "source-ns",
+		Params: map[string]string{
+			"targetNamespace": metav1.NamespaceDefault,
+			"targetName":      "test-dv",
+		},
+	}
+}
+
 func newS3ImportDataVolume(name string) *cdiv1.DataVolume {
 	return &cdiv1.DataVolume{
 		TypeMeta: metav1.TypeMeta{APIVersion: cdiv1.SchemeGroupVersion.String()},
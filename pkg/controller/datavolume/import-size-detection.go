@@ -0,0 +1,300 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datavolume
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+)
+
+const (
+	// ImportSizeDetectionFailed provides a const to indicate the size of the source of an import could not be found
+	ImportSizeDetectionFailed = "ImportSizeDetectionFailed"
+	// MessageImportSizeDetectionFailed provides a const to form the message of a source whose size could not be found
+	MessageImportSizeDetectionFailed = "Unable to find the size of the source: %s. Set the storage size of the DataVolume"
+
+	importSizeDetectionCertVolName = "cdi-cert-vol"
+	// importSizeDetectionSecretHeadersVolName is the format string of the volumes of the secrets of extra headers
+	importSizeDetectionSecretHeadersVolName = "cdi-secret-extra-headers-vol-%d"
+)
+
+// isImportSizeOmitted returns true if the DataVolume imports an http source without a storage size,
+// the size of the PVC is then found by probing the source.
+func isImportSizeOmitted(dv *cdiv1.DataVolume) bool {
+	if dv.Spec.Storage == nil || dv.Spec.Source == nil || dv.Spec.Source.HTTP == nil {
+		return false
+	}
+	_, found := dv.Spec.Storage.Resources.Requests[corev1.ResourceStorage]
+	return !found
+}
+
+// getRequiredImportSize returns the virtual size of the source of an import whose storage size is
+// omitted, once found by the size-detection pod.
+func getRequiredImportSize(dv *cdiv1.DataVolume) (int64, bool) {
+	if !isImportSizeOmitted(dv) {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(dv.Annotations[cc.AnnRequiredSize], 10, 64)
+	return size, err == nil && size > 0
+}
+
+// detectImportSize finds the virtual size of the image of an http source imported without a storage
+// size, with a size-detection pod probing the source. The size is recorded in the requiredSize
+// annotation of the DataVolume, and the PVC is not created until then. The DataVolume fails if the
+// size cannot be found, the source is not probed again.
+func (r ImportReconciler) detectImportSize(syncRes *dataVolumeSyncResult) error {
+	dv := syncRes.dvMutated
+	if syncRes.pvc != nil || !isImportSizeOmitted(dv) {
+		return nil
+	}
+	if _, found := getRequiredImportSize(dv); found {
+		return nil
+	}
+	syncRes.result = &reconcile.Result{}
+	if dv.Status.Phase == cdiv1.Failed {
+		return nil
+	}
+
+	pod, err := r.getOrCreateImportSizeDetectionPod(dv)
+	if err != nil {
+		return err
+	}
+	var message string
+	if status := pod.Status.ContainerStatuses; len(status) > 0 && status[0].State.Terminated != nil {
+		message = status[0].State.Terminated.Message
+	}
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		if err := r.deleteImportSizeDetectionPod(pod); err != nil {
+			return err
+		}
+		size, err := strconv.ParseInt(message, 10, 64)
+		if err != nil || size <= 0 {
+			r.failImportSizeDetection(syncRes, fmt.Sprintf("invalid size %q", message))
+			return nil
+		}
+		cc.AddAnnotation(dv, cc.AnnRequiredSize, message)
+		r.log.V(1).Info("Found the size of the source", "DataVolume", dv.Name, "size", size)
+		syncRes.result = nil
+	case corev1.PodFailed:
+		if err := r.deleteImportSizeDetectionPod(pod); err != nil {
+			return err
+		}
+		if message == "" {
+			message = "the size-detection pod failed"
+		}
+		r.failImportSizeDetection(syncRes, message)
+	default:
+		syncRes.phaseSync = &statusPhaseSync{
+			phase: cdiv1.Pending,
+			event: Event{
+				eventType: corev1.EventTypeNormal,
+				reason:    SizeDetectionPodNotReady,
+				message:   MessageSizeDetectionPodNotReady,
+			},
+		}
+	}
+	return nil
+}
+
+// failImportSizeDetection fails the DataVolume whose source size could not be found.
+func (r ImportReconciler) failImportSizeDetection(syncRes *dataVolumeSyncResult, reason string) {
+	syncRes.phaseSync = &statusPhaseSync{
+		phase: cdiv1.Failed,
+		event: Event{
+			eventType: corev1.EventTypeWarning,
+			reason:    ImportSizeDetectionFailed,
+			message:   fmt.Sprintf(MessageImportSizeDetectionFailed, reason),
+		},
+	}
+}
+
+func (r ImportReconciler) deleteImportSizeDetectionPod(pod *corev1.Pod) error {
+	if err := r.client.Delete(context.TODO(), pod); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// getOrCreateImportSizeDetectionPod gets the size-detection pod of the DataVolume if it already exists/creates it if not
+func (r ImportReconciler) getOrCreateImportSizeDetectionPod(dv *cdiv1.DataVolume) (*corev1.Pod, error) {
+	pod := &corev1.Pod{}
+	nn := types.NamespacedName{Namespace: dv.Namespace, Name: importSizeDetectionPodName(dv)}
+	if err := r.client.Get(context.TODO(), nn, pod); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+		pod, err = r.makeImportSizeDetectionPodSpec(dv)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.client.Create(context.TODO(), pod); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		r.recorder.Event(dv, corev1.EventTypeNormal, SizeDetectionPodCreated, MessageSizeDetectionPodCreated)
+		r.log.V(3).Info(MessageSizeDetectionPodCreated, "pod.Name", pod.Name, "pod.Namespace", pod.Namespace)
+	}
+	return pod, nil
+}
+
+// makeImportSizeDetectionPodSpec creates the spec of the pod probing the http source of the DataVolume,
+// with the credentials, certificates, headers and proxy the importer would use.
+func (r ImportReconciler) makeImportSizeDetectionPodSpec(dv *cdiv1.DataVolume) (*corev1.Pod, error) {
+	source := dv.Spec.Source.HTTP
+	workloadNodePlacement, err := cc.GetWorkloadNodePlacement(r.client)
+	if err != nil {
+		return nil, err
+	}
+	resourceRequirements, err := cc.GetDefaultPodResourceRequirements(r.client)
+	if err != nil {
+		return nil, err
+	}
+	cdiConfig := &cdiv1.CDIConfig{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig); err != nil {
+		return nil, err
+	}
+
+	container := corev1.Container{
+		Name:            "size-detection",
+		Image:           r.importerImage,
+		ImagePullPolicy: corev1.PullPolicy(r.pullPolicy),
+		Command:         []string{"/usr/bin/cdi-image-size-detection"},
+		Args:            []string{"-image-path", source.URL},
+		Env:             makeImportSizeDetectionEnv(dv, cdiConfig),
+	}
+	if resourceRequirements != nil {
+		container.Resources = *resourceRequirements
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      importSizeDetectionPodName(dv),
+			Namespace: dv.Namespace,
+			Labels: map[string]string{
+				common.CDILabelKey:       common.CDILabelValue,
+				common.CDIComponentLabel: common.ImporterPodName,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers:    []corev1.Container{container},
+			RestartPolicy: corev1.RestartPolicyNever,
+			NodeSelector:  workloadNodePlacement.NodeSelector,
+			Tolerations:   workloadNodePlacement.Tolerations,
+			Affinity:      workloadNodePlacement.Affinity,
+		},
+	}
+	if source.CertConfigMap != "" {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: importSizeDetectionCertVolName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: source.CertConfigMap},
+				},
+			},
+		})
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      importSizeDetectionCertVolName,
+			MountPath: common.ImporterCertDir,
+		})
+	}
+	for index, secret := range source.SecretExtraHeaders {
+		name := fmt.Sprintf(importSizeDetectionSecretHeadersVolName, index)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: secret},
+			},
+		})
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: path.Join(common.ImporterSecretExtraHeadersDir, fmt.Sprint(index)),
+		})
+	}
+	if err := controllerutil.SetControllerReference(dv, pod, r.scheme); err != nil {
+		return nil, err
+	}
+	cc.SetRestrictedSecurityContext(&pod.Spec)
+	return pod, nil
+}
+
+// makeImportSizeDetectionEnv returns the environment of the size-detection pod of the DataVolume
+func makeImportSizeDetectionEnv(dv *cdiv1.DataVolume, cdiConfig *cdiv1.CDIConfig) []corev1.EnvVar {
+	source := dv.Spec.Source.HTTP
+	var env []corev1.EnvVar
+	if source.SecretRef != "" {
+		// the secret holds either an access key or a bearer token
+		optional := true
+		for _, secretEnv := range []struct{ name, key string }{
+			{common.ImporterAccessKeyID, common.KeyAccess},
+			{common.ImporterSecretKey, common.KeySecret},
+			{common.ImporterBearerToken, common.KeyToken},
+		} {
+			env = append(env, corev1.EnvVar{
+				Name: secretEnv.name,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: source.SecretRef},
+						Key:                  secretEnv.key,
+						Optional:             &optional,
+					},
+				},
+			})
+		}
+	}
+	if source.CertConfigMap != "" {
+		env = append(env, corev1.EnvVar{Name: common.ImporterCertDirVar, Value: common.ImporterCertDir})
+	}
+	for index, header := range source.ExtraHeaders {
+		env = append(env, corev1.EnvVar{Name: fmt.Sprintf("%s%d", common.ImporterExtraHeader, index), Value: header})
+	}
+	values := []corev1.EnvVar{
+		{Name: common.ImporterURLPattern, Value: dv.Annotations[cc.AnnURLPattern]},
+		{Name: common.ImporterMaxRedirects, Value: dv.Annotations[cc.AnnMaxRedirects]},
+		{Name: common.ImporterRedirectHosts, Value: dv.Annotations[cc.AnnRedirectHosts]},
+	}
+	if proxy := cdiConfig.Status.ImportProxy; proxy != nil {
+		values = append(values,
+			corev1.EnvVar{Name: common.ImportProxyHTTP, Value: pointer.StringDeref(proxy.HTTPProxy, "")},
+			corev1.EnvVar{Name: common.ImportProxyHTTPS, Value: pointer.StringDeref(proxy.HTTPSProxy, "")},
+			corev1.EnvVar{Name: common.ImportProxyNoProxy, Value: pointer.StringDeref(proxy.NoProxy, "")},
+		)
+	}
+	for _, value := range values {
+		if value.Value != "" {
+			env = append(env, value)
+		}
+	}
+	return env
+}
+
+// importSizeDetectionPodName returns the name of the size-detection pod according to the DataVolume's UID
+func importSizeDetectionPodName(dv *cdiv1.DataVolume) string {
+	return fmt.Sprintf("size-detection-%s", dv.UID)
+}
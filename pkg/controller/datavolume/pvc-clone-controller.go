@@ -31,6 +31,7 @@ import (
 	storagev1 "k8s.io/api/storage/v1"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -81,7 +82,7 @@ func NewPvcCloneController(
 	clonerImage string,
 	importerImage string,
 	pullPolicy string,
-	tokenPublicKey *rsa.PublicKey,
+	tokenPublicKeys []*rsa.PublicKey,
 	tokenPrivateKey *rsa.PrivateKey,
 	installerLabels map[string]string,
 ) (controller.Controller, error) {
@@ -105,7 +106,7 @@ func NewPvcCloneController(
 			clonerImage:    clonerImage,
 			importerImage:  importerImage,
 			pullPolicy:     pullPolicy,
-			tokenValidator: cc.NewCloneTokenValidator(common.CloneTokenIssuer, tokenPublicKey),
+			tokenValidator: cc.NewCloneTokenValidator(common.CloneTokenIssuer, tokenPublicKeys...),
 			// for long term tokens to handle cross namespace dumb clones
 			tokenGenerator: newLongTermCloneTokenGenerator(tokenPrivateKey),
 		},
@@ -176,9 +177,39 @@ func addDataVolumeCloneControllerWatches(mgr manager.Manager, datavolumeControll
 		return err
 	}
 
+	if err := addSmartCloneSnapshotWatch(mgr, datavolumeController); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// addSmartCloneSnapshotWatch reconciles the DataVolumes whose smart-clone snapshot changes, so that their phase
+// follows the snapshot
+func addSmartCloneSnapshotWatch(mgr manager.Manager, c controller.Controller) error {
+	// check if volume snapshots exist
+	err := mgr.GetClient().List(context.TODO(), &snapshotv1.VolumeSnapshotList{})
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil && !cc.IsErrCacheNotStarted(err) {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &snapshotv1.VolumeSnapshot{}}, handler.EnqueueRequestsFromMapFunc(
+		func(obj client.Object) []reconcile.Request {
+			if !hasAnnOwnedByDataVolume(obj) || !shouldReconcileSnapshot(obj.(*snapshotv1.VolumeSnapshot)) {
+				return nil
+			}
+			namespace, name, err := getAnnOwnedByDataVolume(obj)
+			if err != nil {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+		},
+	))
+}
+
 func addDataSourceWatch(mgr manager.Manager, c controller.Controller) error {
 	const dvDataSourceField = "datasource"
 
@@ -387,7 +418,7 @@ func (r PvcCloneReconciler) syncClone(log logr.Logger, req reconcile.Request) (d
 
 	switch selectedCloneStrategy {
 	case HostAssistedClone:
-		if err := r.ensureExtendedToken(pvc); err != nil {
+		if err := r.ensureExtendedToken(datavolume, pvc); err != nil {
 			return syncRes, err
 		}
 	case CsiClone:
@@ -413,6 +444,10 @@ func (r PvcCloneReconciler) syncClone(log logr.Logger, req reconcile.Request) (d
 		fallthrough
 	case SmartClone:
 		if !shouldBeMarkedWaitForFirstConsumer {
+			if selectedCloneStrategy == SmartClone && pvc.Status.Phase != corev1.ClaimLost && pvc.Annotations[cc.AnnCloneOf] != "true" {
+				// the smart-clone controller marks the PVC restored from the snapshot once it is bound
+				return syncRes, r.syncCloneStatusPhase(&syncRes, cdiv1.SmartClonePVCInProgress, pvc)
+			}
 			res, err := r.finishClone(log, &syncRes, transferName)
 			syncRes.result = &res
 			return syncRes, err
@@ -613,7 +648,8 @@ func (r *PvcCloneReconciler) reconcileSmartClonePvc(log logr.Logger,
 	}
 
 	nn := client.ObjectKeyFromObject(newSnapshot)
-	if err := r.client.Get(context.TODO(), nn, newSnapshot.DeepCopy()); err != nil {
+	snapshot := &snapshotv1.VolumeSnapshot{}
+	if err := r.client.Get(context.TODO(), nn, snapshot); err != nil {
 		if !k8serrors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
@@ -639,7 +675,27 @@ func (r *PvcCloneReconciler) reconcileSmartClonePvc(log logr.Logger,
 			} else {
 				r.log.V(1).Info("snapshot created successfully", "snapshot.Namespace", newSnapshot.Namespace, "snapshot.Name", newSnapshot.Name)
 			}
+		} else {
+			// the snapshot was deleted once the PVC restored from it was bound
+			return reconcile.Result{}, r.syncCloneStatusPhase(syncRes, cdiv1.SmartClonePVCInProgress, nil)
+		}
+	} else if snapshot.Status != nil && snapshot.Status.Error != nil {
+		// The snapshot controller keeps retrying, the clone resumes if the error clears. The snapshot is deleted
+		// along with the DataVolume otherwise.
+		errMessage := "no details"
+		if msg := snapshot.Status.Error.Message; msg != nil {
+			errMessage = *msg
 		}
+		sourceName, sourceNamespace := cc.GetCloneSourceNameAndNamespace(datavolume)
+		return reconcile.Result{},
+			r.syncDataVolumeStatusPhaseWithEvent(syncRes, cdiv1.Failed, nil,
+				Event{
+					eventType: corev1.EventTypeWarning,
+					reason:    SmartCloneSnapshotFailed,
+					message:   fmt.Sprintf(MessageSmartCloneSnapshotFailed, sourceNamespace, sourceName, errMessage),
+				})
+	} else if snapshot.Status != nil && snapshot.Status.ReadyToUse != nil && *snapshot.Status.ReadyToUse {
+		return reconcile.Result{}, r.syncCloneStatusPhase(syncRes, cdiv1.SmartClonePVCInProgress, nil)
 	}
 
 	return reconcile.Result{}, r.syncCloneStatusPhase(syncRes, cdiv1.SnapshotForSmartCloneInProgress, nil)
@@ -713,6 +769,9 @@ func (r PvcCloneReconciler) cleanup(syncRes *dataVolumeSyncResult) error {
 	r.log.V(3).Info("Cleanup initiated in dv PVC clone controller")
 
 	if isCrossNamespaceClone(dv) {
+		if err := r.cleanupSmartCloneSnapshot(dv); err != nil {
+			return err
+		}
 		if err := r.cleanupTransfer(dv); err != nil {
 			return err
 		}
@@ -721,6 +780,35 @@ func (r PvcCloneReconciler) cleanup(syncRes *dataVolumeSyncResult) error {
 	return nil
 }
 
+// cleanupSmartCloneSnapshot deletes the snapshot of a cross-namespace smart clone, which the DataVolume
+// does not own as it is in the namespace of the source.
+func (r *PvcCloneReconciler) cleanupSmartCloneSnapshot(dv *cdiv1.DataVolume) error {
+	sourceName, sourceNamespace := cc.GetCloneSourceNameAndNamespace(dv)
+	if sourceName == "" {
+		return nil
+	}
+	snapshot := &snapshotv1.VolumeSnapshot{}
+	nn := types.NamespacedName{Namespace: sourceNamespace, Name: getTransferName(dv)}
+	if err := r.client.Get(context.TODO(), nn, snapshot); err != nil {
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	if snapshot.DeletionTimestamp != nil || snapshot.Labels[common.CDIComponentLabel] != common.SmartClonerCDILabel {
+		return nil
+	}
+	if namespace, name, err := getAnnOwnedByDataVolume(snapshot); err != nil || namespace != dv.Namespace || name != dv.Name {
+		return nil
+	}
+
+	r.log.V(1).Info("Deleting smart-clone snapshot", "snapshot.Namespace", snapshot.Namespace, "snapshot.Name", snapshot.Name)
+	if err := r.client.Delete(context.TODO(), snapshot); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 func (r *PvcCloneReconciler) getSnapshotClassForSmartClone(dataVolume *cdiv1.DataVolume, targetStorageSpec *corev1.PersistentVolumeClaimSpec) (string, error) {
 	log := r.log.WithName("getSnapshotClassForSmartClone").V(3)
 	// Check if relevant CRDs are available
@@ -752,17 +840,27 @@ func (r *PvcCloneReconciler) getSnapshotClassForSmartClone(dataVolume *cdiv1.Dat
 		log.Info("Cannot list snapshot classes, falling back to host assisted clone")
 		return "", err
 	}
+	snapshotClassName := ""
 	for _, snapshotClass := range scs.Items {
-		// Validate association between snapshot class and storage class
+		// Validate association between snapshot class and storage class, the default class of the driver wins
 		if snapshotClass.Driver == srcStorageClass.Provisioner {
-			log.Info("smart-clone is applicable for datavolume", "datavolume",
-				dataVolume.Name, "snapshot class", snapshotClass.Name)
-			return snapshotClass.Name, nil
+			if snapshotClass.Annotations[cc.AnnDefaultSnapshotClass] == "true" {
+				snapshotClassName = snapshotClass.Name
+				break
+			}
+			if snapshotClassName == "" {
+				snapshotClassName = snapshotClass.Name
+			}
 		}
 	}
+	if snapshotClassName == "" {
+		log.Info("Could not match snapshotter with storage class, falling back to host assisted clone")
+		return "", nil
+	}
 
-	log.Info("Could not match snapshotter with storage class, falling back to host assisted clone")
-	return "", nil
+	log.Info("smart-clone is applicable for datavolume", "datavolume",
+		dataVolume.Name, "snapshot class", snapshotClassName)
+	return snapshotClassName, nil
 }
 
 // isCsiCrdsDeployed checks whether the CSI snapshotter CRD are deployed
@@ -1269,7 +1367,7 @@ func (r *PvcCloneReconciler) makeSizeDetectionPodSpec(
 			NodeSelector:      workloadNodePlacement.NodeSelector,
 			Tolerations:       workloadNodePlacement.Tolerations,
 			Affinity:          workloadNodePlacement.Affinity,
-			PriorityClassName: cc.GetPriorityClass(sourcePvc),
+			PriorityClassName: cc.GetPriorityClass(r.client, sourcePvc),
 		},
 	}
 
@@ -36,6 +36,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -148,6 +149,69 @@ var _ = Describe("All DataVolume Tests", func() {
 			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: dv.Namespace, Name: dv.Name}, snap)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("volumesnapshots.snapshot.storage.k8s.io \"test-dv\" not found"))
+			By("Verifying that phase is smart-clone PVC in progress until the PVC is marked as a clone")
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Phase).To(Equal(cdiv1.SmartClonePVCInProgress))
+		})
+
+		DescribeTable("Should report the smart-clone snapshot", func(status *snapshotv1.VolumeSnapshotStatus, expectedPhase cdiv1.DataVolumePhase, expectedEvent string) {
+			dv := newCloneDataVolume("test-dv")
+			scName := "testsc"
+			sc := CreateStorageClassWithProvisioner(scName, map[string]string{
+				AnnDefaultStorageClass: "true",
+			}, map[string]string{}, "csi-plugin")
+			sp := createStorageProfile(scName, []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}, BlockMode)
+
+			dv.Spec.PVC.StorageClassName = &scName
+			pvc := CreatePvcInStorageClass("test", metav1.NamespaceDefault, &scName, nil, nil, corev1.ClaimBound)
+			snapClass := createSnapshotClass("snap-class", nil, "csi-plugin")
+			snapshot := newSnapshot(dv, dv.Name, snapClass.Name)
+			snapshot.Status = status
+			reconciler = createCloneReconciler(sc, sp, dv, pvc, snapClass, snapshot, createVolumeSnapshotContentCrd(), createVolumeSnapshotClassCrd(), createVolumeSnapshotCrd())
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			dv = &cdiv1.DataVolume{}
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}, dv)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dv.Status.Phase).To(Equal(expectedPhase))
+
+			events := reconciler.recorder.(*record.FakeRecorder).Events
+			found := false
+			for len(events) > 0 {
+				if strings.Contains(<-events, expectedEvent) {
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		},
+			Entry("in progress until it is ready", &snapshotv1.VolumeSnapshotStatus{ReadyToUse: &[]bool{false}[0]},
+				cdiv1.SnapshotForSmartCloneInProgress, "Creating snapshot for smart-clone is in progress (for pvc default/test)"),
+			Entry("restoring the PVC once it is ready", &snapshotv1.VolumeSnapshotStatus{ReadyToUse: &[]bool{true}[0]},
+				cdiv1.SmartClonePVCInProgress, "Creating PVC for smart-clone is in progress (for pvc default/test)"),
+			Entry("failed when it is in error", &snapshotv1.VolumeSnapshotStatus{Error: &snapshotv1.VolumeSnapshotError{Message: &[]string{"driver failure"}[0]}},
+				cdiv1.Failed, "Creating snapshot for smart-clone failed (for pvc default/test): driver failure"),
+		)
+
+		It("Should delete the snapshot of a cross-namespace smart clone along with the DataVolume", func() {
+			dv := newCloneDataVolumeWithPVCNS("test-dv", "ns2")
+			// another finalizer keeps the DataVolume once the clone is cleaned up
+			dv.Finalizers = append(dv.Finalizers, crossNamespaceFinalizer, "test")
+			now := metav1.Now()
+			dv.DeletionTimestamp = &now
+			snapshot := newSnapshot(dv, getTransferName(dv), "snap-class")
+			err := setAnnOwnedByDataVolume(snapshot, dv)
+			Expect(err).ToNot(HaveOccurred())
+			otherSnapshot := newSnapshot(newCloneDataVolumeWithPVCNS("other-dv", "ns2"), "other", "snap-class")
+			err = setAnnOwnedByDataVolume(otherSnapshot, dv)
+			Expect(err).ToNot(HaveOccurred())
+			reconciler = createCloneReconciler(dv, snapshot, otherSnapshot)
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-dv", Namespace: metav1.NamespaceDefault}})
+			Expect(err).ToNot(HaveOccurred())
+			err = reconciler.client.Get(context.TODO(), client.ObjectKeyFromObject(snapshot), &snapshotv1.VolumeSnapshot{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			err = reconciler.client.Get(context.TODO(), client.ObjectKeyFromObject(otherSnapshot), &snapshotv1.VolumeSnapshot{})
+			Expect(err).ToNot(HaveOccurred())
 		})
 
 		It("Should do nothing when smart clone with namespace transfer and not target found", func() {
@@ -181,6 +245,32 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(dv.Status.Phase).To(Equal(cdiv1.NamespaceTransferInProgress))
 		})
 
+		It("Should report an invalid clone token, and not transfer the PVC of another namespace", func() {
+			dv := newCloneDataVolumeWithPVCNS("test-dv", "ns2")
+			dv.Annotations[AnnCloneToken] = "expired"
+			reconciler = createCloneReconciler(dv)
+			name := fmt.Sprintf("cdi-tmp-%s", dv.UID)
+			initialized, err := reconciler.initTransfer(reconciler.log, dv, reconciler.tokenValidator, name, "ns2")
+			Expect(err).To(HaveOccurred())
+			Expect(initialized).To(BeFalse())
+			err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: name}, &cdiv1.ObjectTransfer{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			event := <-reconciler.recorder.(*record.FakeRecorder).Events
+			Expect(event).To(ContainSubstring(InvalidCloneToken))
+			Expect(event).To(ContainSubstring("The clone token of DataVolume default/test-dv is invalid"))
+		})
+
+		It("Should report an invalid clone token, and not extend it", func() {
+			dv := newCloneDataVolumeWithPVCNS("test-dv", "ns2")
+			pvc := CreatePvc("test-dv", metav1.NamespaceDefault, map[string]string{AnnCloneToken: "expired"}, nil)
+			reconciler = createCloneReconciler(dv, pvc)
+			err := reconciler.ensureExtendedToken(dv, pvc)
+			Expect(err).To(HaveOccurred())
+			Expect(pvc.Annotations).ToNot(HaveKey(AnnExtendedCloneToken))
+			event := <-reconciler.recorder.(*record.FakeRecorder).Events
+			Expect(event).To(ContainSubstring(InvalidCloneToken))
+		})
+
 		DescribeTable("Should NOT create a snapshot if source PVC mounted", func(podFunc func(*cdiv1.DataVolume) *corev1.Pod) {
 			dv := newCloneDataVolume("test-dv")
 			scName := "testsc"
@@ -407,6 +497,23 @@ var _ = Describe("All DataVolume Tests", func() {
 			Expect(snapclass).To(Equal(expectedSnapshotClass))
 		})
 
+		It("Should return the default snapshot class of the driver", func() {
+			dv := newCloneDataVolume("test-dv")
+			scName := "testsc"
+			sc := CreateStorageClassWithProvisioner(scName, map[string]string{
+				AnnDefaultStorageClass: "true",
+			}, map[string]string{}, "csi-plugin")
+			dv.Spec.PVC.StorageClassName = &scName
+			pvc := CreatePvcInStorageClass("test", metav1.NamespaceDefault, &scName, nil, nil, corev1.ClaimBound)
+			otherDriverClass := createSnapshotClass("other-default", map[string]string{AnnDefaultSnapshotClass: "true"}, "other-plugin")
+			snapClass := createSnapshotClass("snap-class", nil, "csi-plugin")
+			defaultSnapClass := createSnapshotClass("z-default", map[string]string{AnnDefaultSnapshotClass: "true"}, "csi-plugin")
+			reconciler = createCloneReconciler(sc, dv, pvc, otherDriverClass, snapClass, defaultSnapClass, createVolumeSnapshotContentCrd(), createVolumeSnapshotClassCrd(), createVolumeSnapshotCrd())
+			snapclass, err := reconciler.getSnapshotClassForSmartClone(dv, dv.Spec.PVC)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapclass).To(Equal("z-default"))
+		})
+
 		DescribeTable("Setting clone strategy affects the output of getGlobalCloneStrategyOverride", func(expectedCloneStrategy cdiv1.CDICloneStrategy) {
 			dv := newCloneDataVolume("test-dv")
 			reconciler = createCloneReconciler(dv)
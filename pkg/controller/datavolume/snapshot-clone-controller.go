@@ -60,7 +60,7 @@ func NewSnapshotCloneController(
 	clonerImage string,
 	importerImage string,
 	pullPolicy string,
-	tokenPublicKey *rsa.PublicKey,
+	tokenPublicKeys []*rsa.PublicKey,
 	tokenPrivateKey *rsa.PrivateKey,
 	installerLabels map[string]string,
 ) (controller.Controller, error) {
@@ -78,7 +78,7 @@ func NewSnapshotCloneController(
 			clonerImage:    clonerImage,
 			importerImage:  importerImage,
 			pullPolicy:     pullPolicy,
-			tokenValidator: cc.NewCloneTokenValidator(common.CloneTokenIssuer, tokenPublicKey),
+			tokenValidator: cc.NewCloneTokenValidator(common.CloneTokenIssuer, tokenPublicKeys...),
 			// for long term tokens to handle cross namespace dumb clones
 			tokenGenerator: newLongTermCloneTokenGenerator(tokenPrivateKey),
 		},
@@ -243,7 +243,7 @@ func (r SnapshotCloneReconciler) syncSnapshotClone(log logr.Logger, req reconcil
 	}
 
 	if fallBackToHostAssisted {
-		if err := r.ensureExtendedToken(pvc); err != nil {
+		if err := r.ensureExtendedToken(datavolume, pvc); err != nil {
 			return syncRes, err
 		}
 		return syncRes, syncErr
@@ -115,7 +115,7 @@ func pvcFromStorage(client client.Client, recorder record.EventRecorder, log log
 		}
 	}
 
-	requestedVolumeSize, err := resolveVolumeSize(client, dv.Spec, pvcSpec)
+	requestedVolumeSize, err := resolveVolumeSize(client, dv, pvcSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -239,7 +239,8 @@ func getDefaultAccessModes(c client.Client, storageClass *storagev1.StorageClass
 	return nil, errors.Errorf("no accessMode defined on StorageProfile for %s StorageClass", storageClass.Name)
 }
 
-func resolveVolumeSize(c client.Client, dvSpec cdiv1.DataVolumeSpec, pvcSpec *v1.PersistentVolumeClaimSpec) (*resource.Quantity, error) {
+func resolveVolumeSize(c client.Client, dv *cdiv1.DataVolume, pvcSpec *v1.PersistentVolumeClaimSpec) (*resource.Quantity, error) {
+	dvSpec := dv.Spec
 	// resources.requests[storage] - just copy it to pvc,
 	requestedSize, found := dvSpec.Storage.Resources.Requests[v1.ResourceStorage]
 
@@ -249,6 +250,11 @@ func resolveVolumeSize(c client.Client, dvSpec cdiv1.DataVolumeSpec, pvcSpec *v1
 		if isClone {
 			return &requestedSize, nil
 		}
+		// or when importing, once the size of the source is found
+		if size, found := getRequiredImportSize(dv); found {
+			requestedSize, err := inflateSizeWithOverhead(c, size, pvcSpec)
+			return &requestedSize, err
+		}
 		return nil, errors.Errorf("Datavolume Spec is not valid - missing storage size")
 	}
 
@@ -42,7 +42,7 @@ var _ = Describe("resolveVolumeSize", func() {
 		}
 		storageSpec := &cdiv1.StorageSpec{}
 		dv := createDataVolumeWithStorageAPI("testDV", "testNamespace", pvcSource, storageSpec)
-		requestedVolumeSize, err := resolveVolumeSize(client, dv.Spec, pvcSpec)
+		requestedVolumeSize, err := resolveVolumeSize(client, dv, pvcSpec)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(requestedVolumeSize.IsZero()).To(Equal(true))
 	})
@@ -53,7 +53,7 @@ var _ = Describe("resolveVolumeSize", func() {
 		}
 		storageSpec := &cdiv1.StorageSpec{}
 		dv := createDataVolumeWithStorageAPI("testDV", "testNamespace", httpSource, storageSpec)
-		requestedVolumeSize, err := resolveVolumeSize(client, dv.Spec, pvcSpec)
+		requestedVolumeSize, err := resolveVolumeSize(client, dv, pvcSpec)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("Datavolume Spec is not valid - missing storage size")))
 		Expect(requestedVolumeSize).To(BeNil())
@@ -70,7 +70,7 @@ var _ = Describe("resolveVolumeSize", func() {
 		volumeMode := corev1.PersistentVolumeBlock
 		pvcSpec.VolumeMode = &volumeMode
 		dv := createDataVolumeWithStorageAPI("testDV", "testNamespace", nil, storageSpec)
-		requestedVolumeSize, err := resolveVolumeSize(client, dv.Spec, pvcSpec)
+		requestedVolumeSize, err := resolveVolumeSize(client, dv, pvcSpec)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(storageSpec.Resources.Requests.Storage().Value()).To(Equal(requestedVolumeSize.Value()))
 	})
@@ -86,7 +86,7 @@ var _ = Describe("resolveVolumeSize", func() {
 		volumeMode := corev1.PersistentVolumeFilesystem
 		pvcSpec.VolumeMode = &volumeMode
 		dv := createDataVolumeWithStorageAPI("testDV", "testNamespace", nil, storageSpec)
-		requestedVolumeSize, err := resolveVolumeSize(client, dv.Spec, pvcSpec)
+		requestedVolumeSize, err := resolveVolumeSize(client, dv, pvcSpec)
 		Expect(err).ToNot(HaveOccurred())
 		// Inflate expected size with overhead
 		fsOverhead, err2 := GetFilesystemOverheadForStorageClass(client, dv.Spec.Storage.StorageClassName)
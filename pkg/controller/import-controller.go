@@ -15,6 +15,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -50,6 +51,9 @@ const (
 
 	// ImportTargetInUse is reason for event created when an import pvc is in use
 	ImportTargetInUse = "ImportTargetInUse"
+	// ProvisioningFailedOnNode is reason for event created when the volume of a WaitForFirstConsumer pvc could not be
+	// provisioned on the node selected for the importer pod, which is rescheduled on another node
+	ProvisioningFailedOnNode = "ProvisioningFailedOnNode"
 
 	// importPodImageStreamFinalizer ensures image stream import pod is deleted when pvc is deleted,
 	// as in this case pod has no pvc OwnerReference
@@ -57,8 +61,48 @@ const (
 
 	// secretExtraHeadersVolumeName is the format string that specifies where extra HTTP header secrets will be mounted
 	secretExtraHeadersVolumeName = "cdi-secret-extra-headers-vol-%d"
+	// encryptionSecretVolumeName is the name of the volume of the secret holding the passphrase of an encrypted image
+	encryptionSecretVolumeName = "cdi-encryption-secret-vol"
+	// sftpSecretVolumeName is the name of the volume of the secret holding the credentials and the known hosts of an SFTP source
+	sftpSecretVolumeName = "cdi-sftp-secret-vol"
+	// smbSecretVolumeName is the name of the volume of the secret holding the credentials of an SMB source
+	smbSecretVolumeName = "cdi-smb-secret-vol"
+	// clientCertVolumeName is the name of the volume of the client certificate and key of the secret of an http source
+	clientCertVolumeName = "cdi-client-cert-vol"
+	// registryAuthVolumeName is the name of the volume of the docker config of the secret of a registry source
+	registryAuthVolumeName = "cdi-registry-auth-vol"
+	// fileSourceVolumeName is the name of the volume of the PVC or of the directory of the node holding the file of a file source
+	fileSourceVolumeName = "cdi-file-source-vol"
+	// signatureKeyVolumeName is the name of the volume of the configmap holding the public keys trusted to sign the data of an http source
+	signatureKeyVolumeName = "cdi-signature-key-vol"
+
+	// defaultQemuImgNiceness is the niceness of qemu-img unless set by the CDIConfig
+	defaultQemuImgNiceness = 10
+
+	// defaultNFSMountTimeout is the time an importer pod has to mount the NFS export of an NFS source unless set by the
+	// CDIConfig
+	defaultNFSMountTimeout = 5 * time.Minute
+	// nfsSourceCapacity is the capacity of the volume of the NFS export of an NFS source, which is never written
+	nfsSourceCapacity = "1Mi"
 )
 
+// defaultNFSMountOptions are the options of the mounts of the NFS exports unless set by the CDIConfig, a soft mount
+// of an unreachable server fails instead of hanging the importer
+var defaultNFSMountOptions = []string{"soft", "timeo=100", "retrans=3"}
+
+// terminalExitCodeReasons maps the exit codes of the importer pods which are not going to succeed when restarted to the
+// reason of the running condition, set along with its message when not empty
+var terminalExitCodeReasons = map[int32]string{
+	common.UnsupportedFormatExitCode:        "",
+	common.InvalidEncryptionKeyExitCode:     "",
+	common.CorruptImageExitCode:             "",
+	common.InvalidClientCertificateExitCode: cc.InvalidClientCertificate,
+	common.ChecksumMismatchExitCode:         cc.ChecksumMismatch,
+	common.InvalidSignatureExitCode:         cc.InvalidSignature,
+	common.NoMatchingEntryExitCode:          cc.NoMatchingEntry,
+	common.SourceNotFoundExitCode:           cc.SourceNotFound,
+}
+
 // ImportReconciler members
 type ImportReconciler struct {
 	client             client.Client
@@ -84,6 +128,7 @@ type importPodEnvVar struct {
 	certConfigMap      string
 	diskID             string
 	uuid               string
+	moref              string
 	readyFile          string
 	doneFile           string
 	backingFile        string
@@ -93,13 +138,52 @@ type importPodEnvVar struct {
 	currentCheckpoint  string
 	previousCheckpoint string
 	finalCheckpoint    string
+	archiveEntry       string
+	flattenChain       bool
+	encryptionSecret   string
+	diskFormat         string
+	compressQcow2      bool
+	qcow2ClusterSize   string
+	qcow2Compat        string
+	preserveImageSize  bool
+	skipImageCheck     bool
 	preallocation      bool
+	preallocationMode  string
+	qemuCacheMode      string
+	qemuCoroutines     string
+	qemuMemoryLimit    string
+	qemuNiceness       string
+	bandwidthLimit     string
+	s3AddressingStyle  string
+	s3PartSize         string
+	s3Concurrency      string
+	glanceImage        string
+	glanceProject      string
+	glanceRegion       string
+	filePVC            string
+	fileHostPath       string
+	artifactMediaType  string
+	registryPlatform   string
+	defaultPlatform    string
 	httpProxy          string
 	httpsProxy         string
 	noProxy            string
 	certConfigMapProxy string
 	extraHeaders       []string
 	secretExtraHeaders []string
+	mirrors            string
+	urlPattern         string
+	httpConcurrency    string
+	maxRedirects       string
+	redirectHosts      string
+	checksum           string
+	signatureKeys      string
+	signatureURL       string
+	signature          string
+	sourceETag         string
+	sourceLastModified string
+	blankFilesystem    string
+	blankFsLabel       string
 }
 
 type importerPodArgs struct {
@@ -114,6 +198,7 @@ type importerPodArgs struct {
 	workloadNodePlacement   *sdkapi.NodePlacement
 	vddkImageName           *string
 	priorityClassName       string
+	restartPolicy           corev1.RestartPolicy
 }
 
 // NewImportController creates a new instance of the import controller.
@@ -170,7 +255,7 @@ func (r *ImportReconciler) shouldReconcilePVC(pvc *corev1.PersistentVolumeClaim,
 		return false, nil
 	}
 
-	_, isImmediateBindingRequested := pvc.Annotations[AnnImmediateBinding]
+	_, isImmediateBindingRequested := pvc.Annotations[cc.AnnImmediateBinding]
 	waitForFirstConsumerEnabled, err := isWaitForFirstConsumerEnabled(isImmediateBindingRequested, r.featureGates)
 	if err != nil {
 		return false, err
@@ -198,7 +283,9 @@ func (r *ImportReconciler) Reconcile(_ context.Context, req reconcile.Request) (
 	pvc := &corev1.PersistentVolumeClaim{}
 	if err := r.client.Get(context.TODO(), req.NamespacedName, pvc); err != nil {
 		if k8serrors.IsNotFound(err) {
-			return reconcile.Result{}, nil
+			setImportInFlight(req.NamespacedName, false)
+			// The volume of the NFS export of an NFS source is cluster scoped, it is not deleted with the PVC
+			return reconcile.Result{}, r.deleteNFSSourceVolume(req.Namespace, req.Name)
 		}
 		return reconcile.Result{}, err
 	}
@@ -208,6 +295,7 @@ func (r *ImportReconciler) Reconcile(_ context.Context, req reconcile.Request) (
 		return reconcile.Result{}, err
 	}
 	if !shouldReconcile {
+		setImportInFlight(req.NamespacedName, false)
 		multiStageImport := metav1.HasAnnotation(pvc.ObjectMeta, cc.AnnCurrentCheckpoint)
 		multiStageAlreadyDone := metav1.HasAnnotation(pvc.ObjectMeta, cc.AnnMultiStageImportDone)
 
@@ -220,9 +308,9 @@ func (r *ImportReconciler) Reconcile(_ context.Context, req reconcile.Request) (
 	}
 
 	// In case this is a request to create a blank disk on a block device, we do not create a pod.
-	// we just mark the DV as successful
+	// we just mark the DV as successful, unless a filesystem is requested on the disk, the importer pod creates it.
 	volumeMode := cc.GetVolumeMode(pvc)
-	if volumeMode == corev1.PersistentVolumeBlock && pvc.GetAnnotations()[cc.AnnSource] == cc.SourceNone && pvc.GetAnnotations()[cc.AnnPreallocationRequested] != "true" {
+	if volumeMode == corev1.PersistentVolumeBlock && pvc.GetAnnotations()[cc.AnnSource] == cc.SourceNone && pvc.GetAnnotations()[cc.AnnPreallocationRequested] != "true" && pvc.GetAnnotations()[cc.AnnBlankFilesystem] == "" {
 		log.V(1).Info("attempting to create blank disk for block mode, this is a no-op, marking pvc with pod-phase succeeded")
 		if pvc.GetAnnotations() == nil {
 			pvc.SetAnnotations(make(map[string]string, 0))
@@ -263,6 +351,9 @@ func (r *ImportReconciler) reconcilePvc(pvc *corev1.PersistentVolumeClaim, log l
 		if cc.IsPVCComplete(pvc) {
 			// Don't create the POD if the PVC is completed already
 			log.V(1).Info("PVC is already complete")
+		} else if isImportTerminallyFailed(pvc) {
+			// Don't create the POD if retrying the import cannot succeed
+			log.V(1).Info("PVC import failed terminally")
 		} else if pvc.DeletionTimestamp == nil {
 			podsUsingPVC, err := cc.GetPodsUsingPVCs(r.client, pvc.Namespace, sets.NewString(pvc.Name), false)
 			if err != nil {
@@ -281,6 +372,16 @@ func (r *ImportReconciler) reconcilePvc(pvc *corev1.PersistentVolumeClaim, log l
 			}
 
 			if _, ok := pvc.Annotations[cc.AnnImportPod]; ok {
+				if wait := importRetryWait(pvc); wait > 0 {
+					log.V(1).Info("Waiting to retry the failed import", "retryAfter", pvc.Annotations[cc.AnnImportRetryAfter])
+					return reconcile.Result{RequeueAfter: wait}, nil
+				}
+				if message, err := r.hostPathImportRejection(pvc); err != nil {
+					return reconcile.Result{}, err
+				} else if message != "" {
+					log.V(1).Info("Import of a file of a node directory not allowed", "hostPath", pvc.Annotations[cc.AnnFileHostPath])
+					return reconcile.Result{}, r.rejectImport(pvc, cc.HostPathImportNotAllowed, message, log)
+				}
 				// Create importer pod, make sure the PVC owns it.
 				if err := r.createImporterPod(pvc); err != nil {
 					return reconcile.Result{}, err
@@ -299,6 +400,10 @@ func (r *ImportReconciler) reconcilePvc(pvc *corev1.PersistentVolumeClaim, log l
 				return reconcile.Result{}, err
 			}
 		} else {
+			rescheduled, err := r.reschedulePendingImporterPod(pvc, pod, log)
+			if err != nil || rescheduled {
+				return reconcile.Result{RequeueAfter: 2 * time.Second}, err
+			}
 			// Copy import proxy ConfigMap (if exists) from cdi namespace to the import namespace
 			if err := r.copyImportProxyConfigMap(pvc, pod); err != nil {
 				return reconcile.Result{}, err
@@ -310,7 +415,9 @@ func (r *ImportReconciler) reconcilePvc(pvc *corev1.PersistentVolumeClaim, log l
 		}
 	}
 
-	if !cc.IsPVCComplete(pvc) {
+	inFlight := !cc.IsPVCComplete(pvc) && !isImportTerminallyFailed(pvc)
+	setImportInFlight(types.NamespacedName{Namespace: pvc.Namespace, Name: pvc.Name}, inFlight && pvc.DeletionTimestamp == nil)
+	if inFlight {
 		// We are not done yet, force a re-reconcile in 2 seconds to get an update.
 		log.V(1).Info("Force Reconcile pvc import not finished", "pvc.Name", pvc.Name)
 
@@ -319,6 +426,42 @@ func (r *ImportReconciler) reconcilePvc(pvc *corev1.PersistentVolumeClaim, log l
 	return reconcile.Result{}, nil
 }
 
+// reschedulePendingImporterPod follows the provisioning of the volume of a WaitForFirstConsumer pvc whose first consumer
+// is the importer pod. The node the scheduler selected for the pvc is recorded; when the provisioner gives up on it and
+// removes the selection, the node is excluded and the pending importer pod is deleted to be scheduled on another node.
+func (r *ImportReconciler) reschedulePendingImporterPod(pvc *corev1.PersistentVolumeClaim, pod *corev1.Pod, log logr.Logger) (bool, error) {
+	if pvc.Status.Phase == corev1.ClaimBound || pod.Status.Phase != corev1.PodPending {
+		return false, nil
+	}
+	selectedNode := pvc.Annotations[cc.AnnSelectedNode]
+	provisioningNode := pvc.Annotations[cc.AnnProvisioningNode]
+	if selectedNode != "" {
+		if selectedNode == provisioningNode {
+			return false, nil
+		}
+		pvc.Annotations[cc.AnnProvisioningNode] = selectedNode
+		return false, r.updatePVC(pvc, log)
+	}
+	if provisioningNode == "" {
+		return false, nil
+	}
+
+	failedNodes := sets.NewString(cc.GetProvisioningFailedNodes(pvc)...)
+	failedNodes.Insert(provisioningNode)
+	pvc.Annotations[cc.AnnProvisioningFailedNodes] = strings.Join(failedNodes.List(), ",")
+	delete(pvc.Annotations, cc.AnnProvisioningNode)
+	if err := r.updatePVC(pvc, log); err != nil {
+		return false, err
+	}
+	log.V(1).Info("Volume could not be provisioned on the selected node, rescheduling the importer pod", "node", provisioningNode, "pod.Name", pod.Name)
+	r.recorder.Eventf(pvc, corev1.EventTypeWarning, ProvisioningFailedOnNode,
+		"Volume of PersistentVolumeClaim %s could not be provisioned on node %s, rescheduling importer pod %s", pvc.Name, provisioningNode, pod.Name)
+	if err := r.client.Delete(context.TODO(), pod); cc.IgnoreNotFound(err) != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (r *ImportReconciler) copyImportProxyConfigMap(pvc *corev1.PersistentVolumeClaim, pod *corev1.Pod) error {
 	cdiConfig := &cdiv1.CDIConfig{}
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig); err != nil {
@@ -376,6 +519,7 @@ func (r *ImportReconciler) initPvcPodName(pvc *corev1.PersistentVolumeClaim, log
 			return err
 		}
 		log.V(1).Info("Updated PVC", "pvc.anno.AnnImportPod", anno[cc.AnnImportPod])
+		ImportsStartedCounter.WithLabelValues(cc.GetSource(pvc)).Inc()
 	}
 	return nil
 }
@@ -383,22 +527,59 @@ func (r *ImportReconciler) initPvcPodName(pvc *corev1.PersistentVolumeClaim, log
 func (r *ImportReconciler) updatePvcFromPod(pvc *corev1.PersistentVolumeClaim, pod *corev1.Pod, log logr.Logger) error {
 	// Keep a copy of the original for comparison later.
 	currentPvcCopy := pvc.DeepCopyObject()
+	wasComplete := cc.IsPVCComplete(pvc)
+	wasTerminallyFailed := isImportTerminallyFailed(pvc)
 
 	log.V(1).Info("Updating PVC from pod")
 	anno := pvc.GetAnnotations()
 	setAnnotationsFromPodWithPrefix(anno, pod, cc.AnnRunningCondition)
 
 	scratchExitCode := false
-	if pod.Status.ContainerStatuses != nil &&
-		pod.Status.ContainerStatuses[0].LastTerminationState.Terminated != nil &&
-		pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.ExitCode > 0 {
-		log.Info("Pod termination code", "pod.Name", pod.Name, "ExitCode", pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.ExitCode)
-		if pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.ExitCode == common.ScratchSpaceNeededExitCode {
+	terminalExitCode := false
+	terminated := failedTermination(pod)
+	if terminated != nil && terminated.ExitCode > 0 {
+		log.Info("Pod termination code", "pod.Name", pod.Name, "ExitCode", terminated.ExitCode)
+		if terminated.ExitCode == common.ScratchSpaceNeededExitCode {
 			log.V(1).Info("Pod requires scratch space, terminating pod, and restarting with scratch space", "pod.Name", pod.Name)
 			scratchExitCode = true
 			anno[cc.AnnRequiresScratch] = "true"
+		} else if reason, terminal := terminalExitCodeReasons[terminated.ExitCode]; terminal {
+			log.V(1).Info("Pod cannot find the source, import its format, decrypt it, authenticate to it, verify its checksum or signature, match it in its directory index, or the image is corrupt, terminating pod", "pod.Name", pod.Name)
+			terminalExitCode = true
+			anno[cc.AnnImportTerminalError] = terminated.Message
+			anno[cc.AnnImportLastFailure] = simplifyKnownMessage(terminated.Message)
+			if reason != "" {
+				anno[cc.AnnRunningConditionMessage] = simplifyKnownMessage(terminated.Message)
+				anno[cc.AnnRunningConditionReason] = reason
+			}
+			r.recorder.Event(pvc, corev1.EventTypeWarning, ErrImportFailedPVC, terminated.Message)
 		} else {
-			r.recorder.Event(pvc, corev1.EventTypeWarning, ErrImportFailedPVC, pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.Message)
+			anno[cc.AnnImportLastFailure] = simplifyKnownMessage(terminated.Message)
+			r.recorder.Event(pvc, corev1.EventTypeWarning, ErrImportFailedPVC, terminated.Message)
+		}
+	}
+	if !terminalExitCode && cc.GetSource(pvc) == cc.SourceNFS {
+		_, timeout, err := r.getNFSMountConfig()
+		if err != nil {
+			return err
+		}
+		if nfsMountTimedOut(pod, timeout) {
+			// A hard mount of an unreachable server never fails, the pod is not going to start.
+			message := fmt.Sprintf("Unable to mount the NFS export of %s within %s", anno[cc.AnnEndpoint], timeout)
+			log.V(1).Info("Pod could not mount the NFS export, terminating pod", "pod.Name", pod.Name)
+			terminalExitCode = true
+			anno[cc.AnnImportTerminalError] = message
+			anno[cc.AnnImportLastFailure] = message
+			anno[cc.AnnRunningConditionMessage] = message
+			anno[cc.AnnRunningConditionReason] = cc.NFSMountTimeout
+			r.recorder.Event(pvc, corev1.EventTypeWarning, ErrImportFailedPVC, message)
+		}
+	}
+	// A pod which is not restarted by the kubelet is replaced by the controller, as its retry policy allows
+	retryFailedPod := pod.Spec.RestartPolicy == corev1.RestartPolicyNever && pod.Status.Phase == corev1.PodFailed && !scratchExitCode && !terminalExitCode
+	if retryFailedPod {
+		if err := r.setImportRetry(pvc, pod, terminated, log); err != nil {
+			return err
 		}
 	}
 
@@ -407,11 +588,18 @@ func (r *ImportReconciler) updatePvcFromPod(pvc *corev1.PersistentVolumeClaim, p
 	}
 
 	anno[cc.AnnImportPod] = string(pod.Name)
-	if !scratchExitCode {
+	if terminalExitCode {
+		// The restarted pod is not going to succeed, the import failed.
+		anno[cc.AnnPodPhase] = string(corev1.PodFailed)
+	} else if !scratchExitCode {
 		// No scratch exit code, update the phase based on the pod. If we do have scratch exit code we don't want to update the
 		// phase, because the pod might terminate cleanly and mistakenly mark the import complete.
 		anno[cc.AnnPodPhase] = string(pod.Status.Phase)
 	}
+	if anno[cc.AnnPodPhase] == string(corev1.PodSucceeded) && anno[cc.AnnDiskFormat] == "" && cc.GetContentType(pvc) == string(cdiv1.DataVolumeKubeVirt) {
+		// The importer only reports the format of a disk image that is not raw
+		anno[cc.AnnDiskFormat] = string(cdiv1.DataVolumeDiskFormatRaw)
+	}
 
 	// Check if the POD is waiting for scratch space, if so create some.
 	if pod.Status.Phase == corev1.PodPending && r.requiresScratchSpace(pvc) {
@@ -439,27 +627,167 @@ func (r *ImportReconciler) updatePvcFromPod(pvc *corev1.PersistentVolumeClaim, p
 			return err
 		}
 		log.V(1).Info("Updated PVC", "pvc.anno.Phase", anno[cc.AnnPodPhase], "pvc.anno.Restarts", anno[cc.AnnPodRestarts])
+		if !wasComplete && cc.IsPVCComplete(pvc) {
+			observeImportSucceeded(pvc)
+		}
+		if !wasTerminallyFailed && isImportTerminallyFailed(pvc) {
+			ImportsFailedCounter.WithLabelValues(cc.GetSource(pvc)).Inc()
+		}
 	}
 
 	if cc.IsPVCComplete(pvc) || scratchExitCode {
 		if !scratchExitCode {
-			r.recorder.Event(pvc, corev1.EventTypeNormal, ImportSucceededPVC, "Import Successful")
+			message := "Import Successful"
+			if digest := anno[cc.AnnDestinationDigest]; digest != "" {
+				message += ", " + common.DestinationDigest + " " + digest
+			}
+			r.recorder.Event(pvc, corev1.EventTypeNormal, ImportSucceededPVC, message)
 			log.V(1).Info("Import completed successfully")
 		}
-		if cc.ShouldDeletePod(pvc) {
+		if cc.ShouldDeletePod(pvc) || pod.Status.Phase == corev1.PodFailed {
 			log.V(1).Info("Deleting pod", "pod.Name", pod.Name)
 			if err := r.cleanup(pvc, pod, log); err != nil {
 				return err
 			}
 		}
 	}
+	if terminalExitCode || retryFailedPod {
+		// Delete the pod to stop it from restarting, or to replace it
+		log.V(1).Info("Deleting pod", "pod.Name", pod.Name)
+		if err := r.cleanup(pvc, pod, log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// failedTermination returns the state the importer container last terminated in, once restarted by the kubelet or in
+// the failed pod which is not restarted.
+func failedTermination(pod *corev1.Pod) *corev1.ContainerStateTerminated {
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return nil
+	}
+	if pod.Status.Phase == corev1.PodFailed && pod.Status.ContainerStatuses[0].State.Terminated != nil {
+		return pod.Status.ContainerStatuses[0].State.Terminated
+	}
+	return pod.Status.ContainerStatuses[0].LastTerminationState.Terminated
+}
+
+// setImportRetry records the failure of the importer pod which is not restarted by the kubelet, and the time the import
+// is retried after, unless the retry policy of the import does not allow another retry and the import failed.
+func (r *ImportReconciler) setImportRetry(pvc *corev1.PersistentVolumeClaim, pod *corev1.Pod, terminated *corev1.ContainerStateTerminated, log logr.Logger) error {
+	anno := pvc.GetAnnotations()
+	if retryAfter, err := time.Parse(time.RFC3339, anno[cc.AnnImportRetryAfter]); err == nil && pod.CreationTimestamp.Time.Before(retryAfter) {
+		// The failure of the pod was recorded already, it is being replaced
+		return nil
+	}
+	message := pod.Status.Message
+	if terminated != nil {
+		message = terminated.Message
+	}
+	anno[cc.AnnImportLastFailure] = simplifyKnownMessage(message)
+
+	policy, _, err := r.getImportRetryPolicy(pvc)
+	if err != nil {
+		return err
+	}
+	retries, _ := strconv.Atoi(anno[cc.AnnPodRestarts])
+	if policy.maxRetries >= 0 && retries >= policy.maxRetries {
+		log.V(1).Info("Import failed as many times as its retry policy allows", "retries", retries)
+		anno[cc.AnnImportTerminalError] = fmt.Sprintf("import failed after %d retries: %s", retries, anno[cc.AnnImportLastFailure])
+		anno[cc.AnnRunningConditionMessage] = anno[cc.AnnImportTerminalError]
+		anno[cc.AnnRunningConditionReason] = cc.RetryLimitExceeded
+		return nil
+	}
+	finished := time.Now()
+	if terminated != nil && !terminated.FinishedAt.IsZero() {
+		finished = terminated.FinishedAt.Time
+	}
+	// Rounded up to the second, the retry is created after the failed pod whatever the precision of the timestamps
+	retryAfter := finished.Add(policy.backoff(retries))
+	if truncated := retryAfter.Truncate(time.Second); truncated.Before(retryAfter) {
+		retryAfter = truncated.Add(time.Second)
+	}
+	anno[cc.AnnPodRestarts] = strconv.Itoa(retries + 1)
+	anno[cc.AnnImportRetryAfter] = retryAfter.UTC().Format(time.RFC3339)
+	log.V(1).Info("Retrying the failed import", "retries", retries+1, "retryAfter", anno[cc.AnnImportRetryAfter])
+	return nil
+}
+
+// importRetryWait returns how long the failed import of the PVC waits before it is retried.
+func importRetryWait(pvc *corev1.PersistentVolumeClaim) time.Duration {
+	retryAfter, err := time.Parse(time.RFC3339, pvc.GetAnnotations()[cc.AnnImportRetryAfter])
+	if err != nil {
+		return 0
+	}
+	return time.Until(retryAfter)
+}
+
+// isImportTerminallyFailed returns true if the import into the PVC failed in a way that retrying cannot fix.
+// hostPathImportRejection returns why the directory of the node named by the PVC may not be mounted in its importer
+// pod, or an empty string when it may. Anyone creating a PVC could name any directory, it is only mounted for the
+// DataVolume owning the PVC, admitted by the webhook, while the HostPathImport feature gate is enabled.
+func (r *ImportReconciler) hostPathImportRejection(pvc *corev1.PersistentVolumeClaim) (string, error) {
+	hostPath, ok := pvc.Annotations[cc.AnnFileHostPath]
+	if !ok || cc.GetSource(pvc) != cc.SourceFile {
+		return "", nil
+	}
+	enabled, err := r.featureGates.HostPathImportEnabled()
+	if err != nil {
+		return "", err
+	}
+	if !enabled {
+		return fmt.Sprintf("Importing a file of the node directory %s requires the %s feature gate", hostPath, featuregates.HostPathImport), nil
+	}
+	owner := metav1.GetControllerOf(pvc)
+	if owner == nil || owner.Kind != "DataVolume" {
+		return fmt.Sprintf("Importing a file of the node directory %s is only allowed for a DataVolume", hostPath), nil
+	}
+	dv := &cdiv1.DataVolume{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: pvc.Namespace, Name: owner.Name}, dv); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Sprintf("Importing a file of the node directory %s is only allowed for a DataVolume", hostPath), nil
+		}
+		return "", err
+	}
+	if dv.UID != owner.UID || dv.Spec.Source == nil || dv.Spec.Source.File == nil || dv.Spec.Source.File.HostPath != hostPath {
+		return fmt.Sprintf("The node directory %s is not the one of the file source of DataVolume %s", hostPath, dv.Name), nil
+	}
+	return "", nil
+}
+
+// rejectImport fails the import into the PVC terminally, without creating its importer pod
+func (r *ImportReconciler) rejectImport(pvc *corev1.PersistentVolumeClaim, reason, message string, log logr.Logger) error {
+	pvc = pvc.DeepCopy()
+	anno := pvc.GetAnnotations()
+	anno[cc.AnnPodPhase] = string(corev1.PodFailed)
+	anno[cc.AnnImportTerminalError] = message
+	anno[cc.AnnImportLastFailure] = message
+	anno[cc.AnnRunningCondition] = "false"
+	anno[cc.AnnRunningConditionMessage] = message
+	anno[cc.AnnRunningConditionReason] = reason
+	if err := r.updatePVC(pvc, log); err != nil {
+		return err
+	}
+	r.recorder.Event(pvc, corev1.EventTypeWarning, ErrImportFailedPVC, message)
 	return nil
 }
 
+func isImportTerminallyFailed(pvc *corev1.PersistentVolumeClaim) bool {
+	_, ok := pvc.GetAnnotations()[cc.AnnImportTerminalError]
+	return ok
+}
+
 func (r *ImportReconciler) cleanup(pvc *corev1.PersistentVolumeClaim, pod *corev1.Pod, log logr.Logger) error {
 	if err := r.client.Delete(context.TODO(), pod); cc.IgnoreNotFound(err) != nil {
 		return err
 	}
+	// The NFS export is kept mounted by the pods retrying the import
+	if cc.GetSource(pvc) == cc.SourceNFS && (cc.IsPVCComplete(pvc) || isImportTerminallyFailed(pvc) || pvc.DeletionTimestamp != nil) {
+		if err := r.deleteNFSSource(pvc); err != nil {
+			return err
+		}
+	}
 	if cc.HasFinalizer(pvc, importPodImageStreamFinalizer) {
 		cc.RemoveFinalizer(pvc, importPodImageStreamFinalizer)
 		if err := r.updatePVC(pvc, log); err != nil {
@@ -515,6 +843,18 @@ func (r *ImportReconciler) createImporterPod(pvc *corev1.PersistentVolumeClaim)
 	if err != nil {
 		return err
 	}
+	if podEnvVar.source == cc.SourceNFS {
+		if err := r.createNFSSource(pvc); err != nil {
+			return err
+		}
+	}
+	restartPolicy := corev1.RestartPolicyOnFailure
+	if _, set, err := r.getImportRetryPolicy(pvc); err != nil {
+		return err
+	} else if set {
+		// the controller replaces the failed pod as the retry policy allows
+		restartPolicy = corev1.RestartPolicyNever
+	}
 	// all checks passed, let's create the importer pod!
 	podArgs := &importerPodArgs{
 		image:             r.image,
@@ -524,7 +864,8 @@ func (r *ImportReconciler) createImporterPod(pvc *corev1.PersistentVolumeClaim)
 		pvc:               pvc,
 		scratchPvcName:    scratchPvcName,
 		vddkImageName:     vddkImageName,
-		priorityClassName: cc.GetPriorityClass(pvc),
+		priorityClassName: cc.GetPriorityClass(r.client, pvc),
+		restartPolicy:     restartPolicy,
 	}
 
 	pod, err := createImporterPod(r.log, r.client, podArgs, r.installerLabels)
@@ -571,6 +912,28 @@ func (r *ImportReconciler) createImportEnvVar(pvc *corev1.PersistentVolumeClaim)
 		if podEnvVar.secretName == "" {
 			r.log.V(2).Info("no secret will be supplied to endpoint", "endPoint", podEnvVar.ep)
 		}
+		if podEnvVar.source == cc.SourceS3 {
+			podEnvVar.s3AddressingStyle = getValueFromAnnotation(pvc, cc.AnnS3AddressingStyle)
+			podEnvVar.s3PartSize = getValueFromAnnotation(pvc, cc.AnnS3PartSize)
+			podEnvVar.s3Concurrency = getValueFromAnnotation(pvc, cc.AnnS3Concurrency)
+		}
+		if podEnvVar.source == cc.SourceGlance {
+			podEnvVar.glanceImage = getValueFromAnnotation(pvc, cc.AnnGlanceImage)
+			podEnvVar.glanceProject = getValueFromAnnotation(pvc, cc.AnnGlanceProject)
+			podEnvVar.glanceRegion = getValueFromAnnotation(pvc, cc.AnnGlanceRegion)
+		}
+		if podEnvVar.source == cc.SourceFile {
+			podEnvVar.filePVC = getValueFromAnnotation(pvc, cc.AnnFilePVC)
+			podEnvVar.fileHostPath = getValueFromAnnotation(pvc, cc.AnnFileHostPath)
+		}
+		if podEnvVar.source == cc.SourceNFS {
+			// the directory of the file is mounted as the PVC holding the file of a file source
+			podEnvVar.filePVC = nfsSourceNameFromPvc(pvc)
+		}
+		if podEnvVar.source == cc.SourceRegistry {
+			podEnvVar.artifactMediaType = getValueFromAnnotation(pvc, cc.AnnRegistryArtifactMediaType)
+			podEnvVar.registryPlatform = getValueFromAnnotation(pvc, cc.AnnRegistryPlatform)
+		}
 		//get the CDIConfig to extract the proxy configuration to be used to import an image
 		cdiConfig := &cdiv1.CDIConfig{}
 		err = r.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
@@ -588,10 +951,23 @@ func (r *ImportReconciler) createImportEnvVar(pvc *corev1.PersistentVolumeClaim)
 		podEnvVar.diskID = getValueFromAnnotation(pvc, cc.AnnDiskID)
 		podEnvVar.backingFile = getValueFromAnnotation(pvc, cc.AnnBackingFile)
 		podEnvVar.uuid = getValueFromAnnotation(pvc, cc.AnnUUID)
+		podEnvVar.moref = getValueFromAnnotation(pvc, cc.AnnMoref)
 		podEnvVar.thumbprint = getValueFromAnnotation(pvc, cc.AnnThumbprint)
 		podEnvVar.previousCheckpoint = getValueFromAnnotation(pvc, cc.AnnPreviousCheckpoint)
 		podEnvVar.currentCheckpoint = getValueFromAnnotation(pvc, cc.AnnCurrentCheckpoint)
 		podEnvVar.finalCheckpoint = getValueFromAnnotation(pvc, cc.AnnFinalCheckpoint)
+		// archive content is unpacked as a whole, only a disk image is extracted from an archive
+		if podEnvVar.contentType == string(cdiv1.DataVolumeKubeVirt) {
+			podEnvVar.archiveEntry = getValueFromAnnotation(pvc, cc.AnnArchiveEntry)
+			podEnvVar.flattenChain = getValueFromAnnotation(pvc, cc.AnnFlattenBackingChain) == "true"
+			podEnvVar.encryptionSecret = getValueFromAnnotation(pvc, cc.AnnEncryptionSecret)
+			podEnvVar.diskFormat = getValueFromAnnotation(pvc, cc.AnnDiskFormatRequested)
+			podEnvVar.compressQcow2 = getValueFromAnnotation(pvc, cc.AnnCompressQcow2) == "true"
+			podEnvVar.qcow2ClusterSize = getValueFromAnnotation(pvc, cc.AnnQcow2ClusterSize)
+			podEnvVar.qcow2Compat = getValueFromAnnotation(pvc, cc.AnnQcow2Compat)
+			podEnvVar.preserveImageSize = getValueFromAnnotation(pvc, cc.AnnPreserveImageSize) == "true"
+			podEnvVar.skipImageCheck = getValueFromAnnotation(pvc, cc.AnnSkipImageCheck) == "true"
+		}
 
 		for annotation, value := range pvc.Annotations {
 			if strings.HasPrefix(annotation, cc.AnnExtraHeaders) {
@@ -601,6 +977,22 @@ func (r *ImportReconciler) createImportEnvVar(pvc *corev1.PersistentVolumeClaim)
 				podEnvVar.secretExtraHeaders = append(podEnvVar.secretExtraHeaders, value)
 			}
 		}
+		if podEnvVar.source == cc.SourceHTTP {
+			podEnvVar.mirrors = getValueFromAnnotation(pvc, cc.AnnMirrors)
+			podEnvVar.urlPattern = getValueFromAnnotation(pvc, cc.AnnURLPattern)
+			podEnvVar.httpConcurrency = getValueFromAnnotation(pvc, cc.AnnHTTPConcurrency)
+			podEnvVar.maxRedirects = getValueFromAnnotation(pvc, cc.AnnMaxRedirects)
+			podEnvVar.redirectHosts = getValueFromAnnotation(pvc, cc.AnnRedirectHosts)
+			podEnvVar.signatureKeys = getValueFromAnnotation(pvc, cc.AnnSignatureKeyConfigMap)
+			podEnvVar.signatureURL = getValueFromAnnotation(pvc, cc.AnnSignatureURL)
+			podEnvVar.signature = getValueFromAnnotation(pvc, cc.AnnSignatureArmored)
+		}
+		podEnvVar.checksum = getValueFromAnnotation(pvc, cc.AnnChecksum)
+		if (podEnvVar.source == cc.SourceHTTP || podEnvVar.source == cc.SourceS3) && pvc.Annotations[cc.AnnReimport] == cc.ReimportIfChanged {
+			// the data of the PVC is kept if the source did not change since it was imported
+			podEnvVar.sourceETag = getValueFromAnnotation(pvc, cc.AnnSourceETag)
+			podEnvVar.sourceLastModified = getValueFromAnnotation(pvc, cc.AnnSourceLastModified)
+		}
 
 		var field string
 		if field, err = GetImportProxyConfig(cdiConfig, common.ImportProxyHTTP); err != nil {
@@ -619,6 +1011,14 @@ func (r *ImportReconciler) createImportEnvVar(pvc *corev1.PersistentVolumeClaim)
 			r.log.V(3).Info("no proxy CA certiticate will be supplied:", err.Error())
 		}
 		podEnvVar.certConfigMapProxy = field
+		setQemuImgEnvVars(podEnvVar, cdiConfig.Spec.QemuImg)
+		podEnvVar.bandwidthLimit = importBandwidthLimit(pvc, cdiConfig)
+		if podEnvVar.source == cc.SourceRegistry && cdiConfig.Spec.RegistryPlatform != nil {
+			podEnvVar.defaultPlatform = *cdiConfig.Spec.RegistryPlatform
+		}
+	} else if podEnvVar.contentType == string(cdiv1.DataVolumeKubeVirt) {
+		podEnvVar.blankFilesystem = getValueFromAnnotation(pvc, cc.AnnBlankFilesystem)
+		podEnvVar.blankFsLabel = getValueFromAnnotation(pvc, cc.AnnBlankFilesystemLabel)
 	}
 
 	fsOverhead, err := GetFilesystemOverhead(r.client, pvc)
@@ -630,6 +1030,7 @@ func (r *ImportReconciler) createImportEnvVar(pvc *corev1.PersistentVolumeClaim)
 	if preallocation, err := strconv.ParseBool(getValueFromAnnotation(pvc, cc.AnnPreallocationRequested)); err == nil {
 		podEnvVar.preallocation = preallocation
 	} // else use the default "false"
+	podEnvVar.preallocationMode = getValueFromAnnotation(pvc, cc.AnnPreallocationModeRequested)
 
 	//get the requested image size.
 	podEnvVar.imageSize, err = cc.GetRequestedImageSize(pvc)
@@ -639,6 +1040,101 @@ func (r *ImportReconciler) createImportEnvVar(pvc *corev1.PersistentVolumeClaim)
 	return podEnvVar, nil
 }
 
+// setQemuImgEnvVars sets the bounds of the resources of qemu-img from the config passed in, the defaults of the
+// importer when it is nil
+func setQemuImgEnvVars(podEnvVar *importPodEnvVar, config *cdiv1.QemuImgConfig) {
+	podEnvVar.qemuNiceness = strconv.Itoa(defaultQemuImgNiceness)
+	if config == nil {
+		return
+	}
+	podEnvVar.qemuCacheMode = config.CacheMode
+	if config.Coroutines != nil {
+		podEnvVar.qemuCoroutines = strconv.Itoa(int(*config.Coroutines))
+	}
+	if config.MemoryLimit != nil {
+		podEnvVar.qemuMemoryLimit = config.MemoryLimit.String()
+	}
+	if config.Niceness != nil {
+		podEnvVar.qemuNiceness = strconv.Itoa(int(*config.Niceness))
+	}
+}
+
+// importBandwidthLimit returns the limit of the rate at which the import of the PVC reads its source, its annotation
+// overriding the default of the CDIConfig, empty when it is not limited
+func importBandwidthLimit(pvc *corev1.PersistentVolumeClaim, cdiConfig *cdiv1.CDIConfig) string {
+	if limit := getValueFromAnnotation(pvc, cc.AnnImportBandwidthLimit); limit != "" {
+		return limit
+	}
+	if limit := cdiConfig.Spec.ImportBandwidthLimit; limit != nil && limit.Sign() > 0 {
+		return limit.String()
+	}
+	return ""
+}
+
+const (
+	// defaultImportInitialBackoff and defaultImportMaxBackoff match the backoff of the kubelet restarting a failed container
+	defaultImportInitialBackoff = 10 * time.Second
+	defaultImportMaxBackoff     = 5 * time.Minute
+)
+
+// importRetryPolicy bounds the retries of a failed import and the backoff between them
+type importRetryPolicy struct {
+	// maxRetries is negative when the retries are not limited
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// backoff returns the delay before the retry of the import which failed after the given number of retries
+func (p importRetryPolicy) backoff(retries int) time.Duration {
+	backoff := p.initialBackoff
+	for i := 0; i < retries && backoff < p.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.maxBackoff {
+		return p.maxBackoff
+	}
+	return backoff
+}
+
+// importRetryPolicyFor returns the retry policy of the import of the PVC, its annotations overriding the importRetryPolicy
+// of the CDIConfig, and whether one is set. Without one the kubelet restarts the failed importer pod.
+func importRetryPolicyFor(pvc *corev1.PersistentVolumeClaim, cdiConfig *cdiv1.CDIConfig) (importRetryPolicy, bool) {
+	policy := importRetryPolicy{maxRetries: -1, initialBackoff: defaultImportInitialBackoff, maxBackoff: defaultImportMaxBackoff}
+	set := false
+	if config := cdiConfig.Spec.ImportRetryPolicy; config != nil {
+		set = true
+		if config.MaxRetries != nil && *config.MaxRetries >= 0 {
+			policy.maxRetries = int(*config.MaxRetries)
+		}
+		if config.InitialBackoff != nil && config.InitialBackoff.Duration > 0 {
+			policy.initialBackoff = config.InitialBackoff.Duration
+		}
+		if config.MaxBackoff != nil && config.MaxBackoff.Duration > 0 {
+			policy.maxBackoff = config.MaxBackoff.Duration
+		}
+	}
+	if maxRetries, err := strconv.Atoi(getValueFromAnnotation(pvc, cc.AnnImportMaxRetries)); err == nil && maxRetries >= 0 {
+		policy.maxRetries, set = maxRetries, true
+	}
+	if backoff, err := time.ParseDuration(getValueFromAnnotation(pvc, cc.AnnImportInitialBackoff)); err == nil && backoff > 0 {
+		policy.initialBackoff, set = backoff, true
+	}
+	if backoff, err := time.ParseDuration(getValueFromAnnotation(pvc, cc.AnnImportMaxBackoff)); err == nil && backoff > 0 {
+		policy.maxBackoff, set = backoff, true
+	}
+	return policy, set
+}
+
+func (r *ImportReconciler) getImportRetryPolicy(pvc *corev1.PersistentVolumeClaim) (importRetryPolicy, bool, error) {
+	cdiConfig := &cdiv1.CDIConfig{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig); err != nil {
+		return importRetryPolicy{}, false, err
+	}
+	policy, set := importRetryPolicyFor(pvc, cdiConfig)
+	return policy, set, nil
+}
+
 func (r *ImportReconciler) isInsecureTLS(pvc *corev1.PersistentVolumeClaim, cdiConfig *cdiv1.CDIConfig) (bool, error) {
 	ep, ok := pvc.Annotations[cc.AnnEndpoint]
 	if !ok || ep == "" {
@@ -792,6 +1288,177 @@ func (r *ImportReconciler) createScratchPvcForPod(pvc *corev1.PersistentVolumeCl
 	return nil
 }
 
+func nfsSourceNameFromPvc(pvc *corev1.PersistentVolumeClaim) string {
+	return naming.GetResourceName(pvc.Name, common.NFSSourceNameSuffix)
+}
+
+// nfsSourceVolumeName returns the name of the volume of the NFS export of the NFS source of the PVC, unique in the
+// cluster as a namespace name has no dot
+func nfsSourceVolumeName(namespace, name string) string {
+	return naming.GetResourceName(namespace+"."+name, common.NFSSourceNameSuffix)
+}
+
+// nfsMountConfigFor returns the options of the mounts of the NFS exports, and the time an importer pod has to mount the
+// NFS export of an NFS source. The exports are mounted read-only whatever the options.
+func nfsMountConfigFor(cdiConfig *cdiv1.CDIConfig) ([]string, time.Duration) {
+	options, timeout := defaultNFSMountOptions, defaultNFSMountTimeout
+	if config := cdiConfig.Spec.NFS; config != nil {
+		if len(config.MountOptions) > 0 {
+			options = []string{}
+			for _, option := range config.MountOptions {
+				if option != "rw" && option != "ro" {
+					options = append(options, option)
+				}
+			}
+		}
+		if config.MountTimeout != nil && config.MountTimeout.Duration > 0 {
+			timeout = config.MountTimeout.Duration
+		}
+	}
+	return options, timeout
+}
+
+func (r *ImportReconciler) getNFSMountConfig() ([]string, time.Duration, error) {
+	cdiConfig := &cdiv1.CDIConfig{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig); err != nil {
+		return nil, 0, err
+	}
+	options, timeout := nfsMountConfigFor(cdiConfig)
+	return options, timeout, nil
+}
+
+// nfsMountTimedOut returns true if the importer pod was scheduled more than timeout ago and its container was never
+// started, the kubelet could not mount the NFS export.
+func nfsMountTimedOut(pod *corev1.Pod, timeout time.Duration) bool {
+	if pod.Status.Phase != corev1.PodPending {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil {
+			return false
+		}
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionTrue {
+			return time.Since(condition.LastTransitionTime.Time) > timeout
+		}
+	}
+	return false
+}
+
+// createNFSSource creates the volume of the directory of the NFS export holding the file of the NFS source of the PVC,
+// and the claim bound to it mounted read-only in the importer pod. They are kept for the retries of the import.
+func (r *ImportReconciler) createNFSSource(pvc *corev1.PersistentVolumeClaim) error {
+	ep, err := cc.GetEndpoint(pvc)
+	if err != nil {
+		return err
+	}
+	nfsURL, err := url.Parse(ep)
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse the NFS URL %q", ep)
+	}
+	options, _, err := r.getNFSMountConfig()
+	if err != nil {
+		return err
+	}
+	claimName := nfsSourceNameFromPvc(pvc)
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nfsSourceVolumeName(pvc.Namespace, pvc.Name),
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse(nfsSourceCapacity),
+			},
+			AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			MountOptions:                  options,
+			ClaimRef: &corev1.ObjectReference{
+				Namespace: pvc.Namespace,
+				Name:      claimName,
+			},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				NFS: &corev1.NFSVolumeSource{
+					Server:   nfsURL.Hostname(),
+					Path:     path.Dir(nfsURL.Path),
+					ReadOnly: true,
+				},
+			},
+		},
+	}
+	util.SetRecommendedLabels(pv, r.installerLabels, "cdi-controller")
+	if err := r.client.Create(context.TODO(), pv); err != nil {
+		if !k8serrors.IsAlreadyExists(err) {
+			return errors.Wrap(err, "NFS source volume API create errored")
+		}
+		existing := &corev1.PersistentVolume{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: pv.Name}, existing); err != nil {
+			return err
+		}
+		if existing.DeletionTimestamp != nil {
+			// The volume of the previous import is not gone yet, the claim would not bind to it
+			return errors.Errorf("terminating NFS source volume %s found", pv.Name)
+		}
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      claimName,
+			Namespace: pvc.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "v1",
+				Kind:               "PersistentVolumeClaim",
+				Name:               pvc.Name,
+				UID:                pvc.UID,
+				BlockOwnerDeletion: pointer.Bool(true),
+				Controller:         pointer.Bool(true),
+			}},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(nfsSourceCapacity),
+				},
+			},
+			StorageClassName: pointer.String(""),
+			VolumeName:       pv.Name,
+		},
+	}
+	util.SetRecommendedLabels(claim, r.installerLabels, "cdi-controller")
+	if err := r.client.Create(context.TODO(), claim); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return errors.Wrap(err, "NFS source PVC API create errored")
+	}
+	return nil
+}
+
+// deleteNFSSource deletes the claim and the volume of the NFS export of the NFS source of the PVC.
+func (r *ImportReconciler) deleteNFSSource(pvc *corev1.PersistentVolumeClaim) error {
+	claim := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: nfsSourceNameFromPvc(pvc), Namespace: pvc.Namespace}, claim); cc.IgnoreNotFound(err) != nil {
+		return err
+	} else if err == nil && metav1.IsControlledBy(claim, pvc) {
+		if err := r.client.Delete(context.TODO(), claim); cc.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return r.deleteNFSSourceVolume(pvc.Namespace, pvc.Name)
+}
+
+// deleteNFSSourceVolume deletes the volume of the NFS export of the NFS source of the PVC of namespace and name, if any.
+func (r *ImportReconciler) deleteNFSSourceVolume(namespace, name string) error {
+	pv := &corev1.PersistentVolume{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: nfsSourceVolumeName(namespace, name)}, pv); err != nil {
+		return cc.IgnoreNotFound(err)
+	}
+	if pv.DeletionTimestamp != nil {
+		return nil
+	}
+	if err := r.client.Delete(context.TODO(), pv); cc.IgnoreNotFound(err) != nil {
+		return err
+	}
+	return nil
+}
+
 // Get path to VDDK image from 'v2v-vmware' ConfigMap
 func (r *ImportReconciler) getVddkImageName() (*string, error) {
 	namespace := util.GetNamespace()
@@ -862,15 +1529,21 @@ func createImportPodNameFromPvc(pvc *corev1.PersistentVolumeClaim) string {
 // importer pod.
 func createImporterPod(log logr.Logger, client client.Client, args *importerPodArgs, installerLabels map[string]string) (*corev1.Pod, error) {
 	var err error
-	args.podResourceRequirements, err = cc.GetDefaultPodResourceRequirements(client)
+	args.podResourceRequirements, err = cc.GetPodResourceRequirements(client, args.pvc)
 	if err != nil {
 		return nil, err
 	}
 
-	args.workloadNodePlacement, err = cc.GetWorkloadNodePlacement(client)
+	args.workloadNodePlacement, err = cc.GetPodNodePlacement(client, args.pvc)
 	if err != nil {
 		return nil, err
 	}
+	cc.RequireSelectedNode(args.workloadNodePlacement, args.pvc)
+	cc.ExcludeProvisioningFailedNodes(args.workloadNodePlacement, args.pvc)
+
+	if args.restartPolicy == "" {
+		args.restartPolicy = corev1.RestartPolicyOnFailure
+	}
 
 	var pod *corev1.Pod
 	if cc.GetSource(args.pvc) == cc.SourceRegistry && args.pvc.Annotations[cc.AnnRegistryImportMethod] == string(cdiv1.RegistryPullNode) {
@@ -965,7 +1638,7 @@ func makeNodeImporterPodSpec(args *importerPodArgs) *corev1.Pod {
 					},
 				},
 			},
-			RestartPolicy:     corev1.RestartPolicyOnFailure,
+			RestartPolicy:     args.restartPolicy,
 			Volumes:           volumes,
 			NodeSelector:      args.workloadNodePlacement.NodeSelector,
 			Tolerations:       args.workloadNodePlacement.Tolerations,
@@ -1079,7 +1752,7 @@ func makeImporterPodSpec(args *importerPodArgs) *corev1.Pod {
 			Containers: []corev1.Container{
 				*importerContainer,
 			},
-			RestartPolicy:     corev1.RestartPolicyOnFailure,
+			RestartPolicy:     args.restartPolicy,
 			Volumes:           volumes,
 			NodeSelector:      args.workloadNodePlacement.NodeSelector,
 			Tolerations:       args.workloadNodePlacement.Tolerations,
@@ -1138,6 +1811,143 @@ func makeImporterPodSpec(args *importerPodArgs) *corev1.Pod {
 		pod.Spec.Volumes = append(pod.Spec.Volumes, createConfigMapVolume(ProxyCertVolName, GetImportProxyConfigMapName(args.pvc.Name)))
 	}
 
+	if args.podEnvVar.signatureKeys != "" {
+		vm := corev1.VolumeMount{
+			Name:      signatureKeyVolumeName,
+			MountPath: common.ImporterSignatureKeyDir,
+			ReadOnly:  true,
+		}
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, vm)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, createConfigMapVolume(signatureKeyVolumeName, args.podEnvVar.signatureKeys))
+	}
+
+	if args.podEnvVar.secretName != "" && args.podEnvVar.source == cc.SourceSFTP {
+		vm := corev1.VolumeMount{
+			Name:      sftpSecretVolumeName,
+			MountPath: common.ImporterSFTPSecretDir,
+			ReadOnly:  true,
+		}
+		vol := corev1.Volume{
+			Name: sftpSecretVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: args.podEnvVar.secretName,
+				},
+			},
+		}
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, vm)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, vol)
+	}
+
+	if args.podEnvVar.secretName != "" && args.podEnvVar.source == cc.SourceSMB {
+		vm := corev1.VolumeMount{
+			Name:      smbSecretVolumeName,
+			MountPath: common.ImporterSMBSecretDir,
+			ReadOnly:  true,
+		}
+		vol := corev1.Volume{
+			Name: smbSecretVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: args.podEnvVar.secretName,
+				},
+			},
+		}
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, vm)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, vol)
+	}
+
+	if args.podEnvVar.filePVC != "" || args.podEnvVar.fileHostPath != "" {
+		// the file is never written, a failed import is retried with the same data
+		vm := corev1.VolumeMount{
+			Name:      fileSourceVolumeName,
+			MountPath: common.ImporterFileSourceDir,
+			ReadOnly:  true,
+		}
+		vol := corev1.Volume{Name: fileSourceVolumeName}
+		if args.podEnvVar.filePVC != "" {
+			vol.VolumeSource.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: args.podEnvVar.filePVC,
+				ReadOnly:  true,
+			}
+		} else {
+			hostPathType := corev1.HostPathDirectory
+			vol.VolumeSource.HostPath = &corev1.HostPathVolumeSource{
+				Path: args.podEnvVar.fileHostPath,
+				Type: &hostPathType,
+			}
+		}
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, vm)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, vol)
+	}
+
+	if args.podEnvVar.encryptionSecret != "" {
+		vm := corev1.VolumeMount{
+			Name:      encryptionSecretVolumeName,
+			MountPath: common.ImporterEncryptionSecretDir,
+			ReadOnly:  true,
+		}
+		vol := corev1.Volume{
+			Name: encryptionSecretVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: args.podEnvVar.encryptionSecret,
+				},
+			},
+		}
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, vm)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, vol)
+	}
+
+	if args.podEnvVar.secretName != "" && args.podEnvVar.source == cc.SourceHTTP {
+		// only the client certificate and key of the secret are mounted, the secret may hold neither
+		optional := true
+		vm := corev1.VolumeMount{
+			Name:      clientCertVolumeName,
+			MountPath: common.ImporterClientCertDir,
+			ReadOnly:  true,
+		}
+		vol := corev1.Volume{
+			Name: clientCertVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: args.podEnvVar.secretName,
+					Items: []corev1.KeyToPath{
+						{Key: common.KeyClientCert, Path: common.KeyClientCert},
+						{Key: common.KeyClientKey, Path: common.KeyClientKey},
+					},
+					Optional: &optional,
+				},
+			},
+		}
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, vm)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, vol)
+	}
+
+	if args.podEnvVar.secretName != "" && args.podEnvVar.source == cc.SourceRegistry {
+		// the docker config of a kubernetes.io/dockerconfigjson secret, which may hold an access key instead
+		optional := true
+		vm := corev1.VolumeMount{
+			Name:      registryAuthVolumeName,
+			MountPath: common.ImporterRegistryAuthDir,
+			ReadOnly:  true,
+		}
+		vol := corev1.Volume{
+			Name: registryAuthVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: args.podEnvVar.secretName,
+					Items: []corev1.KeyToPath{
+						{Key: corev1.DockerConfigJsonKey, Path: common.ImporterRegistryAuthFileName},
+					},
+					Optional: &optional,
+				},
+			},
+		}
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, vm)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, vol)
+	}
+
 	for index, header := range args.podEnvVar.secretExtraHeaders {
 		vm := corev1.VolumeMount{
 			Name:      fmt.Sprintf(secretExtraHeadersVolumeName, index),
@@ -1293,12 +2103,177 @@ func makeImportEnv(podEnvVar *importPodEnvVar, uid types.UID) []corev1.EnvVar {
 			Name:  common.ImporterFinalCheckpoint,
 			Value: podEnvVar.finalCheckpoint,
 		},
+		{
+			Name:  common.ImporterArchiveEntry,
+			Value: podEnvVar.archiveEntry,
+		},
+		{
+			Name:  common.ImporterFlattenBackingChain,
+			Value: strconv.FormatBool(podEnvVar.flattenChain),
+		},
+		{
+			Name:  common.ImporterDiskFormat,
+			Value: podEnvVar.diskFormat,
+		},
+		{
+			Name:  common.ImporterCompressQcow2,
+			Value: strconv.FormatBool(podEnvVar.compressQcow2),
+		},
+		{
+			Name:  common.ImporterQcow2ClusterSize,
+			Value: podEnvVar.qcow2ClusterSize,
+		},
+		{
+			Name:  common.ImporterQcow2Compat,
+			Value: podEnvVar.qcow2Compat,
+		},
+		{
+			Name:  common.ImporterPreserveImageSize,
+			Value: strconv.FormatBool(podEnvVar.preserveImageSize),
+		},
+		{
+			Name:  common.ImporterSkipImageCheck,
+			Value: strconv.FormatBool(podEnvVar.skipImageCheck),
+		},
 		{
 			Name:  common.Preallocation,
 			Value: strconv.FormatBool(podEnvVar.preallocation),
 		},
+		{
+			Name:  common.PreallocationMode,
+			Value: podEnvVar.preallocationMode,
+		},
+		{
+			Name:  common.ImporterQemuCacheMode,
+			Value: podEnvVar.qemuCacheMode,
+		},
+		{
+			Name:  common.ImporterQemuCoroutines,
+			Value: podEnvVar.qemuCoroutines,
+		},
+		{
+			Name:  common.ImporterQemuMemoryLimit,
+			Value: podEnvVar.qemuMemoryLimit,
+		},
+		{
+			Name:  common.ImporterQemuNiceness,
+			Value: podEnvVar.qemuNiceness,
+		},
 	}
-	if podEnvVar.secretName != "" {
+	if podEnvVar.bandwidthLimit != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterBandwidthLimit,
+			Value: podEnvVar.bandwidthLimit,
+		})
+	}
+	if podEnvVar.s3AddressingStyle != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterS3AddressingStyle,
+			Value: podEnvVar.s3AddressingStyle,
+		})
+	}
+	if podEnvVar.s3PartSize != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterS3PartSize,
+			Value: podEnvVar.s3PartSize,
+		})
+	}
+	if podEnvVar.s3Concurrency != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterS3Concurrency,
+			Value: podEnvVar.s3Concurrency,
+		})
+	}
+	if podEnvVar.filePVC != "" || podEnvVar.fileHostPath != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterFileSourceDirVar,
+			Value: common.ImporterFileSourceDir,
+		})
+	}
+	if podEnvVar.glanceImage != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterGlanceImage,
+			Value: podEnvVar.glanceImage,
+		})
+	}
+	if podEnvVar.glanceProject != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterGlanceProject,
+			Value: podEnvVar.glanceProject,
+		})
+	}
+	if podEnvVar.glanceRegion != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterGlanceRegion,
+			Value: podEnvVar.glanceRegion,
+		})
+	}
+	if podEnvVar.blankFilesystem != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterBlankFilesystem,
+			Value: podEnvVar.blankFilesystem,
+		}, corev1.EnvVar{
+			Name:  common.ImporterBlankFilesystemLabel,
+			Value: podEnvVar.blankFsLabel,
+		})
+	}
+	if podEnvVar.moref != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterMoref,
+			Value: podEnvVar.moref,
+		})
+	}
+	if podEnvVar.artifactMediaType != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterRegistryArtifactMediaType,
+			Value: podEnvVar.artifactMediaType,
+		})
+	}
+	if podEnvVar.registryPlatform != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterRegistryPlatform,
+			Value: podEnvVar.registryPlatform,
+		})
+	}
+	if podEnvVar.defaultPlatform != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterRegistryDefaultPlatform,
+			Value: podEnvVar.defaultPlatform,
+		})
+	}
+	if podEnvVar.secretName != "" && podEnvVar.source == cc.SourceGCS {
+		// the secret of a GCS source holds the JSON key of a service account
+		env = append(env, corev1.EnvVar{
+			Name: common.ImporterGCSServiceAccount,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: podEnvVar.secretName,
+					},
+					Key: common.KeyServiceAccount,
+				},
+			},
+		})
+	} else if podEnvVar.secretName != "" && podEnvVar.source == cc.SourceSFTP {
+		// the credentials and the known hosts of an SFTP source are read from the mounted secret
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterSFTPSecretDirVar,
+			Value: common.ImporterSFTPSecretDir,
+		})
+	} else if podEnvVar.secretName != "" && podEnvVar.source == cc.SourceSMB {
+		// the credentials of an SMB source are read from the mounted secret, the domain key being optional
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterSMBSecretDirVar,
+			Value: common.ImporterSMBSecretDir,
+		})
+	} else if podEnvVar.secretName != "" {
+		// the secret of an http source holds either an access key or a bearer token, the secret of a registry source
+		// either an access key or a docker config
+		var optionalKeys *bool
+		if podEnvVar.source == cc.SourceHTTP || podEnvVar.source == cc.SourceRegistry {
+			optional := true
+			optionalKeys = &optional
+		}
 		env = append(env, corev1.EnvVar{
 			Name: common.ImporterAccessKeyID,
 			ValueFrom: &corev1.EnvVarSource{
@@ -1306,7 +2281,8 @@ func makeImportEnv(podEnvVar *importPodEnvVar, uid types.UID) []corev1.EnvVar {
 					LocalObjectReference: corev1.LocalObjectReference{
 						Name: podEnvVar.secretName,
 					},
-					Key: common.KeyAccess,
+					Key:      common.KeyAccess,
+					Optional: optionalKeys,
 				},
 			},
 		}, corev1.EnvVar{
@@ -1316,11 +2292,62 @@ func makeImportEnv(podEnvVar *importPodEnvVar, uid types.UID) []corev1.EnvVar {
 					LocalObjectReference: corev1.LocalObjectReference{
 						Name: podEnvVar.secretName,
 					},
-					Key: common.KeySecret,
+					Key:      common.KeySecret,
+					Optional: optionalKeys,
 				},
 			},
 		})
-
+		if podEnvVar.source == cc.SourceHTTP {
+			env = append(env, corev1.EnvVar{
+				Name: common.ImporterBearerToken,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: podEnvVar.secretName,
+						},
+						Key:      common.KeyToken,
+						Optional: optionalKeys,
+					},
+				},
+			}, corev1.EnvVar{
+				// the client certificate and key are read from the mounted secret
+				Name:  common.ImporterClientCertDirVar,
+				Value: common.ImporterClientCertDir,
+			})
+		}
+		if podEnvVar.source == cc.SourceRegistry {
+			env = append(env, corev1.EnvVar{
+				Name:  common.ImporterRegistryAuthFile,
+				Value: path.Join(common.ImporterRegistryAuthDir, common.ImporterRegistryAuthFileName),
+			})
+		}
+		if podEnvVar.source == cc.SourceS3 {
+			// the endpoint and the region of an S3 source are optional
+			optional := true
+			env = append(env, corev1.EnvVar{
+				Name: common.ImporterS3Endpoint,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: podEnvVar.secretName,
+						},
+						Key:      common.KeyEndpoint,
+						Optional: &optional,
+					},
+				},
+			}, corev1.EnvVar{
+				Name: common.ImporterS3Region,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: podEnvVar.secretName,
+						},
+						Key:      common.KeyRegion,
+						Optional: &optional,
+					},
+				},
+			})
+		}
 	}
 	if podEnvVar.certConfigMap != "" {
 		env = append(env, corev1.EnvVar{
@@ -1328,6 +2355,13 @@ func makeImportEnv(podEnvVar *importPodEnvVar, uid types.UID) []corev1.EnvVar {
 			Value: common.ImporterCertDir,
 		})
 	}
+	if podEnvVar.encryptionSecret != "" {
+		// the passphrase is read by qemu-img from the mounted secret, it is never in the environment
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterEncryptionKeyFile,
+			Value: path.Join(common.ImporterEncryptionSecretDir, common.KeyPassphrase),
+		})
+	}
 	if podEnvVar.certConfigMapProxy != "" {
 		env = append(env, corev1.EnvVar{
 			Name:  common.ImporterProxyCertDirVar,
@@ -1340,5 +2374,71 @@ func makeImportEnv(podEnvVar *importPodEnvVar, uid types.UID) []corev1.EnvVar {
 			Value: header,
 		})
 	}
+	if podEnvVar.mirrors != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterMirrors,
+			Value: podEnvVar.mirrors,
+		})
+	}
+	if podEnvVar.urlPattern != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterURLPattern,
+			Value: podEnvVar.urlPattern,
+		})
+	}
+	if podEnvVar.httpConcurrency != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterHTTPConcurrency,
+			Value: podEnvVar.httpConcurrency,
+		})
+	}
+	if podEnvVar.maxRedirects != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterMaxRedirects,
+			Value: podEnvVar.maxRedirects,
+		})
+	}
+	if podEnvVar.redirectHosts != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterRedirectHosts,
+			Value: podEnvVar.redirectHosts,
+		})
+	}
+	if podEnvVar.checksum != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterChecksum,
+			Value: podEnvVar.checksum,
+		})
+	}
+	if podEnvVar.signatureKeys != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterSignatureKeyDirVar,
+			Value: common.ImporterSignatureKeyDir,
+		})
+	}
+	if podEnvVar.signatureURL != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterSignatureURL,
+			Value: podEnvVar.signatureURL,
+		})
+	}
+	if podEnvVar.signature != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterSignature,
+			Value: podEnvVar.signature,
+		})
+	}
+	if podEnvVar.sourceETag != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterSourceETag,
+			Value: podEnvVar.sourceETag,
+		})
+	}
+	if podEnvVar.sourceLastModified != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ImporterSourceLastModified,
+			Value: podEnvVar.sourceLastModified,
+		})
+	}
 	return env
 }
@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -34,6 +35,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -46,11 +48,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	kvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 )
 
 const (
@@ -64,6 +68,9 @@ var (
 	importLog        = logf.Log.WithName("import-controller-test")
 )
 
+// hostPathDirectory is the type of the directories of the node mounted in the importer pod
+var hostPathDirectory = corev1.HostPathDirectory
+
 var _ = Describe("Test PVC annotations status", func() {
 
 	It("Should return complete if annotation is set", func() {
@@ -116,10 +123,10 @@ var _ = Describe("Test PVC annotations status", func() {
 		r := createImportReconciler()
 		r.featureGates = &FakeFeatureGates{honorWaitForFirstConsumerEnabled: true}
 		testPvc := createPendingPvc("testPvc1", "default", map[string]string{
-			cc.AnnPodPhase:      string(corev1.PodPending),
-			cc.AnnEndpoint:      testEndPoint,
-			cc.AnnSource:        cc.SourceHTTP,
-			AnnImmediateBinding: "true",
+			cc.AnnPodPhase:         string(corev1.PodPending),
+			cc.AnnEndpoint:         testEndPoint,
+			cc.AnnSource:           cc.SourceHTTP,
+			cc.AnnImmediateBinding: "true",
 		}, nil)
 		Expect(r.shouldReconcilePVC(testPvc, importLog)).To(BeTrue())
 	})
@@ -127,10 +134,10 @@ var _ = Describe("Test PVC annotations status", func() {
 		r := createImportReconciler()
 		r.featureGates = &FakeFeatureGates{honorWaitForFirstConsumerEnabled: false}
 		testPvc := createPendingPvc("testPvc1", "default", map[string]string{
-			cc.AnnPodPhase:      string(corev1.PodPending),
-			cc.AnnEndpoint:      testEndPoint,
-			cc.AnnSource:        cc.SourceHTTP,
-			AnnImmediateBinding: "true",
+			cc.AnnPodPhase:         string(corev1.PodPending),
+			cc.AnnEndpoint:         testEndPoint,
+			cc.AnnSource:           cc.SourceHTTP,
+			cc.AnnImmediateBinding: "true",
 		}, nil)
 		Expect(r.shouldReconcilePVC(testPvc, importLog)).To(BeTrue())
 	})
@@ -196,6 +203,17 @@ var _ = Describe("ImportConfig Controller reconcile loop", func() {
 		Expect(resultPvc.GetAnnotations()[cc.AnnPodPhase]).To(BeEquivalentTo(corev1.PodSucceeded))
 	})
 
+	It("Should create a pod, if creating a block PVC with source none and a filesystem", func() {
+		reconciler = createImportReconciler(createBlockPvc("testPvc1", "block", map[string]string{cc.AnnSource: cc.SourceNone, cc.AnnBlankFilesystem: "ext4"}, nil))
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "block"}})
+		Expect(err).ToNot(HaveOccurred())
+		resultPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "block"}, resultPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resultPvc.GetAnnotations()[cc.AnnPodPhase]).ToNot(BeEquivalentTo(corev1.PodSucceeded))
+		Expect(resultPvc.GetAnnotations()[cc.AnnImportPod]).ToNot(BeEmpty())
+	})
+
 	It("should do nothing and not error, if a PVC that is completed is passed", func() {
 		orgPvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodSucceeded)}, nil)
 		orgPvc.TypeMeta.APIVersion = "v1"
@@ -316,6 +334,88 @@ var _ = Describe("ImportConfig Controller reconcile loop", func() {
 		Expect(pod.Spec.Tolerations).To(Equal(dummyTolerations))
 	})
 
+	It("Should create a POD with the annotated node placement on the node selected for the PVC", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", nil, map[string]string{
+			cc.AnnEndpoint:        testEndPoint,
+			cc.AnnImportPod:       "importer-testPvc1",
+			cc.AnnPodNodeSelector: `{"kubernetes.io/os":"linux"}`,
+			cc.AnnPodTolerations:  `[{"key":"storage","operator":"Exists"}]`,
+			cc.AnnSelectedNode:    "node01",
+		}, nil, v1.ClaimPending)
+		reconciler = createImportReconciler(pvc)
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		pod := &corev1.Pod{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, pod)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux"}))
+		Expect(pod.Spec.Tolerations).To(ContainElement(v1.Toleration{Key: "storage", Operator: v1.TolerationOpExists}))
+		terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		Expect(terms).To(HaveLen(1))
+		Expect(terms[0].MatchFields).To(ConsistOf(v1.NodeSelectorRequirement{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{"node01"}}))
+	})
+
+	It("Should create a POD avoiding the nodes where the volume of the PVC could not be provisioned", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", nil, map[string]string{
+			cc.AnnEndpoint:                testEndPoint,
+			cc.AnnImportPod:               "importer-testPvc1",
+			cc.AnnProvisioningFailedNodes: "node01,node02",
+		}, nil, v1.ClaimPending)
+		reconciler = createImportReconciler(pvc)
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		pod := &corev1.Pod{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, pod)
+		Expect(err).ToNot(HaveOccurred())
+
+		terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		Expect(terms).To(HaveLen(1))
+		Expect(terms[0].MatchFields).To(ConsistOf(v1.NodeSelectorRequirement{Key: "metadata.name", Operator: v1.NodeSelectorOpNotIn, Values: []string{"node01", "node02"}}))
+	})
+
+	It("Should record the node selected for the PVC of a pending importer POD", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", nil, map[string]string{
+			cc.AnnEndpoint:     testEndPoint,
+			cc.AnnImportPod:    "importer-testPvc1",
+			cc.AnnSelectedNode: "node01",
+		}, nil, v1.ClaimPending)
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", nil)
+		pod.Status.Phase = corev1.PodPending
+		reconciler = createImportReconciler(pvc, pod)
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.Annotations[cc.AnnProvisioningNode]).To(Equal("node01"))
+		Expect(resPvc.Annotations).ToNot(HaveKey(cc.AnnProvisioningFailedNodes))
+	})
+
+	It("Should reschedule a pending importer POD when the volume could not be provisioned on the selected node", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", nil, map[string]string{
+			cc.AnnEndpoint:                testEndPoint,
+			cc.AnnImportPod:               "importer-testPvc1",
+			cc.AnnProvisioningNode:        "node02",
+			cc.AnnProvisioningFailedNodes: "node01",
+		}, nil, v1.ClaimPending)
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", nil)
+		pod.Status.Phase = corev1.PodPending
+		reconciler = createImportReconciler(pvc, pod)
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.Annotations[cc.AnnProvisioningFailedNodes]).To(Equal("node01,node02"))
+		Expect(resPvc.Annotations).ToNot(HaveKey(cc.AnnProvisioningNode))
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: "default"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(ProvisioningFailedOnNode))
+		Expect(event).To(ContainSubstring("node02"))
+	})
+
 	It("Should create a POD if a PVC with all needed annotations is passed", func() {
 		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnImportPod: "importer-testPvc1", cc.AnnPodNetwork: "net1"}, nil)
 		pvc.Status.Phase = v1.ClaimBound
@@ -339,6 +439,59 @@ var _ = Describe("ImportConfig Controller reconcile loop", func() {
 		Expect(pod.GetAnnotations()[cc.AnnPodSidecarInjection]).To(Equal(cc.AnnPodSidecarInjectionDefault))
 	})
 
+	It("Should not create a POD if the import of the PVC failed terminally", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnImportPod: "importer-testPvc1", cc.AnnPodPhase: string(corev1.PodFailed), cc.AnnImportTerminalError: "unsupported format"}, nil)
+		pvc.Status.Phase = v1.ClaimBound
+		reconciler = createImportReconciler(pvc)
+		result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+		Expect(result.RequeueAfter).To(BeZero())
+		pod := &corev1.Pod{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, pod)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	table.DescribeTable("Should mount the node directory of a file source", func(enabled, owned bool, dvHostPath string, allowed bool) {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnSource: cc.SourceFile, cc.AnnEndpoint: "disk.img", cc.AnnFileHostPath: "/var/images", cc.AnnImportPod: "importer-testPvc1"}, nil)
+		pvc.Status.Phase = v1.ClaimBound
+		objs := []runtime.Object{pvc}
+		if owned {
+			dv := &cdiv1.DataVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "testPvc1", Namespace: "default", UID: "dv-uid"},
+				Spec: cdiv1.DataVolumeSpec{
+					Source: &cdiv1.DataVolumeSource{File: &cdiv1.DataVolumeSourceFile{Path: "disk.img", HostPath: dvHostPath}},
+				},
+			}
+			pvc.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(dv, cdiv1.SchemeGroupVersion.WithKind("DataVolume"))}
+			objs = append(objs, dv)
+		}
+		reconciler = createImportReconciler(objs...)
+		reconciler.featureGates = &FakeFeatureGates{hostPathImportEnabled: enabled}
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		pod := &corev1.Pod{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, pod)
+		resPvc := &corev1.PersistentVolumeClaim{}
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)).To(Succeed())
+		if allowed {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pod.Spec.Volumes).To(ContainElement(HaveField("VolumeSource.HostPath.Path", "/var/images")))
+			Expect(isImportTerminallyFailed(resPvc)).To(BeFalse())
+			return
+		}
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(isImportTerminallyFailed(resPvc)).To(BeTrue())
+		Expect(resPvc.Annotations[cc.AnnPodPhase]).To(Equal(string(corev1.PodFailed)))
+		Expect(resPvc.Annotations[cc.AnnRunningConditionReason]).To(Equal(cc.HostPathImportNotAllowed))
+		Expect(<-reconciler.recorder.(*record.FakeRecorder).Events).To(ContainSubstring(ErrImportFailedPVC))
+	},
+		table.Entry("for its DataVolume with the feature gate", true, true, "/var/images", true),
+		table.Entry("not without the feature gate", false, true, "/var/images", false),
+		table.Entry("not for a PVC without DataVolume", true, false, "", false),
+		table.Entry("not for another directory than the one of its DataVolume", true, true, "/var/other", false),
+	)
+
 	It("Should not pass non-approved PVC annotation to created POD", func() {
 		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnImportPod: "importer-testPvc1", "annot1": "value1"}, nil)
 		pvc.Status.Phase = v1.ClaimBound
@@ -409,11 +562,14 @@ var _ = Describe("Update PVC from POD", func() {
 		resPod := &corev1.Pod{}
 		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, resPod)
 		Expect(err).ToNot(HaveOccurred())
+		succeeded := testutil.ToFloat64(ImportsSucceededCounter.WithLabelValues(cc.SourceHTTP))
 		err = reconciler.updatePvcFromPod(pvc, pod, reconciler.log)
 		Expect(err).ToNot(HaveOccurred())
 		By("Checking import successful event recorded")
 		event := <-reconciler.recorder.(*record.FakeRecorder).Events
 		Expect(event).To(ContainSubstring("Import Successful"))
+		By("Checking the import is reported to prometheus")
+		Expect(testutil.ToFloat64(ImportsSucceededCounter.WithLabelValues(cc.SourceHTTP))).To(Equal(succeeded + 1))
 		By("Checking pvc phase has been updated")
 		resPvc := &corev1.PersistentVolumeClaim{}
 		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
@@ -429,6 +585,65 @@ var _ = Describe("Update PVC from POD", func() {
 		Expect(resPvc.GetAnnotations()[cc.AnnRunningConditionReason]).To(Equal("Reason"))
 	})
 
+	It("Should include the digest of the disk image written in the import successful event", func() {
+		digest := "sha256:" + strings.Repeat("d", 64)
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodPending)}, nil)
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", nil)
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.DestinationDigest + " " + digest,
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		reconciler = createImportReconciler(pvc, pod)
+		err := reconciler.updatePvcFromPod(pvc, pod, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring("Import Successful, " + common.DestinationDigest + " " + digest))
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.GetAnnotations()[cc.AnnDestinationDigest]).To(Equal(digest))
+	})
+
+	table.DescribeTable("Should record the format of the disk image written by a succeeded pod", func(message, contentType, expectedFormat string) {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodPending), cc.AnnContentType: contentType}, nil)
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", nil)
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: message,
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		reconciler = createImportReconciler(pvc, pod)
+		err := reconciler.updatePvcFromPod(pvc, pod, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		format, found := resPvc.GetAnnotations()[cc.AnnDiskFormat]
+		Expect(found).To(Equal(expectedFormat != ""))
+		Expect(format).To(Equal(expectedFormat))
+	},
+		table.Entry("raw when no format is reported", "Import Complete", string(cdiv1.DataVolumeKubeVirt), "raw"),
+		table.Entry("the reported format", "Import Complete, "+common.DiskFormat+" qcow2", string(cdiv1.DataVolumeKubeVirt), "qcow2"),
+		table.Entry("none for an archive", "Import Complete", string(cdiv1.DataVolumeArchive), ""),
+	)
+
 	It("Should update the PVC status to running, if pod is running", func() {
 		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodPending)}, nil)
 		pod := cc.CreateImporterTestPod(pvc, "testPvc1", nil)
@@ -506,6 +721,72 @@ var _ = Describe("Update PVC from POD", func() {
 
 	})
 
+	table.DescribeTable("Should create scratch PVC of the size multiplied by the scratch space size multiplier", func(annotation, configured string, expectedSize int64) {
+		scratchPvcName := &corev1.PersistentVolumeClaim{}
+		scratchPvcName.Name = "testPvc1-scratch"
+		annotations := map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnRequiresScratch: "true"}
+		if annotation != "" {
+			annotations[cc.AnnScratchSizeMultiplier] = annotation
+		}
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, annotations, nil, corev1.ClaimBound)
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", scratchPvcName)
+		reconciler = createImportReconciler(pvc, pod)
+		config := &cdiv1.CDIConfig{}
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, config)).To(Succeed())
+		config.Status.ScratchSpaceSizeMultiplier = configured
+		Expect(reconciler.client.Update(context.TODO(), config)).To(Succeed())
+
+		allocated := testutil.ToFloat64(ScratchSpaceAllocatedCounter)
+		Expect(reconciler.createScratchPvcForPod(pvc, pod)).To(Succeed())
+		scratchPvc := &v1.PersistentVolumeClaim{}
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1-scratch", Namespace: "default"}, scratchPvc)).To(Succeed())
+		size := scratchPvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		Expect(size.Value()).To(Equal(expectedSize))
+		Expect(testutil.ToFloat64(ScratchSpaceAllocatedCounter)).To(Equal(allocated + float64(expectedSize)))
+	},
+		table.Entry("of the same size by default", "", "", int64(1000000000)),
+		table.Entry("of the CDIConfig", "", "1.5", int64(1500000000)),
+		table.Entry("of the annotation overriding the CDIConfig", "0.25", "1.5", int64(250000000)),
+	)
+
+	It("Should create scratch PVC in the storage class of the scratch storage class annotation", func() {
+		scratchPvcName := &corev1.PersistentVolumeClaim{}
+		scratchPvcName.Name = "testPvc1-scratch"
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnRequiresScratch: "true", cc.AnnScratchStorageClass: "local-nvme"}, nil, corev1.ClaimBound)
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", scratchPvcName)
+		reconciler = createImportReconciler(pvc, pod)
+		Expect(reconciler.createScratchPvcForPod(pvc, pod)).To(Succeed())
+		scratchPvc := &v1.PersistentVolumeClaim{}
+		Expect(reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1-scratch", Namespace: "default"}, scratchPvc)).To(Succeed())
+		Expect(*scratchPvc.Spec.StorageClassName).To(Equal("local-nvme"))
+	})
+
+	It("Should not create scratch PVC in a storage class only providing block volumes", func() {
+		scratchPvcName := &corev1.PersistentVolumeClaim{}
+		scratchPvcName.Name = "testPvc1-scratch"
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnRequiresScratch: "true", cc.AnnScratchStorageClass: "block-only"}, nil, corev1.ClaimBound)
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", scratchPvcName)
+		blockMode := corev1.PersistentVolumeBlock
+		storageProfile := &cdiv1.StorageProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "block-only"},
+			Status: cdiv1.StorageProfileStatus{
+				ClaimPropertySets: []cdiv1.ClaimPropertySet{{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					VolumeMode:  &blockMode,
+				}},
+			},
+		}
+		reconciler = createImportReconciler(pvc, pod, storageProfile)
+		err := reconciler.createScratchPvcForPod(pvc, pod)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("only provides Block volumes"))
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(cc.ErrScratchSpaceBlockOnly))
+		scratchPvc := &v1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1-scratch", Namespace: "default"}, scratchPvc)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
 	// TODO: Update me to stay in progress if we were in progress already, its a pod failure and it will get restarted.
 	It("Should update phase on PVC, if pod exited with error state that is NOT scratchspace exit", func() {
 		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodRunning)}, nil, corev1.ClaimBound)
@@ -595,6 +876,81 @@ var _ = Describe("Update PVC from POD", func() {
 		Expect(resPvc.GetAnnotations()[cc.AnnRunningConditionReason]).To(Equal("Explosion"))
 	})
 
+	table.DescribeTable("Should mark PVC as failed and delete the pod, if pod exited with", func(exitCode int32, message string) {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodRunning)}, nil, corev1.ClaimBound)
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", nil)
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: exitCode,
+							Message:  "Unable to process data: " + message,
+						},
+					},
+				},
+			},
+		}
+		reconciler = createImportReconciler(pvc, pod)
+		failed := testutil.ToFloat64(ImportsFailedCounter.WithLabelValues(cc.SourceHTTP))
+		err := reconciler.updatePvcFromPod(pvc, pod, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.GetAnnotations()[cc.AnnPodPhase]).To(BeEquivalentTo(corev1.PodFailed))
+		Expect(resPvc.GetAnnotations()[cc.AnnImportTerminalError]).To(Equal("Unable to process data: " + message))
+		Expect(testutil.ToFloat64(ImportsFailedCounter.WithLabelValues(cc.SourceHTTP))).To(Equal(failed + 1))
+		By("Checking error event recorded")
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(message))
+		By("Checking the pod has been deleted")
+		resPod := &corev1.Pod{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: "default"}, resPod)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	},
+		table.Entry("the unsupported format exit code", int32(common.UnsupportedFormatExitCode), "unsupported format"),
+		table.Entry("the invalid encryption key exit code", int32(common.InvalidEncryptionKeyExitCode), "qcow2 invalid encryption key"),
+		table.Entry("the corrupt image exit code", int32(common.CorruptImageExitCode), "qcow2 corrupt image: qemu-img check: 2 leaked clusters were found on the image."),
+		table.Entry("the invalid client certificate exit code", int32(common.InvalidClientCertificateExitCode), "invalid client certificate: the certificate of CN=importer expired on 2022-03-01T12:00:00Z"),
+		table.Entry("the checksum mismatch exit code", int32(common.ChecksumMismatchExitCode), "checksum mismatch: the data of the source is sha256:"+strings.Repeat("ab", 32)+", sha256:"+strings.Repeat("cd", 32)+" expected"),
+		table.Entry("the invalid signature exit code", int32(common.InvalidSignatureExitCode), "invalid signature: the source is not signed by a trusted key, but by 0123456789ABCDEF"),
+		table.Entry("the no matching entry exit code", int32(common.NoMatchingEntryExitCode), "no matching entry: none of the 12 entries of the index of https://www.example.com/images/ matches \"fedora-*.qcow2\""),
+		table.Entry("the source not found exit code", int32(common.SourceNotFoundExitCode), "source not found: could not get s3 object: \"images/fedora.qcow2\""),
+	)
+
+	It("Should set the invalid client certificate reason of the running condition, if pod exited with the invalid client certificate exit code", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodRunning)}, nil, corev1.ClaimBound)
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", nil)
+		message := "Unable to connect to http data source: invalid client certificate: tls: private key does not match public key"
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: common.InvalidClientCertificateExitCode,
+							Message:  message,
+						},
+					},
+				},
+			},
+		}
+		reconciler = createImportReconciler(pvc, pod)
+		err := reconciler.updatePvcFromPod(pvc, pod, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.GetAnnotations()[cc.AnnPodPhase]).To(BeEquivalentTo(corev1.PodFailed))
+		Expect(resPvc.GetAnnotations()[cc.AnnRunningConditionReason]).To(Equal(cc.InvalidClientCertificate))
+		Expect(resPvc.GetAnnotations()[cc.AnnRunningConditionMessage]).To(Equal(message))
+	})
+
 	It("Should mark PVC as waiting for VDDK configmap, if not already present", func() {
 		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnImportPod: "testpod", cc.AnnSource: cc.SourceVDDK}, nil, corev1.ClaimPending)
 		reconciler = createImportReconciler(pvc)
@@ -792,39 +1148,618 @@ var _ = Describe("Create Importer Pod", func() {
 		table.Entry("should create pod with block volume mode and scratchspace", createBlockPvc("testBlockPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodPending), cc.AnnImportPod: "podName", cc.AnnPriorityClassName: "p0"}, nil), &scratchPvcName),
 	)
 
-	table.DescribeTable("should append current checkpoint name to importer pod", func(pvcName, checkpointID string) {
-		pvc := cc.CreatePvc(pvcName, "default", map[string]string{cc.AnnCurrentCheckpoint: checkpointID, cc.AnnEndpoint: testEndPoint}, nil)
-		pvc.Status.Phase = v1.ClaimBound
-
-		suffix := fmt.Sprintf("%s-checkpoint-%s", pvcName, checkpointID)
-		expectedName := fmt.Sprintf("importer-%s", suffix)
-		if len(expectedName) > kvalidation.DNS1123SubdomainMaxLength {
-			expectedName = naming.GetResourceName("importer", suffix)
+	It("should mount the encryption secret and pass the file of its passphrase", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnImportPod: "podName"}, nil)
+		reconciler := createImportReconciler(pvc)
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  &importPodEnvVar{imageSize: "1G", filesystemOverhead: "0.055", encryptionSecret: "passphrase-secret"},
+			pvc:        pvc,
 		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: encryptionSecretVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "passphrase-secret"},
+			},
+		}))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      encryptionSecretVolumeName,
+			MountPath: common.ImporterEncryptionSecretDir,
+			ReadOnly:  true,
+		}))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterEncryptionKeyFile,
+			Value: "/encryption/passphrase",
+		}))
+	})
 
+	It("should override the resources of the pod with the resource annotations of the PVC", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnImportPod: "podName", cc.AnnPodCPULimit: "2", cc.AnnPodMemoryRequest: "1Gi"}, nil)
 		reconciler := createImportReconciler(pvc)
-		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: pvcName, Namespace: "default"}})
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  &importPodEnvVar{imageSize: "1G", filesystemOverhead: "0.055"},
+			pvc:        pvc,
+		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
 		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Containers[0].Resources).To(Equal(corev1.ResourceRequirements{
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		}))
+	})
 
-		// First reconcile sets cc.AnnImportPod
-		resPvc := &corev1.PersistentVolumeClaim{}
-		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pvcName, Namespace: "default"}, resPvc)
+	It("should pass the optional endpoint and region of an S3 secret and the transfer settings", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: "s3://bucket/disk.img", cc.AnnImportPod: "podName"}, nil)
+		reconciler := createImportReconciler(pvc)
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  &importPodEnvVar{source: cc.SourceS3, secretName: "s3-secret", s3AddressingStyle: "path", s3PartSize: "128Mi", s3Concurrency: "8", imageSize: "1G", filesystemOverhead: "0.055"},
+			pvc:        pvc,
+		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
 		Expect(err).ToNot(HaveOccurred())
-		Expect(resPvc.Annotations[cc.AnnImportPod]).To(Equal(expectedName))
+		optional := true
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name: common.ImporterS3Endpoint,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "s3-secret"},
+					Key:                  common.KeyEndpoint,
+					Optional:             &optional,
+				},
+			},
+		}))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name: common.ImporterS3Region,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "s3-secret"},
+					Key:                  common.KeyRegion,
+					Optional:             &optional,
+				},
+			},
+		}))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterS3AddressingStyle,
+			Value: "path",
+		}))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterS3PartSize,
+			Value: "128Mi",
+		}))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterS3Concurrency,
+			Value: "8",
+		}))
+	})
 
-		// Second reconcile creates pod
-		_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: pvcName, Namespace: "default"}})
+	It("should pass the optional access key and bearer token of the secret of an http source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnImportPod: "podName"}, nil)
+		reconciler := createImportReconciler(pvc)
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  &importPodEnvVar{source: cc.SourceHTTP, secretName: "http-secret", imageSize: "1G", filesystemOverhead: "0.055"},
+			pvc:        pvc,
+		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
 		Expect(err).ToNot(HaveOccurred())
+		optional := true
+		for name, key := range map[string]string{
+			common.ImporterAccessKeyID: common.KeyAccess,
+			common.ImporterSecretKey:   common.KeySecret,
+			common.ImporterBearerToken: common.KeyToken,
+		} {
+			Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+				Name: name,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "http-secret"},
+						Key:                  key,
+						Optional:             &optional,
+					},
+				},
+			}))
+		}
+		By("Mounting the optional client certificate and key of the secret")
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterClientCertDirVar,
+			Value: common.ImporterClientCertDir,
+		}))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      clientCertVolumeName,
+			MountPath: common.ImporterClientCertDir,
+			ReadOnly:  true,
+		}))
+		Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: clientCertVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: "http-secret",
+					Items: []corev1.KeyToPath{
+						{Key: common.KeyClientCert, Path: common.KeyClientCert},
+						{Key: common.KeyClientKey, Path: common.KeyClientKey},
+					},
+					Optional: &optional,
+				},
+			},
+		}))
+	})
 
-		resPod := &corev1.Pod{}
-		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: expectedName, Namespace: "default"}, resPod)
+	It("should mount the docker config of the secret of a registry source along with its optional access key", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: "docker://registry.example.com/disk", cc.AnnImportPod: "podName"}, nil)
+		reconciler := createImportReconciler(pvc)
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  &importPodEnvVar{source: cc.SourceRegistry, secretName: "registry-secret", imageSize: "1G", filesystemOverhead: "0.055"},
+			pvc:        pvc,
+		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
 		Expect(err).ToNot(HaveOccurred())
-	},
-		table.Entry("with short PVC and checkpoint names", "testPvc1", "snap1"),
-		table.Entry("with long checkpoint name", "testPvc1", strings.Repeat("repeating-checkpoint-id-", 10)),
-		table.Entry("with long PVC name", strings.Repeat("test-pvc-", 20), "snap1"),
-		table.Entry("with long PVC and checkpoint names", strings.Repeat("test-pvc-", 20), strings.Repeat("repeating-checkpoint-id-", 10)),
-	)
+		optional := true
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name: common.ImporterAccessKeyID,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "registry-secret"},
+					Key:                  common.KeyAccess,
+					Optional:             &optional,
+				},
+			},
+		}))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterRegistryAuthFile,
+			Value: "/registryauth/auth.json",
+		}))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      registryAuthVolumeName,
+			MountPath: common.ImporterRegistryAuthDir,
+			ReadOnly:  true,
+		}))
+		Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: registryAuthVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: "registry-secret",
+					Items:      []corev1.KeyToPath{{Key: corev1.DockerConfigJsonKey, Path: common.ImporterRegistryAuthFileName}},
+					Optional:   &optional,
+				},
+			},
+		}))
+	})
+
+	It("should pass the service account key of the secret of a GCS source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: "gs://bucket/disk.img", cc.AnnImportPod: "podName"}, nil)
+		reconciler := createImportReconciler(pvc)
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  &importPodEnvVar{source: cc.SourceGCS, secretName: "gcs-key", imageSize: "1G", filesystemOverhead: "0.055"},
+			pvc:        pvc,
+		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name: common.ImporterGCSServiceAccount,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "gcs-key"},
+					Key:                  common.KeyServiceAccount,
+				},
+			},
+		}))
+		for _, env := range pod.Spec.Containers[0].Env {
+			Expect(env.Name).ToNot(Equal(common.ImporterAccessKeyID))
+		}
+	})
+
+	It("should mount the secret of an SFTP source instead of passing its keys in the environment", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: "sftp://importer@host/disk.img", cc.AnnImportPod: "podName"}, nil)
+		reconciler := createImportReconciler(pvc)
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  &importPodEnvVar{source: cc.SourceSFTP, secretName: "sftp-secret", imageSize: "1G", filesystemOverhead: "0.055"},
+			pvc:        pvc,
+		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterSFTPSecretDirVar,
+			Value: common.ImporterSFTPSecretDir,
+		}))
+		for _, env := range pod.Spec.Containers[0].Env {
+			Expect(env.ValueFrom).To(BeNil())
+		}
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      sftpSecretVolumeName,
+			MountPath: common.ImporterSFTPSecretDir,
+			ReadOnly:  true,
+		}))
+		Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: sftpSecretVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "sftp-secret"},
+			},
+		}))
+	})
+
+	It("should mount the secret of an SMB source instead of passing its keys in the environment", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: "smb://fileserver/vms/disk.img", cc.AnnImportPod: "podName"}, nil)
+		reconciler := createImportReconciler(pvc)
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  &importPodEnvVar{source: cc.SourceSMB, secretName: "smb-secret", imageSize: "1G", filesystemOverhead: "0.055"},
+			pvc:        pvc,
+		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterSMBSecretDirVar,
+			Value: common.ImporterSMBSecretDir,
+		}))
+		for _, env := range pod.Spec.Containers[0].Env {
+			Expect(env.ValueFrom).To(BeNil())
+		}
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      smbSecretVolumeName,
+			MountPath: common.ImporterSMBSecretDir,
+			ReadOnly:  true,
+		}))
+		Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: smbSecretVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "smb-secret"},
+			},
+		}))
+	})
+
+	table.DescribeTable("should mount the PVC or the directory of the node of a file source read-only", func(podEnvVar *importPodEnvVar, volumeSource corev1.VolumeSource) {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: "images/disk.qcow2", cc.AnnImportPod: "podName"}, nil)
+		reconciler := createImportReconciler(pvc)
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  podEnvVar,
+			pvc:        pvc,
+		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterFileSourceDirVar,
+			Value: common.ImporterFileSourceDir,
+		}))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      fileSourceVolumeName,
+			MountPath: common.ImporterFileSourceDir,
+			ReadOnly:  true,
+		}))
+		Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name:         fileSourceVolumeName,
+			VolumeSource: volumeSource,
+		}))
+	},
+		table.Entry("of a PVC",
+			&importPodEnvVar{source: cc.SourceFile, filePVC: "images", imageSize: "1G", filesystemOverhead: "0.055"},
+			corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "images", ReadOnly: true}}),
+		table.Entry("of a directory of the node",
+			&importPodEnvVar{source: cc.SourceFile, fileHostPath: "/var/images", imageSize: "1G", filesystemOverhead: "0.055"},
+			corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/images", Type: &hostPathDirectory}}),
+	)
+
+	table.DescribeTable("should append current checkpoint name to importer pod", func(pvcName, checkpointID string) {
+		pvc := cc.CreatePvc(pvcName, "default", map[string]string{cc.AnnCurrentCheckpoint: checkpointID, cc.AnnEndpoint: testEndPoint}, nil)
+		pvc.Status.Phase = v1.ClaimBound
+
+		suffix := fmt.Sprintf("%s-checkpoint-%s", pvcName, checkpointID)
+		expectedName := fmt.Sprintf("importer-%s", suffix)
+		if len(expectedName) > kvalidation.DNS1123SubdomainMaxLength {
+			expectedName = naming.GetResourceName("importer", suffix)
+		}
+
+		reconciler := createImportReconciler(pvc)
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: pvcName, Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		// First reconcile sets cc.AnnImportPod
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pvcName, Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.Annotations[cc.AnnImportPod]).To(Equal(expectedName))
+
+		// Second reconcile creates pod
+		_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: pvcName, Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		resPod := &corev1.Pod{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: expectedName, Namespace: "default"}, resPod)
+		Expect(err).ToNot(HaveOccurred())
+	},
+		table.Entry("with short PVC and checkpoint names", "testPvc1", "snap1"),
+		table.Entry("with long checkpoint name", "testPvc1", strings.Repeat("repeating-checkpoint-id-", 10)),
+		table.Entry("with long PVC name", strings.Repeat("test-pvc-", 20), "snap1"),
+		table.Entry("with long PVC and checkpoint names", strings.Repeat("test-pvc-", 20), strings.Repeat("repeating-checkpoint-id-", 10)),
+	)
+})
+
+var _ = Describe("Import retry policy", func() {
+	defaultPolicy := importRetryPolicy{maxRetries: -1, initialBackoff: 10 * time.Second, maxBackoff: 5 * time.Minute}
+	finished := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	message := "Unable to connect to http data source: connection reset by peer"
+
+	failedPod := func(pvc *corev1.PersistentVolumeClaim, created time.Time) *corev1.Pod {
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", nil)
+		pod.CreationTimestamp = metav1.NewTime(created)
+		pod.Spec.RestartPolicy = corev1.RestartPolicyNever
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode:   1,
+							Message:    message,
+							Reason:     "Error",
+							FinishedAt: metav1.NewTime(finished),
+						},
+					},
+				},
+			},
+		}
+		return pod
+	}
+
+	table.DescribeTable("Should set the retry policy of the import", func(config *cdiv1.ImportRetryPolicy, annotations map[string]string, expected importRetryPolicy, expectedSet bool) {
+		pvc := cc.CreatePvc("testPvc1", "default", annotations, nil)
+		cdiConfig := cc.MakeEmptyCDIConfigSpec(common.ConfigName)
+		cdiConfig.Spec.ImportRetryPolicy = config
+		policy, set := importRetryPolicyFor(pvc, cdiConfig)
+		Expect(set).To(Equal(expectedSet))
+		Expect(policy).To(Equal(expected))
+	},
+		table.Entry("restarting the importer pod by the kubelet by default", nil, nil, defaultPolicy, false),
+		table.Entry("from the CDIConfig",
+			&cdiv1.ImportRetryPolicy{MaxRetries: pointer.Int32(3), InitialBackoff: &metav1.Duration{Duration: time.Second}}, nil,
+			importRetryPolicy{maxRetries: 3, initialBackoff: time.Second, maxBackoff: 5 * time.Minute}, true),
+		table.Entry("from the annotations of the PVC",
+			nil, map[string]string{cc.AnnImportMaxRetries: "0", cc.AnnImportMaxBackoff: "1h"},
+			importRetryPolicy{maxRetries: 0, initialBackoff: 10 * time.Second, maxBackoff: time.Hour}, true),
+		table.Entry("from the annotations of the PVC overriding the CDIConfig",
+			&cdiv1.ImportRetryPolicy{MaxRetries: pointer.Int32(3), MaxBackoff: &metav1.Duration{Duration: time.Minute}}, map[string]string{cc.AnnImportMaxRetries: "20", cc.AnnImportInitialBackoff: "30s"},
+			importRetryPolicy{maxRetries: 20, initialBackoff: 30 * time.Second, maxBackoff: time.Minute}, true),
+		table.Entry("ignoring invalid annotations", nil, map[string]string{cc.AnnImportMaxRetries: "-1", cc.AnnImportInitialBackoff: "soon"}, defaultPolicy, false),
+	)
+
+	table.DescribeTable("Should double the backoff up to its cap", func(retries int, expected time.Duration) {
+		policy := importRetryPolicy{maxRetries: -1, initialBackoff: 10 * time.Second, maxBackoff: time.Minute}
+		Expect(policy.backoff(retries)).To(Equal(expected))
+	},
+		table.Entry("before the first retry", 0, 10*time.Second),
+		table.Entry("before the second retry", 1, 20*time.Second),
+		table.Entry("before the third retry", 2, 40*time.Second),
+		table.Entry("once capped", 3, time.Minute),
+		table.Entry("long after it was capped", 100, time.Minute),
+	)
+
+	It("Should not let the kubelet restart the importer pod of an import with a retry policy", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnImportPod: "importer-testPvc1"}, nil, corev1.ClaimBound)
+		reconciler := createImportReconciler(pvc)
+		Expect(reconciler.createImporterPod(pvc)).To(Succeed())
+		pod := &corev1.Pod{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyOnFailure))
+		Expect(reconciler.client.Delete(context.TODO(), pod)).To(Succeed())
+
+		pvc.Annotations[cc.AnnImportMaxRetries] = "3"
+		Expect(reconciler.createImporterPod(pvc)).To(Succeed())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+	})
+
+	It("Should replace the failed importer pod after the backoff of the retry policy", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodRunning), cc.AnnImportMaxRetries: "3", cc.AnnPodRestarts: "1"}, nil, corev1.ClaimBound)
+		pod := failedPod(pvc, finished.Add(-time.Minute))
+		reconciler := createImportReconciler(pvc, pod)
+		err := reconciler.updatePvcFromPod(pvc, pod, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.GetAnnotations()[cc.AnnPodPhase]).To(BeEquivalentTo(corev1.PodFailed))
+		Expect(resPvc.GetAnnotations()[cc.AnnPodRestarts]).To(Equal("2"))
+		Expect(resPvc.GetAnnotations()[cc.AnnImportRetryAfter]).To(Equal("2023-03-01T12:00:20Z"))
+		Expect(resPvc.GetAnnotations()[cc.AnnImportLastFailure]).To(Equal(message))
+		Expect(resPvc.GetAnnotations()).ToNot(HaveKey(cc.AnnImportTerminalError))
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring(message))
+		By("Checking the failed pod has been deleted")
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: "default"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		By("Checking the failure is counted once while the pod is being deleted")
+		err = reconciler.updatePvcFromPod(resPvc, pod, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.GetAnnotations()[cc.AnnPodRestarts]).To(Equal("2"))
+		Expect(resPvc.GetAnnotations()[cc.AnnImportRetryAfter]).To(Equal("2023-03-01T12:00:20Z"))
+	})
+
+	It("Should fail the import once it was retried as many times as the retry policy allows", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnPodPhase: string(corev1.PodRunning), cc.AnnImportMaxRetries: "2", cc.AnnPodRestarts: "2", cc.AnnImportRetryAfter: "2023-03-01T11:58:00Z"}, nil, corev1.ClaimBound)
+		pod := failedPod(pvc, finished.Add(-time.Minute))
+		reconciler := createImportReconciler(pvc, pod)
+		err := reconciler.updatePvcFromPod(pvc, pod, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.GetAnnotations()[cc.AnnPodPhase]).To(BeEquivalentTo(corev1.PodFailed))
+		Expect(resPvc.GetAnnotations()[cc.AnnPodRestarts]).To(Equal("2"))
+		Expect(resPvc.GetAnnotations()[cc.AnnImportTerminalError]).To(Equal("import failed after 2 retries: " + message))
+		Expect(resPvc.GetAnnotations()[cc.AnnRunningConditionReason]).To(Equal(cc.RetryLimitExceeded))
+		Expect(resPvc.GetAnnotations()[cc.AnnImportLastFailure]).To(Equal(message))
+		Expect(isImportTerminallyFailed(resPvc)).To(BeTrue())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: "default"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should wait for the backoff before creating the importer pod retrying the import", func() {
+		retryAfter := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: testEndPoint, cc.AnnImportPod: "importer-testPvc1", cc.AnnPodPhase: string(corev1.PodFailed), cc.AnnImportRetryAfter: retryAfter}, nil, corev1.ClaimBound)
+		reconciler := createImportReconciler(pvc)
+		result, err := reconciler.reconcilePvc(pvc, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 59*time.Minute))
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		pvc.Annotations[cc.AnnImportRetryAfter] = time.Now().Add(-time.Second).UTC().Format(time.RFC3339)
+		_, err = reconciler.reconcilePvc(pvc, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, &corev1.Pod{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("NFS source", func() {
+	const nfsURL = "nfs://nfs.example.com/exports/images/disk.qcow2"
+
+	// pendingPod returns the importer pod of the PVC scheduled at scheduled, its container waiting for the NFS export
+	pendingPod := func(pvc *corev1.PersistentVolumeClaim, scheduled time.Time) *corev1.Pod {
+		pod := cc.CreateImporterTestPod(pvc, "testPvc1", nil)
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{
+				{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(scheduled),
+				},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+					},
+				},
+			},
+		}
+		return pod
+	}
+
+	table.DescribeTable("Should set the mount options and the mount timeout", func(config *cdiv1.NFSConfig, expectedOptions []string, expectedTimeout time.Duration) {
+		cdiConfig := cc.MakeEmptyCDIConfigSpec(common.ConfigName)
+		cdiConfig.Spec.NFS = config
+		options, timeout := nfsMountConfigFor(cdiConfig)
+		Expect(options).To(Equal(expectedOptions))
+		Expect(timeout).To(Equal(expectedTimeout))
+	},
+		table.Entry("with a soft mount by default", nil, []string{"soft", "timeo=100", "retrans=3"}, 5*time.Minute),
+		table.Entry("from the CDIConfig",
+			&cdiv1.NFSConfig{MountOptions: []string{"vers=4.1", "hard"}, MountTimeout: &metav1.Duration{Duration: time.Minute}},
+			[]string{"vers=4.1", "hard"}, time.Minute),
+		table.Entry("always read-only",
+			&cdiv1.NFSConfig{MountOptions: []string{"rw", "vers=3"}},
+			[]string{"vers=3"}, 5*time.Minute),
+	)
+
+	It("Should mount the directory of the file read-only in the importer pod", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: nfsURL, cc.AnnSource: cc.SourceNFS, cc.AnnImportPod: "importer-testPvc1"}, nil, corev1.ClaimBound)
+		reconciler := createImportReconciler(pvc)
+		Expect(reconciler.createImporterPod(pvc)).To(Succeed())
+
+		pv := &corev1.PersistentVolume{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "default.testPvc1-nfs-source"}, pv)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pv.Spec.NFS).To(Equal(&corev1.NFSVolumeSource{Server: "nfs.example.com", Path: "/exports/images", ReadOnly: true}))
+		Expect(pv.Spec.MountOptions).To(Equal([]string{"soft", "timeo=100", "retrans=3"}))
+		Expect(pv.Spec.PersistentVolumeReclaimPolicy).To(Equal(corev1.PersistentVolumeReclaimRetain))
+		Expect(pv.Spec.ClaimRef.Namespace).To(Equal("default"))
+		Expect(pv.Spec.ClaimRef.Name).To(Equal("testPvc1-nfs-source"))
+
+		claim := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1-nfs-source", Namespace: "default"}, claim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(claim.Spec.VolumeName).To(Equal(pv.Name))
+		Expect(*claim.Spec.StorageClassName).To(BeEmpty())
+		Expect(metav1.IsControlledBy(claim, pvc)).To(BeTrue())
+
+		pod := &corev1.Pod{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "importer-testPvc1", Namespace: "default"}, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: fileSourceVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim.Name, ReadOnly: true},
+			},
+		}))
+		Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: common.ImporterFileSourceDirVar, Value: common.ImporterFileSourceDir}))
+	})
+
+	It("Should fail the import when the importer pod could not mount the NFS export in time", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: nfsURL, cc.AnnSource: cc.SourceNFS, cc.AnnImportPod: "importer-testPvc1"}, nil, corev1.ClaimBound)
+		reconciler := createImportReconciler(pvc)
+		Expect(reconciler.createImporterPod(pvc)).To(Succeed())
+		pod := pendingPod(pvc, time.Now().Add(-10*time.Minute))
+		Expect(reconciler.client.Update(context.TODO(), pod)).To(Succeed())
+
+		err := reconciler.updatePvcFromPod(pvc, pod, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		resPvc := &corev1.PersistentVolumeClaim{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1", Namespace: "default"}, resPvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resPvc.GetAnnotations()[cc.AnnPodPhase]).To(BeEquivalentTo(corev1.PodFailed))
+		Expect(resPvc.GetAnnotations()[cc.AnnImportTerminalError]).To(Equal("Unable to mount the NFS export of " + nfsURL + " within 5m0s"))
+		Expect(resPvc.GetAnnotations()[cc.AnnRunningConditionReason]).To(Equal(cc.NFSMountTimeout))
+		event := <-reconciler.recorder.(*record.FakeRecorder).Events
+		Expect(event).To(ContainSubstring("Unable to mount the NFS export"))
+		By("Checking the pod, the claim and the volume of the NFS export have been deleted")
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: "default"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "testPvc1-nfs-source", Namespace: "default"}, &corev1.PersistentVolumeClaim{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "default.testPvc1-nfs-source"}, &corev1.PersistentVolume{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should wait for the importer pod mounting the NFS export", func() {
+		pvc := cc.CreatePvcInStorageClass("testPvc1", "default", &testStorageClass, map[string]string{cc.AnnEndpoint: nfsURL, cc.AnnSource: cc.SourceNFS, cc.AnnImportPod: "importer-testPvc1"}, nil, corev1.ClaimBound)
+		pod := pendingPod(pvc, time.Now().Add(-time.Minute))
+		reconciler := createImportReconciler(pvc, pod)
+		err := reconciler.updatePvcFromPod(pvc, pod, reconciler.log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.GetAnnotations()[cc.AnnPodPhase]).To(BeEquivalentTo(corev1.PodPending))
+		Expect(isImportTerminallyFailed(pvc)).To(BeFalse())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: "default"}, &corev1.Pod{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Should delete the volume of the NFS export once the PVC is deleted", func() {
+		pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "default.testPvc1-nfs-source"}}
+		reconciler := createImportReconciler(pv)
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "testPvc1", Namespace: "default"}})
+		Expect(err).ToNot(HaveOccurred())
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: pv.Name}, &corev1.PersistentVolume{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
 })
 
 var _ = Describe("Import test env", func() {
@@ -852,9 +1787,406 @@ var _ = Describe("Import test env", func() {
 			currentCheckpoint:  "",
 			previousCheckpoint: "",
 			finalCheckpoint:    "",
+			archiveEntry:       "",
 			preallocation:      false}
 		Expect(reflect.DeepEqual(makeImportEnv(testEnvVar, mockUID), createImportTestEnv(testEnvVar, mockUID))).To(BeTrue())
 	})
+
+	It("Should pass the VM moref of a VDDK source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint:    "https://vcenter.example.com",
+			cc.AnnSource:      cc.SourceVDDK,
+			cc.AnnMoref:       "vm-1234",
+			cc.AnnBackingFile: "[datastore1] vm/vm.vmdk",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.moref).To(Equal("vm-1234"))
+		Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterMoref,
+			Value: "vm-1234",
+		}))
+	})
+
+	It("Should pass the artifact media type of a registry source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint:                  "docker://registry.example.com/disk@sha256:8c9c2ae3f6c8ae1a4f4e71fa1a4f3ea0e7f8c1cb4fc2d8b1a1c7e2f1f57c9d4b",
+			cc.AnnSource:                    cc.SourceRegistry,
+			cc.AnnRegistryArtifactMediaType: "application/vnd.example.disk.v1+gzip",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.artifactMediaType).To(Equal("application/vnd.example.disk.v1+gzip"))
+		Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterRegistryArtifactMediaType,
+			Value: "application/vnd.example.disk.v1+gzip",
+		}))
+
+		By("Ignoring the annotation for other sources")
+		pvc.Annotations[cc.AnnSource] = cc.SourceHTTP
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.artifactMediaType).To(BeEmpty())
+	})
+
+	It("Should pass the platform of a registry source and the default platform of the CDIConfig", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint: "docker://registry.example.com/disk:latest",
+			cc.AnnSource:   cc.SourceRegistry,
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		for _, env := range makeImportEnv(podEnvVar, mockUID) {
+			Expect(env.Name).ToNot(Equal(common.ImporterRegistryPlatform))
+			Expect(env.Name).ToNot(Equal(common.ImporterRegistryDefaultPlatform))
+		}
+
+		cdiConfig := &cdiv1.CDIConfig{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		cdiConfig.Spec.RegistryPlatform = pointer.StringPtr("linux/amd64")
+		err = reconciler.client.Update(context.TODO(), cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		pvc.Annotations[cc.AnnRegistryPlatform] = "linux/arm64/v8"
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		env := makeImportEnv(podEnvVar, mockUID)
+		Expect(env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterRegistryPlatform,
+			Value: "linux/arm64/v8",
+		}))
+		Expect(env).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterRegistryDefaultPlatform,
+			Value: "linux/amd64",
+		}))
+
+		By("Ignoring the platforms for other sources")
+		pvc.Annotations[cc.AnnSource] = cc.SourceHTTP
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.registryPlatform).To(BeEmpty())
+		Expect(podEnvVar.defaultPlatform).To(BeEmpty())
+	})
+
+	It("Should pass the mirrors of an http source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint: "https://mirror1.example.com/disk.qcow2",
+			cc.AnnSource:   cc.SourceHTTP,
+			cc.AnnMirrors:  "https://mirror2.example.com/disk.qcow2 http://mirror3.example.com/disk.qcow2",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterMirrors,
+			Value: "https://mirror2.example.com/disk.qcow2 http://mirror3.example.com/disk.qcow2",
+		}))
+
+		By("Ignoring the annotation for other sources")
+		pvc.Annotations[cc.AnnSource] = cc.SourceS3
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.mirrors).To(BeEmpty())
+	})
+
+	It("Should pass the checksum of the source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint: "s3://bucket/disk.qcow2",
+			cc.AnnSource:   cc.SourceS3,
+			cc.AnnChecksum: "sha512:" + strings.Repeat("ab", 64),
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterChecksum,
+			Value: "sha512:" + strings.Repeat("ab", 64),
+		}))
+	})
+
+	It("Should mount the public keys and pass the signature of an http source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint:              "https://www.example.com/disk.qcow2",
+			cc.AnnSource:                cc.SourceHTTP,
+			cc.AnnImportPod:             "podName",
+			cc.AnnSignatureKeyConfigMap: "signing-keys",
+			cc.AnnSignatureURL:          "https://www.example.com/disk.qcow2.sig",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		podArgs := &importerPodArgs{
+			image:      testImage,
+			verbose:    "5",
+			pullPolicy: testPullPolicy,
+			podEnvVar:  podEnvVar,
+			pvc:        pvc,
+		}
+		pod, err := createImporterPod(reconciler.log, reconciler.client, podArgs, map[string]string{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Spec.Containers[0].Env).To(ContainElements(corev1.EnvVar{
+			Name:  common.ImporterSignatureKeyDirVar,
+			Value: common.ImporterSignatureKeyDir,
+		}, corev1.EnvVar{
+			Name:  common.ImporterSignatureURL,
+			Value: "https://www.example.com/disk.qcow2.sig",
+		}))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      signatureKeyVolumeName,
+			MountPath: common.ImporterSignatureKeyDir,
+			ReadOnly:  true,
+		}))
+		Expect(pod.Spec.Volumes).To(ContainElement(createConfigMapVolume(signatureKeyVolumeName, "signing-keys")))
+
+		By("Ignoring the annotations for other sources")
+		pvc.Annotations[cc.AnnSource] = cc.SourceS3
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.signatureKeys).To(BeEmpty())
+		Expect(podEnvVar.signatureURL).To(BeEmpty())
+	})
+
+	It("Should pass the syntax of the pattern ending the URL of an http source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint:   "https://www.example.com/images/fedora-*.qcow2",
+			cc.AnnSource:     cc.SourceHTTP,
+			cc.AnnURLPattern: "glob",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterURLPattern,
+			Value: "glob",
+		}))
+
+		By("Ignoring the annotation for other sources")
+		pvc.Annotations[cc.AnnSource] = cc.SourceS3
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.urlPattern).To(BeEmpty())
+	})
+
+	It("Should pass the concurrency of the download of an http source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint:        "https://www.example.com/disk.qcow2",
+			cc.AnnSource:          cc.SourceHTTP,
+			cc.AnnHTTPConcurrency: "8",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterHTTPConcurrency,
+			Value: "8",
+		}))
+
+		By("Ignoring the annotation for other sources")
+		pvc.Annotations[cc.AnnSource] = cc.SourceS3
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.httpConcurrency).To(BeEmpty())
+	})
+
+	It("Should pass the limits of the redirects of an http source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint:      "https://www.example.com/disk.qcow2",
+			cc.AnnSource:        cc.SourceHTTP,
+			cc.AnnMaxRedirects:  "3",
+			cc.AnnRedirectHosts: "www.example.com *.cdn.example.com",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElements(corev1.EnvVar{
+			Name:  common.ImporterMaxRedirects,
+			Value: "3",
+		}, corev1.EnvVar{
+			Name:  common.ImporterRedirectHosts,
+			Value: "www.example.com *.cdn.example.com",
+		}))
+
+		By("Ignoring the annotations for other sources")
+		pvc.Annotations[cc.AnnSource] = cc.SourceS3
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.maxRedirects).To(BeEmpty())
+		Expect(podEnvVar.redirectHosts).To(BeEmpty())
+	})
+
+	table.DescribeTable("Should pass the validators of the previous import", func(source, reimport string, passed bool) {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint:           "https://www.example.com/disk.qcow2",
+			cc.AnnSource:             source,
+			cc.AnnSourceETag:         `"v1"`,
+			cc.AnnSourceLastModified: "2023-03-01T12:30:00Z",
+		}, nil)
+		if reimport != "" {
+			pvc.Annotations[cc.AnnReimport] = reimport
+		}
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		validators := []corev1.EnvVar{{
+			Name:  common.ImporterSourceETag,
+			Value: `"v1"`,
+		}, {
+			Name:  common.ImporterSourceLastModified,
+			Value: "2023-03-01T12:30:00Z",
+		}}
+		if passed {
+			Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElements(validators))
+		} else {
+			Expect(makeImportEnv(podEnvVar, mockUID)).ToNot(ContainElement(validators[0]))
+			Expect(makeImportEnv(podEnvVar, mockUID)).ToNot(ContainElement(validators[1]))
+		}
+	},
+		table.Entry("of an http source re-imported if it changed", cc.SourceHTTP, cc.ReimportIfChanged, true),
+		table.Entry("of an S3 source re-imported if it changed", cc.SourceS3, cc.ReimportIfChanged, true),
+		table.Entry("but not of a source re-imported whether it changed or not", cc.SourceHTTP, cc.ReimportAlways, false),
+		table.Entry("but not without re-import", cc.SourceHTTP, "", false),
+		table.Entry("but not of other sources", cc.SourceGCS, cc.ReimportIfChanged, false),
+	)
+
+	It("Should pass the PVC of a file source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint: "images/disk.qcow2",
+			cc.AnnSource:   cc.SourceFile,
+			cc.AnnFilePVC:  "images",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.filePVC).To(Equal("images"))
+		Expect(podEnvVar.fileHostPath).To(BeEmpty())
+		Expect(podEnvVar.ep).To(Equal("images/disk.qcow2"))
+	})
+
+	table.DescribeTable("Should pass the filesystem of a blank image and its label", func(contentType string, passed bool) {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnSource:               cc.SourceNone,
+			cc.AnnContentType:          contentType,
+			cc.AnnBlankFilesystem:      "xfs",
+			cc.AnnBlankFilesystemLabel: "data",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		fsEnv := []corev1.EnvVar{
+			{Name: common.ImporterBlankFilesystem, Value: "xfs"},
+			{Name: common.ImporterBlankFilesystemLabel, Value: "data"},
+		}
+		if passed {
+			Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElements(fsEnv))
+		} else {
+			Expect(makeImportEnv(podEnvVar, mockUID)).ToNot(ContainElement(fsEnv[0]))
+		}
+	},
+		table.Entry("of kubevirt content", string(cdiv1.DataVolumeKubeVirt), true),
+		table.Entry("but not of archive content", string(cdiv1.DataVolumeArchive), false),
+	)
+
+	It("Should pass the image, the project and the region of a Glance source", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{
+			cc.AnnEndpoint:      "https://keystone.example.com:5000/v3",
+			cc.AnnSource:        cc.SourceGlance,
+			cc.AnnSecret:        "glance-secret",
+			cc.AnnGlanceImage:   "cirros",
+			cc.AnnGlanceProject: "c3f5d8e2a4b64b1e9f0a7d6c5b4a3f21",
+			cc.AnnGlanceRegion:  "RegionTwo",
+		}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		env := makeImportEnv(podEnvVar, mockUID)
+		Expect(env).To(ContainElements(corev1.EnvVar{
+			Name:  common.ImporterGlanceImage,
+			Value: "cirros",
+		}, corev1.EnvVar{
+			Name:  common.ImporterGlanceProject,
+			Value: "c3f5d8e2a4b64b1e9f0a7d6c5b4a3f21",
+		}, corev1.EnvVar{
+			Name:  common.ImporterGlanceRegion,
+			Value: "RegionTwo",
+		}))
+		// the application credential is passed as an access key
+		var keys []string
+		for _, e := range env {
+			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+				keys = append(keys, e.ValueFrom.SecretKeyRef.Key)
+			}
+		}
+		Expect(keys).To(ConsistOf(common.KeyAccess, common.KeySecret))
+	})
+
+	It("Should bound the resources of qemu-img with the defaults", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.qemuCacheMode).To(BeEmpty())
+		Expect(podEnvVar.qemuCoroutines).To(BeEmpty())
+		Expect(podEnvVar.qemuMemoryLimit).To(BeEmpty())
+		Expect(podEnvVar.qemuNiceness).To(Equal("10"))
+	})
+
+	It("Should bound the resources of qemu-img with the CDIConfig", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint}, nil)
+		reconciler := createImportReconciler(pvc)
+		cdiConfig := &cdiv1.CDIConfig{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		coroutines, niceness := int32(4), int32(0)
+		memoryLimit := resource.MustParse("512Mi")
+		cdiConfig.Spec.QemuImg = &cdiv1.QemuImgConfig{
+			CacheMode:   "none",
+			Coroutines:  &coroutines,
+			MemoryLimit: &memoryLimit,
+			Niceness:    &niceness,
+		}
+		err = reconciler.client.Update(context.TODO(), cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.qemuCacheMode).To(Equal("none"))
+		Expect(podEnvVar.qemuCoroutines).To(Equal("4"))
+		Expect(podEnvVar.qemuMemoryLimit).To(Equal("512Mi"))
+		Expect(podEnvVar.qemuNiceness).To(Equal("0"))
+	})
+
+	It("Should limit the bandwidth of the import with the CDIConfig, unless overridden by the PVC", func() {
+		pvc := cc.CreatePvc("testPvc1", "default", map[string]string{cc.AnnEndpoint: testEndPoint}, nil)
+		reconciler := createImportReconciler(pvc)
+		podEnvVar, err := reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.bandwidthLimit).To(BeEmpty())
+		for _, env := range makeImportEnv(podEnvVar, mockUID) {
+			Expect(env.Name).ToNot(Equal(common.ImporterBandwidthLimit))
+		}
+
+		cdiConfig := &cdiv1.CDIConfig{}
+		err = reconciler.client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		limit := resource.MustParse("100Mi")
+		cdiConfig.Spec.ImportBandwidthLimit = &limit
+		err = reconciler.client.Update(context.TODO(), cdiConfig)
+		Expect(err).ToNot(HaveOccurred())
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(makeImportEnv(podEnvVar, mockUID)).To(ContainElement(corev1.EnvVar{
+			Name:  common.ImporterBandwidthLimit,
+			Value: "100Mi",
+		}))
+
+		pvc.Annotations[cc.AnnImportBandwidthLimit] = "25M"
+		podEnvVar, err = reconciler.createImportEnvVar(pvc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podEnvVar.bandwidthLimit).To(Equal("25M"))
+	})
 })
 
 var _ = Describe("getSecretName", func() {
@@ -969,6 +2301,7 @@ var _ = Describe("getSource", func() {
 	pvcNoAnno := cc.CreatePvc("testPVCNoAnno", "default", nil, nil)
 	pvcNoneAnno := cc.CreatePvc("testPVCNoneAnno", "default", map[string]string{cc.AnnSource: cc.SourceNone}, nil)
 	pvcGlanceAnno := cc.CreatePvc("testPVCNoneAnno", "default", map[string]string{cc.AnnSource: cc.SourceGlance}, nil)
+	pvcFileAnno := cc.CreatePvc("testPVCFileAnno", "default", map[string]string{cc.AnnSource: cc.SourceFile}, nil)
 	pvcInvalidValue := cc.CreatePvc("testPVCInvalidValue", "default", map[string]string{cc.AnnSource: "iaminvalid"}, nil)
 	pvcRegistryAnno := cc.CreatePvc("testPVCRegistryAnno", "default", map[string]string{cc.AnnSource: cc.SourceRegistry}, nil)
 	pvcImageIOAnno := cc.CreatePvc("testPVCImageIOAnno", "default", map[string]string{cc.AnnSource: cc.SourceImageio}, nil)
@@ -981,6 +2314,7 @@ var _ = Describe("getSource", func() {
 		table.Entry("return none if none annotation provided", pvcNoneAnno, cc.SourceNone),
 		table.Entry("return http if no annotation provided", pvcNoAnno, cc.SourceHTTP),
 		table.Entry("return glance if glance annotation provided", pvcGlanceAnno, cc.SourceGlance),
+		table.Entry("return file if file annotation provided", pvcFileAnno, cc.SourceFile),
 		table.Entry("return http if invalid annotation provided", pvcInvalidValue, cc.SourceHTTP),
 		table.Entry("return registry if registry annotation provided", pvcRegistryAnno, cc.SourceRegistry),
 		table.Entry("return imageio if imageio annotation provided", pvcImageIOAnno, cc.SourceImageio),
@@ -1130,10 +2464,62 @@ func createImportTestEnv(podEnvVar *importPodEnvVar, uid string) []corev1.EnvVar
 			Name:  common.ImporterFinalCheckpoint,
 			Value: podEnvVar.finalCheckpoint,
 		},
+		{
+			Name:  common.ImporterArchiveEntry,
+			Value: podEnvVar.archiveEntry,
+		},
+		{
+			Name:  common.ImporterFlattenBackingChain,
+			Value: strconv.FormatBool(podEnvVar.flattenChain),
+		},
+		{
+			Name:  common.ImporterDiskFormat,
+			Value: podEnvVar.diskFormat,
+		},
+		{
+			Name:  common.ImporterCompressQcow2,
+			Value: strconv.FormatBool(podEnvVar.compressQcow2),
+		},
+		{
+			Name:  common.ImporterQcow2ClusterSize,
+			Value: podEnvVar.qcow2ClusterSize,
+		},
+		{
+			Name:  common.ImporterQcow2Compat,
+			Value: podEnvVar.qcow2Compat,
+		},
+		{
+			Name:  common.ImporterPreserveImageSize,
+			Value: strconv.FormatBool(podEnvVar.preserveImageSize),
+		},
+		{
+			Name:  common.ImporterSkipImageCheck,
+			Value: strconv.FormatBool(podEnvVar.skipImageCheck),
+		},
 		{
 			Name:  common.Preallocation,
 			Value: strconv.FormatBool(podEnvVar.preallocation),
 		},
+		{
+			Name:  common.PreallocationMode,
+			Value: podEnvVar.preallocationMode,
+		},
+		{
+			Name:  common.ImporterQemuCacheMode,
+			Value: podEnvVar.qemuCacheMode,
+		},
+		{
+			Name:  common.ImporterQemuCoroutines,
+			Value: podEnvVar.qemuCoroutines,
+		},
+		{
+			Name:  common.ImporterQemuMemoryLimit,
+			Value: podEnvVar.qemuMemoryLimit,
+		},
+		{
+			Name:  common.ImporterQemuNiceness,
+			Value: podEnvVar.qemuNiceness,
+		},
 	}
 
 	if podEnvVar.secretName != "" {
@@ -1164,12 +2550,17 @@ func createImportTestEnv(podEnvVar *importPodEnvVar, uid string) []corev1.EnvVar
 
 type FakeFeatureGates struct {
 	honorWaitForFirstConsumerEnabled bool
+	hostPathImportEnabled            bool
 }
 
 func (f *FakeFeatureGates) HonorWaitForFirstConsumerEnabled() (bool, error) {
 	return f.honorWaitForFirstConsumerEnabled, nil
 }
 
+func (f *FakeFeatureGates) HostPathImportEnabled() (bool, error) {
+	return f.hostPathImportEnabled, nil
+}
+
 func createPendingPvc(name, ns string, annotations, labels map[string]string) *v1.PersistentVolumeClaim {
 	return cc.CreatePvcInStorageClass(name, ns, nil, annotations, labels, v1.ClaimPending)
 }
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+	cdv "kubevirt.io/containerized-data-importer/pkg/controller/datavolume"
+	"kubevirt.io/containerized-data-importer/pkg/monitoring"
+)
+
+const (
+	// prometheusSourceLabel is the label of the import metrics holding the source type of the import, the endpoint
+	// of the source is never a label as it is unbounded
+	prometheusSourceLabel = "source"
+)
+
+var (
+	// ImportsStartedCounter counts the imports started, by source type
+	ImportsStartedCounter = newImportCounter(monitoring.ImportsStarted)
+	// ImportsSucceededCounter counts the imports which succeeded, by source type
+	ImportsSucceededCounter = newImportCounter(monitoring.ImportsSucceeded)
+	// ImportsFailedCounter counts the imports which failed terminally, by source type
+	ImportsFailedCounter = newImportCounter(monitoring.ImportsFailed)
+	// ImportedBytesCounter counts the bytes written by the imports which succeeded, by source type
+	ImportedBytesCounter = newImportCounter(monitoring.ImportedBytes)
+	// ImportDurationHistogram observes the duration of the imports which succeeded, by source type
+	ImportDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: monitoring.MetricOptsList[monitoring.ImportDuration].Name,
+			Help: monitoring.MetricOptsList[monitoring.ImportDuration].Help,
+			// from 10 seconds to more than 5 hours
+			Buckets: prometheus.ExponentialBuckets(10, 2, 12),
+		},
+		[]string{prometheusSourceLabel},
+	)
+	// ImportsInFlightGauge is the number of imports in progress
+	ImportsInFlightGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: monitoring.MetricOptsList[monitoring.ImportsInFlight].Name,
+			Help: monitoring.MetricOptsList[monitoring.ImportsInFlight].Help,
+		})
+	// ScratchSpaceAllocatedCounter counts the bytes requested by the scratch space PVCs created
+	ScratchSpaceAllocatedCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: monitoring.MetricOptsList[monitoring.ScratchSpaceAllocated].Name,
+			Help: monitoring.MetricOptsList[monitoring.ScratchSpaceAllocated].Help,
+		})
+
+	// importsInFlight holds the PVCs with an import in progress, the gauge is their number. The PVCs are tracked
+	// again by the reconciles which follow a restart of the controller.
+	importsInFlight = struct {
+		sync.Mutex
+		pvcs map[types.NamespacedName]struct{}
+	}{pvcs: map[types.NamespacedName]struct{}{}}
+)
+
+func newImportCounter(key monitoring.MetricsKey) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: monitoring.MetricOptsList[key].Name,
+			Help: monitoring.MetricOptsList[key].Help,
+		},
+		[]string{prometheusSourceLabel},
+	)
+}
+
+// RegisterMetrics registers the metrics reported by the CDI controllers
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{
+		IncompleteProfileGauge,
+		DataImportCronOutdatedGauge,
+		ImportsStartedCounter,
+		ImportsSucceededCounter,
+		ImportsFailedCounter,
+		ImportedBytesCounter,
+		ImportDurationHistogram,
+		ImportsInFlightGauge,
+		ScratchSpaceAllocatedCounter,
+		cdv.CloneDurationHistogram,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setImportInFlight records whether the import into the PVC is in progress
+func setImportInFlight(pvc types.NamespacedName, inFlight bool) {
+	importsInFlight.Lock()
+	defer importsInFlight.Unlock()
+	if inFlight {
+		importsInFlight.pvcs[pvc] = struct{}{}
+	} else {
+		delete(importsInFlight.pvcs, pvc)
+	}
+	ImportsInFlightGauge.Set(float64(len(importsInFlight.pvcs)))
+}
+
+// observeImportSucceeded reports the import into the PVC which succeeded, the bytes written are the size of the disk
+// image reported by the importer, or the capacity of the PVC when the image is written to a block volume
+func observeImportSucceeded(pvc *corev1.PersistentVolumeClaim) {
+	source := cc.GetSource(pvc)
+	ImportsSucceededCounter.WithLabelValues(source).Inc()
+	ImportDurationHistogram.WithLabelValues(source).Observe(time.Since(pvc.CreationTimestamp.Time).Seconds())
+	if bytes, err := strconv.ParseInt(pvc.GetAnnotations()[cc.AnnLogicalBytes], 10, 64); err == nil {
+		ImportedBytesCounter.WithLabelValues(source).Add(float64(bytes))
+	} else if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		ImportedBytesCounter.WithLabelValues(source).Add(float64(capacity.Value()))
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+
+	"kubevirt.io/containerized-data-importer/pkg/monitoring"
+)
+
+var _ = Describe("Controller metrics", func() {
+	It("Should register the metrics of the controllers once", func() {
+		registry := prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry)).To(Succeed())
+
+		err := RegisterMetrics(registry)
+		Expect(err).To(HaveOccurred())
+		Expect(err).To(BeAssignableToTypeOf(prometheus.AlreadyRegisteredError{}))
+	})
+
+	It("Should register the metrics under the names of the metrics list", func() {
+		registry := prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry)).To(Succeed())
+		ImportsStartedCounter.WithLabelValues("http")
+		ImportDurationHistogram.WithLabelValues("http")
+
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		var names []string
+		for _, family := range families {
+			names = append(names, family.GetName())
+		}
+		Expect(names).To(ContainElements(
+			monitoring.MetricOptsList[monitoring.ImportsStarted].Name,
+			monitoring.MetricOptsList[monitoring.ImportDuration].Name,
+			monitoring.MetricOptsList[monitoring.ImportsInFlight].Name,
+			monitoring.MetricOptsList[monitoring.ScratchSpaceAllocated].Name,
+			monitoring.MetricOptsList[monitoring.IncompleteProfile].Name,
+		))
+	})
+
+	It("Should report the number of imports in flight", func() {
+		first := types.NamespacedName{Namespace: "default", Name: "metrics-pvc1"}
+		second := types.NamespacedName{Namespace: "default", Name: "metrics-pvc2"}
+		initial := testutil.ToFloat64(ImportsInFlightGauge)
+
+		setImportInFlight(first, true)
+		setImportInFlight(first, true)
+		setImportInFlight(second, true)
+		Expect(testutil.ToFloat64(ImportsInFlightGauge)).To(Equal(initial + 2))
+
+		setImportInFlight(first, false)
+		setImportInFlight(second, false)
+		setImportInFlight(second, false)
+		Expect(testutil.ToFloat64(ImportsInFlightGauge)).To(Equal(initial))
+	})
+})
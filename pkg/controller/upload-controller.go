@@ -155,7 +155,7 @@ func (r *UploadReconciler) Reconcile(_ context.Context, req reconcile.Request) (
 }
 
 func (r *UploadReconciler) shouldReconcile(isUpload bool, isCloneTarget bool, pvc *v1.PersistentVolumeClaim, log logr.Logger) (bool, error) {
-	_, isImmediateBindingRequested := pvc.Annotations[AnnImmediateBinding]
+	_, isImmediateBindingRequested := pvc.Annotations[cc.AnnImmediateBinding]
 	waitForFirstConsumerEnabled, err := isWaitForFirstConsumerEnabled(isImmediateBindingRequested, r.featureGates)
 	if err != nil {
 		return false, err
@@ -574,15 +574,16 @@ func (r *UploadReconciler) makeUploadServiceSpec(name string, pvc *v1.Persistent
 func (r *UploadReconciler) createUploadPod(args UploadPodArgs) (*v1.Pod, error) {
 	ns := args.PVC.Namespace
 
-	podResourceRequirements, err := cc.GetDefaultPodResourceRequirements(r.client)
+	podResourceRequirements, err := cc.GetPodResourceRequirements(r.client, args.PVC)
 	if err != nil {
 		return nil, err
 	}
 
-	workloadNodePlacement, err := cc.GetWorkloadNodePlacement(r.client)
+	workloadNodePlacement, err := cc.GetPodNodePlacement(r.client, args.PVC)
 	if err != nil {
 		return nil, err
 	}
+	cc.RequireSelectedNode(workloadNodePlacement, args.PVC)
 
 	pod := r.makeUploadPodSpec(args, podResourceRequirements, workloadNodePlacement)
 	util.SetRecommendedLabels(pod, r.installerLabels, "cdi-controller")
@@ -839,7 +840,7 @@ func (r *UploadReconciler) makeUploadPodSpec(args UploadPodArgs, resourceRequire
 			NodeSelector:      workloadNodePlacement.NodeSelector,
 			Tolerations:       workloadNodePlacement.Tolerations,
 			Affinity:          workloadNodePlacement.Affinity,
-			PriorityClassName: cc.GetPriorityClass(args.PVC),
+			PriorityClassName: cc.GetPriorityClass(r.client, args.PVC),
 		},
 	}
 
@@ -20,6 +20,8 @@ import (
 	"context"
 	"crypto/rsa"
 	"encoding/json"
+	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -30,6 +32,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -53,9 +56,6 @@ const (
 	// AnnOwnerRef is used when owner is in a different namespace
 	AnnOwnerRef = cc.AnnAPIGroup + "/storage.ownerRef"
 
-	// AnnImmediateBinding provides a const to indicate whether immediate binding should be performed on the PV (overrides global config)
-	AnnImmediateBinding = cc.AnnAPIGroup + "/storage.bind.immediate.requested"
-
 	// PodRunningReason is const that defines the pod was started as a reason
 	PodRunningReason = "Pod is running"
 
@@ -78,7 +78,21 @@ const (
 )
 
 var (
-	vddkInfoMatch = regexp.MustCompile(`((.*; )|^)VDDK: (?P<info>{.*})`)
+	vddkInfoMatch          = regexp.MustCompile(`((.*; )|^)VDDK: (?P<info>{.*})`)
+	sourceDigestMatch      = regexp.MustCompile(common.SourceDigest + ` (sha256:[0-9a-f]{64})`)
+	payloadDigestMatch     = regexp.MustCompile(common.PayloadDigest + ` (sha256:[0-9a-f]{64})`)
+	destinationDigestMatch = regexp.MustCompile(common.DestinationDigest + ` (sha256:[0-9a-f]{64})`)
+	diskFormatMatch        = regexp.MustCompile(common.DiskFormat + ` ([a-z0-9]+)`)
+	sourceFormatMatch      = regexp.MustCompile(common.SourceFormat + ` ([a-z0-9]+)`)
+	qcow2OptionsMatch      = regexp.MustCompile(common.Qcow2Options + ` ([a-z_]+=[0-9a-z.]+(,[a-z_]+=[0-9a-z.]+)*)`)
+	preallocationMatch     = regexp.MustCompile(common.PreallocationModeApplied + ` ([a-z]+)`)
+	logicalBytesMatch      = regexp.MustCompile(common.LogicalBytes + ` ([0-9]+)`)
+	physicalBytesMatch     = regexp.MustCompile(common.PhysicalBytes + ` ([0-9]+)`)
+	sourceURLMatch         = regexp.MustCompile(common.SourceURL + ` ([^\s,]+)`)
+	signedByMatch          = regexp.MustCompile(common.SignedBy + ` ([0-9A-F]+)`)
+	sourceETagMatch        = regexp.MustCompile(common.SourceETag + ` ((W/)?"[^"]*")`)
+	lastModifiedMatch      = regexp.MustCompile(common.SourceLastModified + ` ([0-9TZ:.+-]+)`)
+	milestonesMatch        = regexp.MustCompile(common.ImportMilestones + ` ([A-Za-z0-9]+(,[A-Za-z0-9]+)*)`)
 )
 
 func checkPVC(pvc *v1.PersistentVolumeClaim, annotation string, log logr.Logger) bool {
@@ -169,8 +183,23 @@ func newScratchPersistentVolumeClaimSpec(pvc *v1.PersistentVolumeClaim, pod *v1.
 }
 
 // createScratchPersistentVolumeClaim creates and returns a pointer to a scratch PVC which is created based on the passed-in pvc and storage class name.
+// The size of the scratch PVC is the size of pvc multiplied by the scratch space size multiplier.
 func createScratchPersistentVolumeClaim(client client.Client, pvc *v1.PersistentVolumeClaim, pod *v1.Pod, name, storageClassName string, installerLabels map[string]string, recorder record.EventRecorder) (*v1.PersistentVolumeClaim, error) {
+	blockOnly, err := isBlockOnlyStorageClass(client, storageClassName)
+	if err != nil {
+		return nil, err
+	}
+	if blockOnly {
+		message := fmt.Sprintf("The scratch space storage class %s only provides Block volumes, scratch space requires a Filesystem volume, set another storage class with the scratchSpaceStorageClass of the CDIConfig or the %s annotation", storageClassName, cc.AnnScratchStorageClass)
+		recorder.Event(pvc, v1.EventTypeWarning, cc.ErrScratchSpaceBlockOnly, message)
+		return nil, errors.New(message)
+	}
+	multiplier, err := getScratchSpaceSizeMultiplier(client, pvc)
+	if err != nil {
+		return nil, err
+	}
 	scratchPvcSpec := newScratchPersistentVolumeClaimSpec(pvc, pod, name, storageClassName)
+	scratchPvcSpec.Spec.Resources = scaleStorageResources(pvc.Spec.Resources, multiplier)
 	util.SetRecommendedLabels(scratchPvcSpec, installerLabels, "cdi-controller")
 	if err := client.Create(context.TODO(), scratchPvcSpec); err != nil {
 		if cc.ErrQuotaExceeded(err) {
@@ -179,6 +208,8 @@ func createScratchPersistentVolumeClaim(client client.Client, pvc *v1.Persistent
 		if !k8serrors.IsAlreadyExists(err) {
 			return nil, errors.Wrap(err, "scratch PVC API create errored")
 		}
+	} else if size, ok := scratchPvcSpec.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+		ScratchSpaceAllocatedCounter.Add(float64(size.Value()))
 	}
 	scratchPvc := &v1.PersistentVolumeClaim{}
 	if err := client.Get(context.TODO(), types.NamespacedName{Name: scratchPvcSpec.Name, Namespace: pvc.Namespace}, scratchPvc); err != nil {
@@ -189,6 +220,61 @@ func createScratchPersistentVolumeClaim(client client.Client, pvc *v1.Persistent
 	return scratchPvc, nil
 }
 
+// isBlockOnlyStorageClass returns whether the StorageProfile of the storage class only provides Block volumes, on
+// which the scratch space cannot be mounted.
+func isBlockOnlyStorageClass(c client.Client, storageClassName string) (bool, error) {
+	if storageClassName == "" {
+		return false, nil
+	}
+	storageProfile := &cdiv1.StorageProfile{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: storageClassName}, storageProfile); err != nil {
+		return false, cc.IgnoreNotFound(err)
+	}
+	if len(storageProfile.Status.ClaimPropertySets) == 0 {
+		return false, nil
+	}
+	for _, cps := range storageProfile.Status.ClaimPropertySets {
+		if cps.VolumeMode == nil || *cps.VolumeMode == v1.PersistentVolumeFilesystem {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// getScratchSpaceSizeMultiplier returns the size of the scratch space of pvc relative to the size of pvc, set by the
+// scratch size multiplier annotation of pvc, or by the CDIConfig. It is 1 if the CDIConfig does not exist.
+func getScratchSpaceSizeMultiplier(c client.Client, pvc *v1.PersistentVolumeClaim) (float64, error) {
+	if value, ok := pvc.Annotations[cc.AnnScratchSizeMultiplier]; ok {
+		return cc.ParseScratchSpaceSizeMultiplier(value)
+	}
+	config := &cdiv1.CDIConfig{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, config); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return 1, nil
+		}
+		return 0, err
+	}
+	if config.Status.ScratchSpaceSizeMultiplier == "" {
+		return 1, nil
+	}
+	return cc.ParseScratchSpaceSizeMultiplier(config.Status.ScratchSpaceSizeMultiplier)
+}
+
+// scaleStorageResources returns the resource requirements with their storage request and limit multiplied by
+// multiplier, rounded up to the byte.
+func scaleStorageResources(resources v1.ResourceRequirements, multiplier float64) v1.ResourceRequirements {
+	scaled := *resources.DeepCopy()
+	if multiplier == 1 {
+		return scaled
+	}
+	for _, list := range []v1.ResourceList{scaled.Requests, scaled.Limits} {
+		if size, ok := list[v1.ResourceStorage]; ok {
+			list[v1.ResourceStorage] = *resource.NewQuantity(int64(math.Ceil(float64(size.Value())*multiplier)), size.Format)
+		}
+	}
+	return scaled
+}
+
 // GetFilesystemOverhead determines the filesystem overhead defined in CDIConfig for this PVC's volumeMode and storageClass.
 func GetFilesystemOverhead(client client.Client, pvc *v1.PersistentVolumeClaim) (cdiv1.Percent, error) {
 	if cc.GetVolumeMode(pvc) != v1.PersistentVolumeFilesystem {
@@ -200,10 +286,14 @@ func GetFilesystemOverhead(client client.Client, pvc *v1.PersistentVolumeClaim)
 
 // GetScratchPvcStorageClass tries to determine which storage class to use for use with a scratch persistent
 // volume claim. The order of preference is the following:
-// 1. Defined value in CDI Config field scratchSpaceStorageClass.
-// 2. If 1 is not available, use the storage class name of the original pvc that will own the scratch pvc.
-// 3. If none of those are available, return blank.
+// 1. The scratch storage class annotation of the original pvc.
+// 2. Defined value in CDI Config field scratchSpaceStorageClass.
+// 3. If 2 is not available, use the storage class name of the original pvc that will own the scratch pvc.
+// 4. If none of those are available, return blank.
 func GetScratchPvcStorageClass(client client.Client, pvc *v1.PersistentVolumeClaim) string {
+	if storageClassName := pvc.Annotations[cc.AnnScratchStorageClass]; storageClassName != "" {
+		return storageClassName
+	}
 	config := &cdiv1.CDIConfig{}
 	if err := client.Get(context.TODO(), types.NamespacedName{Name: common.ConfigName}, config); err != nil {
 		return ""
@@ -305,10 +395,56 @@ func setAnnotationsFromPodWithPrefix(anno map[string]string, pod *v1.Pod, prefix
 			if strings.Contains(containerState.Terminated.Message, common.PreallocationApplied) {
 				anno[cc.AnnPreallocationApplied] = "true"
 			}
+			setDigestAnnotation(anno, cc.AnnSourceDigest, sourceDigestMatch, containerState.Terminated.Message)
+			setDigestAnnotation(anno, cc.AnnPayloadDigest, payloadDigestMatch, containerState.Terminated.Message)
+			setDigestAnnotation(anno, cc.AnnDestinationDigest, destinationDigestMatch, containerState.Terminated.Message)
+			if m := diskFormatMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnDiskFormat] = m[1]
+			}
+			if m := sourceFormatMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnSourceFormat] = m[1]
+			}
+			if m := qcow2OptionsMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnQcow2Options] = m[1]
+			}
+			if m := preallocationMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnPreallocationMode] = m[1]
+			}
+			if m := logicalBytesMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnLogicalBytes] = m[1]
+			}
+			if m := physicalBytesMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnPhysicalBytes] = m[1]
+			}
+			if m := sourceURLMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnSourceURL] = m[1]
+			}
+			if m := signedByMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnSignedBy] = m[1]
+			}
+			if m := sourceETagMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnSourceETag] = m[1]
+			}
+			if m := lastModifiedMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnSourceLastModified] = m[1]
+			}
+			if m := milestonesMatch.FindStringSubmatch(containerState.Terminated.Message); m != nil {
+				anno[cc.AnnImportMilestones] = m[1]
+			}
+			if strings.Contains(containerState.Terminated.Message, common.SourceUnchanged) {
+				anno[cc.AnnSourceUnchanged] = "true"
+			}
 		}
 	}
 }
 
+// setDigestAnnotation sets the annotation to the digest matched in the termination message, if any.
+func setDigestAnnotation(anno map[string]string, key string, match *regexp.Regexp, terminationMessage string) {
+	if m := match.FindStringSubmatch(terminationMessage); m != nil {
+		anno[key] = m[1]
+	}
+}
+
 func simplifyKnownMessage(msg string) string {
 	if strings.Contains(msg, "is larger than the reported available") ||
 		strings.Contains(msg, "no space left on device") ||
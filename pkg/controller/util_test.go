@@ -84,6 +84,12 @@ var _ = Describe("GetScratchPVCStorageClass", func() {
 		Expect(GetScratchPvcStorageClass(client, pvc)).To(Equal(storageClassName))
 	})
 
+	It("Should return the storage class of the scratch storage class annotation over the CDIConfig", func() {
+		client := CreateClient(createCDIConfigWithStorageClass(common.ConfigName, "test1"))
+		pvc := CreatePvc("test", "test", map[string]string{AnnScratchStorageClass: "local-nvme"}, nil)
+		Expect(GetScratchPvcStorageClass(client, pvc)).To(Equal("local-nvme"))
+	})
+
 	It("Should return storage class from pvc", func() {
 		storageClassName := "storageClass"
 		client := CreateClient(createCDIConfigWithStorageClass(common.ConfigName, ""))
@@ -217,6 +223,231 @@ var _ = Describe("setAnnotationsFromPod", func() {
 		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
 		Expect(result[AnnPreallocationApplied]).To(Equal("true"))
 	})
+
+	It("Should set the digests of the import", func() {
+		sourceDigest := "sha256:" + strings.Repeat("a", 64)
+		payloadDigest := "sha256:" + strings.Repeat("b", 64)
+		destinationDigest := "sha256:" + strings.Repeat("c", 64)
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.SourceDigest + " " + sourceDigest + ", " + common.PayloadDigest + " " + payloadDigest + ", " + common.DestinationDigest + " " + destinationDigest,
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnSourceDigest]).To(Equal(sourceDigest))
+		Expect(result[AnnPayloadDigest]).To(Equal(payloadDigest))
+		Expect(result[AnnDestinationDigest]).To(Equal(destinationDigest))
+	})
+
+	It("Should set the disk format", func() {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.DiskFormat + " qcow2",
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnDiskFormat]).To(Equal("qcow2"))
+	})
+
+	It("Should set the format of the source", func() {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.SourceFormat + " iso",
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnSourceFormat]).To(Equal("iso"))
+	})
+
+	It("Should set the milestones reached by the import", func() {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.LogicalBytes + " 1048576, " + common.PhysicalBytes + " 4096, " + common.ImportMilestones + " " +
+								common.ImportMilestoneSourceConnected + "," + common.ImportMilestoneConversionStarted + "," + common.ImportMilestoneVerificationPassed,
+							Reason: "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnImportMilestones]).To(Equal("SourceConnected,ConversionStarted,VerificationPassed"))
+	})
+
+	It("Should set the URL which served the data", func() {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.SourceURL + " https://mirror.example.com/disk.qcow2, " + common.LogicalBytes + " 1048576",
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnSourceURL]).To(Equal("https://mirror.example.com/disk.qcow2"))
+	})
+	It("Should set the fingerprint of the key which signed the data", func() {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.SourceURL + " https://www.example.com/disk.qcow2, " + common.SignedBy + " 0123456789ABCDEF0123456789ABCDEF01234567",
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnSignedBy]).To(Equal("0123456789ABCDEF0123456789ABCDEF01234567"))
+	})
+
+	table.DescribeTable("Should set the validators of the source", func(message string, unchanged bool) {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: message,
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnSourceETag]).To(Equal(`W/"5f3a-1, 2"`))
+		Expect(result[AnnSourceLastModified]).To(Equal("2023-03-01T12:30:00Z"))
+		if unchanged {
+			Expect(result[AnnSourceUnchanged]).To(Equal("true"))
+		} else {
+			Expect(result).ToNot(HaveKey(AnnSourceUnchanged))
+		}
+	},
+		table.Entry("of an import", "Import Complete, "+common.SourceURL+" https://www.example.com/disk.qcow2, "+common.SourceETag+` W/"5f3a-1, 2", `+common.SourceLastModified+" 2023-03-01T12:30:00Z, "+common.LogicalBytes+" 1048576", false),
+		table.Entry("of an unchanged source", "Import Complete, "+common.SourceUnchanged+", "+common.SourceETag+` W/"5f3a-1, 2", `+common.SourceLastModified+" 2023-03-01T12:30:00Z", true),
+	)
+
+	It("Should set the qcow2 options", func() {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.DiskFormat + " qcow2, " + common.Qcow2Options + " cluster_size=2097152,compat=0.10, " + common.LogicalBytes + " 1048576",
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnQcow2Options]).To(Equal("cluster_size=2097152,compat=0.10"))
+	})
+
+	It("Should set the qcow2 options of compressed clusters", func() {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.DiskFormat + " qcow2, " + common.Qcow2Options + " cluster_size=65536,compat=1.1,compressed=true",
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnQcow2Options]).To(Equal("cluster_size=65536,compat=1.1,compressed=true"))
+	})
+
+	It("Should set the preallocation mode applied", func() {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.PreallocationApplied + ", " + common.PreallocationModeApplied + " full",
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnPreallocationApplied]).To(Equal("true"))
+		Expect(result[AnnPreallocationMode]).To(Equal("full"))
+	})
+
+	It("Should set the logical and physical bytes written", func() {
+		result := make(map[string]string)
+		testPod := CreateImporterTestPod(CreatePvc("test", metav1.NamespaceDefault, nil, nil), "test", nil)
+		testPod.Status = v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							Message: "Import Complete, " + common.LogicalBytes + " 107374182400, " + common.PhysicalBytes + " 1048576",
+							Reason:  "Completed",
+						},
+					},
+				},
+			},
+		}
+		setAnnotationsFromPodWithPrefix(result, testPod, AnnRunningCondition)
+		Expect(result[AnnLogicalBytes]).To(Equal("107374182400"))
+		Expect(result[AnnPhysicalBytes]).To(Equal("1048576"))
+	})
 })
 
 var _ = Describe("GetPreallocation", func() {
@@ -256,6 +487,27 @@ var _ = Describe("GetPreallocation", func() {
 	})
 })
 
+var _ = Describe("GetDiskFormat", func() {
+	It("Should return the disk format of the DataVolume if specified", func() {
+		client := CreateClient(createCDIConfigWithGlobalDiskFormat(cdiv1.DataVolumeDiskFormatRaw))
+		dv := createDataVolumeWithStorageClass("test-dv", "test-ns", "test-class")
+		dv.Spec.DiskFormat = cdiv1.DataVolumeDiskFormatQcow2
+		Expect(GetDiskFormat(client, dv)).To(Equal(cdiv1.DataVolumeDiskFormatQcow2))
+	})
+
+	It("Should return the global disk format if not defined in DV", func() {
+		client := CreateClient(createCDIConfigWithGlobalDiskFormat(cdiv1.DataVolumeDiskFormatQcow2))
+		dv := createDataVolumeWithStorageClass("test-dv", "test-ns", "test-class")
+		Expect(GetDiskFormat(client, dv)).To(Equal(cdiv1.DataVolumeDiskFormatQcow2))
+	})
+
+	It("Should be raw when neither DV nor Config defines the disk format", func() {
+		client := CreateClient(createCDIConfig("test"))
+		dv := createDataVolumeWithStorageClass("test-dv", "test-ns", "test-class")
+		Expect(GetDiskFormat(client, dv)).To(Equal(cdiv1.DataVolumeDiskFormatRaw))
+	})
+})
+
 var _ = Describe("ValidateClone", func() {
 	sourcePvc := CreatePvc("testPVC", "default", map[string]string{}, nil)
 	blockVM := corev1.PersistentVolumeBlock
@@ -369,6 +621,17 @@ var _ = Describe("HandleFailedPod", func() {
 		table.Entry("quota error", "exceeded quota:", ErrExceededQuota),
 	)
 
+	It("Should record an event naming the priority class if the pod is rejected because it does not exist", func() {
+		cl := CreateClient(pvc)
+		rec := record.NewFakeRecorder(10)
+		err := HandleFailedPod(errors.New(`pods "test-pod" is forbidden: no PriorityClass with name importers was found`), podName, pvc, rec, cl)
+		Expect(err).To(HaveOccurred())
+
+		event := <-rec.Events
+		Expect(event).To(ContainSubstring(ErrPriorityClassNotFound))
+		Expect(event).To(ContainSubstring(fmt.Sprintf(MessageErrPriorityClassNotFound, podName, "importers")))
+	})
+
 	It("Should return a different error if the PVC isn't able to update, but still record the event", func() {
 		// Create a mock reconciler to record the events
 		cl := CreateClient()
@@ -557,6 +820,12 @@ func createCDIConfigWithGlobalPreallocation(globalPreallocation bool) *cdiv1.CDI
 	}
 }
 
+func createCDIConfigWithGlobalDiskFormat(diskFormat cdiv1.DataVolumeDiskFormat) *cdiv1.CDIConfig {
+	config := createCDIConfigWithGlobalPreallocation(false)
+	config.Status.DiskFormat = diskFormat
+	return config
+}
+
 func createCDIWithWorkload(name, uid string) *cdiv1.CDI {
 	return &cdiv1.CDI{
 		ObjectMeta: metav1.ObjectMeta{
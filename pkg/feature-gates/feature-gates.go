@@ -14,12 +14,17 @@ import (
 const (
 	// HonorWaitForFirstConsumer - if enabled will not schedule worker pods on a storage with WaitForFirstConsumer binding mode
 	HonorWaitForFirstConsumer = "HonorWaitForFirstConsumer"
+
+	// HostPathImport - if enabled allows the DataVolumes to import a file of a directory of the node, mounted in the importer pod
+	HostPathImport = "HostPathImport"
 )
 
 // FeatureGates is a util for determining whether an optional feature is enabled or not.
 type FeatureGates interface {
 	// HonorWaitForFirstConsumerEnabled - see the HonorWaitForFirstConsumer const
 	HonorWaitForFirstConsumerEnabled() (bool, error)
+	// HostPathImportEnabled - see the HostPathImport const
+	HostPathImportEnabled() (bool, error)
 }
 
 // CDIConfigFeatureGates is a util for determining whether an optional feature is enabled or not.
@@ -59,3 +64,8 @@ func (f *CDIConfigFeatureGates) getConfig() ([]string, error) {
 func (f *CDIConfigFeatureGates) HonorWaitForFirstConsumerEnabled() (bool, error) {
 	return f.isFeatureGateEnabled(HonorWaitForFirstConsumer)
 }
+
+// HostPathImportEnabled - see the HostPathImport const
+func (f *CDIConfigFeatureGates) HostPathImportEnabled() (bool, error) {
+	return f.isFeatureGateEnabled(HostPathImport)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	cpioNewcMagic = "070701"
+	cpioCrcMagic  = "070702"
+	cpioTrailer   = "TRAILER!!!"
+	// cpioHeaderSize is the size of a newc header: the magic followed by 13 fields of 8 hex digits
+	cpioHeaderSize = 110
+
+	cpioModeType    = 0170000
+	cpioModeRegular = 0100000
+)
+
+type cpioHeader struct {
+	name     string
+	mode     uint32
+	fileSize int64
+	check    uint32
+	crc      bool
+}
+
+// CpioReader streams the single regular file of a newc or crc format cpio archive. Directories and
+// other special entries are skipped. Reading fails once the archive turns out to hold a second
+// regular file, or when the checksum of a crc format entry does not match.
+type CpioReader struct {
+	r      io.Reader
+	hdr    *cpioHeader
+	remain int64
+	sum    uint32
+	done   bool
+	err    error
+}
+
+// NewCpioReader returns a reader of the file stored in the passed in cpio archive.
+func NewCpioReader(r io.Reader) (*CpioReader, error) {
+	cr := &CpioReader{r: r}
+	var skipped []string
+	for {
+		hdr, err := cr.next()
+		if err != nil {
+			return nil, err
+		}
+		if hdr == nil {
+			return nil, NewFormatError(ErrUnsupportedFormat, "cpio",
+				errors.Errorf("cpio archive does not contain a regular file, entries: %s", strings.Join(skipped, ", ")))
+		}
+		if hdr.mode&cpioModeType == cpioModeRegular {
+			klog.V(2).Infof("cpio: extracting %q\n", hdr.name)
+			cr.hdr = hdr
+			cr.remain = hdr.fileSize
+			return cr, nil
+		}
+		skipped = append(skipped, hdr.name)
+		if err := cr.skipData(hdr.fileSize); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (cr *CpioReader) Read(p []byte) (int, error) {
+	if cr.err != nil {
+		return 0, cr.err
+	}
+	if cr.remain == 0 {
+		cr.err = cr.finish()
+		return 0, cr.err
+	}
+	if int64(len(p)) > cr.remain {
+		p = p[:cr.remain]
+	}
+	n, err := cr.r.Read(p)
+	for _, b := range p[:n] {
+		cr.sum += uint32(b)
+	}
+	cr.remain -= int64(n)
+	if err == io.EOF && cr.remain > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil && err != io.EOF {
+		cr.err = errors.Wrap(err, "could not read cpio entry")
+		return n, cr.err
+	}
+	return n, nil
+}
+
+// finish verifies the checksum of the file, and checks that the rest of the archive holds no other
+// regular file. It returns io.EOF if the archive is valid.
+func (cr *CpioReader) finish() error {
+	if cr.done {
+		return io.EOF
+	}
+	cr.done = true
+	if cr.hdr.crc && cr.sum != cr.hdr.check {
+		return NewFormatError(ErrCorruptArchive, "cpio",
+			errors.Errorf("cpio checksum mismatch for %q: expected %08x, got %08x", cr.hdr.name, cr.hdr.check, cr.sum))
+	}
+	if err := cr.skipPadding(cr.hdr.fileSize); err != nil {
+		return err
+	}
+	var others []string
+	for {
+		hdr, err := cr.next()
+		if err != nil {
+			return err
+		}
+		if hdr == nil {
+			break
+		}
+		if hdr.mode&cpioModeType == cpioModeRegular {
+			others = append(others, hdr.name)
+		}
+		if err := cr.skipData(hdr.fileSize); err != nil {
+			return err
+		}
+	}
+	if len(others) > 0 {
+		return NewFormatError(ErrUnsupportedFormat, "cpio",
+			errors.Errorf("cpio archive contains more than one file, repackage the archive, entries: %s, %s", cr.hdr.name, strings.Join(others, ", ")))
+	}
+	return io.EOF
+}
+
+// next reads the header of the next entry, and returns nil once the trailer is reached.
+func (cr *CpioReader) next() (*cpioHeader, error) {
+	buf := make([]byte, cpioHeaderSize)
+	if _, err := io.ReadFull(cr.r, buf); err != nil {
+		return nil, errors.Wrap(err, "could not read cpio header")
+	}
+	hdr := &cpioHeader{}
+	switch string(buf[:6]) {
+	case cpioNewcMagic:
+	case cpioCrcMagic:
+		hdr.crc = true
+	default:
+		return nil, NewFormatError(ErrUnsupportedFormat, "cpio",
+			errors.Errorf("unsupported cpio header %q, only the newc and crc formats are supported", buf[:6]))
+	}
+	field := func(i int) (uint32, error) {
+		off := 6 + 8*i
+		v, err := strconv.ParseUint(string(buf[off:off+8]), 16, 32)
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid cpio header")
+		}
+		return uint32(v), nil
+	}
+	var fields [13]uint32
+	for i := range fields {
+		v, err := field(i)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = v
+	}
+	hdr.mode = fields[1]
+	hdr.fileSize = int64(fields[6])
+	hdr.check = fields[12]
+	nameSize := int64(fields[11])
+	if nameSize == 0 {
+		return nil, errors.New("invalid cpio header: empty name")
+	}
+	name := make([]byte, nameSize)
+	if _, err := io.ReadFull(cr.r, name); err != nil {
+		return nil, errors.Wrap(err, "could not read cpio entry name")
+	}
+	hdr.name = string(bytes.TrimRight(name, "\x00"))
+	if err := cr.skipPadding(cpioHeaderSize + nameSize); err != nil {
+		return nil, err
+	}
+	if hdr.name == cpioTrailer {
+		return nil, nil
+	}
+	return hdr, nil
+}
+
+// skipData skips the data of an entry along with its padding.
+func (cr *CpioReader) skipData(n int64) error {
+	if _, err := io.CopyN(io.Discard, cr.r, n); err != nil {
+		return errors.Wrap(err, "could not read cpio entry")
+	}
+	return cr.skipPadding(n)
+}
+
+// skipPadding skips the padding aligning a field of size n to 4 bytes.
+func (cr *CpioReader) skipPadding(n int64) error {
+	if pad := (4 - n%4) % 4; pad > 0 {
+		if _, err := io.CopyN(io.Discard, cr.r, pad); err != nil {
+			return errors.Wrap(err, "could not read cpio padding")
+		}
+	}
+	return nil
+}
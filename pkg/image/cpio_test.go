@@ -0,0 +1,119 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+type cpioTestEntry struct {
+	name string
+	mode uint32
+	data []byte
+}
+
+// buildCpio writes the entries as a newc archive, or as a crc archive when crc is set.
+func buildCpio(crc bool, entries ...cpioTestEntry) []byte {
+	var b bytes.Buffer
+	pad := func() {
+		for b.Len()%4 != 0 {
+			b.WriteByte(0)
+		}
+	}
+	magic := cpioNewcMagic
+	if crc {
+		magic = cpioCrcMagic
+	}
+	for i, e := range append(entries, cpioTestEntry{name: cpioTrailer}) {
+		var sum uint32
+		if crc {
+			for _, c := range e.data {
+				sum += uint32(c)
+			}
+		}
+		fmt.Fprintf(&b, "%s%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+			magic, i+1, e.mode, 0, 0, 1, 0, len(e.data), 0, 0, 0, 0, len(e.name)+1, sum)
+		b.WriteString(e.name)
+		b.WriteByte(0)
+		pad()
+		b.Write(e.data)
+		pad()
+	}
+	// cpio pads archives to a multiple of 512 bytes
+	b.Write(make([]byte, 512-b.Len()%512))
+	return b.Bytes()
+}
+
+var _ = Describe("Cpio reader", func() {
+	content := []byte("disk image content, padded to an odd size")
+	dir := cpioTestEntry{name: "images", mode: 040755}
+	file := cpioTestEntry{name: "images/disk.img", mode: 0100644, data: content}
+	other := cpioTestEntry{name: "images/other.img", mode: 0100644, data: []byte("other")}
+
+	table.DescribeTable("should stream the file of a cpio archive", func(crc bool, entries ...cpioTestEntry) {
+		r, err := NewCpioReader(bytes.NewReader(buildCpio(crc, entries...)))
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(content))
+	},
+		table.Entry("with the newc format", false, file),
+		table.Entry("with the crc format", true, file),
+		table.Entry("skipping directories", false, dir, file),
+	)
+
+	It("should accept an empty file", func() {
+		r, err := NewCpioReader(bytes.NewReader(buildCpio(false, cpioTestEntry{name: "empty", mode: 0100644})))
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(BeEmpty())
+	})
+
+	It("should reject an archive with more than one file", func() {
+		r, err := NewCpioReader(bytes.NewReader(buildCpio(false, dir, file, other)))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = io.ReadAll(r)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cpio archive contains more than one file"))
+		Expect(err.Error()).To(ContainSubstring("images/disk.img, images/other.img"))
+		Expect(errors.Is(err, ErrUnsupportedFormat)).To(BeTrue())
+	})
+
+	It("should reject an archive without a file", func() {
+		_, err := NewCpioReader(bytes.NewReader(buildCpio(false, dir)))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cpio archive does not contain a regular file, entries: images"))
+	})
+
+	It("should fail on a checksum mismatch", func() {
+		archive := buildCpio(true, file)
+		archive[bytes.Index(archive, content)] ^= 0xFF
+		r, err := NewCpioReader(bytes.NewReader(archive))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = io.ReadAll(r)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cpio checksum mismatch"))
+		Expect(errors.Is(err, ErrCorruptArchive)).To(BeTrue())
+	})
+
+	It("should fail on a truncated archive", func() {
+		archive := buildCpio(false, file)
+		r, err := NewCpioReader(bytes.NewReader(archive[:cpioHeaderSize+20]))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = io.ReadAll(r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject the old binary format", func() {
+		_, err := NewCpioReader(bytes.NewReader(append([]byte{0xC7, 0x71}, make([]byte, 510)...)))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("only the newc and crc formats are supported"))
+		Expect(errors.Is(err, ErrUnsupportedFormat)).To(BeTrue())
+	})
+})
@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Format is a format detected by DetectFormat, its value is the one of the format of a Header.
+type Format string
+
+const (
+	// FormatRaw is returned for data without a known header, a raw disk image for instance
+	FormatRaw Format = ""
+	// FormatGzip is the gzip format
+	FormatGzip Format = "gz"
+	// FormatXz is the xz format
+	FormatXz Format = "xz"
+	// FormatLzma is the legacy LZMA_alone format
+	FormatLzma Format = "lzma"
+	// FormatZstd is the zstd format
+	FormatZstd Format = "zst"
+	// FormatBzip2 is the bzip2 format
+	FormatBzip2 Format = "bz2"
+	// FormatLz4 is the lz4 frame format
+	FormatLz4 Format = "lz4"
+	// FormatLz4Legacy is the legacy lz4 format, which cannot be imported
+	FormatLz4Legacy Format = "lz4-legacy"
+	// FormatTar is the tar format
+	FormatTar Format = "tar"
+	// FormatZip is the zip format
+	FormatZip Format = "zip"
+	// Format7z is the 7z format
+	Format7z Format = "7z"
+	// FormatCpio is the newc cpio format
+	FormatCpio Format = "cpio"
+	// FormatCpioCrc is the newc cpio format with checksums
+	FormatCpioCrc Format = "cpio-crc"
+	// FormatQcow2 is the qcow2 format
+	FormatQcow2 Format = "qcow2"
+	// FormatVmdk is the sparse vmdk format
+	FormatVmdk Format = "vmdk"
+	// FormatVmdkDescriptor is the descriptor of a vmdk image, referencing the extent files of its data
+	FormatVmdkDescriptor Format = "vmdk-descriptor"
+	// FormatVdi is the VirtualBox vdi format
+	FormatVdi Format = "vdi"
+	// FormatVhd is the vhd format
+	FormatVhd Format = "vhd"
+	// FormatVhdx is the vhdx format
+	FormatVhdx Format = "vhdx"
+	// FormatQed is the QEMU Enhanced Disk format
+	FormatQed Format = "qed"
+	// FormatParallels is the Parallels disk image format
+	FormatParallels Format = "parallels"
+	// FormatISO is the ISO9660 format, a raw image detected from its primary volume descriptor
+	FormatISO Format = "iso"
+)
+
+const (
+	// isoMagicOffset is the offset of the identifier of the first volume descriptor of an ISO9660
+	// image, it follows the 32 KiB of the system area and the type of the descriptor.
+	isoMagicOffset = 0x8001
+	isoMagic       = "CD001"
+	// ISO9660HeaderSize is the number of bytes of the data needed by IsISO9660
+	ISO9660HeaderSize = isoMagicOffset + len(isoMagic)
+	// DetectFormatSize is the number of bytes read by DetectFormat
+	DetectFormatSize = ISO9660HeaderSize
+
+	// qcow2SizeOffset is the offset of the big endian virtual size in bytes of a qcow2 image
+	qcow2SizeOffset = 24
+	// vmdkCapacityOffset is the offset of the little endian capacity in sectors of a vmdk sparse extent
+	vmdkCapacityOffset = 12
+	vmdkSectorSize     = 512
+	// VirtualSizeHeaderSize is the number of bytes of the header needed by HeaderVirtualSize
+	VirtualSizeHeaderSize = qcow2SizeOffset + 8
+)
+
+// IsISO9660 returns true if data, the first ISO9660HeaderSize bytes of an image at least, is an
+// ISO9660 image: the identifier of its first volume descriptor is found in sector 16.
+func IsISO9660(data []byte) bool {
+	return len(data) >= ISO9660HeaderSize && string(data[isoMagicOffset:ISO9660HeaderSize]) == isoMagic
+}
+
+// HeaderVirtualSize returns the virtual size of an image of the format detected by DetectFormat, read
+// from hdr, its first VirtualSizeHeaderSize bytes at least. It returns false for the formats whose
+// header does not hold the virtual size, the compressed and the archive formats among others.
+func HeaderVirtualSize(format Format, hdr []byte) (int64, bool) {
+	if len(hdr) < VirtualSizeHeaderSize {
+		return 0, false
+	}
+	switch format {
+	case FormatQcow2:
+		size := binary.BigEndian.Uint64(hdr[qcow2SizeOffset:])
+		return int64(size), size > 0 && size <= math.MaxInt64
+	case FormatVmdk:
+		sectors := binary.LittleEndian.Uint64(hdr[vmdkCapacityOffset:])
+		return int64(sectors) * vmdkSectorSize, sectors > 0 && sectors <= math.MaxInt64/vmdkSectorSize
+	}
+	return 0, false
+}
+
+// DetectFormat reads the first DetectFormatSize bytes of r, and returns the format found in them
+// along with a reader of all the data of r, the bytes read included. Data shorter than
+// DetectFormatSize is matched as is. The headers too short to tell a format apart from other data,
+// like the lzma one, are only matched once the others, ISO9660 included, did not match.
+func DetectFormat(r io.Reader) (Format, io.Reader, error) {
+	buf := make([]byte, DetectFormatSize)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return FormatRaw, nil, errors.Wrap(err, "could not read the header")
+	}
+	buf = buf[:n]
+	data := io.MultiReader(bytes.NewReader(buf), r)
+	var ambiguous []Header
+	for _, h := range sortedHeaders() {
+		if h.Ambiguous() {
+			ambiguous = append(ambiguous, h)
+		} else if h.Match(buf) {
+			return Format(h.Format), data, nil
+		}
+	}
+	if IsISO9660(buf) {
+		return FormatISO, data, nil
+	}
+	for _, h := range ambiguous {
+		if h.Match(buf) {
+			return Format(h.Format), data, nil
+		}
+	}
+	return FormatRaw, data, nil
+}
+
+// sortedHeaders returns the known headers in the order of their formats, so that the result of
+// DetectFormat does not depend on the order of a map.
+func sortedHeaders() []Header {
+	headers := make([]Header, 0, len(knownHeaders))
+	for _, h := range knownHeaders {
+		headers = append(headers, h)
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Format < headers[j].Format })
+	return headers
+}
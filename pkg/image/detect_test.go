@@ -0,0 +1,184 @@
+package image
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing/iotest"
+	"unicode/utf16"
+
+	"github.com/klauspost/compress/zstd"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+const testImagesDir = "../../tests/images"
+
+// bzip2Data is "disk image" compressed by bzip2 1.0.8
+var bzip2Data = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x52, 0xef,
+	0x79, 0x09, 0x00, 0x00, 0x01, 0x11, 0x80, 0x40, 0x00, 0x26, 0xaa, 0x08,
+	0x00, 0x20, 0x00, 0x31, 0x06, 0x4c, 0x41, 0x00, 0xda, 0x9a, 0x1c, 0x84,
+	0x86, 0xe3, 0xc5, 0xdc, 0x91, 0x4e, 0x14, 0x24, 0x14, 0xbb, 0xde, 0x42,
+	0x40,
+}
+
+func testImage(name string) func() []byte {
+	return func() []byte {
+		data, err := os.ReadFile(filepath.Join(testImagesDir, name))
+		Expect(err).ToNot(HaveOccurred())
+		return data
+	}
+}
+
+func zstdData() []byte {
+	var b bytes.Buffer
+	w, err := zstd.NewWriter(&b)
+	Expect(err).ToNot(HaveOccurred())
+	_, err = w.Write([]byte("disk image"))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(w.Close()).To(Succeed())
+	return b.Bytes()
+}
+
+func zipData() []byte {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	f, err := w.Create("disk.img")
+	Expect(err).ToNot(HaveOccurred())
+	_, err = f.Write([]byte("disk image"))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(w.Close()).To(Succeed())
+	return b.Bytes()
+}
+
+// vmdkHeader returns the header of a sparse extent of 1 GiB, as written by qemu-img.
+func vmdkHeader() []byte {
+	hdr := make([]byte, 512)
+	copy(hdr, "KDMV")
+	binary.LittleEndian.PutUint32(hdr[4:], 1)      // version
+	binary.LittleEndian.PutUint32(hdr[8:], 3)      // flags, valid new line test and redundant grain table
+	binary.LittleEndian.PutUint64(hdr[12:], 1<<21) // capacity in sectors
+	binary.LittleEndian.PutUint64(hdr[20:], 128)   // grain size in sectors
+	binary.LittleEndian.PutUint64(hdr[28:], 1)     // descriptor offset
+	binary.LittleEndian.PutUint64(hdr[36:], 20)    // descriptor size
+	binary.LittleEndian.PutUint32(hdr[44:], 512)   // grain table entries
+	copy(hdr[73:], "\n \r\n")
+	return hdr
+}
+
+// vhdHeader returns the copy of the footer starting a dynamic vhd of 1 GiB.
+func vhdHeader() []byte {
+	hdr := make([]byte, 512)
+	copy(hdr, "conectix")
+	binary.BigEndian.PutUint32(hdr[8:], 2)        // features
+	binary.BigEndian.PutUint32(hdr[12:], 0x10000) // file format version
+	binary.BigEndian.PutUint64(hdr[16:], 512)     // offset of the dynamic disk header
+	copy(hdr[28:], "qemu")
+	binary.BigEndian.PutUint64(hdr[40:], 1<<30) // original size
+	binary.BigEndian.PutUint64(hdr[48:], 1<<30) // current size
+	binary.BigEndian.PutUint32(hdr[60:], 3)     // disk type, dynamic
+	return hdr
+}
+
+// vhdxHeader returns the file type identifier starting a vhdx file.
+func vhdxHeader() []byte {
+	hdr := make([]byte, 512)
+	copy(hdr, "vhdxfile")
+	for i, c := range utf16.Encode([]rune("QEMU v7.0.0")) {
+		binary.LittleEndian.PutUint16(hdr[8+2*i:], c)
+	}
+	return hdr
+}
+
+// qedHeader returns the header of a qed image of 1 GiB, as written by qemu-img.
+func qedHeader() []byte {
+	hdr := make([]byte, 512)
+	copy(hdr, []byte{'Q', 'E', 'D', 0x00})
+	binary.LittleEndian.PutUint32(hdr[4:], 64<<10)  // cluster size
+	binary.LittleEndian.PutUint32(hdr[8:], 4)       // table size in clusters
+	binary.LittleEndian.PutUint32(hdr[12:], 1)      // header size in clusters
+	binary.LittleEndian.PutUint64(hdr[40:], 64<<10) // offset of the L1 table
+	binary.LittleEndian.PutUint64(hdr[48:], 1<<30)  // image size
+	return hdr
+}
+
+// parallelsHeader returns the header of a parallels image of 1 GiB, starting with magic.
+func parallelsHeader(magic string) func() []byte {
+	return func() []byte {
+		hdr := make([]byte, 512)
+		copy(hdr, magic)
+		binary.LittleEndian.PutUint32(hdr[16:], 2)     // version
+		binary.LittleEndian.PutUint32(hdr[28:], 2048)  // sectors per cluster
+		binary.LittleEndian.PutUint32(hdr[32:], 1024)  // entries of the block allocation table
+		binary.LittleEndian.PutUint64(hdr[36:], 1<<21) // size in sectors
+		binary.LittleEndian.PutUint32(hdr[48:], 2048)  // offset of the data in sectors
+		return hdr
+	}
+}
+
+var _ = Describe("Detect format", func() {
+	table.DescribeTable("should detect the format of the data", func(data func() []byte, expected Format) {
+		content := data()
+		format, r, err := DetectFormat(bytes.NewReader(content))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(format).To(Equal(expected))
+		// the bytes sniffed are read again
+		read, err := io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(read).To(Equal(content))
+	},
+		table.Entry("of a gzip file", testImage("tinyCore.iso.gz"), FormatGzip),
+		table.Entry("of a xz file", testImage("tinyCore.iso.xz"), FormatXz),
+		table.Entry("of a zstd file", zstdData, FormatZstd),
+		table.Entry("of a bzip2 file", func() []byte { return bzip2Data }, FormatBzip2),
+		table.Entry("of a tar file", testImage("archive.tar"), FormatTar),
+		table.Entry("of a zip file", zipData, FormatZip),
+		table.Entry("of a qcow2 image", testImage("cirros-qcow2.img"), FormatQcow2),
+		table.Entry("of a vmdk image", vmdkHeader, FormatVmdk),
+		table.Entry("of a vmdk descriptor", func() []byte { return []byte(vmdkDescriptor) }, FormatVmdkDescriptor),
+		table.Entry("of a vhd image", vhdHeader, FormatVhd),
+		table.Entry("of a vhdx image", vhdxHeader, FormatVhdx),
+		table.Entry("of a vdi image", testImage("tinyCore.vdi"), FormatVdi),
+		table.Entry("of a qed image", qedHeader, FormatQed),
+		table.Entry("of a parallels image", parallelsHeader("WithoutFreeSpace"), FormatParallels),
+		table.Entry("of a parallels image with 64 bit offsets", parallelsHeader("WithouFreSpacExt"), FormatParallels),
+		table.Entry("of an ISO9660 image", testImage("tinyCore.iso"), FormatISO),
+		table.Entry("of a raw image", testImage("cirros.raw"), FormatRaw),
+		table.Entry("of data shorter than the header of an ISO9660 image", func() []byte { return bzip2Data[:4] }, FormatBzip2),
+		table.Entry("of empty data", func() []byte { return nil }, FormatRaw),
+		table.Entry("of a lzma file", func() []byte { return append([]byte{0x5D, 0x00, 0x00, 0x80, 0x00}, make([]byte, 100)...) }, FormatLzma),
+	)
+
+	It("should prefer the ISO9660 header to an ambiguous one", func() {
+		iso := make([]byte, DetectFormatSize)
+		copy(iso, []byte{0x5D, 0x00, 0x00})
+		copy(iso[isoMagicOffset:], isoMagic)
+		format, _, err := DetectFormat(bytes.NewReader(iso))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(format).To(Equal(FormatISO))
+	})
+
+	It("should fail when the data cannot be read", func() {
+		_, _, err := DetectFormat(iotest.ErrReader(io.ErrClosedPipe))
+		Expect(err).To(MatchError(ContainSubstring(io.ErrClosedPipe.Error())))
+	})
+
+	table.DescribeTable("should read the virtual size from the header", func(data func() []byte, expected int64, found bool) {
+		format, _, err := DetectFormat(bytes.NewReader(data()))
+		Expect(err).ToNot(HaveOccurred())
+		size, ok := HeaderVirtualSize(format, data())
+		Expect(ok).To(Equal(found))
+		Expect(size).To(Equal(expected))
+	},
+		table.Entry("of a qcow2 image", testImage("cirros-qcow2.img"), int64(46137344), true),
+		table.Entry("of a vmdk image", vmdkHeader, int64(1<<30), true),
+		table.Entry("not of a raw image", testImage("cirros.raw"), int64(0), false),
+		table.Entry("not of a compressed image", testImage("tinyCore.iso.gz"), int64(0), false),
+		table.Entry("not of a truncated qcow2 header", func() []byte { return []byte{'Q', 'F', 'I', 0xfb} }, int64(0), false),
+	)
+})
@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"fmt"
+)
+
+var (
+	// ErrUnsupportedFormat indicates that the data is in a format, or a variant of a format, that
+	// cannot be imported. Retrying the import does not help.
+	ErrUnsupportedFormat = fmt.Errorf("unsupported format")
+	// ErrCorruptArchive indicates that compressed or archived data could not be decoded.
+	ErrCorruptArchive = fmt.Errorf("corrupt archive")
+	// ErrTruncatedStream indicates that compressed or archived data ended before its end marker.
+	ErrTruncatedStream = fmt.Errorf("truncated stream")
+	// ErrInvalidEncryptionKey indicates that an encrypted image cannot be decrypted with the key
+	// passed in, or that it is missing. Retrying the import does not help.
+	ErrInvalidEncryptionKey = fmt.Errorf("invalid encryption key")
+	// ErrCorruptImage indicates that qemu-img check found corruptions or leaked clusters in an
+	// image. Retrying the import does not help.
+	ErrCorruptImage = fmt.Errorf("corrupt image")
+)
+
+// FormatError is an error of the data of a format, its Kind is one of ErrUnsupportedFormat,
+// ErrCorruptArchive, ErrTruncatedStream, ErrInvalidEncryptionKey or ErrCorruptImage. Both the kind and the cause of
+// the error match with errors.Is.
+type FormatError struct {
+	Kind   error
+	Format string
+	Err    error
+}
+
+// NewFormatError returns a FormatError of the passed in kind, caused by err.
+func NewFormatError(kind error, format string, err error) *FormatError {
+	return &FormatError{Kind: kind, Format: format, Err: err}
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Format, e.Kind, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the kind of the error.
+func (e *FormatError) Is(target error) bool {
+	return target == e.Kind
+}
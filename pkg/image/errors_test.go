@@ -0,0 +1,19 @@
+package image
+
+import (
+	"errors"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Format errors", func() {
+	It("should match both the kind and the cause of the error", func() {
+		err := NewFormatError(ErrTruncatedStream, "gz", io.ErrUnexpectedEOF)
+		Expect(errors.Is(err, ErrTruncatedStream)).To(BeTrue())
+		Expect(errors.Is(err, io.ErrUnexpectedEOF)).To(BeTrue())
+		Expect(errors.Is(err, ErrCorruptArchive)).To(BeFalse())
+		Expect(err.Error()).To(Equal("gz truncated stream: unexpected EOF"))
+	})
+})
@@ -3,7 +3,9 @@ package image
 import (
 	"bytes"
 	"encoding/hex"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
@@ -46,12 +48,82 @@ var knownHeaders = Headers{
 		SizeOff: 0,
 		SizeLen: 0,
 	},
+	"lzma": Header{
+		Format: "lzma",
+		// LZMA_alone properties of lc=3, lp=0, pb=2 followed by the low bytes of a dictionary size
+		// that is a multiple of 64KiB, as written by lzma and xz --format=lzma
+		magicNumber: []byte{0x5D, 0x00, 0x00},
+		// the uncompressed size at offset 5 is optional, and is all ones when not known
+		SizeOff: 0,
+		SizeLen: 0,
+	},
+	"zst": Header{
+		Format:      "zst",
+		magicNumber: []byte{0x28, 0xB5, 0x2F, 0xFD},
+		// TODO: size not in hdr
+		SizeOff: 0,
+		SizeLen: 0,
+	},
+	"bz2": Header{
+		Format:      "bz2",
+		magicNumber: []byte{'B', 'Z', 'h'},
+		// TODO: size not in hdr
+		SizeOff: 0,
+		SizeLen: 0,
+	},
+	"lz4": Header{
+		Format:      "lz4",
+		magicNumber: []byte{0x04, 0x22, 0x4D, 0x18},
+		// TODO: content size is optional in the frame descriptor
+		SizeOff: 0,
+		SizeLen: 0,
+	},
+	"lz4-legacy": Header{
+		Format:      "lz4-legacy",
+		magicNumber: []byte{0x02, 0x21, 0x4C, 0x18},
+		SizeOff:     0,
+		SizeLen:     0,
+	},
+	"zip": Header{
+		Format:      "zip",
+		magicNumber: []byte{'P', 'K', 0x03, 0x04},
+		// the uncompressed size is stored per entry, and may be deferred to a data descriptor
+		SizeOff: 0,
+		SizeLen: 0,
+	},
+	"7z": Header{
+		Format:      "7z",
+		magicNumber: []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C},
+		// the unpacked sizes are stored in the archive header at the end of the file
+		SizeOff: 0,
+		SizeLen: 0,
+	},
+	"cpio": Header{
+		Format:      "cpio",
+		magicNumber: []byte(cpioNewcMagic),
+		// the file size is stored as hex digits in the newc header
+		SizeOff: 0,
+		SizeLen: 0,
+	},
+	"cpio-crc": Header{
+		Format:      "cpio-crc",
+		magicNumber: []byte(cpioCrcMagic),
+		SizeOff:     0,
+		SizeLen:     0,
+	},
 	"vmdk": Header{
 		Format:      "vmdk",
 		magicNumber: []byte("KDMV"),
 		SizeOff:     0,
 		SizeLen:     0,
 	},
+	"vmdk-descriptor": Header{
+		Format:      "vmdk-descriptor",
+		magicNumber: []byte(vmdkDescriptorMagic),
+		// the data of the disk is in the extent files referenced by the descriptor
+		SizeOff: 0,
+		SizeLen: 0,
+	},
 	"vdi": Header{
 		Format:      "vdi",
 		magicNumber: []byte{0x7F, 0x10, 0xDA, 0xBE},
@@ -71,6 +143,25 @@ var knownHeaders = Headers{
 		SizeOff:     0,
 		SizeLen:     0,
 	},
+	"qed": Header{
+		Format:      "qed",
+		magicNumber: []byte{'Q', 'E', 'D', 0x00},
+		SizeOff:     0,
+		SizeLen:     0,
+	},
+	"parallels": Header{
+		Format:      "parallels",
+		magicNumber: []byte("WithoutFreeSpace"),
+		SizeOff:     0,
+		SizeLen:     0,
+	},
+	// the magic of the parallels images of more than 4 TiB, whose header has 64 bit offsets
+	"parallels-ext": Header{
+		Format:      "parallels",
+		magicNumber: []byte("WithouFreSpacExt"),
+		SizeOff:     0,
+		SizeLen:     0,
+	},
 }
 
 // Header represents our parameters for a file format header
@@ -82,6 +173,52 @@ type Header struct {
 	SizeLen     int // in bytes
 }
 
+// ambiguousFormats are the formats with a magic number too short to tell them apart from other data,
+// the lzma one may as well be the start of a raw image.
+var ambiguousFormats = map[string]bool{
+	"lzma": true,
+}
+
+// extensionFormats maps the file extensions to the format of the header expected in the file, the
+// format is empty for raw images.
+var extensionFormats = map[string]string{
+	ExtImg:    "",
+	ExtIso:    "",
+	ExtGz:     "gz",
+	ExtQcow2:  "qcow2",
+	ExtVmdk:   "vmdk",
+	ExtVdi:    "vdi",
+	ExtVhd:    "vhd",
+	ExtVhdx:   "vhdx",
+	ExtQed:    "qed",
+	ExtHds:    "parallels",
+	ExtTar:    "tar",
+	ExtOva:    "tar",
+	ExtXz:     "xz",
+	ExtLzma:   "lzma",
+	ExtZstd:   "zst",
+	ExtBz2:    "bz2",
+	ExtLz4:    "lz4",
+	ExtZip:    "zip",
+	Ext7z:     "7z",
+	ExtCpio:   "cpio",
+	ExtBrotli: "br",
+}
+
+// FormatFromExtension returns the format expected from the last extension of name, and false if the
+// extension is not known.
+func FormatFromExtension(name string) (string, bool) {
+	format, ok := extensionFormats[strings.ToLower(path.Ext(name))]
+	return format, ok
+}
+
+// FormatMatchesExtension returns true if the format of a header, its variants included (e.g.
+// cpio-crc), is the one expected from the last extension of name.
+func FormatMatchesExtension(format, name string) bool {
+	extFormat, ok := FormatFromExtension(name)
+	return ok && extFormat != "" && (format == extFormat || strings.HasPrefix(format, extFormat+"-"))
+}
+
 // CopyKnownHdrs performs a simple map copy since := assignment copies the reference to the map, not contents.
 func CopyKnownHdrs() Headers {
 	m := make(Headers)
@@ -91,8 +228,17 @@ func CopyKnownHdrs() Headers {
 	return m
 }
 
+// Ambiguous returns true if a match of the header should be confirmed with the file extension, when
+// there is one.
+func (h Header) Ambiguous() bool {
+	return ambiguousFormats[h.Format]
+}
+
 // Match performs a check to see if the provided byte slice matches the bytes in our header data
 func (h Header) Match(b []byte) bool {
+	if len(b) < h.mgOffset+len(h.magicNumber) {
+		return false
+	}
 	return bytes.Equal(b[h.mgOffset:h.mgOffset+len(h.magicNumber)], h.magicNumber)
 }
 
@@ -41,6 +41,34 @@ var _ = Describe("File format tests", func() {
 			Header{"xz", []byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00}, 0, 0, 0},
 			[]byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00},
 			true),
+		table.Entry("match lzma",
+			Header{"lzma", []byte{0x5D, 0x00, 0x00}, 0, 0, 0},
+			[]byte{0x5D, 0x00, 0x00, 0x80, 0x00, 0xFF, 0xFF},
+			true),
+		table.Entry("match zstd",
+			Header{"zst", []byte{0x28, 0xB5, 0x2F, 0xFD}, 0, 0, 0},
+			[]byte{0x28, 0xB5, 0x2F, 0xFD},
+			true),
+		table.Entry("match bz2",
+			Header{"bz2", []byte{'B', 'Z', 'h'}, 0, 0, 0},
+			[]byte{'B', 'Z', 'h', '9'},
+			true),
+		table.Entry("match zip",
+			Header{"zip", []byte{'P', 'K', 0x03, 0x04}, 0, 0, 0},
+			[]byte{'P', 'K', 0x03, 0x04, 0x14, 0x00},
+			true),
+		table.Entry("match 7z",
+			Header{"7z", []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}, 0, 0, 0},
+			[]byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C, 0x00, 0x04},
+			true),
+		table.Entry("match cpio",
+			Header{"cpio", []byte("070701"), 0, 0, 0},
+			[]byte("07070100000001"),
+			true),
+		table.Entry("match cpio crc",
+			Header{"cpio-crc", []byte("070702"), 0, 0, 0},
+			[]byte("07070200000001"),
+			true),
 		table.Entry("failed match",
 			Header{"gz", []byte{0x1F, 0x8B}, 0, 0, 0},
 			[]byte{'Q', 'F', 'I', 0xfb},
@@ -49,6 +77,10 @@ var _ = Describe("File format tests", func() {
 			Header{"vmdk", []byte("KDMV"), 0, 24, 8},
 			[]byte("KDMV"),
 			true),
+		table.Entry("match vmdk descriptor",
+			Header{"vmdk-descriptor", []byte("# Disk DescriptorFile"), 0, 0, 0},
+			[]byte("# Disk DescriptorFile\nversion=1\n"),
+			true),
 		table.Entry("match vdi",
 			Header{"vdi", []byte("<<< Oracle VM"), 0, 24, 8},
 			[]byte("<<< Oracle VM"),
@@ -61,6 +93,14 @@ var _ = Describe("File format tests", func() {
 			Header{"vhdx", []byte("vhdxfile"), 0, 24, 8},
 			[]byte("vhdxfile"),
 			true),
+		table.Entry("match qed",
+			Header{"qed", []byte{'Q', 'E', 'D', 0x00}, 0, 0, 0},
+			[]byte{'Q', 'E', 'D', 0x00, 0x00, 0x00, 0x01, 0x00},
+			true),
+		table.Entry("match parallels",
+			Header{"parallels", []byte("WithoutFreeSpace"), 0, 0, 0},
+			[]byte("WithoutFreeSpace\x02\x00\x00\x00"),
+			true),
 	)
 
 	tokenQcow := make([]byte, 20)
@@ -90,4 +130,34 @@ var _ = Describe("File format tests", func() {
 			int64(0),
 			false),
 	)
+
+	table.DescribeTable("Format from extension", func(name, want string, wantOk bool) {
+		got, ok := FormatFromExtension(name)
+		Expect(ok).To(Equal(wantOk))
+		Expect(got).To(Equal(want))
+	},
+		table.Entry("gz file", "tinyCore.iso.gz", "gz", true),
+		table.Entry("upper case extension", "/images/DISK.QCOW2", "qcow2", true),
+		table.Entry("brotli file", "cirros.qcow2.br", "br", true),
+		table.Entry("parallels image", "disk.hds", "parallels", true),
+		table.Entry("ova appliance", "tinyCore.ova", "tar", true),
+		table.Entry("raw image", "disk.img", "", true),
+		table.Entry("unknown extension", "disk.raw", "", false),
+		table.Entry("no extension", "/download", "", false),
+	)
+
+	table.DescribeTable("Format matches extension", func(format, name string, want bool) {
+		Expect(FormatMatchesExtension(format, name)).To(Equal(want))
+	},
+		table.Entry("same format", "lzma", "tinyCore.iso.lzma", true),
+		table.Entry("format variant", "cpio-crc", "initrd.cpio", true),
+		table.Entry("other format", "lzma", "tinyCore.iso", false),
+		table.Entry("no extension", "gz", "/download", false),
+	)
+
+	It("should only report the lzma header as ambiguous", func() {
+		for format, h := range knownHeaders {
+			Expect(h.Ambiguous()).To(Equal(format == "lzma"), format)
+		}
+	})
 })
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// Filesystem is a filesystem created on a blank image
+type Filesystem string
+
+const (
+	// FilesystemExt4 is the ext4 filesystem
+	FilesystemExt4 Filesystem = "ext4"
+	// FilesystemXFS is the xfs filesystem
+	FilesystemXFS Filesystem = "xfs"
+)
+
+// filesystemLabelLengths are the longest labels of the filesystems created on blank images
+var filesystemLabelLengths = map[Filesystem]int{
+	FilesystemExt4: 16,
+	FilesystemXFS:  12,
+}
+
+var filesystemLabelMatch = regexp.MustCompile(`^[A-Za-z0-9._-]*$`)
+
+// ValidateFilesystem returns an error if fs is not a filesystem created on blank images, or if
+// label is not a label of it. A label is made of letters, digits, '.', '_' and '-'.
+func ValidateFilesystem(fs Filesystem, label string) error {
+	maxLength, ok := filesystemLabelLengths[fs]
+	if !ok {
+		return errors.Errorf("filesystem %q is not one of %s, %s", fs, FilesystemExt4, FilesystemXFS)
+	}
+	if len(label) > maxLength {
+		return errors.Errorf("label %q of the %s filesystem is longer than %d characters", label, fs, maxLength)
+	}
+	if !filesystemLabelMatch.MatchString(label) {
+		return errors.Errorf("label %q of the filesystem may only contain letters, digits, '.', '_' and '-'", label)
+	}
+	return nil
+}
+
+// CreateFilesystem creates the filesystem fs, labeled with label when it is not empty, on the raw
+// image or the block device at dest, spanning all of it. The blocks of dest are not discarded,
+// keeping its preallocation.
+func CreateFilesystem(dest string, fs Filesystem, label string) error {
+	if err := ValidateFilesystem(fs, label); err != nil {
+		return err
+	}
+	var args []string
+	switch fs {
+	case FilesystemExt4:
+		args = []string{"-F", "-q", "-E", "nodiscard"}
+	case FilesystemXFS:
+		args = []string{"-f", "-q", "-K"}
+	}
+	if label != "" {
+		args = append(args, "-L", label)
+	}
+	args = append(args, dest)
+	klog.V(1).Infof("Creating %s filesystem on %s", fs, dest)
+	if _, err := qemuExecFunction(nil, nil, fmt.Sprintf("mkfs.%s", fs), args...); err != nil {
+		return errors.Wrapf(err, "could not create %s filesystem on %s", fs, dest)
+	}
+	return nil
+}
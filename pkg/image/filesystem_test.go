@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerized-data-importer/pkg/system"
+)
+
+var _ = Describe("Filesystem of blank images", func() {
+	table.DescribeTable("should validate the filesystem and its label", func(fs Filesystem, label string, valid bool) {
+		err := ValidateFilesystem(fs, label)
+		if valid {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		table.Entry("ext4 without a label", FilesystemExt4, "", true),
+		table.Entry("xfs without a label", FilesystemXFS, "", true),
+		table.Entry("ext4 with a label", FilesystemExt4, "data_disk-1.0", true),
+		table.Entry("ext4 with a label of 16 characters", FilesystemExt4, "0123456789abcdef", true),
+		table.Entry("ext4 with a label longer than 16 characters", FilesystemExt4, "0123456789abcdefg", false),
+		table.Entry("xfs with a label of 12 characters", FilesystemXFS, "0123456789ab", true),
+		table.Entry("xfs with a label longer than 12 characters", FilesystemXFS, "0123456789abc", false),
+		table.Entry("a label with a space", FilesystemExt4, "data disk", false),
+		table.Entry("a label with a slash", FilesystemExt4, "data/disk", false),
+		table.Entry("a label with a quote", FilesystemXFS, "data'disk", false),
+		table.Entry("an unsupported filesystem", Filesystem("btrfs"), "", false),
+		table.Entry("an empty filesystem", Filesystem(""), "", false),
+	)
+
+	table.DescribeTable("should run mkfs on the image", func(fs Filesystem, label, expectedCmd string, expectedArgs []string) {
+		called := false
+		replaceExecFunction(func(limits *system.ProcessLimitValues, f func(string), cmd string, args ...string) ([]byte, error) {
+			called = true
+			Expect(cmd).To(Equal(expectedCmd))
+			Expect(args).To(Equal(expectedArgs))
+			return nil, nil
+		}, func() {
+			Expect(CreateFilesystem("disk.img", fs, label)).To(Succeed())
+		})
+		Expect(called).To(BeTrue())
+	},
+		table.Entry("ext4", FilesystemExt4, "", "mkfs.ext4", []string{"-F", "-q", "-E", "nodiscard", "disk.img"}),
+		table.Entry("ext4 with a label", FilesystemExt4, "data", "mkfs.ext4", []string{"-F", "-q", "-E", "nodiscard", "-L", "data", "disk.img"}),
+		table.Entry("xfs", FilesystemXFS, "", "mkfs.xfs", []string{"-f", "-q", "-K", "disk.img"}),
+		table.Entry("xfs with a label", FilesystemXFS, "data", "mkfs.xfs", []string{"-f", "-q", "-K", "-L", "data", "disk.img"}),
+	)
+
+	It("should not run mkfs with an invalid label", func() {
+		replaceExecFunction(func(limits *system.ProcessLimitValues, f func(string), cmd string, args ...string) ([]byte, error) {
+			Fail("mkfs should not run")
+			return nil, nil
+		}, func() {
+			Expect(CreateFilesystem("disk.img", FilesystemXFS, "-o bad")).ToNot(Succeed())
+		})
+	})
+
+	It("should fail when mkfs fails", func() {
+		replaceExecFunction(func(limits *system.ProcessLimitValues, f func(string), cmd string, args ...string) ([]byte, error) {
+			return nil, errors.New("exit status 1")
+		}, func() {
+			err := CreateFilesystem("disk.img", FilesystemXFS, "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not create xfs filesystem on disk.img"))
+		})
+	})
+})
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/pgzip"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// GzipParallelMinSize is the size of the smallest gzip stream decoded by the parallel decoder,
+	// the workers and their buffers are not worth it for a smaller stream.
+	GzipParallelMinSize = 16 << 20
+
+	// gzipBlockSize and gzipBlocks are the size and the number of the blocks decoded ahead of the
+	// reader by the parallel decoder
+	gzipBlockSize = 1 << 20
+	gzipBlocks    = 8
+)
+
+// NewGzipReader returns a reader of the data of the gzip stream read from r, size is the size of
+// the stream, 0 if unknown. A stream known to be smaller than GzipParallelMinSize is decoded by the
+// standard library, any other stream by a parallel decoder which decompresses the next blocks and
+// checks their CRC while the previous ones are read. The stream may be made of several gzip members,
+// as written by concatenating gzip files or by parallel compressors, they are decoded as a single
+// stream.
+func NewGzipReader(r io.Reader, size uint64) (io.ReadCloser, error) {
+	if size > 0 && size < GzipParallelMinSize {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create gzip reader")
+		}
+		// multistream is the default, make sure the members following the first one are not dropped
+		gz.Multistream(true)
+		klog.V(2).Infof("gzip: extracting %q\n", gz.Name)
+		return gz, nil
+	}
+	gz, err := pgzip.NewReaderN(r, gzipBlockSize, gzipBlocks)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create gzip reader")
+	}
+	gz.Multistream(true)
+	klog.V(2).Infof("gzip: extracting %q in parallel\n", gz.Name)
+	return gz, nil
+}
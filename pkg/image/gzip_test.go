@@ -0,0 +1,112 @@
+package image
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/rand"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	// benchmarkGzipMemberSize and benchmarkGzipMembers make a synthetic gzip file of 2 GiB
+	benchmarkGzipMemberSize = 16 << 20
+	benchmarkGzipMembers    = 128
+)
+
+// syntheticImage returns data compressing about as well as a disk image: random blocks amid zeroed
+// ones.
+func syntheticImage(size int) []byte {
+	data := make([]byte, size)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < size; i += 4096 {
+		if rnd.Intn(4) == 0 {
+			rnd.Read(data[i : i+4096])
+		}
+	}
+	return data
+}
+
+// repeatedGzip returns a gzip stream made of count copies of the member.
+func repeatedGzip(member []byte, count int) io.Reader {
+	readers := make([]io.Reader, count)
+	for i := range readers {
+		readers[i] = bytes.NewReader(member)
+	}
+	return io.MultiReader(readers...)
+}
+
+var _ = Describe("Gzip reader", func() {
+	content := syntheticImage(4 << 20)
+
+	table.DescribeTable("should decode all the members of a gzip stream", func(size uint64) {
+		member := gzipData(content)
+		gz, err := NewGzipReader(repeatedGzip(member, 3), size)
+		Expect(err).ToNot(HaveOccurred())
+		defer gz.Close()
+		data, err := io.ReadAll(gz)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(bytes.Repeat(content, 3)))
+	},
+		table.Entry("with the standard decoder", uint64(GzipParallelMinSize-1)),
+		table.Entry("with the parallel decoder", uint64(GzipParallelMinSize)),
+		table.Entry("with the parallel decoder if the size is unknown", uint64(0)),
+	)
+
+	table.DescribeTable("should fail on a truncated gzip stream", func(size uint64) {
+		member := gzipData(content)
+		gz, err := NewGzipReader(bytes.NewReader(member[:len(member)/2]), size)
+		Expect(err).ToNot(HaveOccurred())
+		defer gz.Close()
+		_, err = io.Copy(io.Discard, gz)
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+	},
+		table.Entry("with the standard decoder", uint64(GzipParallelMinSize-1)),
+		table.Entry("with the parallel decoder", uint64(0)),
+	)
+
+	It("should reject data that is not gzip", func() {
+		_, err := NewGzipReader(bytes.NewReader(content), 0)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func benchmarkGzipReader(b *testing.B, size uint64) {
+	// gomega is not set up when only the benchmarks run
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(syntheticImage(benchmarkGzipMemberSize)); err != nil {
+		b.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		b.Fatal(err)
+	}
+	member := buf.Bytes()
+	b.SetBytes(benchmarkGzipMemberSize * benchmarkGzipMembers)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gz, err := NewGzipReader(repeatedGzip(member, benchmarkGzipMembers), size)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, gz); err != nil {
+			b.Fatal(err)
+		}
+		gz.Close()
+	}
+}
+
+// BenchmarkGzipReaderStandard decodes the synthetic file with the standard library decoder, the size
+// passed in selects it.
+func BenchmarkGzipReaderStandard(b *testing.B) {
+	benchmarkGzipReader(b, 1)
+}
+
+// BenchmarkGzipReaderParallel decodes the synthetic file with the parallel decoder.
+func BenchmarkGzipReaderParallel(b *testing.B) {
+	benchmarkGzipReader(b, benchmarkGzipMemberSize*benchmarkGzipMembers)
+}
@@ -23,6 +23,12 @@ const (
 	defaultUserAgent      = "cdi-nbdkit-importer"
 )
 
+var (
+	// nbdkitStopTimeout is the time nbdkit is given to close its connections once interrupted, before it
+	// is killed, may be overridden in tests
+	nbdkitStopTimeout = 10 * time.Second
+)
+
 type nbdkitOperations struct {
 	nbdkit *Nbdkit
 }
@@ -68,6 +74,8 @@ type Nbdkit struct {
 	Socket     string
 	Env        []string
 	LogWatcher NbdkitLogWatcher
+	// exited is closed once the nbdkit process exits
+	exited chan struct{}
 }
 
 // NbdkitOperation defines the interface for executing nbdkit
@@ -245,6 +253,8 @@ func (n *Nbdkit) StartNbdkit(source string) error {
 	}
 	klog.V(3).Infof("Start nbdkit with: %v", quotedArgs)
 
+	// a pid file left by a previous nbdkit process would be taken for the one of the new process
+	os.Remove(n.NbdPidFile)
 	n.c = exec.Command("nbdkit", argsNbdkit...)
 	var stdout io.ReadCloser
 	stdout, err = n.c.StdoutPipe()
@@ -263,12 +273,21 @@ func (n *Nbdkit) StartNbdkit(source string) error {
 	err = n.c.Start()
 	if err != nil {
 		klog.Errorf("Unable to start nbdkit: %v", err)
+		n.c = nil
 		return err
 	}
+	n.exited = make(chan struct{})
+	go func(process *os.Process, exited chan struct{}) {
+		// the process is reaped without closing the pipe of its output, read until its end by the log watcher
+		state, err := process.Wait()
+		klog.Infof("nbdkit exited: %v %v", state, err)
+		close(exited)
+	}(n.c.Process, n.exited)
 
-	err = waitForNbd(n.NbdPidFile)
+	err = waitForNbd(n.NbdPidFile, n.exited)
 	if err != nil {
 		klog.Errorf("Failed waiting for nbdkit to start up: %v", err)
+		n.KillNbdkit()
 		return err
 	}
 	return nil
@@ -296,8 +315,9 @@ func watchNbdLog(output *bufio.Reader) {
 	klog.Infof("Stopped watching nbdkit log.")
 }
 
-// waitForNbd waits for nbdkit to start by watching for the existence of the given PID file.
-func waitForNbd(pidfile string) error {
+// waitForNbd waits for nbdkit to start by watching for the existence of the given PID file, it fails
+// as soon as nbdkit exits.
+func waitForNbd(pidfile string, exited <-chan struct{}) error {
 	nbdCheck := make(chan bool, 1)
 	go func() {
 		klog.Infoln("Waiting for nbdkit PID.")
@@ -323,13 +343,18 @@ func waitForNbd(pidfile string) error {
 	case <-nbdCheck:
 		klog.Infoln("nbdkit ready.")
 		return nil
+	case <-exited:
+		nbdCheck <- true
+		return errors.New("nbdkit exited before being ready")
 	case <-time.After(startupTimeoutSeconds * time.Second):
 		nbdCheck <- true
 		return errors.New("timed out waiting for nbdkit to be ready")
 	}
 }
 
-// KillNbdkit stops the nbdkit process
+// KillNbdkit stops the nbdkit process: it is interrupted, so that it closes its connections, and
+// killed if it does not exit in time. It returns once the process exited and its pid file and socket
+// were removed, and may be called again.
 func (n *Nbdkit) KillNbdkit() error {
 	var err error
 	if n.c == nil {
@@ -337,13 +362,23 @@ func (n *Nbdkit) KillNbdkit() error {
 	}
 	if n.c.Process != nil {
 		err = n.c.Process.Signal(os.Interrupt)
-		if err != nil {
+		select {
+		case <-n.exited:
+			err = nil
+		case <-time.After(nbdkitStopTimeout):
+			klog.Warningf("nbdkit did not exit within %v, killing it", nbdkitStopTimeout)
 			err = n.c.Process.Kill()
+			<-n.exited
 		}
 	}
+	n.c = nil
 	if n.LogWatcher != nil {
 		n.LogWatcher.Stop()
 	}
+	os.Remove(n.NbdPidFile)
+	if n.Socket != "" {
+		os.Remove(n.Socket)
+	}
 	return err
 }
 
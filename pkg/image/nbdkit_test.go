@@ -0,0 +1,113 @@
+package image
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeNbdkit is an nbdkit reading the pid file of its arguments, the body runs once its arguments are read.
+const fakeNbdkit = `#!/bin/sh
+while [ $# -gt 0 ]; do
+	if [ "$1" = "--pidfile" ]; then
+		pidfile="$2"
+	fi
+	shift
+done
+`
+
+// discardNbdkitLog drops the log of the fake nbdkit, which would otherwise be written to the log file appended to the
+// qemu-img errors of the other tests
+type discardNbdkitLog struct{}
+
+func (discardNbdkitLog) Start(output *bufio.Reader) {
+	go io.Copy(io.Discard, output)
+}
+
+func (discardNbdkitLog) Stop() {}
+
+var _ = Describe("Nbdkit process", func() {
+	var (
+		tmpDir      string
+		path        string
+		stopTimeout time.Duration
+		n           *Nbdkit
+	)
+
+	// installNbdkit installs an nbdkit running body, found first in the PATH
+	installNbdkit := func(body string) {
+		Expect(os.WriteFile(filepath.Join(tmpDir, "nbdkit"), []byte(fakeNbdkit+body), 0700)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "nbdkit")
+		Expect(err).NotTo(HaveOccurred())
+		path = os.Getenv("PATH")
+		os.Setenv("PATH", tmpDir+string(os.PathListSeparator)+path)
+		stopTimeout, nbdkitStopTimeout = nbdkitStopTimeout, 500*time.Millisecond
+		n = &Nbdkit{
+			NbdPidFile: filepath.Join(tmpDir, "nbdkit.pid"),
+			Socket:     filepath.Join(tmpDir, "nbdkit.sock"),
+			plugin:     NbdkitFilePlugin,
+			LogWatcher: discardNbdkitLog{},
+		}
+	})
+
+	AfterEach(func() {
+		n.KillNbdkit()
+		os.Setenv("PATH", path)
+		nbdkitStopTimeout = stopTimeout
+		os.RemoveAll(tmpDir)
+	})
+
+	It("should wait for nbdkit to exit once interrupted, and remove its pid file and socket", func() {
+		installNbdkit(`trap 'exit 0' INT
+echo $$ > "$pidfile"
+touch ` + n.Socket + `
+while :; do sleep 0.1; done
+`)
+		Expect(n.StartNbdkit("/disk.img")).To(Succeed())
+		Expect(n.NbdPidFile).To(BeAnExistingFile())
+		exited := n.exited
+
+		Expect(n.KillNbdkit()).To(Succeed())
+		Expect(exited).To(BeClosed())
+		Expect(n.NbdPidFile).NotTo(BeAnExistingFile())
+		Expect(n.Socket).NotTo(BeAnExistingFile())
+		Expect(n.KillNbdkit()).To(Succeed())
+	})
+
+	It("should kill nbdkit when it does not exit once interrupted", func() {
+		installNbdkit(`trap '' INT
+echo $$ > "$pidfile"
+while :; do sleep 0.1; done
+`)
+		Expect(n.StartNbdkit("/disk.img")).To(Succeed())
+		exited := n.exited
+
+		n.KillNbdkit()
+		Expect(exited).To(BeClosed())
+		Expect(n.NbdPidFile).NotTo(BeAnExistingFile())
+	})
+
+	It("should fail as soon as nbdkit exits before being ready", func() {
+		installNbdkit("exit 1\n")
+		start := time.Now()
+		err := n.StartNbdkit("/disk.img")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("exited before being ready"))
+		Expect(time.Since(start)).To(BeNumerically("<", startupTimeoutSeconds*time.Second))
+	})
+
+	It("should not take the pid file of a previous nbdkit for the one of a new process", func() {
+		Expect(os.WriteFile(n.NbdPidFile, []byte("1"), 0600)).To(Succeed())
+		installNbdkit("exit 1\n")
+		Expect(n.StartNbdkit("/disk.img")).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"encoding/xml"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ovfEnvelope holds the parts of an OVF descriptor referencing the disks of the appliance. The
+// elements and attributes are matched by their local names, whatever the version of the OVF
+// namespace.
+type ovfEnvelope struct {
+	Files []struct {
+		ID   string `xml:"id,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"References>File"`
+	Disks []struct {
+		FileRef string `xml:"fileRef,attr"`
+	} `xml:"DiskSection>Disk"`
+}
+
+// IsOvfDescriptor returns true if name is the one of an OVF descriptor, the first entry of an OVA
+// appliance.
+func IsOvfDescriptor(name string) bool {
+	return strings.EqualFold(path.Ext(name), ExtOvf)
+}
+
+// OvfDisks returns the files of the disks referenced by an OVF descriptor, in the order of its disk
+// section. The disks without a file, created empty when the appliance is deployed, are left out.
+func OvfDisks(descriptor []byte) ([]string, error) {
+	var envelope ovfEnvelope
+	if err := xml.Unmarshal(descriptor, &envelope); err != nil {
+		return nil, errors.Wrap(err, "could not parse the ovf descriptor")
+	}
+	hrefs := make(map[string]string, len(envelope.Files))
+	for _, f := range envelope.Files {
+		hrefs[f.ID] = f.Href
+	}
+	var disks []string
+	for _, d := range envelope.Disks {
+		if d.FileRef == "" {
+			continue
+		}
+		href, ok := hrefs[d.FileRef]
+		if !ok || href == "" {
+			return nil, errors.Errorf("ovf disk references the unknown file %q", d.FileRef)
+		}
+		disks = append(disks, href)
+	}
+	if len(disks) == 0 {
+		return nil, errors.New("ovf descriptor does not reference a disk file")
+	}
+	return disks, nil
+}
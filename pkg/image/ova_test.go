@@ -0,0 +1,63 @@
+package image
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// ovfDescriptor returns an OVF descriptor, as written by VirtualBox, referencing files and disks.
+func ovfDescriptor(files map[string]string, fileRefs ...string) []byte {
+	var references, disks strings.Builder
+	for id, href := range files {
+		fmt.Fprintf(&references, "    <File ovf:id=\"%s\" ovf:href=\"%s\"/>\n", id, href)
+	}
+	for i, ref := range fileRefs {
+		fmt.Fprintf(&disks, "    <Disk ovf:capacity=\"1073741824\" ovf:diskId=\"vmdisk%d\" ovf:fileRef=\"%s\" ovf:format=\"http://www.vmware.com/interfaces/specifications/vmdk.html#streamOptimized\"/>\n", i+1, ref)
+	}
+	return []byte(fmt.Sprintf(`<?xml version="1.0"?>
+<Envelope ovf:version="1.0" xml:lang="en-US" xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1">
+  <References>
+%s  </References>
+  <DiskSection>
+    <Info>List of the virtual disks used in the package</Info>
+%s  </DiskSection>
+  <VirtualSystem ovf:id="tinyCore">
+    <Info>A virtual machine</Info>
+  </VirtualSystem>
+</Envelope>
+`, references.String(), disks.String()))
+}
+
+var _ = Describe("OVF descriptor", func() {
+	table.DescribeTable("should return the disk files", func(descriptor []byte, expected []string) {
+		disks, err := OvfDisks(descriptor)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(disks).To(Equal(expected))
+	},
+		table.Entry("of a single disk", ovfDescriptor(map[string]string{"file1": "tinyCore-disk001.vmdk"}, "file1"), []string{"tinyCore-disk001.vmdk"}),
+		table.Entry("of two disks, in the order of the disk section",
+			ovfDescriptor(map[string]string{"file1": "disk1.vmdk", "file2": "disk2.vmdk"}, "file2", "file1"), []string{"disk2.vmdk", "disk1.vmdk"}),
+		table.Entry("but not of an empty disk", ovfDescriptor(map[string]string{"file1": "disk1.vmdk"}, "file1", ""), []string{"disk1.vmdk"}),
+	)
+
+	table.DescribeTable("should fail", func(descriptor []byte, expectedErr string) {
+		_, err := OvfDisks(descriptor)
+		Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+	},
+		table.Entry("without a disk", ovfDescriptor(map[string]string{"file1": "disk1.vmdk"}), "does not reference a disk file"),
+		table.Entry("with a disk of an unknown file", ovfDescriptor(map[string]string{"file1": "disk1.vmdk"}, "file2"), `unknown file "file2"`),
+		table.Entry("with invalid xml", []byte("<Envelope><References>"), "could not parse the ovf descriptor"),
+	)
+
+	table.DescribeTable("should tell an OVF descriptor from its name", func(name string, expected bool) {
+		Expect(IsOvfDescriptor(name)).To(Equal(expected))
+	},
+		table.Entry("of an ovf file", "tinyCore.ovf", true),
+		table.Entry("of an upper case extension", "TINYCORE.OVF", true),
+		table.Entry("of a disk", "tinyCore-disk001.vmdk", false),
+	)
+})
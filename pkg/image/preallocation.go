@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+// preallocationZeroBufferSize is the size of the writes of zeros preallocating a file in full
+const preallocationZeroBufferSize = 1 << 20
+
+var fallocateSupportedFunc = fallocateSupported
+
+// fallocateSupported returns false when the filesystem of the file at path does not support
+// fallocate. The file has to exist, and be at least one byte long.
+func fallocateSupported(path string) bool {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		// no way to tell, it is up to whoever reads the file to fail
+		return true
+	}
+	defer f.Close()
+	// the first byte is allocated again, the size and the data of the file are left as they are
+	return !errors.Is(unix.Fallocate(int(f.Fd()), 0, 0, 1), unix.EOPNOTSUPP)
+}
+
+// checkFallocate returns the preallocation mode really applied to the file at path. qemu-img
+// preallocates with posix_fallocate, which writes zeros on a filesystem without fallocate.
+func checkFallocate(path string, applied PreallocationMode) PreallocationMode {
+	if applied == PreallocationFalloc && !fallocateSupportedFunc(path) {
+		klog.V(1).Infof("fallocate is not supported for %s, it was preallocated in full", path)
+		return PreallocationFull
+	}
+	return applied
+}
+
+// PreallocateFile preallocates the space of the file at path, a raw image copied as it is. The file
+// is allocated with fallocate for PreallocationFalloc, or zeros are written to its holes for
+// PreallocationFull and when fallocate is not supported. It returns the preallocation mode applied.
+func PreallocateFile(path string, preallocation PreallocationMode) (PreallocationMode, error) {
+	if preallocation == PreallocationNone {
+		return PreallocationNone, nil
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return PreallocationNone, errors.Wrapf(err, "could not open %s to preallocate it", path)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return PreallocationNone, errors.Wrapf(err, "could not stat %s to preallocate it", path)
+	}
+	size := info.Size()
+	if size == 0 {
+		return preallocation, nil
+	}
+
+	if preallocation == PreallocationFalloc {
+		err = unix.Fallocate(int(f.Fd()), 0, 0, size)
+		if err == nil {
+			return PreallocationFalloc, nil
+		}
+		if !errors.Is(err, unix.EOPNOTSUPP) {
+			return PreallocationNone, errors.Wrapf(err, "could not fallocate %s", path)
+		}
+		klog.V(1).Infof("fallocate is not supported for %s, writing zeros", path)
+	}
+	if err := writeZerosToHoles(f, size); err != nil {
+		return PreallocationNone, errors.Wrapf(err, "could not write zeros to %s", path)
+	}
+	if err := f.Sync(); err != nil {
+		return PreallocationNone, errors.Wrapf(err, "could not sync %s", path)
+	}
+	return PreallocationFull, nil
+}
+
+// writeZerosToHoles writes zeros to the holes of f, the data of f is never written.
+func writeZerosToHoles(f *os.File, size int64) error {
+	zeros := make([]byte, preallocationZeroBufferSize)
+	for offset := int64(0); offset < size; {
+		hole, err := f.Seek(offset, unix.SEEK_HOLE)
+		if err != nil {
+			return err
+		}
+		if hole >= size {
+			break
+		}
+		data, err := f.Seek(hole, unix.SEEK_DATA)
+		if errors.Is(err, unix.ENXIO) {
+			// no data after the hole
+			data = size
+		} else if err != nil {
+			return err
+		}
+		for offset = hole; offset < data; {
+			n := data - offset
+			if n > int64(len(zeros)) {
+				n = int64(len(zeros))
+			}
+			written, err := f.WriteAt(zeros[:n], offset)
+			if err != nil {
+				return err
+			}
+			offset += int64(written)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Preallocate file", func() {
+	const size = 4 << 20
+	var tmpDir, path string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "preallocation")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(tmpDir, "disk.img")
+		// a sparse file with data in the middle
+		f, err := os.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = f.WriteAt(bytes.Repeat([]byte{0x55}, 4096), size/2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Truncate(size)).To(Succeed())
+		Expect(f.Close()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	allocated := func() int64 {
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		return info.Sys().(*syscall.Stat_t).Blocks * 512
+	}
+
+	table.DescribeTable("should allocate the whole file and keep its data", func(preallocation PreallocationMode) {
+		applied, err := PreallocateFile(path, preallocation)
+		Expect(err).NotTo(HaveOccurred())
+		// falloc is reported as full on a filesystem without fallocate
+		Expect(applied).To(BeElementOf(preallocation, PreallocationFull))
+		Expect(allocated()).To(BeNumerically(">=", size))
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(HaveLen(size))
+		Expect(data[size/2 : size/2+4096]).To(Equal(bytes.Repeat([]byte{0x55}, 4096)))
+		Expect(bytes.Count(data, []byte{0})).To(Equal(size - 4096))
+	},
+		table.Entry("with falloc", PreallocationFalloc),
+		table.Entry("in full", PreallocationFull),
+	)
+
+	It("should leave the file sparse without preallocation", func() {
+		before := allocated()
+		applied, err := PreallocateFile(path, PreallocationNone)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(Equal(PreallocationNone))
+		Expect(allocated()).To(Equal(before))
+	})
+
+	It("should fail if the file does not exist", func() {
+		_, err := PreallocateFile(path+".missing", PreallocationFull)
+		Expect(err).To(HaveOccurred())
+	})
+})
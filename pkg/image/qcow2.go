@@ -0,0 +1,216 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+)
+
+const (
+	// qcow2HeaderSize is the size of the fields of the header read by Qcow2BackingFile, up to the
+	// size of the name of the backing file
+	qcow2HeaderSize = 20
+	// qcow2MaxBackingFileSize is the maximum size of the name of a backing file, as enforced by qemu
+	qcow2MaxBackingFileSize = 1023
+	// MaxBackingChainLength is the maximum number of backing files followed from an image
+	MaxBackingChainLength = 16
+	// minQcow2ClusterSize and maxQcow2ClusterSize bound the size of the clusters of a qcow2 image
+	minQcow2ClusterSize = 512
+	maxQcow2ClusterSize = 2 << 20
+	// Qcow2HeaderCheckSize is the size of the start of a qcow2 image read by CheckQcow2Header, the
+	// header extensions follow the header in the first cluster
+	Qcow2HeaderCheckSize = 64 << 10
+	// qcow2V2HeaderLength is the length of the header of a version 2 image, version 3 images record
+	// the length of their header
+	qcow2V2HeaderLength = 72
+	// qcow2V3HeaderLength is the minimum length of the header of a version 3 image
+	qcow2V3HeaderLength = 104
+	// qcow2IncompatDataFile is the incompatible feature bit of an image whose data is in an
+	// external data file
+	qcow2IncompatDataFile = 1 << 2
+	// qcow2KnownIncompatFeatures are the incompatible feature bits known to qemu: dirty, corrupt,
+	// external data file, compression type and extended L2 entries
+	qcow2KnownIncompatFeatures = 1<<5 - 1
+	// qcow2ExtEnd and qcow2ExtDataFile are the types of the header extensions ending the list of
+	// extensions and holding the name of the external data file
+	qcow2ExtEnd      = 0
+	qcow2ExtDataFile = 0x44415441
+)
+
+// qcow2CompatLevels are the compatibility levels of a qcow2 image written by qemu-img
+var qcow2CompatLevels = []string{"0.10", "1.1"}
+
+// Qcow2Options are the options of a qcow2 image written by qemu-img, the options left empty take
+// the defaults of qemu-img.
+type Qcow2Options struct {
+	// Compress compresses the clusters of the image
+	Compress bool
+	// ClusterSize is the size of the clusters of the image, a power of two from 512 to 2M
+	ClusterSize string
+	// Compat is the compatibility level of the image, 0.10 or 1.1
+	Compat string
+}
+
+// Qcow2BackingFile returns the name of the backing file recorded in the header of the qcow2 image
+// read from r, and an empty name when the image has no backing file. Data that is not a qcow2 image
+// has no backing file either.
+func Qcow2BackingFile(r io.ReaderAt) (string, error) {
+	hdr := make([]byte, qcow2HeaderSize)
+	if n, err := r.ReadAt(hdr, 0); n < len(hdr) && err != io.EOF {
+		return "", errors.Wrap(err, "could not read the qcow2 header")
+	}
+	if !knownHeaders["qcow2"].Match(hdr) {
+		return "", nil
+	}
+	offset := binary.BigEndian.Uint64(hdr[8:])
+	size := binary.BigEndian.Uint32(hdr[16:])
+	if offset == 0 {
+		return "", nil
+	}
+	if size == 0 || size > qcow2MaxBackingFileSize {
+		return "", errors.Errorf("invalid size %d of the name of the qcow2 backing file", size)
+	}
+	name := make([]byte, size)
+	if _, err := r.ReadAt(name, int64(offset)); err != nil {
+		return "", errors.Wrap(err, "could not read the name of the qcow2 backing file")
+	}
+	return string(name), nil
+}
+
+// CheckQcow2Header checks the header of the qcow2 image starting with data, up to
+// Qcow2HeaderCheckSize bytes, and fails with an ErrUnsupportedFormat FormatError for an image
+// whose data is in an external data file, qemu-img would read or write the file it names, and for
+// an image with incompatible features unknown to qemu. The header extensions are checked as far as
+// they are in data. Data that is not a qcow2 image passes.
+func CheckQcow2Header(data []byte) error {
+	if len(data) < qcow2V2HeaderLength || !knownHeaders["qcow2"].Match(data) {
+		return nil
+	}
+	version := binary.BigEndian.Uint32(data[4:])
+	if version < 3 {
+		// version 2 images have neither feature bits nor external data files
+		return nil
+	}
+	if len(data) < qcow2V3HeaderLength {
+		return errors.New("could not read the qcow2 header")
+	}
+	incompatible := binary.BigEndian.Uint64(data[72:])
+	if incompatible&qcow2IncompatDataFile != 0 {
+		return NewFormatError(ErrUnsupportedFormat, "qcow2", errors.New("image references an external data file, which is not imported along with it"))
+	}
+	if unknown := incompatible &^ qcow2KnownIncompatFeatures; unknown != 0 {
+		return NewFormatError(ErrUnsupportedFormat, "qcow2", errors.Errorf("image has unknown incompatible features %#x", unknown))
+	}
+	offset := uint64(binary.BigEndian.Uint32(data[100:]))
+	for offset+8 <= uint64(len(data)) {
+		extType := binary.BigEndian.Uint32(data[offset:])
+		length := uint64(binary.BigEndian.Uint32(data[offset+4:]))
+		switch extType {
+		case qcow2ExtEnd:
+			return nil
+		case qcow2ExtDataFile:
+			return NewFormatError(ErrUnsupportedFormat, "qcow2", errors.New("image names an external data file, which is not imported along with it"))
+		}
+		// the data of an extension is padded to a multiple of 8 bytes
+		offset += 8 + (length+7)&^7
+	}
+	return nil
+}
+
+// BackingFilePath returns the path of the backing file name referenced by the image at imagePath,
+// once checked to be a file inside dir, the directory the backing chain is extracted to. Absolute
+// names, names escaping dir and the names qemu-img takes for a protocol, with a colon, are rejected.
+func BackingFilePath(dir, imagePath, name string) (string, error) {
+	if path.IsAbs(name) || filepath.IsAbs(name) || strings.Contains(name, ":") {
+		return "", errors.Errorf("backing file %q is not a relative file name", name)
+	}
+	p := filepath.Join(filepath.Dir(imagePath), filepath.FromSlash(name))
+	if rel, err := filepath.Rel(dir, p); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("backing file %q is outside of %s", name, dir)
+	}
+	return p, nil
+}
+
+// Validate returns an error if the cluster size or the compatibility level of the options is not
+// one of the values allowed, no other option is ever passed to qemu-img.
+func (o Qcow2Options) Validate() error {
+	_, err := o.createOptions()
+	return err
+}
+
+// createOptions returns the value of the -o option of qemu-img writing the image, empty when the
+// defaults of qemu-img are taken.
+func (o Qcow2Options) createOptions() (string, error) {
+	var opts []string
+	if o.ClusterSize != "" {
+		size, err := units.RAMInBytes(o.ClusterSize)
+		if err != nil || size < minQcow2ClusterSize || size > maxQcow2ClusterSize || size&(size-1) != 0 {
+			return "", errors.Errorf("invalid qcow2 cluster size %q, a power of two from 512 to 2M is expected", o.ClusterSize)
+		}
+		opts = append(opts, fmt.Sprintf("cluster_size=%d", size))
+	}
+	if o.Compat != "" {
+		valid := false
+		for _, level := range qcow2CompatLevels {
+			valid = valid || o.Compat == level
+		}
+		if !valid {
+			return "", errors.Errorf("invalid qcow2 compat level %q, one of %s is expected", o.Compat, strings.Join(qcow2CompatLevels, ", "))
+		}
+		opts = append(opts, "compat="+o.Compat)
+	}
+	return strings.Join(opts, ","), nil
+}
+
+// String returns the cluster size and the compatibility level of the options as they are passed to
+// qemu-img, followed by compressed=true when the clusters are compressed,
+// cluster_size=65536,compat=1.1 for instance.
+func (o Qcow2Options) String() string {
+	var opts []string
+	if o.ClusterSize != "" {
+		opts = append(opts, "cluster_size="+o.ClusterSize)
+	}
+	if o.Compat != "" {
+		opts = append(opts, "compat="+o.Compat)
+	}
+	if o.Compress {
+		opts = append(opts, "compressed=true")
+	}
+	return strings.Join(opts, ",")
+}
+
+// AppliedQcow2Options returns the cluster size and the compatibility level of the qcow2 image
+// described by info, as reported by qemu-img info.
+func AppliedQcow2Options(info *ImgInfo) Qcow2Options {
+	var o Qcow2Options
+	if info.ClusterSize > 0 {
+		o.ClusterSize = strconv.FormatInt(info.ClusterSize, 10)
+	}
+	if info.FormatSpecific != nil {
+		o.Compat = info.FormatSpecific.Data.Compat
+	}
+	return o
+}
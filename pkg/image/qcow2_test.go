@@ -0,0 +1,163 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// qcow2Image returns the start of a qcow2 image, with the name of its backing file following the
+// header when it has one.
+func qcow2Image(backing string) []byte {
+	img := make([]byte, 512)
+	copy(img, []byte{'Q', 'F', 'I', 0xfb})
+	binary.BigEndian.PutUint32(img[4:], 3)
+	if backing != "" {
+		binary.BigEndian.PutUint64(img[8:], 112)
+		binary.BigEndian.PutUint32(img[16:], uint32(len(backing)))
+		copy(img[112:], backing)
+	}
+	return img
+}
+
+var _ = Describe("Qcow2 backing file", func() {
+	table.DescribeTable("should read the name of the backing file", func(img []byte, expected string) {
+		name, err := Qcow2BackingFile(bytes.NewReader(img))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal(expected))
+	},
+		table.Entry("of an overlay image", qcow2Image("base.qcow2"), "base.qcow2"),
+		table.Entry("of an image without backing file", qcow2Image(""), ""),
+		table.Entry("of data that is not a qcow2 image", bytes.Repeat([]byte{0xaa}, 512), ""),
+		table.Entry("of data smaller than the header", []byte{'Q', 'F', 'I', 0xfb}, ""),
+	)
+
+	table.DescribeTable("should reject an invalid header", func(corrupt func([]byte) []byte) {
+		_, err := Qcow2BackingFile(bytes.NewReader(corrupt(qcow2Image("base.qcow2"))))
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("with an empty name", func(img []byte) []byte {
+			binary.BigEndian.PutUint32(img[16:], 0)
+			return img
+		}),
+		table.Entry("with a name larger than the maximum", func(img []byte) []byte {
+			binary.BigEndian.PutUint32(img[16:], qcow2MaxBackingFileSize+1)
+			return img
+		}),
+		table.Entry("truncated before the name", func(img []byte) []byte { return img[:qcow2HeaderSize] }),
+	)
+
+	table.DescribeTable("should return the path of a backing file", func(imagePath, name, expected string) {
+		p, err := BackingFilePath("/scratch", imagePath, name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p).To(Equal(expected))
+	},
+		table.Entry("next to the image", "/scratch/disk.img", "base.qcow2", "/scratch/base.qcow2"),
+		table.Entry("in a sub directory", "/scratch/disk.img", "images/base.qcow2", "/scratch/images/base.qcow2"),
+		table.Entry("relative to the directory of the image", "/scratch/images/overlay.qcow2", "../base.qcow2", "/scratch/base.qcow2"),
+	)
+
+	table.DescribeTable("should reject a backing file", func(name string) {
+		_, err := BackingFilePath("/scratch", "/scratch/disk.img", name)
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("with an absolute path", "/scratch/base.qcow2"),
+		table.Entry("outside of the directory", "../base.qcow2"),
+		table.Entry("being the directory", "."),
+		table.Entry("read with a protocol", "json:{\"file.driver\":\"http\"}"),
+	)
+})
+
+var _ = Describe("Qcow2 header", func() {
+	// qcow2Header returns the start of a version 3 qcow2 image with the incompatible features and
+	// the header extensions passed in, each one a type and its data
+	qcow2Header := func(incompatible uint64, extensions ...interface{}) []byte {
+		img := qcow2Image("")
+		binary.BigEndian.PutUint64(img[72:], incompatible)
+		binary.BigEndian.PutUint32(img[100:], 104)
+		offset := 104
+		for i := 0; i < len(extensions); i += 2 {
+			data := []byte(extensions[i+1].(string))
+			binary.BigEndian.PutUint32(img[offset:], extensions[i].(uint32))
+			binary.BigEndian.PutUint32(img[offset+4:], uint32(len(data)))
+			copy(img[offset+8:], data)
+			offset += 8 + (len(data)+7)/8*8
+		}
+		return img
+	}
+
+	table.DescribeTable("should accept", func(img []byte) {
+		Expect(CheckQcow2Header(img)).To(Succeed())
+	},
+		table.Entry("an image without features", qcow2Header(0)),
+		table.Entry("an image with the known incompatible features", qcow2Header(1<<0|1<<1|1<<3|1<<4)),
+		table.Entry("an image with other header extensions", qcow2Header(0, uint32(0xe2792aca), "base.qcow2", uint32(0x6803f857), "")),
+		table.Entry("a version 2 image", func() []byte {
+			img := qcow2Image("")
+			binary.BigEndian.PutUint32(img[4:], 2)
+			binary.BigEndian.PutUint64(img[72:], 1<<2)
+			return img
+		}()),
+		table.Entry("an image whose extensions are beyond the data", qcow2Header(0, uint32(0xe2792aca), "raw")[:108]),
+		table.Entry("data that is not a qcow2 image", bytes.Repeat([]byte{0xaa}, 512)),
+	)
+
+	table.DescribeTable("should reject", func(img []byte, expectedErr string) {
+		err := CheckQcow2Header(img)
+		Expect(errors.Is(err, ErrUnsupportedFormat)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+	},
+		table.Entry("an image with an external data file", qcow2Header(1<<2), "image references an external data file"),
+		table.Entry("an image with unknown incompatible features", qcow2Header(1<<5|1<<63), "image has unknown incompatible features 0x8000000000000020"),
+		table.Entry("an image naming a data file in a header extension", qcow2Header(0, uint32(0xe2792aca), "raw", uint32(0x44415441), "/etc/passwd"),
+			"image names an external data file"),
+	)
+
+	It("should fail on a truncated version 3 header", func() {
+		Expect(CheckQcow2Header(qcow2Header(0)[:100])).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("Qcow2 options", func() {
+	table.DescribeTable("should pass the allowed options to qemu-img", func(options Qcow2Options, expected string) {
+		Expect(options.Validate()).To(Succeed())
+		createOptions, err := options.createOptions()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(createOptions).To(Equal(expected))
+	},
+		table.Entry("with the defaults", Qcow2Options{Compress: true}, ""),
+		table.Entry("with the smallest cluster size", Qcow2Options{ClusterSize: "512"}, "cluster_size=512"),
+		table.Entry("with the largest cluster size", Qcow2Options{ClusterSize: "2M"}, "cluster_size=2097152"),
+		table.Entry("with a binary suffix", Qcow2Options{ClusterSize: "64Ki"}, "cluster_size=65536"),
+		table.Entry("with compat 0.10", Qcow2Options{Compat: "0.10"}, "compat=0.10"),
+		table.Entry("with both", Qcow2Options{ClusterSize: "1M", Compat: "1.1"}, "cluster_size=1048576,compat=1.1"),
+	)
+
+	table.DescribeTable("should reject", func(options Qcow2Options) {
+		Expect(options.Validate()).ToNot(Succeed())
+	},
+		table.Entry("a cluster size that is not a power of two", Qcow2Options{ClusterSize: "3k"}),
+		table.Entry("a cluster size smaller than 512", Qcow2Options{ClusterSize: "256"}),
+		table.Entry("a cluster size larger than 2M", Qcow2Options{ClusterSize: "4M"}),
+		table.Entry("a cluster size with another option", Qcow2Options{ClusterSize: "64k,preallocation=full"}),
+		table.Entry("an unknown compat level", Qcow2Options{Compat: "v3"}),
+		table.Entry("a compat level with another option", Qcow2Options{Compat: "1.1,data_file=/dev/sda"}),
+	)
+
+	It("should return the options applied to an image", func() {
+		info, err := ParseImgInfo([]byte(`{"format": "qcow2", "virtual-size": 1048576, "cluster-size": 2097152, "format-specific": {"type": "qcow2", "data": {"compat": "0.10"}}}`), "disk.img")
+		Expect(err).ToNot(HaveOccurred())
+		options := AppliedQcow2Options(info)
+		Expect(options).To(Equal(Qcow2Options{ClusterSize: "2097152", Compat: "0.10"}))
+		Expect(options.String()).To(Equal("cluster_size=2097152,compat=0.10"))
+	})
+
+	It("should report the compression of the clusters", func() {
+		Expect(Qcow2Options{Compress: true, ClusterSize: "65536", Compat: "1.1"}.String()).To(Equal("cluster_size=65536,compat=1.1,compressed=true"))
+		Expect(Qcow2Options{Compress: true}.String()).To(Equal("compressed=true"))
+	})
+})
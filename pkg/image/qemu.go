@@ -21,31 +21,76 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"kubevirt.io/containerized-data-importer/pkg/monitoring"
 
 	"github.com/docker/go-units"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
-	dto "github.com/prometheus/client_model/go"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/system"
 	"kubevirt.io/containerized-data-importer/pkg/util"
+	prometheusutil "kubevirt.io/containerized-data-importer/pkg/util/prometheus"
 )
 
 const (
 	networkTimeoutSecs = 3600    //max is 10000
 	maxMemory          = 1 << 30 //value from OpenStack Nova
 	maxCPUSecs         = 30      //value from OpenStack Nova
-	matcherString      = "\\((\\d?\\d\\.\\d\\d)\\/100%\\)"
+	matcherString      = "\\((\\d{1,3}\\.\\d\\d)\\/100%\\)"
+
+	// QemuFormatVhd is the name of the vhd format in qemu-img. It has to be passed in for a fixed vhd,
+	// qemu-img only probes the copy of the footer starting a dynamic one.
+	QemuFormatVhd = "vpc"
+	// QemuFormatQed is the name of the QEMU Enhanced Disk format in qemu-img
+	QemuFormatQed = "qed"
+	// QemuFormatParallels is the name of the Parallels disk image format in qemu-img
+	QemuFormatParallels = "parallels"
+	// QemuFormatRaw is the name of the raw format in qemu-img
+	QemuFormatRaw = "raw"
+	// QemuFormatQcow2 is the name of the qcow2 format in qemu-img
+	QemuFormatQcow2 = "qcow2"
+
+	// qemuKeySecretID is the id of the secret object holding the passphrase of an encrypted image
+	qemuKeySecretID = "sec0"
+	// qemuInvalidPassword is reported by qemu-img when no LUKS key slot is unlocked by the passphrase
+	qemuInvalidPassword = "Invalid password"
+
+	// exit codes of qemu-img check
+	qemuCheckCorruptions  = 2
+	qemuCheckLeaks        = 3
+	qemuCheckNotSupported = 63
+	// qemuCheckReportLines is the number of lines of the report of qemu-img check kept in its error
+	qemuCheckReportLines = 8
 )
 
+// PreallocationMode is the preallocation of the space of a raw disk image
+type PreallocationMode string
+
+const (
+	// PreallocationNone leaves the disk image sparse
+	PreallocationNone PreallocationMode = ""
+	// PreallocationFalloc allocates the space of the disk image with fallocate, without writing it
+	PreallocationFalloc PreallocationMode = "falloc"
+	// PreallocationFull writes zeros to the space of the disk image that is not allocated
+	PreallocationFull PreallocationMode = "full"
+)
+
+// preallocationMethod is the qemu-img arguments of a preallocation mode
+type preallocationMethod struct {
+	mode PreallocationMode
+	args []string
+}
+
 // ImgInfo contains the virtual image information.
 type ImgInfo struct {
 	// Format contains the format of the image
@@ -56,17 +101,47 @@ type ImgInfo struct {
 	VirtualSize int64 `json:"virtual-size"`
 	// ActualSize is the size of the qcow2 image
 	ActualSize int64 `json:"actual-size"`
+	// ClusterSize is the size of the clusters of the image, 0 for a format without clusters
+	ClusterSize int64 `json:"cluster-size,omitempty"`
+	// Encrypted is set when the data of the image is encrypted
+	Encrypted bool `json:"encrypted"`
+	// FormatSpecific contains the information specific to the format of the image
+	FormatSpecific *ImgFormatSpecific `json:"format-specific,omitempty"`
+}
+
+// ImgFormatSpecific contains the information specific to the format of an image.
+type ImgFormatSpecific struct {
+	// Type is the format of the image
+	Type string `json:"type"`
+	Data struct {
+		// CreateType is the subformat of a vmdk image
+		CreateType string `json:"create-type,omitempty"`
+		// Compat is the compatibility level of a qcow2 image, 0.10 or 1.1
+		Compat string `json:"compat,omitempty"`
+		// DataFile is the name of the external data file of a qcow2 image, empty when its data is in
+		// the image
+		DataFile string `json:"data-file,omitempty"`
+		// Encrypt contains the encryption of a qcow2 image
+		Encrypt struct {
+			// Format is the encryption format, luks or the legacy aes
+			Format string `json:"format"`
+		} `json:"encrypt"`
+	} `json:"data"`
 }
 
 // QEMUOperations defines the interface for executing qemu subprocesses
 type QEMUOperations interface {
-	ConvertToRawStream(*url.URL, string, bool) error
-	Resize(string, resource.Quantity, bool) error
+	ConvertToRawStream(*url.URL, string, string, PreallocationMode, string) (PreallocationMode, error)
+	ConvertToQcow2Stream(*url.URL, string, string, Qcow2Options, string) error
+	Resize(string, string, resource.Quantity, PreallocationMode) (PreallocationMode, error)
 	Info(url *url.URL) (*ImgInfo, error)
-	Validate(*url.URL, int64) error
-	CreateBlankImage(string, resource.Quantity, bool) error
+	Validate(*url.URL, string, int64, bool, string) error
+	Check(*url.URL, string, string) error
+	CreateBlankImage(string, resource.Quantity, PreallocationMode) (PreallocationMode, error)
 	Rebase(backingFile string, delta string) error
 	Commit(image string) error
+	ConvertRawImage(src, dest, format, createOptions, keyFile string) error
+	CreateOverlay(backingFile, dest string) error
 }
 
 type qemuOperations struct{}
@@ -76,6 +151,7 @@ var (
 	qemuInfoLimits   = &system.ProcessLimitValues{AddressSpaceLimit: maxMemory, CPUTimeLimit: maxCPUSecs}
 	qemuIterface     = NewQEMUOperations()
 	re               = regexp.MustCompile(matcherString)
+	isBlockDevice    = blockDevice
 
 	progress = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -85,14 +161,19 @@ var (
 		[]string{"ownerUID"},
 	)
 	ownerUID                    string
-	convertPreallocationMethods = [][]string{
-		{"-o", "preallocation=falloc"},
-		{"-o", "preallocation=full"},
-		{"-S", "0"},
+	convertProgressStart        float64
+	convertPreallocationMethods = []preallocationMethod{
+		{PreallocationFalloc, []string{"-o", "preallocation=falloc"}},
+		{PreallocationFull, []string{"-o", "preallocation=full"}},
+		{PreallocationFull, []string{"-S", "0"}},
+	}
+	// blockConvertPreallocationMethods preallocate a block device, the zeros are written to it
+	blockConvertPreallocationMethods = []preallocationMethod{
+		{PreallocationFull, []string{"-S", "0"}},
 	}
-	resizePreallocationMethods = [][]string{
-		{"--preallocation=falloc"},
-		{"--preallocation=full"},
+	resizePreallocationMethods = []preallocationMethod{
+		{PreallocationFalloc, []string{"--preallocation=falloc"}},
+		{PreallocationFull, []string{"--preallocation=full"}},
 	}
 )
 
@@ -114,35 +195,122 @@ func NewQEMUOperations() QEMUOperations {
 	return &qemuOperations{}
 }
 
-func convertToRaw(src, dest string, preallocate bool) error {
-	args := []string{"convert", "-t", "writeback", "-p", "-O", "raw", src, dest}
-	var err error
+func convertToRaw(src, format, dest string, preallocation PreallocationMode, keyFile string) (PreallocationMode, error) {
+	return convertImage(src, format, dest, QemuFormatRaw, nil, preallocation, keyFile)
+}
 
-	if preallocate {
-		err = addPreallocation(args, convertPreallocationMethods, func(args []string) ([]byte, error) {
-			return qemuExecFunction(nil, reportProgress, "qemu-img", args...)
-		})
+// convertToQcow2 writes the image to dest in the qcow2 format with the options passed in. The qcow2
+// image is never preallocated.
+func convertToQcow2(src, format, dest string, options Qcow2Options, keyFile string) error {
+	createOptions, err := options.createOptions()
+	if err != nil {
+		return NewFormatError(ErrUnsupportedFormat, QemuFormatQcow2, err)
+	}
+	var outputOptions []string
+	if createOptions != "" {
+		outputOptions = append(outputOptions, "-o", createOptions)
+	}
+	if options.Compress {
+		outputOptions = append(outputOptions, "-c")
+	}
+	_, err = convertImage(src, format, dest, QemuFormatQcow2, outputOptions, PreallocationNone, keyFile)
+	return err
+}
+
+// convertImage converts src to dest with the options of the output format passed in, and returns the
+// preallocation mode applied to dest.
+func convertImage(src, format, dest, outputFormat string, outputOptions []string, preallocation PreallocationMode, keyFile string) (PreallocationMode, error) {
+	res, err := resources()
+	if err != nil {
+		return PreallocationNone, err
+	}
+	args := append([]string{"convert"}, res.convertArgs()...)
+	args = append(args, "-p")
+	outputArgs := append([]string{"-O", outputFormat}, outputOptions...)
+	blockDest := isBlockDevice(dest)
+	preallocationMethods := convertPreallocationMethods
+	if blockDest {
+		// the image is written straight to the device rather than created, with writes out of order,
+		// and the zeros are written with write zeroes requests the device may discard
+		outputArgs = append(outputArgs, "-n", "-W")
+		preallocationMethods = blockConvertPreallocationMethods
+	}
+	if keyFile != "" {
+		args = append(args, encryptedImageArgs(src, keyFile)...)
+		args = append(append(args, outputArgs...), dest)
+	} else {
+		args = append(args, formatArgs(format)...)
+		args = append(append(args, outputArgs...), src, dest)
+	}
+	var output []byte
+	convert := func(args []string) ([]byte, error) {
+		output, err = execQemu(nil, reportProgress, args...)
+		return output, err
+	}
+
+	applied := PreallocationNone
+	if preallocation != PreallocationNone {
+		applied, err = addPreallocation(args, preallocationMethods, preallocation, convert)
 	} else {
 		klog.V(3).Infof("Running qemu-img convert with args: %v", args)
-		_, err = qemuExecFunction(nil, reportProgress, "qemu-img", args...)
+		_, err = convert(args)
 	}
 	if err != nil {
-		os.Remove(dest)
-		errorMsg := "could not convert image to raw"
+		if !blockDest {
+			os.Remove(dest)
+		}
+		// the output of qemu-img is left out, the import fails with the cause alone
+		if keyFile != "" && strings.Contains(string(output), qemuInvalidPassword) {
+			return PreallocationNone, NewFormatError(ErrInvalidEncryptionKey, "qcow2", errors.New("could not unlock the image with the passphrase of its encryption secret"))
+		}
+		errorMsg := "could not convert image to " + outputFormat
 		if nbdkitLog, err := os.ReadFile(common.NbdkitLogPath); err == nil {
 			errorMsg += " " + string(nbdkitLog)
 		}
-		return errors.Wrap(err, errorMsg)
+		return PreallocationNone, errors.Wrap(err, errorMsg)
 	}
 
-	return nil
+	return checkFallocate(dest, applied), nil
 }
 
-func (o *qemuOperations) ConvertToRawStream(url *url.URL, dest string, preallocate bool) error {
+// blockDevice returns true if path is a block device.
+func blockDevice(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeDevice != 0 && info.Mode()&os.ModeCharDevice == 0
+}
+
+func (o *qemuOperations) ConvertToRawStream(url *url.URL, format, dest string, preallocation PreallocationMode, keyFile string) (PreallocationMode, error) {
+	if len(url.Scheme) > 0 && url.Scheme != "nbd+unix" {
+		return PreallocationNone, fmt.Errorf("not valid schema %s", url.Scheme)
+	}
+	return convertToRaw(url.String(), format, dest, preallocation, keyFile)
+}
+
+func (o *qemuOperations) ConvertToQcow2Stream(url *url.URL, format, dest string, options Qcow2Options, keyFile string) error {
 	if len(url.Scheme) > 0 && url.Scheme != "nbd+unix" {
 		return fmt.Errorf("not valid schema %s", url.Scheme)
 	}
-	return convertToRaw(url.String(), dest, preallocate)
+	return convertToQcow2(url.String(), format, dest, options, keyFile)
+}
+
+// encryptedImageArgs returns the arguments opening the LUKS-encrypted qcow2 image src, decrypted
+// with the passphrase read by qemu-img from keyFile. The passphrase is never on the command line.
+func encryptedImageArgs(src, keyFile string) []string {
+	// commas are doubled in the values of qemu-img options
+	escape := func(s string) string { return strings.ReplaceAll(s, ",", ",,") }
+	return []string{
+		"--object", fmt.Sprintf("secret,id=%s,file=%s", qemuKeySecretID, escape(keyFile)),
+		"--image-opts", fmt.Sprintf("driver=qcow2,encrypt.key-secret=%s,file.filename=%s", qemuKeySecretID, escape(src)),
+	}
+}
+
+// formatArgs returns the arguments passing the format of the source image to qemu-img, none when
+// the format is empty and probed by qemu-img.
+func formatArgs(format string) []string {
+	if format == "" {
+		return nil
+	}
+	return []string{"-f", format}
 }
 
 // convertQuantityToQemuSize translates a quantity string into a Qemu compatible string.
@@ -155,28 +323,31 @@ func convertQuantityToQemuSize(size resource.Quantity) string {
 	return strconv.FormatInt(int64Size, 10)
 }
 
-// Resize resizes the given image to size
-func Resize(image string, size resource.Quantity, preallocate bool) error {
-	return qemuIterface.Resize(image, size, preallocate)
+// Resize resizes the given image of the raw or qcow2 format to size, the space added to the image is
+// preallocated with the preallocation mode passed in. It returns the preallocation mode applied.
+func Resize(image, format string, size resource.Quantity, preallocation PreallocationMode) (PreallocationMode, error) {
+	return qemuIterface.Resize(image, format, size, preallocation)
 }
 
-func (o *qemuOperations) Resize(image string, size resource.Quantity, preallocate bool) error {
+func (o *qemuOperations) Resize(image, format string, size resource.Quantity, preallocation PreallocationMode) (PreallocationMode, error) {
 	var err error
-	args := []string{"resize", "-f", "raw", image, convertQuantityToQemuSize(size)}
-	if preallocate {
-		err = addPreallocation(args, resizePreallocationMethods, func(args []string) ([]byte, error) {
-			return qemuExecFunction(nil, nil, "qemu-img", args...)
+	applied := PreallocationNone
+	args := []string{"resize", "-f", format, image, convertQuantityToQemuSize(size)}
+	if preallocation != PreallocationNone {
+		applied, err = addPreallocation(args, resizePreallocationMethods, preallocation, func(args []string) ([]byte, error) {
+			return execQemu(nil, nil, args...)
 		})
 	} else {
-		_, err = qemuExecFunction(nil, nil, "qemu-img", args...)
+		_, err = execQemu(nil, nil, args...)
 	}
 	if err != nil {
-		return errors.Wrapf(err, "Error resizing image %s", image)
+		return PreallocationNone, errors.Wrapf(err, "Error resizing image %s", image)
 	}
-	return nil
+	return checkFallocate(image, applied), nil
 }
 
-func checkOutputQemuImgInfo(output []byte, image string) (*ImgInfo, error) {
+// ParseImgInfo returns the information about the image printed by qemu-img info --output=json.
+func ParseImgInfo(output []byte, image string) (*ImgInfo, error) {
 	var info ImgInfo
 	err := json.Unmarshal(output, &info)
 	if err != nil {
@@ -184,7 +355,6 @@ func checkOutputQemuImgInfo(output []byte, image string) (*ImgInfo, error) {
 		return nil, errors.Wrapf(err, "Invalid json for image %s", image)
 	}
 	return &info, nil
-
 }
 
 // Info returns information about the image from the url
@@ -192,11 +362,24 @@ func Info(url *url.URL) (*ImgInfo, error) {
 	return qemuIterface.Info(url)
 }
 
+// QemuImgInfo returns information about the local image file at path, whose format is probed.
+func QemuImgInfo(path string) (*ImgInfo, error) {
+	return infoWithFormat(&url.URL{Path: path}, "")
+}
+
 func (o *qemuOperations) Info(url *url.URL) (*ImgInfo, error) {
+	return infoWithFormat(url, "")
+}
+
+// infoWithFormat returns information about the image from the url, read as an image of the format
+// passed in unless it is empty.
+func infoWithFormat(url *url.URL, format string) (*ImgInfo, error) {
 	if len(url.Scheme) > 0 && url.Scheme != "nbd+unix" && url.Scheme != "file" {
 		return nil, fmt.Errorf("not valid schema %s", url.Scheme)
 	}
-	output, err := qemuExecFunction(qemuInfoLimits, nil, "qemu-img", "info", "--output=json", url.String())
+	args := append([]string{"info"}, formatArgs(format)...)
+	args = append(args, "--output=json", url.String())
+	output, err := execQemu(qemuInfoLimits, nil, args...)
 	if err != nil {
 		errorMsg := fmt.Sprintf("%s, %s", output, err.Error())
 		if nbdkitLog, err := os.ReadFile(common.NbdkitLogPath); err == nil {
@@ -204,12 +387,12 @@ func (o *qemuOperations) Info(url *url.URL) (*ImgInfo, error) {
 		}
 		return nil, errors.Errorf(errorMsg)
 	}
-	return checkOutputQemuImgInfo(output, url.String())
+	return ParseImgInfo(output, url.String())
 }
 
 func isSupportedFormat(value string) bool {
 	switch value {
-	case "raw", "qcow2", "vmdk", "vdi", "vpc", "vhdx":
+	case "raw", "qcow2", "vmdk", "vdi", "vpc", "vhdx", "qed", "parallels":
 		return true
 	default:
 		return false
@@ -221,9 +404,9 @@ func checkIfURLIsValid(info *ImgInfo, availableSize int64, image string) error {
 		return errors.Errorf("Invalid format %s for image %s", info.Format, image)
 	}
 
-	if len(info.BackingFile) > 0 {
-		if _, err := os.Stat(info.BackingFile); err != nil {
-			return errors.Errorf("Image %s is invalid because it has invalid backing file %s", image, info.BackingFile)
+	if info.Format == "vmdk" {
+		if err := checkVmdkCreateType(info, image); err != nil {
+			return err
 		}
 	}
 
@@ -233,24 +416,181 @@ func checkIfURLIsValid(info *ImgInfo, availableSize int64, image string) error {
 	return nil
 }
 
-func (o *qemuOperations) Validate(url *url.URL, availableSize int64) error {
-	info, err := o.Info(url)
+// checkVmdkCreateType fails for a vmdk image whose data is in external extent files, they are not
+// imported along with it. Older versions of qemu-img do not report the create type, the image is
+// then converted and qemu-img fails to open the missing extents.
+func checkVmdkCreateType(info *ImgInfo, image string) error {
+	if info.FormatSpecific == nil || info.FormatSpecific.Data.CreateType == "" || vmdkCreateTypes[info.FormatSpecific.Data.CreateType] {
+		return nil
+	}
+	return NewFormatError(ErrUnsupportedFormat, "vmdk", errors.Errorf("image %s of create type %s references external extent files, only monolithicSparse and streamOptimized vmdk images can be imported",
+		image, info.FormatSpecific.Data.CreateType))
+}
+
+// checkBackingChain fails for an image referencing a backing file, which is not imported along with
+// it, unless flattening the chain was requested. The backing files are then checked to be extracted
+// next to the local image, qemu-img reads them while converting it.
+func checkBackingChain(src *url.URL, info *ImgInfo, flattenChain bool) error {
+	if info.BackingFile == "" {
+		return nil
+	}
+	if !flattenChain || (src.Scheme != "" && src.Scheme != "file") {
+		return NewFormatError(ErrUnsupportedFormat, info.Format, errors.Errorf("image %s references the backing file %s, which is not imported along with it, flatten the image first or import it from an archive holding its backing chain with the %sflattenBackingChain annotation",
+			src, info.BackingFile, common.CDIAnnKey))
+	}
+	dir := filepath.Dir(src.Path)
+	imagePath := src.Path
+	for backing, n := info.BackingFile, 0; backing != ""; n++ {
+		if n == MaxBackingChainLength {
+			return NewFormatError(ErrUnsupportedFormat, info.Format, errors.Errorf("backing chain of image %s is longer than %d images", src, MaxBackingChainLength))
+		}
+		backingPath, err := BackingFilePath(dir, imagePath, backing)
+		if err != nil {
+			return NewFormatError(ErrUnsupportedFormat, info.Format, err)
+		}
+		backingInfo, err := QemuImgInfo(backingPath)
+		if err != nil {
+			return errors.Wrapf(err, "could not read the backing file %s of image %s", backing, src)
+		}
+		if err := checkDataFile(&url.URL{Path: backingPath}, backingInfo); err != nil {
+			return err
+		}
+		klog.V(1).Infof("flattening the backing file %s of %s\n", backingPath, imagePath)
+		imagePath, backing = backingPath, backingInfo.BackingFile
+	}
+	return nil
+}
+
+// checkDataFile fails for a qcow2 image whose data is in an external data file, which is not
+// imported along with it and would be read, or written, by qemu-img wherever it is.
+func checkDataFile(src *url.URL, info *ImgInfo) error {
+	if info.FormatSpecific == nil || info.FormatSpecific.Data.DataFile == "" {
+		return nil
+	}
+	return NewFormatError(ErrUnsupportedFormat, info.Format, errors.Errorf("image %s references the external data file %s, which is not imported along with it",
+		src, info.FormatSpecific.Data.DataFile))
+}
+
+// checkEncryption fails for an encrypted image without the file of its passphrase, keyFile, and for
+// an image that is not encrypted with one. Only the LUKS encryption of qcow2 images is supported.
+func checkEncryption(src *url.URL, info *ImgInfo, keyFile string) error {
+	switch {
+	case !info.Encrypted && keyFile == "":
+		return nil
+	case !info.Encrypted:
+		return NewFormatError(ErrInvalidEncryptionKey, info.Format, errors.Errorf("image %s is not encrypted, remove the encryption secret from the source", src))
+	case info.Format != "qcow2" || info.FormatSpecific == nil || info.FormatSpecific.Data.Encrypt.Format != "luks":
+		return NewFormatError(ErrUnsupportedFormat, info.Format, errors.Errorf("image %s is not encrypted with LUKS, only LUKS-encrypted qcow2 images can be imported", src))
+	case keyFile == "":
+		return NewFormatError(ErrInvalidEncryptionKey, info.Format, errors.Errorf("image %s is encrypted, reference the secret holding its passphrase with the encryptionSecretRef of the source", src))
+	}
+	return nil
+}
+
+func (o *qemuOperations) Validate(url *url.URL, format string, availableSize int64, flattenChain bool, keyFile string) error {
+	info, err := infoWithFormat(url, format)
 	if err != nil {
 		return err
 	}
-	return checkIfURLIsValid(info, availableSize, url.String())
+	if err := checkIfURLIsValid(info, availableSize, url.String()); err != nil {
+		return err
+	}
+	if err := checkDataFile(url, info); err != nil {
+		return err
+	}
+	if err := checkEncryption(url, info, keyFile); err != nil {
+		return err
+	}
+	return checkBackingChain(url, info, flattenChain)
 }
 
-// ConvertToRawStream converts an http accessible image to raw format without locally caching the image
-func ConvertToRawStream(url *url.URL, dest string, preallocate bool) error {
-	return qemuIterface.ConvertToRawStream(url, dest, preallocate)
+func (o *qemuOperations) Check(url *url.URL, format, keyFile string) error {
+	args := []string{"check"}
+	if keyFile != "" {
+		args = append(args, encryptedImageArgs(url.String(), keyFile)...)
+	} else {
+		args = append(args, formatArgs(format)...)
+		args = append(args, url.String())
+	}
+	// the report is written to stdout, which is not returned when qemu-img check fails
+	var mutex sync.Mutex
+	var report []string
+	collect := func(line string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if line = strings.TrimSpace(line); line != "" {
+			report = append(report, line)
+		}
+	}
+	klog.V(3).Infof("Running qemu-img check with args: %v", args)
+	_, err := execQemu(nil, collect, args...)
+	if err == nil {
+		return nil
+	}
+	if len(report) > qemuCheckReportLines {
+		report = report[len(report)-qemuCheckReportLines:]
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return errors.Wrap(err, "could not check image")
+	}
+	switch exitErr.ExitCode() {
+	case qemuCheckNotSupported:
+		klog.V(1).Infof("The format of %s does not support checks, skipping the check", url.String())
+		return nil
+	case qemuCheckCorruptions, qemuCheckLeaks:
+		if format == "" {
+			format = "image"
+		}
+		return NewFormatError(ErrCorruptImage, format, errors.Errorf("qemu-img check: %s", strings.Join(report, "; ")))
+	default:
+		return errors.Wrapf(err, "could not check image: %s", strings.Join(report, "; "))
+	}
 }
 
-// Validate does basic validation of a qemu image
-func Validate(url *url.URL, availableSize int64) error {
-	return qemuIterface.Validate(url, availableSize)
+// ConvertToRawStream converts an http accessible image to raw format without locally caching the
+// image. The format of the image is probed by qemu-img when empty. A LUKS-encrypted qcow2 image is
+// decrypted with the passphrase read from keyFile, unless it is empty. The raw image is preallocated
+// with the preallocation mode passed in, or the next one supported, and the mode applied is returned.
+// A block device dest is written as it is, and zeros are written to it to preallocate it.
+func ConvertToRawStream(url *url.URL, format, dest string, preallocation PreallocationMode, keyFile string) (PreallocationMode, error) {
+	return qemuIterface.ConvertToRawStream(url, format, dest, preallocation, keyFile)
 }
 
+// ConvertToQcow2Stream converts an http accessible image to the qcow2 format without locally caching
+// the image, the qcow2 image is written with the options passed in. The format of the image and its
+// encryption are handled as by ConvertToRawStream.
+func ConvertToQcow2Stream(url *url.URL, format, dest string, options Qcow2Options, keyFile string) error {
+	return qemuIterface.ConvertToQcow2Stream(url, format, dest, options, keyFile)
+}
+
+// Check checks the consistency of a qemu image with qemu-img check. The format and the encryption of
+// the image are handled as by Validate. An image whose format does not support checks passes, and an
+// image with corruptions or leaked clusters fails with an ErrCorruptImage FormatError holding the end
+// of the report of qemu-img.
+func Check(url *url.URL, format, keyFile string) error {
+	return qemuIterface.Check(url, format, keyFile)
+}
+
+// Validate does basic validation of a qemu image. The format of the image is probed by qemu-img
+// when empty. An image referencing a backing file is rejected, unless flattenChain is set and its
+// backing chain was extracted next to it. A qcow2 image, or backing file, referencing an external data
+// file is rejected. An encrypted image is rejected unless keyFile, the file of its passphrase, is
+// passed in, and an image that is not encrypted is rejected if it is.
+func Validate(url *url.URL, format string, availableSize int64, flattenChain bool, keyFile string) error {
+	return qemuIterface.Validate(url, format, availableSize, flattenChain, keyFile)
+}
+
+// SetConvertProgressStart sets the progress, in percent, reported when qemu-img starts a conversion.
+// The progress printed by qemu-img is scaled to the rest of the range, when the conversion is the
+// second part of an import copying the data to scratch space first.
+func SetConvertProgressStart(start float64) {
+	convertProgressStart = start
+}
+
+// reportProgress reports the progress printed by qemu-img, the lines updated with a carriage return
+// are split by the caller. The progress never goes backwards, a conversion retried with another
+// preallocation mode only reports it again once it gets further.
 func reportProgress(line string) {
 	// (45.34/100%)
 	matches := re.FindStringSubmatch(line)
@@ -258,32 +598,30 @@ func reportProgress(line string) {
 		klog.V(1).Info(matches[1])
 		// Don't need to check for an error, the regex made sure its a number we can parse.
 		v, _ := strconv.ParseFloat(matches[1], 64)
-		metric := &dto.Metric{}
-		err := progress.WithLabelValues(ownerUID).Write(metric)
-		if err == nil && v > 0 && v > *metric.Counter.Value {
-			progress.WithLabelValues(ownerUID).Add(v - *metric.Counter.Value)
+		if v > 0 {
+			prometheusutil.SetProgress(progress, ownerUID, convertProgressStart+v*(100-convertProgressStart)/100)
 		}
 	}
 }
 
-// CreateBlankImage creates empty raw image
-func CreateBlankImage(dest string, size resource.Quantity, preallocate bool) error {
-	klog.V(1).Infof("creating raw image with size %s, preallocation %v", size.String(), preallocate)
-	return qemuIterface.CreateBlankImage(dest, size, preallocate)
+// CreateBlankImage creates empty raw image, and returns the preallocation mode applied to it
+func CreateBlankImage(dest string, size resource.Quantity, preallocation PreallocationMode) (PreallocationMode, error) {
+	klog.V(1).Infof("creating raw image with size %s, preallocation %q", size.String(), preallocation)
+	return qemuIterface.CreateBlankImage(dest, size, preallocation)
 }
 
 // CreateBlankImage creates a raw image with a given size
-func (o *qemuOperations) CreateBlankImage(dest string, size resource.Quantity, preallocate bool) error {
+func (o *qemuOperations) CreateBlankImage(dest string, size resource.Quantity, preallocation PreallocationMode) (PreallocationMode, error) {
 	klog.V(3).Infof("image size is %s", size.String())
 	args := []string{"create", "-f", "raw", dest, convertQuantityToQemuSize(size)}
-	if preallocate {
+	if preallocation != PreallocationNone {
 		klog.V(1).Infof("Added preallocation")
-		args = append(args, []string{"-o", "preallocation=falloc"}...)
+		args = append(args, []string{"-o", "preallocation=" + string(preallocation)}...)
 	}
-	_, err := qemuExecFunction(nil, nil, "qemu-img", args...)
+	_, err := execQemu(nil, nil, args...)
 	if err != nil {
 		os.Remove(dest)
-		return errors.Wrap(err, fmt.Sprintf("could not create raw image with size %s in %s", size.String(), dest))
+		return PreallocationNone, errors.Wrap(err, fmt.Sprintf("could not create raw image with size %s in %s", size.String(), dest))
 	}
 	// Change permissions to 0660
 	err = os.Chmod(dest, 0660)
@@ -291,7 +629,7 @@ func (o *qemuOperations) CreateBlankImage(dest string, size resource.Quantity, p
 		err = errors.Wrap(err, "Unable to change permissions of target file")
 	}
 
-	return nil
+	return checkFallocate(dest, preallocation), nil
 }
 
 func execPreallocation(dest string, bs, count, offset int64) error {
@@ -323,17 +661,25 @@ func PreallocateBlankBlock(dest string, size resource.Quantity) error {
 	return nil
 }
 
-func addPreallocation(args []string, preallocationMethods [][]string, qemuFn func(args []string) ([]byte, error)) error {
+// addPreallocation runs qemuFn with the preallocation methods of the mode passed in, and of the
+// modes after it, until one is supported. It returns the mode of the method run last.
+func addPreallocation(args []string, preallocationMethods []preallocationMethod, preallocation PreallocationMode, qemuFn func(args []string) ([]byte, error)) (PreallocationMode, error) {
 	var err error
+	applied := PreallocationNone
 	for _, preallocationMethod := range preallocationMethods {
 		var output []byte
 
-		klog.V(1).Infof("Adding preallocation method: %v", preallocationMethod)
+		// falloc falls back to full, full never degrades to falloc
+		if preallocation == PreallocationFull && preallocationMethod.mode == PreallocationFalloc {
+			continue
+		}
+		klog.V(1).Infof("Adding preallocation method: %v", preallocationMethod.args)
 		// For some subcommands (e.g. resize), preallocation optinos must come before other options
-		argsToTry := append([]string{args[0]}, preallocationMethod...)
+		argsToTry := append([]string{args[0]}, preallocationMethod.args...)
 		argsToTry = append(argsToTry, args[1:]...)
 		klog.V(3).Infof("Attempting preallocation method, qemu-img convert args: %v", argsToTry)
 
+		applied = preallocationMethod.mode
 		output, err = qemuFn(argsToTry)
 		if err != nil && strings.Contains(string(output), "Unsupported preallocation mode") {
 			klog.V(1).Infof("Unsupported preallocation mode. Retrying")
@@ -342,7 +688,7 @@ func addPreallocation(args []string, preallocationMethods [][]string, qemuFn fun
 		}
 	}
 
-	return err
+	return applied, err
 }
 
 // Rebase changes a QCOW's backing file to point to a previously-downloaded base image.
@@ -350,7 +696,7 @@ func addPreallocation(args []string, preallocationMethods [][]string, qemuFn fun
 func (o *qemuOperations) Rebase(backingFile string, delta string) error {
 	klog.V(1).Infof("Rebasing %s onto %s", delta, backingFile)
 	args := []string{"rebase", "-p", "-u", "-F", "raw", "-b", backingFile, delta}
-	_, err := qemuExecFunction(nil, reportProgress, "qemu-img", args...)
+	_, err := execQemu(nil, reportProgress, args...)
 	return err
 }
 
@@ -358,6 +704,35 @@ func (o *qemuOperations) Rebase(backingFile string, delta string) error {
 func (o *qemuOperations) Commit(image string) error {
 	klog.V(1).Infof("Committing %s to backing file...", image)
 	args := []string{"commit", "-p", image}
-	_, err := qemuExecFunction(nil, reportProgress, "qemu-img", args...)
+	_, err := execQemu(nil, reportProgress, args...)
 	return err
 }
+
+// ConvertRawImage writes the raw image src to dest in the format passed in, with the creation options passed in such
+// as subformat=streamOptimized. Unless keyFile is empty, dest is encrypted with LUKS with the passphrase it holds.
+func (o *qemuOperations) ConvertRawImage(src, dest, format, createOptions, keyFile string) error {
+	args := []string{"convert", "-f", QemuFormatRaw, "-O", format}
+	if keyFile != "" {
+		args = append(args, "--object", fmt.Sprintf("secret,id=%s,file=%s", qemuKeySecretID, strings.ReplaceAll(keyFile, ",", ",,")))
+		createOptions = strings.TrimPrefix(createOptions+",encrypt.format=luks,encrypt.key-secret="+qemuKeySecretID, ",")
+	}
+	if createOptions != "" {
+		args = append(args, "-o", createOptions)
+	}
+	args = append(args, src, dest)
+	if _, err := execQemu(nil, nil, args...); err != nil {
+		os.Remove(dest)
+		return errors.Wrapf(err, "could not convert %s to %s", src, format)
+	}
+	return nil
+}
+
+// CreateOverlay creates the qcow2 image dest holding no data, over the qcow2 image backingFile. A relative backingFile
+// is referenced as it is, from the directory of dest.
+func (o *qemuOperations) CreateOverlay(backingFile, dest string) error {
+	args := []string{"create", "-f", QemuFormatQcow2, "-b", backingFile, "-F", QemuFormatQcow2, dest}
+	if _, err := execQemu(nil, nil, args...); err != nil {
+		return errors.Wrapf(err, "could not create the overlay %s over %s", dest, backingFile)
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -121,6 +122,118 @@ const backingFileValidateJSON = `
 }
 `
 
+const dataFileValidateJSON = `
+{
+    "virtual-size": 4294967296,
+    "filename": "myimage.qcow2",
+    "cluster-size": 65536,
+    "format": "qcow2",
+    "actual-size": 262152192,
+    "format-specific": {
+        "type": "qcow2",
+        "data": {
+            "compat": "1.1",
+            "data-file": "/etc/shadow",
+            "data-file-raw": false,
+            "refcount-bits": 16
+        }
+    },
+    "dirty-flag": false
+}
+`
+
+const streamOptimizedVmdkValidateJSON = `
+{
+    "virtual-size": 4294967296,
+    "filename": "myimage.vmdk",
+    "cluster-size": 65536,
+    "format": "vmdk",
+    "actual-size": 262152192,
+    "format-specific": {
+        "type": "vmdk",
+        "data": {
+            "cid": 2779700067,
+            "parent-cid": 4294967295,
+            "create-type": "streamOptimized",
+            "extents": [
+                {
+                    "compressed": true,
+                    "virtual-size": 4294967296,
+                    "filename": "myimage.vmdk",
+                    "cluster-size": 65536,
+                    "format": "SPARSE"
+                }
+            ]
+        }
+    },
+    "dirty-flag": false
+}
+`
+
+const multiExtentVmdkValidateJSON = `
+{
+    "virtual-size": 4294967296,
+    "filename": "myimage.vmdk",
+    "format": "vmdk",
+    "actual-size": 4096,
+    "format-specific": {
+        "type": "vmdk",
+        "data": {
+            "cid": 2779700067,
+            "parent-cid": 4294967295,
+            "create-type": "twoGbMaxExtentSparse",
+            "extents": [
+                {
+                    "virtual-size": 2147483648,
+                    "filename": "myimage-s001.vmdk",
+                    "cluster-size": 65536,
+                    "format": "SPARSE"
+                },
+                {
+                    "virtual-size": 2147483648,
+                    "filename": "myimage-s002.vmdk",
+                    "cluster-size": 65536,
+                    "format": "SPARSE"
+                }
+            ]
+        }
+    },
+    "dirty-flag": false
+}
+`
+
+const fixedVhdValidateJSON = `
+{
+    "virtual-size": 4294967296,
+    "filename": "myimage.vhd",
+    "format": "vpc",
+    "actual-size": 4294971392,
+    "dirty-flag": false
+}
+`
+
+const qedValidateJSON = `
+{
+    "virtual-size": 1073741824,
+    "filename": "myimage.qed",
+    "cluster-size": 65536,
+    "format": "qed",
+    "actual-size": 339968,
+    "dirty-flag": false
+}
+`
+
+const parallelsValidateJSON = `
+{
+    "virtual-size": 1073741824,
+    "filename": "myimage.hds",
+    "cluster-size": 1048576,
+    "format": "parallels",
+    "actual-size": 204800,
+    "dirty-flag": false
+}
+`
+
 type execFunctionType func(*system.ProcessLimitValues, func(string), string, ...string) ([]byte, error)
 
 func init() {
@@ -147,14 +260,14 @@ var _ = Describe("Convert to Raw", func() {
 
 	It("should return no error if exec function returns no error", func() {
 		replaceExecFunction(mockExecFunction("", "", nil, "convert", "-p", "-O", "raw", "source", destPath), func() {
-			err := convertToRaw("source", destPath, false)
+			_, err := convertToRaw("source", "", destPath, PreallocationNone, "")
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
 
 	It("should return conversion error if exec function returns error", func() {
 		replaceExecFunction(mockExecFunction("", "exit 1", nil, "convert", "-p", "-O", "raw", "source", destPath), func() {
-			err := convertToRaw("source", destPath, false)
+			_, err := convertToRaw("source", "", destPath, PreallocationNone, "")
 			Expect(err).To(HaveOccurred())
 			Expect(strings.Contains(err.Error(), "could not convert image to raw")).To(BeTrue())
 		})
@@ -164,7 +277,7 @@ var _ = Describe("Convert to Raw", func() {
 		replaceExecFunction(mockExecFunction("", "", nil, "convert", "-p", "-O", "raw", "/somefile/somewhere", destPath), func() {
 			ep, err := url.Parse("/somefile/somewhere")
 			Expect(err).NotTo(HaveOccurred())
-			err = ConvertToRawStream(ep, destPath, false)
+			_, err = ConvertToRawStream(ep, "", destPath, PreallocationNone, "")
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -173,7 +286,43 @@ var _ = Describe("Convert to Raw", func() {
 		replaceExecFunction(mockExecFunctionStrict("", "", nil, "convert", "-o", "preallocation=falloc", "-t", "writeback", "-p", "-O", "raw", "/somefile/somewhere", destPath), func() {
 			ep, err := url.Parse("/somefile/somewhere")
 			Expect(err).NotTo(HaveOccurred())
-			err = ConvertToRawStream(ep, destPath, true)
+			applied, err := ConvertToRawStream(ep, "", destPath, PreallocationFalloc, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(applied).To(Equal(PreallocationFalloc))
+		})
+	})
+
+	It("should write to a block device without creating it", func() {
+		isBlockDevice = func(string) bool { return true }
+		defer func() { isBlockDevice = blockDevice }()
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, "convert", "-t", "writeback", "-p", "-O", "raw", "-n", "-W", "/somefile/somewhere", "/dev/cdi-block-volume"), func() {
+			ep, err := url.Parse("/somefile/somewhere")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ConvertToRawStream(ep, "", "/dev/cdi-block-volume", PreallocationNone, "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	table.DescribeTable("should preallocate a block device by writing zeros", func(preallocation PreallocationMode) {
+		isBlockDevice = func(string) bool { return true }
+		defer func() { isBlockDevice = blockDevice }()
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, "convert", "-S", "0", "-t", "writeback", "-p", "-O", "raw", "-n", "-W", "/somefile/somewhere", "/dev/cdi-block-volume"), func() {
+			ep, err := url.Parse("/somefile/somewhere")
+			Expect(err).NotTo(HaveOccurred())
+			applied, err := ConvertToRawStream(ep, "", "/dev/cdi-block-volume", preallocation, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(applied).To(Equal(PreallocationFull))
+		})
+	},
+		table.Entry("with falloc", PreallocationFalloc),
+		table.Entry("in full", PreallocationFull),
+	)
+
+	It("should pass the format of the source if known", func() {
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, "convert", "-t", "writeback", "-p", "-f", "vpc", "-O", "raw", "/somefile/somewhere", destPath), func() {
+			ep, err := url.Parse("/somefile/somewhere")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ConvertToRawStream(ep, QemuFormatVhd, destPath, PreallocationNone, "")
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -182,10 +331,68 @@ var _ = Describe("Convert to Raw", func() {
 		replaceExecFunction(mockExecFunctionStrict("", "", nil, "convert", "-t", "writeback", "-p", "-O", "raw", "/somefile/somewhere", destPath), func() {
 			ep, err := url.Parse("/somefile/somewhere")
 			Expect(err).NotTo(HaveOccurred())
-			err = ConvertToRawStream(ep, destPath, false)
+			_, err = ConvertToRawStream(ep, "", destPath, PreallocationNone, "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	table.DescribeTable("should convert the source to qcow2", func(options Qcow2Options, args ...string) {
+		args = append([]string{"convert", "-t", "writeback", "-p", "-O", "qcow2"}, append(args, "/somefile/somewhere", destPath)...)
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, args...), func() {
+			ep, err := url.Parse("/somefile/somewhere")
+			Expect(err).NotTo(HaveOccurred())
+			err = ConvertToQcow2Stream(ep, "", destPath, options, "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	},
+		table.Entry("uncompressed", Qcow2Options{}),
+		table.Entry("with compressed clusters", Qcow2Options{Compress: true}, "-c"),
+		table.Entry("with a cluster size", Qcow2Options{ClusterSize: "2M"}, "-o", "cluster_size=2097152"),
+		table.Entry("with a compat level", Qcow2Options{Compat: "0.10"}, "-o", "compat=0.10"),
+		table.Entry("with all the options", Qcow2Options{Compress: true, ClusterSize: "64k", Compat: "1.1"}, "-o", "cluster_size=65536,compat=1.1", "-c"),
+	)
+
+	It("should not run qemu-img with invalid qcow2 options", func() {
+		replaceExecFunction(func(*system.ProcessLimitValues, func(string), string, ...string) ([]byte, error) {
+			Fail("qemu-img should not run")
+			return nil, nil
+		}, func() {
+			err := convertToQcow2("source", "", destPath, Qcow2Options{Compat: "1.1,data_file=/etc/passwd"}, "")
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, ErrUnsupportedFormat)).To(BeTrue())
+		})
+	})
+
+	It("should report the output format if the conversion to qcow2 fails", func() {
+		replaceExecFunction(mockExecFunction("", "exit 1", nil, "convert", "-p", "-O", "qcow2", "source", destPath), func() {
+			err := convertToQcow2("source", "", destPath, Qcow2Options{}, "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not convert image to qcow2"))
+		})
+	})
+
+	It("should decrypt the source with the passphrase of the key file", func() {
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, "convert", "-t", "writeback", "-p",
+			"--object", "secret,id=sec0,file=/encryption/pass,,phrase",
+			"--image-opts", "driver=qcow2,encrypt.key-secret=sec0,file.filename=nbd+unix:///?socket=/tmp/nbdkit.sock",
+			"-O", "raw", destPath), func() {
+			ep, err := url.Parse("nbd+unix:///?socket=/tmp/nbdkit.sock")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ConvertToRawStream(ep, "", destPath, PreallocationNone, "/encryption/pass,phrase")
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	It("should fail with the cause alone if the passphrase does not unlock the source", func() {
+		output := "qemu-img: Could not open 'driver=qcow2,encrypt.key-secret=sec0,file.filename=/somefile/somewhere': Invalid password, cannot unlock any keyslot"
+		replaceExecFunction(mockExecFunction(output, "exit 1", nil), func() {
+			ep, err := url.Parse("/somefile/somewhere")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ConvertToRawStream(ep, "", destPath, PreallocationNone, "/encryption/passphrase")
+			Expect(errors.Is(err, ErrInvalidEncryptionKey)).To(BeTrue())
+			Expect(err).To(MatchError("qcow2 invalid encryption key: could not unlock the image with the passphrase of its encryption secret"))
+		})
+	})
 })
 
 var _ = Describe("Resize", func() {
@@ -195,7 +402,17 @@ var _ = Describe("Resize", func() {
 		size := convertQuantityToQemuSize(quantity)
 		replaceExecFunction(mockExecFunction("", "", nil, "resize", "-f", "raw", "image", size), func() {
 			o := NewQEMUOperations()
-			err = o.Resize("image", quantity, false)
+			_, err = o.Resize("image", QemuFormatRaw, quantity, PreallocationNone)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	It("Should resize a qcow2 image as a qcow2 image", func() {
+		quantity, err := resource.ParseQuantity("10Gi")
+		Expect(err).NotTo(HaveOccurred())
+		size := convertQuantityToQemuSize(quantity)
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, "resize", "-f", "qcow2", "image", size), func() {
+			_, err = Resize("image", QemuFormatQcow2, quantity, PreallocationNone)
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -206,7 +423,7 @@ var _ = Describe("Resize", func() {
 		size := convertQuantityToQemuSize(quantity)
 		replaceExecFunction(mockExecFunction("", "exit 1", nil, "resize", "-f", "raw", "image", size), func() {
 			o := NewQEMUOperations()
-			err = o.Resize("image", quantity, false)
+			_, err = o.Resize("image", QemuFormatRaw, quantity, PreallocationNone)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.Contains(err.Error(), "Error resizing image image")).To(BeTrue())
 		})
@@ -218,7 +435,7 @@ var _ = Describe("Validate", func() {
 
 	table.DescribeTable("Validate should", func(execfunc execFunctionType, errString string, image *url.URL) {
 		replaceExecFunction(execfunc, func() {
-			err := Validate(image, 42949672960)
+			err := Validate(image, "", 42949672960, false, "")
 
 			if errString == "" {
 				Expect(err).NotTo(HaveOccurred())
@@ -235,10 +452,148 @@ var _ = Describe("Validate", func() {
 		table.Entry("should return error", mockExecFunction("explosion", "exit 1", expectedLimits), "explosion, exit 1", imageName),
 		table.Entry("should return error on bad json", mockExecFunction(badValidateJSON, "", expectedLimits), "unexpected end of JSON input", imageName),
 		table.Entry("should return error on bad format", mockExecFunction(badFormatValidateJSON, "", expectedLimits), fmt.Sprintf("Invalid format raw2 for image %s", imageName), imageName),
-		table.Entry("should return error on invalid backing file", mockExecFunction(backingFileValidateJSON, "", expectedLimits), fmt.Sprintf("Image %s is invalid because it has invalid backing file backing-file.qcow2", imageName), imageName),
+		table.Entry("should return error on a backing file", mockExecFunction(backingFileValidateJSON, "", expectedLimits),
+			fmt.Sprintf("qcow2 unsupported format: image %s references the backing file backing-file.qcow2, which is not imported along with it, flatten the image first or import it from an archive holding its backing chain with the cdi.kubevirt.io/flattenBackingChain annotation", imageName), imageName),
+		table.Entry("should return error on an external data file", mockExecFunction(dataFileValidateJSON, "", expectedLimits),
+			fmt.Sprintf("qcow2 unsupported format: image %s references the external data file /etc/shadow, which is not imported along with it", imageName), imageName),
+		table.Entry("should return success for a streamOptimized vmdk image", mockExecFunction(streamOptimizedVmdkValidateJSON, "", expectedLimits), "", imageName),
+		table.Entry("should return error for a vmdk image with external extents", mockExecFunction(multiExtentVmdkValidateJSON, "", expectedLimits),
+			fmt.Sprintf("vmdk unsupported format: image %s of create type twoGbMaxExtentSparse references external extent files, only monolithicSparse and streamOptimized vmdk images can be imported", imageName), imageName),
+		table.Entry("should return success for a qed image", mockExecFunction(qedValidateJSON, "", expectedLimits), "", imageName),
+		table.Entry("should return success for a parallels image", mockExecFunction(parallelsValidateJSON, "", expectedLimits), "", imageName),
 		table.Entry("should return error when PVC is too small", mockExecFunction(hugeValidateJSON, "", expectedLimits), fmt.Sprintf("Virtual image size %d is larger than the reported available storage %d. A larger PVC is required.", 52949672960, 42949672960), imageName),
 	)
 
+	It("should pass the format of the image to qemu-img", func() {
+		vhdName, _ := url.Parse("myimage.vhd")
+		replaceExecFunction(mockExecFunctionStrict(fixedVhdValidateJSON, "", expectedLimits, "info", "-f", "vpc", "--output=json", vhdName.String()), func() {
+			Expect(Validate(vhdName, QemuFormatVhd, 42949672960, false, "")).To(Succeed())
+		})
+	})
+
+	Context("when flattening the backing chain", func() {
+		overlay := &url.URL{Path: "/scratch/tmpimage"}
+
+		// mockBackingChain returns the info of the images of a backing chain, in order, each
+		// referencing the next one
+		mockBackingChain := func(paths ...string) execFunctionType {
+			i := 0
+			return func(limits *system.ProcessLimitValues, f func(string), cmd string, args ...string) ([]byte, error) {
+				Expect(args).To(Equal([]string{"info", "--output=json", paths[i]}))
+				i++
+				backing := ""
+				if i < len(paths) {
+					backing = strings.TrimPrefix(paths[i], "/scratch/")
+				}
+				return []byte(fmt.Sprintf(`{"virtual-size": 4294967296, "format": "qcow2", "backing-filename": %q}`, backing)), nil
+			}
+		}
+
+		It("should accept the backing files extracted next to the image", func() {
+			replaceExecFunction(mockBackingChain("/scratch/tmpimage", "/scratch/base.qcow2", "/scratch/images/first.qcow2"), func() {
+				Expect(Validate(overlay, "", 42949672960, true, "")).To(Succeed())
+			})
+		})
+
+		table.DescribeTable("should reject a backing file", func(backing, expectedErr string) {
+			json := fmt.Sprintf(`{"virtual-size": 4294967296, "format": "qcow2", "backing-filename": %q}`, backing)
+			replaceExecFunction(mockExecFunctionStrict(json, "", expectedLimits, "info", "--output=json", overlay.String()), func() {
+				err := Validate(overlay, "", 42949672960, true, "")
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+				Expect(errors.Is(err, ErrUnsupportedFormat)).To(BeTrue())
+			})
+		},
+			table.Entry("with an absolute path", "/etc/hostname", "is not a relative file name"),
+			table.Entry("outside of the directory of the image", "../base.qcow2", "is outside of /scratch"),
+			table.Entry("read with a protocol", "nbd://evil/export", "is not a relative file name"),
+			table.Entry("referencing its own directory", ".", "is outside of /scratch"),
+		)
+
+		It("should reject a backing file referencing an external data file", func() {
+			i := 0
+			replaceExecFunction(func(limits *system.ProcessLimitValues, f func(string), cmd string, args ...string) ([]byte, error) {
+				i++
+				if i == 1 {
+					return []byte(`{"virtual-size": 4294967296, "format": "qcow2", "backing-filename": "base.qcow2"}`), nil
+				}
+				Expect(args).To(Equal([]string{"info", "--output=json", "/scratch/base.qcow2"}))
+				return []byte(dataFileValidateJSON), nil
+			}, func() {
+				err := Validate(overlay, "", 42949672960, true, "")
+				Expect(errors.Is(err, ErrUnsupportedFormat)).To(BeTrue())
+				Expect(err).To(MatchError(ContainSubstring("image /scratch/base.qcow2 references the external data file /etc/shadow")))
+			})
+		})
+
+		It("should reject a backing chain of more than the maximum images", func() {
+			paths := []string{"/scratch/tmpimage"}
+			for i := 0; i <= MaxBackingChainLength; i++ {
+				paths = append(paths, fmt.Sprintf("/scratch/base%d.qcow2", i))
+			}
+			replaceExecFunction(mockBackingChain(paths...), func() {
+				err := Validate(overlay, "", 42949672960, true, "")
+				Expect(err).To(MatchError(ContainSubstring("is longer than 16 images")))
+			})
+		})
+
+		It("should reject the backing file of a remote image", func() {
+			remote, _ := url.Parse("nbd+unix:///?socket=/tmp/nbdkit.sock")
+			replaceExecFunction(mockExecFunction(backingFileValidateJSON, "", expectedLimits), func() {
+				err := Validate(remote, "", 42949672960, true, "")
+				Expect(err).To(MatchError(ContainSubstring("which is not imported along with it")))
+			})
+		})
+	})
+
+	Context("when the image is encrypted", func() {
+		// encryptedJSON returns the info of a qcow2 image, encrypted in the format passed in unless it
+		// is empty
+		encryptedJSON := func(encryptFormat string) string {
+			if encryptFormat == "" {
+				return goodValidateJSON
+			}
+			return fmt.Sprintf(`{"virtual-size": 4294967296, "format": "qcow2", "encrypted": true, "format-specific": {"type": "qcow2", "data": {"encrypt": {"format": %q}}}}`, encryptFormat)
+		}
+
+		It("should accept a LUKS-encrypted qcow2 image with its key file", func() {
+			replaceExecFunction(mockExecFunction(encryptedJSON("luks"), "", expectedLimits), func() {
+				Expect(Validate(&url.URL{Path: "/scratch/tmpimage"}, "", 42949672960, false, "/encryption/passphrase")).To(Succeed())
+			})
+		})
+
+		table.DescribeTable("should reject", func(encryptFormat, keyFile string, expectedKind error, expectedErr string) {
+			replaceExecFunction(mockExecFunction(encryptedJSON(encryptFormat), "", expectedLimits), func() {
+				err := Validate(&url.URL{Path: "/scratch/tmpimage"}, "", 42949672960, false, keyFile)
+				Expect(errors.Is(err, expectedKind)).To(BeTrue())
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+			})
+		},
+			table.Entry("a LUKS-encrypted image without its key file", "luks", "", ErrInvalidEncryptionKey, "reference the secret holding its passphrase"),
+			table.Entry("an image that is not encrypted with a key file", "", "/encryption/passphrase", ErrInvalidEncryptionKey, "is not encrypted"),
+			table.Entry("an image encrypted with the legacy AES encryption", "aes", "/encryption/passphrase", ErrUnsupportedFormat, "is not encrypted with LUKS"),
+		)
+	})
+})
+
+var _ = Describe("Info", func() {
+	It("should return the information about a local image", func() {
+		replaceExecFunction(mockExecFunctionStrict(goodValidateJSON, "", expectedLimits, "info", "--output=json", "/data/myimage.qcow2"), func() {
+			info, err := QemuImgInfo("/data/myimage.qcow2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Format).To(Equal("qcow2"))
+			Expect(info.VirtualSize).To(Equal(int64(4294967296)))
+			Expect(info.ActualSize).To(Equal(int64(262152192)))
+			Expect(info.ClusterSize).To(Equal(int64(65536)))
+			Expect(info.BackingFile).To(BeEmpty())
+			Expect(info.FormatSpecific.Type).To(Equal("qcow2"))
+		})
+	})
+
+	It("should fail on invalid output", func() {
+		_, err := ParseImgInfo([]byte(badValidateJSON), "myimage.qcow2")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Invalid json for image myimage.qcow2"))
+	})
 })
 
 var _ = Describe("Report Progress", func() {
@@ -266,6 +621,29 @@ var _ = Describe("Report Progress", func() {
 		Expect(*metric.Counter.Value).To(Equal(45.34))
 	})
 
+	It("should scale the progress of a conversion following a copy to scratch space", func() {
+		SetConvertProgressStart(50)
+		defer SetConvertProgressStart(0)
+		metric := &dto.Metric{}
+		reportProgress("(45.00/100%)")
+		Expect(progress.WithLabelValues(ownerUID).Write(metric)).To(Succeed())
+		Expect(*metric.Counter.Value).To(Equal(72.5))
+		reportProgress("(100.00/100%)")
+		Expect(progress.WithLabelValues(ownerUID).Write(metric)).To(Succeed())
+		Expect(*metric.Counter.Value).To(Equal(float64(100)))
+	})
+
+	It("should not go backwards when a conversion is retried", func() {
+		metric := &dto.Metric{}
+		reportProgress("(30.00/100%)")
+		reportProgress("(10.00/100%)")
+		Expect(progress.WithLabelValues(ownerUID).Write(metric)).To(Succeed())
+		Expect(*metric.Counter.Value).To(Equal(float64(30)))
+		reportProgress("(60.00/100%)")
+		Expect(progress.WithLabelValues(ownerUID).Write(metric)).To(Succeed())
+		Expect(*metric.Counter.Value).To(Equal(float64(60)))
+	})
+
 	It("Parse invalid progress line", func() {
 		By("Verifying the initial value is 0")
 		progress.WithLabelValues(ownerUID).Add(0)
@@ -300,7 +678,7 @@ var _ = Describe("Create blank image", func() {
 		Expect(err).NotTo(HaveOccurred())
 		size := convertQuantityToQemuSize(quantity)
 		replaceExecFunction(mockExecFunction("", "", nil, "create", "-f", "raw", "image", size), func() {
-			err = CreateBlankImage("image", quantity, false)
+			_, err = CreateBlankImage("image", quantity, PreallocationNone)
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -310,7 +688,7 @@ var _ = Describe("Create blank image", func() {
 		Expect(err).NotTo(HaveOccurred())
 		size := convertQuantityToQemuSize(quantity)
 		replaceExecFunction(mockExecFunction("", "exit 1", nil, "create", "-f", "raw", "image", size), func() {
-			err = CreateBlankImage("image", quantity, false)
+			_, err = CreateBlankImage("image", quantity, PreallocationNone)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.Contains(err.Error(), "could not create raw image with size ")).To(BeTrue())
 		})
@@ -321,8 +699,9 @@ var _ = Describe("Create blank image", func() {
 		Expect(err).NotTo(HaveOccurred())
 		size := convertQuantityToQemuSize(quantity)
 		replaceExecFunction(mockExecFunctionStrict("", "", nil, "create", "-f", "raw", "image", size, "-o", "preallocation=falloc"), func() {
-			err = CreateBlankImage("image", quantity, true)
+			applied, err := CreateBlankImage("image", quantity, PreallocationFalloc)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(applied).To(Equal(PreallocationFalloc))
 		})
 	})
 
@@ -331,7 +710,7 @@ var _ = Describe("Create blank image", func() {
 		Expect(err).NotTo(HaveOccurred())
 		size := convertQuantityToQemuSize(quantity)
 		replaceExecFunction(mockExecFunctionStrict("", "", nil, "create", "-f", "raw", "image", size), func() {
-			err = CreateBlankImage("image", quantity, false)
+			_, err = CreateBlankImage("image", quantity, PreallocationNone)
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -374,19 +753,20 @@ var _ = Describe("Create preallocated blank block", func() {
 var _ = Describe("Try different preallocation modes", func() {
 	It("Should try falloc first", func() {
 		calledCount := 0
-		err := addPreallocation([]string{"command"}, convertPreallocationMethods, func(args []string) ([]byte, error) {
+		applied, err := addPreallocation([]string{"command"}, convertPreallocationMethods, PreallocationFalloc, func(args []string) ([]byte, error) {
 			Expect(args).To(Equal([]string{"command", "-o", "preallocation=falloc"}))
 			calledCount++
 			return []byte{}, nil
 		})
 
 		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(Equal(PreallocationFalloc))
 		Expect(calledCount).To(Equal(1))
 	})
 
 	It("Should try full if falloc fails", func() {
 		calledCount := 0
-		err := addPreallocation([]string{"command"}, convertPreallocationMethods, func(args []string) ([]byte, error) {
+		applied, err := addPreallocation([]string{"command"}, convertPreallocationMethods, PreallocationFalloc, func(args []string) ([]byte, error) {
 			if args[2] == "preallocation=falloc" {
 				calledCount++
 				return []byte("Unsupported preallocation mode"), fmt.Errorf("No, no, no")
@@ -397,12 +777,13 @@ var _ = Describe("Try different preallocation modes", func() {
 		})
 
 		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(Equal(PreallocationFull))
 		Expect(calledCount).To(Equal(2))
 	})
 
 	It("Should try -S0 if full fails", func() {
 		calledCount := 0
-		err := addPreallocation([]string{"command"}, convertPreallocationMethods, func(args []string) ([]byte, error) {
+		applied, err := addPreallocation([]string{"command"}, convertPreallocationMethods, PreallocationFalloc, func(args []string) ([]byte, error) {
 			if calledCount < 2 {
 				calledCount++
 				return []byte("Unsupported preallocation mode"), fmt.Errorf("No, no, no")
@@ -413,19 +794,109 @@ var _ = Describe("Try different preallocation modes", func() {
 		})
 
 		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(Equal(PreallocationFull))
 		Expect(calledCount).To(Equal(3))
 	})
 
 	It("Should fail if output is different than 'Unsupported preallocation'", func() {
 		calledCount := 0
-		err := addPreallocation([]string{"command"}, convertPreallocationMethods, func(args []string) ([]byte, error) {
+		applied, err := addPreallocation([]string{"command"}, convertPreallocationMethods, PreallocationFalloc, func(args []string) ([]byte, error) {
 			calledCount++
 			return []byte("General Protection Fault"), fmt.Errorf("No, no, no")
 		})
 
 		Expect(err).To(HaveOccurred())
+		Expect(applied).To(Equal(PreallocationFalloc))
+		Expect(calledCount).To(Equal(1))
+	})
+
+	It("Should not try falloc if full is requested", func() {
+		calledCount := 0
+		applied, err := addPreallocation([]string{"command"}, resizePreallocationMethods, PreallocationFull, func(args []string) ([]byte, error) {
+			Expect(args).To(Equal([]string{"command", "--preallocation=full"}))
+			calledCount++
+			return []byte{}, nil
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(Equal(PreallocationFull))
 		Expect(calledCount).To(Equal(1))
 	})
+
+	It("Should report full if fallocate is not supported by the filesystem", func() {
+		origFallocateSupported := fallocateSupportedFunc
+		fallocateSupportedFunc = func(string) bool { return false }
+		defer func() { fallocateSupportedFunc = origFallocateSupported }()
+		quantity := resource.MustParse("10Gi")
+		size := convertQuantityToQemuSize(quantity)
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, "resize", "--preallocation=falloc", "-f", "raw", "image", size), func() {
+			applied, err := Resize("image", QemuFormatRaw, quantity, PreallocationFalloc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(applied).To(Equal(PreallocationFull))
+		})
+	})
+})
+
+var _ = Describe("Check", func() {
+	const report = "Leaked cluster 12 refcount=1 reference=0\nLeaked cluster 13 refcount=1 reference=0\n\n2 leaked clusters were found on the image.\nThis means waste of disk space, but no harm to data."
+
+	It("Should pass a consistent image", func() {
+		replaceExecFunction(mockCheckExecFunction("No errors were found on the image.", 0, "check", "-f", "qcow2", "/data/disk.img"), func() {
+			err := NewQEMUOperations().Check(&url.URL{Path: "/data/disk.img"}, "qcow2", "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	It("Should pass an image whose format does not support checks", func() {
+		replaceExecFunction(mockCheckExecFunction("qemu-img: This image format does not support checks", qemuCheckNotSupported, "check", "/data/disk.img"), func() {
+			err := NewQEMUOperations().Check(&url.URL{Path: "/data/disk.img"}, "", "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	table.DescribeTable("Should fail a corrupt image with the report of qemu-img", func(exitCode int) {
+		replaceExecFunction(mockCheckExecFunction(report, exitCode, "check", "-f", "qcow2", "/data/disk.img"), func() {
+			err := NewQEMUOperations().Check(&url.URL{Path: "/data/disk.img"}, "qcow2", "")
+			Expect(errors.Is(err, ErrCorruptImage)).To(BeTrue())
+			Expect(err.Error()).To(Equal("qcow2 corrupt image: qemu-img check: Leaked cluster 12 refcount=1 reference=0; " +
+				"Leaked cluster 13 refcount=1 reference=0; 2 leaked clusters were found on the image.; " +
+				"This means waste of disk space, but no harm to data."))
+		})
+	},
+		table.Entry("with leaked clusters", qemuCheckLeaks),
+		table.Entry("with corruptions", qemuCheckCorruptions),
+	)
+
+	It("Should keep the end of a long report", func() {
+		var lines []string
+		for i := 0; i < 20; i++ {
+			lines = append(lines, fmt.Sprintf("ERROR cluster %d refcount=0 reference=1", i))
+		}
+		replaceExecFunction(mockCheckExecFunction(strings.Join(lines, "\n"), qemuCheckCorruptions), func() {
+			err := NewQEMUOperations().Check(&url.URL{Path: "/data/disk.img"}, "qcow2", "")
+			Expect(errors.Is(err, ErrCorruptImage)).To(BeTrue())
+			Expect(err.Error()).NotTo(ContainSubstring("cluster 11 "))
+			Expect(err.Error()).To(ContainSubstring("cluster 12 "))
+			Expect(err.Error()).To(HaveSuffix("cluster 19 refcount=0 reference=1"))
+		})
+	})
+
+	It("Should fail if the image could not be checked", func() {
+		replaceExecFunction(mockCheckExecFunction("qemu-img: Could not open '/data/disk.img'", 1), func() {
+			err := NewQEMUOperations().Check(&url.URL{Path: "/data/disk.img"}, "qcow2", "")
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, ErrCorruptImage)).To(BeFalse())
+			Expect(err.Error()).To(ContainSubstring("Could not open"))
+		})
+	})
+
+	It("Should check an encrypted image with its passphrase", func() {
+		replaceExecFunction(mockCheckExecFunction("", 0, "check", "--object", "secret,id=sec0,file=/keys/passphrase",
+			"--image-opts", "driver=qcow2,encrypt.key-secret=sec0,file.filename=/data/disk.img"), func() {
+			err := NewQEMUOperations().Check(&url.URL{Path: "/data/disk.img"}, "qcow2", "/keys/passphrase")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
 })
 
 var _ = Describe("Rebase and commit", func() {
@@ -446,6 +917,29 @@ var _ = Describe("Rebase and commit", func() {
 	})
 })
 
+var _ = Describe("Write test images", func() {
+	It("Should convert a raw image with the creation options", func() {
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, "convert", "-f", "raw", "-O", "vmdk", "-o", "subformat=streamOptimized", "src", "dest"), func() {
+			o := NewQEMUOperations()
+			Expect(o.ConvertRawImage("src", "dest", "vmdk", "subformat=streamOptimized", "")).To(Succeed())
+		})
+	})
+
+	It("Should encrypt the converted image with the passphrase of the key file", func() {
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, "convert", "-f", "raw", "-O", "qcow2", "--object", "secret,id=sec0,file=/tmp/key", "-o", "encrypt.format=luks,encrypt.key-secret=sec0", "src", "dest"), func() {
+			o := NewQEMUOperations()
+			Expect(o.ConvertRawImage("src", "dest", "qcow2", "", "/tmp/key")).To(Succeed())
+		})
+	})
+
+	It("Should create an overlay over its backing file", func() {
+		replaceExecFunction(mockExecFunctionStrict("", "", nil, "create", "-f", "qcow2", "-b", "base.qcow2", "-F", "qcow2", "dest"), func() {
+			o := NewQEMUOperations()
+			Expect(o.CreateOverlay("base.qcow2", "dest")).To(Succeed())
+		})
+	})
+})
+
 func mockExecFunction(output, errString string, expectedLimits *system.ProcessLimitValues, checkArgs ...string) execFunctionType {
 	return func(limits *system.ProcessLimitValues, f func(string), cmd string, args ...string) (bytes []byte, err error) {
 		Expect(reflect.DeepEqual(expectedLimits, limits)).To(BeTrue())
@@ -515,6 +1009,25 @@ func mockExecFunctionTwoCalls(output, errString string, expectedLimits *system.P
 	}
 }
 
+// mockCheckExecFunction reports the output line by line, and exits qemu-img check with exitCode.
+func mockCheckExecFunction(output string, exitCode int, checkArgs ...string) execFunctionType {
+	return func(limits *system.ProcessLimitValues, f func(string), cmd string, args ...string) ([]byte, error) {
+		Expect(limits).To(BeNil())
+		if len(checkArgs) > 0 {
+			Expect(args).To(Equal(checkArgs))
+		}
+		for _, line := range strings.Split(output, "\n") {
+			f(line)
+		}
+		if exitCode == 0 {
+			return []byte(output), nil
+		}
+		err := exec.Command("sh", "-c", fmt.Sprintf("exit %d", exitCode)).Run()
+		Expect(err).To(HaveOccurred())
+		return nil, errors.Wrapf(err, "%s execution failed", cmd)
+	}
+}
+
 func replaceExecFunction(replacement execFunctionType, f func()) {
 	orig := qemuExecFunction
 	if replacement != nil {
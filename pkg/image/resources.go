@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/system"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+const (
+	// defaultQemuCacheMode is the cache mode of the image written by a qemu-img conversion
+	defaultQemuCacheMode = "writeback"
+	// maxQemuCoroutines is the maximum number of parallel coroutines of a qemu-img conversion
+	maxQemuCoroutines = 16
+	// maxQemuNiceness is the niceness of the lowest scheduling priority
+	maxQemuNiceness = 19
+)
+
+// qemuResources bounds the resources of qemu-img.
+type qemuResources struct {
+	// cacheMode is the cache mode of the image written by a conversion
+	cacheMode string
+	// coroutines is the number of parallel coroutines of a conversion, the default of qemu-img if 0
+	coroutines int
+	// memoryLimit is the limit of the address space of qemu-img, none if 0
+	memoryLimit uint64
+	// niceness is the niceness of qemu-img, left as it is if 0
+	niceness int
+}
+
+// resources returns the bounds of the resources of qemu-img, the defaults unless overridden by the environment.
+func resources() (qemuResources, error) {
+	res := qemuResources{cacheMode: defaultQemuCacheMode}
+	if value, _ := util.ParseEnvVar(common.ImporterQemuCacheMode, false); value != "" {
+		switch value {
+		case "writeback", "writethrough", "none", "directsync", "unsafe":
+			res.cacheMode = value
+		default:
+			return res, errors.Errorf("invalid %s value %q, one of writeback, writethrough, none, directsync or unsafe is expected", common.ImporterQemuCacheMode, value)
+		}
+	}
+	if value, _ := util.ParseEnvVar(common.ImporterQemuCoroutines, false); value != "" {
+		coroutines, err := strconv.Atoi(value)
+		if err != nil || coroutines < 1 || coroutines > maxQemuCoroutines {
+			return res, errors.Errorf("invalid %s value %q, a number of coroutines between 1 and %d is expected", common.ImporterQemuCoroutines, value, maxQemuCoroutines)
+		}
+		res.coroutines = coroutines
+	}
+	if value, _ := util.ParseEnvVar(common.ImporterQemuMemoryLimit, false); value != "" {
+		limit, err := resource.ParseQuantity(value)
+		if err != nil || limit.Sign() <= 0 {
+			return res, errors.Errorf("invalid %s value %q, a positive quantity is expected", common.ImporterQemuMemoryLimit, value)
+		}
+		res.memoryLimit = uint64(limit.Value())
+	}
+	if value, _ := util.ParseEnvVar(common.ImporterQemuNiceness, false); value != "" {
+		niceness, err := strconv.Atoi(value)
+		if err != nil || niceness < 0 || niceness > maxQemuNiceness {
+			return res, errors.Errorf("invalid %s value %q, a niceness between 0 and %d is expected", common.ImporterQemuNiceness, value, maxQemuNiceness)
+		}
+		res.niceness = niceness
+	}
+	return res, nil
+}
+
+// limits returns the process limits of qemu-img, the ones passed in bounded by the resources. It
+// returns nil, no limits, if there are none.
+func (r qemuResources) limits(limits *system.ProcessLimitValues) *system.ProcessLimitValues {
+	if r.memoryLimit == 0 && r.niceness == 0 {
+		return limits
+	}
+	bounded := &system.ProcessLimitValues{}
+	if limits != nil {
+		*bounded = *limits
+	}
+	if r.memoryLimit > 0 && (bounded.AddressSpaceLimit == 0 || r.memoryLimit < bounded.AddressSpaceLimit) {
+		bounded.AddressSpaceLimit = r.memoryLimit
+	}
+	bounded.Niceness = r.niceness
+	return bounded
+}
+
+// convertArgs returns the arguments of a qemu-img conversion bounding its resources.
+func (r qemuResources) convertArgs() []string {
+	args := []string{"-t", r.cacheMode}
+	if r.coroutines > 0 {
+		args = append(args, "-m", strconv.Itoa(r.coroutines))
+	}
+	return args
+}
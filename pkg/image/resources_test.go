@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"net/url"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/system"
+)
+
+var _ = Describe("qemu-img resources", func() {
+	envs := []string{common.ImporterQemuCacheMode, common.ImporterQemuCoroutines, common.ImporterQemuMemoryLimit, common.ImporterQemuNiceness}
+
+	AfterEach(func() {
+		for _, env := range envs {
+			os.Unsetenv(env)
+		}
+	})
+
+	It("should default to the writeback cache mode and no limits", func() {
+		res, err := resources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.convertArgs()).To(Equal([]string{"-t", "writeback"}))
+		Expect(res.limits(nil)).To(BeNil())
+		Expect(res.limits(qemuInfoLimits)).To(BeIdenticalTo(qemuInfoLimits))
+	})
+
+	It("should follow the environment", func() {
+		os.Setenv(common.ImporterQemuCacheMode, "none")
+		os.Setenv(common.ImporterQemuCoroutines, "2")
+		os.Setenv(common.ImporterQemuMemoryLimit, "512Mi")
+		os.Setenv(common.ImporterQemuNiceness, "10")
+		res, err := resources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.convertArgs()).To(Equal([]string{"-t", "none", "-m", "2"}))
+		Expect(res.limits(nil)).To(Equal(&system.ProcessLimitValues{AddressSpaceLimit: 512 << 20, Niceness: 10}))
+		// the lower address space limit is kept
+		Expect(res.limits(qemuInfoLimits)).To(Equal(&system.ProcessLimitValues{AddressSpaceLimit: 512 << 20, CPUTimeLimit: maxCPUSecs, Niceness: 10}))
+		Expect(qemuInfoLimits.AddressSpaceLimit).To(BeEquivalentTo(maxMemory))
+	})
+
+	It("should keep a lower address space limit than the memory limit", func() {
+		os.Setenv(common.ImporterQemuMemoryLimit, "2Gi")
+		res, err := resources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.limits(qemuInfoLimits)).To(Equal(&system.ProcessLimitValues{AddressSpaceLimit: maxMemory, CPUTimeLimit: maxCPUSecs}))
+	})
+
+	table.DescribeTable("should reject an invalid value", func(env, value string) {
+		os.Setenv(env, value)
+		_, err := resources()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(env))
+	},
+		table.Entry("of cache mode", common.ImporterQemuCacheMode, "writearound"),
+		table.Entry("of no coroutine", common.ImporterQemuCoroutines, "0"),
+		table.Entry("of too many coroutines", common.ImporterQemuCoroutines, "17"),
+		table.Entry("of memory limit", common.ImporterQemuMemoryLimit, "lots"),
+		table.Entry("of negative memory limit", common.ImporterQemuMemoryLimit, "-1Gi"),
+		table.Entry("of niceness", common.ImporterQemuNiceness, "20"),
+		table.Entry("of negative niceness", common.ImporterQemuNiceness, "-5"),
+	)
+
+	It("should bound a conversion", func() {
+		os.Setenv(common.ImporterQemuCacheMode, "none")
+		os.Setenv(common.ImporterQemuCoroutines, "4")
+		os.Setenv(common.ImporterQemuMemoryLimit, "1Gi")
+		os.Setenv(common.ImporterQemuNiceness, "10")
+		limits := &system.ProcessLimitValues{AddressSpaceLimit: 1 << 30, Niceness: 10}
+		replaceExecFunction(mockExecFunctionStrict("", "", limits, "convert", "-t", "none", "-m", "4", "-p", "-O", "raw", "/somefile/somewhere", "/dest"), func() {
+			_, err := NewQEMUOperations().ConvertToRawStream(&url.URL{Path: "/somefile/somewhere"}, "", "/dest", PreallocationNone, "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	It("should not run qemu-img with invalid resources", func() {
+		os.Setenv(common.ImporterQemuNiceness, "high")
+		replaceExecFunction(func(*system.ProcessLimitValues, func(string), string, ...string) ([]byte, error) {
+			Fail("qemu-img should not run")
+			return nil, nil
+		}, func() {
+			_, err := NewQEMUOperations().Info(&url.URL{Path: "/somefile/somewhere"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(common.ImporterQemuNiceness))
+		})
+	})
+})
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/system"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+const (
+	// defaultQemuRetryAttempts is the number of invocations of qemu-img failing with a transient error
+	defaultQemuRetryAttempts = 3
+	// defaultQemuRetryBackoff is the wait before the first retry of qemu-img, doubled before each of the next ones
+	defaultQemuRetryBackoff = 2 * time.Second
+)
+
+var (
+	// transientQemuErrors matches the errors reported by qemu-img that a retry may not run into, mostly
+	// seen on congested NFS-backed storage
+	transientQemuErrors = regexp.MustCompile(`(?i)(Resource temporarily unavailable|Interrupted system call|Stale file handle)`)
+
+	retrySleep = time.Sleep
+)
+
+// qemuRetryPolicy is the policy of the retries of qemu-img when it fails with a transient error.
+type qemuRetryPolicy struct {
+	// attempts is the number of invocations of qemu-img, the first one included
+	attempts int
+	// backoff is the wait before the first retry, doubled before each of the next ones
+	backoff time.Duration
+}
+
+// retryPolicy returns the retry policy of qemu-img, the defaults unless overridden by the environment.
+func retryPolicy() (qemuRetryPolicy, error) {
+	policy := qemuRetryPolicy{attempts: defaultQemuRetryAttempts, backoff: defaultQemuRetryBackoff}
+	if value, _ := util.ParseEnvVar(common.ImporterQemuRetryAttempts, false); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts < 1 {
+			return policy, errors.Errorf("invalid %s value %q, a positive number of attempts is expected", common.ImporterQemuRetryAttempts, value)
+		}
+		policy.attempts = attempts
+	}
+	if value, _ := util.ParseEnvVar(common.ImporterQemuRetryBackoff, false); value != "" {
+		backoff, err := time.ParseDuration(value)
+		if err != nil || backoff < 0 {
+			return policy, errors.Errorf("invalid %s value %q, a duration such as 2s is expected", common.ImporterQemuRetryBackoff, value)
+		}
+		policy.backoff = backoff
+	}
+	return policy, nil
+}
+
+// isTransientQemuError returns true if the error output of qemu-img reports a transient error.
+func isTransientQemuError(stderr []byte) bool {
+	return transientQemuErrors.Match(stderr)
+}
+
+// execQemu runs qemu-img with the args passed in, within the limits passed in bounded by the
+// resources of qemu-img, and runs it again after a backoff as long as it fails with a transient
+// error, up to the attempts of the retry policy. It returns the error output of qemu-img on failure,
+// as the error of the last attempt once they are all used up.
+func execQemu(limits *system.ProcessLimitValues, callback func(string), args ...string) ([]byte, error) {
+	policy, err := retryPolicy()
+	if err != nil {
+		return nil, err
+	}
+	res, err := resources()
+	if err != nil {
+		return nil, err
+	}
+	limits = res.limits(limits)
+	backoff := policy.backoff
+	for attempt := 1; ; attempt++ {
+		output, err := qemuExecFunction(limits, callback, "qemu-img", args...)
+		if err == nil || !isTransientQemuError(output) {
+			return output, err
+		}
+		stderr := strings.TrimSpace(string(output))
+		if attempt >= policy.attempts {
+			return output, errors.Wrapf(err, "qemu-img %s failed %d times with a transient error: %s", args[0], attempt, stderr)
+		}
+		klog.Warningf("qemu-img %s failed with a transient error, retrying in %v (attempt %d of %d): %s", args[0], backoff, attempt, policy.attempts, stderr)
+		retrySleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/system"
+)
+
+const (
+	eagainStderr     = "qemu-img: Could not open '/scratch/disk.img': Could not open '/scratch/disk.img': Resource temporarily unavailable\n"
+	eintrStderr      = "qemu-img: error while reading at byte 1073741824: Interrupted system call\n"
+	estaleStderr     = "qemu-img: error while writing at byte 2147483648: Stale file handle\n"
+	enoentStderr     = "qemu-img: Could not open '/scratch/disk.img': Could not open '/scratch/disk.img': No such file or directory\n"
+	eioStderr        = "qemu-img: error while reading at byte 0: Input/output error\n"
+	invalidPwdStderr = "qemu-img: Could not open 'driver=qcow2,encrypt.key-secret=sec0,file.filename=/scratch/disk.img': Invalid password, cannot unlock any keyslot\n"
+)
+
+var _ = Describe("Transient qemu-img errors", func() {
+	table.DescribeTable("should be told apart from the others", func(stderr string, transient bool) {
+		Expect(isTransientQemuError([]byte(stderr))).To(Equal(transient))
+	},
+		table.Entry("resource temporarily unavailable", eagainStderr, true),
+		table.Entry("interrupted system call", eintrStderr, true),
+		table.Entry("stale file handle", estaleStderr, true),
+		table.Entry("no such file", enoentStderr, false),
+		table.Entry("input/output error", eioStderr, false),
+		table.Entry("invalid password", invalidPwdStderr, false),
+		table.Entry("no output", "", false),
+	)
+})
+
+var _ = Describe("Retry qemu-img", func() {
+	var sleeps []time.Duration
+
+	BeforeEach(func() {
+		sleeps = nil
+		retrySleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	})
+
+	AfterEach(func() {
+		retrySleep = time.Sleep
+		os.Unsetenv(common.ImporterQemuRetryAttempts)
+		os.Unsetenv(common.ImporterQemuRetryBackoff)
+	})
+
+	// failingExecFunction fails the first invocations with the error outputs passed in, and succeeds after them
+	failingExecFunction := func(calls *int, stderrs ...string) execFunctionType {
+		return func(limits *system.ProcessLimitValues, f func(string), cmd string, args ...string) ([]byte, error) {
+			Expect(cmd).To(Equal("qemu-img"))
+			Expect(args).To(Equal([]string{"info", "disk.img"}))
+			*calls++
+			if *calls <= len(stderrs) {
+				return []byte(stderrs[*calls-1]), errors.New("qemu-img execution failed: exit status 1")
+			}
+			return []byte("ok"), nil
+		}
+	}
+
+	It("should retry a transient error with a backoff", func() {
+		calls := 0
+		replaceExecFunction(failingExecFunction(&calls, eagainStderr, eintrStderr), func() {
+			output, err := execQemu(nil, nil, "info", "disk.img")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(output)).To(Equal("ok"))
+		})
+		Expect(calls).To(Equal(3))
+		Expect(sleeps).To(Equal([]time.Duration{defaultQemuRetryBackoff, 2 * defaultQemuRetryBackoff}))
+	})
+
+	It("should not retry an error that is not transient", func() {
+		calls := 0
+		replaceExecFunction(failingExecFunction(&calls, eioStderr), func() {
+			output, err := execQemu(nil, nil, "info", "disk.img")
+			Expect(err).To(MatchError("qemu-img execution failed: exit status 1"))
+			Expect(string(output)).To(Equal(eioStderr))
+		})
+		Expect(calls).To(Equal(1))
+		Expect(sleeps).To(BeEmpty())
+	})
+
+	It("should fail with the error output of the last attempt once the attempts are used up", func() {
+		calls := 0
+		replaceExecFunction(failingExecFunction(&calls, eintrStderr, eintrStderr, estaleStderr), func() {
+			_, err := execQemu(nil, nil, "info", "disk.img")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("qemu-img info failed 3 times with a transient error: " +
+				"qemu-img: error while writing at byte 2147483648: Stale file handle: qemu-img execution failed: exit status 1"))
+		})
+		Expect(calls).To(Equal(3))
+	})
+
+	It("should follow the retry policy of the environment", func() {
+		os.Setenv(common.ImporterQemuRetryAttempts, "5")
+		os.Setenv(common.ImporterQemuRetryBackoff, "100ms")
+		calls := 0
+		replaceExecFunction(failingExecFunction(&calls, eagainStderr, eagainStderr, eagainStderr, eagainStderr), func() {
+			_, err := execQemu(nil, nil, "info", "disk.img")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		Expect(calls).To(Equal(5))
+		Expect(sleeps).To(Equal([]time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond}))
+	})
+
+	It("should not retry with a single attempt", func() {
+		os.Setenv(common.ImporterQemuRetryAttempts, "1")
+		calls := 0
+		replaceExecFunction(failingExecFunction(&calls, eagainStderr), func() {
+			_, err := execQemu(nil, nil, "info", "disk.img")
+			Expect(err).To(HaveOccurred())
+		})
+		Expect(calls).To(Equal(1))
+	})
+
+	table.DescribeTable("should reject an invalid retry policy", func(env, value string) {
+		os.Setenv(env, value)
+		calls := 0
+		replaceExecFunction(failingExecFunction(&calls), func() {
+			_, err := execQemu(nil, nil, "info", "disk.img")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(env))
+		})
+		Expect(calls).To(BeZero())
+	},
+		table.Entry("with no attempt", common.ImporterQemuRetryAttempts, "0"),
+		table.Entry("with attempts that are not a number", common.ImporterQemuRetryAttempts, "three"),
+		table.Entry("with a negative backoff", common.ImporterQemuRetryBackoff, "-1s"),
+		table.Entry("with a backoff that is not a duration", common.ImporterQemuRetryBackoff, "2"),
+	)
+})
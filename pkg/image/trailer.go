@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	gzipTrailerSize = 8
+	// gzipMinSize is the size of the smallest gzip member: a 10 byte header, an empty deflate block
+	// and the trailer
+	gzipMinSize = 20
+
+	xzStreamHeaderSize = 12
+	xzStreamFooterSize = 12
+	// xzMaxIndexSize bounds the memory used to read an index, about a million blocks
+	xzMaxIndexSize = 16 << 20
+)
+
+var (
+	xzHeaderMagic = []byte{0xFD, '7', 'z', 'X', 'Z', 0x00}
+	xzFooterMagic = []byte{'Y', 'Z'}
+)
+
+// GzipUncompressedSize returns the size recorded in the trailer of the gzip file read from r, size
+// is the size of the file. The trailer holds the size modulo 4 GiB of the last member of the file
+// only, so the result is a hint: it is only exact for a file of a single member smaller than 4 GiB.
+func GzipUncompressedSize(r io.ReaderAt, size int64) (uint64, error) {
+	if size < gzipMinSize {
+		return 0, errors.Errorf("gzip file of %d bytes is too small", size)
+	}
+	trailer := make([]byte, gzipTrailerSize)
+	if _, err := r.ReadAt(trailer, size-gzipTrailerSize); err != nil {
+		return 0, errors.Wrap(err, "could not read the gzip trailer")
+	}
+	// CRC32 followed by ISIZE
+	return uint64(binary.LittleEndian.Uint32(trailer[4:])), nil
+}
+
+// XzUncompressedSize returns the size of the data of the xz file read from r, size is the size of
+// the file. The sizes recorded in the index of every stream of the file are added up, from the last
+// stream to the first one.
+func XzUncompressedSize(r io.ReaderAt, size int64) (uint64, error) {
+	total, _, err := readXzIndexes(r, size)
+	return total, err
+}
+
+// XzMaxBlockSize returns the size of the data of the largest block of the xz file read from r, size
+// is the size of the file. A block is only decompressed from its start, so this is the most data a
+// reader seeking in the file decompresses to read a single byte.
+func XzMaxBlockSize(r io.ReaderAt, size int64) (uint64, error) {
+	_, maxBlock, err := readXzIndexes(r, size)
+	return maxBlock, err
+}
+
+// readXzIndexes reads the index of every stream of the xz file read from r, from the last stream to
+// the first one, and returns the size of the data of the file along with the one of its largest
+// block.
+func readXzIndexes(r io.ReaderAt, size int64) (uint64, uint64, error) {
+	var total, maxBlock uint64
+	pos := size
+	word := make([]byte, 4)
+	for pos > 0 {
+		if pos < xzStreamHeaderSize+xzStreamFooterSize {
+			return 0, 0, errors.New("xz stream is too small")
+		}
+		// stream padding, a multiple of 4 null bytes, may follow a stream
+		if _, err := r.ReadAt(word, pos-4); err != nil {
+			return 0, 0, errors.Wrap(err, "could not read the xz stream footer")
+		}
+		if bytes.Equal(word, []byte{0, 0, 0, 0}) {
+			pos -= 4
+			continue
+		}
+		start, index, err := readXzStreamIndex(r, pos)
+		if err != nil {
+			return 0, 0, err
+		}
+		total += index.uncompressed
+		if index.maxBlock > maxBlock {
+			maxBlock = index.maxBlock
+		}
+		pos = start
+	}
+	return total, maxBlock, nil
+}
+
+// xzIndex holds the sizes listed in the index of an xz stream.
+type xzIndex struct {
+	// blocksSize is the size of the blocks, padding included
+	blocksSize int64
+	// uncompressed is the size of the data of the blocks
+	uncompressed uint64
+	// maxBlock is the size of the data of the largest block
+	maxBlock uint64
+}
+
+// readXzStreamIndex reads the index of the xz stream ending at end, and returns the offset where the
+// stream starts along with its index.
+func readXzStreamIndex(r io.ReaderAt, end int64) (int64, *xzIndex, error) {
+	footer := make([]byte, xzStreamFooterSize)
+	if _, err := r.ReadAt(footer, end-xzStreamFooterSize); err != nil {
+		return 0, nil, errors.Wrap(err, "could not read the xz stream footer")
+	}
+	// CRC32, backward size, stream flags and magic
+	if !bytes.Equal(footer[10:], xzFooterMagic) {
+		return 0, nil, errors.New("invalid xz stream footer magic")
+	}
+	if crc32.ChecksumIEEE(footer[4:10]) != binary.LittleEndian.Uint32(footer) {
+		return 0, nil, errors.New("xz stream footer checksum mismatch")
+	}
+	indexSize := (int64(binary.LittleEndian.Uint32(footer[4:])) + 1) * 4
+	indexStart := end - xzStreamFooterSize - indexSize
+	if indexSize > xzMaxIndexSize || indexStart < xzStreamHeaderSize {
+		return 0, nil, errors.Errorf("invalid xz index size %d", indexSize)
+	}
+	data := make([]byte, indexSize)
+	if _, err := r.ReadAt(data, indexStart); err != nil {
+		return 0, nil, errors.Wrap(err, "could not read the xz index")
+	}
+	index, err := parseXzIndex(data, indexStart-xzStreamHeaderSize)
+	if err != nil {
+		return 0, nil, err
+	}
+	start := indexStart - index.blocksSize - xzStreamHeaderSize
+	if start < 0 {
+		return 0, nil, errors.New("xz index does not match the size of the stream")
+	}
+	magic := make([]byte, len(xzHeaderMagic))
+	if _, err := r.ReadAt(magic, start); err != nil {
+		return 0, nil, errors.Wrap(err, "could not read the xz stream header")
+	}
+	if !bytes.Equal(magic, xzHeaderMagic) {
+		return 0, nil, errors.New("xz index does not match the size of the stream")
+	}
+	return start, index, nil
+}
+
+// parseXzIndex returns the sizes listed in the xz index. The blocks must fit in maxBlocksSize bytes.
+func parseXzIndex(data []byte, maxBlocksSize int64) (*xzIndex, error) {
+	if crc32.ChecksumIEEE(data[:len(data)-4]) != binary.LittleEndian.Uint32(data[len(data)-4:]) {
+		return nil, errors.New("xz index checksum mismatch")
+	}
+	if data[0] != 0 {
+		return nil, errors.New("invalid xz index indicator")
+	}
+	buf := bytes.NewReader(data[1 : len(data)-4])
+	records, err := binary.ReadUvarint(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid xz index")
+	}
+	index := &xzIndex{}
+	for i := uint64(0); i < records; i++ {
+		unpadded, err := binary.ReadUvarint(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid xz index record")
+		}
+		size, err := binary.ReadUvarint(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid xz index record")
+		}
+		if unpadded == 0 || unpadded > uint64(maxBlocksSize-index.blocksSize) {
+			return nil, errors.New("xz index does not match the size of the stream")
+		}
+		// blocks are padded to a multiple of 4 bytes
+		index.blocksSize += int64((unpadded + 3) &^ 3)
+		index.uncompressed += size
+		if size > index.maxBlock {
+			index.maxBlock = size
+		}
+	}
+	// index padding, up to 3 null bytes
+	if buf.Len() > 3 {
+		return nil, errors.New("invalid xz index padding")
+	}
+	for buf.Len() > 0 {
+		if c, _ := buf.ReadByte(); c != 0 {
+			return nil, errors.New("invalid xz index padding")
+		}
+	}
+	return index, nil
+}
@@ -0,0 +1,88 @@
+package image
+
+import (
+	"bytes"
+	"compress/gzip"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/ulikunitz/xz"
+)
+
+func gzipData(data []byte) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	_, err := w.Write(data)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(w.Close()).To(Succeed())
+	return b.Bytes()
+}
+
+// xzData compresses the data as a xz stream, with a block every blockSize bytes.
+func xzData(data []byte, blockSize int64) []byte {
+	var b bytes.Buffer
+	w, err := xz.WriterConfig{BlockSize: blockSize}.NewWriter(&b)
+	Expect(err).ToNot(HaveOccurred())
+	_, err = w.Write(data)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(w.Close()).To(Succeed())
+	return b.Bytes()
+}
+
+var _ = Describe("Uncompressed size", func() {
+	content := bytes.Repeat([]byte("disk image content "), 1000)
+
+	It("should read the size from the gzip trailer", func() {
+		file := gzipData(content)
+		size, err := GzipUncompressedSize(bytes.NewReader(file), int64(len(file)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(Equal(uint64(len(content))))
+	})
+
+	It("should reject a file too small to be gzip", func() {
+		_, err := GzipUncompressedSize(bytes.NewReader([]byte{0x1F, 0x8B}), 2)
+		Expect(err).To(HaveOccurred())
+	})
+
+	table.DescribeTable("should add up the sizes of the xz index", func(file []byte, expected int) {
+		size, err := XzUncompressedSize(bytes.NewReader(file), int64(len(file)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(Equal(uint64(expected)))
+	},
+		table.Entry("of a single block", xzData(content, 0), len(content)),
+		table.Entry("of several blocks", xzData(content, 4096), len(content)),
+		table.Entry("of several streams", append(xzData(content, 4096), xzData(content[:100], 0)...), len(content)+100),
+		table.Entry("of streams followed by padding", append(xzData(content, 0), make([]byte, 8)...), len(content)),
+		table.Entry("of empty data", xzData(nil, 0), 0),
+	)
+
+	table.DescribeTable("should find the largest xz block", func(file []byte, expected int) {
+		size, err := XzMaxBlockSize(bytes.NewReader(file), int64(len(file)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(Equal(uint64(expected)))
+	},
+		table.Entry("of a single block", xzData(content, 0), len(content)),
+		table.Entry("of several blocks", xzData(content, 4096), 4096),
+		table.Entry("of several streams", append(xzData(content[:100], 0), xzData(content, 4096)...), 4096),
+		table.Entry("of empty data", xzData(nil, 0), 0),
+	)
+
+	table.DescribeTable("should reject an invalid xz file", func(corrupt func([]byte) []byte) {
+		file := corrupt(xzData(content, 4096))
+		_, err := XzUncompressedSize(bytes.NewReader(file), int64(len(file)))
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("truncated", func(file []byte) []byte { return file[:len(file)-1] }),
+		table.Entry("with a corrupt index", func(file []byte) []byte {
+			file[len(file)-xzStreamFooterSize-6] ^= 0xFF
+			return file
+		}),
+		table.Entry("with a corrupt footer", func(file []byte) []byte {
+			file[len(file)-6] ^= 0xFF
+			return file
+		}),
+		table.Entry("with data before the stream", func(file []byte) []byte { return append([]byte("data"), file...) }),
+		table.Entry("too small", func(file []byte) []byte { return file[:8] }),
+	)
+})
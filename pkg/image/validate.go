@@ -17,12 +17,44 @@ const (
 	ExtVhd = ".vhd"
 	// ExtVhdx is a constant for the .vhd Hyper-V Virtual Hard Disk V.2 extenstion
 	ExtVhdx = ".vhdx"
+	// ExtQed is a constant for the .qed QEMU Enhanced Disk extenstion
+	ExtQed = ".qed"
+	// ExtHds is a constant for the .hds Parallels disk image extenstion
+	ExtHds = ".hds"
 	// ExtTar is a constant for the .tar extenstion
 	ExtTar = ".tar"
 	// ExtXz is a constant for the .xz extenstion
 	ExtXz = ".xz"
+	// ExtLzma is a constant for the .lzma extenstion
+	ExtLzma = ".lzma"
+	// ExtZstd is a constant for the .zst extenstion
+	ExtZstd = ".zst"
+	// ExtBz2 is a constant for the .bz2 extenstion
+	ExtBz2 = ".bz2"
+	// ExtLz4 is a constant for the .lz4 extenstion
+	ExtLz4 = ".lz4"
+	// ExtZip is a constant for the .zip extenstion
+	ExtZip = ".zip"
+	// Ext7z is a constant for the .7z extenstion
+	Ext7z = ".7z"
+	// ExtCpio is a constant for the .cpio extenstion
+	ExtCpio = ".cpio"
+	// ExtBrotli is a constant for the .br extenstion
+	ExtBrotli = ".br"
+	// ExtOva is a constant for the .ova Open Virtualization Appliance extenstion, a tar archive
+	ExtOva = ".ova"
+	// ExtOvf is a constant for the .ovf Open Virtualization Format descriptor extenstion
+	ExtOvf = ".ovf"
 	// ExtTarXz is a constant for the .tar.xz extenstion
 	ExtTarXz = ExtTar + ExtXz
 	// ExtTarGz is a constant for the .tar.gz extenstion
 	ExtTarGz = ExtTar + ExtGz
+	// ExtTarBz2 is a constant for the .tar.bz2 extenstion
+	ExtTarBz2 = ExtTar + ExtBz2
+	// ExtTarLz4 is a constant for the .tar.lz4 extenstion
+	ExtTarLz4 = ExtTar + ExtLz4
+	// ExtTarZstd is a constant for the .tar.zst extenstion
+	ExtTarZstd = ExtTar + ExtZstd
+	// ExtCpioGz is a constant for the .cpio.gz extenstion
+	ExtCpioGz = ExtCpio + ExtGz
 )
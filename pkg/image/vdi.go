@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// vdiTextMagic starts the text identifying the creator of a vdi image, as in
+	// "<<< Oracle VM VirtualBox Disk Image >>>\n"
+	vdiTextMagic = "<<< "
+	// vdiVersion1 is the version of the header written by VirtualBox and qemu-img, 1.1
+	vdiVersion1 = 1
+	// vdiHeaderEnd is the offset following the fields of a version 1 header read by ParseVdiHeader
+	vdiHeaderEnd = 0x1C8
+)
+
+// VdiImageType is the type of a vdi image.
+type VdiImageType uint32
+
+const (
+	// VdiImageDynamic is an image whose blocks are allocated when written
+	VdiImageDynamic VdiImageType = 1
+	// VdiImageFixed is an image whose blocks are all allocated
+	VdiImageFixed VdiImageType = 2
+	// VdiImageUndo is an undo image of VirtualBox, reverted when the machine stops
+	VdiImageUndo VdiImageType = 3
+	// VdiImageDiff is a differencing image, holding the blocks written over its parent image
+	VdiImageDiff VdiImageType = 4
+)
+
+// VdiHeader contains the fields of the header of a vdi image read by ParseVdiHeader.
+type VdiHeader struct {
+	// Type is the type of the image
+	Type VdiImageType
+	// DiskSize is the size of the disk
+	DiskSize uint64
+	// HasParent is set when the header references the UUID of a parent image
+	HasParent bool
+}
+
+// ParseVdiHeader parses the header of the vdi image starting with hdr. Both the text starting the
+// header and the signature at offset 0x40 are checked.
+func ParseVdiHeader(hdr []byte) (*VdiHeader, error) {
+	vdi := knownHeaders["vdi"]
+	if !vdi.Match(hdr) {
+		return nil, errors.New("invalid vdi signature")
+	}
+	if !bytes.HasPrefix(hdr, []byte(vdiTextMagic)) {
+		return nil, errors.New("vdi header has no text identifying its creator")
+	}
+	if len(hdr) < vdiHeaderEnd {
+		return nil, errors.Errorf("vdi header of %d bytes is too small", len(hdr))
+	}
+	if major := binary.LittleEndian.Uint32(hdr[0x44:]) >> 16; major != vdiVersion1 {
+		return nil, errors.Errorf("unsupported vdi header version %d", major)
+	}
+	return &VdiHeader{
+		Type:     VdiImageType(binary.LittleEndian.Uint32(hdr[0x4C:])),
+		DiskSize: binary.LittleEndian.Uint64(hdr[0x170:]),
+		// UUID of the linkage to the parent image
+		HasParent: !bytes.Equal(hdr[0x1A8:0x1B8], make([]byte, 16)),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package image
+
+import (
+	"encoding/binary"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Vdi header", func() {
+	It("should parse the header of an image written by qemu-img", func() {
+		hdr, err := ParseVdiHeader(testImage("tinyCore.vdi")()[:MaxExpectedHdrSize])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*hdr).To(Equal(VdiHeader{Type: VdiImageDynamic, DiskSize: 18 << 20}))
+	})
+
+	It("should report the parent of a differencing image", func() {
+		data := testImage("tinyCore.vdi")()[:MaxExpectedHdrSize]
+		binary.LittleEndian.PutUint32(data[0x4C:], uint32(VdiImageDiff))
+		copy(data[0x1A8:], data[0x188:0x198])
+		hdr, err := ParseVdiHeader(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hdr.Type).To(Equal(VdiImageDiff))
+		Expect(hdr.HasParent).To(BeTrue())
+	})
+
+	table.DescribeTable("should reject an invalid header", func(corrupt func([]byte) []byte, errString string) {
+		_, err := ParseVdiHeader(corrupt(testImage("tinyCore.vdi")()[:MaxExpectedHdrSize]))
+		Expect(err).To(MatchError(ContainSubstring(errString)))
+	},
+		table.Entry("without signature", func(hdr []byte) []byte {
+			hdr[0x40] = 0
+			return hdr
+		}, "invalid vdi signature"),
+		table.Entry("without text", func(hdr []byte) []byte {
+			copy(hdr, "QEMU")
+			return hdr
+		}, "no text identifying its creator"),
+		table.Entry("of an unknown version", func(hdr []byte) []byte {
+			binary.LittleEndian.PutUint32(hdr[0x44:], 0x00020000)
+			return hdr
+		}, "unsupported vdi header version 2"),
+		table.Entry("truncated", func(hdr []byte) []byte { return hdr[:0x100] }, "too small"),
+	)
+})
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// vhdxRegionTableOffset is the offset of the first copy of the region table, the second one
+	// follows it
+	vhdxRegionTableOffset = 192 << 10
+	vhdxRegionTableSize   = 64 << 10
+	// VhdxRegionTablesEnd is the offset following the two copies of the region table of a vhdx image
+	VhdxRegionTablesEnd = vhdxRegionTableOffset + 2*vhdxRegionTableSize
+	// vhdxMaxEntries is the maximum number of entries of the region table and of the metadata table
+	vhdxMaxEntries = 2047
+	vhdxEntrySize  = 32
+)
+
+var (
+	vhdxMetadataRegionID = vhdxGUID("8B7CA206-4790-4B9A-B8FE-575F050F886E")
+	vhdxFileParametersID = vhdxGUID("CAA16737-FA36-4D43-B3B6-33F0AA44E76B")
+	vhdxVirtualSizeID    = vhdxGUID("2FA54224-CD1B-4876-B211-5DBED83BF4B8")
+	vhdxLogicalSectorID  = vhdxGUID("8141BF1D-A96F-4709-BA47-F233A8FAAB5F")
+
+	crc32c = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// VhdxParameters are the parameters of a vhdx image recorded in its metadata region.
+type VhdxParameters struct {
+	// VirtualSize is the size of the disk
+	VirtualSize uint64
+	// LogicalSectorSize is the size of the sectors of the disk, 512 or 4096 bytes
+	LogicalSectorSize uint32
+	// HasParent is set for a differencing image, whose data is partly in its parent image
+	HasParent bool
+}
+
+// vhdxGUID returns the on-disk form of the GUID, its first three fields are little endian.
+func vhdxGUID(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(b) != 16 {
+		panic("invalid GUID " + s)
+	}
+	b[0], b[1], b[2], b[3] = b[3], b[2], b[1], b[0]
+	b[4], b[5] = b[5], b[4]
+	b[6], b[7] = b[7], b[6]
+	return b
+}
+
+// VhdxMetadataRegion returns the offset and the length of the metadata region of the vhdx image
+// starting with hdr, which holds the two copies of the region table, VhdxRegionTablesEnd bytes. The
+// second copy is read when the checksum of the first one does not match.
+func VhdxMetadataRegion(hdr []byte) (int64, int64, error) {
+	if len(hdr) < VhdxRegionTablesEnd {
+		return 0, 0, errors.Errorf("vhdx header of %d bytes does not hold the region tables", len(hdr))
+	}
+	var err error
+	for i := 0; i < 2; i++ {
+		start := vhdxRegionTableOffset + i*vhdxRegionTableSize
+		var offset, length int64
+		if offset, length, err = parseVhdxRegionTable(hdr[start : start+vhdxRegionTableSize]); err == nil {
+			return offset, length, nil
+		}
+	}
+	return 0, 0, err
+}
+
+func parseVhdxRegionTable(table []byte) (int64, int64, error) {
+	if string(table[:4]) != "regi" {
+		return 0, 0, errors.New("invalid vhdx region table signature")
+	}
+	if !vhdxChecksumValid(table) {
+		return 0, 0, errors.New("vhdx region table checksum mismatch")
+	}
+	count := binary.LittleEndian.Uint32(table[8:])
+	if count > vhdxMaxEntries {
+		return 0, 0, errors.Errorf("invalid number of vhdx region table entries %d", count)
+	}
+	for i := 0; i < int(count); i++ {
+		entry := table[16+i*vhdxEntrySize:]
+		if bytes.Equal(entry[:16], vhdxMetadataRegionID) {
+			return int64(binary.LittleEndian.Uint64(entry[16:])), int64(binary.LittleEndian.Uint32(entry[24:])), nil
+		}
+	}
+	return 0, 0, errors.New("vhdx region table has no metadata region")
+}
+
+// vhdxChecksumValid checks the CRC-32C of a structure, computed with its checksum field zeroed.
+func vhdxChecksumValid(data []byte) bool {
+	zeroed := append([]byte{}, data...)
+	copy(zeroed[4:8], []byte{0, 0, 0, 0})
+	return crc32.Checksum(zeroed, crc32c) == binary.LittleEndian.Uint32(data[4:])
+}
+
+// ParseVhdxMetadata returns the parameters recorded in the metadata region of a vhdx image.
+func ParseVhdxMetadata(region []byte) (*VhdxParameters, error) {
+	if len(region) < vhdxEntrySize || string(region[:8]) != "metadata" {
+		return nil, errors.New("invalid vhdx metadata table signature")
+	}
+	count := int(binary.LittleEndian.Uint16(region[10:]))
+	if count > vhdxMaxEntries || len(region) < vhdxEntrySize*(count+1) {
+		return nil, errors.Errorf("invalid number of vhdx metadata table entries %d", count)
+	}
+	params := &VhdxParameters{}
+	for i := 1; i <= count; i++ {
+		entry := region[i*vhdxEntrySize:]
+		offset := uint64(binary.LittleEndian.Uint32(entry[16:]))
+		length := uint64(binary.LittleEndian.Uint32(entry[20:]))
+		if offset+length > uint64(len(region)) {
+			return nil, errors.New("vhdx metadata item is outside of the metadata region")
+		}
+		item := region[offset : offset+length]
+		switch {
+		case bytes.Equal(entry[:16], vhdxFileParametersID) && length >= 8:
+			// block size, then the flags: LeaveBlocksAllocated and HasParent
+			params.HasParent = binary.LittleEndian.Uint32(item[4:])&2 != 0
+		case bytes.Equal(entry[:16], vhdxVirtualSizeID) && length >= 8:
+			params.VirtualSize = binary.LittleEndian.Uint64(item)
+		case bytes.Equal(entry[:16], vhdxLogicalSectorID) && length >= 4:
+			params.LogicalSectorSize = binary.LittleEndian.Uint32(item)
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,113 @@
+package image
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// vhdxMetadataOffset is the offset of the metadata region written by qemu-img
+const vhdxMetadataOffset = 2 << 20
+
+func le32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func le64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	return b
+}
+
+// vhdxImage returns the start of a vhdx image of 1 GiB, up to the end of its metadata region.
+func vhdxImage(hasParent bool, logicalSectorSize uint32) []byte {
+	img := make([]byte, vhdxMetadataOffset+64<<10)
+	copy(img, vhdxHeader())
+	for _, start := range []int{vhdxRegionTableOffset, vhdxRegionTableOffset + vhdxRegionTableSize} {
+		table := img[start : start+vhdxRegionTableSize]
+		copy(table, "regi")
+		binary.LittleEndian.PutUint32(table[8:], 1)
+		copy(table[16:], vhdxMetadataRegionID)
+		binary.LittleEndian.PutUint64(table[32:], vhdxMetadataOffset)
+		binary.LittleEndian.PutUint32(table[40:], 64<<10)
+		binary.LittleEndian.PutUint32(table[4:], crc32.Checksum(table, crc32c))
+	}
+	metadata := img[vhdxMetadataOffset:]
+	copy(metadata, "metadata")
+	var flags uint32
+	if hasParent {
+		flags = 2
+	}
+	items := []struct {
+		id   []byte
+		data []byte
+	}{
+		{vhdxFileParametersID, append(le32(32<<20), le32(flags)...)},
+		{vhdxVirtualSizeID, le64(1 << 30)},
+		{vhdxLogicalSectorID, le32(logicalSectorSize)},
+	}
+	binary.LittleEndian.PutUint16(metadata[10:], uint16(len(items)))
+	offset := 64 << 10 / 2
+	for i, item := range items {
+		entry := metadata[(i+1)*vhdxEntrySize:]
+		copy(entry, item.id)
+		binary.LittleEndian.PutUint32(entry[16:], uint32(offset))
+		binary.LittleEndian.PutUint32(entry[20:], uint32(len(item.data)))
+		offset += copy(metadata[offset:], item.data)
+	}
+	return img
+}
+
+// parseVhdx returns the parameters of the vhdx image starting with img.
+func parseVhdx(img []byte) (*VhdxParameters, error) {
+	offset, length, err := VhdxMetadataRegion(img)
+	if err != nil {
+		return nil, err
+	}
+	return ParseVhdxMetadata(img[offset : offset+length])
+}
+
+var _ = Describe("Vhdx metadata", func() {
+	table.DescribeTable("should read the parameters", func(hasParent bool, logicalSectorSize uint32) {
+		params, err := parseVhdx(vhdxImage(hasParent, logicalSectorSize))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*params).To(Equal(VhdxParameters{VirtualSize: 1 << 30, LogicalSectorSize: logicalSectorSize, HasParent: hasParent}))
+	},
+		table.Entry("of an image of 512 byte sectors", false, uint32(512)),
+		table.Entry("of an image of 4K sectors", false, uint32(4096)),
+		table.Entry("of a differencing image", true, uint32(512)),
+	)
+
+	It("should read the second region table when the checksum of the first one does not match", func() {
+		img := vhdxImage(true, 512)
+		img[vhdxRegionTableOffset+8]++
+		params, err := parseVhdx(img)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(params.HasParent).To(BeTrue())
+	})
+
+	table.DescribeTable("should reject an invalid image", func(corrupt func([]byte) []byte) {
+		_, err := parseVhdx(corrupt(vhdxImage(false, 512)))
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("with both region tables corrupt", func(img []byte) []byte {
+			img[vhdxRegionTableOffset+8]++
+			img[vhdxRegionTableOffset+vhdxRegionTableSize+8]++
+			return img
+		}),
+		table.Entry("truncated before the region tables", func(img []byte) []byte { return img[:vhdxRegionTableOffset] }),
+		table.Entry("with an invalid metadata table signature", func(img []byte) []byte {
+			img[vhdxMetadataOffset] = 'M'
+			return img
+		}),
+		table.Entry("with a metadata item outside of the region", func(img []byte) []byte {
+			binary.LittleEndian.PutUint32(img[vhdxMetadataOffset+vhdxEntrySize+16:], 64<<10)
+			return img
+		}),
+	)
+})
@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"regexp"
+)
+
+// vmdkDescriptorMagic starts the text descriptor of a vmdk image, a monolithicSparse or
+// streamOptimized image embeds its descriptor after its KDMV header instead.
+const vmdkDescriptorMagic = "# Disk DescriptorFile"
+
+// vmdkCreateTypes are the subformats of the vmdk images holding their data in a single file
+var vmdkCreateTypes = map[string]bool{
+	"monolithicSparse": true,
+	"streamOptimized":  true,
+}
+
+// vmdkExtentMatch matches the extent lines of a vmdk descriptor, as in: RW 4192256 SPARSE "disk-s001.vmdk"
+var vmdkExtentMatch = regexp.MustCompile(`(?m)^\s*(?:RW|RDONLY|NOACCESS)\s+\d+\s+\w+\s+"([^"]+)"`)
+
+// VmdkDescriptorExtents returns the names of the extent files referenced by the vmdk descriptor
+// starting with hdr. The descriptor may be longer than hdr, its other extents are not returned.
+func VmdkDescriptorExtents(hdr []byte) []string {
+	var extents []string
+	for _, match := range vmdkExtentMatch.FindAllSubmatch(hdr, -1) {
+		extents = append(extents, string(match[1]))
+	}
+	return extents
+}
@@ -0,0 +1,35 @@
+package image
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// vmdkDescriptor is the descriptor of a twoGbMaxExtentSparse image of 3 GiB, as written by qemu-img.
+const vmdkDescriptor = `# Disk DescriptorFile
+version=1
+CID=a5af6503
+parentCID=ffffffff
+createType="twoGbMaxExtentSparse"
+
+# Extent description
+RW 4192256 SPARSE "disk-s001.vmdk"
+RW 2099200 SPARSE "disk-s002.vmdk"
+
+# The Disk Data Base
+#DDB
+
+ddb.virtualHWVersion = "4"
+ddb.geometry.cylinders = "6241"
+`
+
+var _ = Describe("Vmdk descriptor", func() {
+	table.DescribeTable("should return the extent files", func(descriptor string, expected []string) {
+		Expect(VmdkDescriptorExtents([]byte(descriptor))).To(Equal(expected))
+	},
+		table.Entry("of a multi-extent image", vmdkDescriptor, []string{"disk-s001.vmdk", "disk-s002.vmdk"}),
+		table.Entry("of a flat image", "# Disk DescriptorFile\ncreateType=\"monolithicFlat\"\nRW 2097152 FLAT \"disk-flat.vmdk\" 0\n", []string{"disk-flat.vmdk"}),
+		table.Entry("of a descriptor without extents", "# Disk DescriptorFile\nversion=1\n", nil),
+	)
+})
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	xzLzma2FilterID = 0x21
+	// xzMaxDictSizeBits is the largest dictionary size encoded by the LZMA2 filter properties
+	xzMaxDictSizeBits = 40
+)
+
+// XzDictionarySize returns the size of the LZMA2 dictionary of the first block of the xz stream
+// starting with hdr, 0 if the stream has no block. The decoder allocates the dictionary up front, it
+// is most of the memory needed to decode the block. The following blocks of a stream written by a
+// single compressor use the same dictionary size.
+func XzDictionarySize(hdr []byte) (int64, error) {
+	if len(hdr) < xzStreamHeaderSize+1 || !bytes.Equal(hdr[:len(xzHeaderMagic)], xzHeaderMagic) {
+		return 0, errors.New("invalid xz stream header")
+	}
+	block := hdr[xzStreamHeaderSize:]
+	if block[0] == 0 {
+		// index indicator, the stream is empty
+		return 0, nil
+	}
+	size := (int(block[0]) + 1) * 4
+	if len(block) < size {
+		return 0, errors.Errorf("xz block header of %d bytes is larger than the header read", size)
+	}
+	if crc32.ChecksumIEEE(block[:size-4]) != binary.LittleEndian.Uint32(block[size-4:size]) {
+		return 0, errors.New("xz block header checksum mismatch")
+	}
+	flags := block[1]
+	buf := bytes.NewReader(block[2 : size-4])
+	// compressed and uncompressed sizes
+	for _, present := range []bool{flags&0x40 != 0, flags&0x80 != 0} {
+		if !present {
+			continue
+		}
+		if _, err := binary.ReadUvarint(buf); err != nil {
+			return 0, errors.Wrap(err, "invalid xz block header")
+		}
+	}
+	filters := int(flags&0x03) + 1
+	for i := 0; i < filters; i++ {
+		id, err := binary.ReadUvarint(buf)
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid xz filter flags")
+		}
+		propsSize, err := binary.ReadUvarint(buf)
+		if err != nil || propsSize > uint64(buf.Len()) {
+			return 0, errors.New("invalid xz filter flags")
+		}
+		props := make([]byte, propsSize)
+		if _, err := buf.Read(props); err != nil && propsSize > 0 {
+			return 0, errors.Wrap(err, "invalid xz filter flags")
+		}
+		if id != xzLzma2FilterID {
+			continue
+		}
+		if propsSize != 1 || props[0] > xzMaxDictSizeBits {
+			return 0, errors.New("invalid xz LZMA2 filter properties")
+		}
+		if props[0] == xzMaxDictSizeBits {
+			return 1<<32 - 1, nil
+		}
+		return int64(2|props[0]&1) << (props[0]/2 + 11), nil
+	}
+	return 0, errors.New("xz block header has no LZMA2 filter")
+}
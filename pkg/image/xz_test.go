@@ -0,0 +1,72 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/ulikunitz/xz"
+)
+
+// xzBlockHeader returns the start of a xz stream, its first block has a single LZMA2 filter of the
+// passed in properties. The writer allocates the dictionary, a large one is only tested this way.
+func xzBlockHeader(props byte) []byte {
+	hdr := append([]byte{}, xzHeaderMagic...)
+	hdr = append(hdr, 0, 1, 0, 0, 0, 0)
+	// 12 bytes, a single filter
+	block := []byte{2, 0, xzLzma2FilterID, 1, props, 0, 0, 0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(block[8:], crc32.ChecksumIEEE(block[:8]))
+	return append(hdr, block...)
+}
+
+var _ = Describe("Xz dictionary size", func() {
+	content := bytes.Repeat([]byte("disk image content "), 1000)
+
+	table.DescribeTable("should read the dictionary size of the first block", func(dictCap int) {
+		var b bytes.Buffer
+		w, err := xz.WriterConfig{DictCap: dictCap}.NewWriter(&b)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = w.Write(content)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		size, err := XzDictionarySize(b.Bytes())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(Equal(int64(dictCap)))
+	},
+		table.Entry("of 1 MiB", 1<<20),
+		table.Entry("of 8 MiB", 8<<20),
+	)
+
+	table.DescribeTable("should decode the dictionary size of the LZMA2 filter", func(props byte, expected int64) {
+		size, err := XzDictionarySize(xzBlockHeader(props))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(Equal(expected))
+	},
+		table.Entry("of 4 KiB", byte(0), int64(4<<10)),
+		table.Entry("of 1.5 GiB", byte(37), int64(3<<29)),
+		table.Entry("of 4 GiB", byte(40), int64(1<<32-1)),
+	)
+
+	It("should report no dictionary for an empty stream", func() {
+		// the index indicator follows the stream header
+		empty := append(xzBlockHeader(0)[:xzStreamHeaderSize], 0)
+		size, err := XzDictionarySize(empty)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(BeZero())
+	})
+
+	table.DescribeTable("should reject an invalid block header", func(corrupt func([]byte) []byte) {
+		_, err := XzDictionarySize(corrupt(xzData(content, 0)))
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("with a checksum mismatch", func(file []byte) []byte {
+			file[xzStreamHeaderSize+2] ^= 0xFF
+			return file
+		}),
+		table.Entry("truncated", func(file []byte) []byte { return file[:xzStreamHeaderSize+2] }),
+		table.Entry("not xz", func(file []byte) []byte { return content }),
+	)
+})
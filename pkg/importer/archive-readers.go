@@ -0,0 +1,364 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bodgit/sevenzip"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// StreamToFile writes the data of the top-level reader to the passed in file. Archives that cannot
+// be read as a stream, like zip and 7z, are first spooled next to the file, and the selected entry
+// is then extracted to the file. So are tar archives when the backing chain of a qcow2 entry is
+// extracted along with it, the backing files are extracted next to the file. The holes of a sparse
+// tar entry are skipped rather than written, and so are the blocks of zeros of the data streamed to
+// the file. The digest of the data written is computed along the way, see Digests, and the data of
+// the source is then verified against its checksum and its signature, if any.
+func (fr *FormatReaders) StreamToFile(fileName string) error {
+	if err := fr.streamToFile(fileName); err != nil {
+		return err
+	}
+	return fr.verifySource()
+}
+
+func (fr *FormatReaders) streamToFile(fileName string) error {
+	var openEntry func(archiveFile, entryName string) (*archiveEntryReader, error)
+	var format, ext string
+	switch {
+	case fr.ArchiveZip:
+		openEntry, format, ext = openZipEntry, "zip", image.ExtZip
+	case fr.Archive7z:
+		openEntry, format, ext = open7zEntry, "7z", image.Ext7z
+	case fr.spoolTar:
+		openEntry, format, ext = openTarEntry, "tar", image.ExtTar
+	case fr.sparseEntry != nil:
+		fr.payloadDigest = newDigestReader(fr.TopReader())
+		return fr.sparseEntry.streamToFile(fr.payloadDigest, fileName)
+	default:
+		fr.payloadDigest = newDigestReader(fr.TopReader())
+		skipped, err := util.StreamSparseDataToFile(fr.payloadDigest, fileName)
+		if err == nil && skipped > 0 {
+			klog.V(1).Infof("skipped %d bytes of zeros writing %s\n", skipped, fileName)
+		}
+		return err
+	}
+	archiveFile := fileName + ext
+	if err := util.StreamDataToFileWithSize(fr.TopReader(), archiveFile, fr.total); err != nil {
+		return err
+	}
+	defer os.Remove(archiveFile)
+	entry, err := openEntry(archiveFile, fr.archiveEntry)
+	if err != nil {
+		return archiveError(format, err)
+	}
+	defer entry.Close()
+	if fr.maxSize > 0 && entry.size > uint64(fr.maxSize) {
+		return errors.Wrapf(ErrDecompressedTooLarge, "%s entry of %d bytes", format, entry.size)
+	}
+	fr.payloadDigest = newDigestReader(fr.countDecompressed(entry))
+	err = util.StreamDataToFileWithSize(fr.limitSize(fr.withContext(fr.payloadDigest)), fileName, entry.size)
+	if err == nil && fr.FlattenChain {
+		err = fr.extractBackingChain(archiveFile, entry.name, fileName, openEntry)
+	}
+	return archiveError(format, err)
+}
+
+// Discard reads the data of the top-level reader without writing it, digesting it as StreamToFile
+// does, for a data source that writes the data itself. The data of the source is then verified
+// against its checksum and its signature, if any.
+func (fr *FormatReaders) Discard() error {
+	fr.payloadDigest = newDigestReader(fr.TopReader())
+	if _, err := io.Copy(io.Discard, fr.payloadDigest); err != nil {
+		return err
+	}
+	return fr.verifySource()
+}
+
+// extractBackingChain extracts the backing files of the qcow2 image extracted from the archive to
+// fileName, next to it. The name of a backing file is relative to the image referencing it, both
+// in the archive and once extracted. Other images have no backing file.
+func (fr *FormatReaders) extractBackingChain(archiveFile, entryName, fileName string, openEntry func(archiveFile, entryName string) (*archiveEntryReader, error)) error {
+	dir := filepath.Dir(fileName)
+	imagePath := fileName
+	for n := 0; ; n++ {
+		backing, err := readBackingFile(imagePath)
+		if err != nil || backing == "" {
+			return err
+		}
+		if n == image.MaxBackingChainLength {
+			return image.NewFormatError(image.ErrUnsupportedFormat, "qcow2",
+				errors.Errorf("backing chain of %q is longer than %d images", entryName, image.MaxBackingChainLength))
+		}
+		backingPath, err := image.BackingFilePath(dir, imagePath, backing)
+		if err != nil {
+			return image.NewFormatError(image.ErrUnsupportedFormat, "qcow2", err)
+		}
+		// the data extracted so far, the archive included, is not overwritten
+		if _, err := os.Stat(backingPath); err == nil {
+			return image.NewFormatError(image.ErrUnsupportedFormat, "qcow2", errors.Errorf("backing file %q of %q conflicts with a file already extracted", backing, entryName))
+		}
+		entryName = path.Join(path.Dir(entryName), backing)
+		if err := fr.extractEntry(archiveFile, entryName, backingPath, openEntry); err != nil {
+			return err
+		}
+		imagePath = backingPath
+	}
+}
+
+// extractEntry extracts the named entry of the archive to fileName, creating its directory.
+func (fr *FormatReaders) extractEntry(archiveFile, entryName, fileName string, openEntry func(archiveFile, entryName string) (*archiveEntryReader, error)) error {
+	entry, err := openEntry(archiveFile, entryName)
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+	klog.V(2).Infof("extracting the backing file %q to %s, %d bytes\n", entryName, fileName, entry.size)
+	if err := os.MkdirAll(filepath.Dir(fileName), 0750); err != nil {
+		return errors.Wrapf(err, "could not create the directory of backing file %q", entryName)
+	}
+	return util.StreamDataToFileWithSize(fr.withContext(entry), fileName, entry.size)
+}
+
+// readBackingFile returns the name of the backing file of the qcow2 image in fileName, if any. The
+// header of the image is checked as by checkQcow2.
+func readBackingFile(fileName string) (string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", errors.Wrap(err, "could not open the extracted image")
+	}
+	defer f.Close()
+	hdr := make([]byte, image.Qcow2HeaderCheckSize)
+	n, err := f.ReadAt(hdr, 0)
+	if err != nil && err != io.EOF {
+		return "", errors.Wrap(err, "could not read the extracted image")
+	}
+	if err := image.CheckQcow2Header(hdr[:n]); err != nil {
+		return "", err
+	}
+	return image.Qcow2BackingFile(f)
+}
+
+// archiveDataErrors are the errors of the tar and zip readers caused by the data of the archive, the
+// errors of the 7z reader are classified by sevenzipError.
+var archiveDataErrors = []error{
+	tar.ErrHeader,
+	zip.ErrFormat,
+	zip.ErrChecksum,
+}
+
+// archiveError returns err, an error opening or extracting an entry of an archive spooled to a
+// file, as a FormatError when it is caused by the data of the archive. The other errors, of the
+// file system for instance, are returned as is.
+func archiveError(format string, err error) error {
+	var formatErr *image.FormatError
+	if err == nil || errors.As(err, &formatErr) {
+		return err
+	}
+	for _, unsupported := range unsupportedErrors {
+		if errors.Is(err, unsupported) {
+			return image.NewFormatError(image.ErrUnsupportedFormat, format, err)
+		}
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return image.NewFormatError(image.ErrTruncatedStream, format, err)
+	}
+	for _, corrupt := range archiveDataErrors {
+		if errors.Is(err, corrupt) {
+			return image.NewFormatError(image.ErrCorruptArchive, format, err)
+		}
+	}
+	return err
+}
+
+// archiveEntryReader reads a single entry of an archive, and closes the archive along with the entry.
+type archiveEntryReader struct {
+	io.ReadCloser
+	archive io.Closer
+	size    uint64 // size of the entry once extracted, as recorded by the archive
+	name    string // name of the entry in the archive
+}
+
+func (r *archiveEntryReader) Close() error {
+	err := r.ReadCloser.Close()
+	if archiveErr := r.archive.Close(); err == nil {
+		err = archiveErr
+	}
+	return err
+}
+
+// Return a reader for the named entry of the zip archive. If no entry name is passed in, the archive
+// must contain a single regular file.
+func openZipEntry(archiveFile, entryName string) (*archiveEntryReader, error) {
+	archive, err := zip.OpenReader(archiveFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open zip archive")
+	}
+	var files []*zip.File
+	var names []string
+	for _, f := range archive.File {
+		if f.Mode().IsRegular() {
+			files = append(files, f)
+			names = append(names, f.Name)
+		}
+	}
+	i, err := selectArchiveEntry("zip", names, entryName)
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	// the 64 bit size is read from the zip64 extra field of the entries over 4 GiB
+	klog.V(2).Infof("zip: extracting %q, %d bytes\n", files[i].Name, files[i].UncompressedSize64)
+	rc, err := files[i].Open()
+	if err != nil {
+		archive.Close()
+		return nil, errors.Wrapf(err, "could not open zip entry %q", files[i].Name)
+	}
+	return &archiveEntryReader{ReadCloser: rc, archive: archive, size: files[i].UncompressedSize64, name: files[i].Name}, nil
+}
+
+// Return a reader for the named entry of the 7z archive. If no entry name is passed in, the archive
+// must contain a single file.
+func open7zEntry(archiveFile, entryName string) (*archiveEntryReader, error) {
+	archive, err := open7zArchive(archiveFile)
+	if err != nil {
+		return nil, errors.Wrap(sevenzipError(err), "could not open 7z archive")
+	}
+	var files []*sevenzip.File
+	var names []string
+	for _, f := range archive.File {
+		if !f.FileInfo().IsDir() {
+			files = append(files, f)
+			names = append(names, f.Name)
+		}
+	}
+	i, err := selectArchiveEntry("7z", names, entryName)
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	klog.V(2).Infof("7z: extracting %q, %d bytes\n", files[i].Name, files[i].UncompressedSize)
+	rc, err := files[i].Open()
+	if err != nil {
+		archive.Close()
+		return nil, errors.Wrapf(sevenzipError(err), "could not open 7z entry %q", files[i].Name)
+	}
+	return &archiveEntryReader{ReadCloser: &sevenzipEntryReader{rc}, archive: archive, size: files[i].UncompressedSize, name: files[i].Name}, nil
+}
+
+// open7zArchive opens the 7z archive of archiveFile. The 7z reader panics on some headers it does not
+// expect, a header without substreams info for instance, such an archive is reported as corrupt.
+func open7zArchive(archiveFile string) (archive *sevenzip.ReadCloser, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			archive, err = nil, errors.Errorf("invalid 7z header: %v", r)
+		}
+	}()
+	return sevenzip.OpenReader(archiveFile)
+}
+
+// sevenzipError returns err, an error of the 7z reader, as a FormatError of a corrupt archive unless
+// it is an error of the file system or a truncated stream. The 7z reader does not export its errors,
+// so the archives using a method it does not implement, or encrypted, are reported as corrupt too.
+func sevenzipError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	return image.NewFormatError(image.ErrCorruptArchive, "7z", err)
+}
+
+// sevenzipEntryReader classifies the errors of the data of a 7z entry with sevenzipError.
+type sevenzipEntryReader struct {
+	io.ReadCloser
+}
+
+func (r *sevenzipEntryReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = sevenzipError(err)
+	}
+	return n, err
+}
+
+// Return a reader for the named entry of the tar archive. If no entry name is passed in, the archive
+// must contain a single regular file.
+func openTarEntry(archiveFile, entryName string) (*archiveEntryReader, error) {
+	archive, err := os.Open(archiveFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open tar archive")
+	}
+	tr := tar.NewReader(archive)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			archive.Close()
+			return nil, errors.Wrap(err, "could not read tar header")
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		if entryName != "" && path.Clean(hdr.Name) == path.Clean(entryName) {
+			klog.V(2).Infof("tar: extracting %q, %d bytes\n", hdr.Name, hdr.Size)
+			return &archiveEntryReader{ReadCloser: io.NopCloser(tr), archive: archive, size: uint64(hdr.Size), name: hdr.Name}, nil
+		}
+		names = append(names, hdr.Name)
+	}
+	archive.Close()
+	i, err := selectArchiveEntry("tar", names, entryName)
+	if err != nil {
+		return nil, err
+	}
+	return openTarEntry(archiveFile, names[i])
+}
+
+// Return the index of the named entry from the passed in names of the regular files of an archive.
+// If no entry name is passed in, the archive must contain a single regular file.
+func selectArchiveEntry(format string, names []string, entryName string) (int, error) {
+	for i, name := range names {
+		if entryName != "" && path.Clean(name) == path.Clean(entryName) {
+			return i, nil
+		}
+	}
+	switch {
+	case entryName != "":
+		return -1, errors.Errorf("%s archive does not contain %q, entries: %s", format, entryName, strings.Join(names, ", "))
+	case len(names) == 0:
+		return -1, image.NewFormatError(image.ErrUnsupportedFormat, format,
+			errors.Errorf("%s archive does not contain a regular file", format))
+	case len(names) > 1:
+		return -1, image.NewFormatError(image.ErrUnsupportedFormat, format,
+			errors.Errorf("%s archive contains more than one file, select the entry to import or repackage the archive, entries: %s", format, strings.Join(names, ", ")))
+	}
+	return 0, nil
+}
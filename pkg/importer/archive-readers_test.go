@@ -0,0 +1,371 @@
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/tests/utils"
+)
+
+var _ = Describe("Archive readers", func() {
+	var (
+		fr     *FormatReaders
+		tmpDir string
+		err    error
+	)
+
+	BeforeEach(func() {
+		fr = nil
+		tmpDir, err = os.MkdirTemp("", "scratch")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if fr != nil {
+			fr.Close()
+		}
+		os.Unsetenv(common.ImporterArchiveEntry)
+		os.Unsetenv(common.ImporterFlattenBackingChain)
+		os.RemoveAll(tmpDir)
+	})
+
+	createZip := func(entries ...string) string {
+		zipFile := filepath.Join(tmpDir, "test.zip")
+		f, err := os.Create(zipFile)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		w := zip.NewWriter(f)
+		_, err = w.Create("images/")
+		Expect(err).NotTo(HaveOccurred())
+		for _, entry := range entries {
+			ew, err := w.Create(entry)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ew.Write([]byte("content of " + entry))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		// pad the archive so the header can be read
+		Expect(w.SetComment(strings.Repeat(" ", image.MaxExpectedHdrSize))).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		return zipFile
+	}
+
+	table.DescribeTable("should stream a zip archive to a file", func(archiveEntry, expectedContent, expectedErr string, entries ...string) {
+		if archiveEntry != "" {
+			os.Setenv(common.ImporterArchiveEntry, archiveEntry)
+		}
+		f, err := os.Open(createZip(entries...))
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fr.ArchiveZip).To(BeTrue())
+
+		fileName := filepath.Join(tmpDir, tempFile)
+		err = fr.StreamToFile(fileName)
+		if expectedErr != "" {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErr))
+		} else {
+			Expect(err).NotTo(HaveOccurred())
+			content, err := os.ReadFile(fileName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(expectedContent))
+		}
+		_, err = os.Stat(fileName + ".zip")
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	},
+		table.Entry("with a single entry", "", "content of images/disk.img", "", "images/disk.img"),
+		table.Entry("with the selected entry", "images/disk.img", "content of images/disk.img", "", "README", "images/disk.img"),
+		table.Entry("with a missing selected entry", "disk.qcow2", "", "entries: README, images/disk.img", "README", "images/disk.img"),
+		table.Entry("with multiple entries", "", "", "entries: README, images/disk.img", "README", "images/disk.img"),
+		table.Entry("without an entry", "", "", "does not contain a regular file"),
+	)
+
+	It("should extract an entry larger than 4 GiB from a zip64 archive", func() {
+		const size = 4<<30 + 4096
+		zipFile := filepath.Join(tmpDir, "large.zip")
+		f, err := os.Create(zipFile)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		w := zip.NewWriter(sparseFile{f})
+		ew, err := w.CreateHeader(&zip.FileHeader{Name: "disk.img", Method: zip.Store})
+		Expect(err).NotTo(HaveOccurred())
+		zeros := make([]byte, 1<<20)
+		for written := 0; written < size; {
+			chunk := zeros
+			if size-written < len(chunk) {
+				chunk = chunk[:size-written]
+			}
+			n, err := ew.Write(chunk)
+			Expect(err).NotTo(HaveOccurred())
+			written += n
+		}
+		Expect(w.Close()).To(Succeed())
+		// the central directory follows the entry, past 4 GiB, a zip64 end of central directory
+		// locator records its offset
+		stat, err := f.Stat()
+		Expect(err).NotTo(HaveOccurred())
+		tail := make([]byte, stat.Size()-size)
+		_, err = f.ReadAt(tail, size)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes.Contains(tail, []byte("PK\x06\x07"))).To(BeTrue())
+
+		entry, err := openZipEntry(zipFile, "")
+		Expect(err).NotTo(HaveOccurred())
+		defer entry.Close()
+		Expect(entry.size).To(Equal(uint64(size)))
+		n, err := io.Copy(io.Discard, entry)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(size)))
+	})
+
+	It("should reject a zip entry larger than the maximum before extracting it", func() {
+		f, err := os.Open(createZip("images/disk.img"))
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).NotTo(HaveOccurred())
+		fr.SetMaxDecompressedSize(int64(len("content of images/disk.img")) - 1)
+
+		fileName := filepath.Join(tmpDir, tempFile)
+		err = fr.StreamToFile(fileName)
+		Expect(errors.Is(err, ErrDecompressedTooLarge)).To(BeTrue())
+		_, err = os.Stat(fileName)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	// createSparseTar archives a sparse disk image of 16 MiB, with data at its start and in its middle,
+	// with GNU tar in the passed in format, and selects it as the entry to extract.
+	createSparseTar := func(args ...string) ([]byte, string) {
+		diskFile := filepath.Join(tmpDir, "disk.img")
+		disk, err := os.Create(diskFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(disk.Truncate(16 << 20)).To(Succeed())
+		_, err = disk.WriteAt(bytes.Repeat([]byte("a"), 4096), 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = disk.WriteAt(bytes.Repeat([]byte("b"), 4096), 8<<20)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(disk.Close()).To(Succeed())
+		content, err := os.ReadFile(diskFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		tarFile := filepath.Join(tmpDir, "disk.tar")
+		args = append([]string{"-S", "-cf", tarFile, "-C", tmpDir}, append(args, "disk.img")...)
+		out, err := exec.Command("tar", args...).CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+		Expect(os.Remove(diskFile)).To(Succeed())
+		os.Setenv(common.ImporterArchiveEntry, "disk.img")
+		return content, tarFile
+	}
+
+	table.DescribeTable("should skip the holes of a sparse tar entry", func(args ...string) {
+		expected, tarFile := createSparseTar(args...)
+		f, err := os.Open(tarFile)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fr.ArchiveTar).To(BeTrue())
+		Expect(fr.sparseEntry).NotTo(BeNil())
+
+		fileName := filepath.Join(tmpDir, tempFile)
+		Expect(fr.StreamToFile(fileName)).To(Succeed())
+		content, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(expected))
+		info, err := os.Stat(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Sys().(*syscall.Stat_t).Blocks * 512).To(BeNumerically("<", 1<<20))
+	},
+		table.Entry("in the old GNU format", "--format=gnu"),
+		table.Entry("in the PAX 0.1 format", "--format=pax", "--sparse-version=0.1"),
+		table.Entry("in the PAX 1.0 format", "--format=pax", "--sparse-version=1.0"),
+	)
+
+	It("should stream the holes of a sparse tar entry as zeros", func() {
+		expected, tarFile := createSparseTar("--format=pax", "--sparse-version=1.0")
+		f, err := os.Open(tarFile)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).NotTo(HaveOccurred())
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(expected))
+		Expect(fr.sparseEntry.logged).To(BeTrue())
+	})
+
+	It("should extract a qcow2 image from a 7z archive", func() {
+		tmpFile, err := utils.FormatTestData(cirrosFilePath, tmpDir, image.Ext7z)
+		Expect(err).NotTo(HaveOccurred())
+		f, err := os.Open(tmpFile)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fr.Archive7z).To(BeTrue())
+		Expect(fr.Convert).To(BeTrue())
+
+		fileName := filepath.Join(tmpDir, tempFile)
+		Expect(fr.StreamToFile(fileName)).To(Succeed())
+		expected, err := os.ReadFile(cirrosFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		content, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(expected))
+		_, err = os.Stat(fileName + image.Ext7z)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	// createChainArchive archives an overlay qcow2 image referencing the backing file backing, and
+	// the base image, in a zip or a tar archive.
+	createChainArchive := func(ext, backing string) string {
+		entries := []struct {
+			name string
+			data []byte
+		}{
+			{"images/base.qcow2", qcow2Header("")},
+			{"images/overlay.qcow2", qcow2Header(backing)},
+		}
+		archiveFile := filepath.Join(tmpDir, "chain"+ext)
+		f, err := os.Create(archiveFile)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		if ext == image.ExtZip {
+			w := zip.NewWriter(f)
+			for _, entry := range entries {
+				ew, err := w.Create(entry.name)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = ew.Write(entry.data)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(w.SetComment(strings.Repeat(" ", image.MaxExpectedHdrSize))).To(Succeed())
+			Expect(w.Close()).To(Succeed())
+			return archiveFile
+		}
+		w := tar.NewWriter(f)
+		for _, entry := range entries {
+			Expect(w.WriteHeader(&tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.data))})).To(Succeed())
+			_, err = w.Write(entry.data)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(w.Close()).To(Succeed())
+		return archiveFile
+	}
+
+	// streamChainArchive streams the overlay image of the archive to a file, flattening the backing
+	// chain if requested
+	streamChainArchive := func(archiveFile string, flattenChain bool) (string, error) {
+		os.Setenv(common.ImporterArchiveEntry, "images/overlay.qcow2")
+		os.Setenv(common.ImporterFlattenBackingChain, strconv.FormatBool(flattenChain))
+		f, err := os.Open(archiveFile)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fr.FlattenChain).To(Equal(flattenChain))
+		fileName := filepath.Join(tmpDir, "scratch", tempFile)
+		Expect(os.Mkdir(filepath.Dir(fileName), 0750)).To(Succeed())
+		return fileName, fr.StreamToFile(fileName)
+	}
+
+	table.DescribeTable("should extract the backing chain of a qcow2 entry next to it", func(ext string) {
+		fileName, err := streamChainArchive(createChainArchive(ext, "base.qcow2"), true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fr.Convert).To(BeTrue())
+		content, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(qcow2Header("base.qcow2")))
+		content, err = os.ReadFile(filepath.Join(filepath.Dir(fileName), "base.qcow2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(qcow2Header("")))
+	},
+		table.Entry("from a zip archive", image.ExtZip),
+		table.Entry("from a tar archive", image.ExtTar),
+	)
+
+	table.DescribeTable("should not extract the backing chain unless it is flattened", func(ext string) {
+		fileName, err := streamChainArchive(createChainArchive(ext, "base.qcow2"), false)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = os.Stat(filepath.Join(filepath.Dir(fileName), "base.qcow2"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	},
+		table.Entry("from a zip archive", image.ExtZip),
+		table.Entry("from a tar archive", image.ExtTar),
+	)
+
+	table.DescribeTable("should reject a backing file", func(backing, expectedErr string) {
+		_, err := streamChainArchive(createChainArchive(image.ExtZip, backing), true)
+		Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		Expect(errors.Is(err, image.ErrUnsupportedFormat)).To(BeTrue())
+		_, err = os.Stat(filepath.Join(tmpDir, "base.qcow2"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	},
+		table.Entry("outside of the scratch space", "../base.qcow2", "is outside of"),
+		table.Entry("with an absolute path", "/images/base.qcow2", "is not a relative file name"),
+		table.Entry("conflicting with the extracted image", tempFile, "conflicts with a file already extracted"),
+	)
+
+	It("should stream other formats to a file unchanged", func() {
+		f, err := os.Open(cirrosFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).NotTo(HaveOccurred())
+
+		fileName := filepath.Join(tmpDir, tempFile)
+		Expect(fr.StreamToFile(fileName)).To(Succeed())
+		expected, err := os.ReadFile(cirrosFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		content, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(expected))
+	})
+})
+
+// qcow2Header returns the header of a qcow2 image, followed by the name of its backing file if it has
+// one.
+func qcow2Header(backing string) []byte {
+	hdr := make([]byte, 512)
+	copy(hdr, []byte{'Q', 'F', 'I', 0xfb})
+	binary.BigEndian.PutUint32(hdr[4:], 3)
+	if backing != "" {
+		binary.BigEndian.PutUint64(hdr[8:], 112)
+		binary.BigEndian.PutUint32(hdr[16:], uint32(len(backing)))
+		copy(hdr[112:], backing)
+	}
+	return hdr
+}
+
+// sparseFile writes the blocks of zeros as holes, so that a large file of zeros takes no space.
+type sparseFile struct {
+	*os.File
+}
+
+func (f sparseFile) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b != 0 {
+			return f.File.Write(p)
+		}
+	}
+	if _, err := f.Seek(int64(len(p)), io.SeekCurrent); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
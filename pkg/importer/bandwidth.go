@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"context"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/monitoring"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+var bandwidthLimitGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: monitoring.MetricOptsList[monitoring.ImportBandwidthLimit].Name,
+		Help: monitoring.MetricOptsList[monitoring.ImportBandwidthLimit].Help,
+	},
+	[]string{"ownerUID"},
+)
+
+func init() {
+	if err := prometheus.Register(bandwidthLimitGauge); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			bandwidthLimitGauge = are.ExistingCollector.(*prometheus.GaugeVec)
+		} else {
+			klog.Errorf("Unable to create prometheus bandwidth limit gauge")
+		}
+	}
+}
+
+// bandwidthLimit returns the limit of the rate at which the data of the source is read, in bytes
+// per second, set with the environment, 0 if it is not limited.
+func bandwidthLimit() (int64, error) {
+	value, _ := util.ParseEnvVar(common.ImporterBandwidthLimit, false)
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := resource.ParseQuantity(value)
+	if err != nil || limit.Sign() <= 0 {
+		return 0, errors.Errorf("invalid %s value %q, a positive quantity of bytes per second is expected", common.ImporterBandwidthLimit, value)
+	}
+	return limit.Value(), nil
+}
+
+// limitBandwidth returns a reader of the data of the source reading it at most limit bytes per
+// second, and reports the limit.
+func limitBandwidth(ctx context.Context, source io.ReadCloser, limit int64) io.ReadCloser {
+	klog.Infof("Reading the source at most %d bytes per second", limit)
+	bandwidthLimitGauge.WithLabelValues(ownerUID).Set(float64(limit))
+	burst := limit
+	if burst > math.MaxInt32 {
+		burst = math.MaxInt32
+	}
+	return &rateLimitedReader{ctx: ctx, reader: source, limiter: rate.NewLimiter(rate.Limit(limit), int(burst))}
+}
+
+// rateLimitedReader reads its reader at the rate of a token bucket holding up to a second of data,
+// a read waits for the tokens of the bytes read, which slows down the reads of the network.
+type rateLimitedReader struct {
+	ctx     context.Context
+	reader  io.ReadCloser
+	limiter *rate.Limiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (r *rateLimitedReader) Close() error {
+	return r.reader.Close()
+}
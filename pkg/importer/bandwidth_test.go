@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+)
+
+var _ = Describe("Bandwidth limit", func() {
+	AfterEach(func() {
+		os.Unsetenv(common.ImporterBandwidthLimit)
+	})
+
+	table.DescribeTable("should parse the limit", func(value string, expected int64) {
+		os.Setenv(common.ImporterBandwidthLimit, value)
+		limit, err := bandwidthLimit()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(limit).To(Equal(expected))
+	},
+		table.Entry("not limited by default", "", int64(0)),
+		table.Entry("of a quantity of bytes", "100Mi", int64(100*1024*1024)),
+		table.Entry("of a number of bytes", "125000000", int64(125000000)),
+	)
+
+	table.DescribeTable("should reject the limit", func(value string) {
+		os.Setenv(common.ImporterBandwidthLimit, value)
+		_, err := bandwidthLimit()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(common.ImporterBandwidthLimit))
+	},
+		table.Entry("that is not a quantity", "fast"),
+		table.Entry("of zero", "0"),
+		table.Entry("that is negative", "-1Mi"),
+	)
+
+	It("should read the data at most at the limit", func() {
+		data := bytes.Repeat([]byte{0x5a}, 150*1024)
+		reader := limitBandwidth(context.Background(), io.NopCloser(bytes.NewReader(data)), 100*1024)
+		start := time.Now()
+		read, err := io.ReadAll(reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read).To(Equal(data))
+		// the first second of data is read at once, the rest at the limit
+		Expect(time.Since(start)).To(BeNumerically(">=", 400*time.Millisecond))
+		Expect(testutil.ToFloat64(bandwidthLimitGauge.WithLabelValues(ownerUID))).To(Equal(float64(100 * 1024)))
+	})
+
+	It("should stop reading once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		reader := limitBandwidth(ctx, io.NopCloser(bytes.NewReader(make([]byte, 4096))), 1024)
+		_, err := reader.Read(make([]byte, 4096))
+		Expect(err).NotTo(HaveOccurred())
+		cancel()
+		_, err = reader.Read(make([]byte, 4096))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should stream an http source rather than let nbdkit read it", func() {
+		createNbdkitCurl = image.NewMockNbdkitCurl
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(cirrosData))
+		}))
+		defer server.Close()
+		dp, err := NewHTTPDataSource(server.URL+"/cirros.qcow2", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		phase, err := dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseConvert))
+		dp.Close()
+
+		os.Setenv(common.ImporterBandwidthLimit, "1Gi")
+		dp, err = NewHTTPDataSource(server.URL+"/cirros.qcow2", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		phase, err = dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferScratch))
+		Expect(dp.readers.bandwidthLimit).To(Equal(int64(1024 * 1024 * 1024)))
+	})
+})
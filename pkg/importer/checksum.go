@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// ErrChecksumMismatch is the error of the data of a source that does not match its checksum, retrying the import
+// cannot succeed.
+var ErrChecksumMismatch = fmt.Errorf("checksum mismatch")
+
+// sourceChecksum returns the checksum the data of the source is verified against, set with the
+// environment, nil if there is none.
+func sourceChecksum() (*util.Checksum, error) {
+	value, _ := util.ParseEnvVar(common.ImporterChecksum, false)
+	if value == "" {
+		return nil, nil
+	}
+	checksum, err := util.ParseChecksum(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s value", common.ImporterChecksum)
+	}
+	if checksum.Weak() {
+		klog.Warningf("The %s checksum of the source is weak, sha256 or sha512 is recommended", checksum.Algorithm)
+	}
+	return checksum, nil
+}
+
+// newSourceDigestReader returns a reader computing the digest of the data read from the source, and
+// its digest of the algorithm of checksum, if any.
+func newSourceDigestReader(r io.ReadCloser, checksum *util.Checksum) *util.DigestReader {
+	hashes := map[string]hash.Hash{digestAlgorithm: sha256.New()}
+	if checksum != nil && checksum.Algorithm != digestAlgorithm {
+		hashes[checksum.Algorithm] = checksum.NewHash()
+	}
+	return util.NewDigestReader(r, hashes)
+}
+
+// verifySource verifies the data of the source against its checksum and its signature, if any. The
+// rest of the source, the padding following a tar entry for instance, is read first, the checksum
+// and the signature are the ones of the whole data.
+func (fr *FormatReaders) verifySource() error {
+	if fr.checksum == nil && fr.signature == nil {
+		return nil
+	}
+	if _, err := io.Copy(io.Discard, fr.sourceDigest); err != nil {
+		return errors.Wrap(err, "could not read the rest of the source to verify it")
+	}
+	if err := fr.verifyChecksum(); err != nil {
+		return err
+	}
+	return fr.verifySignature()
+}
+
+// verifyChecksum fails with ErrChecksumMismatch if the data of the source, read to its end, does not
+// match its checksum.
+func (fr *FormatReaders) verifyChecksum() error {
+	if fr.checksum == nil {
+		return nil
+	}
+	digest := fr.sourceDigest.Digests()[fr.checksum.Algorithm]
+	if digest != fr.checksum.String() {
+		return errors.Wrapf(ErrChecksumMismatch, "the data of the source is %s, %s expected", digest, fr.checksum)
+	}
+	klog.V(1).Infof("The data of the source matches its %s checksum", fr.checksum.Algorithm)
+	return nil
+}
+
+// verifyFileChecksum fails with ErrChecksumMismatch if the file does not match the checksum.
+func verifyFileChecksum(path string, checksum *util.Checksum) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not open %s to verify its checksum", path)
+	}
+	defer f.Close()
+	h := checksum.NewHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return errors.Wrapf(err, "could not read %s to verify its checksum", path)
+	}
+	if digest := fmt.Sprintf("%s:%x", checksum.Algorithm, h.Sum(nil)); digest != checksum.String() {
+		return errors.Wrapf(ErrChecksumMismatch, "%s is %s, %s expected", filepath.Base(path), digest, checksum)
+	}
+	klog.V(1).Infof("%s matches its %s checksum", filepath.Base(path), checksum.Algorithm)
+	return nil
+}
+
+// mustStream returns true if the data of the source has to be read from the readers, rather than by
+// nbdkit or qemu-img, to limit its bandwidth or to verify its checksum or its signature.
+func (fr *FormatReaders) mustStream() bool {
+	return fr.bandwidthLimit > 0 || fr.checksum != nil || fr.signature != nil
+}
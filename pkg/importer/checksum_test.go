@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+)
+
+var _ = Describe("Source checksum", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "checksum")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(common.ImporterChecksum)
+		os.RemoveAll(tmpDir)
+	})
+
+	// streamToFile streams data to a file of the temporary directory, verified against checksum
+	streamToFile := func(data []byte, checksum string) error {
+		os.Setenv(common.ImporterChecksum, checksum)
+		fr, err := NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(0))
+		Expect(err).NotTo(HaveOccurred())
+		defer fr.Close()
+		return fr.StreamToFile(filepath.Join(tmpDir, "disk.img"))
+	}
+
+	table.DescribeTable("should accept the data matching its checksum", func(checksum func([]byte) string) {
+		Expect(streamToFile(cirrosData, checksum(cirrosData))).To(Succeed())
+		written, err := os.ReadFile(filepath.Join(tmpDir, "disk.img"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes.Equal(written, cirrosData)).To(BeTrue())
+	},
+		table.Entry("of sha256", func(data []byte) string { return fmt.Sprintf("sha256:%x", sha256.Sum256(data)) }),
+		table.Entry("of sha512", func(data []byte) string { return fmt.Sprintf("sha512:%x", sha512.Sum512(data)) }),
+		table.Entry("of md5", func(data []byte) string { return fmt.Sprintf("md5:%x", md5.Sum(data)) }),
+		table.Entry("in upper case", func(data []byte) string { return fmt.Sprintf("SHA256:%X", sha256.Sum256(data)) }),
+	)
+
+	It("should fail when the data does not match its checksum", func() {
+		err := streamToFile(cirrosData, fmt.Sprintf("sha512:%x", sha512.Sum512([]byte("other data"))))
+		Expect(errors.Is(err, ErrChecksumMismatch)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("sha512:%x", sha512.Sum512(cirrosData))))
+	})
+
+	It("should verify the checksum of compressed data before it is decompressed", func() {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		_, err := w.Write(cirrosData)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		Expect(streamToFile(compressed.Bytes(), fmt.Sprintf("sha256:%x", sha256.Sum256(compressed.Bytes())))).To(Succeed())
+		Expect(os.Remove(filepath.Join(tmpDir, "disk.img"))).To(Succeed())
+		err = streamToFile(compressed.Bytes(), fmt.Sprintf("sha256:%x", sha256.Sum256(cirrosData)))
+		Expect(errors.Is(err, ErrChecksumMismatch)).To(BeTrue())
+	})
+
+	It("should reject an invalid checksum", func() {
+		os.Setenv(common.ImporterChecksum, "crc32:0badf00d")
+		_, err := NewFormatReaders(io.NopCloser(bytes.NewReader(cirrosData)), uint64(0))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(common.ImporterChecksum))
+	})
+
+	It("should stream an http source rather than let nbdkit read it", func() {
+		createNbdkitCurl = image.NewMockNbdkitCurl
+		server := newResumeServer(cirrosData)
+		defer server.Close()
+		os.Setenv(common.ImporterChecksum, fmt.Sprintf("sha256:%x", sha256.Sum256(cirrosData)))
+		dp, err := NewHTTPDataSource(server.URL+"/cirros.qcow2", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		phase, err := dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferScratch))
+		_, err = dp.Transfer(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should remove the target file written with data that does not match its checksum", func() {
+		data, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		server := newResumeServer(data)
+		defer server.Close()
+		os.Setenv(common.ImporterChecksum, fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("other data"))))
+		source, err := NewHTTPDataSource(server.URL+"/tinyCore.iso", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer source.Close()
+		dataFile := filepath.Join(tmpDir, "disk.img")
+		dp := NewDataProcessor(source, dataFile, tmpDir, "scratchDataDir", "1G", 0.055, false)
+		err = dp.ProcessData()
+		Expect(errors.Is(err, ErrChecksumMismatch)).To(BeTrue())
+		Expect(dataFile).NotTo(BeAnExistingFile())
+	})
+})
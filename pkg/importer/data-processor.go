@@ -17,22 +17,65 @@ limitations under the License.
 package importer
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/pkg/monitoring"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
 var qemuOperations = image.NewQEMUOperations()
 
+// milestones is set to 1 for each milestone reached by the import, the controller relays it as an event
+var milestones = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: monitoring.MetricOptsList[monitoring.ImportMilestone].Name,
+		Help: monitoring.MetricOptsList[monitoring.ImportMilestone].Help,
+	},
+	[]string{"ownerUID", "milestone"},
+)
+
+func init() {
+	if err := prometheus.Register(milestones); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			milestones = are.ExistingCollector.(*prometheus.GaugeVec)
+		} else {
+			klog.Errorf("Unable to create prometheus milestones gauge")
+		}
+	}
+}
+
+// reachedMilestones holds the milestones reached by the import, in order
+var reachedMilestones []string
+
+// reachMilestone reports the milestone reached by the import.
+func reachMilestone(milestone string) {
+	milestones.WithLabelValues(ownerUID, milestone).Set(1)
+	for _, reached := range reachedMilestones {
+		if reached == milestone {
+			return
+		}
+	}
+	reachedMilestones = append(reachedMilestones, milestone)
+}
+
+// ReachedMilestones returns the milestones reached by the import, reported in the termination message since the
+// controller may not scrape the metrics of the importer before it exits.
+func ReachedMilestones() []string {
+	return reachedMilestones
+}
+
 // ProcessingPhase is the current phase being processed.
 type ProcessingPhase string
 
@@ -69,12 +112,68 @@ type ValidationSizeError struct {
 
 func (e ValidationSizeError) Error() string { return e.err.Error() }
 
+// Unwrap returns the cause of the validation failure.
+func (e ValidationSizeError) Unwrap() error { return e.err }
+
+// scratchTransferProgress is the share of the progress, in percent, of the transfer of data to scratch
+// space, the conversion of the data reports the rest of it.
+const scratchTransferProgress = 50.0
+
 // ErrRequiresScratchSpace indicates that we require scratch space.
 var ErrRequiresScratchSpace = fmt.Errorf("scratch space required and none found")
 
+// maxDecompressedSizeSetter is implemented by the data sources that may decompress their data.
+type maxDecompressedSizeSetter interface {
+	// SetMaxDecompressedSize limits the size of the decompressed data, once the source is configured
+	// by Info.
+	SetMaxDecompressedSize(max int64)
+}
+
+// progressMaxSetter is implemented by the data sources reporting the progress of their transfer.
+type progressMaxSetter interface {
+	// SetProgressMax sets the progress, in percent, reported once the data is transferred, once the
+	// source is configured by Info.
+	SetProgressMax(max float64)
+}
+
+// sourceSizeValidator is implemented by the data sources knowing the size of their data before it is
+// transferred.
+type sourceSizeValidator interface {
+	// ValidateSourceSize fails if the data, once the source is configured by Info, cannot fit in max
+	// bytes.
+	ValidateSourceSize(max int64) error
+}
+
+// convertFormatter is implemented by the data sources telling the format of the data to convert,
+// when qemu-img cannot probe it.
+type convertFormatter interface {
+	// ConvertFormat returns the format of the data, once the source is configured by Info. It is
+	// probed by qemu-img when empty.
+	ConvertFormat() string
+}
+
+// chainFlattener is implemented by the data sources extracting the backing chain of a qcow2 image
+// from an archive, next to the image.
+type chainFlattener interface {
+	// FlattenChain returns true if the backing chain of the image was requested to be flattened, the
+	// backing files are then extracted along with the image.
+	FlattenChain() bool
+}
+
+// contextSetter is implemented by the data sources that can stop reading their data once a context
+// is done.
+type contextSetter interface {
+	// SetContext sets the context of the transfer, before Info is called.
+	SetContext(ctx context.Context)
+}
+
 // ErrInvalidPath indicates that the path is invalid.
 var ErrInvalidPath = fmt.Errorf("invalid transfer path")
 
+// ErrSourceNotFound is the error of a source whose data does not exist, such as an http URL answered with 404 Not
+// Found or a missing S3 object, retrying the import cannot succeed.
+var ErrSourceNotFound = fmt.Errorf("source not found")
+
 // may be overridden in tests
 var getAvailableSpaceBlockFunc = util.GetAvailableSpaceBlock
 var getAvailableSpaceFunc = util.GetAvailableSpace
@@ -93,6 +192,45 @@ type DataSourceInterface interface {
 	Close() error
 }
 
+// DigestDataSource is implemented by the data sources computing the digests of their data while it
+// is transferred, rather than by reading the target once written.
+type DigestDataSource interface {
+	// Digests returns the digests of the data of the source and of the data transferred.
+	Digests() Digests
+	// PayloadDigest returns the digest of the data transferred followed by zeros up to size bytes.
+	PayloadDigest(size int64) string
+}
+
+// SourceFormatDataSource is implemented by the data sources detecting the format of the disk image
+// read from their source, it is recorded on the PVC.
+type SourceFormatDataSource interface {
+	// SourceFormat returns the format of the disk image of the source, iso for an ISO9660 image,
+	// empty when it is not recorded.
+	SourceFormat() string
+}
+
+// SourceURLDataSource is implemented by the data sources reading their data from one of several
+// URLs, the one which served the data is recorded on the PVC.
+type SourceURLDataSource interface {
+	// SourceURL returns the URL which served the data, empty when it is not recorded.
+	SourceURL() string
+}
+
+// SignedDataSource is implemented by the data sources verifying their data against a detached
+// signature, the signer is recorded on the PVC.
+type SignedDataSource interface {
+	// Signer returns the fingerprint of the primary key of the signer, empty when the data is not
+	// verified against a signature.
+	Signer() string
+}
+
+// ValidatedDataSource is implemented by the data sources whose data is identified by validators,
+// recorded on the PVC so that a later import of the same source is skipped when it did not change.
+type ValidatedDataSource interface {
+	// SourceValidators returns the validators of the data of the source, empty when it has none.
+	SourceValidators() SourceValidators
+}
+
 //ResumableDataSource is the interface all resumeable data sources should implement
 type ResumableDataSource interface {
 	DataSourceInterface
@@ -113,16 +251,43 @@ type DataProcessor struct {
 	scratchDataDir string
 	// requestImageSize is the size we want the resulting image to be.
 	requestImageSize string
+	// preserveImageSize keeps the virtual size of the image instead of expanding it to requestImageSize
+	preserveImageSize bool
+	// skipImageCheck skips the qemu-img check of the image once converted
+	skipImageCheck bool
 	// available space is the available space before downloading the image
 	availableSpace int64
+	// volumeSpace is the space of the target volume, the filesystem overhead included
+	volumeSpace int64
 	// storage overhead is the amount of overhead of the storage used
 	filesystemOverhead float64
+	// ctx is the context of the processing, no phase is started once it is done
+	ctx context.Context
 	// needsDataCleanup decides if the contents of the data directory should be deleted (need to avoid this during delta copy stages in a warm migration)
 	needsDataCleanup bool
 	// preallocation is the flag controlling preallocation setting of qemu-img
 	preallocation bool
-	// preallocationApplied is used to pass information whether preallocation has been performed, or not
-	preallocationApplied bool
+	// preallocationMode is the preallocation mode requested when preallocation is set, falloc if empty
+	preallocationMode image.PreallocationMode
+	// preallocationApplied is the preallocation mode performed, empty if the data file was not preallocated
+	preallocationApplied image.PreallocationMode
+	// logicalBytes is the size of the data file written to a filesystem volume, physicalBytes the space allocated to it
+	logicalBytes, physicalBytes int64
+	// encryptionKeyFile is the file of the passphrase decrypting the image while it is converted, if any
+	encryptionKeyFile string
+	// diskFormat is the format of the disk image written to the data file, raw or qcow2
+	diskFormat string
+	// qcow2Options are the options of a qcow2 disk image
+	qcow2Options image.Qcow2Options
+	// qcow2OptionsApplied are the cluster size and the compat level of the qcow2 disk image written, as reported by qemu-img,
+	// and the compression of its clusters
+	qcow2OptionsApplied image.Qcow2Options
+	// streamed is set when the source wrote its data to the data file as it is, without qemu-img
+	streamed bool
+	// convertedURL is the url of the image converted by qemu-img to the data file, if any
+	convertedURL *url.URL
+	// destinationDigest is the digest of the disk image written to the data file, empty if unknown
+	destinationDigest string
 	// phaseExecutors is a mapping from the given processing phase to its execution function. The function returns the next processing phase or error.
 	phaseExecutors map[ProcessingPhase]func() (ProcessingPhase, error)
 }
@@ -148,6 +313,8 @@ func NewDataProcessor(dataSource DataSourceInterface, dataFile, dataDir, scratch
 		filesystemOverhead: filesystemOverhead,
 		needsDataCleanup:   needsDataCleanup,
 		preallocation:      preallocation,
+		diskFormat:         image.QemuFormatRaw,
+		ctx:                context.Background(),
 	}
 	// Calculate available space before doing anything.
 	dp.availableSpace = dp.calculateTargetSize()
@@ -155,6 +322,54 @@ func NewDataProcessor(dataSource DataSourceInterface, dataFile, dataDir, scratch
 	return dp
 }
 
+// SetEncryptionKeyFile sets the file of the passphrase of a LUKS-encrypted qcow2 image, read by
+// qemu-img to decrypt the image while converting it.
+func (dp *DataProcessor) SetEncryptionKeyFile(keyFile string) {
+	dp.encryptionKeyFile = keyFile
+}
+
+// SetPreserveImageSize keeps the virtual size of the imported image when preserve is set, instead of
+// expanding it to the requested size once converted.
+func (dp *DataProcessor) SetPreserveImageSize(preserve bool) {
+	dp.preserveImageSize = preserve
+}
+
+// SetSkipImageCheck skips the consistency check of the image by qemu-img once it is converted, an
+// extra pass over the data of a huge image.
+func (dp *DataProcessor) SetSkipImageCheck(skip bool) {
+	dp.skipImageCheck = skip
+}
+
+// SetPreallocationMode sets the preallocation mode applied to the raw disk image when preallocation
+// is requested, falloc or full.
+func (dp *DataProcessor) SetPreallocationMode(mode image.PreallocationMode) {
+	dp.preallocationMode = mode
+}
+
+// preallocationRequested returns the preallocation mode requested for the data file.
+func (dp *DataProcessor) preallocationRequested() image.PreallocationMode {
+	if !dp.preallocation {
+		return image.PreallocationNone
+	}
+	if dp.preallocationMode == image.PreallocationNone {
+		return image.PreallocationFalloc
+	}
+	return dp.preallocationMode
+}
+
+// SetDiskFormat sets the format of the disk image written to the data file, raw or qcow2. A qcow2
+// disk image is written with the options passed in, and it is never preallocated.
+func (dp *DataProcessor) SetDiskFormat(format string, options image.Qcow2Options) {
+	dp.diskFormat = format
+	if format == image.QemuFormatQcow2 {
+		if dp.preallocation {
+			klog.V(1).Infoln("Not preallocating the qcow2 disk image")
+		}
+		dp.preallocation = false
+		dp.qcow2Options = options
+	}
+}
+
 // RegisterPhaseExecutor registers an execution function for the given phase.
 // If there is already an function registered, override it with the new function.
 func (dp *DataProcessor) RegisterPhaseExecutor(pp ProcessingPhase, executor func() (ProcessingPhase, error)) {
@@ -166,13 +381,28 @@ func (dp *DataProcessor) RegisterPhaseExecutor(pp ProcessingPhase, executor func
 
 // ProcessData is the main synchronous processing loop
 func (dp *DataProcessor) ProcessData() error {
+	return dp.ProcessDataContext(context.Background())
+}
+
+// ProcessDataContext is the main synchronous processing loop, it stops once ctx is done and returns
+// the error of the context.
+func (dp *DataProcessor) ProcessDataContext(ctx context.Context) (err error) {
+	dp.ctx = ctx
 	if size, _ := util.GetAvailableSpace(dp.scratchDataDir); size > int64(0) {
-		// Clean up before trying to write, in case a previous attempt left a mess. Note the deferred cleanup is intentional.
-		if err := CleanDir(dp.scratchDataDir); err != nil {
+		// Clean up before trying to write, in case a previous attempt left a mess. The data it
+		// downloaded is kept, its download is resumed. Note the deferred cleanup is intentional.
+		if err := cleanScratchSpace(dp.scratchDataDir); err != nil {
 			return errors.Wrap(err, "Failure cleaning up temporary scratch space")
 		}
-		// Attempt to be a good citizen and clean up my mess at the end.
-		defer CleanDir(dp.scratchDataDir)
+		// Attempt to be a good citizen and clean up my mess at the end, but the data downloaded
+		// by a failed attempt, unless it does not match the checksum or the signature of the source.
+		defer func() {
+			if err != nil && !errors.Is(err, ErrChecksumMismatch) && !errors.Is(err, ErrInvalidSignature) {
+				cleanScratchSpace(dp.scratchDataDir)
+			} else {
+				CleanDir(dp.scratchDataDir)
+			}
+		}()
 	}
 
 	if size, _ := util.GetAvailableSpace(dp.dataDir); size > int64(0) && dp.needsDataCleanup {
@@ -198,13 +428,37 @@ func (dp *DataProcessor) ProcessDataResume() error {
 func (dp *DataProcessor) initDefaultPhases() {
 	dp.phaseExecutors = make(map[ProcessingPhase]func() (ProcessingPhase, error))
 	dp.RegisterPhaseExecutor(ProcessingPhaseInfo, func() (ProcessingPhase, error) {
+		if s, ok := dp.source.(contextSetter); ok {
+			s.SetContext(dp.ctx)
+		}
 		pp, err := dp.source.Info()
 		if err != nil {
-			err = errors.Wrap(err, "Unable to obtain information about data source")
+			return pp, errors.Wrap(err, "Unable to obtain information about data source")
 		}
-		return pp, err
+		reachMilestone(common.ImportMilestoneSourceConnected)
+		if s, ok := dp.source.(maxDecompressedSizeSetter); ok {
+			// decompressed data larger than the target volume cannot be imported
+			s.SetMaxDecompressedSize(dp.volumeSpace)
+		}
+		if s, ok := dp.source.(sourceSizeValidator); ok {
+			// neither can a larger raw source, fail before transferring it
+			if err := s.ValidateSourceSize(dp.volumeSpace); err != nil {
+				return ProcessingPhaseError, err
+			}
+		}
+		if pp == ProcessingPhaseTransferDataFile && dp.diskFormat == image.QemuFormatQcow2 {
+			// raw data is converted to qcow2 by qemu-img, from scratch space
+			pp = ProcessingPhaseTransferScratch
+		}
+		return pp, nil
 	})
 	dp.RegisterPhaseExecutor(ProcessingPhaseTransferScratch, func() (ProcessingPhase, error) {
+		if s, ok := dp.source.(progressMaxSetter); ok {
+			// the data copied to scratch space is converted next, qemu-img reports the rest of the
+			// progress
+			s.SetProgressMax(scratchTransferProgress)
+			image.SetConvertProgressStart(scratchTransferProgress)
+		}
 		pp, err := dp.source.Transfer(dp.scratchDataDir)
 		if err == ErrInvalidPath {
 			// Passed in invalid scratch space path, return scratch space needed error.
@@ -216,6 +470,10 @@ func (dp *DataProcessor) initDefaultPhases() {
 	})
 	dp.RegisterPhaseExecutor(ProcessingPhaseTransferDataDir, func() (ProcessingPhase, error) {
 		pp, err := dp.source.Transfer(dp.dataDir)
+		if errors.Is(err, ErrChecksumMismatch) || errors.Is(err, ErrInvalidSignature) {
+			// the files extracted from the source are not kept
+			CleanDir(dp.dataDir)
+		}
 		if err != nil {
 			err = errors.Wrap(err, "Unable to transfer source data to target directory")
 		}
@@ -223,14 +481,18 @@ func (dp *DataProcessor) initDefaultPhases() {
 	})
 	dp.RegisterPhaseExecutor(ProcessingPhaseTransferDataFile, func() (ProcessingPhase, error) {
 		pp, err := dp.source.TransferFile(dp.dataFile)
+		if errors.Is(err, ErrChecksumMismatch) || errors.Is(err, ErrInvalidSignature) {
+			dp.removeDataFile()
+		}
 		if err != nil {
-			err = errors.Wrap(err, "Unable to transfer source data to target file")
+			return pp, errors.Wrap(err, "Unable to transfer source data to target file")
 		}
-		return pp, err
+		dp.streamed = true
+		return pp, nil
 	})
 	dp.RegisterPhaseExecutor(ProcessingPhaseValidatePause, func() (ProcessingPhase, error) {
 		pp := ProcessingPhasePause
-		err := dp.validate(dp.source.GetURL())
+		err := dp.validate(dp.source.GetURL(), dp.convertFormat(), dp.encryptionKeyFile)
 		if err != nil {
 			pp = ProcessingPhaseError
 		}
@@ -268,6 +530,10 @@ func (dp *DataProcessor) ProcessDataWithPause() error {
 			klog.Errorf("%+v", err)
 			return err
 		}
+		if err := dp.ctx.Err(); err != nil {
+			klog.Errorf("Processing stopped before phase %s: %v", dp.currentPhase, err)
+			return err
+		}
 		executor, ok := dp.phaseExecutors[dp.currentPhase]
 		if !ok {
 			return errors.Errorf("Unknown processing phase %s", dp.currentPhase)
@@ -284,53 +550,120 @@ func (dp *DataProcessor) ProcessDataWithPause() error {
 	return nil
 }
 
-func (dp *DataProcessor) validate(url *url.URL) error {
+func (dp *DataProcessor) validate(url *url.URL, format, keyFile string) error {
 	klog.V(1).Infoln("Validating image")
-	err := qemuOperations.Validate(url, dp.availableSpace)
+	err := qemuOperations.Validate(url, format, dp.availableSpace, dp.flattenChain(), keyFile)
 	if err != nil {
 		return ValidationSizeError{err: err}
 	}
 	return nil
 }
 
-// convert is called when convert the image from the url to a RAW disk image. Source formats include RAW/QCOW2 (Raw to raw conversion is a copy)
+// convert is called when convert the image from the url to a RAW disk image, or to a QCOW2 one when requested. Source formats include RAW/QCOW2 (Raw to raw conversion is a copy)
 func (dp *DataProcessor) convert(url *url.URL) (ProcessingPhase, error) {
-	err := dp.validate(url)
+	err := dp.validate(url, dp.convertFormat(), dp.encryptionKeyFile)
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
+	reachMilestone(common.ImportMilestoneConversionStarted)
+	if dp.diskFormat == image.QemuFormatQcow2 {
+		klog.V(3).Infoln("Converting to Qcow2")
+		err = qemuOperations.ConvertToQcow2Stream(url, dp.convertFormat(), dp.dataFile, dp.qcow2Options, dp.encryptionKeyFile)
+		if err != nil {
+			return ProcessingPhaseError, errors.Wrap(err, "Conversion to Qcow2 failed")
+		}
+		dp.convertedURL = url
+		dp.readQcow2Options()
+		return dp.check(url)
+	}
 	klog.V(3).Infoln("Converting to Raw")
-	err = qemuOperations.ConvertToRawStream(url, dp.dataFile, dp.preallocation)
+	dp.preallocationApplied, err = qemuOperations.ConvertToRawStream(url, dp.convertFormat(), dp.dataFile, dp.preallocationRequested(), dp.encryptionKeyFile)
 	if err != nil {
 		return ProcessingPhaseError, errors.Wrap(err, "Conversion to Raw failed")
 	}
-	dp.preallocationApplied = dp.preallocation
+	dp.convertedURL = url
+
+	return dp.check(url)
+}
+
+// readQcow2Options reads the cluster size and the compat level of the qcow2 disk image written to
+// the data file, they are only reported along with the compression of its clusters.
+func (dp *DataProcessor) readQcow2Options() {
+	var applied image.Qcow2Options
+	if info, err := qemuOperations.Info(&url.URL{Path: dp.dataFile}); err != nil {
+		klog.Warningf("Unable to read the options of the qcow2 disk image: %v", err)
+	} else {
+		applied = image.AppliedQcow2Options(info)
+	}
+	// qemu-img info does not report compressed clusters, they are written as requested
+	applied.Compress = dp.qcow2Options.Compress
+	dp.qcow2OptionsApplied = applied
+}
 
+// check checks the consistency of the converted image with qemu-img, unless it is skipped. An image
+// with corruptions or leaked clusters fails the import.
+func (dp *DataProcessor) check(url *url.URL) (ProcessingPhase, error) {
+	if dp.skipImageCheck {
+		klog.V(1).Infoln("Skipping the check of the image")
+		return ProcessingPhaseResize, nil
+	}
+	klog.V(3).Infoln("Checking the image")
+	if err := qemuOperations.Check(url, dp.convertFormat(), dp.encryptionKeyFile); err != nil {
+		return ProcessingPhaseError, errors.Wrap(err, "Check of image failed")
+	}
+	reachMilestone(common.ImportMilestoneVerificationPassed)
 	return ProcessingPhaseResize, nil
 }
 
+// convertFormat returns the format of the data to convert known by the source, if any.
+func (dp *DataProcessor) convertFormat() string {
+	if s, ok := dp.source.(convertFormatter); ok {
+		return s.ConvertFormat()
+	}
+	return ""
+}
+
+func (dp *DataProcessor) flattenChain() bool {
+	if s, ok := dp.source.(chainFlattener); ok {
+		return s.FlattenChain()
+	}
+	return false
+}
+
 func (dp *DataProcessor) resize() (ProcessingPhase, error) {
 	size, _ := getAvailableSpaceBlockFunc(dp.dataFile)
 	klog.V(3).Infof("Available space in dataFile: %d", size)
 	isBlockDev := size >= int64(0)
 	if !isBlockDev {
-		if dp.requestImageSize != "" {
+		if dp.preserveImageSize {
+			klog.V(1).Infoln("Preserving the virtual size of the image")
+		} else if dp.requestImageSize != "" {
 			klog.V(3).Infoln("Resizing image")
-			err := ResizeImage(dp.dataFile, dp.requestImageSize, dp.getUsableSpace(), dp.preallocation)
+			// the space added to a converted image is preallocated as the conversion did
+			applied, err := ResizeImage(dp.dataFile, dp.diskFormat, dp.requestImageSize, dp.getUsableSpace(), dp.preallocationApplied)
 			if err != nil {
 				return ProcessingPhaseError, errors.Wrap(err, "Resize of image failed")
 			}
+			dp.preallocationApplied = applied
+		}
+		if dp.preallocationApplied == image.PreallocationNone && dp.preallocationRequested() != image.PreallocationNone {
+			// a raw image copied as it is
+			applied, err := image.PreallocateFile(dp.dataFile, dp.preallocationRequested())
+			if err != nil {
+				return ProcessingPhaseError, errors.Wrap(err, "Preallocation of image failed")
+			}
+			dp.preallocationApplied = applied
 		}
 		// Validate that a sparse file will fit even as it fills out.
 		dataFileURL, err := url.Parse(dp.dataFile)
 		if err != nil {
 			return ProcessingPhaseError, err
 		}
-		err = dp.validate(dataFileURL)
+		// The data file is already converted, and decrypted
+		err = dp.validate(dataFileURL, dp.diskFormat, "")
 		if err != nil {
 			return ProcessingPhaseError, err
 		}
-		dp.preallocationApplied = dp.preallocation
 	}
 	if dp.dataFile != "" && !isBlockDev {
 		// Change permissions to 0660
@@ -338,19 +671,86 @@ func (dp *DataProcessor) resize() (ProcessingPhase, error) {
 		if err != nil {
 			return ProcessingPhaseError, errors.Wrap(err, "Unable to change permissions of target file")
 		}
+		dp.logicalBytes, dp.physicalBytes, err = util.GetFileAllocation(dp.dataFile)
+		if err != nil {
+			return ProcessingPhaseError, err
+		}
+		klog.V(1).Infof("Wrote %d bytes of the %d bytes of the data file\n", dp.physicalBytes, dp.logicalBytes)
+	}
+	if dp.dataFile != "" {
+		dp.destinationDigest = dp.computeDestinationDigest(isBlockDev)
+		if dp.destinationDigest != "" {
+			klog.Infof("Digest of the disk image written: %s", dp.destinationDigest)
+		}
 	}
 
 	return ProcessingPhaseComplete, nil
 }
 
-// ResizeImage resizes the images to match the requested size. Sometimes provisioners misbehave and the available space
+// computeDestinationDigest returns the digest of the disk image written to the data file, empty when
+// it cannot be computed. The digest of the data streamed by the source is computed as it is written,
+// extended with the zeros added to the file when it is resized. An image converted by qemu-img is
+// read once more to be hashed, up to its virtual size on a block device, larger than the image.
+func (dp *DataProcessor) computeDestinationDigest(isBlockDev bool) string {
+	if s, ok := dp.source.(DigestDataSource); ok && dp.streamed {
+		size := dp.logicalBytes
+		if isBlockDev {
+			// exactly the data written to the device
+			size = 0
+		}
+		if digest := s.PayloadDigest(size); digest != "" {
+			return digest
+		}
+	}
+	size := int64(-1)
+	if isBlockDev {
+		if dp.convertedURL == nil || dp.diskFormat != image.QemuFormatRaw {
+			klog.V(1).Infoln("Unknown size of the disk image written to the block device, not computing its digest")
+			return ""
+		}
+		info, err := qemuOperations.Info(dp.convertedURL)
+		if err != nil {
+			klog.Warningf("Unable to read the virtual size of the disk image: %v", err)
+			return ""
+		}
+		size = info.VirtualSize
+	}
+	digest, err := readFileDigest(dp.dataFile, size)
+	if err != nil {
+		klog.Warningf("Unable to compute the digest of the disk image: %v", err)
+		return ""
+	}
+	return digest
+}
+
+// readFileDigest reads the file at path to compute its digest, only its first size bytes unless size is
+// negative.
+func readFileDigest(path string, size int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if size >= 0 {
+		r = io.LimitReader(f, size)
+	}
+	digestReader := newDigestReader(io.NopCloser(r))
+	if _, err := io.Copy(io.Discard, digestReader); err != nil {
+		return "", err
+	}
+	return digestReader.Digests()[digestAlgorithm], nil
+}
+
+// ResizeImage resizes the image of the format passed in to match the requested size. Sometimes provisioners misbehave and the available space
 // is not the same as the requested space. For those situations we compare the available space to the requested space and
-// use the smallest of the two values.
-func ResizeImage(dataFile, imageSize string, totalTargetSpace int64, preallocation bool) error {
+// use the smallest of the two values. The space added is preallocated with the preallocation mode passed in, and the
+// mode applied is returned, the one passed in when the image is not resized.
+func ResizeImage(dataFile, format, imageSize string, totalTargetSpace int64, preallocation image.PreallocationMode) (image.PreallocationMode, error) {
 	dataFileURL, _ := url.Parse(dataFile)
 	info, err := qemuOperations.Info(dataFileURL)
 	if err != nil {
-		return err
+		return image.PreallocationNone, err
 	}
 	if imageSize != "" {
 		currentImageSizeQuantity := resource.NewScaledQuantity(info.VirtualSize, 0)
@@ -362,17 +762,27 @@ func ResizeImage(dataFile, imageSize string, totalTargetSpace int64, preallocati
 		}
 		if currentImageSizeQuantity.Cmp(minSizeQuantity) == 0 {
 			klog.V(1).Infof("No need to resize image. Requested size: %s, Image size: %d.\n", imageSize, info.VirtualSize)
-			return nil
+			return preallocation, nil
 		}
 		// Check if calculated size is < imageSize, and return error if so.
 		if currentImageSizeQuantity.Cmp(minSizeQuantity) == 1 {
 			klog.V(1).Infof("Calculated new size is < than current size, not resizing: requested size %s, virtual size: %d.\n", minSizeQuantity.String(), info.VirtualSize)
-			return nil
+			return preallocation, nil
 		}
 		klog.V(1).Infof("Expanding image size to: %s\n", minSizeQuantity.String())
-		return qemuOperations.Resize(dataFile, minSizeQuantity, preallocation)
+		return qemuOperations.Resize(dataFile, format, minSizeQuantity, preallocation)
+	}
+	return image.PreallocationNone, errors.New("Image resize called with blank resize")
+}
+
+// removeDataFile removes the target file written with data of the source which is not kept, a block
+// device is left as it is.
+func (dp *DataProcessor) removeDataFile() {
+	if info, err := os.Stat(dp.dataFile); err == nil && info.Mode().IsRegular() {
+		if err := os.Remove(dp.dataFile); err != nil {
+			klog.Errorf("Unable to remove the target file %s: %v", dp.dataFile, err)
+		}
 	}
-	return errors.New("Image resize called with blank resize")
 }
 
 func (dp *DataProcessor) calculateTargetSize() int64 {
@@ -395,6 +805,7 @@ func (dp *DataProcessor) calculateTargetSize() int64 {
 		}
 		targetQuantity = resource.NewScaledQuantity(size, 0)
 	}
+	dp.volumeSpace = targetQuantity.Value()
 	if dp.requestImageSize != "" {
 		klog.V(1).Infof("Request image size not empty.\n")
 		newImageSizeQuantity := resource.MustParse(dp.requestImageSize)
@@ -406,8 +817,37 @@ func (dp *DataProcessor) calculateTargetSize() int64 {
 	return targetSize
 }
 
+// DiskFormat returns the format of the disk image written to the data file.
+func (dp *DataProcessor) DiskFormat() string {
+	return dp.diskFormat
+}
+
+// Qcow2OptionsApplied returns the cluster size and the compat level of the qcow2 disk image written
+// to the data file, empty for a raw disk image.
+func (dp *DataProcessor) Qcow2OptionsApplied() image.Qcow2Options {
+	return dp.qcow2OptionsApplied
+}
+
+// DestinationDigest returns the digest of the disk image written to the data file, empty if it could
+// not be computed.
+func (dp *DataProcessor) DestinationDigest() string {
+	return dp.destinationDigest
+}
+
 // PreallocationApplied returns true if data processing path included preallocation step
 func (dp *DataProcessor) PreallocationApplied() bool {
+	return dp.preallocationApplied != image.PreallocationNone
+}
+
+// BytesWritten returns the size of the data file written to a filesystem volume, and the space allocated to it,
+// smaller when the data file is sparse. Both are 0 when the data file is not written to a filesystem volume.
+func (dp *DataProcessor) BytesWritten() (int64, int64) {
+	return dp.logicalBytes, dp.physicalBytes
+}
+
+// PreallocationModeApplied returns the preallocation mode performed, falloc or full, empty if there was none.
+// It is full when falloc was requested and the filesystem does not support it.
+func (dp *DataProcessor) PreallocationModeApplied() image.PreallocationMode {
 	return dp.preallocationApplied
 }
 
@@ -1,18 +1,24 @@
 package importer
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/pkg/errors"
 
+	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/image"
 )
 
@@ -41,6 +47,14 @@ type fakeQEMUOperations struct {
 	e5             error
 	e6             error
 	resizeQuantity *resource.Quantity
+	formats        []string           // formats passed to Validate and ConvertToRawStream
+	flattenChain   bool               // passed to Validate
+	keyFiles       []string           // key files passed to Validate and ConvertToRawStream
+	convertedTo    []string           // output formats of the conversions
+	qcow2Options   image.Qcow2Options // passed to ConvertToQcow2Stream
+	resizeFormats  []string           // formats passed to Resize
+	checkErr       error              // returned by Check
+	checked        int                // calls of Check
 }
 
 type MockDataProvider struct {
@@ -95,6 +109,64 @@ func (m *MockDataProvider) Close() error {
 	return nil
 }
 
+type MockLimitedDataProvider struct {
+	MockDataProvider
+	maxSize    int64
+	sourceSize int64
+}
+
+// SetMaxDecompressedSize limits the size of the decompressed data.
+func (mldp *MockLimitedDataProvider) SetMaxDecompressedSize(max int64) {
+	mldp.maxSize = max
+}
+
+// ValidateSourceSize fails if the source is larger than max bytes.
+func (mldp *MockLimitedDataProvider) ValidateSourceSize(max int64) error {
+	if mldp.sourceSize > max {
+		return ValidationSizeError{err: errors.New("source too large")}
+	}
+	return nil
+}
+
+type MockConvertFormatDataProvider struct {
+	MockDataProvider
+	format string
+}
+
+// ConvertFormat returns the format of the data to convert.
+func (mcfdp *MockConvertFormatDataProvider) ConvertFormat() string {
+	return mcfdp.format
+}
+
+type MockFlattenChainDataProvider struct {
+	MockDataProvider
+}
+
+// FlattenChain returns true, the backing chain of the image is extracted along with it.
+func (mfcdp *MockFlattenChainDataProvider) FlattenChain() bool {
+	return true
+}
+
+type MockProgressDataProvider struct {
+	MockDataProvider
+	progressMax float64
+}
+
+// SetProgressMax sets the progress reported once the data is transferred.
+func (mpdp *MockProgressDataProvider) SetProgressMax(max float64) {
+	mpdp.progressMax = max
+}
+
+type MockContextDataProvider struct {
+	MockDataProvider
+	ctx context.Context
+}
+
+// SetContext sets the context of the transfer.
+func (mcdp *MockContextDataProvider) SetContext(ctx context.Context) {
+	mcdp.ctx = ctx
+}
+
 type MockAsyncDataProvider struct {
 	MockDataProvider
 	ResumePhase ProcessingPhase
@@ -130,6 +202,22 @@ func (madp *MockAsyncDataProvider) GetResumePhase() ProcessingPhase {
 	return madp.ResumePhase
 }
 
+type MockDigestDataProvider struct {
+	MockDataProvider
+	payloadDigestSize int64
+}
+
+// Digests returns the digests of the data of the source and of the data transferred.
+func (mddp *MockDigestDataProvider) Digests() Digests {
+	return Digests{}
+}
+
+// PayloadDigest returns the digest of the data transferred followed by zeros up to size bytes.
+func (mddp *MockDigestDataProvider) PayloadDigest(size int64) string {
+	mddp.payloadDigestSize = size
+	return "sha256:payload"
+}
+
 const ProcessingPhaseFoo ProcessingPhase = "Foo"
 
 type MockCustomizedDataProvider struct {
@@ -269,6 +357,239 @@ var _ = Describe("Data Processor", func() {
 		})
 	})
 
+	It("should pass the format of the source to qemu-img", func() {
+		tmpDir, err := os.MkdirTemp("", "scratch")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+
+		url, err := url.Parse("http://fakeurl-notreal.fake")
+		Expect(err).ToNot(HaveOccurred())
+		mcfdp := &MockConvertFormatDataProvider{
+			MockDataProvider: MockDataProvider{
+				infoResponse:     ProcessingPhaseTransferScratch,
+				transferResponse: ProcessingPhaseConvert,
+				url:              url,
+			},
+			format: image.QemuFormatVhd,
+		}
+		dp := NewDataProcessor(mcfdp, "", "dataDir", tmpDir, "1G", 0.055, false)
+		dp.availableSpace = int64(1536000)
+		usableSpace := dp.getUsableSpace()
+
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoRet, nil, nil, resource.NewScaledQuantity(usableSpace, 1024*1024))
+		replaceQEMUOperations(qemuOperations, func() {
+			err = dp.ProcessData()
+			Expect(err).ToNot(HaveOccurred())
+			// the image is validated, then converted
+			formats := qemuOperations.(*fakeQEMUOperations).formats
+			Expect(formats).To(ContainElement(image.QemuFormatVhd))
+			Expect(formats).ToNot(ContainElement(""))
+			Expect(qemuOperations.(*fakeQEMUOperations).flattenChain).To(BeFalse())
+		})
+	})
+
+	It("should validate the image with its backing chain if the source flattens it", func() {
+		tmpDir, err := os.MkdirTemp("", "scratch")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+
+		mfcdp := &MockFlattenChainDataProvider{
+			MockDataProvider: MockDataProvider{
+				infoResponse:     ProcessingPhaseTransferScratch,
+				transferResponse: ProcessingPhaseConvert,
+				url:              &url.URL{Path: filepath.Join(tmpDir, "tmpimage")},
+			},
+		}
+		dp := NewDataProcessor(mfcdp, "", "dataDir", tmpDir, "1G", 0.055, false)
+		dp.availableSpace = int64(1536000)
+		usableSpace := dp.getUsableSpace()
+
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoRet, nil, nil, resource.NewScaledQuantity(usableSpace, 1024*1024))
+		replaceQEMUOperations(qemuOperations, func() {
+			Expect(dp.ProcessData()).To(Succeed())
+			Expect(qemuOperations.(*fakeQEMUOperations).flattenChain).To(BeTrue())
+		})
+	})
+
+	It("should validate and convert the image with the encryption key file", func() {
+		tmpDir, err := os.MkdirTemp("", "scratch")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferScratch,
+			transferResponse: ProcessingPhaseConvert,
+			url:              &url.URL{Path: filepath.Join(tmpDir, "tmpimage")},
+		}
+		dp := NewDataProcessor(mdp, "", "dataDir", tmpDir, "1G", 0.055, false)
+		dp.SetEncryptionKeyFile("/encryption/passphrase")
+		dp.availableSpace = int64(1536000)
+		usableSpace := dp.getUsableSpace()
+
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoRet, nil, nil, resource.NewScaledQuantity(usableSpace, 1024*1024))
+		replaceQEMUOperations(qemuOperations, func() {
+			Expect(dp.ProcessData()).To(Succeed())
+			Expect(qemuOperations.(*fakeQEMUOperations).keyFiles).To(Equal([]string{"/encryption/passphrase", "/encryption/passphrase", ""}))
+		})
+	})
+
+	It("should convert raw data from scratch space to a compressed qcow2 disk image", func() {
+		tmpDir, err := os.MkdirTemp("", "scratch")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		targetDir, err := os.MkdirTemp("", "data")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(targetDir)
+
+		dataFile := filepath.Join(targetDir, "disk.img")
+		Expect(os.WriteFile(dataFile, nil, 0600)).To(Succeed())
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataFile,
+			transferResponse: ProcessingPhaseConvert,
+			url:              &url.URL{Path: filepath.Join(tmpDir, "tmpimage")},
+		}
+		dp := NewDataProcessor(mdp, dataFile, "dataDir", tmpDir, "1G", 0.055, true)
+		dp.SetDiskFormat(image.QemuFormatQcow2, image.Qcow2Options{Compress: true, ClusterSize: "2M", Compat: "0.10"})
+		// larger than the image, it is resized
+		dp.availableSpace = int64(4 << 20)
+
+		qcow2Info := fakeSmallImageInfo
+		qcow2Info.ClusterSize = 2 << 20
+		qcow2Info.FormatSpecific = &image.ImgFormatSpecific{Type: image.QemuFormatQcow2}
+		qcow2Info.FormatSpecific.Data.Compat = "0.10"
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{imgInfo: &qcow2Info}, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			Expect(dp.ProcessData()).To(Succeed())
+			// the raw data is not written to the data file
+			Expect(mdp.transferPath).To(Equal(tmpDir))
+			Expect(mdp.transferFile).To(BeEmpty())
+			fakeOperations := qemuOperations.(*fakeQEMUOperations)
+			Expect(fakeOperations.convertedTo).To(Equal([]string{image.QemuFormatQcow2}))
+			Expect(fakeOperations.qcow2Options).To(Equal(image.Qcow2Options{Compress: true, ClusterSize: "2M", Compat: "0.10"}))
+			Expect(fakeOperations.resizeFormats).To(Equal([]string{image.QemuFormatQcow2}))
+			Expect(fakeOperations.formats).To(HaveLen(3))
+			Expect(fakeOperations.formats[2]).To(Equal(image.QemuFormatQcow2))
+			Expect(dp.DiskFormat()).To(Equal(image.QemuFormatQcow2))
+			Expect(dp.Qcow2OptionsApplied().String()).To(Equal("cluster_size=2097152,compat=0.10,compressed=true"))
+			Expect(dp.PreallocationApplied()).To(BeFalse())
+		})
+	})
+
+	It("should write raw data to the data file by default", func() {
+		tmpDir, err := os.MkdirTemp("", "scratch")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		targetDir, err := os.MkdirTemp("", "data")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(targetDir)
+
+		dataFile := filepath.Join(targetDir, "disk.img")
+		Expect(os.WriteFile(dataFile, nil, 0600)).To(Succeed())
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataFile,
+			transferResponse: ProcessingPhaseResize,
+		}
+		dp := NewDataProcessor(mdp, dataFile, "dataDir", tmpDir, "1G", 0.055, false)
+		// larger than the image, it is resized
+		dp.availableSpace = int64(4 << 20)
+
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoRet, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			Expect(dp.ProcessData()).To(Succeed())
+			Expect(mdp.transferFile).To(Equal(dataFile))
+			Expect(qemuOperations.(*fakeQEMUOperations).convertedTo).To(BeEmpty())
+			Expect(qemuOperations.(*fakeQEMUOperations).resizeFormats).To(Equal([]string{image.QemuFormatRaw}))
+			Expect(dp.DiskFormat()).To(Equal(image.QemuFormatRaw))
+		})
+	})
+
+	It("should preallocate raw data copied to the data file with the mode requested", func() {
+		tmpDir, err := os.MkdirTemp("", "scratch")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		targetDir, err := os.MkdirTemp("", "data")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(targetDir)
+
+		// a sparse data file
+		dataFile := filepath.Join(targetDir, "disk.img")
+		Expect(os.WriteFile(dataFile, nil, 0600)).To(Succeed())
+		Expect(os.Truncate(dataFile, 1<<20)).To(Succeed())
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataFile,
+			transferResponse: ProcessingPhaseResize,
+		}
+		dp := NewDataProcessor(mdp, dataFile, "dataDir", tmpDir, "1G", 0.055, true)
+		dp.SetPreallocationMode(image.PreallocationFull)
+		dp.availableSpace = int64(4 << 20)
+
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoRet, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			Expect(dp.ProcessData()).To(Succeed())
+			Expect(dp.PreallocationApplied()).To(BeTrue())
+			Expect(dp.PreallocationModeApplied()).To(Equal(image.PreallocationFull))
+			logical, physical := dp.BytesWritten()
+			Expect(logical).To(Equal(int64(1 << 20)))
+			Expect(physical).To(BeNumerically(">=", 1<<20))
+		})
+	})
+
+	It("should report the bytes allocated to a sparse data file", func() {
+		tmpDir, err := os.MkdirTemp("", "scratch")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		targetDir, err := os.MkdirTemp("", "data")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(targetDir)
+
+		dataFile := filepath.Join(targetDir, "disk.img")
+		Expect(os.WriteFile(dataFile, nil, 0600)).To(Succeed())
+		Expect(os.Truncate(dataFile, 1<<20)).To(Succeed())
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferDataFile,
+			transferResponse: ProcessingPhaseResize,
+		}
+		dp := NewDataProcessor(mdp, dataFile, "dataDir", tmpDir, "1G", 0.055, false)
+		dp.availableSpace = int64(4 << 20)
+
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoRet, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			Expect(dp.ProcessData()).To(Succeed())
+			Expect(dp.PreallocationApplied()).To(BeFalse())
+			logical, physical := dp.BytesWritten()
+			Expect(logical).To(Equal(int64(1 << 20)))
+			Expect(physical).To(BeNumerically("<", 1<<20))
+		})
+	})
+
+	It("should report the digest of the data streamed to the data file, extended to its size", func() {
+		tmpDir, err := os.MkdirTemp("", "scratch")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		targetDir, err := os.MkdirTemp("", "data")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(targetDir)
+
+		dataFile := filepath.Join(targetDir, "disk.img")
+		Expect(os.WriteFile(dataFile, nil, 0600)).To(Succeed())
+		Expect(os.Truncate(dataFile, 1<<20)).To(Succeed())
+		mdp := &MockDigestDataProvider{
+			MockDataProvider: MockDataProvider{
+				infoResponse:     ProcessingPhaseTransferDataFile,
+				transferResponse: ProcessingPhaseResize,
+			},
+		}
+		dp := NewDataProcessor(mdp, dataFile, "dataDir", tmpDir, "1G", 0.055, false)
+		dp.availableSpace = int64(4 << 20)
+
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoRet, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			Expect(dp.ProcessData()).To(Succeed())
+			Expect(dp.DestinationDigest()).To(Equal("sha256:payload"))
+			Expect(mdp.payloadDigestSize).To(Equal(int64(1 << 20)))
+		})
+	})
+
 	It("should allow phase regsitry", func() {
 		mcdp := &MockCustomizedDataProvider{
 			MockDataProvider: MockDataProvider{
@@ -376,6 +697,85 @@ var _ = Describe("Convert", func() {
 			Expect(ProcessingPhaseError).To(Equal(nextPhase))
 		})
 	})
+
+	It("Should check the converted image", func() {
+		mdp := &MockDataProvider{}
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, nil}, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			nextPhase, err := dp.convert(mdp.GetURL())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextPhase).To(Equal(ProcessingPhaseResize))
+			Expect(qemuOperations.(*fakeQEMUOperations).checked).To(Equal(1))
+		})
+	})
+
+	It("Should report the milestones of the import", func() {
+		milestones.Reset()
+		reachedMilestones = nil
+		url, err := url.Parse("http://fakeurl-notreal.fake")
+		Expect(err).ToNot(HaveOccurred())
+		mdp := &MockDataProvider{
+			infoResponse: ProcessingPhaseConvert,
+			url:          url,
+		}
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
+		dp.RegisterPhaseExecutor(ProcessingPhaseResize, func() (ProcessingPhase, error) {
+			return ProcessingPhaseComplete, nil
+		})
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, nil}, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			Expect(dp.ProcessData()).To(Succeed())
+		})
+		Expect(testutil.CollectAndCount(milestones)).To(Equal(3))
+		for _, milestone := range []string{common.ImportMilestoneSourceConnected, common.ImportMilestoneConversionStarted, common.ImportMilestoneVerificationPassed} {
+			Expect(testutil.ToFloat64(milestones.WithLabelValues(ownerUID, milestone))).To(Equal(float64(1)))
+		}
+		Expect(ReachedMilestones()).To(Equal([]string{common.ImportMilestoneSourceConnected, common.ImportMilestoneConversionStarted, common.ImportMilestoneVerificationPassed}))
+	})
+
+	It("Should not report the verification of a corrupt image", func() {
+		milestones.Reset()
+		reachedMilestones = nil
+		mdp := &MockDataProvider{}
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, nil}, nil, nil, nil)
+		qemuOperations.(*fakeQEMUOperations).checkErr = image.NewFormatError(image.ErrCorruptImage, "qcow2", errors.New("2 leaked clusters were found on the image."))
+		replaceQEMUOperations(qemuOperations, func() {
+			_, err := dp.convert(mdp.GetURL())
+			Expect(err).To(HaveOccurred())
+		})
+		Expect(testutil.CollectAndCount(milestones)).To(Equal(1))
+		Expect(testutil.ToFloat64(milestones.WithLabelValues(ownerUID, common.ImportMilestoneConversionStarted))).To(Equal(float64(1)))
+		Expect(ReachedMilestones()).To(Equal([]string{common.ImportMilestoneConversionStarted}))
+	})
+
+	It("Should fail when the converted image is corrupt", func() {
+		mdp := &MockDataProvider{}
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, nil}, nil, nil, nil)
+		qemuOperations.(*fakeQEMUOperations).checkErr = image.NewFormatError(image.ErrCorruptImage, "qcow2", errors.New("2 leaked clusters were found on the image."))
+		replaceQEMUOperations(qemuOperations, func() {
+			nextPhase, err := dp.convert(mdp.GetURL())
+			Expect(errors.Is(err, image.ErrCorruptImage)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("2 leaked clusters"))
+			Expect(nextPhase).To(Equal(ProcessingPhaseError))
+		})
+	})
+
+	It("Should not check the converted image when the check is skipped", func() {
+		mdp := &MockDataProvider{}
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
+		dp.SetSkipImageCheck(true)
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, nil}, nil, nil, nil)
+		qemuOperations.(*fakeQEMUOperations).checkErr = errors.New("should not be checked")
+		replaceQEMUOperations(qemuOperations, func() {
+			nextPhase, err := dp.convert(mdp.GetURL())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextPhase).To(Equal(ProcessingPhaseResize))
+			Expect(qemuOperations.(*fakeQEMUOperations).checked).To(BeZero())
+		})
+	})
 })
 
 var _ = Describe("Resize", func() {
@@ -436,6 +836,63 @@ var _ = Describe("Resize", func() {
 		})
 	})
 
+	It("Should read a converted image to compute its digest", func() {
+		tmpDir, err := os.MkdirTemp(os.TempDir(), "data")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		data := bytes.Repeat([]byte("disk image "), 1000)
+		dataFile := filepath.Join(tmpDir, "disk.img")
+		Expect(os.WriteFile(dataFile, data, 0600)).To(Succeed())
+		dp := NewDataProcessor(&MockDataProvider{}, dataFile, tmpDir, "scratchDataDir", "", 0.055, false)
+		dp.convertedURL = &url.URL{Path: "source.qcow2"}
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, nil}, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			nextPhase, err := dp.resize()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nextPhase).To(Equal(ProcessingPhaseComplete))
+			Expect(dp.DestinationDigest()).To(Equal(fmt.Sprintf("sha256:%x", sha256.Sum256(data))))
+		})
+	})
+
+	It("Should compute the digest of a converted image up to its virtual size on a block device", func() {
+		tmpDir, err := os.MkdirTemp(os.TempDir(), "data")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		// the device is larger than the image
+		data := bytes.Repeat([]byte{0x55}, 2*SmallVirtualSize)
+		dataFile := filepath.Join(tmpDir, "disk.img")
+		Expect(os.WriteFile(dataFile, data, 0600)).To(Succeed())
+		replaceAvailableSpaceBlockFunc(func(dataFile string) (int64, error) {
+			return int64(len(data)), nil
+		}, func() {
+			dp := NewDataProcessor(&MockDataProvider{}, dataFile, "dataDir", "scratchDataDir", "", 0.055, false)
+			dp.convertedURL = &url.URL{Path: "source.qcow2"}
+			qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoRet, nil, nil, nil)
+			replaceQEMUOperations(qemuOperations, func() {
+				nextPhase, err := dp.resize()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(nextPhase).To(Equal(ProcessingPhaseComplete))
+				Expect(dp.DestinationDigest()).To(Equal(fmt.Sprintf("sha256:%x", sha256.Sum256(data[:fakeSmallImageInfo.VirtualSize]))))
+			})
+		})
+	})
+
+	It("Should not resize and return complete, when the image size is preserved", func() {
+		tmpDir, err := os.MkdirTemp(os.TempDir(), "data")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		mdp := &MockDataProvider{}
+		dp := NewDataProcessor(mdp, tmpDir, tmpDir, "scratchDataDir", "1G", 0.055, false)
+		dp.SetPreserveImageSize(true)
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, nil}, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			nextPhase, err := dp.resize()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ProcessingPhaseComplete).To(Equal(nextPhase))
+			Expect(qemuOperations.(*fakeQEMUOperations).resizeFormats).To(BeEmpty())
+		})
+	})
+
 	It("Should not resize and return error, when ResizeImage fails", func() {
 		tmpDir, err := os.MkdirTemp(os.TempDir(), "data")
 		Expect(err).ToNot(HaveOccurred())
@@ -463,6 +920,81 @@ var _ = Describe("Resize", func() {
 		})
 	})
 
+	It("Should limit the decompressed size of the source to the target size", func() {
+		replaceAvailableSpaceBlockFunc(func(dataDir string) (int64, error) {
+			return int64(100000), nil
+		}, func() {
+			mdp := &MockLimitedDataProvider{
+				MockDataProvider: MockDataProvider{
+					infoResponse: ProcessingPhaseComplete,
+				},
+			}
+			dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055, false)
+			err := dp.ProcessData()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mdp.maxSize).To(Equal(int64(100000)))
+		})
+	})
+
+	It("Should fail before the transfer when the source is larger than the target", func() {
+		replaceAvailableSpaceBlockFunc(func(dataDir string) (int64, error) {
+			return int64(100000), nil
+		}, func() {
+			mdp := &MockLimitedDataProvider{
+				MockDataProvider: MockDataProvider{
+					infoResponse:     ProcessingPhaseTransferDataFile,
+					transferResponse: ProcessingPhaseComplete,
+				},
+				sourceSize: 100001,
+			}
+			dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055, false)
+			err := dp.ProcessData()
+			Expect(err).To(BeAssignableToTypeOf(ValidationSizeError{}))
+			Expect(mdp.calledPhases).To(Equal([]ProcessingPhase{ProcessingPhaseInfo}))
+		})
+	})
+
+	It("Should share the progress of a transfer to scratch space with the conversion", func() {
+		mdp := &MockProgressDataProvider{
+			MockDataProvider: MockDataProvider{
+				infoResponse:     ProcessingPhaseTransferScratch,
+				transferResponse: ProcessingPhaseComplete,
+			},
+		}
+		defer image.SetConvertProgressStart(0)
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "", 0.055, false)
+		err := dp.ProcessData()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mdp.progressMax).To(Equal(scratchTransferProgress))
+	})
+
+	It("Should pass the context to the source", func() {
+		mdp := &MockContextDataProvider{
+			MockDataProvider: MockDataProvider{
+				infoResponse: ProcessingPhaseComplete,
+			},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
+		err := dp.ProcessDataContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mdp.ctx).To(Equal(ctx))
+	})
+
+	It("Should not start a phase once the context is cancelled", func() {
+		mdp := &MockDataProvider{
+			infoResponse:     ProcessingPhaseTransferScratch,
+			transferResponse: ProcessingPhaseComplete,
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		dp := NewDataProcessor(mdp, "dest", "dataDir", "scratchDataDir", "1G", 0.055, false)
+		err := dp.ProcessDataContext(ctx)
+		Expect(err).To(Equal(context.Canceled))
+		Expect(mdp.calledPhases).To(BeEmpty())
+	})
+
 	It("Should fail if calculate size returns failure", func() {
 		replaceAvailableSpaceBlockFunc(func(dataDir string) (int64, error) {
 			return int64(-1), errors.New("error")
@@ -480,7 +1012,7 @@ var _ = Describe("ResizeImage", func() {
 	//fakeInfoRet has info.VirtualSize=1024
 	table.DescribeTable("calling ResizeImage", func(qemuOperations image.QEMUOperations, imageSize string, totalSpace int64, wantErr bool) {
 		replaceQEMUOperations(qemuOperations, func() {
-			err := ResizeImage("dest", imageSize, totalSpace, false)
+			_, err := ResizeImage("dest", image.QemuFormatRaw, imageSize, totalSpace, image.PreallocationNone)
 			if !wantErr {
 				Expect(err).ToNot(HaveOccurred())
 			} else {
@@ -571,30 +1103,50 @@ func replaceQEMUOperations(replacement image.QEMUOperations, f func()) {
 }
 
 func NewFakeQEMUOperations(e2, e3 error, ret4 fakeInfoOpRetVal, e5 error, e6 error, targetResize *resource.Quantity) image.QEMUOperations {
-	return &fakeQEMUOperations{e2, e3, ret4, e5, e6, targetResize}
+	return &fakeQEMUOperations{e2, e3, ret4, e5, e6, targetResize, nil, false, nil, nil, image.Qcow2Options{}, nil, nil, 0}
+}
+
+func (o *fakeQEMUOperations) ConvertToRawStream(url *url.URL, format, dest string, preallocation image.PreallocationMode, keyFile string) (image.PreallocationMode, error) {
+	o.formats = append(o.formats, format)
+	o.keyFiles = append(o.keyFiles, keyFile)
+	o.convertedTo = append(o.convertedTo, image.QemuFormatRaw)
+	return preallocation, o.e2
 }
 
-func (o *fakeQEMUOperations) ConvertToRawStream(*url.URL, string, bool) error {
+func (o *fakeQEMUOperations) ConvertToQcow2Stream(url *url.URL, format, dest string, options image.Qcow2Options, keyFile string) error {
+	o.formats = append(o.formats, format)
+	o.keyFiles = append(o.keyFiles, keyFile)
+	o.convertedTo = append(o.convertedTo, image.QemuFormatQcow2)
+	o.qcow2Options = options
 	return o.e2
 }
 
-func (o *fakeQEMUOperations) Validate(*url.URL, int64) error {
+func (o *fakeQEMUOperations) Validate(url *url.URL, format string, availableSize int64, flattenChain bool, keyFile string) error {
+	o.formats = append(o.formats, format)
+	o.flattenChain = flattenChain
+	o.keyFiles = append(o.keyFiles, keyFile)
 	return o.e5
 }
 
-func (o *fakeQEMUOperations) Resize(dest string, size resource.Quantity, preallocate bool) error {
+func (o *fakeQEMUOperations) Check(url *url.URL, format, keyFile string) error {
+	o.checked++
+	return o.checkErr
+}
+
+func (o *fakeQEMUOperations) Resize(dest, format string, size resource.Quantity, preallocation image.PreallocationMode) (image.PreallocationMode, error) {
+	o.resizeFormats = append(o.resizeFormats, format)
 	if o.resizeQuantity != nil {
 		Expect(o.resizeQuantity.Cmp(size)).To(Equal(0), "sizes don't match %v, %v", o.resizeQuantity.String(), size.String())
 	}
-	return o.e3
+	return preallocation, o.e3
 }
 
 func (o *fakeQEMUOperations) Info(url *url.URL) (*image.ImgInfo, error) {
 	return o.ret4.imgInfo, o.ret4.e
 }
 
-func (o *fakeQEMUOperations) CreateBlankImage(dest string, size resource.Quantity, preallocate bool) error {
-	return o.e6
+func (o *fakeQEMUOperations) CreateBlankImage(dest string, size resource.Quantity, preallocation image.PreallocationMode) (image.PreallocationMode, error) {
+	return preallocation, o.e6
 }
 
 // Simulate rebase by changing the backing file.
@@ -615,6 +1167,15 @@ func (o *fakeQEMUOperations) Commit(image string) error {
 	return nil
 }
 
+func (o *fakeQEMUOperations) ConvertRawImage(src, dest, format, createOptions, keyFile string) error {
+	o.convertedTo = append(o.convertedTo, format)
+	return o.e2
+}
+
+func (o *fakeQEMUOperations) CreateOverlay(backingFile, dest string) error {
+	return o.e6
+}
+
 func NewQEMUAllErrors() image.QEMUOperations {
 	err := errors.New("qemu should not be called from this test override with replaceQEMUOperations")
 	return NewFakeQEMUOperations(err, err, fakeInfoOpRetVal{nil, err}, err, err, nil)
@@ -0,0 +1,182 @@
+package importer
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/klog/v2"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// FileDataSource is the struct containing the information needed to import a file of a PVC, or of
+// a directory of the node, mounted read-only in the importer pod. The file is only opened for
+// reading, a failed import is retried with the same data.
+// Sequence of phases:
+// 1a. Info -> Convert, if qemu-img converts the image directly from the file
+// 1b. Info -> TransferDataFile, if the file is a raw image
+// 1c. Info -> TransferDataDir, if the content type is archive
+// 1d. Info -> Transfer in all other cases
+// 2a. Transfer -> Convert
+// 2b. TransferDataDir -> Complete
+type FileDataSource struct {
+	// path of the file, within the mounted directory
+	path string
+	// the file opened for reading
+	file *os.File
+	// size of the file
+	size int64
+	// contentType of the data volume
+	contentType cdiv1.DataVolumeContentType
+	// stack of readers, created by Info
+	sourceReaders
+	// The image file converted by qemu-img, the file itself or its copy in scratch space.
+	url *url.URL
+	// the readers stop once ctx is done
+	ctx context.Context
+}
+
+// NewFileDataSource creates a new instance of the FileDataSource, reading the file of path relative
+// to dir, the directory where the PVC or the directory of the node is mounted. The file, once its
+// symbolic links are resolved, must be a regular file within dir.
+func NewFileDataSource(dir, path string, contentType cdiv1.DataVolumeContentType) (*FileDataSource, error) {
+	filePath, err := resolveSourceFile(dir, path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open the file %q", path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "unable to stat the file %q", path)
+	}
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, errors.Errorf("%q is not a regular file", path)
+	}
+	return &FileDataSource{
+		path:        filePath,
+		file:        file,
+		size:        info.Size(),
+		contentType: contentType,
+		ctx:         context.Background(),
+	}, nil
+}
+
+// NewNFSDataSource creates a new instance of the FileDataSource, reading the file of the NFS URL
+// endpoint, nfs://server/path/file, from dir, the directory where the directory of the file in the
+// NFS export is mounted.
+func NewNFSDataSource(dir, endpoint string, contentType cdiv1.DataVolumeContentType) (*FileDataSource, error) {
+	ep, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse the NFS URL %q", endpoint)
+	}
+	if ep.Scheme != "nfs" || ep.Path == "" || strings.HasSuffix(ep.Path, "/") {
+		return nil, errors.Errorf("%q is not the NFS URL of a file", endpoint)
+	}
+	return NewFileDataSource(dir, filepath.Base(ep.Path), contentType)
+}
+
+// resolveSourceFile returns the path of the file of path relative to dir, with its symbolic links
+// resolved, failing when it is out of dir.
+func resolveSourceFile(dir, path string) (string, error) {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve the source directory %q", dir)
+	}
+	filePath, err := filepath.EvalSymlinks(filepath.Join(root, filepath.Clean("/"+path)))
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve the file %q", path)
+	}
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("the file %q is out of the source directory", path)
+	}
+	return filePath, nil
+}
+
+// Info is called to get initial information about the data.
+func (fd *FileDataSource) Info() (ProcessingPhase, error) {
+	var err error
+	fd.readers, err = newFormatReaders(fd.ctx, fd.file, uint64(fd.size), fd.path)
+	if err != nil {
+		klog.Errorf("Error creating readers: %v", err)
+		return ProcessingPhaseError, err
+	}
+	if fd.contentType == cdiv1.DataVolumeArchive {
+		return ProcessingPhaseTransferDataDir, nil
+	}
+	if !fd.readers.Convert {
+		// Reading a raw file, we can write that directly to the target.
+		return ProcessingPhaseTransferDataFile, nil
+	}
+	if !fd.readers.Archived && !fd.readers.ConvertScratch && !fd.readers.FlattenChain {
+		// qemu-img reads the image from the file, it is not copied to scratch space first
+		fd.url = &url.URL{Path: fd.path}
+		return ProcessingPhaseConvert, nil
+	}
+	return ProcessingPhaseTransferScratch, nil
+}
+
+// SetContext stops the transfer once ctx is done.
+func (fd *FileDataSource) SetContext(ctx context.Context) {
+	fd.ctx = ctx
+}
+
+// Transfer is called to transfer the data from the source to the passed in path.
+func (fd *FileDataSource) Transfer(path string) (ProcessingPhase, error) {
+	if fd.contentType == cdiv1.DataVolumeArchive {
+		if err := util.UnArchiveTar(fd.readers.TopReader(), path); err != nil {
+			return ProcessingPhaseError, errors.Wrap(err, "unable to untar files from the file")
+		}
+		fd.url = nil
+		return ProcessingPhaseComplete, nil
+	}
+	size, _ := util.GetAvailableSpace(path)
+	if size <= int64(0) {
+		//Path provided is invalid.
+		return ProcessingPhaseError, ErrInvalidPath
+	}
+	file := filepath.Join(path, tempFile)
+	fd.readers.StartProgressUpdate()
+	if err := fd.readers.StreamToFile(file); err != nil {
+		return ProcessingPhaseError, err
+	}
+	// If streaming succeeded, then parsing the file into URL will also succeed, no need to check error status
+	fd.url, _ = url.Parse(file)
+	return ProcessingPhaseConvert, nil
+}
+
+// TransferFile is called to transfer the data from the source to the passed in file.
+func (fd *FileDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
+	fd.readers.StartProgressUpdate()
+	if err := fd.readers.StreamToFile(fileName); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseResize, nil
+}
+
+// GetURL returns the url that the data processor can use when converting the data.
+func (fd *FileDataSource) GetURL() *url.URL {
+	return fd.url
+}
+
+// Close closes any readers or other open resources.
+func (fd *FileDataSource) Close() error {
+	if fd.readers != nil {
+		return fd.readers.Close()
+	}
+	if fd.file != nil {
+		return fd.file.Close()
+	}
+	return nil
+}
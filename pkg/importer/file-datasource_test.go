@@ -0,0 +1,168 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+var _ = Describe("File data source", func() {
+	var (
+		fd        *FileDataSource
+		tmpDir    string
+		sourceDir string
+	)
+
+	// addFile copies the file of srcPath to the source directory as name, read-only
+	addFile := func(name, srcPath string) {
+		data, err := os.ReadFile(srcPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Dir(filepath.Join(sourceDir, name)), 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(sourceDir, name), data, 0400)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "file")
+		Expect(err).NotTo(HaveOccurred())
+		sourceDir = filepath.Join(tmpDir, "source")
+		Expect(os.Mkdir(sourceDir, 0700)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if fd != nil {
+			fd.Close()
+			fd = nil
+		}
+		os.RemoveAll(tmpDir)
+	})
+
+	table.DescribeTable("Info should return", func(srcPath string, contentType cdiv1.DataVolumeContentType, expectedPhase ProcessingPhase) {
+		// the name of the file keeps its extensions, the headers of brotli data are not enough
+		name := filepath.Join("images", filepath.Base(srcPath))
+		addFile(name, srcPath)
+		var err error
+		fd, err = NewFileDataSource(sourceDir, name, contentType)
+		Expect(err).NotTo(HaveOccurred())
+		phase, err := fd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(expectedPhase))
+	},
+		table.Entry("TransferDataFile with a raw image", tinyCoreFilePath, cdiv1.DataVolumeKubeVirt, ProcessingPhaseTransferDataFile),
+		table.Entry("TransferDataFile with a compressed raw image", tinyCoreXzFilePath, cdiv1.DataVolumeKubeVirt, ProcessingPhaseTransferDataFile),
+		table.Entry("TransferScratch with a compressed qcow2 image", cirrosBrotliFilePath, cdiv1.DataVolumeKubeVirt, ProcessingPhaseTransferScratch),
+		table.Entry("TransferDataDir with archive content type", archiveFilePath, cdiv1.DataVolumeArchive, ProcessingPhaseTransferDataDir),
+	)
+
+	It("should let qemu-img convert a qcow2 image from the file", func() {
+		addFile("cirros.qcow2", cirrosFilePath)
+		var err error
+		fd, err = NewFileDataSource(sourceDir, "cirros.qcow2", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		phase, err := fd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseConvert))
+		Expect(fd.GetURL().Path).To(Equal(filepath.Join(sourceDir, "cirros.qcow2")))
+	})
+
+	It("should write a raw image directly, leaving the file as it is", func() {
+		addFile("tinyCore.iso", tinyCoreFilePath)
+		var err error
+		fd, err = NewFileDataSource(sourceDir, "tinyCore.iso", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = fd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		target := filepath.Join(tmpDir, "disk.img")
+		phase, err := fd.TransferFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseResize))
+		expected, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := os.ReadFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(expected))
+		data, err = os.ReadFile(filepath.Join(sourceDir, "tinyCore.iso"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(expected))
+	})
+
+	It("should copy a compressed qcow2 image to the scratch space", func() {
+		addFile("cirros.qcow2.br", cirrosBrotliFilePath)
+		var err error
+		fd, err = NewFileDataSource(sourceDir, "cirros.qcow2.br", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = fd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		phase, err := fd.Transfer(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseConvert))
+		Expect(fd.GetURL().Path).To(Equal(filepath.Join(tmpDir, tempFile)))
+		data, err := os.ReadFile(filepath.Join(tmpDir, tempFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(cirrosData))
+	})
+
+	It("should extract the files of an archive", func() {
+		addFile("archive.tar", archiveFilePath)
+		var err error
+		fd, err = NewFileDataSource(sourceDir, "archive.tar", cdiv1.DataVolumeArchive)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = fd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		target := filepath.Join(tmpDir, "target")
+		Expect(os.Mkdir(target, 0700)).To(Succeed())
+		phase, err := fd.Transfer(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseComplete))
+		Expect(filepath.Join(target, tinyCoreFileName)).To(BeAnExistingFile())
+		Expect(filepath.Join(target, cirrosFileName)).To(BeAnExistingFile())
+	})
+
+	table.DescribeTable("should fail to open", func(path, expected string) {
+		addFile("images/disk.img", tinyCoreFilePath)
+		addFile("../outside.img", tinyCoreFilePath)
+		Expect(os.Symlink(filepath.Join(tmpDir, "outside.img"), filepath.Join(sourceDir, "link.img"))).To(Succeed())
+		_, err := NewFileDataSource(sourceDir, path, cdiv1.DataVolumeKubeVirt)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expected))
+	},
+		table.Entry("a missing file", "images/missing.img", "unable to resolve the file"),
+		table.Entry("a file out of the directory", "../outside.img", "unable to resolve the file"),
+		table.Entry("a symbolic link out of the directory", "link.img", "is out of the source directory"),
+		table.Entry("a directory", "images", "is not a regular file"),
+		table.Entry("the directory itself", ".", "is out of the source directory"),
+	)
+
+	It("should open a symbolic link within the directory", func() {
+		addFile("images/disk.img", tinyCoreFilePath)
+		Expect(os.Symlink("images/disk.img", filepath.Join(sourceDir, "inside.img"))).To(Succeed())
+		var err error
+		fd, err = NewFileDataSource(sourceDir, "inside.img", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fd.path).To(Equal(filepath.Join(sourceDir, "images/disk.img")))
+	})
+
+	It("should open the file of an NFS URL in the mounted directory", func() {
+		addFile("disk.img", tinyCoreFilePath)
+		var err error
+		fd, err = NewNFSDataSource(sourceDir, "nfs://nfs.example.com/exports/images/disk.img", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fd.path).To(Equal(filepath.Join(sourceDir, "disk.img")))
+	})
+
+	table.DescribeTable("should fail to open the NFS URL", func(endpoint string) {
+		addFile("disk.img", tinyCoreFilePath)
+		_, err := NewNFSDataSource(sourceDir, endpoint, cdiv1.DataVolumeKubeVirt)
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("of another scheme", "http://nfs.example.com/exports/disk.img"),
+		table.Entry("of a directory", "nfs://nfs.example.com/exports/"),
+		table.Entry("without a path", "nfs://nfs.example.com"),
+		table.Entry("of a missing file", "nfs://nfs.example.com/exports/missing.img"),
+	)
+})
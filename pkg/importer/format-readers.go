@@ -17,15 +17,31 @@ limitations under the License.
 package importer
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
-	"compress/gzip"
+	"compress/bzip2"
+	"context"
+	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"hash"
 	"io"
+	"os"
+	"path"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
@@ -43,6 +59,20 @@ var (
 		},
 		[]string{"ownerUID"},
 	)
+	transferredBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: monitoring.MetricOptsList[monitoring.ImportTransferred].Name,
+			Help: monitoring.MetricOptsList[monitoring.ImportTransferred].Help,
+		},
+		[]string{"ownerUID"},
+	)
+	sourceSizeBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: monitoring.MetricOptsList[monitoring.ImportSourceSize].Name,
+			Help: monitoring.MetricOptsList[monitoring.ImportSourceSize].Help,
+		},
+		[]string{"ownerUID"},
+	)
 	ownerUID string
 )
 
@@ -56,6 +86,20 @@ func init() {
 			klog.Errorf("Unable to create prometheus progress counter")
 		}
 	}
+	if err := prometheus.Register(transferredBytes); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			transferredBytes = are.ExistingCollector.(*prometheus.GaugeVec)
+		} else {
+			klog.Errorf("Unable to create prometheus transferred bytes gauge")
+		}
+	}
+	if err := prometheus.Register(sourceSizeBytes); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			sourceSizeBytes = are.ExistingCollector.(*prometheus.GaugeVec)
+		} else {
+			klog.Errorf("Unable to create prometheus source size gauge")
+		}
+	}
 	ownerUID, _ = util.ParseEnvVar(common.OwnerUID, false)
 }
 
@@ -64,15 +108,115 @@ type reader struct {
 	rdr     io.ReadCloser
 }
 
+// sourceReaders is embedded in the data sources reading their data through FormatReaders, it
+// implements the optional data source interfaces once the readers are created by Info.
+type sourceReaders struct {
+	readers *FormatReaders
+}
+
+// SetMaxDecompressedSize limits the size of the decompressed data.
+func (sr *sourceReaders) SetMaxDecompressedSize(max int64) {
+	if sr.readers != nil {
+		sr.readers.SetMaxDecompressedSize(max)
+	}
+}
+
+// SetProgressMax sets the progress reported once the data is transferred.
+func (sr *sourceReaders) SetProgressMax(max float64) {
+	if sr.readers != nil {
+		sr.readers.SetProgressMax(max)
+	}
+}
+
+// Digests returns the digests of the data read from the source and of the data transferred.
+func (sr *sourceReaders) Digests() Digests {
+	if sr.readers != nil {
+		return sr.readers.Digests()
+	}
+	return Digests{}
+}
+
+// PayloadDigest returns the digest of the data transferred followed by zeros up to size bytes.
+func (sr *sourceReaders) PayloadDigest(size int64) string {
+	if sr.readers != nil {
+		return sr.readers.PayloadDigest(size)
+	}
+	return ""
+}
+
+// SourceFormat returns the format of the disk image of the source, iso for an ISO9660 image.
+func (sr *sourceReaders) SourceFormat() string {
+	if sr.readers != nil {
+		return sr.readers.SourceFormat()
+	}
+	return ""
+}
+
+// ValidateSourceSize fails if the raw data of the source is larger than max bytes.
+func (sr *sourceReaders) ValidateSourceSize(max int64) error {
+	if sr.readers != nil {
+		return sr.readers.ValidateSourceSize(max)
+	}
+	return nil
+}
+
+// ConvertFormat returns the format of the data passed to qemu-img, empty when it is probed.
+func (sr *sourceReaders) ConvertFormat() string {
+	if sr.readers != nil {
+		return sr.readers.ConvertFormat
+	}
+	return ""
+}
+
+// FlattenChain returns true if the backing chain of a qcow2 image extracted from an archive is
+// extracted, and flattened, along with it.
+func (sr *sourceReaders) FlattenChain() bool {
+	return sr.readers != nil && sr.readers.FlattenChain
+}
+
 // FormatReaders contains the stack of readers needed to get information from the input stream (io.ReadCloser)
 type FormatReaders struct {
 	readers        []reader
 	buf            []byte // holds file headers
 	Convert        bool
+	ConvertFormat  string // format passed to qemu-img to convert the data, it is probed when empty
+	ConvertScratch bool   // qemu-img converts a copy of the data on scratch space, not the source
+	FlattenChain   bool   // the backing chain of a qcow2 image is extracted from its archive along with it
+	ISO            bool   // the data is an ISO9660 image, raw data written as it is
 	Archived       bool
 	ArchiveXz      bool
+	ArchiveLzma    bool
+	ArchiveBrotli  bool
 	ArchiveGz      bool
+	ArchiveZstd    bool
+	ArchiveBz2     bool
+	ArchiveLz4     bool
+	ArchiveTar     bool
+	ArchiveCpio    bool
+	ArchiveZip     bool
+	Archive7z      bool
+	archiveEntry   string   // name of the file to extract from an archive, if any
+	name           string   // name of the file, its extensions are used when the headers are not enough
+	maxLayers      int      // maximum number of nested archive and compression layers
+	maxSize        int64    // maximum size of the decompressed data, if any
+	maxXzMemory    int64    // maximum memory of the xz decoder, if any
+	bandwidthLimit int64    // bytes per second the source is read at most, if limited
+	layers         []string // formats of the archive and compression layers found so far
+	formats        []string // formats found so far, the outermost first
+	source         io.ReadCloser
+	total          uint64
+	ctx            context.Context
 	progressReader *prometheusutil.ProgressReader
+	progressMax    float64          // progress reported once the data is read, 100 when 0
+	compressed     *byteCounter     // counts the bytes read from the source
+	decompressed   *byteCounter     // counts the bytes of decompressed data read, once a progress callback is set
+	progressDone   chan struct{}    // stops the progress callback
+	sparseEntry    *sparseTarReader // the tar entry extracted, when it is a sparse file
+	spoolTar       bool             // the tar archive is spooled to a file before its entry is extracted
+	sourceDigest   *util.DigestReader
+	payloadDigest  *util.DigestReader // digests the data written by StreamToFile
+	checksum       *util.Checksum     // checksum the data of the source is verified against, if any
+	signature      *sourceSignature   // detached signature the data of the source is verified against, if any
 }
 
 const (
@@ -80,32 +224,236 @@ const (
 	rdrMulti
 	rdrXz
 	rdrStream
+	rdrZstd
+	rdrBz2
+	rdrLz4
+	rdrTar
+	rdrCpio
+	rdrLzma
+	rdrBrotli
+	rdrSizeLimit
+	rdrProgress
 )
 
+// defaultMaxArchiveLayers is the maximum number of nested archive and compression layers unless
+// overridden with the IMPORTER_MAX_ARCHIVE_LAYERS environment variable.
+const defaultMaxArchiveLayers = 4
+
+// nbdkitXzMaxBlockSize is the size of the largest block of an xz source decompressed by nbdkit. Its
+// xz filter decompresses a whole block to read any of its data, and keeps up to 8 blocks in memory.
+const nbdkitXzMaxBlockSize = 32 << 20
+
+// xzMemoryLimitRatio is the share of the memory limit of the importer container the xz decoder uses
+// at most, unless overridden with the IMPORTER_XZ_MEMORY_LIMIT environment variable.
+const xzMemoryLimitRatio = 2
+
+// vhdxMaxMetadataEnd bounds the data of a vhdx image read ahead to check its metadata, Hyper-V and
+// qemu-img write the metadata region within the first few MiB of the image.
+const vhdxMaxMetadataEnd = 16 << 20
+
+// ovaMaxDescriptorEnd bounds the data of a tar archive read ahead to find the OVF descriptor of an
+// OVA appliance, its first entry.
+const ovaMaxDescriptorEnd = 1 << 20
+
+// ErrDecompressedTooLarge is returned when the decompressed data is larger than the maximum set with
+// SetMaxDecompressedSize.
+var ErrDecompressedTooLarge = fmt.Errorf("image exceeds requested PVC size")
+
+// ErrLz4LegacyFormat is returned when the data is compressed with the legacy lz4 format, which has
+// no frame header.
+var ErrLz4LegacyFormat = errors.New("lz4: legacy lz4 format is not supported, recompress the file using the lz4 frame format")
+
+// ProgressFunc is called with the number of bytes read from the source, compressed, and the number of
+// bytes of decompressed data read.
+type ProgressFunc func(compressedRead, decompressedRead uint64)
+
+// FormatInfo describes the formats found in a stream.
+type FormatInfo struct {
+	// Formats lists the formats found, the outermost first, a raw image has none.
+	Formats []string
+	// Seekable is set when the source can be read at any offset, a file for instance.
+	Seekable bool
+	// CompressedSize is the size of the source, 0 when it is not known.
+	CompressedSize uint64
+	// UncompressedSize is the size of the decompressed data when it is recorded by the compression
+	// format of a seekable source, an xz index or a gzip trailer, 0 otherwise. The gzip trailer only
+	// records the size modulo 4 GiB, of the last member of the file.
+	UncompressedSize uint64
+}
+
+// digestAlgorithm is the algorithm of the digests of the source and of the payload of an import
+const digestAlgorithm = "sha256"
+
+// Digests are the digests of the data of an import, as "sha256:<hex digest>". Each is empty unless
+// its data was read to the end.
+type Digests struct {
+	// Source is the digest of the data read from the source, compressed or archived.
+	Source string
+	// Payload is the digest of the data written by StreamToFile, decompressed and extracted.
+	Payload string
+	// Destination is the digest of the disk image written to the target, once converted and resized.
+	Destination string
+}
+
+// ArchiveLayersError is returned when the source has more nested archive and compression layers
+// than allowed.
+type ArchiveLayersError struct {
+	// Layers are the formats of the layers found, the last one exceeding the maximum.
+	Layers []string
+	Max    int
+}
+
+func (e ArchiveLayersError) Error() string {
+	return fmt.Sprintf("found %d nested archive and compression layers (%s), the maximum is %d, set %s to allow more",
+		len(e.Layers), strings.Join(e.Layers, ", "), e.Max, common.ImporterMaxArchiveLayers)
+}
+
+// Is reports the error as an unsupported format, reading the source again finds as many layers.
+func (e ArchiveLayersError) Is(target error) bool {
+	return target == image.ErrUnsupportedFormat
+}
+
+// XzMemoryLimitError is returned when decoding a xz stream requires more memory than allowed.
+type XzMemoryLimitError struct {
+	// Required is the size of the dictionary of the stream, allocated by the decoder.
+	Required int64
+	Allowed  int64
+}
+
+func (e XzMemoryLimitError) Error() string {
+	return fmt.Sprintf("decoding the xz stream requires %s of memory, the maximum is %s, raise the memory limit of the importer pod or set %s to allow more",
+		resource.NewQuantity(e.Required, resource.BinarySI), resource.NewQuantity(e.Allowed, resource.BinarySI), common.ImporterXzMemoryLimit)
+}
+
+// Is reports the error as an unsupported format, the importer is restarted with the same limit.
+func (e XzMemoryLimitError) Is(target error) bool {
+	return target == image.ErrUnsupportedFormat
+}
+
+// VmdkExtentsError is returned for the descriptor of a vmdk image, the data of the disk is in the
+// extent files it references, which are not imported along with it.
+type VmdkExtentsError struct {
+	// Extents are the extent files found in the header of the descriptor.
+	Extents []string
+}
+
+func (e VmdkExtentsError) Error() string {
+	return fmt.Sprintf("vmdk descriptor referencing the external extent files %s, only monolithicSparse and streamOptimized vmdk images can be imported",
+		strings.Join(e.Extents, ", "))
+}
+
+// Is reports the error as an unsupported format, the extent files are missing on a retry as well.
+func (e VmdkExtentsError) Is(target error) bool {
+	return target == image.ErrUnsupportedFormat
+}
+
+// OvaDisksError is returned for an OVA appliance referencing more than one disk, when the disk to
+// import is not selected.
+type OvaDisksError struct {
+	// Disks are the files of the disks referenced by the OVF descriptor of the appliance.
+	Disks []string
+}
+
+func (e OvaDisksError) Error() string {
+	return fmt.Sprintf("ova appliance with the disks %s, select the disk to import with the %sarchiveEntry annotation",
+		strings.Join(e.Disks, ", "), common.CDIAnnKey)
+}
+
+// Is reports the error as an unsupported format, the appliance has as many disks on a retry.
+func (e OvaDisksError) Is(target error) bool {
+	return target == image.ErrUnsupportedFormat
+}
+
+// unsupportedErrors are the errors of the format readers that tell the data is valid, but uses a
+// variant of its format that cannot be read.
+var unsupportedErrors = []error{
+	ErrLz4LegacyFormat,
+	zip.ErrAlgorithm,
+}
+
 // map scheme and format to rdrType
 var rdrTypM = map[string]int{
-	"gz":     rdrGz,
-	"xz":     rdrXz,
-	"stream": rdrStream,
+	"gz":       rdrGz,
+	"xz":       rdrXz,
+	"stream":   rdrStream,
+	"zst":      rdrZstd,
+	"bz2":      rdrBz2,
+	"lz4":      rdrLz4,
+	"tar":      rdrTar,
+	"cpio":     rdrCpio,
+	"cpio-crc": rdrCpio,
+	"lzma":     rdrLzma,
+	"br":       rdrBrotli,
 }
 
 // NewFormatReaders creates a new instance of FormatReaders using the input stream and content type passed in.
 func NewFormatReaders(stream io.ReadCloser, total uint64) (*FormatReaders, error) {
+	return newFormatReaders(context.Background(), stream, total, "")
+}
+
+// NewFormatReadersContext creates a new instance of FormatReaders like NewFormatReaders, the readers
+// stop once ctx is done and their reads then fail with the error of the context.
+func NewFormatReadersContext(ctx context.Context, stream io.ReadCloser, total uint64) (*FormatReaders, error) {
+	return newFormatReaders(ctx, stream, total, "")
+}
+
+// newFormatReaders creates a new instance of FormatReaders, the formats are detected from the
+// headers of the stream and name, the file name or URL path, is only used when the header does not
+// tell the format.
+func newFormatReaders(ctx context.Context, stream io.ReadCloser, total uint64, name string) (*FormatReaders, error) {
 	var err error
 	readers := &FormatReaders{
-		buf: make([]byte, image.MaxExpectedHdrSize),
+		buf:    make([]byte, image.MaxExpectedHdrSize),
+		name:   name,
+		ctx:    ctx,
+		source: stream,
+		total:  total,
+	}
+	readers.archiveEntry, _ = util.ParseEnvVar(common.ImporterArchiveEntry, false)
+	readers.FlattenChain, _ = strconv.ParseBool(os.Getenv(common.ImporterFlattenBackingChain))
+	if readers.maxLayers, err = maxArchiveLayers(); err != nil {
+		return readers, err
+	}
+	if readers.maxXzMemory, err = maxXzMemory(); err != nil {
+		return readers, err
+	}
+	if readers.bandwidthLimit, err = bandwidthLimit(); err != nil {
+		return readers, err
+	}
+	if readers.bandwidthLimit > 0 {
+		// the data is limited as it is read from the source, before it is decompressed
+		stream = limitBandwidth(ctx, stream, readers.bandwidthLimit)
 	}
-	if total > uint64(0) {
-		readers.progressReader = prometheusutil.NewProgressReader(stream, total, progress, ownerUID)
-		err = readers.constructReaders(readers.progressReader)
-	} else {
-		err = readers.constructReaders(stream)
+	if readers.checksum, err = sourceChecksum(); err != nil {
+		return readers, err
+	}
+	readers.sourceDigest = newSourceDigestReader(stream, readers.checksum)
+	// the bytes read are reported along with the progress, alone when the size of the source is unknown
+	readers.progressReader = prometheusutil.NewProgressReader(readers.sourceDigest, total, progress, ownerUID)
+	readers.progressReader.SetBytesGauges(transferredBytes, sourceSizeBytes)
+	err = readers.constructReaders(readers.progressReader)
+	if err == nil {
+		klog.V(1).Infof("formats found in the source: %v\n", readers.formats)
 	}
 	return readers, err
 }
 
 func (fr *FormatReaders) constructReaders(r io.ReadCloser) error {
-	fr.appendReader(rdrTypM["stream"], r)
+	fr.compressed = &byteCounter{ReadCloser: r}
+	fr.appendReader(rdrTypM["stream"], fr.compressed)
+	if image.FormatMatchesExtension("br", fr.name) {
+		// brotli has no header, decode it before the headers are matched so that the format of the
+		// compressed file, a qcow2 image for instance, is detected
+		klog.V(2).Infof("format %q selected from the extension of %q\n", "br", fr.name)
+		fr.appendReader(rdrTypM["br"], fr.classifyErrors("br", brotli.NewReader(fr.TopReader())))
+		fr.Archived = true
+		fr.ArchiveBrotli = true
+		fr.name = strings.TrimSuffix(fr.name, path.Ext(fr.name))
+		fr.formats = append(fr.formats, "br")
+		if err := fr.addLayer("br"); err != nil {
+			return err
+		}
+	}
 	knownHdrs := image.CopyKnownHdrs() // need local copy since keys are removed
 	klog.V(3).Infof("constructReaders: checking compression and archive formats\n")
 	for {
@@ -113,14 +461,56 @@ func (fr *FormatReaders) constructReaders(r io.ReadCloser) error {
 		if err != nil {
 			return errors.WithMessage(err, "could not process image header")
 		}
+		if hdr == nil || hdr.Ambiguous() {
+			// the system area of an ISO9660 image may start like a header too short to tell formats apart
+			iso, err := fr.isISO9660()
+			if err != nil {
+				return errors.WithMessage(err, "could not process image header")
+			}
+			if iso {
+				klog.V(2).Infof("ISO9660 image detected, it is written as it is\n")
+				fr.ISO = true
+				break
+			}
+		}
 		if hdr == nil {
+			if format, ok := image.FormatFromExtension(fr.name); ok && (format == "vmdk" || format == "vhd") {
+				// qemu-img reads the vmdk variants without a KDMV header, like the ESX sparse one, and
+				// the fixed vhd images, whose footer ends the data
+				klog.V(2).Infof("the data of %q has no %q header, converting it as selected by the extension\n", fr.name, format)
+				fr.Convert = true
+				if format == "vhd" {
+					fr.ConvertFormat = image.QemuFormatVhd
+				}
+			} else if ok && format != "" {
+				klog.Warningf("the extension of %q is the one of the %q format, but the data has no %q header\n", fr.name, format, format)
+			}
 			break // done processing headers, we have the orig source file
 		}
-		klog.V(2).Infof("found header of type %q\n", hdr.Format)
+		// strip the extension of the format, the next one is the format of the decompressed data
+		if image.FormatMatchesExtension(hdr.Format, fr.name) {
+			fr.name = strings.TrimSuffix(fr.name, path.Ext(fr.name))
+		} else {
+			if format, ok := image.FormatFromExtension(fr.name); ok && format != "" {
+				klog.Warningf("the extension of %q does not match the %q format found in the header, ignoring it\n", fr.name, hdr.Format)
+			}
+			fr.name = ""
+		}
 		// create format-specific reader and append it to dataStream readers stack
-		fr.fileFormatSelector(hdr)
-		// exit loop if hdr is qcow2
-		if hdr.Format == "qcow2" {
+		numRdrs := len(fr.readers)
+		if err = fr.fileFormatSelector(hdr); err != nil {
+			return errors.WithMessagef(fr.formatError(hdr.Format, err), "could not process %s format", hdr.Format)
+		}
+		fr.formats = append(fr.formats, hdr.Format)
+		// a format reader appended to the stack, or an archive imported from a file, is one more layer
+		if len(fr.readers) > numRdrs || hdr.Format == "zip" || hdr.Format == "7z" {
+			if err = fr.addLayer(hdr.Format); err != nil {
+				return err
+			}
+		}
+		// exit loop if hdr is qcow2, or if hdr is zip or 7z since the archive is only unpacked once
+		// it has been written to a file
+		if hdr.Format == "qcow2" || hdr.Format == "zip" || hdr.Format == "7z" {
 			break
 		}
 	}
@@ -128,6 +518,157 @@ func (fr *FormatReaders) constructReaders(r io.ReadCloser) error {
 	return nil
 }
 
+// addLayer records an archive or compression layer of the passed in format, and fails once there
+// are more layers than allowed.
+func (fr *FormatReaders) addLayer(format string) error {
+	fr.layers = append(fr.layers, format)
+	if len(fr.layers) > fr.maxLayers {
+		return ArchiveLayersError{Layers: fr.layers, Max: fr.maxLayers}
+	}
+	return nil
+}
+
+// maxArchiveLayers returns the maximum number of nested archive and compression layers.
+func maxArchiveLayers() (int, error) {
+	value, _ := util.ParseEnvVar(common.ImporterMaxArchiveLayers, false)
+	if value == "" {
+		return defaultMaxArchiveLayers, nil
+	}
+	max, err := strconv.Atoi(value)
+	if err != nil || max < 1 {
+		return 0, errors.Errorf("invalid %s value %q, a positive number of layers is expected", common.ImporterMaxArchiveLayers, value)
+	}
+	return max, nil
+}
+
+// maxXzMemory returns the maximum memory of the xz decoder, a share of the memory limit of the
+// container by default, 0 if there is no limit.
+func maxXzMemory() (int64, error) {
+	value, _ := util.ParseEnvVar(common.ImporterXzMemoryLimit, false)
+	if value == "" {
+		return util.GetMemoryLimit() / xzMemoryLimitRatio, nil
+	}
+	max, err := resource.ParseQuantity(value)
+	if err != nil || max.Sign() <= 0 {
+		return 0, errors.Errorf("invalid %s value %q, a positive quantity of memory is expected", common.ImporterXzMemoryLimit, value)
+	}
+	return max.Value(), nil
+}
+
+// nbdkitStream returns true if qemu-img can convert the data served by nbdkit from the source, read
+// at random by ra, rather than a copy of the data on scratch space, along with the nbdkit filter
+// decompressing the source, if any. size is the size of the source. Only an xz source made of small
+// enough blocks is decompressed: the gzip filter of nbdkit decompresses the whole source to a
+// temporary file, and the other formats have no filter.
+func (fr *FormatReaders) nbdkitStream(ra io.ReaderAt, size int64) (image.NbdkitFilter, bool) {
+	if fr.ConvertScratch || fr.FlattenChain {
+		return "", false
+	}
+	if !fr.Archived {
+		return "", true
+	}
+	if len(fr.layers) != 1 || fr.layers[0] != "xz" || size <= 0 {
+		return "", false
+	}
+	maxBlock, err := image.XzMaxBlockSize(ra, size)
+	if err != nil {
+		klog.Warningf("could not read the xz index of the source: %v\n", err)
+		return "", false
+	}
+	if maxBlock > nbdkitXzMaxBlockSize {
+		klog.V(1).Infof("xz block of %d bytes is too large for nbdkit to decompress", maxBlock)
+		return "", false
+	}
+	return image.NbdkitXzFilter, true
+}
+
+// FormatInfo returns the description of the formats found in the stream. The sizes of a seekable
+// source are read from it, so it must not be called while the data is read.
+func (fr *FormatReaders) FormatInfo() FormatInfo {
+	info := FormatInfo{
+		Formats:        append([]string{}, fr.formats...),
+		CompressedSize: fr.total,
+	}
+	ra, isReaderAt := fr.source.(io.ReaderAt)
+	seeker, isSeeker := fr.source.(io.Seeker)
+	info.Seekable = isReaderAt && isSeeker
+	if !info.Seekable {
+		return info
+	}
+	size, err := sourceSize(seeker)
+	if err != nil {
+		klog.Warningf("could not get the size of the source: %v\n", err)
+		return info
+	}
+	info.CompressedSize = uint64(size)
+	// the recorded size is the one of the data, unless it is in turn an archive or compressed
+	if len(fr.layers) != 1 {
+		return info
+	}
+	switch fr.layers[0] {
+	case "gz":
+		info.UncompressedSize, err = image.GzipUncompressedSize(ra, size)
+	case "xz":
+		info.UncompressedSize, err = image.XzUncompressedSize(ra, size)
+	}
+	if err != nil {
+		klog.Warningf("could not read the uncompressed size of the %s source: %v\n", fr.layers[0], err)
+	}
+	return info
+}
+
+// newDigestReader returns a reader computing the digest of the data read from r.
+func newDigestReader(r io.ReadCloser) *util.DigestReader {
+	return util.NewDigestReader(r, map[string]hash.Hash{digestAlgorithm: sha256.New()})
+}
+
+// Digests returns the digests of the data read from the source and of the data written by
+// StreamToFile. The source is not always read to its end, the padding following a tar entry is for
+// instance left unread, its digest is then empty.
+func (fr *FormatReaders) Digests() Digests {
+	var digests Digests
+	if fr.sourceDigest != nil {
+		digests.Source = fr.sourceDigest.Digests()[digestAlgorithm]
+	}
+	if fr.payloadDigest != nil {
+		digests.Payload = fr.payloadDigest.Digests()[digestAlgorithm]
+	}
+	return digests
+}
+
+// SourceFormat returns the format of the disk image read from the source, iso for an ISO9660 image,
+// empty otherwise.
+func (fr *FormatReaders) SourceFormat() string {
+	if fr.ISO {
+		return string(image.FormatISO)
+	}
+	return ""
+}
+
+// PayloadDigest returns the digest of the data written by StreamToFile followed by zeros up to size
+// bytes, the digest of the file written once extended to size. It is empty unless the data was read
+// to its end.
+func (fr *FormatReaders) PayloadDigest(size int64) string {
+	if fr.payloadDigest == nil {
+		return ""
+	}
+	return fr.payloadDigest.PaddedDigests(size)[digestAlgorithm]
+}
+
+// sourceSize returns the size of the source, and leaves the offset the source is read from unchanged.
+func sourceSize(s io.Seeker) (int64, error) {
+	current, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	size, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.Seek(current, io.SeekStart)
+	return size, err
+}
+
 // Append to the receiver's reader stack the passed in reader. If the reader type is multi-reader
 // then wrap a multi-reader around the passed in reader. If the reader is not a Closer then wrap a
 // nop closer.
@@ -146,7 +687,17 @@ func (fr *FormatReaders) appendReader(rType int, x interface{}) {
 	if _, ok := r.(io.Closer); !ok {
 		r = io.NopCloser(r)
 	}
-	fr.readers = append(fr.readers, reader{rdrType: rType, rdr: r.(io.ReadCloser)})
+	fr.readers = append(fr.readers, reader{rdrType: rType, rdr: fr.withContext(r.(io.ReadCloser))})
+}
+
+// withContext returns a reader checking the context of the receiver before every read, so that a
+// cancelled import stops within a buffer of data at every layer of the stack.
+func (fr *FormatReaders) withContext(r io.ReadCloser) io.ReadCloser {
+	// a context that is never done, as the one of NewFormatReaders, needs no checks
+	if fr.ctx == nil || fr.ctx.Done() == nil {
+		return r
+	}
+	return util.NewContextReader(fr.ctx, r)
 }
 
 // TopReader return the top-level io.ReadCloser from the receiver Reader "stack".
@@ -156,7 +707,7 @@ func (fr *FormatReaders) TopReader() io.ReadCloser {
 
 // Based on the passed in header, append the format-specific reader to the readers stack,
 // and update the receiver Size field. Note: a bool is set in the receiver for qcow2 files.
-func (fr *FormatReaders) fileFormatSelector(hdr *image.Header) {
+func (fr *FormatReaders) fileFormatSelector(hdr *image.Header) error {
 	var r io.Reader
 	var err error
 	fFmt := hdr.Format
@@ -170,42 +721,268 @@ func (fr *FormatReaders) fileFormatSelector(hdr *image.Header) {
 	case "qcow2":
 		r, err = fr.qcow2NopReader(hdr)
 		fr.Convert = true
+		if err == nil {
+			err = fr.checkQcow2()
+		}
 	case "xz":
 		r, err = fr.xzReader()
 		if err == nil {
 			fr.Archived = true
 			fr.ArchiveXz = true
 		}
+	case "lzma":
+		r, err = fr.lzmaReader()
+		if err == nil {
+			fr.Archived = true
+			fr.ArchiveLzma = true
+		}
+	case "zst":
+		r, err = fr.zstdReader()
+		if err == nil {
+			fr.Archived = true
+			fr.ArchiveZstd = true
+		}
+	case "bz2":
+		r = fr.bz2Reader()
+		fr.Archived = true
+		fr.ArchiveBz2 = true
+	case "lz4":
+		r = fr.lz4Reader()
+		fr.Archived = true
+		fr.ArchiveLz4 = true
+	case "lz4-legacy":
+		return ErrLz4LegacyFormat
+	case "tar":
+		// a tar archive is imported as is, unless an entry to extract from it was named or it is an
+		// OVA appliance, whose disk is extracted
+		if fr.archiveEntry == "" {
+			err = fr.selectOvaDisk()
+		}
+		if err == nil && fr.archiveEntry != "" && fr.FlattenChain {
+			// the backing files of the entry may precede it in the archive, it is spooled to scratch
+			// space and the entries are extracted from there, see StreamToFile.
+			r = nil
+			fr.Archived = true
+			fr.ArchiveTar = true
+			fr.Convert = true
+			fr.spoolTar = true
+		} else if err == nil && fr.archiveEntry != "" {
+			r, err = fr.tarReader()
+			if err == nil {
+				fr.Archived = true
+				fr.ArchiveTar = true
+			}
+		}
+	case "cpio", "cpio-crc":
+		r, err = image.NewCpioReader(fr.TopReader())
+		if err == nil {
+			fr.Archived = true
+			fr.ArchiveCpio = true
+		}
+	case "zip":
+		// zip needs random access to read its central directory, so the archive is spooled to
+		// scratch space and converted from there, see StreamToFile.
+		r = nil
+		fr.Archived = true
+		fr.ArchiveZip = true
+		fr.Convert = true
+	case "7z":
+		// 7z stores its header at the end of the archive, so it is handled like zip.
+		r = nil
+		fr.Archived = true
+		fr.Archive7z = true
+		fr.Convert = true
 	case "vmdk":
 		r = nil
 		fr.Convert = true
+	case "vmdk-descriptor":
+		return VmdkExtentsError{Extents: image.VmdkDescriptorExtents(fr.buf)}
 	case "vdi":
 		r = nil
 		fr.Convert = true
+		// qemu-img reads the block map of a vdi image at the start of the file, and the blocks at
+		// random offsets
+		fr.ConvertScratch = true
+		err = fr.checkVdi()
 	case "vhd":
 		r = nil
 		fr.Convert = true
+		fr.ConvertFormat = image.QemuFormatVhd
 	case "vhdx":
 		r = nil
 		fr.Convert = true
+		// qemu-img reads the block allocation table and the log of a vhdx image at random offsets
+		fr.ConvertScratch = true
+		err = fr.checkVhdx()
+	case "qed":
+		r = nil
+		fr.Convert = true
+		fr.ConvertFormat = image.QemuFormatQed
+	case "parallels":
+		r = nil
+		fr.Convert = true
+		fr.ConvertFormat = image.QemuFormatParallels
 	}
 	if err == nil && r != nil {
-		fr.appendReader(rdrTypM[fFmt], r)
+		fr.appendReader(rdrTypM[fFmt], fr.classifyErrors(fFmt, r))
 	}
+	return err
+}
+
+// isISO9660 returns true if the data is an ISO9660 image, whose first volume descriptor follows
+// the 32 KiB of its system area.
+func (fr *FormatReaders) isISO9660() (bool, error) {
+	if len(fr.layers) == 0 && fr.total > 0 && fr.total < uint64(image.ISO9660HeaderSize) {
+		// the source is too small to be one, it is not read ahead
+		return false, nil
+	}
+	data, err := fr.peek(image.ISO9660HeaderSize)
+	if err != nil {
+		return false, err
+	}
+	return image.IsISO9660(data), nil
+}
+
+// checkQcow2 checks the header of the qcow2 image ahead of its conversion, and fails for an image
+// whose data is in an external data file or with incompatible features unknown to qemu.
+func (fr *FormatReaders) checkQcow2() error {
+	data, err := fr.peek(image.Qcow2HeaderCheckSize)
+	if err != nil {
+		return err
+	}
+	return image.CheckQcow2Header(data)
+}
+
+// checkVdi checks the header of the vdi image ahead of its conversion, and fails for a differencing
+// image: its parent is not imported along with it.
+func (fr *FormatReaders) checkVdi() error {
+	hdr, err := image.ParseVdiHeader(fr.buf)
+	if err != nil {
+		return err
+	}
+	if hdr.Type == image.VdiImageDiff || hdr.HasParent {
+		return image.NewFormatError(image.ErrUnsupportedFormat, "vdi",
+			errors.New("differencing vdi image references a parent image, which is not imported along with it, merge the image into its parent first"))
+	}
+	klog.V(1).Infof("vdi: image type %d, disk size %d\n", hdr.Type, hdr.DiskSize)
+	return nil
+}
+
+// checkVhdx reads the metadata of the vhdx image ahead of its conversion, and fails for a
+// differencing image: its parent is not imported along with it. The metadata is not checked when it
+// is beyond the first vhdxMaxMetadataEnd bytes of the image, qemu-img then fails on a differencing
+// image.
+func (fr *FormatReaders) checkVhdx() error {
+	hdr, err := fr.peek(image.VhdxRegionTablesEnd)
+	if err != nil {
+		return err
+	}
+	offset, length, err := image.VhdxMetadataRegion(hdr)
+	if err != nil {
+		return err
+	}
+	end := offset + length
+	if end > vhdxMaxMetadataEnd {
+		klog.Warningf("the metadata of the vhdx image ends at offset %d, it is not checked before the conversion\n", end)
+		return nil
+	}
+	data, err := fr.peek(int(end))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) < end {
+		return io.ErrUnexpectedEOF
+	}
+	params, err := image.ParseVhdxMetadata(data[offset:end])
+	if err != nil {
+		return err
+	}
+	if params.HasParent {
+		return image.NewFormatError(image.ErrUnsupportedFormat, "vhdx",
+			errors.New("differencing vhdx image references a parent image, which is not imported along with it, merge the image into its parent first"))
+	}
+	klog.V(1).Infof("vhdx: virtual size %d, logical sector size %d\n", params.VirtualSize, params.LogicalSectorSize)
+	if params.LogicalSectorSize != 512 {
+		klog.Infof("the vhdx image has logical sectors of %d bytes, the imported disk has to be attached with the same logical block size\n", params.LogicalSectorSize)
+	}
+	return nil
+}
+
+// peek returns the next n bytes of the data, less at the end of the data, and reads them again
+// from the top reader. The top reader is replaced rather than stacked, it is not one more layer.
+func (fr *FormatReaders) peek(n int) ([]byte, error) {
+	top := &fr.readers[len(fr.readers)-1]
+	buf := make([]byte, n)
+	read, err := io.ReadFull(top.rdr, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	buf = buf[:read]
+	top.rdr = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), top.rdr), top.rdr}
+	return buf, nil
+}
+
+// classifyErrors returns the reader of the passed in format, its errors turned into FormatErrors,
+// see formatError.
+func (fr *FormatReaders) classifyErrors(format string, r io.Reader) io.ReadCloser {
+	rc, ok := r.(io.ReadCloser)
+	if !ok {
+		rc = io.NopCloser(r)
+	}
+	return &formatErrorReader{ReadCloser: rc, format: format, fr: fr}
+}
+
+// formatError returns err, an error of the reader of the passed in format, as a FormatError of the
+// matching kind. Errors that are not caused by the data are returned as is: the failures of the
+// source, which may not occur on a retry, a cancelled context, and the errors already classified by
+// the reader of an inner layer.
+func (fr *FormatReaders) formatError(format string, err error) error {
+	var formatErr *image.FormatError
+	switch {
+	case errors.As(err, &formatErr), errors.Is(err, image.ErrUnsupportedFormat):
+		return err
+	case fr.compressed != nil && fr.compressed.err != nil:
+		return err
+	case fr.ctx != nil && fr.ctx.Err() != nil, errors.Is(err, ErrDecompressedTooLarge):
+		return err
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return image.NewFormatError(image.ErrTruncatedStream, format, err)
+	}
+	for _, unsupported := range unsupportedErrors {
+		if errors.Is(err, unsupported) {
+			return image.NewFormatError(image.ErrUnsupportedFormat, format, err)
+		}
+	}
+	return image.NewFormatError(image.ErrCorruptArchive, format, err)
+}
+
+// formatErrorReader classifies the errors of the reader of a format.
+type formatErrorReader struct {
+	io.ReadCloser
+	format string
+	fr     *FormatReaders
+}
+
+func (r *formatErrorReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = r.fr.formatError(r.format, err)
+	}
+	return n, err
 }
 
 // Return the gz reader and the size of the endpoint "through the eye" of the previous reader.
-// Assumes a single file was gzipped.
+// Assumes a single file was gzipped. The file may be made of several gzip members, as written by
+// concatenating gzip files or by parallel compressors, they are decoded as a single stream. The
+// stream is decoded in parallel unless the source is known to be small.
 //NOTE: size in gz is stored in the last 4 bytes of the file. This probably requires the file
 //  to be decompressed in order to get its original size. For now 0 is returned.
 //TODO: support gz size.
 func (fr *FormatReaders) gzReader() (io.ReadCloser, error) {
-	gz, err := gzip.NewReader(fr.TopReader())
-	if err != nil {
-		return nil, errors.Wrap(err, "could not create gzip reader")
-	}
-	klog.V(2).Infof("gzip: extracting %q\n", gz.Name)
-	return gz, nil
+	return image.NewGzipReader(fr.TopReader(), fr.total)
 }
 
 // Return the size of the endpoint "through the eye" of the previous reader. Note: there is no
@@ -227,6 +1004,12 @@ func (fr *FormatReaders) qcow2NopReader(h *image.Header) (io.Reader, error) {
 //  order to get its original size. For now 0 is returned.
 //TODO: support gz size.
 func (fr *FormatReaders) xzReader() (io.Reader, error) {
+	if fr.maxXzMemory > 0 {
+		// an invalid header is reported by the decoder
+		if dictSize, err := image.XzDictionarySize(fr.buf); err == nil && dictSize > fr.maxXzMemory {
+			return nil, XzMemoryLimitError{Required: dictSize, Allowed: fr.maxXzMemory}
+		}
+	}
 	xz, err := xz.NewReader(fr.TopReader())
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create xz reader")
@@ -234,25 +1017,187 @@ func (fr *FormatReaders) xzReader() (io.Reader, error) {
 	return xz, nil
 }
 
+// Return the lzma reader and size of the endpoint "through the eye" of the previous reader.
+// Assumes a single file was compressed using the legacy LZMA_alone format, which predates xz.
+func (fr *FormatReaders) lzmaReader() (io.Reader, error) {
+	lz, err := lzma.NewReader(fr.TopReader())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create lzma reader")
+	}
+	return lz, nil
+}
+
+// Return the zstd reader and size of the endpoint "through the eye" of the previous reader.
+// Assumes a single file was compressed. Note: the zstd decoder's Close does not return an
+// error, so the io.ReadCloser wrapper provided by the decoder is returned instead.
+//NOTE: the frame content size is optional in the zstd header and is not used. For now 0 is returned.
+func (fr *FormatReaders) zstdReader() (io.ReadCloser, error) {
+	zst, err := zstd.NewReader(fr.TopReader())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create zstd reader")
+	}
+	return zst.IOReadCloser(), nil
+}
+
+// Return the bzip2 reader and size of the endpoint "through the eye" of the previous reader.
+// Assumes a single file was compressed. Note: the bzip2 reader is not a closer, appendReader
+// wraps a nop Closer around it.
+//NOTE: size is not stored in the bzip2 header. For now 0 is returned.
+func (fr *FormatReaders) bz2Reader() io.Reader {
+	return bzip2.NewReader(fr.TopReader())
+}
+
+// Return the lz4 reader and size of the endpoint "through the eye" of the previous reader.
+// Assumes a single file was compressed using the lz4 frame format. Note: the lz4 reader is
+// not a closer, appendReader wraps a nop Closer around it.
+//NOTE: the content size is optional in the lz4 frame descriptor and is not used. For now 0 is returned.
+func (fr *FormatReaders) lz4Reader() io.Reader {
+	return lz4.NewReader(fr.TopReader())
+}
+
+// Return the tar reader positioned at the entry named by the receiver's archiveEntry. Directory
+// entries and extended headers are skipped while scanning the archive.
+func (fr *FormatReaders) tarReader() (io.Reader, error) {
+	stored := &byteCounter{ReadCloser: fr.TopReader()}
+	tr := tar.NewReader(stored)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read tar header")
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		if path.Clean(hdr.Name) == path.Clean(fr.archiveEntry) {
+			klog.V(2).Infof("tar: extracting %q\n", hdr.Name)
+			if isSparseTarEntry(hdr) {
+				fr.sparseEntry = &sparseTarReader{Reader: tr, name: hdr.Name, size: hdr.Size, stored: stored, start: stored.count()}
+				return fr.sparseEntry, nil
+			}
+			return tr, nil
+		}
+		names = append(names, hdr.Name)
+	}
+	_, err := selectArchiveEntry("tar", names, fr.archiveEntry)
+	return nil, err
+}
+
+// selectOvaDisk names the disk of an OVA appliance, a tar archive starting with its OVF descriptor,
+// as the entry to extract. Other tar archives are left alone.
+func (fr *FormatReaders) selectOvaDisk() error {
+	data, err := fr.peek(ovaMaxDescriptorEnd)
+	if err != nil {
+		return errors.Wrap(err, "could not read the start of the tar archive")
+	}
+	tr := tar.NewReader(bytes.NewReader(data))
+	hdr, err := tr.Next()
+	if err != nil || !image.IsOvfDescriptor(hdr.Name) {
+		return nil
+	}
+	descriptor, err := io.ReadAll(tr)
+	if err != nil {
+		return image.NewFormatError(image.ErrUnsupportedFormat, "ova",
+			errors.Errorf("ovf descriptor %q of %d bytes is larger than %d bytes", hdr.Name, hdr.Size, ovaMaxDescriptorEnd))
+	}
+	disks, err := image.OvfDisks(descriptor)
+	if err != nil {
+		return image.NewFormatError(image.ErrCorruptArchive, "ova", err)
+	}
+	if len(disks) > 1 {
+		return OvaDisksError{Disks: disks}
+	}
+	klog.V(2).Infof("ova: importing the disk %q referenced by the ovf descriptor %q\n", disks[0], hdr.Name)
+	fr.archiveEntry = disks[0]
+	return nil
+}
+
+// isSparseTarEntry returns true if the tar entry is a sparse file, in the old GNU format or in one of
+// the GNU PAX formats.
+func isSparseTarEntry(hdr *tar.Header) bool {
+	if hdr.Typeflag == tar.TypeGNUSparse {
+		return true
+	}
+	for key := range hdr.PAXRecords {
+		if strings.HasPrefix(key, "GNU.sparse.") {
+			return true
+		}
+	}
+	return false
+}
+
+// sparseTarReader reads a sparse tar entry. The tar reader interprets the sparse map of the entry and
+// reads the holes as zeros, they are skipped once the entry is written to a file.
+type sparseTarReader struct {
+	io.Reader
+	name         string
+	size         int64
+	stored       *byteCounter // counts the bytes of the tar archive read
+	start        uint64       // bytes of the tar archive read before the data of the entry
+	holesSkipped bool
+	logged       bool
+}
+
+func (r *sparseTarReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF && !r.holesSkipped && !r.logged {
+		r.logged = true
+		stored := r.stored.count() - r.start
+		if stored > 0 {
+			klog.Infof("tar: the holes of the sparse entry %q were streamed as zeros, %d bytes stored inflated to %d bytes, %.1f times\n",
+				r.name, stored, r.size, float64(r.size)/float64(stored))
+		}
+	}
+	return n, err
+}
+
+// streamToFile writes the entry, read from src, to the passed in file, skipping over the holes.
+func (r *sparseTarReader) streamToFile(src io.Reader, fileName string) error {
+	r.holesSkipped = true
+	skipped, err := util.StreamSparseDataToFile(src, fileName)
+	if err != nil {
+		return err
+	}
+	klog.V(1).Infof("tar: skipped %d bytes of zeros of the sparse entry %q of %d bytes\n", skipped, r.name, r.size)
+	return nil
+}
+
 // Return the matching header, if one is found, from the passed-in map of known headers. After a
 // successful read append a multi-reader to the receiver's reader stack.
-// Note: .iso files are not detected here but rather in the Size() function.
+// Note: ISO9660 images are not detected here but rather in isISO9660, their header is beyond buf.
 // Note: knownHdrs is passed by reference and modified.
 func (fr *FormatReaders) matchHeader(knownHdrs *image.Headers) (*image.Header, error) {
 	_, err := fr.read(fr.buf) // read current header
 	if err != nil {
 		return nil, err
 	}
-	// append multi-reader so that the header data can be re-read by subsequent readers
-	fr.appendReader(rdrMulti, bytes.NewReader(fr.buf))
+	// append multi-reader so that the header data can be re-read by subsequent readers. The
+	// multi-reader gets its own copy of the header, since buf is overwritten by the next header
+	// read while a decompressor, like zstd, may not have consumed all of the previous one yet.
+	fr.appendReader(rdrMulti, bytes.NewReader(append([]byte(nil), fr.buf...)))
 
 	// loop through known headers until a match
 	for format, kh := range *knownHdrs {
-		if kh.Match(fr.buf) {
-			// delete this header format key so that it's not processed again
-			delete(*knownHdrs, format)
-			return &kh, nil
+		if !kh.Match(fr.buf) {
+			continue
+		}
+		_, knownExt := image.FormatFromExtension(fr.name)
+		if kh.Ambiguous() && knownExt {
+			// fall back to the extension to confirm the match
+			if !image.FormatMatchesExtension(kh.Format, fr.name) {
+				klog.V(2).Infof("ignoring the %q header, the extension of %q is not the one of the format\n", kh.Format, fr.name)
+				continue
+			}
+			klog.V(2).Infof("format %q detected from the header, confirmed by the extension of %q\n", kh.Format, fr.name)
+		} else {
+			klog.V(2).Infof("format %q detected from the header\n", kh.Format)
 		}
+		// delete this header format key so that it's not processed again
+		delete(*knownHdrs, format)
+		return &kh, nil
 	}
 	return nil, nil // no match
 }
@@ -266,6 +1211,10 @@ func (fr *FormatReaders) read(buf []byte) (int, error) {
 // Close Readers in reverse order.
 func (fr *FormatReaders) Close() (rtnerr error) {
 	var err error
+	if fr.progressDone != nil {
+		close(fr.progressDone)
+		fr.progressDone = nil
+	}
 	for i := len(fr.readers) - 1; i >= 0; i-- {
 		err = fr.readers[i].rdr.Close()
 		if err != nil {
@@ -275,9 +1224,165 @@ func (fr *FormatReaders) Close() (rtnerr error) {
 	return rtnerr
 }
 
+// SetMaxDecompressedSize limits the size of the data of a compressed or archived stream to max
+// bytes, reading more fails with ErrDecompressedTooLarge. This stops a small compressed file from
+// filling the target, or the scratch space, before the image is validated.
+func (fr *FormatReaders) SetMaxDecompressedSize(max int64) {
+	if !fr.Archived || max <= 0 {
+		return
+	}
+	fr.maxSize = max
+	// zip and 7z are decompressed once spooled to a file, see StreamToFile
+	if !fr.spooled() {
+		fr.appendReader(rdrSizeLimit, fr.limitSize(fr.TopReader()))
+	}
+}
+
+// spooled returns true if the archive is spooled to a file before its entry is extracted, see
+// StreamToFile.
+func (fr *FormatReaders) spooled() bool {
+	return fr.ArchiveZip || fr.Archive7z || fr.spoolTar
+}
+
+// ValidateSourceSize fails with a ValidationSizeError when the data is written as is to the target,
+// neither compressed, archived nor converted, and the size of the source passed in to
+// NewFormatReaders is larger than max bytes. The import then fails before any data is transferred.
+func (fr *FormatReaders) ValidateSourceSize(max int64) error {
+	if max <= 0 || fr.total == 0 || fr.Archived || fr.Convert || fr.total <= uint64(max) {
+		return nil
+	}
+	return ValidationSizeError{err: errors.Errorf("source of %d bytes is larger than the target of %d bytes", fr.total, max)}
+}
+
+// limitSize returns a reader failing with ErrDecompressedTooLarge once more than the maximum size of
+// the decompressed data is read from r.
+func (fr *FormatReaders) limitSize(r io.Reader) io.Reader {
+	if fr.maxSize <= 0 {
+		return r
+	}
+	return &sizeLimitReader{r: r, remaining: fr.maxSize}
+}
+
+// sizeLimitReader reads from r, failing with ErrDecompressedTooLarge once more than remaining bytes
+// are read.
+type sizeLimitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	// read one byte past the limit to tell data of exactly the maximum size from larger data
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		n = int(l.remaining)
+		l.remaining = 0
+		return n, ErrDecompressedTooLarge
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
+
 // StartProgressUpdate starts the go routine to automatically update the progress on a set interval.
+// Without the size of the source the progress of compressed data is estimated from the size of the
+// decompressed data, relative to its maximum size.
 func (fr *FormatReaders) StartProgressUpdate() {
 	if fr.progressReader != nil {
 		fr.progressReader.StartTimedUpdate()
 	}
+	if fr.total == 0 && fr.Archived && fr.maxSize > 0 {
+		fr.SetProgressCallback(time.Second, fr.updateDecompressedProgress)
+	}
+}
+
+// SetProgressMax sets the progress reported once the data is read, 100 by default. It is lower when
+// the data is converted next, the conversion then reports the rest of the progress.
+func (fr *FormatReaders) SetProgressMax(max float64) {
+	fr.progressMax = max
+	if fr.progressReader != nil {
+		fr.progressReader.SetMax(max)
+	}
+}
+
+func (fr *FormatReaders) updateDecompressedProgress(compressedRead, decompressedRead uint64) {
+	max := fr.progressMax
+	if max == 0 {
+		max = 100.0
+	}
+	// the image may be smaller than its maximum size, it is only complete once the import is
+	currentProgress := float64(decompressedRead) / float64(fr.maxSize) * max
+	if currentProgress > max*0.99 {
+		currentProgress = max * 0.99
+	}
+	prometheusutil.SetProgress(progress, ownerUID, currentProgress)
+	klog.V(1).Infof("%.2f, %d bytes read, %d bytes decompressed", currentProgress, compressedRead, decompressedRead)
+}
+
+// SetProgressCallback calls fn every interval with the number of bytes read from the source and the
+// number of bytes of decompressed data read, until the readers are closed. The bytes of zip and 7z
+// archives are counted as they are extracted, see StreamToFile.
+func (fr *FormatReaders) SetProgressCallback(interval time.Duration, fn ProgressFunc) {
+	if fr.progressDone != nil || fr.compressed == nil {
+		return
+	}
+	fr.decompressed = &byteCounter{}
+	if !fr.spooled() {
+		fr.decompressed.ReadCloser = fr.TopReader()
+		fr.appendReader(rdrProgress, fr.decompressed)
+	}
+	fr.progressDone = make(chan struct{})
+	go fr.pollProgress(interval, fn, fr.progressDone)
+}
+
+func (fr *FormatReaders) pollProgress(interval time.Duration, fn ProgressFunc, done <-chan struct{}) {
+	var ctxDone <-chan struct{}
+	if fr.ctx != nil {
+		ctxDone = fr.ctx.Done()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			fn(fr.compressed.count(), fr.decompressed.count())
+		case <-done:
+			fn(fr.compressed.count(), fr.decompressed.count())
+			return
+		case <-ctxDone:
+			return
+		}
+	}
+}
+
+// countDecompressed returns r counting the bytes read in the decompressed bytes of the progress
+// callback, if any.
+func (fr *FormatReaders) countDecompressed(r io.ReadCloser) io.ReadCloser {
+	if fr.decompressed == nil || fr.decompressed.ReadCloser != nil {
+		return r
+	}
+	fr.decompressed.ReadCloser = r
+	return fr.decompressed
+}
+
+// byteCounter counts the bytes read, the count can be read while reading.
+type byteCounter struct {
+	io.ReadCloser
+	n uint64
+	// err is the last error other than io.EOF
+	err error
+}
+
+func (c *byteCounter) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	atomic.AddUint64(&c.n, uint64(n))
+	if err != nil && err != io.EOF {
+		c.err = err
+	}
+	return n, err
+}
+
+func (c *byteCounter) count() uint64 {
+	return atomic.LoadUint64(&c.n)
 }
@@ -1,32 +1,60 @@
 package importer
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"testing/iotest"
+	"time"
 
+	"github.com/klauspost/compress/zstd"
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/tests/utils"
 )
 
 var (
-	archiveFileName           = "archive.tar"
-	imageDir, _               = filepath.Abs(TestImagesDir)
-	tinyCoreFileName          = "tinyCore.iso"
-	tinyCoreFilePath          = filepath.Join(imageDir, tinyCoreFileName)
-	tinyCoreXzFilePath, _     = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtXz)
-	tinyCoreGzFilePath, _     = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtGz)
-	tinyCoreTarFilePath, _    = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtTar)
-	archiveFilePath, _        = utils.ArchiveFiles(archiveFileNameWithoutExt, os.TempDir(), tinyCoreFilePath, cirrosFilePath)
-	archiveFileNameWithoutExt = strings.TrimSuffix(archiveFileName, filepath.Ext(archiveFileName))
-	cirrosFilePath            = filepath.Join(imageDir, cirrosFileName)
-	stringRdr                 = strings.NewReader("test data for reader 1")
+	archiveFileName            = "archive.tar"
+	imageDir, _                = filepath.Abs(TestImagesDir)
+	tinyCoreFileName           = "tinyCore.iso"
+	tinyCoreFilePath           = filepath.Join(imageDir, tinyCoreFileName)
+	tinyCoreXzFilePath, _      = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtXz)
+	tinyCoreGzFilePath, _      = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtGz)
+	tinyCoreLzmaFilePath, _    = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtLzma)
+	tinyCoreZstdFilePath, _    = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtZstd)
+	tinyCoreBz2FilePath, _     = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtBz2)
+	tinyCoreTarBz2FilePath, _  = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtTar, image.ExtBz2)
+	tinyCoreLz4FilePath, _     = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtLz4)
+	tinyCoreBrotliFilePath, _  = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtBrotli)
+	cirrosBrotliFilePath, _    = utils.FormatTestData(cirrosFilePath, os.TempDir(), image.ExtBrotli)
+	tinyCore5LayersFilePath, _ = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtXz, image.ExtLzma, image.ExtGz, image.ExtZstd, image.ExtLz4)
+	tinyCoreTarLz4FilePath, _  = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtTar, image.ExtLz4)
+	tinyCoreTarFilePath, _     = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtTar)
+	tinyCoreCpioFilePath, _    = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtCpio)
+	tinyCoreCpioGzFilePath, _  = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtCpio, image.ExtGz)
+	tinyCoreZipFilePath, _     = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtZip)
+	tinyCore7zFilePath, _      = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.Ext7z)
+	tinyCoreTarZstdFilePath, _ = utils.FormatTestData(tinyCoreFilePath, os.TempDir(), image.ExtTar, image.ExtZstd)
+	archiveFilePath, _         = utils.ArchiveFiles(archiveFileNameWithoutExt, os.TempDir(), tinyCoreFilePath, cirrosFilePath)
+	archiveFileNameWithoutExt  = strings.TrimSuffix(archiveFileName, filepath.Ext(archiveFileName))
+	cirrosFilePath             = filepath.Join(imageDir, cirrosFileName)
+	tinyCoreVdiFilePath        = filepath.Join(imageDir, "tinyCore.vdi")
+	stringRdr                  = strings.NewReader("test data for reader 1")
 )
 
 var _ = Describe("Format Readers", func() {
@@ -61,6 +89,17 @@ var _ = Describe("Format Readers", func() {
 	},
 		table.Entry("successfully construct a xz reader", tinyCoreXzFilePath, 4, false, true, false),              // [stream, multi-r, xz, multi-r] convert = false
 		table.Entry("successfully construct a gz reader", tinyCoreGzFilePath, 4, false, true, false),              // [stream, multi-r, gz, multi-r] convert = false
+		table.Entry("successfully construct a lzma reader", tinyCoreLzmaFilePath, 4, false, true, false),          // [stream, multi-r, lzma, multi-r] convert = false
+		table.Entry("successfully construct a zstd reader", tinyCoreZstdFilePath, 4, false, true, false),          // [stream, multi-r, zst, multi-r] convert = false
+		table.Entry("successfully construct a tar zstd reader", tinyCoreTarZstdFilePath, 5, false, true, false),   // [stream, multi-r, zst, multi-r, multi-r] convert = false
+		table.Entry("successfully construct a bz2 reader", tinyCoreBz2FilePath, 4, false, true, false),            // [stream, multi-r, bz2, multi-r] convert = false
+		table.Entry("successfully construct a tar bz2 reader", tinyCoreTarBz2FilePath, 5, false, true, false),     // [stream, multi-r, bz2, multi-r, multi-r] convert = false
+		table.Entry("successfully construct a lz4 reader", tinyCoreLz4FilePath, 4, false, true, false),            // [stream, multi-r, lz4, multi-r] convert = false
+		table.Entry("successfully construct a tar lz4 reader", tinyCoreTarLz4FilePath, 5, false, true, false),     // [stream, multi-r, lz4, multi-r, multi-r] convert = false
+		table.Entry("successfully construct a cpio reader", tinyCoreCpioFilePath, 4, false, true, false),          // [stream, multi-r, cpio, multi-r] convert = false
+		table.Entry("successfully construct a cpio gz reader", tinyCoreCpioGzFilePath, 6, false, true, false),     // [stream, multi-r, gz, multi-r, cpio, multi-r] convert = false
+		table.Entry("successfully return the base reader when zipped", tinyCoreZipFilePath, 2, false, true, true), // [stream, multi-r] convert = true
+		table.Entry("successfully return the base reader for 7z", tinyCore7zFilePath, 2, false, true, true),       // [stream, multi-r] convert = true
 		table.Entry("successfully return the base reader when archived", archiveFilePath, 3, false, false, false), // [stream, multi-r, multi-r] convert = false
 		table.Entry("successfully construct qcow2 reader", cirrosFilePath, 2, false, false, true),                 // [stream, multi-r] convert = true
 		table.Entry("successfully construct .iso reader", tinyCoreFilePath, 2, false, false, false),               // [stream, multi-r] convert = false
@@ -90,13 +129,747 @@ var _ = Describe("Format Readers", func() {
 		table.Entry("should append io.Multireader", rdrMulti, stringRdr, 3, false),
 	)
 
-	It("should not crash on no progress reader", func() {
+	It("should detect the tar header in a tar.zst made of small frames", func() {
+		tarData, err := os.ReadFile(tinyCoreTarFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		enc, err := zstd.NewWriter(nil)
+		Expect(err).ToNot(HaveOccurred())
+		// split the data so the tar header spans several zstd frames
+		var data []byte
+		for _, chunk := range [][]byte{tarData[:100], tarData[100:300], tarData[300:]} {
+			data = enc.EncodeAll(chunk, data)
+		}
+		Expect(enc.Close()).To(Succeed())
+
+		fr, err = NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.readers).To(HaveLen(5)) // [stream, multi-r, zst, multi-r, multi-r]
+		Expect(fr.readers[2].rdrType).To(Equal(rdrZstd))
+		Expect(fr.ArchiveZstd).To(BeTrue())
+
+		tr := tar.NewReader(fr.TopReader())
+		hdr, err := tr.Next()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hdr.Name).To(Equal(tinyCoreFileName))
+		content, err := io.ReadAll(tr)
+		Expect(err).ToNot(HaveOccurred())
+		expected, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(expected))
+	})
+
+	table.DescribeTable("can extract an entry from a tar archive", func(filename, entry string, numRdrs int, convert bool) {
+		os.Setenv(common.ImporterArchiveEntry, entry)
+		defer os.Unsetenv(common.ImporterArchiveEntry)
+		f, err := os.Open(filename)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.readers).To(HaveLen(numRdrs))
+		Expect(fr.readers[2].rdrType).To(Equal(rdrTar))
+		Expect(fr.Archived).To(BeTrue())
+		Expect(fr.ArchiveTar).To(BeTrue())
+		Expect(fr.Convert).To(Equal(convert))
+	},
+		table.Entry("should extract the only file of a tar", tinyCoreTarFilePath, tinyCoreFileName, 4, false),       // [stream, multi-r, tar, multi-r] convert = false
+		table.Entry("should extract a qcow2 image from a multi-file tar", archiveFilePath, cirrosFileName, 4, true), // [stream, multi-r, tar, multi-r] convert = true
+		table.Entry("should match an entry name with a leading ./", archiveFilePath, "./"+tinyCoreFileName, 4, false),
+	)
+
+	It("should fail extracting a missing entry from a tar archive", func() {
+		os.Setenv(common.ImporterArchiveEntry, "missing.img")
+		defer os.Unsetenv(common.ImporterArchiveEntry)
+		f, err := os.Open(archiveFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		_, err = NewFormatReaders(f, uint64(0))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`tar archive does not contain "missing.img"`))
+		Expect(err.Error()).To(ContainSubstring(tinyCoreFileName))
+		Expect(err.Error()).To(ContainSubstring(cirrosFileName))
+	})
+
+	It("should extract the disk of an OVA appliance", func() {
+		disk := append([]byte("KDMV"), make([]byte, 2*image.MaxExpectedHdrSize)...)
+		var err error
+		fr, err = NewFormatReaders(io.NopCloser(bytes.NewReader(ovaData(disk, "disk1.vmdk"))), uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Archived).To(BeTrue())
+		Expect(fr.ArchiveTar).To(BeTrue())
+		Expect(fr.Convert).To(BeTrue())
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(disk))
+	})
+
+	It("should fail on an OVA appliance of more than one disk", func() {
+		data := ovaData([]byte("disk image"), "disk1.vmdk", "disk2.vmdk")
+		_, err := NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(0))
+		var disksErr OvaDisksError
+		Expect(errors.As(err, &disksErr)).To(BeTrue())
+		Expect(disksErr.Disks).To(Equal([]string{"disk1.vmdk", "disk2.vmdk"}))
+		Expect(errors.Is(err, image.ErrUnsupportedFormat)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("cdi.kubevirt.io/archiveEntry annotation"))
+	})
+
+	It("should extract the selected disk of an OVA appliance", func() {
+		os.Setenv(common.ImporterArchiveEntry, "disk2.vmdk")
+		defer os.Unsetenv(common.ImporterArchiveEntry)
+		disk := bytes.Repeat([]byte("disk image"), image.MaxExpectedHdrSize)
+		data := ovaData(disk, "disk1.vmdk", "disk2.vmdk")
+		var err error
+		fr, err = NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.ArchiveTar).To(BeTrue())
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(disk))
+	})
+
+	It("should fail on an OVA appliance with an invalid descriptor", func() {
+		var b bytes.Buffer
+		tw := tar.NewWriter(&b)
+		Expect(tw.WriteHeader(&tar.Header{Name: "appliance.ovf", Mode: 0644, Size: 10})).To(Succeed())
+		_, err := tw.Write([]byte("<Envelope>"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tw.Close()).To(Succeed())
+		_, err = NewFormatReaders(io.NopCloser(bytes.NewReader(b.Bytes())), uint64(0))
+		Expect(errors.Is(err, image.ErrCorruptArchive)).To(BeTrue())
+	})
+
+	It("should decompress all the members of a multistream gzip file", func() {
+		expected, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		// the same as `cat first.gz second.gz`
+		var data bytes.Buffer
+		for _, half := range [][]byte{expected[:len(expected)/2], expected[len(expected)/2:]} {
+			w := gzip.NewWriter(&data)
+			_, err = w.Write(half)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+		}
+
+		fr, err = NewFormatReaders(io.NopCloser(&data), uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.ArchiveGz).To(BeTrue())
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(HaveLen(len(expected)))
+		Expect(content).To(Equal(expected))
+	})
+
+	table.DescribeTable("can construct readers for a brotli file", func(filename string, convert bool) {
+		f, err := os.Open(filename)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = newFormatReaders(context.Background(), f, uint64(0), filename)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.readers).To(HaveLen(3)) // [stream, br, multi-r]
+		Expect(fr.readers[1].rdrType).To(Equal(rdrBrotli))
+		Expect(fr.Archived).To(BeTrue())
+		Expect(fr.ArchiveBrotli).To(BeTrue())
+		Expect(fr.Convert).To(Equal(convert))
+	},
+		table.Entry("should decompress an iso", tinyCoreBrotliFilePath, false),
+		table.Entry("should detect a qcow2 image once decompressed", cirrosBrotliFilePath, true),
+	)
+
+	It("should stream the content of a brotli file", func() {
+		f, err := os.Open(tinyCoreBrotliFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = newFormatReaders(context.Background(), f, uint64(0), tinyCoreBrotliFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		expected, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(expected))
+	})
+
+	table.DescribeTable("can fall back to the file extension", func(filename, name string, numRdrs int, archived bool) {
+		f, err := os.Open(filename)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = newFormatReaders(context.Background(), f, uint64(0), name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.readers).To(HaveLen(numRdrs))
+		Expect(fr.Archived).To(Equal(archived))
+	},
+		table.Entry("should detect gz in a file without extension", tinyCoreGzFilePath, "/download", 4, true),   // [stream, multi-r, gz, multi-r]
+		table.Entry("should detect gz in a file with a raw extension", tinyCoreGzFilePath, "disk.img", 4, true), // [stream, multi-r, gz, multi-r]
+		table.Entry("should ignore the extension of a raw file named .gz", tinyCoreFilePath, "disk.img.gz", 2, false),
+		table.Entry("should detect lzma confirmed by the extension", tinyCoreLzmaFilePath, "tinyCore.iso.lzma", 4, true),
+		table.Entry("should detect lzma without extension", tinyCoreLzmaFilePath, "/download", 4, true),
+		table.Entry("should decompress brotli within other extensions", cirrosBrotliFilePath, "/images/cirros.qcow2.br", 3, true), // [stream, br, multi-r]
+	)
+
+	It("should not mistake a raw image for lzma when the extension says otherwise", func() {
+		data := append([]byte{0x5D, 0x00, 0x00}, make([]byte, 2*image.MaxExpectedHdrSize)...)
+		var err error
+		fr, err = newFormatReaders(context.Background(), io.NopCloser(bytes.NewReader(data)), uint64(0), "disk.img")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.readers).To(HaveLen(2)) // [stream, multi-r]
+		Expect(fr.Archived).To(BeFalse())
+		Expect(fr.ArchiveLzma).To(BeFalse())
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(data))
+	})
+
+	table.DescribeTable("should convert a headerless image", func(name, convertFormat string) {
+		data := make([]byte, 2*image.MaxExpectedHdrSize)
+		var err error
+		fr, err = newFormatReaders(context.Background(), io.NopCloser(bytes.NewReader(data)), uint64(0), name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Convert).To(BeTrue())
+		Expect(fr.ConvertFormat).To(Equal(convertFormat))
+		Expect(fr.Archived).To(BeFalse())
+	},
+		table.Entry("named .vmdk", "/images/disk.vmdk", ""),
+		table.Entry("named .vhd, a fixed vhd", "/images/disk.vhd", image.QemuFormatVhd),
+	)
+
+	It("should convert a dynamic vhd as vpc", func() {
+		data := append([]byte("conectix"), make([]byte, 2*image.MaxExpectedHdrSize)...)
+		var err error
+		fr, err = NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Convert).To(BeTrue())
+		Expect(fr.ConvertFormat).To(Equal(image.QemuFormatVhd))
+	})
+
+	table.DescribeTable("should convert an image with the format of its header", func(magic []byte, format string) {
+		data := append(magic, make([]byte, 2*image.MaxExpectedHdrSize)...)
+		var err error
+		fr, err = NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Convert).To(BeTrue())
+		Expect(fr.ConvertFormat).To(Equal(format))
+		Expect(fr.ConvertScratch).To(BeFalse())
+	},
+		table.Entry("of a qed image", []byte{'Q', 'E', 'D', 0x00}, image.QemuFormatQed),
+		table.Entry("of a parallels image", []byte("WithoutFreeSpace"), image.QemuFormatParallels),
+		table.Entry("of a parallels image with 64 bit offsets", []byte("WithouFreSpacExt"), image.QemuFormatParallels),
+	)
+
+	table.DescribeTable("should convert a vhdx image on scratch space", func(logicalSectorSize uint32) {
+		data := vhdxData(false, logicalSectorSize)
+		var err error
+		fr, err = NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Convert).To(BeTrue())
+		Expect(fr.ConvertScratch).To(BeTrue())
+		Expect(fr.layers).To(BeEmpty())
+		// the metadata read ahead is read again
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(data))
+	},
+		table.Entry("of 512 byte sectors", uint32(512)),
+		table.Entry("of 4K sectors", uint32(4096)),
+	)
+
+	It("should convert a vdi image on scratch space", func() {
+		f, err := os.Open(tinyCoreVdiFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Convert).To(BeTrue())
+		Expect(fr.ConvertScratch).To(BeTrue())
+	})
+
+	table.DescribeTable("should reject a vdi image", func(modify func([]byte), kind error, errString string) {
+		data, err := os.ReadFile(tinyCoreVdiFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		modify(data)
+		_, err = NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(0))
+		Expect(errors.Is(err, kind)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(errString))
+	},
+		table.Entry("that is a differencing image", func(data []byte) {
+			// type 4 and the UUID of the parent
+			binary.LittleEndian.PutUint32(data[0x4C:], 4)
+			copy(data[0x1A8:0x1B8], data[0x188:0x198])
+		}, image.ErrUnsupportedFormat, "differencing vdi image references a parent image"),
+		table.Entry("without the text of its header", func(data []byte) {
+			copy(data, "QEMU")
+		}, image.ErrCorruptArchive, "vdi header has no text identifying its creator"),
+	)
+
+	table.DescribeTable("should reject a qcow2 image", func(modify func([]byte), errString string) {
+		data, err := os.ReadFile(cirrosFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		modify(data)
+		_, err = NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(0))
+		Expect(errors.Is(err, image.ErrUnsupportedFormat)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(errString))
+	},
+		table.Entry("with an external data file", func(data []byte) {
+			binary.BigEndian.PutUint64(data[72:], 1<<2)
+		}, "image references an external data file"),
+		table.Entry("with unknown incompatible features", func(data []byte) {
+			binary.BigEndian.PutUint64(data[72:], 1<<40)
+		}, "image has unknown incompatible features 0x10000000000"),
+		table.Entry("naming an external data file in a header extension", func(data []byte) {
+			// the image has no other header extension
+			binary.BigEndian.PutUint32(data[104:], 0x44415441)
+			binary.BigEndian.PutUint32(data[108:], 8)
+			copy(data[112:], "/dev/sda")
+			binary.BigEndian.PutUint64(data[120:], 0)
+		}, "image names an external data file"),
+	)
+
+	It("should reject a differencing vhdx image", func() {
+		_, err := NewFormatReaders(io.NopCloser(bytes.NewReader(vhdxData(true, 512))), uint64(0))
+		Expect(errors.Is(err, image.ErrUnsupportedFormat)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("differencing vhdx image references a parent image"))
+	})
+
+	It("should reject a vhdx image truncated before its metadata", func() {
+		_, err := NewFormatReaders(io.NopCloser(bytes.NewReader(vhdxData(false, 512)[:1<<20])), uint64(0))
+		Expect(errors.Is(err, image.ErrTruncatedStream)).To(BeTrue())
+	})
+
+	It("should reject the descriptor of a vmdk image with external extents", func() {
+		// a descriptor of qemu-img, a descriptor shorter than the header read is not matched
+		descriptor := "# Disk DescriptorFile\nversion=1\nCID=a5af6503\nparentCID=ffffffff\ncreateType=\"twoGbMaxExtentSparse\"\n\n" +
+			"# Extent description\nRW 4192256 SPARSE \"disk-s001.vmdk\"\nRW 2099200 SPARSE \"disk-s002.vmdk\"\n\n" +
+			"# The Disk Data Base\n#DDB\n\nddb.virtualHWVersion = \"4\"\nddb.geometry.cylinders = \"6241\"\n" +
+			"ddb.geometry.heads = \"16\"\nddb.geometry.sectors = \"63\"\nddb.adapterType = \"ide\"\n" +
+			"ddb.uuid.image = \"5f3c0a3e-8a1b-4f2e-9c6d-2b7f1e4a9d10\"\nddb.uuid.parent = \"00000000-0000-0000-0000-000000000000\"\n" +
+			"ddb.uuid.modification = \"00000000-0000-0000-0000-000000000000\"\nddb.uuid.parentmodification = \"00000000-0000-0000-0000-000000000000\"\n"
+		Expect(len(descriptor)).To(BeNumerically(">=", image.MaxExpectedHdrSize))
+		_, err := NewFormatReaders(io.NopCloser(strings.NewReader(descriptor)), uint64(0))
+		var extentsErr VmdkExtentsError
+		Expect(errors.As(err, &extentsErr)).To(BeTrue())
+		Expect(extentsErr.Extents).To(Equal([]string{"disk-s001.vmdk", "disk-s002.vmdk"}))
+		Expect(errors.Is(err, image.ErrUnsupportedFormat)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("external extent files disk-s001.vmdk, disk-s002.vmdk"))
+	})
+
+	It("should reject more nested layers than the maximum", func() {
+		f, err := os.Open(tinyCore5LayersFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		_, err = NewFormatReaders(f, uint64(0))
+		Expect(err).To(HaveOccurred())
+		layersErr, ok := err.(ArchiveLayersError)
+		Expect(ok).To(BeTrue())
+		Expect(layersErr.Layers).To(Equal([]string{"lz4", "zst", "gz", "lzma", "xz"}))
+		Expect(layersErr.Max).To(Equal(defaultMaxArchiveLayers))
+		Expect(err.Error()).To(ContainSubstring("found 5 nested archive and compression layers (lz4, zst, gz, lzma, xz), the maximum is 4"))
+	})
+
+	It("should allow more nested layers when overridden", func() {
+		os.Setenv(common.ImporterMaxArchiveLayers, "5")
+		defer os.Unsetenv(common.ImporterMaxArchiveLayers)
+		f, err := os.Open(tinyCore5LayersFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.layers).To(HaveLen(5))
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		expected, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(expected))
+	})
+
+	table.DescribeTable("should reject an invalid maximum number of layers", func(value string) {
+		os.Setenv(common.ImporterMaxArchiveLayers, value)
+		defer os.Unsetenv(common.ImporterMaxArchiveLayers)
+		_, err := NewFormatReaders(io.NopCloser(bytes.NewReader(make([]byte, image.MaxExpectedHdrSize))), uint64(0))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid IMPORTER_MAX_ARCHIVE_LAYERS value"))
+	},
+		table.Entry("not a number", "many"),
+		table.Entry("zero", "0"),
+	)
+
+	table.DescribeTable("can limit the memory of the xz decoder", func(limit string, wantErr bool) {
+		os.Setenv(common.ImporterXzMemoryLimit, limit)
+		defer os.Unsetenv(common.ImporterXzMemoryLimit)
+		f, err := os.Open(tinyCoreXzFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = NewFormatReaders(f, uint64(0))
+		if !wantErr {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fr.ArchiveXz).To(BeTrue())
+			return
+		}
+		var memErr XzMemoryLimitError
+		Expect(errors.As(err, &memErr)).To(BeTrue())
+		Expect(memErr.Required).To(Equal(int64(8 << 20)))
+		Expect(memErr.Allowed).To(Equal(int64(1 << 20)))
+		Expect(errors.Is(err, image.ErrUnsupportedFormat)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("requires 8Mi of memory, the maximum is 1Mi"))
+	},
+		table.Entry("should reject a dictionary larger than the limit", "1Mi", true),
+		table.Entry("should accept a dictionary of the limit", "8Mi", false),
+	)
+
+	table.DescribeTable("should reject an invalid xz memory limit", func(value string) {
+		os.Setenv(common.ImporterXzMemoryLimit, value)
+		defer os.Unsetenv(common.ImporterXzMemoryLimit)
+		_, err := NewFormatReaders(io.NopCloser(bytes.NewReader(make([]byte, image.MaxExpectedHdrSize))), uint64(0))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid IMPORTER_XZ_MEMORY_LIMIT value"))
+	},
+		table.Entry("not a quantity", "lots"),
+		table.Entry("zero", "0"),
+	)
+
+	table.DescribeTable("can limit the decompressed size", func(filename string, margin int64, wantErr bool) {
+		expected, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		f, err := os.Open(filename)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = newFormatReaders(context.Background(), f, uint64(0), filename)
+		Expect(err).ToNot(HaveOccurred())
+		fr.SetMaxDecompressedSize(int64(len(expected)) + margin)
+		content, err := io.ReadAll(fr.TopReader())
+		if wantErr {
+			Expect(errors.Is(err, ErrDecompressedTooLarge)).To(BeTrue())
+		} else {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(Equal(expected))
+		}
+	},
+		table.Entry("should fail when the gz content is larger than the maximum", tinyCoreGzFilePath, int64(-1), true),
+		table.Entry("should fail when the tar content is larger than the maximum", tinyCoreTarLz4FilePath, int64(-1), true),
+		table.Entry("should accept gz content of exactly the maximum", tinyCoreGzFilePath, int64(0), false),
+		table.Entry("should not limit a raw file", tinyCoreFilePath, int64(-1), false),
+	)
+
+	table.DescribeTable("can validate the size of the source", func(filename string, sized bool, margin int64, wantErr bool) {
+		info, err := os.Stat(filename)
+		Expect(err).ToNot(HaveOccurred())
+		f, err := os.Open(filename)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		total := uint64(0)
+		if sized {
+			total = uint64(info.Size())
+		}
+		fr, err = newFormatReaders(context.Background(), f, total, filename)
+		Expect(err).ToNot(HaveOccurred())
+		err = fr.ValidateSourceSize(info.Size() + margin)
+		if wantErr {
+			Expect(err).To(BeAssignableToTypeOf(ValidationSizeError{}))
+		} else {
+			Expect(err).ToNot(HaveOccurred())
+		}
+	},
+		table.Entry("should fail when a raw source is larger than the maximum", tinyCoreFilePath, true, int64(-1), true),
+		table.Entry("should accept a raw source of exactly the maximum", tinyCoreFilePath, true, int64(0), false),
+		table.Entry("should not validate a source of unknown size", tinyCoreFilePath, false, int64(-1), false),
+		table.Entry("should not validate a compressed source", tinyCoreGzFilePath, true, int64(-1), false),
+		table.Entry("should not validate a converted source", cirrosFilePath, true, int64(-1), false),
+	)
+
+	It("should limit the size of a zip entry once extracted", func() {
+		expected, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		f, err := os.Open(tinyCoreZipFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = newFormatReaders(context.Background(), f, uint64(0), tinyCoreZipFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		fr.SetMaxDecompressedSize(int64(len(expected)) - 1)
+		Expect(fr.readers).To(HaveLen(2)) // [stream, multi-r]
+		tmpDir, err := os.MkdirTemp("", "limit")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		err = fr.StreamToFile(filepath.Join(tmpDir, "disk.img"))
+		Expect(errors.Is(err, ErrDecompressedTooLarge)).To(BeTrue())
+	})
+
+	It("should stop reading once the context is cancelled", func() {
+		data, err := os.ReadFile(tinyCoreGzFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		// the source stalls once the file is written, as a connection would, instead of ending
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		go pw.Write(data)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		fr, err = NewFormatReadersContext(ctx, pr, uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.ArchiveGz).To(BeTrue())
+		buf := make([]byte, 32*1024)
+		_, err = io.ReadFull(fr.TopReader(), buf)
+		Expect(err).ToNot(HaveOccurred())
+		cancel()
+		_, err = io.Copy(io.Discard, fr.TopReader())
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	})
+
+	table.DescribeTable("should report the progress of the decompression", func(filename string) {
+		compressed, err := os.Stat(filename)
+		Expect(err).ToNot(HaveOccurred())
+		decompressed, err := os.Stat(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		f, err := os.Open(filename)
+		Expect(err).ToNot(HaveOccurred())
+
+		fr, err = newFormatReaders(context.Background(), f, uint64(0), filename)
+		Expect(err).ToNot(HaveOccurred())
+		var lock sync.Mutex
+		var compressedRead, decompressedRead uint64
+		fr.SetProgressCallback(time.Millisecond, func(c, d uint64) {
+			lock.Lock()
+			defer lock.Unlock()
+			compressedRead, decompressedRead = c, d
+		})
+		tmpDir, err := os.MkdirTemp("", "progress")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		Expect(fr.StreamToFile(filepath.Join(tmpDir, "disk.img"))).To(Succeed())
+		Expect(fr.Close()).To(Succeed())
+		Eventually(func() []uint64 {
+			lock.Lock()
+			defer lock.Unlock()
+			return []uint64{compressedRead, decompressedRead}
+		}).Should(Equal([]uint64{uint64(compressed.Size()), uint64(decompressed.Size())}))
+	},
+		table.Entry("of a xz file", tinyCoreXzFilePath),
+		table.Entry("of a zip file, as it is extracted", tinyCoreZipFilePath),
+	)
+
+	table.DescribeTable("should compute the digests of the source and of the payload", func(filename string) {
+		f, err := os.Open(filename)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = newFormatReaders(context.Background(), f, uint64(0), filename)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.Digests()).To(Equal(Digests{}))
+		tmpDir, err := os.MkdirTemp("", "digest")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		Expect(fr.StreamToFile(filepath.Join(tmpDir, "disk.img"))).To(Succeed())
+		Expect(fr.Digests()).To(Equal(Digests{
+			Source:  fileDigest(filename),
+			Payload: fileDigest(tinyCoreFilePath),
+		}))
+	},
+		table.Entry("of a raw file", tinyCoreFilePath),
+		table.Entry("of a gz file", tinyCoreGzFilePath),
+		table.Entry("of a xz file", tinyCoreXzFilePath),
+		table.Entry("of a zip file", tinyCoreZipFilePath),
+	)
+
+	table.DescribeTable("can describe the formats found", func(filename string, formats []string, uncompressed bool) {
+		stat, err := os.Stat(filename)
+		Expect(err).ToNot(HaveOccurred())
+		iso, err := os.Stat(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		f, err := os.Open(filename)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		info := fr.FormatInfo()
+		Expect(info.Formats).To(Equal(formats))
+		Expect(info.Seekable).To(BeTrue())
+		Expect(info.CompressedSize).To(Equal(uint64(stat.Size())))
+		if uncompressed {
+			Expect(info.UncompressedSize).To(Equal(uint64(iso.Size())))
+		} else {
+			Expect(info.UncompressedSize).To(BeZero())
+		}
+		// the sizes are read without moving the stream
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		if formats[0] != "qcow2" {
+			Expect(content).To(HaveLen(int(iso.Size())))
+		}
+	},
+		table.Entry("of a xz file", tinyCoreXzFilePath, []string{"xz"}, true),
+		table.Entry("of a gz file", tinyCoreGzFilePath, []string{"gz"}, true),
+		table.Entry("of a cpio gz file", tinyCoreCpioGzFilePath, []string{"gz", "cpio"}, false),
+		table.Entry("of a zstd file", tinyCoreZstdFilePath, []string{"zst"}, false),
+		table.Entry("of a qcow2 image", cirrosFilePath, []string{"qcow2"}, false),
+	)
+
+	It("should describe a raw stream", func() {
+		data, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		fr, err = NewFormatReaders(io.NopCloser(bytes.NewReader(data)), uint64(len(data)))
+		Expect(err).ToNot(HaveOccurred())
+		info := fr.FormatInfo()
+		Expect(info.Formats).To(BeEmpty())
+		Expect(info.Seekable).To(BeFalse())
+		Expect(info.CompressedSize).To(Equal(uint64(len(data))))
+		Expect(info.UncompressedSize).To(BeZero())
+	})
+
+	table.DescribeTable("should detect an ISO9660 image", func(filename string, iso bool) {
+		data, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		f, err := os.Open(filename)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+		fr, err = NewFormatReaders(f, uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.ISO).To(Equal(iso))
+		Expect(fr.Convert).To(BeFalse())
+		if iso {
+			Expect(fr.SourceFormat()).To(Equal("iso"))
+			// the data read to detect it is read again
+			content, err := io.ReadAll(fr.TopReader())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(Equal(data))
+		} else {
+			Expect(fr.SourceFormat()).To(BeEmpty())
+		}
+	},
+		table.Entry("as it is", tinyCoreFilePath, true),
+		table.Entry("once decompressed", tinyCoreXzFilePath, true),
+		table.Entry("but not in an archive imported as it is", archiveFilePath, false),
+	)
+
+	It("should detect an ISO9660 image starting like an ambiguous header", func() {
+		iso := make([]byte, image.ISO9660HeaderSize+512)
+		copy(iso, []byte{0x5D, 0x00, 0x00})
+		copy(iso[image.ISO9660HeaderSize-5:], "CD001")
+		var err error
+		fr, err = NewFormatReaders(io.NopCloser(bytes.NewReader(iso)), uint64(len(iso)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fr.ISO).To(BeTrue())
+		Expect(fr.ArchiveLzma).To(BeFalse())
+		content, err := io.ReadAll(fr.TopReader())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(iso))
+	})
+
+	It("should reject the legacy lz4 format", func() {
+		legacy := io.NopCloser(bytes.NewReader(append([]byte{0x02, 0x21, 0x4C, 0x18}, make([]byte, image.MaxExpectedHdrSize)...)))
+		_, err := NewFormatReaders(legacy, uint64(0))
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrLz4LegacyFormat)).To(BeTrue())
+		Expect(errors.Is(err, image.ErrUnsupportedFormat)).To(BeTrue())
+	})
+
+	table.DescribeTable("should classify the errors of the data", func(corrupt func([]byte) io.Reader, kind error) {
+		data, err := os.ReadFile(tinyCoreGzFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		fr, err = NewFormatReaders(io.NopCloser(corrupt(data)), uint64(0))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = io.Copy(io.Discard, fr.TopReader())
+		Expect(err).To(HaveOccurred())
+		for _, k := range []error{image.ErrTruncatedStream, image.ErrCorruptArchive, image.ErrUnsupportedFormat} {
+			Expect(errors.Is(err, k)).To(Equal(k == kind))
+		}
+	},
+		table.Entry("of a truncated gz file", func(data []byte) io.Reader {
+			return bytes.NewReader(data[:len(data)/2])
+		}, image.ErrTruncatedStream),
+		table.Entry("of a corrupted gz file", func(data []byte) io.Reader {
+			// the CRC32 of the trailer
+			data[len(data)-8] ^= 0xFF
+			return bytes.NewReader(data)
+		}, image.ErrCorruptArchive),
+		table.Entry("but not the errors of the source", func(data []byte) io.Reader {
+			return io.MultiReader(bytes.NewReader(data[:len(data)/2]), iotest.ErrReader(errors.New("connection reset")))
+		}, nil),
+	)
+
+	It("should report the bytes read without the size of the source", func() {
 		stringReader := io.NopCloser(strings.NewReader("This is a test string"))
 		testReader, err := NewFormatReaders(stringReader, uint64(0))
 		// Not passing a real string, so the header checking will fail.
 		Expect(err).To(HaveOccurred())
-		Expect(testReader.progressReader).To(BeNil())
+		Expect(testReader.progressReader).ToNot(BeNil())
 		// This should not crash
 		testReader.StartProgressUpdate()
 	})
 })
+
+// fileDigest returns the sha256 digest of the file, as reported by the format readers.
+func fileDigest(fileName string) string {
+	data, err := os.ReadFile(fileName)
+	Expect(err).ToNot(HaveOccurred())
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+// vhdxData returns the start of a vhdx image, up to the end of its metadata region at 2 MiB, along
+// with the file parameters and logical sector size items.
+// ovaData returns an OVA appliance holding an OVF descriptor, then the disks, all of them with the
+// same data.
+func ovaData(disk []byte, names ...string) []byte {
+	var references, disks strings.Builder
+	for i, name := range names {
+		fmt.Fprintf(&references, "<File ovf:id=\"file%d\" ovf:href=\"%s\"/>", i, name)
+		fmt.Fprintf(&disks, "<Disk ovf:diskId=\"vmdisk%d\" ovf:fileRef=\"file%d\"/>", i, i)
+	}
+	descriptor := fmt.Sprintf(`<?xml version="1.0"?>
+<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1">
+<References>%s</References><DiskSection>%s</DiskSection></Envelope>`, references.String(), disks.String())
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	entries := map[string][]byte{"appliance.ovf": []byte(descriptor)}
+	for _, name := range append([]string{"appliance.ovf"}, names...) {
+		data, ok := entries[name]
+		if !ok {
+			data = disk
+		}
+		Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))})).To(Succeed())
+		_, err := tw.Write(data)
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	return b.Bytes()
+}
+
+func vhdxData(hasParent bool, logicalSectorSize uint32) []byte {
+	metadataRegionID := []byte{0x06, 0xA2, 0x7C, 0x8B, 0x90, 0x47, 0x9A, 0x4B, 0xB8, 0xFE, 0x57, 0x5F, 0x05, 0x0F, 0x88, 0x6E}
+	fileParametersID := []byte{0x37, 0x67, 0xA1, 0xCA, 0x36, 0xFA, 0x43, 0x4D, 0xB3, 0xB6, 0x33, 0xF0, 0xAA, 0x44, 0xE7, 0x6B}
+	logicalSectorID := []byte{0x1D, 0xBF, 0x41, 0x81, 0x6F, 0xA9, 0x09, 0x47, 0xBA, 0x47, 0xF2, 0x33, 0xA8, 0xFA, 0xAB, 0x5F}
+	data := make([]byte, 2<<20+64<<10)
+	copy(data, "vhdxfile")
+	// the two copies of the region table
+	for _, table := range [][]byte{data[192<<10 : 256<<10], data[256<<10 : 320<<10]} {
+		copy(table, "regi")
+		binary.LittleEndian.PutUint32(table[8:], 1)
+		copy(table[16:], metadataRegionID)
+		binary.LittleEndian.PutUint64(table[32:], 2<<20)
+		binary.LittleEndian.PutUint32(table[40:], 64<<10)
+		binary.LittleEndian.PutUint32(table[4:], crc32.Checksum(table, crc32.MakeTable(crc32.Castagnoli)))
+	}
+	metadata := data[2<<20:]
+	copy(metadata, "metadata")
+	binary.LittleEndian.PutUint16(metadata[10:], 2)
+	copy(metadata[32:], fileParametersID)
+	binary.LittleEndian.PutUint32(metadata[48:], 1024)
+	binary.LittleEndian.PutUint32(metadata[52:], 8)
+	copy(metadata[64:], logicalSectorID)
+	binary.LittleEndian.PutUint32(metadata[80:], 1032)
+	binary.LittleEndian.PutUint32(metadata[84:], 4)
+	binary.LittleEndian.PutUint32(metadata[1024:], 32<<20)
+	if hasParent {
+		binary.LittleEndian.PutUint32(metadata[1028:], 2)
+	}
+	binary.LittleEndian.PutUint32(metadata[1032:], logicalSectorSize)
+	return data
+}
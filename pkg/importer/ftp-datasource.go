@@ -0,0 +1,416 @@
+package importer
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+	"github.com/pkg/errors"
+
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+const (
+	// ftpScheme is the scheme of the ftp://host/path URLs
+	ftpScheme = "ftp"
+	// ftpsScheme is the scheme of the ftps://host/path URLs, served with explicit FTPS (AUTH TLS)
+	ftpsScheme = "ftps"
+	// ftpDefaultPort is the port of the FTP server when the URL has none, explicit FTPS included
+	ftpDefaultPort = "21"
+	// ftpDialTimeout bounds the connections to the FTP server and each of its replies
+	ftpDialTimeout = 30 * time.Second
+	// ftpReadRetries is the number of times a failed read of a file is resumed before the import fails
+	ftpReadRetries = 3
+	// ftpAnonymousUser and ftpAnonymousPassword log in when the source has no secret
+	ftpAnonymousUser     = "anonymous"
+	ftpAnonymousPassword = "anonymous@"
+	// ftpListedFiles is the number of files of a directory listed in the error of its URL
+	ftpListedFiles = 20
+)
+
+var (
+	// ftpRetryInterval is the delay before the first resumption of a failed read, raised on each
+	// retry, may be overridden in tests
+	ftpRetryInterval = 2 * time.Second
+)
+
+// IsFTPEndpoint returns true if the endpoint of an http source is an ftp:// or ftps:// URL, read by
+// the FTPDataSource.
+func IsFTPEndpoint(endpoint string) bool {
+	ep, err := url.Parse(endpoint)
+	return err == nil && (ep.Scheme == ftpScheme || ep.Scheme == ftpsScheme)
+}
+
+// FTPDataSource is the struct containing the information needed to import a file of an FTP
+// server, named by a ftp://host[:port]/path or a ftps://host[:port]/path URL. The user of the
+// secret logs in, the anonymous user without secret. The ftps URLs upgrade the connections to TLS
+// with AUTH TLS, verifying the server with the CA certificates of certDir.
+// Sequence of phases:
+// 1a. Info -> TransferDataFile, if the file is a raw image
+// 1b. Info -> Transfer in all other cases
+// 2. Transfer -> Convert
+type FTPDataSource struct {
+	// ftp://host/path URL
+	ep *url.URL
+	// Reader
+	ftpReader *ftpFileReader
+	// stack of readers, created by Info
+	sourceReaders
+	// The image file in scratch space.
+	url *url.URL
+	// the readers stop once ctx is done
+	ctx context.Context
+}
+
+// NewFTPDataSource creates a new instance of the FTPDataSource, reading the file of a ftp:// or a
+// ftps:// URL as the user accessKey, with the password secKey.
+func NewFTPDataSource(endpoint, accessKey, secKey, certDir string) (*FTPDataSource, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse endpoint %q", endpoint)
+	}
+	addr, path, err := parseFTPURL(ep)
+	if err != nil {
+		return nil, err
+	}
+	var tlsConfig *tls.Config
+	if ep.Scheme == ftpsScheme {
+		tlsConfig = &tls.Config{}
+		if certDir != "" {
+			if tlsConfig.RootCAs, err = createCertPool(certDir); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if accessKey == "" {
+		accessKey, secKey = ftpAnonymousUser, ftpAnonymousPassword
+	}
+	ctx := context.Background()
+	reader, err := newFTPFileReader(ctx, addr, tlsConfig, accessKey, secKey, path)
+	if err != nil {
+		return nil, err
+	}
+	return &FTPDataSource{
+		ep:        ep,
+		ftpReader: reader,
+		ctx:       ctx,
+	}, nil
+}
+
+// parseFTPURL returns the address of the FTP server of a ftp(s)://host[:port]/path URL, and the
+// path of the file.
+func parseFTPURL(ep *url.URL) (string, string, error) {
+	if ep.Scheme != ftpScheme && ep.Scheme != ftpsScheme {
+		return "", "", errors.Errorf("ftp URL %q has the scheme %q, ftp://host/path or ftps://host/path is expected", ep.Redacted(), ep.Scheme)
+	}
+	if ep.User != nil {
+		return "", "", errors.Errorf("ftp URL %q has a user, the user and its password are read from the secret", ep.Redacted())
+	}
+	if ep.Hostname() == "" || ep.Path == "" || ep.Path == "/" {
+		return "", "", errors.Errorf("ftp URL %q does not name a host and a file, %s://host/path is expected", ep.Redacted(), ep.Scheme)
+	}
+	port := ep.Port()
+	if port == "" {
+		port = ftpDefaultPort
+	}
+	return net.JoinHostPort(ep.Hostname(), port), ep.Path, nil
+}
+
+// Info is called to get initial information about the data.
+func (fd *FTPDataSource) Info() (ProcessingPhase, error) {
+	var err error
+	fd.ftpReader.ctx = fd.ctx
+	fd.readers, err = newFormatReaders(fd.ctx, fd.ftpReader, uint64(fd.ftpReader.size), fd.ep.Path)
+	if err != nil {
+		klog.Errorf("Error creating readers: %v", err)
+		return ProcessingPhaseError, err
+	}
+	if !fd.readers.Convert {
+		// Downloading a raw file, we can write that directly to the target.
+		return ProcessingPhaseTransferDataFile, nil
+	}
+	return ProcessingPhaseTransferScratch, nil
+}
+
+// SetContext stops the transfer once ctx is done.
+func (fd *FTPDataSource) SetContext(ctx context.Context) {
+	fd.ctx = ctx
+}
+
+// Transfer is called to transfer the data from the source to a temporary location.
+func (fd *FTPDataSource) Transfer(path string) (ProcessingPhase, error) {
+	size, _ := util.GetAvailableSpace(path)
+	if size <= int64(0) {
+		//Path provided is invalid.
+		return ProcessingPhaseError, ErrInvalidPath
+	}
+	file := filepath.Join(path, tempFile)
+	fd.readers.StartProgressUpdate()
+	if err := fd.readers.StreamToFile(file); err != nil {
+		return ProcessingPhaseError, err
+	}
+	// If streaming succeeded, then parsing the file into URL will also succeed, no need to check error status
+	fd.url, _ = url.Parse(file)
+	return ProcessingPhaseConvert, nil
+}
+
+// TransferFile is called to transfer the data from the source to the passed in file.
+func (fd *FTPDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
+	fd.readers.StartProgressUpdate()
+	if err := fd.readers.StreamToFile(fileName); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseResize, nil
+}
+
+// GetURL returns the url that the data processor can use when converting the data.
+func (fd *FTPDataSource) GetURL() *url.URL {
+	return fd.url
+}
+
+// Close closes any readers or other open resources.
+func (fd *FTPDataSource) Close() error {
+	var err error
+	if fd.readers != nil {
+		err = fd.readers.Close()
+	}
+	if fd.ftpReader != nil {
+		fd.ftpReader.Close()
+	}
+	return err
+}
+
+// ftpFileReader streams a file of an FTP server. A read that fails is resumed with REST from the
+// offset reached, over a new connection, up to ftpReadRetries times in a row. The import fails if
+// the size or the modification time of the file changed meanwhile.
+type ftpFileReader struct {
+	ctx       context.Context
+	addr      string
+	tlsConfig *tls.Config
+	user      string
+	password  string
+	path      string
+	size      int64
+	modTime   time.Time
+	offset    int64
+	client    *ftp.ServerConn
+	body      *ftp.Response
+}
+
+// newFTPFileReader logs in to the FTP server of addr and starts the transfer of the file of path.
+func newFTPFileReader(ctx context.Context, addr string, tlsConfig *tls.Config, user, password, path string) (*ftpFileReader, error) {
+	r := &ftpFileReader{ctx: ctx, addr: addr, tlsConfig: tlsConfig, user: user, password: password, path: path, size: -1}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open logs in to the FTP server, then starts the transfer of the file from the offset reached.
+// The size and the modification time of the file are recorded on the first connection, and
+// compared on the next ones.
+func (r *ftpFileReader) open() error {
+	client, err := dialFTP(r.addr, r.tlsConfig)
+	if err != nil {
+		return errors.Wrapf(err, "could not connect to ftp server %s", r.addr)
+	}
+	if err := client.Login(r.user, r.password); err != nil {
+		client.Quit()
+		return errors.Wrapf(err, "could not log in to ftp server %s as %s", r.addr, r.user)
+	}
+	size, err := client.FileSize(r.path)
+	if err != nil {
+		if isFTPFileUnavailable(err) && r.size < 0 {
+			if names, listErr := listFTPDirectory(client, r.path); listErr == nil {
+				client.Quit()
+				return errors.Errorf("ftp URL path %q is a directory, holding %s, the URL of a file is expected", r.path, names)
+			}
+		}
+		client.Quit()
+		return errors.Wrapf(err, "could not get the size of ftp file %q", r.path)
+	}
+	// servers not implementing MDTM only have their files compared by size
+	modTime, _ := client.GetTime(r.path)
+	if r.size < 0 {
+		klog.V(1).Infof("ftp file %s:%s, %d bytes", r.addr, r.path, size)
+		r.size, r.modTime = size, modTime
+	} else if size != r.size || !modTime.Equal(r.modTime) {
+		client.Quit()
+		return &ftpFileChangedError{path: r.path}
+	}
+	body, err := client.RetrFrom(r.path, uint64(r.offset))
+	if err != nil {
+		client.Quit()
+		return errors.Wrapf(err, "could not retrieve ftp file %q from offset %d", r.path, r.offset)
+	}
+	r.client, r.body = client, body
+	return nil
+}
+
+// dialFTP connects to the FTP server of addr, upgrading the connection to TLS with AUTH TLS when
+// tlsConfig is set.
+func dialFTP(addr string, tlsConfig *tls.Config) (*ftp.ServerConn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &ftpDialer{host: host}
+	options := []ftp.DialOption{ftp.DialWithDialFunc(dialer.dial)}
+	if tlsConfig != nil {
+		dialer.tlsConfig = tlsConfig.Clone()
+		if dialer.tlsConfig.ServerName == "" {
+			dialer.tlsConfig.ServerName = host
+		}
+		// the data connections resume the TLS session of the control connection, as required
+		// by servers such as vsftpd
+		dialer.tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+		options = append(options, ftp.DialWithExplicitTLS(dialer.tlsConfig))
+	}
+	return ftp.Dial(addr, options...)
+}
+
+// ftpDialer dials the control connection of an FTP session, then its data connections. The data
+// connections are made to the host of the control connection, the address of a PASV reply is
+// often private to a server behind NAT, and are protected by TLS when tlsConfig is set. All the
+// connections fail once the server sent nothing for ftpDialTimeout.
+type ftpDialer struct {
+	host      string
+	tlsConfig *tls.Config
+	// dialed is set once the control connection is dialed
+	dialed bool
+}
+
+func (d *ftpDialer) dial(network, address string) (net.Conn, error) {
+	data := d.dialed
+	if data {
+		_, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		address = net.JoinHostPort(d.host, port)
+	}
+	conn, err := net.DialTimeout(network, address, ftpDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	d.dialed = true
+	conn = &ftpConn{Conn: conn}
+	if data && d.tlsConfig != nil {
+		// the handshake starts with the first read, once the server accepted the transfer
+		conn = tls.Client(conn, d.tlsConfig)
+	}
+	return conn, nil
+}
+
+// ftpConn is a connection to an FTP server whose reads fail after ftpDialTimeout without data.
+type ftpConn struct {
+	net.Conn
+}
+
+func (c *ftpConn) Read(p []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(ftpDialTimeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(p)
+}
+
+// isFTPFileUnavailable returns true if err is the reply of a request naming a file that does not
+// exist, or that cannot be accessed.
+func isFTPFileUnavailable(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code == ftp.StatusFileUnavailable
+}
+
+// listFTPDirectory returns the files of the directory of path, failing if it is not a directory.
+func listFTPDirectory(client *ftp.ServerConn, path string) (string, error) {
+	if err := client.ChangeDir(path); err != nil {
+		return "", err
+	}
+	names, err := client.NameList("")
+	if err != nil {
+		return "", err
+	}
+	for i, name := range names {
+		names[i] = filepath.Base(name)
+	}
+	switch {
+	case len(names) == 0:
+		return "no file", nil
+	case len(names) > ftpListedFiles:
+		return "the files " + strings.Join(names[:ftpListedFiles], ", ") + ", ...", nil
+	}
+	return "the files " + strings.Join(names, ", "), nil
+}
+
+// ftpFileChangedError is the error of a file whose size or modification time changed while it
+// was read, its read is not resumed.
+type ftpFileChangedError struct {
+	path string
+}
+
+func (e *ftpFileChangedError) Error() string {
+	return "ftp file \"" + e.path + "\" changed while it was read"
+}
+
+// Read reads the data of the file, resuming the read over a new connection after an error. A
+// transfer ending before the size of the file is an error too.
+func (r *ftpFileReader) Read(p []byte) (int, error) {
+	for retry := 0; ; retry++ {
+		if r.offset == r.size {
+			return 0, io.EOF
+		}
+		err := errors.New("not connected")
+		if r.body != nil {
+			var n int
+			if remaining := r.size - r.offset; remaining < int64(len(p)) {
+				p = p[:remaining]
+			}
+			n, err = r.body.Read(p)
+			r.offset += int64(n)
+			if n > 0 {
+				return n, nil
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+		if retry == ftpReadRetries || r.ctx.Err() != nil {
+			return 0, errors.Wrapf(err, "could not read ftp file \"%s\" at offset %d after %d retries", r.path, r.offset, retry)
+		}
+		klog.Warningf("Resuming the read of ftp file \"%s\" at offset %d: %v", r.path, r.offset, err)
+		r.Close()
+		select {
+		case <-time.After(ftpRetryInterval * time.Duration(retry+1)):
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+		if err := r.open(); err != nil {
+			if _, changed := err.(*ftpFileChangedError); changed {
+				return 0, err
+			}
+			// the next read fails without connection, and is retried
+			klog.Warningf("Could not resume the read of ftp file \"%s\": %v", r.path, err)
+		}
+	}
+}
+
+// Close ends the transfer and the session.
+func (r *ftpFileReader) Close() error {
+	if r.client == nil {
+		return nil
+	}
+	// the reply to a transfer closed before its end is an error
+	r.body.Close()
+	err := r.client.Quit()
+	r.client, r.body = nil, nil
+	return err
+}
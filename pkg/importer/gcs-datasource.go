@@ -0,0 +1,320 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+const (
+	// gcsScheme is the scheme of the gs://bucket/object URLs
+	gcsScheme = "gs"
+	// gcsReadOnlyScope is the OAuth2 scope of the tokens reading the objects
+	gcsReadOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+	// gcsReadRetries is the number of times a failed read of an object is resumed before the import fails
+	gcsReadRetries = 3
+)
+
+var (
+	// gcsStorageEndpoint is the endpoint of the JSON API of Cloud Storage, may be overridden in tests
+	gcsStorageEndpoint = "https://storage.googleapis.com"
+	// gcsRetryInterval is the delay before the first resumption of a failed read, raised on each
+	// retry, may be overridden in tests
+	gcsRetryInterval = 2 * time.Second
+)
+
+// GCSDataSource is the struct containing the information needed to import from a Google Cloud
+// Storage object, named by a gs://bucket/object URL. The object is read from its generation found
+// when the data source is created, or the one of the URL, gs://bucket/object#generation.
+// Sequence of phases:
+// 1a. Info -> TransferDataFile, if the object is a raw image
+// 1b. Info -> Transfer in all other cases
+// 2. Transfer -> Convert
+type GCSDataSource struct {
+	// gs://bucket/object URL
+	ep *url.URL
+	// Reader
+	gcsReader *gcsObjectReader
+	// stack of readers, created by Info
+	sourceReaders
+	// The image file in scratch space.
+	url *url.URL
+	// the readers stop once ctx is done
+	ctx context.Context
+}
+
+// NewGCSDataSource creates a new instance of the GCSDataSource, reading the object of a
+// gs://bucket/object URL with the JSON key of a service account, or with the workload identity of
+// the pod when the key is empty. The CA certificates of the endpoint are read from certDir, unless
+// it is empty.
+func NewGCSDataSource(endpoint, serviceAccount, certDir string) (*GCSDataSource, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, fmt.Sprintf("unable to parse endpoint %q", endpoint))
+	}
+	bucket, object, generation, err := parseGCSURL(ep)
+	if err != nil {
+		return nil, err
+	}
+	client, err := createHTTPClient(certDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating http client for gcs")
+	}
+	ctx := context.Background()
+	var tokenSource oauth2.TokenSource
+	if serviceAccount != "" {
+		config, err := google.JWTConfigFromJSON([]byte(serviceAccount), gcsReadOnlyScope)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid service account key, the JSON key of a service account is expected")
+		}
+		// the token endpoint of the key is reached with the CA certificates of the endpoint
+		tokenSource = config.TokenSource(context.WithValue(ctx, oauth2.HTTPClient, client))
+	} else {
+		klog.V(1).Infoln("No service account key, using the workload identity of the pod")
+		tokenSource = google.ComputeTokenSource("")
+	}
+	client = &http.Client{
+		Transport: &oauth2.Transport{
+			Source: tokenSource,
+			Base:   client.Transport,
+		},
+	}
+	reader, err := newGCSObjectReader(ctx, client, bucket, object, generation)
+	if err != nil {
+		return nil, err
+	}
+	return &GCSDataSource{
+		ep:        ep,
+		gcsReader: reader,
+		ctx:       ctx,
+	}, nil
+}
+
+// parseGCSURL returns the bucket and the object of a gs://bucket/object URL, along with the
+// generation of its fragment, 0 when it has none.
+func parseGCSURL(ep *url.URL) (string, string, int64, error) {
+	if ep.Scheme != gcsScheme {
+		return "", "", 0, errors.Errorf("gcs URL %q has the scheme %q, gs://bucket/object is expected", ep, ep.Scheme)
+	}
+	bucket, object := ep.Host, strings.TrimPrefix(ep.Path, "/")
+	if bucket == "" || object == "" {
+		return "", "", 0, errors.Errorf("gcs URL %q does not name a bucket and an object, gs://bucket/object is expected", ep)
+	}
+	var generation int64
+	if ep.Fragment != "" {
+		var err error
+		if generation, err = strconv.ParseInt(ep.Fragment, 10, 64); err != nil || generation <= 0 {
+			return "", "", 0, errors.Errorf("gcs URL %q has the invalid generation %q", ep, ep.Fragment)
+		}
+	}
+	return bucket, object, generation, nil
+}
+
+// Info is called to get initial information about the data.
+func (gd *GCSDataSource) Info() (ProcessingPhase, error) {
+	var err error
+	gd.gcsReader.ctx = gd.ctx
+	gd.readers, err = newFormatReaders(gd.ctx, gd.gcsReader, uint64(gd.gcsReader.size), gd.ep.Path)
+	if err != nil {
+		klog.Errorf("Error creating readers: %v", err)
+		return ProcessingPhaseError, err
+	}
+	if !gd.readers.Convert {
+		// Downloading a raw file, we can write that directly to the target.
+		return ProcessingPhaseTransferDataFile, nil
+	}
+	return ProcessingPhaseTransferScratch, nil
+}
+
+// SetContext stops the transfer once ctx is done.
+func (gd *GCSDataSource) SetContext(ctx context.Context) {
+	gd.ctx = ctx
+}
+
+// Transfer is called to transfer the data from the source to a temporary location.
+func (gd *GCSDataSource) Transfer(path string) (ProcessingPhase, error) {
+	size, _ := util.GetAvailableSpace(path)
+	if size <= int64(0) {
+		//Path provided is invalid.
+		return ProcessingPhaseError, ErrInvalidPath
+	}
+	file := filepath.Join(path, tempFile)
+	gd.readers.StartProgressUpdate()
+	if err := gd.readers.StreamToFile(file); err != nil {
+		return ProcessingPhaseError, err
+	}
+	// If streaming succeeded, then parsing the file into URL will also succeed, no need to check error status
+	gd.url, _ = url.Parse(file)
+	return ProcessingPhaseConvert, nil
+}
+
+// TransferFile is called to transfer the data from the source to the passed in file.
+func (gd *GCSDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
+	gd.readers.StartProgressUpdate()
+	if err := gd.readers.StreamToFile(fileName); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseResize, nil
+}
+
+// GetURL returns the url that the data processor can use when converting the data.
+func (gd *GCSDataSource) GetURL() *url.URL {
+	return gd.url
+}
+
+// Close closes any readers or other open resources.
+func (gd *GCSDataSource) Close() error {
+	if gd.readers != nil {
+		return gd.readers.Close()
+	}
+	if gd.gcsReader != nil {
+		return gd.gcsReader.Close()
+	}
+	return nil
+}
+
+// gcsObjectReader streams a generation of an object. A read that fails is resumed from the offset
+// reached, with a range request of the same generation, up to gcsReadRetries times in a row: the
+// object may be replaced while it is read, its data is then still the one of the generation read.
+type gcsObjectReader struct {
+	ctx        context.Context
+	client     *http.Client
+	bucket     string
+	object     string
+	generation int64
+	size       int64
+	offset     int64
+	body       io.ReadCloser
+}
+
+// gcsObjectMetadata holds the fields of the metadata of an object, int64 values are strings in the
+// JSON API
+type gcsObjectMetadata struct {
+	Generation string `json:"generation"`
+	Size       string `json:"size"`
+}
+
+// newGCSObjectReader opens the generation of the object, its latest generation when it is 0.
+func newGCSObjectReader(ctx context.Context, client *http.Client, bucket, object string, generation int64) (*gcsObjectReader, error) {
+	r := &gcsObjectReader{ctx: ctx, client: client, bucket: bucket, object: object, generation: generation}
+	metadata := &gcsObjectMetadata{}
+	if err := r.get(url.Values{"fields": {"generation,size"}}, "", func(resp *http.Response) error {
+		defer resp.Body.Close()
+		return json.NewDecoder(resp.Body).Decode(metadata)
+	}); err != nil {
+		return nil, err
+	}
+	var err error
+	if r.generation, err = strconv.ParseInt(metadata.Generation, 10, 64); err != nil {
+		return nil, errors.Errorf("invalid generation %q of gcs object \"%s/%s\"", metadata.Generation, bucket, object)
+	}
+	if r.size, err = strconv.ParseInt(metadata.Size, 10, 64); err != nil {
+		return nil, errors.Errorf("invalid size %q of gcs object \"%s/%s\"", metadata.Size, bucket, object)
+	}
+	klog.V(1).Infof("gcs object %s/%s, generation %d, %d bytes", bucket, object, r.generation, r.size)
+	if err = r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open requests the data of the object from the offset reached.
+func (r *gcsObjectReader) open() error {
+	byteRange := ""
+	if r.offset > 0 {
+		byteRange = fmt.Sprintf("bytes=%d-", r.offset)
+	}
+	return r.get(url.Values{"alt": {"media"}}, byteRange, func(resp *http.Response) error {
+		if byteRange != "" && resp.StatusCode != http.StatusPartialContent {
+			resp.Body.Close()
+			return errors.Errorf("gcs object \"%s/%s\" served without the range %s", r.bucket, r.object, byteRange)
+		}
+		r.body = resp.Body
+		return nil
+	})
+}
+
+// get sends a request of the object, of its generation unless it is 0, and of the passed in range
+// unless it is empty, then passes the response to handle.
+func (r *gcsObjectReader) get(query url.Values, byteRange string, handle func(*http.Response) error) error {
+	if r.generation != 0 {
+		query.Set("generation", strconv.FormatInt(r.generation, 10))
+	}
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?%s", gcsStorageEndpoint, url.PathEscape(r.bucket), escapeGCSObject(r.object), query.Encode())
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not create gcs request")
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "could not get gcs object: \"%s/%s\"", r.bucket, r.object)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound && r.generation != 0 {
+			return errors.Errorf("generation %d of gcs object \"%s/%s\" not found", r.generation, r.bucket, r.object)
+		}
+		return errors.Errorf("could not get gcs object: \"%s/%s\": %s", r.bucket, r.object, resp.Status)
+	}
+	return handle(resp)
+}
+
+// escapeGCSObject escapes the name of an object in the path of a request, slashes included.
+func escapeGCSObject(object string) string {
+	return strings.ReplaceAll(url.PathEscape(object), "/", "%2F")
+}
+
+// Read reads the data of the object, resuming the request after an error. The error of a read
+// returning data is left to the next read.
+func (r *gcsObjectReader) Read(p []byte) (int, error) {
+	for retry := 0; ; retry++ {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == io.EOF && r.offset < r.size {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+		if retry == gcsReadRetries || r.ctx.Err() != nil {
+			return 0, errors.Wrapf(err, "could not read gcs object \"%s/%s\" at offset %d after %d retries", r.bucket, r.object, r.offset, gcsReadRetries)
+		}
+		klog.Warningf("Resuming the read of gcs object \"%s/%s\" at offset %d: %v", r.bucket, r.object, r.offset, err)
+		r.body.Close()
+		select {
+		case <-time.After(gcsRetryInterval * time.Duration(retry+1)):
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+		if openErr := r.open(); openErr != nil {
+			// the closed body fails the next read, which is retried
+			klog.Warningf("Could not resume the read of gcs object \"%s/%s\": %v", r.bucket, r.object, openErr)
+		}
+	}
+}
+
+// Close closes the body of the current request.
+func (r *gcsObjectReader) Close() error {
+	return r.body.Close()
+}
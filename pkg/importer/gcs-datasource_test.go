@@ -0,0 +1,277 @@
+package importer
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GCS data source", func() {
+	var (
+		gd      *GCSDataSource
+		tmpDir  string
+		storage *fakeGCSStorage
+		server  *httptest.Server
+		err     error
+	)
+
+	BeforeEach(func() {
+		tmpDir, err = os.MkdirTemp("", "scratch")
+		Expect(err).NotTo(HaveOccurred())
+		storage = &fakeGCSStorage{
+			generations: map[int64][]byte{1: cirrosData},
+			latest:      1,
+			token:       "metadata-token",
+		}
+		server = httptest.NewServer(storage)
+		gcsStorageEndpoint = server.URL
+		// the metadata server of the workload identity is reached on the host of the environment
+		os.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+		gcsRetryInterval = time.Millisecond
+	})
+
+	AfterEach(func() {
+		if gd != nil {
+			gd.Close()
+			gd = nil
+		}
+		server.Close()
+		gcsStorageEndpoint = "https://storage.googleapis.com"
+		os.Unsetenv("GCE_METADATA_HOST")
+		gcsRetryInterval = 2 * time.Second
+		os.RemoveAll(tmpDir)
+	})
+
+	transfer := func() []byte {
+		phase, err := gd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferScratch))
+		phase, err = gd.Transfer(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseConvert))
+		data, err := os.ReadFile(filepath.Join(tmpDir, tempFile))
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	It("should read an object with the workload identity of the pod", func() {
+		gd, err = NewGCSDataSource("gs://bucket-1/images/cirros.qcow2", "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transfer()).To(Equal(cirrosData))
+		Expect(storage.objects).To(ContainElement("images/cirros.qcow2"))
+	})
+
+	It("should read an object with the key of a service account", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		storage.serviceAccountKey = &key.PublicKey
+		storage.token = "service-account-token"
+		gd, err = NewGCSDataSource("gs://bucket-1/cirros.qcow2", newServiceAccountKeyJSON(key, server.URL+"/token"), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transfer()).To(Equal(cirrosData))
+		Expect(storage.assertions).To(Equal(1))
+	})
+
+	It("should resume a failed read from the generation read", func() {
+		storage.failReads = 2
+		gd, err = NewGCSDataSource("gs://bucket-1/cirros.qcow2", "", "")
+		Expect(err).NotTo(HaveOccurred())
+		// a new generation of the object is written while it is read
+		storage.generations[2] = tinyCoreVdiData
+		storage.latest = 2
+		Expect(transfer()).To(Equal(cirrosData))
+		Expect(storage.ranges).To(HaveLen(2))
+	})
+
+	It("should fail once a read failed on every retry", func() {
+		storage.failReads = 1
+		storage.failResumes = true
+		gd, err = NewGCSDataSource("gs://bucket-1/cirros.qcow2", "", "")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = gd.Info()
+		if err == nil {
+			_, err = gd.Transfer(tmpDir)
+		}
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should read the generation of the URL", func() {
+		storage.generations[2] = tinyCoreVdiData
+		storage.latest = 2
+		gd, err = NewGCSDataSource("gs://bucket-1/cirros.qcow2#1", "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transfer()).To(Equal(cirrosData))
+	})
+
+	It("should fail when the generation of the URL does not exist", func() {
+		gd, err = NewGCSDataSource("gs://bucket-1/cirros.qcow2#7", "", "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("generation 7"))
+	})
+
+	table.DescribeTable("should reject", func(endpoint, serviceAccount, expectedErr string) {
+		gd, err = NewGCSDataSource(endpoint, serviceAccount, "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErr))
+	},
+		table.Entry("an http URL", "http://storage.googleapis.com/bucket-1/cirros.qcow2", "", "gs://bucket/object is expected"),
+		table.Entry("a URL without object", "gs://bucket-1", "", "does not name a bucket and an object"),
+		table.Entry("an invalid generation", "gs://bucket-1/cirros.qcow2#latest", "", "invalid generation"),
+		table.Entry("a key that is not JSON", "gs://bucket-1/cirros.qcow2", "secret", "the JSON key of a service account is expected"),
+		table.Entry("the key of a user", "gs://bucket-1/cirros.qcow2", `{"type": "authorized_user"}`, "the JSON key of a service account is expected"),
+	)
+
+	It("should escape the slashes of the name of an object", func() {
+		Expect(escapeGCSObject("images/disk 1.qcow2")).To(Equal("images%2Fdisk%201.qcow2"))
+	})
+})
+
+func newServiceAccountKeyJSON(key *rsa.PrivateKey, tokenURI string) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "importer@project.iam.gserviceaccount.com",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":      tokenURI,
+	})
+	Expect(err).NotTo(HaveOccurred())
+	return string(data)
+}
+
+// fakeGCSStorage serves the generations of an object with the JSON API of Cloud Storage, along with
+// the tokens of the metadata server, and of the token endpoint of a service account when its key is
+// set. The first failReads reads of the data of the object fail half way, and the reads resuming
+// them fail when failResumes is set.
+type fakeGCSStorage struct {
+	mu                sync.Mutex
+	generations       map[int64][]byte
+	latest            int64
+	token             string
+	serviceAccountKey *rsa.PublicKey
+	failReads         int
+	failResumes       bool
+	assertions        int
+	objects           []string
+	ranges            []string
+}
+
+func (s *fakeGCSStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.URL.Path == "/computeMetadata/v1/instance/service-accounts/default/token":
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token": %q, "token_type": "Bearer", "expires_in": 3600}`, s.token)
+	case r.URL.Path == "/token":
+		if err := s.verifyAssertion(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.assertions++
+		fmt.Fprintf(w, `{"access_token": %q, "token_type": "Bearer", "expires_in": 3600}`, s.token)
+	case strings.HasPrefix(r.URL.EscapedPath(), "/storage/v1/b/bucket-1/o/"):
+		if r.Header.Get("Authorization") != "Bearer "+s.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.serveObject(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *fakeGCSStorage) serveObject(w http.ResponseWriter, r *http.Request) {
+	object, _ := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/storage/v1/b/bucket-1/o/"))
+	s.objects = append(s.objects, object)
+	generation := s.latest
+	if value := r.URL.Query().Get("generation"); value != "" {
+		generation, _ = strconv.ParseInt(value, 10, 64)
+	}
+	data, ok := s.generations[generation]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.URL.Query().Get("alt") != "media" {
+		fmt.Fprintf(w, `{"generation": "%d", "size": "%d"}`, generation, len(data))
+		return
+	}
+	start := 0
+	if byteRange := r.Header.Get("Range"); byteRange != "" {
+		s.ranges = append(s.ranges, byteRange)
+		if s.failResumes {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Sscanf(byteRange, "bytes=%d-", &start)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)-start))
+		w.WriteHeader(http.StatusPartialContent)
+	} else {
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	}
+	if s.failReads > 0 {
+		s.failReads--
+		// the connection is closed once fewer bytes than the content length are written
+		w.Write(data[start : start+(len(data)-start)/2])
+		return
+	}
+	w.Write(data[start:])
+}
+
+func (s *fakeGCSStorage) verifyAssertion(r *http.Request) error {
+	if s.serviceAccountKey == nil {
+		return fmt.Errorf("no service account")
+	}
+	if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		return fmt.Errorf("invalid grant type %q", r.FormValue("grant_type"))
+	}
+	parts := strings.Split(r.FormValue("assertion"), ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid assertion")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(s.serviceAccountKey, crypto.SHA256, digest[:], signature); err != nil {
+		return err
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(claims, &decoded); err != nil {
+		return err
+	}
+	if decoded["iss"] != "importer@project.iam.gserviceaccount.com" || decoded["scope"] != gcsReadOnlyScope {
+		return fmt.Errorf("invalid claims %v", decoded)
+	}
+	return nil
+}
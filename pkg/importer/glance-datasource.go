@@ -0,0 +1,433 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+const (
+	// glanceImageService is the type of the image service in the catalog of a Keystone token
+	glanceImageService = "image"
+	// glanceActiveStatus is the status of the images whose data can be downloaded
+	glanceActiveStatus = "active"
+)
+
+var (
+	// glanceImageID matches the UUIDs identifying the images
+	glanceImageID = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+	// errGlanceNotFound is the error of the requests of missing images
+	errGlanceNotFound = errors.New("not found")
+)
+
+// glanceDiskFormatExtensions are the extensions selecting the formats of the disk_format values of
+// Glance, the formats without an extension are probed
+var glanceDiskFormatExtensions = map[string]string{
+	"raw":   image.ExtImg,
+	"iso":   image.ExtIso,
+	"qcow2": image.ExtQcow2,
+	"vmdk":  image.ExtVmdk,
+	"vdi":   image.ExtVdi,
+	"vhd":   image.ExtVhd,
+	"vhdx":  image.ExtVhdx,
+	"ploop": image.ExtHds,
+}
+
+// glanceHashes creates the hashes of the os_hash_algo values of Glance
+var glanceHashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha224": sha256.New224,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// GlanceDataSource is the struct containing the information needed to import an image of the
+// Glance service of an OpenStack cloud. A token is obtained from the Keystone service of the cloud
+// with an application credential, the image is found by its ID or its name, and its data is verified
+// against the checksum and the hash recorded by Glance once it is read.
+// Sequence of phases:
+// 1a. Info -> TransferDataFile, if the image is a raw image
+// 1b. Info -> Transfer in all other cases
+// 2. Transfer -> Convert
+type GlanceDataSource struct {
+	client *http.Client
+	// the image service and the token authenticating to it
+	endpoint string
+	token    string
+	// the image read
+	image *glanceImage
+	// Reader
+	glanceReader *glanceImageReader
+	// stack of readers, created by Info
+	sourceReaders
+	// The image file in scratch space.
+	url *url.URL
+	// the readers stop once ctx is done
+	ctx context.Context
+}
+
+// glanceImage holds the fields of the metadata of an image
+type glanceImage struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	DiskFormat  string `json:"disk_format"`
+	Size        *int64 `json:"size"`
+	Checksum    string `json:"checksum"`
+	OsHashAlgo  string `json:"os_hash_algo"`
+	OsHashValue string `json:"os_hash_value"`
+}
+
+// keystoneCatalog holds the service catalog of a Keystone token
+type keystoneCatalog struct {
+	Token struct {
+		Catalog []struct {
+			Type      string `json:"type"`
+			Endpoints []struct {
+				Interface string `json:"interface"`
+				Region    string `json:"region"`
+				RegionID  string `json:"region_id"`
+				URL       string `json:"url"`
+			} `json:"endpoints"`
+		} `json:"catalog"`
+	} `json:"token"`
+}
+
+// NewGlanceDataSource creates a new instance of the GlanceDataSource, authenticating to the Keystone
+// service of the endpoint with the ID and the secret of an application credential. The image is the
+// ID or the name of an image, a name is looked up among the images of the project unless it is
+// empty. The image service of the region is used, the first one of the catalog when the region is
+// empty. The CA certificates of the services are read from certDir, unless it is empty.
+func NewGlanceDataSource(endpoint, credentialID, credentialSecret, imageName, project, region, certDir string) (*GlanceDataSource, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, fmt.Sprintf("unable to parse endpoint %q", endpoint))
+	}
+	if imageName == "" {
+		return nil, errors.New("the ID or the name of the glance image is required")
+	}
+	client, err := createHTTPClient(certDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating http client for glance")
+	}
+	gd := &GlanceDataSource{client: client, ctx: context.Background()}
+	if err = gd.authenticate(ep, credentialID, credentialSecret, region); err != nil {
+		return nil, err
+	}
+	if gd.image, err = gd.findImage(imageName, project); err != nil {
+		return nil, err
+	}
+	if gd.image.Status != glanceActiveStatus {
+		return nil, errors.Errorf("glance image %q is %s, an %s image is expected", gd.image.ID, gd.image.Status, glanceActiveStatus)
+	}
+	klog.V(1).Infof("glance image %s %q, disk format %q", gd.image.ID, gd.image.Name, gd.image.DiskFormat)
+	return gd, nil
+}
+
+// authenticate gets a token of the application credential, and the endpoint of the image service
+// of the region from the catalog of the token.
+func (gd *GlanceDataSource) authenticate(ep *url.URL, credentialID, credentialSecret, region string) error {
+	if credentialID == "" || credentialSecret == "" {
+		return errors.New("the ID and the secret of an application credential are required to authenticate to keystone")
+	}
+	auth := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"application_credential"},
+				"application_credential": map[string]string{
+					"id":     credentialID,
+					"secret": credentialSecret,
+				},
+			},
+		},
+	}
+	body, err := json.Marshal(auth)
+	if err != nil {
+		return err
+	}
+	identity := strings.TrimSuffix(ep.String(), "/")
+	if !strings.HasSuffix(identity, "/v3") {
+		identity += "/v3"
+	}
+	resp, err := gd.client.Post(identity+"/auth/tokens", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not authenticate to keystone")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return errors.Errorf("could not authenticate to keystone: %s", resp.Status)
+	}
+	gd.token = resp.Header.Get("X-Subject-Token")
+	if gd.token == "" {
+		return errors.New("keystone returned no token")
+	}
+	catalog := &keystoneCatalog{}
+	if err := json.NewDecoder(resp.Body).Decode(catalog); err != nil {
+		return errors.Wrap(err, "invalid keystone token response")
+	}
+	for _, service := range catalog.Token.Catalog {
+		if service.Type != glanceImageService {
+			continue
+		}
+		for _, endpoint := range service.Endpoints {
+			if endpoint.Interface != "public" || (region != "" && endpoint.Region != region && endpoint.RegionID != region) {
+				continue
+			}
+			gd.endpoint = strings.TrimSuffix(strings.TrimSuffix(endpoint.URL, "/"), "/v2")
+			return nil
+		}
+	}
+	if region != "" {
+		return errors.Errorf("no public image service of region %q in the keystone catalog", region)
+	}
+	return errors.New("no public image service in the keystone catalog")
+}
+
+// findImage returns the image of an ID, or the only image of a name, owned by the project unless it
+// is empty.
+func (gd *GlanceDataSource) findImage(imageName, project string) (*glanceImage, error) {
+	if glanceImageID.MatchString(imageName) {
+		found := &glanceImage{}
+		err := gd.get("/v2/images/"+url.PathEscape(imageName), found)
+		if err == nil {
+			return found, nil
+		}
+		if !errors.Is(err, errGlanceNotFound) {
+			return nil, err
+		}
+		// the name of an image may look like an ID
+	}
+	query := url.Values{"name": {imageName}}
+	if project != "" {
+		query.Set("owner", project)
+	}
+	var list struct {
+		Images []glanceImage `json:"images"`
+	}
+	if err := gd.get("/v2/images?"+query.Encode(), &list); err != nil {
+		return nil, err
+	}
+	switch len(list.Images) {
+	case 0:
+		return nil, errors.Errorf("glance image %q not found", imageName)
+	case 1:
+		return &list.Images[0], nil
+	default:
+		return nil, errors.Errorf("glance image name %q is ambiguous, %d images have this name, use the ID of the image", imageName, len(list.Images))
+	}
+}
+
+// get decodes the JSON response of a request of the image service.
+func (gd *GlanceDataSource) get(path string, v interface{}) error {
+	resp, err := gd.request(path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "invalid glance response")
+	}
+	return nil
+}
+
+// request sends a request of the image service, authenticated with the token.
+func (gd *GlanceDataSource) request(path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(gd.ctx, http.MethodGet, gd.endpoint+path, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create glance request")
+	}
+	req.Header.Set("X-Auth-Token", gd.token)
+	resp, err := gd.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not reach glance")
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errors.Wrapf(errGlanceNotFound, "glance request %s", path)
+		}
+		return nil, errors.Errorf("glance request %s failed: %s", path, resp.Status)
+	}
+	return resp, nil
+}
+
+// Info is called to get initial information about the data. The disk_format of the image selects
+// its format, the formats of Glance without an extension are probed.
+func (gd *GlanceDataSource) Info() (ProcessingPhase, error) {
+	resp, err := gd.request("/v2/images/" + url.PathEscape(gd.image.ID) + "/file")
+	if err != nil {
+		klog.Errorf("Error downloading glance image: %v", err)
+		return ProcessingPhaseError, err
+	}
+	gd.glanceReader = newGlanceImageReader(resp.Body, gd.image)
+	var size uint64
+	if gd.image.Size != nil && *gd.image.Size > 0 {
+		size = uint64(*gd.image.Size)
+	}
+	gd.readers, err = newFormatReaders(gd.ctx, gd.glanceReader, size, gd.image.ID+glanceDiskFormatExtensions[gd.image.DiskFormat])
+	if err != nil {
+		klog.Errorf("Error creating readers: %v", err)
+		return ProcessingPhaseError, err
+	}
+	if !gd.readers.Convert {
+		// Downloading a raw file, we can write that directly to the target.
+		return ProcessingPhaseTransferDataFile, nil
+	}
+	return ProcessingPhaseTransferScratch, nil
+}
+
+// SetContext stops the transfer once ctx is done.
+func (gd *GlanceDataSource) SetContext(ctx context.Context) {
+	gd.ctx = ctx
+}
+
+// Transfer is called to transfer the data from the source to a temporary location.
+func (gd *GlanceDataSource) Transfer(path string) (ProcessingPhase, error) {
+	size, _ := util.GetAvailableSpace(path)
+	if size <= int64(0) {
+		//Path provided is invalid.
+		return ProcessingPhaseError, ErrInvalidPath
+	}
+	file := filepath.Join(path, tempFile)
+	gd.readers.StartProgressUpdate()
+	if err := gd.readers.StreamToFile(file); err != nil {
+		return ProcessingPhaseError, err
+	}
+	if err := gd.glanceReader.verify(); err != nil {
+		return ProcessingPhaseError, err
+	}
+	// If streaming succeeded, then parsing the file into URL will also succeed, no need to check error status
+	gd.url, _ = url.Parse(file)
+	return ProcessingPhaseConvert, nil
+}
+
+// TransferFile is called to transfer the data from the source to the passed in file.
+func (gd *GlanceDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
+	gd.readers.StartProgressUpdate()
+	if err := gd.readers.StreamToFile(fileName); err != nil {
+		return ProcessingPhaseError, err
+	}
+	if err := gd.glanceReader.verify(); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseResize, nil
+}
+
+// GetURL returns the url that the data processor can use when converting the data.
+func (gd *GlanceDataSource) GetURL() *url.URL {
+	return gd.url
+}
+
+// Close closes any readers or other open resources.
+func (gd *GlanceDataSource) Close() error {
+	if gd.readers != nil {
+		return gd.readers.Close()
+	}
+	if gd.glanceReader != nil {
+		return gd.glanceReader.Close()
+	}
+	return nil
+}
+
+// glanceImageReader reads the data of an image, hashed with the algorithms of its checksum, md5,
+// and of its os_hash_value. The data is checked once it is read to the end: a mismatch fails the
+// read returning io.EOF.
+type glanceImageReader struct {
+	body     io.ReadCloser
+	id       string
+	hashes   map[string]hash.Hash
+	expected map[string]string
+	// err is the result of the check of the data, once it is read
+	err  error
+	done bool
+}
+
+func newGlanceImageReader(body io.ReadCloser, img *glanceImage) *glanceImageReader {
+	r := &glanceImageReader{body: body, id: img.ID, hashes: map[string]hash.Hash{}, expected: map[string]string{}}
+	if img.Checksum != "" {
+		r.hashes["md5"], r.expected["md5"] = md5.New(), strings.ToLower(img.Checksum)
+	}
+	if img.OsHashAlgo != "" && img.OsHashValue != "" {
+		newHash, ok := glanceHashes[strings.ToLower(img.OsHashAlgo)]
+		if !ok {
+			klog.Warningf("Unknown hash algorithm %q of glance image %s, not verifying its hash", img.OsHashAlgo, img.ID)
+		} else {
+			algo := strings.ToLower(img.OsHashAlgo)
+			r.hashes[algo], r.expected[algo] = newHash(), strings.ToLower(img.OsHashValue)
+		}
+	}
+	if len(r.expected) == 0 {
+		klog.Warningf("Glance image %s has no checksum, its data is not verified", img.ID)
+	}
+	return r
+}
+
+// Read reads the data of the image, and checks it once it is read to the end.
+func (r *glanceImageReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, r.result()
+	}
+	n, err := r.body.Read(p)
+	for _, h := range r.hashes {
+		h.Write(p[:n])
+	}
+	if err == io.EOF {
+		r.done = true
+		r.err = r.check()
+		return n, r.result()
+	}
+	return n, err
+}
+
+// result returns io.EOF if the data matches its checksums, the mismatch otherwise.
+func (r *glanceImageReader) result() error {
+	if r.err != nil {
+		return r.err
+	}
+	return io.EOF
+}
+
+// check compares the data read to the checksums of the image.
+func (r *glanceImageReader) check() error {
+	for algo, expected := range r.expected {
+		if actual := hex.EncodeToString(r.hashes[algo].Sum(nil)); actual != expected {
+			return errors.Errorf("the data of glance image %s does not match its %s checksum %s, the data read has the checksum %s", r.id, algo, expected, actual)
+		}
+		klog.V(1).Infof("Verified the %s checksum of glance image %s", algo, r.id)
+	}
+	return nil
+}
+
+// verify reads the rest of the data, the format readers may stop before its end, and returns the
+// result of its check.
+func (r *glanceImageReader) verify() error {
+	_, err := io.Copy(io.Discard, r)
+	return err
+}
+
+// Close closes the body of the download.
+func (r *glanceImageReader) Close() error {
+	return r.body.Close()
+}
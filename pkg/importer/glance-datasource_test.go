@@ -0,0 +1,246 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	testGlanceCredentialID     = "credential-id"
+	testGlanceCredentialSecret = "credential-secret"
+	testGlanceToken            = "glance-token"
+	testGlanceCirrosID         = "3b5a8e0e-6d2c-4f7a-9c1e-0f2d4b6a8c01"
+	testGlanceRawID            = "7c1d2e3f-4a5b-4c6d-8e9f-a0b1c2d3e4f5"
+)
+
+// fakeGlance serves the token requests of Keystone, authenticating an application credential, and
+// the images of Glance, in the catalog of the tokens as the image service of RegionTwo.
+type fakeGlance struct {
+	*httptest.Server
+	images map[string]*glanceImage
+	data   map[string][]byte
+	owners map[string]string
+	// downloads are the IDs of the images downloaded
+	downloads []string
+}
+
+func newFakeGlance() *fakeGlance {
+	g := &fakeGlance{images: map[string]*glanceImage{}, data: map[string][]byte{}, owners: map[string]string{}}
+	g.Server = httptest.NewServer(http.HandlerFunc(g.serve))
+	return g
+}
+
+// addImage adds an active image, with the md5 checksum and the sha512 hash of its data.
+func (g *fakeGlance) addImage(id, name, diskFormat, owner string, data []byte) *glanceImage {
+	md5sum, sha512sum := md5.Sum(data), sha512.Sum512(data)
+	size := int64(len(data))
+	img := &glanceImage{
+		ID:          id,
+		Name:        name,
+		Status:      glanceActiveStatus,
+		DiskFormat:  diskFormat,
+		Size:        &size,
+		Checksum:    hex.EncodeToString(md5sum[:]),
+		OsHashAlgo:  "sha512",
+		OsHashValue: hex.EncodeToString(sha512sum[:]),
+	}
+	g.images[id], g.data[id], g.owners[id] = img, data, owner
+	return img
+}
+
+func (g *fakeGlance) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/identity/v3/auth/tokens" {
+		g.serveToken(w, r)
+		return
+	}
+	if r.Header.Get("X-Auth-Token") != testGlanceToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch path := strings.TrimPrefix(r.URL.Path, "/image/v2/images"); {
+	case path == "":
+		var found []*glanceImage
+		for id, img := range g.images {
+			owner := r.URL.Query().Get("owner")
+			if img.Name == r.URL.Query().Get("name") && (owner == "" || owner == g.owners[id]) {
+				found = append(found, img)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"images": found})
+	case strings.HasSuffix(path, "/file"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/file")
+		data, ok := g.data[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		g.downloads = append(g.downloads, id)
+		w.Write(data)
+	default:
+		img, ok := g.images[strings.TrimPrefix(path, "/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(img)
+	}
+}
+
+func (g *fakeGlance) serveToken(w http.ResponseWriter, r *http.Request) {
+	var auth struct {
+		Auth struct {
+			Identity struct {
+				Methods               []string `json:"methods"`
+				ApplicationCredential struct {
+					ID     string `json:"id"`
+					Secret string `json:"secret"`
+				} `json:"application_credential"`
+			} `json:"identity"`
+		} `json:"auth"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&auth); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	credential := auth.Auth.Identity.ApplicationCredential
+	if credential.ID != testGlanceCredentialID || credential.Secret != testGlanceCredentialSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("X-Subject-Token", testGlanceToken)
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `{"token": {"catalog": [
+		{"type": "identity", "endpoints": [{"interface": "public", "region": "RegionTwo", "url": "%[1]s/identity"}]},
+		{"type": "image", "endpoints": [
+			{"interface": "internal", "region": "RegionTwo", "url": "http://glance.internal:9292"},
+			{"interface": "public", "region": "RegionOne", "url": "http://glance.example.com:9292"},
+			{"interface": "public", "region": "RegionTwo", "region_id": "RegionTwo", "url": "%[1]s/image/v2/"}
+		]}
+	]}}`, g.URL)
+}
+
+var _ = Describe("Glance data source", func() {
+	var (
+		gd      *GlanceDataSource
+		glance  *fakeGlance
+		tmpDir  string
+		rawData []byte
+		err     error
+	)
+
+	BeforeEach(func() {
+		tmpDir, err = os.MkdirTemp("", "scratch")
+		Expect(err).NotTo(HaveOccurred())
+		rawData = bytes.Repeat([]byte("glance raw image data\n"), 4096)
+		glance = newFakeGlance()
+		glance.addImage(testGlanceCirrosID, "cirros", "qcow2", "demo", cirrosData)
+		glance.addImage(testGlanceRawID, "raw-image", "raw", "demo", rawData)
+	})
+
+	AfterEach(func() {
+		if gd != nil {
+			gd.Close()
+			gd = nil
+		}
+		glance.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	newDataSource := func(image, project string) (*GlanceDataSource, error) {
+		return NewGlanceDataSource(glance.URL+"/identity/v3", testGlanceCredentialID, testGlanceCredentialSecret, image, project, "RegionTwo", "")
+	}
+
+	It("should convert a qcow2 image found by its name, once its data is verified", func() {
+		gd, err = newDataSource("cirros", "")
+		Expect(err).NotTo(HaveOccurred())
+		phase, err := gd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferScratch))
+		phase, err = gd.Transfer(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseConvert))
+		data, err := os.ReadFile(filepath.Join(tmpDir, tempFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(cirrosData))
+		Expect(glance.downloads).To(Equal([]string{testGlanceCirrosID}))
+	})
+
+	It("should write a raw image found by its ID directly", func() {
+		gd, err = NewGlanceDataSource(glance.URL+"/identity", testGlanceCredentialID, testGlanceCredentialSecret, testGlanceRawID, "", "RegionTwo", "")
+		Expect(err).NotTo(HaveOccurred())
+		phase, err := gd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferDataFile))
+		target := filepath.Join(tmpDir, "disk.img")
+		phase, err = gd.TransferFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseResize))
+		data, err := os.ReadFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes.Equal(data, rawData)).To(BeTrue())
+	})
+
+	It("should find an image by its name among the images of the project", func() {
+		glance.addImage("9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b", "cirros", "qcow2", "other", tinyCoreVdiData)
+		gd, err = newDataSource("cirros", "demo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gd.image.ID).To(Equal(testGlanceCirrosID))
+	})
+
+	table.DescribeTable("should fail to find", func(image, project, credentialSecret, expected string) {
+		glance.addImage("9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b", "cirros", "qcow2", "other", tinyCoreVdiData)
+		glance.addImage("0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d", "queued", "qcow2", "demo", nil).Status = "queued"
+		_, err := NewGlanceDataSource(glance.URL+"/identity/v3", testGlanceCredentialID, credentialSecret, image, project, "RegionTwo", "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expected))
+	},
+		table.Entry("an image with a rejected credential", "cirros", "demo", "wrong", "could not authenticate to keystone"),
+		table.Entry("a missing image", "fedora", "", testGlanceCredentialSecret, "not found"),
+		table.Entry("an image of an ambiguous name", "cirros", "", testGlanceCredentialSecret, "ambiguous"),
+		table.Entry("an image which is not active", "queued", "", testGlanceCredentialSecret, "is queued"),
+	)
+
+	It("should fail without an image service in the region", func() {
+		_, err := NewGlanceDataSource(glance.URL+"/identity/v3", testGlanceCredentialID, testGlanceCredentialSecret, "cirros", "", "RegionThree", "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("no public image service of region \"RegionThree\""))
+	})
+
+	table.DescribeTable("should fail when the data does not match", func(corrupt func(*glanceImage), expected string) {
+		corrupt(glance.images[testGlanceRawID])
+		gd, err = newDataSource(testGlanceRawID, "")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = gd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = gd.TransferFile(filepath.Join(tmpDir, "disk.img"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expected))
+	},
+		table.Entry("its checksum", func(img *glanceImage) { img.Checksum = strings.Repeat("0", 32) }, "does not match its md5 checksum"),
+		table.Entry("its hash", func(img *glanceImage) { img.OsHashValue = strings.Repeat("0", 128) }, "does not match its sha512 checksum"),
+	)
+
+	It("should fail the transfer to the scratch space when the data does not match", func() {
+		glance.images[testGlanceCirrosID].Checksum = strings.Repeat("0", 32)
+		gd, err = newDataSource(testGlanceCirrosID, "")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = gd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = gd.Transfer(tmpDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("does not match its md5 checksum"))
+	})
+})
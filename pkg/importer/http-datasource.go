@@ -17,6 +17,7 @@ limitations under the License.
 package importer
 
 import (
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -33,6 +34,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/pkg/errors"
 
 	"k8s.io/klog/v2"
@@ -41,6 +43,7 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/util"
+	prometheusutil "kubevirt.io/containerized-data-importer/pkg/util/prometheus"
 )
 
 const (
@@ -52,8 +55,9 @@ const (
 
 // HTTPDataSource is the data provider for http(s) endpoints.
 // Sequence of phases:
-// 1a. Info -> Convert (In Info phase the format readers are configured), if the source Reader image is not archived, and no custom CA is used, and can be converted by QEMU-IMG (RAW/QCOW2)
+// 1a. Info -> Convert (In Info phase the format readers are configured), if the source Reader image is not archived, or only compressed with xz, and no custom CA is used, and can be converted by QEMU-IMG (RAW/QCOW2)
 // 1b. Info -> TransferArchive if the content type is archive
+// 1b'. Info -> TransferDataFile if the raw data is archived, is an ISO9660 image, or a custom CA is used
 // 1c. Info -> Transfer in all other cases.
 // 2a. Transfer -> Convert if content type is kube virt
 // 2b. Transfer -> Complete if content type is archive (Transfer is called with the target instead of the scratch space). Non block PVCs only.
@@ -64,8 +68,8 @@ type HTTPDataSource struct {
 	cancelLock sync.Mutex
 	// content type expected by the to live on the endpoint.
 	contentType cdiv1.DataVolumeContentType
-	// stack of readers
-	readers *FormatReaders
+	// stack of readers, created by Info
+	sourceReaders
 	// endpoint the http endpoint to retrieve the data from.
 	endpoint *url.URL
 	// url the url to report to the caller of getURL, could be the endpoint, or a file in scratch space.
@@ -76,18 +80,66 @@ type HTTPDataSource struct {
 	brokenForQemuImg bool
 	// the content length reported by the http server.
 	contentLength uint64
+	// true if the http client decoded the Content-Encoding of the response.
+	contentDecoded bool
+	// reads the endpoint at random, with range requests.
+	rangeReader io.ReaderAt
+	// the body of the response, when its download can be resumed.
+	download *resumableBody
+	// the endpoint is reached through a proxy trusted with the CA bundle of the proxy configuration.
+	proxyCA bool
+	// the endpoint is authenticated with the client certificate of the secret, which nbdkit does not present.
+	clientCert bool
+	// the endpoint has mirrors, the URL which served the data is reported.
+	mirrored bool
+	// the endpoint was matched in the index of its directory, the URL which served the data is reported.
+	matched bool
+	// the URL the endpoint, or its mirror, was redirected to, which served the data.
+	resolved *url.URL
+	// the endpoint was redirected to another origin, with credentials or limited redirects nbdkit
+	// would not strip nor enforce.
+	redirected bool
+	// the number of ranges of the data downloaded at once to scratch space.
+	concurrency int
+	// the detached signature the data is verified against, if any.
+	signature *sourceSignature
+	// the validators of the data of the endpoint, or of its mirror which served it.
+	validators SourceValidators
 
 	n image.NbdkitOperation
 }
 
 var createNbdkitCurl = image.NewNbdkitCurl
 
+// acceptEncoding is the Accept-Encoding header sent when the user did not provide one.
+const acceptEncoding = "br, gzip"
+
+// ErrInvalidClientCertificate is the error of a client certificate that is expired, not yet valid or does not match
+// its key, retrying the import cannot succeed.
+var ErrInvalidClientCertificate = fmt.Errorf("invalid client certificate")
+
 // NewHTTPDataSource creates a new instance of the http data provider.
 func NewHTTPDataSource(endpoint, accessKey, secKey, certDir string, contentType cdiv1.DataVolumeContentType) (*HTTPDataSource, error) {
 	ep, err := ParseEndpoint(endpoint)
 	if err != nil {
 		return nil, errors.Wrapf(err, fmt.Sprintf("unable to parse endpoint %q", endpoint))
 	}
+	mirrors, err := getMirrors()
+	if err != nil {
+		return nil, err
+	}
+	concurrency, err := httpConcurrency()
+	if err != nil {
+		return nil, err
+	}
+	limits, err := httpRedirectLimits()
+	if err != nil {
+		return nil, err
+	}
+	previous, err := PreviousSourceValidators()
+	if err != nil {
+		return nil, err
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 
 	extraHeaders, secretExtraHeaders, err := getExtraHeaders()
@@ -95,12 +147,70 @@ func NewHTTPDataSource(endpoint, accessKey, secKey, certDir string, contentType
 		cancel()
 		return nil, errors.Wrap(err, "Error getting extra headers for HTTP client")
 	}
+	if token, _ := util.ParseEnvVar(common.ImporterBearerToken, false); strings.TrimSpace(token) != "" {
+		// the bearer token of the secret authenticates the requests instead of its access key
+		accessKey, secKey = "", ""
+		secretExtraHeaders = append(secretExtraHeaders, "Authorization: Bearer "+strings.TrimSpace(token))
+	}
+	clientCert, err := clientCertificate()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	pattern, err := httpURLPattern()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if pattern != "" {
+		// the endpoint is the newest file of its directory matching the pattern ending its URL
+		matched, err := resolveIndexedEndpoint(ctx, ep, pattern, accessKey, secKey, certDir, extraHeaders, secretExtraHeaders)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		for i, mirror := range mirrors {
+			mirrors[i] = resolveMirror(mirror, path.Base(matched.Path))
+		}
+		if !sameOrigin(matched, ep) {
+			// the index was redirected to another origin
+			accessKey, secKey, secretExtraHeaders = "", "", nil
+		}
+		ep = matched
+	}
+	// the signature is verified to be issued by a trusted key before the data is requested
+	signature, err := newSourceSignature(ctx, certDir)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 
-	httpReader, contentLength, brokenForQemuImg, err := createHTTPReader(ctx, ep, accessKey, secKey, certDir, extraHeaders, secretExtraHeaders)
+	endpoints := append([]*url.URL{ep}, mirrors...)
+	// the data is only served if it changed since its previous import
+	conditionalHeaders := append(previous.conditionalHeaders(), extraHeaders...)
+	httpReader, contentLength, brokenForQemuImg, served, resp, err := createMirroredHTTPReader(ctx, endpoints, accessKey, secKey, certDir, conditionalHeaders, secretExtraHeaders)
 	if err != nil {
 		cancel()
+		var statusErr *httpStatusError
+		if errors.As(err, &statusErr) && statusErr.code == http.StatusNotModified {
+			return nil, ErrSourceUnchanged
+		}
 		return nil, err
 	}
+	validators := responseValidators(resp)
+	if !previous.Empty() && validators.Matches(previous) {
+		// the server ignored the conditional request
+		httpReader.Close()
+		cancel()
+		return nil, ErrSourceUnchanged
+	}
+	resolved := resp.Request.URL
+	if !sameOrigin(served, ep) {
+		// the credentials are only sent to the origin of the endpoint, as when redirected
+		accessKey, secKey, secretExtraHeaders = "", "", nil
+	}
+	ep = served
+	credentials := accessKey != "" || len(secretExtraHeaders) > 0
 
 	httpSource := &HTTPDataSource{
 		ctx:              ctx,
@@ -111,10 +221,34 @@ func NewHTTPDataSource(endpoint, accessKey, secKey, certDir string, contentType
 		customCA:         certDir,
 		brokenForQemuImg: brokenForQemuImg,
 		contentLength:    contentLength,
+		clientCert:       clientCert != nil,
+		mirrored:         len(mirrors) > 0,
+		matched:          pattern != "",
+		resolved:         resolved,
+		redirected:       !sameOrigin(resolved, ep) && (credentials || limits.configured()),
+		concurrency:      concurrency,
+		signature:        signature,
+		validators:       validators,
 	}
 	httpSource.n = createNbdkitCurl(nbdkitPid, accessKey, secKey, certDir, nbdkitSocket, extraHeaders, secretExtraHeaders)
+	if proxy, err := importProxyFromEnvironment(); err == nil && proxy.configured() {
+		if proxyURL, _ := proxy.proxyFor(&http.Request{URL: ep}); proxyURL == nil {
+			// nbdkit reaches the endpoint directly too, whatever the no_proxy list of curl matches
+			httpSource.n.AddEnvVariable("no_proxy=*")
+		} else {
+			// curl does not trust the CA bundle of the proxy, the endpoint is not served by nbdkit
+			httpSource.proxyCA = proxyCertDir() != ""
+		}
+	}
+	httpSource.rangeReader, err = newHTTPRangeReader(ctx, ep, accessKey, secKey, certDir, extraHeaders, secretExtraHeaders)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 	// We know this is a counting reader, so no need to check.
 	countingReader := httpReader.(*util.CountingReader)
+	_, httpSource.contentDecoded = countingReader.Reader.(*decodedBody)
+	httpSource.download, _ = countingReader.Reader.(*resumableBody)
 	go httpSource.pollProgress(countingReader, 10*time.Minute, time.Second)
 	return httpSource, nil
 }
@@ -122,20 +256,44 @@ func NewHTTPDataSource(endpoint, accessKey, secKey, certDir string, contentType
 // Info is called to get initial information about the data.
 func (hs *HTTPDataSource) Info() (ProcessingPhase, error) {
 	var err error
-	hs.readers, err = NewFormatReaders(hs.httpReader, hs.contentLength)
+	// The extension of the URL helps when the headers are not enough to detect the format, unless
+	// the data was decoded already because of the Content-Encoding of the response.
+	name := hs.endpoint.Path
+	if hs.contentDecoded {
+		name = ""
+	}
+	stream := hs.httpReader
+	if hs.signature != nil {
+		// the data is hashed from its first byte, before its format is detected
+		stream = hs.signature.newReader(stream)
+	}
+	hs.readers, err = newFormatReaders(hs.ctx, stream, hs.contentLength, name)
 	if err != nil {
 		klog.Errorf("Error creating readers: %v", err)
 		return ProcessingPhaseError, err
 	}
+	hs.readers.signature = hs.signature
 	if hs.contentType == cdiv1.DataVolumeArchive {
 		return ProcessingPhaseTransferDataDir, nil
 	}
+	// nbdkit would read the endpoint regardless of the bandwidth limit, of the checksum and of the signature
+	streamed := hs.readers.mustStream()
 	if hs.readers.Convert {
-		if hs.brokenForQemuImg || hs.readers.Archived || hs.customCA != "" {
+		if hs.brokenForQemuImg || hs.customCA != "" || hs.proxyCA || hs.clientCert || hs.redirected || streamed {
 			return ProcessingPhaseTransferScratch, nil
 		}
+		// nbdkit serves the endpoint to qemu-img, decompressing it if needed, unless it has to be
+		// copied to scratch space first
+		filter, ok := hs.readers.nbdkitStream(hs.rangeReader, int64(hs.contentLength))
+		if !ok {
+			return ProcessingPhaseTransferScratch, nil
+		}
+		if filter != "" {
+			hs.n.AddFilter(filter)
+		}
 	} else {
-		if hs.readers.Archived || hs.customCA != "" {
+		// an ISO9660 image is copied as it is, qemu-img would only copy it once more
+		if hs.readers.Archived || hs.customCA != "" || hs.readers.ISO || hs.redirected || streamed {
 			return ProcessingPhaseTransferDataFile, nil
 		}
 	}
@@ -146,6 +304,55 @@ func (hs *HTTPDataSource) Info() (ProcessingPhase, error) {
 	return ProcessingPhaseConvert, nil
 }
 
+// SourceURL returns the URL of the endpoint, of its mirror, or the URL it was redirected to, which
+// served the data, without its user info and query, empty when the endpoint has no mirrors, was not
+// matched in the index of its directory and was not redirected.
+func (hs *HTTPDataSource) SourceURL() string {
+	served := *hs.endpoint
+	if hs.resolved != nil {
+		served = *hs.resolved
+	}
+	if hs.download != nil && hs.download.req.URL.String() != hs.endpoint.String() {
+		// the download failed over to a mirror
+		served = *hs.download.req.URL
+	}
+	if !hs.mirrored && !hs.matched && served.String() == hs.endpoint.String() {
+		return ""
+	}
+	served.User, served.RawQuery, served.Fragment = nil, "", ""
+	return served.String()
+}
+
+// SourceValidators returns the validators of the data of the endpoint, or of its mirror which served
+// it.
+func (hs *HTTPDataSource) SourceValidators() SourceValidators {
+	return hs.validators
+}
+
+// Signer returns the fingerprint of the primary key of the signer of the data, once verified against
+// its detached signature, empty otherwise.
+func (hs *HTTPDataSource) Signer() string {
+	if hs.signature != nil {
+		return hs.signature.fingerprint()
+	}
+	return ""
+}
+
+// SetContext cancels the transfer once ctx is done.
+func (hs *HTTPDataSource) SetContext(ctx context.Context) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			hs.cancelLock.Lock()
+			if hs.cancel != nil {
+				hs.cancel()
+			}
+			hs.cancelLock.Unlock()
+		case <-hs.ctx.Done():
+		}
+	}()
+}
+
 // Transfer is called to transfer the data from the source to a scratch location.
 func (hs *HTTPDataSource) Transfer(path string) (ProcessingPhase, error) {
 	if hs.contentType == cdiv1.DataVolumeKubeVirt {
@@ -155,7 +362,12 @@ func (hs *HTTPDataSource) Transfer(path string) (ProcessingPhase, error) {
 			return ProcessingPhaseError, ErrInvalidPath
 		}
 		file := filepath.Join(path, tempFile)
-		err = util.StreamDataToFile(hs.readers.TopReader(), file)
+		hs.readers.StartProgressUpdate()
+		if hs.download != nil && !hs.readers.spooled() {
+			err = hs.transferResumable(path, file)
+		} else {
+			err = hs.readers.StreamToFile(file)
+		}
 		if err != nil {
 			return ProcessingPhaseError, err
 		}
@@ -166,16 +378,56 @@ func (hs *HTTPDataSource) Transfer(path string) (ProcessingPhase, error) {
 		if err := util.UnArchiveTar(hs.readers.TopReader(), path); err != nil {
 			return ProcessingPhaseError, errors.Wrap(err, "unable to untar files from endpoint")
 		}
+		if err := hs.readers.verifySource(); err != nil {
+			return ProcessingPhaseError, err
+		}
 		hs.url = nil
 		return ProcessingPhaseComplete, nil
 	}
 	return ProcessingPhaseError, errors.Errorf("Unknown content type: %s", hs.contentType)
 }
 
+// transferResumable transfers the data to file, recording the data downloaded as it is to the
+// scratch space of path so that the next attempt of a failed import resumes its download. The data
+// downloaded is the image unless it is compressed or archived, it is then decompressed to file.
+// The ranges of the data are downloaded concurrently first, unless its bandwidth is limited.
+func (hs *HTTPDataSource) transferResumable(path, file string) error {
+	if err := hs.download.persist(path); err != nil {
+		return err
+	}
+	if hs.concurrency > 1 && hs.readers.bandwidthLimit == 0 {
+		if err := hs.download.fill(hs.concurrency, hs.updateDownloadProgress); err != nil {
+			return err
+		}
+	}
+	if hs.readers.Archived {
+		if err := hs.readers.StreamToFile(file); err != nil {
+			return err
+		}
+		return hs.download.complete("")
+	}
+	if err := hs.readers.Discard(); err != nil {
+		return err
+	}
+	return hs.download.complete(file)
+}
+
+// updateDownloadProgress reports the progress of the download of the ranges of the data.
+func (hs *HTTPDataSource) updateDownloadProgress(downloaded int64) {
+	max := hs.readers.progressMax
+	if max == 0 {
+		max = 100.0
+	}
+	currentProgress := float64(downloaded) / float64(hs.download.validators.Size) * max
+	prometheusutil.SetProgress(progress, ownerUID, currentProgress)
+	prometheusutil.SetBytes(transferredBytes, ownerUID, uint64(downloaded))
+	klog.V(1).Infof("%.2f, %d bytes downloaded", currentProgress, downloaded)
+}
+
 // TransferFile is called to transfer the data from the source to the passed in file.
 func (hs *HTTPDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
 	hs.readers.StartProgressUpdate()
-	err := util.StreamDataToFile(hs.readers.TopReader(), fileName)
+	err := hs.readers.StreamToFile(fileName)
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
@@ -203,23 +455,11 @@ func (hs *HTTPDataSource) Close() error {
 }
 
 func createCertPool(certDir string) (*x509.CertPool, error) {
-	// let's get system certs as well
-	certPool, err := x509.SystemCertPool()
+	// let's get system certs as well, along with the user-provided trusted CA certificates bundle
+	// when making egress connections using proxy
+	certPool, err := proxyCertPool()
 	if err != nil {
-		return nil, errors.Wrap(err, "Error getting system certs")
-	}
-
-	// append the user-provided trusted CA certificates bundle when making egress connections using proxy
-	if files, err := os.ReadDir(common.ImporterProxyCertDir); err == nil {
-		for _, file := range files {
-			if file.IsDir() || file.Name()[0] == '.' {
-				continue
-			}
-			fp := path.Join(common.ImporterProxyCertDir, file.Name())
-			if certs, err := os.ReadFile(fp); err == nil {
-				certPool.AppendCertsFromPEM(certs)
-			}
-		}
+		return nil, err
 	}
 
 	// append server CA certificates
@@ -250,24 +490,78 @@ func createCertPool(certDir string) (*x509.CertPool, error) {
 	return certPool, nil
 }
 
+// clientCertificate returns the client certificate of the secret of the source, mounted in the directory of
+// IMPORTER_CLIENT_CERT_DIR, nil if the secret holds none. Its key is only read from the mounted secret, to memory.
+// It fails with ErrInvalidClientCertificate if the certificate is expired, not yet valid or does not match its key.
+func clientCertificate() (*tls.Certificate, error) {
+	dir := os.Getenv(common.ImporterClientCertDirVar)
+	if dir == "" {
+		return nil, nil
+	}
+	certPEM, certErr := os.ReadFile(filepath.Join(dir, common.KeyClientCert))
+	keyPEM, keyErr := os.ReadFile(filepath.Join(dir, common.KeyClientKey))
+	if os.IsNotExist(certErr) && os.IsNotExist(keyErr) {
+		return nil, nil
+	}
+	if certErr != nil || keyErr != nil {
+		return nil, fmt.Errorf("%w: the secret must hold both %s and %s", ErrInvalidClientCertificate, common.KeyClientCert, common.KeyClientKey)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidClientCertificate, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidClientCertificate, err)
+	}
+	now := time.Now()
+	if now.After(leaf.NotAfter) {
+		return nil, fmt.Errorf("%w: the certificate of %s expired on %s", ErrInvalidClientCertificate, leaf.Subject, leaf.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(leaf.NotBefore) {
+		return nil, fmt.Errorf("%w: the certificate of %s is not valid before %s", ErrInvalidClientCertificate, leaf.Subject, leaf.NotBefore.UTC().Format(time.RFC3339))
+	}
+	cert.Leaf = leaf
+	return &cert, nil
+}
+
 func createHTTPClient(certDir string) (*http.Client, error) {
 	client := &http.Client{
 		// Don't set timeout here, since that will be an absolute timeout, we need a relative to last progress timeout.
 	}
 
-	if certDir == "" {
-		return client, nil
+	proxy, err := importProxyFromEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	var certPool *x509.CertPool
+	if certDir != "" {
+		if certPool, err = createCertPool(certDir); err != nil {
+			return nil, err
+		}
+	} else if proxyCertDir() != "" {
+		// the CA of the proxy is trusted by the tunneled TLS connections too, the proxy may inspect them
+		if certPool, err = proxyCertPool(); err != nil {
+			return nil, err
+		}
 	}
 
-	certPool, err := createCertPool(certDir)
+	cert, err := clientCertificate()
 	if err != nil {
 		return nil, err
 	}
 
-	// the default transport contains Proxy configurations to use environment variables and default timeouts
+	// the default transport contains default timeouts, the proxy is chosen with the configuration of the environment
 	transport := http.DefaultTransport.(*http.Transport).Clone()
-	transport.TLSClientConfig = &tls.Config{
-		RootCAs: certPool,
+	transport.Proxy = proxy.proxyFor
+	if certPool != nil || cert != nil {
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs: certPool,
+		}
+		if cert != nil {
+			// the client certificate is presented to the servers requesting one
+			transport.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+		}
 	}
 	transport.GetProxyConnectHeader = func(ctx context.Context, proxyURL *url.URL, target string) (http.Header, error) {
 		h := http.Header{}
@@ -289,22 +583,132 @@ func addExtraheaders(req *http.Request, extraHeaders []string) {
 	req.Header.Add("User-Agent", defaultUserAgent)
 }
 
-func createHTTPReader(ctx context.Context, ep *url.URL, accessKey, secKey, certDir string, extraHeaders, secretExtraHeaders []string) (io.ReadCloser, uint64, bool, error) {
+// redirectPolicy returns the CheckRedirect function of a client authenticating its requests with
+// basic auth or with secret headers, following the redirects within limits, if any. The headers of
+// the first request are copied to the redirected requests, but the credentials are only sent to the
+// origin of the first request: they are removed from a request redirected to another origin, along
+// with any Authorization header.
+func redirectPolicy(limits *redirectLimits, accessKey, secKey string, secretExtraHeaders []string) func(*http.Request, []*http.Request) error {
+	return func(r *http.Request, via []*http.Request) error {
+		if limits != nil {
+			if err := limits.check(r, via); err != nil {
+				return err
+			}
+		}
+		for _, header := range secretExtraHeaders {
+			if name, _, ok := strings.Cut(header, ":"); ok {
+				r.Header.Del(name)
+			}
+		}
+		if !sameOrigin(r.URL, via[0].URL) {
+			r.Header.Del("Authorization")
+			return nil
+		}
+		// the client drops the Authorization header once redirected to another host, even when
+		// redirected back, the credentials are set again
+		for _, header := range secretExtraHeaders {
+			if name, value, ok := strings.Cut(header, ":"); ok {
+				r.Header.Add(name, value)
+			}
+		}
+		if len(accessKey) > 0 && len(secKey) > 0 {
+			r.SetBasicAuth(accessKey, secKey)
+		}
+		return nil
+	}
+}
+
+// sameOrigin returns true if both URLs have the same scheme, host and port.
+func sameOrigin(a, b *url.URL) bool {
+	port := func(u *url.URL) string {
+		if p := u.Port(); p != "" {
+			return p
+		}
+		if strings.EqualFold(u.Scheme, "https") {
+			return "443"
+		}
+		return "80"
+	}
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Hostname(), b.Hostname()) && port(a) == port(b)
+}
+
+// createMirroredHTTPReader returns the reader of the first of the endpoints which can be reached
+// and does not fail with a server error, that endpoint and its response. The
+// credentials are only sent to the endpoints of the origin of the first one. A download which can
+// be resumed fails over to the next endpoints once it cannot be resumed anymore.
+func createMirroredHTTPReader(ctx context.Context, endpoints []*url.URL, accessKey, secKey, certDir string, extraHeaders, secretExtraHeaders []string) (io.ReadCloser, uint64, bool, *url.URL, *http.Response, error) {
+	var err error
+	for i, ep := range endpoints {
+		epAccessKey, epSecKey, epSecretExtraHeaders := accessKey, secKey, secretExtraHeaders
+		if !sameOrigin(ep, endpoints[0]) {
+			epAccessKey, epSecKey, epSecretExtraHeaders = "", "", nil
+		}
+		var reader io.ReadCloser
+		var total uint64
+		var brokenForQemuImg bool
+		var resp *http.Response
+		reader, total, brokenForQemuImg, resp, err = createHTTPReader(ctx, ep, epAccessKey, epSecKey, certDir, extraHeaders, epSecretExtraHeaders)
+		if err == nil {
+			if download, ok := reader.(*util.CountingReader).Reader.(*resumableBody); ok {
+				// the validators identify the data of the endpoint, whichever mirror serves it
+				download.validators.URL = endpoints[0].String()
+				download.origin, download.mirrors = endpoints[0], endpoints[i+1:]
+				download.credentials = redirectPolicy(nil, accessKey, secKey, secretExtraHeaders)
+			}
+			return reader, total, brokenForQemuImg, ep, resp, nil
+		}
+		if i == len(endpoints)-1 || !isMirrorFailure(ctx, err) {
+			break
+		}
+		klog.Warningf("Could not download %s, trying the mirror %s: %v", ep, endpoints[i+1], err)
+	}
+	return nil, uint64(0), true, nil, nil, err
+}
+
+// isMirrorFailure returns true if the error of a request is worth trying the next mirror: the
+// server could not be reached or failed with a server error.
+func isMirrorFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// httpStatusError is the error of a response of an unexpected status code.
+type httpStatusError struct {
+	expected int
+	code     int
+	status   string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("expected status code %d, got %d. Status: %s", e.expected, e.code, e.status)
+}
+
+// Is makes the error of a 404 Not Found response match ErrSourceNotFound.
+func (e *httpStatusError) Is(target error) bool {
+	return target == ErrSourceNotFound && e.code == http.StatusNotFound
+}
+
+func createHTTPReader(ctx context.Context, ep *url.URL, accessKey, secKey, certDir string, extraHeaders, secretExtraHeaders []string) (io.ReadCloser, uint64, bool, *http.Response, error) {
 	var brokenForQemuImg bool
 	client, err := createHTTPClient(certDir)
 	if err != nil {
-		return nil, uint64(0), false, errors.Wrap(err, "Error creating http client")
+		return nil, uint64(0), false, nil, errors.Wrap(err, "Error creating http client")
 	}
 
 	allExtraHeaders := append(extraHeaders, secretExtraHeaders...)
 
-	client.CheckRedirect = func(r *http.Request, via []*http.Request) error {
-		if len(accessKey) > 0 && len(secKey) > 0 {
-			r.SetBasicAuth(accessKey, secKey) // Redirects will lose basic auth, so reset them manually
-		}
-		addExtraheaders(r, allExtraHeaders)
-		return nil
+	limits, err := httpRedirectLimits()
+	if err != nil {
+		return nil, uint64(0), false, nil, err
 	}
+	client.CheckRedirect = redirectPolicy(limits, accessKey, secKey, secretExtraHeaders)
 
 	total, err := getContentLength(client, ep, accessKey, secKey, allExtraHeaders)
 	if err != nil {
@@ -314,6 +718,11 @@ func createHTTPReader(ctx context.Context, ep *url.URL, accessKey, secKey, certD
 	req, _ := http.NewRequest("GET", ep.String(), nil)
 
 	addExtraheaders(req, allExtraHeaders)
+	if req.Header.Get("Accept-Encoding") == "" {
+		// Setting the header disables the transparent gzip decoding of the transport, gzip is
+		// decoded in decodeContentEncoding along with brotli.
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
 
 	req = req.WithContext(ctx)
 	if len(accessKey) > 0 && len(secKey) > 0 {
@@ -322,11 +731,12 @@ func createHTTPReader(ctx context.Context, ep *url.URL, accessKey, secKey, certD
 	klog.V(2).Infof("Attempting to get object %q via http client\n", ep.String())
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, uint64(0), true, errors.Wrap(err, "HTTP request errored")
+		return nil, uint64(0), true, nil, errors.Wrap(err, "HTTP request errored")
 	}
 	if resp.StatusCode != 200 {
 		klog.Errorf("http: expected status code 200, got %d", resp.StatusCode)
-		return nil, uint64(0), true, errors.Errorf("expected status code 200, got %d. Status: %s", resp.StatusCode, resp.Status)
+		resp.Body.Close()
+		return nil, uint64(0), true, nil, &httpStatusError{expected: http.StatusOK, code: resp.StatusCode, status: resp.Status}
 	}
 
 	acceptRanges, ok := resp.Header["Accept-Ranges"]
@@ -335,15 +745,127 @@ func createHTTPReader(ctx context.Context, ep *url.URL, accessKey, secKey, certD
 		brokenForQemuImg = true
 	}
 
-	if total == 0 {
+	body, decoded, err := decodeContentEncoding(resp)
+	if err != nil {
+		resp.Body.Close()
+		return nil, uint64(0), true, nil, err
+	}
+	if decoded {
+		// The content length is the one of the encoded data, and qemu-img would not decode it.
+		klog.V(2).Infof("Content-Encoding is %q, avoiding qemu-img", resp.Header.Get("Content-Encoding"))
+		total = 0
+		brokenForQemuImg = true
+	} else if total == 0 {
 		// The total seems bogus. Let's try the GET Content-Length header
 		total = parseHTTPHeader(resp)
 	}
+	if !decoded {
+		// a failed download is resumed with range requests, if the server allows it
+		if resumable := newResumableBody(ctx, client, req, resp); resumable != nil {
+			body = resumable
+		}
+	}
 	countingReader := &util.CountingReader{
-		Reader:  resp.Body,
+		Reader:  body,
 		Current: 0,
 	}
-	return countingReader, total, brokenForQemuImg, nil
+	if resp.Request.URL.String() != ep.String() {
+		klog.V(1).Infof("%s was redirected to %s", ep.Redacted(), resp.Request.URL.Redacted())
+	}
+	return countingReader, total, brokenForQemuImg, resp, nil
+}
+
+// httpRangeReader reads an http endpoint at random, with a range request for every read.
+type httpRangeReader struct {
+	ctx       context.Context
+	client    *http.Client
+	ep        *url.URL
+	accessKey string
+	secKey    string
+	headers   []string
+}
+
+func newHTTPRangeReader(ctx context.Context, ep *url.URL, accessKey, secKey, certDir string, extraHeaders, secretExtraHeaders []string) (*httpRangeReader, error) {
+	client, err := createHTTPClient(certDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating http client")
+	}
+	r := &httpRangeReader{
+		ctx:       ctx,
+		client:    client,
+		ep:        ep,
+		accessKey: accessKey,
+		secKey:    secKey,
+		headers:   append(append([]string{}, extraHeaders...), secretExtraHeaders...),
+	}
+	limits, err := httpRedirectLimits()
+	if err != nil {
+		return nil, err
+	}
+	client.CheckRedirect = redirectPolicy(limits, accessKey, secKey, secretExtraHeaders)
+	return r, nil
+}
+
+func (r *httpRangeReader) addHeaders(req *http.Request) {
+	if len(r.accessKey) > 0 && len(r.secKey) > 0 {
+		req.SetBasicAuth(r.accessKey, r.secKey)
+	}
+	addExtraheaders(req, r.headers)
+}
+
+// ReadAt reads len(p) bytes of the endpoint starting at off.
+func (r *httpRangeReader) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	req, _ := http.NewRequestWithContext(r.ctx, "GET", r.ep.String(), nil)
+	r.addHeaders(req)
+	req.Header.Set("Accept-Encoding", "identity")
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "HTTP range request errored")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, errors.Errorf("expected status code 206, got %d. Status: %s", resp.StatusCode, resp.Status)
+	}
+	n, err := io.ReadFull(resp.Body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// decodeContentEncoding returns a reader of the decoded response body, and whether the body had to
+// be decoded.
+func decodeContentEncoding(resp *http.Response) (io.ReadCloser, bool, error) {
+	var decoder io.Reader
+	var err error
+	switch encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return resp.Body, false, nil
+	case "br":
+		decoder = brotli.NewReader(resp.Body)
+	case "gzip", "x-gzip":
+		decoder, err = gzip.NewReader(resp.Body)
+	default:
+		return nil, false, fmt.Errorf("unsupported Content-Encoding %q: %w", encoding, image.ErrUnsupportedFormat)
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "could not decode the response body")
+	}
+	return &decodedBody{Reader: decoder, body: resp.Body}, true, nil
+}
+
+// decodedBody reads the decoded content of a response body, closing the body when closed.
+type decodedBody struct {
+	io.Reader
+	body io.Closer
+}
+
+func (d *decodedBody) Close() error {
+	return d.body.Close()
 }
 
 func (hs *HTTPDataSource) pollProgress(reader *util.CountingReader, idleTime, pollInterval time.Duration) {
@@ -422,6 +944,19 @@ func parseHTTPHeader(resp *http.Response) uint64 {
 	return total
 }
 
+// getMirrors returns the mirrors of the endpoint, from the environment.
+func getMirrors() ([]*url.URL, error) {
+	var mirrors []*url.URL
+	for _, mirror := range strings.Fields(os.Getenv(common.ImporterMirrors)) {
+		u, err := url.Parse(mirror)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse mirror %q", mirror)
+		}
+		mirrors = append(mirrors, u)
+	}
+	return mirrors, nil
+}
+
 // Check for any extra headers to pass along. Return secret headers separately so callers can suppress logging them.
 func getExtraHeaders() ([]string, []string, error) {
 	extraHeaders := getExtraHeadersFromEnvironment()
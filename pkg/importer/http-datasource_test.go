@@ -1,9 +1,14 @@
 package importer
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"io"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -14,6 +19,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/andybalholm/brotli"
+	"github.com/pkg/errors"
+	"github.com/ulikunitz/xz"
+
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -106,8 +115,78 @@ var _ = Describe("Http data source", func() {
 		table.Entry("return Convert phase ", cirrosFileName, cdiv1.DataVolumeKubeVirt, ProcessingPhaseConvert, cirrosData, false),
 		table.Entry("return TransferTarget with archive content type but not archive endpoint ", cirrosFileName, cdiv1.DataVolumeArchive, ProcessingPhaseTransferDataDir, cirrosData, false),
 		table.Entry("return TransferTarget with archive content type and archive endpoint ", diskimageTarFileName, cdiv1.DataVolumeArchive, ProcessingPhaseTransferDataDir, diskimageArchiveData, false),
+		table.Entry("return TransferScratch with a vdi image ", "tinyCore.vdi", cdiv1.DataVolumeKubeVirt, ProcessingPhaseTransferScratch, nil, false),
 	)
 
+	It("calling info with a vhdx image should return TransferScratch", func() {
+		vhdxDir, err := os.MkdirTemp("", "vhdx")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(vhdxDir)
+		Expect(os.WriteFile(filepath.Join(vhdxDir, "disk.vhdx"), vhdxData(false, 512), 0644)).To(Succeed())
+		vhdxServer := createTestServer(vhdxDir)
+		defer vhdxServer.Close()
+		dp, err = NewHTTPDataSource(vhdxServer.URL+"/disk.vhdx", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		newPhase, err := dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ProcessingPhaseTransferScratch).To(Equal(newPhase))
+	})
+
+	table.DescribeTable("calling info with a compressed qcow2 image should", func(compress func([]byte) []byte, expectedPhase ProcessingPhase, expectedFilters []image.NbdkitFilter) {
+		flushRead = nil
+		imgDir, err := os.MkdirTemp("", "compressed")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(imgDir)
+		Expect(os.WriteFile(filepath.Join(imgDir, "disk.img"), compress(cirrosData), 0644)).To(Succeed())
+		imgServer := createTestServer(imgDir)
+		defer imgServer.Close()
+		nbdkit := &recordingNbdkit{}
+		createNbdkitCurl = func(nbdkitPidFile, user, password, certDir, socket string, extraHeaders, secretExtraHeaders []string) image.NbdkitOperation {
+			return nbdkit
+		}
+		dp, err = NewHTTPDataSource(imgServer.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		// the data source is closed before its server
+		defer func() {
+			Expect(dp.Close()).To(Succeed())
+			dp = nil
+		}()
+		newPhase, err := dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newPhase).To(Equal(expectedPhase))
+		Expect(nbdkit.filters).To(Equal(expectedFilters))
+		Expect(nbdkit.started).To(Equal(newPhase == ProcessingPhaseConvert))
+	},
+		table.Entry("be converted from nbdkit decompressing xz blocks", func(data []byte) []byte {
+			return xzCompress(data, 1<<20)
+		}, ProcessingPhaseConvert, []image.NbdkitFilter{image.NbdkitXzFilter}),
+		table.Entry("return TransferScratch with an xz block too large for nbdkit", func(data []byte) []byte {
+			return xzCompress(append(append([]byte{}, data...), make([]byte, nbdkitXzMaxBlockSize)...), 0)
+		}, ProcessingPhaseTransferScratch, nil),
+		table.Entry("return TransferScratch with gzip", func(data []byte) []byte {
+			var b strings.Builder
+			w := gzip.NewWriter(&b)
+			_, err := w.Write(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+			return []byte(b.String())
+		}, ProcessingPhaseTransferScratch, nil),
+	)
+
+	It("should read the endpoint at random", func() {
+		ep, err := url.Parse(ts.URL + "/" + cirrosFileName)
+		Expect(err).NotTo(HaveOccurred())
+		r, err := newHTTPRangeReader(context.Background(), ep, "", "", "", nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		p := make([]byte, 100)
+		n, err := r.ReadAt(p, 1000)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p[:n]).To(Equal(cirrosData[1000:1100]))
+		n, err = r.ReadAt(p, int64(len(cirrosData))-10)
+		Expect(err).To(Equal(io.EOF))
+		Expect(p[:n]).To(Equal(cirrosData[len(cirrosData)-10:]))
+	})
+
 	It("calling info with raw gz image should return TransferDataFile", func() {
 		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreGz, "", "", "", cdiv1.DataVolumeKubeVirt)
 		Expect(err).NotTo(HaveOccurred())
@@ -116,6 +195,34 @@ var _ = Describe("Http data source", func() {
 		Expect(ProcessingPhaseTransferDataFile).To(Equal(newPhase))
 	})
 
+	It("should write an ISO9660 image as it is, without qemu-img", func() {
+		flushRead = nil
+		isoData, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		nbdkit := &recordingNbdkit{}
+		createNbdkitCurl = func(nbdkitPidFile, user, password, certDir, socket string, extraHeaders, secretExtraHeaders []string) image.NbdkitOperation {
+			return nbdkit
+		}
+		dp, err = NewHTTPDataSource(ts.URL+"/"+tinyCoreFileName, "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		dataDir := filepath.Join(tmpDir, "data")
+		scratchDir := filepath.Join(tmpDir, "scratch")
+		Expect(os.Mkdir(dataDir, 0700)).To(Succeed())
+		Expect(os.Mkdir(scratchDir, 0700)).To(Succeed())
+		dataFile := filepath.Join(dataDir, "disk.img")
+		processor := NewDataProcessor(dp, dataFile, dataDir, scratchDir, "", 0.055, false)
+		qemuOperations := NewFakeQEMUOperations(nil, nil, fakeInfoOpRetVal{&fakeZeroImageInfo, nil}, nil, nil, nil)
+		replaceQEMUOperations(qemuOperations, func() {
+			Expect(processor.ProcessData()).To(Succeed())
+			Expect(qemuOperations.(*fakeQEMUOperations).convertedTo).To(BeEmpty())
+		})
+		Expect(nbdkit.started).To(BeFalse())
+		Expect(dp.SourceFormat()).To(Equal("iso"))
+		written, err := os.ReadFile(dataFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written).To(Equal(isoData))
+	})
+
 	table.DescribeTable("calling transfer should", func(image string, contentType cdiv1.DataVolumeContentType, expectedPhase ProcessingPhase, scratchPath string, want []byte, wantErr bool) {
 		flushRead = want
 		if scratchPath == "" {
@@ -236,9 +343,118 @@ var _ = Describe("Http client", func() {
 
 })
 
+var _ = Describe("Http client certificate", func() {
+	var (
+		ca      *triple.KeyPair
+		certDir string
+	)
+
+	// writeClientCert writes a client certificate and key to the directory of the mounted secret
+	writeClientCert := func(clientCert *x509.Certificate, key []byte) {
+		Expect(os.WriteFile(filepath.Join(certDir, common.KeyClientCert), cert.EncodeCertPEM(clientCert), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(certDir, common.KeyClientKey), key, 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		ca, err = triple.NewCA("client-ca.cdi.kubevirt.io")
+		Expect(err).ToNot(HaveOccurred())
+		certDir, err = os.MkdirTemp("", "client-cert")
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv(common.ImporterClientCertDirVar, certDir)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(common.ImporterClientCertDirVar)
+		os.RemoveAll(certDir)
+	})
+
+	It("should not load a client certificate when the secret holds none", func() {
+		clientCert, err := clientCertificate()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(clientCert).To(BeNil())
+	})
+
+	It("should present the client certificate to a server requiring one", func() {
+		createNbdkitCurl = image.NewMockNbdkitCurl
+		clientKeyPair, err := triple.NewClientKeyPair(ca, "importer", nil)
+		Expect(err).ToNot(HaveOccurred())
+		writeClientCert(clientKeyPair.Cert, cert.EncodePrivateKeyPEM(clientKeyPair.Key))
+
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(ca.Cert)
+		server := httptest.NewUnstartedServer(http.FileServer(http.Dir(imageDir)))
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		server.StartTLS()
+		defer server.Close()
+		serverCertDir, err := os.MkdirTemp("", "server-cert")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(serverCertDir)
+		Expect(os.WriteFile(filepath.Join(serverCertDir, "tls.crt"), cert.EncodeCertPEM(server.Certificate()), 0600)).To(Succeed())
+
+		dp, err := NewHTTPDataSource(server.URL+"/"+cirrosFileName, "", "", serverCertDir, cdiv1.DataVolumeKubeVirt)
+		Expect(err).ToNot(HaveOccurred())
+		defer dp.Close()
+		Expect(dp.clientCert).To(BeTrue())
+		phase, err := dp.Info()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferScratch))
+
+		By("Failing without the client certificate")
+		os.Unsetenv(common.ImporterClientCertDirVar)
+		_, err = NewHTTPDataSource(server.URL+"/"+cirrosFileName, "", "", serverCertDir, cdiv1.DataVolumeKubeVirt)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail with an expired client certificate", func() {
+		key, err := cert.NewPrivateKey()
+		Expect(err).ToNot(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "importer"},
+			NotBefore:    time.Now().Add(-48 * time.Hour),
+			NotAfter:     time.Now().Add(-24 * time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.Key)
+		Expect(err).ToNot(HaveOccurred())
+		expired, err := x509.ParseCertificate(der)
+		Expect(err).ToNot(HaveOccurred())
+		writeClientCert(expired, cert.EncodePrivateKeyPEM(key))
+
+		_, err = NewHTTPDataSource("http://localhost:9999/"+cirrosFileName, "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrInvalidClientCertificate)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("expired"))
+	})
+
+	It("should fail with a client certificate not matching its key", func() {
+		clientKeyPair, err := triple.NewClientKeyPair(ca, "importer", nil)
+		Expect(err).ToNot(HaveOccurred())
+		otherKey, err := cert.NewPrivateKey()
+		Expect(err).ToNot(HaveOccurred())
+		writeClientCert(clientKeyPair.Cert, cert.EncodePrivateKeyPEM(otherKey))
+
+		_, err = createHTTPClient("")
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrInvalidClientCertificate)).To(BeTrue())
+	})
+
+	It("should fail with a client certificate without its key", func() {
+		clientKeyPair, err := triple.NewClientKeyPair(ca, "importer", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(certDir, common.KeyClientCert), cert.EncodeCertPEM(clientKeyPair.Cert), 0600)).To(Succeed())
+
+		_, err = clientCertificate()
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrInvalidClientCertificate)).To(BeTrue())
+	})
+})
+
 var _ = Describe("Http reader", func() {
 	It("should fail when passed an invalid cert directory", func() {
-		_, total, _, err := createHTTPReader(context.Background(), nil, "", "", "/invalid", nil, nil)
+		_, total, _, _, err := createHTTPReader(context.Background(), nil, "", "", "/invalid", nil, nil)
 		Expect(err).To(HaveOccurred())
 		Expect(uint64(0)).To(Equal(total))
 	})
@@ -255,36 +471,78 @@ var _ = Describe("Http reader", func() {
 		defer ts.Close()
 		ep, err := url.Parse(ts.URL)
 		Expect(err).ToNot(HaveOccurred())
-		r, total, _, err := createHTTPReader(context.Background(), ep, "user", "password", "", nil, nil)
+		r, total, _, _, err := createHTTPReader(context.Background(), ep, "user", "password", "", nil, nil)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(uint64(25)).To(Equal(total))
 		err = r.Close()
 		Expect(err).ToNot(HaveOccurred())
 	})
 
-	It("should pass auth info in request if set and redirected", func() {
-		redirTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	It("should pass auth info in request if set and redirected to the same origin", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/redirected" {
+				http.Redirect(w, r, "/redirected", http.StatusFound)
+				return
+			}
 			user, pass, ok := r.BasicAuth()
 			defer w.WriteHeader(http.StatusOK)
 			Expect(ok).To(BeTrue())
 			Expect("user").To(Equal(user))
 			Expect("password").To(Equal(pass))
+			Expect(r.Header.Values("X-Secret-Header")).To(Equal([]string{"secret"}))
+			w.Header().Add("Content-Length", "25")
+		}))
+		defer ts.Close()
+		ep, err := url.Parse(ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		r, total, _, _, err := createHTTPReader(context.Background(), ep, "user", "password", "", nil, []string{"X-Secret-Header: secret"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(uint64(25)).To(Equal(total))
+		err = r.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should not pass auth info and secret headers in request redirected to another origin", func() {
+		redirTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer w.WriteHeader(http.StatusOK)
+			Expect(r.Header.Get("Authorization")).To(BeEmpty())
+			Expect(r.Header.Get("X-Secret-Header")).To(BeEmpty())
+			Expect(r.Header.Get("X-Extra-Header")).To(Equal("extra"))
 			w.Header().Add("Content-Length", "25")
 		}))
 		defer redirTs.Close()
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer t0ken"))
 			http.Redirect(w, r, redirTs.URL, http.StatusFound)
 		}))
 		defer ts.Close()
 		ep, err := url.Parse(ts.URL)
 		Expect(err).ToNot(HaveOccurred())
-		r, total, _, err := createHTTPReader(context.Background(), ep, "user", "password", "", nil, nil)
+		r, total, _, _, err := createHTTPReader(context.Background(), ep, "", "", "", []string{"X-Extra-Header:extra"}, []string{"X-Secret-Header:secret", "Authorization:Bearer t0ken"})
 		Expect(err).ToNot(HaveOccurred())
 		Expect(uint64(25)).To(Equal(total))
 		err = r.Close()
 		Expect(err).ToNot(HaveOccurred())
 	})
 
+	It("should pass the bearer token of the secret instead of its access key", func() {
+		os.Setenv(common.ImporterBearerToken, "t0ken\n")
+		defer os.Unsetenv(common.ImporterBearerToken)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer t0ken" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Add("Content-Length", "25")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+		dp, err := NewHTTPDataSource(ts.URL, "user", "password", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dp.contentLength).To(Equal(uint64(25)))
+		Expect(dp.Close()).To(Succeed())
+	})
+
 	It("should redirect properly without auth if not set", func() {
 		redirTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			_, _, ok := r.BasicAuth()
@@ -300,7 +558,7 @@ var _ = Describe("Http reader", func() {
 		defer ts.Close()
 		ep, err := url.Parse(ts.URL)
 		Expect(err).ToNot(HaveOccurred())
-		r, total, brokenForQemuImg, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
+		r, total, brokenForQemuImg, _, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
 		Expect(brokenForQemuImg).To(BeFalse())
 		Expect(err).ToNot(HaveOccurred())
 		Expect(uint64(25)).To(Equal(total))
@@ -321,7 +579,7 @@ var _ = Describe("Http reader", func() {
 		defer ts.Close()
 		ep, err := url.Parse(ts.URL)
 		Expect(err).ToNot(HaveOccurred())
-		r, total, _, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
+		r, total, _, _, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(uint64(0)).To(Equal(total))
 		err = r.Close()
@@ -345,7 +603,7 @@ var _ = Describe("Http reader", func() {
 		defer ts.Close()
 		ep, err := url.Parse(ts.URL)
 		Expect(err).ToNot(HaveOccurred())
-		r, total, brokenForQemuImg, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
+		r, total, brokenForQemuImg, _, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
 		Expect(brokenForQemuImg).To(BeTrue())
 		Expect(err).ToNot(HaveOccurred())
 		Expect(uint64(25)).To(Equal(total))
@@ -365,7 +623,7 @@ var _ = Describe("Http reader", func() {
 		defer ts.Close()
 		ep, err := url.Parse(ts.URL)
 		Expect(err).ToNot(HaveOccurred())
-		r, total, brokenForQemuImg, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
+		r, total, brokenForQemuImg, _, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
 		Expect(brokenForQemuImg).To(BeTrue())
 		Expect(err).ToNot(HaveOccurred())
 		Expect(uint64(25)).To(Equal(total))
@@ -380,10 +638,84 @@ var _ = Describe("Http reader", func() {
 		defer ts.Close()
 		ep, err := url.Parse(ts.URL)
 		Expect(err).ToNot(HaveOccurred())
-		_, total, _, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
+		_, total, _, _, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
 		Expect(err).To(HaveOccurred())
 		Expect(uint64(0)).To(Equal(total))
 		Expect("expected status code 200, got 500. Status: 500 Internal Server Error").To(Equal(err.Error()))
+		Expect(errors.Is(err, ErrSourceNotFound)).To(BeFalse())
+	})
+
+	It("should fail terminally if server does not find the endpoint", func() {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		defer ts.Close()
+		ep, err := url.Parse(ts.URL + "/missing.qcow2")
+		Expect(err).ToNot(HaveOccurred())
+		_, _, _, _, err = createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrSourceNotFound)).To(BeTrue())
+		Expect(err.Error()).To(Equal("expected status code 200, got 404. Status: 404 Not Found"))
+	})
+
+	table.DescribeTable("should decode the Content-Encoding of the response", func(encoding string, encode func(io.Writer) io.WriteCloser) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Encoding", encoding)
+			w.Header().Add("Accept-Ranges", "bytes")
+			if r.Method == "HEAD" {
+				w.Header().Add("Content-Length", "25")
+				return
+			}
+			Expect(r.Header.Get("Accept-Encoding")).To(Equal("br, gzip"))
+			enc := encode(w)
+			_, err := enc.Write(cirrosData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(enc.Close()).To(Succeed())
+		}))
+		defer ts.Close()
+		ep, err := url.Parse(ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		r, total, brokenForQemuImg, _, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.(*util.CountingReader).Reader).To(BeAssignableToTypeOf(&decodedBody{}))
+		Expect(brokenForQemuImg).To(BeTrue())
+		// the length of the decoded content is not known
+		Expect(total).To(Equal(uint64(0)))
+		data, err := io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(cirrosData))
+		Expect(r.Close()).To(Succeed())
+	},
+		table.Entry("with brotli", "br", func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }),
+		table.Entry("with gzip", "gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }),
+	)
+
+	It("should fail on an unsupported Content-Encoding", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Encoding", "compress")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+		ep, err := url.Parse(ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, _, _, err = createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`unsupported Content-Encoding "compress": unsupported format`))
+		Expect(errors.Is(err, image.ErrUnsupportedFormat)).To(BeTrue())
+	})
+
+	It("should not override the Accept-Encoding extra header", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" {
+				Expect(r.Header.Get("Accept-Encoding")).To(Equal("identity"))
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+		ep, err := url.Parse(ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		r, _, _, _, err := createHTTPReader(context.Background(), ep, "", "", "", []string{"Accept-Encoding:identity"}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.(*util.CountingReader).Reader).ToNot(BeAssignableToTypeOf(&decodedBody{}))
+		Expect(r.Close()).To(Succeed())
 	})
 
 	It("should pass through extra headers", func() {
@@ -397,7 +729,7 @@ var _ = Describe("Http reader", func() {
 		defer ts.Close()
 		ep, err := url.Parse(ts.URL)
 		Expect(err).ToNot(HaveOccurred())
-		r, total, _, err := createHTTPReader(context.Background(), ep, "", "", "", []string{"Extra-Header: 123"}, nil)
+		r, total, _, _, err := createHTTPReader(context.Background(), ep, "", "", "", []string{"Extra-Header: 123"}, nil)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(uint64(0)).To(Equal(total))
 		err = r.Close()
@@ -436,6 +768,39 @@ var _ = Describe("http pollprogress", func() {
 	})
 })
 
+// recordingNbdkit is a mock nbdkit recording its filters, and whether it was started
+type recordingNbdkit struct {
+	filters []image.NbdkitFilter
+	started bool
+}
+
+func (n *recordingNbdkit) StartNbdkit(source string) error {
+	n.started = true
+	return nil
+}
+
+func (n *recordingNbdkit) KillNbdkit() error {
+	return nil
+}
+
+func (n *recordingNbdkit) AddEnvVariable(v string) {}
+
+func (n *recordingNbdkit) AddFilter(filter image.NbdkitFilter) {
+	n.filters = append(n.filters, filter)
+}
+
+// xzCompress returns data compressed with xz, in blocks of blockSize bytes of data, a single block
+// if it is 0.
+func xzCompress(data []byte, blockSize int64) []byte {
+	var b strings.Builder
+	w, err := xz.WriterConfig{BlockSize: blockSize}.NewWriter(&b)
+	Expect(err).NotTo(HaveOccurred())
+	_, err = w.Write(data)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(w.Close()).To(Succeed())
+	return []byte(b.String())
+}
+
 func createTestServer(imageDir string) *httptest.Server {
 	return httptest.NewServer(http.FileServer(http.Dir(imageDir)))
 }
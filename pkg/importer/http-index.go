@@ -0,0 +1,258 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+const (
+	// urlPatternGlob matches the names of the entries of the index with the shell pattern ending the URL
+	urlPatternGlob = "glob"
+	// urlPatternRegex matches the whole names of the entries of the index with the regular expression ending the URL
+	urlPatternRegex = "regex"
+
+	// maxIndexSize bounds the size of the directory index of an http source
+	maxIndexSize = 16 << 20
+)
+
+// ErrNoMatchingEntry is the error of a directory index none of whose entries matches the pattern of the
+// endpoint, retrying the import cannot succeed.
+var ErrNoMatchingEntry = fmt.Errorf("no matching entry")
+
+// indexTime matches the modification time shown next to an entry of an nginx or Apache autoindex.
+var indexTime = regexp.MustCompile(`\d{2}-[A-Z][a-z]{2}-\d{4} \d{2}:\d{2}(:\d{2})?|\d{4}-\d{2}-\d{2} \d{2}:\d{2}(:\d{2})?`)
+
+// indexTimeLayouts are the layouts of the modification times of nginx, and of Apache with or without
+// HTMLTable.
+var indexTimeLayouts = []string{"02-Jan-2006 15:04", "02-Jan-2006 15:04:05", "2006-01-02 15:04", "2006-01-02 15:04:05"}
+
+// indexEntry is a file listed by a directory index.
+type indexEntry struct {
+	url  *url.URL
+	name string
+	// the modification time shown by the index, zero if it shows none
+	modified time.Time
+}
+
+// httpURLPattern returns the syntax of the pattern ending the URL of an http source, glob or regex, set
+// with the IMPORTER_URL_PATTERN environment variable, empty if the URL names the data.
+func httpURLPattern() (string, error) {
+	value, _ := util.ParseEnvVar(common.ImporterURLPattern, false)
+	switch value {
+	case "", urlPatternGlob, urlPatternRegex:
+		return value, nil
+	}
+	return "", errors.Errorf("invalid %s value %q, %s or %s is expected", common.ImporterURLPattern, value, urlPatternGlob, urlPatternRegex)
+}
+
+// splitURLPattern returns the URL of the directory of ep, and the pattern of the last segment of its path.
+func splitURLPattern(ep *url.URL) (*url.URL, string) {
+	dir := *ep
+	i := strings.LastIndex(ep.Path, "/")
+	dir.Path, dir.RawPath, dir.Fragment = ep.Path[:i+1], "", ""
+	return &dir, ep.Path[i+1:]
+}
+
+// newNameMatcher returns a function matching the names of the entries of an index with the pattern.
+func newNameMatcher(syntax, pattern string) (func(string) bool, error) {
+	if pattern == "" {
+		return nil, errors.New("the URL does not end with a pattern")
+	}
+	if syntax == urlPatternRegex {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regular expression %q", pattern)
+		}
+		return re.MatchString, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern %q", pattern)
+	}
+	return func(name string) bool {
+		matched, _ := path.Match(pattern, name)
+		return matched
+	}, nil
+}
+
+// resolveIndexedEndpoint returns the URL of the newest entry of the directory index of ep whose name
+// matches the pattern ending the path of ep. It fails with ErrNoMatchingEntry if none of the entries
+// matches.
+func resolveIndexedEndpoint(ctx context.Context, ep *url.URL, syntax, accessKey, secKey, certDir string, extraHeaders, secretExtraHeaders []string) (*url.URL, error) {
+	dir, pattern := splitURLPattern(ep)
+	match, err := newNameMatcher(syntax, pattern)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readIndex(ctx, dir, accessKey, secKey, certDir, extraHeaders, secretExtraHeaders)
+	if err != nil {
+		return nil, err
+	}
+	var matching []indexEntry
+	for _, entry := range entries {
+		if match(entry.name) {
+			matching = append(matching, entry)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, fmt.Errorf("%w: none of the %d entries of the index of %s matches %q", ErrNoMatchingEntry, len(entries), dir.Redacted(), pattern)
+	}
+	newest := newestEntry(matching)
+	klog.V(1).Infof("%q matched %d entries of the index of %s, importing %s", pattern, len(matching), dir.Redacted(), newest.url.Redacted())
+	return newest.url, nil
+}
+
+// readIndex downloads the directory index of dir, and returns its entries.
+func readIndex(ctx context.Context, dir *url.URL, accessKey, secKey, certDir string, extraHeaders, secretExtraHeaders []string) ([]indexEntry, error) {
+	client, err := createHTTPClient(certDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating http client")
+	}
+	limits, err := httpRedirectLimits()
+	if err != nil {
+		return nil, err
+	}
+	client.CheckRedirect = redirectPolicy(limits, accessKey, secKey, secretExtraHeaders)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dir.String(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create the request of the index of %s", dir.Redacted())
+	}
+	addExtraheaders(req, append(append([]string{}, extraHeaders...), secretExtraHeaders...))
+	if len(accessKey) > 0 && len(secKey) > 0 {
+		req.SetBasicAuth(accessKey, secKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not download the index of %s", dir.Redacted())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("could not download the index of %s: %s", dir.Redacted(), resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIndexSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not download the index of %s", dir.Redacted())
+	}
+	if len(data) > maxIndexSize {
+		return nil, errors.Errorf("the index of %s is larger than %d bytes", dir.Redacted(), maxIndexSize)
+	}
+	// the links are relative to the URL which served the index
+	return parseIndex(bytes.NewReader(data), resp.Request.URL)
+}
+
+// parseIndex returns the files linked by an nginx or Apache autoindex of the directory base, along with
+// the modification time shown after each link. The links to subdirectories, to the parent directory,
+// to other hosts and the sorting links are skipped.
+func parseIndex(r io.Reader, base *url.URL) ([]indexEntry, error) {
+	dir := base.Path[:strings.LastIndex(base.Path, "/")+1]
+	var entries []indexEntry
+	seen := map[string]bool{}
+	// the last entry, which the time shown in the text following its link belongs to
+	last := -1
+	inLink := false
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return entries, nil
+			}
+			return nil, errors.Wrap(z.Err(), "could not parse the index")
+		case html.StartTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "a" {
+				continue
+			}
+			inLink, last = true, -1
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = z.TagAttr()
+				if string(key) != "href" {
+					continue
+				}
+				ref, err := url.Parse(string(value))
+				if err != nil {
+					continue
+				}
+				u := base.ResolveReference(ref)
+				u.Fragment = ""
+				if u.Scheme != base.Scheme || u.Host != base.Host || u.RawQuery != "" || u.Path[:strings.LastIndex(u.Path, "/")+1] != dir {
+					continue
+				}
+				if strings.HasSuffix(u.Path, "/") || seen[u.Path] {
+					continue
+				}
+				seen[u.Path] = true
+				entries = append(entries, indexEntry{url: u, name: path.Base(u.Path)})
+				last = len(entries) - 1
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "a" {
+				inLink = false
+			}
+		case html.TextToken:
+			if inLink || last < 0 || !entries[last].modified.IsZero() {
+				continue
+			}
+			if t, ok := parseIndexTime(z.Text()); ok {
+				entries[last].modified = t
+			}
+		}
+	}
+}
+
+// parseIndexTime returns the first modification time of the text of an index.
+func parseIndexTime(text []byte) (time.Time, bool) {
+	value := indexTime.Find(text)
+	if value == nil {
+		return time.Time{}, false
+	}
+	for _, layout := range indexTimeLayouts {
+		if t, err := time.ParseInLocation(layout, string(value), time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// newestEntry returns the newest of the entries, by the modification time shown by the index when it
+// shows one for all of them, the last in lexical order otherwise or among the entries modified at the
+// same time.
+func newestEntry(entries []indexEntry) indexEntry {
+	dated := true
+	for _, entry := range entries {
+		dated = dated && !entry.modified.IsZero()
+	}
+	sorted := append([]indexEntry{}, entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if dated && !sorted[i].modified.Equal(sorted[j].modified) {
+			return sorted[i].modified.Before(sorted[j].modified)
+		}
+		return sorted[i].name < sorted[j].name
+	})
+	return sorted[len(sorted)-1]
+}
+
+// resolveMirror returns the URL of the file of the mirror of a directory, named like the entry resolved
+// from the index of the endpoint in place of the pattern ending the path of the mirror.
+func resolveMirror(mirror *url.URL, name string) *url.URL {
+	resolved, _ := splitURLPattern(mirror)
+	resolved.Path += name
+	return resolved
+}
@@ -0,0 +1,213 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+)
+
+const nginxIndex = `<html>
+<head><title>Index of /images/</title></head>
+<body>
+<h1>Index of /images/</h1><hr><pre><a href="../">../</a>
+<a href="archive/">archive/</a>                                           01-Oct-2026 08:00                   -
+<a href="fedora-20261013.qcow2">fedora-20261013.qcow2</a>                              13-Oct-2026 02:11            21233664
+<a href="fedora-20261014.qcow2">fedora-20261014.qcow2</a>                              14-Oct-2026 02:09            21299200
+<a href="fedora-20261012.qcow2">fedora-20261012.qcow2</a>                              15-Oct-2026 09:30            21233664
+<a href="fedora-20261014.qcow2.sha256">fedora-20261014.qcow2.sha256</a>                       14-Oct-2026 02:09                  88
+<a href="fedora%20latest.qcow2">fedora latest.qcow2</a>                                14-Oct-2026 02:09            21299200
+</pre><hr></body>
+</html>
+`
+
+const apacheIndex = `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">
+<html>
+ <head>
+  <title>Index of /images</title>
+ </head>
+ <body>
+<h1>Index of /images</h1>
+  <table>
+   <tr><th valign="top"><img src="/icons/blank.gif" alt="[ICO]"></th><th><a href="?C=N;O=D">Name</a></th><th><a href="?C=M;O=A">Last modified</a></th><th><a href="?C=S;O=A">Size</a></th><th><a href="?C=D;O=A">Description</a></th></tr>
+   <tr><th colspan="5"><hr></th></tr>
+<tr><td valign="top"><img src="/icons/back.gif" alt="[PARENTDIR]"></td><td><a href="/">Parent Directory</a></td><td>&nbsp;</td><td align="right">  - </td><td>&nbsp;</td></tr>
+<tr><td valign="top"><img src="/icons/folder.gif" alt="[DIR]"></td><td><a href="archive/">archive/</a></td><td align="right">2026-10-01 08:00  </td><td align="right">  - </td><td>&nbsp;</td></tr>
+<tr><td valign="top"><img src="/icons/unknown.gif" alt="[   ]"></td><td><a href="fedora-20261013.qcow2">fedora-20261013.qcow2</a></td><td align="right">2026-10-13 02:11  </td><td align="right"> 20M</td><td>&nbsp;</td></tr>
+<tr><td valign="top"><img src="/icons/unknown.gif" alt="[   ]"></td><td><a href="fedora-20261014.qcow2">fedora-20261014.qcow2</a></td><td align="right">2026-10-14 02:09  </td><td align="right"> 20M</td><td>&nbsp;</td></tr>
+<tr><td valign="top"><img src="/icons/unknown.gif" alt="[   ]"></td><td><a href="http://www.example.com/images/other.qcow2">other.qcow2</a></td><td align="right">2026-10-15 00:00  </td><td align="right"> 20M</td><td>&nbsp;</td></tr>
+   <tr><th colspan="5"><hr></th></tr>
+</table>
+<address>Apache/2.4.57 (Fedora Linux) Server at localhost Port 80</address>
+</body></html>
+`
+
+func date(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", value)
+	Expect(err).NotTo(HaveOccurred())
+	return t
+}
+
+var _ = Describe("Http directory indexes", func() {
+	var (
+		server *httptest.Server
+		index  string
+	)
+
+	BeforeEach(func() {
+		createNbdkitCurl = image.NewMockNbdkitCurl
+		index = nginxIndex
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, password, _ := r.BasicAuth(); user != "user" || password != "password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if strings.HasSuffix(r.URL.Path, "/") {
+				w.Write([]byte(index))
+				return
+			}
+			http.ServeContent(w, r, "", time.Time{}, strings.NewReader(string(cirrosData)))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.Unsetenv(common.ImporterURLPattern)
+		os.Unsetenv(common.ImporterMirrors)
+	})
+
+	// names returns the names of the entries and the dates shown by the index
+	names := func(entries []indexEntry) map[string]time.Time {
+		result := map[string]time.Time{}
+		for _, entry := range entries {
+			result[entry.name] = entry.modified
+		}
+		return result
+	}
+
+	It("should parse the files of an nginx autoindex", func() {
+		base, _ := url.Parse("https://www.example.com/images/")
+		entries, err := parseIndex(strings.NewReader(nginxIndex), base)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(entries)).To(Equal(map[string]time.Time{
+			"fedora-20261013.qcow2":        date("2026-10-13 02:11"),
+			"fedora-20261014.qcow2":        date("2026-10-14 02:09"),
+			"fedora-20261012.qcow2":        date("2026-10-15 09:30"),
+			"fedora-20261014.qcow2.sha256": date("2026-10-14 02:09"),
+			"fedora latest.qcow2":          date("2026-10-14 02:09"),
+		}))
+		Expect(entries[0].url.String()).To(Equal("https://www.example.com/images/fedora-20261013.qcow2"))
+	})
+
+	It("should parse the files of an Apache autoindex, skipping the sorting links and other hosts", func() {
+		base, _ := url.Parse("https://www.example.com/images/")
+		entries, err := parseIndex(strings.NewReader(apacheIndex), base)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(entries)).To(Equal(map[string]time.Time{
+			"fedora-20261013.qcow2": date("2026-10-13 02:11"),
+			"fedora-20261014.qcow2": date("2026-10-14 02:09"),
+		}))
+	})
+
+	It("should not take the date of the next entry for an entry without date", func() {
+		base, _ := url.Parse("https://www.example.com/")
+		entries, err := parseIndex(strings.NewReader(`<ul><li><a href="a.img">a.img</a></li><li><a href="b.img">b.img</a> 2026-10-14 02:09</li></ul>`), base)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(entries)).To(Equal(map[string]time.Time{
+			"a.img": {},
+			"b.img": date("2026-10-14 02:09"),
+		}))
+	})
+
+	table.DescribeTable("should pick the newest entry", func(entries map[string]string, expected string) {
+		var list []indexEntry
+		for name, modified := range entries {
+			entry := indexEntry{name: name}
+			if modified != "" {
+				entry.modified = date(modified)
+			}
+			list = append(list, entry)
+		}
+		Expect(newestEntry(list).name).To(Equal(expected))
+	},
+		table.Entry("by date", map[string]string{"b.img": "2026-10-13 02:00", "a.img": "2026-10-14 02:00"}, "a.img"),
+		table.Entry("by name among the entries of the same date", map[string]string{"b.img": "2026-10-14 02:00", "a.img": "2026-10-14 02:00"}, "b.img"),
+		table.Entry("by name when a date is missing", map[string]string{"b.img": "", "a.img": "2026-10-14 02:00"}, "b.img"),
+	)
+
+	table.DescribeTable("should match names", func(syntax, pattern, name string, matched bool) {
+		match, err := newNameMatcher(syntax, pattern)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(match(name)).To(Equal(matched))
+	},
+		table.Entry("with a glob", urlPatternGlob, "fedora-*.qcow2", "fedora-20261014.qcow2", true),
+		table.Entry("not with a glob matching another suffix", urlPatternGlob, "fedora-*.qcow2", "fedora-20261014.qcow2.sha256", false),
+		table.Entry("with a regular expression", urlPatternRegex, `fedora-\d{8}\.qcow2`, "fedora-20261014.qcow2", true),
+		table.Entry("not with a regular expression matching part of the name", urlPatternRegex, `fedora-\d{8}\.qcow2`, "fedora-20261014.qcow2.sha256", false),
+	)
+
+	table.DescribeTable("should reject", func(syntax, pattern string) {
+		_, err := newNameMatcher(syntax, pattern)
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("an invalid glob", urlPatternGlob, "fedora-[.qcow2"),
+		table.Entry("an invalid regular expression", urlPatternRegex, "fedora-(.qcow2"),
+		table.Entry("an empty pattern", urlPatternGlob, ""),
+	)
+
+	It("should resolve the newest matching file of the index", func() {
+		ep, _ := url.Parse(server.URL + "/images/fedora-*.qcow2")
+		resolved, err := resolveIndexedEndpoint(context.Background(), ep, urlPatternGlob, "user", "password", "", nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.String()).To(Equal(server.URL + "/images/fedora-20261012.qcow2"))
+	})
+
+	It("should fail when no file of the index matches", func() {
+		ep, _ := url.Parse(server.URL + "/images/centos-*.qcow2")
+		_, err := resolveIndexedEndpoint(context.Background(), ep, urlPatternGlob, "user", "password", "", nil, nil)
+		Expect(errors.Is(err, ErrNoMatchingEntry)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`none of the 5 entries of the index of ` + server.URL + `/images/ matches "centos-*.qcow2"`))
+	})
+
+	It("should fail when the index cannot be downloaded", func() {
+		ep, _ := url.Parse(server.URL + "/images/fedora-*.qcow2")
+		_, err := resolveIndexedEndpoint(context.Background(), ep, urlPatternGlob, "", "", "", nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrNoMatchingEntry)).To(BeFalse())
+		Expect(err.Error()).To(ContainSubstring("401 Unauthorized"))
+	})
+
+	It("should import the matching file, and report its URL", func() {
+		index = apacheIndex
+		os.Setenv(common.ImporterURLPattern, urlPatternRegex)
+		dp, err := NewHTTPDataSource(server.URL+`/images/fedora-\d+\.qcow2`, "user", "password", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		Expect(dp.endpoint.String()).To(Equal(server.URL + "/images/fedora-20261014.qcow2"))
+		Expect(dp.SourceURL()).To(Equal(server.URL + "/images/fedora-20261014.qcow2"))
+	})
+
+	It("should fail with an invalid pattern syntax", func() {
+		os.Setenv(common.ImporterURLPattern, "wildcard")
+		_, err := NewHTTPDataSource(server.URL+"/images/fedora-*.qcow2", "user", "password", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(common.ImporterURLPattern))
+	})
+
+	It("should name the file matched in the index in the mirrors", func() {
+		mirror, _ := url.Parse("https://mirror.example.com/images/fedora-*.qcow2?token=secret")
+		Expect(resolveMirror(mirror, "fedora 20261014.qcow2").String()).To(Equal("https://mirror.example.com/images/fedora%2020261014.qcow2?token=secret"))
+	})
+})
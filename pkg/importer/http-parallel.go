@@ -0,0 +1,234 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+const (
+	// defaultHTTPConcurrency is the number of ranges of an http source downloaded at once to
+	// scratch space unless overridden
+	defaultHTTPConcurrency = 4
+	// httpChunkBufferSize is the size of the buffer copying a range to scratch space
+	httpChunkBufferSize = 1 << 20
+)
+
+var (
+	// httpMinChunkSize is the minimum size of the ranges downloaded at once, smaller data is
+	// downloaded in fewer ranges, may be overridden in tests
+	httpMinChunkSize int64 = 16 << 20
+)
+
+// httpConcurrency returns the number of ranges of an http source downloaded at once to scratch
+// space, set with the IMPORTER_HTTP_CONCURRENCY environment variable.
+func httpConcurrency() (int, error) {
+	value, _ := util.ParseEnvVar(common.ImporterHTTPConcurrency, false)
+	if value == "" {
+		return defaultHTTPConcurrency, nil
+	}
+	concurrency, err := strconv.Atoi(value)
+	if err != nil || concurrency < 1 {
+		return 0, errors.Errorf("invalid %s value %q, a positive number is expected", common.ImporterHTTPConcurrency, value)
+	}
+	return concurrency, nil
+}
+
+// httpChunk is a range of the data downloaded concurrently with the others, from off to end,
+// excluded, of which written bytes were recorded.
+type httpChunk struct {
+	off     int64
+	end     int64
+	written int64
+}
+
+// fill downloads the data from the end of the recorded data in up to concurrency ranges of at least
+// httpMinChunkSize bytes, requested at once and recorded at their offsets, so that the data is then
+// read from scratch space. report is called every second with the number of bytes recorded, and
+// once the ranges are downloaded. The validators are removed while the ranges are downloaded,
+// the next attempt of an import interrupted meanwhile starts over. When a range cannot be
+// downloaded, the data recorded up to the first missing byte is kept, and the download goes on in
+// a single stream from there.
+func (r *resumableBody) fill(concurrency int, report func(int64)) error {
+	start := r.recorded
+	remaining := r.validators.Size - start
+	if chunks := remaining / httpMinChunkSize; chunks < int64(concurrency) {
+		concurrency = int(chunks)
+	}
+	if r.file == nil || concurrency < 2 {
+		return nil
+	}
+	if r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	if err := os.Remove(r.statePath); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "could not remove the validators of the downloaded data")
+	}
+	chunkSize := (remaining + int64(concurrency) - 1) / int64(concurrency)
+	chunks := make([]*httpChunk, 0, concurrency)
+	for off := start; off < r.validators.Size; off += chunkSize {
+		end := off + chunkSize
+		if end > r.validators.Size {
+			end = r.validators.Size
+		}
+		chunks = append(chunks, &httpChunk{off: off, end: end})
+	}
+	klog.V(1).Infof("Downloading %d bytes of %s in %d ranges of %d bytes", remaining, r.req.URL, len(chunks), chunkSize)
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
+	errs := make(chan error, len(chunks))
+	var wg sync.WaitGroup
+	for _, chunk := range chunks {
+		wg.Add(1)
+		go func(chunk *httpChunk) {
+			defer wg.Done()
+			if err := r.downloadChunk(ctx, chunk); err != nil {
+				errs <- err
+				cancel()
+			}
+		}(chunk)
+	}
+	recorded := func() int64 {
+		downloaded := start
+		for _, chunk := range chunks {
+			downloaded += atomic.LoadInt64(&chunk.written)
+		}
+		return downloaded
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report(recorded())
+			case <-done:
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+
+	var err error
+	select {
+	case err = <-errs:
+	default:
+	}
+	// the data recorded up to the first missing byte
+	r.recorded = start
+	for _, chunk := range chunks {
+		r.recorded += chunk.written
+		if chunk.off+chunk.written < chunk.end {
+			break
+		}
+	}
+	if _, changed := err.(*httpDataChangedError); changed {
+		// the next attempt of the import starts over
+		r.discard()
+		return err
+	}
+	if truncateErr := r.file.Truncate(r.recorded); truncateErr != nil {
+		return errors.Wrap(truncateErr, "could not truncate the downloaded data")
+	}
+	state, _ := json.Marshal(r.validators)
+	if writeErr := os.WriteFile(r.statePath, state, 0600); writeErr != nil {
+		return errors.Wrap(writeErr, "could not write the validators of the downloaded data")
+	}
+	if _, ok := err.(partWriteError); ok {
+		return err
+	}
+	if r.ctx.Err() != nil {
+		return r.ctx.Err()
+	}
+	if err != nil {
+		klog.Warningf("Downloading %s in a single stream from offset %d: %v", r.req.URL, r.recorded, err)
+		return nil
+	}
+	info, err := r.file.Stat()
+	if err != nil {
+		return errors.Wrap(err, "could not stat the downloaded data")
+	}
+	if info.Size() != r.validators.Size {
+		return errors.Errorf("the data downloaded from %s is %d bytes, %d expected", r.req.URL, info.Size(), r.validators.Size)
+	}
+	report(r.recorded)
+	return nil
+}
+
+// downloadChunk records the range of chunk. The rest of the range is requested again when its
+// request or the read of its body fails, up to httpResumeRetries times in a row.
+func (r *resumableBody) downloadChunk(ctx context.Context, chunk *httpChunk) error {
+	var err error
+	for retry := 0; retry <= httpResumeRetries; retry++ {
+		if retry > 0 {
+			klog.Warningf("Retrying the download of bytes %d-%d of %s: %v", chunk.off+chunk.written, chunk.end-1, r.req.URL, err)
+			select {
+			case <-time.After(httpResumeInterval * time.Duration(retry)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		before := atomic.LoadInt64(&chunk.written)
+		if err = r.writeChunk(ctx, chunk); err == nil {
+			return nil
+		}
+		switch err.(type) {
+		case *httpDataChangedError, partWriteError:
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if atomic.LoadInt64(&chunk.written) > before {
+			// the range progressed, the retries start over
+			retry = 0
+		}
+	}
+	return errors.Wrapf(err, "could not download bytes %d-%d of %s after %d retries", chunk.off+chunk.written, chunk.end-1, r.req.URL, httpResumeRetries)
+}
+
+// writeChunk requests the rest of the range of chunk and records it at its offset.
+func (r *resumableBody) writeChunk(ctx context.Context, chunk *httpChunk) error {
+	off := chunk.off + atomic.LoadInt64(&chunk.written)
+	req := r.req.WithContext(ctx)
+	body, err := r.requestRange(req, off, chunk.end-1)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	buf := make([]byte, httpChunkBufferSize)
+	for off < chunk.end {
+		size := int64(len(buf))
+		if chunk.end-off < size {
+			size = chunk.end - off
+		}
+		n, err := io.ReadFull(body, buf[:size])
+		if n > 0 {
+			if _, writeErr := r.file.WriteAt(buf[:n], off); writeErr != nil {
+				return partWriteError{errors.Wrap(writeErr, "could not record the downloaded data")}
+			}
+			off += int64(n)
+			atomic.AddInt64(&chunk.written, int64(n))
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not read bytes %d-%d of %s", off, chunk.end-1, r.req.URL)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+)
+
+var _ = Describe("Concurrent http range downloads", func() {
+	var (
+		server   *resumeServer
+		tmpDir   string
+		interval time.Duration
+		minChunk int64
+	)
+
+	BeforeEach(func() {
+		createNbdkitCurl = image.NewMockNbdkitCurl
+		interval, httpResumeInterval = httpResumeInterval, time.Millisecond
+		minChunk, httpMinChunkSize = httpMinChunkSize, 1024*1024
+		server = newResumeServer(cirrosData)
+		var err error
+		tmpDir, err = os.MkdirTemp("", "scratch")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		httpResumeInterval, httpMinChunkSize = interval, minChunk
+		os.Unsetenv(common.ImporterHTTPConcurrency)
+		os.Unsetenv(common.ImporterBandwidthLimit)
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	// transfer transfers the data of the server to the scratch space
+	transfer := func() error {
+		dp, err := NewHTTPDataSource(server.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		_, err = dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = dp.Transfer(tmpDir)
+		return err
+	}
+
+	// chunkRanges returns the bounded range requests of the server, but the one of the data read
+	// before the download is persisted
+	chunkRanges := func() []string {
+		var chunks []string
+		for _, byteRange := range server.requestedRanges() {
+			if !strings.HasSuffix(byteRange, "-") && !strings.HasPrefix(byteRange, "bytes=0-") {
+				chunks = append(chunks, byteRange)
+			}
+		}
+		return chunks
+	}
+
+	expectTransferred := func(want []byte) {
+		written, err := os.ReadFile(filepath.Join(tmpDir, tempFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes.Equal(written, want)).To(BeTrue())
+		Expect(filepath.Join(tmpDir, downloadFile)).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tmpDir, downloadStateFile)).NotTo(BeAnExistingFile())
+	}
+
+	It("should download the data in 4 concurrent ranges by default", func() {
+		Expect(transfer()).To(Succeed())
+		expectTransferred(cirrosData)
+		Expect(server.requestedRanges()[0]).To(HavePrefix("bytes=0-"))
+		Expect(chunkRanges()).To(HaveLen(4))
+	})
+
+	It("should download compressed data in ranges, decompressed once downloaded", func() {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		_, err := w.Write(cirrosData)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		server.data = compressed.Bytes()
+		os.Setenv(common.ImporterHTTPConcurrency, "2")
+		Expect(transfer()).To(Succeed())
+		expectTransferred(cirrosData)
+		Expect(chunkRanges()).To(HaveLen(2))
+	})
+
+	It("should download fewer ranges of small data", func() {
+		httpMinChunkSize = int64(len(cirrosData)) / 3
+		os.Setenv(common.ImporterHTTPConcurrency, "8")
+		Expect(transfer()).To(Succeed())
+		expectTransferred(cirrosData)
+		Expect(chunkRanges()).To(HaveLen(2))
+	})
+
+	table.DescribeTable("should download the data in a single stream", func(setup func()) {
+		setup()
+		Expect(transfer()).To(Succeed())
+		expectTransferred(cirrosData)
+		Expect(chunkRanges()).To(BeEmpty())
+	},
+		table.Entry("with a concurrency of 1", func() { os.Setenv(common.ImporterHTTPConcurrency, "1") }),
+		table.Entry("with a limited bandwidth", func() { os.Setenv(common.ImporterBandwidthLimit, "1Gi") }),
+		table.Entry("when the data is smaller than two ranges", func() { httpMinChunkSize = int64(len(cirrosData)) }),
+	)
+
+	It("should go on in a single stream when the ranges cannot be downloaded", func() {
+		server.failChunks = true
+		Expect(transfer()).To(Succeed())
+		expectTransferred(cirrosData)
+		Expect(len(chunkRanges())).To(BeNumerically(">=", 4))
+		ranges := server.requestedRanges()
+		Expect(ranges[len(ranges)-1]).To(HaveSuffix("-"))
+	})
+
+	It("should fail when the data changes while its ranges are downloaded", func() {
+		server.changedData = append([]byte{}, cirrosData...)
+		err := transfer()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("changed while it was downloaded"))
+		Expect(filepath.Join(tmpDir, downloadFile)).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tmpDir, downloadStateFile)).NotTo(BeAnExistingFile())
+	})
+
+	table.DescribeTable("should reject the concurrency", func(value string) {
+		os.Setenv(common.ImporterHTTPConcurrency, value)
+		_, err := NewHTTPDataSource(server.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("a positive number is expected"))
+	},
+		table.Entry("of 0", "0"),
+		table.Entry("which is not a number", "four"),
+	)
+})
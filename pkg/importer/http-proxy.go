@@ -0,0 +1,224 @@
+package importer
+
+import (
+	"bufio"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+)
+
+const (
+	// serviceDomain is the domain of the services of the cluster, "service.namespace.svc"
+	serviceDomain = "svc"
+	// defaultClusterDomain is the domain of the cluster unless it is configured otherwise
+	defaultClusterDomain = "cluster.local"
+)
+
+var (
+	// resolvConf is the DNS configuration of the pod, whose search domains tell the domain of the
+	// cluster, may be overridden in tests
+	resolvConf = "/etc/resolv.conf"
+)
+
+// importProxy is the proxy configuration of the CDIConfig, passed to the importer pod by the
+// http_proxy, https_proxy and no_proxy environment variables. The proxy of a request is chosen
+// as http.ProxyFromEnvironment does, but the hosts of the cluster are always reached directly.
+type importProxy struct {
+	httpProxy  *url.URL
+	httpsProxy *url.URL
+	// noProxyAll is set by the "*" entry of no_proxy
+	noProxyAll bool
+	// noProxyDomains are the hosts of no_proxy, reached directly along with their subdomains
+	noProxyDomains []string
+	// noProxyNets are the IP addresses and the CIDRs of no_proxy
+	noProxyNets []*net.IPNet
+}
+
+// importProxyFromEnvironment returns the proxy configuration of the environment. The domains of
+// the services of the cluster are added to its no_proxy list.
+func importProxyFromEnvironment() (*importProxy, error) {
+	p := &importProxy{}
+	var err error
+	if p.httpProxy, err = parseProxyURL(common.ImportProxyHTTP); err != nil {
+		return nil, err
+	}
+	if p.httpsProxy, err = parseProxyURL(common.ImportProxyHTTPS); err != nil {
+		return nil, err
+	}
+	for _, entry := range strings.Split(getEnvAnyCase(common.ImportProxyNoProxy), ",") {
+		p.addNoProxy(entry)
+	}
+	p.addNoProxy(serviceDomain)
+	p.addNoProxy(clusterDomain())
+	return p, nil
+}
+
+// getEnvAnyCase returns the value of the environment variable name, in lower case, or of the
+// same variable in upper case.
+func getEnvAnyCase(name string) string {
+	if value, ok := os.LookupEnv(strings.ToLower(name)); ok {
+		return value
+	}
+	return os.Getenv(strings.ToUpper(name))
+}
+
+// parseProxyURL returns the URL of the proxy of the environment variable name, nil if it is not
+// set. A proxy without a scheme is an http proxy. The URL may hold the credentials of the proxy, it
+// is never part of the errors.
+func parseProxyURL(name string) (*url.URL, error) {
+	proxy := getEnvAnyCase(name)
+	if proxy == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+		if proxyURL, err = url.Parse("http://" + proxy); err != nil {
+			return nil, errors.Errorf("invalid proxy URL of %s", name)
+		}
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, errors.Errorf("invalid scheme %q of the proxy URL of %s", proxyURL.Scheme, name)
+	}
+	return proxyURL, nil
+}
+
+// clusterDomain returns the domain of the cluster, found among the search domains of the pod,
+// "namespace.svc.<domain> svc.<domain> <domain>".
+func clusterDomain() string {
+	file, err := os.Open(resolvConf)
+	if err != nil {
+		return defaultClusterDomain
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "search" {
+			continue
+		}
+		for _, domain := range fields[1:] {
+			if strings.HasPrefix(domain, serviceDomain+".") {
+				return strings.TrimSuffix(strings.TrimPrefix(domain, serviceDomain+"."), ".")
+			}
+		}
+	}
+	return defaultClusterDomain
+}
+
+// addNoProxy adds an entry of no_proxy: "*", an IP address, a CIDR, or a host, with an optional
+// leading "." or "*.". A host is reached directly along with its subdomains, on any port.
+func (p *importProxy) addNoProxy(entry string) {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if entry == "" {
+		return
+	}
+	if entry == "*" {
+		p.noProxyAll = true
+		return
+	}
+	if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+		p.noProxyNets = append(p.noProxyNets, ipNet)
+		return
+	}
+	if host, _, err := net.SplitHostPort(entry); err == nil {
+		entry = host
+	}
+	if ip := net.ParseIP(strings.Trim(entry, "[]")); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		p.noProxyNets = append(p.noProxyNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		return
+	}
+	entry = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(entry, "*"), "."), ".")
+	if entry != "" {
+		p.noProxyDomains = append(p.noProxyDomains, entry)
+	}
+}
+
+// proxyFor returns the proxy of a request, nil if the request is sent directly. It is the Proxy
+// function of the transports of the importer.
+func (p *importProxy) proxyFor(req *http.Request) (*url.URL, error) {
+	proxy := p.httpProxy
+	if req.URL.Scheme == "https" {
+		proxy = p.httpsProxy
+	}
+	if proxy == nil || p.direct(req.URL.Hostname()) {
+		return nil, nil
+	}
+	return proxy, nil
+}
+
+// configured returns true if a proxy is configured.
+func (p *importProxy) configured() bool {
+	return p.httpProxy != nil || p.httpsProxy != nil
+}
+
+// direct returns true if host is reached directly: it is matched by no_proxy, it is a loopback
+// address, or it is a host of the cluster, either a service or a host without a domain.
+func (p *importProxy) direct(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if p.noProxyAll || host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.IsLoopback() {
+			return true
+		}
+		for _, ipNet := range p.noProxyNets {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	if !strings.Contains(host, ".") {
+		return true
+	}
+	for _, domain := range p.noProxyDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyCertDir returns the directory of the CA bundle trusted when making egress connections
+// using a proxy, empty when the CDIConfig has none.
+func proxyCertDir() string {
+	return os.Getenv(common.ImporterProxyCertDirVar)
+}
+
+// proxyCertPool returns the system certificates along with the CA bundle of the proxy.
+func proxyCertPool() (*x509.CertPool, error) {
+	certPool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting system certs")
+	}
+	dir := proxyCertDir()
+	if dir == "" {
+		return certPool, nil
+	}
+	if files, err := os.ReadDir(dir); err == nil {
+		for _, file := range files {
+			if file.IsDir() || file.Name()[0] == '.' {
+				continue
+			}
+			if certs, err := os.ReadFile(path.Join(dir, file.Name())); err == nil {
+				certPool.AppendCertsFromPEM(certs)
+			}
+		}
+	}
+	return certPool, nil
+}
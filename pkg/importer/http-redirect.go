@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// defaultMaxRedirects is the number of redirects followed by a request of an http source unless
+// overridden, as many as the http client follows by default
+const defaultMaxRedirects = 10
+
+// redirectLimits limits the redirects followed by the requests of an http source.
+type redirectLimits struct {
+	// max is the number of redirects followed by a request
+	max int
+	// hosts are the hosts a request may be redirected to besides the host of its URL, any host
+	// when empty. A host starting with *. matches the subdomains of the rest of the host.
+	hosts []string
+}
+
+// httpRedirectLimits returns the limits of the redirects followed by the requests of an http source,
+// set with the IMPORTER_MAX_REDIRECTS and IMPORTER_REDIRECT_HOSTS environment variables.
+func httpRedirectLimits() (*redirectLimits, error) {
+	limits := &redirectLimits{max: defaultMaxRedirects}
+	if value, _ := util.ParseEnvVar(common.ImporterMaxRedirects, false); value != "" {
+		max, err := strconv.Atoi(value)
+		if err != nil || max < 0 {
+			return nil, errors.Errorf("invalid %s value %q, a number of redirects is expected", common.ImporterMaxRedirects, value)
+		}
+		limits.max = max
+	}
+	if value, _ := util.ParseEnvVar(common.ImporterRedirectHosts, false); value != "" {
+		limits.hosts = strings.Fields(value)
+	}
+	return limits, nil
+}
+
+// configured returns true if the redirects are limited more than by default.
+func (l *redirectLimits) configured() bool {
+	return l.max != defaultMaxRedirects || len(l.hosts) > 0
+}
+
+// check fails if the request r, redirected from the requests via, exceeds the limits.
+func (l *redirectLimits) check(r *http.Request, via []*http.Request) error {
+	if len(via) > l.max {
+		return errors.Errorf("stopped after %d redirects", l.max)
+	}
+	if len(l.hosts) == 0 || strings.EqualFold(r.URL.Hostname(), via[0].URL.Hostname()) {
+		return nil
+	}
+	for _, host := range l.hosts {
+		if matchHost(host, r.URL.Hostname()) {
+			return nil
+		}
+	}
+	return errors.Errorf("the redirect of %s to the host %s is not allowed", via[0].URL.Hostname(), r.URL.Hostname())
+}
+
+// matchHost returns true if hostname is host, or one of its subdomains when host starts with *.
+func matchHost(host, hostname string) bool {
+	if suffix := strings.TrimPrefix(host, "*"); suffix != host {
+		return len(hostname) > len(suffix) && strings.HasSuffix(strings.ToLower(hostname), strings.ToLower(suffix))
+	}
+	return strings.EqualFold(host, hostname)
+}
@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+)
+
+var _ = Describe("Http redirects", func() {
+	var (
+		server *resumeServer
+		// redirecting redirects a request to /hops/ followed by n x n times on its own host, then to
+		// the server
+		redirecting *httptest.Server
+		// localhost is the host of the server as seen from the redirecting server, another host
+		localhost string
+	)
+
+	BeforeEach(func() {
+		createNbdkitCurl = image.NewMockNbdkitCurl
+		server = newResumeServer(cirrosData)
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		localhost = "localhost:" + serverURL.Port()
+		redirecting = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hops := strings.Count(r.URL.Path, "x")
+			if hops > 0 {
+				http.Redirect(w, r, "/hops/"+strings.Repeat("x", hops-1), http.StatusFound)
+				return
+			}
+			http.Redirect(w, r, "http://"+localhost+"/disk.img?token=secret", http.StatusFound)
+		}))
+	})
+
+	AfterEach(func() {
+		redirecting.Close()
+		server.Close()
+		os.Unsetenv(common.ImporterMaxRedirects)
+		os.Unsetenv(common.ImporterRedirectHosts)
+	})
+
+	// read reads the data of the endpoint redirected hops times on its host before being redirected to
+	// localhost
+	read := func(hops int) error {
+		ep, err := url.Parse(redirecting.URL + "/hops/" + strings.Repeat("x", hops))
+		Expect(err).NotTo(HaveOccurred())
+		r, _, _, _, err := createHTTPReader(context.Background(), ep, "", "", "", nil, nil)
+		if err == nil {
+			r.Close()
+		}
+		return err
+	}
+
+	table.DescribeTable("should follow the redirects within the limits", func(maxRedirects, redirectHosts string, hops int, allowed bool) {
+		if maxRedirects != "" {
+			os.Setenv(common.ImporterMaxRedirects, maxRedirects)
+		}
+		if redirectHosts != "" {
+			os.Setenv(common.ImporterRedirectHosts, redirectHosts)
+		}
+		err := read(hops)
+		if allowed {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		table.Entry("as many redirects as by default", "", "", defaultMaxRedirects-1, true),
+		table.Entry("not more redirects than by default", "", "", defaultMaxRedirects, false),
+		table.Entry("up to the maximum number of redirects", "3", "", 2, true),
+		table.Entry("not more than the maximum number of redirects", "3", "", 3, false),
+		table.Entry("no redirect", "0", "", 0, false),
+		table.Entry("to an allowed host", "", "localhost", 0, true),
+		table.Entry("to an allowed host in upper case", "", "example.com LOCALHOST", 0, true),
+		table.Entry("not to a host that is not allowed", "", "example.com", 0, false),
+		table.Entry("on the same host whatever the allowed hosts", "", "localhost", 3, true),
+		table.Entry("not to a host not matching a wildcard", "", "*.localhost", 0, false),
+	)
+
+	It("should fail with an invalid maximum number of redirects", func() {
+		os.Setenv(common.ImporterMaxRedirects, "-1")
+		_, err := NewHTTPDataSource(redirecting.URL+"/hops/x", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(common.ImporterMaxRedirects))
+	})
+
+	It("should report the URL the endpoint was redirected to, without its query", func() {
+		dp, err := NewHTTPDataSource(redirecting.URL+"/hops/x", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		Expect(dp.SourceURL()).To(Equal("http://" + localhost + "/disk.img"))
+	})
+
+	table.DescribeTable("should let nbdkit read an endpoint redirected to another origin", func(accessKey, redirectHosts string, phase ProcessingPhase) {
+		if redirectHosts != "" {
+			os.Setenv(common.ImporterRedirectHosts, redirectHosts)
+		}
+		dp, err := NewHTTPDataSource(redirecting.URL+"/disk.img", accessKey, "password", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		Expect(dp.Info()).To(Equal(phase))
+	},
+		table.Entry("without credentials", "", "", ProcessingPhaseConvert),
+		table.Entry("unless it would send the credentials", "user", "", ProcessingPhaseTransferScratch),
+		table.Entry("unless the redirects are limited", "", "localhost", ProcessingPhaseTransferScratch),
+	)
+})
+
+var _ = Describe("Redirect host matching", func() {
+	table.DescribeTable("should match", func(host, hostname string, match bool) {
+		Expect(matchHost(host, hostname)).To(Equal(match))
+	},
+		table.Entry("the same host", "cdn.example.com", "cdn.example.com", true),
+		table.Entry("the same host in another case", "CDN.example.com", "cdn.EXAMPLE.com", true),
+		table.Entry("not another host", "cdn.example.com", "example.com", false),
+		table.Entry("a subdomain of a wildcard", "*.example.com", "cdn.example.com", true),
+		table.Entry("a nested subdomain of a wildcard", "*.example.com", "eu.cdn.example.com", true),
+		table.Entry("not the domain of a wildcard", "*.example.com", "example.com", false),
+		table.Entry("not a host ending like a wildcard", "*.example.com", "badexample.com", false),
+	)
+})
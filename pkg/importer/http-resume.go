@@ -0,0 +1,368 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// downloadFile is the data of an http source downloaded to scratch space as it is, before it is
+	// decompressed, kept when the import fails so that the next attempt resumes its download
+	downloadFile = tempFile + ".download"
+	// downloadStateFile holds the validators of the data of downloadFile
+	downloadStateFile = downloadFile + ".json"
+	// httpResumeRetries is the number of times a failed download is resumed before the import fails
+	httpResumeRetries = 3
+)
+
+var (
+	// httpResumeInterval is the delay before the first resumption of a failed download, raised on
+	// each retry, may be overridden in tests
+	httpResumeInterval = 2 * time.Second
+)
+
+// httpValidators identify the data of an http source, its download is only resumed while they
+// do not change.
+type httpValidators struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Size         int64  `json:"size"`
+}
+
+// resumableValidators returns the validators of a response whose download may be resumed with
+// range requests: the server accepts byte ranges and identifies the data with a strong ETag or a
+// Last-Modified date, and the data is neither encoded nor of unknown size.
+func resumableValidators(url string, resp *http.Response) (httpValidators, bool) {
+	v := httpValidators{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Size:         resp.ContentLength,
+	}
+	if strings.HasPrefix(v.ETag, "W/") {
+		// a weak ETag does not tell whether the bytes of the data are the same
+		v.ETag = ""
+	}
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	resumable := resp.Header.Get("Accept-Ranges") == "bytes" && (v.ETag != "" || v.LastModified != "") &&
+		(encoding == "" || encoding == "identity") && v.Size > 0
+	return v, resumable
+}
+
+// ifRange returns the If-Range header of the range requests resuming the download, the server
+// returns the whole data rather than the range when the data changed.
+func (v httpValidators) ifRange() string {
+	if v.ETag != "" {
+		return v.ETag
+	}
+	return v.LastModified
+}
+
+// httpDataChangedError is the error of the data of an http source that changed while it was
+// downloaded, its download is not resumed.
+type httpDataChangedError struct {
+	url string
+}
+
+func (e *httpDataChangedError) Error() string {
+	return fmt.Sprintf("the data of %s changed while it was downloaded", e.url)
+}
+
+// resumableBody reads the body of a response whose download is resumed from the offset reached,
+// with a range request, after an error, up to httpResumeRetries times in a row. Once persisted to
+// scratch space, the data read is recorded there too, and the next attempt of the import reads the
+// data recorded before resuming the download. Once the download cannot be resumed anymore, it fails
+// over to the next mirror serving the same data.
+type resumableBody struct {
+	ctx        context.Context
+	client     *http.Client
+	req        *http.Request
+	validators httpValidators
+	body       io.ReadCloser
+	// offset is the number of bytes read
+	offset int64
+	// file records the data read, up to recorded bytes, once persisted
+	file      *os.File
+	recorded  int64
+	statePath string
+	// origin is the URL of the endpoint, mirrors are the next mirrors of its data
+	origin  *url.URL
+	mirrors []*url.URL
+	// credentials sets the credentials of a request to a mirror, only sent to the origin
+	credentials func(*http.Request, []*http.Request) error
+}
+
+// newResumableBody returns a reader of the body of the response to req, resumed with range
+// requests, or nil if the download of the response cannot be resumed.
+func newResumableBody(ctx context.Context, client *http.Client, req *http.Request, resp *http.Response) *resumableBody {
+	validators, ok := resumableValidators(req.URL.String(), resp)
+	if !ok {
+		return nil
+	}
+	klog.V(1).Infof("The download of %s may be resumed, validators %+v", req.URL, validators)
+	return &resumableBody{ctx: ctx, client: client, req: req, validators: validators, body: resp.Body}
+}
+
+// Read reads the data recorded by a previous attempt, then the body of the response, resuming the
+// download after an error.
+func (r *resumableBody) Read(p []byte) (int, error) {
+	if r.offset == r.validators.Size {
+		return 0, io.EOF
+	}
+	if r.offset < r.recorded {
+		if remaining := r.recorded - r.offset; remaining < int64(len(p)) {
+			p = p[:remaining]
+		}
+		n, err := r.file.ReadAt(p, r.offset)
+		r.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		return 0, errors.Wrap(err, "could not read the downloaded data")
+	}
+	if remaining := r.validators.Size - r.offset; remaining < int64(len(p)) {
+		p = p[:remaining]
+	}
+	for retry := 0; ; retry++ {
+		var err error
+		if r.body == nil {
+			err = r.resume()
+		}
+		if err == nil {
+			var n int
+			n, err = r.body.Read(p)
+			if n > 0 {
+				if err := r.record(p[:n]); err != nil {
+					return 0, err
+				}
+				return n, nil
+			}
+			if err == io.EOF {
+				// the body ended before the size of the data
+				err = io.ErrUnexpectedEOF
+			}
+			r.body.Close()
+			r.body = nil
+		}
+		if _, changed := err.(*httpDataChangedError); changed {
+			// the next attempt of the import starts over
+			r.discard()
+			return 0, err
+		}
+		if retry == httpResumeRetries && r.ctx.Err() == nil && r.failOver() {
+			retry = -1
+			continue
+		}
+		if retry == httpResumeRetries || r.ctx.Err() != nil {
+			return 0, errors.Wrapf(err, "could not download %s at offset %d after %d retries", r.req.URL, r.offset, retry)
+		}
+		klog.Warningf("Resuming the download of %s at offset %d: %v", r.req.URL, r.offset, err)
+		select {
+		case <-time.After(httpResumeInterval * time.Duration(retry+1)):
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+	}
+}
+
+// record records the data read once persisted.
+func (r *resumableBody) record(data []byte) error {
+	if r.file != nil {
+		if _, err := r.file.WriteAt(data, r.offset); err != nil {
+			return errors.Wrap(err, "could not record the downloaded data")
+		}
+		r.recorded = r.offset + int64(len(data))
+	}
+	r.offset += int64(len(data))
+	return nil
+}
+
+// resume requests the data from the offset reached.
+func (r *resumableBody) resume() error {
+	body, err := r.rangeRequest(r.offset, -1)
+	if err != nil {
+		return err
+	}
+	r.body = body
+	return nil
+}
+
+// failOver switches the download to the next mirror serving the same data from the offset reached,
+// returning false when no mirror does. The credentials are only sent to the origin of the endpoint,
+// as when redirected.
+func (r *resumableBody) failOver() bool {
+	for len(r.mirrors) > 0 {
+		mirror := r.mirrors[0]
+		r.mirrors = r.mirrors[1:]
+		req := r.req.Clone(r.ctx)
+		req.URL, req.Host = mirror, mirror.Host
+		if r.credentials != nil && r.origin != nil {
+			if err := r.credentials(req, []*http.Request{{URL: r.origin}}); err != nil {
+				continue
+			}
+		}
+		body, err := r.requestRange(req, r.offset, -1)
+		if err != nil {
+			klog.Warningf("The mirror %s cannot resume the download of %s: %v", mirror, r.req.URL, err)
+			continue
+		}
+		klog.Infof("Resuming the download of %s at offset %d from the mirror %s", r.req.URL, r.offset, mirror)
+		r.req, r.body = req, body
+		return true
+	}
+	return false
+}
+
+// rangeRequest returns the body of a range request of the data from start to end, included, or
+// up to the end of the data when end is negative.
+func (r *resumableBody) rangeRequest(start, end int64) (io.ReadCloser, error) {
+	return r.requestRange(r.req, start, end)
+}
+
+// requestRange returns the body of the range request of the data from start to end cloned from
+// req, with its context. The data must match the validators.
+func (r *resumableBody) requestRange(req *http.Request, start, end int64) (io.ReadCloser, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", "identity")
+	req.Header.Set("If-Range", r.validators.ifRange())
+	byteRange := fmt.Sprintf("bytes=%d-", start)
+	if end >= 0 {
+		byteRange += fmt.Sprint(end)
+	}
+	req.Header.Set("Range", byteRange)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "HTTP range request errored")
+	}
+	if resp.StatusCode == http.StatusOK {
+		// the data does not match the validators anymore
+		resp.Body.Close()
+		return nil, &httpDataChangedError{url: req.URL.String()}
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, &httpStatusError{expected: http.StatusPartialContent, code: resp.StatusCode, status: resp.Status}
+	}
+	contentRange := resp.Header.Get("Content-Range")
+	if !strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", start)) {
+		resp.Body.Close()
+		return nil, errors.Errorf("expected the range starting at %d, got %q", start, contentRange)
+	}
+	etag := resp.Header.Get("ETag")
+	if !strings.HasSuffix(contentRange, fmt.Sprintf("/%d", r.validators.Size)) ||
+		(r.validators.ETag != "" && etag != "" && etag != r.validators.ETag) {
+		// a server ignoring If-Range returns the range of other data
+		resp.Body.Close()
+		return nil, &httpDataChangedError{url: req.URL.String()}
+	}
+	return resp.Body, nil
+}
+
+// persist records the data downloaded to dir, along with its validators. The data recorded by a
+// previous attempt of the import is read first, and its download resumed, if its validators did
+// not change. Otherwise the data read so far is downloaded again to be recorded.
+func (r *resumableBody) persist(dir string) error {
+	dataPath, statePath := filepath.Join(dir, downloadFile), filepath.Join(dir, downloadStateFile)
+	file, err := os.OpenFile(dataPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not open the downloaded data")
+	}
+	r.file, r.statePath = file, statePath
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Wrap(err, "could not open the downloaded data")
+	}
+	if previous, err := readDownloadState(statePath); err == nil && previous == r.validators &&
+		info.Size() >= r.offset && info.Size() <= r.validators.Size {
+		klog.Infof("Resuming the download of %s recorded by a previous attempt, at offset %d", r.req.URL, info.Size())
+		r.recorded = info.Size()
+		if r.recorded > r.offset && r.body != nil {
+			// the body is requested again from the end of the recorded data
+			r.body.Close()
+			r.body = nil
+		}
+		return nil
+	}
+	if err := file.Truncate(0); err != nil {
+		return errors.Wrap(err, "could not truncate the downloaded data")
+	}
+	state, _ := json.Marshal(r.validators)
+	if err := os.WriteFile(statePath, state, 0600); err != nil {
+		return errors.Wrap(err, "could not write the validators of the downloaded data")
+	}
+	if r.offset > 0 {
+		body, err := r.rangeRequest(0, r.offset-1)
+		if err != nil {
+			if _, changed := err.(*httpDataChangedError); changed {
+				r.discard()
+			}
+			return err
+		}
+		defer body.Close()
+		if _, err := io.CopyN(file, body, r.offset); err != nil {
+			return errors.Wrap(err, "could not record the downloaded data")
+		}
+	}
+	r.recorded = r.offset
+	return nil
+}
+
+// readDownloadState returns the validators of the data recorded by a previous attempt.
+func readDownloadState(statePath string) (httpValidators, error) {
+	var validators httpValidators
+	state, err := os.ReadFile(statePath)
+	if err != nil {
+		return validators, err
+	}
+	err = json.Unmarshal(state, &validators)
+	return validators, err
+}
+
+// complete moves the recorded data to fileName, or removes it when fileName is empty.
+func (r *resumableBody) complete(fileName string) error {
+	if r.file == nil {
+		return nil
+	}
+	dataPath := r.file.Name()
+	r.file.Close()
+	r.file = nil
+	os.Remove(r.statePath)
+	if fileName == "" {
+		return os.Remove(dataPath)
+	}
+	return os.Rename(dataPath, fileName)
+}
+
+// discard removes the recorded data.
+func (r *resumableBody) discard() {
+	if r.file != nil {
+		r.complete("")
+	}
+}
+
+// Close closes the body, the recorded data is kept for the next attempt.
+func (r *resumableBody) Close() error {
+	var err error
+	if r.body != nil {
+		err = r.body.Close()
+		r.body = nil
+	}
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	return err
+}
@@ -0,0 +1,331 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+)
+
+// resumeServer serves data with range requests, validated by its ETag. The first cuts downloads
+// are cut after cutAfter bytes, the data is replaced by changedData, when it is set, once a bounded
+// range request was served, and the range requests resuming a download fail with failResume, the
+// bounded range requests from an offset other than 0 with failChunks.
+type resumeServer struct {
+	*httptest.Server
+
+	mutex       sync.Mutex
+	data        []byte
+	etag        string
+	cuts        int
+	cutAfter    int64
+	changedData []byte
+	failResume  bool
+	failChunks  bool
+	ranges      []string
+}
+
+func newResumeServer(data []byte) *resumeServer {
+	s := &resumeServer{data: data, etag: `"v1"`}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *resumeServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	data, etag := s.data, s.etag
+	byteRange := r.Header.Get("Range")
+	if byteRange != "" {
+		s.ranges = append(s.ranges, byteRange)
+	}
+	// the responses downloading the data are cut, not those to HEAD or bounded range requests
+	cut := s.cuts > 0 && r.Method == http.MethodGet && (byteRange == "" || strings.HasSuffix(byteRange, "-"))
+	if cut {
+		s.cuts--
+	}
+	failResume, failChunks := s.failResume, s.failChunks
+	s.mutex.Unlock()
+	if failResume && strings.HasSuffix(byteRange, "-") {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if failChunks && byteRange != "" && !strings.HasSuffix(byteRange, "-") && !strings.HasPrefix(byteRange, "bytes=0-") {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	if !cut {
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+		if byteRange != "" && !strings.HasSuffix(byteRange, "-") {
+			s.mutex.Lock()
+			if s.changedData != nil {
+				s.data, s.etag = s.changedData, `"v2"`
+			}
+			s.mutex.Unlock()
+		}
+		return
+	}
+	http.ServeContent(&cuttingWriter{ResponseWriter: w, remaining: s.cutAfter}, r, "", time.Time{}, bytes.NewReader(data))
+	w.(http.Flusher).Flush()
+	// closes the connection before the end of the response
+	panic(http.ErrAbortHandler)
+}
+
+func (s *resumeServer) requestedRanges() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.ranges...)
+}
+
+// cuttingWriter writes up to remaining bytes of a response.
+type cuttingWriter struct {
+	http.ResponseWriter
+	remaining int64
+}
+
+func (w *cuttingWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > w.remaining {
+		p = p[:w.remaining]
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.remaining -= int64(n)
+	if err == nil && w.remaining == 0 {
+		err = http.ErrAbortHandler
+	}
+	return n, err
+}
+
+var _ = Describe("Resumed http downloads", func() {
+	var (
+		server   *resumeServer
+		tmpDir   string
+		interval time.Duration
+	)
+
+	BeforeEach(func() {
+		createNbdkitCurl = image.NewMockNbdkitCurl
+		interval, httpResumeInterval = httpResumeInterval, time.Millisecond
+		server = newResumeServer(cirrosData)
+		server.cutAfter = 1024 * 1024
+		var err error
+		tmpDir, err = os.MkdirTemp("", "scratch")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		httpResumeInterval = interval
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	// transfer transfers the data of the server to the scratch space
+	transfer := func() (*HTTPDataSource, error) {
+		dp, err := NewHTTPDataSource(server.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dp.download).NotTo(BeNil())
+		_, err = dp.Transfer(tmpDir)
+		dp.Close()
+		return dp, err
+	}
+
+	expectTransferred := func(want []byte) {
+		written, err := os.ReadFile(filepath.Join(tmpDir, tempFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes.Equal(written, want)).To(BeTrue())
+		Expect(filepath.Join(tmpDir, downloadFile)).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tmpDir, downloadStateFile)).NotTo(BeAnExistingFile())
+	}
+
+	table.DescribeTable("should tell whether the download of a response can be resumed", func(header http.Header, size int64, expected bool) {
+		header.Set("Accept-Ranges", "bytes")
+		_, resumable := resumableValidators("http://example.com/disk.img", &http.Response{Header: header, ContentLength: size})
+		Expect(resumable).To(Equal(expected))
+	},
+		table.Entry("with a strong ETag", http.Header{"Etag": {`"v1"`}}, int64(100), true),
+		table.Entry("with a Last-Modified date", http.Header{"Last-Modified": {"Tue, 01 Mar 2022 12:34:56 GMT"}}, int64(100), true),
+		table.Entry("not with a weak ETag", http.Header{"Etag": {`W/"v1"`}}, int64(100), false),
+		table.Entry("not without validators", http.Header{}, int64(100), false),
+		table.Entry("not with a Content-Encoding", http.Header{"Etag": {`"v1"`}, "Content-Encoding": {"gzip"}}, int64(100), false),
+		table.Entry("not with an unknown size", http.Header{"Etag": {`"v1"`}}, int64(-1), false),
+	)
+
+	It("should not resume the download of a server not accepting byte ranges", func() {
+		_, resumable := resumableValidators("http://example.com/disk.img", &http.Response{Header: http.Header{"Etag": {`"v1"`}}, ContentLength: 100})
+		Expect(resumable).To(BeFalse())
+	})
+
+	It("should resume a download after the connection drops", func() {
+		server.cuts = 2
+		_, err := transfer()
+		Expect(err).NotTo(HaveOccurred())
+		expectTransferred(cirrosData)
+		Expect(len(server.requestedRanges())).To(BeNumerically(">=", 2))
+	})
+
+	It("should resume the download of compressed data, decompressed once downloaded", func() {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		_, err := w.Write(cirrosData)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		server.data = compressed.Bytes()
+		server.cutAfter = int64(compressed.Len() / 3)
+		server.cuts = 1
+		_, err = transfer()
+		Expect(err).NotTo(HaveOccurred())
+		expectTransferred(cirrosData)
+	})
+
+	It("should resume the download recorded by a previous attempt", func() {
+		server.cuts, server.failResume = 1, true
+		_, err := transfer()
+		Expect(err).To(HaveOccurred())
+		info, err := os.Stat(filepath.Join(tmpDir, downloadFile))
+		Expect(err).NotTo(HaveOccurred())
+		recorded := info.Size()
+		Expect(recorded).To(BeNumerically(">", 0))
+		Expect(recorded).To(BeNumerically("<", len(cirrosData)))
+		Expect(filepath.Join(tmpDir, downloadStateFile)).To(BeAnExistingFile())
+
+		Expect(cleanScratchSpace(tmpDir)).To(Succeed())
+		Expect(filepath.Join(tmpDir, tempFile)).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tmpDir, downloadFile)).To(BeAnExistingFile())
+
+		server.failResume = false
+		_, err = transfer()
+		Expect(err).NotTo(HaveOccurred())
+		expectTransferred(cirrosData)
+		Expect(server.requestedRanges()).To(ContainElement(fmt.Sprintf("bytes=%d-", recorded)))
+	})
+
+	It("should restart the download recorded by a previous attempt when the data changed", func() {
+		server.cuts, server.failResume = 1, true
+		_, err := transfer()
+		Expect(err).To(HaveOccurred())
+		Expect(filepath.Join(tmpDir, downloadFile)).To(BeAnExistingFile())
+
+		changed := append([]byte{}, cirrosData...)
+		changed[len(changed)-1]++
+		server.data, server.etag, server.failResume = changed, `"v2"`, false
+		_, err = transfer()
+		Expect(err).NotTo(HaveOccurred())
+		expectTransferred(changed)
+	})
+
+	It("should fail when the data changes while it is downloaded", func() {
+		server.cuts = 1
+		server.changedData = append([]byte{}, cirrosData...)
+		_, err := transfer()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("changed while it was downloaded"))
+		Expect(filepath.Join(tmpDir, downloadFile)).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tmpDir, downloadStateFile)).NotTo(BeAnExistingFile())
+	})
+
+	Context("with mirrors", func() {
+		var (
+			mirror   *resumeServer
+			failing  *httptest.Server
+			requests int
+		)
+
+		BeforeEach(func() {
+			mirror = newResumeServer(cirrosData)
+			requests = 0
+			failing = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+		})
+
+		AfterEach(func() {
+			os.Unsetenv(common.ImporterMirrors)
+			mirror.Close()
+			failing.Close()
+		})
+
+		// transferFrom transfers the data of the endpoint, or of its mirrors, to the scratch space
+		transferFrom := func(endpoint string, mirrors ...string) (*HTTPDataSource, error) {
+			os.Setenv(common.ImporterMirrors, strings.Join(mirrors, " "))
+			dp, err := NewHTTPDataSource(endpoint, "", "", "", cdiv1.DataVolumeKubeVirt)
+			if err != nil {
+				return nil, err
+			}
+			_, err = dp.Info()
+			Expect(err).NotTo(HaveOccurred())
+			_, err = dp.Transfer(tmpDir)
+			dp.Close()
+			return dp, err
+		}
+
+		It("should download the data of a mirror when the endpoint fails with a server error", func() {
+			dp, err := transferFrom(failing.URL+"/disk.img", mirror.URL+"/disk.img?token=secret")
+			Expect(err).NotTo(HaveOccurred())
+			expectTransferred(cirrosData)
+			Expect(requests).To(BeNumerically(">", 0))
+			Expect(dp.SourceURL()).To(Equal(mirror.URL + "/disk.img"))
+		})
+
+		It("should download the data of a mirror when the endpoint cannot be reached", func() {
+			failing.Close()
+			dp, err := transferFrom(failing.URL+"/disk.img", failing.URL+"/mirror.img", mirror.URL+"/disk.img")
+			Expect(err).NotTo(HaveOccurred())
+			expectTransferred(cirrosData)
+			Expect(dp.SourceURL()).To(Equal(mirror.URL + "/disk.img"))
+		})
+
+		It("should not try the mirrors when the endpoint is not found", func() {
+			notFound := httptest.NewServer(http.NotFoundHandler())
+			defer notFound.Close()
+			_, err := transferFrom(notFound.URL+"/disk.img", failing.URL+"/disk.img")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("expected status code 200, got 404"))
+			Expect(requests).To(BeZero())
+		})
+
+		It("should report the endpoint which served the data", func() {
+			dp, err := transferFrom(server.URL+"/disk.img", mirror.URL+"/disk.img")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dp.SourceURL()).To(Equal(server.URL + "/disk.img"))
+			dp, err = transferFrom(server.URL + "/disk.img")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dp.SourceURL()).To(BeEmpty())
+		})
+
+		It("should resume a download from a mirror serving the same data", func() {
+			server.cuts, server.failResume = 1, true
+			dp, err := transferFrom(server.URL+"/disk.img", failing.URL+"/disk.img", mirror.URL+"/disk.img")
+			Expect(err).NotTo(HaveOccurred())
+			expectTransferred(cirrosData)
+			Expect(dp.SourceURL()).To(Equal(mirror.URL + "/disk.img"))
+			Expect(mirror.requestedRanges()).To(HaveLen(1))
+			Expect(mirror.requestedRanges()[0]).To(HavePrefix("bytes="))
+			Expect(mirror.requestedRanges()[0]).NotTo(Equal("bytes=0-"))
+		})
+
+		It("should not resume a download from a mirror serving other data", func() {
+			server.cuts, server.failResume = 1, true
+			mirror.etag = `"v2"`
+			_, err := transferFrom(server.URL+"/disk.img", mirror.URL+"/disk.img")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("after 3 retries"))
+			Expect(filepath.Join(tmpDir, downloadFile)).To(BeAnExistingFile())
+		})
+	})
+})
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// ErrUnknownVirtualSize is the error of an http source whose virtual size cannot be found without
+// downloading it, retrying cannot succeed.
+var ErrUnknownVirtualSize = fmt.Errorf("unknown virtual size")
+
+// HTTPVirtualSize returns the virtual size of the disk image of an http source without downloading
+// it. The size of a qcow2 or vmdk image is read from its header, requested with a range request of
+// its first bytes, the size of a raw image is its content length, returned by a HEAD request. It
+// fails with ErrUnknownVirtualSize for the other formats, the compressed images among others, and
+// for a raw image whose content length is not returned.
+func HTTPVirtualSize(endpoint, accessKey, secKey, certDir string) (int64, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to parse endpoint %q", endpoint)
+	}
+	ctx := context.Background()
+	extraHeaders, secretExtraHeaders, err := getExtraHeaders()
+	if err != nil {
+		return 0, errors.Wrap(err, "Error getting extra headers for HTTP client")
+	}
+	if token, _ := util.ParseEnvVar(common.ImporterBearerToken, false); strings.TrimSpace(token) != "" {
+		accessKey, secKey = "", ""
+		secretExtraHeaders = append(secretExtraHeaders, "Authorization: Bearer "+strings.TrimSpace(token))
+	}
+	pattern, err := httpURLPattern()
+	if err != nil {
+		return 0, err
+	}
+	if pattern != "" {
+		matched, err := resolveIndexedEndpoint(ctx, ep, pattern, accessKey, secKey, certDir, extraHeaders, secretExtraHeaders)
+		if err != nil {
+			return 0, err
+		}
+		if !sameOrigin(matched, ep) {
+			accessKey, secKey, secretExtraHeaders = "", "", nil
+		}
+		ep = matched
+	}
+
+	r, err := newHTTPRangeReader(ctx, ep, accessKey, secKey, certDir, extraHeaders, secretExtraHeaders)
+	if err != nil {
+		return 0, err
+	}
+	hdr := make([]byte, image.MaxExpectedHdrSize)
+	n, err := r.ReadAt(hdr, 0)
+	if err != nil && err != io.EOF {
+		return 0, errors.Wrapf(err, "could not read the header of %s", ep.Redacted())
+	}
+	hdr = hdr[:n]
+	format, _, err := image.DetectFormat(bytes.NewReader(hdr))
+	if err != nil {
+		return 0, err
+	}
+	if size, ok := image.HeaderVirtualSize(format, hdr); ok {
+		klog.V(1).Infof("The virtual size of the %s image %s is %d", format, ep.Redacted(), size)
+		return size, nil
+	}
+	if format != image.FormatRaw {
+		return 0, fmt.Errorf("%w: the header of a %s image does not hold its virtual size", ErrUnknownVirtualSize, format)
+	}
+	size, err := getContentLength(r.client, ep, r.accessKey, r.secKey, r.headers)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not get the content length of %s", ep.Redacted())
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("%w: the server did not return the content length of the raw image", ErrUnknownVirtualSize)
+	}
+	klog.V(1).Infof("The virtual size of the raw image %s is its content length %d", ep.Redacted(), size)
+	return int64(size), nil
+}
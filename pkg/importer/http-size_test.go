@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Http virtual size", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, password, _ := r.BasicAuth(); user != "user" || password != "password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			data, err := os.ReadFile(filepath.Join(imageDir, filepath.Base(r.URL.Path)))
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.URL.Query().Get("ranges") == "none" {
+				w.Write(data)
+				return
+			}
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	table.DescribeTable("should find the virtual size", func(name string, expected int64) {
+		size, err := HTTPVirtualSize(server.URL+"/"+name, "user", "password", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(expected))
+	},
+		table.Entry("of a qcow2 image in its header", cirrosFileName, int64(46137344)),
+		table.Entry("of a raw image from its content length", tinyCoreFileName, int64(18874368)),
+	)
+
+	It("should not find the virtual size of a compressed image", func() {
+		_, err := HTTPVirtualSize(server.URL+"/"+tinyCoreGz, "user", "password", "")
+		Expect(errors.Is(err, ErrUnknownVirtualSize)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("the header of a gz image does not hold its virtual size"))
+	})
+
+	It("should fail when the server does not serve ranges", func() {
+		_, err := HTTPVirtualSize(server.URL+"/"+cirrosFileName+"?ranges=none", "user", "password", "")
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrUnknownVirtualSize)).To(BeFalse())
+		Expect(err.Error()).To(ContainSubstring("expected status code 206"))
+	})
+
+	It("should fail when the source cannot be downloaded", func() {
+		_, err := HTTPVirtualSize(server.URL+"/"+cirrosFileName, "", "", "")
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrUnknownVirtualSize)).To(BeFalse())
+		Expect(err.Error()).To(ContainSubstring("401"))
+	})
+})
@@ -41,6 +41,10 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
+// imageioTicketExtendInterval is how often the transfer ticket is extended while the disk is streamed,
+// well before the ticket expires.
+var imageioTicketExtendInterval = time.Minute
+
 // ImageioDataSource is the data provider for ovirt-imageio.
 type ImageioDataSource struct {
 	imageioReader io.ReadCloser
@@ -49,6 +53,8 @@ type ImageioDataSource struct {
 	cancelLock    sync.Mutex
 	cleanupLock   sync.Mutex
 	cleanupDone   bool
+	// transferred is set once the whole disk was read, the transfer is then finalized instead of cancelled
+	transferred bool
 	// stack of readers
 	readers *FormatReaders
 	// url the url to report to the caller of getURL, could be the endpoint, or a file in scratch space.
@@ -70,7 +76,7 @@ func NewImageioDataSource(endpoint string, accessKey string, secKey string, cert
 	ctx, cancel := context.WithCancel(context.Background())
 	imageioReader, contentLength, it, conn, err := createImageioReader(ctx, endpoint, accessKey, secKey, certDir, diskID, currentCheckpoint, previousCheckpoint)
 	if err != nil {
-		cleanupError := cleanupTransfer(conn, it)
+		cleanupError := cleanupTransfer(conn, it, false)
 		if cleanupError != nil {
 			klog.Errorf("Failed to close image transfer after failure creating data source: %v", cleanupError)
 		}
@@ -91,6 +97,11 @@ func NewImageioDataSource(endpoint string, accessKey string, secKey string, cert
 	// We know this is a counting reader, so no need to check.
 	countingReader := imageioReader.(*util.CountingReader)
 	go imageioSource.pollProgress(countingReader, 10*time.Minute, time.Second)
+	if transferID, available := it.Id(); available {
+		go imageioSource.extendTicket(transferID, imageioTicketExtendInterval)
+	} else {
+		klog.Warning("Unable to retrieve image transfer ID, the transfer ticket will not be extended")
+	}
 
 	terminationChannel := newTerminationChannel()
 	go func() {
@@ -135,6 +146,7 @@ func (is *ImageioDataSource) Transfer(path string) (ProcessingPhase, error) {
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
+	is.markTransferred()
 	// If we successfully wrote to the file, then the parse will succeed.
 	is.url, _ = url.Parse(file)
 
@@ -175,6 +187,7 @@ func (is *ImageioDataSource) TransferFile(fileName string) (ProcessingPhase, err
 			return ProcessingPhaseError, err
 		}
 	}
+	is.markTransferred()
 	return ProcessingPhaseResize, nil
 }
 
@@ -229,6 +242,45 @@ func (is *ImageioDataSource) pollProgress(reader *util.CountingReader, idleTime,
 	}
 }
 
+// extendTicket extends the transfer ticket every interval, so that it does not expire while the
+// disk is streamed, until the transfer is cleaned up or the data source is closed.
+func (is *ImageioDataSource) extendTicket(transferID string, interval time.Duration) {
+	transferService := is.connection.SystemService().ImageTransfersService().ImageTransferService(transferID)
+	for {
+		select {
+		case <-time.After(interval):
+		case <-is.ctx.Done():
+			return
+		}
+		if is.isCleanupDone() {
+			return
+		}
+		_, err := transferService.Extend().Send()
+		// The transfer may have been cleaned up while the ticket was extended, failing the extension.
+		if is.isCleanupDone() {
+			return
+		}
+		if err != nil {
+			klog.Warningf("Unable to extend transfer ticket: %v", err)
+		}
+	}
+}
+
+// isCleanupDone returns true once the transfer was finalized or cancelled.
+func (is *ImageioDataSource) isCleanupDone() bool {
+	is.cleanupLock.Lock()
+	defer is.cleanupLock.Unlock()
+	return is.cleanupDone
+}
+
+// markTransferred records that the whole disk was read, it is guarded by the cleanup lock since
+// the transfer can be cleaned up from the termination handler at the same time.
+func (is *ImageioDataSource) markTransferred() {
+	is.cleanupLock.Lock()
+	defer is.cleanupLock.Unlock()
+	is.transferred = true
+}
+
 // IsDeltaCopy is called to determine if this is a full copy or one delta copy stage
 // in a multi-stage migration.
 func (is *ImageioDataSource) IsDeltaCopy() bool {
@@ -607,13 +659,14 @@ func createImageioReader(ctx context.Context, ep string, accessKey string, secKe
 
 // Wrapper around cleanupTransfer that also clears is.imageTransfer.
 // Avoids unnecessary cleanup work when the transfer is interrupted by SIGTERM.
+// The transfer is finalized once the whole disk was read, and cancelled otherwise.
 func (is *ImageioDataSource) cleanupTransfer() {
 	is.cleanupLock.Lock()
 	defer is.cleanupLock.Unlock()
 	if is.cleanupDone {
 		return
 	}
-	err := cleanupTransfer(is.connection, is.imageTransfer)
+	err := cleanupTransfer(is.connection, is.imageTransfer, is.transferred)
 	if err != nil {
 		klog.Errorf("Failed to clean up image transfer: %v", err)
 	} else {
@@ -621,8 +674,10 @@ func (is *ImageioDataSource) cleanupTransfer() {
 	}
 }
 
-// cleanupTransfer makes sure the disk is unlocked before shutting down importer
-func cleanupTransfer(conn ConnectionInterface, it *ovirtsdk4.ImageTransfer) error {
+// cleanupTransfer makes sure the disk is unlocked before shutting down importer. A transfer still
+// in progress is finalized when finalize is set, and cancelled otherwise so that an interrupted
+// transfer does not leave the disk locked.
+func cleanupTransfer(conn ConnectionInterface, it *ovirtsdk4.ImageTransfer, finalize bool) error {
 	var err error
 
 	if conn == nil || it == nil {
@@ -643,8 +698,8 @@ func cleanupTransfer(conn ConnectionInterface, it *ovirtsdk4.ImageTransfer) erro
 	for retries := 10; retries > 0; retries-- {
 		cancelTransfer := func() error {
 			klog.Info("Cancelling image transfer.")
-			if _, cancelError := transferService.Cancel().Send(); err != nil {
-				klog.Errorf("Unable to cancel transfer request: %v", err)
+			if _, cancelError := transferService.Cancel().Send(); cancelError != nil {
+				klog.Errorf("Unable to cancel transfer request: %v", cancelError)
 				return cancelError
 			}
 			return nil
@@ -652,8 +707,8 @@ func cleanupTransfer(conn ConnectionInterface, it *ovirtsdk4.ImageTransfer) erro
 
 		finalizeTransfer := func() error {
 			klog.Info("Finalizing image transfer.")
-			if _, finalizeError := transferService.Finalize().Send(); err != nil {
-				klog.Errorf("Unable to finalize transfer request: %v", err)
+			if _, finalizeError := transferService.Finalize().Send(); finalizeError != nil {
+				klog.Errorf("Unable to finalize transfer request: %v", finalizeError)
 				return finalizeError
 			}
 			return nil
@@ -689,6 +744,10 @@ func cleanupTransfer(conn ConnectionInterface, it *ovirtsdk4.ImageTransfer) erro
 		}
 
 		klog.Infof("Current image transfer phase is: %+v", transferPhase)
+		transferringAction := cancelTransfer
+		if finalize {
+			transferringAction = finalizeTransfer
+		}
 		phaseActions := map[ovirtsdk4.ImageTransferPhase]func() error{
 			ovirtsdk4.IMAGETRANSFERPHASE_CANCELLED:          nil,
 			ovirtsdk4.IMAGETRANSFERPHASE_FINALIZING_FAILURE: nil,
@@ -699,7 +758,7 @@ func cleanupTransfer(conn ConnectionInterface, it *ovirtsdk4.ImageTransfer) erro
 			ovirtsdk4.IMAGETRANSFERPHASE_PAUSED_SYSTEM:      cancelTransfer,
 			ovirtsdk4.IMAGETRANSFERPHASE_PAUSED_USER:        cancelTransfer,
 			ovirtsdk4.IMAGETRANSFERPHASE_RESUMING:           cancelTransfer,
-			ovirtsdk4.IMAGETRANSFERPHASE_TRANSFERRING:       finalizeTransfer,
+			ovirtsdk4.IMAGETRANSFERPHASE_TRANSFERRING:       transferringAction,
 			ovirtsdk4.IMAGETRANSFERPHASE_UNKNOWN:            cancelTransfer,
 			// Observed from RHV, but not yet listed in Go API:
 			"cancelled_system":   nil,
@@ -13,6 +13,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -35,6 +36,7 @@ var diskSnapshots = &ovirtsdk4.DiskSnapshotSlice{}
 var storageDomain = &ovirtsdk4.StorageDomain{}
 var storageDomains = &ovirtsdk4.StorageDomainSlice{}
 var renewalTime time.Time
+var renewalLock sync.Mutex
 
 var _ = Describe("Imageio reader", func() {
 	var (
@@ -257,6 +259,7 @@ var _ = Describe("Imageio cancel", func() {
 	AfterEach(func() {
 		mockCancelHook = nil
 		mockFinalizeHook = nil
+		mockExtendHook = nil
 		newOvirtClientFunc = getOvirtClient
 		if tempDir != "" {
 			os.RemoveAll(tempDir)
@@ -264,21 +267,21 @@ var _ = Describe("Imageio cancel", func() {
 		ts.Close()
 	})
 
-	It("should clean up transfer on SIGTERM", func() {
+	It("should cancel the transfer on SIGTERM", func() {
 		dp, err := NewImageioDataSource(ts.URL, "", "", tempDir, diskID, "", "")
 		Expect(err).ToNot(HaveOccurred())
-		timesFinalized := 0
+		timesCancelled := 0
 		resultChannel := make(chan struct {
 			*ImageioDataSource
 			int
 		}, 1)
-		mockFinalizeHook = func() error {
-			dp.imageTransfer.SetPhase(ovirtsdk4.IMAGETRANSFERPHASE_FINALIZING_SUCCESS)
-			timesFinalized++
+		mockCancelHook = func() error {
+			dp.imageTransfer.SetPhase(ovirtsdk4.IMAGETRANSFERPHASE_CANCELLED)
+			timesCancelled++
 			resultChannel <- struct {
 				*ImageioDataSource
 				int
-			}{dp, timesFinalized}
+			}{dp, timesCancelled}
 			return nil
 		}
 		mockTerminationChannel <- os.Interrupt
@@ -287,18 +290,79 @@ var _ = Describe("Imageio cancel", func() {
 		case <-timeout:
 			Fail("Timed out waiting for cancel result")
 		case result := <-resultChannel:
-			timesFinalized = result.int
+			timesCancelled = result.int
 			dp = result.ImageioDataSource
 		}
 		Expect(err).ToNot(HaveOccurred())
+		Expect(timesCancelled).To(Equal(1))
+		Expect(dp.imageTransfer.MustPhase()).To(Equal(ovirtsdk4.IMAGETRANSFERPHASE_CANCELLED))
+	})
+
+	It("should finalize the transfer once the disk was transferred", func() {
+		dp, err := NewImageioDataSource(ts.URL, "", "", tempDir, diskID, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		timesFinalized := 0
+		mockFinalizeHook = func() error {
+			dp.imageTransfer.SetPhase(ovirtsdk4.IMAGETRANSFERPHASE_FINALIZING_SUCCESS)
+			timesFinalized++
+			return nil
+		}
+		mockCancelHook = func() error {
+			Fail("the transferred disk should not be cancelled")
+			return nil
+		}
+		_, err = dp.Info()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = dp.TransferFile(path.Join(tempDir, "disk.img"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dp.Close()).To(Succeed())
 		Expect(timesFinalized).To(Equal(1))
-		Expect(dp.imageTransfer.MustPhase()).To(Equal(ovirtsdk4.IMAGETRANSFERPHASE_FINALIZING_SUCCESS))
+	})
+
+	It("should extend the transfer ticket until the transfer is cleaned up", func() {
+		interval := imageioTicketExtendInterval
+		imageioTicketExtendInterval = time.Millisecond
+		defer func() { imageioTicketExtendInterval = interval }()
+		ticketTime := time.Now()
+		renewalTime = ticketTime
+		dp, err := NewImageioDataSource(ts.URL, "", "", tempDir, diskID, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(func() bool {
+			renewalLock.Lock()
+			defer renewalLock.Unlock()
+			return renewalTime.After(ticketTime)
+		}, 5*time.Second, time.Millisecond).Should(BeTrue())
+		Expect(dp.Close()).To(Succeed())
+	})
+
+	It("should clean up the transfer while the transfer ticket is extended", func() {
+		interval := imageioTicketExtendInterval
+		imageioTicketExtendInterval = time.Millisecond
+		defer func() { imageioTicketExtendInterval = interval }()
+		extending := make(chan struct{}, 1)
+		release := make(chan struct{})
+		mockExtendHook = func() error {
+			select {
+			case extending <- struct{}{}:
+			default:
+			}
+			<-release
+			return errors.New("transfer is finalized")
+		}
+		dp, err := NewImageioDataSource(ts.URL, "", "", tempDir, diskID, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(extending, 5*time.Second).Should(Receive())
+		dp.cleanupTransfer()
+		Expect(dp.isCleanupDone()).To(BeTrue())
+		close(release)
+		Expect(dp.Close()).To(Succeed())
 	})
 
 	DescribeTable("should finalize successful transfer on close", func(initialPhase, expectedPhase ovirtsdk4.ImageTransferPhase) {
 		dp, err := NewImageioDataSource(ts.URL, "", "", tempDir, diskID, "", "")
 		dp.imageTransfer.SetPhase(initialPhase)
 		Expect(err).ToNot(HaveOccurred())
+		dp.transferred = true
 		timesFinalized := 0
 		mockFinalizeHook = func() error {
 			dp.imageTransfer.SetPhase(expectedPhase)
@@ -332,6 +396,7 @@ var _ = Describe("Imageio cancel", func() {
 		Entry("from paused_system", ovirtsdk4.IMAGETRANSFERPHASE_PAUSED_SYSTEM, ovirtsdk4.IMAGETRANSFERPHASE_CANCELLED),
 		Entry("from paused_user", ovirtsdk4.IMAGETRANSFERPHASE_PAUSED_USER, ovirtsdk4.IMAGETRANSFERPHASE_CANCELLED),
 		Entry("from resuming", ovirtsdk4.IMAGETRANSFERPHASE_RESUMING, ovirtsdk4.IMAGETRANSFERPHASE_CANCELLED),
+		Entry("from transferring", ovirtsdk4.IMAGETRANSFERPHASE_TRANSFERRING, ovirtsdk4.IMAGETRANSFERPHASE_CANCELLED),
 		Entry("from unknown", ovirtsdk4.IMAGETRANSFERPHASE_UNKNOWN, ovirtsdk4.IMAGETRANSFERPHASE_CANCELLED),
 	)
 
@@ -826,6 +891,13 @@ func (conn *MockCancelService) Send() (ImageTransferServiceCancelResponseInterfa
 }
 
 func (conn *MockExtendService) Send() (ImageTransferServiceExtendResponseInterface, error) {
+	if mockExtendHook != nil {
+		if err := mockExtendHook(); err != nil {
+			return nil, err
+		}
+	}
+	renewalLock.Lock()
+	defer renewalLock.Unlock()
 	renewalTime = time.Now()
 	return &MockImageTransferServiceExtendResponse{}, nil
 }
@@ -862,6 +934,7 @@ func (conn *MockOvirtClient) Close() error {
 
 var mockCancelHook func() error
 var mockFinalizeHook func() error
+var mockExtendHook func() error
 
 func failMockOvirtClient(ep string, accessKey string, secKey string, certDir string) (ConnectionInterface, error) {
 	return nil, errors.New("Failed to create client")
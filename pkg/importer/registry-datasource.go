@@ -33,6 +33,11 @@ const (
 	// containerDiskImageDir - Expected disk image location in container image as described in
 	// https://github.com/kubevirt/kubevirt/blob/main/docs/container-register-disks.md
 	containerDiskImageDir = "disk"
+	// containerDiskImageFile - Disk image at the root of a container image, such as one made of a single layer holding the
+	// disk image alone, imported when the image has no disk image directory
+	containerDiskImageFile = "disk.img"
+	// defaultArtifactMediaType - Media type of the disk image blob of an OCI artifact, such as one pushed with oras
+	defaultArtifactMediaType = "application/x-qemu-disk"
 )
 
 // RegistryDataSource is the struct containing the information needed to import from a registry data source.
@@ -45,7 +50,13 @@ type RegistryDataSource struct {
 	secKey      string
 	certDir     string
 	insecureTLS bool
-	imageDir    string
+	// artifactMediaType is the media type of the blob imported from an OCI artifact
+	artifactMediaType string
+	// platform is the platform of the image imported from a manifest list, the platform of the node when empty
+	platform string
+	// defaultPlatform is the platform imported from a manifest list without an image for the platform of the node
+	defaultPlatform string
+	imageDir        string
 	//The discovered image file in scratch space.
 	url *url.URL
 }
@@ -63,12 +74,19 @@ func NewRegistryDataSource(endpoint, accessKey, secKey, certDir string, insecure
 		}
 		allCertDir = certDir
 	}
+	artifactMediaType := os.Getenv(common.ImporterRegistryArtifactMediaType)
+	if artifactMediaType == "" {
+		artifactMediaType = defaultArtifactMediaType
+	}
 	return &RegistryDataSource{
-		endpoint:    endpoint,
-		accessKey:   accessKey,
-		secKey:      secKey,
-		certDir:     allCertDir,
-		insecureTLS: insecureTLS,
+		endpoint:          endpoint,
+		accessKey:         accessKey,
+		secKey:            secKey,
+		certDir:           allCertDir,
+		insecureTLS:       insecureTLS,
+		artifactMediaType: artifactMediaType,
+		platform:          os.Getenv(common.ImporterRegistryPlatform),
+		defaultPlatform:   os.Getenv(common.ImporterRegistryDefaultPlatform),
 	}
 }
 
@@ -90,18 +108,36 @@ func (rd *RegistryDataSource) Transfer(path string) (ProcessingPhase, error) {
 	rd.imageDir = filepath.Join(path, containerDiskImageDir)
 
 	klog.V(1).Infof("Copying registry image to scratch space.")
-	err = CopyRegistryImage(rd.endpoint, path, containerDiskImageDir, rd.accessKey, rd.secKey, rd.certDir, rd.insecureTLS)
+	// the blob of an artifact is imported as the disk image at the root, otherwise the layers are searched
+	// for the first file of the disk image directory, or the disk image at the root
+	err = copyRegistryImage(rd.endpoint, path, []string{containerDiskImageDir + "/", containerDiskImageFile}, rd.artifactMediaType,
+		rd.platform, rd.defaultPlatform, rd.accessKey, rd.secKey, rd.certDir, rd.insecureTLS, true)
 	if err != nil {
 		return ProcessingPhaseError, errors.Wrapf(err, "Failed to read registry image")
 	}
 
-	imageFile, err := getImageFileName(rd.imageDir)
+	imagePath := filepath.Join(path, containerDiskImageFile)
+	if _, err := os.Stat(imagePath); err != nil {
+		imageFile, err := getImageFileName(rd.imageDir)
+		if err != nil {
+			return ProcessingPhaseError, errors.Wrapf(err, "Cannot locate image file")
+		}
+		imagePath = filepath.Join(rd.imageDir, imageFile)
+	}
+
+	// the disk image is verified as it is stored in the image, before qemu-img converts it
+	checksum, err := sourceChecksum()
 	if err != nil {
-		return ProcessingPhaseError, errors.Wrapf(err, "Cannot locate image file")
+		return ProcessingPhaseError, err
+	}
+	if checksum != nil {
+		if err := verifyFileChecksum(imagePath, checksum); err != nil {
+			return ProcessingPhaseError, errors.Wrap(err, "the disk image of the registry image does not match its checksum")
+		}
 	}
 
-	// imageFile and rd.imageDir are both valid, thus the Join will be valid, and the parse will work, no need to check for parse errors
-	rd.url, _ = url.Parse(filepath.Join(rd.imageDir, imageFile))
+	// imagePath is valid, and the parse will work, no need to check for parse errors
+	rd.url, _ = url.Parse(imagePath)
 	klog.V(3).Infof("Successfully found file. VM disk image filename is %s", rd.url.String())
 	return ProcessingPhaseConvert, nil
 }
@@ -1,6 +1,15 @@
 package importer
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -8,13 +17,130 @@ import (
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
 )
 
 var (
 	imageFile = filepath.Join(imageDir, "registry-image.tar")
 )
 
+// ociBlobs are the blobs of an OCI image layout, by the hex of their sha256 digest.
+type ociBlobs map[string][]byte
+
+func (blobs ociBlobs) add(data []byte) (string, int) {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	blobs[strings.TrimPrefix(digest, "sha256:")] = data
+	return digest, len(data)
+}
+
+func tarFiles(files map[string][]byte, names []string) []byte {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for _, name := range names {
+		Expect(w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := w.Write(files[name])
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(w.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+// writeOCIArchive writes an oci-archive of an image made of layers, each one holding files named by their path.
+func writeOCIArchive(archivePath string, layers ...map[string][]byte) {
+	blobs := ociBlobs{}
+	var diffIDs []string
+	var layerDescriptors []map[string]interface{}
+	for _, files := range layers {
+		var names []string
+		for name := range files {
+			names = append(names, name)
+		}
+		digest, size := blobs.add(tarFiles(files, names))
+		diffIDs = append(diffIDs, digest)
+		layerDescriptors = append(layerDescriptors, map[string]interface{}{"mediaType": "application/vnd.oci.image.layer.v1.tar", "digest": digest, "size": size})
+	}
+	config, _ := json.Marshal(map[string]interface{}{"architecture": "amd64", "os": "linux", "rootfs": map[string]interface{}{"type": "layers", "diff_ids": diffIDs}})
+	configDigest, configSize := blobs.add(config)
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config":        map[string]interface{}{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": configDigest, "size": configSize},
+		"layers":        layerDescriptors,
+	})
+	writeOCILayoutArchive(archivePath, blobs, manifest, "application/vnd.oci.image.manifest.v1+json")
+}
+
+// artifactManifestBlob returns the manifest of an OCI artifact made of a blob of mediaType, added to blobs, as oras
+// pushes it: an image manifest listing the blob as a layer, or an artifact manifest listing it as a blob.
+func artifactManifestBlob(blobs ociBlobs, manifestMediaType, mediaType string, data []byte) []byte {
+	digest, size := blobs.add(data)
+	descriptors := []map[string]interface{}{{"mediaType": mediaType, "digest": digest, "size": size}}
+	if manifestMediaType == "application/vnd.oci.artifact.manifest.v1+json" {
+		manifest, _ := json.Marshal(map[string]interface{}{
+			"mediaType":    manifestMediaType,
+			"artifactType": "application/vnd.example.disk",
+			"blobs":        descriptors,
+		})
+		return manifest
+	}
+	configDigest, configSize := blobs.add([]byte("{}"))
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     manifestMediaType,
+		"config":        map[string]interface{}{"mediaType": "application/vnd.unknown.config.v1+json", "digest": configDigest, "size": configSize},
+		"layers":        descriptors,
+	})
+	return manifest
+}
+
+// writeOCILayoutArchive writes an oci-archive of blobs, whose index lists manifest.
+func writeOCILayoutArchive(archivePath string, blobs ociBlobs, manifest []byte, manifestMediaType string) {
+	manifestDigest, manifestSize := blobs.add(manifest)
+	index, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"manifests":     []map[string]interface{}{{"mediaType": manifestMediaType, "digest": manifestDigest, "size": manifestSize}},
+	})
+
+	files := map[string][]byte{"oci-layout": []byte(`{"imageLayoutVersion":"1.0.0"}`), "index.json": index}
+	names := []string{"oci-layout", "index.json"}
+	for hex, data := range blobs {
+		files["blobs/sha256/"+hex] = data
+		names = append(names, "blobs/sha256/"+hex)
+	}
+	Expect(os.WriteFile(archivePath, tarFiles(files, names), 0600)).To(Succeed())
+}
+
+// newArtifactRegistry returns a registry serving the manifest, whatever its reference, and the blobs of images/disk.
+// The blobs are looked up when they are requested, they may be replaced meanwhile.
+func newArtifactRegistry(blobs ociBlobs, manifest []byte, manifestMediaType string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case strings.HasPrefix(r.URL.Path, "/v2/images/disk/manifests/"):
+			w.Header().Set("Content-Type", manifestMediaType)
+			w.Write(manifest)
+		case strings.HasPrefix(r.URL.Path, "/v2/images/disk/blobs/sha256:"):
+			data, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/images/disk/blobs/sha256:")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
 var _ = Describe("Registry data source", func() {
+	// artifactData is the disk image of the artifacts, large enough for the headers of the formats once compressed
+	var artifactData []byte
+	for i := 0; i < 4096; i++ {
+		artifactData = append(artifactData, fmt.Sprintf("disk image data %d\n", i)...)
+	}
+
 	var tmpDir string
 	var err error
 	var ds *RegistryDataSource
@@ -64,6 +190,172 @@ var _ = Describe("Registry data source", func() {
 		table.Entry("return Error on valid scratch space, but CopyImage failed", "invalid", "", "", "", "", true, true),
 	)
 
+	It("should import the disk image at the root of a single layer image", func() {
+		archive := filepath.Join(tmpDir, "image.tar")
+		writeOCIArchive(archive, map[string][]byte{"disk.img": []byte("disk image data")})
+		scratch := filepath.Join(tmpDir, "scratch")
+		Expect(os.Mkdir(scratch, 0700)).To(Succeed())
+		ds = NewRegistryDataSource("oci-archive:"+archive, "", "", "", false)
+		result, err := ds.Transfer(scratch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseConvert))
+		Expect(ds.GetURL().Path).To(Equal(filepath.Join(scratch, containerDiskImageFile)))
+		data, err := os.ReadFile(ds.GetURL().Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("disk image data"))
+	})
+
+	It("should search the layers of an image for the disk image directory", func() {
+		archive := filepath.Join(tmpDir, "image.tar")
+		writeOCIArchive(archive,
+			map[string][]byte{"etc/hosts": []byte("127.0.0.1 localhost")},
+			map[string][]byte{"disk/fedora.qcow2": []byte("disk image data"), "README": []byte("readme")})
+		scratch := filepath.Join(tmpDir, "scratch")
+		Expect(os.Mkdir(scratch, 0700)).To(Succeed())
+		ds = NewRegistryDataSource("oci-archive:"+archive, "", "", "", false)
+		result, err := ds.Transfer(scratch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseConvert))
+		Expect(ds.GetURL().Path).To(Equal(filepath.Join(scratch, containerDiskImageDir, "fedora.qcow2")))
+	})
+
+	table.DescribeTable("should verify the disk image against its checksum", func(checksum string, matches bool) {
+		os.Setenv(common.ImporterChecksum, checksum)
+		defer os.Unsetenv(common.ImporterChecksum)
+		archive := filepath.Join(tmpDir, "image.tar")
+		writeOCIArchive(archive, map[string][]byte{"disk/fedora.qcow2": []byte("disk image data")})
+		scratch := filepath.Join(tmpDir, "scratch")
+		Expect(os.Mkdir(scratch, 0700)).To(Succeed())
+		ds = NewRegistryDataSource("oci-archive:"+archive, "", "", "", false)
+		result, err := ds.Transfer(scratch)
+		if matches {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(ProcessingPhaseConvert))
+			return
+		}
+		Expect(errors.Is(err, ErrChecksumMismatch)).To(BeTrue())
+		Expect(result).To(Equal(ProcessingPhaseError))
+		Expect(err.Error()).To(ContainSubstring("fedora.qcow2 is sha256:%x", sha256.Sum256([]byte("disk image data"))))
+	},
+		table.Entry("and import it when it matches", fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("disk image data"))), true),
+		table.Entry("and fail when it does not match", "sha256:"+strings.Repeat("0a", 32), false),
+	)
+
+	It("should fail when no layer of an image holds a disk image", func() {
+		archive := filepath.Join(tmpDir, "image.tar")
+		writeOCIArchive(archive,
+			map[string][]byte{"etc/hosts": []byte("127.0.0.1 localhost")},
+			map[string][]byte{"images/fedora.qcow2": []byte("disk image data"), "diskette.img": []byte("floppy")})
+		scratch := filepath.Join(tmpDir, "scratch")
+		Expect(os.Mkdir(scratch, 0700)).To(Succeed())
+		ds = NewRegistryDataSource("oci-archive:"+archive, "", "", "", false)
+		result, err := ds.Transfer(scratch)
+		Expect(err).To(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseError))
+		Expect(err.Error()).To(ContainSubstring("no file matches 'disk/' or 'disk.img' in its 2 layers"))
+	})
+
+	It("should pull oci:// images from a registry", func() {
+		ref, err := parseImageName("oci://registry.example.com:5000/images/fedora:36")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref.Transport().Name()).To(Equal("docker"))
+		Expect(ref.DockerReference().String()).To(Equal("registry.example.com:5000/images/fedora:36"))
+	})
+
+	table.DescribeTable("should import the disk image blob of an artifact", func(manifestMediaType string) {
+		blobs := ociBlobs{}
+		manifest := artifactManifestBlob(blobs, manifestMediaType, defaultArtifactMediaType, artifactData)
+		archive := filepath.Join(tmpDir, "artifact.tar")
+		writeOCILayoutArchive(archive, blobs, manifest, manifestMediaType)
+		scratch := filepath.Join(tmpDir, "scratch")
+		Expect(os.Mkdir(scratch, 0700)).To(Succeed())
+		ds = NewRegistryDataSource("oci-archive:"+archive, "", "", "", false)
+		result, err := ds.Transfer(scratch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ProcessingPhaseConvert))
+		Expect(ds.GetURL().Path).To(Equal(filepath.Join(scratch, containerDiskImageFile)))
+		data, err := os.ReadFile(ds.GetURL().Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(artifactData))
+	},
+		table.Entry("listed as the layer of an image manifest", "application/vnd.oci.image.manifest.v1+json"),
+		table.Entry("listed as a blob of an artifact manifest", "application/vnd.oci.artifact.manifest.v1+json"),
+	)
+
+	It("should import the blob of the configured media type, decompressed", func() {
+		os.Setenv(common.ImporterRegistryArtifactMediaType, "application/vnd.example.disk.v1+gzip")
+		defer os.Unsetenv(common.ImporterRegistryArtifactMediaType)
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		_, err := w.Write(artifactData)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		blobs := ociBlobs{}
+		manifest := artifactManifestBlob(blobs, "application/vnd.oci.image.manifest.v1+json", "application/vnd.example.disk.v1+gzip", compressed.Bytes())
+		archive := filepath.Join(tmpDir, "artifact.tar")
+		writeOCILayoutArchive(archive, blobs, manifest, "application/vnd.oci.image.manifest.v1+json")
+		ds = NewRegistryDataSource("oci-archive:"+archive, "", "", "", false)
+		_, err = ds.Transfer(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := os.ReadFile(filepath.Join(tmpDir, containerDiskImageFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(artifactData))
+	})
+
+	It("should honor the digest pinning an artifact", func() {
+		blobs := ociBlobs{}
+		manifest := artifactManifestBlob(blobs, "application/vnd.oci.image.manifest.v1+json", defaultArtifactMediaType, artifactData)
+		registry := newArtifactRegistry(blobs, manifest, "application/vnd.oci.image.manifest.v1+json")
+		defer registry.Close()
+		image := "docker://" + registry.Listener.Addr().String() + "/images/disk"
+
+		ds = NewRegistryDataSource(fmt.Sprintf("%s@sha256:%x", image, sha256.Sum256(manifest)), "", "", "", true)
+		_, err := ds.Transfer(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := os.ReadFile(filepath.Join(tmpDir, containerDiskImageFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(artifactData))
+
+		By("Rejecting the manifest of another digest")
+		Expect(os.Remove(filepath.Join(tmpDir, containerDiskImageFile))).To(Succeed())
+		ds = NewRegistryDataSource(fmt.Sprintf("%s@sha256:%x", image, sha256.Sum256([]byte("another manifest"))), "", "", "", true)
+		_, err = ds.Transfer(tmpDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Manifest does not match provided manifest digest"))
+		Expect(filepath.Join(tmpDir, containerDiskImageFile)).NotTo(BeAnExistingFile())
+	})
+
+	It("should fail when the blob of an artifact does not match its digest", func() {
+		blobs := ociBlobs{}
+		manifest := artifactManifestBlob(blobs, "application/vnd.oci.image.manifest.v1+json", defaultArtifactMediaType, artifactData)
+		registry := newArtifactRegistry(blobs, manifest, "application/vnd.oci.image.manifest.v1+json")
+		defer registry.Close()
+		corrupted := append([]byte{}, artifactData...)
+		corrupted[len(corrupted)-1]++
+		blobs[fmt.Sprintf("%x", sha256.Sum256(artifactData))] = corrupted
+
+		ds = NewRegistryDataSource("docker://"+registry.Listener.Addr().String()+"/images/disk:v1", "", "", "", true)
+		_, err := ds.Transfer(tmpDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("does not match its digest"))
+		Expect(filepath.Join(tmpDir, containerDiskImageFile)).NotTo(BeAnExistingFile())
+	})
+
+	It("should authenticate with the docker config of the secret, unless it holds an access key", func() {
+		authFile := filepath.Join(tmpDir, common.ImporterRegistryAuthFileName)
+		Expect(os.WriteFile(authFile, []byte(`{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNzd29yZA=="}}}`), 0600)).To(Succeed())
+		os.Setenv(common.ImporterRegistryAuthFile, authFile)
+		defer os.Unsetenv(common.ImporterRegistryAuthFile)
+		Expect(buildSourceContext("", "", "", false).AuthFilePath).To(Equal(authFile))
+		ctx := buildSourceContext("user", "password", "", false)
+		Expect(ctx.AuthFilePath).To(BeEmpty())
+		Expect(ctx.DockerAuthConfig.Username).To(Equal("user"))
+
+		By("Ignoring the docker config missing from the secret")
+		Expect(os.Remove(authFile)).To(Succeed())
+		Expect(buildSourceContext("", "", "", false).AuthFilePath).To(BeEmpty())
+	})
+
 	It("TransferFile should not be called", func() {
 		ds = NewRegistryDataSource("", "", "", "", true)
 		result, err := ds.TransferFile("file")
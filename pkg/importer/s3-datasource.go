@@ -1,30 +1,87 @@
 package importer
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 
 	"github.com/pkg/errors"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/util"
+	prometheusutil "kubevirt.io/containerized-data-importer/pkg/util/prometheus"
 )
 
 const (
 	s3FolderSep = "/"
 	httpScheme  = "http"
+	httpsScheme = "https"
+	// s3Scheme is the scheme of the s3://bucket/key URLs, whose endpoint is passed in S3Options
+	s3Scheme = "s3"
+	// defaultS3Region is the region of the buckets of an endpoint that is not in a region of AWS
+	defaultS3Region = "us-east-1"
+	// s3DownloadFile is the file of scratch space the parts of an object are downloaded to
+	s3DownloadFile = "s3object"
 )
 
+const (
+	// defaultS3PartSize is the size of the parts of an object downloaded in parallel unless overridden
+	// with the IMPORTER_S3_PART_SIZE environment variable.
+	defaultS3PartSize = 64 << 20
+	// defaultS3Concurrency is the number of parts of an object downloaded at once unless overridden
+	// with the IMPORTER_S3_CONCURRENCY environment variable, the object is streamed by default.
+	defaultS3Concurrency = 1
+	// s3PartRetries is the number of times the download of a part is retried before the import fails
+	s3PartRetries = 3
+	// s3PartBufferSize is the size of the buffer copying the body of a part to the downloaded object
+	s3PartBufferSize = 1 << 20
+)
+
+// s3PartRetryInterval is the delay before the first retry of the download of a part, raised on each
+// retry, may be overridden in tests
+var s3PartRetryInterval = 2 * time.Second
+
+const (
+	// S3PathStyle addresses the bucket of an s3:// URL in the path of the requests, https://endpoint/bucket/key
+	S3PathStyle = "path"
+	// S3VirtualHostedStyle addresses the bucket of an s3:// URL in the host name, https://bucket.endpoint/key
+	S3VirtualHostedStyle = "virtual"
+)
+
+// S3Options are the options of the S3 service of an s3://bucket/key URL. An http(s) URL names the
+// endpoint, with the bucket in its path, only the region applies to it.
+type S3Options struct {
+	// Endpoint is the URL, or the host and port, of the S3 service: Ceph RGW or MinIO for instance.
+	// The endpoint of AWS in the region is used when it is empty.
+	Endpoint string
+	// Region is the region of the bucket, read from an endpoint of AWS when it is empty
+	Region string
+	// AddressingStyle is S3PathStyle or S3VirtualHostedStyle, the default is path-style for an
+	// endpoint passed in and virtual-hosted for AWS
+	AddressingStyle string
+}
+
 // S3Client is the interface to the used S3 client.
 type S3Client interface {
 	GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error)
@@ -35,7 +92,10 @@ var newClientFunc = getS3Client
 
 // S3DataSource is the struct containing the information needed to import from an S3 data source.
 // Sequence of phases:
-// 1. Info -> Transfer
+// 1a. Info -> Convert, if the object can be converted by qemu-img from nbdkit, see nbdkitStream
+// 1b. Info -> TransferDataFile, if the object is a raw image
+// 1c. Info -> Transfer in all other cases, the parts of the object being downloaded in parallel when
+// the concurrency is above 1, see transferParts
 // 2. Transfer -> Convert
 type S3DataSource struct {
 	// S3 end point
@@ -46,34 +106,101 @@ type S3DataSource struct {
 	secKey string
 	// Reader
 	s3Reader io.ReadCloser
-	// stack of readers
-	readers *FormatReaders
+	// The object, read at random by nbdkit
+	object *s3Object
+	// The size of the object, 0 if unknown
+	contentLength uint64
+	// stack of readers, created by Info
+	sourceReaders
 	// The image file in scratch space.
 	url *url.URL
+	// the readers stop once ctx is done
+	ctx context.Context
+	// serves the object to nbdkit
+	server *http.Server
+	n      image.NbdkitOperation
+	// the size of the parts of the object downloaded in parallel, and the number of parts downloaded at once
+	partSize    int64
+	concurrency int
+	// passed on to the readers of the downloaded object
+	maxDecompressedSize int64
+	progressMax         float64
 }
 
-// NewS3DataSource creates a new instance of the S3DataSource
-func NewS3DataSource(endpoint, accessKey, secKey string, certDir string) (*S3DataSource, error) {
+// NewS3DataSource creates a new instance of the S3DataSource, reading the object of an
+// http(s)://endpoint/bucket/key or s3://bucket/key URL. The CA certificates of the endpoint are
+// read from certDir, unless it is empty.
+func NewS3DataSource(endpoint, accessKey, secKey string, certDir string, options S3Options) (*S3DataSource, error) {
 	ep, err := ParseEndpoint(endpoint)
 	if err != nil {
 		return nil, errors.Wrapf(err, fmt.Sprintf("unable to parse endpoint %q", endpoint))
 	}
-	s3Reader, err := createS3Reader(ep, accessKey, secKey, certDir)
+	partSize, err := s3PartSize()
+	if err != nil {
+		return nil, err
+	}
+	concurrency, err := s3Concurrency()
+	if err != nil {
+		return nil, err
+	}
+	previous, err := PreviousSourceValidators()
 	if err != nil {
 		return nil, err
 	}
+	object, s3Reader, contentLength, err := createS3Reader(ep, accessKey, secKey, certDir, options, previous.ETag)
+	if err != nil {
+		return nil, err
+	}
+	if previous.ETag != "" && previous.Matches(SourceValidators{ETag: object.etag}) {
+		// the service ignored the conditional request
+		s3Reader.Close()
+		return nil, ErrSourceUnchanged
+	}
 	return &S3DataSource{
-		ep:        ep,
-		accessKey: accessKey,
-		secKey:    secKey,
-		s3Reader:  s3Reader,
+		ep:            ep,
+		accessKey:     accessKey,
+		secKey:        secKey,
+		s3Reader:      s3Reader,
+		object:        object,
+		contentLength: contentLength,
+		ctx:           context.Background(),
+		partSize:      partSize,
+		concurrency:   concurrency,
 	}, nil
 }
 
+// s3PartSize returns the size of the parts of an object downloaded in parallel, set with the
+// IMPORTER_S3_PART_SIZE environment variable.
+func s3PartSize() (int64, error) {
+	value, _ := util.ParseEnvVar(common.ImporterS3PartSize, false)
+	if value == "" {
+		return defaultS3PartSize, nil
+	}
+	size, err := resource.ParseQuantity(value)
+	if err != nil || size.Sign() <= 0 {
+		return 0, errors.Errorf("invalid %s value %q, a positive quantity is expected", common.ImporterS3PartSize, value)
+	}
+	return size.Value(), nil
+}
+
+// s3Concurrency returns the number of parts of an object downloaded at once, set with the
+// IMPORTER_S3_CONCURRENCY environment variable.
+func s3Concurrency() (int, error) {
+	value, _ := util.ParseEnvVar(common.ImporterS3Concurrency, false)
+	if value == "" {
+		return defaultS3Concurrency, nil
+	}
+	concurrency, err := strconv.Atoi(value)
+	if err != nil || concurrency < 1 {
+		return 0, errors.Errorf("invalid %s value %q, a positive number is expected", common.ImporterS3Concurrency, value)
+	}
+	return concurrency, nil
+}
+
 // Info is called to get initial information about the data.
 func (sd *S3DataSource) Info() (ProcessingPhase, error) {
 	var err error
-	sd.readers, err = NewFormatReaders(sd.s3Reader, uint64(0))
+	sd.readers, err = newFormatReaders(sd.ctx, sd.s3Reader, sd.contentLength, sd.ep.Path)
 	if err != nil {
 		klog.Errorf("Error creating readers: %v", err)
 		return ProcessingPhaseError, err
@@ -82,8 +209,73 @@ func (sd *S3DataSource) Info() (ProcessingPhase, error) {
 		// Downloading a raw file, we can write that directly to the target.
 		return ProcessingPhaseTransferDataFile, nil
 	}
+	// nbdkit serves the object to qemu-img, decompressing it if needed, unless it has to be copied
+	// to scratch space first. nbdkit needs the size of the object, and would read it regardless of
+	// the bandwidth limit and of the checksum.
+	if sd.contentLength == 0 || sd.parallel() || sd.readers.mustStream() {
+		return ProcessingPhaseTransferScratch, nil
+	}
+	filter, ok := sd.readers.nbdkitStream(sd.object, int64(sd.contentLength))
+	if !ok {
+		return ProcessingPhaseTransferScratch, nil
+	}
+	if err = sd.startNbdkit(filter); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseConvert, nil
+}
+
+// startNbdkit serves the object over http on the loopback interface, for the curl plugin of nbdkit
+// to read it with the credentials of the S3 client.
+func (sd *S3DataSource) startNbdkit(filter image.NbdkitFilter) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return errors.Wrap(err, "could not listen for the requests of nbdkit")
+	}
+	sd.server = &http.Server{Handler: sd.object}
+	go func() {
+		if err := sd.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			klog.Errorf("Error serving the s3 object to nbdkit: %v", err)
+		}
+	}()
+	sd.n = createNbdkitCurl(nbdkitPid, "", "", "", nbdkitSocket, nil, nil)
+	if filter != "" {
+		sd.n.AddFilter(filter)
+	}
+	endpoint := &url.URL{Scheme: httpScheme, Host: listener.Addr().String(), Path: "/" + sd.object.key}
+	if err = sd.n.StartNbdkit(endpoint.String()); err != nil {
+		return err
+	}
+	sd.url, _ = url.Parse(fmt.Sprintf("nbd+unix:///?socket=%s", nbdkitSocket))
+	return nil
+}
+
+// parallel returns true if the object is downloaded in parts, there is more than one part and they
+// are downloaded concurrently. A limited bandwidth is shared by a single stream.
+func (sd *S3DataSource) parallel() bool {
+	return sd.concurrency > 1 && sd.contentLength > uint64(sd.partSize) && sd.readers.bandwidthLimit == 0
+}
+
+// SetMaxDecompressedSize limits the size of the decompressed data.
+func (sd *S3DataSource) SetMaxDecompressedSize(max int64) {
+	sd.maxDecompressedSize = max
+	sd.sourceReaders.SetMaxDecompressedSize(max)
+}
 
-	return ProcessingPhaseTransferScratch, nil
+// SetProgressMax sets the progress reported once the data is transferred.
+func (sd *S3DataSource) SetProgressMax(max float64) {
+	sd.progressMax = max
+	sd.sourceReaders.SetProgressMax(max)
+}
+
+// SourceValidators returns the ETag of the object.
+func (sd *S3DataSource) SourceValidators() SourceValidators {
+	return SourceValidators{ETag: sd.object.etag}
+}
+
+// SetContext stops the transfer once ctx is done.
+func (sd *S3DataSource) SetContext(ctx context.Context) {
+	sd.ctx = ctx
 }
 
 // Transfer is called to transfer the data from the source to a temporary location.
@@ -94,7 +286,13 @@ func (sd *S3DataSource) Transfer(path string) (ProcessingPhase, error) {
 		return ProcessingPhaseError, ErrInvalidPath
 	}
 	file := filepath.Join(path, tempFile)
-	err := util.StreamDataToFile(sd.readers.TopReader(), file)
+	var err error
+	if sd.parallel() {
+		err = sd.transferParts(path, file)
+	} else {
+		sd.readers.StartProgressUpdate()
+		err = sd.readers.StreamToFile(file)
+	}
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
@@ -103,9 +301,51 @@ func (sd *S3DataSource) Transfer(path string) (ProcessingPhase, error) {
 	return ProcessingPhaseConvert, nil
 }
 
+// transferParts downloads the parts of the object to scratch space, concurrently, then unpacks the
+// downloaded object to file. The readers of the stream opened by NewS3DataSource are replaced by
+// readers of the downloaded object, which decompress and extract it. The progress of the download
+// is the progress of the transfer.
+func (sd *S3DataSource) transferParts(path, file string) error {
+	if err := sd.readers.Close(); err != nil {
+		klog.Warningf("Error closing the stream of the s3 object: %v", err)
+	}
+	downloadFile := filepath.Join(path, s3DownloadFile)
+	defer os.Remove(downloadFile)
+	klog.V(1).Infof("downloading %d bytes in parts of %d bytes, %d at once", sd.contentLength, sd.partSize, sd.concurrency)
+	if err := sd.object.download(sd.ctx, downloadFile, sd.partSize, sd.concurrency, sd.updateDownloadProgress); err != nil {
+		return err
+	}
+	f, err := os.Open(downloadFile)
+	if err != nil {
+		return errors.Wrap(err, "could not open the downloaded s3 object")
+	}
+	readers, err := newFormatReaders(sd.ctx, f, 0, sd.ep.Path)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	sd.readers = readers
+	sd.readers.SetMaxDecompressedSize(sd.maxDecompressedSize)
+	sd.readers.SetProgressMax(sd.progressMax)
+	return sd.readers.StreamToFile(file)
+}
+
+// updateDownloadProgress reports the progress of the download of the parts of the object.
+func (sd *S3DataSource) updateDownloadProgress(downloaded uint64) {
+	max := sd.progressMax
+	if max == 0 {
+		max = 100.0
+	}
+	currentProgress := float64(downloaded) / float64(sd.contentLength) * max
+	prometheusutil.SetProgress(progress, ownerUID, currentProgress)
+	prometheusutil.SetBytes(transferredBytes, ownerUID, downloaded)
+	klog.V(1).Infof("%.2f, %d bytes downloaded", currentProgress, downloaded)
+}
+
 // TransferFile is called to transfer the data from the source to the passed in file.
 func (sd *S3DataSource) TransferFile(fileName string) (ProcessingPhase, error) {
-	err := util.StreamDataToFile(sd.readers.TopReader(), fileName)
+	sd.readers.StartProgressUpdate()
+	err := sd.readers.StreamToFile(fileName)
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
@@ -123,38 +363,326 @@ func (sd *S3DataSource) Close() error {
 	if sd.readers != nil {
 		err = sd.readers.Close()
 	}
+	if sd.n != nil {
+		if killErr := sd.n.KillNbdkit(); killErr != nil {
+			err = killErr
+		}
+	}
+	if sd.server != nil {
+		sd.server.Close()
+	}
 	return err
 }
 
-func createS3Reader(ep *url.URL, accessKey, secKey string, certDir string) (io.ReadCloser, error) {
+// s3Object reads an object of a bucket.
+type s3Object struct {
+	svc    S3Client
+	bucket string
+	key    string
+	size   int64
+	// the entity tag of the object, quoted
+	etag string
+}
+
+// ReadAt reads len(p) bytes of the object starting at off.
+func (o *s3Object) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	body, _, _, err := o.get(fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	n, err := io.ReadFull(body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// download writes the object to fileName, downloading parts of partSize bytes with concurrency
+// requests at once. A part whose request fails is downloaded again, up to s3PartRetries times, the
+// other parts are kept. report is called every second with the number of bytes of the parts
+// downloaded, and once they all are.
+func (o *s3Object) download(ctx context.Context, fileName string, partSize int64, concurrency int, report func(uint64)) error {
+	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not create the file of the s3 object")
+	}
+	defer f.Close()
+	if err := f.Truncate(o.size); err != nil {
+		return errors.Wrap(err, "could not size the file of the s3 object")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var downloaded uint64
+	parts := make(chan int64)
+	errs := make(chan error, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for off := range parts {
+				n, err := o.downloadPart(ctx, f, off, partSize)
+				if err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+				atomic.AddUint64(&downloaded, uint64(n))
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report(atomic.LoadUint64(&downloaded))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+feed:
+	for off := int64(0); off < o.size; off += partSize {
+		select {
+		case parts <- off:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(parts)
+	wg.Wait()
+	close(done)
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	report(atomic.LoadUint64(&downloaded))
+	return f.Close()
+}
+
+// partWriteError is an error writing a part to the file of the object, which is not retried.
+type partWriteError struct {
+	error
+}
+
+// downloadPart writes the part of the object at off, of partSize bytes or up to the end of the
+// object, to f. The part is downloaded again when its request or the read of its body fails.
+func (o *s3Object) downloadPart(ctx context.Context, f io.WriterAt, off, partSize int64) (int64, error) {
+	end := off + partSize
+	if end > o.size {
+		end = o.size
+	}
+	var err error
+	for attempt := 0; attempt <= s3PartRetries; attempt++ {
+		if attempt > 0 {
+			klog.Warningf("Retrying the download of bytes %d-%d of the s3 object: %v", off, end-1, err)
+			select {
+			case <-time.After(s3PartRetryInterval * time.Duration(attempt)):
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+		if err = o.writePart(ctx, f, off, end); err == nil {
+			return end - off, nil
+		}
+		if writeErr, ok := err.(partWriteError); ok {
+			return 0, writeErr.error
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+	}
+	return 0, errors.Wrapf(err, "could not download bytes %d-%d of s3 object \"%s/%s\" after %d retries", off, end-1, o.bucket, o.key, s3PartRetries)
+}
+
+// writePart copies the bytes from off to end of the object to f, at the same offset.
+func (o *s3Object) writePart(ctx context.Context, f io.WriterAt, off, end int64) error {
+	body, _, _, err := o.get(fmt.Sprintf("bytes=%d-%d", off, end-1))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	buf := make([]byte, s3PartBufferSize)
+	for off < end {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		size := int64(len(buf))
+		if end-off < size {
+			size = end - off
+		}
+		n, err := io.ReadFull(body, buf[:size])
+		if n > 0 {
+			if _, writeErr := f.WriteAt(buf[:n], off); writeErr != nil {
+				return partWriteError{errors.Wrap(writeErr, "could not write the s3 object")}
+			}
+			off += int64(n)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not read bytes %d-%d of the s3 object", off, end-1)
+		}
+	}
+	return nil
+}
+
+// get returns the body of a request of the object, of the passed in range unless it is empty, along
+// with its size and the range of the object it holds.
+func (o *s3Object) get(byteRange string) (io.ReadCloser, int64, string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(o.bucket),
+		Key:    aws.String(o.key),
+	}
+	if byteRange != "" {
+		input.Range = aws.String(byteRange)
+	}
+	output, err := o.svc.GetObject(input)
+	if err != nil {
+		return nil, 0, "", errors.Wrapf(err, "could not get s3 object: \"%s/%s\"", o.bucket, o.key)
+	}
+	return output.Body, aws.Int64Value(output.ContentLength), aws.StringValue(output.ContentRange), nil
+}
+
+// ServeHTTP serves the object to the curl plugin of nbdkit, which finds its size with a HEAD request
+// then reads it with range requests.
+func (o *s3Object) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Accept-Ranges", "bytes")
+	switch r.Method {
+	case http.MethodHead:
+		w.Header().Set("Content-Length", fmt.Sprint(o.size))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		byteRange := r.Header.Get("Range")
+		body, length, contentRange, err := o.get(byteRange)
+		if err != nil {
+			klog.Errorf("Error reading the s3 object for nbdkit: %v", err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer body.Close()
+		w.Header().Set("Content-Length", fmt.Sprint(length))
+		if byteRange != "" {
+			w.Header().Set("Content-Range", contentRange)
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		if _, err := io.Copy(w, body); err != nil {
+			klog.Errorf("Error serving the s3 object to nbdkit: %v", err)
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// createS3Reader returns the object of the URL and its reader. The object is not read, failing with
+// ErrSourceUnchanged, if its ETag is ifNoneMatch.
+func createS3Reader(ep *url.URL, accessKey, secKey string, certDir string, options S3Options, ifNoneMatch string) (*s3Object, io.ReadCloser, uint64, error) {
 	klog.V(3).Infoln("Using S3 client to get data")
 
-	endpoint := ep.Host
-	urlScheme := ep.Scheme
+	var endpoint, urlScheme, region, bucket, object string
+	pathStyle := true
+	if ep.Scheme == s3Scheme {
+		var err error
+		if endpoint, urlScheme, err = parseS3Endpoint(options.Endpoint); err != nil {
+			return nil, nil, uint64(0), err
+		}
+		bucket, object = ep.Host, strings.TrimPrefix(ep.Path, "/")
+		if bucket == "" || object == "" {
+			return nil, nil, uint64(0), errors.Errorf("s3 URL %q does not name a bucket and an object, s3://bucket/key is expected", ep)
+		}
+		switch options.AddressingStyle {
+		case S3PathStyle:
+		case S3VirtualHostedStyle:
+			pathStyle = false
+		case "":
+			pathStyle = endpoint != ""
+		default:
+			return nil, nil, uint64(0), errors.Errorf("invalid s3 addressing style %q, %s or %s is expected", options.AddressingStyle, S3PathStyle, S3VirtualHostedStyle)
+		}
+		region = options.Region
+		if region == "" {
+			region = defaultS3Region
+			if strings.HasSuffix(endpoint, ".amazonaws.com") {
+				region = extractRegion(endpoint)
+			}
+		}
+	} else {
+		endpoint, urlScheme = ep.Host, ep.Scheme
+		bucket, object = extractBucketAndObject(strings.Trim(ep.Path, "/"))
+		region = options.Region
+		if region == "" {
+			region = extractRegion(endpoint)
+		}
+	}
 	klog.Infof("Endpoint %s", endpoint)
-	path := strings.Trim(ep.Path, "/")
-	bucket, object := extractBucketAndObject(path)
 
 	klog.V(1).Infof("bucket %s", bucket)
 	klog.V(1).Infof("object %s", object)
-	svc, err := newClientFunc(endpoint, accessKey, secKey, certDir, urlScheme)
+	klog.V(1).Infof("region %s, path-style %t", region, pathStyle)
+	svc, err := newClientFunc(endpoint, region, accessKey, secKey, certDir, urlScheme, pathStyle)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not build s3 client for %q", ep.Host)
+		return nil, nil, uint64(0), errors.Wrapf(err, "could not build s3 client for %q", endpoint)
 	}
 
 	objInput := &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(object),
 	}
+	if ifNoneMatch != "" {
+		objInput.IfNoneMatch = aws.String(ifNoneMatch)
+	}
 	objOutput, err := svc.GetObject(objInput)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not get s3 object: \"%s/%s\"", bucket, object)
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotModified {
+			return nil, nil, uint64(0), ErrSourceUnchanged
+		}
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+			return nil, nil, uint64(0), fmt.Errorf("%w: could not get s3 object: \"%s/%s\": %v", ErrSourceNotFound, bucket, object, err)
+		}
+		return nil, nil, uint64(0), errors.Wrapf(err, "could not get s3 object: \"%s/%s\"", bucket, object)
 	}
 	objectReader := objOutput.Body
-	return objectReader, nil
+	contentLength := uint64(0)
+	if size := aws.Int64Value(objOutput.ContentLength); size > 0 {
+		contentLength = uint64(size)
+		klog.V(3).Infof("Content length: %d\n", contentLength)
+	}
+	return &s3Object{svc: svc, bucket: bucket, key: object, size: int64(contentLength), etag: aws.StringValue(objOutput.ETag)}, objectReader, contentLength, nil
+}
+
+// parseS3Endpoint returns the host and port, and the scheme, of the endpoint of an s3:// URL, an
+// https one when it has no scheme. The host is empty for the endpoint of AWS.
+func parseS3Endpoint(endpoint string) (string, string, error) {
+	if endpoint == "" {
+		return "", httpsScheme, nil
+	}
+	raw := endpoint
+	if !strings.Contains(endpoint, "://") {
+		raw = httpsScheme + "://" + endpoint
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != httpScheme && u.Scheme != httpsScheme) {
+		return "", "", errors.Errorf("invalid s3 endpoint %q, an http(s) URL or a host is expected", endpoint)
+	}
+	return u.Host, u.Scheme, nil
 }
 
-func getS3Client(endpoint, accessKey, secKey string, certDir string, urlScheme string) (S3Client, error) {
+func getS3Client(endpoint, region, accessKey, secKey string, certDir string, urlScheme string, pathStyle bool) (S3Client, error) {
 	// Adding certs using CustomCABundle will overwrite the SystemCerts, so we opt by creating a custom HTTPClient
 	httpClient, err := createHTTPClient(certDir)
 
@@ -163,7 +691,6 @@ func getS3Client(endpoint, accessKey, secKey string, certDir string, urlScheme s
 	}
 
 	creds := credentials.NewStaticCredentials(accessKey, secKey, "")
-	region := extractRegion(endpoint)
 	disableSSL := false
 	// Disable SSL for http endpoint. This should cause the s3 client to create http requests.
 	if urlScheme == httpScheme {
@@ -174,7 +701,7 @@ func getS3Client(endpoint, accessKey, secKey string, certDir string, urlScheme s
 		Region:           aws.String(region),
 		Endpoint:         aws.String(endpoint),
 		Credentials:      creds,
-		S3ForcePathStyle: aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(pathStyle),
 		HTTPClient:       httpClient,
 		DisableSSL:       &disableSSL,
 	},
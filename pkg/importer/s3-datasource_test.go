@@ -1,11 +1,20 @@
 package importer
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
 
 	. "github.com/onsi/ginkgo"
@@ -13,6 +22,10 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/pkg/errors"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/tests/utils"
 )
 
 var _ = Describe("S3 data source", func() {
@@ -24,6 +37,7 @@ var _ = Describe("S3 data source", func() {
 
 	BeforeEach(func() {
 		newClientFunc = createMockS3Client
+		createNbdkitCurl = image.NewMockNbdkitCurl
 		tmpDir, err = os.MkdirTemp("", "scratch")
 		Expect(err).NotTo(HaveOccurred())
 		By("tmpDir: " + tmpDir)
@@ -38,25 +52,41 @@ var _ = Describe("S3 data source", func() {
 	})
 
 	It("NewS3DataSource should Error, when passed in an invalid endpoint", func() {
-		sd, err = NewS3DataSource("thisisinvalid#$%#ep", "", "", "")
+		sd, err = NewS3DataSource("thisisinvalid#$%#ep", "", "", "", S3Options{})
 		Expect(err).To(HaveOccurred())
 	})
 
 	It("NewS3DataSource should Error, when failing to create S3 client", func() {
 		newClientFunc = failMockS3Client
-		sd, err = NewS3DataSource("http://amazon.com", "", "", "")
+		sd, err = NewS3DataSource("http://amazon.com", "", "", "", S3Options{})
 		Expect(err).To(HaveOccurred())
 	})
 
 	It("NewS3DataSource should Error, when failing to get object", func() {
 		newClientFunc = createErrMockS3Client
-		sd, err = NewS3DataSource("http://amazon.com", "", "", "")
+		sd, err = NewS3DataSource("http://amazon.com", "", "", "", S3Options{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("NewS3DataSource should fail terminally, when the object does not exist", func() {
+		newClientFunc = func(endpoint, region, accKey, secKey string, certDir string, urlScheme string, pathStyle bool) (S3Client, error) {
+			return &notFoundMockS3Client{}, nil
+		}
+		sd, err = NewS3DataSource("http://amazon.com/bucket-1/missing", "", "", "", S3Options{})
 		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrSourceNotFound)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`could not get s3 object: "bucket-1/missing"`))
+	})
+
+	It("NewS3DataSource should keep the size of the object", func() {
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sd.contentLength).To(Equal(uint64(mockS3ObjectSize)))
 	})
 
 	It("NewS3DataSource should fail when called with an invalid certdir", func() {
 		newClientFunc = getS3Client
-		sd, err = NewS3DataSource("http://amazon.com", "", "", "/invaliddir")
+		sd, err = NewS3DataSource("http://amazon.com", "", "", "/invaliddir", S3Options{})
 		Expect(err).To(HaveOccurred())
 	})
 
@@ -66,7 +96,7 @@ var _ = Describe("S3 data source", func() {
 		Expect(err).NotTo(HaveOccurred())
 		err = file.Close()
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
 		Expect(err).NotTo(HaveOccurred())
 		sd.s3Reader = file
 		result, err := sd.Info()
@@ -74,11 +104,37 @@ var _ = Describe("S3 data source", func() {
 		Expect(ProcessingPhaseError).To(Equal(result))
 	})
 
-	It("Info should return Transfer, when passed in a valid image", func() {
+	It("Info should return Convert, when passed in a valid qcow2 image", func() {
+		// Don't need to defer close, since ud.Close will close the reader
+		file, err := os.Open(cirrosFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
+		Expect(err).NotTo(HaveOccurred())
+		sd.s3Reader = file
+		result, err := sd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ProcessingPhaseConvert).To(Equal(result))
+		Expect(sd.GetURL().String()).To(Equal("nbd+unix:///?socket=/tmp/nbdkit.sock"))
+	})
+
+	It("Info should return TransferScratch, when the size of a qcow2 object is unknown", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(cirrosFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
+		Expect(err).NotTo(HaveOccurred())
+		sd.s3Reader = file
+		sd.contentLength = 0
+		result, err := sd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ProcessingPhaseTransferScratch).To(Equal(result))
+	})
+
+	It("Info should return TransferScratch, when passed in an image converted on scratch space", func() {
+		// Don't need to defer close, since ud.Close will close the reader
+		file, err := os.Open(tinyCoreVdiFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
 		Expect(err).NotTo(HaveOccurred())
 		sd.s3Reader = file
 		result, err := sd.Info()
@@ -90,7 +146,7 @@ var _ = Describe("S3 data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
 		Expect(err).NotTo(HaveOccurred())
 		sd.s3Reader = file
 		result, err := sd.Info()
@@ -105,7 +161,7 @@ var _ = Describe("S3 data source", func() {
 		sourceFile, err := os.Open(fileName)
 		Expect(err).NotTo(HaveOccurred())
 
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = sourceFile
@@ -131,15 +187,15 @@ var _ = Describe("S3 data source", func() {
 			Expect(ProcessingPhaseError).To(Equal(result))
 		}
 	},
-		table.Entry("return Error with missing scratch space", cirrosFilePath, "/imaninvalidpath", nil, true),
-		table.Entry("return Convert with scratch space and valid qcow file", cirrosFilePath, "", cirrosData, false),
+		table.Entry("return Error with missing scratch space", tinyCoreVdiFilePath, "/imaninvalidpath", nil, true),
+		table.Entry("return Convert with scratch space and valid vdi file", tinyCoreVdiFilePath, "", tinyCoreVdiData, false),
 	)
 
 	It("Transfer should fail on reader error", func() {
-		sourceFile, err := os.Open(cirrosFilePath)
+		sourceFile, err := os.Open(tinyCoreVdiFilePath)
 		Expect(err).NotTo(HaveOccurred())
 
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = sourceFile
@@ -157,7 +213,7 @@ var _ = Describe("S3 data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = file
@@ -173,7 +229,7 @@ var _ = Describe("S3 data source", func() {
 		// Don't need to defer close, since ud.Close will close the reader
 		file, err := os.Open(tinyCoreFilePath)
 		Expect(err).NotTo(HaveOccurred())
-		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "")
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
 		Expect(err).NotTo(HaveOccurred())
 		// Replace minio.Object with a reader we can use.
 		sd.s3Reader = file
@@ -185,8 +241,156 @@ var _ = Describe("S3 data source", func() {
 		Expect(ProcessingPhaseError).To(Equal(result))
 	})
 
+	It("should serve an object to nbdkit", func() {
+		data := bytes.Repeat([]byte("s3 object data "), 100)
+		object := &s3Object{svc: &rangeMockS3Client{data: data}, bucket: "bucket-1", key: "object-1", size: int64(len(data))}
+		server := httptest.NewServer(object)
+		defer server.Close()
+
+		By("Answering a HEAD request with the size of the object")
+		resp, err := http.Head(server.URL + "/object-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.ContentLength).To(Equal(int64(len(data))))
+		Expect(resp.Header.Get("Accept-Ranges")).To(Equal("bytes"))
+
+		By("Answering a range request with the range of the object")
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/object-1", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Range", "bytes=10-29")
+		resp, err = http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusPartialContent))
+		Expect(resp.Header.Get("Content-Range")).To(Equal(fmt.Sprintf("bytes 10-29/%d", len(data))))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal(data[10:30]))
+	})
+
+	It("should read an object at random", func() {
+		data := bytes.Repeat([]byte("s3 object data "), 100)
+		object := &s3Object{svc: &rangeMockS3Client{data: data}, bucket: "bucket-1", key: "object-1", size: int64(len(data))}
+		p := make([]byte, 20)
+		n, err := object.ReadAt(p, 100)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p[:n]).To(Equal(data[100:120]))
+		n, err = object.ReadAt(p, int64(len(data))-10)
+		Expect(err).To(Equal(io.EOF))
+		Expect(p[:n]).To(Equal(data[len(data)-10:]))
+	})
+
+	It("should download an object in parts written at their offsets", func() {
+		data := bytes.Repeat([]byte("s3 object data "), 100)
+		object := &s3Object{svc: &rangeMockS3Client{data: data}, bucket: "bucket-1", key: "object-1", size: int64(len(data))}
+		var reported uint64
+		fileName := filepath.Join(tmpDir, s3DownloadFile)
+		err := object.download(context.Background(), fileName, 100, 4, func(downloaded uint64) { reported = downloaded })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reported).To(Equal(uint64(len(data))))
+		downloaded, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(downloaded).To(Equal(data))
+	})
+
+	It("should retry the download of the part that failed only", func() {
+		defer func(interval time.Duration) { s3PartRetryInterval = interval }(s3PartRetryInterval)
+		s3PartRetryInterval = time.Millisecond
+		data := bytes.Repeat([]byte("s3 object data "), 100)
+		client := &rangeMockS3Client{data: data, failures: map[string]int{"bytes=300-399": s3PartRetries}}
+		object := &s3Object{svc: client, bucket: "bucket-1", key: "object-1", size: int64(len(data))}
+		fileName := filepath.Join(tmpDir, s3DownloadFile)
+		err := object.download(context.Background(), fileName, 100, 4, func(uint64) {})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.requests["bytes=300-399"]).To(Equal(s3PartRetries + 1))
+		Expect(client.requests["bytes=200-299"]).To(Equal(1))
+		Expect(client.requests["bytes=1400-1499"]).To(Equal(1))
+		downloaded, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(downloaded).To(Equal(data))
+	})
+
+	It("should fail the download once a part failed on every retry", func() {
+		defer func(interval time.Duration) { s3PartRetryInterval = interval }(s3PartRetryInterval)
+		s3PartRetryInterval = time.Millisecond
+		data := bytes.Repeat([]byte("s3 object data "), 100)
+		client := &rangeMockS3Client{data: data, failures: map[string]int{"bytes=300-399": s3PartRetries + 1}}
+		object := &s3Object{svc: client, bucket: "bucket-1", key: "object-1", size: int64(len(data))}
+		err := object.download(context.Background(), filepath.Join(tmpDir, s3DownloadFile), 100, 4, func(uint64) {})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("could not download bytes 300-399"))
+	})
+
+	table.DescribeTable("Transfer should download the parts of an object and unpack it", func(fileName string, want []byte) {
+		os.Setenv(common.ImporterS3PartSize, "1Mi")
+		os.Setenv(common.ImporterS3Concurrency, "4")
+		defer os.Unsetenv(common.ImporterS3PartSize)
+		defer os.Unsetenv(common.ImporterS3Concurrency)
+		data, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		sourceFile, err := os.Open(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		sd, err = NewS3DataSource("http://region.amazon.com/bucket-1/object-1", "", "", "", S3Options{})
+		Expect(err).NotTo(HaveOccurred())
+		sd.s3Reader = sourceFile
+		sd.object = &s3Object{svc: &rangeMockS3Client{data: data}, bucket: "bucket-1", key: "object-1", size: int64(len(data))}
+		sd.contentLength = uint64(len(data))
+		nextPhase, err := sd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nextPhase).To(Equal(ProcessingPhaseTransferScratch))
+		nextPhase, err = sd.Transfer(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nextPhase).To(Equal(ProcessingPhaseConvert))
+		transferred, err := os.ReadFile(filepath.Join(tmpDir, tempFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes.Equal(transferred, want)).To(BeTrue())
+		Expect(filepath.Join(tmpDir, s3DownloadFile)).ToNot(BeAnExistingFile())
+		Expect(sd.Digests().Source).ToNot(BeEmpty())
+	},
+		table.Entry("of a qcow2 image otherwise served to nbdkit", cirrosFilePath, cirrosData),
+		table.Entry("of a gzip compressed qcow2 image", cirrosGzFilePath, cirrosData),
+	)
+
+	table.DescribeTable("NewS3DataSource should read the object of an s3:// URL", func(options S3Options, endpoint, region, urlScheme string, pathStyle bool) {
+		sd, err = NewS3DataSource("s3://bucket-1/images/object-1", "", "", "", options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sd.object.bucket).To(Equal("bucket-1"))
+		Expect(sd.object.key).To(Equal("images/object-1"))
+		client := sd.object.svc.(*MockS3Client)
+		Expect(client.endpoint).To(Equal(endpoint))
+		Expect(client.region).To(Equal(region))
+		Expect(client.urlScheme).To(Equal(urlScheme))
+		Expect(client.pathStyle).To(Equal(pathStyle))
+	},
+		table.Entry("from AWS", S3Options{Region: "eu-west-1"}, "", "eu-west-1", "https", false),
+		table.Entry("from AWS in the default region", S3Options{}, "", "us-east-1", "https", false),
+		table.Entry("from an endpoint of AWS", S3Options{Endpoint: "s3.eu-west-1.amazonaws.com", AddressingStyle: S3VirtualHostedStyle}, "s3.eu-west-1.amazonaws.com", "eu-west-1", "https", false),
+		table.Entry("from MinIO", S3Options{Endpoint: "http://minio.example.com:9000"}, "minio.example.com:9000", "us-east-1", "http", true),
+		table.Entry("from Ceph RGW with virtual-hosted addressing", S3Options{Endpoint: "https://rgw.example.com", Region: "default", AddressingStyle: S3VirtualHostedStyle},
+			"rgw.example.com", "default", "https", false),
+		table.Entry("from AWS with path-style addressing", S3Options{AddressingStyle: S3PathStyle}, "", "us-east-1", "https", true),
+	)
+
+	table.DescribeTable("NewS3DataSource should reject", func(url string, options S3Options, expectedErr string) {
+		sd, err = NewS3DataSource(url, "", "", "", options)
+		Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+	},
+		table.Entry("an s3:// URL without key", "s3://bucket-1", S3Options{}, "does not name a bucket and an object"),
+		table.Entry("an invalid endpoint", "s3://bucket-1/object-1", S3Options{Endpoint: "ftp://minio.example.com"}, "invalid s3 endpoint"),
+		table.Entry("an unknown addressing style", "s3://bucket-1/object-1", S3Options{AddressingStyle: "dns"}, "invalid s3 addressing style"),
+	)
+
+	It("NewS3DataSource should take the region passed in for an http(s) URL", func() {
+		sd, err = NewS3DataSource("https://s3.example.com/bucket-1/object-1", "", "", "", S3Options{Region: "eu-central-1"})
+		Expect(err).NotTo(HaveOccurred())
+		client := sd.object.svc.(*MockS3Client)
+		Expect(client.endpoint).To(Equal("s3.example.com"))
+		Expect(client.region).To(Equal("eu-central-1"))
+		Expect(client.pathStyle).To(BeTrue())
+	})
+
 	It("GetS3Client should return a real client", func() {
-		_, err := getS3Client("", "", "", "", "")
+		_, err := getS3Client("", "", "", "", "", "", true)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -201,29 +405,42 @@ var _ = Describe("S3 data source", func() {
 	})
 })
 
+var tinyCoreVdiData, _ = readFile(tinyCoreVdiFilePath)
+var cirrosGzFilePath, _ = utils.FormatTestData(cirrosFilePath, os.TempDir(), image.ExtGz)
+
+// mockS3ObjectSize is the size of the objects of MockS3Client
+const mockS3ObjectSize = 1024
+
 // MockS3Client is a mock AWS S3 client
 type MockS3Client struct {
-	endpoint string
-	accKey   string
-	secKey   string
-	certDir  string
-	doErr    bool
+	endpoint  string
+	region    string
+	accKey    string
+	secKey    string
+	certDir   string
+	urlScheme string
+	pathStyle bool
+	doErr     bool
 }
 
-func failMockS3Client(endpoint, accKey, secKey string, certDir string, urlScheme string) (S3Client, error) {
+func failMockS3Client(endpoint, region, accKey, secKey string, certDir string, urlScheme string, pathStyle bool) (S3Client, error) {
 	return nil, errors.New("Failed to create client")
 }
 
-func createMockS3Client(endpoint, accKey, secKey string, certDir string, urlScheme string) (S3Client, error) {
+func createMockS3Client(endpoint, region, accKey, secKey string, certDir string, urlScheme string, pathStyle bool) (S3Client, error) {
 	return &MockS3Client{
-		accKey:  accKey,
-		secKey:  secKey,
-		certDir: certDir,
-		doErr:   false,
+		endpoint:  endpoint,
+		region:    region,
+		accKey:    accKey,
+		secKey:    secKey,
+		certDir:   certDir,
+		urlScheme: urlScheme,
+		pathStyle: pathStyle,
+		doErr:     false,
 	}, nil
 }
 
-func createErrMockS3Client(endpoint, accKey, secKey string, certDir string, urlScheme string) (S3Client, error) {
+func createErrMockS3Client(endpoint, region, accKey, secKey string, certDir string, urlScheme string, pathStyle bool) (S3Client, error) {
 	return &MockS3Client{
 		doErr: true,
 	}, nil
@@ -231,7 +448,53 @@ func createErrMockS3Client(endpoint, accKey, secKey string, certDir string, urlS
 
 func (mc *MockS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
 	if !mc.doErr {
-		return &s3.GetObjectOutput{}, nil
+		return &s3.GetObjectOutput{ContentLength: aws.Int64(mockS3ObjectSize)}, nil
 	}
 	return nil, errors.New("Failed to get object")
 }
+
+// notFoundMockS3Client is a mock AWS S3 client of a bucket holding no object.
+type notFoundMockS3Client struct{}
+
+func (mc *notFoundMockS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	return nil, awserr.NewRequestFailure(awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil), http.StatusNotFound, "request")
+}
+
+// rangeMockS3Client is a mock AWS S3 client serving data, and the ranges of it requested. The
+// requests of the ranges of failures fail as many times.
+type rangeMockS3Client struct {
+	data     []byte
+	failures map[string]int
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (mc *rangeMockS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	if input.Range != nil {
+		mc.mu.Lock()
+		if mc.requests == nil {
+			mc.requests = map[string]int{}
+		}
+		mc.requests[*input.Range]++
+		failed := mc.requests[*input.Range] <= mc.failures[*input.Range]
+		mc.mu.Unlock()
+		if failed {
+			return nil, errors.New("connection reset by peer")
+		}
+	}
+	if input.Range == nil {
+		return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(mc.data)), ContentLength: aws.Int64(int64(len(mc.data)))}, nil
+	}
+	var start, end int
+	if _, err := fmt.Sscanf(*input.Range, "bytes=%d-%d", &start, &end); err != nil {
+		return nil, err
+	}
+	if end >= len(mc.data) {
+		end = len(mc.data) - 1
+	}
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(mc.data[start : end+1])),
+		ContentLength: aws.Int64(int64(end + 1 - start)),
+		ContentRange:  aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, len(mc.data))),
+	}, nil
+}
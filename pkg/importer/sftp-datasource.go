@@ -0,0 +1,428 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+const (
+	// sftpScheme is the scheme of the sftp://user@host/path URLs
+	sftpScheme = "sftp"
+	// sftpDefaultPort is the port of the SSH server when the URL has none
+	sftpDefaultPort = "22"
+	// sftpDialTimeout bounds the connection to the SSH server and its handshake
+	sftpDialTimeout = 30 * time.Second
+	// sftpReadRetries is the number of times a failed read of a file is resumed before the import fails
+	sftpReadRetries = 3
+	// sftpReadBufferSize is the size of the data requested at once, with pipelined reads
+	sftpReadBufferSize = 2 << 20
+)
+
+var (
+	// sftpRetryInterval is the delay before the first resumption of a failed read, raised on each
+	// retry, may be overridden in tests
+	sftpRetryInterval = 2 * time.Second
+)
+
+// SFTPDataSource is the struct containing the information needed to import a file of an SFTP
+// server, named by a sftp://user@host[:port]/path URL. The user authenticates with the password or
+// the private key of the secret, whose known_hosts entries verify the key of the host.
+// Sequence of phases:
+// 1a. Info -> TransferDataFile, if the file is a raw image
+// 1b. Info -> Transfer in all other cases
+// 2. Transfer -> Convert
+type SFTPDataSource struct {
+	// sftp://user@host/path URL
+	ep *url.URL
+	// Reader
+	sftpReader *sftpFileReader
+	// stack of readers, created by Info
+	sourceReaders
+	// The image file in scratch space.
+	url *url.URL
+	// the readers stop once ctx is done
+	ctx context.Context
+}
+
+// NewSFTPDataSource creates a new instance of the SFTPDataSource, reading the file of a
+// sftp://user@host/path URL with the password, the private key and the known_hosts entries of
+// the secret mounted in secretDir.
+func NewSFTPDataSource(endpoint, secretDir string) (*SFTPDataSource, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse endpoint %q", endpoint)
+	}
+	addr, path, err := parseSFTPURL(ep)
+	if err != nil {
+		return nil, err
+	}
+	config, err := newSFTPClientConfig(ep.User.Username(), secretDir)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	reader, err := newSFTPFileReader(ctx, addr, config, path)
+	if err != nil {
+		return nil, err
+	}
+	return &SFTPDataSource{
+		ep:         ep,
+		sftpReader: reader,
+		ctx:        ctx,
+	}, nil
+}
+
+// parseSFTPURL returns the address of the SSH server of a sftp://user@host[:port]/path URL, and
+// the path of the file. A path starting with /~/ is relative to the home directory of the user.
+func parseSFTPURL(ep *url.URL) (string, string, error) {
+	if ep.Scheme != sftpScheme {
+		return "", "", errors.Errorf("sftp URL %q has the scheme %q, sftp://user@host/path is expected", ep.Redacted(), ep.Scheme)
+	}
+	if ep.User == nil || ep.User.Username() == "" {
+		return "", "", errors.Errorf("sftp URL %q does not name a user, sftp://user@host/path is expected", ep.Redacted())
+	}
+	if _, ok := ep.User.Password(); ok {
+		return "", "", errors.Errorf("sftp URL %q has a password, the password of the user is read from the secret", ep.Redacted())
+	}
+	if ep.Hostname() == "" || ep.Path == "" || ep.Path == "/" {
+		return "", "", errors.Errorf("sftp URL %q does not name a host and a file, sftp://user@host/path is expected", ep.Redacted())
+	}
+	port := ep.Port()
+	if port == "" {
+		port = sftpDefaultPort
+	}
+	return net.JoinHostPort(ep.Hostname(), port), strings.TrimPrefix(ep.Path, "/~/"), nil
+}
+
+// newSFTPClientConfig returns the configuration of the SSH connections of the user, authenticated
+// with the private key or the password of the secret. The key of the host is always verified with
+// the known_hosts entries of the secret.
+func newSFTPClientConfig(user, secretDir string) (*ssh.ClientConfig, error) {
+	knownHostsFile := filepath.Join(secretDir, common.KeyKnownHosts)
+	knownHosts, err := readSecretKey(knownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(knownHosts)) == 0 {
+		return nil, errors.Errorf("the %s key of the secret of an sftp source must hold the known_hosts entries of the host, its key is always verified", common.KeyKnownHosts)
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s key of the secret of the sftp source", common.KeyKnownHosts)
+	}
+	hostKeyAlgorithms, err := knownHostKeyAlgorithms(knownHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	var auth []ssh.AuthMethod
+	privateKey, err := readSecretKey(filepath.Join(secretDir, common.KeyPrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(privateKey) > 0 {
+		signer, err := ssh.ParsePrivateKey(privateKey)
+		if _, ok := err.(*ssh.PassphraseMissingError); ok {
+			return nil, errors.Errorf("the private key of the sftp source is protected by a passphrase, an unencrypted key is expected")
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s key of the secret of the sftp source", common.KeyPrivateKey)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	password, err := readSecretKey(filepath.Join(secretDir, common.KeyPassword))
+	if err != nil {
+		return nil, err
+	}
+	if len(password) > 0 {
+		// servers may only enable the keyboard interactive authentication, asking for the password
+		auth = append(auth, ssh.Password(string(password)), ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			for i := range answers {
+				answers[i] = string(password)
+			}
+			return answers, nil
+		}))
+	}
+	if len(auth) == 0 {
+		return nil, errors.Errorf("the secret of an sftp source must hold the %s or the %s of the user", common.KeyPassword, common.KeyPrivateKey)
+	}
+	return &ssh.ClientConfig{
+		User:              user,
+		Auth:              auth,
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: hostKeyAlgorithms,
+		Timeout:           sftpDialTimeout,
+	}, nil
+}
+
+// readSecretKey returns the value of a key of a mounted secret, empty when the secret does not have
+// the key. The trailing line break of a value created from a file is removed.
+func readSecretKey(path string) ([]byte, error) {
+	value, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", path)
+	}
+	return bytes.TrimRight(value, "\r\n"), nil
+}
+
+// knownHostKeyAlgorithms returns the algorithms of the host keys of the known_hosts entries, the
+// server then presents one of those keys even if it prefers another one. It returns nil, and the
+// default algorithms, when an entry holds a certificate authority.
+func knownHostKeyAlgorithms(knownHosts []byte) ([]string, error) {
+	var algorithms []string
+	for rest := knownHosts; ; {
+		marker, _, key, _, next, err := ssh.ParseKnownHosts(rest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid known_hosts entries in the %s key of the secret of the sftp source", common.KeyKnownHosts)
+		}
+		rest = next
+		switch {
+		case marker == "cert-authority":
+			return nil, nil
+		case marker == "revoked":
+			continue
+		case key.Type() == ssh.KeyAlgoRSA:
+			algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA)
+		default:
+			algorithms = append(algorithms, key.Type())
+		}
+	}
+	return algorithms, nil
+}
+
+// Info is called to get initial information about the data.
+func (sd *SFTPDataSource) Info() (ProcessingPhase, error) {
+	var err error
+	sd.sftpReader.ctx = sd.ctx
+	sd.readers, err = newFormatReaders(sd.ctx, sd.sftpReader, uint64(sd.sftpReader.size), sd.ep.Path)
+	if err != nil {
+		klog.Errorf("Error creating readers: %v", err)
+		return ProcessingPhaseError, err
+	}
+	if !sd.readers.Convert {
+		// Downloading a raw file, we can write that directly to the target.
+		return ProcessingPhaseTransferDataFile, nil
+	}
+	return ProcessingPhaseTransferScratch, nil
+}
+
+// SetContext stops the transfer once ctx is done.
+func (sd *SFTPDataSource) SetContext(ctx context.Context) {
+	sd.ctx = ctx
+}
+
+// Transfer is called to transfer the data from the source to a temporary location.
+func (sd *SFTPDataSource) Transfer(path string) (ProcessingPhase, error) {
+	size, _ := util.GetAvailableSpace(path)
+	if size <= int64(0) {
+		//Path provided is invalid.
+		return ProcessingPhaseError, ErrInvalidPath
+	}
+	file := filepath.Join(path, tempFile)
+	sd.readers.StartProgressUpdate()
+	if err := sd.readers.StreamToFile(file); err != nil {
+		return ProcessingPhaseError, err
+	}
+	// If streaming succeeded, then parsing the file into URL will also succeed, no need to check error status
+	sd.url, _ = url.Parse(file)
+	return ProcessingPhaseConvert, nil
+}
+
+// TransferFile is called to transfer the data from the source to the passed in file.
+func (sd *SFTPDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
+	sd.readers.StartProgressUpdate()
+	if err := sd.readers.StreamToFile(fileName); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseResize, nil
+}
+
+// GetURL returns the url that the data processor can use when converting the data.
+func (sd *SFTPDataSource) GetURL() *url.URL {
+	return sd.url
+}
+
+// Close closes any readers or other open resources.
+func (sd *SFTPDataSource) Close() error {
+	var err error
+	if sd.readers != nil {
+		err = sd.readers.Close()
+	}
+	if sd.sftpReader != nil {
+		sd.sftpReader.Close()
+	}
+	return err
+}
+
+// sftpFileReader streams a file of an SFTP server. A read that fails is resumed from the offset
+// reached, over a new connection, up to sftpReadRetries times in a row. The import fails if the
+// size or the modification time of the file changed meanwhile.
+type sftpFileReader struct {
+	ctx     context.Context
+	addr    string
+	config  *ssh.ClientConfig
+	path    string
+	size    int64
+	modTime time.Time
+	offset  int64
+	conn    *ssh.Client
+	client  *sftp.Client
+	file    *sftp.File
+	buf     []byte
+	data    []byte
+}
+
+// newSFTPFileReader connects to the SSH server of addr and opens the file of path.
+func newSFTPFileReader(ctx context.Context, addr string, config *ssh.ClientConfig, path string) (*sftpFileReader, error) {
+	r := &sftpFileReader{ctx: ctx, addr: addr, config: config, path: path, size: -1}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open connects to the SSH server, then opens the file in its sftp subsystem. The attributes of
+// the file are recorded on the first connection, and compared on the next ones.
+func (r *sftpFileReader) open() error {
+	conn, err := ssh.Dial("tcp", r.addr, r.config)
+	if err != nil {
+		return errors.Wrapf(err, "could not connect to sftp server %s as %s", r.addr, r.config.User)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "could not start the sftp subsystem of the ssh server")
+	}
+	file, err := client.Open(r.path)
+	if err != nil {
+		client.Close()
+		conn.Close()
+		return errors.Wrapf(err, "could not open sftp file %q", r.path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		client.Close()
+		conn.Close()
+		return errors.Wrapf(err, "could not stat sftp file %q", r.path)
+	}
+	if r.size < 0 {
+		if !info.Mode().IsRegular() || info.Size() < 0 {
+			client.Close()
+			conn.Close()
+			return errors.Errorf("sftp file %q is not a regular file", r.path)
+		}
+		klog.V(1).Infof("sftp file %s:%s, %d bytes", r.addr, r.path, info.Size())
+		r.size, r.modTime = info.Size(), info.ModTime()
+	} else if info.Size() != r.size || !info.ModTime().Equal(r.modTime) {
+		client.Close()
+		conn.Close()
+		return &sftpFileChangedError{path: r.path}
+	}
+	r.conn, r.client, r.file = conn, client, file
+	return nil
+}
+
+// sftpFileChangedError is the error of a file whose size or modification time changed while it
+// was read, its read is not resumed.
+type sftpFileChangedError struct {
+	path string
+}
+
+func (e *sftpFileChangedError) Error() string {
+	return "sftp file \"" + e.path + "\" changed while it was read"
+}
+
+// Read reads the data of the file, resuming the read over a new connection after an error. The
+// error of a read returning data is left to the next read.
+func (r *sftpFileReader) Read(p []byte) (int, error) {
+	for retry := 0; ; retry++ {
+		if len(r.data) == 0 {
+			if r.offset == r.size {
+				return 0, io.EOF
+			}
+			if err := r.fill(); err != nil {
+				if _, changed := err.(*sftpFileChangedError); changed || retry == sftpReadRetries || r.ctx.Err() != nil {
+					return 0, errors.Wrapf(err, "could not read sftp file \"%s\" at offset %d after %d retries", r.path, r.offset, retry)
+				}
+				klog.Warningf("Resuming the read of sftp file \"%s\" at offset %d: %v", r.path, r.offset, err)
+				r.Close()
+				select {
+				case <-time.After(sftpRetryInterval * time.Duration(retry+1)):
+				case <-r.ctx.Done():
+					return 0, r.ctx.Err()
+				}
+				if err := r.open(); err != nil {
+					if _, changed := err.(*sftpFileChangedError); changed {
+						return 0, err
+					}
+					// the next read fails without connection, and is retried
+					klog.Warningf("Could not resume the read of sftp file \"%s\": %v", r.path, err)
+				}
+				continue
+			}
+		}
+		n := copy(p, r.data)
+		r.data = r.data[n:]
+		return n, nil
+	}
+}
+
+// fill reads the next data of the file, up to its size.
+func (r *sftpFileReader) fill() error {
+	if r.file == nil {
+		return errors.New("not connected")
+	}
+	if r.buf == nil {
+		r.buf = make([]byte, sftpReadBufferSize)
+	}
+	buf := r.buf
+	if remaining := r.size - r.offset; remaining < int64(len(buf)) {
+		buf = buf[:remaining]
+	}
+	n, err := r.file.ReadAt(buf, r.offset)
+	r.offset += int64(n)
+	r.data = buf[:n]
+	if n > 0 {
+		return nil
+	}
+	if err == io.EOF {
+		// the file is shorter than its size
+		return &sftpFileChangedError{path: r.path}
+	}
+	return err
+}
+
+// Close closes the sftp session and the connection.
+func (r *sftpFileReader) Close() error {
+	if r.conn == nil {
+		return nil
+	}
+	r.client.Close()
+	err := r.conn.Close()
+	r.conn, r.client, r.file = nil, nil, nil
+	return err
+}
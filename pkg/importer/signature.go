@@ -0,0 +1,286 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// ErrInvalidSignature is the error of the data of a source that is not signed by a trusted key, or whose
+// signature is expired, retrying the import cannot succeed.
+var ErrInvalidSignature = fmt.Errorf("invalid signature")
+
+// maxSignatureSize is the size of the largest detached signature downloaded
+const maxSignatureSize = 64 << 10
+
+// sourceSignature is a detached OpenPGP signature of the data of the source, hashed as it is read.
+type sourceSignature struct {
+	sig *packet.Signature
+	// the keys of the keyring which may have issued the signature, valid when the data was signed
+	keys []openpgp.Key
+	hash hash.Hash
+	// the key which issued the signature, once verified
+	signer *openpgp.Key
+}
+
+// newSourceSignature returns the detached signature the data of the source is verified against, set with
+// the environment, nil if there is none. The signature is downloaded, trusting the CAs of certDir, unless
+// it is armored in the environment. It fails with ErrInvalidSignature before any data is read if none of
+// the trusted keys was allowed to issue the signature when the data was signed, or if it is expired.
+func newSourceSignature(ctx context.Context, certDir string) (*sourceSignature, error) {
+	keyDir, _ := util.ParseEnvVar(common.ImporterSignatureKeyDirVar, false)
+	if keyDir == "" {
+		return nil, nil
+	}
+	keyring, err := readKeyRing(keyDir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readSignature(ctx, certDir)
+	if err != nil {
+		return nil, err
+	}
+	sigs, err := parseSignatures(data)
+	if err != nil {
+		return nil, err
+	}
+	return selectSignature(keyring, sigs, time.Now())
+}
+
+// readKeyRing returns the armored public keys of the files of dir, the keys of a mounted ConfigMap.
+func readKeyRing(dir string) (openpgp.EntityList, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the public keys trusted to sign the source")
+	}
+	var keyring openpgp.EntityList
+	for _, entry := range entries {
+		// the keys of a mounted ConfigMap link to its hidden data directory
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read the public keys of %s", entry.Name())
+		}
+		entities, err := openpgp.ReadArmoredKeyRing(file)
+		file.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse the armored public keys of %s", entry.Name())
+		}
+		keyring = append(keyring, entities...)
+	}
+	if len(keyring) == 0 {
+		return nil, errors.New("no public key trusted to sign the source")
+	}
+	return keyring, nil
+}
+
+// readSignature returns the detached signature armored in the environment, or downloaded from the URL
+// set with the environment.
+func readSignature(ctx context.Context, certDir string) ([]byte, error) {
+	if armored, _ := util.ParseEnvVar(common.ImporterSignature, false); armored != "" {
+		return []byte(armored), nil
+	}
+	signatureURL, _ := util.ParseEnvVar(common.ImporterSignatureURL, false)
+	if signatureURL == "" {
+		return nil, errors.Errorf("neither %s nor %s is set", common.ImporterSignature, common.ImporterSignatureURL)
+	}
+	client, err := createHTTPClient(certDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the HTTP client downloading the signature")
+	}
+	limits, err := httpRedirectLimits()
+	if err != nil {
+		return nil, err
+	}
+	client.CheckRedirect = redirectPolicy(limits, "", "", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signatureURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create the request of the signature %s", signatureURL)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not download the signature %s", signatureURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("could not download the signature %s: %s", signatureURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not download the signature %s", signatureURL)
+	}
+	if len(data) > maxSignatureSize {
+		return nil, errors.Wrapf(ErrInvalidSignature, "the signature %s is larger than %d bytes", signatureURL, maxSignatureSize)
+	}
+	return data, nil
+}
+
+// parseSignatures returns the signatures of data, an armored or a binary detached signature.
+func parseSignatures(data []byte) ([]*packet.Signature, error) {
+	var r io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		block, err := armor.Decode(bytes.NewReader(bytes.TrimSpace(data)))
+		if err != nil || block.Type != openpgp.SignatureType {
+			return nil, errors.Wrap(ErrInvalidSignature, "the signature of the source is not an armored OpenPGP signature")
+		}
+		r = block.Body
+	}
+	var sigs []*packet.Signature
+	packets := packet.NewReader(r)
+	for {
+		p, err := packets.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidSignature, "could not parse the signature of the source: %v", err)
+		}
+		// version 3 signatures are obsolete
+		if sig, ok := p.(*packet.Signature); ok {
+			sigs = append(sigs, sig)
+		}
+	}
+	if len(sigs) == 0 {
+		return nil, errors.Wrap(ErrInvalidSignature, "no OpenPGP signature found in the signature of the source")
+	}
+	return sigs, nil
+}
+
+// selectSignature returns the first of sigs issued by a key of keyring, that is a primary key or a subkey
+// allowed to sign, neither revoked nor expired when the data was signed. The signature must not be expired
+// at now.
+func selectSignature(keyring openpgp.EntityList, sigs []*packet.Signature, now time.Time) (*sourceSignature, error) {
+	var issuers []string
+	for _, sig := range sigs {
+		if sig.IssuerKeyId == nil {
+			continue
+		}
+		issuers = append(issuers, fmt.Sprintf("%016X", *sig.IssuerKeyId))
+		candidates := keyring.KeysById(*sig.IssuerKeyId)
+		if len(candidates) == 0 {
+			continue
+		}
+		if sig.SigType != packet.SigTypeBinary {
+			return nil, errors.Wrapf(ErrInvalidSignature, "the signature of the source is of type %#x, a binary document signature is expected", sig.SigType)
+		}
+		if sig.SigLifetimeSecs != nil && *sig.SigLifetimeSecs != 0 && now.After(sig.CreationTime.Add(time.Duration(*sig.SigLifetimeSecs)*time.Second)) {
+			return nil, errors.Wrapf(ErrInvalidSignature, "the signature of the source by key %016X is expired", *sig.IssuerKeyId)
+		}
+		if !sig.Hash.Available() {
+			return nil, errors.Wrapf(ErrInvalidSignature, "the hash function %d of the signature of the source is not available", sig.Hash)
+		}
+		var keys []openpgp.Key
+		var reason string
+		for _, key := range candidates {
+			switch {
+			case len(key.Entity.Revocations) > 0 || key.SelfSignature.RevocationReason != nil:
+				reason = "revoked"
+			case key.SelfSignature.FlagsValid && !key.SelfSignature.FlagSign:
+				reason = "not allowed to sign"
+			case sig.CreationTime.Before(key.PublicKey.CreationTime) || keyExpired(key, sig.CreationTime):
+				reason = "not valid when the data was signed"
+			default:
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return nil, errors.Wrapf(ErrInvalidSignature, "the key %016X which signed the source is %s", *sig.IssuerKeyId, reason)
+		}
+		return &sourceSignature{sig: sig, keys: keys, hash: sig.Hash.New()}, nil
+	}
+	return nil, errors.Wrapf(ErrInvalidSignature, "the source is not signed by a trusted key, but by %s", strings.Join(issuers, ", "))
+}
+
+// keyExpired returns true if key, or the primary key of its entity, is expired at t. The lifetime of a key
+// starts with its creation, rather than with its self-signature.
+func keyExpired(key openpgp.Key, t time.Time) bool {
+	expired := func(created time.Time, selfSignature *packet.Signature) bool {
+		return selfSignature.KeyLifetimeSecs != nil && *selfSignature.KeyLifetimeSecs != 0 &&
+			t.After(created.Add(time.Duration(*selfSignature.KeyLifetimeSecs)*time.Second))
+	}
+	if expired(key.PublicKey.CreationTime, key.SelfSignature) {
+		return true
+	}
+	// the primary key is valid as long as one of its identities is
+	for _, identity := range key.Entity.Identities {
+		if !expired(key.Entity.PrimaryKey.CreationTime, identity.SelfSignature) {
+			return false
+		}
+	}
+	return len(key.Entity.Identities) > 0
+}
+
+// newReader returns a reader hashing the data read from r for the signature.
+func (s *sourceSignature) newReader(r io.ReadCloser) io.ReadCloser {
+	return &signedReader{ReadCloser: r, hash: s.hash}
+}
+
+// verify fails with ErrInvalidSignature if the data hashed is not signed by the signature.
+func (s *sourceSignature) verify() error {
+	if s.signer != nil {
+		return nil
+	}
+	var err error
+	for i := range s.keys {
+		if err = s.keys[i].PublicKey.VerifySignature(s.hash, s.sig); err == nil {
+			s.signer = &s.keys[i]
+			break
+		}
+	}
+	if s.signer == nil {
+		return errors.Wrapf(ErrInvalidSignature, "the data of the source does not match its signature by key %016X: %v", *s.sig.IssuerKeyId, err)
+	}
+	klog.Infof("The data of the source is signed by %s", s.fingerprint())
+	if keyExpired(*s.signer, time.Now()) {
+		klog.Warningf("The key %016X which signed the source expired since the data was signed on %s", *s.sig.IssuerKeyId, s.sig.CreationTime.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// fingerprint returns the fingerprint of the primary key of the signer, once the signature is verified.
+func (s *sourceSignature) fingerprint() string {
+	if s.signer == nil {
+		return ""
+	}
+	return fmt.Sprintf("%X", s.signer.Entity.PrimaryKey.Fingerprint[:])
+}
+
+// signedReader is a reader hashing the data read from it for a signature.
+type signedReader struct {
+	io.ReadCloser
+	hash hash.Hash
+}
+
+func (r *signedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	// hashes never fail to write
+	r.hash.Write(p[:n])
+	return n, err
+}
+
+// verifySignature fails with ErrInvalidSignature if the data of the source is not signed by its signature,
+// once it is read to its end.
+func (fr *FormatReaders) verifySignature() error {
+	if fr.signature == nil {
+		return nil
+	}
+	return fr.signature.verify()
+}
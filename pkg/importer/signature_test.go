@@ -0,0 +1,280 @@
+package importer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+)
+
+// newSigningEntity returns an OpenPGP key created at created, expiring lifetime later unless 0
+func newSigningEntity(created time.Time, lifetime time.Duration) *openpgp.Entity {
+	config := &packet.Config{RSABits: 1024, Time: func() time.Time { return created }}
+	entity, err := openpgp.NewEntity("CDI", "test", "cdi@example.com", config)
+	Expect(err).NotTo(HaveOccurred())
+	if lifetime > 0 {
+		secs := uint32(lifetime.Seconds())
+		for _, identity := range entity.Identities {
+			identity.SelfSignature.KeyLifetimeSecs = &secs
+			Expect(identity.SelfSignature.SignUserId(identity.UserId.Id, entity.PrimaryKey, entity.PrivateKey, config)).To(Succeed())
+		}
+	}
+	return entity
+}
+
+// detachSign returns the detached signature of data by entity at signed, armored or binary
+func detachSign(entity *openpgp.Entity, data []byte, signed time.Time, armored bool) []byte {
+	var sig bytes.Buffer
+	config := &packet.Config{Time: func() time.Time { return signed }}
+	if armored {
+		Expect(openpgp.ArmoredDetachSign(&sig, entity, bytes.NewReader(data), config)).To(Succeed())
+	} else {
+		Expect(openpgp.DetachSign(&sig, entity, bytes.NewReader(data), config)).To(Succeed())
+	}
+	return sig.Bytes()
+}
+
+func fingerprint(entity *openpgp.Entity) string {
+	return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint[:])
+}
+
+var _ = Describe("Source signature", func() {
+	var (
+		tmpDir string
+		keyDir string
+		entity *openpgp.Entity
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "signature")
+		Expect(err).NotTo(HaveOccurred())
+		// the keys of a ConfigMap, next to its hidden data directory
+		keyDir = filepath.Join(tmpDir, "keys")
+		Expect(os.MkdirAll(filepath.Join(keyDir, "..data"), 0755)).To(Succeed())
+		entity = newSigningEntity(time.Now().Add(-time.Hour), 0)
+		var key bytes.Buffer
+		w, err := armor.Encode(&key, openpgp.PublicKeyType, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entity.Serialize(w)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(keyDir, "key.asc"), key.Bytes(), 0644)).To(Succeed())
+		os.Setenv(common.ImporterSignatureKeyDirVar, keyDir)
+		createNbdkitCurl = image.NewMockNbdkitCurl
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(common.ImporterSignatureKeyDirVar)
+		os.Unsetenv(common.ImporterSignatureURL)
+		os.Unsetenv(common.ImporterSignature)
+		os.RemoveAll(tmpDir)
+	})
+
+	// serveSignature serves sig and sets its URL in the environment
+	serveSignature := func(sig []byte) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(sig)
+		}))
+		os.Setenv(common.ImporterSignatureURL, server.URL+"/cirros.qcow2.sig")
+		return server
+	}
+
+	table.DescribeTable("should import the data verified against its signature", func(armored, downloaded bool) {
+		sig := detachSign(entity, cirrosData, time.Now(), armored)
+		if downloaded {
+			defer serveSignature(sig).Close()
+		} else {
+			os.Setenv(common.ImporterSignature, string(sig))
+		}
+		server := newResumeServer(cirrosData)
+		defer server.Close()
+		dp, err := NewHTTPDataSource(server.URL+"/cirros.qcow2", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		phase, err := dp.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferScratch))
+		_, err = dp.Transfer(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dp.Signer()).To(Equal(fingerprint(entity)))
+	},
+		table.Entry("armored in the environment", true, false),
+		table.Entry("armored at its URL", true, true),
+		table.Entry("binary at its URL", false, true),
+	)
+
+	It("should remove the target file written with data that does not match its signature", func() {
+		data, err := os.ReadFile(tinyCoreFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		os.Setenv(common.ImporterSignature, string(detachSign(entity, []byte("other data"), time.Now(), true)))
+		server := newResumeServer(data)
+		defer server.Close()
+		source, err := NewHTTPDataSource(server.URL+"/tinyCore.iso", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer source.Close()
+		dataFile := filepath.Join(tmpDir, "disk.img")
+		dp := NewDataProcessor(source, dataFile, tmpDir, "scratchDataDir", "1G", 0.055, false)
+		err = dp.ProcessData()
+		Expect(errors.Is(err, ErrInvalidSignature)).To(BeTrue())
+		Expect(dataFile).NotTo(BeAnExistingFile())
+		Expect(source.Signer()).To(BeEmpty())
+	})
+
+	It("should fail before the data is requested when the source is not signed by a trusted key", func() {
+		other := newSigningEntity(time.Now().Add(-time.Hour), 0)
+		os.Setenv(common.ImporterSignature, string(detachSign(other, cirrosData, time.Now(), true)))
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.Write(cirrosData)
+		}))
+		defer server.Close()
+		_, err := NewHTTPDataSource(server.URL+"/cirros.qcow2", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(errors.Is(err, ErrInvalidSignature)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("%016X", other.PrimaryKey.KeyId)))
+		Expect(atomic.LoadInt32(&requests)).To(BeZero())
+	})
+
+	It("should fail when the signature cannot be downloaded, the import is retried", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		os.Setenv(common.ImporterSignatureURL, server.URL+"/cirros.qcow2.sig")
+		_, err := NewHTTPDataSource(server.URL+"/cirros.qcow2", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrInvalidSignature)).To(BeFalse())
+		Expect(err.Error()).To(ContainSubstring("404"))
+	})
+
+	It("should reject a signature which is not an OpenPGP signature", func() {
+		_, err := parseSignatures([]byte("-----BEGIN PGP MESSAGE-----\n\n-----END PGP MESSAGE-----\n"))
+		Expect(errors.Is(err, ErrInvalidSignature)).To(BeTrue())
+		_, err = parseSignatures([]byte("not a signature"))
+		Expect(errors.Is(err, ErrInvalidSignature)).To(BeTrue())
+	})
+
+	Context("with the keys valid when the data was signed", func() {
+		var now time.Time
+
+		BeforeEach(func() {
+			now = time.Now()
+		})
+
+		// verify verifies data against sig, with the keys of entity
+		verify := func(entity *openpgp.Entity, sig, data []byte) error {
+			sigs, err := parseSignatures(sig)
+			Expect(err).NotTo(HaveOccurred())
+			signature, err := selectSignature(openpgp.EntityList{entity}, sigs, now)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(io.Discard, signature.newReader(io.NopCloser(bytes.NewReader(data))))
+			Expect(err).NotTo(HaveOccurred())
+			if err := signature.verify(); err != nil {
+				return err
+			}
+			Expect(signature.fingerprint()).To(Equal(fingerprint(entity)))
+			return nil
+		}
+
+		// sign returns the binary detached signature of data by key at signed, expiring lifetime later unless 0
+		sign := func(key *packet.PrivateKey, data []byte, signed time.Time, lifetime time.Duration) []byte {
+			sig := &packet.Signature{
+				SigType:      packet.SigTypeBinary,
+				PubKeyAlgo:   key.PubKeyAlgo,
+				Hash:         crypto.SHA256,
+				CreationTime: signed,
+				IssuerKeyId:  &key.KeyId,
+			}
+			if lifetime > 0 {
+				secs := uint32(lifetime.Seconds())
+				sig.SigLifetimeSecs = &secs
+			}
+			h := sig.Hash.New()
+			h.Write(data)
+			Expect(sig.Sign(h, key, nil)).To(Succeed())
+			var out bytes.Buffer
+			Expect(sig.Serialize(&out)).To(Succeed())
+			return out.Bytes()
+		}
+
+		It("should accept the data signed by a signing subkey", func() {
+			entity := newSigningEntity(now.Add(-time.Hour), 0)
+			signingKey, err := rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			subkey := openpgp.Subkey{
+				PublicKey:  packet.NewRSAPublicKey(now.Add(-time.Hour), &signingKey.PublicKey),
+				PrivateKey: packet.NewRSAPrivateKey(now.Add(-time.Hour), signingKey),
+				Sig: &packet.Signature{
+					CreationTime: now.Add(-time.Hour),
+					SigType:      packet.SigTypeSubkeyBinding,
+					PubKeyAlgo:   packet.PubKeyAlgoRSA,
+					Hash:         crypto.SHA256,
+					FlagsValid:   true,
+					FlagSign:     true,
+					IssuerKeyId:  &entity.PrimaryKey.KeyId,
+				},
+			}
+			subkey.PublicKey.IsSubkey = true
+			subkey.PrivateKey.IsSubkey = true
+			entity.Subkeys = append(entity.Subkeys, subkey)
+			Expect(verify(entity, sign(subkey.PrivateKey, cirrosData, now, 0), cirrosData)).To(Succeed())
+		})
+
+		It("should accept the data signed by a key expired since", func() {
+			entity := newSigningEntity(now.Add(-48*time.Hour), 24*time.Hour)
+			Expect(verify(entity, detachSign(entity, cirrosData, now.Add(-36*time.Hour), true), cirrosData)).To(Succeed())
+		})
+
+		It("should fail when the data does not match its signature", func() {
+			err := verify(entity, detachSign(entity, cirrosData, now, true), []byte("other data"))
+			Expect(errors.Is(err, ErrInvalidSignature)).To(BeTrue())
+		})
+
+		table.DescribeTable("should reject the signature", func(invalidate func(*openpgp.Entity, []byte) []byte, reason string) {
+			entity := newSigningEntity(now.Add(-48*time.Hour), 24*time.Hour)
+			err := verify(entity, invalidate(entity, cirrosData), cirrosData)
+			Expect(errors.Is(err, ErrInvalidSignature)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(reason))
+		},
+			table.Entry("by a key expired when the data was signed", func(entity *openpgp.Entity, data []byte) []byte {
+				return detachSign(entity, data, now.Add(-time.Hour), true)
+			}, "not valid when the data was signed"),
+			table.Entry("made before the key was created", func(entity *openpgp.Entity, data []byte) []byte {
+				return detachSign(entity, data, now.Add(-72*time.Hour), true)
+			}, "not valid when the data was signed"),
+			table.Entry("by a revoked key", func(entity *openpgp.Entity, data []byte) []byte {
+				entity.Revocations = append(entity.Revocations, &packet.Signature{SigType: packet.SigTypeKeyRevocation})
+				return detachSign(entity, data, now.Add(-36*time.Hour), true)
+			}, "revoked"),
+			table.Entry("of a text document", func(entity *openpgp.Entity, data []byte) []byte {
+				var sig bytes.Buffer
+				config := &packet.Config{Time: func() time.Time { return now.Add(-36 * time.Hour) }}
+				Expect(openpgp.DetachSignText(&sig, entity, bytes.NewReader(data), config)).To(Succeed())
+				return sig.Bytes()
+			}, "a binary document signature is expected"),
+			table.Entry("which is expired", func(entity *openpgp.Entity, data []byte) []byte {
+				return sign(entity.PrivateKey, data, now.Add(-36*time.Hour), time.Hour)
+			}, "is expired"),
+		)
+	})
+})
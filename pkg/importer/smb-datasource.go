@@ -0,0 +1,326 @@
+package importer
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/klog/v2"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+	"kubevirt.io/containerized-data-importer/pkg/util/smb"
+)
+
+const (
+	// smbScheme is the scheme of the smb://server/share/path URLs
+	smbScheme = "smb"
+	// smbReadRetries is the number of times a failed read of a file is resumed before the import fails
+	smbReadRetries = 3
+	// smbReadBufferSize is the size of the data requested at once
+	smbReadBufferSize = 2 << 20
+)
+
+var (
+	// smbRetryInterval is the delay before the first resumption of a failed read, raised on each
+	// retry, may be overridden in tests
+	smbRetryInterval = 2 * time.Second
+)
+
+// smbFile is a file of an SMB share opened for reading.
+type smbFile interface {
+	io.ReaderAt
+	io.Closer
+	Size() int64
+	ModTime() time.Time
+}
+
+// openSMBFile opens the file of a share with the credentials of the user, and returns it with the
+// client to close once it is read, may be overridden in tests.
+var openSMBFile = func(credentials smb.Credentials, server, share, name string) (smbFile, io.Closer, error) {
+	client := smb.NewClient(credentials)
+	file, err := client.Open(server, share, name)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	return file, client, nil
+}
+
+// SMBDataSource is the struct containing the information needed to import a file of an SMB share,
+// named by a smb://server[:port]/share/path URL. The file is read over SMB 2 or 3 by the importer,
+// without mounting the share, with the credentials of the user held by the secret. The DFS links
+// of the path are followed.
+// Sequence of phases:
+// 1a. Info -> TransferDataFile, if the file is a raw image
+// 1b. Info -> Transfer in all other cases
+// 2. Transfer -> Convert
+type SMBDataSource struct {
+	// smb://server/share/path URL
+	ep *url.URL
+	// Reader
+	smbReader *smbFileReader
+	// stack of readers, created by Info
+	sourceReaders
+	// The image file in scratch space.
+	url *url.URL
+	// the readers stop once ctx is done
+	ctx context.Context
+}
+
+// NewSMBDataSource creates a new instance of the SMBDataSource, reading the file of a
+// smb://server/share/path URL with the user name, the password and the domain of the secret
+// mounted in secretDir.
+func NewSMBDataSource(endpoint, secretDir string) (*SMBDataSource, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse endpoint %q", endpoint)
+	}
+	share, name, err := parseSMBURL(ep)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := newSMBCredentials(secretDir)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	reader, err := newSMBFileReader(ctx, credentials, ep.Host, share, name)
+	if err != nil {
+		return nil, err
+	}
+	return &SMBDataSource{
+		ep:        ep,
+		smbReader: reader,
+		ctx:       ctx,
+	}, nil
+}
+
+// parseSMBURL returns the share of a smb://server[:port]/share/path URL, and the path of the file
+// in the share.
+func parseSMBURL(ep *url.URL) (string, string, error) {
+	if ep.Scheme != smbScheme {
+		return "", "", errors.Errorf("smb URL %q has the scheme %q, smb://server/share/path is expected", ep.Redacted(), ep.Scheme)
+	}
+	if ep.User != nil {
+		return "", "", errors.Errorf("smb URL %q names a user, the credentials of the user are read from the secret", ep.Redacted())
+	}
+	parts := strings.SplitN(strings.TrimPrefix(ep.Path, "/"), "/", 2)
+	if ep.Hostname() == "" || len(parts) < 2 || parts[0] == "" || strings.Trim(parts[1], "/") == "" {
+		return "", "", errors.Errorf("smb URL %q does not name a server, a share and a file, smb://server/share/path is expected", ep.Redacted())
+	}
+	return parts[0], parts[1], nil
+}
+
+// newSMBCredentials returns the credentials of the user held by the secret, the domain being
+// optional.
+func newSMBCredentials(secretDir string) (smb.Credentials, error) {
+	var values [3][]byte
+	for i, key := range []string{common.KeyUsername, common.KeyPassword, common.KeyDomain} {
+		value, err := readSecretKey(filepath.Join(secretDir, key))
+		if err != nil {
+			return smb.Credentials{}, err
+		}
+		values[i] = value
+	}
+	if len(values[0]) == 0 || len(values[1]) == 0 {
+		return smb.Credentials{}, errors.Errorf("the secret of an smb source must hold the %s and the %s of the user", common.KeyUsername, common.KeyPassword)
+	}
+	return smb.Credentials{User: string(values[0]), Password: string(values[1]), Domain: string(values[2])}, nil
+}
+
+// Info is called to get initial information about the data.
+func (sd *SMBDataSource) Info() (ProcessingPhase, error) {
+	var err error
+	sd.smbReader.ctx = sd.ctx
+	sd.readers, err = newFormatReaders(sd.ctx, sd.smbReader, uint64(sd.smbReader.size), sd.ep.Path)
+	if err != nil {
+		klog.Errorf("Error creating readers: %v", err)
+		return ProcessingPhaseError, err
+	}
+	if !sd.readers.Convert {
+		// Downloading a raw file, we can write that directly to the target.
+		return ProcessingPhaseTransferDataFile, nil
+	}
+	return ProcessingPhaseTransferScratch, nil
+}
+
+// SetContext stops the transfer once ctx is done.
+func (sd *SMBDataSource) SetContext(ctx context.Context) {
+	sd.ctx = ctx
+}
+
+// Transfer is called to transfer the data from the source to a temporary location.
+func (sd *SMBDataSource) Transfer(path string) (ProcessingPhase, error) {
+	size, _ := util.GetAvailableSpace(path)
+	if size <= int64(0) {
+		//Path provided is invalid.
+		return ProcessingPhaseError, ErrInvalidPath
+	}
+	file := filepath.Join(path, tempFile)
+	sd.readers.StartProgressUpdate()
+	if err := sd.readers.StreamToFile(file); err != nil {
+		return ProcessingPhaseError, err
+	}
+	// If streaming succeeded, then parsing the file into URL will also succeed, no need to check error status
+	sd.url, _ = url.Parse(file)
+	return ProcessingPhaseConvert, nil
+}
+
+// TransferFile is called to transfer the data from the source to the passed in file.
+func (sd *SMBDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
+	sd.readers.StartProgressUpdate()
+	if err := sd.readers.StreamToFile(fileName); err != nil {
+		return ProcessingPhaseError, err
+	}
+	return ProcessingPhaseResize, nil
+}
+
+// GetURL returns the url that the data processor can use when converting the data.
+func (sd *SMBDataSource) GetURL() *url.URL {
+	return sd.url
+}
+
+// Close closes any readers or other open resources.
+func (sd *SMBDataSource) Close() error {
+	var err error
+	if sd.readers != nil {
+		err = sd.readers.Close()
+	}
+	if sd.smbReader != nil {
+		sd.smbReader.Close()
+	}
+	return err
+}
+
+// smbFileReader streams a file of an SMB share. A read that fails is resumed from the offset
+// reached, over a new session, up to smbReadRetries times in a row. The import fails if the size
+// or the modification time of the file changed meanwhile.
+type smbFileReader struct {
+	ctx         context.Context
+	credentials smb.Credentials
+	server      string
+	share       string
+	name        string
+	size        int64
+	modTime     time.Time
+	offset      int64
+	client      io.Closer
+	file        smbFile
+	buf         []byte
+	data        []byte
+}
+
+// newSMBFileReader connects to the server and opens the file of the share.
+func newSMBFileReader(ctx context.Context, credentials smb.Credentials, server, share, name string) (*smbFileReader, error) {
+	r := &smbFileReader{ctx: ctx, credentials: credentials, server: server, share: share, name: name, size: -1}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the file in a new session of the server. The size and the modification time of the
+// file are recorded on the first session, and compared on the next ones.
+func (r *smbFileReader) open() error {
+	file, client, err := openSMBFile(r.credentials, r.server, r.share, r.name)
+	if err != nil {
+		return errors.Wrapf(err, "could not open smb file %q of share %s of %s", r.name, r.share, r.server)
+	}
+	if r.size < 0 {
+		klog.V(1).Infof("smb file %s/%s/%s, %d bytes", r.server, r.share, r.name, file.Size())
+		r.size, r.modTime = file.Size(), file.ModTime()
+	} else if file.Size() != r.size || !file.ModTime().Equal(r.modTime) {
+		file.Close()
+		client.Close()
+		return &smbFileChangedError{name: r.name}
+	}
+	r.client, r.file = client, file
+	return nil
+}
+
+// smbFileChangedError is the error of a file whose size or modification time changed while it
+// was read, its read is not resumed.
+type smbFileChangedError struct {
+	name string
+}
+
+func (e *smbFileChangedError) Error() string {
+	return "smb file \"" + e.name + "\" changed while it was read"
+}
+
+// Read reads the data of the file, resuming the read over a new session after an error. The error
+// of a read returning data is left to the next read.
+func (r *smbFileReader) Read(p []byte) (int, error) {
+	for retry := 0; ; retry++ {
+		if len(r.data) == 0 {
+			if r.offset == r.size {
+				return 0, io.EOF
+			}
+			if err := r.fill(); err != nil {
+				if _, changed := err.(*smbFileChangedError); changed || retry == smbReadRetries || r.ctx.Err() != nil {
+					return 0, errors.Wrapf(err, "could not read smb file \"%s\" at offset %d after %d retries", r.name, r.offset, retry)
+				}
+				klog.Warningf("Resuming the read of smb file \"%s\" at offset %d: %v", r.name, r.offset, err)
+				r.Close()
+				select {
+				case <-time.After(smbRetryInterval * time.Duration(retry+1)):
+				case <-r.ctx.Done():
+					return 0, r.ctx.Err()
+				}
+				if err := r.open(); err != nil {
+					if _, changed := err.(*smbFileChangedError); changed {
+						return 0, err
+					}
+					// the next read fails without session, and is retried
+					klog.Warningf("Could not resume the read of smb file \"%s\": %v", r.name, err)
+				}
+				continue
+			}
+		}
+		n := copy(p, r.data)
+		r.data = r.data[n:]
+		return n, nil
+	}
+}
+
+// fill reads the next data of the file, up to its size.
+func (r *smbFileReader) fill() error {
+	if r.file == nil {
+		return errors.New("not connected")
+	}
+	if r.buf == nil {
+		r.buf = make([]byte, smbReadBufferSize)
+	}
+	buf := r.buf
+	if remaining := r.size - r.offset; remaining < int64(len(buf)) {
+		buf = buf[:remaining]
+	}
+	n, err := r.file.ReadAt(buf, r.offset)
+	r.offset += int64(n)
+	r.data = buf[:n]
+	if n > 0 {
+		return nil
+	}
+	if err == io.EOF {
+		// the file is shorter than its size
+		return &smbFileChangedError{name: r.name}
+	}
+	return err
+}
+
+// Close closes the file and the session.
+func (r *smbFileReader) Close() error {
+	if r.client == nil {
+		return nil
+	}
+	r.file.Close()
+	err := r.client.Close()
+	r.client, r.file = nil, nil
+	return err
+}
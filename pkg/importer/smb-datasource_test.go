@@ -0,0 +1,215 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util/smb"
+)
+
+var _ = Describe("SMB data source", func() {
+	var (
+		sd        *SMBDataSource
+		tmpDir    string
+		secretDir string
+		share     *fakeSMBShare
+		err       error
+	)
+
+	BeforeEach(func() {
+		tmpDir, err = os.MkdirTemp("", "scratch")
+		Expect(err).NotTo(HaveOccurred())
+		secretDir, err = os.MkdirTemp("", "smb")
+		Expect(err).NotTo(HaveOccurred())
+		share = &fakeSMBShare{files: map[string][]byte{"images/cirros.qcow2": cirrosData}}
+		openSMBFile = share.open
+		smbRetryInterval = time.Millisecond
+	})
+
+	AfterEach(func() {
+		if sd != nil {
+			sd.Close()
+			sd = nil
+		}
+		Expect(share.sessions).To(BeZero())
+		openSMBFile = origOpenSMBFile
+		smbRetryInterval = 2 * time.Second
+		os.RemoveAll(tmpDir)
+		os.RemoveAll(secretDir)
+	})
+
+	writeSecret := func(key, value string) {
+		Expect(os.WriteFile(filepath.Join(secretDir, key), []byte(value), 0600)).To(Succeed())
+	}
+
+	transfer := func() []byte {
+		phase, err := sd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseTransferScratch))
+		phase, err = sd.Transfer(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phase).To(Equal(ProcessingPhaseConvert))
+		data, err := os.ReadFile(filepath.Join(tmpDir, tempFile))
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	It("should read a file with the credentials of the user", func() {
+		writeSecret(common.KeyUsername, "importer\n")
+		writeSecret(common.KeyPassword, "s3cret\n")
+		sd, err = NewSMBDataSource("smb://fileserver/vms/images/cirros.qcow2", secretDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transfer()).To(Equal(cirrosData))
+		Expect(share.opened).To(ConsistOf(`fileserver vms images/cirros.qcow2`))
+		Expect(share.credentials).To(ConsistOf(smb.Credentials{User: "importer", Password: "s3cret"}))
+	})
+
+	It("should read a file with the credentials of a domain user, on the port of the URL", func() {
+		writeSecret(common.KeyUsername, "importer")
+		writeSecret(common.KeyPassword, "s3cret")
+		writeSecret(common.KeyDomain, "EXAMPLE")
+		sd, err = NewSMBDataSource("smb://fileserver:1445/vms/images/cirros.qcow2", secretDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transfer()).To(Equal(cirrosData))
+		Expect(share.opened).To(ConsistOf(`fileserver:1445 vms images/cirros.qcow2`))
+		Expect(share.credentials).To(ConsistOf(smb.Credentials{User: "importer", Password: "s3cret", Domain: "EXAMPLE"}))
+	})
+
+	It("should fail when the file cannot be opened", func() {
+		writeSecret(common.KeyUsername, "importer")
+		writeSecret(common.KeyPassword, "s3cret")
+		sd, err = NewSMBDataSource("smb://fileserver/vms/images/missing.qcow2", secretDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("could not open smb file \"images/missing.qcow2\" of share vms of fileserver"))
+		Expect(smb.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should resume an interrupted read from its offset", func() {
+		share.failAfter = 3 << 20
+		share.failures = 2
+		writeSecret(common.KeyUsername, "importer")
+		writeSecret(common.KeyPassword, "s3cret")
+		sd, err = NewSMBDataSource("smb://fileserver/vms/images/cirros.qcow2", secretDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transfer()).To(Equal(cirrosData))
+		Expect(share.opened).To(HaveLen(3))
+		// the data read before an interruption is not read again
+		Expect(share.served).To(BeNumerically("<=", len(cirrosData)+2*smbReadBufferSize))
+	})
+
+	It("should fail when the file changed while it was read", func() {
+		share.failAfter = 3 << 20
+		share.failures = 1
+		share.onFailure = func() {
+			share.files["images/cirros.qcow2"] = tinyCoreVdiData
+		}
+		writeSecret(common.KeyUsername, "importer")
+		writeSecret(common.KeyPassword, "s3cret")
+		sd, err = NewSMBDataSource("smb://fileserver/vms/images/cirros.qcow2", secretDir)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = sd.Info()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = sd.Transfer(tmpDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("changed while it was read"))
+	})
+
+	table.DescribeTable("should reject", func(endpoint string, secret map[string]string, expectedErr string) {
+		for key, value := range secret {
+			writeSecret(key, value)
+		}
+		sd, err = NewSMBDataSource(endpoint, secretDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErr))
+		Expect(share.opened).To(BeEmpty())
+	},
+		table.Entry("an http URL", "http://fileserver/vms/images/cirros.qcow2", map[string]string{}, "smb://server/share/path is expected"),
+		table.Entry("a URL with a user", "smb://importer@fileserver/vms/images/cirros.qcow2", map[string]string{}, "the credentials of the user are read from the secret"),
+		table.Entry("a URL without share", "smb://fileserver/", map[string]string{}, "does not name a server, a share and a file"),
+		table.Entry("a URL without file", "smb://fileserver/vms/", map[string]string{}, "does not name a server, a share and a file"),
+		table.Entry("a secret without user", "smb://fileserver/vms/images/cirros.qcow2",
+			map[string]string{common.KeyPassword: "s3cret"}, "must hold the username and the password of the user"),
+		table.Entry("a secret without password", "smb://fileserver/vms/images/cirros.qcow2",
+			map[string]string{common.KeyUsername: "importer"}, "must hold the username and the password of the user"),
+	)
+})
+
+var origOpenSMBFile = openSMBFile
+
+// fakeSMBShare serves the files of a share, each open starting a session. The reads fail after
+// failAfter bytes, failures times.
+type fakeSMBShare struct {
+	files       map[string][]byte
+	opened      []string
+	credentials []smb.Credentials
+	sessions    int
+	served      int
+	failAfter   int
+	failures    int
+	onFailure   func()
+}
+
+func (s *fakeSMBShare) open(credentials smb.Credentials, server, share, name string) (smbFile, io.Closer, error) {
+	s.opened = append(s.opened, server+" "+share+" "+name)
+	s.credentials = append(s.credentials, credentials)
+	data, ok := s.files[name]
+	if !ok {
+		return nil, nil, &smb.StatusError{Status: 0xc0000034, Op: "create"}
+	}
+	s.sessions++
+	return &fakeSMBFile{share: s, data: data, modTime: time.Unix(int64(len(data)), 0)}, closerFunc(func() error {
+		s.sessions--
+		return nil
+	}), nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+type fakeSMBFile struct {
+	share   *fakeSMBShare
+	data    []byte
+	modTime time.Time
+	read    int
+}
+
+func (f *fakeSMBFile) ReadAt(p []byte, off int64) (int, error) {
+	s := f.share
+	if s.failures > 0 && f.read >= s.failAfter {
+		s.failures--
+		if s.onFailure != nil {
+			s.onFailure()
+		}
+		return 0, errors.New("connection reset by peer")
+	}
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[off:])
+	f.read += n
+	s.served += n
+	return n, nil
+}
+
+func (f *fakeSMBFile) Close() error {
+	return nil
+}
+
+func (f *fakeSMBFile) Size() int64 {
+	return int64(len(f.data))
+}
+
+func (f *fakeSMBFile) ModTime() time.Time {
+	return f.modTime
+}
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/util"
+)
+
+// ErrSourceUnchanged is the error of a source whose data did not change since it was imported to the
+// volume, the data of the volume is kept.
+var ErrSourceUnchanged = fmt.Errorf("the source did not change since its previous import")
+
+// SourceValidators identify the data of a source: the ETag and the Last-Modified date of an http
+// response, or the ETag of an S3 object.
+type SourceValidators struct {
+	// ETag is the entity tag of the data, quoted, weak when prefixed with W/
+	ETag string
+	// LastModified is the time the data was last modified, zero when unknown
+	LastModified time.Time
+}
+
+// Empty returns true if there is no validator.
+func (v SourceValidators) Empty() bool {
+	return v.ETag == "" && v.LastModified.IsZero()
+}
+
+// Matches returns true if the validators identify the same data as the other ones: their ETags are
+// the same when both have one, compared weakly as by If-None-Match, or else their Last-Modified dates.
+func (v SourceValidators) Matches(other SourceValidators) bool {
+	if v.ETag != "" && other.ETag != "" {
+		return strings.TrimPrefix(v.ETag, "W/") == strings.TrimPrefix(other.ETag, "W/")
+	}
+	return !v.LastModified.IsZero() && v.LastModified.Equal(other.LastModified)
+}
+
+// conditionalHeaders returns the headers of a request of the data of an http source answered with
+// 304 Not Modified if it still matches the validators.
+func (v SourceValidators) conditionalHeaders() []string {
+	var headers []string
+	if v.ETag != "" {
+		headers = append(headers, "If-None-Match: "+v.ETag)
+	}
+	if !v.LastModified.IsZero() {
+		headers = append(headers, "If-Modified-Since: "+v.LastModified.UTC().Format(http.TimeFormat))
+	}
+	return headers
+}
+
+// responseValidators returns the validators of the data of an http response.
+func responseValidators(resp *http.Response) SourceValidators {
+	v := SourceValidators{ETag: resp.Header.Get("ETag")}
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		v.LastModified = lastModified.UTC()
+	}
+	return v
+}
+
+// PreviousSourceValidators returns the validators of the data imported to the volume by a previous
+// import of the source, set with the IMPORTER_SOURCE_ETAG and IMPORTER_SOURCE_LAST_MODIFIED
+// environment variables. They are empty unless the import is skipped when the source did not change.
+func PreviousSourceValidators() (SourceValidators, error) {
+	var v SourceValidators
+	v.ETag, _ = util.ParseEnvVar(common.ImporterSourceETag, false)
+	if value, _ := util.ParseEnvVar(common.ImporterSourceLastModified, false); value != "" {
+		lastModified, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return v, errors.Errorf("invalid %s value %q, an RFC 3339 time is expected", common.ImporterSourceLastModified, value)
+		}
+		v.LastModified = lastModified.UTC()
+	}
+	return v, nil
+}
@@ -0,0 +1,181 @@
+package importer
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
+)
+
+var _ = Describe("Source validators", func() {
+	lastModified := time.Date(2023, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	AfterEach(func() {
+		os.Unsetenv(common.ImporterSourceETag)
+		os.Unsetenv(common.ImporterSourceLastModified)
+	})
+
+	table.DescribeTable("should match", func(v, other SourceValidators, match bool) {
+		Expect(v.Matches(other)).To(Equal(match))
+	},
+		table.Entry("the same ETag", SourceValidators{ETag: `"v1"`}, SourceValidators{ETag: `"v1"`}, true),
+		table.Entry("not another ETag", SourceValidators{ETag: `"v1"`}, SourceValidators{ETag: `"v2"`}, false),
+		table.Entry("the same weak ETag", SourceValidators{ETag: `W/"v1"`}, SourceValidators{ETag: `"v1"`}, true),
+		table.Entry("not another ETag whatever the Last-Modified dates",
+			SourceValidators{ETag: `"v1"`, LastModified: lastModified}, SourceValidators{ETag: `"v2"`, LastModified: lastModified}, false),
+		table.Entry("the same Last-Modified date", SourceValidators{LastModified: lastModified}, SourceValidators{LastModified: lastModified}, true),
+		table.Entry("not another Last-Modified date", SourceValidators{LastModified: lastModified}, SourceValidators{LastModified: lastModified.Add(time.Second)}, false),
+		table.Entry("not without validators", SourceValidators{}, SourceValidators{}, false),
+	)
+
+	It("should send the conditional headers of the validators", func() {
+		v := SourceValidators{ETag: `"v1"`, LastModified: lastModified}
+		Expect(v.conditionalHeaders()).To(Equal([]string{`If-None-Match: "v1"`, "If-Modified-Since: Wed, 01 Mar 2023 12:30:00 GMT"}))
+		Expect(SourceValidators{}.conditionalHeaders()).To(BeEmpty())
+	})
+
+	It("should read the validators of the previous import from the environment", func() {
+		os.Setenv(common.ImporterSourceETag, `"v1"`)
+		os.Setenv(common.ImporterSourceLastModified, "2023-03-01T13:30:00+01:00")
+		v, err := PreviousSourceValidators()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.ETag).To(Equal(`"v1"`))
+		Expect(v.LastModified).To(Equal(lastModified))
+	})
+
+	It("should fail with an invalid Last-Modified time", func() {
+		os.Setenv(common.ImporterSourceLastModified, "Wed, 01 Mar 2023 12:30:00 GMT")
+		_, err := PreviousSourceValidators()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(common.ImporterSourceLastModified))
+	})
+})
+
+var _ = Describe("Http source validators", func() {
+	lastModified := time.Date(2023, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		createNbdkitCurl = image.NewMockNbdkitCurl
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(common.ImporterSourceETag)
+		os.Unsetenv(common.ImporterSourceLastModified)
+	})
+
+	It("should report the ETag of the data", func() {
+		server := newResumeServer(cirrosData)
+		defer server.Close()
+		dp, err := NewHTTPDataSource(server.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		Expect(dp.SourceValidators()).To(Equal(SourceValidators{ETag: `"v1"`}))
+	})
+
+	table.DescribeTable("with the ETag of the previous import", func(etag string, unchanged bool) {
+		server := newResumeServer(cirrosData)
+		defer server.Close()
+		os.Setenv(common.ImporterSourceETag, etag)
+		dp, err := NewHTTPDataSource(server.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt)
+		if unchanged {
+			Expect(err).To(MatchError(ErrSourceUnchanged))
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		Expect(dp.SourceValidators().ETag).To(Equal(`"v1"`))
+	},
+		table.Entry("should not read the data that did not change", `"v1"`, true),
+		table.Entry("should read the data that changed", `"v0"`, false),
+	)
+
+	table.DescribeTable("with the Last-Modified date of the previous import", func(previous time.Time, unchanged bool) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "", lastModified, bytes.NewReader(cirrosData))
+		}))
+		defer server.Close()
+		os.Setenv(common.ImporterSourceLastModified, previous.Format(time.RFC3339))
+		dp, err := NewHTTPDataSource(server.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt)
+		if unchanged {
+			Expect(err).To(MatchError(ErrSourceUnchanged))
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+		defer dp.Close()
+		Expect(dp.SourceValidators()).To(Equal(SourceValidators{LastModified: lastModified}))
+	},
+		table.Entry("should not read the data that was not modified since", lastModified, true),
+		table.Entry("should read the data that was modified since", lastModified.Add(-time.Hour), false),
+	)
+
+	It("should not read the data whose ETag did not change when the server ignores the conditional request", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			w.Write(cirrosData)
+		}))
+		defer server.Close()
+		os.Setenv(common.ImporterSourceETag, `"v1"`)
+		_, err := NewHTTPDataSource(server.URL+"/disk.img", "", "", "", cdiv1.DataVolumeKubeVirt)
+		Expect(err).To(MatchError(ErrSourceUnchanged))
+	})
+})
+
+// etagMockS3Client is a mock AWS S3 client serving an object of the ETag v1, which fails with 304 Not
+// Modified when requested if it does not match v1.
+type etagMockS3Client struct {
+	ignoreIfNoneMatch bool
+}
+
+func (mc *etagMockS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	if !mc.ignoreIfNoneMatch && aws.StringValue(input.IfNoneMatch) == `"v1"` {
+		return nil, awserr.NewRequestFailure(awserr.New("NotModified", "Not Modified", nil), http.StatusNotModified, "request")
+	}
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader([]byte("data"))),
+		ContentLength: aws.Int64(4),
+		ETag:          aws.String(`"v1"`),
+	}, nil
+}
+
+var _ = Describe("S3 source validators", func() {
+	AfterEach(func() {
+		newClientFunc = getS3Client
+		os.Unsetenv(common.ImporterSourceETag)
+	})
+
+	table.DescribeTable("with the ETag of the previous import", func(etag string, ignoreIfNoneMatch, unchanged bool) {
+		newClientFunc = func(endpoint, region, accKey, secKey string, certDir string, urlScheme string, pathStyle bool) (S3Client, error) {
+			return &etagMockS3Client{ignoreIfNoneMatch: ignoreIfNoneMatch}, nil
+		}
+		if etag != "" {
+			os.Setenv(common.ImporterSourceETag, etag)
+		}
+		sd, err := NewS3DataSource("http://amazon.com/bucket/disk.img", "", "", "", S3Options{})
+		if unchanged {
+			Expect(err).To(MatchError(ErrSourceUnchanged))
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+		defer sd.Close()
+		Expect(sd.SourceValidators()).To(Equal(SourceValidators{ETag: `"v1"`}))
+	},
+		table.Entry("should read the object without one", "", false, false),
+		table.Entry("should not read the object that did not change", `"v1"`, false, true),
+		table.Entry("should not read the object that did not change when the condition is ignored", `"v1"`, true, true),
+		table.Entry("should read the object that changed", `"v0"`, false, false),
+	)
+})
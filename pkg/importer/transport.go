@@ -19,9 +19,11 @@ package importer
 import (
 	"archive/tar"
 	"context"
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/containers/image/v5/docker"
@@ -30,10 +32,12 @@ import (
 	"github.com/containers/image/v5/oci/archive"
 	"github.com/containers/image/v5/pkg/blobinfocache"
 	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
@@ -41,6 +45,20 @@ const (
 	whFilePrefix = ".wh."
 )
 
+// artifactManifest is an OCI image manifest, or an OCI artifact manifest listing blobs rather than
+// layers. The disk image of an artifact is a blob of its own, not a file of a tar layer.
+type artifactManifest struct {
+	MediaType string               `json:"mediaType"`
+	Layers    []artifactDescriptor `json:"layers"`
+	Blobs     []artifactDescriptor `json:"blobs"`
+}
+
+type artifactDescriptor struct {
+	MediaType string        `json:"mediaType"`
+	Digest    digest.Digest `json:"digest"`
+	Size      int64         `json:"size"`
+}
+
 func commandTimeoutContext() (context.Context, context.CancelFunc) {
 	return context.WithCancel(context.Background())
 }
@@ -52,6 +70,11 @@ func buildSourceContext(accessKey, secKey, certDir string, insecureRegistry bool
 			Username: accessKey,
 			Password: secKey,
 		}
+	} else if authFile := os.Getenv(common.ImporterRegistryAuthFile); authFile != "" {
+		if _, err := os.Stat(authFile); err == nil {
+			// the docker config of a kubernetes.io/dockerconfigjson secret is read as an auth file
+			ctx.AuthFilePath = authFile
+		}
 	}
 	if certDir != "" {
 		ctx.DockerCertPath = certDir
@@ -88,7 +111,8 @@ func parseImageName(img string) (types.ImageReference, error) {
 		return nil, errors.Errorf(`Invalid image name "%s", expected colon-separated transport:reference`, img)
 	}
 	switch parts[0] {
-	case cdiv1.RegistrySchemeDocker:
+	case cdiv1.RegistrySchemeDocker, cdiv1.RegistrySchemeOciRegistry:
+		// an OCI distribution registry is pulled from as a docker registry
 		return docker.ParseReference(parts[1])
 	case cdiv1.RegistrySchemeOci:
 		return archive.ParseReference(parts[1])
@@ -107,6 +131,15 @@ func hasPrefix(path string, pathPrefix string) bool {
 		strings.HasPrefix(path, "./"+pathPrefix)
 }
 
+func hasAnyPrefix(path string, pathPrefixes []string) bool {
+	for _, pathPrefix := range pathPrefixes {
+		if hasPrefix(path, pathPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func isWhiteout(path string) bool {
 	return strings.HasPrefix(filepath.Base(path), whFilePrefix)
 }
@@ -120,7 +153,7 @@ func processLayer(ctx context.Context,
 	src types.ImageSource,
 	layer types.BlobInfo,
 	destDir string,
-	pathPrefix string,
+	pathPrefixes []string,
 	cache types.BlobInfoCache,
 	stopAtFirst bool) (bool, error) {
 
@@ -148,7 +181,7 @@ func processLayer(ctx context.Context,
 			return false, errors.Wrap(err, "Error reading layer")
 		}
 
-		if hasPrefix(hdr.Name, pathPrefix) && !isWhiteout(hdr.Name) && !isDir(hdr) {
+		if hasAnyPrefix(hdr.Name, pathPrefixes) && !isWhiteout(hdr.Name) && !isDir(hdr) {
 			klog.Infof("File '%v' found in the layer", hdr.Name)
 			destFile := filepath.Join(destDir, hdr.Name)
 
@@ -172,8 +205,131 @@ func processLayer(ctx context.Context,
 	return found, nil
 }
 
-func copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir string, insecureRegistry, stopAtFirst bool) error {
-	klog.Infof("Downloading image from '%v', copying file from '%v' to '%v'", url, pathPrefix, destDir)
+// findArtifactBlob returns the blob of mediaType of an artifact manifest, false when the manifest
+// has none, such as the manifest of a container image.
+func findArtifactBlob(manifestBlob []byte, mediaType string) (types.BlobInfo, bool) {
+	if mediaType == "" {
+		return types.BlobInfo{}, false
+	}
+	m := artifactManifest{}
+	if err := json.Unmarshal(manifestBlob, &m); err != nil {
+		return types.BlobInfo{}, false
+	}
+	for _, blob := range append(m.Layers, m.Blobs...) {
+		if blob.MediaType == mediaType {
+			return types.BlobInfo{Digest: blob.Digest, Size: blob.Size, MediaType: blob.MediaType}, true
+		}
+	}
+	return types.BlobInfo{}, false
+}
+
+// chooseListInstance returns the digest of the image of the manifest list manifestBlob for platform, when it
+// is set, or else for the platform of the node, or defaultPlatform, when the list has no image for the node.
+// The error lists the platforms of the list when it has no image for them.
+func chooseListInstance(manifestBlob []byte, mimeType, platform, defaultPlatform string) (digest.Digest, error) {
+	list, err := manifest.ListFromBlob(manifestBlob, mimeType)
+	if err != nil {
+		return "", errors.Wrap(err, "Error parsing manifest list")
+	}
+	var wanted []util.ImagePlatform
+	if platform != "" {
+		p, err := util.ParseImagePlatform(platform)
+		if err != nil {
+			return "", err
+		}
+		wanted = append(wanted, *p)
+	} else {
+		// the variant of the node is detected when the platform is not set
+		wanted = append(wanted, util.ImagePlatform{})
+		if defaultPlatform != "" {
+			p, err := util.ParseImagePlatform(defaultPlatform)
+			if err != nil {
+				return "", err
+			}
+			wanted = append(wanted, *p)
+		}
+	}
+	for _, p := range wanted {
+		sys := &types.SystemContext{OSChoice: p.OS, ArchitectureChoice: p.Architecture, VariantChoice: p.Variant}
+		if instance, err := list.ChooseInstance(sys); err == nil {
+			klog.Infof("Importing the image %s of the manifest list for the platform %s", instance, describePlatform(p))
+			return instance, nil
+		}
+	}
+	names := make([]string, len(wanted))
+	for i, p := range wanted {
+		names[i] = describePlatform(p)
+	}
+	return "", errors.Errorf("The manifest list has no image for the platform %s, the available platforms are %s",
+		strings.Join(names, " nor "), strings.Join(listPlatforms(list), ", "))
+}
+
+// describePlatform returns the platform p, or the platform of the node when p is not set.
+func describePlatform(p util.ImagePlatform) string {
+	if p.OS == "" {
+		return util.ImagePlatform{OS: runtime.GOOS, Architecture: runtime.GOARCH}.String() + " of the node"
+	}
+	return p.String()
+}
+
+// listPlatforms returns the platforms of the images of a manifest list.
+func listPlatforms(list manifest.List) []string {
+	var platforms []string
+	switch l := list.(type) {
+	case *manifest.Schema2List:
+		for _, m := range l.Manifests {
+			platforms = append(platforms, util.ImagePlatform{OS: m.Platform.OS, Architecture: m.Platform.Architecture, Variant: m.Platform.Variant}.String())
+		}
+	case *manifest.OCI1Index:
+		for _, m := range l.Manifests {
+			if m.Platform != nil {
+				platforms = append(platforms, util.ImagePlatform{OS: m.Platform.OS, Architecture: m.Platform.Architecture, Variant: m.Platform.Variant}.String())
+			}
+		}
+	}
+	if len(platforms) == 0 {
+		return []string{"none"}
+	}
+	return platforms
+}
+
+// copyArtifactBlob streams the blob of an artifact to destFile, decompressed if needed. The bytes
+// downloaded are verified against the digest of the blob, destFile is removed when they do not match.
+func copyArtifactBlob(ctx context.Context, src types.ImageSource, blob types.BlobInfo, destFile string, cache types.BlobInfoCache) error {
+	if err := blob.Digest.Validate(); err != nil {
+		return errors.Wrapf(err, "Invalid digest of the artifact blob")
+	}
+	reader, _, err := src.GetBlob(ctx, blob, cache)
+	if err != nil {
+		klog.Errorf("Could not read artifact blob: %v", err)
+		return errors.Wrap(err, "Could not read artifact blob")
+	}
+	defer reader.Close()
+
+	verifier := blob.Digest.Verifier()
+	blobReader := io.TeeReader(reader, verifier)
+	fr, err := NewFormatReaders(io.NopCloser(blobReader), 0)
+	if err != nil {
+		return errors.Wrap(err, "Could not read artifact blob")
+	}
+	defer fr.Close()
+	if err := util.StreamDataToFile(fr.TopReader(), destFile); err != nil {
+		klog.Errorf("Error copying artifact blob: %v", err)
+		return errors.Wrap(err, "Error copying artifact blob")
+	}
+	// the decompressed data may end before the blob
+	if _, err := io.Copy(io.Discard, blobReader); err != nil {
+		return errors.Wrap(err, "Could not read artifact blob")
+	}
+	if !verifier.Verified() {
+		os.Remove(destFile)
+		return errors.Errorf("The artifact blob does not match its digest %s", blob.Digest)
+	}
+	return nil
+}
+
+func copyRegistryImage(url, destDir string, pathPrefixes []string, artifactMediaType, platform, defaultPlatform, accessKey, secKey, certDir string, insecureRegistry, stopAtFirst bool) error {
+	klog.Infof("Downloading image from '%v', copying file from '%v' to '%v'", url, strings.Join(pathPrefixes, "', '"), destDir)
 
 	ctx, cancel := commandTimeoutContext()
 	defer cancel()
@@ -185,34 +341,67 @@ func copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir stri
 	}
 	defer closeImage(src)
 
-	imgCloser, err := image.FromSource(ctx, srcCtx, src)
+	// the manifest is verified against the digest of the image name, when it is pinned by digest
+	unparsed := image.UnparsedInstance(src, nil)
+	manifestBlob, mimeType, err := unparsed.Manifest(ctx)
+	if err != nil {
+		klog.Errorf("Error retrieving image manifest: %v", err)
+		return errors.Wrap(err, "Error retrieving image manifest")
+	}
+	if manifest.MIMETypeIsMultiImage(mimeType) {
+		instance, err := chooseListInstance(manifestBlob, mimeType, platform, defaultPlatform)
+		if err != nil {
+			klog.Errorf("%v", err)
+			return err
+		}
+		// the manifest of the image is verified against its digest in the list
+		unparsed = image.UnparsedInstance(src, &instance)
+		manifestBlob, _, err = unparsed.Manifest(ctx)
+		if err != nil {
+			klog.Errorf("Error retrieving image manifest: %v", err)
+			return errors.Wrap(err, "Error retrieving image manifest")
+		}
+	}
+
+	cache := blobinfocache.DefaultCache(srcCtx)
+	if blob, ok := findArtifactBlob(manifestBlob, artifactMediaType); ok {
+		klog.Infof("Processing artifact blob %+v", blob)
+		return copyArtifactBlob(ctx, src, blob, filepath.Join(destDir, containerDiskImageFile), cache)
+	}
+
+	img, err := image.FromUnparsedImage(ctx, srcCtx, unparsed)
 	if err != nil {
 		klog.Errorf("Error retrieving image: %v", err)
 		return errors.Wrap(err, "Error retrieving image")
 	}
-	defer imgCloser.Close()
 
-	cache := blobinfocache.DefaultCache(srcCtx)
 	found := false
-	layers := imgCloser.LayerInfos()
+	layers := img.LayerInfos()
+	var layerErr error
 
 	for _, layer := range layers {
 		klog.Infof("Processing layer %+v", layer)
 
-		found, err = processLayer(ctx, srcCtx, src, layer, destDir, pathPrefix, cache, stopAtFirst)
+		found, err = processLayer(ctx, srcCtx, src, layer, destDir, pathPrefixes, cache, stopAtFirst)
 		if found {
 			break
 		}
 		if err != nil {
 			// Skipping layer and trying the next one.
 			// Error already logged in processLayer
+			layerErr = err
 			continue
 		}
 	}
 
 	if !found {
-		klog.Errorf("Failed to find VM disk image file in the container image")
-		return errors.New("Failed to find VM disk image file in the container image")
+		err := errors.Errorf("Failed to find VM disk image file in the container image, no file matches '%s' in its %d layers",
+			strings.Join(pathPrefixes, "' or '"), len(layers))
+		if layerErr != nil {
+			err = errors.Errorf("%v, the last unreadable layer failed with: %v", err, layerErr)
+		}
+		klog.Errorf("%v", err)
+		return err
 	}
 
 	return nil
@@ -259,7 +448,7 @@ func GetImageDigest(url, accessKey, secKey, certDir string, insecureRegistry boo
 // certDir: directory public CA keys are stored for registry identity verification
 // insecureRegistry: boolean if true will allow insecure registries.
 func CopyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir string, insecureRegistry bool) error {
-	return copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir, insecureRegistry, true)
+	return copyRegistryImage(url, destDir, []string{pathPrefix}, "", "", "", accessKey, secKey, certDir, insecureRegistry, true)
 }
 
 // CopyRegistryImageAll download image from registry with docker image API. It will extract all files under the pathPrefix
@@ -271,5 +460,5 @@ func CopyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir stri
 // certDir: directory public CA keys are stored for registry identity verification
 // insecureRegistry: boolean if true will allow insecure registries.
 func CopyRegistryImageAll(url, destDir, pathPrefix, accessKey, secKey, certDir string, insecureRegistry bool) error {
-	return copyRegistryImage(url, destDir, pathPrefix, accessKey, secKey, certDir, insecureRegistry, false)
+	return copyRegistryImage(url, destDir, []string{pathPrefix}, "", "", "", accessKey, secKey, certDir, insecureRegistry, false)
 }
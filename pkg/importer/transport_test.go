@@ -16,11 +16,17 @@ limitations under the License.
 package importer
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 
+	"github.com/containers/image/v5/manifest"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 var _ = Describe("Registry Importer", func() {
@@ -68,3 +74,83 @@ var _ = Describe("Registry Importer", func() {
 		Expect(err).To(HaveOccurred())
 	})
 })
+
+var _ = Describe("Manifest list", func() {
+	node := runtime.GOOS + "/" + runtime.GOARCH
+
+	// platformDigest returns a fake digest of the image of platform
+	platformDigest := func(platform string) digest.Digest {
+		return digest.FromString(platform)
+	}
+
+	schema2List := func(platforms ...string) []byte {
+		list := manifest.Schema2List{SchemaVersion: 2, MediaType: manifest.DockerV2ListMediaType}
+		for _, platform := range platforms {
+			parts := strings.Split(platform, "/")
+			m := manifest.Schema2ManifestDescriptor{}
+			m.MediaType = manifest.DockerV2Schema2MediaType
+			m.Digest = platformDigest(platform)
+			m.Platform.OS = parts[0]
+			m.Platform.Architecture = parts[1]
+			if len(parts) > 2 {
+				m.Platform.Variant = parts[2]
+			}
+			list.Manifests = append(list.Manifests, m)
+		}
+		blob, err := json.Marshal(list)
+		Expect(err).ToNot(HaveOccurred())
+		return blob
+	}
+
+	ociIndex := func(platforms ...string) []byte {
+		index := imgspecv1.Index{MediaType: imgspecv1.MediaTypeImageIndex}
+		index.SchemaVersion = 2
+		for _, platform := range platforms {
+			parts := strings.Split(platform, "/")
+			m := imgspecv1.Descriptor{MediaType: imgspecv1.MediaTypeImageManifest, Digest: platformDigest(platform)}
+			m.Platform = &imgspecv1.Platform{OS: parts[0], Architecture: parts[1]}
+			if len(parts) > 2 {
+				m.Platform.Variant = parts[2]
+			}
+			index.Manifests = append(index.Manifests, m)
+		}
+		blob, err := json.Marshal(index)
+		Expect(err).ToNot(HaveOccurred())
+		return blob
+	}
+
+	It("Should choose the image of the node", func() {
+		instance, err := chooseListInstance(schema2List("linux/s390x", node), manifest.DockerV2ListMediaType, "", "linux/s390x")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instance).To(Equal(platformDigest(node)))
+	})
+
+	It("Should choose the image of the platform over the image of the node", func() {
+		instance, err := chooseListInstance(ociIndex(node, "linux/arm64/v8"), imgspecv1.MediaTypeImageIndex, "linux/arm64/v8", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instance).To(Equal(platformDigest("linux/arm64/v8")))
+	})
+
+	It("Should choose the image of the default platform when the list has no image for the node", func() {
+		instance, err := chooseListInstance(ociIndex("plan9/s390x", "plan9/ppc64le"), imgspecv1.MediaTypeImageIndex, "", "plan9/ppc64le")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instance).To(Equal(platformDigest("plan9/ppc64le")))
+	})
+
+	It("Should list the available platforms when the list has no image for the platform", func() {
+		_, err := chooseListInstance(schema2List(node, "windows/arm64"), manifest.DockerV2ListMediaType, "plan9/s390x", "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("no image for the platform plan9/s390x"))
+		Expect(err.Error()).To(ContainSubstring("the available platforms are " + node + ", windows/arm64"))
+
+		_, err = chooseListInstance(schema2List("plan9/s390x"), manifest.DockerV2ListMediaType, "", "windows/arm64")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("no image for the platform " + node + " of the node nor windows/arm64"))
+		Expect(err.Error()).To(ContainSubstring("the available platforms are plan9/s390x"))
+	})
+
+	It("Should reject an invalid platform", func() {
+		_, err := chooseListInstance(schema2List(node), manifest.DockerV2ListMediaType, "amd64", "")
+		Expect(err).To(HaveOccurred())
+	})
+})
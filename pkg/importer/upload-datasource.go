@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"context"
 	"io"
 	"net/url"
 	"path/filepath"
@@ -21,12 +22,14 @@ import (
 type UploadDataSource struct {
 	// Data strean
 	stream io.ReadCloser
-	// stack of readers
-	readers *FormatReaders
+	// stack of readers, created by Info
+	sourceReaders
 	// url to a file in scratch space.
 	url *url.URL
 	// contentType expected from the upload content
 	contentType cdiv1.DataVolumeContentType
+	// the readers stop once ctx is done
+	ctx context.Context
 }
 
 // NewUploadDataSource creates a new instance of an UploadDataSource
@@ -34,6 +37,7 @@ func NewUploadDataSource(stream io.ReadCloser, contentType cdiv1.DataVolumeConte
 	return &UploadDataSource{
 		stream:      stream,
 		contentType: contentType,
+		ctx:         context.Background(),
 	}
 }
 
@@ -41,7 +45,7 @@ func NewUploadDataSource(stream io.ReadCloser, contentType cdiv1.DataVolumeConte
 func (ud *UploadDataSource) Info() (ProcessingPhase, error) {
 	var err error
 	// Hardcoded to only accept kubevirt content type.
-	ud.readers, err = NewFormatReaders(ud.stream, uint64(0))
+	ud.readers, err = NewFormatReadersContext(ud.ctx, ud.stream, uint64(0))
 	if err != nil {
 		klog.Errorf("Error creating readers: %v", err)
 		return ProcessingPhaseError, err
@@ -56,6 +60,11 @@ func (ud *UploadDataSource) Info() (ProcessingPhase, error) {
 	return ProcessingPhaseTransferScratch, nil
 }
 
+// SetContext stops the transfer once ctx is done.
+func (ud *UploadDataSource) SetContext(ctx context.Context) {
+	ud.ctx = ctx
+}
+
 // Transfer is called to transfer the data from the source to the passed in path.
 func (ud *UploadDataSource) Transfer(path string) (ProcessingPhase, error) {
 	if ud.contentType == cdiv1.DataVolumeKubeVirt {
@@ -68,7 +77,7 @@ func (ud *UploadDataSource) Transfer(path string) (ProcessingPhase, error) {
 			return ProcessingPhaseError, ErrInvalidPath
 		}
 		file := filepath.Join(path, tempFile)
-		err = util.StreamDataToFile(ud.readers.TopReader(), file)
+		err = ud.readers.StreamToFile(file)
 		if err != nil {
 			return ProcessingPhaseError, err
 		}
@@ -87,7 +96,7 @@ func (ud *UploadDataSource) Transfer(path string) (ProcessingPhase, error) {
 
 // TransferFile is called to transfer the data from the source to the passed in file.
 func (ud *UploadDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
-	err := util.StreamDataToFile(ud.readers.TopReader(), fileName)
+	err := ud.readers.StreamToFile(fileName)
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
@@ -122,6 +131,7 @@ func NewAsyncUploadDataSource(stream io.ReadCloser) *AsyncUploadDataSource {
 	return &AsyncUploadDataSource{
 		uploadDataSource: UploadDataSource{
 			stream: stream,
+			ctx:    context.Background(),
 		},
 		ResumePhase: ProcessingPhaseInfo,
 	}
@@ -132,6 +142,42 @@ func (aud *AsyncUploadDataSource) Info() (ProcessingPhase, error) {
 	return aud.uploadDataSource.Info()
 }
 
+// SetMaxDecompressedSize limits the size of the decompressed data.
+func (aud *AsyncUploadDataSource) SetMaxDecompressedSize(max int64) {
+	aud.uploadDataSource.SetMaxDecompressedSize(max)
+}
+
+// Digests returns the digests of the data read from the source and of the data transferred.
+func (aud *AsyncUploadDataSource) Digests() Digests {
+	return aud.uploadDataSource.Digests()
+}
+
+// PayloadDigest returns the digest of the data transferred followed by zeros up to size bytes.
+func (aud *AsyncUploadDataSource) PayloadDigest(size int64) string {
+	return aud.uploadDataSource.PayloadDigest(size)
+}
+
+// SourceFormat returns the format of the disk image of the source, iso for an ISO9660 image.
+func (aud *AsyncUploadDataSource) SourceFormat() string {
+	return aud.uploadDataSource.SourceFormat()
+}
+
+// ConvertFormat returns the format of the data passed to qemu-img, empty when it is probed.
+func (aud *AsyncUploadDataSource) ConvertFormat() string {
+	return aud.uploadDataSource.ConvertFormat()
+}
+
+// FlattenChain returns true if the backing chain of a qcow2 image extracted from an archive is
+// extracted, and flattened, along with it.
+func (aud *AsyncUploadDataSource) FlattenChain() bool {
+	return aud.uploadDataSource.FlattenChain()
+}
+
+// SetContext stops the transfer once ctx is done.
+func (aud *AsyncUploadDataSource) SetContext(ctx context.Context) {
+	aud.uploadDataSource.SetContext(ctx)
+}
+
 // Transfer is called to transfer the data from the source to the passed in path.
 func (aud *AsyncUploadDataSource) Transfer(path string) (ProcessingPhase, error) {
 	size, err := util.GetAvailableSpace(path)
@@ -143,7 +189,7 @@ func (aud *AsyncUploadDataSource) Transfer(path string) (ProcessingPhase, error)
 		return ProcessingPhaseError, ErrInvalidPath
 	}
 	file := filepath.Join(path, tempFile)
-	err = util.StreamDataToFile(aud.uploadDataSource.readers.TopReader(), file)
+	err = aud.uploadDataSource.readers.StreamToFile(file)
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
@@ -155,7 +201,7 @@ func (aud *AsyncUploadDataSource) Transfer(path string) (ProcessingPhase, error)
 
 // TransferFile is called to transfer the data from the source to the passed in file.
 func (aud *AsyncUploadDataSource) TransferFile(fileName string) (ProcessingPhase, error) {
-	err := util.StreamDataToFile(aud.uploadDataSource.readers.TopReader(), fileName)
+	err := aud.uploadDataSource.readers.StreamToFile(fileName)
 	if err != nil {
 		return ProcessingPhaseError, err
 	}
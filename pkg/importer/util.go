@@ -45,6 +45,27 @@ func CleanDir(dest string) error {
 	return nil
 }
 
+// cleanScratchSpace removes all files in the scratch space dest but the data of an http source
+// downloaded by a failed attempt of the import, whose download is resumed by the next attempt.
+func cleanScratchSpace(dest string) error {
+	dir, err := os.ReadDir(dest)
+	if err != nil {
+		klog.Errorf("Unable read directory to clean: %s, %v", dest, err)
+		return err
+	}
+	for _, d := range dir {
+		if d.Name() == downloadFile || d.Name() == downloadStateFile {
+			continue
+		}
+		klog.V(1).Infoln("deleting file: " + filepath.Join(dest, d.Name()))
+		if err := os.RemoveAll(filepath.Join(dest, d.Name())); err != nil {
+			klog.Errorf("Unable to delete file: %s, %v", filepath.Join(dest, d.Name()), err)
+			return err
+		}
+	}
+	return nil
+}
+
 // GetTerminationChannel returns a channel that listens for SIGTERM
 func GetTerminationChannel() <-chan os.Signal {
 	terminationChannel := make(chan os.Signal, 1)
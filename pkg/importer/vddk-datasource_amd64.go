@@ -31,6 +31,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -42,6 +43,7 @@ import (
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
 	"github.com/vmware/govmomi/vim25/types"
 	"golang.org/x/sys/unix"
 	v1 "k8s.io/api/core/v1"
@@ -60,6 +62,29 @@ var newVMwareClient = createVMwareClient
 var newNbdKitWrapper = createNbdKitWrapper
 var newNbdKitLogWatcher = createNbdKitLogWatcher
 
+var (
+	// vddkConnectAttempts is the number of attempts to log in to vCenter/ESXi, and to open the disk
+	// with nbdkit, failing with a connection error
+	vddkConnectAttempts = 3
+	// vddkConnectBackoff is the wait before the first retry, doubled before each of the next ones
+	vddkConnectBackoff = 5 * time.Second
+)
+
+// retryVddkConnection calls connect up to vddkConnectAttempts times while it fails with an error
+// that may be transient.
+func retryVddkConnection(what string, connect func() error, transient func(error) bool) error {
+	backoff := vddkConnectBackoff
+	for attempt := 1; ; attempt++ {
+		err := connect()
+		if err == nil || attempt == vddkConnectAttempts || !transient(err) {
+			return err
+		}
+		klog.Warningf("Unable to %s, retrying in %v: %v", what, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
 /* Section: nbdkit */
 
 const (
@@ -115,6 +140,7 @@ func createNbdKitWrapper(vmware *VMwareClient, diskFileName string) (*NbdKitWrap
 	err = handle.ConnectUri("nbd+unix://?socket=" + nbdUnixSocket)
 	if err != nil {
 		klog.Errorf("Unable to connect to socket %s: %v", socket, err)
+		handle.Close()
 		n.KillNbdkit()
 		return nil, err
 	}
@@ -249,8 +275,8 @@ type VMwareClient struct {
 	vm         VMwareVMOperations // *object.VirtualMachine
 }
 
-// createVMwareClient creates a govmomi handle and finds the VM with the given UUID
-func createVMwareClient(endpoint string, accessKey string, secKey string, thumbprint string, uuid string) (*VMwareClient, error) {
+// createVMwareClient creates a govmomi handle and finds the VM with the given MOref, or with the given UUID
+func createVMwareClient(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, moref string) (*VMwareClient, error) {
 	vmwURL, err := url.Parse(endpoint)
 	if err != nil {
 		klog.Errorf("Unable to parse endpoint: %v", endpoint)
@@ -260,18 +286,29 @@ func createVMwareClient(endpoint string, accessKey string, secKey string, thumbp
 	vmwURL.User = url.UserPassword(accessKey, secKey)
 	vmwURL.Path = "sdk"
 
-	// Log in to vCenter
+	// Log in to vCenter, retrying unless the login is rejected
 	ctx, cancel := context.WithCancel(context.Background())
-	conn, err := govmomi.NewClient(ctx, vmwURL, true)
+	var conn *govmomi.Client
+	err = retryVddkConnection("connect to vCenter", func() error {
+		conn, err = govmomi.NewClient(ctx, vmwURL, true)
+		return err
+	}, func(err error) bool {
+		return !soap.IsSoapFault(err) && !soap.IsVimFault(err)
+	})
 	if err != nil {
 		klog.Errorf("Unable to connect to vCenter: %v", err)
 		cancel()
 		return nil, err
 	}
 
-	moref, vm, err := FindVM(ctx, conn, uuid)
+	var vm *object.VirtualMachine
+	if moref != "" {
+		vm, err = FindVMByMoref(ctx, conn, moref)
+	} else {
+		moref, vm, err = FindVM(ctx, conn, uuid)
+	}
 	if err != nil {
-		klog.Errorf("Unable to find MORef for VM with UUID %s!", uuid)
+		klog.Errorf("Unable to find VM with MORef %q or UUID %q!", moref, uuid)
 		cancel()
 		return nil, err
 	}
@@ -438,6 +475,18 @@ func (vmware *VMwareClient) FindSnapshotDiskName(snapshotRef *types.ManagedObjec
 	return "", fmt.Errorf("Could not find disk image with ID %s in snapshot %s", diskID, snapshotRef.Value)
 }
 
+// FindVMByMoref takes the MOref of the VM to migrate, such as vm-1234, and checks that the VM exists
+func FindVMByMoref(context context.Context, conn *govmomi.Client, moref string) (*object.VirtualMachine, error) {
+	vm := object.NewVirtualMachine(conn.Client, types.ManagedObjectReference{Type: "VirtualMachine", Value: moref})
+	name, err := vm.ObjectName(context)
+	if err != nil {
+		klog.Errorf("Unable to find VM %s: %v", moref, err)
+		return nil, err
+	}
+	klog.Infof("VM %s found: %s", moref, name)
+	return vm, nil
+}
+
 // FindVM takes the UUID of the VM to migrate and finds its MOref
 func FindVM(context context.Context, conn *govmomi.Client, uuid string) (string, *object.VirtualMachine, error) {
 	// Get the list of datacenters to search for VM UUID
@@ -768,6 +817,8 @@ type VDDKDataSource struct {
 	PreviousSnapshot string
 	Size             uint64
 	VolumeMode       v1.PersistentVolumeMode
+	closeOnce        sync.Once
+	closeErr         error
 }
 
 func init() {
@@ -784,11 +835,11 @@ func init() {
 }
 
 // NewVDDKDataSource creates a new instance of the vddk data provider.
-func NewVDDKDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, backingFile string, currentCheckpoint string, previousCheckpoint string, finalCheckpoint string, volumeMode v1.PersistentVolumeMode) (*VDDKDataSource, error) {
-	return newVddkDataSource(endpoint, accessKey, secKey, thumbprint, uuid, backingFile, currentCheckpoint, previousCheckpoint, finalCheckpoint, volumeMode)
+func NewVDDKDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, moref string, backingFile string, currentCheckpoint string, previousCheckpoint string, finalCheckpoint string, volumeMode v1.PersistentVolumeMode) (*VDDKDataSource, error) {
+	return newVddkDataSource(endpoint, accessKey, secKey, thumbprint, uuid, moref, backingFile, currentCheckpoint, previousCheckpoint, finalCheckpoint, volumeMode)
 }
 
-func createVddkDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, backingFile string, currentCheckpoint string, previousCheckpoint string, finalCheckpoint string, volumeMode v1.PersistentVolumeMode) (*VDDKDataSource, error) {
+func createVddkDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, moref string, backingFile string, currentCheckpoint string, previousCheckpoint string, finalCheckpoint string, volumeMode v1.PersistentVolumeMode) (*VDDKDataSource, error) {
 	klog.Infof("Creating VDDK data source: backingFile [%s], currentCheckpoint [%s], previousCheckpoint [%s], finalCheckpoint [%s]", backingFile, currentCheckpoint, previousCheckpoint, finalCheckpoint)
 
 	if currentCheckpoint == "" && previousCheckpoint != "" {
@@ -797,7 +848,7 @@ func createVddkDataSource(endpoint string, accessKey string, secKey string, thum
 	}
 
 	// Log in to VMware, and get everything needed up front
-	vmware, err := newVMwareClient(endpoint, accessKey, secKey, thumbprint, uuid)
+	vmware, err := newVMwareClient(endpoint, accessKey, secKey, thumbprint, uuid, moref)
 	if err != nil {
 		klog.Errorf("Unable to log in to VMware: %v", err)
 		return nil, err
@@ -869,7 +920,14 @@ func createVddkDataSource(endpoint string, accessKey string, secKey string, thum
 		}
 		klog.Infof("Set disk file name from current snapshot: %s", diskFileName)
 	}
-	nbdkit, err := newNbdKitWrapper(vmware, diskFileName)
+	// nbdkit is started again when VDDK fails to open the disk, a failed nbdkit is stopped
+	var nbdkit *NbdKitWrapper
+	err = retryVddkConnection("open the disk with nbdkit", func() error {
+		nbdkit, err = newNbdKitWrapper(vmware, diskFileName)
+		return err
+	}, func(error) bool {
+		return true
+	})
 	if err != nil {
 		klog.Errorf("Unable to start nbdkit: %v", err)
 		return nil, err
@@ -886,6 +944,8 @@ func createVddkDataSource(endpoint string, accessKey string, secKey string, thum
 		size, err = nbdkit.Handle.GetSize()
 		if err != nil {
 			klog.Errorf("Unable to get source disk size: %v", err)
+			nbdkit.Handle.Close()
+			nbdkit.n.KillNbdkit()
 			return nil, err
 		}
 	}
@@ -921,8 +981,16 @@ func (vs *VDDKDataSource) Info() (ProcessingPhase, error) {
 	return ProcessingPhaseTransferDataFile, nil
 }
 
-// Close closes any readers or other open resources.
+// Close closes any readers or other open resources. It stops nbdkit once, whether the import
+// completes or it is cancelled by the termination of the pod.
 func (vs *VDDKDataSource) Close() error {
+	vs.closeOnce.Do(func() {
+		vs.closeErr = vs.close()
+	})
+	return vs.closeErr
+}
+
+func (vs *VDDKDataSource) close() error {
 	if vddkVersion != "" || vddkHost != "" {
 		existingbytes, _ := os.ReadFile(common.PodTerminationMessageFile)
 		existing := string(existingbytes)
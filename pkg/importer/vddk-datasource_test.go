@@ -12,6 +12,9 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -49,7 +52,10 @@ func defaultMockNbdExport() mockNbdExport {
 var currentExport mockNbdExport
 
 var _ = Describe("VDDK data source", func() {
+	var connectBackoff time.Duration
+
 	BeforeEach(func() {
+		connectBackoff, vddkConnectBackoff = vddkConnectBackoff, time.Millisecond
 		mockSinkBuffer = bytes.Repeat([]byte{0x00}, 512)
 		newVddkDataSource = createMockVddkDataSource
 		newVddkDataSink = createMockVddkDataSink
@@ -62,19 +68,20 @@ var _ = Describe("VDDK data source", func() {
 
 	AfterEach(func() {
 		newVddkDataSource = createVddkDataSource
+		vddkConnectBackoff = connectBackoff
 	})
 
 	It("NewVDDKDataSource should fail when called with an invalid endpoint", func() {
 		newVddkDataSource = createVddkDataSource
 		newVMwareClient = createVMwareClient
-		_, err := NewVDDKDataSource("httpx://-------", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		_, err := NewVDDKDataSource("httpx://-------", "", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
 		Expect(err).To(HaveOccurred())
 	})
 
 	It("NewVDDKDataSource should not fail on credentials with special characters", func() {
 		newVddkDataSource = createVddkDataSource
 		newVMwareClient = createVMwareClient
-		_, err := NewVDDKDataSource("http://--------", "test#user@vsphere.local", "Test#password", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		_, err := NewVDDKDataSource("http://--------", "test#user@vsphere.local", "Test#password", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("no such host"))
 		Expect(err.Error()).ToNot(ContainSubstring("Test#password"))
@@ -82,15 +89,81 @@ var _ = Describe("VDDK data source", func() {
 	})
 
 	It("VDDK data source GetURL should pass through NBD socket information", func() {
-		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		socket := dp.GetURL()
 		path := socket.String()
 		Expect(path).To(Equal(socketPath))
 	})
 
+	It("VDDK data source should find the VM by its MOref rather than by its UUID", func() {
+		newVddkDataSource = createVddkDataSource
+		var vmUUID, vmMoref string
+		newVMwareClient = func(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, moref string) (*VMwareClient, error) {
+			vmUUID, vmMoref = uuid, moref
+			return createMockVMwareClient(endpoint, accessKey, secKey, thumbprint, uuid, moref)
+		}
+		_, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "", "vm-1234", "", "", "", "", v1.PersistentVolumeFilesystem)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(vmUUID).To(BeEmpty())
+		Expect(vmMoref).To(Equal("vm-1234"))
+	})
+
+	It("VDDK data source should start nbdkit again when VDDK fails to open the disk", func() {
+		newVddkDataSource = createVddkDataSource
+		attempts := 0
+		newNbdKitWrapper = func(vmware *VMwareClient, fileName string) (*NbdKitWrapper, error) {
+			attempts++
+			if attempts < vddkConnectAttempts {
+				return nil, errors.New("VixDiskLib_Open failed")
+			}
+			return createMockNbdKitWrapper(vmware, fileName)
+		}
+		_, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(Equal(vddkConnectAttempts))
+
+		By("Failing once every attempt failed")
+		attempts = -vddkConnectAttempts
+		_, err = NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		Expect(err).To(MatchError("VixDiskLib_Open failed"))
+		Expect(attempts).To(Equal(0))
+	})
+
+	It("VDDK data source should stop nbdkit when it fails to get the size of the disk", func() {
+		newVddkDataSource = createVddkDataSource
+		nbdkit := &countingNbdkit{}
+		newNbdKitWrapper = func(vmware *VMwareClient, fileName string) (*NbdKitWrapper, error) {
+			wrapper, err := createMockNbdKitWrapper(vmware, fileName)
+			wrapper.n = nbdkit
+			return wrapper, err
+		}
+		currentExport.Size = func() (uint64, error) {
+			return 0, errors.New("get size failed")
+		}
+		_, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		Expect(err).To(HaveOccurred())
+		Expect(nbdkit.stops()).To(Equal(int32(1)))
+	})
+
+	It("VDDK data source should stop nbdkit once, on the termination of the pod and when it is closed", func() {
+		newVddkDataSource = createVddkDataSource
+		nbdkit := &countingNbdkit{}
+		newNbdKitWrapper = func(vmware *VMwareClient, fileName string) (*NbdKitWrapper, error) {
+			wrapper, err := createMockNbdKitWrapper(vmware, fileName)
+			wrapper.n = nbdkit
+			return wrapper, err
+		}
+		dp, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		Expect(err).ToNot(HaveOccurred())
+		mockTerminationChannel <- syscall.SIGTERM
+		Eventually(nbdkit.stops).Should(Equal(int32(1)))
+		Expect(dp.Close()).To(Succeed())
+		Expect(nbdkit.stops()).To(Equal(int32(1)))
+	})
+
 	It("VDDK data source should move to transfer data phase after Info", func() {
-		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		phase, err := dp.Info()
 		Expect(err).ToNot(HaveOccurred())
@@ -106,7 +179,7 @@ var _ = Describe("VDDK data source", func() {
 			return bytes.Repeat([]byte{0x55}, 512), nil
 		}
 		currentExport = replaceExport
-		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		phase, err := dp.Info()
 		Expect(err).ToNot(HaveOccurred())
@@ -118,7 +191,7 @@ var _ = Describe("VDDK data source", func() {
 
 	It("VDDK data source should fail if TransferFile fails", func() {
 		newVddkDataSink = createVddkDataSink
-		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		phase, err := dp.Info()
 		Expect(err).ToNot(HaveOccurred())
@@ -129,19 +202,19 @@ var _ = Describe("VDDK data source", func() {
 	})
 
 	It("VDDK data source should know if it is a delta copy", func() {
-		dp, err := NewVDDKDataSource("", "", "", "", "", "", "checkpoint-1", "checkpoint-2", "", v1.PersistentVolumeFilesystem)
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "checkpoint-1", "checkpoint-2", "", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(dp.IsDeltaCopy()).To(Equal(true))
 	})
 
 	It("VDDK data source should know if it is not a delta copy", func() {
-		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(dp.IsDeltaCopy()).To(Equal(false))
 	})
 
 	It("VDDK delta copy should return immediately if there are no changed blocks", func() {
-		dp, err := NewVDDKDataSource("", "", "", "", "", "", "checkpoint-1", "checkpoint-2", "", v1.PersistentVolumeFilesystem)
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "checkpoint-1", "checkpoint-2", "", v1.PersistentVolumeFilesystem)
 		dp.ChangedBlocks = &types.DiskChangeInfo{
 			StartOffset: 0,
 			Length:      0,
@@ -153,7 +226,7 @@ var _ = Describe("VDDK data source", func() {
 	})
 
 	It("VDDK full copy should successfully copy the same bytes passed in", func() {
-		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
+		dp, err := NewVDDKDataSource("", "", "", "", "", "", "", "", "", "", v1.PersistentVolumeFilesystem)
 		dp.Size = 40 << 20
 		sourceBytes := bytes.Repeat([]byte{0x55}, int(dp.Size))
 		replaceExport := currentExport
@@ -179,7 +252,7 @@ var _ = Describe("VDDK data source", func() {
 	It("VDDK delta copy should sucessfully apply a delta to a base disk image", func() {
 
 		// Copy base disk ("snapshot 1")
-		snap1, err := NewVDDKDataSource("", "", "", "", "", "", "checkpoint-1", "", "", v1.PersistentVolumeFilesystem)
+		snap1, err := NewVDDKDataSource("", "", "", "", "", "", "", "checkpoint-1", "", "", v1.PersistentVolumeFilesystem)
 		snap1.Size = 40 << 20
 		sourceBytes := bytes.Repeat([]byte{0x55}, int(snap1.Size))
 		replaceExport := currentExport
@@ -202,7 +275,7 @@ var _ = Describe("VDDK data source", func() {
 		Expect(sourceSum).To(Equal(destSum))
 
 		// Write some data to the first snapshot, then copy the delta from difference between the two snapshots
-		snap2, err := NewVDDKDataSource("", "", "", "", "", "", "checkpoint-1", "checkpoint-2", "", v1.PersistentVolumeFilesystem)
+		snap2, err := NewVDDKDataSource("", "", "", "", "", "", "", "checkpoint-1", "checkpoint-2", "", v1.PersistentVolumeFilesystem)
 		snap2.Size = 40 << 20
 		copy(sourceBytes[1024:2048], bytes.Repeat([]byte{0xAA}, 1024))
 		snap2.ChangedBlocks = &types.DiskChangeInfo{
@@ -263,7 +336,7 @@ var _ = Describe("VDDK data source", func() {
 			}, nil
 		}
 
-		ds, err := NewVDDKDataSource("", "", "", "", "", diskName, snapshotName, changeID, "", v1.PersistentVolumeFilesystem)
+		ds, err := NewVDDKDataSource("", "", "", "", "", "", diskName, snapshotName, changeID, "", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(ds.ChangedBlocks).To(Equal(&changeInfo))
 	})
@@ -293,7 +366,7 @@ var _ = Describe("VDDK data source", func() {
 			return nil
 		}
 
-		_, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", targetDiskName, "", "", "", v1.PersistentVolumeFilesystem)
+		_, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", targetDiskName, "", "", "", v1.PersistentVolumeFilesystem)
 		if expectedSuccess {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(returnedDiskName).To(Equal(targetDiskName))
@@ -360,7 +433,7 @@ var _ = Describe("VDDK data source", func() {
 		}
 
 		// Expect source.ChangedBlocks to equal local changed blocks
-		source, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", diskName, "snapshot-1", "snapshot-2", "false", v1.PersistentVolumeFilesystem)
+		source, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", diskName, "snapshot-1", "snapshot-2", "false", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(changedBlockList.StartOffset).To(Equal(source.ChangedBlocks.StartOffset))
 		Expect(changedBlockList.Length).To(Equal(source.ChangedBlocks.Length))
@@ -385,7 +458,7 @@ var _ = Describe("VDDK data source", func() {
 			return nil
 		}
 
-		_, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", diskName, "", "", "false", v1.PersistentVolumeFilesystem)
+		_, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", diskName, "", "", "false", v1.PersistentVolumeFilesystem)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("disk 'testdisk.vmdk' is not present in VM hardware config or snapshot list"))
 	})
@@ -403,7 +476,7 @@ var _ = Describe("VDDK data source", func() {
 			}
 			return nil
 		}
-		_, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", diskName, "snapshot-1", "snapshot-2", "false", v1.PersistentVolumeFilesystem)
+		_, err := NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", diskName, "snapshot-1", "snapshot-2", "false", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		mockTerminationChannel <- os.Interrupt
 		Expect(err).ToNot(HaveOccurred())
@@ -422,10 +495,10 @@ var _ = Describe("VDDK data source", func() {
 			}
 			return nil
 		}
-		_, err := NewVDDKDataSource("http://esx.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", diskName, "", "", "", v1.PersistentVolumeFilesystem)
+		_, err := NewVDDKDataSource("http://esx.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", diskName, "", "", "", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(MaxPreadLength).To(Equal(uint32(MaxPreadLengthESX)))
-		_, err = NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", diskName, "", "", "", v1.PersistentVolumeFilesystem)
+		_, err = NewVDDKDataSource("http://vcenter.test", "user", "pass", "aa:bb:cc:dd", "1-2-3-4", "", diskName, "", "", "", v1.PersistentVolumeFilesystem)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(MaxPreadLength).To(Equal(uint32(MaxPreadLengthVC)))
 	})
@@ -464,6 +537,28 @@ var _ = Describe("VDDK log watcher", func() {
 	)
 })
 
+// countingNbdkit counts the times nbdkit is stopped.
+type countingNbdkit struct {
+	killed int32
+}
+
+func (n *countingNbdkit) StartNbdkit(source string) error {
+	return nil
+}
+
+func (n *countingNbdkit) KillNbdkit() error {
+	atomic.AddInt32(&n.killed, 1)
+	return nil
+}
+
+func (n *countingNbdkit) AddEnvVariable(v string) {}
+
+func (n *countingNbdkit) AddFilter(filter image.NbdkitFilter) {}
+
+func (n *countingNbdkit) stops() int32 {
+	return atomic.LoadInt32(&n.killed)
+}
+
 type mockNbdOperations struct{}
 
 func (handle *mockNbdOperations) GetSize() (uint64, error) {
@@ -487,7 +582,7 @@ func (handle *mockNbdOperations) BlockStatus(length uint64, offset uint64, callb
 	return nil
 }
 
-func createMockVddkDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, backingFile string, currentCheckpoint string, previousCheckpoint string, finalCheckpoint string, volumeMode v1.PersistentVolumeMode) (*VDDKDataSource, error) {
+func createMockVddkDataSource(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, moref string, backingFile string, currentCheckpoint string, previousCheckpoint string, finalCheckpoint string, volumeMode v1.PersistentVolumeMode) (*VDDKDataSource, error) {
 	socketURL, err := url.Parse(socketPath)
 	if err != nil {
 		return nil, err
@@ -608,7 +703,7 @@ func (ops *mockVMwareVMOperations) Client() *vim25.Client {
 	return currentVMwareFunctions.Client()
 }
 
-func createMockVMwareClient(endpoint string, accessKey string, secKey string, thumbprint string, uuid string) (*VMwareClient, error) {
+func createMockVMwareClient(endpoint string, accessKey string, secKey string, thumbprint string, uuid string, moref string) (*VMwareClient, error) {
 	ep, _ := url.Parse(endpoint)
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -82,6 +82,41 @@ func GetOrCreatePrivateKey(client kubernetes.Interface, namespace, secretName st
 	return parsePrivateKey(bytes)
 }
 
+// GetPublicKeys gets the public keys of a private key secret, the public key of the private key followed by the public
+// keys of the previous private keys of a key rotation, whose tokens are still accepted
+func GetPublicKeys(client kubernetes.Interface, namespace, secretName string) ([]*rsa.PublicKey, error) {
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting secret")
+	}
+
+	bytes, ok := secret.Data[KeyStorePublicKeyFile]
+	if !ok {
+		return nil, errors.New("Secret missing public key")
+	}
+
+	return DecodePublicKeys(bytes)
+}
+
+// DecodePublicKeys decodes the PEM encoded RSA public keys
+func DecodePublicKeys(bytes []byte) ([]*rsa.PublicKey, error) {
+	objs, err := cert.ParsePublicKeysPEM(bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing public keys")
+	}
+
+	var keys []*rsa.PublicKey
+	for _, obj := range objs {
+		key, ok := obj.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("PEM does not contain RSA keys")
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
 // newPrivateKeySecret returns a new private key secret
 func newPrivateKeySecret(client kubernetes.Interface, namespace, secretName string, privateKey *rsa.PrivateKey) (*v1.Secret, error) {
 	privateKeyBytes := cert.EncodePrivateKeyPEM(privateKey)
@@ -32,6 +32,7 @@ import (
 	core "k8s.io/client-go/testing"
 
 	"kubevirt.io/containerized-data-importer/pkg/keys/keystest"
+	"kubevirt.io/containerized-data-importer/pkg/util/cert"
 )
 
 func privateKeySecretCreateAction(namespace, secretName string, privateKey *rsa.PrivateKey) core.Action {
@@ -167,3 +168,34 @@ var _ = Describe("Create Private Key", func() {
 		}
 	})
 })
+
+var _ = Describe("Get Public Keys", func() {
+	namespace := "default"
+	secret := "mysecret"
+
+	It("Should get the public key of the private key, and the public keys of the previous private keys", func() {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		previousKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+
+		privateKeySecret, err := keystest.NewPrivateKeySecret(namespace, secret, privateKey)
+		Expect(err).NotTo(HaveOccurred())
+		previousPublicKey, err := cert.EncodePublicKeyPEM(&previousKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		privateKeySecret.Data[KeyStorePublicKeyFile] = append(privateKeySecret.Data[KeyStorePublicKeyFile], previousPublicKey...)
+
+		client := k8sfake.NewSimpleClientset(privateKeySecret)
+
+		publicKeys, err := GetPublicKeys(client, namespace, secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publicKeys).To(HaveLen(2))
+		Expect(publicKeys[0].Equal(&privateKey.PublicKey)).To(BeTrue())
+		Expect(publicKeys[1].Equal(&previousKey.PublicKey)).To(BeTrue())
+	})
+
+	It("Should fail without public key", func() {
+		_, err := DecodePublicKeys([]byte("not a key"))
+		Expect(err).To(HaveOccurred())
+	})
+})
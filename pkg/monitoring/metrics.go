@@ -29,6 +29,19 @@ const (
 	IncompleteProfile      MetricsKey = "incompleteProfile"
 	DataImportCronOutdated MetricsKey = "dataImportCronOutdated"
 	CloneProgress          MetricsKey = "cloneProgress"
+	ImportBandwidthLimit   MetricsKey = "importBandwidthLimit"
+	ImportTransferred      MetricsKey = "importTransferred"
+	ImportSourceSize       MetricsKey = "importSourceSize"
+	ImportsStarted         MetricsKey = "importsStarted"
+	ImportsSucceeded       MetricsKey = "importsSucceeded"
+	ImportsFailed          MetricsKey = "importsFailed"
+	ImportsInFlight        MetricsKey = "importsInFlight"
+	ImportMilestone        MetricsKey = "importMilestone"
+	ImportDuration         MetricsKey = "importDuration"
+	ImportedBytes          MetricsKey = "importedBytes"
+	CloneDuration          MetricsKey = "cloneDuration"
+	ScratchSpaceAllocated  MetricsKey = "scratchSpaceAllocated"
+	WebhookRejections      MetricsKey = "webhookRejections"
 )
 
 // MetricOptsList list all CDI metrics
@@ -38,11 +51,66 @@ var MetricOptsList = map[MetricsKey]MetricOpts{
 		Help: "The clone progress in percentage",
 		Type: "Counter",
 	},
+	CloneDuration: {
+		Name: "kubevirt_cdi_clone_duration_seconds",
+		Help: "The duration of the clones of DataVolumes which succeeded, from the creation of the DataVolume, by clone type",
+		Type: "Histogram",
+	},
 	DataImportCronOutdated: {
 		Name: "kubevirt_cdi_dataimportcron_outdated",
 		Help: "DataImportCron has an outdated import",
 		Type: "Gauge",
 	},
+	ImportBandwidthLimit: {
+		Name: "kubevirt_cdi_import_bandwidth_limit_bytes",
+		Help: "The limit of the rate at which an import reads the data of its source, in bytes per second",
+		Type: "Gauge",
+	},
+	ImportDuration: {
+		Name: "kubevirt_cdi_import_duration_seconds",
+		Help: "The duration of the imports which succeeded, from the creation of the PVC, by source type",
+		Type: "Histogram",
+	},
+	ImportedBytes: {
+		Name: "kubevirt_cdi_imported_bytes_total",
+		Help: "The bytes of the disk images written by the imports which succeeded, by source type",
+		Type: "Counter",
+	},
+	ImportsFailed: {
+		Name: "kubevirt_cdi_imports_failed_total",
+		Help: "The number of imports which failed and are not retried, by source type",
+		Type: "Counter",
+	},
+	ImportsInFlight: {
+		Name: "kubevirt_cdi_imports_in_flight",
+		Help: "The number of imports in progress",
+		Type: "Gauge",
+	},
+	ImportsStarted: {
+		Name: "kubevirt_cdi_imports_started_total",
+		Help: "The number of imports started, by source type",
+		Type: "Counter",
+	},
+	ImportsSucceeded: {
+		Name: "kubevirt_cdi_imports_succeeded_total",
+		Help: "The number of imports which succeeded, by source type",
+		Type: "Counter",
+	},
+	ImportMilestone: {
+		Name: "kubevirt_cdi_import_milestone",
+		Help: "The milestones reached by an import, relayed as events by the controller",
+		Type: "Gauge",
+	},
+	ImportSourceSize: {
+		Name: "kubevirt_cdi_import_source_size_bytes",
+		Help: "The size of the data of the source of an import, in bytes, when it is known",
+		Type: "Gauge",
+	},
+	ImportTransferred: {
+		Name: "kubevirt_cdi_import_transferred_bytes",
+		Help: "The bytes of the data of the source read by an import",
+		Type: "Gauge",
+	},
 	IncompleteProfile: {
 		Name: "kubevirt_cdi_incomplete_storageprofiles_total",
 		Help: "Total number of incomplete and hence unusable StorageProfile",
@@ -53,6 +121,16 @@ var MetricOptsList = map[MetricsKey]MetricOpts{
 		Help: "CDI CR Ready",
 		Type: "Gauge",
 	},
+	ScratchSpaceAllocated: {
+		Name: "kubevirt_cdi_scratch_space_allocated_bytes_total",
+		Help: "The bytes of storage requested by the scratch space PVCs created for imports and uploads",
+		Type: "Counter",
+	},
+	WebhookRejections: {
+		Name: "kubevirt_cdi_webhook_rejections_total",
+		Help: "The number of requests denied by the admission webhooks of the CDI API server, by resource and operation",
+		Type: "Counter",
+	},
 }
 
 // GetRecordRulesDesc returns CDI Prometheus Record Rules
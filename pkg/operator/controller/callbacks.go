@@ -38,7 +38,7 @@ import (
 
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	"kubevirt.io/containerized-data-importer/pkg/common"
-	cdicontroller "kubevirt.io/containerized-data-importer/pkg/controller"
+	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
 	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
 )
 
@@ -226,7 +226,7 @@ func reconcileSetConfigAuthority(args *callbacks.ReconcileCallbackArgs) error {
 		return nil
 	}
 
-	if _, ok = cdi.Annotations[cdicontroller.AnnConfigAuthority]; ok {
+	if _, ok = cdi.Annotations[cc.AnnConfigAuthority]; ok {
 		return nil
 	}
 
@@ -254,7 +254,7 @@ func reconcileSetConfigAuthority(args *callbacks.ReconcileCallbackArgs) error {
 	if cdi.Annotations == nil {
 		cdi.Annotations = map[string]string{}
 	}
-	cdi.Annotations[cdicontroller.AnnConfigAuthority] = ""
+	cdi.Annotations[cc.AnnConfigAuthority] = ""
 
 	return args.Client.Update(context.TODO(), cdi)
 }
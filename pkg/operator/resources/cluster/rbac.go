@@ -64,6 +64,7 @@ func getAdminPolicyRules() []rbacv1.PolicyRule {
 			},
 			Resources: []string{
 				"uploadtokenrequests",
+				"clonetokenrequests",
 			},
 			Verbs: []string{
 				"*",
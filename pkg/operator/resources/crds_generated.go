@@ -92,9 +92,18 @@ spec:
                   dataVolumeTTLSeconds:
                     description: DataVolumeTTLSeconds is the time in seconds after
                       DataVolume completion it can be garbage collected. The default
-                      is 0 sec. To disable GC use -1.
+                      is -1, disabling GC. A DataVolume can override it with the cdi.kubevirt.io/storage.ttlSecondsAfterCompletion
+                      annotation.
                     format: int32
                     type: integer
+                  diskFormat:
+                    description: DiskFormat is the default format of the disk images
+                      written to filesystem volumes by imports, raw or qcow2. Defaults
+                      to raw.
+                    enum:
+                    - raw
+                    - qcow2
+                    type: string
                   featureGates:
                     description: FeatureGates are a list of specific enabled feature
                       gates
@@ -125,6 +134,16 @@ spec:
                           global value
                         type: object
                     type: object
+                  importBandwidthLimit:
+                    anyOf:
+                    - type: integer
+                    - type: string
+                    description: ImportBandwidthLimit is the default limit of the
+                      rate at which each import reads the data of its source, in bytes
+                      per second, overridden by the cdi.kubevirt.io/storage.import.bandwidthLimit
+                      annotation of the DataVolume. Not limited by default.
+                    pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                    x-kubernetes-int-or-string: true
                   importProxy:
                     description: ImportProxy contains importer pod proxy configuration.
                     properties:
@@ -156,11 +175,57 @@ spec:
                           ... -----END CERTIFICATE-----"
                         type: string
                     type: object
+                  importRetryPolicy:
+                    description: ImportRetryPolicy bounds the retries of the failed
+                      imports and the backoff between them, overridden by the annotations
+                      of the DataVolume. The kubelet restarts the failed importer
+                      pods without limit by default.
+                    properties:
+                      initialBackoff:
+                        description: InitialBackoff is the delay before the first
+                          retry of a failed import, doubled by each further retry.
+                          Defaults to 10s.
+                        type: string
+                      maxBackoff:
+                        description: MaxBackoff caps the delay before the retry of
+                          a failed import. Defaults to 5m.
+                        type: string
+                      maxRetries:
+                        description: MaxRetries is the number of times a failed import
+                          is retried before its DataVolume fails, 0 fails it on its
+                          first failure. Not limited by default.
+                        format: int32
+                        minimum: 0
+                        type: integer
+                    type: object
                   insecureRegistries:
                     description: InsecureRegistries is a list of TLS disabled registries
                     items:
                       type: string
                     type: array
+                  nfs:
+                    description: NFS configures the mounts of the NFS exports holding
+                      the files of the NFS sources
+                    properties:
+                      mountOptions:
+                        description: MountOptions are the options of the mounts of
+                          the NFS exports, such as vers, soft or hard. The exports
+                          are always mounted read-only. Defaults to soft,timeo=100,retrans=3.
+                        items:
+                          type: string
+                        type: array
+                      mountTimeout:
+                        description: MountTimeout is the time after which an import
+                          fails when its importer pod could not mount the NFS export.
+                          Defaults to 5m.
+                        type: string
+                    type: object
+                  podPriorityClassName:
+                    description: PodPriorityClassName is the default priority
+                      class of the importer, cloner and uploader pods, overridden
+                      by the priorityClassName of the DataVolume. The pods have no
+                      priority class by default.
+                    type: string
                   podResourceRequirements:
                     description: ResourceRequirements describes the compute resource
                       requirements.
@@ -192,6 +257,64 @@ spec:
                     description: Preallocation controls whether storage for DataVolumes
                       should be allocated in advance.
                     type: boolean
+                  qemuImg:
+                    description: QemuImg bounds the resources of the qemu-img subprocess
+                      of the importer pods
+                    properties:
+                      cacheMode:
+                        description: CacheMode is the cache mode of the disk image
+                          written by a conversion. Defaults to writeback, none bypasses
+                          the page cache of the importer pod.
+                        enum:
+                        - writeback
+                        - writethrough
+                        - none
+                        - directsync
+                        - unsafe
+                        type: string
+                      coroutines:
+                        description: Coroutines is the number of parallel coroutines
+                          of a conversion, each one holding a buffer of the data converted.
+                          Defaults to 8.
+                        format: int32
+                        maximum: 16
+                        minimum: 1
+                        type: integer
+                      memoryLimit:
+                        anyOf:
+                        - type: integer
+                        - type: string
+                        description: MemoryLimit is the limit of the address space
+                          of qemu-img, which fails rather than getting the importer
+                          pod killed once it is reached. Not limited by default.
+                        pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                        x-kubernetes-int-or-string: true
+                      niceness:
+                        description: Niceness is the niceness of qemu-img, from 0
+                          to 19, leaving CPU time to the transfer of the data. Defaults
+                          to 10.
+                        format: int32
+                        maximum: 19
+                        minimum: 0
+                        type: integer
+                    type: object
+                  registryPlatform:
+                    description: RegistryPlatform is the
+                      <os>/<architecture>[/<variant>] platform of the image
+                      imported from a manifest list of a registry when the list
+                      has no image for the platform of the node of the importer,
+                      such as linux/amd64. The
+                      cdi.kubevirt.io/storage.import.registryPlatform annotation
+                      of the DataVolume selects the platform instead.
+                    pattern: ^[^/\s]+/[^/\s]+(/[^/\s]+)?$
+                    type: string
+                  scratchSpaceSizeMultiplier:
+                    description: ScratchSpaceSizeMultiplier is the size of the scratch
+                      space relative to the size of the DataVolume, such as 1.5 or
+                      0.5, overridden by the cdi.kubevirt.io/storage.scratch.sizeMultiplier
+                      annotation of the DataVolume. Defaults to 1.
+                    pattern: ^\d+(\.\d{1,3})?$
+                    type: string
                   scratchSpaceStorageClass:
                     description: 'Override the storage class to used for scratch space
                       during transfer operations. The scratch space storage class
@@ -2308,9 +2431,18 @@ spec:
                   dataVolumeTTLSeconds:
                     description: DataVolumeTTLSeconds is the time in seconds after
                       DataVolume completion it can be garbage collected. The default
-                      is 0 sec. To disable GC use -1.
+                      is -1, disabling GC. A DataVolume can override it with the cdi.kubevirt.io/storage.ttlSecondsAfterCompletion
+                      annotation.
                     format: int32
                     type: integer
+                  diskFormat:
+                    description: DiskFormat is the default format of the disk images
+                      written to filesystem volumes by imports, raw or qcow2. Defaults
+                      to raw.
+                    enum:
+                    - raw
+                    - qcow2
+                    type: string
                   featureGates:
                     description: FeatureGates are a list of specific enabled feature
                       gates
@@ -2341,6 +2473,16 @@ spec:
                           global value
                         type: object
                     type: object
+                  importBandwidthLimit:
+                    anyOf:
+                    - type: integer
+                    - type: string
+                    description: ImportBandwidthLimit is the default limit of the
+                      rate at which each import reads the data of its source, in bytes
+                      per second, overridden by the cdi.kubevirt.io/storage.import.bandwidthLimit
+                      annotation of the DataVolume. Not limited by default.
+                    pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                    x-kubernetes-int-or-string: true
                   importProxy:
                     description: ImportProxy contains importer pod proxy configuration.
                     properties:
@@ -2372,11 +2514,57 @@ spec:
                           ... -----END CERTIFICATE-----"
                         type: string
                     type: object
+                  importRetryPolicy:
+                    description: ImportRetryPolicy bounds the retries of the failed
+                      imports and the backoff between them, overridden by the annotations
+                      of the DataVolume. The kubelet restarts the failed importer
+                      pods without limit by default.
+                    properties:
+                      initialBackoff:
+                        description: InitialBackoff is the delay before the first
+                          retry of a failed import, doubled by each further retry.
+                          Defaults to 10s.
+                        type: string
+                      maxBackoff:
+                        description: MaxBackoff caps the delay before the retry of
+                          a failed import. Defaults to 5m.
+                        type: string
+                      maxRetries:
+                        description: MaxRetries is the number of times a failed import
+                          is retried before its DataVolume fails, 0 fails it on its
+                          first failure. Not limited by default.
+                        format: int32
+                        minimum: 0
+                        type: integer
+                    type: object
                   insecureRegistries:
                     description: InsecureRegistries is a list of TLS disabled registries
                     items:
                       type: string
                     type: array
+                  nfs:
+                    description: NFS configures the mounts of the NFS exports holding
+                      the files of the NFS sources
+                    properties:
+                      mountOptions:
+                        description: MountOptions are the options of the mounts of
+                          the NFS exports, such as vers, soft or hard. The exports
+                          are always mounted read-only. Defaults to soft,timeo=100,retrans=3.
+                        items:
+                          type: string
+                        type: array
+                      mountTimeout:
+                        description: MountTimeout is the time after which an import
+                          fails when its importer pod could not mount the NFS export.
+                          Defaults to 5m.
+                        type: string
+                    type: object
+                  podPriorityClassName:
+                    description: PodPriorityClassName is the default priority
+                      class of the importer, cloner and uploader pods, overridden
+                      by the priorityClassName of the DataVolume. The pods have no
+                      priority class by default.
+                    type: string
                   podResourceRequirements:
                     description: ResourceRequirements describes the compute resource
                       requirements.
@@ -2408,6 +2596,64 @@ spec:
                     description: Preallocation controls whether storage for DataVolumes
                       should be allocated in advance.
                     type: boolean
+                  qemuImg:
+                    description: QemuImg bounds the resources of the qemu-img subprocess
+                      of the importer pods
+                    properties:
+                      cacheMode:
+                        description: CacheMode is the cache mode of the disk image
+                          written by a conversion. Defaults to writeback, none bypasses
+                          the page cache of the importer pod.
+                        enum:
+                        - writeback
+                        - writethrough
+                        - none
+                        - directsync
+                        - unsafe
+                        type: string
+                      coroutines:
+                        description: Coroutines is the number of parallel coroutines
+                          of a conversion, each one holding a buffer of the data converted.
+                          Defaults to 8.
+                        format: int32
+                        maximum: 16
+                        minimum: 1
+                        type: integer
+                      memoryLimit:
+                        anyOf:
+                        - type: integer
+                        - type: string
+                        description: MemoryLimit is the limit of the address space
+                          of qemu-img, which fails rather than getting the importer
+                          pod killed once it is reached. Not limited by default.
+                        pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                        x-kubernetes-int-or-string: true
+                      niceness:
+                        description: Niceness is the niceness of qemu-img, from 0
+                          to 19, leaving CPU time to the transfer of the data. Defaults
+                          to 10.
+                        format: int32
+                        maximum: 19
+                        minimum: 0
+                        type: integer
+                    type: object
+                  registryPlatform:
+                    description: RegistryPlatform is the
+                      <os>/<architecture>[/<variant>] platform of the image
+                      imported from a manifest list of a registry when the list
+                      has no image for the platform of the node of the importer,
+                      such as linux/amd64. The
+                      cdi.kubevirt.io/storage.import.registryPlatform annotation
+                      of the DataVolume selects the platform instead.
+                    pattern: ^[^/\s]+/[^/\s]+(/[^/\s]+)?$
+                    type: string
+                  scratchSpaceSizeMultiplier:
+                    description: ScratchSpaceSizeMultiplier is the size of the scratch
+                      space relative to the size of the DataVolume, such as 1.5 or
+                      0.5, overridden by the cdi.kubevirt.io/storage.scratch.sizeMultiplier
+                      annotation of the DataVolume. Defaults to 1.
+                    pattern: ^\d+(\.\d{1,3})?$
+                    type: string
                   scratchSpaceStorageClass:
                     description: 'Override the storage class to used for scratch space
                       during transfer operations. The scratch space storage class
@@ -4498,10 +4744,18 @@ spec:
             properties:
               dataVolumeTTLSeconds:
                 description: DataVolumeTTLSeconds is the time in seconds after DataVolume
-                  completion it can be garbage collected. The default is 0 sec. To
-                  disable GC use -1.
+                  completion it can be garbage collected. The default is -1, disabling
+                  GC. A DataVolume can override it with the cdi.kubevirt.io/storage.ttlSecondsAfterCompletion
+                  annotation.
                 format: int32
                 type: integer
+              diskFormat:
+                description: DiskFormat is the default format of the disk images written
+                  to filesystem volumes by imports, raw or qcow2. Defaults to raw.
+                enum:
+                - raw
+                - qcow2
+                type: string
               featureGates:
                 description: FeatureGates are a list of specific enabled feature gates
                 items:
@@ -4531,6 +4785,16 @@ spec:
                       value
                     type: object
                 type: object
+              importBandwidthLimit:
+                anyOf:
+                - type: integer
+                - type: string
+                description: ImportBandwidthLimit is the default limit of the rate
+                  at which each import reads the data of its source, in bytes per
+                  second, overridden by the cdi.kubevirt.io/storage.import.bandwidthLimit
+                  annotation of the DataVolume. Not limited by default.
+                pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                x-kubernetes-int-or-string: true
               importProxy:
                 description: ImportProxy contains importer pod proxy configuration.
                 properties:
@@ -4561,11 +4825,57 @@ spec:
                       <base64 encoded cert> ... -----END CERTIFICATE-----"
                     type: string
                 type: object
+              importRetryPolicy:
+                description: ImportRetryPolicy bounds the retries of the failed imports
+                  and the backoff between them, overridden by the annotations of the
+                  DataVolume. The kubelet restarts the failed importer pods without
+                  limit by default.
+                properties:
+                  initialBackoff:
+                    description: InitialBackoff is the delay before the first retry
+                      of a failed import, doubled by each further retry. Defaults
+                      to 10s.
+                    type: string
+                  maxBackoff:
+                    description: MaxBackoff caps the delay before the retry of a failed
+                      import. Defaults to 5m.
+                    type: string
+                  maxRetries:
+                    description: MaxRetries is the number of times a failed import
+                      is retried before its DataVolume fails, 0 fails it on its first
+                      failure. Not limited by default.
+                    format: int32
+                    minimum: 0
+                    type: integer
+                type: object
               insecureRegistries:
                 description: InsecureRegistries is a list of TLS disabled registries
                 items:
                   type: string
                 type: array
+              nfs:
+                description: NFS configures the mounts of the NFS exports holding
+                  the files of the NFS sources
+                properties:
+                  mountOptions:
+                    description: MountOptions are the options of the mounts of the
+                      NFS exports, such as vers, soft or hard. The exports are always
+                      mounted read-only. Defaults to soft,timeo=100,retrans=3.
+                    items:
+                      type: string
+                    type: array
+                  mountTimeout:
+                    description: MountTimeout is the time after which an import fails
+                      when its importer pod could not mount the NFS export. Defaults
+                      to 5m.
+                    type: string
+                type: object
+              podPriorityClassName:
+                description: PodPriorityClassName is the default priority class
+                  of the importer, cloner and uploader pods, overridden by the
+                  priorityClassName of the DataVolume. The pods have no priority
+                  class by default.
+                type: string
               podResourceRequirements:
                 description: ResourceRequirements describes the compute resource requirements.
                 properties:
@@ -4596,6 +4906,62 @@ spec:
                 description: Preallocation controls whether storage for DataVolumes
                   should be allocated in advance.
                 type: boolean
+              qemuImg:
+                description: QemuImg bounds the resources of the qemu-img subprocess
+                  of the importer pods
+                properties:
+                  cacheMode:
+                    description: CacheMode is the cache mode of the disk image written
+                      by a conversion. Defaults to writeback, none bypasses the page
+                      cache of the importer pod.
+                    enum:
+                    - writeback
+                    - writethrough
+                    - none
+                    - directsync
+                    - unsafe
+                    type: string
+                  coroutines:
+                    description: Coroutines is the number of parallel coroutines of
+                      a conversion, each one holding a buffer of the data converted.
+                      Defaults to 8.
+                    format: int32
+                    maximum: 16
+                    minimum: 1
+                    type: integer
+                  memoryLimit:
+                    anyOf:
+                    - type: integer
+                    - type: string
+                    description: MemoryLimit is the limit of the address space of
+                      qemu-img, which fails rather than getting the importer pod killed
+                      once it is reached. Not limited by default.
+                    pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                    x-kubernetes-int-or-string: true
+                  niceness:
+                    description: Niceness is the niceness of qemu-img, from 0 to 19,
+                      leaving CPU time to the transfer of the data. Defaults to 10.
+                    format: int32
+                    maximum: 19
+                    minimum: 0
+                    type: integer
+                type: object
+              registryPlatform:
+                description: RegistryPlatform is the
+                  <os>/<architecture>[/<variant>] platform of the image imported
+                  from a manifest list of a registry when the list has no image
+                  for the platform of the node of the importer, such as
+                  linux/amd64. The cdi.kubevirt.io/storage.import.registryPlatform
+                  annotation of the DataVolume selects the platform instead.
+                pattern: ^[^/\s]+/[^/\s]+(/[^/\s]+)?$
+                type: string
+              scratchSpaceSizeMultiplier:
+                description: ScratchSpaceSizeMultiplier is the size of the scratch
+                  space relative to the size of the DataVolume, such as 1.5 or 0.5,
+                  overridden by the cdi.kubevirt.io/storage.scratch.sizeMultiplier
+                  annotation of the DataVolume. Defaults to 1.
+                pattern: ^\d+(\.\d{1,3})?$
+                type: string
               scratchSpaceStorageClass:
                 description: 'Override the storage class to used for scratch space
                   during transfer operations. The scratch space storage class is determined
@@ -4724,6 +5090,10 @@ spec:
                       to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/'
                     type: object
                 type: object
+              diskFormat:
+                description: DiskFormat is the default format of the disk images written
+                  to filesystem volumes by imports
+                type: string
               filesystemOverhead:
                 description: FilesystemOverhead describes the space reserved for overhead
                   when using Filesystem volumes. A percentage value is between 0 and
@@ -4748,6 +5118,15 @@ spec:
                       value
                     type: object
                 type: object
+              importBandwidthLimit:
+                anyOf:
+                - type: integer
+                - type: string
+                description: The calculated default limit of the rate at which
+                  each import reads the data of its source, in bytes per second,
+                  unset when the imports are not limited
+                pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                x-kubernetes-int-or-string: true
               importProxy:
                 description: ImportProxy contains importer pod proxy configuration.
                 properties:
@@ -4778,10 +5157,35 @@ spec:
                       <base64 encoded cert> ... -----END CERTIFICATE-----"
                     type: string
                 type: object
+              importRetryPolicy:
+                description: The calculated default retry policy of the failed
+                  imports, its unset backoffs defaulted, unset when the kubelet
+                  restarts the failed importer pods
+                properties:
+                  initialBackoff:
+                    description: InitialBackoff is the delay before the first retry
+                      of a failed import, doubled by each further retry. Defaults
+                      to 10s.
+                    type: string
+                  maxBackoff:
+                    description: MaxBackoff caps the delay before the retry of a failed
+                      import. Defaults to 5m.
+                    type: string
+                  maxRetries:
+                    description: MaxRetries is the number of times a failed import
+                      is retried before its DataVolume fails, 0 fails it on its first
+                      failure. Not limited by default.
+                    format: int32
+                    minimum: 0
+                    type: integer
+                type: object
               preallocation:
                 description: Preallocation controls whether storage for DataVolumes
                   should be allocated in advance.
                 type: boolean
+              scratchSpaceSizeMultiplier:
+                description: The calculated size multiplier of the scratch space
+                type: string
               scratchSpaceStorageClass:
                 description: The calculated storage class to be used for scratch space
                 type: string
@@ -4887,6 +5291,14 @@ spec:
                   spec:
                     description: DataVolumeSpec defines the DataVolume type specification
                     properties:
+                      backoffLimit:
+                        description: BackoffLimit is the number of times a failed
+                          import is retried before the DataVolume fails, 0 fails it
+                          on its first failure. Overrides the maxRetries annotation
+                          of the DataVolume and the importRetryPolicy of the CDIConfig.
+                        format: int32
+                        minimum: 0
+                        type: integer
                       checkpoints:
                         description: Checkpoints is a list of DataVolumeCheckpoints,
                           representing stages in a multistage import.
@@ -4913,6 +5325,14 @@ spec:
                         - kubevirt
                         - archive
                         type: string
+                      diskFormat:
+                        description: DiskFormat is the format of the disk image written
+                          to a filesystem volume by an import, raw or qcow2. Defaults
+                          to the diskFormat of the CDIConfig, raw if it is not set.
+                        enum:
+                        - raw
+                        - qcow2
+                        type: string
                       finalCheckpoint:
                         description: FinalCheckpoint indicates whether the current
                           DataVolumeCheckpoint is the final checkpoint.
@@ -4921,6 +5341,15 @@ spec:
                         description: Preallocation controls whether storage for DataVolumes
                           should be allocated in advance.
                         type: boolean
+                      preallocationMode:
+                        description: PreallocationMode is the preallocation of the
+                          raw disk image written by an import when preallocation is
+                          set, falloc or full. Defaults to falloc, the preallocation
+                          is full when the filesystem does not support fallocate.
+                        enum:
+                        - falloc
+                        - full
+                        type: string
                       priorityClassName:
                         description: PriorityClassName for Importer, Cloner and Uploader
                           pod
@@ -5101,6 +5530,131 @@ spec:
                           blank:
                             description: DataVolumeBlankImage provides the parameters
                               to create a new raw blank image for the PVC
+                            properties:
+                              filesystem:
+                                description: Filesystem is the filesystem created
+                                  on the blank image, ext4 or xfs, the image is left
+                                  empty when it is not set
+                                enum:
+                                - ext4
+                                - xfs
+                                type: string
+                              filesystemLabel:
+                                description: FilesystemLabel is the label of the filesystem
+                                  created on the blank image, of letters, digits,
+                                  '.', '_' and '-', up to 16 characters for ext4 and
+                                  12 for xfs
+                                type: string
+                            type: object
+                          file:
+                            description: DataVolumeSourceFile provides the parameters
+                              to create a Data Volume from a file of a PVC or of a
+                              directory of the node, mounted read-only in the importer
+                              pod
+                            properties:
+                              hostPath:
+                                description: HostPath is the absolute path of the
+                                  directory of the node holding the file, allowed
+                                  by the HostPathImport feature gate
+                                type: string
+                              namespace:
+                                description: Namespace is the namespace of the PVC
+                                  holding the file, the file of a PVC of another namespace
+                                  is imported with the permission of the user to clone
+                                  the PVCs of that namespace
+                                type: string
+                              path:
+                                description: Path is the path of the file, relative
+                                  to the root of the PVC or of the directory of the
+                                  node
+                                type: string
+                              pvc:
+                                description: PVC is the name of the PVC holding the
+                                  file, in the namespace of the Data Volume unless
+                                  Namespace is set
+                                type: string
+                            required:
+                            - path
+                            type: object
+                          gcs:
+                            description: DataVolumeSourceGCS provides the parameters
+                              to create a Data Volume from a Google Cloud Storage
+                              source
+                            properties:
+                              checksum:
+                                description: Checksum is the checksum of the data
+                                  of the source, before it is decompressed. The import
+                                  fails if the data does not match it
+                                properties:
+                                  algorithm:
+                                    description: Algorithm is the hash algorithm of
+                                      the checksum, sha256, sha512 or md5
+                                    enum:
+                                    - sha256
+                                    - sha512
+                                    - md5
+                                    type: string
+                                  value:
+                                    description: Value is the hex digest of the data,
+                                      64 hex digits for sha256, 128 for sha512 and
+                                      32 for md5
+                                    type: string
+                                required:
+                                - algorithm
+                                - value
+                                type: object
+                              secretRef:
+                                description: SecretRef provides the secret reference
+                                  holding the JSON key of the service account reading
+                                  the GCS source, in its serviceAccount key. The workload
+                                  identity of the importer pod is used when it is
+                                  empty
+                                type: string
+                              url:
+                                description: 'URL is the url of the GCS source, gs://bucket/object,
+                                  optionally followed by #generation to import a given
+                                  generation of the object'
+                                type: string
+                            required:
+                            - url
+                            type: object
+                          glance:
+                            description: DataVolumeSourceGlance provides the parameters
+                              to create a Data Volume from an OpenStack Glance image
+                            properties:
+                              certConfigMap:
+                                description: CertConfigMap is a configmap reference,
+                                  containing a Certificate Authority(CA) public key,
+                                  and a base64 encoded pem certificate
+                                type: string
+                              image:
+                                description: Image is the ID or the name of the Glance
+                                  image
+                                type: string
+                              project:
+                                description: Project is the ID of the project owning
+                                  the image, an image named by Image is looked up
+                                  among the images of this project
+                                type: string
+                              region:
+                                description: Region is the region of the image service
+                                  in the service catalog of the cloud, the first image
+                                  service of the catalog is used when it is empty
+                                type: string
+                              secretRef:
+                                description: SecretRef provides the secret reference
+                                  holding the ID and the secret of the application
+                                  credential authenticating to Keystone, in its accessKeyId
+                                  and secretKey keys
+                                type: string
+                              url:
+                                description: URL is the url of the Keystone identity
+                                  service of the OpenStack cloud, such as https://keystone.example.com:5000/v3
+                                type: string
+                            required:
+                            - image
+                            - secretRef
+                            - url
                             type: object
                           http:
                             description: DataVolumeSourceHTTP can be either an http
@@ -5113,12 +5667,47 @@ spec:
                                   containing a Certificate Authority(CA) public key,
                                   and a base64 encoded pem certificate
                                 type: string
+                              checksum:
+                                description: Checksum is the checksum of the data
+                                  of the source, before it is decompressed. The import
+                                  fails if the data does not match it
+                                properties:
+                                  algorithm:
+                                    description: Algorithm is the hash algorithm of
+                                      the checksum, sha256, sha512 or md5
+                                    enum:
+                                    - sha256
+                                    - sha512
+                                    - md5
+                                    type: string
+                                  value:
+                                    description: Value is the hex digest of the data,
+                                      64 hex digits for sha256, 128 for sha512 and
+                                      32 for md5
+                                    type: string
+                                required:
+                                - algorithm
+                                - value
+                                type: object
+                              encryptionSecretRef:
+                                description: EncryptionSecretRef is a Secret reference,
+                                  the secret should contain the passphrase of a LUKS-encrypted
+                                  qcow2 source image in its passphrase key
+                                type: string
                               extraHeaders:
                                 description: ExtraHeaders is a list of strings containing
                                   extra headers to include with HTTP transfer requests
                                 items:
                                   type: string
                                 type: array
+                              mirrors:
+                                description: Mirrors is an ordered list of http(s)
+                                  URLs of the same data, tried in turn when the URL,
+                                  or the previous mirror, cannot be reached or fails
+                                  with a server error
+                                items:
+                                  type: string
+                                type: array
                               secretExtraHeaders:
                                 description: SecretExtraHeaders is a list of Secret
                                   references, each containing an extra HTTP header
@@ -5129,10 +5718,38 @@ spec:
                               secretRef:
                                 description: SecretRef A Secret reference, the secret
                                   should contain accessKeyId (user name) base64 encoded,
-                                  and secretKey (password) also base64 encoded
+                                  and secretKey (password) also base64 encoded, or
+                                  a bearer token in its token key, and optionally
+                                  a client certificate in its tls.crt and tls.key
+                                  keys
                                 type: string
+                              signature:
+                                description: Signature is a detached OpenPGP signature
+                                  of the data of the source, before it is decompressed.
+                                  The import fails if the data is not signed by one
+                                  of the trusted keys
+                                properties:
+                                  armored:
+                                    description: Armored is the armored detached signature
+                                      of the data, when it is not downloaded from
+                                      URL
+                                    type: string
+                                  keyConfigMap:
+                                    description: KeyConfigMap is a ConfigMap reference,
+                                      containing the armored OpenPGP public keys trusted
+                                      to sign the data in any of its keys
+                                    type: string
+                                  url:
+                                    description: URL is the URL of the detached signature,
+                                      armored or binary, such as the .sig or .asc
+                                      file published along with the data
+                                    type: string
+                                required:
+                                - keyConfigMap
+                                type: object
                               url:
-                                description: URL is the URL of the http(s) endpoint
+                                description: URL is the URL of the http(s) or ftp(s)
+                                  endpoint
                                 type: string
                             required:
                             - url
@@ -5159,6 +5776,18 @@ spec:
                             - diskId
                             - url
                             type: object
+                          nfs:
+                            description: DataVolumeSourceNFS provides the parameters
+                              to create a Data Volume from a file of an NFS export,
+                              whose directory is mounted read-only in the importer
+                              pod
+                            properties:
+                              url:
+                                description: URL is the URL of the file, nfs://server/path/file
+                                type: string
+                            required:
+                            - url
+                            type: object
                           pvc:
                             description: DataVolumeSourcePVC provides the parameters
                               to create a Data Volume from an existing PVC
@@ -5181,6 +5810,29 @@ spec:
                                 description: CertConfigMap provides a reference to
                                   the Registry certs
                                 type: string
+                              checksum:
+                                description: Checksum is the checksum of the disk
+                                  image of the container image, as it is stored in
+                                  the image. The import fails if the disk image does
+                                  not match it. Not supported by the node pull method
+                                properties:
+                                  algorithm:
+                                    description: Algorithm is the hash algorithm of
+                                      the checksum, sha256, sha512 or md5
+                                    enum:
+                                    - sha256
+                                    - sha512
+                                    - md5
+                                    type: string
+                                  value:
+                                    description: Value is the hex digest of the data,
+                                      64 hex digits for sha256, 128 for sha512 and
+                                      32 for md5
+                                    type: string
+                                required:
+                                - algorithm
+                                - value
+                                type: object
                               imageStream:
                                 description: ImageStream is the name of image stream
                                   for import
@@ -5195,7 +5847,7 @@ spec:
                                 type: string
                               url:
                                 description: 'URL is the url of the registry source
-                                  (starting with the scheme: docker, oci-archive)'
+                                  (starting with the scheme: docker, oci, oci-archive)'
                                 type: string
                             type: object
                           s3:
@@ -5207,6 +5859,33 @@ spec:
                                   containing a Certificate Authority(CA) public key,
                                   and a base64 encoded pem certificate
                                 type: string
+                              checksum:
+                                description: Checksum is the checksum of the data
+                                  of the source, before it is decompressed. The import
+                                  fails if the data does not match it
+                                properties:
+                                  algorithm:
+                                    description: Algorithm is the hash algorithm of
+                                      the checksum, sha256, sha512 or md5
+                                    enum:
+                                    - sha256
+                                    - sha512
+                                    - md5
+                                    type: string
+                                  value:
+                                    description: Value is the hex digest of the data,
+                                      64 hex digits for sha256, 128 for sha512 and
+                                      32 for md5
+                                    type: string
+                                required:
+                                - algorithm
+                                - value
+                                type: object
+                              encryptionSecretRef:
+                                description: EncryptionSecretRef is a Secret reference,
+                                  the secret should contain the passphrase of a LUKS-encrypted
+                                  qcow2 source image in its passphrase key
+                                type: string
                               secretRef:
                                 description: SecretRef provides the secret reference
                                   needed to access the S3 source
@@ -5217,6 +5896,89 @@ spec:
                             required:
                             - url
                             type: object
+                          sftp:
+                            description: DataVolumeSourceSFTP provides the parameters
+                              to create a Data Volume from an SFTP source
+                            properties:
+                              checksum:
+                                description: Checksum is the checksum of the data
+                                  of the source, before it is decompressed. The import
+                                  fails if the data does not match it
+                                properties:
+                                  algorithm:
+                                    description: Algorithm is the hash algorithm of
+                                      the checksum, sha256, sha512 or md5
+                                    enum:
+                                    - sha256
+                                    - sha512
+                                    - md5
+                                    type: string
+                                  value:
+                                    description: Value is the hex digest of the data,
+                                      64 hex digits for sha256, 128 for sha512 and
+                                      32 for md5
+                                    type: string
+                                required:
+                                - algorithm
+                                - value
+                                type: object
+                              secretRef:
+                                description: SecretRef provides the secret reference
+                                  holding the password or the private key of the user,
+                                  in its password or privateKey key, and the known_hosts
+                                  entries verifying the key of the host, in its knownHosts
+                                  key
+                                type: string
+                              url:
+                                description: URL is the url of the SFTP source, sftp://user@host[:port]/path,
+                                  a path starting with /~/ is relative to the home
+                                  directory of the user
+                                type: string
+                            required:
+                            - secretRef
+                            - url
+                            type: object
+                          smb:
+                            description: DataVolumeSourceSMB provides the parameters
+                              to create a Data Volume from a file of an SMB share,
+                              read by the importer without mounting the share
+                            properties:
+                              checksum:
+                                description: Checksum is the checksum of the data
+                                  of the source, before it is decompressed. The import
+                                  fails if the data does not match it
+                                properties:
+                                  algorithm:
+                                    description: Algorithm is the hash algorithm of
+                                      the checksum, sha256, sha512 or md5
+                                    enum:
+                                    - sha256
+                                    - sha512
+                                    - md5
+                                    type: string
+                                  value:
+                                    description: Value is the hex digest of the data,
+                                      64 hex digits for sha256, 128 for sha512 and
+                                      32 for md5
+                                    type: string
+                                required:
+                                - algorithm
+                                - value
+                                type: object
+                              secretRef:
+                                description: SecretRef provides the secret reference
+                                  holding the name and the password of the user, in
+                                  its username and password keys, and the domain of
+                                  the user in its optional domain key
+                                type: string
+                              url:
+                                description: URL is the URL of the file, smb://server[:port]/share/path,
+                                  the DFS links of the path are followed
+                                type: string
+                            required:
+                            - secretRef
+                            - url
+                            type: object
                           snapshot:
                             description: DataVolumeSourceSnapshot provides the parameters
                               to create a Data Volume from an existing VolumeSnapshot
@@ -5248,6 +6010,11 @@ spec:
                                   image containing an extracted VDDK library, overrides
                                   v2v-vmware config map
                                 type: string
+                              moref:
+                                description: Moref is the managed object reference
+                                  of the virtual machine in vCenter/ESXi, such as
+                                  vm-1234, used instead of its UUID
+                                type: string
                               secretRef:
                                 description: SecretRef provides a reference to a secret
                                   containing the username and password needed to access
@@ -5490,19 +6257,39 @@ spec:
                           - type
                           type: object
                         type: array
+                      importAttempts:
+                        description: ImportAttempts is the number of attempts of the
+                          import populating the DataVolume, the running one included
+                        format: int32
+                        type: integer
+                      lastImportFailure:
+                        description: LastImportFailure is the reason the last failed
+                          attempt of the import populating the DataVolume failed with
+                        type: string
                       phase:
                         description: Phase is the current phase of the data volume
                         type: string
                       progress:
                         description: DataVolumeProgress is the current progress of
                           the DataVolume transfer operation. Value between 0 and 100
-                          inclusive, N/A if not available
+                          inclusive, the bytes transferred when the size of the source
+                          is unknown, N/A if not available
+                        type: string
+                      qcow2Options:
+                        description: Qcow2Options are the cluster size and the compat
+                          level of the qcow2 disk image written to the PVC, followed
+                          by compressed=true when its clusters are compressed, cluster_size=65536,compat=1.1
+                          for instance
                         type: string
                       restartCount:
                         description: RestartCount is the number of times the pod populating
                           the DataVolume has restarted
                         format: int32
                         type: integer
+                      sourceURL:
+                        description: SourceURL is the URL of the http source, or of
+                          its mirror, which served the data of the import
+                        type: string
                     type: object
                 required:
                 - spec
@@ -5771,7 +6558,8 @@ spec:
       jsonPath: .status.phase
       name: Phase
       type: string
-    - description: Transfer progress in percentage if known, N/A otherwise
+    - description: Transfer progress in percentage if known, the bytes transferred
+        when the size of the source is unknown, N/A otherwise
       jsonPath: .status.progress
       name: Progress
       type: string
@@ -5804,6 +6592,14 @@ spec:
           spec:
             description: DataVolumeSpec defines the DataVolume type specification
             properties:
+              backoffLimit:
+                description: BackoffLimit is the number of times a failed import is
+                  retried before the DataVolume fails, 0 fails it on its first failure.
+                  Overrides the maxRetries annotation of the DataVolume and the importRetryPolicy
+                  of the CDIConfig.
+                format: int32
+                minimum: 0
+                type: integer
               checkpoints:
                 description: Checkpoints is a list of DataVolumeCheckpoints, representing
                   stages in a multistage import.
@@ -5829,6 +6625,14 @@ spec:
                 - kubevirt
                 - archive
                 type: string
+              diskFormat:
+                description: DiskFormat is the format of the disk image written to
+                  a filesystem volume by an import, raw or qcow2. Defaults to the
+                  diskFormat of the CDIConfig, raw if it is not set.
+                enum:
+                - raw
+                - qcow2
+                type: string
               finalCheckpoint:
                 description: FinalCheckpoint indicates whether the current DataVolumeCheckpoint
                   is the final checkpoint.
@@ -5837,6 +6641,15 @@ spec:
                 description: Preallocation controls whether storage for DataVolumes
                   should be allocated in advance.
                 type: boolean
+              preallocationMode:
+                description: PreallocationMode is the preallocation of the raw disk
+                  image written by an import when preallocation is set, falloc or
+                  full. Defaults to falloc, the preallocation is full when the filesystem
+                  does not support fallocate.
+                enum:
+                - falloc
+                - full
+                type: string
               priorityClassName:
                 description: PriorityClassName for Importer, Cloner and Uploader pod
                 type: string
@@ -6008,6 +6821,123 @@ spec:
                   blank:
                     description: DataVolumeBlankImage provides the parameters to create
                       a new raw blank image for the PVC
+                    properties:
+                      filesystem:
+                        description: Filesystem is the filesystem created on the blank
+                          image, ext4 or xfs, the image is left empty when it is not
+                          set
+                        enum:
+                        - ext4
+                        - xfs
+                        type: string
+                      filesystemLabel:
+                        description: FilesystemLabel is the label of the filesystem
+                          created on the blank image, of letters, digits, '.', '_'
+                          and '-', up to 16 characters for ext4 and 12 for xfs
+                        type: string
+                    type: object
+                  file:
+                    description: DataVolumeSourceFile provides the parameters to create
+                      a Data Volume from a file of a PVC or of a directory of the
+                      node, mounted read-only in the importer pod
+                    properties:
+                      hostPath:
+                        description: HostPath is the absolute path of the directory
+                          of the node holding the file, allowed by the HostPathImport
+                          feature gate
+                        type: string
+                      namespace:
+                        description: Namespace is the namespace of the PVC holding
+                          the file, the file of a PVC of another namespace is imported
+                          with the permission of the user to clone the PVCs of that
+                          namespace
+                        type: string
+                      path:
+                        description: Path is the path of the file, relative to the
+                          root of the PVC or of the directory of the node
+                        type: string
+                      pvc:
+                        description: PVC is the name of the PVC holding the file,
+                          in the namespace of the Data Volume unless Namespace is
+                          set
+                        type: string
+                    required:
+                    - path
+                    type: object
+                  gcs:
+                    description: DataVolumeSourceGCS provides the parameters to create
+                      a Data Volume from a Google Cloud Storage source
+                    properties:
+                      checksum:
+                        description: Checksum is the checksum of the data of the source,
+                          before it is decompressed. The import fails if the data
+                          does not match it
+                        properties:
+                          algorithm:
+                            description: Algorithm is the hash algorithm of the checksum,
+                              sha256, sha512 or md5
+                            enum:
+                            - sha256
+                            - sha512
+                            - md5
+                            type: string
+                          value:
+                            description: Value is the hex digest of the data, 64 hex
+                              digits for sha256, 128 for sha512 and 32 for md5
+                            type: string
+                        required:
+                        - algorithm
+                        - value
+                        type: object
+                      secretRef:
+                        description: SecretRef provides the secret reference holding
+                          the JSON key of the service account reading the GCS source,
+                          in its serviceAccount key. The workload identity of the
+                          importer pod is used when it is empty
+                        type: string
+                      url:
+                        description: 'URL is the url of the GCS source, gs://bucket/object,
+                          optionally followed by #generation to import a given generation
+                          of the object'
+                        type: string
+                    required:
+                    - url
+                    type: object
+                  glance:
+                    description: DataVolumeSourceGlance provides the parameters to
+                      create a Data Volume from an OpenStack Glance image
+                    properties:
+                      certConfigMap:
+                        description: CertConfigMap is a configmap reference, containing
+                          a Certificate Authority(CA) public key, and a base64 encoded
+                          pem certificate
+                        type: string
+                      image:
+                        description: Image is the ID or the name of the Glance image
+                        type: string
+                      project:
+                        description: Project is the ID of the project owning the image,
+                          an image named by Image is looked up among the images of
+                          this project
+                        type: string
+                      region:
+                        description: Region is the region of the image service in
+                          the service catalog of the cloud, the first image service
+                          of the catalog is used when it is empty
+                        type: string
+                      secretRef:
+                        description: SecretRef provides the secret reference holding
+                          the ID and the secret of the application credential authenticating
+                          to Keystone, in its accessKeyId and secretKey keys
+                        type: string
+                      url:
+                        description: URL is the url of the Keystone identity service
+                          of the OpenStack cloud, such as https://keystone.example.com:5000/v3
+                        type: string
+                    required:
+                    - image
+                    - secretRef
+                    - url
                     type: object
                   http:
                     description: DataVolumeSourceHTTP can be either an http or https
@@ -6019,12 +6949,45 @@ spec:
                           a Certificate Authority(CA) public key, and a base64 encoded
                           pem certificate
                         type: string
+                      checksum:
+                        description: Checksum is the checksum of the data of the source,
+                          before it is decompressed. The import fails if the data
+                          does not match it
+                        properties:
+                          algorithm:
+                            description: Algorithm is the hash algorithm of the checksum,
+                              sha256, sha512 or md5
+                            enum:
+                            - sha256
+                            - sha512
+                            - md5
+                            type: string
+                          value:
+                            description: Value is the hex digest of the data, 64 hex
+                              digits for sha256, 128 for sha512 and 32 for md5
+                            type: string
+                        required:
+                        - algorithm
+                        - value
+                        type: object
+                      encryptionSecretRef:
+                        description: EncryptionSecretRef is a Secret reference, the
+                          secret should contain the passphrase of a LUKS-encrypted
+                          qcow2 source image in its passphrase key
+                        type: string
                       extraHeaders:
                         description: ExtraHeaders is a list of strings containing
                           extra headers to include with HTTP transfer requests
                         items:
                           type: string
                         type: array
+                      mirrors:
+                        description: Mirrors is an ordered list of http(s) URLs of
+                          the same data, tried in turn when the URL, or the previous
+                          mirror, cannot be reached or fails with a server error
+                        items:
+                          type: string
+                        type: array
                       secretExtraHeaders:
                         description: SecretExtraHeaders is a list of Secret references,
                           each containing an extra HTTP header that may include sensitive
@@ -6035,10 +6998,34 @@ spec:
                       secretRef:
                         description: SecretRef A Secret reference, the secret should
                           contain accessKeyId (user name) base64 encoded, and secretKey
-                          (password) also base64 encoded
+                          (password) also base64 encoded, or a bearer token in its
+                          token key, and optionally a client certificate in its tls.crt
+                          and tls.key keys
                         type: string
+                      signature:
+                        description: Signature is a detached OpenPGP signature of
+                          the data of the source, before it is decompressed. The import
+                          fails if the data is not signed by one of the trusted keys
+                        properties:
+                          armored:
+                            description: Armored is the armored detached signature
+                              of the data, when it is not downloaded from URL
+                            type: string
+                          keyConfigMap:
+                            description: KeyConfigMap is a ConfigMap reference, containing
+                              the armored OpenPGP public keys trusted to sign the
+                              data in any of its keys
+                            type: string
+                          url:
+                            description: URL is the URL of the detached signature,
+                              armored or binary, such as the .sig or .asc file published
+                              along with the data
+                            type: string
+                        required:
+                        - keyConfigMap
+                        type: object
                       url:
-                        description: URL is the URL of the http(s) endpoint
+                        description: URL is the URL of the http(s) or ftp(s) endpoint
                         type: string
                     required:
                     - url
@@ -6065,6 +7052,17 @@ spec:
                     - diskId
                     - url
                     type: object
+                  nfs:
+                    description: DataVolumeSourceNFS provides the parameters to create
+                      a Data Volume from a file of an NFS export, whose directory
+                      is mounted read-only in the importer pod
+                    properties:
+                      url:
+                        description: URL is the URL of the file, nfs://server/path/file
+                        type: string
+                    required:
+                    - url
+                    type: object
                   pvc:
                     description: DataVolumeSourcePVC provides the parameters to create
                       a Data Volume from an existing PVC
@@ -6087,6 +7085,28 @@ spec:
                         description: CertConfigMap provides a reference to the Registry
                           certs
                         type: string
+                      checksum:
+                        description: Checksum is the checksum of the disk image of
+                          the container image, as it is stored in the image. The import
+                          fails if the disk image does not match it. Not supported
+                          by the node pull method
+                        properties:
+                          algorithm:
+                            description: Algorithm is the hash algorithm of the checksum,
+                              sha256, sha512 or md5
+                            enum:
+                            - sha256
+                            - sha512
+                            - md5
+                            type: string
+                          value:
+                            description: Value is the hex digest of the data, 64 hex
+                              digits for sha256, 128 for sha512 and 32 for md5
+                            type: string
+                        required:
+                        - algorithm
+                        - value
+                        type: object
                       imageStream:
                         description: ImageStream is the name of image stream for import
                         type: string
@@ -6100,7 +7120,7 @@ spec:
                         type: string
                       url:
                         description: 'URL is the url of the registry source (starting
-                          with the scheme: docker, oci-archive)'
+                          with the scheme: docker, oci, oci-archive)'
                         type: string
                     type: object
                   s3:
@@ -6112,6 +7132,32 @@ spec:
                           a Certificate Authority(CA) public key, and a base64 encoded
                           pem certificate
                         type: string
+                      checksum:
+                        description: Checksum is the checksum of the data of the source,
+                          before it is decompressed. The import fails if the data
+                          does not match it
+                        properties:
+                          algorithm:
+                            description: Algorithm is the hash algorithm of the checksum,
+                              sha256, sha512 or md5
+                            enum:
+                            - sha256
+                            - sha512
+                            - md5
+                            type: string
+                          value:
+                            description: Value is the hex digest of the data, 64 hex
+                              digits for sha256, 128 for sha512 and 32 for md5
+                            type: string
+                        required:
+                        - algorithm
+                        - value
+                        type: object
+                      encryptionSecretRef:
+                        description: EncryptionSecretRef is a Secret reference, the
+                          secret should contain the passphrase of a LUKS-encrypted
+                          qcow2 source image in its passphrase key
+                        type: string
                       secretRef:
                         description: SecretRef provides the secret reference needed
                           to access the S3 source
@@ -6122,6 +7168,86 @@ spec:
                     required:
                     - url
                     type: object
+                  sftp:
+                    description: DataVolumeSourceSFTP provides the parameters to create
+                      a Data Volume from an SFTP source
+                    properties:
+                      checksum:
+                        description: Checksum is the checksum of the data of the source,
+                          before it is decompressed. The import fails if the data
+                          does not match it
+                        properties:
+                          algorithm:
+                            description: Algorithm is the hash algorithm of the checksum,
+                              sha256, sha512 or md5
+                            enum:
+                            - sha256
+                            - sha512
+                            - md5
+                            type: string
+                          value:
+                            description: Value is the hex digest of the data, 64 hex
+                              digits for sha256, 128 for sha512 and 32 for md5
+                            type: string
+                        required:
+                        - algorithm
+                        - value
+                        type: object
+                      secretRef:
+                        description: SecretRef provides the secret reference holding
+                          the password or the private key of the user, in its password
+                          or privateKey key, and the known_hosts entries verifying
+                          the key of the host, in its knownHosts key
+                        type: string
+                      url:
+                        description: URL is the url of the SFTP source, sftp://user@host[:port]/path,
+                          a path starting with /~/ is relative to the home directory
+                          of the user
+                        type: string
+                    required:
+                    - secretRef
+                    - url
+                    type: object
+                  smb:
+                    description: DataVolumeSourceSMB provides the parameters to create
+                      a Data Volume from a file of an SMB share, read by the importer
+                      without mounting the share
+                    properties:
+                      checksum:
+                        description: Checksum is the checksum of the data of the source,
+                          before it is decompressed. The import fails if the data
+                          does not match it
+                        properties:
+                          algorithm:
+                            description: Algorithm is the hash algorithm of the checksum,
+                              sha256, sha512 or md5
+                            enum:
+                            - sha256
+                            - sha512
+                            - md5
+                            type: string
+                          value:
+                            description: Value is the hex digest of the data, 64 hex
+                              digits for sha256, 128 for sha512 and 32 for md5
+                            type: string
+                        required:
+                        - algorithm
+                        - value
+                        type: object
+                      secretRef:
+                        description: SecretRef provides the secret reference holding
+                          the name and the password of the user, in its username and
+                          password keys, and the domain of the user in its optional
+                          domain key
+                        type: string
+                      url:
+                        description: URL is the URL of the file, smb://server[:port]/share/path,
+                          the DFS links of the path are followed
+                        type: string
+                    required:
+                    - secretRef
+                    - url
+                    type: object
                   snapshot:
                     description: DataVolumeSourceSnapshot provides the parameters
                       to create a Data Volume from an existing VolumeSnapshot
@@ -6152,6 +7278,11 @@ spec:
                         description: InitImageURL is an optional URL to an image containing
                           an extracted VDDK library, overrides v2v-vmware config map
                         type: string
+                      moref:
+                        description: Moref is the managed object reference of the
+                          virtual machine in vCenter/ESXi, such as vm-1234, used instead
+                          of its UUID
+                        type: string
                       secretRef:
                         description: SecretRef provides a reference to a secret containing
                           the username and password needed to access the vCenter or
@@ -6386,19 +7517,61 @@ spec:
                   - type
                   type: object
                 type: array
+              importAttempts:
+                description: ImportAttempts is the number of attempts of the import
+                  populating the DataVolume, the running one included
+                format: int32
+                type: integer
+              lastImportFailure:
+                description: LastImportFailure is the reason the last failed attempt
+                  of the import populating the DataVolume failed with
+                type: string
               phase:
                 description: Phase is the current phase of the data volume
                 type: string
+              preallocation:
+                description: Preallocation is the preallocation requested for the
+                  PVC, by the DataVolume or by the CDIConfig, and the one performed
+                  by the pod populating it
+                properties:
+                  applied:
+                    description: Applied is true once the pod populating the PVC preallocated
+                      the disk image
+                    type: boolean
+                  mode:
+                    description: Mode is the preallocation performed, falloc or full,
+                      full when falloc was requested but the filesystem does not support
+                      fallocate
+                    type: string
+                  requested:
+                    description: Requested is true when preallocation is requested,
+                      by the preallocation of the DataVolume, or of the CDIConfig
+                      when the DataVolume does not set it
+                    type: boolean
+                required:
+                - applied
+                - requested
+                type: object
               progress:
                 description: DataVolumeProgress is the current progress of the DataVolume
-                  transfer operation. Value between 0 and 100 inclusive, N/A if not
-                  available
+                  transfer operation. Value between 0 and 100 inclusive, the bytes
+                  transferred when the size of the source is unknown, N/A if not available
+                type: string
+              qcow2Options:
+                description: Qcow2Options are the cluster size and the compat level
+                  of the qcow2 disk image written to the PVC, followed by compressed=true
+                  when its clusters are compressed, cluster_size=65536,compat=1.1
+                  for instance
                 type: string
               restartCount:
                 description: RestartCount is the number of times the pod populating
                   the DataVolume has restarted
                 format: int32
                 type: integer
+              sourceURL:
+                description: SourceURL is the URL of the http source, of its mirror,
+                  or the URL it was redirected to, which served the data of the import
+                type: string
             type: object
         required:
         - spec
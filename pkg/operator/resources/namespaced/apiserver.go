@@ -30,6 +30,7 @@ import (
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	utils "kubevirt.io/containerized-data-importer/pkg/operator/resources/utils"
+	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
 const (
@@ -98,6 +99,17 @@ func createAPIServerDeployment(image, verbosity, pullPolicy, priorityClassName s
 		deployment.Spec.Template.Spec.PriorityClassName = priorityClassName
 	}
 	container := utils.CreateContainer(apiServerRessouceName, image, verbosity, pullPolicy)
+	// the metrics of the webhooks are scraped through the prometheus service, like the metrics of the controller
+	container.Ports = []corev1.ContainerPort{
+		{
+			Name:          "metrics",
+			ContainerPort: 8080,
+			Protocol:      "TCP",
+		},
+	}
+	labels := util.MergeLabels(deployment.Spec.Template.GetLabels(), map[string]string{common.PrometheusLabelKey: common.PrometheusLabelValue})
+	deployment.SetLabels(labels)
+	deployment.Spec.Template.SetLabels(labels)
 	container.Env = []corev1.EnvVar{
 		{
 			Name: common.InstallerPartOfLabel,
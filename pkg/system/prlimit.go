@@ -34,12 +34,15 @@ import (
 type ProcessLimiter interface {
 	SetAddressSpaceLimit(pid int, value uint64) error
 	SetCPUTimeLimit(pid int, value uint64) error
+	SetNiceness(pid int, value int) error
 }
 
 // ProcessLimitValues specifies the resource limits available to a process
 type ProcessLimitValues struct {
 	AddressSpaceLimit uint64
 	CPUTimeLimit      uint64
+	// Niceness is the niceness of the process, between 0 and 19, left as it is when 0
+	Niceness int
 }
 
 type processLimiter struct{}
@@ -62,6 +65,13 @@ func (p *processLimiter) SetCPUTimeLimit(pid int, value uint64) error {
 	return prlimit(pid, unix.RLIMIT_CPU, &syscall.Rlimit{Cur: value, Max: value})
 }
 
+func (p *processLimiter) SetNiceness(pid int, value int) error {
+	if err := unix.Setpriority(unix.PRIO_PROCESS, pid, value); err != nil {
+		return errors.Wrapf(err, "error setting niceness %d on pid %d", value, pid)
+	}
+	return nil
+}
+
 // SetAddressSpaceLimit sets a limit on total address space of a process
 func SetAddressSpaceLimit(pid int, value uint64) error {
 	return limiter.SetAddressSpaceLimit(pid, value)
@@ -72,6 +82,11 @@ func SetCPUTimeLimit(pid int, value uint64) error {
 	return limiter.SetCPUTimeLimit(pid, value)
 }
 
+// SetNiceness sets the niceness of a process, the scheduling priority of its threads started afterwards
+func SetNiceness(pid int, value int) error {
+	return limiter.SetNiceness(pid, value)
+}
+
 // scanLinesWithCR is an alternate split function that works with carriage returns as well
 // as new lines.
 func scanLinesWithCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
@@ -162,6 +177,13 @@ func executeWithLimits(limits *ProcessLimitValues, callback func(string), logErr
 			return nil, errors.Wrap(err, "Couldn't set address space limit")
 		}
 	}
+	if limits != nil && limits.Niceness > 0 {
+		klog.V(3).Infof("Setting niceness to %d\n", limits.Niceness)
+		err = SetNiceness(cmd.Process.Pid, limits.Niceness)
+		if err != nil {
+			return nil, errors.Wrap(err, "Couldn't set niceness")
+		}
+	}
 	<-stdoutDone
 	<-stderrDone
 	// The wait has to be after the reading channels are finished otherwise there is a race where the wait completes and closes stdout/err before anything
@@ -37,8 +37,8 @@ import (
 )
 
 var _ = Describe("Process Limits", func() {
-	limits := &ProcessLimitValues{1, 1}
-	nullLimiter := newTestProcessLimiter(nil, nil)
+	limits := &ProcessLimitValues{AddressSpaceLimit: 1, CPUTimeLimit: 1, Niceness: 1}
+	nullLimiter := newTestProcessLimiter(nil, nil, nil)
 
 	table.DescribeTable("exec", func(commandOverride func(context.Context, string, ...string) *exec.Cmd, limiter ProcessLimiter, limits *ProcessLimitValues, command, output, errString string, args ...string) {
 		replaceExecCommandContext(commandOverride, func() {
@@ -60,9 +60,10 @@ var _ = Describe("Process Limits", func() {
 			})
 		})
 	},
-		table.Entry("command success with real limits", fakeCommandContext, nil, &ProcessLimitValues{1 << 30, 10}, "faker", "", "", "0", "", ""),
+		table.Entry("command success with real limits", fakeCommandContext, nil, &ProcessLimitValues{AddressSpaceLimit: 1 << 30, CPUTimeLimit: 10, Niceness: 10}, "faker", "", "", "0", "", ""),
 		table.Entry("command start fails", badCommand, nullLimiter, limits, "faker", "", "fork/exec /usr/bin/doesnotexist: no such file or directory", "", "", ""),
-		table.Entry("address space limit fails", fakeCommandContext, newTestProcessLimiter(errors.New("Set address limit fails"), nil), limits, "faker", "", "Set address limit fails", "", "", ""),
+		table.Entry("address space limit fails", fakeCommandContext, newTestProcessLimiter(errors.New("Set address limit fails"), nil, nil), limits, "faker", "", "Set address limit fails", "", "", ""),
+		table.Entry("niceness fails", fakeCommandContext, newTestProcessLimiter(nil, nil, errors.New("Set niceness fails")), limits, "faker", "", "Set niceness fails", "", "", ""),
 		table.Entry("command exit bad", fakeCommandContext, nullLimiter, limits, "faker", "", "exit status 1", "1", "", ""),
 	)
 
@@ -74,6 +75,18 @@ var _ = Describe("Process Limits", func() {
 		table.Entry("killed by memory limit", 10*time.Second, func(p int) error { return SetAddressSpaceLimit(p, (1<<21)*10) }, "hog", "exit status 2"),
 	)
 
+	It("Niceness should be set", func() {
+		cmd := fakeCommand("spinner")
+		Expect(cmd.Start()).To(Succeed())
+		defer cmd.Process.Kill()
+		Expect(SetNiceness(cmd.Process.Pid, 7)).To(Succeed())
+		stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", cmd.Process.Pid))
+		Expect(err).NotTo(HaveOccurred())
+		// the fields following the command name, its niceness is the 19th field of the stat
+		fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+		Expect(fields[16]).To(Equal("7"))
+	})
+
 	It("Carriage return split should work", func() {
 		reader := strings.NewReader("This is a line\rThis is line two\nThis is line three")
 		scanner := bufio.NewScanner(reader)
@@ -95,10 +108,11 @@ var _ = Describe("Process Limits", func() {
 type testProcessLimiter struct {
 	addressSpaceError error
 	cpuTimeError      error
+	nicenessError     error
 }
 
-func newTestProcessLimiter(addressSpaceError, cpuTimeError error) ProcessLimiter {
-	return &testProcessLimiter{addressSpaceError, cpuTimeError}
+func newTestProcessLimiter(addressSpaceError, cpuTimeError, nicenessError error) ProcessLimiter {
+	return &testProcessLimiter{addressSpaceError, cpuTimeError, nicenessError}
 }
 
 func (p *testProcessLimiter) SetAddressSpaceLimit(pid int, value uint64) error {
@@ -109,6 +123,10 @@ func (p *testProcessLimiter) SetCPUTimeLimit(pid int, value uint64) error {
 	return p.cpuTimeError
 }
 
+func (p *testProcessLimiter) SetNiceness(pid int, value int) error {
+	return p.nicenessError
+}
+
 func testProgress(line string) {
 	// No-op
 }
@@ -58,13 +58,19 @@ type Validator interface {
 
 type validator struct {
 	issuer string
-	key    *rsa.PublicKey
+	keys   []*rsa.PublicKey
 	leeway time.Duration
 }
 
 // NewValidator return a new Validator implementation
 func NewValidator(issuer string, key *rsa.PublicKey, leeway time.Duration) Validator {
-	return &validator{issuer: issuer, key: key, leeway: leeway}
+	return &validator{issuer: issuer, keys: []*rsa.PublicKey{key}, leeway: leeway}
+}
+
+// NewMultiKeyValidator returns a new Validator accepting the tokens signed with any of the keys, the current
+// signing key and the previous ones of a key rotation
+func NewMultiKeyValidator(issuer string, keys []*rsa.PublicKey, leeway time.Duration) Validator {
+	return &validator{issuer: issuer, keys: keys, leeway: leeway}
 }
 
 // Validate checks the token signature and returns the contents
@@ -77,7 +83,13 @@ func (v *validator) Validate(token string) (*Payload, error) {
 	public := &jwt.Claims{}
 	private := &Payload{}
 
-	if err = tok.Claims(v.key, public, private); err != nil {
+	err = errors.New("no key to verify the token")
+	for _, key := range v.keys {
+		if err = tok.Claims(key, public, private); err == nil {
+			break
+		}
+	}
+	if err != nil {
 		return nil, err
 	}
 
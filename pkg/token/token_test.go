@@ -165,4 +165,45 @@ var _ = Describe("Token test", func() {
 		_, err = validator.Validate(signedToken)
 		Expect(err).To(HaveOccurred())
 	})
+
+	It("Rotated keys", func() {
+		issuer := "issuer"
+
+		previousKey, err := generateTestKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		currentKey, err := generateTestKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		otherKey, err := generateTestKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		tokenData := &Payload{
+			Operation: OperationClone,
+			Name:      "fakepvc",
+			Namespace: "fakenamespace",
+			Resource: metav1.GroupVersionResource{
+				Group:    "",
+				Version:  "v1",
+				Resource: "persistentvolumeclaims",
+			},
+		}
+
+		validator := NewMultiKeyValidator(issuer, []*rsa.PublicKey{&currentKey.PublicKey, &previousKey.PublicKey}, 0)
+
+		for _, key := range []*rsa.PrivateKey{currentKey, previousKey} {
+			signedToken, err := NewGenerator(issuer, key, 5*time.Minute).Generate(tokenData)
+			Expect(err).ToNot(HaveOccurred())
+
+			payload, err := validator.Validate(signedToken)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reflect.DeepEqual(tokenData, payload)).To(BeTrue())
+		}
+
+		signedToken, err := NewGenerator(issuer, otherKey, 5*time.Minute).Generate(tokenData)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = validator.Validate(signedToken)
+		Expect(err).To(HaveOccurred())
+	})
 })
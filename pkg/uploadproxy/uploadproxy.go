@@ -27,6 +27,7 @@ import (
 	"kubevirt.io/containerized-data-importer/pkg/common"
 	"kubevirt.io/containerized-data-importer/pkg/controller"
 	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
+	"kubevirt.io/containerized-data-importer/pkg/keys"
 	"kubevirt.io/containerized-data-importer/pkg/token"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/fetcher"
 	cryptowatch "kubevirt.io/containerized-data-importer/pkg/util/tls-crypto-watch"
@@ -300,12 +301,15 @@ func (app *uploadProxyApp) proxyUploadRequest(namespace, pvcName, uploadPath str
 }
 
 func (app *uploadProxyApp) getSigningKey(publicKeyPEM string) error {
-	publicKey, err := controller.DecodePublicKey([]byte(publicKeyPEM))
+	publicKeys, err := keys.DecodePublicKeys([]byte(publicKeyPEM))
 	if err != nil {
 		return err
 	}
+	if len(publicKeys) == 0 {
+		return errors.New("no apiserver public key")
+	}
 
-	app.tokenValidator = token.NewValidator(common.UploadTokenIssuer, publicKey, uploadTokenLeeway)
+	app.tokenValidator = token.NewMultiKeyValidator(common.UploadTokenIssuer, publicKeys, uploadTokenLeeway)
 	return nil
 }
 
@@ -40,6 +40,7 @@ import (
 
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/importer"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 	cryptowatch "kubevirt.io/containerized-data-importer/pkg/util/tls-crypto-watch"
@@ -54,6 +55,7 @@ const (
 type UploadServer interface {
 	Run() error
 	PreallocationApplied() bool
+	PreallocationModeApplied() image.PreallocationMode
 }
 
 type uploadServerApp struct {
@@ -74,7 +76,7 @@ type uploadServerApp struct {
 	uploading            bool
 	processing           bool
 	done                 bool
-	preallocationApplied bool
+	preallocationApplied image.PreallocationMode
 	doneChan             chan struct{}
 	errChan              chan error
 	mutex                sync.Mutex
@@ -334,7 +336,7 @@ func (app *uploadServerApp) uploadHandlerAsync(irc imageReadCloser) http.Handler
 
 		if err != nil {
 			klog.Errorf("Saving stream failed: %s", err)
-			if _, ok := err.(importer.ValidationSizeError); ok {
+			if _, ok := err.(importer.ValidationSizeError); ok || errors.Is(err, importer.ErrDecompressedTooLarge) {
 				w.WriteHeader(http.StatusBadRequest)
 			} else {
 				w.WriteHeader(http.StatusInternalServerError)
@@ -360,7 +362,7 @@ func (app *uploadServerApp) uploadHandlerAsync(irc imageReadCloser) http.Handler
 			defer app.mutex.Unlock()
 			app.processing = false
 			app.done = true
-			app.preallocationApplied = processor.PreallocationApplied()
+			app.preallocationApplied = processor.PreallocationModeApplied()
 			klog.Infof("Wrote data to %s", app.destination)
 		}()
 
@@ -389,7 +391,12 @@ func (app *uploadServerApp) processUpload(irc imageReadCloser, w http.ResponseWr
 
 	if err != nil {
 		klog.Errorf("Saving stream failed: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, importer.ErrDecompressedTooLarge) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(fmt.Sprintf("Saving stream failed: %s", importer.ErrDecompressedTooLarge.Error())))
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 		app.uploading = false
 		return
 	}
@@ -419,6 +426,12 @@ func (app *uploadServerApp) uploadArchiveHandler(irc imageReadCloser) http.Handl
 }
 
 func (app *uploadServerApp) PreallocationApplied() bool {
+	return app.preallocationApplied != image.PreallocationNone
+}
+
+// PreallocationModeApplied returns the preallocation performed on the disk image, falloc or full, empty if
+// there was none.
+func (app *uploadServerApp) PreallocationModeApplied() image.PreallocationMode {
 	return app.preallocationApplied
 }
 
@@ -432,16 +445,16 @@ func newAsyncUploadStreamProcessor(stream io.ReadCloser, dest, imageSize string,
 	return processor, processor.ProcessDataWithPause()
 }
 
-func newUploadStreamProcessor(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, sourceContentType string, dvContentType cdiv1.DataVolumeContentType) (bool, error) {
+func newUploadStreamProcessor(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, sourceContentType string, dvContentType cdiv1.DataVolumeContentType) (image.PreallocationMode, error) {
 	if sourceContentType == common.FilesystemCloneContentType {
-		return false, filesystemCloneProcessor(stream, dest)
+		return image.PreallocationNone, filesystemCloneProcessor(stream, dest)
 	}
 
 	// Clone block device to block device or file system
 	uds := importer.NewUploadDataSource(newContentReader(stream, sourceContentType), dvContentType)
 	processor := importer.NewDataProcessor(uds, dest, common.ImporterVolumePath, common.ScratchDataDir, imageSize, filesystemOverhead, preallocation)
 	err := processor.ProcessData()
-	return processor.PreallocationApplied(), err
+	return processor.PreallocationModeApplied(), err
 }
 
 // Clone file system to block device or file system
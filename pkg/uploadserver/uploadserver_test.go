@@ -38,6 +38,7 @@ import (
 
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/importer"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert"
 	"kubevirt.io/containerized-data-importer/pkg/util/cert/triple"
@@ -90,12 +91,16 @@ func newHTTPClient(clientKeyPair *triple.KeyPair, serverCACert *x509.Certificate
 	return client
 }
 
-func saveProcessorSuccess(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, contentType string, dvContentType cdiv1.DataVolumeContentType) (bool, error) {
-	return false, nil
+func saveProcessorSuccess(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, contentType string, dvContentType cdiv1.DataVolumeContentType) (image.PreallocationMode, error) {
+	return image.PreallocationNone, nil
 }
 
-func saveProcessorFailure(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, contentType string, dvContentType cdiv1.DataVolumeContentType) (bool, error) {
-	return false, fmt.Errorf("Error using datastream")
+func saveProcessorPreallocated(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, contentType string, dvContentType cdiv1.DataVolumeContentType) (image.PreallocationMode, error) {
+	return image.PreallocationFull, nil
+}
+
+func saveProcessorFailure(stream io.ReadCloser, dest, imageSize string, filesystemOverhead float64, preallocation bool, contentType string, dvContentType cdiv1.DataVolumeContentType) (image.PreallocationMode, error) {
+	return image.PreallocationNone, fmt.Errorf("Error using datastream")
 }
 
 func withProcessorSuccess(f func()) {
@@ -106,7 +111,7 @@ func withProcessorFailure(f func()) {
 	replaceProcessorFunc(saveProcessorFailure, f)
 }
 
-func replaceProcessorFunc(replacement func(io.ReadCloser, string, string, float64, bool, string, cdiv1.DataVolumeContentType) (bool, error), f func()) {
+func replaceProcessorFunc(replacement func(io.ReadCloser, string, string, float64, bool, string, cdiv1.DataVolumeContentType) (image.PreallocationMode, error), f func()) {
 	origProcessorFunc := uploadProcessorFunc
 	uploadProcessorFunc = replacement
 	defer func() {
@@ -268,6 +273,23 @@ var _ = Describe("Upload server tests", func() {
 		})
 	})
 
+	It("should report the preallocation performed on the disk image", func() {
+		replaceProcessorFunc(saveProcessorPreallocated, func() {
+			req, err := http.NewRequest("POST", common.UploadPathSync, strings.NewReader("data"))
+			Expect(err).ToNot(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+
+			server := newServer()
+			Expect(server.PreallocationApplied()).To(BeFalse())
+			server.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(server.PreallocationApplied()).To(BeTrue())
+			Expect(server.PreallocationModeApplied()).To(Equal(image.PreallocationFull))
+		})
+	})
+
 	table.DescribeTable("Success, async", func(method string) {
 		withAsyncProcessorSuccess(func() {
 			req, err := http.NewRequest(method, common.UploadPathAsync, strings.NewReader("data"))
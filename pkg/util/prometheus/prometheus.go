@@ -24,6 +24,10 @@ type ProgressReader struct {
 	progress *prometheus.CounterVec
 	ownerUID string
 	final    bool
+	// max is the progress reported once all the data is read, 100 when 0
+	max float64
+	// transferred reports the bytes read, if set
+	transferred *prometheus.GaugeVec
 }
 
 // NewProgressReader creates a new instance of a prometheus updating progress reader.
@@ -58,21 +62,60 @@ func (r *ProgressReader) timedUpdateProgress() {
 }
 
 func (r *ProgressReader) updateProgress() bool {
+	finished := r.final && r.Done
+	if r.transferred != nil {
+		SetBytes(r.transferred, r.ownerUID, r.Current)
+	}
 	if r.total > 0 {
-		finished := r.final && r.Done
-		currentProgress := 100.0
-		if !finished && r.Current < r.total {
-			currentProgress = float64(r.Current) / float64(r.total) * 100.0
+		max := r.max
+		if max == 0 {
+			max = 100.0
 		}
-		metric := &dto.Metric{}
-		r.progress.WithLabelValues(r.ownerUID).Write(metric)
-		if currentProgress > *metric.Counter.Value {
-			r.progress.WithLabelValues(r.ownerUID).Add(currentProgress - *metric.Counter.Value)
+		currentProgress := max
+		if !finished && r.Current < r.total {
+			currentProgress = float64(r.Current) / float64(r.total) * max
 		}
+		SetProgress(r.progress, r.ownerUID, currentProgress)
 		klog.V(1).Infoln(fmt.Sprintf("%.2f", currentProgress))
 		return !finished
 	}
-	return false
+	// without the total only the bytes read are reported
+	return r.transferred != nil && !finished
+}
+
+// SetBytesGauges reports the bytes read to the transferred gauge along with the progress, and the
+// total bytes, when known, to the total gauge. Without the total, the progress is not reported.
+func (r *ProgressReader) SetBytesGauges(transferred, total *prometheus.GaugeVec) {
+	r.transferred = transferred
+	if r.total > 0 {
+		total.WithLabelValues(r.ownerUID).Set(float64(r.total))
+	}
+}
+
+// SetMax sets the progress reported once all the data is read, 100 by default. It is lower when
+// reading the data is only the first part of the operation, which reports the rest of the progress.
+func (r *ProgressReader) SetMax(max float64) {
+	r.max = max
+}
+
+// SetProgress raises the progress counter of the owner to the passed in percentage, the counter
+// never decreases.
+func SetProgress(progress *prometheus.CounterVec, ownerUID string, currentProgress float64) {
+	metric := &dto.Metric{}
+	progress.WithLabelValues(ownerUID).Write(metric)
+	if currentProgress > *metric.Counter.Value {
+		progress.WithLabelValues(ownerUID).Add(currentProgress - *metric.Counter.Value)
+	}
+}
+
+// SetBytes raises the bytes gauge of the owner to the passed in number of bytes, like the progress
+// the gauge never decreases, when the data is read again after it was downloaded.
+func SetBytes(gauge *prometheus.GaugeVec, ownerUID string, bytes uint64) {
+	metric := &dto.Metric{}
+	gauge.WithLabelValues(ownerUID).Write(metric)
+	if float64(bytes) > metric.GetGauge().GetValue() {
+		gauge.WithLabelValues(ownerUID).Set(float64(bytes))
+	}
 }
 
 // SetNextReader replaces the current counting reader with a new one,
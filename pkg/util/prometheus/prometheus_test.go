@@ -10,6 +10,7 @@ import (
 
 	. "github.com/onsi/ginkgo/extensions/table"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	dto "github.com/prometheus/client_model/go"
 
 	"kubevirt.io/containerized-data-importer/pkg/util"
@@ -75,6 +76,17 @@ var _ = Describe("Update Progress", func() {
 		Expect(*metric.Counter.Value).To(Equal(float64(45)))
 	})
 
+	It("should not decrease the progress", func() {
+		metric := &dto.Metric{}
+		SetProgress(progress, ownerUID, 30)
+		SetProgress(progress, ownerUID, 20)
+		progress.WithLabelValues(ownerUID).Write(metric)
+		Expect(*metric.Counter.Value).To(Equal(float64(30)))
+		SetProgress(progress, ownerUID, 60)
+		progress.WithLabelValues(ownerUID).Write(metric)
+		Expect(*metric.Counter.Value).To(Equal(float64(60)))
+	})
+
 	It("0 total should return 0", func() {
 		metric := &dto.Metric{}
 		By("Calling updateProgress with value")
@@ -112,6 +124,78 @@ var _ = Describe("Update Progress", func() {
 		Expect(*metric.Counter.Value).To(Equal(float64(100)))
 	})
 
+	It("should scale the progress to its maximum", func() {
+		metric := &dto.Metric{}
+		promReader := &ProgressReader{
+			CountingReader: util.CountingReader{
+				Current: uint64(45),
+			},
+			total:    uint64(100),
+			progress: progress,
+			ownerUID: ownerUID,
+			final:    true,
+		}
+		promReader.SetMax(50)
+		Expect(promReader.updateProgress()).To(BeTrue())
+		progress.WithLabelValues(ownerUID).Write(metric)
+		Expect(*metric.Counter.Value).To(Equal(float64(22.5)))
+		promReader.Current = 100
+		promReader.Done = true
+		Expect(promReader.updateProgress()).To(BeFalse())
+		progress.WithLabelValues(ownerUID).Write(metric)
+		Expect(*metric.Counter.Value).To(Equal(float64(50)))
+	})
+
+	It("should report the bytes read, and only them without the total", func() {
+		transferred := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_transferred_bytes"}, []string{"ownerUID"})
+		total := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_total_bytes"}, []string{"ownerUID"})
+		promReader := &ProgressReader{
+			CountingReader: util.CountingReader{
+				Current: uint64(45),
+			},
+			progress: progress,
+			ownerUID: ownerUID,
+			final:    true,
+		}
+		promReader.SetBytesGauges(transferred, total)
+		Expect(promReader.updateProgress()).To(BeTrue())
+		metric := &dto.Metric{}
+		transferred.WithLabelValues(ownerUID).Write(metric)
+		Expect(metric.GetGauge().GetValue()).To(Equal(float64(45)))
+		Expect(testutil.CollectAndCount(total)).To(BeZero())
+		Expect(testutil.CollectAndCount(progress)).To(BeZero())
+		promReader.Current = 100
+		promReader.Done = true
+		Expect(promReader.updateProgress()).To(BeFalse())
+		transferred.WithLabelValues(ownerUID).Write(metric)
+		Expect(metric.GetGauge().GetValue()).To(Equal(float64(100)))
+	})
+
+	It("should report the total bytes along with the progress", func() {
+		transferred := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_transferred_bytes"}, []string{"ownerUID"})
+		total := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_total_bytes"}, []string{"ownerUID"})
+		promReader := NewProgressReader(io.NopCloser(strings.NewReader("")), uint64(200), progress, ownerUID)
+		promReader.SetBytesGauges(transferred, total)
+		promReader.Current = 50
+		Expect(promReader.updateProgress()).To(BeTrue())
+		metric := &dto.Metric{}
+		total.WithLabelValues(ownerUID).Write(metric)
+		Expect(metric.GetGauge().GetValue()).To(Equal(float64(200)))
+		transferred.WithLabelValues(ownerUID).Write(metric)
+		Expect(metric.GetGauge().GetValue()).To(Equal(float64(50)))
+		progress.WithLabelValues(ownerUID).Write(metric)
+		Expect(metric.GetCounter().GetValue()).To(Equal(float64(25)))
+	})
+
+	It("should not decrease the bytes", func() {
+		transferred := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_transferred_bytes"}, []string{"ownerUID"})
+		metric := &dto.Metric{}
+		SetBytes(transferred, ownerUID, 300)
+		SetBytes(transferred, ownerUID, 200)
+		transferred.WithLabelValues(ownerUID).Write(metric)
+		Expect(metric.GetGauge().GetValue()).To(Equal(float64(300)))
+	})
+
 	DescribeTable("update progress on non-final readers", func(readerDone, isFinal, expectedResult bool) {
 		promReader := &ProgressReader{
 			CountingReader: util.CountingReader{
@@ -0,0 +1,618 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smb
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	headerSize          = 64
+	transformHeaderSize = 52
+
+	cmdNegotiate      = 0x0
+	cmdSessionSetup   = 0x1
+	cmdLogoff         = 0x2
+	cmdTreeConnect    = 0x3
+	cmdTreeDisconnect = 0x4
+	cmdCreate         = 0x5
+	cmdClose          = 0x6
+	cmdRead           = 0x8
+	cmdIoctl          = 0xb
+
+	flagServerToRedir = 0x00000001
+	flagAsync         = 0x00000002
+	flagSigned        = 0x00000008
+	flagDFSOperations = 0x10000000
+
+	dialect202 = 0x0202
+	dialect210 = 0x0210
+	dialect300 = 0x0300
+	dialect302 = 0x0302
+	dialect311 = 0x0311
+
+	securitySigningEnabled = 0x1
+
+	capDFS        = 0x1
+	capLargeMTU   = 0x4
+	capEncryption = 0x40
+
+	contextPreauthIntegrity = 0x1
+	contextEncryption       = 0x2
+	hashSHA512              = 0x1
+	cipherAES128CCM         = 0x1
+	cipherAES128GCM         = 0x2
+
+	sessionFlagGuest       = 0x1
+	sessionFlagNull        = 0x2
+	sessionFlagEncryptData = 0x4
+
+	// creditSize is the size of the payload of a credit, a request larger than it is charged more
+	// credits
+	creditSize = 64 * 1024
+	// creditTarget is the number of credits requested to be available, so that reads of several
+	// credits are not split
+	creditTarget = 128
+	// maxMessageSize bounds the size of the messages received
+	maxMessageSize = 16 << 20
+	// oplockBreakID is the message id of the notifications of the server
+	oplockBreakID = 0xffffffffffffffff
+)
+
+var (
+	protocolID  = []byte{0xfe, 'S', 'M', 'B'}
+	transformID = []byte{0xfd, 'S', 'M', 'B'}
+
+	clientDialects = []uint16{dialect202, dialect210, dialect300, dialect302, dialect311}
+)
+
+// dialectName returns the version of the SMB protocol of a dialect.
+func dialectName(dialect uint16) string {
+	switch dialect {
+	case dialect202:
+		return "2.0.2"
+	case dialect210:
+		return "2.1"
+	case dialect300:
+		return "3.0"
+	case dialect302:
+		return "3.0.2"
+	case dialect311:
+		return "3.1.1"
+	}
+	return fmt.Sprintf("0x%04x", dialect)
+}
+
+// session is an authenticated session of the connection to a server, sending one request at a
+// time.
+type session struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	server  string
+	timeout time.Duration
+
+	dialect      uint16
+	capabilities uint32
+	maxRead      uint32
+	cipher       uint16
+	preauthHash  []byte
+
+	messageID uint64
+	credits   uint64
+
+	id          uint64
+	signingKey  []byte
+	encrypter   cipher.AEAD
+	decrypter   cipher.AEAD
+	encryptData bool
+
+	trees map[string]*tree
+}
+
+// response is a response of the server.
+type response struct {
+	status uint32
+	flags  uint32
+	treeID uint32
+	// msg is the whole message, its header included
+	msg []byte
+}
+
+// body returns the body of the response, following the header.
+func (r *response) body() []byte {
+	return r.msg[headerSize:]
+}
+
+// buffer returns the bytes of the response at an offset from the start of its header, or nil if
+// they are outside of the response.
+func (r *response) buffer(offset, length uint32) []byte {
+	if uint64(offset)+uint64(length) > uint64(len(r.msg)) {
+		return nil
+	}
+	return r.msg[offset : offset+length]
+}
+
+// newSession negotiates the dialect of the connection, then authenticates the user.
+func newSession(conn net.Conn, server string, credentials Credentials, timeout time.Duration) (*session, error) {
+	s := &session{conn: conn, server: server, timeout: timeout, credits: 1, trees: map[string]*tree{}}
+	if err := s.negotiate(); err != nil {
+		return nil, err
+	}
+	if err := s.setup(credentials); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// negotiate negotiates the dialect, and with SMB 3.1.1 the cipher and the preauthentication hash.
+func (s *session) negotiate() error {
+	req := make([]byte, 36, 128)
+	binary.LittleEndian.PutUint16(req, 36)
+	binary.LittleEndian.PutUint16(req[2:], uint16(len(clientDialects)))
+	binary.LittleEndian.PutUint16(req[4:], securitySigningEnabled)
+	binary.LittleEndian.PutUint32(req[8:], capDFS|capLargeMTU|capEncryption)
+	if _, err := rand.Read(req[12:28]); err != nil {
+		return err
+	}
+	for _, dialect := range clientDialects {
+		req = append(req, byte(dialect), byte(dialect>>8))
+	}
+	// the negotiate contexts of SMB 3.1.1 are aligned on 8 bytes
+	for (headerSize+len(req))%8 != 0 {
+		req = append(req, 0)
+	}
+	binary.LittleEndian.PutUint32(req[28:], uint32(headerSize+len(req)))
+	binary.LittleEndian.PutUint16(req[32:], 2)
+	preauth := make([]byte, 38)
+	binary.LittleEndian.PutUint16(preauth, 1)
+	binary.LittleEndian.PutUint16(preauth[2:], 32)
+	binary.LittleEndian.PutUint16(preauth[4:], hashSHA512)
+	if _, err := rand.Read(preauth[6:]); err != nil {
+		return err
+	}
+	req = appendContext(req, contextPreauthIntegrity, preauth)
+	for (headerSize+len(req))%8 != 0 {
+		req = append(req, 0)
+	}
+	req = appendContext(req, contextEncryption, []byte{2, 0, cipherAES128GCM, 0, cipherAES128CCM, 0})
+
+	msg := s.header(cmdNegotiate, 0, 0, 0)
+	msg = append(msg, req...)
+	resp, err := s.roundTrip(msg, false)
+	if err != nil {
+		return err
+	}
+	if resp.status != statusSuccess {
+		return &StatusError{Status: resp.status, Op: "negotiate"}
+	}
+	body := resp.body()
+	if len(body) < 64 {
+		return errors.New("smb: invalid negotiate response")
+	}
+	s.dialect = binary.LittleEndian.Uint16(body[4:])
+	s.capabilities = binary.LittleEndian.Uint32(body[24:])
+	s.maxRead = binary.LittleEndian.Uint32(body[32:])
+	switch s.dialect {
+	case dialect202, dialect210:
+	case dialect300, dialect302:
+		if s.capabilities&capEncryption != 0 {
+			s.cipher = cipherAES128CCM
+		}
+	case dialect311:
+		s.preauthHash = make([]byte, sha512.Size)
+		s.preauthHash = preauthHash(s.preauthHash, msg)
+		s.preauthHash = preauthHash(s.preauthHash, resp.msg)
+		count := binary.LittleEndian.Uint16(body[6:])
+		offset := binary.LittleEndian.Uint32(body[60:])
+		if err := s.parseContexts(resp, offset, int(count)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("smb: unsupported dialect %s of the server", dialectName(s.dialect))
+	}
+	return nil
+}
+
+func appendContext(req []byte, typ uint16, data []byte) []byte {
+	context := make([]byte, 8, 8+len(data))
+	binary.LittleEndian.PutUint16(context, typ)
+	binary.LittleEndian.PutUint16(context[2:], uint16(len(data)))
+	return append(req, append(context, data...)...)
+}
+
+// parseContexts reads the cipher selected by the server in the negotiate contexts of its response.
+func (s *session) parseContexts(resp *response, offset uint32, count int) error {
+	for i := 0; i < count; i++ {
+		offset = (offset + 7) &^ 7
+		context := resp.buffer(offset, 8)
+		if context == nil {
+			return errors.New("smb: invalid negotiate context")
+		}
+		typ, length := binary.LittleEndian.Uint16(context), uint32(binary.LittleEndian.Uint16(context[2:]))
+		data := resp.buffer(offset+8, length)
+		if data == nil {
+			return errors.New("smb: invalid negotiate context")
+		}
+		if typ == contextEncryption && len(data) >= 4 && binary.LittleEndian.Uint16(data) == 1 {
+			s.cipher = binary.LittleEndian.Uint16(data[2:])
+		}
+		offset += 8 + length
+	}
+	return nil
+}
+
+func preauthHash(hash, msg []byte) []byte {
+	h := sha512.New()
+	h.Write(hash)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// setup authenticates the user with NTLM in the security buffers of two session setup requests,
+// then derives the keys signing and encrypting the messages of the session.
+func (s *session) setup(credentials Credentials) error {
+	hash := s.preauthHash
+	resp, err := s.sessionSetup(spnegoInit(ntlmNegotiate()), &hash)
+	if err != nil {
+		return err
+	}
+	if resp.status != statusMoreProcessingRequired {
+		return &StatusError{Status: resp.status, Op: "session setup"}
+	}
+	if hash != nil {
+		hash = preauthHash(hash, resp.msg)
+	}
+	s.id = binary.LittleEndian.Uint64(resp.msg[40:])
+	token, err := securityBuffer(resp)
+	if err != nil {
+		return err
+	}
+	if token, err = parseSPNEGOResponse(token); err != nil {
+		return err
+	}
+	challenge, err := parseNTLMChallenge(token)
+	if err != nil {
+		return err
+	}
+	auth, sessionKey, err := ntlmAuthenticate(challenge, credentials.User, credentials.Password, credentials.Domain)
+	if err != nil {
+		return err
+	}
+	resp, err = s.sessionSetup(spnegoResponse(auth), &hash)
+	if err != nil {
+		return err
+	}
+	if resp.status != statusSuccess {
+		return &authenticationError{user: credentials.qualifiedUser(), server: s.server, err: &StatusError{Status: resp.status, Op: "session setup"}}
+	}
+	body := resp.body()
+	if len(body) < 8 {
+		return errors.New("smb: invalid session setup response")
+	}
+	flags := binary.LittleEndian.Uint16(body[2:])
+	if flags&(sessionFlagGuest|sessionFlagNull) != 0 {
+		// the messages of guest sessions are not signed, a guest has no access to protected shares
+		return &authenticationError{user: credentials.qualifiedUser(), server: s.server, err: errors.New("the server granted a guest session")}
+	}
+	if err := s.deriveKeys(sessionKey, hash); err != nil {
+		return err
+	}
+	if resp.flags&flagSigned != 0 && !s.verify(resp.msg) {
+		return errors.New("smb: invalid signature of the session setup response, the keys of the session do not match")
+	}
+	if flags&sessionFlagEncryptData != 0 {
+		if s.encrypter == nil {
+			return s.encryptionError(fmt.Sprintf("the session of %s on %s", credentials.qualifiedUser(), s.server))
+		}
+		s.encryptData = true
+	}
+	return nil
+}
+
+// sessionSetup sends a session setup request with a security token, adding it to the
+// preauthentication hash of SMB 3.1.1.
+func (s *session) sessionSetup(token []byte, hash *[]byte) (*response, error) {
+	req := make([]byte, 24, 24+len(token))
+	binary.LittleEndian.PutUint16(req, 25)
+	req[3] = securitySigningEnabled
+	binary.LittleEndian.PutUint32(req[4:], capDFS)
+	binary.LittleEndian.PutUint16(req[12:], headerSize+24)
+	binary.LittleEndian.PutUint16(req[14:], uint16(len(token)))
+	req = append(req, token...)
+	msg := append(s.header(cmdSessionSetup, 0, 0, 0), req...)
+	resp, err := s.roundTrip(msg, false)
+	if err == nil && *hash != nil {
+		// the message id and the credits are set in msg when it is sent
+		*hash = preauthHash(*hash, msg)
+	}
+	return resp, err
+}
+
+func securityBuffer(resp *response) ([]byte, error) {
+	body := resp.body()
+	if len(body) < 8 {
+		return nil, errors.New("smb: invalid session setup response")
+	}
+	token := resp.buffer(uint32(binary.LittleEndian.Uint16(body[4:])), uint32(binary.LittleEndian.Uint16(body[6:])))
+	if token == nil {
+		return nil, errors.New("smb: invalid security buffer of the session setup response")
+	}
+	return token, nil
+}
+
+// deriveKeys derives the signing and encryption keys from the session key of the authentication.
+func (s *session) deriveKeys(sessionKey, hash []byte) error {
+	var encryptionKey, decryptionKey []byte
+	switch s.dialect {
+	case dialect202, dialect210:
+		s.signingKey = sessionKey
+		return nil
+	case dialect300, dialect302:
+		s.signingKey = kdf(sessionKey, []byte("SMB2AESCMAC\x00"), []byte("SmbSign\x00"))
+		encryptionKey = kdf(sessionKey, []byte("SMB2AESCCM\x00"), []byte("ServerIn \x00"))
+		decryptionKey = kdf(sessionKey, []byte("SMB2AESCCM\x00"), []byte("ServerOut\x00"))
+	default:
+		s.signingKey = kdf(sessionKey, []byte("SMBSigningKey\x00"), hash)
+		encryptionKey = kdf(sessionKey, []byte("SMBC2SCipherKey\x00"), hash)
+		decryptionKey = kdf(sessionKey, []byte("SMBS2CCipherKey\x00"), hash)
+	}
+	var err error
+	switch s.cipher {
+	case cipherAES128CCM:
+		if s.encrypter, err = newCCM(encryptionKey, 11, 16); err == nil {
+			s.decrypter, err = newCCM(decryptionKey, 11, 16)
+		}
+	case cipherAES128GCM:
+		if s.encrypter, err = newGCM(encryptionKey); err == nil {
+			s.decrypter, err = newGCM(decryptionKey)
+		}
+	}
+	return err
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptionError is the error of a session or a share requiring the encryption of its messages,
+// when the server did not negotiate a cipher.
+func (s *session) encryptionError(what string) error {
+	if s.dialect < dialect300 {
+		return fmt.Errorf("smb: %s requires encryption, which is not supported by SMB %s negotiated with the server, SMB 3 is required", what, dialectName(s.dialect))
+	}
+	return fmt.Errorf("smb: %s requires encryption, but the server did not negotiate a cipher with SMB %s", what, dialectName(s.dialect))
+}
+
+// signature returns the signature of the message with the signing key of the dialect.
+func (s *session) signature(msg []byte) []byte {
+	signed := make([]byte, len(msg))
+	copy(signed, msg)
+	copy(signed[48:64], make([]byte, 16))
+	if s.dialect < dialect300 {
+		mac := hmac.New(sha256.New, s.signingKey)
+		mac.Write(signed)
+		return mac.Sum(nil)[:16]
+	}
+	return cmac(s.signingKey, signed)
+}
+
+func (s *session) verify(msg []byte) bool {
+	return hmac.Equal(s.signature(msg), msg[48:64])
+}
+
+// header returns the header of a request of the session. The message id is set when it is sent.
+func (s *session) header(command uint16, treeID uint32, flags uint32, creditCharge uint16) []byte {
+	h := make([]byte, headerSize)
+	copy(h, protocolID)
+	binary.LittleEndian.PutUint16(h[4:], headerSize)
+	if s.dialect != dialect202 {
+		binary.LittleEndian.PutUint16(h[6:], creditCharge)
+	}
+	binary.LittleEndian.PutUint16(h[12:], command)
+	binary.LittleEndian.PutUint32(h[16:], flags)
+	binary.LittleEndian.PutUint32(h[36:], treeID)
+	binary.LittleEndian.PutUint64(h[40:], s.id)
+	return h
+}
+
+// request sends a request of the authenticated session, signed or encrypted, and returns its
+// response.
+func (s *session) request(command uint16, treeID uint32, flags uint32, creditCharge uint16, encrypt bool, body []byte) (*response, error) {
+	msg := append(s.header(command, treeID, flags, creditCharge), body...)
+	return s.roundTrip(msg, encrypt || s.encryptData)
+}
+
+// roundTrip sends a message then receives its response, skipping the interim responses of the
+// requests processed asynchronously.
+func (s *session) roundTrip(msg []byte, encrypt bool) (*response, error) {
+	if s.timeout > 0 {
+		if err := s.conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+			return nil, err
+		}
+	}
+	charge := uint64(binary.LittleEndian.Uint16(msg[6:]))
+	if charge == 0 {
+		charge = 1
+	}
+	if s.credits < charge {
+		return nil, fmt.Errorf("smb: %d credits granted by the server, %d are required", s.credits, charge)
+	}
+	s.credits -= charge
+	request := charge
+	if s.credits < creditTarget {
+		request = creditTarget - s.credits
+	}
+	binary.LittleEndian.PutUint16(msg[14:], uint16(request))
+	messageID := s.messageID
+	s.messageID += charge
+	binary.LittleEndian.PutUint64(msg[24:], messageID)
+
+	command := binary.LittleEndian.Uint16(msg[12:])
+	switch {
+	case encrypt:
+		var err error
+		if msg, err = s.encrypt(msg); err != nil {
+			return nil, err
+		}
+	case s.signingKey != nil && command != cmdSessionSetup:
+		binary.LittleEndian.PutUint32(msg[16:], binary.LittleEndian.Uint32(msg[16:])|flagSigned)
+		copy(msg[48:], s.signature(msg))
+	}
+	if err := s.write(msg); err != nil {
+		return nil, err
+	}
+
+	for {
+		resp, err := s.read()
+		if err != nil {
+			return nil, err
+		}
+		id := binary.LittleEndian.Uint64(resp.msg[24:])
+		if id == oplockBreakID {
+			continue
+		}
+		if id != messageID {
+			return nil, fmt.Errorf("smb: unexpected response to message %d, %d is expected", id, messageID)
+		}
+		s.credits += uint64(binary.LittleEndian.Uint16(resp.msg[14:]))
+		if resp.status == statusPending && resp.flags&flagAsync != 0 {
+			continue
+		}
+		return resp, nil
+	}
+}
+
+func (s *session) write(msg []byte) error {
+	frame := make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(frame, uint32(len(msg)))
+	if _, err := s.conn.Write(append(frame, msg...)); err != nil {
+		return fmt.Errorf("smb: could not send request to %s: %w", s.server, err)
+	}
+	return nil
+}
+
+// read receives a message, decrypting it if it is encrypted and verifying its signature if it
+// is signed.
+func (s *session) read() (*response, error) {
+	frame := make([]byte, 4)
+	if _, err := io.ReadFull(s.conn, frame); err != nil {
+		return nil, fmt.Errorf("smb: could not receive response from %s: %w", s.server, noEOF(err))
+	}
+	length := binary.BigEndian.Uint32(frame)
+	if frame[0] != 0 || length > maxMessageSize {
+		return nil, fmt.Errorf("smb: invalid message length %d", length)
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(s.conn, msg); err != nil {
+		return nil, fmt.Errorf("smb: could not receive response from %s: %w", s.server, noEOF(err))
+	}
+	encrypted := false
+	if len(msg) >= 4 && bytes.Equal(msg[:4], transformID) {
+		var err error
+		if msg, err = s.decrypt(msg); err != nil {
+			return nil, err
+		}
+		encrypted = true
+	}
+	if len(msg) < headerSize || !bytes.Equal(msg[:4], protocolID) {
+		return nil, errors.New("smb: invalid response header")
+	}
+	resp := &response{
+		status: binary.LittleEndian.Uint32(msg[8:]),
+		flags:  binary.LittleEndian.Uint32(msg[16:]),
+		treeID: binary.LittleEndian.Uint32(msg[36:]),
+		msg:    msg,
+	}
+	if resp.flags&flagSigned != 0 && !encrypted && s.signingKey != nil && resp.status != statusPending && !s.verify(msg) {
+		return nil, errors.New("smb: invalid signature of a response")
+	}
+	return resp, nil
+}
+
+// encrypt returns the message encrypted in a transform message.
+func (s *session) encrypt(msg []byte) ([]byte, error) {
+	if s.encrypter == nil {
+		return nil, s.encryptionError("the request")
+	}
+	header := make([]byte, transformHeaderSize)
+	copy(header, transformID)
+	if _, err := rand.Read(header[20 : 20+s.encrypter.NonceSize()]); err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(header[36:], uint32(len(msg)))
+	binary.LittleEndian.PutUint16(header[42:], 1)
+	binary.LittleEndian.PutUint64(header[44:], s.id)
+	sealed := s.encrypter.Seal(nil, header[20:20+s.encrypter.NonceSize()], msg, header[20:])
+	tag := sealed[len(msg):]
+	copy(header[4:20], tag)
+	return append(header, sealed[:len(msg)]...), nil
+}
+
+// decrypt returns the message encrypted in a transform message.
+func (s *session) decrypt(msg []byte) ([]byte, error) {
+	if len(msg) < transformHeaderSize || s.decrypter == nil || binary.LittleEndian.Uint64(msg[44:]) != s.id {
+		return nil, errors.New("smb: unexpected encrypted response")
+	}
+	sealed := append(append([]byte{}, msg[transformHeaderSize:]...), msg[4:20]...)
+	plain, err := s.decrypter.Open(nil, msg[20:20+s.decrypter.NonceSize()], sealed, msg[20:transformHeaderSize])
+	if err != nil {
+		return nil, errors.New("smb: could not decrypt a response")
+	}
+	return plain, nil
+}
+
+// close logs the session off, then closes the connection.
+func (s *session) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	req := make([]byte, 4)
+	binary.LittleEndian.PutUint16(req, 4)
+	// the connection is closed whatever the response
+	s.request(cmdLogoff, 0, 0, 0, false, req)
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// noEOF turns the end of the responses into an unexpected one, the connection is never closed by
+// the server
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// kdf derives a key of 128 bits from the session key, with the KDF in counter mode of
+// NIST SP800-108 and HMAC-SHA256, as specified by section 3.1.4.2 of [MS-SMB2].
+func kdf(key, label, context []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte{0, 0, 0, 1})
+	mac.Write(label)
+	mac.Write([]byte{0})
+	mac.Write(context)
+	mac.Write([]byte{0, 0, 0, 128})
+	return mac.Sum(nil)[:16]
+}
+
+// cmac returns the AES-CMAC of msg (RFC 4493).
+func cmac(key, msg []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		// the keys are always derived with 128 bits
+		panic(err)
+	}
+	k1 := make([]byte, aes.BlockSize)
+	block.Encrypt(k1, k1)
+	k1 = cmacSubkey(k1)
+	k2 := cmacSubkey(k1)
+
+	n := (len(msg) + aes.BlockSize - 1) / aes.BlockSize
+	last := make([]byte, aes.BlockSize)
+	if n > 0 && len(msg)%aes.BlockSize == 0 {
+		xorBytes(last, msg[(n-1)*aes.BlockSize:], k1)
+	} else {
+		if n == 0 {
+			n = 1
+		}
+		rest := msg[(n-1)*aes.BlockSize:]
+		copy(last, rest)
+		last[len(rest)] = 0x80
+		xorBytes(last, last, k2)
+	}
+	x := make([]byte, aes.BlockSize)
+	for i := 0; i < n-1; i++ {
+		xorBytes(x, x, msg[i*aes.BlockSize:])
+		block.Encrypt(x, x)
+	}
+	xorBytes(x, x, last)
+	block.Encrypt(x, x)
+	return x
+}
+
+func cmacSubkey(l []byte) []byte {
+	k := make([]byte, len(l))
+	for i := 0; i < len(l); i++ {
+		k[i] = l[i] << 1
+		if i+1 < len(l) {
+			k[i] |= l[i+1] >> 7
+		}
+	}
+	if l[0]&0x80 != 0 {
+		k[len(k)-1] ^= 0x87
+	}
+	return k
+}
+
+// xorBytes sets dst to the xor of the first len(dst) bytes of a and b.
+func xorBytes(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+// ccm implements the AES-CCM authenticated encryption (NIST SP800-38C), with a nonce and a tag of
+// the passed in sizes. SMB 3 uses a nonce of 11 bytes and a tag of 16 bytes.
+type ccm struct {
+	block     cipher.Block
+	nonceSize int
+	tagSize   int
+}
+
+var errCCMOpen = errors.New("smb: message authentication failed")
+
+func newCCM(key []byte, nonceSize, tagSize int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if nonceSize < 7 || nonceSize > 13 || tagSize < 4 || tagSize > 16 || tagSize%2 != 0 {
+		return nil, errors.New("smb: invalid CCM parameters")
+	}
+	return &ccm{block: block, nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+func (c *ccm) NonceSize() int {
+	return c.nonceSize
+}
+
+func (c *ccm) Overhead() int {
+	return c.tagSize
+}
+
+// Seal appends the encrypted plaintext followed by its tag to dst.
+func (c *ccm) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	tag := c.mac(nonce, plaintext, additionalData)
+	ret, out := sliceForAppend(dst, len(plaintext)+c.tagSize)
+	c.ctr(nonce, out, plaintext, tag)
+	copy(out[len(plaintext):], tag[:c.tagSize])
+	return ret
+}
+
+// Open appends the decrypted ciphertext to dst once its tag is verified.
+func (c *ccm) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < c.tagSize {
+		return nil, errCCMOpen
+	}
+	data := ciphertext[:len(ciphertext)-c.tagSize]
+	tag := make([]byte, aes.BlockSize)
+	copy(tag, ciphertext[len(data):])
+	ret, out := sliceForAppend(dst, len(data))
+	// the counter mode decrypts the tag along with the data
+	c.ctr(nonce, out, data, tag)
+	expected := c.mac(nonce, out, additionalData)
+	if subtle.ConstantTimeCompare(expected[:c.tagSize], tag[:c.tagSize]) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, errCCMOpen
+	}
+	return ret, nil
+}
+
+// mac returns the CBC-MAC of the formatted nonce, additional data and plaintext.
+func (c *ccm) mac(nonce, plaintext, additionalData []byte) []byte {
+	l := 15 - c.nonceSize
+	b := make([]byte, aes.BlockSize)
+	b[0] = byte((c.tagSize-2)/2<<3 | (l - 1))
+	if len(additionalData) > 0 {
+		b[0] |= 0x40
+	}
+	copy(b[1:], nonce)
+	size := uint64(len(plaintext))
+	for i := aes.BlockSize - 1; i > c.nonceSize; i-- {
+		b[i] = byte(size)
+		size >>= 8
+	}
+	c.block.Encrypt(b, b)
+
+	update := func(data []byte) {
+		for len(data) > 0 {
+			n := 0
+			for ; n < aes.BlockSize && n < len(data); n++ {
+				b[n] ^= data[n]
+			}
+			data = data[n:]
+			c.block.Encrypt(b, b)
+		}
+	}
+	if len(additionalData) > 0 {
+		// the additional data of SMB is always shorter than 2^16-2^8 bytes
+		header := make([]byte, 2, 2+len(additionalData))
+		binary.BigEndian.PutUint16(header, uint16(len(additionalData)))
+		update(append(header, additionalData...))
+	}
+	update(plaintext)
+	return b
+}
+
+// ctr xors src with the key stream into dst, and tag with the first block of the key stream.
+func (c *ccm) ctr(nonce, dst, src, tag []byte) {
+	l := 15 - c.nonceSize
+	counter := make([]byte, aes.BlockSize)
+	counter[0] = byte(l - 1)
+	copy(counter[1:], nonce)
+	stream := make([]byte, aes.BlockSize)
+	c.block.Encrypt(stream, counter)
+	xorBytes(tag[:aes.BlockSize], tag, stream)
+	for i := 0; i < len(src); i += aes.BlockSize {
+		for j := aes.BlockSize - 1; j > c.nonceSize; j-- {
+			counter[j]++
+			if counter[j] != 0 {
+				break
+			}
+		}
+		c.block.Encrypt(stream, counter)
+		n := len(src) - i
+		if n > aes.BlockSize {
+			n = aes.BlockSize
+		}
+		xorBytes(dst[i:i+n], src[i:], stream)
+	}
+}
+
+// sliceForAppend extends in by n bytes, returning the whole slice and the extension.
+func sliceForAppend(in []byte, n int) ([]byte, []byte) {
+	total := len(in) + n
+	var head []byte
+	if cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	return head, head[len(in):]
+}
@@ -0,0 +1,91 @@
+package smb
+
+import (
+	"bytes"
+	"crypto/rc4"
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	Expect(err).ToNot(HaveOccurred())
+	return b
+}
+
+var _ = Describe("Cryptography of SMB", func() {
+	// RFC 4493, section 4
+	table.DescribeTable("should compute the AES-CMAC of a message", func(msg, mac string) {
+		Expect(cmac(unhex("2b7e151628aed2a6abf7158809cf4f3c"), unhex(msg))).To(Equal(unhex(mac)))
+	},
+		table.Entry("of 0 bytes", "", "bb1d6929e95937287fa37d129b756746"),
+		table.Entry("of 16 bytes", "6bc1bee22e409f96e93d7e117393172a", "070a16b46b4d4144f79bdd9dd04a287c"),
+		table.Entry("of 40 bytes", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411", "dfa66747de9ae63030ca32611497c827"),
+		table.Entry("of 64 bytes", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710", "51f0bebf7e3b9d92fc49741779363cfe"),
+	)
+
+	// NIST SP800-38C, appendix C
+	table.DescribeTable("should encrypt and authenticate with AES-CCM", func(nonce, additionalData, plaintext, ciphertext string, tagSize int) {
+		aead, err := newCCM(unhex("404142434445464748494a4b4c4d4e4f"), len(nonce)/2, tagSize)
+		Expect(err).ToNot(HaveOccurred())
+		sealed := aead.Seal(nil, unhex(nonce), unhex(plaintext), unhex(additionalData))
+		Expect(sealed).To(Equal(unhex(ciphertext)))
+		opened, err := aead.Open(nil, unhex(nonce), sealed, unhex(additionalData))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal(unhex(plaintext)))
+		sealed[0] ^= 1
+		_, err = aead.Open(nil, unhex(nonce), sealed, unhex(additionalData))
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("example 1", "10111213141516", "0001020304050607", "20212223", "7162015b4dac255d", 4),
+		table.Entry("example 2", "1011121314151617", "000102030405060708090a0b0c0d0e0f", "202122232425262728292a2b2c2d2e2f", "d2a1f0e051ea5f62081a7792073d593d1fc64fbfaccd", 6),
+		table.Entry("example 3", "101112131415161718191a1b", "000102030405060708090a0b0c0d0e0f10111213", "202122232425262728292a2b2c2d2e2f3031323334353637", "e3b201a9f5b71a7a9b1ceaeccd97e70b6176aad9a4428aa5484392fbc1b09951", 8),
+	)
+
+	It("should round trip AES-CCM with the nonce and the tag of SMB 3", func() {
+		aead, err := newCCM(bytes.Repeat([]byte{7}, 16), 11, 16)
+		Expect(err).ToNot(HaveOccurred())
+		plaintext := bytes.Repeat([]byte("smb"), 1000)
+		nonce := bytes.Repeat([]byte{1}, 11)
+		sealed := aead.Seal(nil, nonce, plaintext, []byte("header"))
+		Expect(sealed).To(HaveLen(len(plaintext) + 16))
+		opened, err := aead.Open(nil, nonce, sealed, []byte("header"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal(plaintext))
+		_, err = aead.Open(nil, nonce, sealed, []byte("Header"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	// [MS-NLMP], section 4.2.4
+	It("should compute the NTLMv2 responses", func() {
+		targetInfo := append([]byte{2, 0, 12, 0}, utf16le("Domain")...)
+		targetInfo = append(targetInfo, 1, 0, 12, 0)
+		targetInfo = append(targetInfo, utf16le("Server")...)
+		targetInfo = append(targetInfo, 0, 0, 0, 0)
+		responseKey := ntowfv2("User", "Password", "Domain")
+		Expect(responseKey).To(Equal(unhex("0c868a403bfd7a93a3001ef22ef02e3f")))
+		nt, lm, sessionBaseKey := ntlmv2Response(responseKey, unhex("0123456789abcdef"), unhex("aaaaaaaaaaaaaaaa"), 0, targetInfo)
+		Expect(nt[:16]).To(Equal(unhex("68cd0ab851e51c96aabc927bebef6a1c")))
+		Expect(lm).To(Equal(unhex("86c35097ac9cec102554764a57cccc19aaaaaaaaaaaaaaaa")))
+		Expect(sessionBaseKey).To(Equal(unhex("8de40ccadbc14a82f15cb0ad0de95ca3")))
+		cipher, err := rc4.NewCipher(sessionBaseKey)
+		Expect(err).ToNot(HaveOccurred())
+		encryptedKey := make([]byte, 16)
+		cipher.XORKeyStream(encryptedKey, bytes.Repeat([]byte{0x55}, 16))
+		Expect(encryptedKey).To(Equal(unhex("c5dad2544fc9799094ce1ce90bc9d03e")))
+	})
+
+	It("should parse the token of a SPNEGO response", func() {
+		token := der(0xa1, der(0x30, append(der(0xa0, der(0x0a, []byte{1})), der(0xa2, der(0x04, bytes.Repeat([]byte{1}, 300)))...)))
+		parsed, err := parseSPNEGOResponse(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed).To(Equal(bytes.Repeat([]byte{1}, 300)))
+
+		rejected := der(0xa1, der(0x30, der(0xa0, der(0x0a, []byte{2}))))
+		_, err = parseSPNEGOResponse(rejected)
+		Expect(err).To(MatchError(ContainSubstring("rejected")))
+	})
+})
@@ -0,0 +1,310 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smb
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rc4"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// The NTLM authentication ([MS-NLMP]) of the user, with the NTLMv2 responses, wrapped in the SPNEGO
+// tokens (RFC 4178) of the session setup requests.
+
+const (
+	ntlmNegotiateUnicode                 = 0x00000001
+	ntlmRequestTarget                    = 0x00000004
+	ntlmNegotiateSign                    = 0x00000010
+	ntlmNegotiateNTLM                    = 0x00000200
+	ntlmNegotiateAlwaysSign              = 0x00008000
+	ntlmNegotiateExtendedSessionSecurity = 0x00080000
+	ntlmNegotiateTargetInfo              = 0x00800000
+	ntlmNegotiate128                     = 0x20000000
+	ntlmNegotiateKeyExchange             = 0x40000000
+	ntlmNegotiate56                      = 0x80000000
+
+	ntlmClientFlags = ntlmNegotiateUnicode | ntlmRequestTarget | ntlmNegotiateSign | ntlmNegotiateNTLM |
+		ntlmNegotiateAlwaysSign | ntlmNegotiateExtendedSessionSecurity | ntlmNegotiateTargetInfo |
+		ntlmNegotiate128 | ntlmNegotiateKeyExchange | ntlmNegotiate56
+
+	ntlmNegotiateMessage    = 1
+	ntlmChallengeMessage    = 2
+	ntlmAuthenticateMessage = 3
+
+	// avTimestamp is the id of the attribute holding the time of the server in the target info
+	avTimestamp = 7
+	avEOL       = 0
+)
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmNegotiate returns the NEGOTIATE_MESSAGE starting the authentication.
+func ntlmNegotiate() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], ntlmNegotiateMessage)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmClientFlags)
+	return msg
+}
+
+// ntlmChallenge holds the fields of the CHALLENGE_MESSAGE of the server.
+type ntlmChallenge struct {
+	flags      uint32
+	challenge  []byte
+	targetInfo []byte
+}
+
+func parseNTLMChallenge(msg []byte) (*ntlmChallenge, error) {
+	if len(msg) < 48 || !bytes.Equal(msg[:8], ntlmSignature) || binary.LittleEndian.Uint32(msg[8:]) != ntlmChallengeMessage {
+		return nil, errors.New("smb: invalid NTLM challenge of the server")
+	}
+	c := &ntlmChallenge{
+		flags:     binary.LittleEndian.Uint32(msg[20:]),
+		challenge: msg[24:32],
+	}
+	length, offset := int(binary.LittleEndian.Uint16(msg[40:])), int(binary.LittleEndian.Uint32(msg[44:]))
+	if offset+length > len(msg) {
+		return nil, errors.New("smb: invalid target info in the NTLM challenge of the server")
+	}
+	c.targetInfo = msg[offset : offset+length]
+	return c, nil
+}
+
+// avPair returns the value of an attribute of the target info, or nil.
+func avPair(targetInfo []byte, id uint16) []byte {
+	for len(targetInfo) >= 4 {
+		avID, length := binary.LittleEndian.Uint16(targetInfo), int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if avID == avEOL || len(targetInfo) < 4+length {
+			return nil
+		}
+		if avID == id {
+			return targetInfo[4 : 4+length]
+		}
+		targetInfo = targetInfo[4+length:]
+	}
+	return nil
+}
+
+// ntowfv2 returns the NTLMv2 hash of the password of the user.
+func ntowfv2(user, password, domain string) []byte {
+	h := md4.New()
+	h.Write(utf16le(password))
+	mac := hmac.New(md5.New, h.Sum(nil))
+	mac.Write(utf16le(strings.ToUpper(user) + domain))
+	return mac.Sum(nil)
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// ntlmv2Response returns the NTLMv2 and LMv2 responses to the challenge, and the session base key.
+func ntlmv2Response(responseKey, serverChallenge, clientChallenge []byte, timestamp uint64, targetInfo []byte) ([]byte, []byte, []byte) {
+	temp := make([]byte, 28, 28+len(targetInfo)+4)
+	temp[0], temp[1] = 1, 1
+	binary.LittleEndian.PutUint64(temp[8:], timestamp)
+	copy(temp[16:], clientChallenge)
+	temp = append(temp, targetInfo...)
+	temp = append(temp, 0, 0, 0, 0)
+	proof := hmacMD5(responseKey, serverChallenge, temp)
+	lm := append(hmacMD5(responseKey, serverChallenge, clientChallenge), clientChallenge...)
+	return append(proof, temp...), lm, hmacMD5(responseKey, proof)
+}
+
+// fileTime returns the time in 100ns intervals since January 1, 1601 (UTC).
+func fileTime(t time.Time) uint64 {
+	return uint64(t.UnixNano()/100) + 116444736000000000
+}
+
+// ntlmAuthenticate returns the AUTHENTICATE_MESSAGE answering the challenge with the password of
+// the user, and the session key it exports.
+func ntlmAuthenticate(challenge *ntlmChallenge, user, password, domain string) ([]byte, []byte, error) {
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, nil, err
+	}
+	timestamp := fileTime(time.Now())
+	serverTime := avPair(challenge.targetInfo, avTimestamp)
+	if len(serverTime) == 8 {
+		timestamp = binary.LittleEndian.Uint64(serverTime)
+	}
+	responseKey := ntowfv2(user, password, domain)
+	nt, lm, sessionBaseKey := ntlmv2Response(responseKey, challenge.challenge, clientChallenge, timestamp, challenge.targetInfo)
+	if len(serverTime) == 8 {
+		// the LMv2 response is not sent when the server sends its time
+		lm = make([]byte, 24)
+	}
+
+	flags := challenge.flags & ntlmClientFlags
+	sessionKey := sessionBaseKey
+	var encryptedKey []byte
+	if flags&ntlmNegotiateKeyExchange != 0 {
+		sessionKey = make([]byte, 16)
+		if _, err := rand.Read(sessionKey); err != nil {
+			return nil, nil, err
+		}
+		cipher, err := rc4.NewCipher(sessionBaseKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		encryptedKey = make([]byte, 16)
+		cipher.XORKeyStream(encryptedKey, sessionKey)
+	}
+
+	// the message has no version and no MIC
+	msg := make([]byte, 64)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], ntlmAuthenticateMessage)
+	field := func(at int, value []byte) {
+		binary.LittleEndian.PutUint16(msg[at:], uint16(len(value)))
+		binary.LittleEndian.PutUint16(msg[at+2:], uint16(len(value)))
+		binary.LittleEndian.PutUint32(msg[at+4:], uint32(len(msg)))
+		msg = append(msg, value...)
+	}
+	field(12, lm)
+	field(20, nt)
+	field(28, utf16le(domain))
+	field(36, utf16le(user))
+	field(44, nil)
+	field(52, encryptedKey)
+	binary.LittleEndian.PutUint32(msg[60:], flags)
+	return msg, sessionKey, nil
+}
+
+func utf16le(s string) []byte {
+	codes := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(codes))
+	for i, c := range codes {
+		binary.LittleEndian.PutUint16(b[2*i:], c)
+	}
+	return b
+}
+
+func fromUTF16le(b []byte) string {
+	codes := make([]uint16, len(b)/2)
+	for i := range codes {
+		codes[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(codes))
+}
+
+var (
+	// spnegoOID is 1.3.6.1.5.5.2
+	spnegoOID = []byte{0x2b, 0x06, 0x01, 0x05, 0x05, 0x02}
+	// ntlmsspOID is 1.3.6.1.4.1.311.2.2.10
+	ntlmsspOID = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x02, 0x02, 0x0a}
+)
+
+// spnegoInit wraps the NTLM negotiate message in the NegTokenInit of the GSS-API initial token.
+func spnegoInit(token []byte) []byte {
+	mechTypes := der(0xa0, der(0x30, der(0x06, ntlmsspOID)))
+	negTokenInit := der(0xa0, der(0x30, append(mechTypes, der(0xa2, der(0x04, token))...)))
+	return der(0x60, append(der(0x06, spnegoOID), negTokenInit...))
+}
+
+// spnegoResponse wraps the NTLM authenticate message in a NegTokenResp.
+func spnegoResponse(token []byte) []byte {
+	return der(0xa1, der(0x30, der(0xa2, der(0x04, token))))
+}
+
+// parseSPNEGOResponse returns the token of the NegTokenResp of the server.
+func parseSPNEGOResponse(msg []byte) ([]byte, error) {
+	invalid := errors.New("smb: invalid SPNEGO response of the server")
+	resp, _, ok := parseDER(msg, 0xa1)
+	if !ok {
+		return nil, invalid
+	}
+	if resp, _, ok = parseDER(resp, 0x30); !ok {
+		return nil, invalid
+	}
+	var token []byte
+	for len(resp) > 0 {
+		if len(resp) < 2 {
+			return nil, invalid
+		}
+		tag := resp[0]
+		value, rest, ok := parseDER(resp, tag)
+		if !ok {
+			return nil, invalid
+		}
+		resp = rest
+		switch tag {
+		case 0xa0:
+			// reject
+			if state, _, ok := parseDER(value, 0x0a); ok && len(state) == 1 && state[0] == 2 {
+				return nil, errors.New("smb: the server rejected the NTLM authentication")
+			}
+		case 0xa2:
+			if token, _, ok = parseDER(value, 0x04); !ok {
+				return nil, invalid
+			}
+		}
+	}
+	return token, nil
+}
+
+// der returns the DER encoding of a value with the passed in tag.
+func der(tag byte, value []byte) []byte {
+	length := len(value)
+	var encoded []byte
+	switch {
+	case length < 0x80:
+		encoded = []byte{tag, byte(length)}
+	case length < 0x100:
+		encoded = []byte{tag, 0x81, byte(length)}
+	default:
+		encoded = []byte{tag, 0x82, byte(length >> 8), byte(length)}
+	}
+	return append(encoded, value...)
+}
+
+// parseDER returns the value of the DER encoded data, if it has the passed in tag, and the data
+// following it.
+func parseDER(data []byte, tag byte) ([]byte, []byte, bool) {
+	if len(data) < 2 || data[0] != tag {
+		return nil, nil, false
+	}
+	length, data := int(data[1]), data[2:]
+	if length >= 0x80 {
+		n := length & 0x7f
+		if n == 0 || n > 3 || len(data) < n {
+			return nil, nil, false
+		}
+		length = 0
+		for _, b := range data[:n] {
+			length = length<<8 | int(b)
+		}
+		data = data[n:]
+	}
+	if len(data) < length {
+		return nil, nil, false
+	}
+	return data[:length], data[length:], true
+}
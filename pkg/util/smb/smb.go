@@ -0,0 +1,638 @@
+/*
+Copyright 2022 The CDI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package smb implements a client reading files of SMB shares with versions 2 and 3 of the SMB
+// protocol ([MS-SMB2]), without mounting them. The user is authenticated with NTLMv2 ([MS-NLMP]),
+// the messages are signed, and encrypted with SMB 3 when the session or the share requires it. The
+// links of DFS namespaces ([MS-DFSC]) are followed to the shares they refer to.
+package smb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	// DefaultPort is the port of the SMB servers
+	DefaultPort = "445"
+
+	statusSuccess                = 0x00000000
+	statusPending                = 0x00000103
+	statusInvalidParameter       = 0xc000000d
+	statusEndOfFile              = 0xc0000011
+	statusMoreProcessingRequired = 0xc0000016
+	statusAccessDenied           = 0xc0000022
+	statusObjectNameNotFound     = 0xc0000034
+	statusObjectPathNotFound     = 0xc000003a
+	statusSharingViolation       = 0xc0000043
+	statusLogonFailure           = 0xc000006d
+	statusAccountRestriction     = 0xc000006e
+	statusPasswordExpired        = 0xc0000071
+	statusAccountDisabled        = 0xc0000072
+	statusFileIsADirectory       = 0xc00000ba
+	statusNotSupported           = 0xc00000bb
+	statusBadNetworkName         = 0xc00000cc
+	statusFSDriverRequired       = 0xc000019c
+	statusNotFound               = 0xc0000225
+	statusPathNotCovered         = 0xc0000257
+
+	shareFlagEncryptData = 0x00008000
+	shareCapDFS          = 0x00000008
+
+	fileReadData         = 0x00000001
+	fileReadAttributes   = 0x00000080
+	readControl          = 0x00020000
+	synchronize          = 0x00100000
+	fileShareRead        = 0x00000001
+	fileOpen             = 0x00000001
+	fileNonDirectoryFile = 0x00000040
+	impersonation        = 0x00000002
+	fileAttributeDir     = 0x00000010
+
+	fsctlDFSGetReferrals = 0x00060194
+	ioctlIsFsctl         = 0x00000001
+	maxReferralLevel     = 4
+	nameListReferral     = 0x0002
+	maxReferralResponse  = 64 * 1024
+
+	// maxReferrals bounds the DFS referrals followed to open a file, a link may refer to another
+	// namespace
+	maxReferrals = 8
+	// maxReadSize is the largest size of a read request
+	maxReadSize = 1 << 20
+	// defaultTimeout bounds the connection to a server and each request
+	defaultTimeout = time.Minute
+)
+
+var statusNames = map[uint32]string{
+	statusInvalidParameter:   "STATUS_INVALID_PARAMETER",
+	statusEndOfFile:          "STATUS_END_OF_FILE",
+	statusAccessDenied:       "STATUS_ACCESS_DENIED",
+	statusObjectNameNotFound: "STATUS_OBJECT_NAME_NOT_FOUND",
+	statusObjectPathNotFound: "STATUS_OBJECT_PATH_NOT_FOUND",
+	statusSharingViolation:   "STATUS_SHARING_VIOLATION",
+	statusLogonFailure:       "STATUS_LOGON_FAILURE",
+	statusAccountRestriction: "STATUS_ACCOUNT_RESTRICTION",
+	statusPasswordExpired:    "STATUS_PASSWORD_EXPIRED",
+	statusAccountDisabled:    "STATUS_ACCOUNT_DISABLED",
+	statusFileIsADirectory:   "STATUS_FILE_IS_A_DIRECTORY",
+	statusNotSupported:       "STATUS_NOT_SUPPORTED",
+	statusBadNetworkName:     "STATUS_BAD_NETWORK_NAME",
+	statusFSDriverRequired:   "STATUS_FS_DRIVER_REQUIRED",
+	statusNotFound:           "STATUS_NOT_FOUND",
+	statusPathNotCovered:     "STATUS_PATH_NOT_COVERED",
+}
+
+// StatusError is the error status of a request.
+type StatusError struct {
+	Status uint32
+	Op     string
+}
+
+func (e *StatusError) Error() string {
+	if name, ok := statusNames[e.Status]; ok {
+		return fmt.Sprintf("smb: %s: %s", e.Op, name)
+	}
+	return fmt.Sprintf("smb: %s: status 0x%08x", e.Op, e.Status)
+}
+
+// IsNotExist returns true if the error is the status of a share or a file that does not exist.
+func IsNotExist(err error) bool {
+	return isStatus(err, statusObjectNameNotFound) || isStatus(err, statusObjectPathNotFound) || isStatus(err, statusBadNetworkName)
+}
+
+func isStatus(err error, status uint32) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Status == status
+}
+
+// authenticationError is the error of a user who could not authenticate.
+type authenticationError struct {
+	user   string
+	server string
+	err    error
+}
+
+func (e *authenticationError) Error() string {
+	return fmt.Sprintf("smb: authentication of %s on %s failed: %v", e.user, e.server, e.err)
+}
+
+func (e *authenticationError) Unwrap() error {
+	return e.err
+}
+
+// Credentials are the credentials of the user reading the shares.
+type Credentials struct {
+	User     string
+	Password string
+	// Domain is the domain of the user, empty for a local user of the server
+	Domain string
+}
+
+func (c Credentials) qualifiedUser() string {
+	if c.Domain == "" {
+		return c.User
+	}
+	return c.Domain + `\` + c.User
+}
+
+// uncPath is the path of a file of a share, \\server\share\name.
+type uncPath struct {
+	server string
+	share  string
+	name   string
+}
+
+func (p uncPath) String() string {
+	return `\` + p.dfsPath()
+}
+
+// dfsPath is the path of a file of a DFS share, and of a DFS referral, \server\share\name.
+func (p uncPath) dfsPath() string {
+	path := `\` + p.server + `\` + p.share
+	if p.name != "" {
+		path += `\` + p.name
+	}
+	return path
+}
+
+// parseUNCPath parses a \\server\share\name path, with one or two leading backslashes.
+func parseUNCPath(path string) (uncPath, bool) {
+	parts := strings.SplitN(strings.TrimLeft(path, `\`), `\`, 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return uncPath{}, false
+	}
+	p := uncPath{server: parts[0], share: parts[1]}
+	if len(parts) == 3 {
+		p.name = strings.Trim(parts[2], `\`)
+	}
+	return p, true
+}
+
+// Client opens files of SMB shares, over a session per server.
+type Client struct {
+	credentials Credentials
+	// Dial connects to the address of a server, host:port
+	Dial func(addr string) (net.Conn, error)
+	// Timeout bounds each request
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// NewClient returns a client authenticating the user with the passed in credentials.
+func NewClient(credentials Credentials) *Client {
+	return &Client{
+		credentials: credentials,
+		Dial: func(addr string) (net.Conn, error) {
+			return net.DialTimeout("tcp", addr, defaultTimeout)
+		},
+		Timeout:  defaultTimeout,
+		sessions: map[string]*session{},
+	}
+}
+
+// Open opens the file of a share for reading, name being its path in the share, separated by
+// slashes or backslashes. The server may be followed by a port, 445 is the default. The DFS links
+// of the path are followed.
+func (c *Client) Open(server, share, name string) (*File, error) {
+	name = strings.Trim(strings.ReplaceAll(name, "/", `\`), `\`)
+	if server == "" || share == "" || name == "" {
+		return nil, errors.New("smb: a server, a share and a file are required")
+	}
+	return c.open(uncPath{server: server, share: share, name: name}, 0)
+}
+
+func (c *Client) open(path uncPath, referrals int) (*File, error) {
+	s, err := c.session(path.server)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.treeConnect(path.share)
+	if err != nil {
+		if isStatus(err, statusBadNetworkName) {
+			// the share may be the namespace of a domain-based DFS, referred to by the domain
+			// controllers
+			if targets, referralErr := s.referral(path); referralErr == nil {
+				return c.follow(path, targets, referrals)
+			}
+		}
+		return nil, err
+	}
+	f, err := t.open(path)
+	if isStatus(err, statusPathNotCovered) {
+		targets, err := s.referral(path)
+		if err != nil {
+			return nil, fmt.Errorf("smb: %s is a DFS link whose referral failed: %w", path, err)
+		}
+		return c.follow(path, targets, referrals)
+	}
+	return f, err
+}
+
+// follow opens the file on the first of the DFS targets of its path that can be opened.
+func (c *Client) follow(path uncPath, targets []uncPath, referrals int) (*File, error) {
+	if referrals == maxReferrals {
+		return nil, fmt.Errorf("smb: more than %d DFS referrals were followed to open %s", maxReferrals, path)
+	}
+	var err error
+	for _, target := range targets {
+		var f *File
+		if f, err = c.open(target, referrals+1); err == nil {
+			return f, nil
+		}
+		err = fmt.Errorf("smb: DFS target %s of %s: %w", target, path, err)
+	}
+	return nil, err
+}
+
+// session returns the session of the server, connecting to it and authenticating the user the
+// first time.
+func (c *Client) session(server string) (*session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := strings.ToLower(server)
+	if s, ok := c.sessions[key]; ok {
+		return s, nil
+	}
+	addr, host := server, server
+	if h, _, err := net.SplitHostPort(server); err == nil {
+		host = h
+	} else {
+		addr = net.JoinHostPort(server, DefaultPort)
+	}
+	conn, err := c.Dial(addr)
+	if err != nil {
+		return nil, fmt.Errorf("smb: could not connect to %s: %w", addr, err)
+	}
+	s, err := newSession(conn, host, c.credentials, c.Timeout)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c.sessions[key] = s
+	return s, nil
+}
+
+// Close logs off the sessions and closes the connections to the servers.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var err error
+	for key, s := range c.sessions {
+		if closeErr := s.close(); closeErr != nil {
+			err = closeErr
+		}
+		delete(c.sessions, key)
+	}
+	return err
+}
+
+// tree is a share connected in a session.
+type tree struct {
+	s            *session
+	id           uint32
+	share        string
+	dfs          bool
+	encrypt      bool
+	capabilities uint32
+}
+
+// treeConnect connects to a share of the server, once per session.
+func (s *session) treeConnect(share string) (*tree, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(share)
+	if t, ok := s.trees[key]; ok {
+		return t, nil
+	}
+	unc := `\\` + s.server + `\` + share
+	path := utf16le(unc)
+	req := make([]byte, 8, 8+len(path))
+	binary.LittleEndian.PutUint16(req, 9)
+	binary.LittleEndian.PutUint16(req[4:], headerSize+8)
+	binary.LittleEndian.PutUint16(req[6:], uint16(len(path)))
+	resp, err := s.request(cmdTreeConnect, 0, 0, 1, false, append(req, path...))
+	if err != nil {
+		return nil, err
+	}
+	if resp.status != statusSuccess {
+		err := &StatusError{Status: resp.status, Op: "connect to share " + unc}
+		if resp.status == statusAccessDenied && s.encrypter == nil {
+			return nil, fmt.Errorf("%w, the share may require encryption, which is not supported by SMB %s negotiated with the server", err, dialectName(s.dialect))
+		}
+		return nil, err
+	}
+	body := resp.body()
+	if len(body) < 16 {
+		return nil, errors.New("smb: invalid tree connect response")
+	}
+	t := &tree{
+		s:            s,
+		id:           resp.treeID,
+		share:        share,
+		capabilities: binary.LittleEndian.Uint32(body[8:]),
+	}
+	t.dfs = t.capabilities&shareCapDFS != 0
+	if binary.LittleEndian.Uint32(body[4:])&shareFlagEncryptData != 0 && !s.encryptData {
+		if s.encrypter == nil {
+			req := make([]byte, 4)
+			binary.LittleEndian.PutUint16(req, 4)
+			s.request(cmdTreeDisconnect, t.id, 0, 1, false, req)
+			return nil, s.encryptionError("share " + unc)
+		}
+		t.encrypt = true
+	}
+	s.trees[key] = t
+	return t, nil
+}
+
+// open opens the file of the path, named by its whole path in a DFS share.
+func (t *tree) open(path uncPath) (*File, error) {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := path.name
+	var flags uint32
+	if t.dfs {
+		flags = flagDFSOperations
+		name = strings.TrimPrefix(path.dfsPath(), `\`)
+	}
+	encoded := utf16le(name)
+	req := make([]byte, 56, 56+len(encoded))
+	binary.LittleEndian.PutUint16(req, 57)
+	binary.LittleEndian.PutUint32(req[4:], impersonation)
+	binary.LittleEndian.PutUint32(req[24:], fileReadData|fileReadAttributes|readControl|synchronize)
+	binary.LittleEndian.PutUint32(req[32:], fileShareRead)
+	binary.LittleEndian.PutUint32(req[36:], fileOpen)
+	binary.LittleEndian.PutUint32(req[40:], fileNonDirectoryFile)
+	binary.LittleEndian.PutUint16(req[44:], headerSize+56)
+	binary.LittleEndian.PutUint16(req[46:], uint16(len(encoded)))
+	req = append(req, encoded...)
+	resp, err := s.request(cmdCreate, t.id, flags, 1, t.encrypt, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.status != statusSuccess {
+		return nil, &StatusError{Status: resp.status, Op: "open " + path.String()}
+	}
+	body := resp.body()
+	if len(body) < 88 {
+		return nil, errors.New("smb: invalid create response")
+	}
+	f := &File{
+		t:       t,
+		id:      append([]byte{}, body[64:80]...),
+		path:    path,
+		size:    int64(binary.LittleEndian.Uint64(body[48:])),
+		modTime: binary.LittleEndian.Uint64(body[24:]),
+	}
+	if binary.LittleEndian.Uint32(body[56:])&fileAttributeDir != 0 {
+		f.close()
+		return nil, fmt.Errorf("smb: %s is a directory", path)
+	}
+	return f, nil
+}
+
+// referral returns the targets of the DFS path, requested to the server.
+func (s *session) referral(path uncPath) ([]uncPath, error) {
+	ipc, err := s.treeConnect("IPC$")
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dfsPath := path.dfsPath()
+	input := append([]byte{maxReferralLevel, 0}, utf16le(dfsPath)...)
+	input = append(input, 0, 0)
+	req := make([]byte, 56, 56+len(input))
+	binary.LittleEndian.PutUint16(req, 57)
+	binary.LittleEndian.PutUint32(req[4:], fsctlDFSGetReferrals)
+	copy(req[8:24], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	binary.LittleEndian.PutUint32(req[24:], headerSize+56)
+	binary.LittleEndian.PutUint32(req[28:], uint32(len(input)))
+	binary.LittleEndian.PutUint32(req[44:], maxReferralResponse)
+	binary.LittleEndian.PutUint32(req[48:], ioctlIsFsctl)
+	req = append(req, input...)
+	resp, err := s.request(cmdIoctl, ipc.id, 0, 1, ipc.encrypt, req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.status {
+	case statusSuccess:
+	case statusNotFound, statusFSDriverRequired:
+		return nil, fmt.Errorf("smb: %s is not a DFS path of %s", path, s.server)
+	default:
+		return nil, &StatusError{Status: resp.status, Op: "DFS referral of " + path.String()}
+	}
+	body := resp.body()
+	if len(body) < 48 {
+		return nil, errors.New("smb: invalid ioctl response")
+	}
+	out := resp.buffer(binary.LittleEndian.Uint32(body[32:]), binary.LittleEndian.Uint32(body[36:]))
+	if out == nil {
+		return nil, errors.New("smb: invalid ioctl response")
+	}
+	return parseReferral(out, path)
+}
+
+// parseReferral returns the targets of a DFS referral response, the targets followed by the part
+// of the path not consumed by the referral.
+func parseReferral(data []byte, path uncPath) ([]uncPath, error) {
+	invalid := fmt.Errorf("smb: invalid DFS referral of %s", path)
+	if len(data) < 8 {
+		return nil, invalid
+	}
+	units := utf16.Encode([]rune(path.dfsPath()))
+	consumed := int(binary.LittleEndian.Uint16(data)) / 2
+	if consumed > len(units) {
+		consumed = len(units)
+	}
+	rest := string(utf16.Decode(units[consumed:]))
+	count := int(binary.LittleEndian.Uint16(data[2:]))
+	var targets []uncPath
+	offset := 8
+	for i := 0; i < count; i++ {
+		if offset+8 > len(data) {
+			return nil, invalid
+		}
+		entry := data[offset:]
+		version, size, flags := binary.LittleEndian.Uint16(entry), int(binary.LittleEndian.Uint16(entry[2:])), binary.LittleEndian.Uint16(entry[6:])
+		var address string
+		switch version {
+		case 1:
+			address = readUTF16z(entry[8:])
+		case 2:
+			if len(entry) < 22 {
+				return nil, invalid
+			}
+			address = readUTF16z(sliceFrom(entry, int(binary.LittleEndian.Uint16(entry[20:]))))
+		case 3, 4:
+			if flags&nameListReferral != 0 {
+				return nil, fmt.Errorf("smb: %s is a domain, whose DFS referral to its domain controllers is not supported, name a namespace of the domain", path)
+			}
+			if len(entry) < 18 {
+				return nil, invalid
+			}
+			address = readUTF16z(sliceFrom(entry, int(binary.LittleEndian.Uint16(entry[16:]))))
+		default:
+			return nil, fmt.Errorf("smb: unsupported version %d of the DFS referral of %s", version, path)
+		}
+		if target, ok := parseUNCPath(address + rest); ok {
+			targets = append(targets, target)
+		}
+		if size == 0 {
+			break
+		}
+		offset += size
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("smb: the DFS referral of %s has no target", path)
+	}
+	return targets, nil
+}
+
+func sliceFrom(b []byte, offset int) []byte {
+	if offset > len(b) {
+		return nil
+	}
+	return b[offset:]
+}
+
+// readUTF16z reads a null terminated UTF-16 string.
+func readUTF16z(b []byte) string {
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0 && b[i+1] == 0 {
+			return fromUTF16le(b[:i])
+		}
+	}
+	return fromUTF16le(b)
+}
+
+// File is a file of a share opened for reading.
+type File struct {
+	t       *tree
+	id      []byte
+	path    uncPath
+	size    int64
+	modTime uint64
+}
+
+// Path returns the path of the file, \\server\share\name, on the target of its DFS links.
+func (f *File) Path() string {
+	return f.path.String()
+}
+
+// Size returns the size of the file when it was opened.
+func (f *File) Size() int64 {
+	return f.size
+}
+
+// ModTime returns the time the file was last written when it was opened.
+func (f *File) ModTime() time.Time {
+	const epochDelta = 116444736000000000
+	return time.Unix(0, (int64(f.modTime)-epochDelta)*100).UTC()
+}
+
+// ReadAt reads len(p) bytes of the file from offset off, with reads of at most the size the
+// server allows and the credits it granted.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	s := f.t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	read := 0
+	for read < len(p) {
+		size := len(p) - read
+		if limit := s.readSize(); size > limit {
+			size = limit
+		}
+		charge := (size + creditSize - 1) / creditSize
+		if s.dialect != dialect202 && uint64(charge) > s.credits && s.credits > 0 {
+			charge = int(s.credits)
+			size = charge * creditSize
+		}
+		req := make([]byte, 49)
+		binary.LittleEndian.PutUint16(req, 49)
+		req[2] = headerSize + 16
+		binary.LittleEndian.PutUint32(req[4:], uint32(size))
+		binary.LittleEndian.PutUint64(req[8:], uint64(off)+uint64(read))
+		copy(req[16:32], f.id)
+		resp, err := s.request(cmdRead, f.t.id, 0, uint16(charge), f.t.encrypt, req)
+		if err != nil {
+			return read, err
+		}
+		if resp.status == statusEndOfFile {
+			return read, io.EOF
+		}
+		if resp.status != statusSuccess {
+			return read, &StatusError{Status: resp.status, Op: "read " + f.path.String()}
+		}
+		body := resp.body()
+		if len(body) < 16 {
+			return read, errors.New("smb: invalid read response")
+		}
+		data := resp.buffer(uint32(body[2]), binary.LittleEndian.Uint32(body[4:]))
+		if data == nil || len(data) > size {
+			return read, errors.New("smb: invalid data of a read response")
+		}
+		if len(data) == 0 {
+			return read, io.EOF
+		}
+		read += copy(p[read:], data)
+	}
+	return read, nil
+}
+
+// readSize returns the largest size of a read request.
+func (s *session) readSize() int {
+	size := maxReadSize
+	if s.dialect == dialect202 || s.capabilities&capLargeMTU == 0 {
+		size = creditSize
+	}
+	if s.maxRead > 0 && int(s.maxRead) < size {
+		size = int(s.maxRead)
+	}
+	return size
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	f.t.s.mu.Lock()
+	defer f.t.s.mu.Unlock()
+	return f.close()
+}
+
+func (f *File) close() error {
+	req := make([]byte, 24)
+	binary.LittleEndian.PutUint16(req, 24)
+	copy(req[8:], f.id)
+	resp, err := f.t.s.request(cmdClose, f.t.id, 0, 1, f.t.encrypt, req)
+	if err != nil {
+		return err
+	}
+	if resp.status != statusSuccess {
+		return &StatusError{Status: resp.status, Op: "close " + f.path.String()}
+	}
+	return nil
+}
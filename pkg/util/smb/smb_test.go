@@ -0,0 +1,669 @@
+package smb
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rc4"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// fakeShare is a share of a fake server, its files are named by their path in the share.
+type fakeShare struct {
+	files   map[string][]byte
+	encrypt bool
+	dfs     bool
+	// links are the DFS links of the share, requests of their paths are not covered
+	links []string
+}
+
+// fakeServer serves the shares of a map over pipes, to the user of its credentials. Its referrals
+// map DFS paths, \server\share[\link], to their targets.
+type fakeServer struct {
+	name           string
+	dialect        uint16
+	cipher         uint16
+	credentials    Credentials
+	encryptSession bool
+	shares         map[string]*fakeShare
+	referrals      map[string][]string
+	maxRead        uint32
+	grant          uint16
+
+	mu        sync.Mutex
+	encrypted map[uint16]int
+	signed    map[uint16]int
+	reads     int
+}
+
+func newFakeServer(name string, dialect uint16) *fakeServer {
+	return &fakeServer{
+		name:        name,
+		dialect:     dialect,
+		cipher:      cipherAES128GCM,
+		credentials: Credentials{User: "importer", Password: "s3cret", Domain: "EXAMPLE"},
+		shares:      map[string]*fakeShare{},
+		referrals:   map[string][]string{},
+		maxRead:     maxReadSize,
+		grant:       32,
+		encrypted:   map[uint16]int{},
+		signed:      map[uint16]int{},
+	}
+}
+
+// fakeConn is the state of a connection to a fake server.
+type fakeConn struct {
+	server    *fakeServer
+	conn      net.Conn
+	s         *session
+	hash      []byte
+	challenge []byte
+	messageID uint64
+	trees     map[uint32]string
+	files     map[uint64]string
+	nextID    uint64
+}
+
+func (fs *fakeServer) serve(conn net.Conn) {
+	c := &fakeConn{server: fs, conn: conn, s: &session{dialect: fs.dialect}, trees: map[uint32]string{}, files: map[uint64]string{}}
+	defer GinkgoRecover()
+	defer conn.Close()
+	for {
+		frame := make([]byte, 4)
+		if _, err := io.ReadFull(conn, frame); err != nil {
+			return
+		}
+		msg := make([]byte, binary.BigEndian.Uint32(frame))
+		if _, err := io.ReadFull(conn, msg); err != nil {
+			return
+		}
+		encrypted := bytes.Equal(msg[:4], transformID)
+		if encrypted {
+			var err error
+			if msg, err = c.s.decrypt(msg); err != nil {
+				return
+			}
+		}
+		resp := c.handle(msg, encrypted)
+		if encrypted {
+			var err error
+			if resp, err = c.s.encrypt(resp); err != nil {
+				return
+			}
+		}
+		out := make([]byte, 4, 4+len(resp))
+		binary.BigEndian.PutUint32(out, uint32(len(resp)))
+		if _, err := conn.Write(append(out, resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// handle returns the response to a request.
+func (c *fakeConn) handle(msg []byte, encrypted bool) []byte {
+	fs := c.server
+	command := binary.LittleEndian.Uint16(msg[12:])
+	flags := binary.LittleEndian.Uint32(msg[16:])
+	messageID := binary.LittleEndian.Uint64(msg[24:])
+	treeID := binary.LittleEndian.Uint32(msg[36:])
+	charge := uint64(binary.LittleEndian.Uint16(msg[6:]))
+	if charge == 0 {
+		charge = 1
+	}
+	body := msg[headerSize:]
+	reply := func(status uint32, treeID uint32, respBody []byte) []byte {
+		h := make([]byte, headerSize)
+		copy(h, protocolID)
+		binary.LittleEndian.PutUint16(h[4:], headerSize)
+		binary.LittleEndian.PutUint32(h[8:], status)
+		binary.LittleEndian.PutUint16(h[12:], command)
+		grant := binary.LittleEndian.Uint16(msg[14:])
+		if grant > fs.grant {
+			grant = fs.grant
+		}
+		binary.LittleEndian.PutUint16(h[14:], grant)
+		binary.LittleEndian.PutUint32(h[16:], flagServerToRedir)
+		binary.LittleEndian.PutUint64(h[24:], messageID)
+		binary.LittleEndian.PutUint32(h[36:], treeID)
+		binary.LittleEndian.PutUint64(h[40:], c.s.id)
+		resp := append(h, respBody...)
+		if c.s.signingKey != nil && !encrypted && command != cmdNegotiate {
+			binary.LittleEndian.PutUint32(resp[16:], flagServerToRedir|flagSigned)
+			copy(resp[48:], c.s.signature(resp))
+		}
+		return resp
+	}
+	fail := func(status uint32) []byte {
+		return reply(status, treeID, []byte{9, 0, 0, 0, 0, 0, 0, 0, 0})
+	}
+
+	if messageID != c.messageID {
+		Fail("unexpected message id")
+	}
+	c.messageID += charge
+
+	fs.mu.Lock()
+	if encrypted {
+		fs.encrypted[command]++
+	}
+	if flags&flagSigned != 0 {
+		fs.signed[command]++
+	}
+	fs.mu.Unlock()
+	if command != cmdNegotiate && command != cmdSessionSetup {
+		if !encrypted && (flags&flagSigned == 0 || !c.s.verify(msg)) {
+			return fail(statusAccessDenied)
+		}
+		if !encrypted && (fs.encryptSession || (treeID != 0 && fs.shares[strings.ToLower(c.trees[treeID])] != nil && fs.shares[strings.ToLower(c.trees[treeID])].encrypt && c.s.encrypter != nil)) {
+			return fail(statusAccessDenied)
+		}
+	}
+
+	switch command {
+	case cmdNegotiate:
+		return c.negotiate(msg, reply)
+	case cmdSessionSetup:
+		return c.sessionSetup(msg, reply, fail)
+	case cmdTreeConnect:
+		path := fromUTF16le(msg[binary.LittleEndian.Uint16(body[4:]):][:binary.LittleEndian.Uint16(body[6:])])
+		unc, ok := parseUNCPath(path)
+		Expect(ok).To(BeTrue())
+		Expect(strings.ToLower(unc.server)).To(Equal(strings.ToLower(fs.name)))
+		resp := make([]byte, 16)
+		binary.LittleEndian.PutUint16(resp, 16)
+		if strings.EqualFold(unc.share, "IPC$") {
+			resp[2] = 2
+		} else {
+			share, ok := fs.shares[strings.ToLower(unc.share)]
+			if !ok {
+				return fail(statusBadNetworkName)
+			}
+			resp[2] = 1
+			if share.encrypt {
+				if c.s.encrypter == nil && fs.dialect < dialect300 {
+					return fail(statusAccessDenied)
+				}
+				binary.LittleEndian.PutUint32(resp[4:], shareFlagEncryptData)
+			}
+			if share.dfs {
+				binary.LittleEndian.PutUint32(resp[8:], shareCapDFS)
+			}
+		}
+		c.nextID++
+		c.trees[uint32(c.nextID)] = unc.share
+		return reply(statusSuccess, uint32(c.nextID), resp)
+	case cmdCreate:
+		share := fs.shares[strings.ToLower(c.trees[treeID])]
+		name := fromUTF16le(msg[binary.LittleEndian.Uint16(body[44:]):][:binary.LittleEndian.Uint16(body[46:])])
+		if share.dfs {
+			if flags&flagDFSOperations == 0 {
+				return fail(statusInvalidParameter)
+			}
+			unc, ok := parseUNCPath(name)
+			if !ok || !strings.EqualFold(unc.server, fs.name) || !strings.EqualFold(unc.share, c.trees[treeID]) {
+				return fail(statusInvalidParameter)
+			}
+			name = unc.name
+		}
+		for _, link := range share.links {
+			if strings.EqualFold(name, link) || strings.HasPrefix(strings.ToLower(name), strings.ToLower(link)+`\`) {
+				return fail(statusPathNotCovered)
+			}
+		}
+		data, ok := share.files[name]
+		if !ok {
+			return fail(statusObjectNameNotFound)
+		}
+		resp := make([]byte, 88)
+		binary.LittleEndian.PutUint16(resp, 89)
+		binary.LittleEndian.PutUint64(resp[24:], 132000000000000000)
+		binary.LittleEndian.PutUint64(resp[48:], uint64(len(data)))
+		c.nextID++
+		binary.LittleEndian.PutUint64(resp[64:], c.nextID)
+		c.files[c.nextID] = name
+		return reply(statusSuccess, treeID, resp)
+	case cmdRead:
+		fs.mu.Lock()
+		fs.reads++
+		fs.mu.Unlock()
+		size := binary.LittleEndian.Uint32(body[4:])
+		Expect(uint64(size)).To(BeNumerically("<=", charge*creditSize))
+		offset := binary.LittleEndian.Uint64(body[8:])
+		data := fs.shares[strings.ToLower(c.trees[treeID])].files[c.files[binary.LittleEndian.Uint64(body[16:])]]
+		if offset >= uint64(len(data)) {
+			return fail(statusEndOfFile)
+		}
+		if size > fs.maxRead {
+			size = fs.maxRead
+		}
+		chunk := data[offset:]
+		if uint32(len(chunk)) > size {
+			chunk = chunk[:size]
+		}
+		resp := make([]byte, 16, 16+len(chunk))
+		binary.LittleEndian.PutUint16(resp, 17)
+		resp[2] = headerSize + 16
+		binary.LittleEndian.PutUint32(resp[4:], uint32(len(chunk)))
+		return reply(statusSuccess, treeID, append(resp, chunk...))
+	case cmdIoctl:
+		Expect(binary.LittleEndian.Uint32(body[4:])).To(Equal(uint32(fsctlDFSGetReferrals)))
+		Expect(c.trees[treeID]).To(Equal("IPC$"))
+		input := msg[binary.LittleEndian.Uint32(body[24:]):][:binary.LittleEndian.Uint32(body[28:])]
+		path := readUTF16z(input[2:])
+		for prefix, targets := range fs.referrals {
+			if !strings.EqualFold(path, prefix) && !strings.HasPrefix(strings.ToLower(path), strings.ToLower(prefix)+`\`) {
+				continue
+			}
+			out := referralResponse(len(utf16le(prefix)), targets)
+			resp := make([]byte, 48, 48+len(out))
+			binary.LittleEndian.PutUint16(resp, 49)
+			binary.LittleEndian.PutUint32(resp[4:], fsctlDFSGetReferrals)
+			binary.LittleEndian.PutUint32(resp[32:], headerSize+48)
+			binary.LittleEndian.PutUint32(resp[36:], uint32(len(out)))
+			return reply(statusSuccess, treeID, append(resp, out...))
+		}
+		return fail(statusNotFound)
+	case cmdClose:
+		resp := make([]byte, 60)
+		binary.LittleEndian.PutUint16(resp, 60)
+		return reply(statusSuccess, treeID, resp)
+	case cmdTreeDisconnect, cmdLogoff:
+		return reply(statusSuccess, treeID, []byte{4, 0, 0, 0})
+	}
+	return fail(statusNotSupported)
+}
+
+func (c *fakeConn) negotiate(msg []byte, reply func(uint32, uint32, []byte) []byte) []byte {
+	fs := c.server
+	body := msg[headerSize:]
+	count := int(binary.LittleEndian.Uint16(body[2:]))
+	offered := map[uint16]bool{}
+	for i := 0; i < count; i++ {
+		offered[binary.LittleEndian.Uint16(body[36+2*i:])] = true
+	}
+	Expect(offered).To(HaveKey(fs.dialect))
+	resp := make([]byte, 64)
+	binary.LittleEndian.PutUint16(resp, 65)
+	binary.LittleEndian.PutUint16(resp[4:], fs.dialect)
+	capabilities := uint32(capDFS | capLargeMTU)
+	if fs.cipher != 0 && fs.dialect >= dialect300 {
+		capabilities |= capEncryption
+	}
+	binary.LittleEndian.PutUint32(resp[24:], capabilities)
+	binary.LittleEndian.PutUint32(resp[32:], fs.maxRead)
+	binary.LittleEndian.PutUint16(resp[56:], headerSize+64)
+	if fs.dialect == dialect311 {
+		resp = append(resp, make([]byte, 8-(headerSize+len(resp))%8)...)
+		binary.LittleEndian.PutUint32(resp[60:], uint32(headerSize+len(resp)))
+		preauth := make([]byte, 38)
+		binary.LittleEndian.PutUint16(preauth, 1)
+		binary.LittleEndian.PutUint16(preauth[2:], 32)
+		binary.LittleEndian.PutUint16(preauth[4:], hashSHA512)
+		resp = appendContext(resp, contextPreauthIntegrity, preauth)
+		contexts := 1
+		if fs.cipher != 0 {
+			for (headerSize+len(resp))%8 != 0 {
+				resp = append(resp, 0)
+			}
+			resp = appendContext(resp, contextEncryption, []byte{1, 0, byte(fs.cipher), 0})
+			contexts++
+		}
+		binary.LittleEndian.PutUint16(resp[6:], uint16(contexts))
+	} else if fs.dialect >= dialect300 && fs.cipher != 0 {
+		fs.cipher = cipherAES128CCM
+	}
+	c.s.cipher = fs.cipher
+	out := reply(statusSuccess, 0, resp)
+	if fs.dialect == dialect311 {
+		c.hash = preauthHash(preauthHash(make([]byte, sha512.Size), msg), out)
+	}
+	return out
+}
+
+func (c *fakeConn) sessionSetup(msg []byte, reply func(uint32, uint32, []byte) []byte, fail func(uint32) []byte) []byte {
+	fs := c.server
+	body := msg[headerSize:]
+	token := msg[binary.LittleEndian.Uint16(body[12:]):][:binary.LittleEndian.Uint16(body[14:])]
+	if c.hash != nil {
+		c.hash = preauthHash(c.hash, msg)
+	}
+	if c.challenge == nil {
+		// the NTLM negotiate message in the NegTokenInit
+		Expect(token[0]).To(Equal(byte(0x60)))
+		c.challenge = make([]byte, 8)
+		rand.Read(c.challenge)
+		targetInfo := append([]byte{2, 0, 14, 0}, utf16le("EXAMPLE")...)
+		targetInfo = append(targetInfo, avTimestamp, 0, 8, 0)
+		timestamp := make([]byte, 8)
+		binary.LittleEndian.PutUint64(timestamp, fileTime(time.Now()))
+		targetInfo = append(targetInfo, timestamp...)
+		targetInfo = append(targetInfo, 0, 0, 0, 0)
+		challenge := make([]byte, 48)
+		copy(challenge, ntlmSignature)
+		binary.LittleEndian.PutUint32(challenge[8:], ntlmChallengeMessage)
+		binary.LittleEndian.PutUint32(challenge[20:], ntlmClientFlags)
+		copy(challenge[24:], c.challenge)
+		binary.LittleEndian.PutUint16(challenge[40:], uint16(len(targetInfo)))
+		binary.LittleEndian.PutUint16(challenge[42:], uint16(len(targetInfo)))
+		binary.LittleEndian.PutUint32(challenge[44:], 48)
+		challenge = append(challenge, targetInfo...)
+		spnego := der(0xa1, der(0x30, append(append(der(0xa0, der(0x0a, []byte{1})), der(0xa1, der(0x06, ntlmsspOID))...), der(0xa2, der(0x04, challenge))...)))
+		c.s.id = 0x1234
+		resp := make([]byte, 8, 8+len(spnego))
+		binary.LittleEndian.PutUint16(resp, 9)
+		binary.LittleEndian.PutUint16(resp[4:], headerSize+8)
+		binary.LittleEndian.PutUint16(resp[6:], uint16(len(spnego)))
+		out := reply(statusMoreProcessingRequired, 0, append(resp, spnego...))
+		if c.hash != nil {
+			c.hash = preauthHash(c.hash, out)
+		}
+		return out
+	}
+
+	auth, err := parseSPNEGOResponse(token)
+	Expect(err).ToNot(HaveOccurred())
+	field := func(at int) []byte {
+		length, offset := binary.LittleEndian.Uint16(auth[at:]), binary.LittleEndian.Uint32(auth[at+4:])
+		return auth[offset : offset+uint32(length)]
+	}
+	nt := field(20)
+	user, domain := fromUTF16le(field(36)), fromUTF16le(field(28))
+	responseKey := ntowfv2(fs.credentials.User, fs.credentials.Password, fs.credentials.Domain)
+	proof := hmacMD5(responseKey, c.challenge, nt[16:])
+	if user != fs.credentials.User || domain != fs.credentials.Domain || !bytes.Equal(proof, nt[:16]) {
+		return fail(statusLogonFailure)
+	}
+	Expect(field(12)).To(Equal(make([]byte, 24)))
+	sessionKey := make([]byte, 16)
+	cipher, _ := rc4.NewCipher(hmacMD5(responseKey, proof))
+	cipher.XORKeyStream(sessionKey, field(52))
+
+	// the server encrypts with the decryption key of the client
+	server := &session{dialect: fs.dialect, cipher: c.s.cipher, id: c.s.id}
+	Expect(server.deriveKeys(sessionKey, c.hash)).To(Succeed())
+	c.s.signingKey = server.signingKey
+	c.s.encrypter, c.s.decrypter = server.decrypter, server.encrypter
+
+	resp := make([]byte, 8)
+	binary.LittleEndian.PutUint16(resp, 9)
+	if fs.encryptSession {
+		binary.LittleEndian.PutUint16(resp[2:], sessionFlagEncryptData)
+	}
+	return reply(statusSuccess, 0, resp)
+}
+
+// referralResponse returns a DFS referral response with entries of version 3.
+func referralResponse(pathConsumed int, targets []string) []byte {
+	const entrySize = 34
+	out := make([]byte, 8)
+	binary.LittleEndian.PutUint16(out, uint16(pathConsumed))
+	binary.LittleEndian.PutUint16(out[2:], uint16(len(targets)))
+	binary.LittleEndian.PutUint32(out[4:], 2)
+	var strs []byte
+	for i := range targets {
+		entry := make([]byte, entrySize)
+		binary.LittleEndian.PutUint16(entry, 3)
+		binary.LittleEndian.PutUint16(entry[2:], entrySize)
+		offset := (len(targets)-i)*entrySize + len(strs)
+		binary.LittleEndian.PutUint16(entry[12:], uint16(offset))
+		binary.LittleEndian.PutUint16(entry[14:], uint16(offset))
+		binary.LittleEndian.PutUint16(entry[16:], uint16(offset))
+		strs = append(strs, append(utf16le(targets[i]), 0, 0)...)
+		out = append(out, entry...)
+	}
+	return append(out, strs...)
+}
+
+// fakeNetwork dials the fake servers by name.
+type fakeNetwork map[string]*fakeServer
+
+func (n fakeNetwork) dial(addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(port).To(Equal(DefaultPort))
+	fs, ok := n[host]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	client, server := net.Pipe()
+	go fs.serve(server)
+	return client, nil
+}
+
+func randomData(size int) []byte {
+	data := make([]byte, size)
+	rand.Read(data)
+	return data
+}
+
+var _ = Describe("SMB client", func() {
+	var (
+		network fakeNetwork
+		client  *Client
+		data    []byte
+	)
+
+	newServer := func(name string, dialect uint16) *fakeServer {
+		fs := newFakeServer(name, dialect)
+		network[name] = fs
+		return fs
+	}
+
+	BeforeEach(func() {
+		network = fakeNetwork{}
+		data = randomData(3<<20 + 12345)
+		client = NewClient(Credentials{User: "importer", Password: "s3cret", Domain: "EXAMPLE"})
+		client.Dial = network.dial
+	})
+
+	AfterEach(func() {
+		client.Close()
+	})
+
+	readAll := func(f *File) []byte {
+		buf := make([]byte, f.Size())
+		n, err := f.ReadAt(buf, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(len(buf)))
+		_, err = f.ReadAt(make([]byte, 1), f.Size())
+		Expect(err).To(Equal(io.EOF))
+		return buf
+	}
+
+	table.DescribeTable("should read a file with signed messages", func(dialect uint16) {
+		fs := newServer("files", dialect)
+		fs.shares["images"] = &fakeShare{files: map[string][]byte{`vm\disk.img`: data}}
+		f, err := client.Open("files", "images", "/vm/disk.img")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Size()).To(Equal(int64(len(data))))
+		Expect(f.Path()).To(Equal(`\\files\images\vm\disk.img`))
+		Expect(readAll(f)).To(Equal(data))
+		Expect(f.Close()).To(Succeed())
+		Expect(fs.signed[cmdRead]).To(Equal(fs.reads))
+		Expect(fs.encrypted).To(BeEmpty())
+	},
+		table.Entry("with SMB 2.0.2", uint16(dialect202)),
+		table.Entry("with SMB 2.1", uint16(dialect210)),
+		table.Entry("with SMB 3.0", uint16(dialect300)),
+		table.Entry("with SMB 3.0.2", uint16(dialect302)),
+		table.Entry("with SMB 3.1.1", uint16(dialect311)),
+	)
+
+	It("should split the reads by the size and the credits the server allows", func() {
+		fs := newServer("files", dialect311)
+		fs.maxRead = 256 * 1024
+		fs.grant = 2
+		fs.shares["images"] = &fakeShare{files: map[string][]byte{"disk.img": data}}
+		f, err := client.Open("files", "images", "disk.img")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readAll(f)).To(Equal(data))
+		Expect(fs.reads).To(BeNumerically(">", len(data)/(256*1024)))
+	})
+
+	table.DescribeTable("should encrypt the messages of a share requiring encryption", func(dialect, cipher uint16) {
+		fs := newServer("files", dialect)
+		fs.cipher = cipher
+		fs.shares["secure"] = &fakeShare{files: map[string][]byte{"disk.img": data}, encrypt: true}
+		f, err := client.Open("files", "secure", "disk.img")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readAll(f)).To(Equal(data))
+		Expect(fs.encrypted[cmdCreate]).To(Equal(1))
+		Expect(fs.encrypted[cmdRead]).To(Equal(fs.reads))
+		// the share is connected before its messages are encrypted
+		Expect(fs.encrypted).ToNot(HaveKey(uint16(cmdTreeConnect)))
+	},
+		table.Entry("with SMB 3.0 and AES-128-CCM", uint16(dialect300), uint16(cipherAES128CCM)),
+		table.Entry("with SMB 3.1.1 and AES-128-CCM", uint16(dialect311), uint16(cipherAES128CCM)),
+		table.Entry("with SMB 3.1.1 and AES-128-GCM", uint16(dialect311), uint16(cipherAES128GCM)),
+	)
+
+	It("should encrypt all the messages of a session requiring encryption", func() {
+		fs := newServer("files", dialect311)
+		fs.encryptSession = true
+		fs.shares["images"] = &fakeShare{files: map[string][]byte{"disk.img": data}}
+		f, err := client.Open("files", "images", "disk.img")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readAll(f)).To(Equal(data))
+		Expect(fs.encrypted[cmdTreeConnect]).To(Equal(1))
+		Expect(fs.signed).To(BeEmpty())
+	})
+
+	table.DescribeTable("should explain why a share requiring encryption can not be read", func(dialect, cipher uint16, expected string) {
+		fs := newServer("files", dialect)
+		fs.cipher = cipher
+		fs.shares["secure"] = &fakeShare{files: map[string][]byte{"disk.img": data}, encrypt: true}
+		_, err := client.Open("files", "secure", "disk.img")
+		Expect(err).To(MatchError(ContainSubstring(expected)))
+	},
+		table.Entry("with SMB 2.1", uint16(dialect210), uint16(0), `connect to share \\files\secure: STATUS_ACCESS_DENIED, the share may require encryption, which is not supported by SMB 2.1`),
+		table.Entry("with SMB 3.0 without encryption", uint16(dialect300), uint16(0), `share \\files\secure requires encryption, but the server did not negotiate a cipher with SMB 3.0`),
+		table.Entry("with SMB 3.1.1 without cipher", uint16(dialect311), uint16(0), `share \\files\secure requires encryption, but the server did not negotiate a cipher with SMB 3.1.1`),
+	)
+
+	It("should fail when the session requires encryption without cipher", func() {
+		fs := newServer("files", dialect311)
+		fs.cipher = 0
+		fs.encryptSession = true
+		_, err := client.Open("files", "images", "disk.img")
+		Expect(err).To(MatchError(ContainSubstring(`the session of EXAMPLE\importer on files requires encryption`)))
+	})
+
+	It("should fail with a wrong password", func() {
+		fs := newServer("files", dialect311)
+		fs.credentials.Password = "other"
+		_, err := client.Open("files", "images", "disk.img")
+		Expect(err).To(MatchError(ContainSubstring(`authentication of EXAMPLE\importer on files failed: smb: session setup: STATUS_LOGON_FAILURE`)))
+	})
+
+	It("should fail when the share or the file does not exist", func() {
+		fs := newServer("files", dialect311)
+		fs.shares["images"] = &fakeShare{files: map[string][]byte{"disk.img": data}}
+		_, err := client.Open("files", "images", "other.img")
+		Expect(IsNotExist(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(`open \\files\images\other.img: STATUS_OBJECT_NAME_NOT_FOUND`)))
+		_, err = client.Open("files", "other", "disk.img")
+		Expect(IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should fail when the server can not be reached", func() {
+		_, err := client.Open("files", "images", "disk.img")
+		Expect(err).To(MatchError(ContainSubstring("could not connect to files:445: connection refused")))
+	})
+
+	Context("with DFS", func() {
+		var (
+			namespace *fakeServer
+			files     *fakeServer
+		)
+
+		BeforeEach(func() {
+			namespace = newServer("dfs", dialect311)
+			namespace.shares["ns"] = &fakeShare{dfs: true, links: []string{"images"}, files: map[string][]byte{"readme.txt": []byte("dfs")}}
+			files = newServer("files", dialect302)
+			files.shares["share"] = &fakeShare{files: map[string][]byte{`vm\disk.img`: data}}
+		})
+
+		It("should open the files of a DFS share with their whole path", func() {
+			f, err := client.Open("dfs", "ns", "readme.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readAll(f)).To(Equal([]byte("dfs")))
+		})
+
+		It("should follow a DFS link to its target", func() {
+			namespace.referrals[`\dfs\ns\images`] = []string{`\files\share\vm`}
+			f, err := client.Open("dfs", "ns", "images/disk.img")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Path()).To(Equal(`\\files\share\vm\disk.img`))
+			Expect(readAll(f)).To(Equal(data))
+		})
+
+		It("should follow a DFS link to the first target that can be opened", func() {
+			namespace.referrals[`\dfs\ns\images`] = []string{`\offline\share\vm`, `\files\share\vm`}
+			f, err := client.Open("dfs", "ns", "images/disk.img")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Path()).To(Equal(`\\files\share\vm\disk.img`))
+		})
+
+		It("should follow the referral of the namespace of a domain", func() {
+			domain := newServer("example.com", dialect311)
+			domain.referrals[`\example.com\ns`] = []string{`\dfs\ns`}
+			namespace.referrals[`\dfs\ns\images`] = []string{`\files\share\vm`}
+			f, err := client.Open("example.com", "ns", "images/disk.img")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Path()).To(Equal(`\\files\share\vm\disk.img`))
+			Expect(readAll(f)).To(Equal(data))
+		})
+
+		It("should fail when the referral of a DFS link fails", func() {
+			_, err := client.Open("dfs", "ns", "images/disk.img")
+			Expect(err).To(MatchError(ContainSubstring(`\\dfs\ns\images\disk.img is a DFS link whose referral failed: smb: \\dfs\ns\images\disk.img is not a DFS path of dfs`)))
+		})
+
+		It("should fail when the targets of a DFS link can not be opened", func() {
+			namespace.referrals[`\dfs\ns\images`] = []string{`\offline\share\vm`}
+			_, err := client.Open("dfs", "ns", "images/disk.img")
+			Expect(err).To(MatchError(ContainSubstring(`DFS target \\offline\share\vm\disk.img of \\dfs\ns\images\disk.img: smb: could not connect to offline:445`)))
+		})
+
+		It("should stop following DFS links referring to each other", func() {
+			namespace.referrals[`\dfs\ns\images`] = []string{`\dfs\ns\images`}
+			_, err := client.Open("dfs", "ns", "images/disk.img")
+			Expect(err).To(MatchError(ContainSubstring("more than 8 DFS referrals were followed")))
+		})
+	})
+
+	It("should parse the referral entries of version 2", func() {
+		entry := make([]byte, 22)
+		binary.LittleEndian.PutUint16(entry, 2)
+		binary.LittleEndian.PutUint16(entry[2:], 22)
+		binary.LittleEndian.PutUint16(entry[20:], 22)
+		out := make([]byte, 8)
+		binary.LittleEndian.PutUint16(out, uint16(len(utf16le(`\dfs\ns\images`))))
+		binary.LittleEndian.PutUint16(out[2:], 1)
+		out = append(append(out, entry...), append(utf16le(`\files\share`), 0, 0)...)
+		targets, err := parseReferral(out, uncPath{server: "dfs", share: "ns", name: `images\disk.img`})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets).To(Equal([]uncPath{{server: "files", share: "share", name: "disk.img"}}))
+	})
+
+	It("should reject the referrals to the domain controllers", func() {
+		entry := make([]byte, 34)
+		binary.LittleEndian.PutUint16(entry, 4)
+		binary.LittleEndian.PutUint16(entry[2:], 34)
+		binary.LittleEndian.PutUint16(entry[6:], nameListReferral)
+		out := make([]byte, 8)
+		binary.LittleEndian.PutUint16(out[2:], 1)
+		_, err := parseReferral(append(out, entry...), uncPath{server: "example.com", share: "ns"})
+		Expect(err).To(MatchError(ContainSubstring("whose DFS referral to its domain controllers is not supported")))
+	})
+})
@@ -3,10 +3,15 @@ package util
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"math"
 	"math/rand"
@@ -17,6 +22,7 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	"unsafe"
 
 	"github.com/pkg/errors"
 	"golang.org/x/sys/unix"
@@ -30,11 +36,22 @@ import (
 )
 
 const (
-	blockdevFileName = "/usr/sbin/blockdev"
 	// DefaultAlignBlockSize is the alignment size we use to align disk images, its a multiple of all known hardware block sizes 512/4k/8k/32k/64k.
 	DefaultAlignBlockSize = 1024 * 1024
+	// unlimitedMemory is the smallest cgroup memory limit considered to be no limit at all
+	unlimitedMemory = 1 << 62
+	// sparseBlockSize is the size of the blocks of zeros skipped by StreamSparseDataToFile
+	sparseBlockSize = 64 * 1024
+	// minCopyBufferSize and maxCopyBufferSize bound the buffer of StreamDataToFileWithSize
+	minCopyBufferSize = 32 * 1024
+	maxCopyBufferSize = 1024 * 1024
+	// defaultDirectIOAlignment is the alignment of the writes with direct I/O to a device whose logical block size is unknown
+	defaultDirectIOAlignment = 4096
 )
 
+// zeroBlock is compared with the blocks of data written by StreamSparseDataToFile
+var zeroBlock = make([]byte, sparseBlockSize)
+
 // CountingReader is a reader that keeps track of how much has been read
 type CountingReader struct {
 	Reader  io.ReadCloser
@@ -42,6 +59,38 @@ type CountingReader struct {
 	Done    bool
 }
 
+// ContextReader is a reader that stops reading once its context is done
+type ContextReader struct {
+	Reader io.ReadCloser
+	ctx    context.Context
+}
+
+// NewContextReader returns a reader reading from r until ctx is done, the reads then fail with the
+// error of the context.
+func NewContextReader(ctx context.Context, r io.ReadCloser) *ContextReader {
+	return &ContextReader{Reader: r, ctx: ctx}
+}
+
+// DigestReader is a reader computing the digests of the data read from it
+type DigestReader struct {
+	Reader io.ReadCloser
+	hashes map[string]hash.Hash
+	writer io.Writer
+	done   bool
+	// count is the number of bytes read
+	count int64
+}
+
+// NewDigestReader returns a reader tee-ing the data read from r through the passed in hashes, keyed
+// by the name of their algorithm, as in "sha256".
+func NewDigestReader(r io.ReadCloser, hashes map[string]hash.Hash) *DigestReader {
+	writers := make([]io.Writer, 0, len(hashes))
+	for _, h := range hashes {
+		writers = append(writers, h)
+	}
+	return &DigestReader{Reader: r, hashes: hashes, writer: io.MultiWriter(writers...)}
+}
+
 // VddkInfo holds VDDK version and connection information returned by an importer pod
 type VddkInfo struct {
 	Version string
@@ -73,6 +122,30 @@ func getNamespace(path string) string {
 	return "cdi"
 }
 
+// GetMemoryLimit returns the memory limit of the container the pod is executing in, read from its
+// cgroup, or 0 if the container has no limit or it cannot be detected
+func GetMemoryLimit() int64 {
+	return getMemoryLimit("/sys/fs/cgroup/memory.max", "/sys/fs/cgroup/memory/memory.limit_in_bytes")
+}
+
+// getMemoryLimit returns the limit read from the first of the cgroup v2 memory.max or the cgroup v1
+// memory.limit_in_bytes files found
+func getMemoryLimit(paths ...string) int64 {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// cgroup v2 writes "max" when there is no limit, v1 a value close to the largest int64
+		limit, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil || limit <= 0 || limit >= unlimitedMemory {
+			return 0
+		}
+		return limit
+	}
+	return 0
+}
+
 // ParseEnvVar provides a wrapper to attempt to fetch the specified env var
 func ParseEnvVar(envVarName string, decode bool) (string, error) {
 	value := os.Getenv(envVarName)
@@ -99,6 +172,181 @@ func (r *CountingReader) Close() error {
 	return r.Reader.Close()
 }
 
+// Read reads bytes from the stream, unless the context is done.
+func (r *ContextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.Reader.Read(p)
+}
+
+// Close closes the stream
+func (r *ContextReader) Close() error {
+	return r.Reader.Close()
+}
+
+// Read reads bytes from the stream, and writes them to the hashes.
+func (r *DigestReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	// hashes never fail to write
+	r.writer.Write(p[:n])
+	r.count += int64(n)
+	r.done = err == io.EOF
+	return n, err
+}
+
+// Close closes the stream
+func (r *DigestReader) Close() error {
+	return r.Reader.Close()
+}
+
+// Digests returns the digests of the data, keyed by algorithm, as "<algorithm>:<hex digest>". They
+// are only returned once the stream has been read to its end, nil otherwise: the digest of a part of
+// the data would not match the one of the source.
+func (r *DigestReader) Digests() map[string]string {
+	if !r.done {
+		return nil
+	}
+	digests := make(map[string]string, len(r.hashes))
+	for algorithm, h := range r.hashes {
+		digests[algorithm] = fmt.Sprintf("%s:%x", algorithm, h.Sum(nil))
+	}
+	return digests
+}
+
+// PaddedDigests returns the digests of the data followed by zeros up to size bytes, the digests of
+// a file holding the data once extended to size. The zeros are only written to the hashes, whose
+// state is restored afterwards. They are the digests of the data when size is not larger than the
+// data read, and nil unless the stream has been read to its end, or if a hash cannot save its state.
+func (r *DigestReader) PaddedDigests(size int64) map[string]string {
+	if !r.done {
+		return nil
+	}
+	if size <= r.count {
+		return r.Digests()
+	}
+	zeros := make([]byte, 64*1024)
+	digests := make(map[string]string, len(r.hashes))
+	for algorithm, h := range r.hashes {
+		saver, ok := h.(interface {
+			encoding.BinaryMarshaler
+			encoding.BinaryUnmarshaler
+		})
+		if !ok {
+			return nil
+		}
+		state, err := saver.MarshalBinary()
+		if err != nil {
+			return nil
+		}
+		for padding := size - r.count; padding > 0; {
+			n := int64(len(zeros))
+			if padding < n {
+				n = padding
+			}
+			h.Write(zeros[:n])
+			padding -= n
+		}
+		digests[algorithm] = fmt.Sprintf("%s:%x", algorithm, h.Sum(nil))
+		if err := saver.UnmarshalBinary(state); err != nil {
+			return nil
+		}
+	}
+	return digests
+}
+
+// checksumHashes create the hashes of the algorithms of the checksum of a source
+var checksumHashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Checksum is the checksum of the data of a source
+type Checksum struct {
+	// Algorithm is md5, sha256 or sha512
+	Algorithm string
+	// Digest is the hex digest of the data, in lower case
+	Digest string
+}
+
+// ParseChecksum parses a checksum written as "<algorithm>:<hex digest>", the algorithm is md5,
+// sha256 or sha512.
+func ParseChecksum(value string) (*Checksum, error) {
+	algorithm, digest, ok := strings.Cut(value, ":")
+	if _, known := checksumHashes[strings.ToLower(algorithm)]; !ok || !known {
+		return nil, errors.Errorf("invalid checksum %q, <algorithm>:<hex digest> is expected, the algorithm is sha256, sha512 or md5", value)
+	}
+	return NewChecksum(algorithm, digest)
+}
+
+// NewChecksum returns the checksum of the hex digest of the algorithm, md5, sha256 or sha512. The digest
+// must be as long as the digests of the algorithm.
+func NewChecksum(algorithm, digest string) (*Checksum, error) {
+	algorithm = strings.ToLower(algorithm)
+	newHash, known := checksumHashes[algorithm]
+	if !known {
+		return nil, errors.Errorf("invalid checksum algorithm %q, sha256, sha512 or md5 is expected", algorithm)
+	}
+	size := newHash().Size()
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != size {
+		return nil, errors.Errorf("invalid %s checksum %q, a digest of %d hex digits is expected", algorithm, digest, 2*size)
+	}
+	return &Checksum{Algorithm: algorithm, Digest: strings.ToLower(digest)}, nil
+}
+
+// NewHash returns a hash of the algorithm of the checksum.
+func (c *Checksum) NewHash() hash.Hash {
+	return checksumHashes[c.Algorithm]()
+}
+
+// Weak returns true if the algorithm of the checksum is not collision resistant, the data of a
+// source could be forged to match it.
+func (c *Checksum) Weak() bool {
+	return c.Algorithm == "md5"
+}
+
+// String returns the checksum as "<algorithm>:<hex digest>".
+func (c *Checksum) String() string {
+	return c.Algorithm + ":" + c.Digest
+}
+
+// ImagePlatform is the platform of an image of a manifest list of a registry
+type ImagePlatform struct {
+	// OS is the operating system, such as linux
+	OS string
+	// Architecture is the CPU architecture, such as amd64 or arm64
+	Architecture string
+	// Variant is the optional variant of the architecture, such as v8 for arm64
+	Variant string
+}
+
+// ParseImagePlatform parses a platform written as "<os>/<architecture>[/<variant>]", such as linux/arm64.
+func ParseImagePlatform(value string) (*ImagePlatform, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, errors.Errorf("invalid platform %q, <os>/<architecture>[/<variant>] is expected", value)
+	}
+	for _, part := range parts {
+		if part == "" || strings.TrimSpace(part) != part {
+			return nil, errors.Errorf("invalid platform %q, <os>/<architecture>[/<variant>] is expected", value)
+		}
+	}
+	platform := &ImagePlatform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		platform.Variant = parts[2]
+	}
+	return platform, nil
+}
+
+// String returns the platform as "<os>/<architecture>[/<variant>]".
+func (p ImagePlatform) String() string {
+	if p.Variant == "" {
+		return p.OS + "/" + p.Architecture
+	}
+	return p.OS + "/" + p.Architecture + "/" + p.Variant
+}
+
 // GetAvailableSpaceByVolumeMode calls another method based on the volumeMode parameter to get the amount of
 // available space at the path specified.
 func GetAvailableSpaceByVolumeMode(volumeMode v1.PersistentVolumeMode) (int64, error) {
@@ -108,6 +356,21 @@ func GetAvailableSpaceByVolumeMode(volumeMode v1.PersistentVolumeMode) (int64, e
 	return GetAvailableSpace(common.ImporterVolumePath)
 }
 
+// GetFileAllocation returns the size of the file at the path specified, and the space allocated to it, smaller
+// than its size when the file is sparse.
+func GetFileAllocation(path string) (int64, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, errors.Errorf("could not get the space allocated to %s", path)
+	}
+	// the blocks of stat are 512 bytes long, whatever the block size of the filesystem
+	return info.Size(), stat.Blocks * 512, nil
+}
+
 // GetAvailableSpace gets the amount of available space at the path specified.
 func GetAvailableSpace(path string) (int64, error) {
 	var stat syscall.Statfs_t
@@ -125,32 +388,26 @@ func GetAvailableSpaceBlock(deviceName string) (int64, error) {
 	if os.IsNotExist(err) {
 		return int64(-1), nil
 	}
-	if !isDevice(info.Mode()) {
+	if !isBlockDevice(info.Mode()) {
 		return int64(-1), nil
 	}
 	// Device exists, attempt to get size.
-	cmd := exec.Command(blockdevFileName, "--getsize64", deviceName)
-	var out bytes.Buffer
-	var errBuf bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &errBuf
-	err = cmd.Run()
-	if err != nil {
-		return int64(-1), errors.Errorf("%v, %s", err, errBuf.String())
-	}
-	i, err := strconv.ParseInt(strings.TrimSpace(out.String()), 10, 64)
+	f, err := os.Open(deviceName)
 	if err != nil {
 		return int64(-1), err
 	}
-	return i, nil
+	defer f.Close()
+	// BLKGETSIZE64 writes the size in bytes as a u64
+	var size uint64
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), unix.BLKGETSIZE64, uintptr(unsafe.Pointer(&size))); errno != 0 {
+		return int64(-1), errors.Wrapf(errno, "could not get the size of %s", deviceName)
+	}
+	return int64(size), nil
 }
 
-// isDevice returns true if it's a device file
-func isDevice(fileMode os.FileMode) bool {
-	if (fileMode & os.ModeDevice) != 0 {
-		return true
-	}
-	return false
+// isBlockDevice returns true if it's a block device file, not a character device one
+func isBlockDevice(fileMode os.FileMode) bool {
+	return fileMode&os.ModeDevice != 0 && fileMode&os.ModeCharDevice == 0
 }
 
 // MinQuantity calculates the minimum of two quantities.
@@ -183,13 +440,22 @@ func OpenFileOrBlockDevice(fileName string) (*os.File, error) {
 
 // StreamDataToFile provides a function to stream the specified io.Reader to the specified local file
 func StreamDataToFile(r io.Reader, fileName string) error {
+	return StreamDataToFileWithSize(r, fileName, 0)
+}
+
+// StreamDataToFileWithSize streams r to the passed in file like StreamDataToFile, size is the size
+// of the source of the data when it is known, 0 otherwise. It sizes the buffer of the copy, a large
+// source is copied in fewer and larger writes.
+func StreamDataToFileWithSize(r io.Reader, fileName string, size uint64) error {
 	outFile, err := OpenFileOrBlockDevice(fileName)
 	if err != nil {
 		return err
 	}
 	defer outFile.Close()
 	klog.V(1).Infof("Writing data...\n")
-	if _, err = io.Copy(outFile, r); err != nil {
+	// hide the ReadFrom of the file and the WriteTo of the reader, they copy through buffers of their own
+	buf := make([]byte, CopyBufferSize(size))
+	if _, err = io.CopyBuffer(struct{ io.Writer }{outFile}, struct{ io.Reader }{r}, buf); err != nil {
 		klog.Errorf("Unable to write file from dataReader: %v\n", err)
 		os.Remove(outFile.Name())
 		return errors.Wrapf(err, "unable to write to file")
@@ -198,6 +464,202 @@ func StreamDataToFile(r io.Reader, fileName string) error {
 	return err
 }
 
+// CopyBufferSize returns the size of the buffer copying a source of the passed in size, 0 if unknown:
+// the size of the source, between the 32 KiB of io.Copy and 1 MiB.
+func CopyBufferSize(size uint64) int {
+	switch {
+	case size < minCopyBufferSize:
+		return minCopyBufferSize
+	case size > maxCopyBufferSize:
+		return maxCopyBufferSize
+	}
+	return int(size)
+}
+
+// StreamSparseDataToFile writes the data of r to the passed in file like StreamDataToFile, but the
+// blocks of zeros are skipped rather than written: the file is extended over them, and they are
+// punched as holes in a block device. It returns the number of bytes of zeros skipped.
+func StreamSparseDataToFile(r io.Reader, fileName string) (int64, error) {
+	outFile, err := OpenFileOrBlockDevice(fileName)
+	if err != nil {
+		return 0, err
+	}
+	defer outFile.Close()
+	info, err := outFile.Stat()
+	if err != nil {
+		return 0, err
+	}
+	// Choose truncate for regular files, always created empty, and hole punching for block devices
+	zeroRange := AppendZeroWithTruncate
+	if !info.Mode().IsRegular() {
+		// a block device is written with direct I/O, the data does not go through the page cache
+		if err = setDirectIO(outFile); err == nil {
+			return streamSparseDataDirect(r, outFile)
+		}
+		klog.Infof("Unable to write %s with direct I/O, writing it through the page cache. Error was: %v", fileName, err)
+		zeroRange = PunchHole
+	}
+	klog.V(1).Infof("Writing data, skipping blocks of zeros...\n")
+	var offset, zeroStart, zeroLength, skipped int64
+	writeZeros := func() error {
+		if zeroLength == 0 {
+			return nil
+		}
+		if err := zeroRange(outFile, zeroStart, zeroLength); err != nil {
+			klog.Infof("Initial zero method failed, trying AppendZeroWithWrite instead. Error was: %v", err)
+			zeroRange = AppendZeroWithWrite // If the initial choice fails, fall back to regular file writing
+			if err := zeroRange(outFile, zeroStart, zeroLength); err != nil {
+				return err
+			}
+		}
+		skipped += zeroLength
+		zeroLength = 0
+		return nil
+	}
+	buf := make([]byte, sparseBlockSize)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			if bytes.Equal(buf[:n], zeroBlock[:n]) {
+				if zeroLength == 0 {
+					zeroStart = offset
+				}
+				zeroLength += int64(n)
+			} else {
+				if err = writeZeros(); err == nil {
+					_, err = outFile.Write(buf[:n])
+				}
+			}
+			offset += int64(n)
+		}
+		if err == nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			err = readErr
+		}
+		if err != nil {
+			klog.Errorf("Unable to write file from dataReader: %v\n", err)
+			os.Remove(outFile.Name())
+			return skipped, errors.Wrapf(err, "unable to write to file")
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	if err = writeZeros(); err != nil {
+		os.Remove(outFile.Name())
+		return skipped, errors.Wrapf(err, "unable to write to file")
+	}
+	return skipped, outFile.Sync()
+}
+
+// setDirectIO sets O_DIRECT on the open file f, its writes then have to be aligned.
+func setDirectIO(f *os.File) error {
+	flags, err := unix.FcntlInt(f.Fd(), unix.F_GETFL, 0)
+	if err != nil {
+		return err
+	}
+	_, err = unix.FcntlInt(f.Fd(), unix.F_SETFL, flags|unix.O_DIRECT)
+	return err
+}
+
+// logicalBlockSize returns the logical block size of the block device f, the alignment of its
+// writes with direct I/O.
+func logicalBlockSize(f *os.File) int64 {
+	size, err := unix.IoctlGetInt(int(f.Fd()), unix.BLKSSZGET)
+	if err != nil || size <= 0 {
+		return defaultDirectIOAlignment
+	}
+	return int64(size)
+}
+
+// streamSparseDataDirect writes the data of r to the block device f opened with direct I/O, and
+// returns the number of bytes of zeros skipped. The blocks of zeros are punched as holes, falling
+// back to writing them. The buffers, offsets and sizes of the writes are aligned to the logical
+// block size of the device, the last block of data is padded with zeros.
+func streamSparseDataDirect(r io.Reader, f *os.File) (int64, error) {
+	align := logicalBlockSize(f)
+	// anonymous mappings are page aligned, and zeroed
+	buf, err := unix.Mmap(-1, 0, sparseBlockSize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to allocate an aligned buffer")
+	}
+	defer unix.Munmap(buf)
+	// the zeros written when holes cannot be punched
+	var zeros []byte
+	defer func() {
+		if zeros != nil {
+			unix.Munmap(zeros)
+		}
+	}()
+	punchHole := true
+	klog.V(1).Infof("Writing data with direct I/O, skipping blocks of zeros...\n")
+	var offset, zeroStart, zeroLength, skipped int64
+	writeZeros := func() error {
+		if zeroLength == 0 {
+			return nil
+		}
+		length := RoundUp(zeroLength, align)
+		if punchHole {
+			err := PunchHole(f, zeroStart, length)
+			if err == nil {
+				skipped += zeroLength
+				zeroLength = 0
+				return nil
+			}
+			klog.Infof("Punching holes failed, writing the zeros instead. Error was: %v", err)
+			punchHole = false
+		}
+		if zeros == nil {
+			if zeros, err = unix.Mmap(-1, 0, sparseBlockSize, unix.PROT_READ, unix.MAP_ANON|unix.MAP_PRIVATE); err != nil {
+				return errors.Wrap(err, "unable to allocate an aligned buffer")
+			}
+		}
+		for written := int64(0); written < length; {
+			chunk := length - written
+			if chunk > sparseBlockSize {
+				chunk = sparseBlockSize
+			}
+			n, err := f.WriteAt(zeros[:chunk], zeroStart+written)
+			if err != nil {
+				return err
+			}
+			written += int64(n)
+		}
+		skipped += zeroLength
+		zeroLength = 0
+		return nil
+	}
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			if bytes.Equal(buf[:n], zeroBlock[:n]) {
+				if zeroLength == 0 {
+					zeroStart = offset
+				}
+				zeroLength += int64(n)
+			} else if err = writeZeros(); err == nil {
+				size := RoundUp(int64(n), align)
+				copy(buf[n:size], zeroBlock)
+				_, err = f.WriteAt(buf[:size], offset)
+			}
+			offset += int64(n)
+		}
+		if err == nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			err = readErr
+		}
+		if err != nil {
+			klog.Errorf("Unable to write file from dataReader: %v\n", err)
+			return skipped, errors.Wrapf(err, "unable to write to file")
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	if err = writeZeros(); err != nil {
+		return skipped, errors.Wrapf(err, "unable to write to file")
+	}
+	return skipped, f.Sync()
+}
+
 // UnArchiveTar unarchives a tar file and streams its files
 // using the specified io.Reader to the specified destination.
 func UnArchiveTar(reader io.Reader, destDir string) error {
@@ -385,7 +847,7 @@ func Md5sum(filePath string) (string, error) {
 
 // PunchHole attempts to zero a range in a file with fallocate, for block devices and pre-allocated files.
 func PunchHole(outFile *os.File, start, length int64) error {
-	klog.Infof("Punching %d-byte hole at offset %d", length, start)
+	klog.V(3).Infof("Punching %d-byte hole at offset %d", length, start)
 	flags := uint32(unix.FALLOC_FL_PUNCH_HOLE | unix.FALLOC_FL_KEEP_SIZE)
 	err := syscall.Fallocate(int(outFile.Fd()), flags, start, length)
 	if err == nil {
@@ -396,7 +858,7 @@ func PunchHole(outFile *os.File, start, length int64) error {
 
 // AppendZeroWithTruncate resizes the file to append zeroes, meant only for newly-created (empty and zero-length) regular files.
 func AppendZeroWithTruncate(outFile *os.File, start, length int64) error {
-	klog.Infof("Truncating %d-bytes from offset %d", length, start)
+	klog.V(3).Infof("Truncating %d-bytes from offset %d", length, start)
 	end, err := outFile.Seek(0, io.SeekEnd)
 	if err != nil {
 		return err
@@ -2,10 +2,18 @@ package util
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"syscall"
+	"testing/iotest"
 
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
@@ -71,6 +79,34 @@ var _ = Describe("GetNameSpace", func() {
 	})
 })
 
+var _ = Describe("Memory limit", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "cgroup")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	table.DescribeTable("should read the limit of the cgroup", func(content string, expected int64) {
+		limitFile := filepath.Join(tmpDir, "memory.max")
+		Expect(os.WriteFile(limitFile, []byte(content), 0644)).To(Succeed())
+		Expect(getMemoryLimit(filepath.Join(tmpDir, "doesnotexist"), limitFile)).To(Equal(expected))
+	},
+		table.Entry("with a limit", "629145600\n", int64(629145600)),
+		table.Entry("without a cgroup v2 limit", "max\n", int64(0)),
+		table.Entry("without a cgroup v1 limit", "9223372036854771712\n", int64(0)),
+	)
+
+	It("should report no limit without cgroup files", func() {
+		Expect(getMemoryLimit(filepath.Join(tmpDir, "doesnotexist"))).To(BeZero())
+	})
+})
+
 var _ = Describe("ParseEnv", func() {
 	BeforeEach(func() {
 		os.Setenv("value1", "value1")
@@ -115,6 +151,233 @@ var _ = Describe("Compare quantities", func() {
 	})
 })
 
+var _ = Describe("Context reader", func() {
+	It("Should stop reading once the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r := NewContextReader(ctx, io.NopCloser(bytes.NewReader([]byte("0123456789"))))
+		buf := make([]byte, 4)
+		n, err := r.Read(buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buf[:n]).To(Equal([]byte("0123")))
+		cancel()
+		n, err = r.Read(buf)
+		Expect(n).To(BeZero())
+		Expect(err).To(Equal(context.Canceled))
+	})
+})
+
+// readSizeRecorder records the size of the largest read of r
+type readSizeRecorder struct {
+	r       io.Reader
+	largest int
+}
+
+func (rec *readSizeRecorder) Read(p []byte) (int, error) {
+	if len(p) > rec.largest {
+		rec.largest = len(p)
+	}
+	return rec.r.Read(p)
+}
+
+var _ = Describe("Digest reader", func() {
+	data := bytes.Repeat([]byte("disk image "), 1000)
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+
+	It("Should compute the digests of the data read", func() {
+		r := NewDigestReader(io.NopCloser(bytes.NewReader(data)), map[string]hash.Hash{"sha256": sha256.New()})
+		content, err := io.ReadAll(iotest.HalfReader(r))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(data))
+		Expect(r.Digests()).To(Equal(map[string]string{"sha256": digest}))
+		Expect(r.Close()).To(Succeed())
+	})
+
+	It("Should not report the digests of a part of the data", func() {
+		r := NewDigestReader(io.NopCloser(bytes.NewReader(data)), map[string]hash.Hash{"sha256": sha256.New()})
+		_, err := r.Read(make([]byte, 100))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Digests()).To(BeNil())
+		Expect(r.PaddedDigests(int64(len(data)))).To(BeNil())
+	})
+
+	It("Should compute the digests of the data padded with zeros", func() {
+		r := NewDigestReader(io.NopCloser(bytes.NewReader(data)), map[string]hash.Hash{"sha256": sha256.New()})
+		_, err := io.Copy(io.Discard, r)
+		Expect(err).NotTo(HaveOccurred())
+		padded := append(append([]byte{}, data...), make([]byte, 100<<10)...)
+		Expect(r.PaddedDigests(int64(len(padded)))).To(Equal(map[string]string{"sha256": fmt.Sprintf("sha256:%x", sha256.Sum256(padded))}))
+		Expect(r.PaddedDigests(int64(len(data)))).To(Equal(map[string]string{"sha256": digest}))
+		// the hashes are left as they were
+		Expect(r.Digests()).To(Equal(map[string]string{"sha256": digest}))
+	})
+})
+
+var _ = Describe("ImagePlatform", func() {
+	table.DescribeTable("Should parse", func(value string, expected ImagePlatform) {
+		platform, err := ParseImagePlatform(value)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*platform).To(Equal(expected))
+		Expect(platform.String()).To(Equal(value))
+	},
+		table.Entry("a platform", "linux/amd64", ImagePlatform{OS: "linux", Architecture: "amd64"}),
+		table.Entry("a platform with a variant", "linux/arm64/v8", ImagePlatform{OS: "linux", Architecture: "arm64", Variant: "v8"}),
+	)
+
+	table.DescribeTable("Should reject", func(value string) {
+		_, err := ParseImagePlatform(value)
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("a platform without os", "arm64"),
+		table.Entry("an empty architecture", "linux/"),
+		table.Entry("too many parts", "linux/arm64/v8/extra"),
+		table.Entry("a part with spaces", "linux/ arm64"),
+	)
+})
+
+var _ = Describe("Checksum", func() {
+	sha256Digest := strings.Repeat("ab", 32)
+
+	table.DescribeTable("Should parse", func(value, algorithm, digest string, weak bool) {
+		checksum, err := ParseChecksum(value)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(checksum.Algorithm).To(Equal(algorithm))
+		Expect(checksum.Digest).To(Equal(digest))
+		Expect(checksum.Weak()).To(Equal(weak))
+		Expect(checksum.NewHash().Size()).To(Equal(len(digest) / 2))
+	},
+		table.Entry("a sha256 checksum", "sha256:"+sha256Digest, "sha256", sha256Digest, false),
+		table.Entry("a checksum in upper case", "SHA256:"+strings.ToUpper(sha256Digest), "sha256", sha256Digest, false),
+		table.Entry("a sha512 checksum", "sha512:"+strings.Repeat("0f", 64), "sha512", strings.Repeat("0f", 64), false),
+		table.Entry("a weak md5 checksum", "md5:"+strings.Repeat("12", 16), "md5", strings.Repeat("12", 16), true),
+	)
+
+	table.DescribeTable("Should reject", func(value string) {
+		_, err := ParseChecksum(value)
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("a checksum without algorithm", sha256Digest),
+		table.Entry("an unknown algorithm", "sha1:"+strings.Repeat("ab", 20)),
+		table.Entry("a digest which is not hex", "sha256:"+strings.Repeat("zz", 32)),
+		table.Entry("a digest of another size", "sha256:"+strings.Repeat("ab", 16)),
+	)
+
+	table.DescribeTable("Should reject the checksum of", func(algorithm, digest, expectedErr string) {
+		_, err := NewChecksum(algorithm, digest)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErr))
+	},
+		table.Entry("an unknown algorithm", "sha1", strings.Repeat("ab", 20), `invalid checksum algorithm "sha1"`),
+		table.Entry("a digest which is not hex", "sha256", strings.Repeat("zz", 32), "a digest of 64 hex digits is expected"),
+		table.Entry("a sha512 digest of the size of a sha256 one", "sha512", sha256Digest, "a digest of 128 hex digits is expected"),
+	)
+})
+
+var _ = Describe("Data to file", func() {
+	table.DescribeTable("should size the copy buffer from the size of the source", func(size uint64, expected int) {
+		Expect(CopyBufferSize(size)).To(Equal(expected))
+	},
+		table.Entry("of an unknown size", uint64(0), minCopyBufferSize),
+		table.Entry("of a small source", uint64(100), minCopyBufferSize),
+		table.Entry("of a source between the bounds", uint64(100<<10), 100<<10),
+		table.Entry("of a large source", uint64(10<<30), maxCopyBufferSize),
+	)
+
+	It("Should copy the data through the buffer sized from the source", func() {
+		destTmp, err := os.MkdirTemp("", "data")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(destTmp)
+		data := bytes.Repeat([]byte("disk image "), 300<<10)
+		fileName := filepath.Join(destTmp, "disk.img")
+		rec := &readSizeRecorder{r: bytes.NewReader(data)}
+		// the multi-reader would copy through a buffer of its own
+		Expect(StreamDataToFileWithSize(io.MultiReader(rec), fileName, uint64(len(data)))).To(Succeed())
+		Expect(rec.largest).To(Equal(maxCopyBufferSize))
+		content, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(data))
+	})
+})
+
+var _ = Describe("Sparse data to file", func() {
+	var destTmp string
+
+	BeforeEach(func() {
+		var err error
+		destTmp, err = os.MkdirTemp("", "sparse")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(destTmp)
+	})
+
+	It("Should skip the blocks of zeros", func() {
+		var data []byte
+		data = append(data, bytes.Repeat([]byte("a"), sparseBlockSize)...)
+		data = append(data, make([]byte, 1<<20)...)
+		data = append(data, bytes.Repeat([]byte("b"), 100)...)
+		// trailing zeros, the last block is partial
+		data = append(data, make([]byte, 200<<10)...)
+		fileName := filepath.Join(destTmp, "disk.img")
+		skipped, err := StreamSparseDataToFile(bytes.NewReader(data), fileName)
+		Expect(err).NotTo(HaveOccurred())
+		// the zeros of the block holding the b are written
+		Expect(skipped).To(Equal(int64(1<<20 + 200<<10 - (sparseBlockSize - 100))))
+		content, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(data))
+		info, err := os.Stat(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Sys().(*syscall.Stat_t).Blocks * 512).To(BeNumerically("<", len(data)))
+	})
+
+	It("Should report the space allocated to the sparse file", func() {
+		data := append(bytes.Repeat([]byte("a"), sparseBlockSize), make([]byte, 4<<20)...)
+		fileName := filepath.Join(destTmp, "disk.img")
+		_, err := StreamSparseDataToFile(bytes.NewReader(data), fileName)
+		Expect(err).NotTo(HaveOccurred())
+		size, allocated, err := GetFileAllocation(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(int64(len(data))))
+		Expect(allocated).To(BeNumerically(">=", sparseBlockSize))
+		Expect(allocated).To(BeNumerically("<", 4<<20))
+	})
+
+	It("Should write the data with direct I/O, punching the blocks of zeros", func() {
+		// a device is simulated with a file of data, it is overwritten
+		fileName := filepath.Join(destTmp, "device")
+		Expect(os.WriteFile(fileName, bytes.Repeat([]byte("x"), 2<<20), 0600)).To(Succeed())
+		f, err := os.OpenFile(fileName, os.O_RDWR, 0)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		if err := setDirectIO(f); err != nil {
+			Skip("direct I/O is not supported by the filesystem: " + err.Error())
+		}
+		var data []byte
+		data = append(data, bytes.Repeat([]byte("a"), sparseBlockSize)...)
+		data = append(data, make([]byte, 1<<20)...)
+		// the last block is partial, it is padded with zeros
+		data = append(data, bytes.Repeat([]byte("b"), 100)...)
+		skipped, err := streamSparseDataDirect(bytes.NewReader(data), f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(skipped).To(Equal(int64(1 << 20)))
+		content, err := os.ReadFile(fileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(HaveLen(2 << 20))
+		Expect(content[:len(data)]).To(Equal(data))
+		Expect(content[len(data) : len(data)-100+defaultDirectIOAlignment]).To(Equal(make([]byte, defaultDirectIOAlignment-100)))
+	})
+
+	It("Should remove the file when the data cannot be read", func() {
+		fileName := filepath.Join(destTmp, "disk.img")
+		_, err := StreamSparseDataToFile(io.MultiReader(bytes.NewReader(make([]byte, 1<<20)), iotest.ErrReader(io.ErrClosedPipe)), fileName)
+		Expect(err).To(HaveOccurred())
+		_, err = os.Stat(fileName)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
+
 var _ = Describe("Copy files", func() {
 	var destTmp string
 	var err error
@@ -224,6 +487,19 @@ var _ = Describe("Zero out ranges in files", func() {
 		table.Entry("using write", AppendZeroWithWrite),
 	)
 })
+
+var _ = Describe("Block device size", func() {
+	table.DescribeTable("Should not size a file that is not a block device", func(path string) {
+		size, err := GetAvailableSpaceBlock(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(Equal(int64(-1)))
+	},
+		table.Entry("missing file", "/dev/cdi-missing-volume"),
+		table.Entry("regular file", filepath.Join(TestImagesDir, "cirros-qcow2.img")),
+		table.Entry("character device", "/dev/null"),
+	)
+})
+
 var _ = Describe("Usable Space calculation", func() {
 
 	const (
@@ -19,6 +19,7 @@ package v1beta1
 import (
 	ocpconfigv1 "github.com/openshift/api/config/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
 )
@@ -30,7 +31,7 @@ import (
 // +kubebuilder:storageversion
 // +kubebuilder:resource:shortName=dv;dvs,categories=all
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The phase the data volume is in"
-// +kubebuilder:printcolumn:name="Progress",type="string",JSONPath=".status.progress",description="Transfer progress in percentage if known, N/A otherwise"
+// +kubebuilder:printcolumn:name="Progress",type="string",JSONPath=".status.progress",description="Transfer progress in percentage if known, the bytes transferred when the size of the source is unknown, N/A otherwise"
 // +kubebuilder:printcolumn:name="Restarts",type="integer",JSONPath=".status.restartCount",description="The number of times the transfer has been restarted."
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type DataVolume struct {
@@ -64,6 +65,18 @@ type DataVolumeSpec struct {
 	FinalCheckpoint bool `json:"finalCheckpoint,omitempty"`
 	// Preallocation controls whether storage for DataVolumes should be allocated in advance.
 	Preallocation *bool `json:"preallocation,omitempty"`
+	// DiskFormat is the format of the disk image written to a filesystem volume by an import, raw or qcow2. Defaults to the diskFormat of the CDIConfig, raw if it is not set.
+	// +kubebuilder:validation:Enum="raw";"qcow2"
+	// +optional
+	DiskFormat DataVolumeDiskFormat `json:"diskFormat,omitempty"`
+	// PreallocationMode is the preallocation of the raw disk image written by an import when preallocation is set, falloc or full. Defaults to falloc, the preallocation is full when the filesystem does not support fallocate.
+	// +kubebuilder:validation:Enum="falloc";"full"
+	// +optional
+	PreallocationMode DataVolumePreallocationMode `json:"preallocationMode,omitempty"`
+	// BackoffLimit is the number of times a failed import is retried before the DataVolume fails, 0 fails it on its first failure. Overrides the maxRetries annotation of the DataVolume and the importRetryPolicy of the CDIConfig.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
 }
 
 // StorageSpec defines the Storage type specification
@@ -122,10 +135,36 @@ const (
 	DataVolumeArchive DataVolumeContentType = "archive"
 )
 
-// DataVolumeSource represents the source for our Data Volume, this can be HTTP, Imageio, S3, Registry or an existing PVC
+// DataVolumeDiskFormat represents the format of the disk image written to a volume
+type DataVolumeDiskFormat string
+
+const (
+	// DataVolumeDiskFormatRaw is the raw format, the default
+	DataVolumeDiskFormatRaw DataVolumeDiskFormat = "raw"
+	// DataVolumeDiskFormatQcow2 is the qcow2 format, the space of the unallocated clusters of the disk is not used
+	DataVolumeDiskFormatQcow2 DataVolumeDiskFormat = "qcow2"
+)
+
+// DataVolumePreallocationMode represents the preallocation of the disk image written to a volume
+type DataVolumePreallocationMode string
+
+const (
+	// DataVolumePreallocationFalloc allocates the space of the disk image with fallocate, the default
+	DataVolumePreallocationFalloc DataVolumePreallocationMode = "falloc"
+	// DataVolumePreallocationFull writes zeros to the space of the disk image
+	DataVolumePreallocationFull DataVolumePreallocationMode = "full"
+)
+
+// DataVolumeSource represents the source for our Data Volume, this can be HTTP, Imageio, S3, GCS, SFTP, Glance, File, NFS, SMB, Registry or an existing PVC
 type DataVolumeSource struct {
 	HTTP     *DataVolumeSourceHTTP     `json:"http,omitempty"`
 	S3       *DataVolumeSourceS3       `json:"s3,omitempty"`
+	GCS      *DataVolumeSourceGCS      `json:"gcs,omitempty"`
+	SFTP     *DataVolumeSourceSFTP     `json:"sftp,omitempty"`
+	Glance   *DataVolumeSourceGlance   `json:"glance,omitempty"`
+	File     *DataVolumeSourceFile     `json:"file,omitempty"`
+	NFS      *DataVolumeSourceNFS      `json:"nfs,omitempty"`
+	SMB      *DataVolumeSourceSMB      `json:"smb,omitempty"`
 	Registry *DataVolumeSourceRegistry `json:"registry,omitempty"`
 	PVC      *DataVolumeSourcePVC      `json:"pvc,omitempty"`
 	Upload   *DataVolumeSourceUpload   `json:"upload,omitempty"`
@@ -152,7 +191,25 @@ type DataVolumeSourceSnapshot struct {
 }
 
 // DataVolumeBlankImage provides the parameters to create a new raw blank image for the PVC
-type DataVolumeBlankImage struct{}
+type DataVolumeBlankImage struct {
+	// Filesystem is the filesystem created on the blank image, ext4 or xfs, the image is left empty when it is not set
+	// +kubebuilder:validation:Enum="ext4";"xfs"
+	// +optional
+	Filesystem DataVolumeBlankFilesystem `json:"filesystem,omitempty"`
+	// FilesystemLabel is the label of the filesystem created on the blank image, of letters, digits, '.', '_' and '-', up to 16 characters for ext4 and 12 for xfs
+	// +optional
+	FilesystemLabel string `json:"filesystemLabel,omitempty"`
+}
+
+// DataVolumeBlankFilesystem represents the filesystem created on a blank image
+type DataVolumeBlankFilesystem string
+
+const (
+	// DataVolumeBlankFilesystemExt4 is the ext4 filesystem
+	DataVolumeBlankFilesystemExt4 DataVolumeBlankFilesystem = "ext4"
+	// DataVolumeBlankFilesystemXFS is the xfs filesystem
+	DataVolumeBlankFilesystemXFS DataVolumeBlankFilesystem = "xfs"
+)
 
 // DataVolumeSourceUpload provides the parameters to create a Data Volume by uploading the source
 type DataVolumeSourceUpload struct {
@@ -167,11 +224,91 @@ type DataVolumeSourceS3 struct {
 	// CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate
 	// +optional
 	CertConfigMap string `json:"certConfigMap,omitempty"`
+	// EncryptionSecretRef is a Secret reference, the secret should contain the passphrase of a LUKS-encrypted qcow2 source image in its passphrase key
+	// +optional
+	EncryptionSecretRef string `json:"encryptionSecretRef,omitempty"`
+	// Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it
+	// +optional
+	Checksum *DataVolumeChecksum `json:"checksum,omitempty"`
+}
+
+// DataVolumeSourceGCS provides the parameters to create a Data Volume from a Google Cloud Storage source
+type DataVolumeSourceGCS struct {
+	//URL is the url of the GCS source, gs://bucket/object, optionally followed by #generation to import a given generation of the object
+	URL string `json:"url"`
+	//SecretRef provides the secret reference holding the JSON key of the service account reading the GCS source, in its serviceAccount key. The workload identity of the importer pod is used when it is empty
+	// +optional
+	SecretRef string `json:"secretRef,omitempty"`
+	// Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it
+	// +optional
+	Checksum *DataVolumeChecksum `json:"checksum,omitempty"`
+}
+
+// DataVolumeSourceSFTP provides the parameters to create a Data Volume from an SFTP source
+type DataVolumeSourceSFTP struct {
+	//URL is the url of the SFTP source, sftp://user@host[:port]/path, a path starting with /~/ is relative to the home directory of the user
+	URL string `json:"url"`
+	//SecretRef provides the secret reference holding the password or the private key of the user, in its password or privateKey key, and the known_hosts entries verifying the key of the host, in its knownHosts key
+	SecretRef string `json:"secretRef"`
+	// Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it
+	// +optional
+	Checksum *DataVolumeChecksum `json:"checksum,omitempty"`
+}
+
+// DataVolumeSourceGlance provides the parameters to create a Data Volume from an OpenStack Glance image
+type DataVolumeSourceGlance struct {
+	//URL is the url of the Keystone identity service of the OpenStack cloud, such as https://keystone.example.com:5000/v3
+	URL string `json:"url"`
+	//Image is the ID or the name of the Glance image
+	Image string `json:"image"`
+	//Project is the ID of the project owning the image, an image named by Image is looked up among the images of this project
+	// +optional
+	Project string `json:"project,omitempty"`
+	//Region is the region of the image service in the service catalog of the cloud, the first image service of the catalog is used when it is empty
+	// +optional
+	Region string `json:"region,omitempty"`
+	//SecretRef provides the secret reference holding the ID and the secret of the application credential authenticating to Keystone, in its accessKeyId and secretKey keys
+	SecretRef string `json:"secretRef"`
+	// CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate
+	// +optional
+	CertConfigMap string `json:"certConfigMap,omitempty"`
+}
+
+// DataVolumeSourceFile provides the parameters to create a Data Volume from a file of a PVC or of a directory of the node, mounted read-only in the importer pod
+type DataVolumeSourceFile struct {
+	//Path is the path of the file, relative to the root of the PVC or of the directory of the node
+	Path string `json:"path"`
+	//PVC is the name of the PVC holding the file, in the namespace of the Data Volume unless Namespace is set
+	// +optional
+	PVC string `json:"pvc,omitempty"`
+	//Namespace is the namespace of the PVC holding the file, the file of a PVC of another namespace is imported with the permission of the user to clone the PVCs of that namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	//HostPath is the absolute path of the directory of the node holding the file, allowed by the HostPathImport feature gate
+	// +optional
+	HostPath string `json:"hostPath,omitempty"`
+}
+
+// DataVolumeSourceNFS provides the parameters to create a Data Volume from a file of an NFS export, whose directory is mounted read-only in the importer pod
+type DataVolumeSourceNFS struct {
+	//URL is the URL of the file, nfs://server/path/file
+	URL string `json:"url"`
+}
+
+// DataVolumeSourceSMB provides the parameters to create a Data Volume from a file of an SMB share, read by the importer without mounting the share
+type DataVolumeSourceSMB struct {
+	//URL is the URL of the file, smb://server[:port]/share/path, the DFS links of the path are followed
+	URL string `json:"url"`
+	//SecretRef provides the secret reference holding the name and the password of the user, in its username and password keys, and the domain of the user in its optional domain key
+	SecretRef string `json:"secretRef"`
+	// Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it
+	// +optional
+	Checksum *DataVolumeChecksum `json:"checksum,omitempty"`
 }
 
 // DataVolumeSourceRegistry provides the parameters to create a Data Volume from an registry source
 type DataVolumeSourceRegistry struct {
-	//URL is the url of the registry source (starting with the scheme: docker, oci-archive)
+	//URL is the url of the registry source (starting with the scheme: docker, oci, oci-archive)
 	// +optional
 	URL *string `json:"url,omitempty"`
 	//ImageStream is the name of image stream for import
@@ -186,6 +323,9 @@ type DataVolumeSourceRegistry struct {
 	//CertConfigMap provides a reference to the Registry certs
 	// +optional
 	CertConfigMap *string `json:"certConfigMap,omitempty"`
+	// Checksum is the checksum of the disk image of the container image, as it is stored in the image. The import fails if the disk image does not match it. Not supported by the node pull method
+	// +optional
+	Checksum *DataVolumeChecksum `json:"checksum,omitempty"`
 }
 
 const (
@@ -193,6 +333,8 @@ const (
 	RegistrySchemeDocker = "docker"
 	// RegistrySchemeOci is oci-archive scheme prefix
 	RegistrySchemeOci = "oci-archive"
+	// RegistrySchemeOciRegistry is the scheme prefix of an image of an OCI distribution registry, pulled as with docker
+	RegistrySchemeOciRegistry = "oci"
 )
 
 // RegistryPullMethod represents the registry import pull method
@@ -207,9 +349,9 @@ const (
 
 // DataVolumeSourceHTTP can be either an http or https endpoint, with an optional basic auth user name and password, and an optional configmap containing additional CAs
 type DataVolumeSourceHTTP struct {
-	// URL is the URL of the http(s) endpoint
+	// URL is the URL of the http(s) or ftp(s) endpoint
 	URL string `json:"url"`
-	// SecretRef A Secret reference, the secret should contain accessKeyId (user name) base64 encoded, and secretKey (password) also base64 encoded
+	// SecretRef A Secret reference, the secret should contain accessKeyId (user name) base64 encoded, and secretKey (password) also base64 encoded, or a bearer token in its token key, and optionally a client certificate in its tls.crt and tls.key keys
 	// +optional
 	SecretRef string `json:"secretRef,omitempty"`
 	// CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate
@@ -221,6 +363,51 @@ type DataVolumeSourceHTTP struct {
 	// SecretExtraHeaders is a list of Secret references, each containing an extra HTTP header that may include sensitive information
 	// +optional
 	SecretExtraHeaders []string `json:"secretExtraHeaders,omitempty"`
+	// EncryptionSecretRef is a Secret reference, the secret should contain the passphrase of a LUKS-encrypted qcow2 source image in its passphrase key
+	// +optional
+	EncryptionSecretRef string `json:"encryptionSecretRef,omitempty"`
+	// Mirrors is an ordered list of http(s) URLs of the same data, tried in turn when the URL, or the previous mirror, cannot be reached or fails with a server error
+	// +optional
+	Mirrors []string `json:"mirrors,omitempty"`
+	// Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it
+	// +optional
+	Checksum *DataVolumeChecksum `json:"checksum,omitempty"`
+	// Signature is a detached OpenPGP signature of the data of the source, before it is decompressed. The import fails if the data is not signed by one of the trusted keys
+	// +optional
+	Signature *DataVolumeSourceSignature `json:"signature,omitempty"`
+}
+
+// DataVolumeChecksum is the checksum the data of a source is verified against
+type DataVolumeChecksum struct {
+	// Algorithm is the hash algorithm of the checksum, sha256, sha512 or md5
+	// +kubebuilder:validation:Enum="sha256";"sha512";"md5"
+	Algorithm DataVolumeChecksumAlgorithm `json:"algorithm"`
+	// Value is the hex digest of the data, 64 hex digits for sha256, 128 for sha512 and 32 for md5
+	Value string `json:"value"`
+}
+
+// DataVolumeChecksumAlgorithm is the hash algorithm of the checksum of a source
+type DataVolumeChecksumAlgorithm string
+
+const (
+	// DataVolumeChecksumSHA256 is the sha256 algorithm
+	DataVolumeChecksumSHA256 DataVolumeChecksumAlgorithm = "sha256"
+	// DataVolumeChecksumSHA512 is the sha512 algorithm
+	DataVolumeChecksumSHA512 DataVolumeChecksumAlgorithm = "sha512"
+	// DataVolumeChecksumMD5 is the md5 algorithm, which is weak: data could be forged to match it
+	DataVolumeChecksumMD5 DataVolumeChecksumAlgorithm = "md5"
+)
+
+// DataVolumeSourceSignature provides the parameters to verify a detached OpenPGP signature of the data of a source
+type DataVolumeSourceSignature struct {
+	// KeyConfigMap is a ConfigMap reference, containing the armored OpenPGP public keys trusted to sign the data in any of its keys
+	KeyConfigMap string `json:"keyConfigMap"`
+	// URL is the URL of the detached signature, armored or binary, such as the .sig or .asc file published along with the data
+	// +optional
+	URL string `json:"url,omitempty"`
+	// Armored is the armored detached signature of the data, when it is not downloaded from URL
+	// +optional
+	Armored string `json:"armored,omitempty"`
 }
 
 // DataVolumeSourceImageIO provides the parameters to create a Data Volume from an imageio source
@@ -241,6 +428,8 @@ type DataVolumeSourceVDDK struct {
 	URL string `json:"url,omitempty"`
 	// UUID is the UUID of the virtual machine that the backing file is attached to in vCenter/ESXi
 	UUID string `json:"uuid,omitempty"`
+	// Moref is the managed object reference of the virtual machine in vCenter/ESXi, such as vm-1234, used instead of its UUID
+	Moref string `json:"moref,omitempty"`
 	// BackingFile is the path to the virtual hard disk to migrate from vCenter/ESXi
 	BackingFile string `json:"backingFile,omitempty"`
 	// Thumbprint is the certificate thumbprint of the vCenter or ESXi host
@@ -275,8 +464,29 @@ type DataVolumeStatus struct {
 	Phase    DataVolumePhase    `json:"phase,omitempty"`
 	Progress DataVolumeProgress `json:"progress,omitempty"`
 	// RestartCount is the number of times the pod populating the DataVolume has restarted
-	RestartCount int32                 `json:"restartCount,omitempty"`
-	Conditions   []DataVolumeCondition `json:"conditions,omitempty" optional:"true"`
+	RestartCount int32 `json:"restartCount,omitempty"`
+	// ImportAttempts is the number of attempts of the import populating the DataVolume, the running one included
+	ImportAttempts int32 `json:"importAttempts,omitempty"`
+	// LastImportFailure is the reason the last failed attempt of the import populating the DataVolume failed with
+	LastImportFailure string `json:"lastImportFailure,omitempty"`
+	// Qcow2Options are the cluster size and the compat level of the qcow2 disk image written to the PVC, followed by compressed=true when its clusters are compressed, cluster_size=65536,compat=1.1 for instance
+	Qcow2Options string `json:"qcow2Options,omitempty"`
+	// SourceURL is the URL of the http source, of its mirror, or the URL it was redirected to, which served the data of the import
+	SourceURL string `json:"sourceURL,omitempty"`
+	// Preallocation is the preallocation requested for the PVC, by the DataVolume or by the CDIConfig, and the one performed by the pod populating it
+	Preallocation *DataVolumePreallocationStatus `json:"preallocation,omitempty"`
+	Conditions    []DataVolumeCondition          `json:"conditions,omitempty" optional:"true"`
+}
+
+// DataVolumePreallocationStatus is the preallocation requested for the PVC of a DataVolume, and the one performed
+type DataVolumePreallocationStatus struct {
+	// Requested is true when preallocation is requested, by the preallocation of the DataVolume, or of the CDIConfig when the DataVolume does not set it
+	Requested bool `json:"requested"`
+	// Applied is true once the pod populating the PVC preallocated the disk image
+	Applied bool `json:"applied"`
+	// Mode is the preallocation performed, falloc or full, full when falloc was requested but the filesystem does not support fallocate
+	// +optional
+	Mode DataVolumePreallocationMode `json:"mode,omitempty"`
 }
 
 // DataVolumeList provides the needed parameters to do request a list of Data Volumes from the system
@@ -302,7 +512,8 @@ type DataVolumeCondition struct {
 // DataVolumePhase is the current phase of the DataVolume
 type DataVolumePhase string
 
-// DataVolumeProgress is the current progress of the DataVolume transfer operation. Value between 0 and 100 inclusive, N/A if not available
+// DataVolumeProgress is the current progress of the DataVolume transfer operation. Value between 0 and 100 inclusive, the bytes transferred
+// when the size of the source is unknown, N/A if not available
 type DataVolumeProgress string
 
 // DataVolumeConditionType is the string representation of known condition types
@@ -356,6 +567,10 @@ const (
 	// WaitForFirstConsumer represents a data volume with a current phase of WaitForFirstConsumer
 	WaitForFirstConsumer DataVolumePhase = "WaitForFirstConsumer"
 
+	// PendingPopulation represents a data volume whose WaitForFirstConsumer PVC waits for the CDI pod populating it,
+	// its first consumer, to be scheduled
+	PendingPopulation DataVolumePhase = "PendingPopulation"
+
 	// Succeeded represents a DataVolumePhase of Succeeded
 	Succeeded DataVolumePhase = "Succeeded"
 	// Failed represents a DataVolumePhase of Failed
@@ -758,17 +973,43 @@ type CDIConfigSpec struct {
 	ImportProxy *ImportProxy `json:"importProxy,omitempty"`
 	// Override the storage class to used for scratch space during transfer operations. The scratch space storage class is determined in the following order: 1. value of scratchSpaceStorageClass, if that doesn't exist, use the default storage class, if there is no default storage class, use the storage class of the DataVolume, if no storage class specified, use no storage class for scratch space
 	ScratchSpaceStorageClass *string `json:"scratchSpaceStorageClass,omitempty"`
+	// ScratchSpaceSizeMultiplier is the size of the scratch space relative to the size of the DataVolume, such as 1.5 or 0.5, overridden by the cdi.kubevirt.io/storage.scratch.sizeMultiplier annotation of the DataVolume. Defaults to 1.
+	// +kubebuilder:validation:Pattern=`^\d+(\.\d{1,3})?$`
+	// +optional
+	ScratchSpaceSizeMultiplier *string `json:"scratchSpaceSizeMultiplier,omitempty"`
 	// ResourceRequirements describes the compute resource requirements.
 	PodResourceRequirements *corev1.ResourceRequirements `json:"podResourceRequirements,omitempty"`
+	// PodPriorityClassName is the default priority class of the importer, cloner and uploader pods, overridden by the priorityClassName of the DataVolume. The pods have no priority class by default.
+	// +optional
+	PodPriorityClassName *string `json:"podPriorityClassName,omitempty"`
 	// FeatureGates are a list of specific enabled feature gates
 	FeatureGates []string `json:"featureGates,omitempty"`
 	// FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A value is between 0 and 1, if not defined it is 0.055 (5.5% overhead)
 	FilesystemOverhead *FilesystemOverhead `json:"filesystemOverhead,omitempty"`
 	// Preallocation controls whether storage for DataVolumes should be allocated in advance.
 	Preallocation *bool `json:"preallocation,omitempty"`
+	// DiskFormat is the default format of the disk images written to filesystem volumes by imports, raw or qcow2. Defaults to raw.
+	// +kubebuilder:validation:Enum="raw";"qcow2"
+	DiskFormat *DataVolumeDiskFormat `json:"diskFormat,omitempty"`
+	// QemuImg bounds the resources of the qemu-img subprocess of the importer pods
+	// +optional
+	QemuImg *QemuImgConfig `json:"qemuImg,omitempty"`
+	// ImportBandwidthLimit is the default limit of the rate at which each import reads the data of its source, in bytes per second, overridden by the cdi.kubevirt.io/storage.import.bandwidthLimit annotation of the DataVolume. Not limited by default.
+	// +optional
+	ImportBandwidthLimit *resource.Quantity `json:"importBandwidthLimit,omitempty"`
+	// ImportRetryPolicy bounds the retries of the failed imports and the backoff between them, overridden by the annotations of the DataVolume. The kubelet restarts the failed importer pods without limit by default.
+	// +optional
+	ImportRetryPolicy *ImportRetryPolicy `json:"importRetryPolicy,omitempty"`
+	// NFS configures the mounts of the NFS exports holding the files of the NFS sources
+	// +optional
+	NFS *NFSConfig `json:"nfs,omitempty"`
 	// InsecureRegistries is a list of TLS disabled registries
 	InsecureRegistries []string `json:"insecureRegistries,omitempty"`
-	// DataVolumeTTLSeconds is the time in seconds after DataVolume completion it can be garbage collected. The default is 0 sec. To disable GC use -1.
+	// RegistryPlatform is the <os>/<architecture>[/<variant>] platform of the image imported from a manifest list of a registry when the list has no image for the platform of the node of the importer, such as linux/amd64. The cdi.kubevirt.io/storage.import.registryPlatform annotation of the DataVolume selects the platform instead.
+	// +kubebuilder:validation:Pattern=`^[^/\s]+/[^/\s]+(/[^/\s]+)?$`
+	// +optional
+	RegistryPlatform *string `json:"registryPlatform,omitempty"`
+	// DataVolumeTTLSeconds is the time in seconds after DataVolume completion it can be garbage collected. The default is -1, disabling GC. A DataVolume can override it with the cdi.kubevirt.io/storage.ttlSecondsAfterCompletion annotation.
 	// +optional
 	DataVolumeTTLSeconds *int32 `json:"dataVolumeTTLSeconds,omitempty"`
 	// TLSSecurityProfile is used by operators to apply cluster-wide TLS security settings to operands.
@@ -784,12 +1025,22 @@ type CDIConfigStatus struct {
 	ImportProxy *ImportProxy `json:"importProxy,omitempty"`
 	// The calculated storage class to be used for scratch space
 	ScratchSpaceStorageClass string `json:"scratchSpaceStorageClass,omitempty"`
+	// The calculated size multiplier of the scratch space
+	ScratchSpaceSizeMultiplier string `json:"scratchSpaceSizeMultiplier,omitempty"`
 	// ResourceRequirements describes the compute resource requirements.
 	DefaultPodResourceRequirements *corev1.ResourceRequirements `json:"defaultPodResourceRequirements,omitempty"`
 	// FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A percentage value is between 0 and 1
 	FilesystemOverhead *FilesystemOverhead `json:"filesystemOverhead,omitempty"`
 	// Preallocation controls whether storage for DataVolumes should be allocated in advance.
 	Preallocation bool `json:"preallocation,omitempty"`
+	// DiskFormat is the default format of the disk images written to filesystem volumes by imports
+	DiskFormat DataVolumeDiskFormat `json:"diskFormat,omitempty"`
+	// The calculated default limit of the rate at which each import reads the data of its source, in bytes per second, unset when the imports are not limited
+	// +optional
+	ImportBandwidthLimit *resource.Quantity `json:"importBandwidthLimit,omitempty"`
+	// The calculated default retry policy of the failed imports, its unset backoffs defaulted, unset when the kubelet restarts the failed importer pods
+	// +optional
+	ImportRetryPolicy *ImportRetryPolicy `json:"importRetryPolicy,omitempty"`
 }
 
 // CDIConfigList provides the needed parameters to do request a list of CDIConfigs from the system
@@ -830,3 +1081,48 @@ type ImportProxy struct {
 	// +optional
 	TrustedCAProxy *string `json:"trustedCAProxy,omitempty"`
 }
+
+// QemuImgConfig bounds the resources of the qemu-img subprocess converting and resizing the disk images of the importer pods.
+type QemuImgConfig struct {
+	// CacheMode is the cache mode of the disk image written by a conversion. Defaults to writeback, none bypasses the page cache of the importer pod.
+	// +kubebuilder:validation:Enum="writeback";"writethrough";"none";"directsync";"unsafe"
+	// +optional
+	CacheMode string `json:"cacheMode,omitempty"`
+	// Coroutines is the number of parallel coroutines of a conversion, each one holding a buffer of the data converted. Defaults to 8.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=16
+	// +optional
+	Coroutines *int32 `json:"coroutines,omitempty"`
+	// MemoryLimit is the limit of the address space of qemu-img, which fails rather than getting the importer pod killed once it is reached. Not limited by default.
+	// +optional
+	MemoryLimit *resource.Quantity `json:"memoryLimit,omitempty"`
+	// Niceness is the niceness of qemu-img, from 0 to 19, leaving CPU time to the transfer of the data. Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=19
+	// +optional
+	Niceness *int32 `json:"niceness,omitempty"`
+}
+
+// ImportRetryPolicy bounds the retries of the failed imports and the backoff between them.
+type ImportRetryPolicy struct {
+	// MaxRetries is the number of times a failed import is retried before its DataVolume fails, 0 fails it on its first failure. Not limited by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+	// InitialBackoff is the delay before the first retry of a failed import, doubled by each further retry. Defaults to 10s.
+	// +optional
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+	// MaxBackoff caps the delay before the retry of a failed import. Defaults to 5m.
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+}
+
+// NFSConfig configures the mounts of the NFS exports holding the files of the NFS sources.
+type NFSConfig struct {
+	// MountOptions are the options of the mounts of the NFS exports, such as vers, soft or hard. The exports are always mounted read-only. Defaults to soft,timeo=100,retrans=3.
+	// +optional
+	MountOptions []string `json:"mountOptions,omitempty"`
+	// MountTimeout is the time after which an import fails when its importer pod could not mount the NFS export. Defaults to 5m.
+	// +optional
+	MountTimeout *metav1.Duration `json:"mountTimeout,omitempty"`
+}
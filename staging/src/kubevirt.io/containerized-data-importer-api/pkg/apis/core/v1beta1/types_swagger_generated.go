@@ -20,6 +20,9 @@ func (DataVolumeSpec) SwaggerDoc() map[string]string {
 		"checkpoints":       "Checkpoints is a list of DataVolumeCheckpoints, representing stages in a multistage import.",
 		"finalCheckpoint":   "FinalCheckpoint indicates whether the current DataVolumeCheckpoint is the final checkpoint.",
 		"preallocation":     "Preallocation controls whether storage for DataVolumes should be allocated in advance.",
+		"diskFormat":        "DiskFormat is the format of the disk image written to a filesystem volume by an import, raw or qcow2. Defaults to the diskFormat of the CDIConfig, raw if it is not set.\n+kubebuilder:validation:Enum=\"raw\";\"qcow2\"\n+optional",
+		"preallocationMode": "PreallocationMode is the preallocation of the raw disk image written by an import when preallocation is set, falloc or full. Defaults to falloc, the preallocation is full when the filesystem does not support fallocate.\n+kubebuilder:validation:Enum=\"falloc\";\"full\"\n+optional",
+		"backoffLimit":      "BackoffLimit is the number of times a failed import is retried before the DataVolume fails, 0 fails it on its first failure. Overrides the maxRetries annotation of the DataVolume and the importRetryPolicy of the CDIConfig.\n+kubebuilder:validation:Minimum=0\n+optional",
 	}
 }
 
@@ -47,7 +50,7 @@ func (DataVolumeCheckpoint) SwaggerDoc() map[string]string {
 
 func (DataVolumeSource) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"": "DataVolumeSource represents the source for our Data Volume, this can be HTTP, Imageio, S3, Registry or an existing PVC",
+		"": "DataVolumeSource represents the source for our Data Volume, this can be HTTP, Imageio, S3, GCS, SFTP, Glance, File, NFS, SMB, Registry or an existing PVC",
 	}
 }
 
@@ -69,7 +72,9 @@ func (DataVolumeSourceSnapshot) SwaggerDoc() map[string]string {
 
 func (DataVolumeBlankImage) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"": "DataVolumeBlankImage provides the parameters to create a new raw blank image for the PVC",
+		"":                "DataVolumeBlankImage provides the parameters to create a new raw blank image for the PVC",
+		"filesystem":      "Filesystem is the filesystem created on the blank image, ext4 or xfs, the image is left empty when it is not set\n+kubebuilder:validation:Enum=\"ext4\";\"xfs\"\n+optional",
+		"filesystemLabel": "FilesystemLabel is the label of the filesystem created on the blank image, of letters, digits, '.', '_' and '-', up to 16 characters for ext4 and 12 for xfs\n+optional",
 	}
 }
 
@@ -81,32 +86,112 @@ func (DataVolumeSourceUpload) SwaggerDoc() map[string]string {
 
 func (DataVolumeSourceS3) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":              "DataVolumeSourceS3 provides the parameters to create a Data Volume from an S3 source",
-		"url":           "URL is the url of the S3 source",
-		"secretRef":     "SecretRef provides the secret reference needed to access the S3 source",
+		"":                    "DataVolumeSourceS3 provides the parameters to create a Data Volume from an S3 source",
+		"url":                 "URL is the url of the S3 source",
+		"secretRef":           "SecretRef provides the secret reference needed to access the S3 source",
+		"certConfigMap":       "CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate\n+optional",
+		"encryptionSecretRef": "EncryptionSecretRef is a Secret reference, the secret should contain the passphrase of a LUKS-encrypted qcow2 source image in its passphrase key\n+optional",
+		"checksum":            "Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it\n+optional",
+	}
+}
+
+func (DataVolumeSourceGCS) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "DataVolumeSourceGCS provides the parameters to create a Data Volume from a Google Cloud Storage source",
+		"url":       "URL is the url of the GCS source, gs://bucket/object, optionally followed by #generation to import a given generation of the object",
+		"secretRef": "SecretRef provides the secret reference holding the JSON key of the service account reading the GCS source, in its serviceAccount key. The workload identity of the importer pod is used when it is empty\n+optional",
+		"checksum":  "Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it\n+optional",
+	}
+}
+
+func (DataVolumeSourceSFTP) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "DataVolumeSourceSFTP provides the parameters to create a Data Volume from an SFTP source",
+		"url":       "URL is the url of the SFTP source, sftp://user@host[:port]/path, a path starting with /~/ is relative to the home directory of the user",
+		"secretRef": "SecretRef provides the secret reference holding the password or the private key of the user, in its password or privateKey key, and the known_hosts entries verifying the key of the host, in its knownHosts key",
+		"checksum":  "Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it\n+optional",
+	}
+}
+
+func (DataVolumeSourceGlance) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":              "DataVolumeSourceGlance provides the parameters to create a Data Volume from an OpenStack Glance image",
+		"url":           "URL is the url of the Keystone identity service of the OpenStack cloud, such as https://keystone.example.com:5000/v3",
+		"image":         "Image is the ID or the name of the Glance image",
+		"project":       "Project is the ID of the project owning the image, an image named by Image is looked up among the images of this project\n+optional",
+		"region":        "Region is the region of the image service in the service catalog of the cloud, the first image service of the catalog is used when it is empty\n+optional",
+		"secretRef":     "SecretRef provides the secret reference holding the ID and the secret of the application credential authenticating to Keystone, in its accessKeyId and secretKey keys",
 		"certConfigMap": "CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate\n+optional",
 	}
 }
 
+func (DataVolumeSourceFile) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "DataVolumeSourceFile provides the parameters to create a Data Volume from a file of a PVC or of a directory of the node, mounted read-only in the importer pod",
+		"path":      "Path is the path of the file, relative to the root of the PVC or of the directory of the node",
+		"pvc":       "PVC is the name of the PVC holding the file, in the namespace of the Data Volume unless Namespace is set\n+optional",
+		"namespace": "Namespace is the namespace of the PVC holding the file, the file of a PVC of another namespace is imported with the permission of the user to clone the PVCs of that namespace\n+optional",
+		"hostPath":  "HostPath is the absolute path of the directory of the node holding the file, allowed by the HostPathImport feature gate\n+optional",
+	}
+}
+
+func (DataVolumeSourceNFS) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":    "DataVolumeSourceNFS provides the parameters to create a Data Volume from a file of an NFS export, whose directory is mounted read-only in the importer pod",
+		"url": "URL is the URL of the file, nfs://server/path/file",
+	}
+}
+
+func (DataVolumeSourceSMB) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "DataVolumeSourceSMB provides the parameters to create a Data Volume from a file of an SMB share, read by the importer without mounting the share",
+		"url":       "URL is the URL of the file, smb://server[:port]/share/path, the DFS links of the path are followed",
+		"secretRef": "SecretRef provides the secret reference holding the name and the password of the user, in its username and password keys, and the domain of the user in its optional domain key",
+		"checksum":  "Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it\n+optional",
+	}
+}
+
 func (DataVolumeSourceRegistry) SwaggerDoc() map[string]string {
 	return map[string]string{
 		"":              "DataVolumeSourceRegistry provides the parameters to create a Data Volume from an registry source",
-		"url":           "URL is the url of the registry source (starting with the scheme: docker, oci-archive)\n+optional",
+		"url":           "URL is the url of the registry source (starting with the scheme: docker, oci, oci-archive)\n+optional",
 		"imageStream":   "ImageStream is the name of image stream for import\n+optional",
 		"pullMethod":    "PullMethod can be either \"pod\" (default import), or \"node\" (node docker cache based import)\n+optional",
 		"secretRef":     "SecretRef provides the secret reference needed to access the Registry source\n+optional",
 		"certConfigMap": "CertConfigMap provides a reference to the Registry certs\n+optional",
+		"checksum":      "Checksum is the checksum of the disk image of the container image, as it is stored in the image. The import fails if the disk image does not match it. Not supported by the node pull method\n+optional",
 	}
 }
 
 func (DataVolumeSourceHTTP) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":                   "DataVolumeSourceHTTP can be either an http or https endpoint, with an optional basic auth user name and password, and an optional configmap containing additional CAs",
-		"url":                "URL is the URL of the http(s) endpoint",
-		"secretRef":          "SecretRef A Secret reference, the secret should contain accessKeyId (user name) base64 encoded, and secretKey (password) also base64 encoded\n+optional",
-		"certConfigMap":      "CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate\n+optional",
-		"extraHeaders":       "ExtraHeaders is a list of strings containing extra headers to include with HTTP transfer requests\n+optional",
-		"secretExtraHeaders": "SecretExtraHeaders is a list of Secret references, each containing an extra HTTP header that may include sensitive information\n+optional",
+		"":                    "DataVolumeSourceHTTP can be either an http or https endpoint, with an optional basic auth user name and password, and an optional configmap containing additional CAs",
+		"url":                 "URL is the URL of the http(s) or ftp(s) endpoint",
+		"secretRef":           "SecretRef A Secret reference, the secret should contain accessKeyId (user name) base64 encoded, and secretKey (password) also base64 encoded, or a bearer token in its token key, and optionally a client certificate in its tls.crt and tls.key keys\n+optional",
+		"certConfigMap":       "CertConfigMap is a configmap reference, containing a Certificate Authority(CA) public key, and a base64 encoded pem certificate\n+optional",
+		"extraHeaders":        "ExtraHeaders is a list of strings containing extra headers to include with HTTP transfer requests\n+optional",
+		"secretExtraHeaders":  "SecretExtraHeaders is a list of Secret references, each containing an extra HTTP header that may include sensitive information\n+optional",
+		"encryptionSecretRef": "EncryptionSecretRef is a Secret reference, the secret should contain the passphrase of a LUKS-encrypted qcow2 source image in its passphrase key\n+optional",
+		"mirrors":             "Mirrors is an ordered list of http(s) URLs of the same data, tried in turn when the URL, or the previous mirror, cannot be reached or fails with a server error\n+optional",
+		"checksum":            "Checksum is the checksum of the data of the source, before it is decompressed. The import fails if the data does not match it\n+optional",
+		"signature":           "Signature is a detached OpenPGP signature of the data of the source, before it is decompressed. The import fails if the data is not signed by one of the trusted keys\n+optional",
+	}
+}
+
+func (DataVolumeChecksum) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "DataVolumeChecksum is the checksum the data of a source is verified against",
+		"algorithm": "Algorithm is the hash algorithm of the checksum, sha256, sha512 or md5\n+kubebuilder:validation:Enum=\"sha256\";\"sha512\";\"md5\"",
+		"value":     "Value is the hex digest of the data, 64 hex digits for sha256, 128 for sha512 and 32 for md5",
+	}
+}
+
+func (DataVolumeSourceSignature) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":             "DataVolumeSourceSignature provides the parameters to verify a detached OpenPGP signature of the data of a source",
+		"keyConfigMap": "KeyConfigMap is a ConfigMap reference, containing the armored OpenPGP public keys trusted to sign the data in any of its keys",
+		"url":          "URL is the URL of the detached signature, armored or binary, such as the .sig or .asc file published along with the data\n+optional",
+		"armored":      "Armored is the armored detached signature of the data, when it is not downloaded from URL\n+optional",
 	}
 }
 
@@ -125,6 +210,7 @@ func (DataVolumeSourceVDDK) SwaggerDoc() map[string]string {
 		"":             "DataVolumeSourceVDDK provides the parameters to create a Data Volume from a Vmware source",
 		"url":          "URL is the URL of the vCenter or ESXi host with the VM to migrate",
 		"uuid":         "UUID is the UUID of the virtual machine that the backing file is attached to in vCenter/ESXi",
+		"moref":        "Moref is the managed object reference of the virtual machine in vCenter/ESXi, such as vm-1234, used instead of its UUID",
 		"backingFile":  "BackingFile is the path to the virtual hard disk to migrate from vCenter/ESXi",
 		"thumbprint":   "Thumbprint is the certificate thumbprint of the vCenter or ESXi host",
 		"secretRef":    "SecretRef provides a reference to a secret containing the username and password needed to access the vCenter or ESXi host",
@@ -143,10 +229,24 @@ func (DataVolumeSourceRef) SwaggerDoc() map[string]string {
 
 func (DataVolumeStatus) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":             "DataVolumeStatus contains the current status of the DataVolume",
-		"claimName":    "ClaimName is the name of the underlying PVC used by the DataVolume.",
-		"phase":        "Phase is the current phase of the data volume",
-		"restartCount": "RestartCount is the number of times the pod populating the DataVolume has restarted",
+		"":                  "DataVolumeStatus contains the current status of the DataVolume",
+		"claimName":         "ClaimName is the name of the underlying PVC used by the DataVolume.",
+		"phase":             "Phase is the current phase of the data volume",
+		"restartCount":      "RestartCount is the number of times the pod populating the DataVolume has restarted",
+		"importAttempts":    "ImportAttempts is the number of attempts of the import populating the DataVolume, the running one included",
+		"lastImportFailure": "LastImportFailure is the reason the last failed attempt of the import populating the DataVolume failed with",
+		"qcow2Options":      "Qcow2Options are the cluster size and the compat level of the qcow2 disk image written to the PVC, followed by compressed=true when its clusters are compressed, cluster_size=65536,compat=1.1 for instance",
+		"sourceURL":         "SourceURL is the URL of the http source, of its mirror, or the URL it was redirected to, which served the data of the import",
+		"preallocation":     "Preallocation is the preallocation requested for the PVC, by the DataVolume or by the CDIConfig, and the one performed by the pod populating it",
+	}
+}
+
+func (DataVolumePreallocationStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "DataVolumePreallocationStatus is the preallocation requested for the PVC of a DataVolume, and the one performed",
+		"requested": "Requested is true when preallocation is requested, by the preallocation of the DataVolume, or of the CDIConfig when the DataVolume does not set it",
+		"applied":   "Applied is true once the pod populating the PVC preallocated the disk image",
+		"mode":      "Mode is the preallocation performed, falloc or full, full when falloc was requested but the filesystem does not support fallocate\n+optional",
 	}
 }
 
@@ -364,17 +464,25 @@ func (FilesystemOverhead) SwaggerDoc() map[string]string {
 
 func (CDIConfigSpec) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":                         "CDIConfigSpec defines specification for user configuration",
-		"uploadProxyURLOverride":   "Override the URL used when uploading to a DataVolume",
-		"importProxy":              "ImportProxy contains importer pod proxy configuration.\n+optional",
-		"scratchSpaceStorageClass": "Override the storage class to used for scratch space during transfer operations. The scratch space storage class is determined in the following order: 1. value of scratchSpaceStorageClass, if that doesn't exist, use the default storage class, if there is no default storage class, use the storage class of the DataVolume, if no storage class specified, use no storage class for scratch space",
-		"podResourceRequirements":  "ResourceRequirements describes the compute resource requirements.",
-		"featureGates":             "FeatureGates are a list of specific enabled feature gates",
-		"filesystemOverhead":       "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A value is between 0 and 1, if not defined it is 0.055 (5.5% overhead)",
-		"preallocation":            "Preallocation controls whether storage for DataVolumes should be allocated in advance.",
-		"insecureRegistries":       "InsecureRegistries is a list of TLS disabled registries",
-		"dataVolumeTTLSeconds":     "DataVolumeTTLSeconds is the time in seconds after DataVolume completion it can be garbage collected. The default is 0 sec. To disable GC use -1.\n+optional",
-		"tlsSecurityProfile":       "TLSSecurityProfile is used by operators to apply cluster-wide TLS security settings to operands.",
+		"":                           "CDIConfigSpec defines specification for user configuration",
+		"uploadProxyURLOverride":     "Override the URL used when uploading to a DataVolume",
+		"importProxy":                "ImportProxy contains importer pod proxy configuration.\n+optional",
+		"scratchSpaceStorageClass":   "Override the storage class to used for scratch space during transfer operations. The scratch space storage class is determined in the following order: 1. value of scratchSpaceStorageClass, if that doesn't exist, use the default storage class, if there is no default storage class, use the storage class of the DataVolume, if no storage class specified, use no storage class for scratch space",
+		"scratchSpaceSizeMultiplier": "ScratchSpaceSizeMultiplier is the size of the scratch space relative to the size of the DataVolume, such as 1.5 or 0.5, overridden by the cdi.kubevirt.io/storage.scratch.sizeMultiplier annotation of the DataVolume. Defaults to 1.\n+kubebuilder:validation:Pattern=`^\\d+(\\.\\d{1,3})?$`\n+optional",
+		"podResourceRequirements":    "ResourceRequirements describes the compute resource requirements.",
+		"podPriorityClassName":       "PodPriorityClassName is the default priority class of the importer, cloner and uploader pods, overridden by the priorityClassName of the DataVolume. The pods have no priority class by default.\n+optional",
+		"featureGates":               "FeatureGates are a list of specific enabled feature gates",
+		"filesystemOverhead":         "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A value is between 0 and 1, if not defined it is 0.055 (5.5% overhead)",
+		"preallocation":              "Preallocation controls whether storage for DataVolumes should be allocated in advance.",
+		"diskFormat":                 "DiskFormat is the default format of the disk images written to filesystem volumes by imports, raw or qcow2. Defaults to raw.\n+kubebuilder:validation:Enum=\"raw\";\"qcow2\"",
+		"qemuImg":                    "QemuImg bounds the resources of the qemu-img subprocess of the importer pods\n+optional",
+		"importBandwidthLimit":       "ImportBandwidthLimit is the default limit of the rate at which each import reads the data of its source, in bytes per second, overridden by the cdi.kubevirt.io/storage.import.bandwidthLimit annotation of the DataVolume. Not limited by default.\n+optional",
+		"importRetryPolicy":          "ImportRetryPolicy bounds the retries of the failed imports and the backoff between them, overridden by the annotations of the DataVolume. The kubelet restarts the failed importer pods without limit by default.\n+optional",
+		"nfs":                        "NFS configures the mounts of the NFS exports holding the files of the NFS sources\n+optional",
+		"insecureRegistries":         "InsecureRegistries is a list of TLS disabled registries",
+		"registryPlatform":           "RegistryPlatform is the <os>/<architecture>[/<variant>] platform of the image imported from a manifest list of a registry when the list has no image for the platform of the node of the importer, such as linux/amd64. The cdi.kubevirt.io/storage.import.registryPlatform annotation of the DataVolume selects the platform instead.\n+kubebuilder:validation:Pattern=`^[^/\\s]+/[^/\\s]+(/[^/\\s]+)?$`\n+optional",
+		"dataVolumeTTLSeconds":       "DataVolumeTTLSeconds is the time in seconds after DataVolume completion it can be garbage collected. The default is -1, disabling GC. A DataVolume can override it with the cdi.kubevirt.io/storage.ttlSecondsAfterCompletion annotation.\n+optional",
+		"tlsSecurityProfile":         "TLSSecurityProfile is used by operators to apply cluster-wide TLS security settings to operands.",
 	}
 }
 
@@ -384,9 +492,13 @@ func (CDIConfigStatus) SwaggerDoc() map[string]string {
 		"uploadProxyURL":                 "The calculated upload proxy URL",
 		"importProxy":                    "ImportProxy contains importer pod proxy configuration.\n+optional",
 		"scratchSpaceStorageClass":       "The calculated storage class to be used for scratch space",
+		"scratchSpaceSizeMultiplier":     "The calculated size multiplier of the scratch space",
 		"defaultPodResourceRequirements": "ResourceRequirements describes the compute resource requirements.",
 		"filesystemOverhead":             "FilesystemOverhead describes the space reserved for overhead when using Filesystem volumes. A percentage value is between 0 and 1",
 		"preallocation":                  "Preallocation controls whether storage for DataVolumes should be allocated in advance.",
+		"diskFormat":                     "DiskFormat is the default format of the disk images written to filesystem volumes by imports",
+		"importBandwidthLimit":           "The calculated default limit of the rate at which each import reads the data of its source, in bytes per second, unset when the imports are not limited\n+optional",
+		"importRetryPolicy":              "The calculated default retry policy of the failed imports, its unset backoffs defaulted, unset when the kubelet restarts the failed importer pods\n+optional",
 	}
 }
 
@@ -406,3 +518,30 @@ func (ImportProxy) SwaggerDoc() map[string]string {
 		"trustedCAProxy": "TrustedCAProxy is the name of a ConfigMap in the cdi namespace that contains a user-provided trusted certificate authority (CA) bundle.\nThe TrustedCAProxy ConfigMap is consumed by the DataImportCron controller for creating cronjobs, and by the import controller referring a copy of the ConfigMap in the import namespace.\nHere is an example of the ConfigMap (in yaml):\n\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: my-ca-proxy-cm\n  namespace: cdi\ndata:\n  ca.pem: |",
 	}
 }
+
+func (QemuImgConfig) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":            "QemuImgConfig bounds the resources of the qemu-img subprocess converting and resizing the disk images of the importer pods.",
+		"cacheMode":   "CacheMode is the cache mode of the disk image written by a conversion. Defaults to writeback, none bypasses the page cache of the importer pod.\n+kubebuilder:validation:Enum=\"writeback\";\"writethrough\";\"none\";\"directsync\";\"unsafe\"\n+optional",
+		"coroutines":  "Coroutines is the number of parallel coroutines of a conversion, each one holding a buffer of the data converted. Defaults to 8.\n+kubebuilder:validation:Minimum=1\n+kubebuilder:validation:Maximum=16\n+optional",
+		"memoryLimit": "MemoryLimit is the limit of the address space of qemu-img, which fails rather than getting the importer pod killed once it is reached. Not limited by default.\n+optional",
+		"niceness":    "Niceness is the niceness of qemu-img, from 0 to 19, leaving CPU time to the transfer of the data. Defaults to 10.\n+kubebuilder:validation:Minimum=0\n+kubebuilder:validation:Maximum=19\n+optional",
+	}
+}
+
+func (ImportRetryPolicy) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":               "ImportRetryPolicy bounds the retries of the failed imports and the backoff between them.",
+		"maxRetries":     "MaxRetries is the number of times a failed import is retried before its DataVolume fails, 0 fails it on its first failure. Not limited by default.\n+kubebuilder:validation:Minimum=0\n+optional",
+		"initialBackoff": "InitialBackoff is the delay before the first retry of a failed import, doubled by each further retry. Defaults to 10s.\n+optional",
+		"maxBackoff":     "MaxBackoff caps the delay before the retry of a failed import. Defaults to 5m.\n+optional",
+	}
+}
+
+func (NFSConfig) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":             "NFSConfig configures the mounts of the NFS exports holding the files of the NFS sources.",
+		"mountOptions": "MountOptions are the options of the mounts of the NFS exports, such as vers, soft or hard. The exports are always mounted read-only. Defaults to soft,timeo=100,retrans=3.\n+optional",
+		"mountTimeout": "MountTimeout is the time after which an import fails when its importer pod could not mount the NFS export. Defaults to 5m.\n+optional",
+	}
+}
@@ -161,11 +161,21 @@ func (in *CDIConfigSpec) DeepCopyInto(out *CDIConfigSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ScratchSpaceSizeMultiplier != nil {
+		in, out := &in.ScratchSpaceSizeMultiplier, &out.ScratchSpaceSizeMultiplier
+		*out = new(string)
+		**out = **in
+	}
 	if in.PodResourceRequirements != nil {
 		in, out := &in.PodResourceRequirements, &out.PodResourceRequirements
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodPriorityClassName != nil {
+		in, out := &in.PodPriorityClassName, &out.PodPriorityClassName
+		*out = new(string)
+		**out = **in
+	}
 	if in.FeatureGates != nil {
 		in, out := &in.FeatureGates, &out.FeatureGates
 		*out = make([]string, len(*in))
@@ -181,11 +191,41 @@ func (in *CDIConfigSpec) DeepCopyInto(out *CDIConfigSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.DiskFormat != nil {
+		in, out := &in.DiskFormat, &out.DiskFormat
+		*out = new(DataVolumeDiskFormat)
+		**out = **in
+	}
+	if in.QemuImg != nil {
+		in, out := &in.QemuImg, &out.QemuImg
+		*out = new(QemuImgConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImportBandwidthLimit != nil {
+		in, out := &in.ImportBandwidthLimit, &out.ImportBandwidthLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ImportRetryPolicy != nil {
+		in, out := &in.ImportRetryPolicy, &out.ImportRetryPolicy
+		*out = new(ImportRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NFS != nil {
+		in, out := &in.NFS, &out.NFS
+		*out = new(NFSConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.InsecureRegistries != nil {
 		in, out := &in.InsecureRegistries, &out.InsecureRegistries
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RegistryPlatform != nil {
+		in, out := &in.RegistryPlatform, &out.RegistryPlatform
+		*out = new(string)
+		**out = **in
+	}
 	if in.DataVolumeTTLSeconds != nil {
 		in, out := &in.DataVolumeTTLSeconds, &out.DataVolumeTTLSeconds
 		*out = new(int32)
@@ -232,6 +272,16 @@ func (in *CDIConfigStatus) DeepCopyInto(out *CDIConfigStatus) {
 		*out = new(FilesystemOverhead)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ImportBandwidthLimit != nil {
+		in, out := &in.ImportBandwidthLimit, &out.ImportBandwidthLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ImportRetryPolicy != nil {
+		in, out := &in.ImportRetryPolicy, &out.ImportRetryPolicy
+		*out = new(ImportRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -764,6 +814,22 @@ func (in *DataVolumeCheckpoint) DeepCopy() *DataVolumeCheckpoint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeChecksum) DeepCopyInto(out *DataVolumeChecksum) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeChecksum.
+func (in *DataVolumeChecksum) DeepCopy() *DataVolumeChecksum {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeChecksum)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeCondition) DeepCopyInto(out *DataVolumeCondition) {
 	*out = *in
@@ -815,6 +881,22 @@ func (in *DataVolumeList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumePreallocationStatus) DeepCopyInto(out *DataVolumePreallocationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumePreallocationStatus.
+func (in *DataVolumePreallocationStatus) DeepCopy() *DataVolumePreallocationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumePreallocationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeSource) DeepCopyInto(out *DataVolumeSource) {
 	*out = *in
@@ -826,8 +908,38 @@ func (in *DataVolumeSource) DeepCopyInto(out *DataVolumeSource) {
 	if in.S3 != nil {
 		in, out := &in.S3, &out.S3
 		*out = new(DataVolumeSourceS3)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(DataVolumeSourceGCS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SFTP != nil {
+		in, out := &in.SFTP, &out.SFTP
+		*out = new(DataVolumeSourceSFTP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Glance != nil {
+		in, out := &in.Glance, &out.Glance
+		*out = new(DataVolumeSourceGlance)
+		**out = **in
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(DataVolumeSourceFile)
 		**out = **in
 	}
+	if in.NFS != nil {
+		in, out := &in.NFS, &out.NFS
+		*out = new(DataVolumeSourceNFS)
+		**out = **in
+	}
+	if in.SMB != nil {
+		in, out := &in.SMB, &out.SMB
+		*out = new(DataVolumeSourceSMB)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Registry != nil {
 		in, out := &in.Registry, &out.Registry
 		*out = new(DataVolumeSourceRegistry)
@@ -876,6 +988,59 @@ func (in *DataVolumeSource) DeepCopy() *DataVolumeSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceFile) DeepCopyInto(out *DataVolumeSourceFile) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceFile.
+func (in *DataVolumeSourceFile) DeepCopy() *DataVolumeSourceFile {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceGCS) DeepCopyInto(out *DataVolumeSourceGCS) {
+	*out = *in
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(DataVolumeChecksum)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceGCS.
+func (in *DataVolumeSourceGCS) DeepCopy() *DataVolumeSourceGCS {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceGCS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceGlance) DeepCopyInto(out *DataVolumeSourceGlance) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceGlance.
+func (in *DataVolumeSourceGlance) DeepCopy() *DataVolumeSourceGlance {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceGlance)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeSourceHTTP) DeepCopyInto(out *DataVolumeSourceHTTP) {
 	*out = *in
@@ -889,6 +1054,21 @@ func (in *DataVolumeSourceHTTP) DeepCopyInto(out *DataVolumeSourceHTTP) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(DataVolumeChecksum)
+		**out = **in
+	}
+	if in.Signature != nil {
+		in, out := &in.Signature, &out.Signature
+		*out = new(DataVolumeSourceSignature)
+		**out = **in
+	}
 	return
 }
 
@@ -918,6 +1098,22 @@ func (in *DataVolumeSourceImageIO) DeepCopy() *DataVolumeSourceImageIO {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceNFS) DeepCopyInto(out *DataVolumeSourceNFS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceNFS.
+func (in *DataVolumeSourceNFS) DeepCopy() *DataVolumeSourceNFS {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceNFS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeSourcePVC) DeepCopyInto(out *DataVolumeSourcePVC) {
 	*out = *in
@@ -983,6 +1179,11 @@ func (in *DataVolumeSourceRegistry) DeepCopyInto(out *DataVolumeSourceRegistry)
 		*out = new(string)
 		**out = **in
 	}
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(DataVolumeChecksum)
+		**out = **in
+	}
 	return
 }
 
@@ -999,6 +1200,11 @@ func (in *DataVolumeSourceRegistry) DeepCopy() *DataVolumeSourceRegistry {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeSourceS3) DeepCopyInto(out *DataVolumeSourceS3) {
 	*out = *in
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(DataVolumeChecksum)
+		**out = **in
+	}
 	return
 }
 
@@ -1012,6 +1218,64 @@ func (in *DataVolumeSourceS3) DeepCopy() *DataVolumeSourceS3 {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceSFTP) DeepCopyInto(out *DataVolumeSourceSFTP) {
+	*out = *in
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(DataVolumeChecksum)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceSFTP.
+func (in *DataVolumeSourceSFTP) DeepCopy() *DataVolumeSourceSFTP {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceSFTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceSMB) DeepCopyInto(out *DataVolumeSourceSMB) {
+	*out = *in
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(DataVolumeChecksum)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceSMB.
+func (in *DataVolumeSourceSMB) DeepCopy() *DataVolumeSourceSMB {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceSMB)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeSourceSignature) DeepCopyInto(out *DataVolumeSourceSignature) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSourceSignature.
+func (in *DataVolumeSourceSignature) DeepCopy() *DataVolumeSourceSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeSourceSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeSourceSnapshot) DeepCopyInto(out *DataVolumeSourceSnapshot) {
 	*out = *in
@@ -1093,6 +1357,11 @@ func (in *DataVolumeSpec) DeepCopyInto(out *DataVolumeSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -1109,6 +1378,11 @@ func (in *DataVolumeSpec) DeepCopy() *DataVolumeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataVolumeStatus) DeepCopyInto(out *DataVolumeStatus) {
 	*out = *in
+	if in.Preallocation != nil {
+		in, out := &in.Preallocation, &out.Preallocation
+		*out = new(DataVolumePreallocationStatus)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]DataVolumeCondition, len(*in))
@@ -1188,6 +1462,37 @@ func (in *ImportProxy) DeepCopy() *ImportProxy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportRetryPolicy) DeepCopyInto(out *ImportRetryPolicy) {
+	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportRetryPolicy.
+func (in *ImportRetryPolicy) DeepCopy() *ImportRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImportStatus) DeepCopyInto(out *ImportStatus) {
 	*out = *in
@@ -1204,6 +1509,32 @@ func (in *ImportStatus) DeepCopy() *ImportStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSConfig) DeepCopyInto(out *NFSConfig) {
+	*out = *in
+	if in.MountOptions != nil {
+		in, out := &in.MountOptions, &out.MountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MountTimeout != nil {
+		in, out := &in.MountTimeout, &out.MountTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSConfig.
+func (in *NFSConfig) DeepCopy() *NFSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectTransfer) DeepCopyInto(out *ObjectTransfer) {
 	*out = *in
@@ -1336,6 +1667,37 @@ func (in *ObjectTransferStatus) DeepCopy() *ObjectTransferStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QemuImgConfig) DeepCopyInto(out *QemuImgConfig) {
+	*out = *in
+	if in.Coroutines != nil {
+		in, out := &in.Coroutines, &out.Coroutines
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MemoryLimit != nil {
+		in, out := &in.MemoryLimit, &out.MemoryLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Niceness != nil {
+		in, out := &in.Niceness, &out.Niceness
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QemuImgConfig.
+func (in *QemuImgConfig) DeepCopy() *QemuImgConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QemuImgConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageProfile) DeepCopyInto(out *StorageProfile) {
 	*out = *in
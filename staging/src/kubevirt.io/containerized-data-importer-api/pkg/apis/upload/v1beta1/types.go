@@ -55,3 +55,53 @@ type UploadTokenRequestList struct {
 	// Items contains a list of UploadTokenRequests
 	Items []UploadTokenRequest `json:"items"`
 }
+
+// CloneTokenRequest is the CR used to request a token authorizing a DataVolume of another namespace to clone a PVC
+// or a snapshot of the namespace of the request
+// +genclient
+// +genclient:onlyVerbs=create
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CloneTokenRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec contains the parameters of the request
+	Spec CloneTokenRequestSpec `json:"spec"`
+
+	// Status contains the status of the request
+	Status CloneTokenRequestStatus `json:"status"`
+}
+
+// CloneTokenRequestSpec defines the parameters of the clone token request
+type CloneTokenRequestSpec struct {
+	// SourceName is the name of the PVC or of the snapshot to clone
+	SourceName string `json:"sourceName"`
+	// SourceKind is the kind of the source, PersistentVolumeClaim or VolumeSnapshot, PersistentVolumeClaim by default
+	// +optional
+	SourceKind string `json:"sourceKind,omitempty"`
+	// TargetNamespace is the namespace of the DataVolume cloning the source
+	TargetNamespace string `json:"targetNamespace"`
+	// TargetName is the name of the DataVolume cloning the source
+	TargetName string `json:"targetName"`
+	// ExpirationSeconds is the lifetime of the token in seconds, 3600 by default and 86400 at most
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// CloneTokenRequestStatus stores the status of a clone token request
+type CloneTokenRequestStatus struct {
+	// Token is a JWT token to be set in the cdi.kubevirt.io/storage.clone.token annotation of the DataVolume
+	Token string `json:"token,omitempty"`
+	// ExpirationTimestamp is the time the token expires
+	ExpirationTimestamp metav1.Time `json:"expirationTimestamp,omitempty"`
+}
+
+// CloneTokenRequestList contains a list of CloneTokenRequests
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CloneTokenRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items contains a list of CloneTokenRequests
+	Items []CloneTokenRequest `json:"items"`
+}
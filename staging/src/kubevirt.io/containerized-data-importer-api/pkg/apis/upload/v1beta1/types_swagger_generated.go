@@ -30,3 +30,37 @@ func (UploadTokenRequestList) SwaggerDoc() map[string]string {
 		"items": "Items contains a list of UploadTokenRequests",
 	}
 }
+
+func (CloneTokenRequest) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":       "CloneTokenRequest is the CR used to request a token authorizing a DataVolume of another namespace to clone a PVC\nor a snapshot of the namespace of the request\n+genclient\n+genclient:onlyVerbs=create\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object",
+		"spec":   "Spec contains the parameters of the request",
+		"status": "Status contains the status of the request",
+	}
+}
+
+func (CloneTokenRequestSpec) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                  "CloneTokenRequestSpec defines the parameters of the clone token request",
+		"sourceName":        "SourceName is the name of the PVC or of the snapshot to clone",
+		"sourceKind":        "SourceKind is the kind of the source, PersistentVolumeClaim or VolumeSnapshot, PersistentVolumeClaim by default\n+optional",
+		"targetNamespace":   "TargetNamespace is the namespace of the DataVolume cloning the source",
+		"targetName":        "TargetName is the name of the DataVolume cloning the source",
+		"expirationSeconds": "ExpirationSeconds is the lifetime of the token in seconds, 3600 by default and 86400 at most\n+optional",
+	}
+}
+
+func (CloneTokenRequestStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                    "CloneTokenRequestStatus stores the status of a clone token request",
+		"token":               "Token is a JWT token to be set in the cdi.kubevirt.io/storage.clone.token annotation of the DataVolume",
+		"expirationTimestamp": "ExpirationTimestamp is the time the token expires",
+	}
+}
+
+func (CloneTokenRequestList) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":      "CloneTokenRequestList contains a list of CloneTokenRequests\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object",
+		"items": "Items contains a list of CloneTokenRequests",
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	"kubevirt.io/containerized-data-importer/pkg/controller"
+	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
 	dvc "kubevirt.io/containerized-data-importer/pkg/controller/datavolume"
 	"kubevirt.io/containerized-data-importer/tests/framework"
 	"kubevirt.io/containerized-data-importer/tests/utils"
@@ -81,7 +82,7 @@ var _ = Describe("DataSource", func() {
 
 		By("Create clone DV with SourceRef pointing the DataSource")
 		dv := utils.NewDataVolumeWithSourceRef("clone-dv", "1Gi", ds.Namespace, ds.Name)
-		dv.Annotations[controller.AnnImmediateBinding] = "true"
+		dv.Annotations[cc.AnnImmediateBinding] = "true"
 		Expect(dv).ToNot(BeNil())
 		dv, err = utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dv)
 		Expect(err).ToNot(HaveOccurred())
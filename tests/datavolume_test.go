@@ -28,6 +28,7 @@ import (
 	controller "kubevirt.io/containerized-data-importer/pkg/controller/common"
 	dvc "kubevirt.io/containerized-data-importer/pkg/controller/datavolume"
 	featuregates "kubevirt.io/containerized-data-importer/pkg/feature-gates"
+	"kubevirt.io/containerized-data-importer/pkg/image"
 	"kubevirt.io/containerized-data-importer/pkg/util/naming"
 	"kubevirt.io/containerized-data-importer/tests/framework"
 	"kubevirt.io/containerized-data-importer/tests/utils"
@@ -64,27 +65,48 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 	httpsTinyCoreVmdkURL := func() string {
 		return fmt.Sprintf(utils.HTTPSTinyCoreVmdkURL, f.CdiInstallNs)
 	}
+	httpsTinyCoreStreamOptimizedVmdkURL := func() string {
+		return fmt.Sprintf(utils.HTTPSTinyCoreStreamOptimizedVmdkURL, f.CdiInstallNs)
+	}
 	httpsTinyCoreVdiURL := func() string {
 		return fmt.Sprintf(utils.HTTPSTinyCoreVdiURL, f.CdiInstallNs)
 	}
 	httpsTinyCoreVhdURL := func() string {
 		return fmt.Sprintf(utils.HTTPSTinyCoreVhdURL, f.CdiInstallNs)
 	}
+	httpsTinyCoreFixedVhdURL := func() string {
+		return fmt.Sprintf(utils.HTTPSTinyCoreFixedVhdURL, f.CdiInstallNs)
+	}
 	httpsTinyCoreVhdxURL := func() string {
 		return fmt.Sprintf(utils.HTTPSTinyCoreVhdxURL, f.CdiInstallNs)
 	}
+	httpsTinyCoreQedURL := func() string {
+		return fmt.Sprintf(utils.HTTPSTinyCoreQedURL, f.CdiInstallNs)
+	}
+	httpsTinyCoreParallelsURL := func() string {
+		return fmt.Sprintf(utils.HTTPSTinyCoreParallelsURL, f.CdiInstallNs)
+	}
 	tinyCoreQcow2URL := func() string {
 		return fmt.Sprintf(utils.TinyCoreQcow2URL+".gz", f.CdiInstallNs)
 	}
+	tinyCoreQcow2ZipURL := func() string {
+		return fmt.Sprintf(utils.TinyCoreQcow2URL+".zip", f.CdiInstallNs)
+	}
 	tinyCoreIsoRegistryURL := func() string {
 		return fmt.Sprintf(utils.TinyCoreIsoRegistryURL, f.CdiInstallNs)
 	}
+	tinyCoreIsoOciRegistryURL := func() string {
+		return strings.Replace(tinyCoreIsoRegistryURL(), "docker://", "oci://", 1)
+	}
 	tinyCoreIsoRegistryProxyURL := func() string {
 		return fmt.Sprintf(utils.TinyCoreIsoRegistryProxyURL, f.CdiInstallNs)
 	}
 	tinyCoreIsoAuthURL := func() string {
 		return fmt.Sprintf(utils.TinyCoreIsoAuthURL, f.CdiInstallNs)
 	}
+	tinyCoreIsoTokenURL := func() string {
+		return fmt.Sprintf(utils.TinyCoreIsoTokenURL, f.CdiInstallNs)
+	}
 	tarArchiveURL := func() string {
 		return fmt.Sprintf(utils.TarArchiveURL, f.CdiInstallNs)
 	}
@@ -280,6 +302,19 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 			return dataVolume
 		}
 
+		createHTTPBearerToken := func(dataVolumeName, size, url string) *cdiv1.DataVolume {
+			stringData := map[string]string{
+				common.KeyToken: utils.BearerTokenValue,
+			}
+			secret, err := utils.CreateSecretFromDefinition(f.K8sClient, utils.NewSecretDefinition(nil, stringData, nil, f.Namespace.Name, "bearer-token"))
+			Expect(err).ToNot(HaveOccurred())
+
+			dataVolume := utils.NewDataVolumeWithHTTPImport(dataVolumeName, size, url)
+			dataVolume.Spec.Source.HTTP.SecretRef = secret.Name
+			dataVolume.Spec.Source.HTTP.ExtraHeaders = []string{utils.RouteHeader}
+			return dataVolume
+		}
+
 		createCloneDataVolume := func(dataVolumeName, size, command string) *cdiv1.DataVolume {
 			sourcePodFillerName := fmt.Sprintf("%s-filler-pod", dataVolumeName)
 			pvcDef := utils.NewPVCDefinition(pvcName, size, nil, nil)
@@ -456,6 +491,30 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 					Message: "Import Complete",
 					Reason:  "Completed",
 				}}),
+			table.Entry("succeed creating import dv from a zip archive", dataVolumeTestArguments{
+				name:             "dv-http-import-zip",
+				size:             "1Gi",
+				url:              tinyCoreQcow2ZipURL,
+				dvFunc:           utils.NewDataVolumeWithHTTPImport,
+				eventReason:      dvc.ImportSucceeded,
+				phase:            cdiv1.Succeeded,
+				checkPermissions: true,
+				readyCondition: &cdiv1.DataVolumeCondition{
+					Type:   cdiv1.DataVolumeReady,
+					Status: v1.ConditionTrue,
+				},
+				boundCondition: &cdiv1.DataVolumeCondition{
+					Type:    cdiv1.DataVolumeBound,
+					Status:  v1.ConditionTrue,
+					Message: "PVC dv-http-import-zip Bound",
+					Reason:  "Bound",
+				},
+				runningCondition: &cdiv1.DataVolumeCondition{
+					Type:    cdiv1.DataVolumeRunning,
+					Status:  v1.ConditionFalse,
+					Message: "Import Complete",
+					Reason:  "Completed",
+				}}),
 			table.Entry("[rfe_id:1115][crit:high][posneg:negative][test_id:1358]fail creating import dv due to invalid DNS entry", dataVolumeTestArguments{
 				name:         "dv-http-import-invalid-url",
 				size:         "1Gi",
@@ -465,8 +524,10 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 				eventReason:  "Error",
 				phase:        cdiv1.ImportInProgress,
 				readyCondition: &cdiv1.DataVolumeCondition{
-					Type:   cdiv1.DataVolumeReady,
-					Status: v1.ConditionFalse,
+					Type:    cdiv1.DataVolumeReady,
+					Status:  v1.ConditionFalse,
+					Message: "Unable to connect to http data source",
+					Reason:  string(cdiv1.ImportInProgress),
 				},
 				boundCondition: &cdiv1.DataVolumeCondition{
 					Type:    cdiv1.DataVolumeBound,
@@ -489,8 +550,10 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 				eventReason:  "Error",
 				phase:        cdiv1.ImportInProgress,
 				readyCondition: &cdiv1.DataVolumeCondition{
-					Type:   cdiv1.DataVolumeReady,
-					Status: v1.ConditionFalse,
+					Type:    cdiv1.DataVolumeReady,
+					Status:  v1.ConditionFalse,
+					Message: "Unable to connect to http data source: expected status code 200, got 404. Status: 404 Not Found",
+					Reason:  string(cdiv1.ImportInProgress),
 				},
 				boundCondition: &cdiv1.DataVolumeCondition{
 					Type:    cdiv1.DataVolumeBound,
@@ -513,8 +576,10 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 				eventReason:      "Error",
 				phase:            cdiv1.ImportInProgress,
 				readyCondition: &cdiv1.DataVolumeCondition{
-					Type:   cdiv1.DataVolumeReady,
-					Status: v1.ConditionFalse,
+					Type:    cdiv1.DataVolumeReady,
+					Status:  v1.ConditionFalse,
+					Message: "L1 size too big",
+					Reason:  string(cdiv1.ImportInProgress),
 				},
 				boundCondition: &cdiv1.DataVolumeCondition{
 					Type:    cdiv1.DataVolumeBound,
@@ -672,6 +737,30 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 					Message: "Import Complete",
 					Reason:  "Completed",
 				}}),
+			table.Entry("succeed creating import dv with a bearer token and a routing header", dataVolumeTestArguments{
+				name:             "dv-http-import-token",
+				size:             "1Gi",
+				url:              tinyCoreIsoTokenURL,
+				dvFunc:           createHTTPBearerToken,
+				eventReason:      dvc.ImportSucceeded,
+				phase:            cdiv1.Succeeded,
+				checkPermissions: true,
+				readyCondition: &cdiv1.DataVolumeCondition{
+					Type:   cdiv1.DataVolumeReady,
+					Status: v1.ConditionTrue,
+				},
+				boundCondition: &cdiv1.DataVolumeCondition{
+					Type:    cdiv1.DataVolumeBound,
+					Status:  v1.ConditionTrue,
+					Message: "PVC dv-http-import-token Bound",
+					Reason:  "Bound",
+				},
+				runningCondition: &cdiv1.DataVolumeCondition{
+					Type:    cdiv1.DataVolumeRunning,
+					Status:  v1.ConditionFalse,
+					Message: "Import Complete",
+					Reason:  "Completed",
+				}}),
 			table.Entry("[rfe_id:1111][crit:high][test_id:1361]succeed creating blank image dv", dataVolumeTestArguments{
 				name:             "blank-image-dv",
 				size:             "1Gi",
@@ -751,8 +840,10 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 				eventReason:  "Error",
 				phase:        cdiv1.ImportInProgress,
 				readyCondition: &cdiv1.DataVolumeCondition{
-					Type:   cdiv1.DataVolumeReady,
-					Status: v1.ConditionFalse,
+					Type:    cdiv1.DataVolumeReady,
+					Status:  v1.ConditionFalse,
+					Message: "Unable to process data",
+					Reason:  string(cdiv1.ImportInProgress),
 				},
 				boundCondition: &cdiv1.DataVolumeCondition{
 					Type:    cdiv1.DataVolumeBound,
@@ -890,6 +981,30 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 					Message: "Import Complete",
 					Reason:  "Completed",
 				}}),
+			table.Entry("succeed creating import dv with given valid oci registry url", dataVolumeTestArguments{
+				name:             "dv-import-registry",
+				size:             "1Gi",
+				url:              tinyCoreIsoOciRegistryURL,
+				dvFunc:           createRegistryImportDataVolume,
+				eventReason:      dvc.ImportSucceeded,
+				phase:            cdiv1.Succeeded,
+				checkPermissions: true,
+				readyCondition: &cdiv1.DataVolumeCondition{
+					Type:   cdiv1.DataVolumeReady,
+					Status: v1.ConditionTrue,
+				},
+				boundCondition: &cdiv1.DataVolumeCondition{
+					Type:    cdiv1.DataVolumeBound,
+					Status:  v1.ConditionTrue,
+					Message: "PVC dv-import-registry Bound",
+					Reason:  "Bound",
+				},
+				runningCondition: &cdiv1.DataVolumeCondition{
+					Type:    cdiv1.DataVolumeRunning,
+					Status:  v1.ConditionFalse,
+					Message: "Import Complete",
+					Reason:  "Completed",
+				}}),
 			table.Entry("[rfe_id:4334][test_id:6433]succeed creating import dv with given valid registry url and DV barely big enough", dataVolumeTestArguments{
 				name:             "dv-import-registry",
 				size:             "22Mi", // The image has 18M
@@ -1005,6 +1120,7 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 				readyCondition: &cdiv1.DataVolumeCondition{
 					Type:   cdiv1.DataVolumeReady,
 					Status: v1.ConditionFalse,
+					Reason: string(cdiv1.ImportScheduled),
 				},
 				boundCondition: &cdiv1.DataVolumeCondition{
 					Type:    cdiv1.DataVolumeBound,
@@ -1343,10 +1459,17 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 		)
 	})
 
-	table.DescribeTable("Succeed HTTPS import in various formats", func(url func() string, skipOnOpenshift bool) {
+	table.DescribeTable("Succeed HTTPS import in various formats", func(url func() string, skipOnOpenshift bool, qemuFormat string) {
 		if skipOnOpenshift && utils.IsOpenshift(f.K8sClient) {
 			Skip("This test doesn't work when building using centos, see: https://bugzilla.redhat.com/show_bug.cgi?id=2013331")
 		}
+		if qemuFormat != "" {
+			supported, err := f.QemuImgSupportsFormat(qemuFormat)
+			Expect(err).ToNot(HaveOccurred())
+			if !supported {
+				Skip(fmt.Sprintf("The qemu-img of the importer image does not support the %s format", qemuFormat))
+			}
+		}
 		By(fmt.Sprintf("Importing from %s", url()))
 		dataVolume := utils.NewDataVolumeWithHTTPImport(dataVolumeName, "1Gi", url())
 		cm, err := utils.CopyFileHostCertConfigMap(f.K8sClient, f.Namespace.Name, f.CdiInstallNs)
@@ -1369,12 +1492,229 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 		Expect(err).ToNot(HaveOccurred())
 		Expect(md5).To(Equal(utils.TinyCoreMD5))
 	},
-		table.Entry("when importing in the VMDK format", httpsTinyCoreVmdkURL, false),
-		table.Entry("When importing in the VDI format", httpsTinyCoreVdiURL, true),
-		table.Entry("when importing in the VHD format", httpsTinyCoreVhdURL, false),
-		table.Entry("when importing in the VHDX format", httpsTinyCoreVhdxURL, false),
+		table.Entry("when importing in the VMDK format", httpsTinyCoreVmdkURL, false, ""),
+		table.Entry("when importing in the streamOptimized VMDK format", httpsTinyCoreStreamOptimizedVmdkURL, false, ""),
+		table.Entry("When importing in the VDI format", httpsTinyCoreVdiURL, true, ""),
+		table.Entry("when importing in the VHD format", httpsTinyCoreVhdURL, false, ""),
+		table.Entry("when importing in the fixed VHD format", httpsTinyCoreFixedVhdURL, false, ""),
+		table.Entry("when importing in the VHDX format", httpsTinyCoreVhdxURL, false, ""),
+		table.Entry("when importing in the QED format", httpsTinyCoreQedURL, false, image.QemuFormatQed),
+		table.Entry("when importing in the Parallels format", httpsTinyCoreParallelsURL, false, image.QemuFormatParallels),
+	)
+
+	It("Fail HTTPS import of a VMDK image with external extents", func() {
+		url := fmt.Sprintf(utils.HTTPSTinyCoreMultiExtentVmdkURL, f.CdiInstallNs)
+		By(fmt.Sprintf("Importing from %s", url))
+		dataVolume := utils.NewDataVolumeWithHTTPImport(dataVolumeName, "1Gi", url)
+		cm, err := utils.CopyFileHostCertConfigMap(f.K8sClient, f.Namespace.Name, f.CdiInstallNs)
+		Expect(err).To(BeNil())
+		dataVolume.Spec.Source.HTTP.CertConfigMap = cm
+
+		By(fmt.Sprintf("creating new datavolume %s", dataVolume.Name))
+		dataVolume, err = utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dataVolume)
+		Expect(err).ToNot(HaveOccurred())
+		f.ForceBindPvcIfDvIsWaitForFirstConsumer(dataVolume)
+
+		By("Verifying the import failed on the extent files")
+		Eventually(func() bool {
+			events, err := f.RunKubectlCommand("get", "events", "-n", dataVolume.Namespace)
+			if err == nil {
+				fmt.Fprintf(GinkgoWriter, "%s", events)
+				return strings.Contains(events, cont.ErrImportFailedPVC) && strings.Contains(events, "external extent files")
+			}
+			fmt.Fprintf(GinkgoWriter, "ERROR: %s\n", err.Error())
+			return false
+		}, timeout, pollingInterval).Should(BeTrue())
+	})
+
+	Context("importing a qcow2 image with a backing file", func() {
+		// createOverlayDataVolume imports from url, with the passed in annotations
+		createOverlayDataVolume := func(url string, annotations map[string]string) *cdiv1.DataVolume {
+			By(fmt.Sprintf("Importing from %s", url))
+			dataVolume := utils.NewDataVolumeWithHTTPImport(dataVolumeName, "1Gi", url)
+			cm, err := utils.CopyFileHostCertConfigMap(f.K8sClient, f.Namespace.Name, f.CdiInstallNs)
+			Expect(err).To(BeNil())
+			dataVolume.Spec.Source.HTTP.CertConfigMap = cm
+			for k, v := range annotations {
+				dataVolume.Annotations[k] = v
+			}
+
+			By(fmt.Sprintf("creating new datavolume %s", dataVolume.Name))
+			dataVolume, err = utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dataVolume)
+			Expect(err).ToNot(HaveOccurred())
+			f.ForceBindPvcIfDvIsWaitForFirstConsumer(dataVolume)
+			return dataVolume
+		}
+
+		It("should fail the import of the image alone", func() {
+			dataVolume := createOverlayDataVolume(fmt.Sprintf(utils.HTTPSTinyCoreOverlayQcow2URL, f.CdiInstallNs), nil)
+
+			By("Verifying the import failed on the backing file")
+			Eventually(func() bool {
+				events, err := f.RunKubectlCommand("get", "events", "-n", dataVolume.Namespace)
+				if err == nil {
+					fmt.Fprintf(GinkgoWriter, "%s", events)
+					return strings.Contains(events, cont.ErrImportFailedPVC) && strings.Contains(events, "backing file")
+				}
+				fmt.Fprintf(GinkgoWriter, "ERROR: %s\n", err.Error())
+				return false
+			}, timeout, pollingInterval).Should(BeTrue())
+		})
+
+		It("should flatten the backing chain extracted from a tar archive", func() {
+			dataVolume := createOverlayDataVolume(fmt.Sprintf(utils.HTTPSTinyCoreBackingChainTarURL, f.CdiInstallNs), map[string]string{
+				controller.AnnArchiveEntry:        "tinyCore" + utils.ExtOverlayQcow2,
+				controller.AnnFlattenBackingChain: "true",
+			})
+
+			err := utils.WaitForDataVolumePhase(f, f.Namespace.Name, cdiv1.Succeeded, dataVolume.Name)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Verify content")
+			pvc, err := utils.FindPVC(f.K8sClient, dataVolume.Namespace, dataVolume.Name)
+			Expect(err).ToNot(HaveOccurred())
+			md5, err := f.GetMD5(f.Namespace, pvc, utils.DefaultImagePath, utils.MD5PrefixSize)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(md5).To(Equal(utils.TinyCoreMD5))
+		})
+	})
+
+	Context("importing a LUKS-encrypted qcow2 image", func() {
+		// createEncryptedDataVolume imports the encrypted image with the passphrase held by a new secret
+		createEncryptedDataVolume := func(passphrase string) *cdiv1.DataVolume {
+			By("Creating the secret of the passphrase")
+			secret := utils.NewSecretDefinition(nil, map[string]string{common.KeyPassphrase: passphrase}, nil, f.Namespace.Name, "passphrase-secret")
+			secret, err := utils.CreateSecretFromDefinition(f.K8sClient, secret)
+			Expect(err).ToNot(HaveOccurred())
+
+			url := fmt.Sprintf(utils.HTTPSTinyCoreLuksQcow2URL, f.CdiInstallNs)
+			By(fmt.Sprintf("Importing from %s", url))
+			dataVolume := utils.NewDataVolumeWithHTTPImport(dataVolumeName, "1Gi", url)
+			cm, err := utils.CopyFileHostCertConfigMap(f.K8sClient, f.Namespace.Name, f.CdiInstallNs)
+			Expect(err).To(BeNil())
+			dataVolume.Spec.Source.HTTP.CertConfigMap = cm
+			dataVolume.Spec.Source.HTTP.EncryptionSecretRef = secret.Name
+
+			By(fmt.Sprintf("creating new datavolume %s", dataVolume.Name))
+			dataVolume, err = utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dataVolume)
+			Expect(err).ToNot(HaveOccurred())
+			f.ForceBindPvcIfDvIsWaitForFirstConsumer(dataVolume)
+			return dataVolume
+		}
+
+		It("should decrypt the image with the passphrase of the secret", func() {
+			dataVolume := createEncryptedDataVolume(utils.LuksPassphrase)
+
+			err := utils.WaitForDataVolumePhase(f, f.Namespace.Name, cdiv1.Succeeded, dataVolume.Name)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Verify content")
+			pvc, err := utils.FindPVC(f.K8sClient, dataVolume.Namespace, dataVolume.Name)
+			Expect(err).ToNot(HaveOccurred())
+			md5, err := f.GetMD5(f.Namespace, pvc, utils.DefaultImagePath, utils.MD5PrefixSize)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(md5).To(Equal(utils.TinyCoreMD5))
+		})
+
+		It("should fail the import with a wrong passphrase", func() {
+			dataVolume := createEncryptedDataVolume("wrong-passphrase")
+
+			By("Verifying the import failed on the encryption key")
+			Eventually(func() bool {
+				events, err := f.RunKubectlCommand("get", "events", "-n", dataVolume.Namespace)
+				if err == nil {
+					fmt.Fprintf(GinkgoWriter, "%s", events)
+					return strings.Contains(events, cont.ErrImportFailedPVC) && strings.Contains(events, "invalid encryption key")
+				}
+				fmt.Fprintf(GinkgoWriter, "ERROR: %s\n", err.Error())
+				return false
+			}, timeout, pollingInterval).Should(BeTrue())
+		})
+	})
+
+	table.DescribeTable("should write a qcow2 disk image", func(url string, compress bool) {
+		dataVolume := utils.NewDataVolumeWithHTTPImport(dataVolumeName, "1Gi", fmt.Sprintf(url, f.CdiInstallNs))
+		dataVolume.Spec.DiskFormat = cdiv1.DataVolumeDiskFormatQcow2
+		if compress {
+			controller.AddAnnotation(dataVolume, controller.AnnCompressQcow2, "true")
+		}
+
+		By(fmt.Sprintf("creating new datavolume %s", dataVolume.Name))
+		dataVolume, err := utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dataVolume)
+		Expect(err).ToNot(HaveOccurred())
+		f.ForceBindPvcIfDvIsWaitForFirstConsumer(dataVolume)
+
+		err = utils.WaitForDataVolumePhase(f, f.Namespace.Name, cdiv1.Succeeded, dataVolume.Name)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("Verifying the format of the disk image is recorded")
+		pvc, err := utils.FindPVC(f.K8sClient, dataVolume.Namespace, dataVolume.Name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Annotations[controller.AnnDiskFormat]).To(Equal(string(cdiv1.DataVolumeDiskFormatQcow2)))
+	},
+		table.Entry("converted from a raw image", utils.TinyCoreIsoURL, false),
+		table.Entry("copied from a qcow2 image", utils.TinyCoreQcow2URL, false),
+		table.Entry("compressed from a qcow2 image", utils.TinyCoreQcow2URL, true),
 	)
 
+	It("should compress the clusters of a qcow2 disk image", func() {
+		// the virtual size of the tinyCore images, preserved rather than grown to the PVC
+		const tinyCoreVirtualSize = 18874368
+		dataVolume := utils.NewDataVolumeWithHTTPImport(dataVolumeName, "1Gi", fmt.Sprintf(utils.TinyCoreQcow2URL, f.CdiInstallNs))
+		dataVolume.Spec.DiskFormat = cdiv1.DataVolumeDiskFormatQcow2
+		controller.AddAnnotation(dataVolume, controller.AnnCompressQcow2, "true")
+		controller.AddAnnotation(dataVolume, controller.AnnPreserveImageSize, "true")
+
+		By(fmt.Sprintf("creating new datavolume %s", dataVolume.Name))
+		dataVolume, err := utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dataVolume)
+		Expect(err).ToNot(HaveOccurred())
+		f.ForceBindPvcIfDvIsWaitForFirstConsumer(dataVolume)
+
+		err = utils.WaitForDataVolumePhase(f, f.Namespace.Name, cdiv1.Succeeded, dataVolume.Name)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("Verifying the compression is recorded")
+		pvc, err := utils.FindPVC(f.K8sClient, dataVolume.Namespace, dataVolume.Name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Annotations[controller.AnnQcow2Options]).To(HaveSuffix("compressed=true"))
+		dataVolume, err = f.CdiClient.CdiV1beta1().DataVolumes(dataVolume.Namespace).Get(context.TODO(), dataVolume.Name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dataVolume.Status.Qcow2Options).To(HaveSuffix("compressed=true"))
+
+		By("Verifying the clusters are compressed")
+		var clusters int64
+		Expect(f.GetCompressedClusters(f.Namespace, pvc, utils.DefaultImagePath, &clusters)).To(Succeed())
+		Expect(clusters).To(BeNumerically(">", 0))
+
+		By("Verifying the virtual size of the image")
+		var info image.ImgInfo
+		Expect(f.GetImageInfo(f.Namespace, pvc, utils.DefaultImagePath, &info)).To(Succeed())
+		Expect(info.Format).To(Equal(image.QemuFormatQcow2))
+		Expect(info.VirtualSize).To(Equal(int64(tinyCoreVirtualSize)))
+	})
+
+	It("should write an ISO9660 image as it is", func() {
+		dataVolume := utils.NewDataVolumeWithHTTPImport(dataVolumeName, "1Gi", fmt.Sprintf(utils.TinyCoreIsoURL, f.CdiInstallNs))
+
+		By(fmt.Sprintf("creating new datavolume %s", dataVolume.Name))
+		dataVolume, err := utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dataVolume)
+		Expect(err).ToNot(HaveOccurred())
+		f.ForceBindPvcIfDvIsWaitForFirstConsumer(dataVolume)
+
+		err = utils.WaitForDataVolumePhase(f, f.Namespace.Name, cdiv1.Succeeded, dataVolume.Name)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("Verifying the format of the source is recorded")
+		pvc, err := utils.FindPVC(f.K8sClient, dataVolume.Namespace, dataVolume.Name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvc.Annotations[controller.AnnSourceFormat]).To(Equal("iso"))
+
+		By("Verify content")
+		md5, err := f.GetMD5(f.Namespace, pvc, utils.DefaultImagePath, utils.MD5PrefixSize)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(md5).To(Equal(utils.TinyCoreMD5))
+	})
+
 	Describe("[rfe_id:1115][crit:high][posneg:negative]Delete resources of DataVolume with an invalid URL (POD in retry loop)", func() {
 		Context("using invalid import URL for DataVolume", func() {
 			dataVolumeName := "invalid-url-dv"
@@ -1624,7 +1964,7 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 			dataVolume := utils.NewCloningDataVolume(dataVolumeName, "10Mi", sourcePvc)
 			dataVolume.Spec.PVC = nil
 			dataVolume.Spec.Storage = &storageSpec
-			dataVolume.Annotations[cont.AnnImmediateBinding] = "true"
+			dataVolume.Annotations[controller.AnnImmediateBinding] = "true"
 
 			dv, err := utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dataVolume)
 			Expect(err).ToNot(HaveOccurred())
@@ -2389,7 +2729,7 @@ var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]DataVolume tests",
 			size := "1Gi"
 
 			dataVolume := dvFunc(dvName, size, url())
-			dataVolume.Annotations[cont.AnnImmediateBinding] = "true"
+			dataVolume.Annotations[controller.AnnImmediateBinding] = "true"
 
 			By(fmt.Sprintf("creating new datavolume %s", dataVolume.Name))
 			dataVolume, err := utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dataVolume)
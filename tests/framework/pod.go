@@ -1,17 +1,25 @@
 package framework
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/onsi/ginkgo"
 
 	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"kubevirt.io/containerized-data-importer/pkg/common"
+	controller "kubevirt.io/containerized-data-importer/pkg/controller/common"
 	"kubevirt.io/containerized-data-importer/tests/utils"
 )
 
+// qemuImgFormatsPrefix starts the line of the formats listed by qemu-img --help
+const qemuImgFormatsPrefix = "Supported formats:"
+
 // CreatePod is a wrapper around utils.CreatePod
 func (f *Framework) CreatePod(podDef *k8sv1.Pod) (*k8sv1.Pod, error) {
 	return utils.CreatePod(f.K8sClient, f.Namespace.Name, podDef)
@@ -60,3 +68,65 @@ func (f *Framework) PrintPodLog(podName, namespace string) {
 		fmt.Fprintf(ginkgo.GinkgoWriter, "INFO: Unable to get pod log, %s\n", err.Error())
 	}
 }
+
+// importerImage returns the importer image the controller creates the importer pods with
+func (f *Framework) importerImage() string {
+	for _, e := range f.ControllerPod.Spec.Containers[0].Env {
+		if e.Name == "IMPORTER_IMAGE" {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+// QemuImgSupportsFormat returns true if the qemu-img of the importer image supports the format,
+// which builds may leave out, as listed by qemu-img --help in a pod of the importer image.
+func (f *Framework) QemuImgSupportsFormat(format string) (bool, error) {
+	pod := &k8sv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "qemu-img-formats-",
+		},
+		Spec: k8sv1.PodSpec{
+			RestartPolicy: k8sv1.RestartPolicyNever,
+			Containers: []k8sv1.Container{
+				{
+					Name:    "runner",
+					Image:   f.importerImage(),
+					Command: []string{"qemu-img", "--help"},
+				},
+			},
+		},
+	}
+	controller.SetRestrictedSecurityContext(&pod.Spec)
+	pod, err := f.CreatePod(pod)
+	if err != nil {
+		return false, err
+	}
+	defer f.DeletePod(pod)
+	// older versions of qemu-img exit with an error after printing the help
+	err = wait.PollImmediate(2*time.Second, utils.PodWaitForTime, func() (bool, error) {
+		pod, err := f.K8sClient.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return pod.Status.Phase == k8sv1.PodSucceeded || pod.Status.Phase == k8sv1.PodFailed, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	help, err := f.RunKubectlCommand("logs", pod.Name, "-n", f.Namespace.Name)
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(help, "\n") {
+		if strings.HasPrefix(line, qemuImgFormatsPrefix) {
+			for _, supported := range strings.Fields(strings.TrimPrefix(line, qemuImgFormatsPrefix)) {
+				if supported == format {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("the help of qemu-img does not list the supported formats:\n%s", help)
+}
@@ -208,6 +208,23 @@ func (f *Framework) verifyInPod(namespace *k8sv1.Namespace, pvc *k8sv1.Persisten
 	return verifyFn(output, stderr)
 }
 
+// verifyCommandInPod runs the command in a verifier pod of the PVC, without a shell, and verifies its output
+func (f *Framework) verifyCommandInPod(namespace *k8sv1.Namespace, pvc *k8sv1.PersistentVolumeClaim, cmd []string, verifyFn func(output, stderr string) (bool, error)) (bool, error) {
+	executorPod, err := f.startVerifierPod(namespace, pvc)
+	if err != nil {
+		fmt.Fprintf(ginkgo.GinkgoWriter, "INFO: could not start verifier pod: [%s]\n", err)
+		return false, err
+	}
+
+	output, stderr, err := f.ExecCommandInPod(executorPod.Name, namespace.Name, cmd...)
+	if err != nil {
+		fmt.Fprintf(ginkgo.GinkgoWriter, "INFO: stderr: [%s]\n", stderr)
+		return false, err
+	}
+
+	return verifyFn(output, stderr)
+}
+
 // VerifyBlankDisk checks a blank disk on a file mode PVC by validating that the disk.img file is sparse.
 func (f *Framework) VerifyBlankDisk(namespace *k8sv1.Namespace, pvc *k8sv1.PersistentVolumeClaim) (bool, error) {
 	cmd := fmt.Sprintf("tr -d '\\000' <%s/disk.img | grep -q -m 1 ^ || echo \"All zeros\"", utils.DefaultPvcMountPath)
@@ -347,13 +364,7 @@ func (f *Framework) RunCommandAndCaptureOutput(pvc *k8sv1.PersistentVolumeClaim,
 
 // NewPodWithPVC creates a new pod that mounts the given PVC
 func (f *Framework) NewPodWithPVC(podName, cmd string, pvc *k8sv1.PersistentVolumeClaim) *k8sv1.Pod {
-	var importerImage string
 	volumeName := naming.GetLabelNameFromResourceName(pvc.GetName())
-	for _, e := range f.ControllerPod.Spec.Containers[0].Env {
-		if e.Name == "IMPORTER_IMAGE" {
-			importerImage = e.Value
-		}
-	}
 	pod := &k8sv1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: podName,
@@ -368,7 +379,7 @@ func (f *Framework) NewPodWithPVC(podName, cmd string, pvc *k8sv1.PersistentVolu
 			Containers: []k8sv1.Container{
 				{
 					Name:    "runner",
-					Image:   importerImage,
+					Image:   f.importerImage(),
 					Command: []string{"/bin/sh", "-c", cmd},
 					Resources: k8sv1.ResourceRequirements{
 						Limits: map[k8sv1.ResourceName]resource.Quantity{
@@ -459,17 +470,37 @@ func addVolumeMounts(pvc *k8sv1.PersistentVolumeClaim, volumeName string) []k8sv
 
 // GetImageInfo returns qemu-img information about given image
 func (f *Framework) GetImageInfo(namespace *k8sv1.Namespace, pvc *k8sv1.PersistentVolumeClaim, imagePath string, info *image.ImgInfo) error {
-	cmd := fmt.Sprintf("qemu-img info %s --output=json", imagePath)
+	cmd := []string{"qemu-img", "info", imagePath, "--output=json"}
 
-	_, err := f.verifyInPod(namespace, pvc, cmd, func(output, stderr string) (bool, error) {
+	_, err := f.verifyCommandInPod(namespace, pvc, cmd, func(output, stderr string) (bool, error) {
 		fmt.Fprintf(ginkgo.GinkgoWriter, "INFO: qemu-img info output %s\n", output)
 
-		err := json.Unmarshal([]byte(output), info)
+		parsed, err := image.ParseImgInfo([]byte(output), imagePath)
 		if err != nil {
-			klog.Errorf("Invalid JSON:\n%s\n", string(output))
 			return false, err
 		}
+		*info = *parsed
+		return true, nil
+	})
 
+	return err
+}
+
+// GetCompressedClusters returns the number of compressed clusters of the qcow2 image, as reported
+// by qemu-img check
+func (f *Framework) GetCompressedClusters(namespace *k8sv1.Namespace, pvc *k8sv1.PersistentVolumeClaim, imagePath string, clusters *int64) error {
+	cmd := []string{"qemu-img", "check", imagePath, "--output=json"}
+
+	_, err := f.verifyCommandInPod(namespace, pvc, cmd, func(output, stderr string) (bool, error) {
+		fmt.Fprintf(ginkgo.GinkgoWriter, "INFO: qemu-img check output %s\n", output)
+
+		var check struct {
+			CompressedClusters int64 `json:"compressed-clusters"`
+		}
+		if err := json.Unmarshal([]byte(output), &check); err != nil {
+			return false, err
+		}
+		*clusters = check.CompressedClusters
 		return true, nil
 	})
 
@@ -767,7 +767,8 @@ var _ = Describe("ALL Operator tests", func() {
 				}
 
 				_, err = f.CdiClient.CdiV1beta1().CDIConfigs().Update(context.TODO(), cdiConfig, metav1.UpdateOptions{})
-				Expect(err).ToNot(HaveOccurred())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("the spec of the CDIConfig is set from the spec.config of the CDI resource"))
 
 				Eventually(func() bool {
 					config, err := f.CdiClient.CdiV1beta1().CDIConfigs().Get(context.TODO(), common.ConfigName, metav1.GetOptions{})
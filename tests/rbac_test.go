@@ -240,6 +240,7 @@ var _ = Describe("Aggregated role definition tests", func() {
 			},
 			Resources: []string{
 				"uploadtokenrequests",
+				"clonetokenrequests",
 			},
 			Verbs: []string{
 				"*",
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"kubevirt.io/containerized-data-importer/pkg/common"
+	"kubevirt.io/containerized-data-importer/tests/framework"
+	"kubevirt.io/containerized-data-importer/tests/utils"
+)
+
+// The smb container of cdi-file-host runs Samba, see manifests/templates/file-host.yaml.in. Its images share serves
+// the images of the file host, its secure share serves them to encrypted sessions only, and its dfs share is the DFS
+// root of the links created by the tests.
+var _ = Describe("[vendor:cnv-qe@redhat.com][level:component]SMB import", func() {
+	const (
+		smbFile      = "tinyCore.iso"
+		smbSecretTag = "smb-import"
+		smbDFSRoot   = "/tmp/shared/dfs"
+	)
+
+	var (
+		f          = framework.NewFramework("smb-import")
+		dataVolume *cdiv1.DataVolume
+	)
+
+	smbServer := func() string {
+		return utils.FileHostName + "." + f.CdiInstallNs
+	}
+
+	smbURL := func(share, name string) string {
+		return fmt.Sprintf("smb://%s/%s/%s", smbServer(), share, name)
+	}
+
+	createSMBSecret := func(password string) string {
+		stringData := map[string]string{
+			common.KeyUsername: utils.AccessKeyValue,
+			common.KeyPassword: password,
+		}
+		secret, err := utils.CreateSecretFromDefinition(f.K8sClient, utils.NewSecretDefinition(nil, stringData, nil, f.Namespace.Name, smbSecretTag))
+		Expect(err).ToNot(HaveOccurred())
+		return secret.Name
+	}
+
+	BeforeEach(func() {
+		By(fmt.Sprintf("Waiting for all \"%s/%s\" deployment replicas to be Ready", f.CdiInstallNs, utils.FileHostName))
+		utils.WaitForDeploymentReplicasReadyOrDie(f.K8sClient, f.CdiInstallNs, utils.FileHostName)
+
+		By("Linking the images share from the DFS root")
+		fileHostPod, err := utils.FindPodByPrefix(f.K8sClient, f.CdiInstallNs, utils.FileHostName, "name="+utils.FileHostName)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = f.ExecCommandInContainerWithFullOutput(fileHostPod.Namespace, fileHostPod.Name, "smb",
+			"/bin/sh",
+			"-c",
+			fmt.Sprintf(`mkdir -p %s && ln -sfn 'msdfs:%s\images' %s/images`, smbDFSRoot, smbServer(), smbDFSRoot))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if dataVolume != nil {
+			By("Delete DV")
+			err := utils.DeleteDataVolume(f.CdiClient, f.Namespace.Name, dataVolume.Name)
+			Expect(err).ToNot(HaveOccurred())
+			dataVolume = nil
+		}
+	})
+
+	table.DescribeTable("should import a file", func(url func() string) {
+		var err error
+		dv := utils.NewDataVolumeWithSMBImport("import-smb", "500Mi", url(), createSMBSecret(utils.SecretKeyValue))
+		By(fmt.Sprintf("Creating new datavolume %s importing %s", dv.Name, dv.Spec.Source.SMB.URL))
+		dataVolume, err = utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dv)
+		Expect(err).ToNot(HaveOccurred())
+		f.ForceBindPvcIfDvIsWaitForFirstConsumer(dataVolume)
+
+		By("Waiting for the import to succeed")
+		err = utils.WaitForDataVolumePhase(f, f.Namespace.Name, cdiv1.Succeeded, dataVolume.Name)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("Verifying the content")
+		pvc, err := f.K8sClient.CoreV1().PersistentVolumeClaims(f.Namespace.Name).Get(context.TODO(), dataVolume.Name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		same, err := f.VerifyTargetPVCContentMD5(f.Namespace, pvc, utils.DefaultImagePath, utils.TinyCoreMD5, utils.MD5PrefixSize)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(same).To(BeTrue())
+	},
+		table.Entry("of a share", func() string { return smbURL("images", smbFile) }),
+		table.Entry("of a share requiring encryption", func() string { return smbURL("secure", smbFile) }),
+		table.Entry("through a DFS link", func() string { return smbURL("dfs", "images/"+smbFile) }),
+	)
+
+	It("should report the failed authentication of a user with a wrong password", func() {
+		var err error
+		dv := utils.NewDataVolumeWithSMBImport("import-smb-wrong-password", "500Mi", smbURL("images", smbFile), createSMBSecret("wrong"))
+		By(fmt.Sprintf("Creating new datavolume %s importing %s", dv.Name, dv.Spec.Source.SMB.URL))
+		dataVolume, err = utils.CreateDataVolumeFromDefinition(f.CdiClient, f.Namespace.Name, dv)
+		Expect(err).ToNot(HaveOccurred())
+		f.ForceBindPvcIfDvIsWaitForFirstConsumer(dataVolume)
+
+		By("Waiting for the running condition to report the failed authentication")
+		Eventually(func() string {
+			dv, err := f.CdiClient.CdiV1beta1().DataVolumes(f.Namespace.Name).Get(context.TODO(), dataVolume.Name, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			for _, condition := range dv.Status.Conditions {
+				if condition.Type == cdiv1.DataVolumeRunning {
+					return condition.Message
+				}
+			}
+			return ""
+		}, timeout, pollingInterval).Should(SatisfyAll(
+			ContainSubstring("Unable to connect to smb data source"),
+			ContainSubstring("authentication of "+utils.AccessKeyValue),
+			ContainSubstring("STATUS_LOGON_FAILURE"),
+		))
+	})
+})
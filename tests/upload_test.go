@@ -272,6 +272,9 @@ var _ = Describe("[rfe_id:138][crit:high][vendor:cnv-qe@redhat.com][level:compon
 		Entry("fail given an invalid token", uploadArchive, false, ""),
 		Entry("succeed upload of tar.gz", uploadArchive, true, image.ExtGz),
 		Entry("succeed upload of tar.xz", uploadArchive, true, image.ExtXz),
+		Entry("succeed upload of tar.bz2", uploadArchive, true, image.ExtBz2),
+		Entry("succeed upload of tar.lz4", uploadArchive, true, image.ExtLz4),
+		Entry("succeed upload of tar.zst", uploadArchive, true, image.ExtZstd),
 	)
 
 	It("[test_id:4988]Verify upload to the same pvc fails", func() {
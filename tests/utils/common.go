@@ -32,12 +32,18 @@ const (
 	FileHostName = "cdi-file-host"
 	// FileHostS3Bucket provides an S3 bucket name for tests (e.g. http://<serviceIP:port>/FileHostS3Bucket/image)
 	FileHostS3Bucket = "images"
-	// AccessKeyValue provides a username to use for http and S3 (see hack/build/docker/cdi-func-test-file-host-http/htpasswd)
+	// AccessKeyValue provides a username to use for http, S3 and SMB (see hack/build/docker/cdi-func-test-file-host-http/htpasswd)
 	AccessKeyValue = "admin"
-	// SecretKeyValue provides a password to use for http and S3 (see hack/build/docker/cdi-func-test-file-host-http/htpasswd)
+	// SecretKeyValue provides a password to use for http, S3 and SMB (see hack/build/docker/cdi-func-test-file-host-http/htpasswd)
 	SecretKeyValue = "password"
 	// HttpAuthPort provides a cdi-file-host service auth port for tests
 	HTTPAuthPort = 81
+	// HTTPTokenPort provides a cdi-file-host service port for tests, requires BearerTokenValue and RouteHeader
+	HTTPTokenPort = 84
+	// BearerTokenValue provides a bearer token to use for http (see tools/cdi-func-test-file-host-init/nginx.conf)
+	BearerTokenValue = "cdi-test-token"
+	// RouteHeader provides the routing header required by the HTTPTokenPort of cdi-file-host
+	RouteHeader = "X-Cdi-Route: images"
 	// HttpNoAuthPort provides a cdi-file-host service no-auth port for tests, requires AccessKeyValue and SecretKeyValue
 	HTTPNoAuthPort = 80
 	// HTTPRateLimitPort provides a cdi-file-host service rate limit port for tests, speed is limited to 25k/s to allow for testing slow connection behavior. No auth.
@@ -6,7 +6,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
-	controller "kubevirt.io/containerized-data-importer/pkg/controller"
+	cc "kubevirt.io/containerized-data-importer/pkg/controller/common"
 )
 
 // NewDataImportCron initializes a DataImportCron struct
@@ -15,7 +15,7 @@ func NewDataImportCron(name, size, schedule, dataSource string, importsToKeep in
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
 			Annotations: map[string]string{
-				controller.AnnImmediateBinding: "true",
+				cc.AnnImmediateBinding: "true",
 			},
 		},
 		Spec: cdiv1.DataImportCronSpec{
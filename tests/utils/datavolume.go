@@ -42,6 +42,8 @@ const (
 	TinyCoreIsoRegistryProxyURL = "docker://cdi-file-host.%s:83/tinycoreqcow2"
 	// TinyCoreIsoAuthURL provides a tinyCore ISO from a URL that requires basic authentication
 	TinyCoreIsoAuthURL = "http://cdi-file-host.%s:81/tinyCore.iso"
+	// TinyCoreIsoTokenURL provides a tinyCore ISO from a URL that requires a bearer token and a routing header
+	TinyCoreIsoTokenURL = "http://cdi-file-host.%s:84/tinyCore.iso"
 	// HTTPSTinyCoreIsoURL provides a test (https) url for the tineyCore iso image
 	HTTPSTinyCoreIsoURL = "https://cdi-file-host.%s/tinyCore.iso"
 	// HTTPSTinyCoreQcow2URL provides a test (https) url for the tineyCore qcow2 image
@@ -52,12 +54,31 @@ const (
 	TinyCoreQcow2GzURLRateLimit = "http://cdi-file-host.%s:82/tinyCore.qcow2.gz"
 	// HTTPSTinyCoreVmdkURL provides a test url for the tineyCore qcow2 image
 	HTTPSTinyCoreVmdkURL = "https://cdi-file-host.%s/tinyCore.vmdk"
+	// HTTPSTinyCoreStreamOptimizedVmdkURL provides a test url for the tinyCore streamOptimized vmdk image
+	HTTPSTinyCoreStreamOptimizedVmdkURL = "https://cdi-file-host.%s/tinyCore.stream.vmdk"
+	// HTTPSTinyCoreMultiExtentVmdkURL provides a test url for the descriptor of the tinyCore vmdk image
+	// split in extents of 2 GiB, the extent files are not served
+	HTTPSTinyCoreMultiExtentVmdkURL = "https://cdi-file-host.%s/tinyCore.split.vmdk"
 	// HTTPSTinyCoreVdiURL provides a test url for the tineyCore qcow2 image
 	HTTPSTinyCoreVdiURL = "https://cdi-file-host.%s/tinyCore.vdi"
 	// HTTPSTinyCoreVhdURL provides a test url for the tineyCore qcow2 image
 	HTTPSTinyCoreVhdURL = "https://cdi-file-host.%s/tinyCore.vhd"
+	// HTTPSTinyCoreFixedVhdURL provides a test url for the tinyCore fixed vhd image
+	HTTPSTinyCoreFixedVhdURL = "https://cdi-file-host.%s/tinyCore.fixed.vhd"
 	// HTTPSTinyCoreVhdxURL provides a test url for the tineyCore qcow2 image
 	HTTPSTinyCoreVhdxURL = "https://cdi-file-host.%s/tinyCore.vhdx"
+	// HTTPSTinyCoreQedURL provides a test url for the tinyCore qed image
+	HTTPSTinyCoreQedURL = "https://cdi-file-host.%s/tinyCore.qed"
+	// HTTPSTinyCoreParallelsURL provides a test url for the tinyCore parallels image
+	HTTPSTinyCoreParallelsURL = "https://cdi-file-host.%s/tinyCore.hds"
+	// HTTPSTinyCoreOverlayQcow2URL provides a test url for a qcow2 overlay image of tinyCore, its backing
+	// file is not served
+	HTTPSTinyCoreOverlayQcow2URL = "https://cdi-file-host.%s/tinyCore.overlay.qcow2"
+	// HTTPSTinyCoreBackingChainTarURL provides a test url for a tar archive of the tinyCore qcow2 overlay
+	// image and of its backing file
+	HTTPSTinyCoreBackingChainTarURL = "https://cdi-file-host.%s/tinyCore.overlay.qcow2.chain.tar"
+	// HTTPSTinyCoreLuksQcow2URL provides a test url for the tinyCore qcow2 image encrypted with LUKS
+	HTTPSTinyCoreLuksQcow2URL = "https://cdi-file-host.%s/tinyCore.luks.qcow2"
 	// InvalidQcowImagesURL provides a test url for invalid qcow images
 	InvalidQcowImagesURL = "http://cdi-file-host.%s/invalid_qcow_images/"
 	// LargeVirtualDiskQcow provides a test url for a cirros image with a large virtual size, in qcow2 format
@@ -204,6 +225,19 @@ func NewDataVolumeWithHTTPImport(dataVolumeName string, size string, httpURL str
 	}
 }
 
+// NewDataVolumeWithSMBImport initializes a DataVolume struct importing a file of an SMB share with the credentials of
+// the secret
+func NewDataVolumeWithSMBImport(dataVolumeName string, size string, smbURL string, secretRef string) *cdiv1.DataVolume {
+	dv := NewDataVolumeWithHTTPImport(dataVolumeName, size, "")
+	dv.Spec.Source = &cdiv1.DataVolumeSource{
+		SMB: &cdiv1.DataVolumeSourceSMB{
+			URL:       smbURL,
+			SecretRef: secretRef,
+		},
+	}
+	return dv
+}
+
 // NewDataVolumeWithHTTPImportAndStorageSpec initializes a DataVolume struct with HTTP annotations
 func NewDataVolumeWithHTTPImportAndStorageSpec(dataVolumeName string, size string, httpURL string) *cdiv1.DataVolume {
 	storageSpec := &cdiv1.StorageSpec{
@@ -2,29 +2,84 @@ package utils
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 	"github.com/pkg/errors"
 	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
 
 	"kubevirt.io/containerized-data-importer/pkg/image"
 )
 
+const (
+	// ExtStreamOptimizedVmdk is the pseudo extension of a streamOptimized vmdk image
+	ExtStreamOptimizedVmdk = ".stream" + image.ExtVmdk
+	// ExtMultiExtentVmdk is the pseudo extension of the descriptor of a twoGbMaxExtentSparse vmdk
+	// image, its extent files are written next to it
+	ExtMultiExtentVmdk = ".split" + image.ExtVmdk
+	// ExtFixedVhd is the pseudo extension of a fixed vhd image, its only footer ends the file
+	ExtFixedVhd = ".fixed" + image.ExtVhd
+	// ExtOverlayQcow2 is the pseudo extension of a qcow2 image holding no data, whose backing file is
+	// a qcow2 image of the source written next to it
+	ExtOverlayQcow2 = ".overlay" + image.ExtQcow2
+	// ExtBackingChainTar is the pseudo extension of a tar archive of a qcow2 image and of its backing
+	// chain
+	ExtBackingChainTar = ".chain" + image.ExtTar
+	// ExtLuksQcow2 is the pseudo extension of a qcow2 image encrypted with LUKS, with the
+	// LuksPassphrase passphrase
+	ExtLuksQcow2 = ".luks" + image.ExtQcow2
+
+	// LuksPassphrase is the passphrase of the ExtLuksQcow2 images
+	LuksPassphrase = "cdi-luks-passphrase"
+)
+
+// qemuSubformats are the subformats written by qemu-img for the pseudo extensions
+var qemuSubformats = map[string]struct{ format, subformat string }{
+	ExtStreamOptimizedVmdk: {"vmdk", "streamOptimized"},
+	ExtMultiExtentVmdk:     {"vmdk", "twoGbMaxExtentSparse"},
+	ExtFixedVhd:            {"vpc", "fixed"},
+}
+
+// qemuOperations writes the images converted by qemu-img
+var qemuOperations = image.NewQEMUOperations()
+
 var formatTable = map[string]func(string, string, string) (string, error){
-	image.ExtGz:    toGz,
-	image.ExtXz:    toXz,
-	image.ExtTar:   toTar,
-	image.ExtQcow2: convertUsingQemuImg,
-	image.ExtVmdk:  convertUsingQemuImg,
-	image.ExtVdi:   convertUsingQemuImg,
-	image.ExtVhd:   convertUsingQemuImg,
-	image.ExtVhdx:  convertUsingQemuImg,
-	"":             toNoop,
+	image.ExtGz:     toGz,
+	image.ExtXz:     toXz,
+	image.ExtLzma:   toLzma,
+	image.ExtZstd:   toZstd,
+	image.ExtBz2:    toBz2,
+	image.ExtLz4:    toLz4,
+	image.ExtBrotli: toBrotli,
+	image.ExtZip:    toZip,
+	image.Ext7z:     to7z,
+	image.ExtTar:    toTar,
+	image.ExtCpio:   toCpio,
+	image.ExtQcow2:  convertUsingQemuImg,
+	image.ExtVmdk:   convertUsingQemuImg,
+	image.ExtVdi:    convertUsingQemuImg,
+	image.ExtVhd:    convertUsingQemuImg,
+	image.ExtVhdx:   convertUsingQemuImg,
+	image.ExtQed:    convertUsingQemuImg,
+	image.ExtHds:    convertUsingQemuImg,
+	"":              toNoop,
+
+	ExtStreamOptimizedVmdk: convertUsingQemuImg,
+	ExtMultiExtentVmdk:     convertUsingQemuImg,
+	ExtFixedVhd:            convertUsingQemuImg,
+	ExtOverlayQcow2:        toOverlayQcow2,
+	ExtBackingChainTar:     toBackingChainTar,
+	ExtLuksQcow2:           toLuksQcow2,
 }
 
 // FormatTestData accepts the path of a single file (srcFile) and attempts to generate an output
@@ -134,20 +189,285 @@ func toXz(src, tgtDir, ext string) (string, error) {
 	return tgtPath, nil
 }
 
+func toLzma(src, tgtDir, ext string) (string, error) {
+	tgtFile, tgtPath, _ := createTargetFile(src, tgtDir, image.ExtLzma)
+	defer tgtFile.Close()
+
+	w, err := lzma.NewWriter(tgtFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error getting lzma writer for file %s", tgtPath)
+	}
+	defer w.Close()
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error opening file %s", src)
+	}
+	defer srcFile.Close()
+
+	_, err = io.Copy(w, srcFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error writing to file %s", tgtPath)
+	}
+	return tgtPath, nil
+}
+
+func toZstd(src, tgtDir, ext string) (string, error) {
+	tgtFile, tgtPath, _ := createTargetFile(src, tgtDir, image.ExtZstd)
+	defer tgtFile.Close()
+
+	w, err := zstd.NewWriter(tgtFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error getting zstd writer for file %s", tgtPath)
+	}
+	defer w.Close()
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error opening file %s", src)
+	}
+	defer srcFile.Close()
+
+	_, err = io.Copy(w, srcFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error writing to file %s", tgtPath)
+	}
+	return tgtPath, nil
+}
+
+// toBz2 shells out to bzip2 since the standard library only provides a bzip2 decompressor.
+func toBz2(src, tgtDir, ext string) (string, error) {
+	tgtFile, tgtPath, _ := createTargetFile(src, tgtDir, image.ExtBz2)
+	defer tgtFile.Close()
+
+	cmd := exec.Command("bzip2", "-c", src)
+	cmd.Stdout = tgtFile
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "Error writing to file %s", tgtPath)
+	}
+	return tgtPath, nil
+}
+
+func toLz4(src, tgtDir, ext string) (string, error) {
+	tgtFile, tgtPath, _ := createTargetFile(src, tgtDir, image.ExtLz4)
+	defer tgtFile.Close()
+
+	w := lz4.NewWriter(tgtFile)
+	defer w.Close()
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error opening file %s", src)
+	}
+	defer srcFile.Close()
+
+	_, err = io.Copy(w, srcFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error writing to file %s", tgtPath)
+	}
+	return tgtPath, nil
+}
+
+func toBrotli(src, tgtDir, ext string) (string, error) {
+	tgtFile, tgtPath, _ := createTargetFile(src, tgtDir, image.ExtBrotli)
+	defer tgtFile.Close()
+
+	w := brotli.NewWriter(tgtFile)
+	defer w.Close()
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error opening file %s", src)
+	}
+	defer srcFile.Close()
+
+	_, err = io.Copy(w, srcFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error writing to file %s", tgtPath)
+	}
+	return tgtPath, nil
+}
+
+func toZip(src, tgtDir, ext string) (string, error) {
+	tgtFile, tgtPath, _ := createTargetFile(src, tgtDir, image.ExtZip)
+	defer tgtFile.Close()
+
+	w := zip.NewWriter(tgtFile)
+	defer w.Close()
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error opening file %s", src)
+	}
+	defer srcFile.Close()
+
+	entry, err := w.Create(filepath.Base(src))
+	if err != nil {
+		return "", errors.Wrapf(err, "Error creating zip entry for %s", src)
+	}
+	_, err = io.Copy(entry, srcFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error writing to file %s", tgtPath)
+	}
+	return tgtPath, nil
+}
+
+func to7z(src, tgtDir, ext string) (string, error) {
+	tgtFile, tgtPath, _ := createTargetFile(src, tgtDir, image.Ext7z)
+	defer tgtFile.Close()
+
+	w, err := newSevenzipWriter(tgtFile, filepath.Base(src))
+	if err != nil {
+		return "", errors.Wrapf(err, "Error creating 7z writer for %s", tgtPath)
+	}
+	defer w.Close()
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error opening file %s", src)
+	}
+	defer srcFile.Close()
+
+	_, err = io.Copy(w, srcFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error writing to file %s", tgtPath)
+	}
+	return tgtPath, nil
+}
+
+// toCpio writes a newc format cpio archive holding the source file.
+func toCpio(src, tgtDir, ext string) (string, error) {
+	tgtFile, tgtPath, _ := createTargetFile(src, tgtDir, image.ExtCpio)
+	defer tgtFile.Close()
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error opening file %s", src)
+	}
+	defer srcFile.Close()
+
+	srcFileInfo, err := srcFile.Stat()
+	if err != nil {
+		return "", errors.Wrapf(err, "Error stating file %s", src)
+	}
+
+	var written int64
+	write := func(data io.Reader) error {
+		n, err := io.Copy(tgtFile, data)
+		written += n
+		if err != nil {
+			return err
+		}
+		// the name and the data are padded to a multiple of 4 bytes
+		pad, err := tgtFile.Write(make([]byte, (4-written%4)%4))
+		written += int64(pad)
+		return err
+	}
+	writeEntry := func(name string, mode uint32, size int64, data io.Reader) error {
+		// inode, mode, uid, gid, nlink, mtime, filesize, devmajor, devminor, rdevmajor, rdevminor, namesize, check
+		hdr := fmt.Sprintf("070701%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%s\x00",
+			1, mode, 0, 0, 1, 0, size, 0, 0, 0, 0, len(name)+1, 0, name)
+		if err := write(strings.NewReader(hdr)); err != nil {
+			return err
+		}
+		return write(data)
+	}
+
+	if err := writeEntry(filepath.Base(src), 0100644, srcFileInfo.Size(), srcFile); err != nil {
+		return "", errors.Wrapf(err, "Error writing to file %s", tgtPath)
+	}
+	if err := writeEntry("TRAILER!!!", 0, 0, strings.NewReader("")); err != nil {
+		return "", errors.Wrapf(err, "Error writing to file %s", tgtPath)
+	}
+	return tgtPath, nil
+}
+
 func convertUsingQemuImg(srcfile, tgtDir, ext string) (string, error) {
 	base := strings.TrimSuffix(filepath.Base(srcfile), ".iso")
 	tgt := filepath.Join(tgtDir, base+ext)
-	args := []string{"convert", "-f", "raw", "-O", extToQemuFormat(ext), srcfile, tgt}
+	format, createOptions := extToQemuFormat(ext), ""
+	if sub, ok := qemuSubformats[ext]; ok {
+		format, createOptions = sub.format, "subformat="+sub.subformat
+	}
+
+	if err := qemuOperations.ConvertRawImage(srcfile, tgt, format, createOptions, ""); err != nil {
+		return "", err
+	}
+	return tgt, verifyFile(tgt)
+}
 
-	if err := doCmdAndVerifyFile(tgt, "qemu-img", args...); err != nil {
+// toOverlayQcow2 converts the source to a qcow2 base image and creates an overlay image over it, the
+// overlay references the base image by its file name.
+func toOverlayQcow2(src, tgtDir, ext string) (string, error) {
+	baseImage, err := convertUsingQemuImg(src, tgtDir, ".base"+image.ExtQcow2)
+	if err != nil {
 		return "", err
 	}
+	base := strings.TrimSuffix(filepath.Base(src), ".iso")
+	tgt := filepath.Join(tgtDir, base+ext)
+	if err := qemuOperations.CreateOverlay(filepath.Base(baseImage), tgt); err != nil {
+		return "", err
+	}
+	return tgt, verifyFile(tgt)
+}
+
+// toLuksQcow2 converts the source to a qcow2 image encrypted with LUKS, the passphrase is
+// LuksPassphrase.
+func toLuksQcow2(src, tgtDir, ext string) (string, error) {
+	base := strings.TrimSuffix(filepath.Base(src), ".iso")
+	tgt := filepath.Join(tgtDir, base+ext)
+	keyFile, err := os.CreateTemp("", "luks-passphrase")
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating the passphrase file")
+	}
+	defer os.Remove(keyFile.Name())
+	_, err = keyFile.WriteString(LuksPassphrase)
+	keyFile.Close()
+	if err != nil {
+		return "", errors.Wrap(err, "Error writing the passphrase file")
+	}
+	if err := qemuOperations.ConvertRawImage(src, tgt, image.QemuFormatQcow2, "", keyFile.Name()); err != nil {
+		return "", err
+	}
+	return tgt, verifyFile(tgt)
+}
+
+// toBackingChainTar archives the qcow2 image with the backing files it references, found next to it.
+func toBackingChainTar(src, tgtDir, ext string) (string, error) {
+	files := []string{src}
+	for imagePath := src; ; {
+		f, err := os.Open(imagePath)
+		if err != nil {
+			return "", errors.Wrapf(err, "Error opening file %s", imagePath)
+		}
+		backing, err := image.Qcow2BackingFile(f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		if backing == "" {
+			break
+		}
+		imagePath = filepath.Join(filepath.Dir(imagePath), backing)
+		files = append(files, imagePath)
+	}
+	tgtPath, err := ArchiveFiles(src, tgtDir, files...)
+	if err != nil {
+		return "", err
+	}
+	tgt := strings.TrimSuffix(tgtPath, image.ExtTar) + ext
+	if err := os.Rename(tgtPath, tgt); err != nil {
+		return "", errors.Wrapf(err, "Error renaming file %s", tgtPath)
+	}
 	return tgt, nil
 }
 
 func extToQemuFormat(targetFormat string) string {
-	if targetFormat == ".vhd" {
-		return "vpc"
+	switch targetFormat {
+	case image.ExtVhd:
+		return image.QemuFormatVhd
+	case image.ExtHds:
+		return image.QemuFormatParallels
 	}
 	// trim prefix "."
 	return targetFormat[1:]
@@ -157,10 +477,8 @@ func toNoop(src, tgtDir, ext string) (string, error) {
 	return copyIfNotPresent(src, tgtDir)
 }
 
-func doCmdAndVerifyFile(tgt, cmd string, args ...string) error {
-	if err := doCmd(cmd, args...); err != nil {
-		return err
-	}
+// verifyFile returns an error if the file written by a conversion does not exist
+func verifyFile(tgt string) error {
 	if _, err := os.Stat(tgt); err != nil {
 		return errors.Wrapf(err, "Failed to stat file %q", tgt)
 	}
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"hash/crc32"
+	"io"
+	"unicode/utf16"
+
+	"github.com/ulikunitz/xz/lzma"
+)
+
+const (
+	// sevenzipSignatureHeaderSize is the size of the header at the start of a 7z archive, locating
+	// the archive header
+	sevenzipSignatureHeaderSize = 32
+	// sevenzipDictProp is the LZMA2 property of the dictionary used by the sevenzipWriter, 1 MiB
+	sevenzipDictProp = 16
+	sevenzipDictCap  = 1 << 20
+)
+
+// the property IDs of the 7z archive header
+const (
+	sevenzipIDEnd              = 0x00
+	sevenzipIDHeader           = 0x01
+	sevenzipIDMainStreamsInfo  = 0x04
+	sevenzipIDFilesInfo        = 0x05
+	sevenzipIDPackInfo         = 0x06
+	sevenzipIDUnpackInfo       = 0x07
+	sevenzipIDSubStreamsInfo   = 0x08
+	sevenzipIDSize             = 0x09
+	sevenzipIDCRC              = 0x0A
+	sevenzipIDFolder           = 0x0B
+	sevenzipIDCodersUnpackSize = 0x0C
+	sevenzipIDName             = 0x11
+)
+
+var (
+	sevenzipSignature   = []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}
+	sevenzipMethodLZMA2 = []byte{0x21}
+)
+
+// sevenzipWriter writes a 7z archive holding a single file compressed with LZMA2, the Go 7z
+// libraries only read archives. The archive header is written uncompressed.
+type sevenzipWriter struct {
+	w    io.WriteSeeker
+	name string
+
+	start  int64
+	packed countingWriter
+	lz     *lzma.Writer2
+	crc    hash.Hash32
+	size   uint64
+	err    error
+}
+
+// newSevenzipWriter creates a new sevenzipWriter storing the data written to it as the file name.
+func newSevenzipWriter(w io.WriteSeeker, name string) (*sevenzipWriter, error) {
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	// the signature header is written on Close, once the position of the header is known
+	if _, err = w.Write(make([]byte, sevenzipSignatureHeaderSize)); err != nil {
+		return nil, err
+	}
+	z := &sevenzipWriter{w: w, name: name, start: start, crc: crc32.NewIEEE()}
+	z.packed.w = w
+	if z.lz, err = (lzma.Writer2Config{DictCap: sevenzipDictCap}).NewWriter2(&z.packed); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+func (z *sevenzipWriter) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	n, err := z.lz.Write(p)
+	z.crc.Write(p[:n])
+	z.size += uint64(n)
+	z.err = err
+	return n, err
+}
+
+// Close finishes the compressed stream and writes the archive header.
+func (z *sevenzipWriter) Close() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.err = z.lz.Close(); z.err != nil {
+		return z.err
+	}
+	hdr := z.header()
+	if _, z.err = z.w.Write(hdr); z.err != nil {
+		return z.err
+	}
+	sh := make([]byte, sevenzipSignatureHeaderSize)
+	copy(sh, sevenzipSignature)
+	sh[7] = 4 // version 0.4
+	binary.LittleEndian.PutUint64(sh[12:], z.packed.n)
+	binary.LittleEndian.PutUint64(sh[20:], uint64(len(hdr)))
+	binary.LittleEndian.PutUint32(sh[28:], crc32.ChecksumIEEE(hdr))
+	binary.LittleEndian.PutUint32(sh[8:], crc32.ChecksumIEEE(sh[12:]))
+	if _, z.err = z.w.Seek(z.start, io.SeekStart); z.err != nil {
+		return z.err
+	}
+	if _, z.err = z.w.Write(sh); z.err != nil {
+		return z.err
+	}
+	_, z.err = z.w.Seek(0, io.SeekEnd)
+	return z.err
+}
+
+func (z *sevenzipWriter) header() []byte {
+	var b bytes.Buffer
+	b.WriteByte(sevenzipIDHeader)
+	b.WriteByte(sevenzipIDMainStreamsInfo)
+
+	b.WriteByte(sevenzipIDPackInfo)
+	writeSevenzipNumber(&b, 0)
+	writeSevenzipNumber(&b, 1)
+	b.WriteByte(sevenzipIDSize)
+	writeSevenzipNumber(&b, z.packed.n)
+	b.WriteByte(sevenzipIDEnd)
+
+	b.WriteByte(sevenzipIDUnpackInfo)
+	b.WriteByte(sevenzipIDFolder)
+	writeSevenzipNumber(&b, 1)
+	b.WriteByte(0) // not external
+	writeSevenzipNumber(&b, 1)
+	b.WriteByte(0x20 | byte(len(sevenzipMethodLZMA2))) // coder with properties
+	b.Write(sevenzipMethodLZMA2)
+	writeSevenzipNumber(&b, 1)
+	b.WriteByte(sevenzipDictProp)
+	b.WriteByte(sevenzipIDCodersUnpackSize)
+	writeSevenzipNumber(&b, z.size)
+	b.WriteByte(sevenzipIDEnd)
+
+	// the CRC of the file is recorded in the substreams info, as 7-Zip does
+	b.WriteByte(sevenzipIDSubStreamsInfo)
+	b.WriteByte(sevenzipIDCRC)
+	b.WriteByte(1) // all defined
+	binary.Write(&b, binary.LittleEndian, z.crc.Sum32())
+	b.WriteByte(sevenzipIDEnd)
+	b.WriteByte(sevenzipIDEnd)
+
+	b.WriteByte(sevenzipIDFilesInfo)
+	writeSevenzipNumber(&b, 1)
+	name := utf16.Encode([]rune(z.name))
+	b.WriteByte(sevenzipIDName)
+	writeSevenzipNumber(&b, uint64(1+2*(len(name)+1)))
+	b.WriteByte(0) // not external
+	binary.Write(&b, binary.LittleEndian, append(name, 0))
+	b.WriteByte(sevenzipIDEnd)
+
+	b.WriteByte(sevenzipIDEnd)
+	return b.Bytes()
+}
+
+// writeSevenzipNumber writes the variable length encoding of the numbers of the 7z header.
+func writeSevenzipNumber(b *bytes.Buffer, v uint64) {
+	var n int
+	for n = 0; n < 8; n++ {
+		if v < uint64(1)<<(7*(n+1)) {
+			break
+		}
+	}
+	first := byte(0xFF << (8 - n))
+	if n < 8 {
+		first |= byte(v >> (8 * n))
+	}
+	b.WriteByte(first)
+	for i := 0; i < n; i++ {
+		b.WriteByte(byte(v >> (8 * i)))
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
@@ -44,12 +44,25 @@ func main() {
 		[]string{""},
 		[]string{".gz"},
 		[]string{".xz"},
+		[]string{".lzma"},
 		[]string{".qcow2"},
 		[]string{".vmdk"},
+		[]string{utils.ExtStreamOptimizedVmdk},
+		[]string{utils.ExtMultiExtentVmdk},
 		[]string{".vhd"},
+		[]string{utils.ExtFixedVhd},
 		[]string{".vhdx"},
+		[]string{".qed"},
+		[]string{".hds"},
+		[]string{utils.ExtOverlayQcow2},
+		[]string{utils.ExtOverlayQcow2, utils.ExtBackingChainTar},
+		[]string{utils.ExtLuksQcow2},
 		[]string{".qcow2", ".gz"},
 		[]string{".qcow2", ".xz"},
+		[]string{".qcow2", ".zip"},
+		[]string{".qcow2", ".7z"},
+		[]string{".qcow2", ".br"},
+		[]string{".qcow2", ".cpio", ".gz"},
 	}
 
 	if err := utils.CreateCertForTestService(util.GetNamespace(), serviceName, configMapName, *certDir, certFile, keyFile); err != nil {
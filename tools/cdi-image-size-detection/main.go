@@ -1,14 +1,17 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"log"
 	"net/url"
 	"os"
 	"strconv"
 
+	"kubevirt.io/containerized-data-importer/pkg/common"
 	controller "kubevirt.io/containerized-data-importer/pkg/controller/common"
 	"kubevirt.io/containerized-data-importer/pkg/image"
+	"kubevirt.io/containerized-data-importer/pkg/importer"
 	"kubevirt.io/containerized-data-importer/pkg/util"
 )
 
@@ -25,7 +28,7 @@ var (
 )
 
 func init() {
-	flag.StringVar(&imgPath, "image-path", "", "(Mandatory) URL address of the virtual image.")
+	flag.StringVar(&imgPath, "image-path", "", "(Mandatory) URL address of the virtual image, an http or https URL is probed without downloading the image.")
 	flag.StringVar(&scheme, "scheme", defaultScheme, "(Optional) Virtual image's URI scheme.")
 	flag.Parse()
 	if imgPath == "" {
@@ -35,6 +38,11 @@ func init() {
 }
 
 func main() {
+	if ep, err := url.Parse(imgPath); err == nil && (ep.Scheme == "http" || ep.Scheme == "https") {
+		detectHTTPSize(ep)
+		return
+	}
+
 	// Initialize 'qemu-img' handler
 	log.Println("Initializing size-detection pod")
 	qemuOperations := image.NewQEMUOperations()
@@ -52,13 +60,38 @@ func main() {
 		os.Exit(controller.ErrInvalidFile)
 	}
 
-	// Write the parsed virtual size to the termination message file
-	strSize := strconv.FormatInt(imgInfo.VirtualSize, decimal)
-	err = util.WriteTerminationMessage(strSize)
+	writeSize(imgInfo.VirtualSize)
+	log.Println("Size-detection binary has completed")
+}
+
+// detectHTTPSize finds the virtual size of the image of an http source without downloading it, with
+// the credentials, certificates and headers passed in the environment as to the importer. The error
+// is written to the termination message file when the size cannot be found.
+func detectHTTPSize(ep *url.URL) {
+	log.Printf("Probing the size of '%s'", ep.Redacted())
+	accessKey, _ := util.ParseEnvVar(common.ImporterAccessKeyID, false)
+	secKey, _ := util.ParseEnvVar(common.ImporterSecretKey, false)
+	certDir, _ := util.ParseEnvVar(common.ImporterCertDirVar, false)
+	size, err := importer.HTTPVirtualSize(imgPath, accessKey, secKey, certDir)
 	if err != nil {
+		log.Printf("Unable to find the size of '%s': '%s'", ep.Redacted(), err.Error())
+		if err := util.WriteTerminationMessage(err.Error()); err != nil {
+			log.Printf("Unable to write to termination file: '%s'", err.Error())
+		}
+		if errors.Is(err, importer.ErrUnknownVirtualSize) {
+			os.Exit(controller.ErrInvalidFile)
+		}
+		os.Exit(controller.ErrInvalidPath)
+	}
+	writeSize(size)
+	log.Println("Size-detection binary has completed")
+}
+
+// writeSize writes the virtual size to the termination message file
+func writeSize(size int64) {
+	strSize := strconv.FormatInt(size, decimal)
+	if err := util.WriteTerminationMessage(strSize); err != nil {
 		log.Printf("Unable to write to termination file: '%s'", err.Error())
 		os.Exit(controller.ErrBadTermFile)
 	}
-
-	log.Println("Size-detection binary has completed")
 }